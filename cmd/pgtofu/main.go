@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"github.com/accented-ai/pgtofu/internal/cli"
@@ -17,12 +16,12 @@ var (
 func main() {
 	ctx := context.Background()
 
-	if err := cli.Execute(ctx, cli.BuildInfo{
+	err := cli.Execute(ctx, cli.BuildInfo{
 		Version:   version,
 		Commit:    commit,
 		BuildTime: buildTime,
-	}); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	})
+
+	// Execute has already reported the error; only the exit status is left.
+	os.Exit(cli.ExitCode(err))
 }
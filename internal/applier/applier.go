@@ -0,0 +1,234 @@
+// Package applier runs generated migrations against a database. It keeps the
+// version table golang-migrate keeps and takes the advisory lock it takes, so
+// the two can be used on the same database, one after the other.
+package applier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// DefaultTable is the version table golang-migrate uses by default.
+const DefaultTable = "schema_migrations"
+
+// NoVersion is the version of a database no migration has been applied to.
+const NoVersion = -1
+
+// Conn is the database session migrations are applied on. Every call must run
+// on the same session, so the advisory lock and the transactions migration
+// files open hold from one call to the next.
+type Conn interface {
+	Exec(ctx context.Context, sql string, args ...any) error
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Options struct {
+	// Table is the version table, optionally qualified by its schema. An
+	// empty value is treated as DefaultTable in the current schema.
+	Table string
+	// Progress, when set, is called before each migration is applied.
+	Progress func(migration *Migration)
+}
+
+// Applier applies migrations on one session.
+type Applier struct {
+	conn  Conn
+	opts  Options
+	table versionTable
+}
+
+func New(conn Conn, opts Options) *Applier {
+	if opts.Table == "" {
+		opts.Table = DefaultTable
+	}
+
+	return &Applier{conn: conn, opts: opts}
+}
+
+// Result is what Apply did.
+type Result struct {
+	// FromVersion and ToVersion are the versions of the database before and
+	// after the run, NoVersion when none was recorded.
+	FromVersion int
+	ToVersion   int
+	Applied     []Migration
+}
+
+// ErrDirty reports that the version table records a migration that failed
+// part way. The database has to be repaired, and the version forced with
+// golang-migrate, before any other migration runs.
+var ErrDirty = errors.New("database is dirty")
+
+// StatementError is the failure of a statement of a migration. Statements
+// before it have run; Rolledback reports whether the migration's transaction
+// undid them, leaving the database at the version it had before the run.
+type StatementError struct {
+	Migration  *Migration
+	Statement  Statement
+	Rolledback bool
+	Err        error
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("migration %s: statement at line %d failed: %v",
+		e.Migration.FileName, e.Statement.Line, e.Err)
+}
+
+func (e *StatementError) Unwrap() error {
+	return e.Err
+}
+
+// Pending returns the migrations of migrations newer than the version the
+// database records, and that version. It neither locks the version table nor
+// creates it.
+func (a *Applier) Pending(ctx context.Context, migrations []Migration) ([]Migration, int, error) {
+	if err := a.resolveTable(ctx); err != nil {
+		return nil, NoVersion, err
+	}
+
+	exists, err := a.checkTable(ctx)
+	if err != nil || !exists {
+		return pendingAfter(migrations, NoVersion), NoVersion, err
+	}
+
+	version, dirty, err := a.readVersion(ctx)
+	if err != nil {
+		return nil, NoVersion, err
+	}
+
+	if dirty {
+		return nil, version, dirtyError(version)
+	}
+
+	return pendingAfter(migrations, version), version, nil
+}
+
+// Apply runs the pending migrations in version order while holding the
+// advisory lock, creating the version table if it is missing. It stops at the
+// first statement that fails and returns a *StatementError for it.
+func (a *Applier) Apply(ctx context.Context, migrations []Migration) (*Result, error) {
+	if err := a.resolveTable(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := a.conn.Exec(ctx, "SELECT pg_advisory_lock($1)", a.table.lockID); err != nil {
+		return nil, util.WrapError("acquire migration lock", err)
+	}
+
+	defer func() {
+		// The lock goes with the session if it cannot be released here.
+		a.conn.Exec(context.WithoutCancel(ctx), //nolint:errcheck
+			"SELECT pg_advisory_unlock($1)", a.table.lockID)
+	}()
+
+	exists, err := a.checkTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if err := a.conn.Exec(ctx, a.table.createSQL()); err != nil {
+			return nil, util.WrapError("create version table", err)
+		}
+	}
+
+	version, dirty, err := a.readVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if dirty {
+		return nil, dirtyError(version)
+	}
+
+	result := &Result{FromVersion: version, ToVersion: version}
+
+	for _, migration := range pendingAfter(migrations, version) {
+		if a.opts.Progress != nil {
+			a.opts.Progress(&migration)
+		}
+
+		if err := a.applyMigration(ctx, &migration, result.ToVersion); err != nil {
+			return result, err
+		}
+
+		result.ToVersion = migration.Version
+		result.Applied = append(result.Applied, migration)
+	}
+
+	return result, nil
+}
+
+// applyMigration runs the statements of migration, recording it as dirty
+// while they run, as golang-migrate does. previous is the version recorded
+// before it.
+func (a *Applier) applyMigration(ctx context.Context, migration *Migration, previous int) error {
+	if err := a.setVersion(ctx, migration.Version, true); err != nil {
+		return err
+	}
+
+	// committed is set once a statement has run outside a transaction block,
+	// after which a failure leaves the migration applied in part.
+	inTransaction, committed := false, false
+
+	for _, stmt := range migration.Statements {
+		begins, ends := transactionControl(stmt.SQL)
+
+		if err := a.conn.Exec(ctx, stmt.SQL); err != nil {
+			stmtErr := &StatementError{Migration: migration, Statement: stmt, Err: err}
+
+			if inTransaction {
+				a.conn.Exec(context.WithoutCancel(ctx), "ROLLBACK") //nolint:errcheck
+			}
+
+			if (inTransaction || begins) && !committed {
+				stmtErr.Rolledback = true
+
+				if resetErr := a.setVersion(ctx, previous, false); resetErr != nil {
+					return errors.Join(stmtErr, resetErr)
+				}
+			}
+
+			return stmtErr
+		}
+
+		switch {
+		case begins:
+			inTransaction = true
+		case ends:
+			inTransaction = false
+			committed = true
+		case !inTransaction:
+			committed = true
+		}
+	}
+
+	if inTransaction {
+		a.conn.Exec(context.WithoutCancel(ctx), "ROLLBACK") //nolint:errcheck
+
+		return fmt.Errorf("migration %s leaves a transaction open", migration.FileName)
+	}
+
+	return a.setVersion(ctx, migration.Version, false)
+}
+
+// pendingAfter returns the migrations newer than version.
+func pendingAfter(migrations []Migration, version int) []Migration {
+	for i := range migrations {
+		if migrations[i].Version > version {
+			return migrations[i:]
+		}
+	}
+
+	return nil
+}
+
+func dirtyError(version int) error {
+	return fmt.Errorf("%w at version %d: repair it, then force the version with golang-migrate",
+		ErrDirty, version)
+}
@@ -0,0 +1,133 @@
+package applier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// Migration is the up file of one migration version.
+type Migration struct {
+	Version     int
+	Description string
+	// FileName is the name of the file in its directory, such as
+	// "000003_add_table_orders.up.sql".
+	FileName   string
+	Statements []Statement
+}
+
+// Statement is one statement of a migration file, run on its own.
+type Statement struct {
+	SQL string
+	// Line is the line of the file the statement starts on.
+	Line int
+}
+
+// LoadMigrations reads the up files of the golang-migrate migrations in dir,
+// ordered by version. Down files are ignored. Goose files, which hold both
+// directions, are rejected: goose tracks them in a table of its own.
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, util.WrapError("read migrations directory", err)
+	}
+
+	var migrations []Migration
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		version, description, direction, err := generator.ParseMigrationFileName(name)
+		if err != nil {
+			if _, _, _, gooseErr := generator.ParseMigrationFileNameFor(
+				generator.OutputFormatGoose, name); gooseErr == nil {
+				return nil, fmt.Errorf("%s is a goose migration; apply only runs golang-migrate files", name)
+			}
+
+			continue
+		}
+
+		if direction != generator.DirectionUp {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, util.WrapError("read migration "+name, err)
+		}
+
+		statements, err := splitMigration(string(content))
+		if err != nil {
+			return nil, util.WrapError("split migration "+name, err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version:     version,
+			Description: description,
+			FileName:    name,
+			Statements:  statements,
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migrations %s and %s have the same version",
+				migrations[i-1].FileName, migrations[i].FileName)
+		}
+	}
+
+	return migrations, nil
+}
+
+// splitMigration splits the text of a migration file into the statements
+// that are sent to the database one at a time.
+func splitMigration(content string) ([]Statement, error) {
+	parsed, err := parser.SplitStatements(content)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	statements := make([]Statement, 0, len(parsed))
+	for _, stmt := range parsed {
+		sql := stmt.NormalizedSQL()
+		if sql == "" {
+			continue
+		}
+
+		statements = append(statements, Statement{SQL: sql, Line: stmt.Line})
+	}
+
+	return statements, nil
+}
+
+// transactionControl reports whether sql opens or closes a transaction block,
+// as the BEGIN and COMMIT pgtofu wraps transactional migrations in do.
+func transactionControl(sql string) (begins, ends bool) {
+	fields := strings.Fields(strings.ToUpper(sql))
+	if len(fields) == 0 {
+		return false, false
+	}
+
+	switch fields[0] {
+	case "BEGIN", "START":
+		return true, false
+	case "COMMIT", "END", "ABORT":
+		return false, true
+	case "ROLLBACK":
+		// ROLLBACK TO SAVEPOINT leaves the transaction open.
+		return false, len(fields) == 1 || fields[1] != "TO"
+	}
+
+	return false, false
+}
@@ -0,0 +1,297 @@
+package applier_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/applier"
+)
+
+// fakeConn is a session that keeps a golang-migrate version table in memory
+// and records the statements sent to it.
+type fakeConn struct {
+	tableExists  bool
+	tableColumns int
+	version      *int64
+	dirty        bool
+	// failOn fails the first statement containing it.
+	failOn string
+	execs  []string
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{tableColumns: 2}
+}
+
+func (c *fakeConn) Exec(_ context.Context, sql string, args ...any) error {
+	c.execs = append(c.execs, sql)
+
+	switch {
+	case c.failOn != "" && strings.Contains(sql, c.failOn):
+		return errors.New("relation already exists")
+	case strings.HasPrefix(sql, "CREATE TABLE IF NOT EXISTS"):
+		c.tableExists = true
+	case strings.HasPrefix(sql, "TRUNCATE"):
+		c.version = nil
+	case strings.HasPrefix(sql, "INSERT INTO"):
+		version := int64(args[0].(int))               //nolint:forcetypeassert
+		c.version, c.dirty = &version, args[1].(bool) //nolint:forcetypeassert
+	}
+
+	return nil
+}
+
+func (c *fakeConn) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	switch {
+	case strings.Contains(sql, "current_database()"):
+		return fakeRow{values: []any{"app", "public"}}
+	case strings.Contains(sql, "information_schema.columns"):
+		if !c.tableExists {
+			return fakeRow{values: []any{0, 0, 0}}
+		}
+
+		return fakeRow{values: []any{c.tableColumns, 1, 1}}
+	case c.version == nil:
+		return fakeRow{err: pgx.ErrNoRows}
+	default:
+		return fakeRow{values: []any{*c.version, c.dirty}}
+	}
+}
+
+// migrationStatements returns the statements of the migrations sent to the
+// session, leaving out the lock and the version table's, and the transaction
+// each update of the version table runs in.
+func (c *fakeConn) migrationStatements() []string {
+	versionTable := func(i int) bool {
+		return i >= 0 && i < len(c.execs) && strings.Contains(c.execs[i], "schema_migrations")
+	}
+
+	var statements []string
+
+	for i, sql := range c.execs {
+		switch {
+		case strings.Contains(sql, "pg_advisory"), versionTable(i),
+			sql == "BEGIN" && versionTable(i+1),
+			sql == "COMMIT" && versionTable(i-1):
+			continue
+		}
+
+		statements = append(statements, sql)
+	}
+
+	return statements
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	for i := range dest {
+		switch d := dest[i].(type) {
+		case *string:
+			*d = r.values[i].(string) //nolint:forcetypeassert
+		case *int:
+			*d = r.values[i].(int) //nolint:forcetypeassert
+		case *int64:
+			*d = r.values[i].(int64) //nolint:forcetypeassert
+		case *bool:
+			*d = r.values[i].(bool) //nolint:forcetypeassert
+		}
+	}
+
+	return nil
+}
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func loadMigrations(t *testing.T, files map[string]string) []applier.Migration {
+	t.Helper()
+
+	migrations, err := applier.LoadMigrations(writeMigrations(t, files))
+	require.NoError(t, err)
+
+	return migrations
+}
+
+var testMigrations = map[string]string{ //nolint:gochecknoglobals
+	"000001_add_table_orders.up.sql": `-- Migration: 000001_add_table_orders.up.sql
+BEGIN;
+
+CREATE TABLE public.orders (id BIGINT NOT NULL);
+
+COMMIT;
+`,
+	"000001_add_table_orders.down.sql": "DROP TABLE public.orders;\n",
+	"000002_add_index_orders_id.up.sql": `-- Runs outside a transaction.
+CREATE INDEX CONCURRENTLY orders_id_idx ON public.orders (id);
+`,
+	"000002_add_index_orders_id.down.sql": "DROP INDEX public.orders_id_idx;\n",
+	"README.md":                           "not a migration",
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Parallel()
+
+	migrations := loadMigrations(t, testMigrations)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "add_table_orders", migrations[0].Description)
+	assert.Equal(t, []applier.Statement{
+		{SQL: "BEGIN", Line: 2},
+		{SQL: "CREATE TABLE public.orders (id BIGINT NOT NULL)", Line: 4},
+		{SQL: "COMMIT", Line: 6},
+	}, migrations[0].Statements)
+	assert.Equal(t, "000002_add_index_orders_id.up.sql", migrations[1].FileName)
+}
+
+func TestLoadMigrationsRejectsGooseFiles(t *testing.T) {
+	t.Parallel()
+
+	_, err := applier.LoadMigrations(writeMigrations(t, map[string]string{
+		"00001_add_table_orders.sql": "-- +goose Up\nCREATE TABLE orders (id BIGINT);\n",
+	}))
+	require.ErrorContains(t, err, "goose migration")
+}
+
+func TestApplyCreatesVersionTableAndRecordsVersions(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeConn()
+
+	result, err := applier.New(conn, applier.Options{}).
+		Apply(context.Background(), loadMigrations(t, testMigrations))
+	require.NoError(t, err)
+
+	assert.Equal(t, applier.NoVersion, result.FromVersion)
+	assert.Equal(t, 2, result.ToVersion)
+	assert.Len(t, result.Applied, 2)
+	assert.True(t, conn.tableExists)
+	require.NotNil(t, conn.version)
+	assert.Equal(t, int64(2), *conn.version)
+	assert.False(t, conn.dirty)
+
+	assert.Contains(t, conn.execs, "SELECT pg_advisory_lock($1)")
+	assert.Equal(t, []string{
+		"BEGIN",
+		"CREATE TABLE public.orders (id BIGINT NOT NULL)",
+		"COMMIT",
+		"CREATE INDEX CONCURRENTLY orders_id_idx ON public.orders (id)",
+	}, conn.migrationStatements())
+}
+
+func TestApplySkipsAppliedMigrations(t *testing.T) {
+	t.Parallel()
+
+	version := int64(1)
+	conn := newFakeConn()
+	conn.tableExists, conn.version = true, &version
+
+	result, err := applier.New(conn, applier.Options{}).
+		Apply(context.Background(), loadMigrations(t, testMigrations))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.FromVersion)
+	assert.Equal(t, 2, result.ToVersion)
+	assert.Equal(t, []string{
+		"CREATE INDEX CONCURRENTLY orders_id_idx ON public.orders (id)",
+	}, conn.migrationStatements())
+}
+
+func TestApplyRollsBackFailedTransactionalMigration(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeConn()
+	conn.failOn = "CREATE TABLE public.orders"
+
+	result, err := applier.New(conn, applier.Options{}).
+		Apply(context.Background(), loadMigrations(t, testMigrations))
+
+	var stmtErr *applier.StatementError
+	require.ErrorAs(t, err, &stmtErr)
+	assert.Equal(t, "000001_add_table_orders.up.sql", stmtErr.Migration.FileName)
+	assert.Equal(t, 4, stmtErr.Statement.Line)
+	assert.True(t, stmtErr.Rolledback)
+	assert.ErrorContains(t, err,
+		"migration 000001_add_table_orders.up.sql: statement at line 4 failed: relation already exists")
+
+	assert.Empty(t, result.Applied)
+	assert.Nil(t, conn.version, "a rolled back migration leaves no version")
+	assert.Contains(t, conn.migrationStatements(), "ROLLBACK")
+	assert.NotContains(t, conn.migrationStatements(), "CREATE INDEX CONCURRENTLY orders_id_idx ON public.orders (id)")
+}
+
+func TestApplyLeavesPartialMigrationDirty(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeConn()
+	conn.failOn = "CREATE INDEX CONCURRENTLY"
+
+	result, err := applier.New(conn, applier.Options{}).
+		Apply(context.Background(), loadMigrations(t, map[string]string{
+			"000001_add_table_orders.up.sql": "CREATE TABLE orders (id BIGINT);\n" +
+				"CREATE INDEX CONCURRENTLY orders_id_idx ON orders (id);\n",
+		}))
+
+	var stmtErr *applier.StatementError
+	require.ErrorAs(t, err, &stmtErr)
+	assert.False(t, stmtErr.Rolledback)
+	assert.Equal(t, applier.NoVersion, result.ToVersion)
+	require.NotNil(t, conn.version)
+	assert.Equal(t, int64(1), *conn.version)
+	assert.True(t, conn.dirty)
+
+	_, err = applier.New(conn, applier.Options{}).
+		Apply(context.Background(), loadMigrations(t, testMigrations))
+	require.ErrorIs(t, err, applier.ErrDirty)
+}
+
+func TestApplyRejectsForeignVersionTable(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeConn()
+	conn.tableExists, conn.tableColumns = true, 4
+
+	_, err := applier.New(conn, applier.Options{}).
+		Apply(context.Background(), loadMigrations(t, testMigrations))
+	require.ErrorContains(t, err, "is not a golang-migrate version table")
+	assert.Empty(t, conn.migrationStatements())
+}
+
+func TestPendingDoesNotWrite(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeConn()
+
+	pending, version, err := applier.New(conn, applier.Options{}).
+		Pending(context.Background(), loadMigrations(t, testMigrations))
+	require.NoError(t, err)
+
+	assert.Equal(t, applier.NoVersion, version)
+	assert.Len(t, pending, 2)
+	assert.Empty(t, conn.execs)
+	assert.False(t, conn.tableExists)
+}
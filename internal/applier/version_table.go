@@ -0,0 +1,150 @@
+package applier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// advisoryLockSalt is the salt golang-migrate multiplies the checksum of a
+// lock name by.
+const advisoryLockSalt uint32 = 1486364155
+
+// versionTable is the resolved version table and the advisory lock that
+// serializes its migrations.
+type versionTable struct {
+	schema string
+	name   string
+	lockID int64
+}
+
+func (t versionTable) quotedName() string {
+	return schema.QuotedName(t.schema, t.name)
+}
+
+func (t versionTable) createSQL() string {
+	return "CREATE TABLE IF NOT EXISTS " + t.quotedName() +
+		" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"
+}
+
+// advisoryLockID is the key golang-migrate's postgres driver locks for the
+// version table schemaName.tableName of database, so pgtofu and golang-migrate
+// never run migrations at the same time.
+func advisoryLockID(database, schemaName, tableName string) int64 {
+	name := strings.Join([]string{schemaName, tableName, database}, "\x00")
+
+	return int64(crc32.ChecksumIEEE([]byte(name)) * advisoryLockSalt)
+}
+
+// resolveTable qualifies the version table with the current schema when it is
+// not qualified, and derives its lock.
+func (a *Applier) resolveTable(ctx context.Context) error {
+	schemaName, name, qualified := strings.Cut(a.opts.Table, ".")
+	if !qualified {
+		schemaName, name = "", a.opts.Table
+	}
+
+	var database, current string
+
+	if err := a.conn.QueryRow(ctx, "SELECT current_database(), current_schema()").
+		Scan(&database, &current); err != nil {
+		return util.WrapError("read current schema", err)
+	}
+
+	// The table is named as it is written in SQL; current_schema() returns
+	// the name as it is stored.
+	a.table = versionTable{schema: current, name: schema.FoldIdentifier(name)}
+	if schemaName != "" {
+		a.table.schema = schema.FoldIdentifier(schemaName)
+	}
+
+	a.table.lockID = advisoryLockID(database, a.table.schema, a.table.name)
+
+	return nil
+}
+
+// checkTable reports whether the version table exists. A table of that name
+// with other columns than golang-migrate's is an error rather than reused.
+func (a *Applier) checkTable(ctx context.Context) (bool, error) {
+	const query = `
+SELECT count(*),
+       count(*) FILTER (WHERE column_name = 'version' AND data_type = 'bigint'),
+       count(*) FILTER (WHERE column_name = 'dirty' AND data_type = 'boolean')
+FROM information_schema.columns
+WHERE table_schema = $1 AND table_name = $2`
+
+	var columns, version, dirty int
+
+	if err := a.conn.QueryRow(ctx, query, a.table.schema, a.table.name).
+		Scan(&columns, &version, &dirty); err != nil {
+		return false, util.WrapError("inspect version table", err)
+	}
+
+	if columns == 0 {
+		return false, nil
+	}
+
+	if columns != 2 || version != 1 || dirty != 1 {
+		return false, fmt.Errorf(
+			"%s is not a golang-migrate version table (version bigint, dirty boolean)",
+			a.table.quotedName())
+	}
+
+	return true, nil
+}
+
+// readVersion returns the version the version table records, NoVersion when
+// it is empty.
+func (a *Applier) readVersion(ctx context.Context) (int, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := a.conn.QueryRow(ctx, "SELECT version, dirty FROM "+a.table.quotedName()+" LIMIT 1").
+		Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NoVersion, false, nil
+	}
+
+	if err != nil {
+		return NoVersion, false, util.WrapError("read version", err)
+	}
+
+	return int(version), dirty, nil
+}
+
+// setVersion replaces the row of the version table, as golang-migrate does;
+// NoVersion leaves it empty.
+func (a *Applier) setVersion(ctx context.Context, version int, dirty bool) error {
+	statements := []func() error{
+		func() error { return a.conn.Exec(ctx, "BEGIN") },
+		func() error { return a.conn.Exec(ctx, "TRUNCATE "+a.table.quotedName()) },
+	}
+
+	if version != NoVersion {
+		statements = append(statements, func() error {
+			return a.conn.Exec(ctx, "INSERT INTO "+a.table.quotedName()+
+				" (version, dirty) VALUES ($1, $2)", version, dirty)
+		})
+	}
+
+	statements = append(statements, func() error { return a.conn.Exec(ctx, "COMMIT") })
+
+	for _, run := range statements {
+		if err := run(); err != nil {
+			a.conn.Exec(context.WithoutCancel(ctx), "ROLLBACK") //nolint:errcheck
+
+			return util.WrapError(fmt.Sprintf("record version %d", version), err)
+		}
+	}
+
+	return nil
+}
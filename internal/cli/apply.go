@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/applier"
+	"github.com/accented-ai/pgtofu/internal/util"
+	"github.com/accented-ai/pgtofu/pkg/database"
+)
+
+type applyConfig struct {
+	databaseURL   string
+	migrationsDir string
+	table         string
+	dryRun        bool
+}
+
+func newApplyCommand(ctx context.Context) *cobra.Command {
+	cfg := &applyConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply pending migrations to a database",
+		Long: `Apply the migrations of a directory that the database has not run yet,
+in version order.
+
+The applied version is tracked in the same table golang-migrate uses
+(schema_migrations by default, created when missing), under the same
+advisory lock, so apply and golang-migrate can take turns on a database.
+Each statement of a migration is sent on its own: files generated with a
+transaction run in it, and files holding statements that cannot run in a
+transaction, such as CREATE INDEX CONCURRENTLY, run without one.
+
+apply stops at the first statement that fails. A migration that ran in a
+transaction is rolled back and the database stays at the previous version;
+one that failed part way is recorded as dirty, as golang-migrate does, and
+has to be repaired and forced with golang-migrate before apply runs again.
+
+Only golang-migrate files ({version}_{description}.up.sql) are applied.`,
+		Example: `  # Apply pending migrations
+  pgtofu apply --database-url "$DATABASE_URL" --migrations-dir ./migrations
+
+  # Show what would be applied
+  pgtofu apply --database-url "$DATABASE_URL" --migrations-dir ./migrations --dry-run
+
+  # Track versions in another table
+  pgtofu apply --database-url "$DATABASE_URL" --migrations-table ops.schema_migrations`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return commandFailure(phaseApply, runApply(ctx, cfg, cmd.OutOrStdout()))
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.databaseURL, "database-url", os.Getenv("DATABASE_URL"),
+		"PostgreSQL connection URL (or set DATABASE_URL env var)")
+	cmd.Flags().StringVar(&cfg.migrationsDir, "migrations-dir", "./migrations",
+		"Directory of the migration files")
+	cmd.Flags().StringVar(&cfg.table, "migrations-table", applier.DefaultTable,
+		"Version table, optionally schema-qualified")
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false,
+		"Print the pending migrations without applying them")
+
+	cmd.MarkFlagRequired("database-url") //nolint:errcheck
+
+	return cmd
+}
+
+func runApply(ctx context.Context, cfg *applyConfig, out io.Writer) error {
+	migrations, err := applier.LoadMigrations(cfg.migrationsDir)
+	if err != nil {
+		return inputError(phaseLoad, err)
+	}
+
+	pool, err := database.NewPoolFromURL(ctx, cfg.databaseURL)
+	if err != nil {
+		return util.WrapError("connect to database", err)
+	}
+	defer pool.Close()
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return util.WrapError("connect to database", err)
+	}
+	defer conn.Release()
+
+	a := applier.New(conn, applier.Options{
+		Table: cfg.table,
+		Progress: func(migration *applier.Migration) {
+			fmt.Fprintf(os.Stderr, "Applying %s (%d statements)\n",
+				migration.FileName, len(migration.Statements))
+		},
+	})
+
+	if cfg.dryRun {
+		pending, version, err := a.Pending(ctx, migrations)
+		if err != nil {
+			return applyError(err)
+		}
+
+		writeApplyPlan(out, pending, version)
+
+		return nil
+	}
+
+	result, err := a.Apply(ctx, migrations)
+	if err != nil {
+		return applyError(err)
+	}
+
+	if len(result.Applied) == 0 {
+		fmt.Fprintf(os.Stderr, "No pending migrations; database is at version %s\n",
+			formatAppliedVersion(result.ToVersion))
+
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Applied %d migrations: version %s -> %s\n",
+		len(result.Applied),
+		formatAppliedVersion(result.FromVersion),
+		formatAppliedVersion(result.ToVersion))
+
+	return nil
+}
+
+// applyError categorizes a failure of apply. A dirty database is a check
+// that refused to go ahead; a failed statement is reported with its text and
+// what it left behind.
+func applyError(err error) error {
+	if errors.Is(err, applier.ErrDirty) {
+		return validationError(phaseCheck, err)
+	}
+
+	var stmtErr *applier.StatementError
+	if !errors.As(err, &stmtErr) {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\nFailed statement (%s, line %d):\n%s;\n\n",
+		stmtErr.Migration.FileName, stmtErr.Statement.Line, stmtErr.Statement.SQL)
+
+	if stmtErr.Rolledback {
+		fmt.Fprintf(os.Stderr, "The migration was rolled back.\n")
+	} else {
+		fmt.Fprintf(os.Stderr,
+			"The migration was applied in part; version %d is recorded as dirty.\n",
+			stmtErr.Migration.Version)
+	}
+
+	return internalError(phaseApply, err)
+}
+
+// writeApplyPlan writes the statements of the pending migrations, each
+// migration introduced by a "-- >>> file: <name>" line.
+func writeApplyPlan(out io.Writer, pending []applier.Migration, version int) {
+	fmt.Fprintf(os.Stderr, "Database is at version %s; %d pending migrations\n",
+		formatAppliedVersion(version), len(pending))
+
+	for i := range pending {
+		fmt.Fprintf(out, "%s%s\n", migrationStreamDelimiter, pending[i].FileName)
+
+		for _, stmt := range pending[i].Statements {
+			fmt.Fprintf(out, "%s;\n", stmt.SQL)
+		}
+	}
+}
+
+func formatAppliedVersion(version int) string {
+	if version == applier.NoVersion {
+		return "none"
+	}
+
+	return strconv.Itoa(version)
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/applier"
+)
+
+func TestWriteApplyPlan(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	writeApplyPlan(&out, []applier.Migration{
+		{
+			Version:  3,
+			FileName: "000003_add_table_orders.up.sql",
+			Statements: []applier.Statement{
+				{SQL: "BEGIN", Line: 1},
+				{SQL: "CREATE TABLE orders (id BIGINT)", Line: 3},
+				{SQL: "COMMIT", Line: 5},
+			},
+		},
+	}, 2)
+
+	want := "-- >>> file: 000003_add_table_orders.up.sql\n" +
+		"BEGIN;\nCREATE TABLE orders (id BIGINT);\nCOMMIT;\n"
+	if out.String() != want {
+		t.Fatalf("unexpected plan:\n%s", out.String())
+	}
+}
+
+func TestApplyErrorCategories(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{
+			name: "dirty database",
+			err:  fmt.Errorf("%w at version 3", applier.ErrDirty),
+			code: ExitValidationError,
+		},
+		{
+			name: "failed statement",
+			err: &applier.StatementError{
+				Migration: &applier.Migration{Version: 3, FileName: "000003_x.up.sql"},
+				Statement: applier.Statement{SQL: "CREATE TABLE orders ()", Line: 3},
+				Err:       errors.New("relation already exists"),
+			},
+			code: ExitInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := applyError(tt.err)
+			if got := ExitCode(err); got != tt.code {
+				t.Fatalf("ExitCode() = %d, want %d", got, tt.code)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("applyError() lost the cause: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+type baselineConfig struct {
+	desired     string
+	outputDir   string
+	preview     bool
+	down        bool
+	omitTime    bool
+	toolVersion string
+}
+
+// baselineComments are the change types besides ADD_ ones a baseline takes:
+// they set the comment of an object it creates with COMMENT ON.
+var baselineComments = []differ.ChangeType{ //nolint:gochecknoglobals
+	differ.ChangeTypeModifySchemaComment,
+	differ.ChangeTypeModifyTableComment,
+	differ.ChangeTypeModifyColumnComment,
+	differ.ChangeTypeModifyConstraintComment,
+	differ.ChangeTypeModifyIndexComment,
+	differ.ChangeTypeModifyTriggerComment,
+	differ.ChangeTypeModifyCustomTypeComment,
+}
+
+func newBaselineCommand(ctx context.Context, info BuildInfo) *cobra.Command {
+	cfg := &baselineConfig{toolVersion: formatToolVersion(info)}
+
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Write the whole desired schema as a single first migration",
+		Long: `Compare the desired schema with an empty database and write everything it
+declares as migration version 1, named baseline, in dependency order:
+schemas, extensions, types, tables, indexes, functions, triggers, views,
+materialized views, hypertables and policies. The migration is never split,
+whatever the number of statements, and only creates objects.
+
+No database is needed. The output directory must not contain migrations yet.
+Before anything is written, the generated migration is parsed back to check
+that pgtofu reads it cleanly, as the desired schema.
+
+No down migration is written unless --down is given. Its down migration then
+drops every schema the baseline creates objects in with CASCADE, recreating
+an empty public schema, instead of dropping each object in turn.`,
+		Example: `  # Start migrations for an existing desired schema
+  pgtofu baseline --desired ./schema --output-dir ./migrations
+
+  # Preview the baseline without writing files
+  pgtofu baseline --desired ./schema --preview
+
+  # Also write a down migration that drops the schemas
+  pgtofu baseline --desired ./schema --down`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commandFailure(phaseGenerate, runBaseline(ctx, cfg, cmd.InOrStdin()))
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.desired, "desired", "",
+		"Path to desired schema SQL file or directory, or - for stdin")
+	cmd.Flags().StringVar(&cfg.outputDir, "output-dir", "./migrations",
+		"Output directory for migration files")
+	cmd.Flags().BoolVar(&cfg.preview, "preview", false,
+		"Preview the migration without writing files")
+	cmd.Flags().BoolVar(&cfg.down, "down", false,
+		"Write a down migration that drops the schemas the baseline creates objects in")
+	cmd.Flags().BoolVar(&cfg.omitTime, "omit-timestamp", false,
+		"Leave the Generated timestamp out of the migration header")
+
+	cmd.MarkFlagRequired("desired") //nolint:errcheck
+
+	return cmd
+}
+
+func runBaseline(ctx context.Context, cfg *baselineConfig, stdin io.Reader) error {
+	desired, err := loadDesiredSchema(ctx, cfg.desired, stdin)
+	if err != nil {
+		return err
+	}
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = cfg.outputDir
+	opts.Baseline = true
+	opts.GenerateDownMigrations = cfg.down
+	opts.OmitTimestamp = cfg.omitTime
+	opts.ToolVersion = cfg.toolVersion
+	opts.Comparison = "an empty database"
+
+	if err := checkBaselineOutput(opts); err != nil {
+		return err
+	}
+
+	now, err := sourceDateEpoch(os.Getenv(sourceDateEpochEnv))
+	if err != nil {
+		return validationError(phaseUsage, err)
+	}
+
+	opts.Now = now
+
+	fmt.Fprintf(os.Stderr, "Comparing with an empty schema...\n")
+
+	diffResult, err := differ.New(differ.DefaultOptions()).CompareContext(ctx,
+		&schema.Database{Version: schema.SchemaVersion}, desired)
+	if err != nil {
+		return internalError(phaseDiff, util.WrapError("compare schemas", err))
+	}
+
+	displayWarnings("Diff Warnings", diffResult.Diagnostics)
+
+	if !diffResult.HasChanges() {
+		fmt.Fprintf(os.Stderr, "\nThe desired schema is empty. No migration generated.\n")
+		return nil
+	}
+
+	if err := checkBaselineChanges(diffResult.Changes); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d changes\n", len(diffResult.Changes))
+
+	// The migration is laid out in memory first, so that it is parsed back
+	// before any file is written.
+	previewOpts := *opts
+	previewOpts.PreviewMode = true
+
+	preview, err := generator.New(&previewOpts).GenerateContext(ctx, diffResult)
+	if err != nil {
+		return internalError(phaseGenerate, util.WrapError("generate baseline", err))
+	}
+
+	if err := checkBaselineParses(ctx, preview, desired); err != nil {
+		return err
+	}
+
+	if cfg.preview {
+		fmt.Println(preview.Summary())
+		printMigrationContents(preview)
+
+		return changesFound()
+	}
+
+	genResult, err := generator.New(opts).GenerateContext(ctx, diffResult)
+	if err != nil {
+		return internalError(phaseGenerate, util.WrapError("generate baseline", err))
+	}
+
+	fmt.Println(genResult.Summary())
+
+	absPath, _ := filepath.Abs(cfg.outputDir)
+	fmt.Fprintf(os.Stderr, "\nBaseline written to: %s\n", absPath)
+
+	return changesFound()
+}
+
+// checkBaselineOutput refuses to write a baseline into a directory that
+// already holds migrations, whose versions it would not come first in.
+func checkBaselineOutput(opts *generator.Options) error {
+	next, err := generator.New(opts).GetNextMigrationVersion()
+	if err != nil {
+		return inputError(phaseLoad, util.WrapError("read output directory", err))
+	}
+
+	if next != opts.StartVersion {
+		return validationError(phaseUsage, fmt.Errorf(
+			"%s already contains migrations up to version %d; a baseline must be the first",
+			opts.OutputDir, next-1))
+	}
+
+	return nil
+}
+
+// checkBaselineChanges makes sure a comparison against an empty schema only
+// creates objects and sets their comments, and never alters, drops or
+// rewrites one.
+func checkBaselineChanges(changes []differ.Change) error {
+	for _, change := range changes {
+		if !isBaselineChange(change) {
+			verb, _, _ := strings.Cut(string(change.Type), "_")
+
+			return internalError(phaseDiff, fmt.Errorf(
+				"baseline would %s: %s", strings.ToLower(verb), change.Description))
+		}
+	}
+
+	return nil
+}
+
+// isBaselineChange reports whether a baseline may contain change: one that
+// adds an object, or sets the comment of one. The comment of a function is
+// a MODIFY_FUNCTION with the comments in its details.
+func isBaselineChange(change differ.Change) bool {
+	switch {
+	case strings.HasPrefix(string(change.Type), "ADD_"),
+		slices.Contains(baselineComments, change.Type):
+		return true
+	case change.Type == differ.ChangeTypeModifyFunction:
+		_, isComment := change.Details["new_comment"]
+		return isComment
+	default:
+		return false
+	}
+}
+
+// checkBaselineParses parses the up migration of the baseline back and
+// compares it with desired, so that a statement pgtofu writes but cannot
+// read, or reads as another object, fails the command instead of the next
+// comparison against the migrated database.
+func checkBaselineParses(
+	ctx context.Context,
+	result *generator.GenerateResult,
+	desired *schema.Database,
+) error {
+	p := parser.New()
+	db := &schema.Database{Version: schema.SchemaVersion}
+
+	for _, migration := range result.Migrations {
+		if err := p.ParseSQLContext(ctx, migration.UpFile.Content, db); err != nil {
+			return internalError(phaseCheck, util.WrapError(
+				"parse back "+migration.UpFile.FileName, err))
+		}
+	}
+
+	if err := checkParserErrors(p); err != nil {
+		return internalError(phaseCheck,
+			util.WrapError("generated baseline does not parse back cleanly", err))
+	}
+
+	db.Sort()
+
+	again, err := differ.New(differ.DefaultOptions()).CompareContext(ctx, db, desired)
+	if err != nil {
+		return internalError(phaseCheck, util.WrapError("compare the parsed baseline", err))
+	}
+
+	if len(again.Changes) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "generated baseline parses back with %d differences from the desired schema:",
+		len(again.Changes))
+
+	for _, change := range again.Changes {
+		sb.WriteString("\n    ")
+		sb.WriteString(change.Description)
+	}
+
+	return internalError(phaseCheck, errors.New(sb.String()))
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestBaseline(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"schema/app.sql": `CREATE SCHEMA app;
+CREATE TABLE app.users (id BIGINT PRIMARY KEY, email TEXT NOT NULL);
+CREATE INDEX users_email_idx ON app.users (email);
+CREATE VIEW app.active_users AS SELECT id FROM app.users;`,
+	})
+	outputDir := filepath.Join(dir, "migrations")
+
+	code, stderr := runCLI(t, "baseline", "--desired", filepath.Join(dir, "schema"),
+		"--output-dir", outputDir, "--omit-timestamp")
+	if code != ExitChanges {
+		t.Fatalf("expected changes, got %d:\n%s", code, stderr)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "000001_baseline.up.sql" {
+		t.Fatalf("expected only 000001_baseline.up.sql, got %v (%v)", entries, err)
+	}
+
+	up, err := os.ReadFile(filepath.Join(outputDir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"CREATE SCHEMA IF NOT EXISTS app;", "CREATE TABLE app.users (",
+		"CREATE INDEX users_email_idx", "CREATE VIEW app.active_users",
+	} {
+		if !strings.Contains(string(up), want) {
+			t.Fatalf("expected %q in the baseline, got:\n%s", want, up)
+		}
+	}
+
+	code, stderr = runCLI(t, "baseline", "--desired", filepath.Join(dir, "schema"),
+		"--output-dir", outputDir, "--down")
+	if code != ExitValidationError || !strings.Contains(stderr, "must be the first") {
+		t.Fatalf("expected a second baseline to be refused, got %d:\n%s", code, stderr)
+	}
+}
+
+func TestBaselineDown(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"schema.sql": `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+	})
+	outputDir := filepath.Join(dir, "migrations")
+
+	code, stderr := runCLI(t, "baseline", "--desired", filepath.Join(dir, "schema.sql"),
+		"--output-dir", outputDir, "--down")
+	if code != ExitChanges {
+		t.Fatalf("expected changes, got %d:\n%s", code, stderr)
+	}
+
+	down, err := os.ReadFile(filepath.Join(outputDir, "000001_baseline.down.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(down), "DROP SCHEMA IF EXISTS public CASCADE;") ||
+		strings.Contains(string(down), "DROP TABLE") {
+		t.Fatalf("expected the down migration to drop the schema, got:\n%s", down)
+	}
+}
+
+func TestBaselineRejectsAlters(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"schema.sql": `CREATE TABLE users (id BIGINT PRIMARY KEY);
+COMMENT ON TABLE users IS 'People';
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;`,
+	})
+
+	code, stderr := runCLI(t, "baseline", "--desired", filepath.Join(dir, "schema.sql"),
+		"--output-dir", filepath.Join(dir, "migrations"))
+	if code == ExitChanges || !strings.Contains(stderr, "baseline would modify: Row level security") {
+		t.Fatalf("expected the row security change to be refused, got %d:\n%s", code, stderr)
+	}
+}
+
+func TestCheckBaselineParsesComparesWithDesired(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{}
+	if err := parser.New().ParseSQL(`CREATE TABLE users (id BIGINT PRIMARY KEY);`, desired); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := func(up string) *generator.GenerateResult {
+		return &generator.GenerateResult{Migrations: []generator.MigrationPair{{
+			UpFile: &generator.MigrationFile{FileName: "000001_baseline.up.sql", Content: up},
+		}}}
+	}
+
+	ctx := context.Background()
+
+	if err := checkBaselineParses(ctx, baseline(
+		"BEGIN;\nCREATE TABLE public.users (id BIGINT PRIMARY KEY);\nCOMMIT;\n"), desired); err != nil {
+		t.Fatalf("expected the baseline to match, got %v", err)
+	}
+
+	err := checkBaselineParses(ctx, baseline(
+		"CREATE TABLE public.\"Users\" (id BIGINT PRIMARY KEY);\n"), desired)
+	if err == nil || !strings.Contains(err.Error(), "parses back with 2 differences") {
+		t.Fatalf("expected the renamed table to be reported, got %v", err)
+	}
+}
@@ -2,11 +2,12 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/accented-ai/pgtofu/internal/util"
 )
 
 type BuildInfo struct {
@@ -15,17 +16,93 @@ type BuildInfo struct {
 	BuildTime string
 }
 
+// ExitError asks for the process to exit with Code. Err is reported first
+// when it is set; without it the process exits silently, the way a command
+// signals that it found changes, or compare --exit-code that the schemas
+// differ.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Execute runs the command named by the process arguments. A fatal error is
+// reported on stderr in the --error-format before it is returned; the
+// returned error is a *CommandError or an *ExitError, and ExitCode maps it to
+// the exit status.
 func Execute(ctx context.Context, info BuildInfo) error {
+	return execute(ctx, info, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+}
+
+// execute runs args with the streams commands read "-" inputs from and write
+// their reports to; progress messages always go to the process stderr.
+func execute(
+	ctx context.Context,
+	info BuildInfo,
+	args []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+) error {
 	rootCmd := newRootCommand()
 	rootCmd.AddCommand(
+		newInitCommand(ctx),
 		newExtractCommand(ctx),
-		newDiffCommand(),
-		newGenerateCommand(),
+		newDiffCommand(ctx, info),
+		newCompareCommand(ctx),
+		newGenerateCommand(ctx, info),
+		newBaselineCommand(ctx, info),
+		newApplyCommand(ctx),
 		newPartitionCommand(),
+		newCheckCompatCommand(ctx),
+		newLintCommand(ctx),
 		newVersionCommand(info),
 	)
 
-	return util.WrapError("execute command", rootCmd.ExecuteContext(ctx))
+	errorFormat := rootCmd.PersistentFlags().String("error-format", errorFormatText,
+		"Format of fatal errors on stderr (text or json)")
+	rootCmd.PersistentPreRunE = func(*cobra.Command, []string) error {
+		if *errorFormat != errorFormatText && *errorFormat != errorFormatJSON {
+			return validationError(phaseUsage,
+				fmt.Errorf("invalid --error-format %q (use text or json)", *errorFormat))
+		}
+
+		return nil
+	}
+
+	rootCmd.SetArgs(args)
+	rootCmd.SetIn(stdin)
+	rootCmd.SetOut(stdout)
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err == nil {
+		return nil
+	}
+
+	// Commands return categorized errors, so any other error comes from cobra
+	// rejecting a flag, an argument or the command name before anything ran.
+	var (
+		cmdErr  *CommandError
+		exitErr *ExitError
+	)
+
+	if !errors.As(err, &cmdErr) && !errors.As(err, &exitErr) {
+		err = validationError(phaseUsage, err)
+	}
+
+	reportError(stderr, err, *errorFormat)
+
+	return err
 }
 
 func newRootCommand() *cobra.Command {
@@ -41,15 +118,3 @@ safe, versioned migration files compatible with golang-migrate.`,
 		SilenceErrors: true,
 	}
 }
-
-func newVersionCommand(info BuildInfo) *cobra.Command {
-	return &cobra.Command{
-		Use:   "version",
-		Short: "Print version information",
-		Run: func(_ *cobra.Command, _ []string) {
-			fmt.Printf("pgtofu %s\n", info.Version)
-			fmt.Printf("  commit:     %s\n", info.Commit)
-			fmt.Printf("  built:      %s\n", info.BuildTime)
-		},
-	}
-}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/textdiff"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// Exit statuses of compare --exit-code, matching git diff --exit-code. They
+// take the place of ExitChanges and the categorized error statuses.
+const (
+	compareExitDifferent = 1
+	compareExitError     = 2
+)
+
+type compareConfig struct {
+	exitCode bool
+}
+
+func newCompareCommand(ctx context.Context) *cobra.Command {
+	cfg := &compareConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "compare <old> <new>",
+		Short: "Show how two SQL schemas differ",
+		Long: `Parse two SQL schemas, each a file, a directory of .sql files or - for
+stdin, and report how they differ: a unified diff of the canonical SQL of
+every changed object, followed by the list of changes.
+
+Nothing is written and no database is needed, so the schemas can be the
+pg_dump --schema-only output of two environments.`,
+		Example: `  # Compare staging and production schema dumps
+  pgtofu compare staging.sql prod.sql
+
+  # Page through the report
+  pgtofu compare staging.sql prod.sql | less
+
+  # Fail a script when the schemas drift apart
+  pgtofu compare --exit-code ./schema prod.sql
+
+  # Compare against a dump streamed from another tool
+  pg_dump --schema-only "$DATABASE_URL" | pgtofu compare - ./schema`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			different, err := runCompare(ctx, cmd.InOrStdin(), cmd.OutOrStdout(), args[0], args[1])
+			err = commandFailure(phaseDiff, err)
+
+			if !cfg.exitCode {
+				if err == nil && different {
+					return changesFound()
+				}
+
+				return err
+			}
+
+			if err != nil {
+				return &ExitError{Code: compareExitError, Err: err}
+			}
+
+			if different {
+				return &ExitError{Code: compareExitDifferent}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.exitCode, "exit-code", false,
+		"Exit with 1 when the schemas differ and 0 when they do not, like git diff")
+
+	return cmd
+}
+
+// runCompare writes the report for oldPath and newPath to out and reports
+// whether the schemas differ. Either path can be "-" to read it from stdin.
+func runCompare(
+	ctx context.Context,
+	stdin io.Reader,
+	out io.Writer,
+	oldPath, newPath string,
+) (bool, error) {
+	if err := checkStdinInputs(oldPath, newPath); err != nil {
+		return false, err
+	}
+
+	oldDB, err := loadSQLSchema(ctx, "old", oldPath, stdin)
+	if err != nil {
+		return false, err
+	}
+
+	newDB, err := loadSQLSchema(ctx, "new", newPath, stdin)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Comparing schemas...\n")
+
+	result, err := differ.New(differ.DefaultOptions()).CompareContext(ctx, oldDB, newDB)
+	if err != nil {
+		return false, internalError(phaseDiff, util.WrapError("compare schemas", err))
+	}
+
+	displayWarnings("Diff Warnings", result.Diagnostics)
+
+	err = writeCompareReport(ctx, out, displayPath(oldPath), displayPath(newPath), result)
+	if err != nil {
+		return false, err
+	}
+
+	return result.HasChanges(), nil
+}
+
+// writeCompareReport writes a unified diff of the canonical SQL of every
+// object a change applies to, in object order, then the changes themselves.
+// Objects whose canonical SQL is the same on both sides appear in the change
+// list only.
+func writeCompareReport(
+	ctx context.Context,
+	out io.Writer,
+	oldPath, newPath string,
+	result *differ.DiffResult,
+) error {
+	if !result.HasChanges() {
+		fmt.Fprintln(out, "No differences found.")
+		return nil
+	}
+
+	oldSQL, err := renderCanonicalByKey(ctx, result.Current)
+	if err != nil {
+		return err
+	}
+
+	newSQL, err := renderCanonicalByKey(ctx, result.Desired)
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[string]bool, len(result.Changes))
+	for _, change := range result.Changes {
+		changed[generator.CanonicalKey(change)] = true
+	}
+
+	for _, key := range sortedKeys(oldSQL, newSQL) {
+		if !changed[key] {
+			continue
+		}
+
+		diff := textdiff.Unified(
+			oldPath, newPath, oldSQL[key], newSQL[key], textdiff.DefaultContext,
+		)
+		if diff == "" {
+			continue
+		}
+
+		fmt.Fprintf(out, "diff %s\n%s\n", key, diff)
+	}
+
+	fmt.Fprintf(out, "Changes: %d\n", len(result.Changes))
+
+	for _, change := range result.Changes {
+		fmt.Fprintf(out, "[%s] %s: %s\n", change.Severity, change.Type, change.Description)
+	}
+
+	return nil
+}
+
+func renderCanonicalByKey(ctx context.Context, db *schema.Database) (map[string]string, error) {
+	objects, err := generator.RenderCanonical(ctx, db)
+	if err != nil {
+		return nil, util.WrapError("render "+db.DatabaseName+" schema", err)
+	}
+
+	rendered := make(map[string]string, len(objects))
+	for _, object := range objects {
+		rendered[object.Key()] = object.SQL + "\n"
+	}
+
+	return rendered, nil
+}
+
+func sortedKeys(sets ...map[string]string) []string {
+	seen := make(map[string]bool)
+
+	var keys []string
+
+	for _, m := range sets {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCompareSchema(t *testing.T, dir, name, sql string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(sql), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestRunCompare(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	oldPath := writeCompareSchema(t, dir, "old.sql", `
+CREATE TABLE users (id BIGINT PRIMARY KEY, email TEXT NOT NULL);
+CREATE TABLE legacy (id INT);
+`)
+	newPath := writeCompareSchema(t, dir, "new.sql", `
+create table users (
+    id bigint primary key,
+    email varchar(320) not null
+);
+CREATE INDEX idx_users_email ON users (email);
+`)
+
+	var out bytes.Buffer
+
+	different, err := runCompare(context.Background(), nil, &out, oldPath, newPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !different {
+		t.Fatal("expected the schemas to differ")
+	}
+
+	report := out.String()
+
+	for _, want := range []string{
+		"diff table public.users\n--- " + oldPath + "\n+++ " + newPath + "\n",
+		"-    email TEXT NOT NULL,\n+    email VARCHAR(320) NOT NULL,\n",
+		"diff table public.legacy\n",
+		"-CREATE TABLE public.legacy (\n",
+		"diff index public.idx_users_email\n",
+		"+CREATE INDEX idx_users_email ON public.users (email);\n",
+		"Changes: 3\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+
+	if strings.Index(report, "diff index") > strings.Index(report, "diff table") {
+		t.Errorf("objects are not ordered by type:\n%s", report)
+	}
+
+	out.Reset()
+
+	different, err = runCompare(context.Background(), nil, &out, newPath, newPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if different || out.String() != "No differences found.\n" {
+		t.Fatalf("expected no differences, got %v:\n%s", different, out.String())
+	}
+}
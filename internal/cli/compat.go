@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/compat"
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// Values of check-compat --format.
+const (
+	compatFormatText = "text"
+	compatFormatJSON = "json"
+)
+
+type compatConfig struct {
+	desired     string
+	postgres    int
+	timescaleDB string
+	format      string
+}
+
+func newCheckCompatCommand(ctx context.Context) *cobra.Command {
+	cfg := &compatConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "check-compat",
+		Short: "Report which server versions the desired schema needs",
+		Long: `Walk the desired schema (SQL files) and report the PostgreSQL and
+TimescaleDB features it uses, grouped by the minimum version each needs.
+
+Features the requested versions do not support are flagged with the file and
+line that declares them, and the command exits with status 4.`,
+		Example: `  # Check that the schema runs on PostgreSQL 13
+  pgtofu check-compat --desired ./schema --postgres 13
+
+  # Include TimescaleDB features, with a report for CI
+  pgtofu check-compat --desired ./schema --postgres 15 --timescaledb 2.7 --format json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return commandFailure(phaseCheck,
+				runCheckCompat(ctx, cfg, cmd.InOrStdin(), cmd.OutOrStdout()))
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.desired, "desired", "",
+		"Path to desired schema SQL file or directory, or - for stdin")
+	cmd.Flags().IntVar(&cfg.postgres, "postgres", 0,
+		"PostgreSQL major version the schema has to run on")
+	cmd.Flags().StringVar(&cfg.timescaleDB, "timescaledb", "",
+		"TimescaleDB version the schema has to run on, such as 2.9 (unchecked if unset)")
+	cmd.Flags().StringVar(&cfg.format, "format", compatFormatText,
+		"Format of the report (text or json)")
+
+	cmd.MarkFlagRequired("desired")  //nolint:errcheck
+	cmd.MarkFlagRequired("postgres") //nolint:errcheck
+
+	return cmd
+}
+
+func runCheckCompat(ctx context.Context, cfg *compatConfig, stdin io.Reader, out io.Writer) error {
+	if cfg.format != compatFormatText && cfg.format != compatFormatJSON {
+		return validationError(phaseUsage,
+			fmt.Errorf("invalid --format %q (use text or json)", cfg.format))
+	}
+
+	if cfg.postgres <= 0 {
+		return validationError(phaseUsage,
+			fmt.Errorf("invalid --postgres %d (use a major version such as 13)", cfg.postgres))
+	}
+
+	if _, err := compat.ParseTimescaleVersion(cfg.timescaleDB); err != nil {
+		return validationError(phaseUsage, fmt.Errorf("invalid --timescaledb: %w", err))
+	}
+
+	desired, err := loadSQLSchema(ctx, "desired", cfg.desired, stdin)
+	if err != nil {
+		return err
+	}
+
+	report := compat.Check(desired, compat.Target{
+		Postgres:    cfg.postgres,
+		TimescaleDB: cfg.timescaleDB,
+	})
+
+	if cfg.format == compatFormatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return internalError(phaseCheck, err)
+		}
+
+		fmt.Fprintln(out, string(data))
+	} else {
+		writeCompatReport(out, report)
+	}
+
+	incompatible := report.Incompatible()
+	if len(incompatible) == 0 {
+		return nil
+	}
+
+	diagnostics := make([]diag.Warning, 0, len(incompatible))
+	for i := range incompatible {
+		finding := &incompatible[i]
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:       diag.CodeIncompatibleFeature,
+			Severity:   diag.SeverityError,
+			Message:    fmt.Sprintf("%s needs %s", finding.Description, requiredVersion(finding)),
+			ObjectName: finding.Object,
+			File:       finding.File,
+			Line:       finding.Line,
+		})
+	}
+
+	return validationError(phaseCheck,
+		fmt.Errorf("%s of features the target versions do not support",
+			util.Plural(len(incompatible), "use", "uses")),
+		diagnostics...)
+}
+
+// writeCompatReport writes the findings grouped by the version they need,
+// marking the groups the target does not support.
+func writeCompatReport(out io.Writer, report *compat.Report) {
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(out, "No version-dependent features found.")
+		return
+	}
+
+	group := ""
+
+	for i := range report.Findings {
+		finding := &report.Findings[i]
+
+		if version := requiredVersion(finding); version != group {
+			group = version
+
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+
+			if finding.Incompatible {
+				fmt.Fprintf(out, "%s (not supported by the target)\n", version)
+			} else {
+				fmt.Fprintln(out, version)
+			}
+		}
+
+		mark := " "
+		if finding.Incompatible {
+			mark = "✗"
+		}
+
+		fmt.Fprintf(out, "  %s %s: %s", mark, finding.Feature, finding.Object)
+
+		if location := finding.Location(); location != "" {
+			fmt.Fprintf(out, " (%s)", location)
+		}
+
+		fmt.Fprintln(out)
+	}
+
+	target := fmt.Sprintf("PostgreSQL %d", report.Postgres)
+	if report.TimescaleDB != "" {
+		target += " and TimescaleDB " + report.TimescaleDB
+	}
+
+	fmt.Fprintf(out, "\n%d uses of version-dependent features, %d not supported by %s\n",
+		len(report.Findings), len(report.Incompatible()), target)
+}
+
+// requiredVersion names the server version a finding needs.
+func requiredVersion(finding *compat.Finding) string {
+	if finding.MinTimescale != "" {
+		return "TimescaleDB " + finding.MinTimescale
+	}
+
+	return fmt.Sprintf("PostgreSQL %d", finding.MinPostgres)
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/compat"
+	"github.com/accented-ai/pgtofu/internal/diag"
+)
+
+const compatSchema = `
+CREATE TABLE docs (
+    id BIGINT PRIMARY KEY,
+    body TEXT COMPRESSION lz4
+) PARTITION BY HASH (id);
+CREATE UNIQUE INDEX docs_body_key ON docs (body) NULLS NOT DISTINCT;
+`
+
+func TestRunCheckCompatReportsIncompatibleFeatures(t *testing.T) {
+	t.Parallel()
+
+	path := writeCompareSchema(t, t.TempDir(), "schema.sql", compatSchema)
+
+	var out bytes.Buffer
+
+	err := runCheckCompat(context.Background(), &compatConfig{
+		desired:  path,
+		postgres: 14,
+		format:   compatFormatText,
+	}, nil, &out)
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Category != CategoryValidation {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	if len(cmdErr.Diagnostics) != 1 ||
+		cmdErr.Diagnostics[0].Code != diag.CodeIncompatibleFeature ||
+		cmdErr.Diagnostics[0].Location() != path+":6" {
+		t.Fatalf("unexpected diagnostics: %+v", cmdErr.Diagnostics)
+	}
+
+	report := out.String()
+
+	for _, want := range []string{
+		"PostgreSQL 10\n    declarative_partitioning: table public.docs (" + path + ":2)\n",
+		"PostgreSQL 14\n    column_compression: column public.docs.body (" + path + ":4)\n",
+		"PostgreSQL 15 (not supported by the target)\n" +
+			"  ✗ nulls_not_distinct: index public.docs_body_key (" + path + ":6)\n",
+		"5 uses of version-dependent features, 1 not supported by PostgreSQL 14\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestRunCheckCompatJSON(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	err := runCheckCompat(context.Background(), &compatConfig{
+		desired:     "-",
+		postgres:    16,
+		timescaleDB: "2.9",
+		format:      compatFormatJSON,
+	}, strings.NewReader(compatSchema), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report compat.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, out.String())
+	}
+
+	if report.Postgres != 16 || report.TimescaleDB != "2.9" || len(report.Findings) != 5 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if len(report.Incompatible()) != 0 {
+		t.Fatalf("expected no incompatible findings, got %+v", report.Incompatible())
+	}
+}
+
+func TestRunCheckCompatRejectsInvalidFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  compatConfig
+	}{
+		{name: "format", cfg: compatConfig{desired: "-", postgres: 13, format: "yaml"}},
+		{name: "postgres", cfg: compatConfig{desired: "-", format: compatFormatText}},
+		{
+			name: "timescaledb",
+			cfg: compatConfig{
+				desired: "-", postgres: 13, timescaleDB: "latest", format: compatFormatText,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := runCheckCompat(context.Background(), &tt.cfg, strings.NewReader(""), nil)
+
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) || cmdErr.Phase != phaseUsage {
+				t.Fatalf("expected a usage error, got %v", err)
+			}
+		})
+	}
+}
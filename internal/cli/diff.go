@@ -1,22 +1,48 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
 	"github.com/accented-ai/pgtofu/internal/util"
 )
 
+// Values of diff --format.
+const (
+	diffFormatText = "text"
+	diffFormatJSON = "json"
+)
+
 type diffConfig struct {
-	current string
-	desired string
+	current      string
+	currentDump  string
+	desired      string
+	ensureOnly   bool
+	recreate     bool
+	enforceStart bool
+	swapIndexes  bool
+	renames      bool
+	analyzeBody  bool
+	cacheDir     string
+	schemas      []string
+	typeEquivs   map[string]string
+	planSize     differ.PlanSizeLimits
+	toolVersion  string
+	format       string
+	// detailedExit exits with ExitChanges when there are changes. Without it
+	// the exit status only reports errors.
+	detailedExit bool
 }
 
-func newDiffCommand() *cobra.Command {
-	cfg := &diffConfig{}
+func newDiffCommand(ctx context.Context, info BuildInfo) *cobra.Command {
+	cfg := &diffConfig{toolVersion: formatToolVersion(info)}
 
 	cmd := &cobra.Command{
 		Use:   "diff",
@@ -24,66 +50,156 @@ func newDiffCommand() *cobra.Command {
 		Long: `Compare the current database schema (from extract) with the desired
 schema (SQL files) and display the differences.
 
+With --current-from-file, the current schema is read from the output of
+pg_dump --schema-only instead, for databases pgtofu cannot connect to.
+
 This command does not generate migration files. Use 'generate' for that.`,
 		Example: `  # Compare schemas
   pgtofu diff --current current-schema.json --desired ./schema
 
   # Compare with single file
-  pgtofu diff --current current-schema.json --desired schema.sql`,
+  pgtofu diff --current current-schema.json --desired schema.sql
+
+  # Compare a schema dump with the desired schema
+  pg_dump --schema-only mydb > dump.sql
+  pgtofu diff --current-from-file dump.sql --desired ./schema
+
+  # Compare with a desired schema rendered by another tool
+  render-schema | pgtofu diff --current current-schema.json --desired -
+
+  # Report the plan as JSON for CI, exiting 0 whether or not there are changes
+  pgtofu diff --current current-schema.json --desired ./schema --format json \
+    --detailed-exitcode=false`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDiff(cfg)
+			return commandFailure(phaseDiff,
+				runDiff(ctx, cfg, cmd.InOrStdin(), cmd.OutOrStdout()))
 		},
 	}
 
 	cmd.Flags().StringVar(&cfg.current, "current", "",
-		"Path to current schema JSON file (from extract)")
+		"Path to current schema JSON file (from extract), or - for stdin")
+	cmd.Flags().StringVar(&cfg.currentDump, "current-from-file", "",
+		"Path to a pg_dump --schema-only file of the current schema, or - for stdin")
 	cmd.Flags().StringVar(&cfg.desired, "desired", "",
-		"Path to desired schema SQL file or directory")
-
-	cmd.MarkFlagRequired("current") //nolint:errcheck
+		"Path to desired schema SQL file or directory, or - for stdin")
+	cmd.Flags().BoolVar(&cfg.ensureOnly, "if-not-exists-ensure-only", false,
+		"Only create IF NOT EXISTS tables and indexes; never modify or drop existing ones")
+	cmd.Flags().BoolVar(&cfg.recreate, "suggest-table-recreation", false,
+		"Suggest a manual recreation template instead of altering heavily rewritten tables")
+	cmd.Flags().BoolVar(&cfg.enforceStart, "enforce-sequence-start", false,
+		"Alter the START WITH of existing sequences that differ from the desired schema")
+	cmd.Flags().BoolVar(&cfg.swapIndexes, "swap-matview-indexes", false,
+		"Rebuild unique materialized view indexes under a new name before dropping the old one")
+	cmd.Flags().BoolVar(&cfg.renames, "detect-column-renames", false,
+		"Rename a dropped column to an added one that matches it exactly, instead of drop and add")
+	cmd.Flags().BoolVar(&cfg.analyzeBody, "analyze-function-bodies", false,
+		"Warn about dropped or retyped columns that function bodies appear to use (heuristic)")
+	cmd.Flags().StringVar(&cfg.cacheDir, "cache-dir", "",
+		"Directory to cache comparison results in, so unchanged objects are not compared again")
+	cmd.Flags().StringArrayVar(&cfg.schemas, "target-schema", nil,
+		"Only compare objects of this schema (can be specified multiple times)")
+	cmd.Flags().StringToStringVar(&cfg.typeEquivs, "type-equivalence", nil,
+		"Compare a column type as another, as alias=type (e.g. public.citext=citext)")
+	cmd.Flags().StringVar(&cfg.format, "format", diffFormatText,
+		"Format of the output (text or json)")
+	cmd.Flags().BoolVar(&cfg.detailedExit, "detailed-exitcode", true,
+		"Exit with status 2 when there are changes; with false, exit 0")
+	addPlanSizeFlags(cmd, &cfg.planSize)
+
+	cmd.MarkFlagsOneRequired("current", "current-from-file")
+	cmd.MarkFlagsMutuallyExclusive("current", "current-from-file")
 	cmd.MarkFlagRequired("desired") //nolint:errcheck
 
 	return cmd
 }
 
-func runDiff(cfg *diffConfig) error {
-	current, err := loadCurrentSchema(cfg.current)
+func runDiff(ctx context.Context, cfg *diffConfig, stdin io.Reader, out io.Writer) error {
+	if cfg.format != diffFormatText && cfg.format != diffFormatJSON {
+		return validationError(phaseUsage,
+			fmt.Errorf("invalid --format %q (use text or json)", cfg.format))
+	}
+
+	if err := checkStdinInputs(cfg.current, cfg.currentDump, cfg.desired); err != nil {
+		return err
+	}
+
+	current, err := loadCurrentInput(ctx, cfg.current, cfg.currentDump, stdin)
 	if err != nil {
 		return err
 	}
 
-	desired, err := loadDesiredSchema(cfg.desired)
+	desired, err := loadDesiredSchema(ctx, cfg.desired, stdin)
 	if err != nil {
 		return err
 	}
 
 	fmt.Fprintf(os.Stderr, "Comparing schemas...\n")
 
-	d := differ.New(differ.DefaultOptions())
+	diffOpts := differ.DefaultOptions()
+	diffOpts.IfNotExistsMeansEnsureOnly = cfg.ensureOnly
+	diffOpts.EnforceSequenceStart = cfg.enforceStart
+	diffOpts.SwapMaterializedViewIndexes = cfg.swapIndexes
+	diffOpts.DetectColumnRenames = cfg.renames
+	diffOpts.AnalyzeFunctionBodies = cfg.analyzeBody
+	diffOpts.PlanSize = planSizeLimits(cfg.planSize)
+	diffOpts.Cache = openComparisonCache(cfg.cacheDir, cfg.toolVersion)
+	diffOpts.TargetSchemas = cfg.schemas
+	diffOpts.TypeEquivalences = cfg.typeEquivs
+
+	if cfg.recreate {
+		diffOpts.TableRecreation = differ.DefaultTableRecreationThresholds()
+	}
 
-	result, err := d.Compare(current, desired)
+	d := differ.New(diffOpts)
+
+	result, err := d.CompareContext(ctx, current, desired)
 	if err != nil {
-		return util.WrapError("compare schemas", err)
+		return internalError(phaseDiff, util.WrapError("compare schemas", err))
 	}
 
-	fmt.Println(result.Summary())
+	saveComparisonCache(diffOpts.Cache, result)
 
-	if result.HasChanges() {
-		fmt.Println("\nDetailed Changes:")
+	if cfg.format == diffFormatJSON {
+		// SQL such as MAX(id) >= 1 and locations such as <stdin> are kept
+		// readable rather than escaped for HTML.
+		encoder := json.NewEncoder(out)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
 
-		for _, change := range result.Changes {
-			fmt.Printf(
-				"[%s] %s: %s\n",
-				change.Severity,
-				change.Type,
-				change.Description,
-			)
+		report := generator.NewPlanReport(result, generator.DefaultOptions())
+		if err := encoder.Encode(report); err != nil {
+			return internalError(phaseDiff, err)
 		}
+	} else {
+		writeDiffReport(out, result)
 	}
 
 	if result.HasBreakingChanges() {
 		fmt.Fprintf(os.Stderr, "\n⚠️  WARNING: Breaking changes detected!\n")
 	}
 
+	if result.HasChanges() && cfg.detailedExit {
+		return changesFound()
+	}
+
 	return nil
 }
+
+func writeDiffReport(out io.Writer, result *differ.DiffResult) {
+	fmt.Fprintln(out, result.Summary())
+	displayWarnings("Diff Warnings", result.Diagnostics)
+
+	if !result.HasChanges() {
+		return
+	}
+
+	fmt.Fprintln(out, "\nDetailed Changes:")
+
+	for _, change := range result.Changes {
+		fmt.Fprintf(out, "[%s] %s: %s\n", change.Severity, change.Type, change.Description)
+
+		if summary := differ.ViewDiffSummary(change); summary != "" {
+			fmt.Fprintf(out, "    %s\n", summary)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestDiffJSONReport(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{"current.json": `{"tables": []}`})
+	current := filepath.Join(dir, "current.json")
+
+	code, stdout, stderr := runCLIWithInput(t, `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"diff", "--current", current, "--desired", "-", "--format", "json")
+	if code != ExitChanges {
+		t.Fatalf("expected changes, got %d:\n%s", code, stderr)
+	}
+
+	var report generator.PlanReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, stdout)
+	}
+
+	if !report.HasChanges || report.Summary.TotalChanges != 1 ||
+		report.Summary.BySeverity[differ.SeveritySafe] != 1 || len(report.Changes) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	change := report.Changes[0]
+	if change.Type != differ.ChangeTypeAddTable || change.ObjectName != "public.users" ||
+		!strings.Contains(change.UpSQL, "CREATE TABLE public.users (") ||
+		!strings.Contains(change.DownSQL, "DROP TABLE IF EXISTS public.users") {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+
+	code, _, stderr = runCLIWithInput(t, `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"diff", "--current", current, "--desired", "-", "--format", "json",
+		"--detailed-exitcode=false")
+	if code != ExitOK {
+		t.Fatalf("expected exit status 0 without --detailed-exitcode, got %d:\n%s", code, stderr)
+	}
+}
+
+func TestDiffRejectsInvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{"current.json": `{"tables": []}`})
+
+	code, stderr := runCLI(t, "diff", "--current", filepath.Join(dir, "current.json"),
+		"--desired", filepath.Join(dir, "current.json"), "--format", "yaml")
+	if code != ExitValidationError || !strings.Contains(stderr, `invalid --format "yaml"`) {
+		t.Fatalf("expected a validation error, got %d:\n%s", code, stderr)
+	}
+}
+
+func TestDiffCurrentFromFile(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"dump.sql": `SET statement_timeout = 0;
+CREATE TABLE public.users (id bigint NOT NULL);
+ALTER TABLE public.users OWNER TO app;
+CREATE SEQUENCE public.users_id_seq;
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+GRANT SELECT ON TABLE public.users TO reader;
+`,
+		"current.json": `{"tables": []}`,
+	})
+	dump := filepath.Join(dir, "dump.sql")
+
+	code, stdout, stderr := runCLIWithInput(t, `CREATE TABLE users (id BIGSERIAL PRIMARY KEY);`,
+		"diff", "--current-from-file", dump, "--desired", "-")
+	if code != ExitOK || !strings.Contains(stdout, "No changes detected.") {
+		t.Fatalf("expected no changes, got %d:\n%s\n%s", code, stdout, stderr)
+	}
+
+	if strings.Contains(stderr, "Parser Warnings") {
+		t.Errorf("expected pg_dump statements to be ignored quietly, got:\n%s", stderr)
+	}
+
+	code, stderr = runCLI(t, "diff", "--current", filepath.Join(dir, "current.json"),
+		"--current-from-file", dump, "--desired", dump)
+	if code == ExitOK || !strings.Contains(stderr, "none of the others can be") {
+		t.Fatalf("expected --current and --current-from-file to be exclusive, got %d:\n%s",
+			code, stderr)
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+)
+
+// Exit statuses of the pgtofu binary. Scripts rely on them, so they do not
+// change between releases. compare --exit-code keeps the git diff statuses
+// instead.
+const (
+	// ExitOK is success with no changes to report.
+	ExitOK = 0
+	// ExitChanges is success where diff or compare found changes, or generate
+	// produced migrations.
+	ExitChanges = 2
+	// ExitParseError is an input schema or file that could not be parsed.
+	ExitParseError = 3
+	// ExitValidationError is an invalid flag, argument or environment value,
+	// or a check that refused to go ahead.
+	ExitValidationError = 4
+	// ExitInternalError is any other failure, such as an unreachable database
+	// or a file that could not be written.
+	ExitInternalError = 5
+)
+
+// ErrorCategory is the kind of a fatal error, which selects the exit status.
+type ErrorCategory string
+
+const (
+	CategoryParse      ErrorCategory = "parse"
+	CategoryValidation ErrorCategory = "validation"
+	CategoryInternal   ErrorCategory = "internal"
+)
+
+// ExitCode returns the exit status for an error of the category.
+func (c ErrorCategory) ExitCode() int {
+	switch c {
+	case CategoryParse:
+		return ExitParseError
+	case CategoryValidation:
+		return ExitValidationError
+	default:
+		return ExitInternalError
+	}
+}
+
+// Phases of a command that an error is reported in.
+const (
+	phaseUsage    = "usage"
+	phaseLoad     = "load"
+	phaseDiff     = "diff"
+	phaseGenerate = "generate"
+	phaseExtract  = "extract"
+	phaseWrite    = "write"
+	phaseCheck    = "check"
+	phaseApply    = "apply"
+)
+
+// Values of --error-format.
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+// CommandError is a fatal error of a command, with the category that selects
+// the exit status and the phase the command was in. Diagnostics holds the
+// parser errors or check violations behind Err, when there are any.
+type CommandError struct {
+	Category    ErrorCategory
+	Phase       string
+	Err         error
+	Diagnostics []diag.Warning
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+func parseError(phase string, err error, diagnostics ...diag.Warning) *CommandError {
+	return &CommandError{
+		Category:    CategoryParse,
+		Phase:       phase,
+		Err:         err,
+		Diagnostics: diagnostics,
+	}
+}
+
+func validationError(phase string, err error, diagnostics ...diag.Warning) *CommandError {
+	return &CommandError{
+		Category:    CategoryValidation,
+		Phase:       phase,
+		Err:         err,
+		Diagnostics: diagnostics,
+	}
+}
+
+func internalError(phase string, err error) *CommandError {
+	return &CommandError{Category: CategoryInternal, Phase: phase, Err: err}
+}
+
+// inputError categorizes a failure to load an input: a path that does not
+// exist is an invalid argument, any other file system error or a cancellation
+// is internal, and everything else is a parse error.
+func inputError(phase string, err error) *CommandError {
+	var pathErr *fs.PathError
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return validationError(phase, err)
+	case errors.As(err, &pathErr),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return internalError(phase, err)
+	default:
+		return parseError(phase, err)
+	}
+}
+
+// commandFailure returns err as a categorized error. Errors a command has not
+// categorized itself are internal failures of the given phase.
+func commandFailure(phase string, err error) error {
+	var (
+		cmdErr  *CommandError
+		exitErr *ExitError
+	)
+
+	if err == nil || errors.As(err, &cmdErr) || errors.As(err, &exitErr) {
+		return err
+	}
+
+	return internalError(phase, err)
+}
+
+// changesFound is the result of a command that succeeded and found changes.
+func changesFound() error {
+	return &ExitError{Code: ExitChanges}
+}
+
+// ExitCode returns the process exit status for an error returned by Execute.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Category.ExitCode()
+	}
+
+	return ExitInternalError
+}
+
+// errorReport is the JSON object --error-format=json prints for a fatal error.
+type errorReport struct {
+	Code        int            `json:"code"`
+	Category    ErrorCategory  `json:"category"`
+	Phase       string         `json:"phase"`
+	Message     string         `json:"message"`
+	Diagnostics []diag.Warning `json:"diagnostics,omitempty"`
+}
+
+// reportError writes a fatal error to w as "Error: message" or, in the JSON
+// format, as a single line holding an errorReport. An ExitError without an
+// error only sets the exit status and writes nothing.
+func reportError(w io.Writer, err error, format string) {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Err == nil {
+		return
+	}
+
+	if format != errorFormatJSON {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	report := errorReport{
+		Code:     ExitCode(err),
+		Category: CategoryInternal,
+		Phase:    phaseUsage,
+		Message:  err.Error(),
+	}
+
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		report.Category = cmdErr.Category
+		report.Phase = cmdErr.Phase
+		report.Diagnostics = cmdErr.Diagnostics
+	}
+
+	// Locations such as <stdin> are kept readable rather than escaped for HTML.
+	var data bytes.Buffer
+
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+
+	if marshalErr := encoder.Encode(report); marshalErr != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	fmt.Fprint(w, data.String())
+}
+
+// parseErrorDiagnostics converts parser errors into diagnostics.
+func parseErrorDiagnostics(errs []parser.ParseError) []diag.Warning {
+	diagnostics := make([]diag.Warning, 0, len(errs))
+
+	for _, err := range errs {
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:     diag.CodeParseError,
+			Severity: diag.SeverityError,
+			Message:  err.Message,
+			File:     err.File,
+			Line:     err.Line,
+		})
+	}
+
+	return diagnostics
+}
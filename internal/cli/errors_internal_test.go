@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCLI runs pgtofu with args and returns the exit status and what was
+// reported on stderr.
+func runCLI(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	code, _, stderr := runCLIWithInput(t, "", args...)
+
+	return code, stderr
+}
+
+// runCLIWithInput runs pgtofu with args and stdin, and returns the exit
+// status with what was written to stdout and reported on stderr.
+func runCLIWithInput(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	err := execute(context.Background(), BuildInfo{Version: "test"}, args,
+		strings.NewReader(stdin), &stdout, &stderr)
+
+	return ExitCode(err), stdout.String(), stderr.String()
+}
+
+func writeCLIFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeSinceFiles(t, dir, files)
+
+	return dir
+}
+
+func TestExitCodes(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"users.sql":    `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"orders.sql":   `CREATE TABLE orders (id BIGINT PRIMARY KEY);`,
+		"broken.sql":   "CREATE TABLE users (id BIGINT PRIMARY KEY);\nCREATE TABLE;\n",
+		"current.json": `{"tables": []}`,
+		"invalid.json": `{"tables": [`,
+		"blocker":      `a file where a directory is expected`,
+		"typo.sql": "CREATE TABLE metrics (ts TIMESTAMPTZ NOT NULL);\n" +
+			"SELECT create_hypertable('metrics', 'ts', " +
+			"chunk_time_interval => INTERVAL '1 weeek');\n",
+		"enum.sql": "CREATE TYPE status AS ENUM ('active', 'archived');\n" +
+			"CREATE TABLE users (id BIGINT, status status DEFAULT 'inactive');\n",
+		"trigger.sql": "CREATE TABLE users (id BIGINT, updated_at TIMESTAMPTZ);\n" +
+			"CREATE FUNCTION touch() RETURNS void AS $$ BEGIN END; $$ LANGUAGE plpgsql;\n" +
+			"CREATE TRIGGER users_touch AFTER UPDATE ON users " +
+			"FOR EACH ROW EXECUTE FUNCTION touch();\n",
+		"typed.sql": "CREATE TYPE event_type AS (id BIGINT);\n" +
+			"CREATE TABLE events OF event_type (id NOT NULL, note TEXT);\n",
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     int
+		reported bool
+	}{
+		{"no differences", []string{"compare", path("users.sql"), path("users.sql")}, ExitOK, false},
+		{"version", []string{"version"}, ExitOK, false},
+		{"differences", []string{"compare", path("users.sql"), path("orders.sql")}, ExitChanges, false},
+		{
+			"diff with changes",
+			[]string{"diff", "--current", path("current.json"), "--desired", path("users.sql")},
+			ExitChanges,
+			false,
+		},
+		{
+			"migrations generated",
+			[]string{
+				"generate", "--preview",
+				"--current", path("current.json"), "--desired", path("users.sql"),
+				"--output-dir", path("migrations"),
+			},
+			ExitChanges,
+			false,
+		},
+		{
+			"SQL parse error",
+			[]string{"compare", path("users.sql"), path("broken.sql")},
+			ExitParseError,
+			true,
+		},
+		{
+			"current schema parse error",
+			[]string{"diff", "--current", path("invalid.json"), "--desired", path("users.sql")},
+			ExitParseError,
+			true,
+		},
+		{"unknown flag", []string{"diff", "--no-such-flag"}, ExitValidationError, true},
+		{"missing required flag", []string{"diff", "--desired", dir}, ExitValidationError, true},
+		{"unknown command", []string{"migrate"}, ExitValidationError, true},
+		{
+			"missing input",
+			[]string{"compare", path("users.sql"), path("missing.sql")},
+			ExitValidationError,
+			true,
+		},
+		{"invalid error format", []string{"--error-format", "xml", "version"}, ExitValidationError, true},
+		{
+			"invalid cascade drop pattern",
+			[]string{
+				"generate", "--preview", "--cascade-drops", "app.[",
+				"--current", path("current.json"), "--desired", path("users.sql"),
+			},
+			ExitValidationError,
+			true,
+		},
+		{
+			"invalid interval",
+			[]string{"diff", "--current", path("current.json"), "--desired", path("typo.sql")},
+			ExitValidationError,
+			true,
+		},
+		{
+			"undeclared enum value",
+			[]string{"diff", "--current", path("current.json"), "--desired", path("enum.sql")},
+			ExitValidationError,
+			true,
+		},
+		{
+			"trigger function returning void",
+			[]string{"diff", "--current", path("current.json"), "--desired", path("trigger.sql")},
+			ExitValidationError,
+			true,
+		},
+		{
+			"local column of typed table",
+			[]string{"diff", "--current", path("current.json"), "--desired", path("typed.sql")},
+			ExitValidationError,
+			true,
+		},
+		{
+			"invalid modulus",
+			[]string{"partition", "generate", "--table", "t", "--modulus", "0"},
+			ExitValidationError,
+			true,
+		},
+		{
+			"output not writable",
+			[]string{
+				"partition", "generate", "--table", "t",
+				"--output", filepath.Join(path("blocker"), "partitions.sql"),
+			},
+			ExitInternalError,
+			true,
+		},
+		{
+			"compare --exit-code keeps git statuses",
+			[]string{"compare", "--exit-code", path("users.sql"), path("orders.sql")},
+			compareExitDifferent,
+			false,
+		},
+		{
+			"compare --exit-code error",
+			[]string{"compare", "--exit-code", path("users.sql"), path("broken.sql")},
+			compareExitError,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, stderr := runCLI(t, tt.args...)
+			if code != tt.want {
+				t.Fatalf("expected exit status %d, got %d; stderr:\n%s", tt.want, code, stderr)
+			}
+
+			if !tt.reported && stderr != "" {
+				t.Fatalf("expected nothing reported, got:\n%s", stderr)
+			}
+
+			if tt.reported && !strings.HasPrefix(stderr, "Error: ") {
+				t.Fatalf("expected a text error, got:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestErrorFormatJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"users.sql":  `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"broken.sql": "CREATE TABLE users (id BIGINT PRIMARY KEY);\nCREATE TABLE;\n",
+		"blocker":    `a file where a directory is expected`,
+	})
+	broken := filepath.Join(dir, "broken.sql")
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantCode     int
+		wantCategory ErrorCategory
+		wantPhase    string
+		wantMessage  string
+		diagnostics  int
+	}{
+		{
+			name:         "parse error",
+			args:         []string{"compare", filepath.Join(dir, "users.sql"), broken},
+			wantCode:     ExitParseError,
+			wantCategory: CategoryParse,
+			wantPhase:    phaseLoad,
+			wantMessage:  "encountered 1 parsing errors",
+			diagnostics:  1,
+		},
+		{
+			name:         "usage error",
+			args:         []string{"generate", "--desired", dir},
+			wantCode:     ExitValidationError,
+			wantCategory: CategoryValidation,
+			wantPhase:    phaseUsage,
+			wantMessage: "at least one of the flags in the group " +
+				"[current current-from-file since] is required",
+		},
+		{
+			name: "internal error",
+			args: []string{
+				"partition", "generate", "--table", "t",
+				"--output", filepath.Join(dir, "blocker", "partitions.sql"),
+			},
+			wantCode:     ExitInternalError,
+			wantCategory: CategoryInternal,
+			wantPhase:    phaseWrite,
+			wantMessage:  "create output directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, stderr := runCLI(t, append([]string{"--error-format=json"}, tt.args...)...)
+			if code != tt.wantCode {
+				t.Fatalf("expected exit status %d, got %d; stderr:\n%s", tt.wantCode, code, stderr)
+			}
+
+			if strings.Count(stderr, "\n") != 1 {
+				t.Fatalf("expected a single line, got:\n%s", stderr)
+			}
+
+			var report map[string]any
+			if err := json.Unmarshal([]byte(stderr), &report); err != nil {
+				t.Fatalf("expected a JSON object, got %v:\n%s", err, stderr)
+			}
+
+			if report["code"] != float64(tt.wantCode) ||
+				report["category"] != string(tt.wantCategory) ||
+				report["phase"] != tt.wantPhase {
+				t.Fatalf("unexpected report: %v", report)
+			}
+
+			if message, _ := report["message"].(string); !strings.Contains(message, tt.wantMessage) {
+				t.Fatalf("expected message containing %q, got %q", tt.wantMessage, message)
+			}
+
+			diagnostics, _ := report["diagnostics"].([]any)
+			if len(diagnostics) != tt.diagnostics {
+				t.Fatalf("expected %d diagnostics, got %v", tt.diagnostics, report["diagnostics"])
+			}
+
+			if tt.diagnostics == 0 {
+				return
+			}
+
+			first, _ := diagnostics[0].(map[string]any)
+			if first["code"] != "PARSE_ERROR" || first["severity"] != "error" ||
+				first["file"] != broken || first["line"] != float64(2) {
+				t.Fatalf("unexpected diagnostic: %v", first)
+			}
+		})
+	}
+}
+
+func TestErrorFormatJSONSilentOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "users.sql")
+	if err := os.WriteFile(file, []byte(`CREATE TABLE users (id BIGINT);`), 0o600); err != nil {
+		t.Fatalf("write users.sql: %v", err)
+	}
+
+	code, stderr := runCLI(t, "--error-format=json", "compare", file, file)
+	if code != ExitOK || stderr != "" {
+		t.Fatalf("expected a silent success, got %d:\n%s", code, stderr)
+	}
+}
@@ -23,6 +23,7 @@ type extractConfig struct {
 	output        string
 	excludeSchema []string
 	timeout       time.Duration
+	concurrency   int
 }
 
 func newExtractCommand(ctx context.Context) *cobra.Command {
@@ -45,7 +46,7 @@ used with the diff and generate commands.`,
   # Extract to stdout
   pgtofu extract --database-url "$DATABASE_URL" --output -`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runExtract(ctx, cfg)
+			return commandFailure(phaseExtract, runExtract(ctx, cfg))
 		},
 	}
 
@@ -59,6 +60,9 @@ used with the diff and generate commands.`,
 	cmd.Flags().DurationVar(&cfg.timeout, "timeout", defaultExtractTimeout,
 		"Maximum time allowed for database connection and schema extraction (for example 30s, 5m, 0 to disable)")
 
+	cmd.Flags().IntVar(&cfg.concurrency, "concurrency", extractor.DefaultConcurrency,
+		"Maximum number of catalog queries to run at once (1 runs them one after the other)")
+
 	cmd.MarkFlagRequired("database-url") //nolint:errcheck
 
 	return cmd
@@ -66,7 +70,11 @@ used with the diff and generate commands.`,
 
 func runExtract(ctx context.Context, cfg *extractConfig) error {
 	if cfg.timeout < 0 {
-		return errors.New("timeout must be non-negative")
+		return validationError(phaseUsage, errors.New("timeout must be non-negative"))
+	}
+
+	if cfg.concurrency < 1 {
+		return validationError(phaseUsage, errors.New("concurrency must be at least 1"))
 	}
 
 	if cfg.timeout > 0 {
@@ -92,6 +100,7 @@ func runExtract(ctx context.Context, cfg *extractConfig) error {
 
 	extractorOpts := extractor.Options{
 		ExcludeSchemas: cfg.excludeSchema,
+		Concurrency:    cfg.concurrency,
 	}
 
 	ext, err := extractor.New(ctx, pool, extractorOpts)
@@ -159,6 +168,7 @@ func printExtractionSummary(db any, elapsed time.Duration, hasTimescale bool) {
 		GetMaterializedViews() int
 		GetFunctions() int
 		GetTriggers() int
+		GetGrants() int
 		GetHypertables() int
 		GetContinuousAggregates() int
 	}
@@ -176,6 +186,7 @@ func printExtractionSummary(db any, elapsed time.Duration, hasTimescale bool) {
 		fmt.Fprintf(os.Stderr, "  Materialized Views:  %d\n", s.GetMaterializedViews())
 		fmt.Fprintf(os.Stderr, "  Functions:           %d\n", s.GetFunctions())
 		fmt.Fprintf(os.Stderr, "  Triggers:            %d\n", s.GetTriggers())
+		fmt.Fprintf(os.Stderr, "  Grants:              %d\n", s.GetGrants())
 
 		if hasTimescale {
 			fmt.Fprintf(os.Stderr, "  Hypertables:         %d\n", s.GetHypertables())
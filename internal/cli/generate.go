@@ -1,27 +1,64 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
 	"github.com/accented-ai/pgtofu/internal/util"
 )
 
 type generateConfig struct {
 	current      string
+	currentDump  string
+	since        string
 	desired      string
 	outputDir    string
 	preview      bool
 	startVersion int
+	safeUnique   bool
+	safeChecks   bool
+	concurrent   bool
+	detachOnly   bool
+	ensureOnly   bool
+	recreate     bool
+	enforceStart bool
+	swapIndexes  bool
+	renames      bool
+	analyzeBody  bool
+	cacheDir     string
+	schemas      []string
+	typeEquivs   map[string]string
+	planSize     differ.PlanSizeLimits
+	outputFormat string
+	omitTime     bool
+	savepoints   bool
+	stdout       bool
+	bySchema     bool
+	sharedDir    string
+	cascadeDrops []string
+	failOnLock   string
+	allowLocks   []string
+	toolVersion  string
 }
 
-func newGenerateCommand() *cobra.Command {
-	cfg := &generateConfig{}
+// sourceDateEpochEnv is the reproducible-builds convention for pinning build
+// timestamps; when set, it replaces the current time in migration headers.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+func newGenerateCommand(ctx context.Context, info BuildInfo) *cobra.Command {
+	cfg := &generateConfig{toolVersion: formatToolVersion(info)}
 
 	cmd := &cobra.Command{
 		Use:   "generate",
@@ -31,7 +68,23 @@ generate golang-migrate compatible migration files.
 
 Generated files follow the naming convention:
   {version}_{description}.up.sql
-  {version}_{description}.down.sql`,
+  {version}_{description}.down.sql
+
+With --output-format goose, each migration is a single
+{version}_{description}.sql file with goose Up and Down sections.
+
+With --since, no database is involved: the desired schema files as committed
+at the given git revision stand in for the current schema, so the migrations
+show what the schema changes since that revision imply. Combined with
+--preview, the generated files are printed to stdout.
+
+With --current-from-file, the current schema is read from the output of
+pg_dump --schema-only instead of an extracted JSON file.
+
+Either the current or the desired schema can be - to read it from stdin. With --stdout,
+nothing is written to the output directory: the content of every generated
+file is written to stdout instead, each file introduced by a
+"-- >>> file: <name>" line, and the summary goes to stderr.`,
 		Example: `  # Generate migrations
   pgtofu generate --current current-schema.json --desired ./schema
 
@@ -40,49 +93,142 @@ Generated files follow the naming convention:
 
   # Specify output directory and start version
   pgtofu generate --current current-schema.json --desired ./schema \
-    --output-dir ./migrations --start-version 10`,
+    --output-dir ./migrations --start-version 10
+
+  # Generate goose migrations
+  pgtofu generate --current current-schema.json --desired ./schema \
+    --output-format goose
+
+  # Generate migrations against a schema dump
+  pgtofu generate --current-from-file dump.sql --desired ./schema
+
+  # Show the migration the schema changes since main imply, without a database
+  pgtofu generate --since origin/main --desired ./schema --preview
+
+  # Pipe a rendered desired schema in and the migrations out
+  render-schema | pgtofu generate --current current-schema.json --desired - --stdout
+
+  # Reproducible headers for a "regenerate and assert no diff" check
+  SOURCE_DATE_EPOCH=1704067200 pgtofu generate --current current-schema.json \
+    --desired ./schema`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGenerate(cfg)
+			return commandFailure(phaseGenerate,
+				runGenerate(ctx, cfg, cmd.InOrStdin(), cmd.OutOrStdout()))
 		},
 	}
 
 	cmd.Flags().StringVar(&cfg.current, "current", "",
-		"Path to current schema JSON file (from extract)")
+		"Path to current schema JSON file (from extract), or - for stdin")
+	cmd.Flags().StringVar(&cfg.currentDump, "current-from-file", "",
+		"Path to a pg_dump --schema-only file of the current schema, or - for stdin")
+	cmd.Flags().StringVar(&cfg.since, "since", "",
+		"Git revision whose committed desired schema is used as the current schema")
 	cmd.Flags().StringVar(&cfg.desired, "desired", "",
-		"Path to desired schema SQL file or directory")
+		"Path to desired schema SQL file or directory, or - for stdin")
 	cmd.Flags().StringVar(&cfg.outputDir, "output-dir", "./migrations",
 		"Output directory for migration files")
 	cmd.Flags().BoolVar(&cfg.preview, "preview", false,
 		"Preview migrations without writing files")
 	cmd.Flags().IntVar(&cfg.startVersion, "start-version", 0,
 		"Starting version number (0 = auto-detect)")
+	cmd.Flags().BoolVar(&cfg.safeUnique, "safe-unique-constraints", false,
+		"Build new unique constraint indexes CONCURRENTLY before promoting them")
+	cmd.Flags().BoolVar(&cfg.safeChecks, "safe-constraints", false,
+		"Add CHECK, foreign key and NOT NULL constraints NOT VALID, then validate them separately")
+	cmd.Flags().BoolVar(&cfg.concurrent, "concurrent-indexes", false,
+		"Build and drop indexes of existing tables CONCURRENTLY, each in its own migration")
+	cmd.Flags().BoolVar(&cfg.detachOnly, "detach-only", false,
+		"Detach removed partitions instead of dropping them, keeping their rows")
+	cmd.Flags().StringVar(&cfg.outputFormat, "output-format",
+		string(generator.OutputFormatGolangMigrate),
+		"Migration tool to write files for (golang-migrate or goose)")
+	cmd.Flags().BoolVar(&cfg.ensureOnly, "if-not-exists-ensure-only", false,
+		"Only create IF NOT EXISTS tables and indexes; never modify or drop existing ones")
+	cmd.Flags().BoolVar(&cfg.recreate, "suggest-table-recreation", false,
+		"Suggest a manual recreation template instead of altering heavily rewritten tables")
+	cmd.Flags().BoolVar(&cfg.enforceStart, "enforce-sequence-start", false,
+		"Alter the START WITH of existing sequences that differ from the desired schema")
+	cmd.Flags().BoolVar(&cfg.swapIndexes, "swap-matview-indexes", false,
+		"Rebuild unique materialized view indexes under a new name before dropping the old one")
+	cmd.Flags().BoolVar(&cfg.renames, "detect-column-renames", false,
+		"Rename a dropped column to an added one that matches it exactly, instead of drop and add")
+	cmd.Flags().BoolVar(&cfg.analyzeBody, "analyze-function-bodies", false,
+		"Warn about dropped or retyped columns that function bodies appear to use (heuristic)")
+	cmd.Flags().StringVar(&cfg.cacheDir, "cache-dir", "",
+		"Directory to cache comparison results in, so unchanged objects are not compared again")
+	cmd.Flags().StringArrayVar(&cfg.schemas, "target-schema", nil,
+		"Only compare objects of this schema (can be specified multiple times)")
+	cmd.Flags().StringToStringVar(&cfg.typeEquivs, "type-equivalence", nil,
+		"Compare a column type as another, as alias=type (e.g. public.citext=citext)")
+	addPlanSizeFlags(cmd, &cfg.planSize)
+	cmd.Flags().BoolVar(&cfg.omitTime, "omit-timestamp", false,
+		"Leave the Generated timestamp out of migration headers")
+	cmd.Flags().BoolVar(&cfg.savepoints, "emit-savepoints", false,
+		"Wrap each statement of a transactional migration in a numbered savepoint")
+	cmd.Flags().BoolVar(&cfg.stdout, "stdout", false,
+		"Write the generated files to stdout instead of the output directory")
+	cmd.Flags().BoolVar(&cfg.bySchema, "partition-by-schema", false,
+		"Write each schema's migrations to its own subdirectory with its own versions")
+	cmd.Flags().StringVar(&cfg.sharedDir, "shared-subdir", generator.DefaultSharedSubdirectory,
+		"Subdirectory for schema, extension and cross-schema migrations with --partition-by-schema")
+	cmd.Flags().StringSliceVar(&cfg.cascadeDrops, "cascade-drops", nil,
+		"Object kinds or qualified name patterns to drop with CASCADE (e.g. view,reporting.*)")
+	cmd.Flags().StringVar(&cfg.failOnLock, "fail-on-lock", "",
+		"Fail when an up statement takes this lock level or stronger (e.g. access-exclusive)")
+	cmd.Flags().StringSliceVar(&cfg.allowLocks, "allow-lock", nil,
+		"Qualified name patterns of objects --fail-on-lock allows locking (e.g. staging.*)")
 
-	cmd.MarkFlagRequired("current") //nolint:errcheck
+	cmd.MarkFlagsOneRequired("current", "current-from-file", "since")
+	cmd.MarkFlagsMutuallyExclusive("current", "current-from-file", "since")
 	cmd.MarkFlagRequired("desired") //nolint:errcheck
 
 	return cmd
 }
 
-func runGenerate(cfg *generateConfig) error {
-	current, err := loadCurrentSchema(cfg.current)
-	if err != nil {
+func runGenerate(ctx context.Context, cfg *generateConfig, stdin io.Reader, out io.Writer) error {
+	if err := checkGenerateInputs(cfg); err != nil {
 		return err
 	}
 
-	desired, err := loadDesiredSchema(cfg.desired)
+	current, err := loadGenerateCurrent(ctx, cfg, stdin)
+	if err != nil {
+		return commandFailure(phaseLoad, err)
+	}
+
+	desired, err := loadDesiredSchema(ctx, cfg.desired, stdin)
 	if err != nil {
 		return err
 	}
 
 	fmt.Fprintf(os.Stderr, "Comparing schemas...\n")
 
-	d := differ.New(differ.DefaultOptions())
+	diffOpts := differ.DefaultOptions()
+	diffOpts.IfNotExistsMeansEnsureOnly = cfg.ensureOnly
+	diffOpts.EnforceSequenceStart = cfg.enforceStart
+	diffOpts.SwapMaterializedViewIndexes = cfg.swapIndexes
+	diffOpts.DetectColumnRenames = cfg.renames
+	diffOpts.AnalyzeFunctionBodies = cfg.analyzeBody
+	diffOpts.PlanSize = planSizeLimits(cfg.planSize)
+	diffOpts.Cache = openComparisonCache(cfg.cacheDir, cfg.toolVersion)
+	diffOpts.TargetSchemas = cfg.schemas
+	diffOpts.TypeEquivalences = cfg.typeEquivs
 
-	diffResult, err := d.Compare(current, desired)
+	if cfg.recreate {
+		diffOpts.TableRecreation = differ.DefaultTableRecreationThresholds()
+	}
+
+	d := differ.New(diffOpts)
+
+	diffResult, err := d.CompareContext(ctx, current, desired)
 	if err != nil {
-		return util.WrapError("compare schemas", err)
+		return internalError(phaseDiff, util.WrapError("compare schemas", err))
 	}
 
+	saveComparisonCache(diffOpts.Cache, diffResult)
+
+	displayWarnings("Diff Warnings", diffResult.Diagnostics)
+	displayDiffNotes(diffResult)
+
 	if !diffResult.HasChanges() {
 		fmt.Fprintf(os.Stderr, "\nNo changes detected. No migrations generated.\n")
 		return nil
@@ -92,32 +238,193 @@ func runGenerate(cfg *generateConfig) error {
 
 	opts := generator.DefaultOptions()
 	opts.OutputDir = cfg.outputDir
-	opts.PreviewMode = cfg.preview
+	opts.PreviewMode = cfg.preview || cfg.stdout
+	opts.SafeUniqueConstraints = cfg.safeUnique
+	opts.SafeConstraintMode = cfg.safeChecks
+	opts.ConcurrentIndexes = cfg.concurrent
+	opts.DetachPartitions = cfg.detachOnly
+	opts.OutputFormat = generator.OutputFormat(cfg.outputFormat)
+	opts.OmitTimestamp = cfg.omitTime
+	opts.ToolVersion = cfg.toolVersion
+	opts.EmitSavepoints = cfg.savepoints
+	opts.PartitionOutputBySchema = cfg.bySchema
+	opts.SharedSubdirectory = cfg.sharedDir
+	opts.CascadeDrops = cfg.cascadeDrops
+	opts.AllowLocks = cfg.allowLocks
+
+	if cfg.failOnLock != "" {
+		level, err := generator.ParseLockLevel(cfg.failOnLock)
+		if err != nil {
+			return validationError(phaseUsage, util.WrapError("--fail-on-lock", err))
+		}
+
+		opts.FailOnLock = level
+	}
+
+	if cfg.since != "" {
+		opts.Comparison = sinceComparison(cfg.since, cfg.desired)
+		fmt.Fprintf(os.Stderr, "Compared: %s\n", opts.Comparison)
+	}
+
+	now, err := sourceDateEpoch(os.Getenv(sourceDateEpochEnv))
+	if err != nil {
+		return validationError(phaseUsage, err)
+	}
 
+	opts.Now = now
+
+	// Without an output directory to continue, --stdout numbers from the
+	// start version. Per-schema subdirectories are continued by the
+	// generator itself.
 	if cfg.startVersion > 0 {
 		opts.StartVersion = cfg.startVersion
-	} else {
+	} else if !cfg.stdout && !cfg.bySchema {
 		gen := generator.New(opts)
 		if nextVersion, err := gen.GetNextMigrationVersion(); err == nil {
 			opts.StartVersion = nextVersion
 		}
 	}
 
+	if err := opts.Validate(); err != nil {
+		return validationError(phaseUsage, err)
+	}
+
 	gen := generator.New(opts)
 
 	fmt.Fprintf(os.Stderr, "Generating migrations...\n")
 
-	genResult, err := gen.Generate(diffResult)
+	genResult, err := gen.GenerateContext(ctx, diffResult)
+
+	var dependentsErr *generator.DropDependentsError
+	if errors.As(err, &dependentsErr) {
+		return validationError(phaseGenerate, err, dependentsErr.Diagnostics()...)
+	}
+
+	var lockErr *generator.LockError
+	if errors.As(err, &lockErr) {
+		return validationError(phaseGenerate, err, lockErr.Diagnostics()...)
+	}
+
 	if err != nil {
-		return util.WrapError("generate migrations", err)
+		return internalError(phaseGenerate, util.WrapError("generate migrations", err))
+	}
+
+	if cfg.stdout {
+		fmt.Fprintln(os.Stderr, genResult.Summary())
+
+		if err := writeMigrationStream(out, genResult); err != nil {
+			return internalError(phaseWrite, util.WrapError("write migrations to stdout", err))
+		}
+
+		return changesFound()
 	}
 
 	fmt.Println(genResult.Summary())
 
+	if cfg.preview && cfg.since != "" {
+		printMigrationContents(genResult)
+	}
+
 	if !cfg.preview {
 		absPath, _ := filepath.Abs(cfg.outputDir)
 		fmt.Fprintf(os.Stderr, "\nMigrations written to: %s\n", absPath)
 	}
 
+	return changesFound()
+}
+
+// checkGenerateInputs rejects inputs that cannot be combined: both schemas
+// read from stdin, or --since with a desired schema that has no git history.
+func checkGenerateInputs(cfg *generateConfig) error {
+	if cfg.since != "" && cfg.desired == stdinPath {
+		return validationError(phaseUsage,
+			errors.New("--since needs --desired to be a path, not stdin"))
+	}
+
+	return checkStdinInputs(cfg.current, cfg.currentDump, cfg.desired)
+}
+
+// loadGenerateCurrent loads the current schema from the extracted JSON file or
+// the pg_dump file, or from the desired schema files as committed at the
+// --since revision.
+func loadGenerateCurrent(
+	ctx context.Context,
+	cfg *generateConfig,
+	stdin io.Reader,
+) (*schema.Database, error) {
+	if cfg.since != "" {
+		return loadSchemaAtRef(ctx, cfg.since, cfg.desired)
+	}
+
+	return loadCurrentInput(ctx, cfg.current, cfg.currentDump, stdin)
+}
+
+// migrationStreamDelimiter introduces each file generate --stdout writes.
+const migrationStreamDelimiter = "-- >>> file: "
+
+// writeMigrationStream writes every generated file to w, each introduced by
+// a delimiter line naming it, so a pipeline can split the stream back into
+// files.
+func writeMigrationStream(w io.Writer, result *generator.GenerateResult) error {
+	for _, migration := range result.Migrations {
+		for _, file := range []*generator.MigrationFile{migration.UpFile, migration.DownFile} {
+			if file == nil {
+				continue
+			}
+
+			content := file.Content
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+
+			if _, err := fmt.Fprintf(w, "%s%s\n%s", migrationStreamDelimiter,
+				migration.Path(file), content); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
+
+// printMigrationContents writes every generated file to stdout, so a preview
+// of a --since comparison can be posted for review as is.
+func printMigrationContents(result *generator.GenerateResult) {
+	for _, migration := range result.Migrations {
+		for _, file := range []*generator.MigrationFile{migration.UpFile, migration.DownFile} {
+			if file != nil {
+				fmt.Printf("\n==> %s <==\n%s", migration.Path(file), file.Content)
+			}
+		}
+	}
+}
+
+// sourceDateEpoch parses a SOURCE_DATE_EPOCH value into a fixed clock. An
+// empty value returns nil so the generator uses the current time.
+func sourceDateEpoch(value string) (func() time.Time, error) {
+	if value == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return nil, fmt.Errorf(
+			"invalid %s %q: must be a non-negative number of seconds since the Unix epoch",
+			sourceDateEpochEnv, value,
+		)
+	}
+
+	timestamp := time.Unix(seconds, 0).UTC()
+
+	return func() time.Time { return timestamp }, nil
+}
+
+// formatToolVersion renders the build version for migration headers,
+// leaving out a commit the build did not record.
+func formatToolVersion(info BuildInfo) string {
+	if info.Commit == "" || info.Commit == "unknown" {
+		return info.Version
+	}
+
+	return fmt.Sprintf("%s (%s)", info.Version, info.Commit)
+}
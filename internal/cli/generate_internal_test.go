@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceDateEpoch(t *testing.T) {
+	t.Parallel()
+
+	now, err := sourceDateEpoch("")
+	if err != nil || now != nil {
+		t.Fatalf("expected no clock for an unset value, got %v", err)
+	}
+
+	now, err = sourceDateEpoch("1704067200")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if got := now(); !got.Equal(want) || got.Location() != time.UTC {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	for _, value := range []string{"yesterday", "-1", "1.5"} {
+		if _, err := sourceDateEpoch(value); err == nil ||
+			!strings.Contains(err.Error(), sourceDateEpochEnv) {
+			t.Fatalf("expected %s error for %q, got %v", sourceDateEpochEnv, value, err)
+		}
+	}
+}
+
+func TestFormatToolVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]BuildInfo{
+		"v1.4.0 (3f2a9c1)": {Version: "v1.4.0", Commit: "3f2a9c1"},
+		"dev":              {Version: "dev", Commit: "unknown"},
+		"v1.4.0":           {Version: "v1.4.0"},
+	}
+
+	for want, info := range tests {
+		if got := formatToolVersion(info); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestGenerateStdout(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"current.json":                      `{"tables": []}`,
+		"migrations/000007_existing.up.sql": `SELECT 1;`,
+	})
+	outputDir := filepath.Join(dir, "migrations")
+
+	code, stdout, stderr := runCLIWithInput(t,
+		`CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"generate", "--current", filepath.Join(dir, "current.json"), "--desired", "-",
+		"--output-dir", outputDir, "--stdout", "--omit-timestamp")
+	if code != ExitChanges {
+		t.Fatalf("expected changes, got %d:\n%s", code, stderr)
+	}
+
+	up := strings.Index(stdout, "-- >>> file: 000001_")
+	down := strings.Index(stdout, ".down.sql\n")
+
+	if up != 0 || down < up || !strings.Contains(stdout[:down], ".up.sql\n") {
+		t.Fatalf("expected up then down files numbered from 000001, got:\n%s", stdout)
+	}
+
+	if !strings.Contains(stdout, "CREATE TABLE public.users (") ||
+		!strings.Contains(stdout, "DROP TABLE IF EXISTS public.users") {
+		t.Fatalf("expected the migration content, got:\n%s", stdout)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected nothing written to %s, got %v (%v)", outputDir, entries, err)
+	}
+}
+
+func TestGenerateDropsEverythingForEmptyDesiredSchema(t *testing.T) {
+	t.Parallel()
+
+	column := `{"name": "id", "data_type": "bigint", "position": 1, "is_nullable": false}`
+	dir := writeCLIFiles(t, map[string]string{
+		"current.json": `{
+			"schemas": [{"name": "public"}, {"name": "app"}],
+			"tables": [
+				{"schema": "public", "name": "orders", "columns": [` + column + `]},
+				{"schema": "app", "name": "users", "columns": [` + column + `]}
+			]
+		}`,
+		"schema/retired.sql": "/* Everything was retired.\n   See the changelog. */\n-- gone\n",
+	})
+	outputDir := filepath.Join(dir, "migrations")
+
+	code, stderr := runCLI(t, "generate", "--current", filepath.Join(dir, "current.json"),
+		"--desired", filepath.Join(dir, "schema"), "--output-dir", outputDir, "--omit-timestamp")
+	if code != ExitChanges {
+		t.Fatalf("expected changes, got %d:\n%s", code, stderr)
+	}
+
+	if strings.Contains(stderr, "SKIPPED_STATEMENT") {
+		t.Fatalf("expected the comment-only file to parse cleanly, got:\n%s", stderr)
+	}
+
+	ups, err := filepath.Glob(filepath.Join(outputDir, "*.up.sql"))
+	if err != nil || len(ups) == 0 {
+		t.Fatalf("expected up migrations in %s, got %v (%v)", outputDir, ups, err)
+	}
+
+	var all strings.Builder
+
+	for _, path := range ups {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(content), "DROP ") {
+			t.Fatalf("expected %s to drop something, got:\n%s", path, content)
+		}
+
+		all.Write(content)
+	}
+
+	for _, want := range []string{
+		"DROP TABLE IF EXISTS public.orders", "DROP TABLE IF EXISTS app.users", "DROP SCHEMA",
+	} {
+		if !strings.Contains(all.String(), want) {
+			t.Fatalf("expected %q in the migrations, got:\n%s", want, all.String())
+		}
+	}
+
+	if regexp.MustCompile(`DROP SCHEMA (IF EXISTS )?public\b`).MatchString(all.String()) {
+		t.Fatalf("expected the public schema to be kept, got:\n%s", all.String())
+	}
+}
+
+func TestGenerateFailOnLock(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"current.json": `{"tables": [{"schema": "public", "name": "orders", "columns": [
+			{"name": "id", "data_type": "bigint", "position": 1, "is_nullable": false}
+		]}]}`,
+		"orders.sql": `CREATE TABLE orders (id BIGINT NOT NULL, note TEXT);`,
+	})
+	outputDir := filepath.Join(dir, "migrations")
+	args := []string{
+		"generate", "--current", filepath.Join(dir, "current.json"),
+		"--desired", filepath.Join(dir, "orders.sql"), "--output-dir", outputDir,
+	}
+
+	code, stderr := runCLI(t, append(args, "--fail-on-lock", "access-exclusive")...)
+	if code != ExitValidationError ||
+		!strings.Contains(stderr, "ACCESS EXCLUSIVE on public.orders") {
+		t.Fatalf("expected the lock to be rejected, got %d:\n%s", code, stderr)
+	}
+
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Fatalf("expected nothing written to %s, got %v", outputDir, entries)
+	}
+
+	code, stderr = runCLI(t,
+		append(args, "--fail-on-lock", "access-exclusive", "--allow-lock", "public.*")...)
+	if code != ExitChanges {
+		t.Fatalf("expected the allowlisted lock to pass, got %d:\n%s", code, stderr)
+	}
+
+	code, stderr = runCLI(t, append(args, "--fail-on-lock", "table")...)
+	if code != ExitValidationError || !strings.Contains(stderr, `unknown lock level "table"`) {
+		t.Fatalf("expected an unknown lock level error, got %d:\n%s", code, stderr)
+	}
+}
@@ -1,57 +1,265 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/parser"
 	"github.com/accented-ai/pgtofu/internal/schema"
 	"github.com/accented-ai/pgtofu/internal/util"
 )
 
-func loadCurrentSchema(path string) (*schema.Database, error) {
-	fmt.Fprintf(os.Stderr, "Loading current schema from: %s\n", path)
+// stdinPath is the input path that stands for standard input.
+const stdinPath = "-"
+
+// stdinName names standard input in progress messages and in the locations
+// of parser errors and warnings.
+const stdinName = "<stdin>"
+
+// checkStdinInputs rejects reading more than one input from standard input,
+// which can only be read once.
+func checkStdinInputs(paths ...string) error {
+	readers := 0
+
+	for _, path := range paths {
+		if path == stdinPath {
+			readers++
+		}
+	}
+
+	if readers > 1 {
+		return validationError(phaseUsage,
+			errors.New("only one input can be read from stdin (-)"))
+	}
+
+	return nil
+}
+
+// displayPath is the path as shown in progress messages.
+func displayPath(path string) string {
+	if path == stdinPath {
+		return stdinName
+	}
+
+	return path
+}
+
+// loadCurrentSchema reads the current schema JSON from path, or from stdin
+// when path is "-".
+func loadCurrentSchema(path string, stdin io.Reader) (*schema.Database, error) {
+	fmt.Fprintf(os.Stderr, "Loading current schema from: %s\n", displayPath(path))
+
+	var (
+		data []byte
+		err  error
+	)
+
+	if path == stdinPath {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
 
-	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, util.WrapError("read current schema", err)
+		return nil, inputError(phaseLoad, util.WrapError("read current schema", err))
 	}
 
 	var db schema.Database
 	if err := json.Unmarshal(data, &db); err != nil {
-		return nil, util.WrapError("parse current schema", err)
+		return nil, parseError(phaseLoad, util.WrapError("parse current schema", err))
 	}
 
 	return &db, nil
 }
 
-func loadDesiredSchema(path string) (*schema.Database, error) {
-	fmt.Fprintf(os.Stderr, "Loading desired schema from: %s\n", path)
+// loadCurrentInput loads the current schema from the extracted JSON file at
+// path, or from the pg_dump --schema-only file at dumpPath when it is set.
+func loadCurrentInput(
+	ctx context.Context,
+	path, dumpPath string,
+	stdin io.Reader,
+) (*schema.Database, error) {
+	if dumpPath == "" {
+		return loadCurrentSchema(path, stdin)
+	}
 
-	info, err := os.Stat(path)
+	fmt.Fprintf(os.Stderr, "Loading current schema from pg_dump file: %s\n",
+		displayPath(dumpPath))
+
+	return parseSQLSchema(ctx, "current", dumpPath, stdin, parser.WithPgDump())
+}
+
+func loadDesiredSchema(
+	ctx context.Context,
+	path string,
+	stdin io.Reader,
+) (*schema.Database, error) {
+	db, err := loadSQLSchema(ctx, "desired", path, stdin)
 	if err != nil {
-		return nil, util.WrapError("stat path", err)
+		return nil, err
+	}
+
+	if err := checkIntervals(db); err != nil {
+		return nil, err
+	}
+
+	if err := checkEnumLiterals(db); err != nil {
+		return nil, err
+	}
+
+	if err := checkTriggerFunctions(db); err != nil {
+		return nil, err
+	}
+
+	if err := checkTypedTables(db); err != nil {
+		return nil, err
 	}
 
-	p := parser.New()
+	return db, nil
+}
+
+// checkIntervals rejects a desired schema with TimescaleDB interval settings
+// that would only fail once the migration runs.
+func checkIntervals(db *schema.Database) error {
+	invalid := schema.ValidateIntervals(db)
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	message := util.Plural(len(invalid), "invalid interval setting", "invalid interval settings") +
+		" in the desired schema:"
+	diagnostics := make([]diag.Warning, 0, len(invalid))
+
+	for _, err := range invalid {
+		message += "\n  " + err.Error()
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:       diag.CodeInvalidInterval,
+			Severity:   diag.SeverityError,
+			Message:    err.Error(),
+			ObjectName: err.Object,
+		})
+	}
+
+	return validationError(phaseLoad, errors.New(message), diagnostics...)
+}
+
+// checkEnumLiterals rejects a desired schema with defaults and CHECK
+// constraints that use values their enum types do not declare, which would
+// only fail once the migration runs.
+func checkEnumLiterals(db *schema.Database) error {
+	invalid := schema.ValidateEnumLiterals(db)
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	message := util.Plural(len(invalid), "undeclared enum value", "undeclared enum values") +
+		" in the desired schema:"
+	diagnostics := make([]diag.Warning, 0, len(invalid))
+
+	for _, err := range invalid {
+		message += "\n  " + err.Error()
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:       diag.CodeInvalidEnumLiteral,
+			Severity:   diag.SeverityError,
+			Message:    err.Error(),
+			ObjectName: err.Object,
+		})
+	}
+
+	return validationError(phaseLoad, errors.New(message), diagnostics...)
+}
+
+// checkTriggerFunctions rejects a desired schema with triggers that execute
+// functions not returning trigger, which CREATE TRIGGER only refuses once the
+// migration runs.
+func checkTriggerFunctions(db *schema.Database) error {
+	invalid := schema.ValidateTriggerFunctions(db)
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	message := util.Plural(len(invalid), "trigger", "triggers") +
+		" in the desired schema executing non-trigger functions:"
+	diagnostics := make([]diag.Warning, 0, len(invalid))
+
+	for _, err := range invalid {
+		message += "\n  " + err.Error()
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:       diag.CodeInvalidTriggerFunction,
+			Severity:   diag.SeverityError,
+			Message:    err.Error(),
+			ObjectName: err.Object,
+		})
+	}
+
+	return validationError(phaseLoad, errors.New(message), diagnostics...)
+}
+
+// checkTypedTables rejects a desired schema with typed tables that declare
+// columns of their own, which PostgreSQL only refuses once the migration runs.
+func checkTypedTables(db *schema.Database) error {
+	invalid := schema.ValidateTypedTables(db)
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	message := util.Plural(len(invalid), "column", "columns") +
+		" of typed tables in the desired schema not among the attributes of their types:"
+	diagnostics := make([]diag.Warning, 0, len(invalid))
+
+	for _, err := range invalid {
+		message += "\n  " + err.Error()
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:       diag.CodeInvalidTypedTableColumn,
+			Severity:   diag.SeverityError,
+			Message:    err.Error(),
+			ObjectName: err.Object,
+		})
+	}
+
+	return validationError(phaseLoad, errors.New(message), diagnostics...)
+}
+
+// loadSQLSchema parses a SQL file, every .sql file below a directory, or the
+// SQL read from stdin when path is "-", into a database named after the role
+// the schema plays.
+func loadSQLSchema(
+	ctx context.Context,
+	name, path string,
+	stdin io.Reader,
+) (*schema.Database, error) {
+	fmt.Fprintf(os.Stderr, "Loading %s schema from: %s\n", name, displayPath(path))
+
+	return parseSQLSchema(ctx, name, path, stdin)
+}
+
+func parseSQLSchema(
+	ctx context.Context,
+	name, path string,
+	stdin io.Reader,
+	opts ...parser.Option,
+) (*schema.Database, error) {
+	p := parser.New(append(
+		[]parser.Option{parser.WithTableConflictDescriber(describeTableConflict)}, opts...)...)
 	db := &schema.Database{
 		Version:      schema.SchemaVersion,
-		DatabaseName: "desired",
+		DatabaseName: name,
 		Tables:       []schema.Table{},
 	}
 
-	if info.IsDir() {
-		if err := parseDirectory(p, path, db); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := p.ParseFile(path, db); err != nil {
-			return nil, util.WrapError("parse file", err)
-		}
+	if err := parseSQLInput(ctx, p, path, stdin, db); err != nil {
+		return nil, err
 	}
 
 	db.Sort()
@@ -60,12 +268,116 @@ func loadDesiredSchema(path string) (*schema.Database, error) {
 		return nil, err
 	}
 
-	displayParserWarnings(p)
+	displayWarnings("Parser Warnings", p.GetWarnings())
 
 	return db, nil
 }
 
-func parseDirectory(p *parser.Parser, path string, db *schema.Database) error {
+func parseSQLInput(
+	ctx context.Context,
+	p *parser.Parser,
+	path string,
+	stdin io.Reader,
+	db *schema.Database,
+) error {
+	if path == stdinPath {
+		if err := p.ParseReaderContext(ctx, stdinName, stdin, db); err != nil {
+			return inputError(phaseLoad, util.WrapError("parse "+stdinName, err))
+		}
+
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return inputError(phaseLoad, util.WrapError("stat path", err))
+	}
+
+	if info.IsDir() {
+		if err := parseDirectory(ctx, p, path, db); err != nil {
+			return inputError(phaseLoad, err)
+		}
+
+		return nil
+	}
+
+	if err := p.ParseFileContext(ctx, path, db); err != nil {
+		return inputError(phaseLoad, util.WrapError("parse file", err))
+	}
+
+	return nil
+}
+
+// describeTableConflict reuses the differ's table comparison to explain how a
+// second CREATE TABLE for the same table differs from the first. Each line
+// is worded from the type and object of a change: its description speaks of
+// a database being migrated, not of two files.
+func describeTableConflict(
+	first, second *schema.Table,
+	firstAt, secondAt schema.SourceLocation,
+) []string {
+	changes := differ.NewTableComparator(differ.DefaultOptions()).CompareTables(first, second)
+
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		object := conflictObject(change)
+
+		switch changeType := string(change.Type); {
+		case strings.HasPrefix(changeType, "ADD_"):
+			lines = append(lines, fmt.Sprintf("%s is only defined in %s", object, secondAt))
+		case strings.HasPrefix(changeType, "DROP_"):
+			lines = append(lines, fmt.Sprintf("%s is only defined in %s", object, firstAt))
+		default:
+			lines = append(lines, fmt.Sprintf("%s is defined in %s and again in %s with a different %s",
+				object, firstAt, secondAt, conflictAspect(change)))
+		}
+	}
+
+	return lines
+}
+
+// conflictObject names the column, constraint or index of the table that
+// change is about, or the table itself.
+func conflictObject(change differ.Change) string {
+	for _, key := range []string{"column", "constraint", "index", "desired", "current"} {
+		switch object := change.Details[key].(type) {
+		case *schema.Column:
+			return "column " + object.Name
+		case *schema.Constraint:
+			return "constraint " + object.Name
+		case *schema.Index:
+			return "index " + object.Name
+		}
+	}
+
+	if name, ok := change.Details["column_name"].(string); ok {
+		return "column " + name
+	}
+
+	return change.ObjectType + " " + change.ObjectName
+}
+
+// conflictAspect names what differs for a MODIFY_ change, such as "type"
+// for MODIFY_COLUMN_TYPE, or "definition" when the type names nothing more
+// than the object.
+func conflictAspect(change differ.Change) string {
+	aspect := strings.TrimPrefix(string(change.Type), "MODIFY_")
+	aspect = strings.TrimPrefix(aspect, strings.ToUpper(change.ObjectType))
+	aspect = strings.TrimPrefix(aspect, "_")
+
+	if aspect == "" {
+		return "definition"
+	}
+
+	return strings.ToLower(strings.ReplaceAll(aspect, "_", " "))
+}
+
+func parseDirectory(
+	ctx context.Context,
+	p *parser.Parser,
+	path string,
+	db *schema.Database,
+) error {
 	err := filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
 		if err != nil {
 			return util.WrapError("walking directory", err)
@@ -79,7 +391,7 @@ func parseDirectory(p *parser.Parser, path string, db *schema.Database) error {
 			return nil
 		}
 
-		if err := p.ParseFileWithoutProcessingDeferred(filePath, db); err != nil {
+		if err := p.ParseFileWithoutProcessingDeferredContext(ctx, filePath, db); err != nil {
 			return util.WrapError("parse file "+filePath, err)
 		}
 
@@ -162,30 +474,124 @@ func validatePartitions(db *schema.Database) { //nolint:gocognit
 }
 
 func checkParserErrors(p *parser.Parser) error {
-	errors := p.GetErrors()
-	if len(errors) == 0 {
+	parseErrors := p.GetErrors()
+	if len(parseErrors) == 0 {
 		return nil
 	}
 
 	fmt.Fprintf(os.Stderr, "\n⚠️  Parser Errors:\n")
 
-	for _, err := range errors {
+	for _, err := range parseErrors {
 		fmt.Fprintf(os.Stderr, "  - %s\n", err.Error())
 	}
 
-	return fmt.Errorf("encountered %d parsing errors", len(errors))
+	return parseError(
+		phaseLoad,
+		fmt.Errorf("encountered %d parsing errors", len(parseErrors)),
+		parseErrorDiagnostics(parseErrors)...,
+	)
 }
 
-func displayParserWarnings(p *parser.Parser) {
-	warnings := p.GetWarnings()
+// openComparisonCache opens the --cache-dir cache, or returns nil without one.
+func openComparisonCache(dir, version string) *differ.ComparisonCache {
+	if dir == "" {
+		return nil
+	}
+
+	return differ.OpenComparisonCache(dir, version)
+}
+
+// saveComparisonCache writes the comparison cache back after a comparison. A
+// cache that cannot be written only slows down the next run, so the failure
+// is added to the diff warnings instead of failing the command.
+func saveComparisonCache(cache *differ.ComparisonCache, result *differ.DiffResult) {
+	if cache == nil {
+		return
+	}
+
+	reused, compared := cache.Stats()
+	fmt.Fprintf(os.Stderr, "Comparison cache: %d reused, %d compared\n", reused, compared)
+
+	if err := cache.Save(); err != nil {
+		result.Diagnostics = append(result.Diagnostics, diag.Warning{
+			Code:     diag.CodeComparisonCache,
+			Severity: diag.SeverityWarning,
+			Message:  fmt.Sprintf("comparison cache %s was not saved: %v", cache.Path(), err),
+		})
+	}
+}
+
+// displayWarnings prints parser, differ and generator warnings in one
+// format: severity, location, code and message. The severity is colored when
+// stderr is a terminal and NO_COLOR is unset.
+func displayWarnings(title string, warnings []diag.Warning) {
 	if len(warnings) == 0 {
 		return
 	}
 
-	fmt.Fprintf(os.Stderr, "\n⚠️  Parser Warnings:\n")
+	fmt.Fprintf(os.Stderr, "\n⚠️  %s:\n", title)
+
+	color := useColor(os.Stderr)
 
 	for _, w := range warnings {
-		fmt.Fprintf(os.Stderr, "  - %s:%d: %s\n", w.File, w.Line, w.Message)
+		fmt.Fprintf(os.Stderr, "  - %s: %s\n", formatSeverity(w.Severity, color), w)
+	}
+}
+
+var severityColors = map[diag.Severity]string{ //nolint:gochecknoglobals
+	diag.SeverityInfo:    "\033[36m",
+	diag.SeverityWarning: "\033[33m",
+	diag.SeverityError:   "\033[31m",
+}
+
+func formatSeverity(severity diag.Severity, color bool) string {
+	code, ok := severityColors[severity]
+	if !color || !ok {
+		return string(severity)
+	}
+
+	return code + string(severity) + "\033[0m"
+}
+
+func useColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// addPlanSizeFlags registers the limits above which a plan is reported as
+// too big to review.
+func addPlanSizeFlags(cmd *cobra.Command, limits *differ.PlanSizeLimits) {
+	cmd.Flags().IntVar(&limits.MaxChanges, "max-changes", 0,
+		"Warn when the plan has more changes than this (0 = no limit)")
+	cmd.Flags().IntVar(&limits.MaxDestructiveChanges, "max-destructive-changes", 0,
+		"Warn when the plan has more breaking or data migration changes than this (0 = no limit)")
+	cmd.Flags().IntVar(&limits.MaxTablesTouched, "max-tables", 0,
+		"Warn when the plan changes more tables than this (0 = no limit)")
+}
+
+// planSizeLimits returns the limits to check, or nil when none is set.
+func planSizeLimits(limits differ.PlanSizeLimits) *differ.PlanSizeLimits {
+	if limits == (differ.PlanSizeLimits{}) {
+		return nil
+	}
+
+	return &limits
+}
+
+func displayDiffNotes(result *differ.DiffResult) {
+	if len(result.Notes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\nNotes:\n")
+
+	for _, note := range result.Notes {
+		fmt.Fprintf(os.Stderr, "  - %s\n", note)
 	}
 }
 
@@ -198,12 +604,12 @@ func writeOutput(path string, data []byte) error {
 	outputDir := filepath.Dir(path)
 	if outputDir != "." && outputDir != "" {
 		if err := os.MkdirAll(outputDir, 0o755); err != nil {
-			return util.WrapError("create output directory", err)
+			return internalError(phaseWrite, util.WrapError("create output directory", err))
 		}
 	}
 
 	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return util.WrapError("write output file", err)
+		return internalError(phaseWrite, util.WrapError("write output file", err))
 	}
 
 	return nil
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDesiredSchemaFromStdin(t *testing.T) {
+	t.Parallel()
+
+	desired, err := loadDesiredSchema(context.Background(), stdinPath,
+		strings.NewReader(`CREATE TABLE users (id BIGINT PRIMARY KEY);`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users := desired.GetTable("public", "users")
+	if users == nil || users.Source == nil || users.Source.File != stdinName {
+		t.Fatalf("expected users declared in %s, got %+v", stdinName, users)
+	}
+}
+
+func TestStdinParseErrorLocation(t *testing.T) {
+	t.Parallel()
+
+	_, err := loadDesiredSchema(context.Background(), stdinPath,
+		strings.NewReader("CREATE TABLE users (id BIGINT PRIMARY KEY);\n\nCREATE TABLE;\n"))
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Category != CategoryParse {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+
+	if len(cmdErr.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got %v", cmdErr.Diagnostics)
+	}
+
+	if location := cmdErr.Diagnostics[0].Location(); location != "<stdin>:3" {
+		t.Fatalf("expected the error at <stdin>:3, got %q", location)
+	}
+}
+
+func TestDuplicateTableDefinitionWording(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"a.sql": `CREATE TABLE t (id BIGINT PRIMARY KEY, a TEXT, legacy TEXT);`,
+		"b.sql": "\n\nCREATE TABLE t (id BIGINT PRIMARY KEY, a INTEGER, name TEXT);",
+	})
+	first, second := filepath.Join(dir, "a.sql"), filepath.Join(dir, "b.sql")
+
+	_, err := loadDesiredSchema(context.Background(), dir, nil)
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || len(cmdErr.Diagnostics) != 1 {
+		t.Fatalf("expected one parse error, got %v", err)
+	}
+
+	message := cmdErr.Diagnostics[0].Message
+	for _, want := range []string{
+		"table public.t is already defined at " + first + ":1 with a different definition:",
+		"column a is defined in " + first + ":1 and again in " + second + ":3 with a different type",
+		"column legacy is only defined in " + first + ":1",
+		"column name is only defined in " + second + ":3",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("expected %q in:\n%s", want, message)
+		}
+	}
+
+	if strings.Contains(message, "will be") {
+		t.Errorf("expected no migration wording in:\n%s", message)
+	}
+}
+
+func TestStdinInputs(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCLIFiles(t, map[string]string{
+		"users.sql":    `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"current.json": `{"tables": []}`,
+	})
+	users := filepath.Join(dir, "users.sql")
+
+	t.Run("compare reads either side from stdin", func(t *testing.T) {
+		t.Parallel()
+
+		code, stdout, stderr := runCLIWithInput(t,
+			`CREATE TABLE users (id BIGINT PRIMARY KEY);`, "compare", "-", users)
+		if code != ExitOK || stderr != "" {
+			t.Fatalf("expected no differences, got %d:\n%s", code, stderr)
+		}
+
+		if !strings.Contains(stdout, "No differences found.") {
+			t.Fatalf("unexpected report:\n%s", stdout)
+		}
+	})
+
+	t.Run("current schema JSON from stdin", func(t *testing.T) {
+		t.Parallel()
+
+		code, _, stderr := runCLIWithInput(t, `{"tables": []}`,
+			"diff", "--current", "-", "--desired", users)
+		if code != ExitChanges {
+			t.Fatalf("expected changes, got %d:\n%s", code, stderr)
+		}
+	})
+
+	t.Run("parse errors located in stdin", func(t *testing.T) {
+		t.Parallel()
+
+		code, _, stderr := runCLIWithInput(t, "CREATE TABLE;\n",
+			"--error-format=json", "diff", "--current", filepath.Join(dir, "current.json"),
+			"--desired", "-")
+		if code != ExitParseError {
+			t.Fatalf("expected a parse error, got %d:\n%s", code, stderr)
+		}
+
+		if !strings.Contains(stderr, `"file":"<stdin>","line":1`) {
+			t.Fatalf("expected a diagnostic at <stdin>:1, got:\n%s", stderr)
+		}
+	})
+
+	for name, args := range map[string][]string{
+		"compare":  {"compare", "-", "-"},
+		"diff":     {"diff", "--current", "-", "--desired", "-"},
+		"generate": {"generate", "--current", "-", "--desired", "-", "--stdout"},
+	} {
+		t.Run(name+" rejects both inputs from stdin", func(t *testing.T) {
+			t.Parallel()
+
+			code, _, stderr := runCLIWithInput(t, "", args...)
+			if code != ExitValidationError ||
+				!strings.Contains(stderr, "only one input can be read from stdin") {
+				t.Fatalf("expected a validation error, got %d:\n%s", code, stderr)
+			}
+		})
+	}
+
+	t.Run("since rejects a desired schema from stdin", func(t *testing.T) {
+		t.Parallel()
+
+		code, _, stderr := runCLIWithInput(t, "", "generate", "--since", "HEAD", "--desired", "-")
+		if code != ExitValidationError || !strings.Contains(stderr, "--since needs --desired") {
+			t.Fatalf("expected a validation error, got %d:\n%s", code, stderr)
+		}
+	})
+}
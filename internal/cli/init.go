@@ -0,0 +1,553 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+const (
+	initManifestFile = "manifest.json"
+	// initQuarantineFile does not end in .sql so that loading the directory
+	// as a desired state does not pick it up.
+	initQuarantineFile = "quarantine.sql.txt"
+)
+
+const initQuarantineHeader = `-- WARNING: pgtofu init could not turn the statements below into
+-- desired state. They are NOT part of the schema in this directory, and this
+-- file is not loaded with it. Move what belongs to the schema into the
+-- matching files, then delete this file.
+--
+-- When bootstrapping from migrations, statements such as DROP TABLE or
+-- ALTER TABLE ... DROP COLUMN end up here too, so the objects they remove
+-- may still be present in the generated files.
+`
+
+type initConfig struct {
+	fromDump       string
+	fromMigrations string
+	out            string
+	force          bool
+}
+
+func newInitCommand(ctx context.Context) *cobra.Command {
+	cfg := &initConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Bootstrap a desired-state directory from a dump or migrations",
+		Long: `Parse an existing schema, either a pg_dump --schema-only file or a folder of
+golang-migrate migrations replayed in version order, and write it out as a
+desired-state directory with one canonical SQL file per object:
+
+  extensions/<extension>.sql
+  schemas/<schema>/schema.sql
+  schemas/<schema>/tables/<table>.sql          table, indexes, constraints,
+                                               comments, grants, partitions
+                                               and TimescaleDB settings
+  schemas/<schema>/views/<view>.sql
+  schemas/<schema>/materialized_views/<view>.sql
+  schemas/<schema>/continuous_aggregates/<view>.sql
+  schemas/<schema>/functions/<function>.sql    every overload
+  schemas/<schema>/triggers/<table>.<trigger>.sql
+  schemas/<schema>/types/<type>.sql
+  schemas/<schema>/sequences/<sequence>.sql
+
+manifest.json lists every file and the objects in it. Statements that were
+skipped or failed to parse are written to quarantine.sql.txt for review.
+
+The output directory must be empty; --force deletes its contents first.`,
+		Example: `  # Bootstrap from a schema dump
+  pg_dump --schema-only mydb > schema_dump.sql
+  pgtofu init --from-dump schema_dump.sql --out ./schema
+
+  # Bootstrap from golang-migrate migrations
+  pgtofu init --from-migrations ./migrations --out ./schema`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commandFailure(phaseWrite, runInit(ctx, cfg))
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.fromDump, "from-dump", "",
+		"Path to a pg_dump --schema-only SQL file")
+	cmd.Flags().StringVar(&cfg.fromMigrations, "from-migrations", "",
+		"Path to a directory of golang-migrate migrations")
+	cmd.Flags().StringVar(&cfg.out, "out", "",
+		"Directory to write the desired-state files to")
+	cmd.Flags().BoolVar(&cfg.force, "force", false,
+		"Delete the contents of a non-empty output directory first")
+
+	cmd.MarkFlagsOneRequired("from-dump", "from-migrations")
+	cmd.MarkFlagsMutuallyExclusive("from-dump", "from-migrations")
+	cmd.MarkFlagRequired("out") //nolint:errcheck
+
+	return cmd
+}
+
+func runInit(ctx context.Context, cfg *initConfig) error {
+	source := cmp.Or(cfg.fromDump, cfg.fromMigrations)
+
+	if err := checkInitOutput(cfg.out, source, cfg.force); err != nil {
+		return err
+	}
+
+	db, skipped, err := loadInitSchema(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	objects, err := generator.RenderCanonical(ctx, db)
+	if err != nil {
+		return internalError(phaseGenerate, util.WrapError("render schema", err))
+	}
+
+	files := layoutInitFiles(db, objects)
+
+	if cfg.force {
+		if err := clearDirectory(cfg.out); err != nil {
+			return err
+		}
+	}
+
+	if err := writeInitDirectory(cfg.out, source, files, skipped); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Wrote %d objects to %d files in %s\n",
+		len(objects), len(files), cfg.out)
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d statements could not be converted; review %s\n",
+			len(skipped), filepath.Join(cfg.out, initQuarantineFile))
+	}
+
+	return nil
+}
+
+// checkInitOutput refuses to write into a non-empty directory unless force
+// is set, and never lets force delete the schema being converted.
+func checkInitOutput(out, source string, force bool) error {
+	entries, err := os.ReadDir(out)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return util.WrapError("read output directory", err)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if !force {
+		return validationError(phaseUsage,
+			fmt.Errorf("output directory %s is not empty (use --force to replace it)", out))
+	}
+
+	outAbs, err := filepath.Abs(out)
+	if err != nil {
+		return util.WrapError("resolve output directory", err)
+	}
+
+	sourceAbs, err := filepath.Abs(source)
+	if err != nil {
+		return util.WrapError("resolve source", err)
+	}
+
+	if rel, err := filepath.Rel(outAbs, sourceAbs); err == nil && !strings.HasPrefix(rel, "..") {
+		return validationError(phaseUsage,
+			fmt.Errorf("output directory %s contains the source %s", out, source))
+	}
+
+	return nil
+}
+
+func loadInitSchema(
+	ctx context.Context,
+	cfg *initConfig,
+) (*schema.Database, []parser.SkippedStatement, error) {
+	var skipped []parser.SkippedStatement
+
+	p := parser.New(
+		parser.WithTableConflictDescriber(describeTableConflict),
+		parser.WithSkippedStatements(func(stmt parser.SkippedStatement) {
+			skipped = append(skipped, stmt)
+		}),
+	)
+	db := &schema.Database{
+		Version:      schema.SchemaVersion,
+		DatabaseName: "desired",
+		Tables:       []schema.Table{},
+	}
+
+	if cfg.fromDump != "" {
+		fmt.Fprintf(os.Stderr, "Loading schema dump from: %s\n", cfg.fromDump)
+
+		if err := p.ParseFileContext(ctx, cfg.fromDump, db); err != nil {
+			return nil, nil, inputError(phaseLoad, util.WrapError("parse file", err))
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "Replaying migrations from: %s\n", cfg.fromMigrations)
+
+		if err := replayMigrations(ctx, p, cfg.fromMigrations, db); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	db.Sort()
+
+	return db, skipped, nil
+}
+
+// replayMigrations parses the up migrations of dir in version order, so that
+// later migrations build on the objects earlier ones created.
+func replayMigrations(
+	ctx context.Context,
+	p *parser.Parser,
+	dir string,
+	db *schema.Database,
+) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return inputError(phaseLoad, util.WrapError("read migrations directory", err))
+	}
+
+	type migration struct {
+		version int
+		name    string
+	}
+
+	var migrations []migration
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		version, _, direction, err := generator.ParseMigrationFileName(entry.Name())
+		if err != nil || direction != generator.DirectionUp {
+			continue
+		}
+
+		migrations = append(migrations, migration{version: version, name: entry.Name()})
+	}
+
+	if len(migrations) == 0 {
+		return validationError(phaseLoad,
+			fmt.Errorf("no {version}_{description}.up.sql migrations found in %s", dir))
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int {
+		return cmp.Or(cmp.Compare(a.version, b.version), strings.Compare(a.name, b.name))
+	})
+
+	for _, m := range migrations {
+		path := filepath.Join(dir, m.name)
+		if err := p.ParseFileWithoutProcessingDeferredContext(ctx, path, db); err != nil {
+			return inputError(phaseLoad, util.WrapError("parse migration "+m.name, err))
+		}
+	}
+
+	if err := p.ProcessDeferredPartitions(db); err != nil {
+		return parseError(phaseLoad, util.WrapError("processing deferred partitions", err))
+	}
+
+	return nil
+}
+
+type initFile struct {
+	path    string
+	objects []generator.CanonicalObject
+}
+
+// layoutInitFiles groups the canonical objects into the files they are
+// written to. Within a file the owning object comes first, so that reading
+// the directory back finds a table before its indexes.
+func layoutInitFiles(db *schema.Database, objects []generator.CanonicalObject) []initFile {
+	layout := newInitLayout(db)
+	positions := make(map[string]int)
+
+	var files []initFile
+
+	for _, object := range objects {
+		path := layout.path(object)
+
+		pos, ok := positions[path]
+		if !ok {
+			pos = len(files)
+			positions[path] = pos
+			files = append(files, initFile{path: path})
+		}
+
+		files[pos].objects = append(files[pos].objects, object)
+	}
+
+	for i := range files {
+		slices.SortStableFunc(files[i].objects, func(a, b generator.CanonicalObject) int {
+			return cmp.Compare(initObjectRank(a.ObjectType), initObjectRank(b.ObjectType))
+		})
+	}
+
+	slices.SortFunc(files, func(a, b initFile) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	return files
+}
+
+func initObjectRank(objectType string) int {
+	switch objectType {
+	case "partition", "hypertable":
+		return 1
+	case "dimension":
+		return 2
+	case "compression_policy", "retention_policy":
+		return 3
+	case "index":
+		return 4
+	case "grant":
+		return 5
+	default:
+		return 0
+	}
+}
+
+// initLayout knows which file every object belongs in. Indexes, grants and
+// TimescaleDB settings live with the relation they belong to.
+type initLayout struct {
+	owners map[string]string
+}
+
+func newInitLayout(db *schema.Database) *initLayout {
+	layout := &initLayout{owners: make(map[string]string)}
+
+	addIndexes := func(indexes []schema.Index, schemaName, path string) {
+		for _, idx := range indexes {
+			layout.owners["index "+differ.IndexKey(cmp.Or(idx.Schema, schemaName), idx.Name)] = path
+		}
+	}
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		path := initObjectPath(table.Schema, "tables", table.Name)
+		addIndexes(table.Indexes, table.Schema, path)
+		layout.owners["relation "+differ.TableKey(table.Schema, table.Name)] = path
+
+		if table.PartitionStrategy != nil {
+			for _, partition := range table.PartitionStrategy.Partitions {
+				addIndexes(partition.Indexes, table.Schema, path)
+			}
+		}
+	}
+
+	for i := range db.MaterializedViews {
+		mv := &db.MaterializedViews[i]
+		path := initObjectPath(mv.Schema, "materialized_views", mv.Name)
+		addIndexes(mv.Indexes, mv.Schema, path)
+		layout.owners["relation "+differ.ViewKey(mv.Schema, mv.Name)] = path
+	}
+
+	for i := range db.Views {
+		view := &db.Views[i]
+		layout.owners["relation "+differ.ViewKey(view.Schema, view.Name)] =
+			initObjectPath(view.Schema, "views", view.Name)
+	}
+
+	for i := range db.ContinuousAggregates {
+		ca := &db.ContinuousAggregates[i]
+		path := initObjectPath(ca.Schema, "continuous_aggregates", ca.ViewName)
+		addIndexes(ca.Indexes, ca.Schema, path)
+		layout.owners["relation "+differ.ViewKey(ca.Schema, ca.ViewName)] = path
+	}
+
+	return layout
+}
+
+func (l *initLayout) path(object generator.CanonicalObject) string {
+	schemaName, name, _ := strings.Cut(object.ObjectName, ".")
+
+	switch object.ObjectType {
+	case "extension":
+		return filepath.Join("extensions", initFileName(object.ObjectName)+".sql")
+	case "schema":
+		return filepath.Join("schemas", initFileName(object.ObjectName), "schema.sql")
+	case "table":
+		return initObjectPath(schemaName, "tables", name)
+	case "hypertable", "dimension", "compression_policy", "retention_policy", "grant":
+		if path, ok := l.owners["relation "+object.ObjectName]; ok {
+			return path
+		}
+
+		return initObjectPath(schemaName, "tables", name)
+	case "partition":
+		parent, _, _ := strings.Cut(name, ".")
+		return initObjectPath(schemaName, "tables", parent)
+	case "index":
+		if path, ok := l.owners["index "+object.ObjectName]; ok {
+			return path
+		}
+
+		return initObjectPath(schemaName, "indexes", name)
+	case "view":
+		return initObjectPath(schemaName, "views", name)
+	case "materialized_view":
+		return initObjectPath(schemaName, "materialized_views", name)
+	case "continuous_aggregate":
+		return initObjectPath(schemaName, "continuous_aggregates", name)
+	case "function":
+		function, _, _ := strings.Cut(name, "(")
+		return initObjectPath(schemaName, "functions", function)
+	case "trigger":
+		return initObjectPath(schemaName, "triggers", name)
+	case "type":
+		return initObjectPath(schemaName, "types", name)
+	case "sequence":
+		return initObjectPath(schemaName, "sequences", name)
+	default:
+		return initObjectPath(schemaName, object.ObjectType, name)
+	}
+}
+
+func initObjectPath(schemaName, kind, name string) string {
+	schemaName = cmp.Or(schemaName, schema.DefaultSchema)
+
+	return filepath.Join("schemas", initFileName(schemaName), kind, initFileName(name)+".sql")
+}
+
+// initFileName turns an object name into a file name, replacing anything
+// that is not safe in a path.
+func initFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, name)
+
+	if strings.Trim(name, ".") == "" {
+		return strings.Repeat("_", max(len(name), 1))
+	}
+
+	return name
+}
+
+type initManifest struct {
+	Source     string              `json:"source"`
+	Files      []initManifestEntry `json:"files"`
+	Quarantine *initQuarantine     `json:"quarantine,omitempty"`
+}
+
+type initManifestEntry struct {
+	Path    string   `json:"path"`
+	Objects []string `json:"objects"`
+}
+
+type initQuarantine struct {
+	Path       string `json:"path"`
+	Statements int    `json:"statements"`
+}
+
+func writeInitDirectory(
+	out, source string,
+	files []initFile,
+	skipped []parser.SkippedStatement,
+) error {
+	manifest := initManifest{
+		Source: filepath.ToSlash(source),
+		Files:  make([]initManifestEntry, 0, len(files)),
+	}
+
+	for _, file := range files {
+		parts := make([]string, 0, len(file.objects))
+		entry := initManifestEntry{Path: filepath.ToSlash(file.path)}
+
+		for _, object := range file.objects {
+			parts = append(parts, object.SQL)
+			entry.Objects = append(entry.Objects, object.Key())
+		}
+
+		content := strings.Join(parts, "\n\n") + "\n"
+		if err := writeOutput(filepath.Join(out, file.path), []byte(content)); err != nil {
+			return err
+		}
+
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	if len(skipped) > 0 {
+		if err := writeOutput(
+			filepath.Join(out, initQuarantineFile), formatQuarantine(skipped),
+		); err != nil {
+			return err
+		}
+
+		manifest.Quarantine = &initQuarantine{
+			Path:       initQuarantineFile,
+			Statements: len(skipped),
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return util.WrapError("marshal manifest", err)
+	}
+
+	return writeOutput(filepath.Join(out, initManifestFile), append(data, '\n'))
+}
+
+func formatQuarantine(skipped []parser.SkippedStatement) []byte {
+	var sb strings.Builder
+
+	sb.WriteString(initQuarantineHeader)
+
+	for _, stmt := range skipped {
+		location := schema.SourceLocation{File: filepath.ToSlash(stmt.File), Line: stmt.Line}
+		reason := strings.ReplaceAll(stmt.Reason, "\n", " ")
+		fmt.Fprintf(&sb, "\n-- %s: %s\n%s", location, reason, stmt.SQL)
+
+		if !strings.HasSuffix(stmt.SQL, ";") {
+			sb.WriteString(";")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return []byte(sb.String())
+}
+
+func clearDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return util.WrapError("read output directory", err)
+	}
+
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return util.WrapError("clear output directory", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+const initDump = `SET statement_timeout = 0;
+CREATE EXTENSION IF NOT EXISTS timescaledb WITH SCHEMA public;
+CREATE SCHEMA billing;
+CREATE TYPE public.status AS ENUM ('active', 'disabled');
+
+CREATE TABLE public.users (
+    id bigint NOT NULL,
+    email text NOT NULL,
+    status public.status DEFAULT 'active'::public.status NOT NULL
+);
+ALTER TABLE public.users OWNER TO app;
+COMMENT ON TABLE public.users IS 'Registered users';
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX users_email_key ON public.users USING btree (email);
+
+CREATE TABLE billing.invoices (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    total numeric(12,2) NOT NULL
+);
+ALTER TABLE ONLY billing.invoices ADD CONSTRAINT invoices_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY billing.invoices
+    ADD CONSTRAINT invoices_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);
+
+CREATE TABLE public.metrics (
+    "time" timestamp with time zone NOT NULL,
+    value double precision
+);
+SELECT create_hypertable('public.metrics', 'time');
+
+CREATE VIEW public.active_users AS
+ SELECT id, email FROM public.users WHERE status = 'active'::public.status;
+
+CREATE MATERIALIZED VIEW public.user_totals AS
+ SELECT user_id, sum(total) AS total FROM billing.invoices GROUP BY user_id;
+CREATE INDEX user_totals_user_idx ON public.user_totals USING btree (user_id);
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER users_touch BEFORE UPDATE ON public.users
+    FOR EACH ROW EXECUTE FUNCTION public.touch();
+
+GRANT SELECT ON public.users TO reporting;
+`
+
+func writeInitFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func readInitFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+
+	return string(data)
+}
+
+func TestRunInitFromDumpRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	dump := writeInitFile(t, dir, "schema_dump.sql", initDump)
+	out := filepath.Join(dir, "schema")
+
+	if err := runInit(ctx, &initConfig{fromDump: dump, out: out}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, path := range []string{
+		"extensions/timescaledb.sql",
+		"schemas/billing/schema.sql",
+		"schemas/billing/tables/invoices.sql",
+		"schemas/public/tables/users.sql",
+		"schemas/public/tables/metrics.sql",
+		"schemas/public/types/status.sql",
+		"schemas/public/views/active_users.sql",
+		"schemas/public/materialized_views/user_totals.sql",
+		"schemas/public/functions/touch.sql",
+		"schemas/public/triggers/users.users_touch.sql",
+		initManifestFile,
+		initQuarantineFile,
+	} {
+		if _, err := os.Stat(filepath.Join(out, path)); err != nil {
+			t.Errorf("expected %s to be written: %v", path, err)
+		}
+	}
+
+	users := readInitFile(t, filepath.Join(out, "schemas/public/tables/users.sql"))
+	for _, want := range []string{
+		"CREATE TABLE public.users (",
+		"COMMENT ON TABLE public.users IS\n'Registered users';",
+		"CREATE UNIQUE INDEX users_email_key ON public.users (email);",
+		"GRANT SELECT ON TABLE public.users TO reporting;",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("expected users.sql to contain %q, got:\n%s", want, users)
+		}
+	}
+
+	if strings.Index(users, "CREATE TABLE") > strings.Index(users, "CREATE UNIQUE INDEX") {
+		t.Errorf("expected the table before its index, got:\n%s", users)
+	}
+
+	quarantine := readInitFile(t, filepath.Join(out, initQuarantineFile))
+	for _, want := range []string{
+		initQuarantineHeader,
+		"\n-- " + dump + ":1: unsupported statement: SET statement_timeout = 0\n" +
+			"SET statement_timeout = 0;\n",
+		"ALTER TABLE public.users OWNER TO app;\n",
+	} {
+		if !strings.Contains(quarantine, want) {
+			t.Errorf("expected the quarantine to contain %q, got:\n%s", want, quarantine)
+		}
+	}
+
+	var manifest initManifest
+	if err := json.Unmarshal([]byte(readInitFile(t, filepath.Join(out, initManifestFile))),
+		&manifest); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+
+	if manifest.Quarantine == nil || manifest.Quarantine.Statements != 2 {
+		t.Errorf("expected 2 quarantined statements, got %+v", manifest.Quarantine)
+	}
+
+	for _, file := range manifest.Files {
+		if file.Path == "schemas/public/tables/users.sql" {
+			got := strings.Join(file.Objects, ",")
+			if got != "table public.users,index public.users_email_key,grant public.users" {
+				t.Errorf("unexpected users.sql objects: %v", file.Objects)
+			}
+		}
+	}
+
+	original, _, err := loadInitSchema(ctx, &initConfig{fromDump: dump})
+	if err != nil {
+		t.Fatalf("reparse dump: %v", err)
+	}
+
+	bootstrapped, err := loadSQLSchema(ctx, "desired", out, nil)
+	if err != nil {
+		t.Fatalf("load bootstrapped directory: %v", err)
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(original, bootstrapped)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+
+	for _, change := range result.Changes {
+		t.Errorf("unexpected %s change: %s", change.Type, change.Description)
+	}
+}
+
+func TestRunInitIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	dump := writeInitFile(t, dir, "schema_dump.sql", initDump)
+
+	render := func(name string) map[string]string {
+		out := filepath.Join(dir, name)
+		if err := runInit(ctx, &initConfig{fromDump: dump, out: out}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		files := make(map[string]string)
+
+		err := filepath.WalkDir(out, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, _ := filepath.Rel(out, path)
+			files[rel] = readInitFile(t, path)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk %s: %v", out, err)
+		}
+
+		return files
+	}
+
+	first, second := render("first"), render("second")
+	if len(first) != len(second) {
+		t.Fatalf("expected the same files, got %d and %d", len(first), len(second))
+	}
+
+	for path, content := range first {
+		if second[path] != content {
+			t.Errorf("%s differs between runs", path)
+		}
+	}
+}
+
+func TestRunInitOutputDirectory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	dump := writeInitFile(t, dir, "schema_dump.sql", "CREATE TABLE users (id BIGINT);\n")
+	out := filepath.Join(dir, "schema")
+
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	stale := writeInitFile(t, out, "stale.sql", "CREATE TABLE stale (id INT);\n")
+
+	err := runInit(ctx, &initConfig{fromDump: dump, out: out})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected a non-empty directory to be refused, got %v", err)
+	}
+
+	if err := runInit(ctx, &initConfig{fromDump: dump, out: out, force: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected --force to remove %s", stale)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, initQuarantineFile)); !os.IsNotExist(err) {
+		t.Errorf("expected no quarantine without skipped statements")
+	}
+
+	err = runInit(ctx, &initConfig{fromDump: dump, out: dir, force: true})
+	if err == nil || !strings.Contains(err.Error(), "contains the source") {
+		t.Fatalf("expected --force over the source to be refused, got %v", err)
+	}
+}
+
+func TestRunInitFromMigrations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	migrations := t.TempDir()
+	writeInitFile(t, migrations, "000002_add_email.up.sql",
+		"ALTER TABLE users ADD COLUMN email TEXT;\nCREATE INDEX idx_users_id ON users (id);\n")
+	writeInitFile(t, migrations, "000001_create_users.up.sql",
+		"CREATE TABLE users (id BIGINT PRIMARY KEY);\n")
+	writeInitFile(t, migrations, "000001_create_users.down.sql", "DROP TABLE users;\n")
+	out := filepath.Join(t.TempDir(), "schema")
+
+	if err := runInit(ctx, &initConfig{fromMigrations: migrations, out: out}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users := readInitFile(t, filepath.Join(out, "schemas/public/tables/users.sql"))
+	if !strings.Contains(users, "CREATE INDEX idx_users_id ON public.users (id);") {
+		t.Errorf("expected the index from the later migration, got:\n%s", users)
+	}
+
+	quarantine := readInitFile(t, filepath.Join(out, initQuarantineFile))
+	if !strings.Contains(quarantine, "000002_add_email.up.sql:1: ") ||
+		!strings.Contains(quarantine, "ALTER TABLE users ADD COLUMN email TEXT;") {
+		t.Errorf("expected the unsupported ALTER TABLE to be quarantined, got:\n%s", quarantine)
+	}
+
+	err := runInit(ctx, &initConfig{fromMigrations: t.TempDir(), out: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "no {version}_{description}.up.sql") {
+		t.Fatalf("expected an error for a directory without migrations, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/lint"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// Values of lint --format.
+const (
+	lintFormatText = "text"
+	lintFormatJSON = "json"
+)
+
+type lintConfig struct {
+	desired string
+	format  string
+}
+
+func newLintCommand(ctx context.Context) *cobra.Command {
+	cfg := &lintConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the desired schema without a database",
+		Long: `Parse the desired schema (SQL files) and check it for mistakes PostgreSQL
+would only report once a migration runs: duplicate names, foreign keys, triggers
+and views referencing objects the schema does not declare, and more.
+
+Parser errors and warnings are reported with the findings. The command exits
+with status 3 when the schema could not be parsed and 4 when a check failed.`,
+		Example: `  # Lint a schema directory
+  pgtofu lint --desired ./schema
+
+  # Report for CI
+  pgtofu lint --desired ./schema --format json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return commandFailure(phaseCheck,
+				runLint(ctx, cfg, cmd.InOrStdin(), cmd.OutOrStdout()))
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.desired, "desired", "",
+		"Path to desired schema SQL file or directory, or - for stdin")
+	cmd.Flags().StringVar(&cfg.format, "format", lintFormatText,
+		"Format of the report (text or json)")
+
+	cmd.MarkFlagRequired("desired") //nolint:errcheck
+
+	return cmd
+}
+
+func runLint(ctx context.Context, cfg *lintConfig, stdin io.Reader, out io.Writer) error {
+	if cfg.format != lintFormatText && cfg.format != lintFormatJSON {
+		return validationError(phaseUsage,
+			fmt.Errorf("invalid --format %q (use text or json)", cfg.format))
+	}
+
+	fmt.Fprintf(os.Stderr, "Linting desired schema from: %s\n", displayPath(cfg.desired))
+
+	// Unlike the other commands, lint reports parser errors with its findings
+	// rather than stopping at them.
+	p := parser.New(parser.WithTableConflictDescriber(describeTableConflict))
+	db := &schema.Database{
+		Version:      schema.SchemaVersion,
+		DatabaseName: "desired",
+		Tables:       []schema.Table{},
+	}
+
+	if err := parseSQLInput(ctx, p, cfg.desired, stdin, db); err != nil {
+		return err
+	}
+
+	db.Sort()
+
+	parseErrors := parseErrorDiagnostics(p.GetErrors())
+	report := lint.Check(db, append(parseErrors, p.GetWarnings()...))
+
+	if cfg.format == lintFormatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return internalError(phaseCheck, err)
+		}
+
+		fmt.Fprintln(out, string(data))
+	} else {
+		writeLintReport(out, report)
+	}
+
+	if len(parseErrors) > 0 {
+		return parseError(phaseLoad,
+			fmt.Errorf("encountered %d parsing errors", len(parseErrors)), parseErrors...)
+	}
+
+	errs := report.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	diagnostics := make([]diag.Warning, 0, len(errs))
+	for i := range errs {
+		diagnostics = append(diagnostics, errs[i].Diagnostic())
+	}
+
+	return validationError(phaseCheck,
+		fmt.Errorf("%s in the desired schema",
+			util.Plural(len(errs), "lint error", "lint errors")), diagnostics...)
+}
+
+// writeLintReport writes one line per finding, located where the object was
+// declared, and a count of the errors and warnings.
+func writeLintReport(out io.Writer, report *lint.Report) {
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(out, "No problems found.")
+		return
+	}
+
+	for i := range report.Findings {
+		finding := &report.Findings[i]
+
+		if location := finding.Location(); location != "" {
+			fmt.Fprintf(out, "%s: ", location)
+		}
+
+		fmt.Fprintf(out, "%s: %s (%s)\n", finding.Severity, finding.Message, finding.Rule)
+	}
+
+	errs := len(report.Errors())
+	fmt.Fprintf(out, "\nErrors: %d, warnings: %d\n", errs, len(report.Findings)-errs)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/lint"
+)
+
+const lintSchema = `
+CREATE TABLE orders (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT REFERENCES customers (id)
+);
+CREATE VIEW order_ids AS SELECT id FROM orders;
+`
+
+func TestRunLintReportsFindings(t *testing.T) {
+	t.Parallel()
+
+	path := writeCompareSchema(t, t.TempDir(), "schema.sql", lintSchema)
+
+	var out bytes.Buffer
+
+	err := runLint(context.Background(), &lintConfig{desired: path, format: lintFormatText},
+		nil, &out)
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Category != CategoryValidation {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	if len(cmdErr.Diagnostics) != 1 ||
+		cmdErr.Diagnostics[0].Code != diag.CodeMissingReferencedTable ||
+		cmdErr.Diagnostics[0].Location() != path+":2" {
+		t.Fatalf("unexpected diagnostics: %+v", cmdErr.Diagnostics)
+	}
+
+	want := path + ":2: error: foreign key constraint orders_customer_id_fkey on " +
+		"public.orders references public.customers, which the schema does not declare " +
+		"(MISSING_REFERENCED_TABLE)\n\nErrors: 1, warnings: 0\n"
+	if out.String() != want {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestRunLintJSON(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	err := runLint(context.Background(), &lintConfig{desired: "-", format: lintFormatJSON},
+		strings.NewReader("CREATE TABLE orders (id BIGINT PRIMARY KEY);"), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report lint.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, out.String())
+	}
+
+	if report.Findings == nil || len(report.Findings) != 0 {
+		t.Fatalf("expected an empty list of findings, got %s", out.String())
+	}
+}
+
+func TestRunLintReportsParseErrors(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	err := runLint(context.Background(), &lintConfig{desired: "-", format: lintFormatText},
+		strings.NewReader("CREATE TABLE orders_2024 PARTITION OF orders FOR VALUES IN (2024);"),
+		&out)
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Category != CategoryParse {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Errors: 1, warnings: 0") {
+		t.Fatalf("expected the parse error in the report:\n%s", out.String())
+	}
+}
+
+func TestRunLintRejectsInvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	err := runLint(context.Background(), &lintConfig{desired: "-", format: "yaml"},
+		strings.NewReader(""), nil)
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Phase != phaseUsage {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+}
@@ -53,7 +53,7 @@ stored directly in your schema files.`,
   pgtofu partition generate --table questions --schema assessment --modulus 32 \
     --output schema/tables/questions_partitions.sql`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPartitionGenerate(cfg)
+			return commandFailure(phaseGenerate, runPartitionGenerate(cfg))
 		},
 	}
 
@@ -77,7 +77,8 @@ stored directly in your schema files.`,
 
 func runPartitionGenerate(cfg *partitionConfig) error {
 	if cfg.modulus < 1 {
-		return fmt.Errorf("modulus must be at least 1, got %d", cfg.modulus)
+		return validationError(phaseUsage,
+			fmt.Errorf("modulus must be at least 1, got %d", cfg.modulus))
 	}
 
 	if cfg.modulus > 1024 {
@@ -95,7 +96,7 @@ func runPartitionGenerate(cfg *partitionConfig) error {
 		dir := filepath.Dir(cfg.output)
 		if dir != "." {
 			if err := os.MkdirAll(dir, 0o755); err != nil {
-				return util.WrapError("create output directory", err)
+				return internalError(phaseWrite, util.WrapError("create output directory", err))
 			}
 		}
 
@@ -103,7 +104,7 @@ func runPartitionGenerate(cfg *partitionConfig) error {
 
 		output, err = os.Create(cfg.output)
 		if err != nil {
-			return util.WrapError("create output file", err)
+			return internalError(phaseWrite, util.WrapError("create output file", err))
 		}
 		defer output.Close()
 	}
@@ -135,7 +136,8 @@ func runPartitionGenerate(cfg *partitionConfig) error {
 			}
 		}
 	default:
-		return fmt.Errorf("unknown format: %s (use 'sql' or 'list')", cfg.format)
+		return validationError(phaseUsage,
+			fmt.Errorf("unknown format: %s (use 'sql' or 'list')", cfg.format))
 	}
 
 	if cfg.output != "-" {
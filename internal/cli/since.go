@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// loadSchemaAtRef parses the SQL file or directory at path as it was committed
+// at the git ref, following the same discovery rules as the working tree.
+// Files are read with git show into a scratch directory, so files added or
+// removed since the ref are simply present on one side only. A path that did
+// not exist at the ref yields an empty schema.
+func loadSchemaAtRef(ctx context.Context, ref, path string) (*schema.Database, error) {
+	top, rel, err := gitRelativePath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := runGit(ctx, top, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, validationError(phaseLoad, fmt.Errorf("--since: unknown git revision %q", ref))
+	}
+
+	fmt.Fprintf(os.Stderr, "Loading current schema from: %s:%s\n", ref, filepath.ToSlash(rel))
+
+	listing, err := runGit(ctx, top, "ls-tree", "-r", "-z", "--name-only", ref, "--", rel)
+	if err != nil {
+		return nil, util.WrapError("list files at "+ref, err)
+	}
+
+	scratch, err := os.MkdirTemp("", "pgtofu-since-")
+	if err != nil {
+		return nil, util.WrapError("create scratch directory", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	target := filepath.Join(scratch, rel)
+
+	for name := range strings.SplitSeq(strings.TrimSuffix(string(listing), "\x00"), "\x00") {
+		file := filepath.Join(scratch, filepath.FromSlash(name))
+		if name == "" || (file != target && filepath.Ext(name) != ".sql") {
+			continue
+		}
+
+		content, err := runGit(ctx, top, "show", ref+":"+name)
+		if err != nil {
+			return nil, util.WrapError("read "+name+" at "+ref, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			return nil, util.WrapError("create scratch directory", err)
+		}
+
+		if err := os.WriteFile(file, content, 0o600); err != nil {
+			return nil, util.WrapError("write scratch file", err)
+		}
+	}
+
+	if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "%s did not exist at %s; treating it as empty\n", rel, ref)
+
+		return &schema.Database{
+			Version:      schema.SchemaVersion,
+			DatabaseName: "current",
+			Tables:       []schema.Table{},
+		}, nil
+	}
+
+	return parseSQLSchema(ctx, "current", target, nil)
+}
+
+// gitRelativePath returns the top of the git work tree containing path and
+// path relative to it.
+func gitRelativePath(ctx context.Context, path string) (top, rel string, err error) {
+	resolved, err := filepath.Abs(path)
+	if err == nil {
+		resolved, err = filepath.EvalSymlinks(resolved)
+	}
+
+	if err != nil {
+		return "", "", util.WrapError("resolve path", err)
+	}
+
+	dir := resolved
+	if info, err := os.Stat(resolved); err == nil && !info.IsDir() {
+		dir = filepath.Dir(resolved)
+	}
+
+	output, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", validationError(phaseLoad, fmt.Errorf(
+			"--since requires %s to be inside a git work tree: %w", path, err,
+		))
+	}
+
+	top = strings.TrimSpace(string(output))
+
+	rel, err = filepath.Rel(top, resolved)
+	if err != nil {
+		return "", "", util.WrapError("resolve path", err)
+	}
+
+	return top, rel, nil
+}
+
+// runGit runs git in dir and returns its standard output. The error carries
+// git's standard error, which explains most failures.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+
+		return nil, util.WrapError("git "+args[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// sinceComparison labels migrations generated by generate --since, which
+// compare two revisions of the schema files rather than a database.
+func sinceComparison(ref, path string) string {
+	return fmt.Sprintf(
+		"schema-to-schema diff of %s from %s to the working tree (no database was read)",
+		path, ref,
+	)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+// newSinceRepo creates a git repository holding schema/ with the given files
+// committed, and returns the repository's directory.
+func newSinceRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	writeSinceFiles(t, dir, files)
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--quiet", "-m", "base"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).
+			CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", args[0], err, output)
+		}
+	}
+
+	return dir
+}
+
+func writeSinceFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, sql := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+
+		if err := os.WriteFile(path, []byte(sql), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadSchemaAtRef(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newSinceRepo(t, map[string]string{
+		"schema/tables/users.sql":  `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+		"schema/tables/legacy.sql": `CREATE TABLE legacy (id INT);`,
+		"schema/README.md":         `not SQL`,
+	})
+
+	writeSinceFiles(t, repo, map[string]string{
+		"schema/tables/users.sql":  `CREATE TABLE users (id BIGINT PRIMARY KEY, email TEXT);`,
+		"schema/tables/orders.sql": `CREATE TABLE orders (id BIGINT PRIMARY KEY);`,
+	})
+
+	if err := os.Remove(filepath.Join(repo, "schema/tables/legacy.sql")); err != nil {
+		t.Fatalf("remove legacy.sql: %v", err)
+	}
+
+	schemaDir := filepath.Join(repo, "schema")
+
+	current, err := loadSchemaAtRef(ctx, "HEAD", schemaDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	desired, err := loadDesiredSchema(ctx, schemaDir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var descriptions []string
+	for _, change := range result.Changes {
+		descriptions = append(descriptions, change.Description)
+	}
+
+	got := strings.Join(descriptions, "\n")
+	for _, want := range []string{
+		"Table public.orders is in desired schema but not in database",
+		"Table public.legacy exists in database but not in desired schema",
+		"Column public.users.email (TEXT) is in desired schema but not in database",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("changes are missing %q:\n%s", want, got)
+		}
+	}
+
+	if len(result.Changes) != 3 {
+		t.Errorf("expected 3 changes, got:\n%s", got)
+	}
+
+	file, err := loadSchemaAtRef(ctx, "HEAD", filepath.Join(schemaDir, "tables", "users.sql"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(file.Tables) != 1 || len(file.Tables[0].Columns) != 1 {
+		t.Fatalf("expected users as committed, got %+v", file.Tables)
+	}
+
+	added, err := loadSchemaAtRef(ctx, "HEAD", filepath.Join(schemaDir, "tables", "orders.sql"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(added.Tables) != 0 {
+		t.Fatalf("expected a file added since HEAD to be empty, got %+v", added.Tables)
+	}
+}
+
+func TestLoadSchemaAtRefErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newSinceRepo(t, map[string]string{
+		"schema/users.sql": `CREATE TABLE users (id BIGINT PRIMARY KEY);`,
+	})
+
+	_, err := loadSchemaAtRef(ctx, "no-such-branch", filepath.Join(repo, "schema"))
+	if err == nil || !strings.Contains(err.Error(), `unknown git revision "no-such-branch"`) {
+		t.Fatalf("expected an unknown revision error, got %v", err)
+	}
+
+	outside := t.TempDir()
+
+	_, err = loadSchemaAtRef(ctx, "HEAD", outside)
+	if err == nil || !strings.Contains(err.Error(), "inside a git work tree") {
+		t.Fatalf("expected a work tree error, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/accented-ai/pgtofu/internal/compat"
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/lint"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// versionFormatVersion is the version of the version --json document. It
+// changes only when a field is removed or changes meaning; new fields and new
+// entries in the feature lists keep it.
+const versionFormatVersion = 1
+
+// versionReport is what version --json prints.
+type versionReport struct {
+	FormatVersion int             `json:"format_version"`
+	Version       string          `json:"version"`
+	Commit        string          `json:"commit"`
+	BuildTime     string          `json:"build_time"`
+	Features      versionFeatures `json:"features"`
+}
+
+// versionFeatures lists the capabilities of this build, so wrappers can check
+// for one instead of comparing version strings.
+type versionFeatures struct {
+	// SchemaFormatVersion is the version of the schema JSON extract writes.
+	SchemaFormatVersion string                   `json:"schema_format_version"`
+	OutputFormats       []generator.OutputFormat `json:"output_formats"`
+	ErrorFormats        []string                 `json:"error_formats"`
+	CompatFormats       []string                 `json:"compat_formats"`
+	// CompatFeatures are the keys of the features check-compat reports.
+	CompatFeatures []string `json:"compat_features"`
+	LintFormats    []string `json:"lint_formats"`
+	// LintRules are the codes of the rules lint checks.
+	LintRules   []diag.Code         `json:"lint_rules"`
+	ChangeTypes []differ.ChangeType `json:"change_types"`
+}
+
+func newVersionReport(info BuildInfo) versionReport {
+	features := compat.Features()
+	compatFeatures := make([]string, 0, len(features))
+
+	for i := range features {
+		compatFeatures = append(compatFeatures, features[i].Key)
+	}
+
+	rules := lint.Rules()
+	lintRules := make([]diag.Code, 0, len(rules))
+
+	for i := range rules {
+		lintRules = append(lintRules, rules[i].Code)
+	}
+
+	return versionReport{
+		FormatVersion: versionFormatVersion,
+		Version:       info.Version,
+		Commit:        info.Commit,
+		BuildTime:     info.BuildTime,
+		Features: versionFeatures{
+			SchemaFormatVersion: schema.SchemaVersion,
+			OutputFormats:       generator.OutputFormats(),
+			ErrorFormats:        []string{errorFormatText, errorFormatJSON},
+			CompatFormats:       []string{compatFormatText, compatFormatJSON},
+			CompatFeatures:      compatFeatures,
+			LintFormats:         []string{lintFormatText, lintFormatJSON},
+			LintRules:           lintRules,
+			ChangeTypes:         differ.ChangeTypes(),
+		},
+	}
+}
+
+func newVersionCommand(info BuildInfo) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Long: `Print the version, commit and build time of pgtofu.
+
+With --json, print them as a JSON document together with the features this
+build supports: output formats, error formats, check-compat features, the
+change types it can report and the schema format version.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runVersion(info, asJSON, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false,
+		"Print version information and supported features as JSON")
+
+	return cmd
+}
+
+func runVersion(info BuildInfo, asJSON bool, out io.Writer) error {
+	if !asJSON {
+		fmt.Fprintf(out, "pgtofu %s\n", info.Version)
+		fmt.Fprintf(out, "  commit:     %s\n", info.Commit)
+		fmt.Fprintf(out, "  built:      %s\n", info.BuildTime)
+
+		return nil
+	}
+
+	data, err := json.MarshalIndent(newVersionReport(info), "", "  ")
+	if err != nil {
+		return internalError(phaseWrite, err)
+	}
+
+	fmt.Fprintln(out, string(data))
+
+	return nil
+}
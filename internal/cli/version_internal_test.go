@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestVersionJSON(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, stderr := runCLIWithInput(t, "", "version", "--json")
+	if code != 0 {
+		t.Fatalf("version --json exited %d: %s", code, stderr)
+	}
+
+	var report versionReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("version --json printed invalid JSON: %v\n%s", err, stdout)
+	}
+
+	if report.FormatVersion != versionFormatVersion || report.Version != "test" {
+		t.Errorf("unexpected report header: %+v", report)
+	}
+
+	features := report.Features
+	if features.SchemaFormatVersion == "" {
+		t.Error("schema format version is missing")
+	}
+
+	if !slices.Contains(features.OutputFormats, generator.OutputFormatGoose) {
+		t.Errorf("output formats miss goose: %v", features.OutputFormats)
+	}
+
+	if !slices.Contains(features.ChangeTypes, differ.ChangeTypeModifyCustomType) ||
+		len(features.ChangeTypes) != len(differ.ChangeTypes()) {
+		t.Errorf("change types do not match the differ's: %v", features.ChangeTypes)
+	}
+
+	if !slices.Contains(features.CompatFeatures, "declarative_partitioning") {
+		t.Errorf("compat features miss declarative_partitioning: %v", features.CompatFeatures)
+	}
+}
+
+func TestVersionText(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, _ := runCLIWithInput(t, "", "version")
+	if code != 0 || !strings.HasPrefix(stdout, "pgtofu test\n  commit:") {
+		t.Fatalf("version exited %d and printed:\n%s", code, stdout)
+	}
+}
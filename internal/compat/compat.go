@@ -0,0 +1,182 @@
+// Package compat reports which server versions the objects of a schema need.
+// Each feature of the registry pairs the minimum PostgreSQL or TimescaleDB
+// version with a function that finds its uses in a schema.Database.
+package compat
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// Feature is a schema feature that needs a minimum server version.
+type Feature struct {
+	// Key identifies the feature in reports, such as "generated_columns".
+	Key         string
+	Description string
+	// MinPostgres is the first PostgreSQL major version with the feature.
+	MinPostgres int
+	// MinTimescale is the first TimescaleDB version with the feature, as
+	// major.minor, or empty for a PostgreSQL feature.
+	MinTimescale string
+	// Detect returns the objects of db that use the feature.
+	Detect func(db *schema.Database) []Use
+}
+
+// Use is an object that uses a feature.
+type Use struct {
+	// Object names the object with its kind, such as "column app.orders.total".
+	Object string
+	Source *schema.SourceLocation
+}
+
+// Target is the server a schema has to run on. An empty TimescaleDB version
+// leaves TimescaleDB features unchecked.
+type Target struct {
+	Postgres    int
+	TimescaleDB string
+}
+
+// Finding is a use of a feature in the checked schema.
+type Finding struct {
+	Feature      string `json:"feature"`
+	Description  string `json:"description"`
+	MinPostgres  int    `json:"min_postgres,omitempty"`
+	MinTimescale string `json:"min_timescaledb,omitempty"`
+	Object       string `json:"object"`
+	File         string `json:"file,omitempty"`
+	Line         int    `json:"line,omitempty"`
+	// Incompatible is set when the target is older than the feature.
+	Incompatible bool `json:"incompatible"`
+}
+
+// Location is where the object was declared, or an empty string.
+func (f *Finding) Location() string {
+	if f.File == "" {
+		return ""
+	}
+
+	return schema.SourceLocation{File: f.File, Line: f.Line}.String()
+}
+
+// Report lists the features a schema uses, ordered by the versions they need:
+// PostgreSQL features first, then TimescaleDB features.
+type Report struct {
+	Postgres    int       `json:"postgres"`
+	TimescaleDB string    `json:"timescaledb,omitempty"`
+	Findings    []Finding `json:"findings"`
+}
+
+// Incompatible returns the findings the target is too old for.
+func (r *Report) Incompatible() []Finding {
+	var incompatible []Finding
+
+	for _, finding := range r.Findings {
+		if finding.Incompatible {
+			incompatible = append(incompatible, finding)
+		}
+	}
+
+	return incompatible
+}
+
+// Check finds every use of the features of the registry in db and marks those
+// the target does not support.
+func Check(db *schema.Database, target Target) *Report {
+	return CheckFeatures(db, target, Features())
+}
+
+// CheckFeatures is Check with the given registry.
+func CheckFeatures(db *schema.Database, target Target, features []Feature) *Report {
+	report := &Report{
+		Postgres:    target.Postgres,
+		TimescaleDB: target.TimescaleDB,
+		Findings:    []Finding{},
+	}
+
+	for _, feature := range features {
+		for _, use := range feature.Detect(db) {
+			finding := Finding{
+				Feature:      feature.Key,
+				Description:  feature.Description,
+				MinPostgres:  feature.MinPostgres,
+				MinTimescale: feature.MinTimescale,
+				Object:       use.Object,
+				Incompatible: feature.MinPostgres > target.Postgres ||
+					(feature.MinTimescale != "" && target.TimescaleDB != "" &&
+						compareVersions(feature.MinTimescale, target.TimescaleDB) > 0),
+			}
+
+			if use.Source != nil {
+				finding.File = use.Source.File
+				finding.Line = use.Source.Line
+			}
+
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(product(&a), product(&b)),
+			cmp.Compare(a.MinPostgres, b.MinPostgres),
+			compareVersions(a.MinTimescale, b.MinTimescale),
+			cmp.Compare(a.Feature, b.Feature),
+			cmp.Compare(a.Object, b.Object),
+		)
+	})
+
+	return report
+}
+
+// product orders PostgreSQL findings before TimescaleDB ones.
+func product(f *Finding) int {
+	if f.MinTimescale != "" {
+		return 1
+	}
+
+	return 0
+}
+
+// ParseTimescaleVersion checks a TimescaleDB version such as "2.9" or
+// "2.14.2" and returns it unchanged.
+func ParseTimescaleVersion(version string) (string, error) {
+	if _, err := versionParts(version); err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// compareVersions orders dotted versions numerically. An empty version comes
+// first.
+func compareVersions(a, b string) int {
+	partsA, _ := versionParts(a)
+	partsB, _ := versionParts(b)
+
+	return slices.Compare(partsA, partsB)
+}
+
+func versionParts(version string) ([]int, error) {
+	if version == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(version, ".")
+	parts := make([]int, 0, len(fields))
+
+	for _, field := range fields {
+		part, err := strconv.Atoi(field)
+		if err != nil || part < 0 {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
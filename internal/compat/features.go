@@ -0,0 +1,338 @@
+package compat
+
+import (
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// Features returns the registry, oldest feature first. A new feature is a new
+// entry with its detection function.
+func Features() []Feature {
+	return []Feature{
+		{
+			Key:         "declarative_partitioning",
+			Description: "PARTITION BY and PARTITION OF",
+			MinPostgres: 10,
+			Detect:      detectPartitionedTables,
+		},
+		{
+			Key:         "identity_columns",
+			Description: "GENERATED ... AS IDENTITY columns",
+			MinPostgres: 10,
+			Detect:      detectIdentityColumns,
+		},
+		{
+			Key:         "hash_partitioning",
+			Description: "PARTITION BY HASH",
+			MinPostgres: 11,
+			Detect:      detectHashPartitioning,
+		},
+		{
+			Key:         "partitioned_table_keys",
+			Description: "primary keys and unique constraints on partitioned tables",
+			MinPostgres: 11,
+			Detect:      detectPartitionedTableKeys,
+		},
+		{
+			Key:         "covering_indexes",
+			Description: "CREATE INDEX ... INCLUDE",
+			MinPostgres: 11,
+			Detect:      detectCoveringIndexes,
+		},
+		{
+			Key:         "execute_function_triggers",
+			Description: "CREATE TRIGGER ... EXECUTE FUNCTION",
+			MinPostgres: 11,
+			Detect:      detectTriggers,
+		},
+		{
+			Key:         "generated_columns",
+			Description: "GENERATED ALWAYS AS (...) STORED columns",
+			MinPostgres: 12,
+			Detect:      detectGeneratedColumns,
+		},
+		{
+			Key:         "foreign_keys_to_partitioned_tables",
+			Description: "foreign keys referencing a partitioned table",
+			MinPostgres: 12,
+			Detect:      detectForeignKeysToPartitionedTables,
+		},
+		{
+			Key:         "column_compression",
+			Description: "COMPRESSION and SET COMPRESSION on columns",
+			MinPostgres: 14,
+			Detect:      detectColumnCompression,
+		},
+		{
+			Key:         "multirange_types",
+			Description: "multirange column types",
+			MinPostgres: 14,
+			Detect:      detectMultirangeColumns,
+		},
+		{
+			Key:         "nulls_not_distinct",
+			Description: "unique indexes with NULLS NOT DISTINCT",
+			MinPostgres: 15,
+			Detect:      detectNullsNotDistinct,
+		},
+		{
+			Key:          "hypertable_compression",
+			Description:  "native compression of hypertables",
+			MinTimescale: "1.5",
+			Detect:       detectHypertableCompression,
+		},
+		{
+			Key:          "continuous_aggregates",
+			Description:  "CREATE MATERIALIZED VIEW ... WITH (timescaledb.continuous)",
+			MinTimescale: "2.0",
+			Detect:       detectContinuousAggregates,
+		},
+		{
+			Key:          "continuous_aggregate_compression",
+			Description:  "compression of continuous aggregates",
+			MinTimescale: "2.6",
+			Detect:       detectContinuousAggregateCompression,
+		},
+		{
+			Key:          "hierarchical_continuous_aggregates",
+			Description:  "continuous aggregates on continuous aggregates",
+			MinTimescale: "2.9",
+			Detect:       detectHierarchicalContinuousAggregates,
+		},
+	}
+}
+
+func detectPartitionedTables(db *schema.Database) []Use {
+	return tableUses(db, func(table *schema.Table) bool {
+		return table.PartitionStrategy != nil
+	})
+}
+
+func detectHashPartitioning(db *schema.Database) []Use {
+	return tableUses(db, func(table *schema.Table) bool {
+		return table.PartitionStrategy != nil &&
+			strings.EqualFold(table.PartitionStrategy.Type, "HASH")
+	})
+}
+
+func detectPartitionedTableKeys(db *schema.Database) []Use {
+	return constraintUses(db, func(table *schema.Table, constraint *schema.Constraint) bool {
+		return table.PartitionStrategy != nil &&
+			(constraint.IsPrimaryKey() || constraint.IsUnique())
+	})
+}
+
+func detectForeignKeysToPartitionedTables(db *schema.Database) []Use {
+	return constraintUses(db, func(table *schema.Table, constraint *schema.Constraint) bool {
+		if !constraint.IsForeignKey() {
+			return false
+		}
+
+		referencedSchema := constraint.ReferencedSchema
+		if referencedSchema == "" {
+			referencedSchema = table.Schema
+		}
+
+		referenced := db.GetTable(referencedSchema, constraint.ReferencedTable)
+
+		return referenced != nil && referenced.PartitionStrategy != nil
+	})
+}
+
+func detectIdentityColumns(db *schema.Database) []Use {
+	return columnUses(db, func(col *schema.Column) bool { return col.Identity != nil })
+}
+
+func detectGeneratedColumns(db *schema.Database) []Use {
+	return columnUses(db, func(col *schema.Column) bool { return col.IsGenerated })
+}
+
+func detectColumnCompression(db *schema.Database) []Use {
+	return columnUses(db, func(col *schema.Column) bool { return col.Compression != "" })
+}
+
+func detectMultirangeColumns(db *schema.Database) []Use {
+	return columnUses(db, func(col *schema.Column) bool {
+		return strings.Contains(strings.ToLower(col.DataType), "multirange")
+	})
+}
+
+func detectCoveringIndexes(db *schema.Database) []Use {
+	return indexUses(db, func(idx *schema.Index) bool { return len(idx.IncludeColumns) > 0 })
+}
+
+func detectNullsNotDistinct(db *schema.Database) []Use {
+	return indexUses(db, func(idx *schema.Index) bool { return idx.NullsNotDistinct })
+}
+
+// detectTriggers finds every trigger: the generator writes EXECUTE FUNCTION,
+// which replaced EXECUTE PROCEDURE.
+func detectTriggers(db *schema.Database) []Use {
+	uses := make([]Use, 0, len(db.Triggers))
+
+	for i := range db.Triggers {
+		trigger := &db.Triggers[i]
+		uses = append(uses, Use{
+			Object: "trigger " + trigger.Name + " on " + trigger.QualifiedTableName(),
+			Source: trigger.Source,
+		})
+	}
+
+	return uses
+}
+
+func detectHypertableCompression(db *schema.Database) []Use {
+	var uses []Use
+
+	for i := range db.Hypertables {
+		ht := &db.Hypertables[i]
+		if ht.CompressionEnabled || ht.CompressionPolicy != nil {
+			uses = append(uses, Use{
+				Object: "hypertable " + ht.QualifiedTableName(),
+				Source: tableSource(db, ht.Schema, ht.TableName),
+			})
+		}
+	}
+
+	return uses
+}
+
+func detectContinuousAggregates(db *schema.Database) []Use {
+	return aggregateUses(db, func(*schema.ContinuousAggregate) bool { return true })
+}
+
+func detectContinuousAggregateCompression(db *schema.Database) []Use {
+	return aggregateUses(db, func(ca *schema.ContinuousAggregate) bool {
+		return ca.CompressionEnabled || ca.CompressionPolicy != nil
+	})
+}
+
+// detectHierarchicalContinuousAggregates finds the continuous aggregates
+// whose source is another continuous aggregate rather than a hypertable.
+func detectHierarchicalContinuousAggregates(db *schema.Database) []Use {
+	aggregates := make(map[string]bool, len(db.ContinuousAggregates))
+	for i := range db.ContinuousAggregates {
+		aggregates[strings.ToLower(db.ContinuousAggregates[i].QualifiedViewName())] = true
+	}
+
+	return aggregateUses(db, func(ca *schema.ContinuousAggregate) bool {
+		return aggregates[strings.ToLower(ca.QualifiedHypertableName())]
+	})
+}
+
+func tableUses(db *schema.Database, uses func(*schema.Table) bool) []Use {
+	var found []Use
+
+	for i := range db.Tables {
+		if table := &db.Tables[i]; uses(table) {
+			found = append(found, Use{
+				Object: "table " + table.QualifiedName(),
+				Source: table.Source,
+			})
+		}
+	}
+
+	return found
+}
+
+func columnUses(db *schema.Database, uses func(*schema.Column) bool) []Use {
+	var found []Use
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+
+		for j := range table.Columns {
+			col := &table.Columns[j]
+			if !uses(col) {
+				continue
+			}
+
+			source := col.Source
+			if source == nil {
+				source = table.Source
+			}
+
+			found = append(found, Use{
+				Object: "column " + table.QualifiedName() + "." + col.Name,
+				Source: source,
+			})
+		}
+	}
+
+	return found
+}
+
+func constraintUses(
+	db *schema.Database,
+	uses func(*schema.Table, *schema.Constraint) bool,
+) []Use {
+	var found []Use
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+
+		for j := range table.Constraints {
+			if constraint := &table.Constraints[j]; uses(table, constraint) {
+				found = append(found, Use{
+					Object: "constraint " + constraint.Name + " on " + table.QualifiedName(),
+					Source: table.Source,
+				})
+			}
+		}
+	}
+
+	return found
+}
+
+// indexUses finds the matching indexes of tables, materialized views and
+// continuous aggregates.
+func indexUses(db *schema.Database, uses func(*schema.Index) bool) []Use {
+	var found []Use
+
+	add := func(indexes []schema.Index) {
+		for i := range indexes {
+			if idx := &indexes[i]; uses(idx) {
+				found = append(found, Use{
+					Object: "index " + idx.QualifiedName(),
+					Source: idx.Source,
+				})
+			}
+		}
+	}
+
+	for i := range db.Tables {
+		add(db.Tables[i].Indexes)
+	}
+
+	for i := range db.MaterializedViews {
+		add(db.MaterializedViews[i].Indexes)
+	}
+
+	for i := range db.ContinuousAggregates {
+		add(db.ContinuousAggregates[i].Indexes)
+	}
+
+	return found
+}
+
+func aggregateUses(db *schema.Database, uses func(*schema.ContinuousAggregate) bool) []Use {
+	var found []Use
+
+	for i := range db.ContinuousAggregates {
+		if ca := &db.ContinuousAggregates[i]; uses(ca) {
+			found = append(found, Use{Object: "continuous aggregate " + ca.QualifiedViewName()})
+		}
+	}
+
+	return found
+}
+
+// tableSource is where the named table was declared, if it was.
+func tableSource(db *schema.Database, schemaName, tableName string) *schema.SourceLocation {
+	if table := db.GetTable(schemaName, tableName); table != nil {
+		return table.Source
+	}
+
+	return nil
+}
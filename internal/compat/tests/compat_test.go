@@ -0,0 +1,301 @@
+package compat_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/compat"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseSchema(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(sql, db))
+
+	return db
+}
+
+// featureObjects returns the objects reported for the feature.
+func featureObjects(report *compat.Report, key string) []string {
+	var objects []string
+
+	for _, finding := range report.Findings {
+		if finding.Feature == key {
+			objects = append(objects, finding.Object)
+		}
+	}
+
+	return objects
+}
+
+func TestCheck_DetectsFeatures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sql     string
+		feature string
+		want    []string
+	}{
+		{
+			name: "declarative partitioning",
+			sql: `CREATE TABLE events (id BIGINT, created_at DATE) PARTITION BY RANGE (created_at);
+CREATE TABLE plain (id BIGINT);`,
+			feature: "declarative_partitioning",
+			want:    []string{"table public.events"},
+		},
+		{
+			name: "hash partitioning",
+			sql: `CREATE TABLE events (id BIGINT) PARTITION BY HASH (id);
+CREATE TABLE ranged (id BIGINT) PARTITION BY RANGE (id);`,
+			feature: "hash_partitioning",
+			want:    []string{"table public.events"},
+		},
+		{
+			name: "keys of partitioned tables",
+			sql: `CREATE TABLE events (id BIGINT, kind TEXT, PRIMARY KEY (id, kind))
+    PARTITION BY LIST (kind);
+CREATE TABLE plain (id BIGINT PRIMARY KEY);`,
+			feature: "partitioned_table_keys",
+			want:    []string{"constraint events_pkey on public.events"},
+		},
+		{
+			name: "covering index",
+			sql: `CREATE TABLE orders (id BIGINT, total NUMERIC);
+CREATE INDEX orders_id_idx ON orders (id) INCLUDE (total);
+CREATE INDEX orders_total_idx ON orders (total);`,
+			feature: "covering_indexes",
+			want:    []string{"index public.orders_id_idx"},
+		},
+		{
+			name: "trigger",
+			sql: `CREATE TABLE orders (id BIGINT);
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END $$;
+CREATE TRIGGER orders_touch BEFORE UPDATE ON orders
+    FOR EACH ROW EXECUTE FUNCTION touch();`,
+			feature: "execute_function_triggers",
+			want:    []string{"trigger orders_touch on public.orders"},
+		},
+		{
+			name: "foreign key to partitioned table",
+			sql: `CREATE TABLE accounts (id BIGINT PRIMARY KEY) PARTITION BY HASH (id);
+CREATE TABLE users (id BIGINT PRIMARY KEY);
+CREATE TABLE orders (
+    id BIGINT,
+    account_id BIGINT REFERENCES accounts (id),
+    user_id BIGINT REFERENCES users (id)
+);`,
+			feature: "foreign_keys_to_partitioned_tables",
+			want:    []string{"constraint orders_account_id_fkey on public.orders"},
+		},
+		{
+			name:    "column compression",
+			sql:     `CREATE TABLE docs (id BIGINT, body TEXT COMPRESSION lz4, title TEXT);`,
+			feature: "column_compression",
+			want:    []string{"column public.docs.body"},
+		},
+		{
+			name:    "multirange column",
+			sql:     `CREATE TABLE rooms (id BIGINT, booked tstzmultirange, span tstzrange);`,
+			feature: "multirange_types",
+			want:    []string{"column public.rooms.booked"},
+		},
+		{
+			name: "nulls not distinct",
+			sql: `CREATE TABLE users (email TEXT, phone TEXT);
+CREATE UNIQUE INDEX users_email_key ON users (email) NULLS NOT DISTINCT;
+CREATE UNIQUE INDEX users_phone_key ON users (phone);`,
+			feature: "nulls_not_distinct",
+			want:    []string{"index public.users_email_key"},
+		},
+		{
+			name: "hypertable compression",
+			sql: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+ALTER TABLE metrics SET (timescaledb.compress);
+CREATE TABLE readings (time TIMESTAMPTZ NOT NULL);
+SELECT create_hypertable('readings', 'time');`,
+			feature: "hypertable_compression",
+			want:    []string{"hypertable public.metrics"},
+		},
+		{
+			name: "continuous aggregate",
+			sql: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) FROM metrics GROUP BY 1;`,
+			feature: "continuous_aggregates",
+			want:    []string{"continuous aggregate public.metrics_hourly"},
+		},
+		{
+			name: "continuous aggregate compression",
+			sql: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) FROM metrics GROUP BY 1;
+ALTER MATERIALIZED VIEW metrics_hourly SET (timescaledb.compress = true);`,
+			feature: "continuous_aggregate_compression",
+			want:    []string{"continuous aggregate public.metrics_hourly"},
+		},
+		{
+			name: "hierarchical continuous aggregate",
+			sql: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) AS value FROM metrics GROUP BY 1;
+CREATE MATERIALIZED VIEW metrics_daily WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', bucket) AS bucket, avg(value) FROM metrics_hourly GROUP BY 1;`,
+			feature: "hierarchical_continuous_aggregates",
+			want:    []string{"continuous aggregate public.metrics_daily"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := compat.Check(parseSchema(t, tt.sql), compat.Target{Postgres: 17})
+			assert.Equal(t, tt.want, featureObjects(report, tt.feature))
+		})
+	}
+}
+
+func TestCheck_DetectsColumnKinds(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{
+		Tables: []schema.Table{{
+			Schema: "public",
+			Name:   "orders",
+			Columns: []schema.Column{
+				{
+					Name:     "id",
+					DataType: "bigint",
+					Identity: schema.NewIdentity(schema.IdentityAlways, "bigint"),
+				},
+				{Name: "total", DataType: "numeric"},
+				{
+					Name:                 "total_cents",
+					DataType:             "bigint",
+					IsGenerated:          true,
+					GenerationExpression: "(total * 100)::bigint",
+				},
+			},
+		}},
+	}
+
+	report := compat.Check(db, compat.Target{Postgres: 17})
+
+	assert.Equal(t, []string{"column public.orders.id"}, featureObjects(report, "identity_columns"))
+	assert.Equal(t, []string{"column public.orders.total_cents"},
+		featureObjects(report, "generated_columns"))
+}
+
+func TestCheck_FlagsFeaturesNewerThanTarget(t *testing.T) {
+	t.Parallel()
+
+	db := parseSchema(t, `
+CREATE TABLE docs (
+    id BIGINT PRIMARY KEY,
+    body TEXT COMPRESSION lz4
+) PARTITION BY HASH (id);
+CREATE UNIQUE INDEX docs_body_key ON docs (body) NULLS NOT DISTINCT;
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) AS value FROM metrics GROUP BY 1;
+CREATE MATERIALIZED VIEW metrics_daily WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', bucket) AS bucket, avg(value) FROM metrics_hourly GROUP BY 1;
+`)
+
+	report := compat.Check(db, compat.Target{Postgres: 14, TimescaleDB: "2.7"})
+
+	var incompatible []string
+	for _, finding := range report.Incompatible() {
+		incompatible = append(incompatible, finding.Feature)
+	}
+
+	assert.Equal(t, []string{"nulls_not_distinct", "hierarchical_continuous_aggregates"},
+		incompatible)
+
+	var order []string
+	for _, finding := range report.Findings {
+		order = append(order, finding.Feature)
+	}
+
+	assert.Equal(t, []string{
+		"declarative_partitioning",
+		"hash_partitioning",
+		"partitioned_table_keys",
+		"column_compression",
+		"nulls_not_distinct",
+		"continuous_aggregates",
+		"continuous_aggregates",
+		"hierarchical_continuous_aggregates",
+	}, order)
+
+	assert.Equal(t, 15, report.Incompatible()[0].MinPostgres)
+}
+
+func TestCheck_TimescaleFeaturesUncheckedWithoutVersion(t *testing.T) {
+	t.Parallel()
+
+	db := parseSchema(t, `
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) FROM metrics GROUP BY 1;
+`)
+
+	report := compat.Check(db, compat.Target{Postgres: 12})
+
+	assert.Len(t, report.Findings, 1)
+	assert.Empty(t, report.Incompatible())
+
+	report = compat.Check(db, compat.Target{Postgres: 12, TimescaleDB: "1.7"})
+	assert.Len(t, report.Incompatible(), 1)
+}
+
+func TestCheckFeatures_UsesGivenRegistry(t *testing.T) {
+	t.Parallel()
+
+	db := parseSchema(t, `CREATE TABLE orders (id BIGINT);`)
+	features := []compat.Feature{{
+		Key:         "everything",
+		Description: "every table",
+		MinPostgres: 99,
+		Detect: func(db *schema.Database) []compat.Use {
+			return []compat.Use{{Object: "table " + db.Tables[0].QualifiedName()}}
+		},
+	}}
+
+	report := compat.CheckFeatures(db, compat.Target{Postgres: 17}, features)
+
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, compat.Finding{
+		Feature:      "everything",
+		Description:  "every table",
+		MinPostgres:  99,
+		Object:       "table public.orders",
+		Incompatible: true,
+	}, report.Findings[0])
+}
+
+func TestParseTimescaleVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{"", "2", "2.9", "2.14.2"} {
+		_, err := compat.ParseTimescaleVersion(version)
+		require.NoError(t, err, version)
+	}
+
+	for _, version := range []string{"v2.9", "2.x", "2..9", "-1"} {
+		_, err := compat.ParseTimescaleVersion(version)
+		require.Error(t, err, version)
+	}
+}
@@ -0,0 +1,233 @@
+// Package diag defines the warnings reported by the parser, differ and
+// generator. Each warning carries a stable Code so tooling can act on it
+// without matching message text, which may change between releases.
+package diag
+
+import "fmt"
+
+// Code identifies the kind of a warning. Codes do not change between releases.
+type Code string
+
+// Parser warnings.
+const (
+	// CodeDuplicateDefinition is a table declared twice with an identical
+	// definition, in which case the second declaration is ignored, or an index
+	// declared twice on the same table, in which case the second replaces the
+	// first.
+	CodeDuplicateDefinition Code = "DUPLICATE_DEFINITION"
+	// CodeObjectNotFound is a statement that targets an object, such as a
+	// COMMENT ON or CREATE INDEX, whose object was never declared.
+	CodeObjectNotFound Code = "OBJECT_NOT_FOUND"
+	// CodeSkippedStatement is a statement the parser does not support.
+	CodeSkippedStatement Code = "SKIPPED_STATEMENT"
+	// CodeSkippedDefinition is a column or constraint inside CREATE TABLE that
+	// could not be parsed and was left out of the table.
+	CodeSkippedDefinition Code = "SKIPPED_DEFINITION"
+	// CodeProceduralPartitioning is a DO block that creates partitions, which
+	// the parser cannot see into.
+	CodeProceduralPartitioning Code = "PROCEDURAL_PARTITIONING"
+	// CodeParseError is a statement that could not be parsed. It is reported
+	// with SeverityError, and the command that parsed it fails.
+	CodeParseError Code = "PARSE_ERROR"
+	// CodeInvalidInterval is a TimescaleDB interval setting of the desired
+	// schema that PostgreSQL would reject, such as INTERVAL '1 dya'. It is
+	// reported with SeverityError, and the command fails.
+	CodeInvalidInterval Code = "INVALID_INTERVAL"
+	// CodeInvalidEnumLiteral is a default or CHECK constraint of the desired
+	// schema that compares a column of an enum type with a value the enum
+	// does not declare. It is reported with SeverityError, and the command
+	// fails.
+	CodeInvalidEnumLiteral Code = "INVALID_ENUM_LITERAL"
+	// CodeInvalidTriggerFunction is a trigger of the desired schema that
+	// executes a function returning something other than trigger. It is
+	// reported with SeverityError, and the command fails.
+	CodeInvalidTriggerFunction Code = "INVALID_TRIGGER_FUNCTION"
+	// CodeInvalidTypedTableColumn is a column of a typed table of the desired
+	// schema that its composite type does not have. It is reported with
+	// SeverityError, and the command fails.
+	CodeInvalidTypedTableColumn Code = "INVALID_TYPED_TABLE_COLUMN"
+	// CodeOperationalStatement is a statement that acts on data rather than
+	// declaring schema, such as REFRESH MATERIALIZED VIEW, and is ignored. A
+	// run reports all of them in one warning with SeverityInfo.
+	CodeOperationalStatement Code = "OPERATIONAL_STATEMENT"
+	// CodeIdentifierTooLong is a name longer than the 63 bytes PostgreSQL
+	// keeps of an identifier, which the server cuts without an error.
+	CodeIdentifierTooLong Code = "IDENTIFIER_TOO_LONG"
+)
+
+// Differ warnings.
+const (
+	// CodeHypertableIntervalChange is a changed chunk interval, which only
+	// applies to new chunks unless the hypertable is recreated.
+	CodeHypertableIntervalChange Code = "HYPERTABLE_INTERVAL_CHANGE"
+	// CodeOverlappingUniqueness is a unique partial index on the same columns
+	// as a UNIQUE constraint, which makes its predicate moot.
+	CodeOverlappingUniqueness Code = "OVERLAPPING_UNIQUENESS"
+	// CodePinnedDependency is a change pulled into a pinned migration because
+	// a change pinned to it depends on it.
+	CodePinnedDependency Code = "PINNED_DEPENDENCY"
+	// CodeMatviewConcurrentRefreshHazard is a change that leaves a
+	// materialized view without its unique index for a while, during which
+	// REFRESH MATERIALIZED VIEW CONCURRENTLY fails.
+	CodeMatviewConcurrentRefreshHazard Code = "MATVIEW_CONCURRENT_REFRESH_HAZARD"
+	// CodeRemoteAccess is a change to a view that queries another server
+	// through dblink or postgres_fdw, whose remote side pgtofu cannot check.
+	// It is reported with SeverityInfo.
+	CodeRemoteAccess Code = "REMOTE_ACCESS"
+	// CodePlanTooLarge is a plan with more changes, destructive changes or
+	// touched tables than the configured limit, which is hard to review in
+	// one go. It never blocks generation.
+	CodePlanTooLarge Code = "PLAN_TOO_LARGE"
+	// CodeFunctionColumnReference is a dropped or retyped column that a
+	// function body appears to use, found by the heuristic search of
+	// AnalyzeFunctionBodies. It never blocks generation.
+	CodeFunctionColumnReference Code = "FUNCTION_COLUMN_REFERENCE"
+	// CodeComparisonCache is a comparison cache file that could not be read,
+	// in which case every object is compared, or written back, in which case
+	// the next run compares every object.
+	CodeComparisonCache Code = "COMPARISON_CACHE"
+	// CodeAmbiguousColumnRename is a column dropped from a table that, with
+	// DetectColumnRenames, could have been renamed to more than one added
+	// column, or a column added that more than one dropped column matches. It
+	// is dropped and added, losing its data.
+	CodeAmbiguousColumnRename Code = "AMBIGUOUS_COLUMN_RENAME"
+	// CodeOutsideTargetSchemas is an object the desired schema declares in a
+	// schema outside the TargetSchemas allowlist. It is left out of the
+	// comparison, so it is neither created nor changed.
+	CodeOutsideTargetSchemas Code = "OUTSIDE_TARGET_SCHEMAS"
+	// CodeMissingGrantee is a role the desired grants name that the database
+	// does not have. Its grants are left out of the plan rather than failing
+	// the migration.
+	CodeMissingGrantee Code = "MISSING_GRANTEE"
+	// CodePartitionBoundsChange is a partition whose FOR VALUES bounds
+	// changed. PostgreSQL cannot change them in place, so the partition is
+	// dropped, or detached, and created again with the new bounds.
+	CodePartitionBoundsChange Code = "PARTITION_BOUNDS_CHANGE"
+)
+
+// Generator warnings.
+const (
+	// CodeNoChanges is reported when there is nothing to generate.
+	CodeNoChanges Code = "NO_CHANGES"
+	// CodeEmptyMigration is a batch of changes none of which needs an up
+	// statement, such as hypertable settings of a table the plan drops, so
+	// no migration is written for it. It is reported with SeverityInfo.
+	CodeEmptyMigration Code = "EMPTY_MIGRATION"
+	// CodeEmptyStatement is a statement a builder returned with an empty
+	// statement in it, such as a doubled semicolon, which generation removed.
+	// It is reported with SeverityInfo and names the builder.
+	CodeEmptyStatement Code = "EMPTY_STATEMENT"
+	// CodeUnsafeOperation is an up statement that may lose data or block.
+	CodeUnsafeOperation Code = "UNSAFE_OPERATION"
+	// CodeUnsafeRollback is a down statement that may lose data or block.
+	CodeUnsafeRollback Code = "UNSAFE_ROLLBACK"
+	// CodeManualRollbackRequired is a down statement that cannot restore the
+	// previous state and has to be written by hand.
+	CodeManualRollbackRequired Code = "MANUAL_ROLLBACK_REQUIRED"
+	// CodeBuildUpFailed is a change whose up statement could not be built;
+	// it is missing from the migration.
+	CodeBuildUpFailed Code = "BUILD_UP_FAILED"
+	// CodeBuildDownFailed is a change whose down statement could not be
+	// built; the down migration holds a manual rollback placeholder instead.
+	CodeBuildDownFailed Code = "BUILD_DOWN_FAILED"
+	// CodeCrossSchemaDependency is a migration that depends on a migration of
+	// another schema when migrations are written to per-schema directories,
+	// which separate pipelines may apply in either order.
+	CodeCrossSchemaDependency Code = "CROSS_SCHEMA_DEPENDENCY"
+	// CodeOversizedMigration is a migration with more changes than
+	// MaxOperationsPerFile, kept in one file because a change before every
+	// possible cut depends on an object created after it.
+	CodeOversizedMigration Code = "OVERSIZED_MIGRATION"
+	// CodeDropBlockedByDependent is an object of the current schema that
+	// depends on an object dropped without CASCADE and is neither dropped nor
+	// changed by the plan. It is reported with SeverityError, and generation
+	// fails.
+	CodeDropBlockedByDependent Code = "DROP_BLOCKED_BY_DEPENDENT"
+	// CodeLockNotAllowed is an up statement taking a lock at least as strong
+	// as the one generation was asked to fail on, on an object no allowlist
+	// pattern matches. It is reported with SeverityError, and generation fails.
+	CodeLockNotAllowed Code = "LOCK_NOT_ALLOWED"
+)
+
+// Compatibility check warnings.
+const (
+	// CodeIncompatibleFeature is an object of the desired schema that uses a
+	// feature the target PostgreSQL or TimescaleDB version of check-compat
+	// does not have. It is reported with SeverityError, and the command fails.
+	CodeIncompatibleFeature Code = "INCOMPATIBLE_FEATURE"
+)
+
+// Lint warnings. pgtofu lint reports them with SeverityError, and the command
+// fails.
+const (
+	// CodeDuplicateRelation is a name that two tables, views, sequences or
+	// indexes of the same schema share, although PostgreSQL keeps them in one
+	// namespace.
+	CodeDuplicateRelation Code = "DUPLICATE_RELATION"
+	// CodeDuplicateConstraint is a constraint name declared twice on a table.
+	CodeDuplicateConstraint Code = "DUPLICATE_CONSTRAINT"
+	// CodeMissingReferencedTable is a foreign key referencing a table the
+	// schema does not declare.
+	CodeMissingReferencedTable Code = "MISSING_REFERENCED_TABLE"
+	// CodeMissingTriggerFunction is a trigger executing a function the schema
+	// does not declare.
+	CodeMissingTriggerFunction Code = "MISSING_TRIGGER_FUNCTION"
+	// CodeMissingViewRelation is a view, materialized view or continuous
+	// aggregate reading a table or view the schema does not declare.
+	CodeMissingViewRelation Code = "MISSING_VIEW_RELATION"
+	// CodeMissingViewColumn is a view, materialized view or continuous
+	// aggregate reading a column its table does not have.
+	CodeMissingViewColumn Code = "MISSING_VIEW_COLUMN"
+)
+
+// Severity is how much attention a warning needs.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Warning is one diagnostic. ObjectName, ChangeType, File and Line are set
+// where they apply and are empty otherwise.
+type Warning struct {
+	Code       Code     `json:"code"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	ObjectName string   `json:"object_name,omitempty"`
+	ChangeType string   `json:"change_type,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Line       int      `json:"line,omitempty"`
+}
+
+// Location returns "file:line", "file" or an empty string.
+func (w Warning) Location() string {
+	switch {
+	case w.File == "":
+		return ""
+	case w.Line > 0:
+		return fmt.Sprintf("%s:%d", w.File, w.Line)
+	default:
+		return w.File
+	}
+}
+
+func (w Warning) String() string {
+	if location := w.Location(); location != "" {
+		return fmt.Sprintf("%s: [%s] %s", location, w.Code, w.Message)
+	}
+
+	return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+}
+
+// Messages renders warnings as their plain messages, the form of the
+// deprecated string warning slices.
+func Messages(warnings []Warning) []string {
+	messages := make([]string, len(warnings))
+	for i := range warnings {
+		messages[i] = warnings[i].Message
+	}
+
+	return messages
+}
@@ -1,40 +1,100 @@
 package differ
 
 import (
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 func (d *Differ) processContinuousAggregateRecreationForColumnChanges(result *DiffResult) {
-	tablesWithColumnChanges := d.findTablesWithColumnChangesAffectingCAs(result.Changes)
-	if len(tablesWithColumnChanges) == 0 {
+	columnChanges := columnChangesByTable(result.Changes,
+		ChangeTypeModifyColumnType, ChangeTypeModifyColumnNullability)
+	if len(columnChanges) == 0 {
 		return
 	}
 
-	d.processContinuousAggregatesForColumnChanges(result, tablesWithColumnChanges)
+	d.processContinuousAggregatesForColumnChanges(result, columnChanges)
 	d.filterDuplicateCAIndexChanges(result)
 }
 
-func (d *Differ) findTablesWithColumnChangesAffectingCAs(changes []Change) map[string]bool {
-	tables := make(map[string]bool)
+// processViewRecreationForContinuousAggregates drops and recreates the views
+// and materialized views that select from a continuous aggregate being
+// recreated, directly or through other views. Dropping the aggregate
+// cascades to them, so they must be recreated once it exists again.
+func (d *Differ) processViewRecreationForContinuousAggregates(result *DiffResult) {
+	recreated := recreatedContinuousAggregates(result.Changes)
+	if len(recreated) == 0 {
+		return
+	}
+
+	addDependentViews(result.Desired, recreated)
+
+	d.processViewsForTypeChanges(result, selectsFromAny(recreated),
+		causeContinuousAggregateRecreation)
+	d.processMaterializedViewsForTypeChanges(result, selectsFromAny(recreated),
+		causeContinuousAggregateRecreation)
+}
+
+func recreatedContinuousAggregates(changes []Change) map[string]bool {
+	aggregates := make(map[string]bool)
 
 	for _, change := range changes {
-		if change.Type == ChangeTypeModifyColumnType ||
-			change.Type == ChangeTypeModifyColumnNullability {
-			tableName, _ := change.Details["table"].(string)
-			if tableName != "" {
-				tables[strings.ToLower(tableName)] = true
-			}
+		willBeRecreated, _ := change.Details["will_be_recreated"].(bool)
+
+		if change.Type == ChangeTypeModifyContinuousAggregate ||
+			(change.Type == ChangeTypeDropContinuousAggregate && willBeRecreated) {
+			aggregates[change.ObjectName] = true
 		}
 	}
 
-	return tables
+	return aggregates
 }
 
+// addDependentViews adds every view and materialized view that selects from
+// an object in objects, until no more are found.
+func addDependentViews(db *schema.Database, objects map[string]bool) {
+	type view struct {
+		key        string
+		definition string
+	}
+
+	views := make([]view, 0, len(db.Views)+len(db.MaterializedViews))
+	for i := range db.Views {
+		views = append(views, view{
+			key:        ViewKey(db.Views[i].Schema, db.Views[i].Name),
+			definition: db.Views[i].Definition,
+		})
+	}
+
+	for i := range db.MaterializedViews {
+		views = append(views, view{
+			key:        ViewKey(db.MaterializedViews[i].Schema, db.MaterializedViews[i].Name),
+			definition: db.MaterializedViews[i].Definition,
+		})
+	}
+
+	for added := true; added; {
+		added = false
+
+		for _, v := range views {
+			if !objects[v.key] &&
+				viewDependsOnAnyTable(extractViewDependencies(v.definition), objects) {
+				objects[v.key] = true
+				added = true
+			}
+		}
+	}
+}
+
+// processContinuousAggregatesForColumnChanges drops and recreates the
+// continuous aggregates whose query reads a changed column of their
+// hypertable. An aggregate whose output merely has a column of the same name
+// is left alone.
 func (d *Differ) processContinuousAggregatesForColumnChanges(
 	result *DiffResult,
-	tablesWithColumnChanges map[string]bool,
+	columnChanges map[string][]columnRef,
 ) {
 	currentCAs := buildContinuousAggregateMap(result.Current.ContinuousAggregates)
 	desiredCAs := buildContinuousAggregateMap(result.Desired.ContinuousAggregates)
@@ -48,9 +108,20 @@ func (d *Differ) processContinuousAggregatesForColumnChanges(
 		}
 	}
 
-	for key, desiredCA := range desiredCAs {
-		hypertableName := desiredCA.QualifiedHypertableName()
-		if !caHypertableMatchesTables(hypertableName, tablesWithColumnChanges) {
+	for _, key := range slices.Sorted(maps.Keys(desiredCAs)) {
+		desiredCA := desiredCAs[key]
+
+		columns := tableColumnChanges(desiredCA.QualifiedHypertableName(), columnChanges)
+		if len(columns) == 0 {
+			continue
+		}
+
+		query := desiredCA.Query
+		if currentCA, exists := currentCAs[key]; exists {
+			query = currentCA.Query
+		}
+
+		if !queryUsesAnyColumn(query, columns) {
 			continue
 		}
 
@@ -66,30 +137,6 @@ func (d *Differ) processContinuousAggregatesForColumnChanges(
 	}
 }
 
-func caHypertableMatchesTables(hypertableName string, tables map[string]bool) bool {
-	hypertableLower := strings.ToLower(hypertableName)
-
-	if tables[hypertableLower] {
-		return true
-	}
-
-	parts := strings.Split(hypertableLower, ".")
-
-	if len(parts) == 2 && (parts[0] == schema.DefaultSchema || parts[0] == "public") {
-		if tables[parts[1]] {
-			return true
-		}
-	}
-
-	if len(parts) == 1 {
-		if tables["public."+hypertableLower] || tables[schema.DefaultSchema+"."+hypertableLower] {
-			return true
-		}
-	}
-
-	return false
-}
-
 func (d *Differ) convertModifyCAToDropAdd(
 	result *DiffResult,
 	idx int,
@@ -117,11 +164,12 @@ func (d *Differ) convertModifyCAToDropAdd(
 	}
 
 	result.Changes[idx] = Change{
-		Type:        ChangeTypeDropContinuousAggregate,
-		Severity:    SeverityBreaking,
-		Description: "Drop continuous aggregate for column change: " + currentCA.QualifiedViewName(),
-		ObjectType:  "continuous_aggregate",
-		ObjectName:  key,
+		Type:     ChangeTypeDropContinuousAggregate,
+		Severity: SeverityBreaking,
+		Description: describe(descDropForRecreation, "Continuous aggregate",
+			currentCA.QualifiedViewName(), "column change"),
+		ObjectType: "continuous_aggregate",
+		ObjectName: key,
 		Details: map[string]any{
 			"aggregate":         currentCA,
 			"for_column_change": true,
@@ -131,11 +179,12 @@ func (d *Differ) convertModifyCAToDropAdd(
 	}
 
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeAddContinuousAggregate,
-		Severity:    SeveritySafe,
-		Description: "Recreate continuous aggregate: " + desiredCA.QualifiedViewName(),
-		ObjectType:  "continuous_aggregate",
-		ObjectName:  key,
+		Type:     ChangeTypeAddContinuousAggregate,
+		Severity: SeveritySafe,
+		Description: describe(descRecreated, "Continuous aggregate",
+			desiredCA.QualifiedViewName(), "column change"),
+		ObjectType: "continuous_aggregate",
+		ObjectName: key,
 		Details: map[string]any{
 			"aggregate":         desiredCA,
 			"for_column_change": true,
@@ -151,11 +200,12 @@ func (d *Differ) addCARecreationChanges(
 	currentCA, desiredCA *schema.ContinuousAggregate,
 ) {
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeDropContinuousAggregate,
-		Severity:    SeverityBreaking,
-		Description: "Drop continuous aggregate for column change: " + currentCA.QualifiedViewName(),
-		ObjectType:  "continuous_aggregate",
-		ObjectName:  key,
+		Type:     ChangeTypeDropContinuousAggregate,
+		Severity: SeverityBreaking,
+		Description: describe(descDropForRecreation, "Continuous aggregate",
+			currentCA.QualifiedViewName(), "column change"),
+		ObjectType: "continuous_aggregate",
+		ObjectName: key,
 		Details: map[string]any{
 			"aggregate":         currentCA,
 			"for_column_change": true,
@@ -164,11 +214,12 @@ func (d *Differ) addCARecreationChanges(
 	})
 
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeAddContinuousAggregate,
-		Severity:    SeveritySafe,
-		Description: "Recreate continuous aggregate after column change: " + desiredCA.QualifiedViewName(),
-		ObjectType:  "continuous_aggregate",
-		ObjectName:  key,
+		Type:     ChangeTypeAddContinuousAggregate,
+		Severity: SeveritySafe,
+		Description: describe(descRecreated, "Continuous aggregate",
+			desiredCA.QualifiedViewName(), "column change"),
+		ObjectType: "continuous_aggregate",
+		ObjectName: key,
 		Details: map[string]any{
 			"aggregate":         desiredCA,
 			"for_column_change": true,
@@ -0,0 +1,296 @@
+package differ
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// ComparisonCacheFile is the file a ComparisonCache keeps in its directory.
+const ComparisonCacheFile = "comparisons.json"
+
+// ComparisonCache remembers, across runs, which pairs of current and desired
+// definitions of a table, view, materialized view, function or trigger were
+// compared and found equal, so later runs skip comparing them again. Pairs
+// that produce changes are compared on every run: their changes refer to the
+// parsed definitions, which only exist for the run that parsed them. Every
+// other pass, dependency resolution and ordering always run.
+//
+// A cache is only reused by the tool version that wrote it, and is emptied
+// when a Compare runs with options of a different Options.Hash. It is not
+// safe for concurrent use.
+type ComparisonCache struct {
+	path    string
+	version string
+	// optionsHash is the Options.Hash the entries were recorded with.
+	optionsHash string
+	// known holds the pairs read from the file, used the pairs this run found
+	// equal, which are all that Save writes back.
+	known map[string]bool
+	used  map[string]bool
+	// problem is why the file could not be read, reported by the next
+	// Compare.
+	problem string
+	hits    int
+	misses  int
+}
+
+type comparisonCacheFile struct {
+	Version     string   `json:"version"`
+	OptionsHash string   `json:"options_hash"`
+	Equal       []string `json:"equal"`
+}
+
+// OpenComparisonCache reads the cache kept in dir for the tool version. A
+// missing file, or one written by another version, starts an empty cache; an
+// unreadable one does too, and the next Compare reports it with a
+// COMPARISON_CACHE warning.
+func OpenComparisonCache(dir, version string) *ComparisonCache {
+	cache := &ComparisonCache{
+		path:    filepath.Join(dir, ComparisonCacheFile),
+		version: version,
+		known:   make(map[string]bool),
+		used:    make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			cache.problem = err.Error()
+		}
+
+		return cache
+	}
+
+	var file comparisonCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		cache.problem = fmt.Sprintf("%s: %v", cache.path, err)
+		return cache
+	}
+
+	if file.Version != version {
+		return cache
+	}
+
+	cache.optionsHash = file.OptionsHash
+	for _, pair := range file.Equal {
+		cache.known[pair] = true
+	}
+
+	return cache
+}
+
+// Save writes back the pairs the last Compare found equal, replacing the
+// file in one rename so an interrupted run never leaves it half written.
+func (c *ComparisonCache) Save() error {
+	pairs := make([]string, 0, len(c.used))
+	for pair := range c.used {
+		pairs = append(pairs, pair)
+	}
+
+	slices.Sort(pairs)
+
+	data, err := json.Marshal(comparisonCacheFile{
+		Version:     c.version,
+		OptionsHash: c.optionsHash,
+		Equal:       pairs,
+	})
+	if err != nil {
+		return util.WrapError("encoding comparison cache", err)
+	}
+
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return util.WrapError("creating cache directory", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ComparisonCacheFile+".*")
+	if err != nil {
+		return util.WrapError("creating comparison cache", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return util.WrapError("writing comparison cache", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return util.WrapError("writing comparison cache", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return util.WrapError("replacing comparison cache", err)
+	}
+
+	return nil
+}
+
+// Stats returns how many comparisons the last Compare reused from the cache
+// and how many it ran.
+func (c *ComparisonCache) Stats() (reused, compared int) {
+	return c.hits, c.misses
+}
+
+// Path is the cache file.
+func (c *ComparisonCache) Path() string {
+	return c.path
+}
+
+// begin prepares the cache for a Compare with the options hash, dropping
+// entries recorded under other options and reporting a file that could not
+// be read.
+func (c *ComparisonCache) begin(result *DiffResult) {
+	if c.optionsHash != result.OptionsHash {
+		c.known = make(map[string]bool)
+		c.optionsHash = result.OptionsHash
+	}
+
+	c.used = make(map[string]bool)
+	c.hits, c.misses = 0, 0
+
+	if c.problem != "" {
+		result.addWarning(diag.Warning{
+			Code:     diag.CodeComparisonCache,
+			Severity: diag.SeverityWarning,
+			Message: fmt.Sprintf(
+				"comparison cache is unreadable and was ignored (%s); every object is compared",
+				c.problem,
+			),
+		})
+
+		c.problem = ""
+	}
+}
+
+// compare runs compare, the comparison of two definitions of the object kind
+// and key, unless the cache holds the pair as equal. A pair whose comparison
+// adds no changes, warnings or notes is recorded as equal. A nil cache always
+// compares.
+func (c *ComparisonCache) compare(
+	result *DiffResult,
+	kind, key string,
+	current, desired any,
+	compare func(),
+) {
+	if c == nil {
+		compare()
+		return
+	}
+
+	pair, ok := comparisonPair(kind, key, current, desired)
+	if !ok {
+		compare()
+		return
+	}
+
+	if c.known[pair] {
+		c.used[pair] = true
+		c.hits++
+
+		return
+	}
+
+	c.misses++
+
+	changes, diagnostics := len(result.Changes), len(result.Diagnostics)
+	warnings, notes := len(result.Warnings), len(result.Notes)
+
+	compare()
+
+	if len(result.Changes) == changes && len(result.Diagnostics) == diagnostics &&
+		len(result.Warnings) == warnings && len(result.Notes) == notes {
+		c.used[pair] = true
+	}
+}
+
+// comparisonPair digests the kind, key and both definitions of an object.
+// Source locations are left out: they move whenever lines are added above a
+// definition, and comparisons never look at them.
+func comparisonPair(kind, key string, current, desired any) (string, bool) {
+	currentData, err := json.Marshal(withoutSources(current))
+	if err != nil {
+		return "", false
+	}
+
+	desiredData, err := json.Marshal(withoutSources(desired))
+	if err != nil {
+		return "", false
+	}
+
+	currentSum := sha256.Sum256(currentData)
+	desiredSum := sha256.Sum256(desiredData)
+
+	sum := sha256.Sum256([]byte(kind + "\x00" + key + "\x00" +
+		hex.EncodeToString(currentSum[:]) + "\x00" + hex.EncodeToString(desiredSum[:])))
+
+	return hex.EncodeToString(sum[:]), true
+}
+
+// withoutSources returns a copy of a cached definition with the source
+// locations of it and its columns, indexes and policies cleared.
+func withoutSources(object any) any {
+	switch o := object.(type) {
+	case *schema.Table:
+		table := *o
+		table.Source = nil
+
+		table.Columns = slices.Clone(o.Columns)
+		for i := range table.Columns {
+			table.Columns[i].Source = nil
+		}
+
+		table.Indexes = indexesWithoutSources(o.Indexes)
+
+		table.Policies = slices.Clone(o.Policies)
+		for i := range table.Policies {
+			table.Policies[i].Source = nil
+		}
+
+		return &table
+	case *schema.View:
+		view := *o
+		view.Source = nil
+
+		return &view
+	case *schema.MaterializedView:
+		view := *o
+		view.Source = nil
+		view.Indexes = indexesWithoutSources(o.Indexes)
+
+		return &view
+	case *schema.Function:
+		fn := *o
+		fn.Source = nil
+
+		return &fn
+	case *schema.Trigger:
+		trigger := *o
+		trigger.Source = nil
+
+		return &trigger
+	default:
+		return object
+	}
+}
+
+func indexesWithoutSources(indexes []schema.Index) []schema.Index {
+	indexes = slices.Clone(indexes)
+	for i := range indexes {
+		indexes[i].Source = nil
+	}
+
+	return indexes
+}
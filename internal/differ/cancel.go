@@ -0,0 +1,54 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// ProgressFunc is called after each comparison pass with the pass name and
+// the number of schema objects compared so far.
+type ProgressFunc func(pass string, done, total int)
+
+type comparePass struct {
+	name    string
+	objects int
+	run     func(*DiffResult)
+}
+
+func (d *Differ) runPasses(ctx context.Context, result *DiffResult) error {
+	passes := d.comparePasses(result)
+
+	total := 0
+	for _, pass := range passes {
+		total += pass.objects
+	}
+
+	done := 0
+
+	for _, pass := range passes {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("diff cancelled during %s after %d of %d objects: %w",
+				pass.name, done, total, err)
+		}
+
+		pass.run(result)
+		done += pass.objects
+
+		if d.options.Progress != nil {
+			d.options.Progress(pass.name, done, total)
+		}
+	}
+
+	return nil
+}
+
+func countIndexes(db *schema.Database) int {
+	count := 0
+	for i := range db.Tables {
+		count += len(db.Tables[i].Indexes)
+	}
+
+	return count
+}
@@ -6,15 +6,20 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 type ColumnComparator struct {
 	options *Options
+	types   *schema.DataTypeMapper
 }
 
 func NewColumnComparator(opts *Options) *ColumnComparator {
-	return &ColumnComparator{options: opts}
+	return &ColumnComparator{
+		options: opts,
+		types:   schema.NewDataTypeMapper(opts.TypeEquivalences),
+	}
 }
 
 func (cc *ColumnComparator) Compare(
@@ -26,40 +31,46 @@ func (cc *ColumnComparator) Compare(
 	currentCols := cc.buildColumnMap(current.Columns)
 	desiredCols := cc.buildColumnMap(desired.Columns)
 
+	if cc.options.DetectColumnRenames {
+		cc.detectRenamedColumns(result, tableKey, current, desired, currentCols, desiredCols)
+	}
+
 	cc.detectAddedColumns(result, tableKey, desired, currentCols, desiredCols)
-	cc.detectDroppedColumns(result, tableKey, currentTable, currentCols, desiredCols)
+	cc.detectDroppedColumns(result, tableKey, currentTable, current, currentCols, desiredCols)
 	cc.detectModifiedColumns(result, tableKey, desired, currentCols, desiredCols)
 }
 
+// buildColumnMap keys columns by lowercased name. The detect functions walk
+// the column slices and look each column up here, so changes come out in
+// declaration order and each lookup is constant time however wide the table.
 func (cc *ColumnComparator) buildColumnMap(columns []schema.Column) map[string]*schema.Column {
 	m := make(map[string]*schema.Column, len(columns))
 	for i := range columns {
-		key := strings.ToLower(columns[i].Name)
-		m[key] = &columns[i]
+		m[columnKey(&columns[i])] = &columns[i]
 	}
 
 	return m
 }
 
+func columnKey(col *schema.Column) string {
+	return col.Name
+}
+
 func (cc *ColumnComparator) detectAddedColumns(
 	result *DiffResult,
 	tableKey string,
 	table *schema.Table,
 	currentCols, desiredCols map[string]*schema.Column,
 ) {
-	for key, col := range desiredCols {
+	for key, col := range mappedInOrder(table.Columns, desiredCols, columnKey) {
 		if _, exists := currentCols[key]; !exists {
 			severity := cc.getAddColumnSeverity(col)
 
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeAddColumn,
 				Severity: severity,
-				Description: fmt.Sprintf(
-					"Add column: %s.%s (%s)",
-					table.QualifiedName(),
-					col.Name,
-					col.DataType,
-				),
+				Description: describeAdded("column",
+					fmt.Sprintf("%s.%s (%s)", table.QualifiedName(), col.Name, col.DataType)),
 				ObjectType: "column",
 				ObjectName: tableKey,
 				Details:    map[string]any{"table": table.QualifiedName(), "column": col},
@@ -70,8 +81,118 @@ func (cc *ColumnComparator) detectAddedColumns(
 	}
 }
 
+// detectRenamedColumns pairs columns that exist only in the current table
+// with columns that exist only in the desired one and are the same column
+// under another name. Each pair becomes a RenameColumn change and is removed
+// from currentCols and desiredCols. A column with more than one candidate is
+// left to be dropped or added, with a warning.
+func (cc *ColumnComparator) detectRenamedColumns(
+	result *DiffResult,
+	tableKey string,
+	current, desired *schema.Table,
+	currentCols, desiredCols map[string]*schema.Column,
+) {
+	var dropped, added []*schema.Column
+
+	for key, col := range mappedInOrder(current.Columns, currentCols, columnKey) {
+		if _, exists := desiredCols[key]; !exists {
+			dropped = append(dropped, col)
+		}
+	}
+
+	for key, col := range mappedInOrder(desired.Columns, desiredCols, columnKey) {
+		if _, exists := currentCols[key]; !exists {
+			added = append(added, col)
+		}
+	}
+
+	var ambiguous []string
+
+	for _, from := range dropped {
+		targets := cc.renameCandidates(from, added)
+		if len(targets) == 0 {
+			continue
+		}
+
+		if len(targets) > 1 || len(cc.renameCandidates(targets[0], dropped)) > 1 {
+			ambiguous = append(ambiguous, from.Name)
+			continue
+		}
+
+		to := targets[0]
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeRenameColumn,
+			Severity: SeverityPotentiallyBreaking,
+			Description: describe(descRenamedColumn,
+				desired.QualifiedName()+"."+from.Name, to.Name),
+			ObjectType: "column",
+			ObjectName: tableKey,
+			Details: map[string]any{
+				"table":    desired.QualifiedName(),
+				"column":   to,
+				"old_name": from.Name,
+				"new_name": to.Name,
+			},
+		})
+
+		delete(currentCols, columnKey(from))
+		delete(desiredCols, columnKey(to))
+	}
+
+	if len(ambiguous) == 0 {
+		return
+	}
+
+	result.addWarning(diag.Warning{
+		Code:     diag.CodeAmbiguousColumnRename,
+		Severity: diag.SeverityWarning,
+		Message: fmt.Sprintf(
+			"dropped columns %s of %s match more than one added column, or share one "+
+				"with another dropped column, so they are dropped and the added columns "+
+				"created empty; if a column was renamed, rename it with ALTER TABLE ... "+
+				"RENAME COLUMN before applying the migration",
+			strings.Join(ambiguous, ", "), desired.QualifiedName(),
+		),
+		ObjectName: tableKey,
+		ChangeType: string(ChangeTypeDropColumn),
+	})
+}
+
+// renameCandidates returns the columns of cols that col could have been
+// renamed to, or from. Columns are only paired when each is the other's only
+// candidate, so a paired column is never a candidate of another one.
+func (cc *ColumnComparator) renameCandidates(
+	col *schema.Column,
+	cols []*schema.Column,
+) []*schema.Column {
+	var matches []*schema.Column
+
+	for _, other := range cols {
+		if cc.isRename(col, other) {
+			matches = append(matches, other)
+		}
+	}
+
+	return matches
+}
+
+// isRename reports whether two columns of different names are otherwise the
+// same column.
+func (cc *ColumnComparator) isRename(a, b *schema.Column) bool {
+	return cc.sameType(a, b) &&
+		a.IsNullable == b.IsNullable &&
+		AreDefaultsEqual(a.Default, b.Default) &&
+		(cc.options.IgnoreComments || cc.options.commentsEqual(a.Comment, b.Comment)) &&
+		identitiesEqual(a.Identity, b.Identity) &&
+		a.IsGenerated == b.IsGenerated &&
+		a.GenerationExpression == b.GenerationExpression &&
+		a.EffectiveStorage() == b.EffectiveStorage() &&
+		strings.EqualFold(a.Compression, b.Compression)
+}
+
 func (cc *ColumnComparator) getAddColumnSeverity(col *schema.Column) ChangeSeverity {
-	if !col.IsNullable && col.Default == "" {
+	if !col.IsNullable && col.Default == "" && col.Identity == nil {
 		return SeverityDataMigrationRequired
 	}
 
@@ -81,10 +202,10 @@ func (cc *ColumnComparator) getAddColumnSeverity(col *schema.Column) ChangeSever
 func (cc *ColumnComparator) detectDroppedColumns(
 	result *DiffResult,
 	tableKey string,
-	table *schema.Table,
+	table, current *schema.Table,
 	currentCols, desiredCols map[string]*schema.Column,
 ) {
-	for key, col := range currentCols {
+	for key, col := range mappedInOrder(current.Columns, currentCols, columnKey) {
 		if _, exists := desiredCols[key]; !exists {
 			severity := SeverityPotentiallyBreaking
 			if !col.IsNullable {
@@ -94,7 +215,7 @@ func (cc *ColumnComparator) detectDroppedColumns(
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropColumn,
 				Severity:    severity,
-				Description: fmt.Sprintf("Drop column: %s.%s", table.QualifiedName(), col.Name),
+				Description: describeDropped("column", table.QualifiedName()+"."+col.Name),
 				ObjectType:  "column",
 				ObjectName:  tableKey,
 				Details:     map[string]any{"table": table.QualifiedName(), "column": col},
@@ -109,16 +230,25 @@ func (cc *ColumnComparator) detectModifiedColumns(
 	table *schema.Table,
 	currentCols, desiredCols map[string]*schema.Column,
 ) {
-	for key, desiredCol := range desiredCols {
+	for key, desiredCol := range mappedInOrder(table.Columns, desiredCols, columnKey) {
 		currentCol, exists := currentCols[key]
 		if !exists {
 			continue
 		}
 
+		// A column re-added for its new expression has its desired definition,
+		// so there is nothing else to change.
+		if cc.compareColumnGeneration(result, tableKey, table, currentCol, desiredCol) {
+			continue
+		}
+
 		cc.compareColumnType(result, tableKey, table, currentCol, desiredCol)
 		cc.compareColumnNullability(result, tableKey, table, currentCol, desiredCol)
 		cc.compareColumnDefault(result, tableKey, table, currentCol, desiredCol)
+		cc.compareColumnIdentity(result, tableKey, table, currentCol, desiredCol)
 		cc.compareColumnComment(result, tableKey, table, currentCol, desiredCol)
+		cc.compareColumnStorage(result, tableKey, table, currentCol, desiredCol)
+		cc.compareColumnCompression(result, tableKey, table, currentCol, desiredCol)
 	}
 }
 
@@ -128,7 +258,7 @@ func (cc *ColumnComparator) compareColumnType(
 	table *schema.Table,
 	current, desired *schema.Column,
 ) {
-	if columnsHaveSameType(current, desired) {
+	if cc.sameType(current, desired) {
 		return
 	}
 
@@ -137,27 +267,75 @@ func (cc *ColumnComparator) compareColumnType(
 		severity = SeveritySafe
 	}
 
+	name := table.QualifiedName() + "." + current.Name
+	description := describeValue("Column type", name,
+		current.FullDataType(), desired.FullDataType())
+	details := map[string]any{
+		"table":       table.QualifiedName(),
+		"column_name": current.Name,
+		"old_type":    current.FullDataType(),
+		"new_type":    desired.FullDataType(),
+	}
+
+	switch precisionChange := cc.timePrecisionChange(current, desired); precisionChange {
+	case PrecisionChangeWiden:
+		severity = SeveritySafe
+		description = describeValueBy("Column precision", name,
+			current.FullDataType(), desired.FullDataType(), "will be widened")
+		details[DetailKeyPrecisionChange] = precisionChange
+	case PrecisionChangeNarrow:
+		severity = SeverityPotentiallyBreaking
+		description = describeValueBy("Column precision", name,
+			current.FullDataType(), desired.FullDataType(),
+			"will be narrowed, rounding stored values")
+		details[DetailKeyPrecisionChange] = precisionChange
+	}
+
 	result.Changes = append(result.Changes, Change{
-		Type:     ChangeTypeModifyColumnType,
-		Severity: severity,
-		Description: fmt.Sprintf(
-			"Change column type: %s.%s from %s to %s",
-			table.QualifiedName(),
-			current.Name,
-			current.FullDataType(),
-			desired.FullDataType(),
-		),
-		ObjectType: "column",
-		ObjectName: tableKey,
-		Details: map[string]any{
-			"table":       table.QualifiedName(),
-			"column_name": current.Name,
-			"old_type":    current.FullDataType(),
-			"new_type":    desired.FullDataType(),
-		},
+		Type:        ChangeTypeModifyColumnType,
+		Severity:    severity,
+		Description: description,
+		ObjectType:  "column",
+		ObjectName:  tableKey,
+		Details:     details,
 	})
 }
 
+// DetailKeyPrecisionChange marks a MODIFY_COLUMN_TYPE change that keeps a
+// timestamp, time or interval type and only changes its fractional seconds
+// precision. Its value is PrecisionChangeWiden or PrecisionChangeNarrow.
+const DetailKeyPrecisionChange = "precision_change"
+
+const (
+	// PrecisionChangeWiden raises the precision, which rewrites nothing and
+	// keeps every stored value.
+	PrecisionChangeWiden = "widen"
+	// PrecisionChangeNarrow lowers the precision, which rounds stored values.
+	PrecisionChangeNarrow = "narrow"
+)
+
+// timePrecisionChange returns whether current and desired are the same time
+// type at a wider or a narrower precision, or "" when they are not.
+func (cc *ColumnComparator) timePrecisionChange(current, desired *schema.Column) string {
+	currentType, currentPrecision, _ := cc.canonicalColumnType(current)
+	desiredType, desiredPrecision, _ := cc.canonicalColumnType(desired)
+
+	if currentType != desiredType || !schema.IsTimeType(currentType) ||
+		current.IsArray != desired.IsArray ||
+		currentPrecision == nil || desiredPrecision == nil {
+		return ""
+	}
+
+	switch {
+	case *desiredPrecision > *currentPrecision:
+		return PrecisionChangeWiden
+	case *desiredPrecision < *currentPrecision:
+		return PrecisionChangeNarrow
+	default:
+		return ""
+	}
+}
+
 func (cc *ColumnComparator) compareColumnNullability(
 	result *DiffResult,
 	tableKey string,
@@ -169,17 +347,13 @@ func (cc *ColumnComparator) compareColumnNullability(
 	}
 
 	severity := SeveritySafe
-	description := fmt.Sprintf("Make column nullable: %s.%s", table.QualifiedName(), current.Name)
-
 	if !desired.IsNullable {
 		severity = SeverityDataMigrationRequired
-		description = fmt.Sprintf(
-			"Make column NOT NULL: %s.%s",
-			table.QualifiedName(),
-			current.Name,
-		)
 	}
 
+	description := describeValue("Column nullability", table.QualifiedName()+"."+current.Name,
+		nullability(current), nullability(desired))
+
 	result.Changes = append(result.Changes, Change{
 		Type:        ChangeTypeModifyColumnNullability,
 		Severity:    severity,
@@ -208,11 +382,8 @@ func (cc *ColumnComparator) compareColumnDefault(
 	result.Changes = append(result.Changes, Change{
 		Type:     ChangeTypeModifyColumnDefault,
 		Severity: SeveritySafe,
-		Description: fmt.Sprintf(
-			"Change default value: %s.%s",
-			table.QualifiedName(),
-			current.Name,
-		),
+		Description: describeValue("Column default", table.QualifiedName()+"."+current.Name,
+			current.Default, desired.Default),
 		ObjectType: "column",
 		ObjectName: tableKey,
 		Details: map[string]any{
@@ -234,21 +405,15 @@ func (cc *ColumnComparator) compareColumnComment(
 		return
 	}
 
-	currentComment := normalizeComment(current.Comment)
-	desiredComment := normalizeComment(desired.Comment)
-
-	if currentComment == desiredComment {
+	if cc.options.commentsEqual(current.Comment, desired.Comment) {
 		return
 	}
 
 	result.Changes = append(result.Changes, Change{
 		Type:     ChangeTypeModifyColumnComment,
 		Severity: SeveritySafe,
-		Description: fmt.Sprintf(
-			"Modify column comment: %s.%s",
-			table.QualifiedName(),
-			current.Name,
-		),
+		Description: describeComment("column", table.QualifiedName()+"."+current.Name,
+			current.Comment, desired.Comment),
 		ObjectType: "column",
 		ObjectName: tableKey,
 		Details: map[string]any{
@@ -260,6 +425,237 @@ func (cc *ColumnComparator) compareColumnComment(
 	})
 }
 
+// compareColumnStorage compares the storage strategies in effect, so naming
+// the default strategy of the type is the same as naming none. A new strategy
+// applies to values written from then on and rewrites nothing.
+func (cc *ColumnComparator) compareColumnStorage(
+	result *DiffResult,
+	tableKey string,
+	table *schema.Table,
+	current, desired *schema.Column,
+) {
+	currentStorage := current.EffectiveStorage()
+	desiredStorage := desired.EffectiveStorage()
+
+	// Changing the type of the column resets it to the default of the new
+	// type, which is where a strategy set afterwards starts from.
+	if !cc.sameType(current, desired) {
+		currentStorage = schema.DefaultStorage(desired)
+	}
+
+	if currentStorage == desiredStorage {
+		return
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyColumnStorage,
+		Severity: SeveritySafe,
+		Description: describeValue("Column storage", table.QualifiedName()+"."+current.Name,
+			currentStorage, desiredStorage),
+		ObjectType: "column",
+		ObjectName: tableKey,
+		Details: map[string]any{
+			"table":       table.QualifiedName(),
+			"column_name": current.Name,
+			"old_storage": currentStorage,
+			"new_storage": desiredStorage,
+		},
+	})
+}
+
+// compareColumnGeneration compares the expressions of generated columns and
+// reports whether the column is re-added. PostgreSQL can only turn a generated
+// column into a plain one in place, keeping its values with DROP EXPRESSION;
+// a new or changed expression drops the column and adds it again, which loses
+// its values and the indexes and constraints on it.
+func (cc *ColumnComparator) compareColumnGeneration(
+	result *DiffResult,
+	tableKey string,
+	table *schema.Table,
+	current, desired *schema.Column,
+) bool {
+	if current.IsGenerated == desired.IsGenerated &&
+		(!desired.IsGenerated ||
+			normalizeExpression(current.GenerationExpression) ==
+				normalizeExpression(desired.GenerationExpression)) {
+		return false
+	}
+
+	readd := desired.IsGenerated
+
+	severity := SeveritySafe
+	consequence := "expression will be dropped, keeping stored values"
+
+	switch {
+	case readd && current.IsGenerated:
+		severity = SeverityPotentiallyBreaking
+		consequence = "column will be dropped and re-added"
+	case readd:
+		severity = SeverityDataMigrationRequired
+		consequence = "column will be dropped and re-added, replacing its values"
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyColumnGeneration,
+		Severity: severity,
+		Description: describeValueBy("Column generation expression",
+			table.QualifiedName()+"."+current.Name,
+			current.GenerationExpression, desired.GenerationExpression, consequence),
+		ObjectType: "column",
+		ObjectName: tableKey,
+		Details: map[string]any{
+			"table":          table.QualifiedName(),
+			"column_name":    current.Name,
+			"old_column":     current,
+			"new_column":     desired,
+			"old_expression": current.GenerationExpression,
+			"new_expression": desired.GenerationExpression,
+		},
+	})
+
+	return readd
+}
+
+// compareColumnIdentity compares identity columns. A column becomes one and
+// stops being one in place, keeping its values, and the options of its
+// sequence are set without recreating it. A SERIAL column turned into an
+// identity column therefore loses only its default and its sequence.
+func (cc *ColumnComparator) compareColumnIdentity(
+	result *DiffResult,
+	tableKey string,
+	table *schema.Table,
+	current, desired *schema.Column,
+) {
+	if identitiesEqual(current.Identity, desired.Identity) {
+		return
+	}
+
+	name := table.QualifiedName() + "." + current.Name
+	details := map[string]any{
+		"table":         table.QualifiedName(),
+		"column_name":   current.Name,
+		"old_column":    current,
+		"new_column":    desired,
+		"enforce_start": cc.options.EnforceSequenceStart,
+	}
+
+	change := Change{
+		Severity:   SeveritySafe,
+		ObjectType: "column",
+		ObjectName: tableKey,
+		Details:    details,
+	}
+
+	switch {
+	case current.Identity == nil:
+		change.Type = ChangeTypeAddColumnIdentity
+		change.Description = describeValueBy("Column identity", name,
+			"", desired.Identity.Generation, "will be added, starting after the highest value")
+	case desired.Identity == nil:
+		change.Type = ChangeTypeDropColumnIdentity
+		change.Description = describeValueBy("Column identity", name,
+			current.Identity.Generation, "", "will be dropped")
+
+		// Inserts leaving the column out fail unless it gets a default.
+		if desired.Default == "" {
+			change.Severity = SeverityPotentiallyBreaking
+		}
+	default:
+		differences := identityDifferences(current, desired, cc.options.EnforceSequenceStart)
+		if len(differences) == 0 {
+			return
+		}
+
+		described := make([]string, len(differences))
+		for i, diff := range differences {
+			described[i] = diff.String()
+		}
+
+		change.Type = ChangeTypeModifyColumnIdentity
+		change.Description = describe(descIdentityDiffers, name, strings.Join(described, ", "))
+	}
+
+	// Inserts that supply a value for a GENERATED ALWAYS column fail.
+	if desired.Identity != nil && desired.Identity.Generation == schema.IdentityAlways &&
+		(current.Identity == nil || current.Identity.Generation != schema.IdentityAlways) {
+		change.Severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, change)
+}
+
+// identityDifferences lists the generation and the sequence options that
+// differ between two identities of a column, comparing START WITH only when
+// enforceStart is set, as for sequences.
+func identityDifferences(current, desired *schema.Column, enforceStart bool) []sequenceDifference {
+	var differences []sequenceDifference
+
+	if current.Identity.Generation != desired.Identity.Generation {
+		differences = append(differences, sequenceDifference{
+			"generation", current.Identity.Generation, desired.Identity.Generation,
+		})
+	}
+
+	dataType := strings.ToLower(desired.DataType)
+
+	return append(differences, sequenceDifferences(
+		current.Identity.Sequence(dataType), desired.Identity.Sequence(dataType), enforceStart)...)
+}
+
+// identitiesEqual reports whether two columns are identity columns generating
+// the same values, or both plain columns.
+func identitiesEqual(a, b *schema.Identity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// compareColumnCompression compares TOAST compression methods. Like a new
+// storage strategy, a new method only applies to values written afterwards.
+func (cc *ColumnComparator) compareColumnCompression(
+	result *DiffResult,
+	tableKey string,
+	table *schema.Table,
+	current, desired *schema.Column,
+) {
+	if strings.EqualFold(current.Compression, desired.Compression) {
+		return
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyColumnCompression,
+		Severity: SeveritySafe,
+		Description: describeValue("Column compression", table.QualifiedName()+"."+current.Name,
+			compressionName(current.Compression), compressionName(desired.Compression)),
+		ObjectType: "column",
+		ObjectName: tableKey,
+		Details: map[string]any{
+			"table":           table.QualifiedName(),
+			"column_name":     current.Name,
+			"old_compression": strings.ToLower(current.Compression),
+			"new_compression": strings.ToLower(desired.Compression),
+		},
+	})
+}
+
+func nullability(col *schema.Column) string {
+	if col.IsNullable {
+		return "nullable"
+	}
+
+	return "NOT NULL"
+}
+
+func compressionName(compression string) string {
+	if compression == "" {
+		return "default"
+	}
+
+	return strings.ToLower(compression)
+}
+
 func (cc *ColumnComparator) addColumnCommentChange(
 	result *DiffResult,
 	tableKey string,
@@ -272,11 +668,12 @@ func (cc *ColumnComparator) addColumnCommentChange(
 	}
 
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeModifyColumnComment,
-		Severity:    SeveritySafe,
-		Description: fmt.Sprintf("Add column comment: %s.%s", table.QualifiedName(), col.Name),
-		ObjectType:  "column",
-		ObjectName:  tableKey,
+		Type:     ChangeTypeModifyColumnComment,
+		Severity: SeveritySafe,
+		Description: describeComment("column", table.QualifiedName()+"."+col.Name,
+			oldComment, col.Comment),
+		ObjectType: "column",
+		ObjectName: tableKey,
 		Details: map[string]any{
 			"table":       table.QualifiedName(),
 			"column_name": col.Name,
@@ -286,13 +683,22 @@ func (cc *ColumnComparator) addColumnCommentChange(
 	})
 }
 
+// maxRealPrecision is the largest float(p) precision stored as real.
+const maxRealPrecision = 24
+
 func isTypeSafeChange(current, desired *schema.Column) bool {
 	currentType := strings.ToLower(current.DataType)
 	desiredType := strings.ToLower(desired.DataType)
 
 	if (strings.HasPrefix(currentType, "varchar") || strings.HasPrefix(currentType, "character varying")) &&
 		(strings.HasPrefix(desiredType, "varchar") || strings.HasPrefix(desiredType, "character varying")) {
-		if current.MaxLength != nil && desired.MaxLength != nil {
+		// A varchar without a length is unlimited, so dropping the length
+		// rewrites nothing.
+		if desired.MaxLength == nil {
+			return true
+		}
+
+		if current.MaxLength != nil {
 			return *desired.MaxLength >= *current.MaxLength
 		}
 	}
@@ -305,7 +711,11 @@ func isTypeSafeChange(current, desired *schema.Column) bool {
 
 	if strings.HasPrefix(currentType, "numeric") &&
 		strings.HasPrefix(desiredType, "numeric") {
-		if current.Precision != nil && desired.Precision != nil {
+		if desired.Precision == nil {
+			return true
+		}
+
+		if current.Precision != nil {
 			if current.Scale != nil && desired.Scale != nil {
 				return *desired.Precision >= *current.Precision && *desired.Scale >= *current.Scale
 			}
@@ -331,40 +741,10 @@ func isTypeSafeChange(current, desired *schema.Column) bool {
 	return false
 }
 
+// NormalizeDataType returns the canonical spelling of dataType, resolving
+// the built-in aliases of schema.CanonicalDataType.
 func NormalizeDataType(dataType string) string {
-	dt := strings.ToLower(strings.TrimSpace(dataType))
-
-	aliases := map[string]string{
-		"int":               "integer",
-		"int2":              "smallint",
-		"int4":              "integer",
-		"int8":              "bigint",
-		"float":             "double precision",
-		"float4":            "real",
-		"float8":            "double precision",
-		"serial":            "integer",
-		"bigserial":         "bigint",
-		"bool":              "boolean",
-		"character varying": "varchar",
-		"character":         "char",
-		"decimal":           "numeric",
-		"timestamp":         "timestamp without time zone",
-		"timestamptz":       "timestamp with time zone",
-		"time":              "time without time zone",
-		"timetz":            "time with time zone",
-	}
-
-	if normalized, exists := aliases[dt]; exists {
-		return normalized
-	}
-
-	for alias, canonical := range aliases {
-		if strings.HasPrefix(dt, alias+"(") {
-			return canonical + dt[len(alias):]
-		}
-	}
-
-	return dt
+	return schema.CanonicalDataType(dataType)
 }
 
 func AreDefaultsEqual(default1, default2 string) bool {
@@ -375,8 +755,11 @@ func AreDefaultsEqual(default1, default2 string) bool {
 	return normalizeDefault(default1) == normalizeDefault(default2)
 }
 
-func columnsHaveSameType(current, desired *schema.Column) bool {
-	if NormalizeDataType(current.DataType) != NormalizeDataType(desired.DataType) {
+func (cc *ColumnComparator) sameType(current, desired *schema.Column) bool {
+	currentType, currentPrecision, currentScale := cc.canonicalColumnType(current)
+	desiredType, desiredPrecision, desiredScale := cc.canonicalColumnType(desired)
+
+	if currentType != desiredType {
 		return false
 	}
 
@@ -384,11 +767,11 @@ func columnsHaveSameType(current, desired *schema.Column) bool {
 		return false
 	}
 
-	if !intPointerEqual(current.Precision, desired.Precision) {
+	if !intPointerEqual(currentPrecision, desiredPrecision) {
 		return false
 	}
 
-	if !intPointerEqual(current.Scale, desired.Scale) {
+	if !intPointerEqual(currentScale, desiredScale) {
 		return false
 	}
 
@@ -399,6 +782,37 @@ func columnsHaveSameType(current, desired *schema.Column) bool {
 	return true
 }
 
+// canonicalColumnType returns the normalized type of col with the precision
+// and scale PostgreSQL records for it: numeric(p) is numeric(p,0), float(p)
+// is real up to 24 bits and double precision above, and a time type without a
+// precision has the default one. A bare numeric has neither, so it differs
+// from every numeric(p,s) in both directions.
+func (cc *ColumnComparator) canonicalColumnType(col *schema.Column) (string, *int, *int) {
+	dataType := cc.types.Canonical(col.DataType)
+	precision, scale := col.Precision, col.Scale
+
+	switch {
+	case dataType == "numeric" && precision != nil && scale == nil:
+		zero := 0
+		scale = &zero
+	case strings.EqualFold(strings.TrimSpace(col.DataType), "float") && precision != nil:
+		if *precision <= maxRealPrecision {
+			dataType = "real"
+		}
+
+		precision, scale = nil, nil
+	case schema.IsTimeType(dataType):
+		if precision == nil {
+			defaultPrecision := schema.DefaultTimePrecision
+			precision = &defaultPrecision
+		}
+
+		scale = nil
+	}
+
+	return dataType, precision, scale
+}
+
 func intPointerEqual(a, b *int) bool {
 	switch {
 	case a == nil && b == nil:
@@ -410,6 +824,16 @@ func intPointerEqual(a, b *int) bool {
 	}
 }
 
+var literalCastPattern = regexp.MustCompile(
+	`^('(?:[^']|'')*')::[a-z_][a-z0-9_]*(?:\.[a-z_][a-z0-9_]*)?$`,
+)
+
+var arrayCastPattern = regexp.MustCompile(`::[a-z_][a-z0-9_]*\[\]`)
+
+// publicNextvalPattern matches the public schema pg_dump writes before the
+// sequence of a serial column; the database and parsed serials leave it out.
+var publicNextvalPattern = regexp.MustCompile(`nextval\('public\.`)
+
 func normalizeDefault(defaultValue string) string {
 	if defaultValue == "" {
 		return ""
@@ -418,8 +842,8 @@ func normalizeDefault(defaultValue string) string {
 	def := strings.TrimSpace(defaultValue)
 	def = strings.ToLower(def)
 
-	arrayCastPattern := regexp.MustCompile(`::[a-z_][a-z0-9_]*\[\]`)
 	def = arrayCastPattern.ReplaceAllString(def, "")
+	def = publicNextvalPattern.ReplaceAllString(def, "nextval('")
 
 	typeCasts := []string{
 		"::text", "::character varying", "::varchar", "::integer", "::bigint",
@@ -445,5 +869,10 @@ func normalizeDefault(defaultValue string) string {
 		return replacement
 	}
 
+	// A literal cast to a named type, such as pg_dump's 'active'::public.status
+	// for an enum column, is the same default as the bare literal assigned to
+	// the column, which is how generated migrations write it.
+	def = literalCastPattern.ReplaceAllString(def, "$1")
+
 	return strings.Join(strings.Fields(def), " ")
 }
@@ -0,0 +1,376 @@
+package differ
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// columnRef is a column of the table with the given key.
+type columnRef struct {
+	table  string
+	column string
+}
+
+// columnChangesByTable returns the columns modified by the changes of the
+// given types, by the key of their table.
+func columnChangesByTable(changes []Change, types ...ChangeType) map[string][]columnRef {
+	columns := make(map[string][]columnRef)
+
+	for i := range changes {
+		if !slices.Contains(types, changes[i].Type) {
+			continue
+		}
+
+		if tableName, columnName, ok := getModifiedColumnFromChange(&changes[i]); ok {
+			columns[tableName] = append(columns[tableName],
+				columnRef{table: tableName, column: columnName})
+		}
+	}
+
+	return columns
+}
+
+// tableColumnChanges returns the changed columns of the named table.
+func tableColumnChanges(tableName string, columnChanges map[string][]columnRef) []columnRef {
+	var columns []columnRef
+
+	for table, refs := range columnChanges {
+		if depMatchesTables(tableName, map[string]bool{table: true}) {
+			columns = append(columns, refs...)
+		}
+	}
+
+	return columns
+}
+
+// queryUsesAnyColumn reports whether a view or continuous aggregate query
+// reads any of the columns.
+func queryUsesAnyColumn(query string, columns []columnRef) bool {
+	return slices.ContainsFunc(columns, func(ref columnRef) bool {
+		return queryUsesColumn(query, ref)
+	})
+}
+
+// queryUsesColumn reports whether a query reads a column of a table. A name
+// is a reference to the column when it is qualified by the table or one of
+// its aliases, or when it stands unqualified as an expression. An output
+// alias or a relation alias that happens to share the column's name is not,
+// nor is a column of another relation of the FROM clause. Selecting * reads
+// every column. A query that cannot be tokenized, or that qualifies the name
+// by something other than a relation of its FROM clauses, is assumed to read
+// it.
+func queryUsesColumn(query string, ref columnRef) bool {
+	tokens, err := parser.NewLexer(query).Tokenize()
+	if err != nil {
+		return true
+	}
+
+	tokens = slices.DeleteFunc(tokens, func(tok parser.Token) bool {
+		return tok.Type == parser.TokenComment
+	})
+
+	table := ref.table
+	if dot := strings.LastIndex(table, "."); dot >= 0 {
+		table = table[dot+1:]
+	}
+
+	relations, relationTokens := fromRelations(tokens)
+	self, other := relationQualifiers(relations, table)
+	column := schema.NormalizeIdentifier(ref.column)
+
+	at := func(i int) parser.Token {
+		if i < 0 || i >= len(tokens) {
+			return parser.Token{Type: parser.TokenEOF}
+		}
+
+		return tokens[i]
+	}
+
+	for i, tok := range tokens {
+		if tok.Type == parser.TokenOperator && tok.Literal == "*" {
+			if selectsStar(at(i - 1)) {
+				return true
+			}
+
+			if at(i-1).Type == parser.TokenDot &&
+				!other[schema.FoldIdentifier(at(i-2).Literal)] {
+				return true
+			}
+
+			continue
+		}
+
+		if !isNameToken(tok) || schema.FoldIdentifier(tok.Literal) != column ||
+			relationTokens[i] {
+			continue
+		}
+
+		next := at(i + 1)
+		if next.Type == parser.TokenLParen || next.Type == parser.TokenDot {
+			continue
+		}
+
+		prev := at(i - 1)
+		if prev.Type == parser.TokenDot {
+			qualifier := schema.FoldIdentifier(at(i - 2).Literal)
+			if self[qualifier] || !other[qualifier] {
+				return true
+			}
+
+			continue
+		}
+
+		if !isColumnAlias(prev) && !(prev.Type == parser.TokenColon &&
+			at(i-2).Type == parser.TokenColon) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectsStar reports whether a * following prev selects every column,
+// rather than multiplying or standing for count(*).
+func selectsStar(prev parser.Token) bool {
+	if prev.Type == parser.TokenComma {
+		return true
+	}
+
+	return prev.Type == parser.TokenKeyword &&
+		(tokenLiteralEqual(prev, "SELECT") || tokenLiteralEqual(prev, "DISTINCT") ||
+			tokenLiteralEqual(prev, "ALL"))
+}
+
+// isColumnAlias reports whether a name following prev is an alias: after AS,
+// or right after the expression it names, as in "sum(value) total".
+func isColumnAlias(prev parser.Token) bool {
+	switch prev.Type {
+	case parser.TokenRParen, parser.TokenRBracket, parser.TokenString, parser.TokenNumber,
+		parser.TokenIdentifier, parser.TokenQuotedIdentifier:
+		return true
+	case parser.TokenKeyword:
+		return tokenLiteralEqual(prev, "AS") || tokenLiteralEqual(prev, "END")
+	default:
+		return false
+	}
+}
+
+func isNameToken(tok parser.Token) bool {
+	return tok.Type == parser.TokenIdentifier || tok.Type == parser.TokenQuotedIdentifier ||
+		tok.Type == parser.TokenKeyword
+}
+
+// fromRelation is an item of a FROM clause. Name is empty for a subquery or
+// a function call, and schema when the query does not qualify the name.
+type fromRelation struct {
+	schema string
+	name   string
+	alias  string
+}
+
+// fromRelations returns the items of every FROM clause and JOIN of the
+// query, with the positions of the tokens that name them or their aliases.
+// A FROM inside the parentheses of EXTRACT or SUBSTRING is not a clause.
+func fromRelations(tokens []parser.Token) ([]fromRelation, map[int]bool) {
+	var relations []fromRelation
+
+	positions := make(map[int]bool)
+
+	// selects holds, for each open parenthesis, whether a SELECT began in it.
+	selects := []bool{false}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		switch {
+		case tok.Type == parser.TokenLParen:
+			selects = append(selects, false)
+		case tok.Type == parser.TokenRParen && len(selects) > 1:
+			selects = selects[:len(selects)-1]
+		case tok.Type == parser.TokenKeyword && tokenLiteralEqual(tok, "SELECT"):
+			selects[len(selects)-1] = true
+		case tok.Type == parser.TokenKeyword && tokenLiteralEqual(tok, "JOIN"),
+			tok.Type == parser.TokenKeyword && tokenLiteralEqual(tok, "FROM") &&
+				selects[len(selects)-1] && !(i > 0 && tokenLiteralEqual(tokens[i-1], "DISTINCT")):
+			for j := i + 1; j < len(tokens); j++ {
+				var relation fromRelation
+
+				relation, j = readFromRelation(tokens, j, positions)
+				relations = append(relations, relation)
+
+				if j >= len(tokens) || tokens[j].Type != parser.TokenComma ||
+					tokenLiteralEqual(tok, "JOIN") {
+					break
+				}
+			}
+		}
+	}
+
+	return relations, positions
+}
+
+// readFromRelation reads the FROM item starting at tokens[i] and returns it
+// with the position of the token after it. The parentheses of a subquery or
+// function call are left for the caller to scan.
+func readFromRelation(
+	tokens []parser.Token,
+	i int,
+	positions map[int]bool,
+) (fromRelation, int) {
+	var relation fromRelation
+
+	for i < len(tokens) && (tokenLiteralEqual(tokens[i], "LATERAL") ||
+		tokenLiteralEqual(tokens[i], "ONLY")) {
+		i++
+	}
+
+	if i < len(tokens) && isNameToken(tokens[i]) {
+		start := i
+		for i+2 < len(tokens) && tokens[i+1].Type == parser.TokenDot && isNameToken(tokens[i+2]) {
+			i += 2
+		}
+
+		if i+1 < len(tokens) && tokens[i+1].Type == parser.TokenLParen {
+			return relation, i + 1
+		}
+
+		for j := start; j <= i; j += 2 {
+			positions[j] = true
+		}
+
+		relation.name = schema.FoldIdentifier(tokens[i].Literal)
+		if i > start {
+			relation.schema = schema.FoldIdentifier(tokens[i-2].Literal)
+		}
+
+		i++
+	} else {
+		// A subquery: its parentheses are scanned as usual, so its alias is
+		// read where it appears after the closing one.
+		return relation, i
+	}
+
+	if i < len(tokens) && tokenLiteralEqual(tokens[i], "AS") {
+		i++
+	} else if i >= len(tokens) || tokens[i].Type == parser.TokenKeyword {
+		return relation, i
+	}
+
+	if i < len(tokens) && isNameToken(tokens[i]) {
+		positions[i] = true
+		relation.alias = schema.FoldIdentifier(tokens[i].Literal)
+		i++
+	}
+
+	return relation, i
+}
+
+// relationQualifiers returns the names a query can qualify the columns of
+// the table with, and those that qualify the columns of its other relations.
+func relationQualifiers(relations []fromRelation, table string) (self, other map[string]bool) {
+	self = make(map[string]bool)
+	other = make(map[string]bool)
+
+	for _, relation := range relations {
+		qualifier := relation.alias
+		if qualifier == "" {
+			qualifier = relation.name
+		}
+
+		if qualifier == "" {
+			continue
+		}
+
+		if relation.name == table {
+			self[qualifier] = true
+			self[relation.name] = true
+		} else {
+			other[qualifier] = true
+		}
+	}
+
+	return self, other
+}
+
+// QueryRelation is a table or view a query reads in a FROM clause or JOIN.
+// Schema is empty when the query does not qualify the name. Columns are the
+// names the query qualifies by the relation or its alias, such as total in
+// o.total.
+type QueryRelation struct {
+	Schema  string
+	Name    string
+	Columns []string
+}
+
+// QueryRelations returns the relations a query reads, in the order it names
+// them. A qualifier that two relations of the query could stand for is left
+// out of their columns. It reports false when the query cannot be tokenized.
+func QueryRelations(query string) ([]QueryRelation, bool) {
+	tokens, err := parser.NewLexer(query).Tokenize()
+	if err != nil {
+		return nil, false
+	}
+
+	tokens = slices.DeleteFunc(tokens, func(tok parser.Token) bool {
+		return tok.Type == parser.TokenComment
+	})
+
+	relations, relationTokens := fromRelations(tokens)
+
+	var found []QueryRelation
+
+	qualifiers := make(map[string]int)
+
+	for _, relation := range relations {
+		if relation.name == "" {
+			continue
+		}
+
+		qualifier := relation.alias
+		if qualifier == "" {
+			qualifier = relation.name
+		}
+
+		if _, taken := qualifiers[qualifier]; taken {
+			qualifiers[qualifier] = -1
+		} else {
+			qualifiers[qualifier] = len(found)
+		}
+
+		found = append(found, QueryRelation{Schema: relation.schema, Name: relation.name})
+	}
+
+	at := func(i int) parser.Token {
+		if i < 0 || i >= len(tokens) {
+			return parser.Token{Type: parser.TokenEOF}
+		}
+
+		return tokens[i]
+	}
+
+	for i, tok := range tokens {
+		if !isNameToken(tok) || relationTokens[i] || at(i+1).Type != parser.TokenDot ||
+			!isNameToken(at(i+2)) {
+			continue
+		}
+
+		if next := at(i + 3); next.Type == parser.TokenDot || next.Type == parser.TokenLParen {
+			continue
+		}
+
+		index, ok := qualifiers[schema.FoldIdentifier(tok.Literal)]
+		if !ok || index < 0 {
+			continue
+		}
+
+		column := schema.FoldIdentifier(at(i + 2).Literal)
+		if !slices.Contains(found[index].Columns, column) {
+			found[index].Columns = append(found[index].Columns, column)
+		}
+	}
+
+	return found, true
+}
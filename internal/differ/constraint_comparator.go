@@ -2,12 +2,18 @@ package differ
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
+// DetailKeyRenameOnly marks a MODIFY_CONSTRAINT change that only renames a
+// constraint whose definition is unchanged.
+const DetailKeyRenameOnly = "rename_only"
+
 type ConstraintComparator struct {
 	options *Options
 }
@@ -23,15 +29,22 @@ func (cc *ConstraintComparator) Compare(result *DiffResult, current, desired *sc
 	currentConstraints := cc.buildConstraintMap(current.Constraints)
 	desiredConstraints := cc.buildConstraintMap(desired.Constraints)
 
-	cc.detectAddedConstraints(result, tableKey, tableName, currentConstraints, desiredConstraints)
-	cc.detectDroppedConstraints(result, tableKey, tableName, currentConstraints, desiredConstraints)
-	cc.detectModifiedConstraints(
-		result,
-		tableKey,
-		tableName,
-		currentConstraints,
-		desiredConstraints,
-	)
+	if !cc.options.IgnoreConstraintNames {
+		cc.detectRenamedConstraints(
+			result,
+			tableKey,
+			tableName,
+			currentConstraints,
+			desiredConstraints,
+		)
+	}
+
+	cc.detectAddedConstraints(result, tableKey, tableName,
+		desired.Constraints, currentConstraints, desiredConstraints)
+	cc.detectDroppedConstraints(result, tableKey, tableName,
+		current.Constraints, currentConstraints, desiredConstraints)
+	cc.detectModifiedConstraints(result, tableKey, tableName,
+		desired.Constraints, currentConstraints, desiredConstraints)
 }
 
 func (cc *ConstraintComparator) buildConstraintMap(
@@ -51,15 +64,76 @@ func (cc *ConstraintComparator) constraintKey(constraint *schema.Constraint) str
 		return constraintStructureKey(constraint)
 	}
 
-	return strings.ToLower(constraint.Name)
+	return constraint.Name
+}
+
+// detectRenamedConstraints renames, in place, constraints that older pgtofu
+// versions named by cutting a long synthesized name at the identifier limit,
+// where names that share a prefix could collide. The renamed pairs are taken
+// out of both maps so they are neither added nor dropped.
+func (cc *ConstraintComparator) detectRenamedConstraints(
+	result *DiffResult,
+	tableKey, tableName string,
+	currentConstraints, desiredConstraints map[string]*schema.Constraint,
+) {
+	for _, key := range slices.Sorted(maps.Keys(desiredConstraints)) {
+		desiredConstraint := desiredConstraints[key]
+		if desiredConstraint.LegacyName == "" {
+			continue
+		}
+
+		if _, exists := currentConstraints[key]; exists {
+			continue
+		}
+
+		legacyKey := desiredConstraint.LegacyName
+		if _, renamed := desiredConstraints[legacyKey]; renamed {
+			continue
+		}
+
+		currentConstraint, exists := currentConstraints[legacyKey]
+		if !exists || !areConstraintsEqual(currentConstraint, desiredConstraint) {
+			continue
+		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeModifyConstraint,
+			Severity: SeveritySafe,
+			Description: describe(descRenamedConstraint, desiredConstraint.Type,
+				currentConstraint.Name, tableName, desiredConstraint.Name),
+			ObjectType: "constraint",
+			ObjectName: tableKey,
+			Details: map[string]any{
+				"table":             tableName,
+				"current":           currentConstraint,
+				"desired":           desiredConstraint,
+				DetailKeyRenameOnly: true,
+			},
+		})
+
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"constraint %s on %s is renamed to %s: long generated names now end in a "+
+				"digest so they cannot collide when cut to %d bytes",
+			currentConstraint.Name, tableName, desiredConstraint.Name, schema.MaxIdentifierLength,
+		))
+
+		currentComment := currentConstraint.Comment
+		if !cc.options.commentsEqual(currentComment, desiredConstraint.Comment) {
+			cc.addCommentChange(result, tableKey, tableName, desiredConstraint, currentComment)
+		}
+
+		delete(currentConstraints, legacyKey)
+		delete(desiredConstraints, key)
+	}
 }
 
 func (cc *ConstraintComparator) detectAddedConstraints(
 	result *DiffResult,
 	tableKey, tableName string,
+	desired []schema.Constraint,
 	currentConstraints, desiredConstraints map[string]*schema.Constraint,
 ) {
-	for key, constraint := range desiredConstraints {
+	for key, constraint := range mappedInOrder(desired, desiredConstraints, cc.constraintKey) {
 		if _, exists := currentConstraints[key]; !exists {
 			severity := SeveritySafe
 			if constraint.IsForeignKey() {
@@ -74,17 +148,15 @@ func (cc *ConstraintComparator) detectAddedConstraints(
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeAddConstraint,
 				Severity: severity,
-				Description: fmt.Sprintf(
-					"Add %s constraint: %s on %s",
-					constraint.Type,
-					constraint.Name,
-					tableName,
-				),
+				Description: describeAdded(constraint.Type+" constraint",
+					constraint.Name+" on "+tableName),
 				ObjectType: "constraint",
 				ObjectName: tableKey,
 				Details:    map[string]any{"table": tableName, "constraint": constraint},
 				DependsOn:  getConstraintDependencies(constraint),
 			})
+
+			cc.addCommentChange(result, tableKey, tableName, constraint, "")
 		}
 	}
 }
@@ -92,9 +164,10 @@ func (cc *ConstraintComparator) detectAddedConstraints(
 func (cc *ConstraintComparator) detectDroppedConstraints(
 	result *DiffResult,
 	tableKey, tableName string,
+	current []schema.Constraint,
 	currentConstraints, desiredConstraints map[string]*schema.Constraint,
 ) {
-	for key, constraint := range currentConstraints {
+	for key, constraint := range mappedInOrder(current, currentConstraints, cc.constraintKey) {
 		if _, exists := desiredConstraints[key]; !exists {
 			severity := SeverityPotentiallyBreaking
 			if constraint.IsPrimaryKey() || constraint.IsUnique() {
@@ -104,12 +177,8 @@ func (cc *ConstraintComparator) detectDroppedConstraints(
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeDropConstraint,
 				Severity: severity,
-				Description: fmt.Sprintf(
-					"Drop %s constraint: %s from %s",
-					constraint.Type,
-					constraint.Name,
-					tableName,
-				),
+				Description: describeDropped(constraint.Type+" constraint",
+					constraint.Name+" on "+tableName),
 				ObjectType: "constraint",
 				ObjectName: tableKey,
 				Details:    map[string]any{"table": tableName, "constraint": constraint},
@@ -121,9 +190,11 @@ func (cc *ConstraintComparator) detectDroppedConstraints(
 func (cc *ConstraintComparator) detectModifiedConstraints(
 	result *DiffResult,
 	tableKey, tableName string,
+	desired []schema.Constraint,
 	currentConstraints, desiredConstraints map[string]*schema.Constraint,
 ) {
-	for key, desiredConstraint := range desiredConstraints {
+	for key, desiredConstraint := range mappedInOrder(desired, desiredConstraints,
+		cc.constraintKey) {
 		currentConstraint, exists := currentConstraints[key]
 		if !exists {
 			continue
@@ -133,12 +204,8 @@ func (cc *ConstraintComparator) detectModifiedConstraints(
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeModifyConstraint,
 				Severity: SeverityPotentiallyBreaking,
-				Description: fmt.Sprintf(
-					"Modify %s constraint: %s on %s",
-					desiredConstraint.Type,
-					desiredConstraint.Name,
-					tableName,
-				),
+				Description: describeReplaced(desiredConstraint.Type+" constraint",
+					desiredConstraint.Name+" on "+tableName),
 				ObjectType: "constraint",
 				ObjectName: tableKey,
 				Details: map[string]any{
@@ -148,19 +215,85 @@ func (cc *ConstraintComparator) detectModifiedConstraints(
 				},
 				DependsOn: getConstraintDependencies(desiredConstraint),
 			})
+
+			// Recreating the constraint drops its comment, so a desired
+			// comment is set again even when it did not change.
+			cc.addCommentChange(result, tableKey, tableName, desiredConstraint, "")
+
+			continue
+		}
+
+		currentComment := currentConstraint.Comment
+		if !cc.options.commentsEqual(currentComment, desiredConstraint.Comment) {
+			cc.addCommentChange(result, tableKey, tableName, desiredConstraint, currentComment)
 		}
 	}
 }
 
+// addCommentChanges records a comment change for every commented constraint
+// of a table that is being created.
+func (cc *ConstraintComparator) addCommentChanges(
+	result *DiffResult,
+	tableKey string,
+	table *schema.Table,
+) {
+	for i := range table.Constraints {
+		cc.addCommentChange(result, tableKey, table.QualifiedName(), &table.Constraints[i], "")
+	}
+}
+
+func (cc *ConstraintComparator) addCommentChange(
+	result *DiffResult,
+	tableKey, tableName string,
+	constraint *schema.Constraint,
+	oldComment string,
+) {
+	if cc.options.IgnoreComments || (constraint.Comment == "" && oldComment == "") {
+		return
+	}
+
+	severity := SeveritySafe
+	if constraint.Comment == "" {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyConstraintComment,
+		Severity: severity,
+		Description: describeComment("constraint", constraint.Name+" on "+tableName,
+			oldComment, constraint.Comment),
+		ObjectType: "constraint",
+		ObjectName: tableKey,
+		Details: map[string]any{
+			"table":           tableName,
+			"constraint_name": constraint.Name,
+			"old_comment":     oldComment,
+			"new_comment":     constraint.Comment,
+		},
+	})
+}
+
 func constraintStructureKey(constraint *schema.Constraint) string {
 	var key strings.Builder
 	key.WriteString(constraint.Type)
 	key.WriteString(":")
 
-	cols := make([]string, len(constraint.Columns))
-	copy(cols, constraint.Columns)
-	sort.Strings(cols)
-	key.WriteString(strings.Join(cols, ","))
+	// The columns of an EXCLUDE constraint are only known once extracted,
+	// so its elements identify it instead.
+	if isExcludeConstraint(constraint) && len(constraint.ExcludeElements) > 0 {
+		key.WriteString(constraint.ExcludeMethod)
+
+		for _, element := range constraint.ExcludeElements {
+			key.WriteString("," + normalizeExpression(element.Element) + " with " + element.Operator)
+		}
+
+		key.WriteString(":" + normalizeExpression(constraint.ExcludeWhere))
+	} else {
+		cols := make([]string, len(constraint.Columns))
+		copy(cols, constraint.Columns)
+		sort.Strings(cols)
+		key.WriteString(strings.Join(cols, ","))
+	}
 
 	if constraint.IsForeignKey() {
 		key.WriteString("->")
@@ -196,7 +329,11 @@ func areConstraintsEqual(c1, c2 *schema.Constraint) bool {
 		return false
 	}
 
-	if !c1.IsCheck() && !isExcludeConstraint(c1) && !equalStringSlices(c1.Columns, c2.Columns) {
+	if !c1.IsCheck() && !isExcludeConstraint(c1) && !slices.Equal(c1.Columns, c2.Columns) {
+		return false
+	}
+
+	if !equalStringSlicesSorted(c1.IncludeColumns, c2.IncludeColumns) {
 		return false
 	}
 
@@ -208,7 +345,7 @@ func areConstraintsEqual(c1, c2 *schema.Constraint) bool {
 			return false
 		}
 
-		if !equalStringSlices(c1.ReferencedColumns, c2.ReferencedColumns) {
+		if !slices.Equal(c1.ReferencedColumns, c2.ReferencedColumns) {
 			return false
 		}
 
@@ -227,10 +364,8 @@ func areConstraintsEqual(c1, c2 *schema.Constraint) bool {
 		}
 	}
 
-	if isExcludeConstraint(c1) {
-		if normalizeExcludeDefinition(c1.Definition) != normalizeExcludeDefinition(c2.Definition) {
-			return false
-		}
+	if isExcludeConstraint(c1) && !excludeConstraintsEqual(c1, c2) {
+		return false
 	}
 
 	if c1.IsDeferrable != c2.IsDeferrable || c1.InitiallyDeferred != c2.InitiallyDeferred {
@@ -255,8 +390,8 @@ func equalStringSlices(a, b []string) bool {
 }
 
 func normalizeTableReference(schemaName, table string) string {
-	normalizedSchema := strings.ToLower(strings.TrimSpace(schemaName))
-	normalizedTable := strings.ToLower(strings.TrimSpace(table))
+	normalizedSchema := strings.TrimSpace(schemaName)
+	normalizedTable := strings.TrimSpace(table)
 
 	if normalizedSchema == "" || normalizedSchema == schema.DefaultSchema {
 		return normalizedTable
@@ -278,6 +413,32 @@ func isExcludeConstraint(c *schema.Constraint) bool {
 	return c.Type == schema.ConstraintExclude
 }
 
+// excludeConstraintsEqual compares the index method, elements, operators and
+// predicates of two EXCLUDE constraints. A constraint recorded without its
+// parts, as in schemas extracted by older pgtofu versions, is compared by its
+// definition instead.
+func excludeConstraintsEqual(c1, c2 *schema.Constraint) bool {
+	if len(c1.ExcludeElements) == 0 || len(c2.ExcludeElements) == 0 {
+		return normalizeExcludeDefinition(c1.Definition) == normalizeExcludeDefinition(c2.Definition)
+	}
+
+	if !strings.EqualFold(c1.ExcludeMethod, c2.ExcludeMethod) ||
+		len(c1.ExcludeElements) != len(c2.ExcludeElements) ||
+		normalizeExpression(c1.ExcludeWhere) != normalizeExpression(c2.ExcludeWhere) {
+		return false
+	}
+
+	for i, element := range c1.ExcludeElements {
+		other := c2.ExcludeElements[i]
+		if normalizeExpression(element.Element) != normalizeExpression(other.Element) ||
+			strings.TrimSpace(element.Operator) != strings.TrimSpace(other.Operator) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func normalizeExcludeDefinition(def string) string {
 	normalized := strings.ToLower(strings.Join(strings.Fields(def), " "))
 	normalized = strings.ReplaceAll(normalized, "( ", "(")
@@ -1,7 +1,12 @@
 package differ
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 type CycleNode interface {
@@ -22,7 +27,28 @@ func FindCycle[T CycleNode](
 		return "unknown cycle"
 	}
 
-	var cycle []string
+	cyclePath := FindCyclePath(remaining, graph)
+	if len(cyclePath) == 0 {
+		cyclePath = remaining
+	}
+
+	cycle := make([]string, len(cyclePath))
+	for i, node := range cyclePath {
+		cycle[i] = graph.FormatNode(node)
+	}
+
+	return strings.Join(cycle, " -> ")
+}
+
+// FindCyclePath returns a cycle among the remaining nodes as a path that
+// starts and ends with the same node, or nil when their edges form none.
+// Nodes and edges are followed in the order given, so sorted input gives a
+// deterministic cycle.
+func FindCyclePath[T CycleNode](
+	remaining []T,
+	graph CycleGraph[T],
+) []T {
+	var cycle []T
 
 	path := make([]T, 0)
 	pathSet := make(map[T]bool)
@@ -41,12 +67,7 @@ func FindCycle[T CycleNode](
 				}
 			}
 
-			cyclePath := append(path[startIdx:], node) //nolint:gocritic
-
-			cycle = make([]string, len(cyclePath))
-			for i, n := range cyclePath {
-				cycle[i] = graph.FormatNode(n)
-			}
+			cycle = append(append([]T(nil), path[startIdx:]...), node)
 
 			return true
 		}
@@ -73,19 +94,209 @@ func FindCycle[T CycleNode](
 	}
 
 	for _, node := range remaining {
-		if !visited[node] {
-			if dfs(node) {
-				break
-			}
+		if !visited[node] && dfs(node) {
+			break
+		}
+	}
+
+	return cycle
+}
+
+// dependencyEdge records why one change has to run after another, so a cycle
+// can be reported reference by reference.
+type dependencyEdge struct {
+	// reference describes the dependency, such as "references view app.v2
+	// in its FROM clause".
+	reference string
+	// excerpt is the referencing SQL around the reference, when there is any.
+	excerpt string
+	// hint suggests how the reference could be taken out of a cycle. Edges
+	// pgtofu imposes itself, such as a trigger on its table, have none.
+	hint string
+}
+
+// CycleStep is one change in a dependency cycle and why it depends on the
+// change of the next step.
+type CycleStep struct {
+	Change    *Change
+	Reference string
+	Excerpt   string
+	Hint      string
+}
+
+// CycleError reports changes that depend on each other in a cycle, so no
+// order can apply them. Steps run around the cycle once: the last step
+// depends on the change of the first.
+type CycleError struct {
+	Steps []CycleStep
+}
+
+func (e *CycleError) Error() string {
+	var b strings.Builder
+
+	labels := make([]string, 0, len(e.Steps)+1)
+	for _, step := range e.Steps {
+		labels = append(labels, cycleNodeLabel(step.Change))
+	}
+
+	if len(labels) > 0 {
+		labels = append(labels, labels[0])
+	}
+
+	b.WriteString("circular dependency detected: " + strings.Join(labels, " -> "))
+
+	for _, step := range e.Steps {
+		b.WriteString("\n  " + cycleNodeLabel(step.Change) + " " + step.Reference)
+
+		if step.Excerpt != "" {
+			fmt.Fprintf(&b, ": %q", step.Excerpt)
 		}
 	}
 
-	if len(cycle) == 0 {
-		cycle = make([]string, len(remaining))
-		for i, node := range remaining {
-			cycle[i] = graph.FormatNode(node)
+	var hints []string
+
+	for _, step := range e.Steps {
+		if step.Hint != "" {
+			hints = append(hints, "\n  "+cycleNodeLabel(step.Change)+": "+step.Hint)
 		}
 	}
 
-	return strings.Join(cycle, " -> ")
+	if len(hints) > 0 {
+		b.WriteString("\nto break the cycle, change one of:")
+		b.WriteString(strings.Join(hints, ""))
+	}
+
+	return b.String()
+}
+
+// cycleNodeLabel names the object of a change with its kind, such as
+// "materialized view app.daily" or "function app.total(integer)".
+func cycleNodeLabel(change *Change) string {
+	kind := strings.ReplaceAll(change.ObjectType, "_", " ")
+	if kind == "" {
+		kind = strings.ToLower(string(change.Type))
+	}
+
+	if change.ObjectName == "" {
+		return kind
+	}
+
+	return kind + " " + change.ObjectName
+}
+
+// describeDependency explains the edge from change to the provider of one of
+// its DependsOn entries.
+func describeDependency(change, provider *Change, dep string) dependencyEdge {
+	target := cycleNodeLabel(provider)
+	text := referencingText(change)
+
+	switch {
+	case provider.ObjectType == "function" && change.ObjectType != "trigger":
+		name, _, _ := strings.Cut(provider.ObjectName, "(")
+
+		where := "its query"
+		if change.ObjectType == "table" || change.ObjectType == "column" {
+			where = "its column defaults"
+		}
+
+		return dependencyEdge{
+			reference: "calls " + target,
+			excerpt:   referenceExcerpt(text, functionCallPattern, name),
+			hint:      "remove the call to " + provider.ObjectName + " from " + where,
+		}
+	case change.ObjectType == "function":
+		return dependencyEdge{
+			reference: "body references " + target,
+			excerpt:   referenceExcerpt(text, viewDependencyPattern, dep),
+			hint: "declare it LANGUAGE plpgsql, whose body is not resolved when it is " +
+				"created, or remove the reference to " + provider.ObjectName,
+		}
+	case change.ObjectType == "view" || change.ObjectType == "materialized_view":
+		return dependencyEdge{
+			reference: "references " + target + " in its FROM clause",
+			excerpt:   referenceExcerpt(text, viewDependencyPattern, dep),
+			hint:      "remove the reference to " + provider.ObjectName + " from its query",
+		}
+	case change.ObjectType == "table" && provider.ObjectType == "table":
+		return dependencyEdge{reference: "references " + target + " in a foreign key"}
+	default:
+		return dependencyEdge{reference: "depends on " + target}
+	}
+}
+
+// describeImplicitDependency explains an ordering edge pgtofu adds between
+// two changes regardless of what their SQL references.
+func describeImplicitDependency(other *Change) dependencyEdge {
+	return dependencyEdge{
+		reference: fmt.Sprintf("must run after %s (%s)", cycleNodeLabel(other), other.Type),
+	}
+}
+
+// referencingText returns the SQL a change's dependencies were read from:
+// a function's body, or the queries and column defaults that may call
+// functions.
+func referencingText(change *Change) string {
+	var fn *schema.Function
+
+	switch change.Type {
+	case ChangeTypeAddFunction:
+		fn, _ = change.Details["function"].(*schema.Function)
+	case ChangeTypeModifyFunction:
+		fn, _ = change.Details["desired"].(*schema.Function)
+	}
+
+	if fn != nil {
+		return fn.Body
+	}
+
+	_, expressions := functionCallSources(change)
+
+	return strings.Join(expressions, "\n")
+}
+
+// excerptContext is how many characters of SQL a reference excerpt keeps on
+// either side of the reference.
+const excerptContext = 24
+
+// referenceExcerpt returns the first match of pattern outside the string
+// literals of text whose first group names the object, with a little of the
+// SQL around it on a single line.
+func referenceExcerpt(text string, pattern *regexp.Regexp, name string) string {
+	masked := schema.MaskStringLiterals(text)
+
+	for _, match := range pattern.FindAllStringSubmatchIndex(masked, -1) {
+		if !sameObjectName(normalizeDependencyIdentifier(text[match[2]:match[3]]), name) {
+			continue
+		}
+
+		start := max(match[0]-excerptContext, 0)
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+
+		end := min(match[1]+excerptContext, len(text))
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+
+		excerpt := strings.Join(strings.Fields(text[start:end]), " ")
+		if start > 0 {
+			excerpt = "..." + excerpt
+		}
+
+		if end < len(text) {
+			excerpt += "..."
+		}
+
+		return excerpt
+	}
+
+	return ""
+}
+
+// sameObjectName reports whether a reference, qualified or not, names the
+// qualified object name.
+func sameObjectName(reference, name string) bool {
+	return reference == name ||
+		(!strings.Contains(reference, ".") && strings.HasSuffix(name, "."+reference))
 }
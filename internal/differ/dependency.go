@@ -1,6 +1,7 @@
 package differ
 
 import (
+	"context"
 	"fmt"
 	"maps"
 	"slices"
@@ -8,10 +9,9 @@ import (
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
-	"github.com/accented-ai/pgtofu/internal/util"
 )
 
-func (d *Differ) resolveDependencies(result *DiffResult) error {
+func (d *Differ) resolveDependencies(ctx context.Context, result *DiffResult) error {
 	graph := newDependencyGraph()
 
 	for i := range result.Changes {
@@ -19,26 +19,59 @@ func (d *Differ) resolveDependencies(result *DiffResult) error {
 	}
 
 	for i := range result.Changes {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf(
+				"diff cancelled during dependency resolution after %d of %d changes: %w",
+				i, len(result.Changes), err,
+			)
+		}
+
 		change := &result.Changes[i]
 
+		// A dropped view's dependencies are what it selected from in the
+		// current schema; objects being added never provide them.
 		for _, dep := range change.DependsOn {
+			if isViewDrop(change.Type) {
+				break
+			}
+
 			for j := range result.Changes {
-				if providesObject(&result.Changes[j], dep) {
-					graph.addEdge(i, j)
+				if provider := &result.Changes[j]; providesObject(provider, dep) {
+					graph.addEdge(i, j, describeDependency(change, provider, dep))
 				}
 			}
 		}
 
 		for j := range result.Changes {
-			if i != j && d.implicitlyDependsOn(change, &result.Changes[j]) {
-				graph.addEdge(i, j)
+			if other := &result.Changes[j]; i != j && d.implicitlyDependsOn(change, other) {
+				graph.addEdge(i, j, describeImplicitDependency(other))
+			}
+		}
+	}
+
+	// Objects are dropped without CASCADE, so each drop waits for the changes
+	// that drop its dependents or remove their references.
+	for _, dep := range findDropDependencies(result) {
+		for _, handler := range dep.handlers {
+			if handler != dep.drop {
+				graph.addEdge(dep.drop, handler, dropDependencyEdge(&dep, &result.Changes[handler]))
 			}
 		}
 	}
 
+	// Compare wraps the error as resolving dependencies.
 	order, err := graph.topologicalSort()
 	if err != nil {
-		return util.WrapError("resolving dependencies", err)
+		return err
+	}
+
+	order, warnings, err := graph.pinMigrations(order)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		result.addWarning(warning)
 	}
 
 	for orderIndex, changeIndex := range order {
@@ -88,7 +121,7 @@ func providesObject(change *Change, objectName string) bool {
 		}
 
 		return false
-	case ChangeTypeAddHypertable:
+	case ChangeTypeAddHypertable, ChangeTypeAddPartition:
 		return change.ObjectName == objectName
 	}
 
@@ -96,7 +129,8 @@ func providesObject(change *Change, objectName string) bool {
 }
 
 func extractSchemaFromChange(change *Change) string {
-	if change.Type == ChangeTypeAddSchema || change.Type == ChangeTypeDropSchema {
+	if change.Type == ChangeTypeAddSchema || change.Type == ChangeTypeDropSchema ||
+		change.Type == ChangeTypeModifySchemaComment {
 		return change.ObjectName
 	}
 
@@ -197,7 +231,105 @@ func constraintUsesColumn(constraintChange *Change, tableName, columnName string
 		}
 	}
 
-	return false
+	return expressionUsesColumn(constraint.CheckExpression, columnLower)
+}
+
+// generationUsesColumn reports whether a change adding a generated column,
+// or giving a column a new generation expression, reads the column in that
+// expression.
+func generationUsesColumn(columnChange *Change, tableName, columnName string) bool {
+	columnKey := "column"
+	if columnChange.Type == ChangeTypeModifyColumnGeneration {
+		columnKey = "new_column"
+	}
+
+	column, ok := columnChange.Details[columnKey].(*schema.Column)
+	if !ok || column.GenerationExpression == "" || strings.EqualFold(column.Name, columnName) {
+		return false
+	}
+
+	changeTableName, _ := columnChange.Details["table"].(string)
+	if !strings.EqualFold(changeTableName, tableName) {
+		return false
+	}
+
+	return expressionUsesColumn(column.GenerationExpression, strings.ToLower(columnName))
+}
+
+// constraintChangeNamed reports whether change adds or recreates the
+// constraint a constraint comment change applies to.
+func constraintChangeNamed(change, commentChange *Change) bool {
+	var constraint *schema.Constraint
+
+	switch change.Type {
+	case ChangeTypeAddConstraint:
+		constraint, _ = change.Details["constraint"].(*schema.Constraint)
+	case ChangeTypeModifyConstraint:
+		constraint, _ = change.Details["desired"].(*schema.Constraint)
+	default:
+		return false
+	}
+
+	name, _ := commentChange.Details["constraint_name"].(string)
+
+	return constraint != nil && strings.EqualFold(constraint.Name, name)
+}
+
+// triggerChangeOn reports whether a trigger change applies to a trigger on
+// the table or view with the given key.
+func triggerChangeOn(change *Change, relationKey string) bool {
+	trigger, ok := change.Details["trigger"].(*schema.Trigger)
+
+	return ok && ViewKey(trigger.Schema, trigger.TableName) == relationKey
+}
+
+// expressionUsesColumn reports whether a CHECK expression mentions the column
+// as an identifier. Table-level CHECK constraints carry no column list, so the
+// expression is the only record of which columns they cover.
+func expressionUsesColumn(expr, columnName string) bool {
+	if columnName == "" {
+		return false
+	}
+
+	lower := strings.ToLower(expr)
+
+	for offset := 0; ; {
+		idx := strings.Index(lower[offset:], columnName)
+		if idx == -1 {
+			return false
+		}
+
+		start, end := offset+idx, offset+idx+len(columnName)
+		if (start == 0 || !isLowerIdentifierPart(lower[start-1])) &&
+			(end == len(lower) || !isLowerIdentifierPart(lower[end]) && lower[end] != '(') {
+			return true
+		}
+
+		offset = end
+	}
+}
+
+// getModifiedColumnFromChange returns the column altered in place by a
+// MODIFY_COLUMN_* change.
+func getModifiedColumnFromChange(change *Change) (tableName, columnName string, ok bool) {
+	switch change.Type {
+	case ChangeTypeModifyColumnType,
+		ChangeTypeModifyColumnNullability,
+		ChangeTypeModifyColumnDefault:
+	default:
+		return "", "", false
+	}
+
+	tableName, _ = change.Details["table"].(string)
+	columnName, _ = change.Details["column_name"].(string)
+
+	return tableName, columnName, tableName != "" && columnName != ""
+}
+
+// addsColumn reports whether change gives a table a column under the name in
+// its "column" detail, which objects using the column wait for.
+func addsColumn(change *Change) bool {
+	return change.Type == ChangeTypeAddColumn || change.Type == ChangeTypeRenameColumn
 }
 
 func getColumnFromChange(change *Change) (tableName, columnName string, ok bool) {
@@ -256,6 +388,21 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 		return true
 	}
 
+	if change.Type == ChangeTypeAddPartition &&
+		otherChange.Type == ChangeTypeDropPartition &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	// A recreated view or trigger is dropped before it is created again.
+	if change.ObjectName == otherChange.ObjectName &&
+		((change.Type == ChangeTypeAddView && otherChange.Type == ChangeTypeDropView) ||
+			(change.Type == ChangeTypeAddMaterializedView &&
+				otherChange.Type == ChangeTypeDropMaterializedView) ||
+			(change.Type == ChangeTypeAddTrigger && otherChange.Type == ChangeTypeDropTrigger)) {
+		return true
+	}
+
 	if change.Type == ChangeTypeAddHypertable &&
 		otherChange.Type == ChangeTypeAddTable &&
 		change.ObjectName == otherChange.ObjectName {
@@ -268,12 +415,38 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 		return true
 	}
 
+	if (change.Type == ChangeTypeAddRefreshPolicy || change.Type == ChangeTypeModifyRefreshPolicy) &&
+		(otherChange.Type == ChangeTypeAddContinuousAggregate ||
+			otherChange.Type == ChangeTypeModifyContinuousAggregate) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeAddDimension &&
+		(otherChange.Type == ChangeTypeAddHypertable || otherChange.Type == ChangeTypeAddColumn) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeModifyCompressionSchedule &&
+		(otherChange.Type == ChangeTypeModifyCompressionPolicy ||
+			otherChange.Type == ChangeTypeAddHypertable) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
 	if change.Type == ChangeTypeDropTable &&
 		otherChange.Type == ChangeTypeDropView &&
 		slices.Contains(otherChange.DependsOn, change.ObjectName) {
 		return true
 	}
 
+	if change.Type == ChangeTypeDropView &&
+		otherChange.Type == ChangeTypeDropTrigger &&
+		triggerChangeOn(otherChange, change.ObjectName) {
+		return true
+	}
+
 	if change.Type == ChangeTypeDropFunction &&
 		otherChange.Type == ChangeTypeDropTrigger &&
 		slices.Contains(otherChange.DependsOn, change.ObjectName) {
@@ -292,12 +465,42 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 		return true
 	}
 
+	if change.Type == ChangeTypeDropContinuousAggregate &&
+		otherChange.Type == ChangeTypeDropRefreshPolicy &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
 	if change.Type == ChangeTypeModifyTableComment &&
 		otherChange.Type == ChangeTypeAddTable &&
 		change.ObjectName == otherChange.ObjectName {
 		return true
 	}
 
+	if change.Type == ChangeTypeModifyCustomTypeComment &&
+		otherChange.Type == ChangeTypeAddCustomType &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeModifyIndexComment &&
+		(otherChange.Type == ChangeTypeAddIndex || otherChange.Type == ChangeTypeModifyIndex) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeModifyTriggerComment &&
+		(otherChange.Type == ChangeTypeAddTrigger || otherChange.Type == ChangeTypeModifyTrigger) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeModifyConstraintComment &&
+		change.ObjectName == otherChange.ObjectName &&
+		(otherChange.Type == ChangeTypeAddTable || constraintChangeNamed(otherChange, change)) {
+		return true
+	}
+
 	if change.Type == ChangeTypeModifyColumnComment {
 		if otherChange.Type == ChangeTypeAddColumn &&
 			change.ObjectName == otherChange.ObjectName {
@@ -356,13 +559,13 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 	}
 
 	if (change.Type == ChangeTypeModifyView || change.Type == ChangeTypeModifyMaterializedView) &&
-		otherChange.Type == ChangeTypeAddColumn &&
+		addsColumn(otherChange) &&
 		tableMatchesDependency(otherChange.ObjectName, change.DependsOn) {
 		return true
 	}
 
 	if (change.Type == ChangeTypeAddView || change.Type == ChangeTypeAddMaterializedView) &&
-		otherChange.Type == ChangeTypeAddColumn &&
+		addsColumn(otherChange) &&
 		tableMatchesDependency(otherChange.ObjectName, change.DependsOn) {
 		return true
 	}
@@ -389,7 +592,7 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 	}
 
 	if (change.Type == ChangeTypeAddIndex || change.Type == ChangeTypeModifyIndex) &&
-		otherChange.Type == ChangeTypeAddColumn {
+		addsColumn(otherChange) {
 		tableName, columnName, ok := getColumnFromChange(otherChange)
 		if ok && indexUsesColumn(change, tableName, columnName) {
 			return true
@@ -397,13 +600,59 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 	}
 
 	if (change.Type == ChangeTypeAddConstraint || change.Type == ChangeTypeModifyConstraint) &&
-		otherChange.Type == ChangeTypeAddColumn {
+		addsColumn(otherChange) {
 		tableName, columnName, ok := getColumnFromChange(otherChange)
 		if ok && constraintUsesColumn(change, tableName, columnName) {
 			return true
 		}
 	}
 
+	// A generated column is added, or takes its new expression, once the
+	// columns the expression reads exist under their new names.
+	if (change.Type == ChangeTypeAddColumn || change.Type == ChangeTypeModifyColumnGeneration) &&
+		addsColumn(otherChange) {
+		tableName, columnName, ok := getColumnFromChange(otherChange)
+		if ok && generationUsesColumn(change, tableName, columnName) {
+			return true
+		}
+	}
+
+	// OWNED BY names a column, so a sequence takes its owner once it exists
+	// itself and the owning table or column has been added.
+	if change.Type == ChangeTypeModifySequence {
+		if otherChange.Type == ChangeTypeAddSequence && otherChange.ObjectName == change.ObjectName {
+			return true
+		}
+
+		owner, _ := change.Details["new_owned_by"].(string)
+		if owner != "" && addsSequenceOwner(otherChange, owner) {
+			return true
+		}
+	}
+
+	// A constraint is added once the columns it covers have their new type,
+	// default and nullability, so its validation scan sees the final column.
+	if change.Type == ChangeTypeAddConstraint || change.Type == ChangeTypeModifyConstraint {
+		tableName, columnName, ok := getModifiedColumnFromChange(otherChange)
+		if ok && constraintUsesColumn(change, tableName, columnName) {
+			return true
+		}
+	}
+
+	// ALTER COLUMN TYPE resets the storage strategy to the default of the new
+	// type, so the strategy is set once the column has its new type.
+	if (change.Type == ChangeTypeModifyColumnStorage ||
+		change.Type == ChangeTypeModifyColumnCompression) &&
+		otherChange.Type == ChangeTypeModifyColumnType {
+		tableName, columnName, ok := getModifiedColumnFromChange(otherChange)
+		table, _ := change.Details["table"].(string)
+		column, _ := change.Details["column_name"].(string)
+
+		if ok && strings.EqualFold(table, tableName) && strings.EqualFold(column, columnName) {
+			return true
+		}
+	}
+
 	if change.Type == ChangeTypeDropColumn &&
 		otherChange.Type == ChangeTypeDropIndex {
 		tableName, columnName, ok := getColumnFromChange(change)
@@ -412,6 +661,66 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 		}
 	}
 
+	if (change.Type == ChangeTypeAddPolicy || change.Type == ChangeTypeModifyPolicy) &&
+		addsColumn(otherChange) {
+		tableName, columnName, ok := getColumnFromChange(otherChange)
+		if ok && policyUsesColumn(change, tableName, columnName) {
+			return true
+		}
+	}
+
+	if change.Type == ChangeTypeDropColumn &&
+		(otherChange.Type == ChangeTypeDropPolicy || otherChange.Type == ChangeTypeModifyPolicy) {
+		tableName, columnName, ok := getColumnFromChange(change)
+		if ok && policyUsesColumn(otherChange, tableName, columnName) {
+			return true
+		}
+	}
+
+	// Row-level security is enabled once the table's new policies exist and
+	// disabled before its old ones go, so no statement in between finds the
+	// table enforcing security with none of the policies it is meant to have.
+	if change.Type == ChangeTypeModifyTableRowSecurity && change.Details["enabled"] == true &&
+		(otherChange.Type == ChangeTypeAddPolicy || otherChange.Type == ChangeTypeModifyPolicy) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeDropPolicy &&
+		otherChange.Type == ChangeTypeModifyTableRowSecurity &&
+		otherChange.Details["enabled"] == false &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	// A column grant waits for its column, and the grants of a relation or
+	// column being dropped are revoked first, so the down migration grants
+	// them again once it is back.
+	if change.Type == ChangeTypeAddGrant && addsColumn(otherChange) {
+		tableName, columnName, ok := getColumnFromChange(otherChange)
+		if ok && grantChangeOn(change, tableName, columnName) {
+			return true
+		}
+	}
+
+	if change.Type == ChangeTypeDropColumn && otherChange.Type == ChangeTypeRevokeGrant {
+		tableName, columnName, ok := getColumnFromChange(change)
+		if ok && grantChangeOn(otherChange, tableName, columnName) {
+			return true
+		}
+	}
+
+	if (change.Type == ChangeTypeDropTable || change.Type == ChangeTypeDropView ||
+		change.Type == ChangeTypeDropMaterializedView || replacesRelation(change)) &&
+		otherChange.Type == ChangeTypeRevokeGrant && change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
+	if change.Type == ChangeTypeAddGrant && replacesRelation(otherChange) &&
+		change.ObjectName == otherChange.ObjectName {
+		return true
+	}
+
 	if change.Type == ChangeTypeDropColumn &&
 		otherChange.Type == ChangeTypeDropConstraint {
 		tableName, columnName, ok := getColumnFromChange(change)
@@ -420,6 +729,34 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 		}
 	}
 
+	// An attribute change of a composite type changes the columns of its
+	// typed tables: indexes and constraints on them come after the attribute
+	// has its new type, and those it replaces or drops go before. A new typed
+	// table is created once its type has its final attributes.
+	switch change.Type {
+	case ChangeTypeAddTable:
+		table, _ := change.Details["table"].(*schema.Table)
+		_, isAttribute := otherChange.Details["attribute"].(string)
+
+		if table != nil && isAttribute && otherChange.Type == ChangeTypeModifyCustomType &&
+			typedOfKey(table) == otherChange.ObjectName {
+			return true
+		}
+	case ChangeTypeAddIndex, ChangeTypeModifyIndex,
+		ChangeTypeAddConstraint, ChangeTypeModifyConstraint:
+		if attributeType(otherChange, "new_type") != "" &&
+			attributeChangeReaches(otherChange, change) {
+			return true
+		}
+	case ChangeTypeModifyCustomType:
+		if (otherChange.Type == ChangeTypeDropIndex ||
+			otherChange.Type == ChangeTypeDropConstraint) &&
+			attributeType(change, "old_type") != "" &&
+			attributeChangeReaches(change, otherChange) {
+			return true
+		}
+	}
+
 	if change.Type == ChangeTypeModifyColumnType &&
 		(otherChange.Type == ChangeTypeDropView || otherChange.Type == ChangeTypeDropMaterializedView) {
 		tableName, _ := change.Details["table"].(string)
@@ -453,12 +790,53 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 		}
 	}
 
+	if (change.Type == ChangeTypeDropContinuousAggregate ||
+		change.Type == ChangeTypeModifyContinuousAggregate) &&
+		isViewDrop(otherChange.Type) &&
+		tableMatchesDependency(change.ObjectName, otherChange.DependsOn) {
+		return true
+	}
+
+	if (change.Type == ChangeTypeAddView || change.Type == ChangeTypeAddMaterializedView) &&
+		otherChange.Type == ChangeTypeModifyMaterializedView &&
+		tableMatchesDependency(otherChange.ObjectName, change.DependsOn) {
+		return true
+	}
+
+	if (change.Type == ChangeTypeAddView || change.Type == ChangeTypeAddMaterializedView) &&
+		(otherChange.Type == ChangeTypeAddContinuousAggregate ||
+			otherChange.Type == ChangeTypeModifyContinuousAggregate) &&
+		tableMatchesDependency(otherChange.ObjectName, change.DependsOn) {
+		return true
+	}
+
+	if isViewDrop(change.Type) && isViewDrop(otherChange.Type) &&
+		tableMatchesDependency(change.ObjectName, otherChange.DependsOn) {
+		return true
+	}
+
 	if change.Type == ChangeTypeAddConstraint && otherChange.Type == ChangeTypeDropConstraint {
 		if isPrimaryKeyConstraintOnSameTable(change, otherChange) {
 			return true
 		}
 	}
 
+	if (change.Type == ChangeTypeAddIndex && otherChange.Type == ChangeTypeDropConstraint) ||
+		(change.Type == ChangeTypeAddConstraint && otherChange.Type == ChangeTypeDropIndex) {
+		if indexAndConstraintShareName(change, otherChange) {
+			return true
+		}
+	}
+
+	// The identity sequence of a column takes the name of the SERIAL
+	// sequence it replaces, or gives it back, once that name is free.
+	if (change.Type == ChangeTypeAddColumnIdentity && otherChange.Type == ChangeTypeDropSequence &&
+		sequenceOwnedByColumn(otherChange, identityChangeColumn(change))) ||
+		(change.Type == ChangeTypeAddSequence && otherChange.Type == ChangeTypeDropColumnIdentity &&
+			sequenceOwnedByColumn(change, identityChangeColumn(otherChange))) {
+		return true
+	}
+
 	if change.Type == ChangeTypeModifyCompressionPolicy {
 		tableName := change.ObjectName
 
@@ -480,6 +858,44 @@ func (d *Differ) implicitlyDependsOn( //nolint:cyclop,gocognit,gocyclo,maintidx
 	return false
 }
 
+// identityChangeColumn returns the column an identity change is on as
+// schema.table.column.
+func identityChangeColumn(change *Change) string {
+	name, _ := change.Details["column_name"].(string)
+	return change.ObjectName + "." + schema.NormalizeIdentifier(name)
+}
+
+// addsSequenceOwner reports whether change adds the table or the column
+// owner names as schema.table.column.
+func addsSequenceOwner(change *Change, owner string) bool {
+	dot := strings.LastIndex(owner, ".")
+	if dot < 0 {
+		return false
+	}
+
+	if change.Type == ChangeTypeAddTable {
+		return strings.EqualFold(change.ObjectName, owner[:dot])
+	}
+
+	if addsColumn(change) {
+		tableName, columnName, ok := getColumnFromChange(change)
+		return ok && strings.EqualFold(tableName+"."+columnName, owner)
+	}
+
+	return false
+}
+
+// sequenceOwnedByColumn reports whether the sequence a change adds or drops
+// is owned by column, given as schema.table.column.
+func sequenceOwnedByColumn(change *Change, column string) bool {
+	seq, ok := change.Details["sequence"].(schema.Sequence)
+	return ok && sequenceOwner(&seq) == column
+}
+
+func isViewDrop(changeType ChangeType) bool {
+	return changeType == ChangeTypeDropView || changeType == ChangeTypeDropMaterializedView
+}
+
 func caChangeMatchesTable(caChange *Change, tableName string) bool {
 	agg, ok := caChange.Details["aggregate"].(*schema.ContinuousAggregate)
 	if !ok {
@@ -494,6 +910,34 @@ func caChangeMatchesTable(caChange *Change, tableName string) bool {
 	return tableMatchesDependency(tableName, []string{hypertableName})
 }
 
+// indexAndConstraintShareName reports whether one change is an index and the
+// other a constraint whose index has the same name, so the object replacing
+// the other has to wait for the name to be freed.
+func indexAndConstraintShareName(a, b *Change) bool {
+	indexChange, constraintChange := a, b
+	if indexChange.ObjectType != "index" {
+		indexChange, constraintChange = b, a
+	}
+
+	idx, ok := indexChange.Details["index"].(*schema.Index)
+	if !ok || idx == nil {
+		return false
+	}
+
+	constraint, ok := constraintChange.Details["constraint"].(*schema.Constraint)
+	if !ok || constraint == nil {
+		return false
+	}
+
+	if constraint.Name != idx.Name && constraint.IndexName != idx.Name {
+		return false
+	}
+
+	tableName, _ := constraintChange.Details["table"].(string)
+
+	return tableMatchesDependency(tableName, []string{idx.QualifiedTableName()})
+}
+
 func isPrimaryKeyConstraintOnSameTable(addChange, dropChange *Change) bool {
 	addConstraint, ok := addChange.Details["constraint"].(*schema.Constraint)
 	if !ok || addConstraint == nil {
@@ -521,14 +965,14 @@ func isPrimaryKeyConstraintOnSameTable(addChange, dropChange *Change) bool {
 
 type dependencyGraph struct {
 	nodes    map[int]*Change
-	edges    map[int]map[int]bool
+	edges    map[int]map[int]dependencyEdge
 	inDegree map[int]int
 }
 
 func newDependencyGraph() *dependencyGraph {
 	return &dependencyGraph{
 		nodes:    make(map[int]*Change),
-		edges:    make(map[int]map[int]bool),
+		edges:    make(map[int]map[int]dependencyEdge),
 		inDegree: make(map[int]int),
 	}
 }
@@ -536,7 +980,7 @@ func newDependencyGraph() *dependencyGraph {
 func (g *dependencyGraph) addNode(index int, change *Change) {
 	g.nodes[index] = change
 	if g.edges[index] == nil {
-		g.edges[index] = make(map[int]bool)
+		g.edges[index] = make(map[int]dependencyEdge)
 	}
 
 	if _, exists := g.inDegree[index]; !exists {
@@ -544,16 +988,18 @@ func (g *dependencyGraph) addNode(index int, change *Change) {
 	}
 }
 
-func (g *dependencyGraph) addEdge(from, to int) {
+// addEdge makes from run after to. The first reason recorded for a pair of
+// changes is kept.
+func (g *dependencyGraph) addEdge(from, to int, edge dependencyEdge) {
 	if g.edges[from] == nil {
-		g.edges[from] = make(map[int]bool)
+		g.edges[from] = make(map[int]dependencyEdge)
 	}
 
-	if g.edges[from][to] {
+	if _, exists := g.edges[from][to]; exists {
 		return
 	}
 
-	g.edges[from][to] = true
+	g.edges[from][to] = edge
 	g.inDegree[from]++
 }
 
@@ -580,7 +1026,7 @@ func (g *dependencyGraph) topologicalSort() ([]int, error) {
 		result = append(result, node)
 
 		for dependent := range g.edges {
-			if g.edges[dependent][node] {
+			if _, depends := g.edges[dependent][node]; depends {
 				inDegree[dependent]--
 				if inDegree[dependent] == 0 {
 					queue = append(queue, dependent)
@@ -591,8 +1037,7 @@ func (g *dependencyGraph) topologicalSort() ([]int, error) {
 	}
 
 	if len(result) != len(g.nodes) {
-		cycle := g.findCycle(inDegree)
-		return nil, fmt.Errorf("circular dependency detected: %s", cycle)
+		return nil, g.cycleError(inDegree)
 	}
 
 	return result, nil
@@ -617,6 +1062,13 @@ func (g *dependencyGraph) sortQueue(queue []int) {
 			return priorityI < priorityJ
 		}
 
+		// Comparators collect changes from maps, so the change index alone
+		// would make ties between same-priority changes on one object
+		// order differently between runs.
+		if changeI.Description != changeJ.Description {
+			return changeI.Description < changeJ.Description
+		}
+
 		return queue[i] < queue[j]
 	})
 }
@@ -635,32 +1087,56 @@ func (g *dependencyGraph) getObjectNameForSorting(change *Change) string {
 
 func getChangePriority(changeType ChangeType) int { //nolint:cyclop
 	switch changeType {
-	case ChangeTypeAddSchema:
+	case ChangeTypeAddSchema, ChangeTypeModifySchemaComment:
 		return 1
 	case ChangeTypeAddExtension, ChangeTypeModifyExtension:
 		return 2
 	case ChangeTypeAddCustomType:
 		return 3
-	case ChangeTypeAddSequence:
+	case ChangeTypeModifyCustomTypeComment:
+		return 5
+	case ChangeTypeAddSequence, ChangeTypeModifySequence:
 		return 4
 	case ChangeTypeAddTable:
 		return 10
-	case ChangeTypeAddColumn:
+	case ChangeTypeAddColumn, ChangeTypeRenameColumn:
 		return 20
 	case ChangeTypeAddConstraint:
 		return 30
+	case ChangeTypeModifyConstraintComment:
+		return 31
 	case ChangeTypeAddIndex:
 		return 40
+	case ChangeTypeModifyIndexComment:
+		return 41
 	case ChangeTypeModifyTableComment:
 		return 11
+	case ChangeTypeRecreateTable:
+		return 12
 	case ChangeTypeModifyColumnComment:
 		return 21
+	case ChangeTypeModifyColumnGeneration:
+		// Before the type and default of a column that stops being generated,
+		// which PostgreSQL refuses while it still has an expression.
+		return 21
+	case ChangeTypeDropColumnIdentity:
+		// Before a default drawing from a sequence replaces the identity,
+		// which PostgreSQL refuses while the column still has one.
+		return 21
 	case ChangeTypeModifyColumnType:
 		return 22
-	case ChangeTypeModifyColumnNullability:
-		return 23
 	case ChangeTypeModifyColumnDefault:
+		return 23
+	case ChangeTypeModifyColumnNullability:
 		return 24
+	case ChangeTypeModifyColumnStorage:
+		return 25
+	case ChangeTypeModifyColumnCompression:
+		return 26
+	case ChangeTypeAddColumnIdentity, ChangeTypeModifyColumnIdentity:
+		// After the default of a SERIAL column is dropped, which PostgreSQL
+		// requires before the column becomes an identity column.
+		return 27
 	case ChangeTypeAddView:
 		return 50
 	case ChangeTypeModifyView:
@@ -675,20 +1151,41 @@ func getChangePriority(changeType ChangeType) int { //nolint:cyclop
 		return 71
 	case ChangeTypeAddTrigger:
 		return 80
+	case ChangeTypeModifyTriggerComment:
+		return 81
+	case ChangeTypeAddPolicy:
+		return 81
+	case ChangeTypeModifyPolicy:
+		return 82
+	case ChangeTypeModifyTableRowSecurity:
+		return 83
+	case ChangeTypeRevokeGrant:
+		return 84
+	case ChangeTypeAddGrant:
+		return 85
 	case ChangeTypeAddHypertable:
 		return 90
+	case ChangeTypeAddDimension:
+		return 90
 	case ChangeTypeAddCompressionPolicy:
 		return 91
+	case ChangeTypeModifyCompressionSchedule:
+		return 93
 	case ChangeTypeAddRetentionPolicy:
 		return 92
 	case ChangeTypeAddContinuousAggregate:
 		return 100
+	case ChangeTypeAddRefreshPolicy, ChangeTypeModifyRefreshPolicy,
+		ChangeTypeModifyCACompression:
+		return 101
 	default:
 		return 1000
 	}
 }
 
-func (g *dependencyGraph) findCycle(remainingInDegree map[int]int) string {
+// cycleError reports a cycle among the changes the topological sort could
+// not order, with the reason for every edge of it.
+func (g *dependencyGraph) cycleError(remainingInDegree map[int]int) error {
 	remaining := make([]int, 0, len(remainingInDegree))
 	for node, degree := range remainingInDegree {
 		if degree > 0 {
@@ -696,18 +1193,11 @@ func (g *dependencyGraph) findCycle(remainingInDegree map[int]int) string {
 		}
 	}
 
+	sort.Ints(remaining)
+
 	graph := CycleGraph[int]{
 		GetEdges: func(node int) []int {
-			if g.edges[node] == nil {
-				return nil
-			}
-
-			deps := make([]int, 0, len(g.edges[node]))
-			for dep := range g.edges[node] {
-				deps = append(deps, dep)
-			}
-
-			return deps
+			return slices.Sorted(maps.Keys(g.edges[node]))
 		},
 		FormatNode: func(node int) string {
 			change := g.nodes[node]
@@ -718,5 +1208,22 @@ func (g *dependencyGraph) findCycle(remainingInDegree map[int]int) string {
 		},
 	}
 
-	return FindCycle(remaining, graph)
+	path := FindCyclePath(remaining, graph)
+	if len(path) == 0 {
+		return fmt.Errorf("circular dependency detected: %s", FindCycle(remaining, graph))
+	}
+
+	steps := make([]CycleStep, 0, len(path)-1)
+
+	for i := range len(path) - 1 {
+		edge := g.edges[path[i]][path[i+1]]
+		steps = append(steps, CycleStep{
+			Change:    g.nodes[path[i]],
+			Reference: edge.reference,
+			Excerpt:   edge.excerpt,
+			Hint:      edge.hint,
+		})
+	}
+
+	return &CycleError{Steps: steps}
 }
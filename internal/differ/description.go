@@ -0,0 +1,213 @@
+package differ
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// descriptionTemplate is the fmt format of one kind of change description.
+// Every Description the differ writes comes from a template below, so each
+// names the database and the desired schema the same way, and the plan, the
+// diff output and migration headers, which all print Description, read alike.
+type descriptionTemplate string
+
+// The lifecycle templates take the capitalized kind of the object and its
+// name. The comment templates take the kind in lowercase and the name.
+const (
+	descAdded descriptionTemplate = "%s %s is in desired schema but not in database " +
+		"(will be created)"
+	descDropped descriptionTemplate = "%s %s exists in database but not in desired schema " +
+		"(will be dropped)"
+	descReplaced descriptionTemplate = "%s %s differs between database and desired schema " +
+		"(will be replaced)"
+	descUpdated descriptionTemplate = "%s %s differs between database and desired schema " +
+		"(will be updated)"
+
+	descCommentAdded descriptionTemplate = "Comment on %s %s is in desired schema " +
+		"but not in database (will be set)"
+	descCommentDropped descriptionTemplate = "Comment on %s %s exists in database " +
+		"but not in desired schema (will be removed)"
+	descCommentChanged descriptionTemplate = "Comment on %s %s differs between database " +
+		"and desired schema (will be updated)"
+
+	// descValueDiffers takes what differs, the object and the value in the
+	// database and in the desired schema.
+	descValueDiffers descriptionTemplate = "%s differs: %s is %s in database, " +
+		"%s in desired schema"
+	// descValueDiffersBy adds a consequence to descValueDiffers.
+	descValueDiffersBy descriptionTemplate = descValueDiffers + " (%s)"
+)
+
+// Templates for changes that do not fit the lifecycle of a single object.
+const (
+	// descRecreateTable takes the table and the number of changes replaced.
+	descRecreateTable descriptionTemplate = "Table %s differs between database " +
+		"and desired schema in ways ALTER TABLE cannot apply " +
+		"(will be recreated, replacing %d changes)"
+	// descDropForRecreation and descRecreated take the capitalized kind, the
+	// name and why the object is recreated.
+	descDropForRecreation descriptionTemplate = "%s %s is dropped for %s (will be recreated)"
+	descRecreated         descriptionTemplate = "%s %s is recreated from desired schema after %s"
+	// descRenamedConstraint takes the constraint type, its name in the
+	// database, its table and its name in the desired schema.
+	descRenamedConstraint descriptionTemplate = "%s constraint %s on %s is named %s " +
+		"in desired schema (will be renamed)"
+	// descRenamedColumn takes the column in the database and its name in the
+	// desired schema.
+	descRenamedColumn descriptionTemplate = "Column %s is named %s in desired schema " +
+		"(will be renamed)"
+	// descRelocatedFunction takes the capitalized kind, the signature and the
+	// schema it has in the desired schema.
+	descRelocatedFunction descriptionTemplate = "%s %s is in schema %s in desired schema " +
+		"(will be moved)"
+	// descRenamedFunction takes the capitalized kind, the signature and its
+	// name in the desired schema.
+	descRenamedFunction descriptionTemplate = "%s %s is named %s in desired schema " +
+		"(will be renamed)"
+	// descEnumValueAdded takes the value, the type and where it goes.
+	descEnumValueAdded descriptionTemplate = "Enum value '%s' of type %s is in desired schema " +
+		"but not in database (will be added %s)"
+	// descEnumValueDropped takes the value and the type.
+	descEnumValueDropped descriptionTemplate = "Enum value '%s' of type %s exists in database " +
+		"but not in desired schema (will be removed)"
+	// descSequenceDiffers takes the sequence and its differing options.
+	descSequenceDiffers descriptionTemplate = "Sequence %s differs between database " +
+		"and desired schema (%s)"
+	// descSequenceOwnerSet takes a sequence the desired schema adds and the
+	// column owning it.
+	descSequenceOwnerSet descriptionTemplate = "Sequence %s is owned by %s in desired schema " +
+		"(OWNED BY will be set once the column exists)"
+	// descIdentityDiffers takes the column and the differing options of its
+	// identity.
+	descIdentityDiffers descriptionTemplate = "Identity of column %s differs between database " +
+		"and desired schema (%s)"
+	// descHypertableAdded takes the table, its time column and its chunk
+	// interval.
+	descHypertableAdded descriptionTemplate = "Table %s is a hypertable in desired schema " +
+		"but not in database (will be converted, time column: %s, interval: %s)"
+	// descHypertableDropped takes the table.
+	descHypertableDropped descriptionTemplate = "Table %s is a hypertable in database " +
+		"but not in desired schema (will be converted to a regular table)"
+	// descSettingsDiffer takes what differs and the object it belongs to.
+	descSettingsDiffer descriptionTemplate = "%s of %s differ between database " +
+		"and desired schema (will be updated)"
+	// descManualDiffers and descManualDropped take the capitalized kind and the
+	// name of an object TimescaleDB cannot change in place.
+	descManualDiffers descriptionTemplate = "%s %s differs between database " +
+		"and desired schema (manual migration required)"
+	descManualDropped descriptionTemplate = "%s %s exists in database " +
+		"but not in desired schema (manual migration required)"
+	// descGranted and descRevoked take what is granted, Grant or Grant option,
+	// the privileges, the grantee and the object or column.
+	descGranted descriptionTemplate = "%s of %s to %s on %s is in desired schema " +
+		"but not in database (will be granted)"
+	descRevoked descriptionTemplate = "%s of %s to %s on %s exists in database " +
+		"but not in desired schema (will be revoked)"
+)
+
+// describe fills template with args.
+func describe(template descriptionTemplate, args ...any) string {
+	return fmt.Sprintf(string(template), args...)
+}
+
+// describeAdded describes an object of kind that only the desired schema has.
+func describeAdded(kind, name string) string {
+	return describe(descAdded, capitalize(kind), name)
+}
+
+// describeDropped describes an object of kind that only the database has.
+func describeDropped(kind, name string) string {
+	return describe(descDropped, capitalize(kind), name)
+}
+
+// describeReplaced describes an object of kind whose definitions differ in a
+// way that replaces it, or that is too long to quote.
+func describeReplaced(kind, name string) string {
+	return describe(descReplaced, capitalize(kind), name)
+}
+
+// describeUpdated describes an object of kind whose definitions differ in a
+// way that is changed in place.
+func describeUpdated(kind, name string) string {
+	return describe(descUpdated, capitalize(kind), name)
+}
+
+// describeValue describes a property, what, of name that is current in the
+// database and desired in the desired schema. An empty value reads as none.
+func describeValue(what, name, current, desired string) string {
+	return describe(descValueDiffers, what, name, valueOrNone(current), valueOrNone(desired))
+}
+
+// describeValueBy is describeValue followed by the consequence of the change.
+func describeValueBy(what, name, current, desired, consequence string) string {
+	return describe(descValueDiffersBy, what, name,
+		valueOrNone(current), valueOrNone(desired), consequence)
+}
+
+// describeGrant describes the grant or revocation of the privileges of
+// grant, or of their grant option alone when optionOnly is set.
+func describeGrant(changeType ChangeType, grant *schema.Grant, optionOnly bool) string {
+	what := "Grant"
+	if optionOnly {
+		what = "Grant option"
+	}
+
+	template := descGranted
+	if changeType == ChangeTypeRevokeGrant {
+		template = descRevoked
+	}
+
+	return describe(template, what, strings.Join(grant.Privileges, ", "),
+		grant.GranteeName(), grant.Target())
+}
+
+// describeComment describes the comment on an object of kind going from
+// oldComment in the database to newComment in the desired schema.
+func describeComment(kind, name, oldComment, newComment string) string {
+	switch {
+	case oldComment == "":
+		return describe(descCommentAdded, kind, name)
+	case newComment == "":
+		return describe(descCommentDropped, kind, name)
+	default:
+		return describe(descCommentChanged, kind, name)
+	}
+}
+
+// formatStorageParams lists storage parameters as WITH would, in name order.
+func formatStorageParams(params map[string]string) string {
+	params = normalizeStorageParams(params)
+	if len(params) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		pairs = append(pairs, name+"="+params[name])
+	}
+
+	return "(" + strings.Join(pairs, ", ") + ")"
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+
+	return value
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -1,9 +1,16 @@
 package differ
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 	"github.com/accented-ai/pgtofu/internal/util"
 )
@@ -24,6 +31,68 @@ type Options struct {
 	DetectRenames         bool
 	IgnoreIndexNames      bool
 	IgnoreConstraintNames bool
+	// NormalizeCommentWhitespace compares comments with runs of whitespace,
+	// line breaks included, taken as a single space and surrounding
+	// whitespace ignored, so the same text wrapped differently compares
+	// equal. Changed comments are still set to the exact desired text. When
+	// unset, comments must match exactly apart from line breaks, which a
+	// generated multi-line comment loses in the database.
+	NormalizeCommentWhitespace bool
+	// IfNotExistsMeansEnsureOnly makes tables and indexes declared with IF NOT
+	// EXISTS ensure-only: they are created when missing, but differences from
+	// an existing definition are reported as notes instead of changes.
+	// UNLOGGED tables are not covered: the parser skips them.
+	IfNotExistsMeansEnsureOnly bool
+	// TableRecreation, when set, collapses the ALTER sequence of a table that
+	// crosses one of its thresholds into a single RECREATE_TABLE change. Nil
+	// always alters tables in place.
+	TableRecreation *TableRecreationThresholds
+	// EnforceSequenceStart compares the START WITH of existing sequences and
+	// alters it when it differs. By default START WITH only applies when a
+	// sequence is created. Either way the current value is never moved: that
+	// takes ALTER SEQUENCE ... RESTART, which is never generated.
+	EnforceSequenceStart bool
+	// SwapMaterializedViewIndexes rebuilds a changed unique index of a
+	// materialized view by creating the new definition under a temporary
+	// name, dropping the old index and renaming the new one, so REFRESH
+	// MATERIALIZED VIEW CONCURRENTLY always finds a unique index.
+	SwapMaterializedViewIndexes bool
+	// PlanSize, when set, adds a PLAN_TOO_LARGE warning for every limit the
+	// plan exceeds. It never changes which changes are produced.
+	PlanSize *PlanSizeLimits
+	// DetectColumnRenames pairs a column dropped from a table with a column
+	// added to it when the two have the same type, nullability, default,
+	// comment and everything else, and nothing else on the table matches
+	// either. Each pair becomes a RENAME_COLUMN change that keeps the data.
+	// Columns with more than one match are dropped and added, with an
+	// AMBIGUOUS_COLUMN_RENAME warning.
+	DetectColumnRenames bool
+	// AnalyzeFunctionBodies searches the function bodies of the desired
+	// schema for uses of the columns the plan drops or retypes, and adds a
+	// FUNCTION_COLUMN_REFERENCE warning for each column that appears used.
+	// The search is heuristic and never changes which changes are produced.
+	AnalyzeFunctionBodies bool
+	// Cache, when set, skips comparing the definitions it recorded as equal
+	// on an earlier run, and records the ones this run finds equal.
+	Cache *ComparisonCache
+	// Progress, when set, is called after every comparison pass.
+	Progress ProgressFunc
+	// IncludeExtensionObjects compares the objects TimescaleDB manages
+	// itself: everything in its internal, catalog, config and cache schemas,
+	// and the materialization hypertables of continuous aggregates. They are
+	// skipped by default, with a note counting them.
+	IncludeExtensionObjects bool
+	// TargetSchemas, when set, limits the comparison to the objects of these
+	// schemas, in both the current and the desired schema. Objects of other
+	// schemas are skipped: those of the database with a note counting them,
+	// those the desired schema declares with an OUTSIDE_TARGET_SCHEMAS warning.
+	TargetSchemas []string
+	// TypeEquivalences adds to the built-in type aliases of
+	// schema.CanonicalDataType, from a type name to the one it is compared
+	// as, for types PostgreSQL spells differently from the schema files, such
+	// as {"public.citext": "citext"} for an extension type the database
+	// reports qualified.
+	TypeEquivalences map[string]string
 }
 
 func DefaultOptions() *Options {
@@ -34,9 +103,73 @@ func DefaultOptions() *Options {
 		DetectRenames:         true,
 		IgnoreIndexNames:      false,
 		IgnoreConstraintNames: false,
+
+		NormalizeCommentWhitespace: true,
+		IfNotExistsMeansEnsureOnly: false,
 	}
 }
 
+// optionsHashLength is how many hex digits of the options digest are kept;
+// it only needs to tell option sets apart, not resist collisions.
+const optionsHashLength = 12
+
+// Hash identifies the options that affect which changes are produced, so a
+// generated migration can record how it was diffed. PlanSize,
+// AnalyzeFunctionBodies, Cache and Progress are not part of the hash.
+func (o *Options) Hash() string {
+	if o == nil {
+		o = DefaultOptions()
+	}
+
+	fields := []string{
+		fmt.Sprintf("ignore_comments=%t", o.IgnoreComments),
+		fmt.Sprintf("ignore_owners=%t", o.IgnoreOwners),
+		fmt.Sprintf("ignore_tablespaces=%t", o.IgnoreTablespaces),
+		fmt.Sprintf("detect_renames=%t", o.DetectRenames),
+		fmt.Sprintf("ignore_index_names=%t", o.IgnoreIndexNames),
+		fmt.Sprintf("ignore_constraint_names=%t", o.IgnoreConstraintNames),
+		fmt.Sprintf("if_not_exists_ensure_only=%t", o.IfNotExistsMeansEnsureOnly),
+	}
+
+	if o.TableRecreation != nil {
+		fields = append(fields, fmt.Sprintf("table_recreation=%g/%d",
+			o.TableRecreation.ColumnChangeRatio, o.TableRecreation.PrimaryKeyColumnChanges))
+	}
+
+	if !o.NormalizeCommentWhitespace {
+		fields = append(fields, "exact_comments=true")
+	}
+
+	if o.EnforceSequenceStart {
+		fields = append(fields, "enforce_sequence_start=true")
+	}
+
+	if o.SwapMaterializedViewIndexes {
+		fields = append(fields, "swap_materialized_view_indexes=true")
+	}
+
+	if o.DetectColumnRenames {
+		fields = append(fields, "detect_column_renames=true")
+	}
+
+	if o.IncludeExtensionObjects {
+		fields = append(fields, "include_extension_objects=true")
+	}
+
+	if len(o.TargetSchemas) > 0 {
+		fields = append(fields, targetSchemasHash(o.TargetSchemas))
+	}
+
+	for _, alias := range slices.Sorted(maps.Keys(o.TypeEquivalences)) {
+		fields = append(fields,
+			fmt.Sprintf("type_equivalence=%s=%s", alias, o.TypeEquivalences[alias]))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+
+	return hex.EncodeToString(sum[:])[:optionsHashLength]
+}
+
 func New(opts *Options) *Differ {
 	if opts == nil {
 		opts = DefaultOptions()
@@ -48,7 +181,7 @@ func New(opts *Options) *Differ {
 		indexComp:    NewIndexComparator(opts),
 		viewComp:     NewViewComparator(opts),
 		functionComp: NewFunctionComparator(opts),
-		triggerComp:  NewTriggerComparator(),
+		triggerComp:  NewTriggerComparator(opts),
 	}
 }
 
@@ -63,29 +196,43 @@ func (d *Differ) compareSchemas(result *DiffResult) {
 		desiredSchemas[sch.Name] = sch
 	}
 
-	for key, sch := range desiredSchemas {
+	for _, key := range slices.Sorted(maps.Keys(desiredSchemas)) {
+		sch := desiredSchemas[key]
+
 		if _, exists := currentSchemas[key]; !exists {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeAddSchema,
 				Severity:    SeveritySafe,
-				Description: "Add schema: " + sch.Name,
+				Description: describeAdded("schema", sch.Name),
 				ObjectType:  "schema",
 				ObjectName:  sch.Name,
 				Details:     map[string]any{"schema": sch},
 			})
+
+			d.addSchemaCommentChange(result, &sch, "")
+
+			continue
+		}
+
+		if current := currentSchemas[key]; !d.options.commentsEqual(current.Comment, sch.Comment) {
+			d.addSchemaCommentChange(result, &sch, current.Comment)
 		}
 	}
 
-	for key, sch := range currentSchemas {
+	for _, key := range slices.Sorted(maps.Keys(currentSchemas)) {
+		sch := currentSchemas[key]
+
 		if _, exists := desiredSchemas[key]; !exists {
-			if sch.Name == schema.DefaultSchema {
+			// PostgreSQL refuses to drop public, which a desired schema that
+			// declares nothing in it leaves out.
+			if schema.NormalizeSchemaName(sch.Name) == schema.DefaultSchema {
 				continue
 			}
 
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropSchema,
 				Severity:    SeverityBreaking,
-				Description: "Drop schema: " + sch.Name,
+				Description: describeDropped("schema", sch.Name),
 				ObjectType:  "schema",
 				ObjectName:  sch.Name,
 				Details:     map[string]any{"schema": sch},
@@ -94,7 +241,40 @@ func (d *Differ) compareSchemas(result *DiffResult) {
 	}
 }
 
+func (d *Differ) addSchemaCommentChange(result *DiffResult, sch *schema.Schema, oldComment string) {
+	if d.options.IgnoreComments || (sch.Comment == "" && oldComment == "") {
+		return
+	}
+
+	severity := SeveritySafe
+	if sch.Comment == "" {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:        ChangeTypeModifySchemaComment,
+		Severity:    severity,
+		Description: describeComment("schema", sch.Name, oldComment, sch.Comment),
+		ObjectType:  "schema",
+		ObjectName:  sch.Name,
+		Details: map[string]any{
+			"schema_name": sch.Name,
+			"old_comment": oldComment,
+			"new_comment": sch.Comment,
+		},
+	})
+}
+
 func (d *Differ) Compare(current, desired *schema.Database) (*DiffResult, error) {
+	return d.CompareContext(context.Background(), current, desired)
+}
+
+// CompareContext is Compare with cancellation checked between comparison
+// passes and during dependency resolution.
+func (d *Differ) CompareContext(
+	ctx context.Context,
+	current, desired *schema.Database,
+) (*DiffResult, error) {
 	if current == nil {
 		return nil, errors.New("current schema is nil")
 	}
@@ -104,37 +284,117 @@ func (d *Differ) Compare(current, desired *schema.Database) (*DiffResult, error)
 	}
 
 	result := &DiffResult{
-		Current:  current,
-		Desired:  desired,
-		Changes:  []Change{},
-		Warnings: []string{},
-	}
-
-	d.compareSchemas(result)
-	d.compareExtensions(result)
-	d.compareCustomTypes(result)
-	d.compareSequences(result)
-	d.tableComp.Compare(result)
-	d.indexComp.Compare(result)
-	d.compareViews(result)
-	d.compareMaterializedViews(result)
-	d.functionComp.Compare(result)
-	d.triggerComp.Compare(result)
-	d.compareHypertables(result)
-	d.compareContinuousAggregates(result)
-	d.filterDuplicateCAIndexChanges(result)
-	d.processViewRecreationForColumnTypeChanges(result)
-	d.processContinuousAggregateRecreationForColumnChanges(result)
-
-	if err := d.resolveDependencies(result); err != nil {
+		Current:     current,
+		Desired:     desired,
+		Changes:     []Change{},
+		Diagnostics: []diag.Warning{},
+		Warnings:    []string{},
+		Notes:       []string{},
+		OptionsHash: d.options.Hash(),
+	}
+
+	d.skipExtensionObjects(result)
+	d.skipUntargetedSchemas(result)
+
+	if d.options.Cache != nil {
+		d.options.Cache.begin(result)
+	}
+
+	if err := d.runPasses(ctx, result); err != nil {
+		return nil, err
+	}
+
+	d.dropDuplicateChanges(result)
+	annotateSources(result)
+
+	if err := d.resolveDependencies(ctx, result); err != nil {
 		return nil, util.WrapError("resolving dependencies", err)
 	}
 
 	d.computeStats(result)
+	d.adviseOnPlanSize(result)
 
 	return result, nil
 }
 
+func (d *Differ) comparePasses(result *DiffResult) []comparePass {
+	current, desired := result.Current, result.Desired
+
+	return []comparePass{
+		{"schema comparison", len(current.Schemas) + len(desired.Schemas), d.compareSchemas},
+		{
+			"extension comparison",
+			len(current.Extensions) + len(desired.Extensions),
+			d.compareExtensions,
+		},
+		{
+			"custom type comparison",
+			len(current.CustomTypes) + len(desired.CustomTypes),
+			d.compareCustomTypes,
+		},
+		{
+			"sequence comparison",
+			len(current.Sequences) + len(desired.Sequences),
+			d.compareSequences,
+		},
+		{"table comparison", len(current.Tables) + len(desired.Tables), d.tableComp.Compare},
+		{"typed table following", 0, d.followTypedTables},
+		{"foreign key cycle breaking", 0, d.breakForeignKeyCycles},
+		{"index comparison", countIndexes(current) + countIndexes(desired), d.indexComp.Compare},
+		{"ensure-only filtering", 0, d.applyEnsureOnly},
+		{"table recreation", 0, d.recreateRewrittenTables},
+		{"view comparison", len(current.Views) + len(desired.Views), d.compareViews},
+		{
+			"materialized view comparison",
+			len(current.MaterializedViews) + len(desired.MaterializedViews),
+			d.compareMaterializedViews,
+		},
+		{
+			"function comparison",
+			len(current.Functions) + len(desired.Functions),
+			d.functionComp.Compare,
+		},
+		{
+			"trigger comparison",
+			len(current.Triggers) + len(desired.Triggers),
+			d.triggerComp.Compare,
+		},
+		{
+			"hypertable comparison",
+			len(current.Hypertables) + len(desired.Hypertables),
+			d.compareHypertables,
+		},
+		{
+			"continuous aggregate comparison",
+			len(current.ContinuousAggregates) + len(desired.ContinuousAggregates),
+			d.compareContinuousAggregates,
+		},
+		{"continuous aggregate index filtering", 0, d.filterDuplicateCAIndexChanges},
+		{"view recreation", 0, d.processViewRecreationForColumnTypeChanges},
+		{"view column list recreation", 0, d.processViewRecreationForColumnListChanges},
+		{
+			"continuous aggregate recreation",
+			0,
+			d.processContinuousAggregateRecreationForColumnChanges,
+		},
+		{
+			"continuous aggregate view recreation",
+			0,
+			d.processViewRecreationForContinuousAggregates,
+		},
+		{
+			"materialized view dependent recreation",
+			0,
+			d.processViewRecreationForMaterializedViews,
+		},
+		{"grant comparison", len(current.Grants) + len(desired.Grants), d.compareGrants},
+		{"function dependency extraction", 0, d.addFunctionDependencies},
+		{"remote access detection", 0, d.noteRemoteAccess},
+		{"function body analysis", 0, d.noteFunctionColumnReferences},
+		{"concurrent refresh hazard detection", 0, d.detectConcurrentRefreshHazards},
+	}
+}
+
 func (d *Differ) compareExtensions(result *DiffResult) {
 	currentExts := make(map[string]schema.Extension)
 	for _, ext := range result.Current.Extensions {
@@ -146,13 +406,15 @@ func (d *Differ) compareExtensions(result *DiffResult) {
 		desiredExts[ext.Name] = ext
 	}
 
-	for key, ext := range desiredExts {
+	for _, key := range slices.Sorted(maps.Keys(desiredExts)) {
+		ext := desiredExts[key]
+
 		if current, exists := currentExts[key]; exists {
 			if extensionNeedsUpdate(current, ext) {
 				result.Changes = append(result.Changes, Change{
 					Type:        ChangeTypeModifyExtension,
 					Severity:    SeverityPotentiallyBreaking,
-					Description: "Modify extension: " + ext.Name,
+					Description: describeUpdated("extension", ext.Name),
 					ObjectType:  "extension",
 					ObjectName:  ext.Name,
 					Details: map[string]any{
@@ -168,19 +430,21 @@ func (d *Differ) compareExtensions(result *DiffResult) {
 		result.Changes = append(result.Changes, Change{
 			Type:        ChangeTypeAddExtension,
 			Severity:    SeveritySafe,
-			Description: "Add extension: " + ext.Name,
+			Description: describeAdded("extension", ext.Name),
 			ObjectType:  "extension",
 			ObjectName:  ext.Name,
 			Details:     map[string]any{"extension": ext},
 		})
 	}
 
-	for key, ext := range currentExts {
+	for _, key := range slices.Sorted(maps.Keys(currentExts)) {
+		ext := currentExts[key]
+
 		if _, exists := desiredExts[key]; !exists {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropExtension,
 				Severity:    SeverityBreaking,
-				Description: "Drop extension: " + ext.Name,
+				Description: describeDropped("extension", ext.Name),
 				ObjectType:  "extension",
 				ObjectName:  ext.Name,
 				Details:     map[string]any{"extension": ext},
@@ -212,25 +476,31 @@ func (d *Differ) compareCustomTypes(result *DiffResult) {
 		desiredTypes[TableKey(ct.Schema, ct.Name)] = ct
 	}
 
-	for key, ct := range desiredTypes {
+	for _, key := range slices.Sorted(maps.Keys(desiredTypes)) {
+		ct := desiredTypes[key]
+
 		if _, exists := currentTypes[key]; !exists {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeAddCustomType,
 				Severity:    SeveritySafe,
-				Description: fmt.Sprintf("Add %s type: %s", ct.Type, ct.Name),
+				Description: describeAdded(ct.Type+" type", ct.QualifiedName()),
 				ObjectType:  "type",
 				ObjectName:  key,
 				Details:     map[string]any{"custom_type": ct},
 			})
+
+			d.addCustomTypeCommentChange(result, key, &ct, "")
 		}
 	}
 
-	for key, ct := range currentTypes {
+	for _, key := range slices.Sorted(maps.Keys(currentTypes)) {
+		ct := currentTypes[key]
+
 		if _, exists := desiredTypes[key]; !exists {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropCustomType,
 				Severity:    SeverityBreaking,
-				Description: fmt.Sprintf("Drop %s type: %s", ct.Type, ct.Name),
+				Description: describeDropped(ct.Type+" type", ct.QualifiedName()),
 				ObjectType:  "type",
 				ObjectName:  key,
 				Details:     map[string]any{"custom_type": ct},
@@ -238,15 +508,54 @@ func (d *Differ) compareCustomTypes(result *DiffResult) {
 		}
 	}
 
-	for key, desired := range desiredTypes {
+	for _, key := range slices.Sorted(maps.Keys(desiredTypes)) {
+		desired := desiredTypes[key]
+
 		if current, exists := currentTypes[key]; exists {
 			if desired.Type == "enum" && current.Type == "enum" {
 				d.compareEnumValues(result, key, &current, &desired)
 			}
+
+			if desired.Type == "composite" && current.Type == "composite" {
+				d.compareTypeAttributes(result, key, &current, &desired)
+			}
+
+			if !d.options.commentsEqual(current.Comment, desired.Comment) {
+				d.addCustomTypeCommentChange(result, key, &desired, current.Comment)
+			}
 		}
 	}
 }
 
+func (d *Differ) addCustomTypeCommentChange(
+	result *DiffResult,
+	key string,
+	ct *schema.CustomType,
+	oldComment string,
+) {
+	if d.options.IgnoreComments || (ct.Comment == "" && oldComment == "") {
+		return
+	}
+
+	severity := SeveritySafe
+	if ct.Comment == "" {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:        ChangeTypeModifyCustomTypeComment,
+		Severity:    severity,
+		Description: describeComment("type", ct.QualifiedName(), oldComment, ct.Comment),
+		ObjectType:  "type",
+		ObjectName:  key,
+		Details: map[string]any{
+			"type_name":   ct.QualifiedName(),
+			"old_comment": oldComment,
+			"new_comment": ct.Comment,
+		},
+	})
+}
+
 func (d *Differ) compareEnumValues(
 	result *DiffResult,
 	key string,
@@ -257,12 +566,17 @@ func (d *Differ) compareEnumValues(
 		currentValues[v] = true
 	}
 
-	for _, v := range desired.Values {
+	for i, v := range desired.Values {
 		if !currentValues[v] {
+			position := "first"
+			if i > 0 {
+				position = fmt.Sprintf("after '%s'", desired.Values[i-1])
+			}
+
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeModifyCustomType,
 				Severity:    SeveritySafe,
-				Description: fmt.Sprintf("Add enum value '%s' to type %s", v, desired.Name),
+				Description: describe(descEnumValueAdded, v, desired.QualifiedName(), position),
 				ObjectType:  "type",
 				ObjectName:  key,
 				Details:     map[string]any{"enum_value": v, "type_name": desired.Name},
@@ -280,7 +594,7 @@ func (d *Differ) compareEnumValues(
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeModifyCustomType,
 				Severity:    SeverityBreaking,
-				Description: fmt.Sprintf("Remove enum value '%s' from type %s", v, current.Name),
+				Description: describe(descEnumValueDropped, v, current.QualifiedName()),
 				ObjectType:  "type",
 				ObjectName:  key,
 				Details:     map[string]any{"enum_value": v, "type_name": current.Name},
@@ -300,21 +614,33 @@ func (d *Differ) compareSequences(result *DiffResult) {
 		desiredSeqs[TableKey(seq.Schema, seq.Name)] = seq
 	}
 
-	for key, seq := range desiredSeqs {
+	for _, key := range slices.Sorted(maps.Keys(desiredSeqs)) {
+		seq := desiredSeqs[key]
+
 		if _, exists := currentSeqs[key]; !exists {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeAddSequence,
 				Severity:    SeveritySafe,
-				Description: "Add sequence: " + seq.QualifiedName(),
+				Description: describeAdded("sequence", seq.QualifiedName()),
 				ObjectType:  "sequence",
 				ObjectName:  key,
 				Details:     map[string]any{"sequence": seq},
 			})
+
+			if owner := sequenceOwner(&seq); owner != "" {
+				result.Changes = append(result.Changes, newSequenceOwnerChange(key, seq, owner))
+			}
 		}
 	}
 
-	for key, seq := range currentSeqs {
+	for _, key := range slices.Sorted(maps.Keys(currentSeqs)) {
+		seq := currentSeqs[key]
+
 		if _, exists := desiredSeqs[key]; !exists {
+			if ownerLeavesSequence(&seq, result.Desired) {
+				continue
+			}
+
 			severity := SeverityBreaking
 			if seq.OwnedByTable != "" {
 				severity = SeverityPotentiallyBreaking
@@ -323,7 +649,7 @@ func (d *Differ) compareSequences(result *DiffResult) {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropSequence,
 				Severity:    severity,
-				Description: "Drop sequence: " + seq.QualifiedName(),
+				Description: describeDropped("sequence", seq.QualifiedName()),
 				ObjectType:  "sequence",
 				ObjectName:  key,
 				Details:     map[string]any{"sequence": seq},
@@ -331,23 +657,206 @@ func (d *Differ) compareSequences(result *DiffResult) {
 		}
 	}
 
-	for key, desired := range desiredSeqs {
+	for _, key := range slices.Sorted(maps.Keys(desiredSeqs)) {
+		desired := desiredSeqs[key]
+
 		if current, exists := currentSeqs[key]; exists {
-			if current.Increment != desired.Increment ||
-				current.MinValue != desired.MinValue ||
-				current.MaxValue != desired.MaxValue ||
-				current.IsCyclic != desired.IsCyclic {
-				result.Changes = append(result.Changes, Change{
-					Type:        ChangeTypeModifySequence,
-					Severity:    SeveritySafe,
-					Description: "Modify sequence: " + desired.QualifiedName(),
-					ObjectType:  "sequence",
-					ObjectName:  key,
-					Details:     map[string]any{"current": current, "desired": desired},
+			differences := sequenceDifferences(&current, &desired, d.options.EnforceSequenceStart)
+			if implicitlyOwned(&current, &desired, result.Desired) {
+				differences = slices.DeleteFunc(differences, func(diff sequenceDifference) bool {
+					return diff.option == sequenceOptionOwnedBy
 				})
 			}
+
+			if len(differences) == 0 {
+				continue
+			}
+
+			details := map[string]any{
+				"current":       current,
+				"desired":       desired,
+				"enforce_start": d.options.EnforceSequenceStart,
+			}
+
+			described := make([]string, len(differences))
+			for i, diff := range differences {
+				described[i] = diff.String()
+				details["old_"+diff.detail()] = diff.from
+				details["new_"+diff.detail()] = diff.to
+			}
+
+			description := describe(descSequenceDiffers,
+				desired.QualifiedName(), strings.Join(described, ", "))
+			if current.StartValue != desired.StartValue && d.options.EnforceSequenceStart {
+				description += "; START WITH only sets the value a later RESTART returns to, " +
+					"the current value is not moved"
+			}
+
+			result.Changes = append(result.Changes, Change{
+				Type:        ChangeTypeModifySequence,
+				Severity:    SeveritySafe,
+				Description: description,
+				ObjectType:  "sequence",
+				ObjectName:  key,
+				Details:     details,
+			})
+		}
+	}
+}
+
+const sequenceOptionOwnedBy = "owned by"
+
+// newSequenceOwnerChange sets OWNED BY on a sequence the desired schema adds.
+// Sequences are created before tables, so the owner is set by a change of
+// its own, once the owning column exists.
+func newSequenceOwnerChange(key string, seq schema.Sequence, owner string) Change {
+	unowned := seq
+	unowned.OwnedByTable, unowned.OwnedByColumn = "", ""
+
+	return Change{
+		Type:        ChangeTypeModifySequence,
+		Severity:    SeveritySafe,
+		Description: describe(descSequenceOwnerSet, seq.QualifiedName(), owner),
+		ObjectType:  "sequence",
+		ObjectName:  key,
+		Details: map[string]any{
+			"current":       unowned,
+			"desired":       seq,
+			"enforce_start": false,
+			"old_owned_by":  "",
+			"new_owned_by":  owner,
+		},
+	}
+}
+
+// sequenceDifference is an option that differs between two versions of a
+// sequence, with its value in each.
+type sequenceDifference struct {
+	option   string
+	from, to any
+}
+
+func (d sequenceDifference) String() string {
+	return fmt.Sprintf("%s %s -> %s", d.option,
+		valueOrNone(fmt.Sprint(d.from)), valueOrNone(fmt.Sprint(d.to)))
+}
+
+// detail is the suffix of the old_ and new_ detail keys holding the values.
+func (d sequenceDifference) detail() string {
+	return strings.ReplaceAll(d.option, " ", "_")
+}
+
+// sequenceDifferences lists the options that differ between two versions of a
+// sequence. START WITH only counts when enforceStart is set; otherwise it is
+// treated as a creation-only setting.
+func sequenceDifferences(
+	current, desired *schema.Sequence,
+	enforceStart bool,
+) []sequenceDifference {
+	var differences []sequenceDifference
+
+	compare := func(option string, from, to any) {
+		if from != to {
+			differences = append(differences, sequenceDifference{option, from, to})
+		}
+	}
+
+	compare("increment", current.Increment, desired.Increment)
+	compare("minvalue", current.MinValue, desired.MinValue)
+	compare("maxvalue", current.MaxValue, desired.MaxValue)
+
+	if enforceStart {
+		compare("start", current.StartValue, desired.StartValue)
+	}
+
+	compare("cache", sequenceCache(current), sequenceCache(desired))
+	compare("cycle", current.IsCyclic, desired.IsCyclic)
+	compare(sequenceOptionOwnedBy, sequenceOwner(current), sequenceOwner(desired))
+
+	return differences
+}
+
+// sequenceCache is the CACHE of a sequence, which PostgreSQL never lets drop
+// below one.
+func sequenceCache(seq *schema.Sequence) int64 {
+	return max(seq.CacheSize, 1)
+}
+
+// sequenceOwner is the column owning a sequence as schema.table.column, or
+// empty when OWNED BY is NONE.
+func sequenceOwner(seq *schema.Sequence) string {
+	if seq.OwnedByTable == "" || seq.OwnedByColumn == "" {
+		return ""
+	}
+
+	parts := splitIdentifierParts(seq.OwnedByTable)
+	tableSchema := ""
+
+	if len(parts) > 1 {
+		tableSchema = schema.NormalizeIdentifier(parts[len(parts)-2])
+	}
+
+	return TableKey(tableSchema, schema.NormalizeIdentifier(parts[len(parts)-1])) + "." +
+		schema.NormalizeIdentifier(seq.OwnedByColumn)
+}
+
+// owningColumn finds the column of db that owns seq and reports whether its
+// default draws from seq.
+func owningColumn(seq *schema.Sequence, db *schema.Database) (*schema.Column, bool) {
+	owner := sequenceOwner(seq)
+	if owner == "" {
+		return nil, false
+	}
+
+	key := TableKey(seq.Schema, seq.Name)
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+
+		for j := range table.Columns {
+			col := &table.Columns[j]
+			if TableKey(table.Schema, table.Name)+"."+col.Name != owner {
+				continue
+			}
+
+			for _, match := range nextvalPattern.FindAllStringSubmatch(col.Default, -1) {
+				if usesType(match[1], table.Schema, key) {
+					return col, true
+				}
+			}
+
+			return col, false
 		}
 	}
+
+	return nil, false
+}
+
+// implicitlyOwned reports whether the database sequence is owned by a column
+// whose desired default still draws from it while the desired sequence names
+// no owner. A SERIAL column owns its sequence without the schema saying so.
+func implicitlyOwned(current, desired *schema.Sequence, desiredDB *schema.Database) bool {
+	if sequenceOwner(desired) != "" {
+		return false
+	}
+
+	_, drawsFrom := owningColumn(current, desiredDB)
+
+	return drawsFrom
+}
+
+// ownerLeavesSequence reports whether a database sequence missing from the
+// desired schema needs no drop of its own: it belongs to a SERIAL column the
+// desired schema keeps, or its owning column goes and PostgreSQL drops the
+// sequence with it.
+func ownerLeavesSequence(seq *schema.Sequence, desired *schema.Database) bool {
+	if sequenceOwner(seq) == "" {
+		return false
+	}
+
+	col, drawsFrom := owningColumn(seq, desired)
+
+	return col == nil || drawsFrom
 }
 
 func (d *Differ) computeStats(result *DiffResult) {
@@ -357,6 +866,8 @@ func (d *Differ) computeStats(result *DiffResult) {
 			result.Stats.TablesAdded++
 		case ChangeTypeDropTable:
 			result.Stats.TablesDropped++
+		case ChangeTypeRecreateTable:
+			result.Stats.TablesModified++
 		case ChangeTypeAddColumn:
 			result.Stats.ColumnsAdded++
 			result.Stats.TablesModified++
@@ -365,7 +876,14 @@ func (d *Differ) computeStats(result *DiffResult) {
 			result.Stats.TablesModified++
 		case ChangeTypeModifyColumnType,
 			ChangeTypeModifyColumnNullability,
-			ChangeTypeModifyColumnDefault:
+			ChangeTypeModifyColumnDefault,
+			ChangeTypeModifyColumnStorage,
+			ChangeTypeModifyColumnCompression,
+			ChangeTypeModifyColumnGeneration,
+			ChangeTypeAddColumnIdentity,
+			ChangeTypeDropColumnIdentity,
+			ChangeTypeModifyColumnIdentity,
+			ChangeTypeRenameColumn:
 			result.Stats.ColumnsModified++
 			result.Stats.TablesModified++
 		case ChangeTypeAddIndex:
@@ -0,0 +1,604 @@
+package differ
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// DropDependent is an object of the current schema that depends on an object
+// a change drops. Without CASCADE the drop fails while the dependent exists,
+// so the plan has to drop the dependent or remove its reference first.
+type DropDependent struct {
+	// Drop is the change that drops the object depended on.
+	Drop *Change
+	// Dependent names the dependent with its kind, such as "view app.summary"
+	// or "column app.orders.status".
+	Dependent string
+	// Reference says how it depends on the dropped object, such as "selects
+	// from view app.daily".
+	Reference string
+}
+
+func (d DropDependent) String() string {
+	return d.Dependent + " " + d.Reference
+}
+
+// UnhandledDropDependents returns the dependents of dropped objects that no
+// change of the plan drops or detaches. Dropping their objects without
+// CASCADE fails; with CASCADE, PostgreSQL drops them silently.
+func (dr *DiffResult) UnhandledDropDependents() []DropDependent {
+	var dependents []DropDependent
+
+	for _, dep := range findDropDependencies(dr) {
+		if len(dep.handlers) == 0 {
+			dependents = append(dependents, DropDependent{
+				Drop:      &dr.Changes[dep.drop],
+				Dependent: dep.dependent,
+				Reference: dep.reference,
+			})
+		}
+	}
+
+	return dependents
+}
+
+// dropDependency is a dependent of the object dropped by the change at index
+// drop, with the changes that drop the dependent or remove its reference.
+// The drop is ordered after them.
+type dropDependency struct {
+	drop      int
+	dependent string
+	reference string
+	handlers  []int
+}
+
+// findDropDependencies returns the dependents in the current schema of every
+// object the plan drops: the views, materialized views and continuous
+// aggregates selecting from a dropped relation, the foreign keys referencing a
+// dropped table, the views, triggers, defaults and checks calling a dropped
+// function, the columns and functions using a dropped type, the defaults
+// drawing from a dropped sequence and the objects left in a dropped schema.
+func findDropDependencies(result *DiffResult) []dropDependency {
+	if result.Current == nil {
+		return nil
+	}
+
+	plan := newDropPlan(result)
+
+	var deps []dropDependency
+
+	for i := range result.Changes {
+		for _, dep := range plan.dependentsOf(&result.Changes[i]) {
+			dep.drop = i
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+// dropPlan finds the changes of a plan by the object they apply to.
+type dropPlan struct {
+	changes  []Change
+	current  *schema.Database
+	byObject map[string][]int
+	declared map[string][]string
+}
+
+func newDropPlan(result *DiffResult) *dropPlan {
+	plan := &dropPlan{
+		changes:  result.Changes,
+		current:  result.Current,
+		byObject: make(map[string][]int, len(result.Changes)),
+		declared: declaredFunctionKeys(result.Current.Functions),
+	}
+
+	for i := range result.Changes {
+		name := strings.ToLower(result.Changes[i].ObjectName)
+		plan.byObject[name] = append(plan.byObject[name], i)
+	}
+
+	return plan
+}
+
+// find returns the changes to the named object that match.
+func (p *dropPlan) find(name string, match func(*Change) bool) []int {
+	var found []int
+
+	for _, i := range p.byObject[strings.ToLower(name)] {
+		if match(&p.changes[i]) {
+			found = append(found, i)
+		}
+	}
+
+	return found
+}
+
+func (p *dropPlan) dependentsOf(change *Change) []dropDependency {
+	switch change.Type {
+	case ChangeTypeDropTable:
+		return append(p.relationDependents(change), p.foreignKeyDependents(change)...)
+	case ChangeTypeDropView, ChangeTypeDropMaterializedView,
+		ChangeTypeDropContinuousAggregate, ChangeTypeModifyContinuousAggregate:
+		return p.relationDependents(change)
+	case ChangeTypeModifyMaterializedView:
+		if _, recreates := change.Details["current"]; recreates {
+			return p.relationDependents(change)
+		}
+	case ChangeTypeDropFunction:
+		return p.functionDependents(change)
+	case ChangeTypeDropCustomType:
+		return p.typeDependents(change)
+	case ChangeTypeDropSequence:
+		return p.sequenceDependents(change)
+	case ChangeTypeDropSchema:
+		return p.schemaDependents(change)
+	}
+
+	return nil
+}
+
+// queryUse reports whether a query, or an expression, of an object in the
+// given schema uses the dropped object.
+type queryUse func(query, objectSchema string) bool
+
+// relationDependents returns the views, materialized views and continuous
+// aggregates selecting from the relation the change drops.
+func (p *dropPlan) relationDependents(change *Change) []dropDependency {
+	key := strings.ToLower(change.ObjectName)
+
+	uses := func(query, _ string) bool {
+		return viewDependsOnAnyTable(extractViewDependencies(query), map[string]bool{key: true})
+	}
+
+	return p.queryDependents(key, uses, "selects from "+cycleNodeLabel(change))
+}
+
+// queryDependents returns the views, materialized views and continuous
+// aggregates, other than the one named skip, whose query uses an object. A
+// dependent is handled by its drop, or by a change that redefines it without
+// the use.
+func (p *dropPlan) queryDependents(skip string, uses queryUse, reference string) []dropDependency {
+	var deps []dropDependency
+
+	add := func(kind, key, viewSchema, query string, handled func(*Change) bool) {
+		if key == skip || !uses(query, viewSchema) {
+			return
+		}
+
+		deps = append(deps, dropDependency{
+			dependent: kind + " " + key,
+			reference: reference,
+			handlers:  p.find(key, handled),
+		})
+	}
+
+	for i := range p.current.Views {
+		view := &p.current.Views[i]
+		add("view", ViewKey(view.Schema, view.Name), view.Schema, view.Definition,
+			func(c *Change) bool {
+				if c.Type == ChangeTypeModifyView {
+					desired := viewFromDetails(c)
+					return desired != nil && !uses(desired.Definition, desired.Schema)
+				}
+
+				return c.Type == ChangeTypeDropView
+			})
+	}
+
+	for i := range p.current.MaterializedViews {
+		view := &p.current.MaterializedViews[i]
+		add("materialized view", ViewKey(view.Schema, view.Name), view.Schema, view.Definition,
+			func(c *Change) bool {
+				if c.Type == ChangeTypeModifyMaterializedView {
+					desired, ok := c.Details["desired"].(*schema.MaterializedView)
+					return ok && !uses(desired.Definition, desired.Schema)
+				}
+
+				return c.Type == ChangeTypeDropMaterializedView
+			})
+	}
+
+	for i := range p.current.ContinuousAggregates {
+		agg := &p.current.ContinuousAggregates[i]
+		add("continuous aggregate", ViewKey(agg.Schema, agg.ViewName), agg.Schema, agg.Query,
+			func(c *Change) bool {
+				if c.Type == ChangeTypeModifyContinuousAggregate {
+					desired, ok := c.Details["desired"].(*schema.ContinuousAggregate)
+					return ok && !uses(desired.Query, desired.Schema)
+				}
+
+				return c.Type == ChangeTypeDropContinuousAggregate
+			})
+	}
+
+	return deps
+}
+
+// foreignKeyDependents returns the foreign keys of other tables that
+// reference the table the change drops.
+func (p *dropPlan) foreignKeyDependents(change *Change) []dropDependency {
+	key := strings.ToLower(change.ObjectName)
+
+	var deps []dropDependency
+
+	for i := range p.current.Tables {
+		table := &p.current.Tables[i]
+
+		tableKey := TableKey(table.Schema, table.Name)
+		if tableKey == key {
+			continue
+		}
+
+		for j := range table.Constraints {
+			constraint := &table.Constraints[j]
+			if !constraint.IsForeignKey() || referencedTableKey(table, constraint) != key {
+				continue
+			}
+
+			deps = append(deps, dropDependency{
+				dependent: fmt.Sprintf("constraint %s on table %s", constraint.Name, tableKey),
+				reference: "references " + cycleNodeLabel(change),
+				handlers: p.find(tableKey, func(c *Change) bool {
+					desired, ok := c.Details["desired"].(*schema.Constraint)
+					if c.Type == ChangeTypeModifyConstraint && ok {
+						return strings.EqualFold(desired.Name, constraint.Name) &&
+							(!desired.IsForeignKey() || referencedTableKey(table, desired) != key)
+					}
+
+					return c.Type == ChangeTypeDropTable ||
+						(c.Type == ChangeTypeDropConstraint && constraintNamed(c, constraint.Name))
+				}),
+			})
+		}
+	}
+
+	return deps
+}
+
+// referencedTableKey returns the key of the table a foreign key of table
+// references. An unqualified reference is to the table's own schema.
+func referencedTableKey(table *schema.Table, constraint *schema.Constraint) string {
+	referencedSchema := constraint.ReferencedSchema
+	if referencedSchema == "" {
+		referencedSchema = table.Schema
+	}
+
+	return TableKey(referencedSchema, constraint.ReferencedTable)
+}
+
+func constraintNamed(change *Change, name string) bool {
+	constraint, ok := change.Details["constraint"].(*schema.Constraint)
+	return ok && strings.EqualFold(constraint.Name, name)
+}
+
+// functionDependents returns the views, triggers, column defaults, generated
+// columns and check constraints that call the function the change drops.
+func (p *dropPlan) functionDependents(change *Change) []dropDependency {
+	key := change.ObjectName
+	reference := "calls " + cycleNodeLabel(change)
+
+	calls := func(expr, objectSchema string) bool {
+		return slices.Contains(referencedFunctionKeys(expr, objectSchema, p.declared), key)
+	}
+
+	deps := p.queryDependents("", calls, reference)
+
+	for i := range p.current.Triggers {
+		trigger := &p.current.Triggers[i]
+		if FunctionKey(trigger.FunctionSchema, trigger.FunctionName, nil) != key {
+			continue
+		}
+
+		relation := ViewKey(trigger.Schema, trigger.TableName)
+		handlers := p.find(triggerKey(trigger), func(c *Change) bool {
+			if c.Type == ChangeTypeModifyTrigger {
+				desired, ok := c.Details["desired"].(*schema.Trigger)
+				return ok && FunctionKey(desired.FunctionSchema, desired.FunctionName, nil) != key
+			}
+
+			return c.Type == ChangeTypeDropTrigger
+		})
+		handlers = append(handlers, p.find(relation, func(c *Change) bool {
+			return c.Type == ChangeTypeDropTable || c.Type == ChangeTypeDropView
+		})...)
+
+		deps = append(deps, dropDependency{
+			dependent: "trigger " + triggerKey(trigger),
+			reference: reference,
+			handlers:  handlers,
+		})
+	}
+
+	for i := range p.current.Tables {
+		table := &p.current.Tables[i]
+
+		for j := range table.Columns {
+			col := &table.Columns[j]
+			if !slices.ContainsFunc(columnExpressions(col), func(expr string) bool {
+				return calls(expr, table.Schema)
+			}) {
+				continue
+			}
+
+			deps = append(deps, p.columnDependency(table, col, reference, func(c *Change) bool {
+				newDefault, ok := c.Details["new_default"].(string)
+				return c.Type == ChangeTypeModifyColumnDefault && ok &&
+					col.GenerationExpression == "" && !calls(newDefault, table.Schema)
+			}))
+		}
+
+		for j := range table.Constraints {
+			constraint := &table.Constraints[j]
+			if !constraint.IsCheck() || !calls(constraint.CheckExpression, table.Schema) {
+				continue
+			}
+
+			deps = append(deps, p.constraintDependency(table, constraint, reference,
+				func(desired *schema.Constraint) bool {
+					return !desired.IsCheck() || !calls(desired.CheckExpression, table.Schema)
+				}))
+		}
+	}
+
+	return deps
+}
+
+// columnDependency describes a column that depends on a dropped object. It is
+// handled by dropping its table or itself, or by a change that matches.
+func (p *dropPlan) columnDependency(
+	table *schema.Table,
+	col *schema.Column,
+	reference string,
+	handled func(*Change) bool,
+) dropDependency {
+	tableKey := TableKey(table.Schema, table.Name)
+
+	return dropDependency{
+		dependent: "column " + tableKey + "." + strings.ToLower(col.Name),
+		reference: reference,
+		handlers: p.find(tableKey, func(c *Change) bool {
+			if c.Type == ChangeTypeDropTable {
+				return true
+			}
+
+			if dropped, ok := c.Details["column"].(*schema.Column); ok &&
+				c.Type == ChangeTypeDropColumn {
+				return strings.EqualFold(dropped.Name, col.Name)
+			}
+
+			name, _ := c.Details["column_name"].(string)
+
+			return strings.EqualFold(name, col.Name) && handled(c)
+		}),
+	}
+}
+
+// constraintDependency describes a constraint that depends on a dropped
+// object. It is handled by dropping its table or itself, or by a modification
+// whose desired constraint no longer depends on it.
+func (p *dropPlan) constraintDependency(
+	table *schema.Table,
+	constraint *schema.Constraint,
+	reference string,
+	redefined func(*schema.Constraint) bool,
+) dropDependency {
+	tableKey := TableKey(table.Schema, table.Name)
+
+	return dropDependency{
+		dependent: fmt.Sprintf("constraint %s on table %s", constraint.Name, tableKey),
+		reference: reference,
+		handlers: p.find(tableKey, func(c *Change) bool {
+			desired, ok := c.Details["desired"].(*schema.Constraint)
+			if c.Type == ChangeTypeModifyConstraint && ok {
+				return strings.EqualFold(desired.Name, constraint.Name) && redefined(desired)
+			}
+
+			return c.Type == ChangeTypeDropTable ||
+				(c.Type == ChangeTypeDropConstraint && constraintNamed(c, constraint.Name))
+		}),
+	}
+}
+
+// typeDependents returns the columns and function signatures that use the
+// type the change drops.
+func (p *dropPlan) typeDependents(change *Change) []dropDependency {
+	key := strings.ToLower(change.ObjectName)
+	reference := "has " + cycleNodeLabel(change)
+
+	var deps []dropDependency
+
+	for i := range p.current.Tables {
+		table := &p.current.Tables[i]
+
+		for j := range table.Columns {
+			col := &table.Columns[j]
+			if !usesType(col.DataType, table.Schema, key) {
+				continue
+			}
+
+			deps = append(deps, p.columnDependency(table, col, reference, func(c *Change) bool {
+				newType, ok := c.Details["new_type"].(string)
+				return c.Type == ChangeTypeModifyColumnType && ok &&
+					!usesType(newType, table.Schema, key)
+			}))
+		}
+	}
+
+	for i := range p.current.Functions {
+		fn := &p.current.Functions[i]
+
+		if !usesType(fn.ReturnType, fn.Schema, key) &&
+			!slices.ContainsFunc(fn.ArgumentTypes, func(argType string) bool {
+				return usesType(argType, fn.Schema, key)
+			}) {
+			continue
+		}
+
+		fnKey := RoutineKey(fn)
+		deps = append(deps, dropDependency{
+			dependent: fn.KindName() + " " + fnKey,
+			reference: "takes or returns " + cycleNodeLabel(change),
+			handlers: p.find(fnKey, func(c *Change) bool {
+				return c.Type == ChangeTypeDropFunction
+			}),
+		})
+	}
+
+	for i := range p.current.Tables {
+		table := &p.current.Tables[i]
+		if typedOfKey(table) != key {
+			continue
+		}
+
+		tableKey := TableKey(table.Schema, table.Name)
+		deps = append(deps, dropDependency{
+			dependent: "table " + tableKey,
+			reference: "is created OF " + cycleNodeLabel(change),
+			handlers: p.find(tableKey, func(c *Change) bool {
+				return c.Type == ChangeTypeDropTable
+			}),
+		})
+	}
+
+	return deps
+}
+
+// usesType reports whether a data type, written in an object of the given
+// schema, is the type with the given key or an array of it. An unqualified
+// name resolves against the object's schema and then public.
+func usesType(dataType, objectSchema, key string) bool {
+	name := strings.ToLower(strings.TrimSpace(dataType))
+	for strings.HasSuffix(name, "[]") {
+		name = strings.TrimSpace(strings.TrimSuffix(name, "[]"))
+	}
+
+	if open := strings.Index(name, "("); open >= 0 {
+		name = strings.TrimSpace(name[:open])
+	}
+
+	name = strings.ReplaceAll(name, `"`, "")
+
+	if strings.Contains(name, ".") {
+		return name == key
+	}
+
+	return TableKey(objectSchema, name) == key || TableKey(schema.DefaultSchema, name) == key
+}
+
+var nextvalPattern = regexp.MustCompile(`(?i)\bnextval\s*\(\s*'([^']+)'`)
+
+// sequenceDependents returns the column defaults that draw from the sequence
+// the change drops.
+func (p *dropPlan) sequenceDependents(change *Change) []dropDependency {
+	key := strings.ToLower(change.ObjectName)
+	reference := "draws its default from " + cycleNodeLabel(change)
+
+	drawsFrom := func(expr, objectSchema string) bool {
+		for _, match := range nextvalPattern.FindAllStringSubmatch(expr, -1) {
+			if usesType(match[1], objectSchema, key) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var deps []dropDependency
+
+	for i := range p.current.Tables {
+		table := &p.current.Tables[i]
+
+		for j := range table.Columns {
+			col := &table.Columns[j]
+			if !drawsFrom(col.Default, table.Schema) {
+				continue
+			}
+
+			deps = append(deps, p.columnDependency(table, col, reference, func(c *Change) bool {
+				newDefault, ok := c.Details["new_default"].(string)
+				return c.Type == ChangeTypeModifyColumnDefault && ok &&
+					!drawsFrom(newDefault, table.Schema)
+			}))
+		}
+	}
+
+	return deps
+}
+
+// schemaDependents returns the objects of the current schema that are in the
+// schema the change drops. Each is handled by its own drop.
+func (p *dropPlan) schemaDependents(change *Change) []dropDependency {
+	name := normalizeSchema(change.ObjectName)
+	reference := "is in " + cycleNodeLabel(change)
+
+	var deps []dropDependency
+
+	add := func(kind, objectSchema, key string, dropType ChangeType) {
+		if normalizeSchema(objectSchema) != name {
+			return
+		}
+
+		deps = append(deps, dropDependency{
+			dependent: kind + " " + key,
+			reference: reference,
+			handlers: p.find(key, func(c *Change) bool {
+				return c.Type == dropType
+			}),
+		})
+	}
+
+	db := p.current
+
+	for i := range db.Tables {
+		add("table", db.Tables[i].Schema,
+			TableKey(db.Tables[i].Schema, db.Tables[i].Name), ChangeTypeDropTable)
+	}
+
+	for i := range db.Views {
+		add("view", db.Views[i].Schema,
+			ViewKey(db.Views[i].Schema, db.Views[i].Name), ChangeTypeDropView)
+	}
+
+	for i := range db.MaterializedViews {
+		view := &db.MaterializedViews[i]
+		add("materialized view", view.Schema,
+			ViewKey(view.Schema, view.Name), ChangeTypeDropMaterializedView)
+	}
+
+	for i := range db.ContinuousAggregates {
+		agg := &db.ContinuousAggregates[i]
+		add("continuous aggregate", agg.Schema,
+			ViewKey(agg.Schema, agg.ViewName), ChangeTypeDropContinuousAggregate)
+	}
+
+	for i := range db.Functions {
+		fn := &db.Functions[i]
+		add(fn.KindName(), fn.Schema, RoutineKey(fn), ChangeTypeDropFunction)
+	}
+
+	for i := range db.CustomTypes {
+		add("type", db.CustomTypes[i].Schema,
+			TableKey(db.CustomTypes[i].Schema, db.CustomTypes[i].Name), ChangeTypeDropCustomType)
+	}
+
+	for i := range db.Sequences {
+		add("sequence", db.Sequences[i].Schema,
+			TableKey(db.Sequences[i].Schema, db.Sequences[i].Name), ChangeTypeDropSequence)
+	}
+
+	return deps
+}
+
+// dropDependencyEdge explains why a drop runs after a change that drops its
+// dependent or removes its reference.
+func dropDependencyEdge(dep *dropDependency, handler *Change) dependencyEdge {
+	return dependencyEdge{
+		reference: fmt.Sprintf("must run after %s (%s), since %s %s",
+			cycleNodeLabel(handler), handler.Type, dep.dependent, dep.reference),
+	}
+}
@@ -0,0 +1,49 @@
+package differ
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// dropDuplicateChanges removes changes identical to an earlier one, keyed on
+// the change type, the object and the value of the details. No pass is meant
+// to produce duplicates; this keeps one that slips through from reaching a
+// migration, where the second statement would fail inside the transaction.
+func (d *Differ) dropDuplicateChanges(result *DiffResult) {
+	seen := make(map[string]bool, len(result.Changes))
+	filtered := make([]Change, 0, len(result.Changes))
+
+	for _, change := range result.Changes {
+		if key, ok := changeIdentity(&change); ok {
+			if seen[key] {
+				result.Notes = append(result.Notes, fmt.Sprintf(
+					"dropped duplicate %s change for %s: %s",
+					change.Type,
+					change.ObjectName,
+					change.Description,
+				))
+
+				continue
+			}
+
+			seen[key] = true
+		}
+
+		filtered = append(filtered, change)
+	}
+
+	result.Changes = filtered
+}
+
+// changeIdentity returns the value a change is deduplicated on. The details
+// are compared by what their pointers refer to rather than by address, so two
+// changes built from different copies of an object still match. It reports
+// false for details that cannot be serialized, which are never deduplicated.
+func changeIdentity(change *Change) (string, bool) {
+	details, err := json.Marshal(change.Details)
+	if err != nil {
+		return "", false
+	}
+
+	return string(change.Type) + "\x00" + change.ObjectName + "\x00" + string(details), true
+}
@@ -0,0 +1,115 @@
+package differ //nolint:testpackage // testing internal function
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDropDuplicateChanges(t *testing.T) {
+	t.Parallel()
+
+	constraint := func(definition string) *schema.Constraint {
+		return &schema.Constraint{Name: "users_pkey", Type: "PRIMARY KEY", Definition: definition}
+	}
+
+	addConstraint := func(details map[string]any) Change {
+		return Change{
+			Type:        ChangeTypeAddConstraint,
+			ObjectName:  "public.users",
+			Description: "Add constraint users_pkey",
+			Details:     details,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		changes   []Change
+		wantKept  []int
+		wantNotes int
+	}{
+		{
+			name: "details equal through different pointers",
+			changes: []Change{
+				addConstraint(map[string]any{"constraint": constraint("PRIMARY KEY (id)")}),
+				addConstraint(map[string]any{"constraint": constraint("PRIMARY KEY (id)")}),
+			},
+			wantKept:  []int{0},
+			wantNotes: 1,
+		},
+		{
+			name: "details differ",
+			changes: []Change{
+				addConstraint(map[string]any{"constraint": constraint("PRIMARY KEY (id)")}),
+				addConstraint(map[string]any{"constraint": constraint("PRIMARY KEY (id, tenant_id)")}),
+			},
+			wantKept: []int{0, 1},
+		},
+		{
+			name: "change types differ",
+			changes: []Change{
+				addConstraint(map[string]any{"constraint": constraint("PRIMARY KEY (id)")}),
+				{
+					Type:       ChangeTypeDropConstraint,
+					ObjectName: "public.users",
+					Details:    map[string]any{"constraint": constraint("PRIMARY KEY (id)")},
+				},
+			},
+			wantKept: []int{0, 1},
+		},
+		{
+			name: "objects differ",
+			changes: []Change{
+				addConstraint(nil),
+				{Type: ChangeTypeAddConstraint, ObjectName: "public.accounts"},
+			},
+			wantKept: []int{0, 1},
+		},
+		{
+			name: "details that cannot be serialized are kept",
+			changes: []Change{
+				addConstraint(map[string]any{"build": func() {}}),
+				addConstraint(map[string]any{"build": func() {}}),
+			},
+			wantKept: []int{0, 1},
+		},
+		{
+			name: "first of three duplicates is kept in place",
+			changes: []Change{
+				addConstraint(nil),
+				{Type: ChangeTypeAddTable, ObjectName: "public.accounts"},
+				addConstraint(nil),
+				addConstraint(nil),
+			},
+			wantKept:  []int{0, 1},
+			wantNotes: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := &DiffResult{Changes: slices.Clone(tt.changes)}
+			New(DefaultOptions()).dropDuplicateChanges(result)
+
+			if len(result.Changes) != len(tt.wantKept) {
+				t.Fatalf("expected %d changes, got %d", len(tt.wantKept), len(result.Changes))
+			}
+
+			for i, kept := range tt.wantKept {
+				if result.Changes[i].Type != tt.changes[kept].Type ||
+					result.Changes[i].ObjectName != tt.changes[kept].ObjectName {
+					t.Errorf("change %d: expected %s %s, got %s %s", i,
+						tt.changes[kept].Type, tt.changes[kept].ObjectName,
+						result.Changes[i].Type, result.Changes[i].ObjectName)
+				}
+			}
+
+			if len(result.Notes) != tt.wantNotes {
+				t.Errorf("expected %d notes, got %v", tt.wantNotes, result.Notes)
+			}
+		})
+	}
+}
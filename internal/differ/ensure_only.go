@@ -0,0 +1,143 @@
+package differ
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// applyEnsureOnly removes changes that would alter an existing table or
+// index the desired state declared with IF NOT EXISTS, and records what was
+// ignored as a note per object. Objects missing from the current schema are
+// still added as usual.
+func (d *Differ) applyEnsureOnly(result *DiffResult) {
+	if !d.options.IfNotExistsMeansEnsureOnly {
+		return
+	}
+
+	tables := existingEnsureOnlyTables(result)
+	indexes := existingEnsureOnlyIndexes(result)
+
+	if len(tables) == 0 && len(indexes) == 0 {
+		return
+	}
+
+	ignored := make(map[string][]string)
+	kept := result.Changes[:0]
+
+	for _, change := range result.Changes {
+		owner, ok := ensureOnlyOwner(change, tables, indexes)
+		if !ok {
+			kept = append(kept, change)
+			continue
+		}
+
+		ignored[owner] = append(ignored[owner], change.Description)
+	}
+
+	result.Changes = kept
+
+	owners := make([]string, 0, len(ignored))
+	for owner := range ignored {
+		owners = append(owners, owner)
+	}
+
+	slices.Sort(owners)
+
+	for _, owner := range owners {
+		descriptions := ignored[owner]
+		slices.Sort(descriptions)
+
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"%s is declared IF NOT EXISTS; ignored differences: %s",
+			owner,
+			strings.Join(descriptions, "; "),
+		))
+	}
+}
+
+func existingEnsureOnlyTables(result *DiffResult) map[string]string {
+	current := buildTableMap(result.Current.Tables)
+	tables := make(map[string]string)
+
+	for i := range result.Desired.Tables {
+		table := &result.Desired.Tables[i]
+
+		key := TableKey(table.Schema, table.Name)
+		if table.IfNotExists && current[key] != nil {
+			tables[key] = "table " + table.QualifiedName()
+		}
+	}
+
+	return tables
+}
+
+func existingEnsureOnlyIndexes(result *DiffResult) map[string]string {
+	current := make(map[string]bool)
+
+	for i := range result.Current.Tables {
+		for _, idx := range result.Current.Tables[i].Indexes {
+			current[IndexKey(idx.Schema, idx.Name)] = true
+		}
+	}
+
+	indexes := make(map[string]string)
+
+	for i := range result.Desired.Tables {
+		for _, idx := range result.Desired.Tables[i].Indexes {
+			key := IndexKey(idx.Schema, idx.Name)
+			if idx.IfNotExists && current[key] {
+				indexes[key] = "index " + idx.QualifiedName()
+			}
+		}
+	}
+
+	return indexes
+}
+
+// ensureOnlyOwner returns the ensure-only object a change would alter. Index
+// changes on an ensure-only table are attributed to the table unless they add
+// an index the desired state declares.
+func ensureOnlyOwner(change Change, tables, indexes map[string]string) (string, bool) {
+	switch change.ObjectType {
+	case "table", "column", "constraint":
+		owner, ok := tables[change.ObjectName]
+		return owner, ok
+	case "partition":
+		tableName, _ := change.Details["table"].(string)
+		owner, ok := tables[tableName]
+
+		return owner, ok
+	case "index":
+		if owner, ok := indexes[change.ObjectName]; ok {
+			return owner, true
+		}
+
+		if change.Type == ChangeTypeAddIndex {
+			return "", false
+		}
+
+		idx := changeIndex(change)
+		if idx == nil {
+			return "", false
+		}
+
+		owner, ok := tables[TableKey(idx.Schema, idx.TableName)]
+
+		return owner, ok
+	default:
+		return "", false
+	}
+}
+
+func changeIndex(change Change) *schema.Index {
+	for _, key := range []string{"index", "current"} {
+		if idx, ok := change.Details[key].(*schema.Index); ok && idx != nil {
+			return idx
+		}
+	}
+
+	return nil
+}
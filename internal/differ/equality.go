@@ -0,0 +1,34 @@
+package differ
+
+import "github.com/accented-ai/pgtofu/internal/schema"
+
+// NormalizeCheckExpression returns the canonical form of a CHECK constraint
+// expression as Compare sees it: the CHECK keyword, redundant parentheses,
+// casts and whitespace differences removed.
+func NormalizeCheckExpression(expr string) string {
+	return normalizeExpression(expr)
+}
+
+// AreCheckExpressionsEqual reports whether two CHECK expressions compare
+// equal after normalization.
+func AreCheckExpressionsEqual(expr1, expr2 string) bool {
+	return normalizeExpression(expr1) == normalizeExpression(expr2)
+}
+
+// NormalizeDefault returns the canonical form of a column default expression
+// as Compare sees it.
+func NormalizeDefault(defaultValue string) string {
+	return normalizeDefault(defaultValue)
+}
+
+// AreViewDefinitionsEqual reports whether two view or materialized view
+// queries compare equal after normalization.
+func AreViewDefinitionsEqual(definition1, definition2 string) bool {
+	return NormalizeViewDefinition(definition1) == NormalizeViewDefinition(definition2)
+}
+
+// AreColumnTypesEqual reports whether two columns have the same type once
+// the built-in aliases, typmods and array dimensions are canonicalized.
+func AreColumnTypesEqual(current, desired *schema.Column) bool {
+	return (&ColumnComparator{}).sameType(current, desired)
+}
@@ -0,0 +1,168 @@
+package differ
+
+import (
+	"fmt"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// extensionSchemas are the schemas TimescaleDB creates and fills itself. A
+// pg_dump of a TimescaleDB database includes their chunks, catalog tables and
+// job configuration, none of which a schema file declares.
+var extensionSchemas = map[string]bool{ //nolint:gochecknoglobals
+	"_timescaledb_internal": true,
+	"_timescaledb_catalog":  true,
+	"_timescaledb_config":   true,
+	"_timescaledb_cache":    true,
+}
+
+// extensionObjects tells which objects of either schema are managed by the
+// TimescaleDB extension rather than declared by the user.
+type extensionObjects struct {
+	// materializations holds the names of the materialization hypertables
+	// that a continuous aggregate of either schema reads from.
+	materializations map[string]bool
+}
+
+func newExtensionObjects(dbs ...*schema.Database) *extensionObjects {
+	objects := &extensionObjects{materializations: make(map[string]bool)}
+
+	for _, db := range dbs {
+		for i := range db.ContinuousAggregates {
+			ca := &db.ContinuousAggregates[i]
+
+			// An aggregate built on another aggregate reads from its
+			// materialization hypertable, which the catalog reports as the
+			// aggregate's hypertable.
+			names := materializationTablePattern.FindAllString(ca.Query, -1)
+			names = append(names, ca.HypertableName)
+
+			for _, name := range names {
+				if name = schema.NormalizeIdentifier(name); isMaterializationTable(name) {
+					objects.materializations[name] = true
+				}
+			}
+		}
+	}
+
+	return objects
+}
+
+func isMaterializationTable(name string) bool {
+	return materializationTablePattern.FindString(name) == name
+}
+
+func (e *extensionObjects) inSchema(schemaName string) bool {
+	return extensionSchemas[schema.NormalizeSchemaName(schemaName)]
+}
+
+func (e *extensionObjects) table(schemaName, name string) bool {
+	return e.inSchema(schemaName) || e.materializations[schema.NormalizeIdentifier(name)]
+}
+
+// filter returns db without the managed objects, and how many it left out.
+// db itself is returned when it has none.
+func (e *extensionObjects) filter(db *schema.Database) (*schema.Database, int) {
+	filtered := *db
+	skipped := 0
+
+	without := func(managed bool) bool {
+		if managed {
+			skipped++
+		}
+
+		return managed
+	}
+
+	filtered.Schemas = deleteManaged(db.Schemas, func(s *schema.Schema) bool {
+		return without(e.inSchema(s.Name))
+	})
+	filtered.CustomTypes = deleteManaged(db.CustomTypes, func(t *schema.CustomType) bool {
+		return without(e.inSchema(t.Schema))
+	})
+	filtered.Sequences = deleteManaged(db.Sequences, func(s *schema.Sequence) bool {
+		return without(e.inSchema(s.Schema))
+	})
+	filtered.Tables = deleteManaged(db.Tables, func(t *schema.Table) bool {
+		return without(e.table(t.Schema, t.Name))
+	})
+	filtered.Views = deleteManaged(db.Views, func(v *schema.View) bool {
+		return without(e.inSchema(v.Schema))
+	})
+	filtered.MaterializedViews = deleteManaged(
+		db.MaterializedViews,
+		func(v *schema.MaterializedView) bool { return without(e.inSchema(v.Schema)) },
+	)
+	filtered.Functions = deleteManaged(db.Functions, func(f *schema.Function) bool {
+		return without(e.inSchema(f.Schema))
+	})
+	filtered.Triggers = deleteManaged(db.Triggers, func(t *schema.Trigger) bool {
+		return without(e.table(t.Schema, t.TableName))
+	})
+	filtered.Grants = deleteManaged(db.Grants, func(g *schema.Grant) bool {
+		return without(e.table(g.Schema, g.ObjectName))
+	})
+	filtered.Hypertables = deleteManaged(db.Hypertables, func(h *schema.Hypertable) bool {
+		return without(e.table(h.Schema, h.TableName))
+	})
+	filtered.ContinuousAggregates = deleteManaged(
+		db.ContinuousAggregates,
+		func(ca *schema.ContinuousAggregate) bool { return without(e.inSchema(ca.Schema)) },
+	)
+
+	if skipped == 0 {
+		return db, 0
+	}
+
+	return &filtered, skipped
+}
+
+// deleteManaged returns objects without the ones managed reports. The
+// caller's slice is never modified, and is returned as it is when nothing is
+// managed.
+func deleteManaged[T any](objects []T, managed func(*T) bool) []T {
+	var kept []T
+
+	for i := range objects {
+		switch {
+		case managed(&objects[i]):
+			if kept == nil {
+				kept = make([]T, i, len(objects))
+				copy(kept, objects[:i])
+			}
+		case kept != nil:
+			kept = append(kept, objects[i])
+		}
+	}
+
+	if kept == nil {
+		return objects
+	}
+
+	return kept
+}
+
+// skipExtensionObjects removes the objects TimescaleDB manages from both
+// schemas before they are compared, so a dump of a TimescaleDB database does
+// not plan to drop its chunks and catalog. Continuous aggregates are read
+// first, which is how their materialization hypertables are recognized.
+func (d *Differ) skipExtensionObjects(result *DiffResult) {
+	if d.options.IncludeExtensionObjects {
+		return
+	}
+
+	objects := newExtensionObjects(result.Current, result.Desired)
+
+	current, skippedCurrent := objects.filter(result.Current)
+	desired, skippedDesired := objects.filter(result.Desired)
+
+	result.Current, result.Desired = current, desired
+
+	if skipped := skippedCurrent + skippedDesired; skipped > 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"skipped %d managed-by-extension objects: TimescaleDB internal schemas "+
+				"and continuous aggregate materialization tables",
+			skipped,
+		))
+	}
+}
@@ -0,0 +1,134 @@
+package differ
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/graph"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// breakForeignKeyCycles handles new tables whose inline foreign keys form a
+// cycle. Each table's CREATE TABLE depends on the tables it references, so
+// mutually referencing tables could never be ordered. Within each cycle the
+// tables are created in name order; a foreign key to a table later in that
+// order is stripped from the CREATE TABLE and emitted as a separate
+// ADD_CONSTRAINT once both tables exist. Self-references stay inline since a
+// table may reference itself at creation time.
+func (d *Differ) breakForeignKeyCycles(result *DiffResult) {
+	addedTables := make(map[string]int)
+
+	for i := range result.Changes {
+		if result.Changes[i].Type == ChangeTypeAddTable {
+			addedTables[result.Changes[i].ObjectName] = i
+		}
+	}
+
+	if len(addedTables) < 2 {
+		return
+	}
+
+	fkGraph := graph.NewDirectedGraph[string]()
+	for key := range addedTables {
+		fkGraph.AddNode(key)
+	}
+
+	for key, idx := range addedTables {
+		table, ok := result.Changes[idx].Details["table"].(*schema.Table)
+		if !ok {
+			continue
+		}
+
+		for _, ref := range referencedTableKeys(table) {
+			if _, added := addedTables[ref]; added && ref != key {
+				fkGraph.AddEdge(key, ref) //nolint:errcheck
+			}
+		}
+	}
+
+	for _, component := range fkGraph.CondensationOrder() {
+		if len(component) < 2 {
+			continue
+		}
+
+		for position, key := range component {
+			later := component[position+1:]
+			deferForeignKeys(result, addedTables[key], later, component)
+		}
+	}
+}
+
+// deferForeignKeys moves the foreign keys of the AddTable change at idx that
+// reference any of later out of its CREATE TABLE.
+func deferForeignKeys(result *DiffResult, idx int, later, cycle []string) {
+	change := &result.Changes[idx]
+
+	table, ok := change.Details["table"].(*schema.Table)
+	if !ok {
+		return
+	}
+
+	var deferred []*schema.Constraint
+
+	for i := range table.Constraints {
+		constraint := &table.Constraints[i]
+		if isForeignKeyTo(constraint, later) {
+			deferred = append(deferred, constraint)
+		}
+	}
+
+	if len(deferred) == 0 {
+		return
+	}
+
+	stripped := *table
+	stripped.Constraints = slices.DeleteFunc(
+		slices.Clone(table.Constraints),
+		func(constraint schema.Constraint) bool { return isForeignKeyTo(&constraint, later) },
+	)
+
+	change.Details["table"] = &stripped
+	change.DependsOn = getTableDependencies(&stripped)
+
+	tableKey := change.ObjectName
+	tableName := table.QualifiedName()
+
+	for _, constraint := range deferred {
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeAddConstraint,
+			Severity: SeveritySafe,
+			Description: describeAdded(constraint.Type+" constraint",
+				constraint.Name+" on "+tableName),
+			ObjectType: "constraint",
+			ObjectName: tableKey,
+			Details:    map[string]any{"table": tableName, "constraint": constraint},
+			DependsOn:  getConstraintDependencies(constraint),
+		})
+
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"foreign key %s on %s is added after table creation to break a cycle among %s",
+			constraint.Name,
+			tableName,
+			strings.Join(cycle, ", "),
+		))
+	}
+}
+
+func referencedTableKeys(table *schema.Table) []string {
+	var keys []string
+
+	for i := range table.Constraints {
+		constraint := &table.Constraints[i]
+		if constraint.IsForeignKey() && constraint.ReferencedTable != "" {
+			keys = append(keys, TableKey(constraint.ReferencedSchema, constraint.ReferencedTable))
+		}
+	}
+
+	return keys
+}
+
+func isForeignKeyTo(constraint *schema.Constraint, tableKeys []string) bool {
+	return constraint.IsForeignKey() && constraint.ReferencedTable != "" &&
+		slices.Contains(tableKeys, TableKey(constraint.ReferencedSchema, constraint.ReferencedTable))
+}
@@ -0,0 +1,336 @@
+package differ
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// relationTargetWords start a relation name in a function body statement:
+// the table an UPDATE, INSERT INTO or DELETE FROM writes, and the relations
+// FROM and JOIN read.
+var relationTargetWords = []string{"UPDATE", "INTO", "FROM", "JOIN"} //nolint:gochecknoglobals
+
+// aliasStopWords follow a relation name without aliasing it. The lexer does
+// not treat them as keywords.
+var aliasStopWords = []string{ //nolint:gochecknoglobals
+	"SET", "VALUES", "RETURNING", "OVERRIDING", "LOOP", "INTO", "STRICT",
+}
+
+// bodyFunction is a function of the desired schema whose body was tokenized
+// for noteFunctionColumnReferences.
+type bodyFunction struct {
+	name string
+	// statements are the tokens of the body, split at semicolons, with
+	// comments removed.
+	statements [][]parser.Token
+	// triggerTables are the keys of the tables a trigger runs the function
+	// for, whose columns NEW and OLD name.
+	triggerTables map[string]bool
+}
+
+// noteFunctionColumnReferences warns, with AnalyzeFunctionBodies, about
+// dropped and retyped columns that a function body appears to use.
+// PostgreSQL checks PL/pgSQL bodies only when they run, so such a plan
+// applies but leaves the function failing when it is next called.
+//
+// The search is a heuristic over the tokens of the bodies of the desired
+// schema, which are what is left once the plan applies. A name is a use of
+// the column when NEW or OLD qualify it in a trigger function of the table,
+// when the table or one of its aliases qualifies it, or when it stands
+// unqualified in a statement that writes or reads the table. Dynamic SQL in
+// string literals is never searched, so it can hide uses, and a variable
+// that shares the column's name can be taken for one.
+func (d *Differ) noteFunctionColumnReferences(result *DiffResult) {
+	if !d.options.AnalyzeFunctionBodies || len(result.Desired.Functions) == 0 {
+		return
+	}
+
+	var functions []bodyFunction
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+
+		ref, verb, ok := analyzedColumnChange(change)
+		if !ok {
+			continue
+		}
+
+		if functions == nil {
+			functions = tokenizeFunctionBodies(result.Desired)
+		}
+
+		var users []string
+
+		for j := range functions {
+			if functions[j].usesColumn(ref) && !slices.Contains(users, functions[j].name) {
+				users = append(users, functions[j].name)
+			}
+		}
+
+		if len(users) == 0 {
+			continue
+		}
+
+		sort.Strings(users)
+
+		result.addWarning(diag.Warning{
+			Code:     diag.CodeFunctionColumnReference,
+			Severity: diag.SeverityWarning,
+			Message: fmt.Sprintf(
+				"column %s.%s is %s, but the body of %s appears to use it (heuristic); "+
+					"PostgreSQL only reports a broken function when it is called",
+				ref.table, ref.column, verb, strings.Join(users, ", "),
+			),
+			ObjectName: change.ObjectName,
+			ChangeType: string(change.Type),
+		})
+	}
+}
+
+// analyzedColumnChange returns the column a change drops or retypes, with
+// what happens to it.
+func analyzedColumnChange(change *Change) (columnRef, string, bool) {
+	var (
+		tableName, columnName string
+		ok                    bool
+		verb                  string
+	)
+
+	switch change.Type {
+	case ChangeTypeDropColumn:
+		tableName, columnName, ok = getColumnFromChange(change)
+		verb = "dropped"
+	case ChangeTypeModifyColumnType:
+		tableName, columnName, ok = getModifiedColumnFromChange(change)
+		verb = "changing type"
+	default:
+		return columnRef{}, "", false
+	}
+
+	if !ok {
+		return columnRef{}, "", false
+	}
+
+	return columnRef{
+		table:  tableName,
+		column: schema.NormalizeIdentifier(columnName),
+	}, verb, true
+}
+
+// tokenizeFunctionBodies tokenizes the body of every function of db. A body
+// the lexer rejects is left out.
+func tokenizeFunctionBodies(db *schema.Database) []bodyFunction {
+	triggerTables := make(map[string]map[string]bool)
+
+	for i := range db.Triggers {
+		trigger := &db.Triggers[i]
+		fn := schema.QualifiedName(
+			schema.NormalizeSchemaName(trigger.FunctionSchema),
+			schema.NormalizeIdentifier(trigger.FunctionName),
+		)
+
+		if triggerTables[fn] == nil {
+			triggerTables[fn] = make(map[string]bool)
+		}
+
+		triggerTables[fn][TableKey(trigger.Schema, trigger.TableName)] = true
+	}
+
+	functions := make([]bodyFunction, 0, len(db.Functions))
+
+	for i := range db.Functions {
+		fn := &db.Functions[i]
+
+		tokens, err := parser.NewLexer(fn.Body).Tokenize()
+		if err != nil {
+			continue
+		}
+
+		name := schema.QualifiedName(
+			schema.NormalizeSchemaName(fn.Schema),
+			schema.NormalizeIdentifier(fn.Name),
+		)
+
+		functions = append(functions, bodyFunction{
+			name:          name,
+			statements:    splitStatements(tokens),
+			triggerTables: triggerTables[name],
+		})
+	}
+
+	return functions
+}
+
+// splitStatements splits tokens at semicolons, dropping comments and the
+// end of input.
+func splitStatements(tokens []parser.Token) [][]parser.Token {
+	var (
+		statements [][]parser.Token
+		current    []parser.Token
+	)
+
+	for _, tok := range tokens {
+		switch tok.Type {
+		case parser.TokenComment, parser.TokenEOF:
+		case parser.TokenSemicolon:
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+		default:
+			current = append(current, tok)
+		}
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+
+	return statements
+}
+
+// usesColumn reports whether any statement of the body appears to use the
+// column.
+func (f *bodyFunction) usesColumn(ref columnRef) bool {
+	rowQualifiers := f.triggerTables[ref.table]
+
+	for _, statement := range f.statements {
+		if statementUsesColumn(statement, ref, rowQualifiers) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statementUsesColumn reports whether a statement of a function body appears
+// to use the column. rowQualifiers is set when NEW and OLD are rows of the
+// column's table.
+func statementUsesColumn(tokens []parser.Token, ref columnRef, rowQualifiers bool) bool {
+	qualifiers, positions := statementTableQualifiers(tokens, ref.table)
+
+	at := func(i int) parser.Token {
+		if i < 0 || i >= len(tokens) {
+			return parser.Token{Type: parser.TokenEOF}
+		}
+
+		return tokens[i]
+	}
+
+	for i, tok := range tokens {
+		if !isNameToken(tok) || positions[i] ||
+			schema.FoldIdentifier(tok.Literal) != ref.column {
+			continue
+		}
+
+		next := at(i + 1)
+		if next.Type == parser.TokenLParen || next.Type == parser.TokenDot {
+			continue
+		}
+
+		if prev := at(i - 1); prev.Type == parser.TokenDot {
+			qualifier := schema.FoldIdentifier(at(i - 2).Literal)
+			if qualifiers[qualifier] ||
+				rowQualifiers && (qualifier == "new" || qualifier == "old") {
+				return true
+			}
+
+			continue
+		}
+
+		if len(qualifiers) > 0 && !tokenLiteralEqual(at(i-1), "AS") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statementTableQualifiers returns the names that qualify the columns of the
+// table in a statement, the table's own name and its aliases, when the
+// statement writes or reads the table, along with the positions of every
+// token that names a relation or its alias.
+func statementTableQualifiers(
+	tokens []parser.Token,
+	tableKey string,
+) (map[string]bool, map[int]bool) {
+	tableSchema, tableName, _ := strings.Cut(tableKey, ".")
+
+	qualifiers := make(map[string]bool)
+	positions := make(map[int]bool)
+
+	for i := 0; i < len(tokens); i++ {
+		if !slices.ContainsFunc(relationTargetWords, func(word string) bool {
+			return tokenLiteralEqual(tokens[i], word)
+		}) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(tokens) && tokenLiteralEqual(tokens[j], "ONLY") {
+			j++
+		}
+
+		if j >= len(tokens) || !isNameToken(tokens[j]) {
+			continue
+		}
+
+		var parts []string
+
+		for {
+			positions[j] = true
+			parts = append(parts, schema.FoldIdentifier(tokens[j].Literal))
+
+			if j+2 >= len(tokens) || tokens[j+1].Type != parser.TokenDot ||
+				!isNameToken(tokens[j+2]) {
+				break
+			}
+
+			j += 2
+		}
+
+		// A name called like a function, or followed by a column list of a
+		// function, is not a relation; INSERT INTO t (a, b) still is.
+		if j+1 < len(tokens) && tokens[j+1].Type == parser.TokenLParen &&
+			!tokenLiteralEqual(tokens[i], "INTO") {
+			continue
+		}
+
+		name := parts[len(parts)-1]
+		matches := name == tableName &&
+			(len(parts) == 1 || parts[len(parts)-2] == tableSchema)
+
+		if matches {
+			qualifiers[name] = true
+		}
+
+		j++
+		if j < len(tokens) && tokenLiteralEqual(tokens[j], "AS") {
+			j++
+		}
+
+		if j < len(tokens) && (tokens[j].Type == parser.TokenIdentifier ||
+			tokens[j].Type == parser.TokenQuotedIdentifier) &&
+			!slices.ContainsFunc(aliasStopWords, func(word string) bool {
+				return tokenLiteralEqual(tokens[j], word)
+			}) {
+			positions[j] = true
+
+			if matches {
+				qualifiers[schema.FoldIdentifier(tokens[j].Literal)] = true
+			}
+
+			j++
+		}
+
+		i = j - 1
+	}
+
+	return qualifiers, positions
+}
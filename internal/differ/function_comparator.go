@@ -2,6 +2,7 @@ package differ
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
@@ -9,16 +10,21 @@ import (
 
 type FunctionComparator struct {
 	options *Options
+	types   *schema.DataTypeMapper
 }
 
 func NewFunctionComparator(opts *Options) *FunctionComparator {
-	return &FunctionComparator{options: opts}
+	return &FunctionComparator{
+		options: opts,
+		types:   schema.NewDataTypeMapper(opts.TypeEquivalences),
+	}
 }
 
 func (fc *FunctionComparator) Compare(result *DiffResult) {
 	currentFuncs := buildFunctionMap(result.Current.Functions)
 	desiredFuncs := buildFunctionMap(result.Desired.Functions)
 
+	fc.detectRelocatedFunctions(result, currentFuncs, desiredFuncs)
 	fc.detectAddedFunctions(result, currentFuncs, desiredFuncs)
 	fc.detectDroppedFunctions(result, currentFuncs, desiredFuncs)
 	fc.detectModifiedFunctions(result, currentFuncs, desiredFuncs, result.Current.Triggers)
@@ -30,31 +36,7 @@ func (fc *FunctionComparator) detectAddedFunctions(
 ) {
 	for key, fn := range desiredFuncs {
 		if _, exists := currentFuncs[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeAddFunction,
-				Severity:    SeveritySafe,
-				Description: "Add function: " + fn.Signature(),
-				ObjectType:  "function",
-				ObjectName:  key,
-				Details: map[string]any{
-					"function": fn,
-				},
-			})
-
-			if !fc.options.IgnoreComments && fn.Comment != "" {
-				result.Changes = append(result.Changes, Change{
-					Type:        ChangeTypeModifyFunction,
-					Severity:    SeveritySafe,
-					Description: "Add function comment: " + fn.Signature(),
-					ObjectType:  "function",
-					ObjectName:  key,
-					Details: map[string]any{
-						"function":    fn,
-						"old_comment": "",
-						"new_comment": fn.Comment,
-					},
-				})
-			}
+			fc.compareFunction(result, key, nil, fn, nil)
 		}
 	}
 }
@@ -65,16 +47,7 @@ func (fc *FunctionComparator) detectDroppedFunctions(
 ) {
 	for key, fn := range currentFuncs {
 		if _, exists := desiredFuncs[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeDropFunction,
-				Severity:    SeverityBreaking,
-				Description: "Drop function: " + fn.Signature(),
-				ObjectType:  "function",
-				ObjectName:  key,
-				Details: map[string]any{
-					"function": fn,
-				},
-			})
+			fc.compareFunction(result, key, fn, nil, nil)
 		}
 	}
 }
@@ -85,71 +58,142 @@ func (fc *FunctionComparator) detectModifiedFunctions(
 	triggers []schema.Trigger,
 ) {
 	for key, desiredFn := range desiredFuncs {
-		currentFn, exists := currentFuncs[key]
-		if !exists {
-			continue
+		if currentFn, exists := currentFuncs[key]; exists {
+			// Triggers only decide the severity of a change, so an equal pair
+			// stays equal whatever calls it.
+			fc.options.Cache.compare(result, "function", key, currentFn, desiredFn, func() {
+				fc.compareFunction(result, key, currentFn, desiredFn, triggers)
+			})
 		}
+	}
+}
 
-		sigEqual := currentFn.Signature() == desiredFn.Signature()
-		retEqual := NormalizeDataType(
-			currentFn.ReturnType,
-		) == NormalizeDataType(
-			desiredFn.ReturnType,
-		)
-		langEqual := strings.EqualFold(currentFn.Language, desiredFn.Language)
-		volEqual := currentFn.Volatility == desiredFn.Volatility
-		secDefEqual := currentFn.IsSecurityDefiner == desiredFn.IsSecurityDefiner
-		strictEqual := currentFn.IsStrict == desiredFn.IsStrict
-		body1 := normalizeFunctionBody(currentFn.Body)
-		body2 := normalizeFunctionBody(desiredFn.Body)
-		bodyEqual := body1 == body2
-		currentComment := normalizeComment(currentFn.Comment)
-		desiredComment := normalizeComment(desiredFn.Comment)
-		commentEqual := currentComment == desiredComment
-
-		funcBodyEqual := sigEqual && retEqual && langEqual && volEqual && secDefEqual &&
-			strictEqual && bodyEqual
-
-		if !funcBodyEqual {
-			severity := SeverityPotentiallyBreaking
-			if isTriggerFunction(triggers, currentFn) {
-				severity = SeverityBreaking
-			}
+// compareFunction appends the changes that turn current into desired. A nil
+// current adds the function and a nil desired drops it. triggers are the
+// current triggers; changing a function one of them calls is breaking.
+func (fc *FunctionComparator) compareFunction(
+	result *DiffResult,
+	key string,
+	current, desired *schema.Function,
+	triggers []schema.Trigger,
+) {
+	switch {
+	case current == nil && desired == nil:
+		return
+	case current == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeAddFunction,
+			Severity:    SeveritySafe,
+			Description: describeAdded(desired.KindName(), desired.Signature()),
+			ObjectType:  "function",
+			ObjectName:  key,
+			Details: map[string]any{
+				"function": desired,
+			},
+		})
 
+		if !fc.options.IgnoreComments && desired.Comment != "" {
 			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeModifyFunction,
-				Severity:    severity,
-				Description: "Modify function: " + desiredFn.Signature(),
-				ObjectType:  "function",
-				ObjectName:  key,
+				Type:     ChangeTypeModifyFunction,
+				Severity: SeveritySafe,
+				Description: describeComment(desired.KindName(), desired.Signature(),
+					"", desired.Comment),
+				ObjectType: "function",
+				ObjectName: key,
 				Details: map[string]any{
-					"current": currentFn,
-					"desired": desiredFn,
+					"function":    desired,
+					"old_comment": "",
+					"new_comment": desired.Comment,
 				},
 			})
 		}
+	case desired == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeDropFunction,
+			Severity:    SeverityBreaking,
+			Description: describeDropped(current.KindName(), current.Signature()),
+			ObjectType:  "function",
+			ObjectName:  key,
+			Details: map[string]any{
+				"function": current,
+			},
+		})
+	default:
+		fc.compareModifiedFunction(result, key, current, desired, triggers)
+	}
+}
 
-		if !fc.options.IgnoreComments && !commentEqual && funcBodyEqual {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeModifyFunction,
-				Severity:    SeveritySafe,
-				Description: "Modify function comment: " + desiredFn.Signature(),
-				ObjectType:  "function",
-				ObjectName:  key,
-				Details: map[string]any{
-					"function":    desiredFn,
-					"old_comment": currentFn.Comment,
-					"new_comment": desiredFn.Comment,
-				},
-			})
+func (fc *FunctionComparator) compareModifiedFunction(
+	result *DiffResult,
+	key string,
+	currentFn, desiredFn *schema.Function,
+	triggers []schema.Trigger,
+) {
+	sigEqual := currentFn.QualifiedName() == desiredFn.QualifiedName() &&
+		fc.sameTypes(currentFn.ArgumentTypes, desiredFn.ArgumentTypes)
+	retEqual := fc.sameTypes([]string{currentFn.ReturnType}, []string{desiredFn.ReturnType})
+	langEqual := strings.EqualFold(currentFn.Language, desiredFn.Language)
+	volEqual := currentFn.Volatility == desiredFn.Volatility
+	secDefEqual := currentFn.IsSecurityDefiner == desiredFn.IsSecurityDefiner
+	strictEqual := currentFn.IsStrict == desiredFn.IsStrict
+	body1 := normalizeFunctionBody(currentFn.Body)
+	body2 := normalizeFunctionBody(desiredFn.Body)
+	bodyEqual := body1 == body2
+	commentEqual := fc.options.commentsEqual(currentFn.Comment, desiredFn.Comment)
+
+	funcBodyEqual := sigEqual && retEqual && langEqual && volEqual && secDefEqual &&
+		strictEqual && bodyEqual
+
+	if !funcBodyEqual {
+		severity := SeverityPotentiallyBreaking
+		if isTriggerFunction(triggers, currentFn) {
+			severity = SeverityBreaking
 		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeModifyFunction,
+			Severity:    severity,
+			Description: describeReplaced(desiredFn.KindName(), desiredFn.Signature()),
+			ObjectType:  "function",
+			ObjectName:  key,
+			Details: map[string]any{
+				"current": currentFn,
+				"desired": desiredFn,
+			},
+		})
+	}
+
+	if !fc.options.IgnoreComments && !commentEqual && funcBodyEqual {
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeModifyFunction,
+			Severity: SeveritySafe,
+			Description: describeComment(desiredFn.KindName(), desiredFn.Signature(),
+				currentFn.Comment, desiredFn.Comment),
+			ObjectType: "function",
+			ObjectName: key,
+			Details: map[string]any{
+				"function":    desiredFn,
+				"old_comment": currentFn.Comment,
+				"new_comment": desiredFn.Comment,
+			},
+		})
 	}
 }
 
-type TriggerComparator struct{}
+// sameTypes reports whether current and desired name the same data types in
+// the same order, once aliases and type equivalences are resolved.
+func (fc *FunctionComparator) sameTypes(current, desired []string) bool {
+	return slices.EqualFunc(current, desired, func(a, b string) bool {
+		return fc.types.Canonical(a) == fc.types.Canonical(b)
+	})
+}
 
-func NewTriggerComparator() *TriggerComparator {
-	return &TriggerComparator{}
+type TriggerComparator struct {
+	options *Options
+}
+
+func NewTriggerComparator(opts *Options) *TriggerComparator {
+	return &TriggerComparator{options: opts}
 }
 
 func (tc *TriggerComparator) Compare(result *DiffResult) {
@@ -167,21 +211,7 @@ func (tc *TriggerComparator) detectAddedTriggers(
 ) {
 	for key, trigger := range desiredTriggers {
 		if _, exists := currentTriggers[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeAddTrigger,
-				Severity: SeveritySafe,
-				Description: fmt.Sprintf(
-					"Add trigger: %s on %s",
-					trigger.Name,
-					trigger.QualifiedTableName(),
-				),
-				ObjectType: "trigger",
-				ObjectName: key,
-				Details: map[string]any{
-					"trigger": trigger,
-				},
-				DependsOn: tc.buildTriggerDependencies(trigger, result.Desired, true),
-			})
+			tc.compareTrigger(result, key, nil, trigger)
 		}
 	}
 }
@@ -192,25 +222,7 @@ func (tc *TriggerComparator) detectDroppedTriggers(
 ) {
 	for key, trigger := range currentTriggers {
 		if _, exists := desiredTriggers[key]; !exists {
-			if tc.isInheritedPartitionTrigger(trigger, result.Desired) {
-				continue
-			}
-
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeDropTrigger,
-				Severity: SeverityBreaking,
-				Description: fmt.Sprintf(
-					"Drop trigger: %s from %s",
-					trigger.Name,
-					trigger.QualifiedTableName(),
-				),
-				ObjectType: "trigger",
-				ObjectName: key,
-				Details: map[string]any{
-					"trigger": trigger,
-				},
-				DependsOn: tc.buildTriggerDependencies(trigger, result.Current, false),
-			})
+			tc.compareTrigger(result, key, trigger, nil)
 		}
 	}
 }
@@ -219,32 +231,142 @@ func (tc *TriggerComparator) detectModifiedTriggers(
 	result *DiffResult,
 	currentTriggers, desiredTriggers map[string]*schema.Trigger,
 ) {
+	// A trigger calling a moved or renamed function already calls it under its
+	// new name, so only other differences need the trigger recreated.
+	relocated := relocatedFunctions(result.Changes)
+
 	for key, desiredTrigger := range desiredTriggers {
-		currentTrigger, exists := currentTriggers[key]
-		if !exists {
-			continue
+		if currentTrigger, exists := currentTriggers[key]; exists {
+			current := followRelocatedFunction(currentTrigger, relocated)
+			tc.options.Cache.compare(result, "trigger", key, current, desiredTrigger, func() {
+				tc.compareTrigger(result, key, current, desiredTrigger)
+			})
 		}
+	}
+}
 
-		if areTriggersEqual(currentTrigger, desiredTrigger) {
-			continue
+// compareTrigger appends the change that turns current into desired. A nil
+// current adds the trigger and a nil desired drops it. Function dependencies
+// are resolved against result.Current and result.Desired.
+func (tc *TriggerComparator) compareTrigger(
+	result *DiffResult,
+	key string,
+	current, desired *schema.Trigger,
+) {
+	switch {
+	case current == nil && desired == nil:
+		return
+	case current == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeAddTrigger,
+			Severity: SeveritySafe,
+			Description: describeAdded("trigger",
+				desired.Name+" on "+desired.QualifiedTableName()),
+			ObjectType: "trigger",
+			ObjectName: key,
+			Details: map[string]any{
+				"trigger": desired,
+			},
+			DependsOn: tc.buildTriggerDependencies(desired, result.Desired, true),
+		})
+
+		tc.addCommentChange(result, key, desired, "")
+	case desired == nil:
+		if tc.isInheritedPartitionTrigger(current, result.Desired) {
+			return
 		}
 
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeDropTrigger,
+			Severity: SeverityBreaking,
+			Description: describeDropped("trigger",
+				current.Name+" on "+current.QualifiedTableName()),
+			ObjectType: "trigger",
+			ObjectName: key,
+			Details: map[string]any{
+				"trigger": current,
+			},
+			DependsOn: tc.buildTriggerDependencies(current, result.Current, false),
+		})
+	case areTriggersEqual(current, desired):
+		if current.GetEnabledState() != desired.GetEnabledState() {
+			result.Changes = append(result.Changes, tc.enabledStateChange(key, current, desired))
+		}
+
+		if !tc.options.commentsEqual(current.Comment, desired.Comment) {
+			tc.addCommentChange(result, key, desired, current.Comment)
+		}
+	default:
 		result.Changes = append(result.Changes, Change{
 			Type:     ChangeTypeModifyTrigger,
 			Severity: SeverityBreaking,
-			Description: fmt.Sprintf(
-				"Modify trigger: %s on %s",
-				desiredTrigger.Name,
-				desiredTrigger.QualifiedTableName(),
-			),
+			Description: describeReplaced("trigger",
+				desired.Name+" on "+desired.QualifiedTableName()),
 			ObjectType: "trigger",
 			ObjectName: key,
 			Details: map[string]any{
-				"current": currentTrigger,
-				"desired": desiredTrigger,
+				"current": current,
+				"desired": desired,
 			},
-			DependsOn: tc.buildTriggerDependencies(desiredTrigger, result.Desired, true),
+			DependsOn: tc.buildTriggerDependencies(desired, result.Desired, true),
 		})
+
+		// Recreating the trigger drops its comment, so a desired comment is
+		// set again even when it did not change.
+		tc.addCommentChange(result, key, desired, "")
+	}
+}
+
+func (tc *TriggerComparator) addCommentChange(
+	result *DiffResult,
+	key string,
+	trigger *schema.Trigger,
+	oldComment string,
+) {
+	if tc.options.IgnoreComments || (trigger.Comment == "" && oldComment == "") {
+		return
+	}
+
+	severity := SeveritySafe
+	if trigger.Comment == "" {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyTriggerComment,
+		Severity: severity,
+		Description: describeComment("trigger", trigger.Name+" on "+trigger.QualifiedTableName(),
+			oldComment, trigger.Comment),
+		ObjectType: "trigger",
+		ObjectName: key,
+		Details: map[string]any{
+			"trigger":     trigger,
+			"old_comment": oldComment,
+			"new_comment": trigger.Comment,
+		},
+	})
+}
+
+// enabledStateChange is a trigger whose definition is unchanged but which is
+// enabled or disabled differently, which ALTER TABLE changes in place.
+func (tc *TriggerComparator) enabledStateChange(
+	key string,
+	current, desired *schema.Trigger,
+) Change {
+	return Change{
+		Type:     ChangeTypeModifyTrigger,
+		Severity: SeverityPotentiallyBreaking,
+		Description: describeValue("Trigger state",
+			desired.Name+" on "+desired.QualifiedTableName(),
+			current.GetEnabledState(), desired.GetEnabledState()),
+		ObjectType: "trigger",
+		ObjectName: key,
+		Details: map[string]any{
+			"current":            current,
+			"desired":            desired,
+			"enabled_state_only": true,
+		},
+		DependsOn: []string{desired.QualifiedTableName()},
 	}
 }
 
@@ -252,7 +374,7 @@ func buildFunctionMap(functions []schema.Function) map[string]*schema.Function {
 	m := make(map[string]*schema.Function, len(functions))
 	for i := range functions {
 		fn := &functions[i]
-		key := FunctionKey(fn.Schema, fn.Name, fn.ArgumentTypes)
+		key := RoutineKey(fn)
 		m[key] = fn
 	}
 
@@ -273,8 +395,8 @@ func buildTriggerMap(triggers []schema.Trigger) map[string]*schema.Trigger {
 func triggerKey(trigger *schema.Trigger) string {
 	return fmt.Sprintf("%s.%s.%s",
 		normalizeSchema(trigger.Schema),
-		strings.ToLower(trigger.TableName),
-		strings.ToLower(trigger.Name))
+		trigger.TableName,
+		trigger.Name)
 }
 
 func areTriggersEqual(t1, t2 *schema.Trigger) bool {
@@ -347,7 +469,7 @@ func (tc *TriggerComparator) resolveTriggerFunctionKeys(
 
 	for i := range db.Functions {
 		fn := &db.Functions[i]
-		if schema.NormalizeSchemaName(fn.Schema) == normalizedSchema &&
+		if !fn.IsProcedure() && schema.NormalizeSchemaName(fn.Schema) == normalizedSchema &&
 			schema.NormalizeIdentifier(fn.Name) == normalizedName {
 			keys = append(keys, FunctionKey(fn.Schema, fn.Name, fn.ArgumentTypes))
 		}
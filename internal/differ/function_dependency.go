@@ -0,0 +1,226 @@
+package differ
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+var functionCallPattern = regexp.MustCompile(
+	`(?i)((?:"[^"]+"|[a-z_][a-z0-9_$]*)(?:\s*\.\s*(?:"[^"]+"|[a-z_][a-z0-9_$]*))?)\s*\(`,
+)
+
+// addFunctionDependencies makes views, materialized views, continuous
+// aggregates, column defaults and generated columns depend on the
+// user-defined functions they call. Only functions declared in the desired
+// schema are matched, so built-ins never produce edges, and a call matches
+// every overload of the name. Function bodies are only scanned for the views
+// a LANGUAGE sql function selects from: a function that reads a table must
+// not be ordered after it, or a default calling that function would cycle.
+func (d *Differ) addFunctionDependencies(result *DiffResult) {
+	declared := declaredFunctionKeys(result.Desired.Functions)
+	if len(declared) == 0 {
+		return
+	}
+
+	views := declaredViewKeys(result.Desired)
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+
+		deps := functionBodyDependencies(change, views)
+		objectSchema, expressions := functionCallSources(change)
+
+		for _, expr := range expressions {
+			deps = append(deps, referencedFunctionKeys(expr, objectSchema, declared)...)
+		}
+
+		if len(deps) > 0 {
+			change.DependsOn = dedupeDependencies(append(change.DependsOn, deps...))
+		}
+	}
+}
+
+// declaredFunctionKeys maps "schema.name" to the keys of every overload
+// declared under that name.
+func declaredFunctionKeys(functions []schema.Function) map[string][]string {
+	declared := make(map[string][]string, len(functions))
+
+	for i := range functions {
+		fn := &functions[i]
+		name := schema.QualifiedName(
+			schema.NormalizeSchemaName(fn.Schema),
+			schema.NormalizeIdentifier(fn.Name),
+		)
+		declared[name] = append(declared[name], RoutineKey(fn))
+	}
+
+	return declared
+}
+
+// declaredViewKeys returns the keys of the views and materialized views of
+// db.
+func declaredViewKeys(db *schema.Database) map[string]bool {
+	views := make(map[string]bool, len(db.Views)+len(db.MaterializedViews))
+
+	for i := range db.Views {
+		views[ViewKey(db.Views[i].Schema, db.Views[i].Name)] = true
+	}
+
+	for i := range db.MaterializedViews {
+		views[ViewKey(db.MaterializedViews[i].Schema, db.MaterializedViews[i].Name)] = true
+	}
+
+	return views
+}
+
+// functionBodyDependencies returns the views and materialized views the body
+// of an added or rewritten LANGUAGE sql function selects from. PostgreSQL
+// resolves a SQL body when the function is created, so they have to exist
+// first; other languages resolve their bodies only when they run.
+func functionBodyDependencies(change *Change, views map[string]bool) []string {
+	var fn *schema.Function
+
+	switch change.Type {
+	case ChangeTypeAddFunction:
+		fn, _ = change.Details["function"].(*schema.Function)
+	case ChangeTypeModifyFunction:
+		fn, _ = change.Details["desired"].(*schema.Function)
+	}
+
+	if fn == nil || !strings.EqualFold(fn.Language, "sql") {
+		return nil
+	}
+
+	fnSchema := schema.NormalizeSchemaName(fn.Schema)
+
+	var deps []string
+
+	for _, name := range extractViewDependencies(fn.Body) {
+		candidates := []string{name}
+		if len(splitIdentifierParts(name)) == 1 {
+			candidates = []string{
+				schema.QualifiedName(fnSchema, name),
+				schema.QualifiedName(schema.DefaultSchema, name),
+			}
+		}
+
+		for _, candidate := range candidates {
+			if views[candidate] {
+				deps = append(deps, candidate)
+				break
+			}
+		}
+	}
+
+	return deps
+}
+
+// functionCallSources returns the schema an object lives in and the SQL
+// expressions of the change that may call functions.
+func functionCallSources(change *Change) (string, []string) {
+	switch change.Type {
+	case ChangeTypeAddView, ChangeTypeModifyView:
+		view := viewFromDetails(change)
+		if view == nil {
+			return "", nil
+		}
+
+		return view.Schema, []string{view.Definition}
+	case ChangeTypeAddMaterializedView:
+		switch view := change.Details["view"].(type) {
+		case *schema.MaterializedView:
+			return view.Schema, []string{view.Definition}
+		case schema.MaterializedView:
+			return view.Schema, []string{view.Definition}
+		}
+	case ChangeTypeAddContinuousAggregate:
+		if agg, ok := change.Details["aggregate"].(*schema.ContinuousAggregate); ok {
+			return agg.Schema, []string{agg.Query}
+		}
+	case ChangeTypeAddTable:
+		if table, ok := change.Details["table"].(*schema.Table); ok {
+			var expressions []string
+			for i := range table.Columns {
+				expressions = append(expressions, columnExpressions(&table.Columns[i])...)
+			}
+
+			return table.Schema, expressions
+		}
+	case ChangeTypeAddColumn:
+		if col, ok := change.Details["column"].(*schema.Column); ok {
+			return extractSchemaFromChange(change), columnExpressions(col)
+		}
+	case ChangeTypeModifyColumnDefault:
+		if newDefault, ok := change.Details["new_default"].(string); ok && newDefault != "" {
+			return extractSchemaFromChange(change), []string{newDefault}
+		}
+	}
+
+	return "", nil
+}
+
+func viewFromDetails(change *Change) *schema.View {
+	key := "view"
+	if change.Type == ChangeTypeModifyView {
+		key = "desired"
+	}
+
+	switch view := change.Details[key].(type) {
+	case *schema.View:
+		return view
+	case schema.View:
+		return &view
+	}
+
+	return nil
+}
+
+func columnExpressions(col *schema.Column) []string {
+	var expressions []string
+
+	if col.Default != "" {
+		expressions = append(expressions, col.Default)
+	}
+
+	if col.GenerationExpression != "" {
+		expressions = append(expressions, col.GenerationExpression)
+	}
+
+	return expressions
+}
+
+// referencedFunctionKeys returns the keys of declared functions called in
+// expr. Unqualified calls resolve against the object's schema first and then
+// public, mirroring the default search_path.
+func referencedFunctionKeys(expr, objectSchema string, declared map[string][]string) []string {
+	expr = schema.MaskStringLiterals(expr)
+	objectSchema = schema.NormalizeSchemaName(objectSchema)
+
+	var keys []string
+
+	for _, match := range functionCallPattern.FindAllStringSubmatch(expr, -1) {
+		name := normalizeDependencyIdentifier(match[1])
+		if name == "" {
+			continue
+		}
+
+		candidates := []string{name}
+		if len(splitIdentifierParts(name)) == 1 {
+			candidates = []string{
+				schema.QualifiedName(objectSchema, name),
+				schema.QualifiedName(schema.DefaultSchema, name),
+			}
+		}
+
+		for _, candidate := range candidates {
+			if overloads, ok := declared[candidate]; ok {
+				keys = append(keys, overloads...)
+				break
+			}
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,181 @@
+package differ
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// detectRelocatedFunctions pairs functions that exist only in the current
+// schema with functions that exist only in the desired one and have the same
+// definition under another schema or, with DetectRenames, another name. Each
+// pair becomes one ModifyFunction change that ALTER FUNCTION applies in place,
+// and is removed from currentFuncs and desiredFuncs. Functions with more than
+// one candidate are left to be dropped and added.
+func (fc *FunctionComparator) detectRelocatedFunctions(
+	result *DiffResult,
+	currentFuncs, desiredFuncs map[string]*schema.Function,
+) {
+	var dropped, added []string
+
+	for key := range currentFuncs {
+		if _, exists := desiredFuncs[key]; !exists {
+			dropped = append(dropped, key)
+		}
+	}
+
+	for key := range desiredFuncs {
+		if _, exists := currentFuncs[key]; !exists {
+			added = append(added, key)
+		}
+	}
+
+	slices.Sort(dropped)
+	slices.Sort(added)
+
+	for _, from := range dropped {
+		targets := fc.relocationCandidates(currentFuncs[from], added, desiredFuncs)
+		if len(targets) != 1 {
+			continue
+		}
+
+		to := targets[0]
+		if len(fc.relocationCandidates(desiredFuncs[to], dropped, currentFuncs)) != 1 {
+			continue
+		}
+
+		result.Changes = append(result.Changes,
+			fc.relocationChange(to, currentFuncs[from], desiredFuncs[to]))
+
+		delete(currentFuncs, from)
+		delete(desiredFuncs, to)
+	}
+}
+
+// relocationCandidates returns the keys of funcs whose function is fn moved
+// or renamed. Keys already paired off are skipped.
+func (fc *FunctionComparator) relocationCandidates(
+	fn *schema.Function,
+	keys []string,
+	funcs map[string]*schema.Function,
+) []string {
+	var matches []string
+
+	for _, key := range keys {
+		if other, exists := funcs[key]; exists && fc.isRelocation(fn, other) {
+			matches = append(matches, key)
+		}
+	}
+
+	return matches
+}
+
+// isRelocation reports whether desired is current moved to another schema, or
+// renamed within its schema when DetectRenames is set, with everything else
+// about the function unchanged.
+func (fc *FunctionComparator) isRelocation(current, desired *schema.Function) bool {
+	sameSchema := normalizeSchema(current.Schema) == normalizeSchema(desired.Schema)
+	sameName := strings.EqualFold(current.Name, desired.Name)
+
+	switch {
+	case sameName && sameSchema:
+		return false
+	case !sameName && !sameSchema:
+		return false
+	case !sameName && !fc.options.DetectRenames:
+		return false
+	}
+
+	return current.Kind == desired.Kind &&
+		equalFunctionDataTypes(current.ArgumentTypes, desired.ArgumentTypes) &&
+		equalStringSlices(current.ArgumentNames, desired.ArgumentNames) &&
+		equalStringSlices(current.ArgumentModes, desired.ArgumentModes) &&
+		NormalizeDataType(current.ReturnType) == NormalizeDataType(desired.ReturnType) &&
+		strings.EqualFold(current.Language, desired.Language) &&
+		current.Volatility == desired.Volatility &&
+		current.IsStrict == desired.IsStrict &&
+		current.IsSecurityDefiner == desired.IsSecurityDefiner &&
+		current.IsAggregate == desired.IsAggregate &&
+		current.IsWindow == desired.IsWindow &&
+		normalizeFunctionBody(current.Body) == normalizeFunctionBody(desired.Body)
+}
+
+func equalFunctionDataTypes(a, b []string) bool {
+	return slices.EqualFunc(a, b, func(x, y string) bool {
+		return NormalizeDataType(x) == NormalizeDataType(y)
+	})
+}
+
+// relocationChange moves or renames current into desired, and sets its comment
+// if that changed too. Triggers and views that call the function follow it,
+// since PostgreSQL binds them to the function itself rather than to its name.
+func (fc *FunctionComparator) relocationChange(
+	key string,
+	current, desired *schema.Function,
+) Change {
+	description := describe(descRelocatedFunction,
+		capitalize(current.KindName()), current.Signature(), normalizeSchema(desired.Schema))
+	if !strings.EqualFold(current.Name, desired.Name) {
+		description = describe(descRenamedFunction,
+			capitalize(current.KindName()), current.Signature(), desired.Name)
+	}
+
+	commentChanged := !fc.options.IgnoreComments &&
+		!fc.options.commentsEqual(current.Comment, desired.Comment)
+
+	return Change{
+		Type:        ChangeTypeModifyFunction,
+		Severity:    SeverityPotentiallyBreaking,
+		Description: description,
+		ObjectType:  "function",
+		ObjectName:  key,
+		Details: map[string]any{
+			"current":         current,
+			"desired":         desired,
+			"relocated":       true,
+			"comment_changed": commentChanged,
+		},
+	}
+}
+
+// relocatedFunctions maps the lowercased qualified name of each moved or
+// renamed function of the current schema to the function it becomes.
+func relocatedFunctions(changes []Change) map[string]*schema.Function {
+	relocated := make(map[string]*schema.Function)
+
+	for i := range changes {
+		change := &changes[i]
+		if change.Type != ChangeTypeModifyFunction || change.Details["relocated"] != true {
+			continue
+		}
+
+		current, _ := change.Details["current"].(*schema.Function)
+		desired, _ := change.Details["desired"].(*schema.Function)
+
+		if current != nil && desired != nil {
+			relocated[strings.ToLower(current.QualifiedName())] = desired
+		}
+	}
+
+	return relocated
+}
+
+// followRelocatedFunction returns trigger calling its function by the name the
+// function is moved or renamed to, which is what the trigger calls once the
+// function is relocated.
+func followRelocatedFunction(
+	trigger *schema.Trigger,
+	relocated map[string]*schema.Function,
+) *schema.Trigger {
+	fn, ok := relocated[strings.ToLower(trigger.QualifiedFunctionName())]
+	if !ok {
+		return trigger
+	}
+
+	followed := *trigger
+	followed.FunctionSchema = normalizeSchema(fn.Schema)
+	followed.FunctionName = fn.Name
+
+	return &followed
+}
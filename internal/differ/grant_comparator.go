@@ -0,0 +1,288 @@
+package differ
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// DetailKeyGrantOptionOnly marks an ADD_GRANT or REVOKE_GRANT change that
+// only gives or takes the grant option of privileges the grantee keeps.
+const DetailKeyGrantOptionOnly = "grant_option_only"
+
+// heldPrivileges are the privileges a grantee holds on an object or column,
+// each with whether it is held with the grant option.
+type heldPrivileges struct {
+	grant      *schema.Grant
+	privileges map[string]bool
+}
+
+// compareGrants appends the changes that give the grantees named by the
+// grants of the desired schema the privileges it declares on tables, views,
+// materialized views and their columns. Grantees it never names, PUBLIC
+// included, are left as they are, so the privileges a database sets up
+// elsewhere do not show up as changes. An object the plan drops and creates
+// again, or replaces, loses its privileges, so its current grants are revoked before the
+// drop and the desired ones granted again after it is created.
+func (d *Differ) compareGrants(result *DiffResult) {
+	managed := make(map[string]bool)
+	for i := range result.Desired.Grants {
+		managed[result.Desired.Grants[i].GranteeName()] = true
+	}
+
+	if len(managed) == 0 {
+		return
+	}
+
+	current := collectPrivileges(result.Current.Grants, managed)
+	desired := collectPrivileges(result.Desired.Grants, nil)
+	recreated := recreatedRelations(result.Changes)
+	missing := missingGrantees(result.Current.Roles, managed)
+
+	keys := slices.Sorted(maps.Keys(current))
+	for key := range desired {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		cur, des := current[key], desired[key]
+
+		grant := grantOf(cur, des)
+		if missing[grant.GranteeName()] {
+			continue
+		}
+
+		if recreated[TableKey(grant.Schema, grant.ObjectName)] {
+			addGrantChanges(result, grant, cur, nil)
+			addGrantChanges(result, grant, nil, des)
+
+			continue
+		}
+
+		addGrantChanges(result, grant, cur, des)
+	}
+
+	for _, grantee := range slices.Sorted(maps.Keys(missing)) {
+		result.addWarning(diag.Warning{
+			Code:     diag.CodeMissingGrantee,
+			Severity: diag.SeverityWarning,
+			Message: fmt.Sprintf(
+				"role %s of the desired grants does not exist in the database; "+
+					"its grants are left out of the plan", grantee),
+			ObjectName: grantee,
+		})
+	}
+}
+
+// collectPrivileges returns the privileges of grants by grant Key, for the
+// grantees of managed alone when it is set.
+func collectPrivileges(grants []schema.Grant, managed map[string]bool) map[string]*heldPrivileges {
+	held := make(map[string]*heldPrivileges)
+
+	for i := range grants {
+		grant := &grants[i]
+		if managed != nil && !managed[grant.GranteeName()] {
+			continue
+		}
+
+		key := grant.Key()
+		if held[key] == nil {
+			held[key] = &heldPrivileges{grant: grant, privileges: make(map[string]bool)}
+		}
+
+		for _, privilege := range grant.PrivilegeNames() {
+			held[key].privileges[privilege] = held[key].privileges[privilege] || grant.WithGrantOption
+		}
+	}
+
+	return held
+}
+
+// missingGrantees returns the managed grantees that are not among the roles
+// of the database. Nothing is missing when the roles are unknown, as they are
+// for a schema that was not extracted.
+func missingGrantees(roles []string, managed map[string]bool) map[string]bool {
+	missing := make(map[string]bool)
+	if len(roles) == 0 {
+		return missing
+	}
+
+	for grantee := range managed {
+		if grantee != schema.GrantRolePublic && !slices.Contains(roles, grantee) {
+			missing[grantee] = true
+		}
+	}
+
+	return missing
+}
+
+// recreatedRelations returns the keys of the tables, views and materialized
+// views the plan both drops and creates, or replaces.
+func recreatedRelations(changes []Change) map[string]bool {
+	dropped := make(map[string]bool)
+	added := make(map[string]bool)
+
+	for i := range changes {
+		if replacesRelation(&changes[i]) {
+			dropped[changes[i].ObjectName] = true
+			added[changes[i].ObjectName] = true
+
+			continue
+		}
+
+		switch changes[i].Type {
+		case ChangeTypeDropTable, ChangeTypeDropView, ChangeTypeDropMaterializedView:
+			dropped[changes[i].ObjectName] = true
+		case ChangeTypeAddTable, ChangeTypeAddView, ChangeTypeAddMaterializedView:
+			added[changes[i].ObjectName] = true
+		}
+	}
+
+	recreated := make(map[string]bool)
+
+	for key := range dropped {
+		if added[key] {
+			recreated[key] = true
+		}
+	}
+
+	return recreated
+}
+
+// replacesRelation reports whether change redefines a materialized view,
+// which drops it and creates it again.
+func replacesRelation(change *Change) bool {
+	if change.Type != ChangeTypeModifyMaterializedView {
+		return false
+	}
+
+	_, ok := change.Details["desired"]
+
+	return ok
+}
+
+func grantOf(held ...*heldPrivileges) *schema.Grant {
+	for _, h := range held {
+		if h != nil {
+			return h.grant
+		}
+	}
+
+	return nil
+}
+
+// addGrantChanges appends the changes that turn the privileges cur holds into
+// those des declares: one per kind of GRANT or REVOKE statement they take.
+func addGrantChanges(result *DiffResult, grant *schema.Grant, cur, des *heldPrivileges) {
+	var granted, grantedWithOption, optionGranted, revoked, revokedWithOption, optionRevoked []string
+
+	if des != nil {
+		for _, privilege := range slices.Sorted(maps.Keys(des.privileges)) {
+			withOption := des.privileges[privilege]
+
+			currentOption, held := false, false
+			if cur != nil {
+				currentOption, held = cur.privileges[privilege]
+			}
+
+			switch {
+			case !held && withOption:
+				grantedWithOption = append(grantedWithOption, privilege)
+			case !held:
+				granted = append(granted, privilege)
+			case withOption && !currentOption:
+				optionGranted = append(optionGranted, privilege)
+			case !withOption && currentOption:
+				optionRevoked = append(optionRevoked, privilege)
+			}
+		}
+	}
+
+	if cur != nil {
+		for _, privilege := range slices.Sorted(maps.Keys(cur.privileges)) {
+			if des != nil {
+				if _, kept := des.privileges[privilege]; kept {
+					continue
+				}
+			}
+
+			if cur.privileges[privilege] {
+				revokedWithOption = append(revokedWithOption, privilege)
+			} else {
+				revoked = append(revoked, privilege)
+			}
+		}
+	}
+
+	appendGrantChange(result, ChangeTypeAddGrant, grant, granted, false, false)
+	appendGrantChange(result, ChangeTypeAddGrant, grant, grantedWithOption, true, false)
+	appendGrantChange(result, ChangeTypeAddGrant, grant, optionGranted, true, true)
+	appendGrantChange(result, ChangeTypeRevokeGrant, grant, revoked, false, false)
+	appendGrantChange(result, ChangeTypeRevokeGrant, grant, revokedWithOption, true, false)
+	appendGrantChange(result, ChangeTypeRevokeGrant, grant, optionRevoked, true, true)
+}
+
+// appendGrantChange appends the change granting or revoking privileges. The
+// grant of its details holds them with the grant option when withOption is
+// set; optionOnly limits the change to the grant option.
+func appendGrantChange(
+	result *DiffResult,
+	changeType ChangeType,
+	grant *schema.Grant,
+	privileges []string,
+	withOption, optionOnly bool,
+) {
+	if len(privileges) == 0 {
+		return
+	}
+
+	changed := *grant
+	changed.Privileges = privileges
+	changed.WithGrantOption = withOption
+
+	objectKey := TableKey(grant.Schema, grant.ObjectName)
+
+	// A revocation runs while the object still exists, before a drop or
+	// recreation of it, so only a grant waits for the object.
+	severity, dependsOn := SeveritySafe, []string{objectKey}
+	if changeType == ChangeTypeRevokeGrant {
+		severity, dependsOn = SeverityPotentiallyBreaking, nil
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:        changeType,
+		Severity:    severity,
+		Description: describeGrant(changeType, &changed, optionOnly),
+		ObjectType:  "grant",
+		ObjectName:  objectKey,
+		Details: map[string]any{
+			"table":                  grant.QualifiedObjectName(),
+			"grant":                  &changed,
+			DetailKeyGrantOptionOnly: optionOnly,
+		},
+		DependsOn: dependsOn,
+	})
+}
+
+// grantChangeOn reports whether a grant change applies to the relation of
+// tableName, and to its column when columnName is set.
+func grantChangeOn(change *Change, tableName, columnName string) bool {
+	if change.Type != ChangeTypeAddGrant && change.Type != ChangeTypeRevokeGrant {
+		return false
+	}
+
+	grant, ok := change.Details["grant"].(*schema.Grant)
+	if !ok || !strings.EqualFold(grant.QualifiedObjectName(), tableName) {
+		return false
+	}
+
+	return columnName == "" || strings.EqualFold(grant.Column, columnName)
+}
@@ -2,10 +2,10 @@ package differ
 
 import (
 	"fmt"
-	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -18,167 +18,278 @@ func NewIndexComparator(opts *Options) *IndexComparator {
 }
 
 func (ic *IndexComparator) Compare(result *DiffResult) {
-	currentIndexes := ic.buildIndexMap(result.Current)
-	desiredIndexes := ic.buildIndexMap(result.Desired)
+	currentIndexes := ic.buildIndexSet(result.Current)
+	desiredIndexes := ic.buildIndexSet(result.Desired)
 
-	ic.detectAddedIndexes(result, currentIndexes, desiredIndexes, result.Desired)
-	ic.detectDroppedIndexes(result, currentIndexes, desiredIndexes, result.Current)
-	ic.detectModifiedIndexes(result, currentIndexes, desiredIndexes, result.Current, result.Desired)
+	ic.detectAddedIndexes(result, currentIndexes, desiredIndexes)
+	ic.detectDroppedIndexes(result, currentIndexes, desiredIndexes)
+	ic.detectModifiedIndexes(result, currentIndexes, desiredIndexes)
+	ic.detectOverlappingUniqueness(result, result.Desired)
 }
 
-func (ic *IndexComparator) buildIndexMap(db *schema.Database) map[string]*schema.Index {
-	m := make(map[string]*schema.Index)
+// indexSet holds indexes keyed by IndexKey, with the keys in the order the
+// indexes are declared so that changes come out in that order.
+type indexSet struct {
+	byKey map[string]*schema.Index
+	keys  []string
+}
+
+func newIndexSet() *indexSet {
+	return &indexSet{byKey: make(map[string]*schema.Index)}
+}
+
+// add keys idx. A later index with the same key replaces the earlier one
+// but keeps its position.
+func (s *indexSet) add(idx *schema.Index) {
+	key := IndexKey(idx.Schema, idx.Name)
+	if _, exists := s.byKey[key]; !exists {
+		s.keys = append(s.keys, key)
+	}
+
+	s.byKey[key] = idx
+}
+
+// buildIndexSet keys the standalone indexes of db by name. Indexes backing a
+// constraint are compared with their constraint and left out, so an index
+// and a constraint that share a name never match each other.
+func (ic *IndexComparator) buildIndexSet(db *schema.Database) *indexSet {
+	set := newIndexSet()
 
 	for i := range db.Tables {
-		for j := range db.Tables[i].Indexes {
-			idx := &db.Tables[i].Indexes[j]
-			key := IndexKey(idx.Schema, idx.Name)
-			m[key] = idx
+		table := &db.Tables[i]
+		backed := constraintIndexNames(table)
+
+		for j := range table.Indexes {
+			idx := &table.Indexes[j]
+			if isConstraintBackedIndex(idx, backed) {
+				continue
+			}
+
+			set.add(idx)
 		}
 	}
 
 	for i := range db.MaterializedViews {
 		for j := range db.MaterializedViews[i].Indexes {
-			idx := &db.MaterializedViews[i].Indexes[j]
-			key := IndexKey(idx.Schema, idx.Name)
-			m[key] = idx
+			set.add(&db.MaterializedViews[i].Indexes[j])
 		}
 	}
 
 	for i := range db.ContinuousAggregates {
 		for j := range db.ContinuousAggregates[i].Indexes {
-			idx := &db.ContinuousAggregates[i].Indexes[j]
-			key := IndexKey(idx.Schema, idx.Name)
-			m[key] = idx
+			set.add(&db.ContinuousAggregates[i].Indexes[j])
 		}
 	}
 
-	return m
+	return set
 }
 
-func (ic *IndexComparator) detectAddedIndexes(
-	result *DiffResult,
-	currentIndexes, desiredIndexes map[string]*schema.Index,
-	desiredDB *schema.Database,
-) {
-	for key, idx := range desiredIndexes {
-		if _, exists := currentIndexes[key]; !exists {
-			if ic.isConstraintBackedIndex(idx, desiredDB) {
-				continue
-			}
+func (ic *IndexComparator) detectAddedIndexes(result *DiffResult, current, desired *indexSet) {
+	for _, key := range desired.keys {
+		if _, exists := current.byKey[key]; !exists {
+			ic.compareIndex(result, key, nil, desired.byKey[key])
+		}
+	}
+}
+
+func (ic *IndexComparator) detectDroppedIndexes(result *DiffResult, current, desired *indexSet) {
+	for _, key := range current.keys {
+		if _, exists := desired.byKey[key]; !exists {
+			ic.compareIndex(result, key, current.byKey[key], nil)
+		}
+	}
+}
 
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeAddIndex,
-				Severity: SeveritySafe,
-				Description: fmt.Sprintf(
-					"Add %sindex: %s on %s(%s)",
-					indexTypeDescription(idx),
-					idx.Name,
-					idx.QualifiedTableName(),
-					idx.ColumnList(),
-				),
-				ObjectType: "index",
-				ObjectName: key,
-				Details:    map[string]any{"index": idx},
-				DependsOn:  []string{idx.QualifiedTableName()},
-			})
+func (ic *IndexComparator) detectModifiedIndexes(result *DiffResult, current, desired *indexSet) {
+	for _, key := range desired.keys {
+		if currentIdx, exists := current.byKey[key]; exists {
+			ic.compareIndex(result, key, currentIdx, desired.byKey[key])
 		}
 	}
 }
 
-func (ic *IndexComparator) detectDroppedIndexes(
+// compareIndex appends the change that turns current into desired. A nil
+// current adds the index and a nil desired drops it.
+func (ic *IndexComparator) compareIndex(
 	result *DiffResult,
-	currentIndexes, desiredIndexes map[string]*schema.Index,
-	currentDB *schema.Database,
+	key string,
+	current, desired *schema.Index,
 ) {
-	for key, idx := range currentIndexes {
-		if _, exists := desiredIndexes[key]; !exists {
-			if ic.isConstraintBackedIndex(idx, currentDB) {
-				continue
-			}
-
-			severity := SeverityPotentiallyBreaking
-			if idx.IsUnique {
-				severity = SeverityBreaking
-			}
+	switch {
+	case current == nil && desired == nil:
+		return
+	case current == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeAddIndex,
+			Severity: SeveritySafe,
+			Description: describeAdded(indexTypeDescription(desired)+"index",
+				fmt.Sprintf("%s on %s(%s)",
+					desired.Name, desired.QualifiedTableName(), desired.ColumnList())),
+			ObjectType: "index",
+			ObjectName: key,
+			Details:    map[string]any{"index": desired},
+			DependsOn:  []string{desired.QualifiedTableName()},
+		})
+
+		ic.addCommentChange(result, key, desired, "")
+	case desired == nil:
+		severity := SeverityPotentiallyBreaking
+		if current.IsUnique {
+			severity = SeverityBreaking
+		}
 
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeDropIndex,
-				Severity: severity,
-				Description: fmt.Sprintf(
-					"Drop %sindex: %s from %s",
-					indexTypeDescription(idx),
-					idx.Name,
-					idx.QualifiedTableName(),
-				),
-				ObjectType: "index",
-				ObjectName: key,
-				Details:    map[string]any{"index": idx},
-			})
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeDropIndex,
+			Severity: severity,
+			Description: describeDropped(indexTypeDescription(current)+"index",
+				current.Name+" on "+current.QualifiedTableName()),
+			ObjectType: "index",
+			ObjectName: key,
+			Details:    map[string]any{"index": current},
+		})
+	case !areIndexesEqual(current, desired):
+		severity := SeverityPotentiallyBreaking
+		if desired.IsUnique {
+			severity = SeverityBreaking
 		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeModifyIndex,
+			Severity: severity,
+			Description: describeReplaced("index",
+				desired.Name+" on "+desired.QualifiedTableName()),
+			ObjectType: "index",
+			ObjectName: key,
+			Details:    map[string]any{"current": current, "desired": desired},
+			DependsOn:  []string{desired.QualifiedTableName()},
+		})
+
+		// Recreating the index drops its comment, so a desired comment is
+		// set again even when it did not change.
+		ic.addCommentChange(result, key, desired, "")
+	case !ic.options.commentsEqual(current.Comment, desired.Comment):
+		ic.addCommentChange(result, key, desired, current.Comment)
 	}
 }
 
-func (ic *IndexComparator) detectModifiedIndexes(
+func (ic *IndexComparator) addCommentChange(
 	result *DiffResult,
-	currentIndexes, desiredIndexes map[string]*schema.Index,
-	currentDB, desiredDB *schema.Database,
+	key string,
+	idx *schema.Index,
+	oldComment string,
 ) {
-	for key, desiredIdx := range desiredIndexes {
-		currentIdx, exists := currentIndexes[key]
-		if !exists {
+	if ic.options.IgnoreComments || (idx.Comment == "" && oldComment == "") {
+		return
+	}
+
+	severity := SeveritySafe
+	if idx.Comment == "" {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyIndexComment,
+		Severity: severity,
+		Description: describeComment("index", idx.Name+" on "+idx.QualifiedTableName(),
+			oldComment, idx.Comment),
+		ObjectType: "index",
+		ObjectName: key,
+		Details: map[string]any{
+			"index":       idx,
+			"old_comment": oldComment,
+			"new_comment": idx.Comment,
+		},
+	})
+}
+
+// isConstraintBackedIndex reports whether idx is the index of a primary key,
+// unique or exclusion constraint, given the constraintIndexNames of its table.
+// A constraint index never has a predicate, so a partial index is always a
+// standalone index even when it shares a constraint's name.
+func isConstraintBackedIndex(idx *schema.Index, backed map[string]bool) bool {
+	if idx.IsPrimary {
+		return true
+	}
+
+	return !idx.IsPartial() && backed[idx.Name]
+}
+
+// constraintIndexNames returns the names an index backing one of the primary
+// key, unique or exclusion constraints of table can have: the constraint's
+// name and the name of the index it was declared to use. Collecting them once
+// keeps matching indexes to constraints linear on tables with many of both.
+func constraintIndexNames(table *schema.Table) map[string]bool {
+	names := make(map[string]bool)
+
+	for i := range table.Constraints {
+		constraint := &table.Constraints[i]
+		if constraint.Type != schema.ConstraintPrimaryKey &&
+			constraint.Type != schema.ConstraintUnique &&
+			constraint.Type != schema.ConstraintExclude {
 			continue
 		}
 
-		if ic.isConstraintBackedIndex(currentIdx, currentDB) ||
-			ic.isConstraintBackedIndex(desiredIdx, desiredDB) {
-			continue
+		names[constraint.Name] = true
+		if constraint.IndexName != "" {
+			names[constraint.IndexName] = true
 		}
+	}
 
-		if !areIndexesEqual(currentIdx, desiredIdx) {
-			severity := SeverityPotentiallyBreaking
-			if desiredIdx.IsUnique {
-				severity = SeverityBreaking
+	return names
+}
+
+// detectOverlappingUniqueness warns about a unique partial index whose columns
+// are already unique for every row through a UNIQUE constraint. The predicate
+// then has no effect, which is rarely intended.
+func (ic *IndexComparator) detectOverlappingUniqueness(result *DiffResult, db *schema.Database) {
+	for i := range db.Tables {
+		table := &db.Tables[i]
+
+		for j := range table.Indexes {
+			idx := &table.Indexes[j]
+			if !idx.IsUnique || !idx.IsPartial() {
+				continue
 			}
 
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeModifyIndex,
-				Severity: severity,
-				Description: fmt.Sprintf(
-					"Modify index: %s on %s",
-					desiredIdx.Name,
-					desiredIdx.QualifiedTableName(),
-				),
-				ObjectType: "index",
-				ObjectName: key,
-				Details:    map[string]any{"current": currentIdx, "desired": desiredIdx},
-				DependsOn:  []string{desiredIdx.QualifiedTableName()},
-			})
+			for k := range table.Constraints {
+				constraint := &table.Constraints[k]
+				if !constraint.IsUnique() {
+					continue
+				}
+
+				if !sameIndexColumnSet(idx.Columns, constraint.Columns) {
+					continue
+				}
+
+				result.addWarning(diag.Warning{
+					Code:     diag.CodeOverlappingUniqueness,
+					Severity: diag.SeverityWarning,
+					Message: fmt.Sprintf(
+						"Unique partial index %s on %s(%s) overlaps UNIQUE constraint %s, "+
+							"which already makes the columns unique for all rows",
+						idx.Name,
+						table.QualifiedName(),
+						idx.ColumnList(),
+						constraint.Name,
+					),
+					ObjectName: IndexKey(idx.Schema, idx.Name),
+				})
+			}
 		}
 	}
 }
 
-func (ic *IndexComparator) isConstraintBackedIndex(idx *schema.Index, db *schema.Database) bool {
-	if idx.IsPrimary {
-		return true
-	}
-
-	table := db.GetTable(idx.Schema, idx.TableName)
-	if table == nil {
+func sameIndexColumnSet(indexColumns, constraintColumns []string) bool {
+	if len(indexColumns) != len(constraintColumns) {
 		return false
 	}
 
-	for i := range table.Constraints {
-		constraint := &table.Constraints[i]
-		if constraint.Name == idx.Name &&
-			(constraint.Type == schema.ConstraintPrimaryKey ||
-				constraint.Type == schema.ConstraintUnique ||
-				constraint.Type == schema.ConstraintExclude) {
-			return true
-		}
+	normalized := make([]string, len(indexColumns))
+	for i, col := range indexColumns {
+		normalized[i] = normalizeIndexColumn(col)
 	}
 
-	return false
+	return equalStringSlicesSorted(normalized, constraintColumns)
 }
 
 func indexTypeDescription(idx *schema.Index) string {
@@ -312,7 +423,7 @@ func normalizeIndexColumn(col string) string {
 	}
 
 	expr = removeTypeCasts(expr)
-	expr = regexp.MustCompile(`\s+`).ReplaceAllString(expr, " ")
+	expr = whitespaceRunPattern.ReplaceAllString(expr, " ")
 	expr = strings.TrimSpace(expr)
 	expr = canonicalizeSortOrder(expr)
 
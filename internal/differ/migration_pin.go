@@ -0,0 +1,157 @@
+package differ
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+)
+
+// pinMigrations pulls everything a pinned change depends on into its
+// migration and returns order rearranged so the changes of each pinned
+// migration are consecutive, after every migration they depend on.
+//
+// A pinned change that depends on a change pinned to another migration is an
+// error naming both. A dependency shared by changes pinned to different
+// migrations joins the migration whose pinned change comes first.
+func (g *dependencyGraph) pinMigrations(order []int) ([]int, []diag.Warning, error) {
+	annotated := make(map[int]bool)
+	position := make(map[int]int, len(order))
+
+	for i, node := range order {
+		position[node] = i
+		annotated[node] = g.nodes[node].Migration != ""
+	}
+
+	var names []string
+
+	pulled := make(map[string][]int)
+
+	for _, node := range order {
+		if !annotated[node] {
+			continue
+		}
+
+		name := g.nodes[node].Migration
+		if _, seen := pulled[name]; !seen {
+			names = append(names, name)
+			pulled[name] = nil
+		}
+
+		visited := map[int]bool{node: true}
+		stack := slices.Sorted(maps.Keys(g.edges[node]))
+
+		for len(stack) > 0 {
+			dep := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if visited[dep] {
+				continue
+			}
+
+			visited[dep] = true
+			change := g.nodes[dep]
+
+			switch {
+			case annotated[dep] && change.Migration != name:
+				return nil, nil, fmt.Errorf(
+					"%s is pinned to migration %s but depends on %s, which is pinned to migration %s",
+					cycleNodeLabel(g.nodes[node]), name, cycleNodeLabel(change), change.Migration,
+				)
+			case change.Migration == "":
+				change.Migration = name
+				pulled[name] = append(pulled[name], dep)
+			}
+
+			stack = append(stack, slices.Sorted(maps.Keys(g.edges[dep]))...)
+		}
+	}
+
+	if len(names) == 0 {
+		return order, nil, nil
+	}
+
+	warnings := make([]diag.Warning, 0, len(names))
+
+	for _, name := range names {
+		nodes := pulled[name]
+		if len(nodes) == 0 {
+			continue
+		}
+
+		slices.SortFunc(nodes, func(a, b int) int { return position[a] - position[b] })
+
+		labels := make([]string, len(nodes))
+		for i, node := range nodes {
+			labels[i] = cycleNodeLabel(g.nodes[node])
+		}
+
+		warnings = append(warnings, diag.Warning{
+			Code:     diag.CodePinnedDependency,
+			Severity: diag.SeverityWarning,
+			Message: fmt.Sprintf("migration %s also includes %s, which its pinned changes depend on",
+				name, strings.Join(labels, ", ")),
+		})
+	}
+
+	return g.groupPinnedMigrations(order), warnings, nil
+}
+
+// groupPinnedMigrations moves the changes of each pinned migration to where
+// its first change is, preceded by the pinned migrations it depends on. The
+// changes a pinned migration depends on are all pinned, so nothing it needs
+// is moved after it.
+func (g *dependencyGraph) groupPinnedMigrations(order []int) []int {
+	members := make(map[string][]int)
+	requires := make(map[string]map[string]bool)
+
+	for _, node := range order {
+		name := g.nodes[node].Migration
+		if name == "" {
+			continue
+		}
+
+		members[name] = append(members[name], node)
+
+		for dep := range g.edges[node] {
+			if other := g.nodes[dep].Migration; other != "" && other != name {
+				if requires[name] == nil {
+					requires[name] = make(map[string]bool)
+				}
+
+				requires[name][other] = true
+			}
+		}
+	}
+
+	grouped := make([]int, 0, len(order))
+	emitted := make(map[string]bool)
+
+	var emit func(name string)
+
+	emit = func(name string) {
+		if emitted[name] {
+			return
+		}
+
+		emitted[name] = true
+
+		for _, other := range slices.Sorted(maps.Keys(requires[name])) {
+			emit(other)
+		}
+
+		grouped = append(grouped, members[name]...)
+	}
+
+	for _, node := range order {
+		if name := g.nodes[node].Migration; name != "" {
+			emit(name)
+		} else {
+			grouped = append(grouped, node)
+		}
+	}
+
+	return grouped
+}
@@ -0,0 +1,110 @@
+package differ
+
+import "github.com/accented-ai/pgtofu/internal/schema"
+
+// The single-object entry points below run exactly the comparison Compare
+// runs for one pair of objects, for callers such as editor tooling that
+// have a single definition in hand rather than two whole databases. A nil
+// current reports the object as added and a nil desired as dropped.
+//
+// The changes are returned in the order they are produced, with Details
+// populated but without dependency resolution, and without the whole-schema
+// passes that need every object at once: foreign key cycle breaking,
+// ensure-only filtering, table recreation and view recreation.
+
+// CompareTable returns the changes that turn current into desired, covering
+// columns, constraints, comments, partitions and partition-local objects.
+// Indexes are separate objects; compare them with CompareIndex.
+func (d *Differ) CompareTable(current, desired *schema.Table) []Change {
+	result := &DiffResult{}
+
+	switch {
+	case desired != nil:
+		d.tableComp.compareTable(result, TableKey(desired.Schema, desired.Name), current, desired)
+	case current != nil:
+		d.tableComp.compareTable(result, TableKey(current.Schema, current.Name), current, nil)
+	}
+
+	return result.Changes
+}
+
+// CompareIndex returns the change that turns current into desired. Indexes
+// backing a primary key, unique or exclusion constraint are compared with
+// their constraint by CompareTable instead.
+func (d *Differ) CompareIndex(current, desired *schema.Index) []Change {
+	result := &DiffResult{}
+
+	switch {
+	case desired != nil:
+		d.indexComp.compareIndex(result, IndexKey(desired.Schema, desired.Name), current, desired)
+	case current != nil:
+		d.indexComp.compareIndex(result, IndexKey(current.Schema, current.Name), current, nil)
+	}
+
+	return result.Changes
+}
+
+// CompareView returns the changes that turn current into desired.
+func (d *Differ) CompareView(current, desired *schema.View) []Change {
+	result := &DiffResult{}
+
+	switch {
+	case desired != nil:
+		d.compareView(result, ViewKey(desired.Schema, desired.Name), current, desired)
+	case current != nil:
+		d.compareView(result, ViewKey(current.Schema, current.Name), current, nil)
+	}
+
+	return result.Changes
+}
+
+// CompareMaterializedView returns the changes that turn current into desired.
+func (d *Differ) CompareMaterializedView(current, desired *schema.MaterializedView) []Change {
+	result := &DiffResult{}
+
+	switch {
+	case desired != nil:
+		d.compareMaterializedView(result, ViewKey(desired.Schema, desired.Name), current, desired)
+	case current != nil:
+		d.compareMaterializedView(result, ViewKey(current.Schema, current.Name), current, nil)
+	}
+
+	return result.Changes
+}
+
+// CompareFunction returns the changes that turn current into desired.
+// triggers are the current triggers: changing a function one of them calls
+// is breaking, as in Compare.
+func (d *Differ) CompareFunction(
+	current, desired *schema.Function,
+	triggers []schema.Trigger,
+) []Change {
+	result := &DiffResult{}
+
+	switch {
+	case desired != nil:
+		key := RoutineKey(desired)
+		d.functionComp.compareFunction(result, key, current, desired, triggers)
+	case current != nil:
+		key := RoutineKey(current)
+		d.functionComp.compareFunction(result, key, current, nil, triggers)
+	}
+
+	return result.Changes
+}
+
+// CompareTrigger returns the change that turns current into desired. Without
+// the surrounding databases, a dropped trigger is never treated as inherited
+// from a partitioned parent.
+func (d *Differ) CompareTrigger(current, desired *schema.Trigger) []Change {
+	result := &DiffResult{Current: &schema.Database{}, Desired: &schema.Database{}}
+
+	switch {
+	case desired != nil:
+		d.triggerComp.compareTrigger(result, triggerKey(desired), current, desired)
+	case current != nil:
+		d.triggerComp.compareTrigger(result, triggerKey(current), current, nil)
+	}
+
+	return result.Changes
+}
@@ -0,0 +1,31 @@
+package differ
+
+import "iter"
+
+// mappedInOrder yields the entries of m in the order their objects appear in
+// objects. Comparators key objects in maps for constant-time matching, but
+// ranging over a map would emit changes in a different order on every run.
+//
+// An object is skipped when m holds another object under its key: two objects
+// that share a key are matched through the last one, as the map keeps it, and
+// an object a comparator has already taken out of the map is not yielded.
+func mappedInOrder[T any](
+	objects []T,
+	m map[string]*T,
+	key func(*T) string,
+) iter.Seq2[string, *T] {
+	return func(yield func(string, *T) bool) {
+		for i := range objects {
+			object := &objects[i]
+
+			k := key(object)
+			if m[k] != object {
+				continue
+			}
+
+			if !yield(k, object) {
+				return
+			}
+		}
+	}
+}
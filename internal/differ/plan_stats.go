@@ -0,0 +1,200 @@
+package differ
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// PlanStats breaks a plan down by what it changes, for tooling that decides
+// whether a plan is small enough to review.
+type PlanStats struct {
+	TotalChanges int
+	ByType       map[ChangeType]int
+	BySeverity   map[ChangeSeverity]int
+	BySchema     map[string]int
+	// ObjectsTouched counts the distinct objects the changes apply to. Column
+	// and constraint changes count as their table.
+	ObjectsTouched int
+	// TablesTouched counts the distinct tables that are added, dropped or
+	// altered, including through their columns, constraints, indexes and
+	// partitions.
+	TablesTouched int
+	// DestructiveChanges counts the BREAKING and DATA_MIGRATION_REQUIRED
+	// changes.
+	DestructiveChanges int
+	// UnsafeChanges counts the changes whose up statement the generator marks
+	// unsafe. It is derived from the changes alone, so statements the
+	// generator only marks unsafe because of the table they run on, such as
+	// an ALTER of a compressed hypertable, are not counted.
+	UnsafeChanges int
+}
+
+// PlanStats computes the breakdown of the changes in a single pass. The
+// DiffResult.Stats field keeps the older per-object summary.
+func (dr *DiffResult) PlanStats() PlanStats {
+	stats := PlanStats{
+		TotalChanges: len(dr.Changes),
+		ByType:       make(map[ChangeType]int),
+		BySeverity:   make(map[ChangeSeverity]int),
+		BySchema:     make(map[string]int),
+	}
+
+	objects := make(map[string]bool)
+	tables := make(map[string]bool)
+
+	for i := range dr.Changes {
+		change := &dr.Changes[i]
+
+		stats.ByType[change.Type]++
+		stats.BySeverity[change.Severity]++
+		stats.BySchema[strings.ToLower(extractSchemaFromChange(change))]++
+
+		if change.ObjectName != "" {
+			objects[strings.ToLower(change.ObjectName)] = true
+		}
+
+		if table := changedTableKey(change); table != "" {
+			tables[table] = true
+		}
+
+		if change.Severity == SeverityBreaking || change.Severity == SeverityDataMigrationRequired {
+			stats.DestructiveChanges++
+		}
+
+		if marksUnsafe(change) {
+			stats.UnsafeChanges++
+		}
+	}
+
+	stats.ObjectsTouched = len(objects)
+	stats.TablesTouched = len(tables)
+
+	return stats
+}
+
+// changedTableKey returns the key of the table a change adds, drops or
+// alters, or an empty string when it changes no table.
+func changedTableKey(change *Change) string {
+	switch change.Type {
+	case ChangeTypeAddTable, ChangeTypeDropTable, ChangeTypeRecreateTable,
+		ChangeTypeModifyTableComment, ChangeTypeAddColumn, ChangeTypeDropColumn,
+		ChangeTypeModifyColumnType, ChangeTypeModifyColumnNullability,
+		ChangeTypeModifyColumnDefault, ChangeTypeModifyColumnComment,
+		ChangeTypeAddConstraint, ChangeTypeDropConstraint, ChangeTypeModifyConstraint,
+		ChangeTypeModifyConstraintComment, ChangeTypeAddPolicy, ChangeTypeDropPolicy,
+		ChangeTypeModifyPolicy, ChangeTypeModifyTableRowSecurity:
+		return strings.ToLower(change.ObjectName)
+	case ChangeTypeAddIndex, ChangeTypeDropIndex, ChangeTypeModifyIndexComment:
+		if idx, ok := change.Details["index"].(*schema.Index); ok {
+			return TableKey(idx.Schema, idx.TableName)
+		}
+	case ChangeTypeModifyIndex:
+		if idx, ok := change.Details["desired"].(*schema.Index); ok {
+			return TableKey(idx.Schema, idx.TableName)
+		}
+	case ChangeTypeAddPartition, ChangeTypeDropPartition:
+		if table, ok := change.Details["table"].(string); ok {
+			return strings.ToLower(table)
+		}
+	}
+
+	return ""
+}
+
+// marksUnsafe reports whether the generator marks the up statement of the
+// change unsafe: drops, rewrites and changes that may fail or block on
+// existing rows.
+func marksUnsafe(change *Change) bool {
+	switch change.Type {
+	case ChangeTypeDropSchema, ChangeTypeDropExtension, ChangeTypeModifyExtension,
+		ChangeTypeDropCustomType, ChangeTypeDropSequence, ChangeTypeDropTable,
+		ChangeTypeRecreateTable, ChangeTypeDropColumn, ChangeTypeDropConstraint,
+		ChangeTypeDropPartition, ChangeTypeDropView,
+		ChangeTypeDropMaterializedView, ChangeTypeModifyMaterializedView,
+		ChangeTypeDropFunction, ChangeTypeDropTrigger, ChangeTypeModifyTrigger,
+		ChangeTypeDropPolicy, ChangeTypeModifyPolicy, ChangeTypeModifyTableRowSecurity,
+		ChangeTypeRevokeGrant,
+		ChangeTypeDropHypertable, ChangeTypeDropDimension, ChangeTypeModifyDimension,
+		ChangeTypeAddRetentionPolicy, ChangeTypeDropContinuousAggregate,
+		ChangeTypeModifyContinuousAggregate:
+		return true
+	case ChangeTypeModifyConstraint:
+		renameOnly, _ := change.Details[DetailKeyRenameOnly].(bool)
+		return !renameOnly
+	case ChangeTypeModifyColumnType:
+		return change.Details[DetailKeyPrecisionChange] != PrecisionChangeWiden
+	case ChangeTypeModifyColumnNullability:
+		nullable, _ := change.Details["new_nullable"].(bool)
+		return !nullable
+	case ChangeTypeModifyColumnGeneration:
+		col, ok := change.Details["new_column"].(*schema.Column)
+		return ok && col.IsGenerated
+	case ChangeTypeAddColumn:
+		col, ok := change.Details["column"].(*schema.Column)
+		return ok && !col.IsNullable && col.Default == "" && col.Identity == nil
+	case ChangeTypeAddConstraint:
+		constraint, ok := change.Details["constraint"].(*schema.Constraint)
+		return ok && constraint.IsForeignKey()
+	default:
+		return false
+	}
+}
+
+// PlanSizeLimits are the sizes above which a plan is considered too big to
+// review in one go. A zero field disables that limit. Crossing a limit only
+// adds an advisory; the plan is produced as usual.
+type PlanSizeLimits struct {
+	// MaxChanges is the most changes a plan may have.
+	MaxChanges int
+	// MaxDestructiveChanges is the most BREAKING and DATA_MIGRATION_REQUIRED
+	// changes a plan may have.
+	MaxDestructiveChanges int
+	// MaxTablesTouched is the most distinct tables a plan may change.
+	MaxTablesTouched int
+}
+
+// PlanSizeAdvisories returns a PLAN_TOO_LARGE warning for every limit the
+// stats exceed. A plan exactly at a limit is within it. A nil limits returns
+// none.
+func PlanSizeAdvisories(stats PlanStats, limits *PlanSizeLimits) []diag.Warning {
+	if limits == nil {
+		return nil
+	}
+
+	var advisories []diag.Warning
+
+	check := func(count, limit int, format string) {
+		if limit <= 0 || count <= limit {
+			return
+		}
+
+		advisories = append(advisories, diag.Warning{
+			Code:     diag.CodePlanTooLarge,
+			Severity: diag.SeverityWarning,
+			Message: fmt.Sprintf(format, count) +
+				fmt.Sprintf(" (limit %d); consider splitting desired-state changes", limit),
+		})
+	}
+
+	check(stats.TotalChanges, limits.MaxChanges, "plan has %d changes")
+	check(stats.DestructiveChanges, limits.MaxDestructiveChanges,
+		"plan has %d destructive changes")
+	check(stats.TablesTouched, limits.MaxTablesTouched, "plan touches %d tables")
+
+	return advisories
+}
+
+// adviseOnPlanSize adds the advisories for the PlanSize limits to the
+// result's diagnostics.
+func (d *Differ) adviseOnPlanSize(result *DiffResult) {
+	if d.options.PlanSize == nil {
+		return
+	}
+
+	for _, advisory := range PlanSizeAdvisories(result.PlanStats(), d.options.PlanSize) {
+		result.addWarning(advisory)
+	}
+}
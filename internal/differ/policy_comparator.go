@@ -0,0 +1,160 @@
+package differ
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+type PolicyComparator struct {
+	options *Options
+}
+
+func NewPolicyComparator(opts *Options) *PolicyComparator {
+	return &PolicyComparator{options: opts}
+}
+
+// Compare appends the changes that turn the policies and row-level security
+// of current into those of desired. current is nil when the table is being
+// added, in which case only what desired declares is created.
+func (pc *PolicyComparator) Compare(
+	result *DiffResult,
+	tableKey string,
+	current, desired *schema.Table,
+) {
+	if current == nil {
+		current = &schema.Table{Schema: desired.Schema, Name: desired.Name}
+	}
+
+	for i := range desired.Policies {
+		policy := &desired.Policies[i]
+
+		currentPolicy := current.GetPolicy(policy.Name)
+		switch {
+		case currentPolicy == nil:
+			pc.addPolicyChange(result, tableKey, desired, policy)
+		case !arePoliciesEqual(currentPolicy, policy):
+			result.Changes = append(result.Changes, Change{
+				Type:     ChangeTypeModifyPolicy,
+				Severity: SeverityPotentiallyBreaking,
+				Description: describeReplaced("policy",
+					policy.Name+" on "+desired.QualifiedName()),
+				ObjectType: "policy",
+				ObjectName: tableKey,
+				Details: map[string]any{
+					"table":   desired.QualifiedName(),
+					"current": currentPolicy,
+					"desired": policy,
+				},
+				DependsOn: []string{tableKey},
+			})
+		}
+	}
+
+	for i := range current.Policies {
+		policy := &current.Policies[i]
+		if desired.GetPolicy(policy.Name) != nil {
+			continue
+		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeDropPolicy,
+			Severity: SeverityPotentiallyBreaking,
+			Description: describeDropped("policy",
+				policy.Name+" on "+current.QualifiedName()),
+			ObjectType: "policy",
+			ObjectName: tableKey,
+			Details:    map[string]any{"table": current.QualifiedName(), "policy": policy},
+		})
+	}
+
+	if current.RowLevelSecurityEnabled != desired.RowLevelSecurityEnabled {
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeModifyTableRowSecurity,
+			Severity: SeverityPotentiallyBreaking,
+			Description: describeValue("Row level security", desired.QualifiedName(),
+				rowSecurityState(current.RowLevelSecurityEnabled),
+				rowSecurityState(desired.RowLevelSecurityEnabled)),
+			ObjectType: "table",
+			ObjectName: tableKey,
+			Details: map[string]any{
+				"table":   desired.QualifiedName(),
+				"enabled": desired.RowLevelSecurityEnabled,
+			},
+			DependsOn: []string{tableKey},
+		})
+	}
+}
+
+// addPolicyChange appends the creation of policy. A restrictive policy hides
+// rows the existing policies let through, so only a permissive one is safe.
+func (pc *PolicyComparator) addPolicyChange(
+	result *DiffResult,
+	tableKey string,
+	table *schema.Table,
+	policy *schema.Policy,
+) {
+	severity := SeveritySafe
+	if policy.Restrictive {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:        ChangeTypeAddPolicy,
+		Severity:    severity,
+		Description: describeAdded("policy", policy.Name+" on "+table.QualifiedName()),
+		ObjectType:  "policy",
+		ObjectName:  tableKey,
+		Details:     map[string]any{"table": table.QualifiedName(), "policy": policy},
+		DependsOn:   []string{tableKey},
+	})
+}
+
+func arePoliciesEqual(p1, p2 *schema.Policy) bool {
+	return p1.CommandName() == p2.CommandName() &&
+		p1.Restrictive == p2.Restrictive &&
+		slices.Equal(p1.RoleNames(), p2.RoleNames()) &&
+		normalizeExpression(p1.Using) == normalizeExpression(p2.Using) &&
+		normalizeExpression(p1.WithCheck) == normalizeExpression(p2.WithCheck)
+}
+
+func rowSecurityState(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+
+	return "disabled"
+}
+
+// policyChangeName returns the name of the policy a policy change applies to.
+func policyChangeName(change *Change) string {
+	for _, key := range []string{"policy", "desired"} {
+		if policy, ok := change.Details[key].(*schema.Policy); ok {
+			return strings.ToLower(policy.Name)
+		}
+	}
+
+	return ""
+}
+
+// policyUsesColumn reports whether the USING or WITH CHECK expression of the
+// policy a policy change applies to mentions the column.
+func policyUsesColumn(change *Change, tableName, columnName string) bool {
+	table, _ := change.Details["table"].(string)
+	if !strings.EqualFold(table, tableName) {
+		return false
+	}
+
+	column := strings.ToLower(columnName)
+
+	for _, key := range []string{"policy", "current", "desired"} {
+		if policy, ok := change.Details[key].(*schema.Policy); ok &&
+			(expressionUsesColumn(policy.Using, column) ||
+				expressionUsesColumn(policy.WithCheck, column)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package differ
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// DetailKeySwapIndex holds the temporary name a MODIFY_INDEX change builds
+// its new definition under. The new index is renamed into place after the old
+// one is dropped, so the table is never without the index.
+const DetailKeySwapIndex = "swap_index"
+
+// swapIndexSuffix ends the name of an index's replacement.
+const swapIndexSuffix = "pgtofu_swap"
+
+// detectConcurrentRefreshHazards warns about changes that leave a
+// materialized view without a unique index for a while. REFRESH MATERIALIZED
+// VIEW CONCURRENTLY needs one and fails in that window. With
+// SwapMaterializedViewIndexes, a rebuilt unique index is swapped in instead
+// and is not a hazard.
+func (d *Differ) detectConcurrentRefreshHazards(result *DiffResult) {
+	uniqueIndexes := make(map[string][]string)
+
+	for i := range result.Current.MaterializedViews {
+		view := &result.Current.MaterializedViews[i]
+
+		for j := range view.Indexes {
+			if view.Indexes[j].IsUnique {
+				key := ViewKey(view.Schema, view.Name)
+				uniqueIndexes[key] = append(uniqueIndexes[key], view.Indexes[j].Name)
+			}
+		}
+	}
+
+	if len(uniqueIndexes) == 0 {
+		return
+	}
+
+	recreated := make(map[string]bool)
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+		if change.ObjectType != "materialized_view" || !dropsMaterializedView(change) {
+			continue
+		}
+
+		recreated[change.ObjectName] = true
+
+		if indexes := uniqueIndexes[change.ObjectName]; len(indexes) > 0 {
+			sort.Strings(indexes)
+			result.addWarning(concurrentRefreshHazard(change, fmt.Sprintf(
+				"materialized view %s is dropped and recreated, so REFRESH MATERIALIZED VIEW "+
+					"CONCURRENTLY fails until it and its unique index %s exist again",
+				change.ObjectName, strings.Join(indexes, ", "),
+			)))
+		}
+	}
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+
+		current := materializedViewUniqueIndex(result.Current, change)
+		if current == nil || recreated[ViewKey(current.Schema, current.TableName)] {
+			continue
+		}
+
+		switch change.Type {
+		case ChangeTypeDropIndex:
+			result.addWarning(concurrentRefreshHazard(change, fmt.Sprintf(
+				"unique index %s on materialized view %s is dropped, so REFRESH MATERIALIZED "+
+					"VIEW CONCURRENTLY fails unless another unique index is created",
+				current.Name, current.QualifiedTableName(),
+			)))
+		case ChangeTypeModifyIndex:
+			desired, _ := change.Details["desired"].(*schema.Index)
+			swapName := schema.SynthesizedIdentifier(swapIndexSuffix, current.Name)
+
+			// Swapping in an index that is not unique keeps no unique index.
+			if d.options.SwapMaterializedViewIndexes && desired != nil && desired.IsUnique {
+				change.Details[DetailKeySwapIndex] = swapName
+				continue
+			}
+
+			result.addWarning(concurrentRefreshHazard(change, fmt.Sprintf(
+				"unique index %s on materialized view %s is dropped and recreated, so "+
+					"REFRESH MATERIALIZED VIEW CONCURRENTLY fails in between; swapping in a new "+
+					"index avoids the window",
+				current.Name, current.QualifiedTableName(),
+			)))
+		}
+	}
+}
+
+// dropsMaterializedView reports whether a materialized view change drops the
+// view, either for good or to create it again with a new definition.
+func dropsMaterializedView(change *Change) bool {
+	switch change.Type {
+	case ChangeTypeDropMaterializedView:
+		return true
+	case ChangeTypeModifyMaterializedView:
+		_, recreates := change.Details["current"]
+		return recreates
+	default:
+		return false
+	}
+}
+
+// materializedViewUniqueIndex returns the current definition of the index an
+// index change drops or rebuilds when it is a unique index of a materialized
+// view.
+func materializedViewUniqueIndex(db *schema.Database, change *Change) *schema.Index {
+	var index *schema.Index
+
+	switch change.Type {
+	case ChangeTypeDropIndex:
+		index, _ = change.Details["index"].(*schema.Index)
+	case ChangeTypeModifyIndex:
+		index, _ = change.Details["current"].(*schema.Index)
+	}
+
+	if index == nil || !index.IsUnique ||
+		db.GetMaterializedView(index.Schema, index.TableName) == nil {
+		return nil
+	}
+
+	return index
+}
+
+func concurrentRefreshHazard(change *Change, message string) diag.Warning {
+	return diag.Warning{
+		Code:       diag.CodeMatviewConcurrentRefreshHazard,
+		Severity:   diag.SeverityWarning,
+		Message:    message,
+		ObjectName: change.ObjectName,
+		ChangeType: string(change.Type),
+	}
+}
@@ -0,0 +1,45 @@
+package differ
+
+import "github.com/accented-ai/pgtofu/internal/diag"
+
+// noteRemoteAccess notes the changes to views and materialized views that
+// read from another server through dblink or postgres_fdw helpers. Their
+// remote queries are opaque, so pgtofu orders them by their local references
+// only and cannot tell whether the remote side still matches.
+func (d *Differ) noteRemoteAccess(result *DiffResult) {
+	remote := make(map[string]bool)
+
+	for i := range result.Desired.Views {
+		view := &result.Desired.Views[i]
+		if view.UsesRemoteAccess() {
+			remote["view\x00"+ViewKey(view.Schema, view.Name)] = true
+		}
+	}
+
+	for i := range result.Desired.MaterializedViews {
+		view := &result.Desired.MaterializedViews[i]
+		if view.UsesRemoteAccess() {
+			remote["materialized_view\x00"+ViewKey(view.Schema, view.Name)] = true
+		}
+	}
+
+	if len(remote) == 0 {
+		return
+	}
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+		if !remote[change.ObjectType+"\x00"+change.ObjectName] {
+			continue
+		}
+
+		result.addWarning(diag.Warning{
+			Code:     diag.CodeRemoteAccess,
+			Severity: diag.SeverityInfo,
+			Message: cycleNodeLabel(change) + " reads from another server through dblink or " +
+				"postgres_fdw; pgtofu cannot check the objects its remote queries use",
+			ObjectName: change.ObjectName,
+			ChangeType: string(change.Type),
+		})
+	}
+}
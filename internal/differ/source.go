@@ -0,0 +1,191 @@
+package differ
+
+import (
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// objectSources maps each object of a database, keyed by change object type
+// and differ key, to its source location. Objects declared without a
+// location are present with a nil value.
+type objectSources struct {
+	locations map[string]*schema.SourceLocation
+	// migrations maps each pinned object to the migration its statement is
+	// annotated with. Columns, indexes and policies of a pinned table are pinned
+	// with it unless their own statement names a migration.
+	migrations   map[string]string
+	hasLocations bool
+}
+
+func collectSources(db *schema.Database) *objectSources {
+	sources := &objectSources{
+		locations:  make(map[string]*schema.SourceLocation),
+		migrations: make(map[string]string),
+	}
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		tableKey := TableKey(table.Schema, table.Name)
+		sources.add("table", tableKey, table.Source)
+
+		for j := range table.Columns {
+			column := &table.Columns[j]
+			key := columnSourceKey(tableKey, column.Name)
+			sources.add("column", key, column.Source)
+			sources.inheritMigration("column", key, table.Source)
+		}
+
+		sources.addIndexes(table.Indexes)
+
+		for j := range table.Indexes {
+			index := &table.Indexes[j]
+			sources.inheritMigration("index", IndexKey(index.Schema, index.Name), table.Source)
+		}
+
+		for j := range table.Policies {
+			policy := &table.Policies[j]
+			key := columnSourceKey(tableKey, policy.Name)
+			sources.add("policy", key, policy.Source)
+			sources.inheritMigration("policy", key, table.Source)
+		}
+	}
+
+	for i := range db.Views {
+		view := &db.Views[i]
+		sources.add("view", ViewKey(view.Schema, view.Name), view.Source)
+	}
+
+	for i := range db.MaterializedViews {
+		view := &db.MaterializedViews[i]
+		sources.add("materialized_view", ViewKey(view.Schema, view.Name), view.Source)
+		sources.addIndexes(view.Indexes)
+	}
+
+	for i := range db.ContinuousAggregates {
+		sources.addIndexes(db.ContinuousAggregates[i].Indexes)
+	}
+
+	for i := range db.Functions {
+		fn := &db.Functions[i]
+		sources.add("function", RoutineKey(fn), fn.Source)
+	}
+
+	for i := range db.Triggers {
+		sources.add("trigger", triggerKey(&db.Triggers[i]), db.Triggers[i].Source)
+	}
+
+	return sources
+}
+
+// add records where an object is declared. An annotated object parsed from
+// SQL that did not come from a file only has its migration recorded.
+func (s *objectSources) add(objectType, key string, location *schema.SourceLocation) {
+	if location != nil && location.Migration != "" {
+		s.migrations[objectType+":"+key] = location.Migration
+	}
+
+	if location != nil && location.File == "" {
+		location = nil
+	}
+
+	s.locations[objectType+":"+key] = location
+	s.hasLocations = s.hasLocations || location != nil
+}
+
+// inheritMigration pins an object that is not pinned itself to the migration
+// of the object declaring it.
+func (s *objectSources) inheritMigration(
+	objectType, key string,
+	parent *schema.SourceLocation,
+) {
+	if parent == nil || parent.Migration == "" {
+		return
+	}
+
+	if _, pinned := s.migrations[objectType+":"+key]; !pinned {
+		s.migrations[objectType+":"+key] = parent.Migration
+	}
+}
+
+func (s *objectSources) addIndexes(indexes []schema.Index) {
+	for i := range indexes {
+		s.add("index", IndexKey(indexes[i].Schema, indexes[i].Name), indexes[i].Source)
+	}
+}
+
+// annotateSources sets the Source of each change to where its object is
+// declared in the desired state, and its Migration to the migration the
+// desired declaration is pinned to. Objects only present in the current state
+// point at their current declaration, or are just marked removed when the
+// current state did not come from files.
+func annotateSources(result *DiffResult) {
+	desired := collectSources(result.Desired)
+	current := collectSources(result.Current)
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+
+		key := changeSourceKey(change)
+		if key == "" {
+			continue
+		}
+
+		change.Migration = desired.migrations[key]
+
+		if location, ok := desired.locations[key]; ok {
+			if location != nil {
+				change.Source = &ChangeSource{Location: *location}
+			}
+
+			continue
+		}
+
+		switch location := current.locations[key]; {
+		case location != nil:
+			change.Source = &ChangeSource{Location: *location, Removed: true}
+		case desired.hasLocations:
+			change.Source = &ChangeSource{Removed: true}
+		}
+	}
+}
+
+// changeSourceKey returns the objectSources key of the object a change
+// applies to. Constraint changes resolve to their table, since constraints
+// are declared inside it. Policies, like columns, are keyed within their
+// table.
+func changeSourceKey(change *Change) string {
+	switch change.ObjectType {
+	case "table", "constraint":
+		return "table:" + change.ObjectName
+	case "column":
+		if name := changeColumnName(change.Details); name != "" {
+			return "column:" + columnSourceKey(change.ObjectName, name)
+		}
+
+		return "table:" + change.ObjectName
+	case "policy":
+		return "policy:" + columnSourceKey(change.ObjectName, policyChangeName(change))
+	case "index", "view", "materialized_view", "function", "trigger":
+		return change.ObjectType + ":" + change.ObjectName
+	default:
+		return ""
+	}
+}
+
+func changeColumnName(details map[string]any) string {
+	switch column := details["column"].(type) {
+	case *schema.Column:
+		return column.Name
+	case schema.Column:
+		return column.Name
+	}
+
+	name, _ := details["column_name"].(string)
+
+	return name
+}
+
+func columnSourceKey(tableKey, column string) string {
+	return tableKey + "." + strings.ToLower(column)
+}
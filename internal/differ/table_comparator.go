@@ -2,7 +2,11 @@ package differ
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -10,6 +14,8 @@ type TableComparator struct {
 	options        *Options
 	columnComp     *ColumnComparator
 	constraintComp *ConstraintComparator
+	indexComp      *IndexComparator
+	policyComp     *PolicyComparator
 }
 
 func NewTableComparator(opts *Options) *TableComparator {
@@ -17,46 +23,42 @@ func NewTableComparator(opts *Options) *TableComparator {
 		options:        opts,
 		columnComp:     NewColumnComparator(opts),
 		constraintComp: NewConstraintComparator(opts),
+		indexComp:      NewIndexComparator(opts),
+		policyComp:     NewPolicyComparator(opts),
 	}
 }
 
 func (tc *TableComparator) Compare(result *DiffResult) {
-	currentMap := tc.buildMap(result.Current.Tables)
-	desiredMap := tc.buildMap(result.Desired.Tables)
+	currentMap := buildTableMap(result.Current.Tables)
+	desiredMap := buildTableMap(result.Desired.Tables)
 
 	tc.detectAddedTables(result, currentMap, desiredMap)
 	tc.detectDroppedTables(result, currentMap, desiredMap)
 	tc.detectModifiedTables(result, currentMap, desiredMap)
 }
 
-func (tc *TableComparator) buildMap(tables []schema.Table) map[string]*schema.Table {
+// buildTableMap keys tables by TableKey, for the passes that look tables up
+// by name instead of scanning the slice for each one.
+func buildTableMap(tables []schema.Table) map[string]*schema.Table {
 	m := make(map[string]*schema.Table, len(tables))
 	for i := range tables {
-		key := TableKey(tables[i].Schema, tables[i].Name)
-		m[key] = &tables[i]
+		m[tableMapKey(&tables[i])] = &tables[i]
 	}
 
 	return m
 }
 
+func tableMapKey(table *schema.Table) string {
+	return TableKey(table.Schema, table.Name)
+}
+
 func (tc *TableComparator) detectAddedTables(
 	result *DiffResult,
 	currentMap, desiredMap map[string]*schema.Table,
 ) {
-	for key, table := range desiredMap {
+	for key, table := range mappedInOrder(result.Desired.Tables, desiredMap, tableMapKey) {
 		if _, exists := currentMap[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeAddTable,
-				Severity:    SeveritySafe,
-				Description: "Add table: " + table.QualifiedName(),
-				ObjectType:  "table",
-				ObjectName:  key,
-				Details:     map[string]any{"table": table},
-				DependsOn:   getTableDependencies(table),
-			})
-
-			tc.addTableCommentChange(result, key, table, "")
-			tc.addColumnCommentChanges(result, key, table)
+			tc.compareTable(result, key, nil, table)
 		}
 	}
 }
@@ -65,34 +67,88 @@ func (tc *TableComparator) detectDroppedTables(
 	result *DiffResult,
 	currentMap, desiredMap map[string]*schema.Table,
 ) {
-	for key, table := range currentMap {
+	for key, table := range mappedInOrder(result.Current.Tables, currentMap, tableMapKey) {
 		if _, exists := desiredMap[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeDropTable,
-				Severity:    SeverityBreaking,
-				Description: "Drop table: " + table.QualifiedName(),
-				ObjectType:  "table",
-				ObjectName:  key,
-				Details:     map[string]any{"table": table},
-			})
+			tc.compareTable(result, key, table, nil)
 		}
 	}
 }
 
-func (tc *TableComparator) detectModifiedTables(
+// compareTable appends the changes that turn current into desired. A nil
+// current adds the table and a nil desired drops it.
+func (tc *TableComparator) compareTable(
 	result *DiffResult,
-	currentMap, desiredMap map[string]*schema.Table,
+	key string,
+	current, desired *schema.Table,
 ) {
-	for key, desired := range desiredMap {
-		current, exists := currentMap[key]
-		if !exists {
-			continue
-		}
-
+	switch {
+	case current == nil && desired == nil:
+		return
+	case current == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeAddTable,
+			Severity:    SeveritySafe,
+			Description: describeAdded("table", desired.QualifiedName()),
+			ObjectType:  "table",
+			ObjectName:  key,
+			Details:     map[string]any{"table": desired},
+			DependsOn:   getTableDependencies(desired),
+		})
+
+		tc.addTableCommentChange(result, key, desired, "")
+		tc.addColumnCommentChanges(result, key, desired)
+		tc.constraintComp.addCommentChanges(result, key, desired)
+		tc.comparePartitionObjects(result, nil, desired)
+		tc.policyComp.Compare(result, key, nil, desired)
+	case desired == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeDropTable,
+			Severity:    SeverityBreaking,
+			Description: describeDropped("table", current.QualifiedName()),
+			ObjectType:  "table",
+			ObjectName:  key,
+			Details:     map[string]any{"table": current},
+		})
+	default:
 		tc.columnComp.Compare(result, key, current, current, desired)
 		tc.constraintComp.Compare(result, current, desired)
 		tc.compareTableComments(result, key, current, desired)
 		tc.comparePartitions(result, key, current, desired)
+		tc.comparePartitionObjects(result, current, desired)
+		tc.policyComp.Compare(result, key, current, desired)
+	}
+}
+
+// CompareTables diffs two definitions of the same table directly, without
+// the surrounding Database values. The changes describe how to turn current
+// into desired and are sorted by description.
+func (tc *TableComparator) CompareTables(current, desired *schema.Table) []Change {
+	result := &DiffResult{}
+	key := TableKey(desired.Schema, desired.Name)
+
+	tc.columnComp.Compare(result, key, current, current, desired)
+	tc.constraintComp.Compare(result, current, desired)
+	tc.compareTableComments(result, key, current, desired)
+	tc.comparePartitions(result, key, current, desired)
+	tc.policyComp.Compare(result, key, current, desired)
+
+	slices.SortFunc(result.Changes, func(a, b Change) int {
+		return strings.Compare(a.Description, b.Description)
+	})
+
+	return result.Changes
+}
+
+func (tc *TableComparator) detectModifiedTables(
+	result *DiffResult,
+	currentMap, desiredMap map[string]*schema.Table,
+) {
+	for key, desired := range mappedInOrder(result.Desired.Tables, desiredMap, tableMapKey) {
+		if current, exists := currentMap[key]; exists {
+			tc.options.Cache.compare(result, "table", key, current, desired, func() {
+				tc.compareTable(result, key, current, desired)
+			})
+		}
 	}
 }
 
@@ -104,50 +160,164 @@ func (tc *TableComparator) comparePartitions(
 	currentPartitions := tc.buildPartitionMap(current)
 	desiredPartitions := tc.buildPartitionMap(desired)
 
-	for name, partition := range desiredPartitions {
-		if _, exists := currentPartitions[name]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeAddPartition,
-				Severity: SeveritySafe,
-				Description: fmt.Sprintf(
-					"Add partition %s to table %s",
-					partition.Name,
-					desired.QualifiedName(),
+	for name, partition := range mappedInOrder(partitionsOf(desired), desiredPartitions,
+		partitionKey) {
+		currentPartition, exists := currentPartitions[name]
+		if exists && !partitionBoundsChanged(currentPartition, partition) {
+			continue
+		}
+
+		// PostgreSQL cannot change the bounds of a partition in place, so a
+		// partition whose bounds changed is dropped and added again.
+		if exists {
+			result.addWarning(diag.Warning{
+				Code:     diag.CodePartitionBoundsChange,
+				Severity: diag.SeverityWarning,
+				Message: fmt.Sprintf(
+					"Bounds of partition %s of %s changed from %q to %q. "+
+						"The partition is dropped and created again, losing its rows, "+
+						"unless partitions are detached instead of dropped.",
+					partition.Name, desired.QualifiedName(),
+					currentPartition.Definition, partition.Definition,
 				),
-				ObjectType: "partition",
 				ObjectName: PartitionKey(desired.Schema, desired.Name, partition.Name),
-				Details: map[string]any{
-					"table":      desired.QualifiedName(),
-					"partition":  partition,
-					"definition": partition.Definition,
-				},
-				DependsOn: []string{tableKey},
+				ChangeType: string(ChangeTypeDropPartition),
 			})
+
+			tc.addDropPartitionChange(result, current, currentPartition)
 		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeAddPartition,
+			Severity: SeveritySafe,
+			Description: describeAdded("partition",
+				partition.Name+" of table "+desired.QualifiedName()),
+			ObjectType: "partition",
+			ObjectName: PartitionKey(desired.Schema, desired.Name, partition.Name),
+			Details: map[string]any{
+				"table":      desired.QualifiedName(),
+				"partition":  partition,
+				"definition": partition.Definition,
+			},
+			DependsOn: []string{tableKey},
+		})
 	}
 
-	for name, partition := range currentPartitions {
+	for name, partition := range mappedInOrder(partitionsOf(current), currentPartitions,
+		partitionKey) {
 		if _, exists := desiredPartitions[name]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:     ChangeTypeDropPartition,
-				Severity: SeverityBreaking,
-				Description: fmt.Sprintf(
-					"Drop partition %s from table %s",
-					partition.Name,
-					current.QualifiedName(),
-				),
-				ObjectType: "partition",
-				ObjectName: PartitionKey(current.Schema, current.Name, partition.Name),
-				Details: map[string]any{
-					"table":      current.QualifiedName(),
-					"partition":  partition,
-					"definition": partition.Definition,
-				},
-			})
+			tc.addDropPartitionChange(result, current, partition)
+		}
+	}
+}
+
+func (tc *TableComparator) addDropPartitionChange(
+	result *DiffResult,
+	table *schema.Table,
+	partition *schema.Partition,
+) {
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeDropPartition,
+		Severity: SeverityBreaking,
+		Description: describeDropped("partition",
+			partition.Name+" of table "+table.QualifiedName()),
+		ObjectType: "partition",
+		ObjectName: PartitionKey(table.Schema, table.Name, partition.Name),
+		Details: map[string]any{
+			"table":      table.QualifiedName(),
+			"partition":  partition,
+			"definition": partition.Definition,
+		},
+	})
+}
+
+// partitionBoundsChanged reports whether the FOR VALUES clauses of two
+// definitions of a partition differ beyond spelling.
+func partitionBoundsChanged(current, desired *schema.Partition) bool {
+	return normalizePartitionBound(current.Definition) !=
+		normalizePartitionBound(desired.Definition)
+}
+
+var midnightBoundPattern = regexp.MustCompile(`'(\d{4}-\d{2}-\d{2}) 00:00:00(?:[+-]00)?'`)
+
+// normalizePartitionBound reduces a FOR VALUES clause to a canonical form.
+// PostgreSQL prints timestamp bounds at midnight in full, such as
+// '2024-01-01 00:00:00+00', where the schema files usually give the date.
+func normalizePartitionBound(bound string) string {
+	bound = normalizeExpression(bound)
+
+	return midnightBoundPattern.ReplaceAllString(bound, "'$1'")
+}
+
+// comparePartitionObjects diffs the constraints and indexes local to each
+// desired partition. Copies of the parent's constraints and indexes are never
+// recorded on a partition, so only objects declared on the partition itself
+// are compared. current is nil when the partitioned table is being added.
+func (tc *TableComparator) comparePartitionObjects(
+	result *DiffResult,
+	current, desired *schema.Table,
+) {
+	if desired.PartitionStrategy == nil {
+		return
+	}
+
+	var currentPartitions map[string]*schema.Partition
+	if current != nil {
+		currentPartitions = tc.buildPartitionMap(current)
+	}
+
+	for i := range desired.PartitionStrategy.Partitions {
+		partition := &desired.PartitionStrategy.Partitions[i]
+
+		// A partition recreated for new bounds comes back without the
+		// objects of the one it replaces.
+		currentPartition := currentPartitions[schema.NormalizeIdentifier(partition.Name)]
+		if currentPartition == nil || partitionBoundsChanged(currentPartition, partition) {
+			currentPartition = &schema.Partition{Name: partition.Name}
+		}
+
+		start := len(result.Changes)
+
+		tc.constraintComp.Compare(
+			result,
+			partitionTable(desired.Schema, currentPartition),
+			partitionTable(desired.Schema, partition),
+		)
+
+		currentIndexes := partitionIndexSet(currentPartition)
+		desiredIndexes := partitionIndexSet(partition)
+		tc.indexComp.detectAddedIndexes(result, currentIndexes, desiredIndexes)
+		tc.indexComp.detectDroppedIndexes(result, currentIndexes, desiredIndexes)
+		tc.indexComp.detectModifiedIndexes(result, currentIndexes, desiredIndexes)
+
+		// A new partition is created by its own change or with its table.
+		for j := start; j < len(result.Changes); j++ {
+			result.Changes[j].DependsOn = append(result.Changes[j].DependsOn,
+				TableKey(desired.Schema, desired.Name),
+				PartitionKey(desired.Schema, desired.Name, partition.Name))
 		}
 	}
 }
 
+// partitionTable presents a partition's local constraints as a table, the
+// shape the constraint comparator works on.
+func partitionTable(schemaName string, partition *schema.Partition) *schema.Table {
+	return &schema.Table{
+		Schema:      schemaName,
+		Name:        partition.Name,
+		Constraints: partition.Constraints,
+	}
+}
+
+func partitionIndexSet(partition *schema.Partition) *indexSet {
+	set := newIndexSet()
+	for i := range partition.Indexes {
+		set.add(&partition.Indexes[i])
+	}
+
+	return set
+}
+
 func (tc *TableComparator) buildPartitionMap(
 	table *schema.Table,
 ) map[string]*schema.Partition {
@@ -158,13 +328,24 @@ func (tc *TableComparator) buildPartitionMap(
 	m := make(map[string]*schema.Partition, len(table.PartitionStrategy.Partitions))
 	for i := range table.PartitionStrategy.Partitions {
 		p := &table.PartitionStrategy.Partitions[i]
-		key := schema.NormalizeIdentifier(p.Name)
-		m[key] = p
+		m[partitionKey(p)] = p
 	}
 
 	return m
 }
 
+func partitionKey(partition *schema.Partition) string {
+	return schema.NormalizeIdentifier(partition.Name)
+}
+
+func partitionsOf(table *schema.Table) []schema.Partition {
+	if table.PartitionStrategy == nil {
+		return nil
+	}
+
+	return table.PartitionStrategy.Partitions
+}
+
 func (tc *TableComparator) addTableCommentChange(
 	result *DiffResult,
 	key string,
@@ -178,7 +359,7 @@ func (tc *TableComparator) addTableCommentChange(
 	result.Changes = append(result.Changes, Change{
 		Type:        ChangeTypeModifyTableComment,
 		Severity:    SeveritySafe,
-		Description: "Add table comment: " + table.QualifiedName(),
+		Description: describeComment("table", table.QualifiedName(), oldComment, table.Comment),
 		ObjectType:  "table",
 		ObjectName:  key,
 		Details: map[string]any{
@@ -204,11 +385,8 @@ func (tc *TableComparator) addColumnCommentChanges(
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeModifyColumnComment,
 				Severity: SeveritySafe,
-				Description: fmt.Sprintf(
-					"Add column comment: %s.%s",
-					table.QualifiedName(),
-					col.Name,
-				),
+				Description: describeComment("column",
+					table.QualifiedName()+"."+col.Name, "", col.Comment),
 				ObjectType: "column",
 				ObjectName: key,
 				Details: map[string]any{
@@ -231,10 +409,7 @@ func (tc *TableComparator) compareTableComments(
 		return
 	}
 
-	currentComment := normalizeComment(current.Comment)
-	desiredComment := normalizeComment(desired.Comment)
-
-	if currentComment == desiredComment {
+	if tc.options.commentsEqual(current.Comment, desired.Comment) {
 		return
 	}
 
@@ -244,11 +419,12 @@ func (tc *TableComparator) compareTableComments(
 	}
 
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeModifyTableComment,
-		Severity:    severity,
-		Description: "Modify table comment: " + desired.QualifiedName(),
-		ObjectType:  "table",
-		ObjectName:  key,
+		Type:     ChangeTypeModifyTableComment,
+		Severity: severity,
+		Description: describeComment("table", desired.QualifiedName(),
+			current.Comment, desired.Comment),
+		ObjectType: "table",
+		ObjectName: key,
 		Details: map[string]any{
 			"table":       desired.QualifiedName(),
 			"old_comment": current.Comment,
@@ -0,0 +1,215 @@
+package differ
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// TableRecreationThresholds decides when a modified table is rewritten so
+// heavily that recreating it is preferable to altering it in place. A zero
+// field disables that threshold.
+type TableRecreationThresholds struct {
+	// ColumnChangeRatio triggers recreation when more than this fraction of
+	// the table's columns (current and desired combined) are added, dropped
+	// or modified.
+	ColumnChangeRatio float64
+	// PrimaryKeyColumnChanges triggers recreation when the primary key is
+	// replaced and at least this many columns change.
+	PrimaryKeyColumnChanges int
+}
+
+func DefaultTableRecreationThresholds() *TableRecreationThresholds {
+	return &TableRecreationThresholds{
+		ColumnChangeRatio:       0.5,
+		PrimaryKeyColumnChanges: 3,
+	}
+}
+
+// recreateRewrittenTables replaces the ALTER sequence of every modified
+// table that crosses a TableRecreation threshold with a single
+// RECREATE_TABLE change. The generator writes it as a commented manual
+// template, since copying the rows into a new table cannot be done safely
+// without review. Partitioned tables and hypertables are never collapsed.
+func (d *Differ) recreateRewrittenTables(result *DiffResult) {
+	thresholds := d.options.TableRecreation
+	if thresholds == nil {
+		return
+	}
+
+	currentTables := buildTableMap(result.Current.Tables)
+
+	for i := range result.Desired.Tables {
+		desired := &result.Desired.Tables[i]
+		key := TableKey(desired.Schema, desired.Name)
+
+		current := currentTables[key]
+		if current == nil || !canRecreateTable(result, current, desired) {
+			continue
+		}
+
+		owned := tableRewriteChanges(result.Changes, key)
+		if len(owned) == 0 {
+			continue
+		}
+
+		reason := recreationReason(thresholds, result.Changes, owned, current, desired)
+		if reason == "" {
+			continue
+		}
+
+		result.Changes = slices.Concat(
+			slices.DeleteFunc(slices.Clone(result.Changes), func(change Change) bool {
+				return ownsTableChange(&change, key)
+			}),
+			[]Change{{
+				Type:        ChangeTypeRecreateTable,
+				Severity:    SeverityDataMigrationRequired,
+				Description: describe(descRecreateTable, desired.QualifiedName(), len(owned)),
+				ObjectType:  "table",
+				ObjectName:  key,
+				Details: map[string]any{
+					"current": current,
+					"desired": desired,
+					"reason":  reason,
+				},
+				DependsOn: getTableDependencies(desired),
+			}},
+		)
+
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"%s is recreated instead of altered in place: %s",
+			desired.QualifiedName(),
+			reason,
+		))
+	}
+}
+
+func canRecreateTable(result *DiffResult, current, desired *schema.Table) bool {
+	if current.PartitionStrategy != nil || desired.PartitionStrategy != nil {
+		return false
+	}
+
+	isHypertable := func(hypertables []schema.Hypertable) bool {
+		return slices.ContainsFunc(hypertables, func(ht schema.Hypertable) bool {
+			return TableKey(ht.Schema, ht.TableName) == TableKey(desired.Schema, desired.Name)
+		})
+	}
+
+	return !isHypertable(result.Current.Hypertables) && !isHypertable(result.Desired.Hypertables)
+}
+
+// tableRewriteChanges returns the indexes of the changes a recreation of the
+// table would replace.
+func tableRewriteChanges(changes []Change, tableKey string) []int {
+	var owned []int
+
+	for i := range changes {
+		if ownsTableChange(&changes[i], tableKey) {
+			owned = append(owned, i)
+		}
+	}
+
+	return owned
+}
+
+func ownsTableChange(change *Change, tableKey string) bool {
+	switch change.Type {
+	case ChangeTypeAddColumn, ChangeTypeDropColumn, ChangeTypeModifyColumnType,
+		ChangeTypeModifyColumnNullability, ChangeTypeModifyColumnDefault,
+		ChangeTypeModifyColumnComment, ChangeTypeAddConstraint, ChangeTypeDropConstraint,
+		ChangeTypeModifyConstraint, ChangeTypeModifyTableComment, ChangeTypeRenameColumn,
+		ChangeTypeModifyColumnGeneration, ChangeTypeAddColumnIdentity,
+		ChangeTypeDropColumnIdentity, ChangeTypeModifyColumnIdentity:
+		return change.ObjectName == tableKey
+	case ChangeTypeAddIndex, ChangeTypeDropIndex:
+		idx, ok := change.Details["index"].(*schema.Index)
+		return ok && TableKey(idx.Schema, idx.TableName) == tableKey
+	case ChangeTypeModifyIndex:
+		idx, ok := change.Details["desired"].(*schema.Index)
+		return ok && TableKey(idx.Schema, idx.TableName) == tableKey
+	default:
+		return false
+	}
+}
+
+// recreationReason returns which threshold the table's changes cross, or an
+// empty string when the table should be altered in place.
+func recreationReason(
+	thresholds *TableRecreationThresholds,
+	changes []Change,
+	owned []int,
+	current, desired *schema.Table,
+) string {
+	changed := make(map[string]bool)
+	primaryKeyReplaced := false
+
+	for _, idx := range owned {
+		change := &changes[idx]
+
+		switch change.Type {
+		case ChangeTypeAddColumn, ChangeTypeDropColumn, ChangeTypeRenameColumn:
+			if col, ok := change.Details["column"].(*schema.Column); ok {
+				changed[strings.ToLower(col.Name)] = true
+			}
+		case ChangeTypeModifyColumnType, ChangeTypeModifyColumnNullability,
+			ChangeTypeModifyColumnDefault, ChangeTypeModifyColumnGeneration,
+			ChangeTypeAddColumnIdentity, ChangeTypeDropColumnIdentity,
+			ChangeTypeModifyColumnIdentity:
+			if name, ok := change.Details["column_name"].(string); ok {
+				changed[strings.ToLower(name)] = true
+			}
+		case ChangeTypeAddConstraint, ChangeTypeDropConstraint, ChangeTypeModifyConstraint:
+			primaryKeyReplaced = primaryKeyReplaced || isPrimaryKeyChange(change)
+		}
+	}
+
+	total := countDistinctColumns(current, desired)
+
+	if thresholds.ColumnChangeRatio > 0 && total > 0 &&
+		float64(len(changed)) > thresholds.ColumnChangeRatio*float64(total) {
+		return fmt.Sprintf(
+			"%d of %d columns change (%.0f%%), above the %.0f%% column change threshold",
+			len(changed),
+			total,
+			100*float64(len(changed))/float64(total),
+			100*thresholds.ColumnChangeRatio,
+		)
+	}
+
+	if thresholds.PrimaryKeyColumnChanges > 0 && primaryKeyReplaced &&
+		len(changed) >= thresholds.PrimaryKeyColumnChanges {
+		return fmt.Sprintf(
+			"the primary key is replaced and %d columns change (threshold %d)",
+			len(changed),
+			thresholds.PrimaryKeyColumnChanges,
+		)
+	}
+
+	return ""
+}
+
+func isPrimaryKeyChange(change *Change) bool {
+	for _, key := range []string{"constraint", "current", "desired"} {
+		if constraint, ok := change.Details[key].(*schema.Constraint); ok &&
+			constraint.IsPrimaryKey() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func countDistinctColumns(current, desired *schema.Table) int {
+	names := make(map[string]bool, len(current.Columns)+len(desired.Columns))
+
+	for _, table := range []*schema.Table{current, desired} {
+		for i := range table.Columns {
+			names[strings.ToLower(table.Columns[i].Name)] = true
+		}
+	}
+
+	return len(names)
+}
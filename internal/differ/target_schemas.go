@@ -0,0 +1,156 @@
+package differ
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// targetSchemas is the TargetSchemas allowlist, by schema name. The names
+// are given as they are written in SQL, so an unquoted name is folded to
+// lower case.
+type targetSchemas map[string]bool
+
+func newTargetSchemas(names []string) targetSchemas {
+	targets := make(targetSchemas, len(names))
+	for _, name := range names {
+		targets[targetSchemaName(name)] = true
+	}
+
+	return targets
+}
+
+func (t targetSchemas) excludes(schemaName string) bool {
+	return !t[schema.NormalizeSchemaName(schemaName)]
+}
+
+// skippedObject is an object filter left out: its kind and qualified name.
+type skippedObject struct {
+	kind string
+	name string
+}
+
+// filter returns db without the objects outside the target schemas, and the
+// ones it left out. db itself is returned when it has none. Indexes,
+// constraints and partitions go with their tables, and an object that refers
+// to one outside the targets, such as a foreign key, is kept as it is.
+func (t targetSchemas) filter(db *schema.Database) (*schema.Database, []skippedObject) {
+	filtered := *db
+
+	var skipped []skippedObject
+
+	outside := func(kind, schemaName, name string) bool {
+		if !t.excludes(schemaName) {
+			return false
+		}
+
+		skipped = append(skipped, skippedObject{kind, schema.QualifiedName(schemaName, name)})
+
+		return true
+	}
+
+	filtered.Schemas = deleteManaged(db.Schemas, func(s *schema.Schema) bool {
+		return outside("schema", s.Name, "")
+	})
+	filtered.CustomTypes = deleteManaged(db.CustomTypes, func(ct *schema.CustomType) bool {
+		return outside("type", ct.Schema, ct.Name)
+	})
+	filtered.Sequences = deleteManaged(db.Sequences, func(s *schema.Sequence) bool {
+		return outside("sequence", s.Schema, s.Name)
+	})
+	filtered.Tables = deleteManaged(db.Tables, func(table *schema.Table) bool {
+		return outside("table", table.Schema, table.Name)
+	})
+	filtered.Views = deleteManaged(db.Views, func(v *schema.View) bool {
+		return outside("view", v.Schema, v.Name)
+	})
+	filtered.MaterializedViews = deleteManaged(
+		db.MaterializedViews,
+		func(v *schema.MaterializedView) bool {
+			return outside("materialized view", v.Schema, v.Name)
+		},
+	)
+	filtered.Functions = deleteManaged(db.Functions, func(fn *schema.Function) bool {
+		return outside(fn.KindName(), fn.Schema, fn.Name)
+	})
+	filtered.Triggers = deleteManaged(db.Triggers, func(trigger *schema.Trigger) bool {
+		return outside("trigger", trigger.Schema, trigger.TableName+"."+trigger.Name)
+	})
+	filtered.Grants = deleteManaged(db.Grants, func(g *schema.Grant) bool {
+		return outside("grant on", g.Schema, g.ObjectName)
+	})
+	filtered.Hypertables = deleteManaged(db.Hypertables, func(h *schema.Hypertable) bool {
+		return outside("hypertable", h.Schema, h.TableName)
+	})
+	filtered.ContinuousAggregates = deleteManaged(
+		db.ContinuousAggregates,
+		func(ca *schema.ContinuousAggregate) bool {
+			return outside("continuous aggregate", ca.Schema, ca.ViewName)
+		},
+	)
+
+	if len(skipped) == 0 {
+		return db, nil
+	}
+
+	return &filtered, skipped
+}
+
+// skipUntargetedSchemas removes the objects outside Options.TargetSchemas
+// from both schemas before they are compared, so a database that shares its
+// schemas with other tools is not planned to lose theirs. Objects the
+// desired schema declares outside the targets are reported, since they are
+// not created either.
+func (d *Differ) skipUntargetedSchemas(result *DiffResult) {
+	if len(d.options.TargetSchemas) == 0 {
+		return
+	}
+
+	targets := newTargetSchemas(d.options.TargetSchemas)
+
+	current, skippedCurrent := targets.filter(result.Current)
+	desired, skippedDesired := targets.filter(result.Desired)
+
+	result.Current, result.Desired = current, desired
+
+	if len(skippedCurrent) > 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"skipped %d database objects outside the target schemas %s",
+			len(skippedCurrent), strings.Join(d.options.TargetSchemas, ", "),
+		))
+	}
+
+	for _, object := range skippedDesired {
+		result.addWarning(diag.Warning{
+			Code:     diag.CodeOutsideTargetSchemas,
+			Severity: diag.SeverityWarning,
+			Message: fmt.Sprintf(
+				"%s %s of the desired schema is outside the target schemas %s and is ignored",
+				object.kind, object.name, strings.Join(d.options.TargetSchemas, ", "),
+			),
+			ObjectName: object.name,
+		})
+	}
+}
+
+// targetSchemasHash is the Options.Hash field of the target schemas, which
+// do not depend on the order they were given in.
+func targetSchemasHash(names []string) string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, targetSchemaName(name))
+	}
+
+	slices.Sort(normalized)
+
+	return "target_schemas=" + strings.Join(slices.Compact(normalized), ",")
+}
+
+// targetSchemaName is the schema a target schema given as written in SQL
+// names.
+func targetSchemaName(name string) string {
+	return schema.NormalizeSchemaName(schema.FoldIdentifier(name))
+}
@@ -1,12 +1,14 @@
 package differ_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -676,3 +678,96 @@ func TestDiffer_NoContinuousAggregateRecreationForUnrelatedTable(t *testing.T) {
 	assert.Equal(t, 0, changeTypes[differ.ChangeTypeAddContinuousAggregate],
 		"should NOT have ADD continuous aggregate - change is on unrelated table")
 }
+
+func TestDiffer_ViewsOverRecreatedContinuousAggregate(t *testing.T) {
+	t.Parallel()
+
+	const schemaSQL = `
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, device_id TEXT NOT NULL, value %s);
+SELECT create_hypertable('metrics', 'time');
+
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket(INTERVAL '1 hour', time) AS bucket, device_id, avg(value) AS avg_value
+FROM metrics GROUP BY bucket, device_id WITH NO DATA;
+
+CREATE VIEW device_hourly AS SELECT bucket, device_id, avg_value FROM metrics_hourly;
+CREATE VIEW device_latest AS SELECT device_id, max(bucket) AS last_bucket FROM device_hourly
+GROUP BY device_id;
+CREATE VIEW raw_devices AS SELECT DISTINCT device_id FROM metrics;
+`
+
+	parse := func(valueType string) *schema.Database {
+		db := &schema.Database{}
+		require.NoError(t, parser.New().ParseSQL(fmt.Sprintf(schemaSQL, valueType), db))
+
+		return db
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).
+		Compare(parse("DOUBLE PRECISION"), parse("NUMERIC"))
+	require.NoError(t, err)
+
+	order := make(map[string]int)
+
+	for _, change := range result.Changes {
+		key := string(change.Type) + " " + change.ObjectName
+		_, duplicate := order[key]
+		require.False(t, duplicate, "views recreated for both causes get one pair: %s", key)
+
+		order[key] = change.Order
+	}
+
+	sequence := []string{
+		"DROP_VIEW public.device_latest",
+		"DROP_VIEW public.device_hourly",
+		"DROP_CONTINUOUS_AGGREGATE public.metrics_hourly",
+		"MODIFY_COLUMN_TYPE public.metrics",
+		"ADD_CONTINUOUS_AGGREGATE public.metrics_hourly",
+		"ADD_VIEW public.device_hourly",
+		"ADD_VIEW public.device_latest",
+	}
+
+	assert.NotContains(t, order, "DROP_VIEW public.raw_devices",
+		"a view not reading the changed column is left alone")
+
+	for i, key := range sequence {
+		require.Contains(t, order, key)
+
+		if i > 0 {
+			assert.Less(t, order[sequence[i-1]], order[key], "%s must precede %s",
+				sequence[i-1], key)
+		}
+	}
+}
+
+func TestDiffer_ViewFilteringOnChangedColumnIsRecreated(t *testing.T) {
+	t.Parallel()
+
+	const schemaSQL = `
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, device_id TEXT NOT NULL, value %s);
+SELECT create_hypertable('metrics', 'time');
+
+CREATE VIEW raw_devices AS SELECT DISTINCT device_id FROM metrics WHERE value > 0;
+`
+
+	parse := func(valueType string) *schema.Database {
+		db := &schema.Database{}
+		require.NoError(t, parser.New().ParseSQL(fmt.Sprintf(schemaSQL, valueType), db))
+
+		return db
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).
+		Compare(parse("DOUBLE PRECISION"), parse("NUMERIC"))
+	require.NoError(t, err)
+
+	dropAt := changeIndex(result, differ.ChangeTypeDropView, "public.raw_devices")
+	alterAt := changeIndex(result, differ.ChangeTypeModifyColumnType, "public.metrics")
+	addAt := changeIndex(result, differ.ChangeTypeAddView, "public.raw_devices")
+
+	require.NotEqual(t, -1, dropAt, "a view reading the column only in WHERE is recreated")
+	require.NotEqual(t, -1, alterAt)
+	require.NotEqual(t, -1, addAt)
+	assert.Less(t, dropAt, alterAt)
+	assert.Less(t, alterAt, addAt)
+}
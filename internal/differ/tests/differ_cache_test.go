@@ -0,0 +1,279 @@
+package differ_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// cacheSchemaSQL returns count groups of a table, a view, a materialized
+// view, a function and a trigger. The view of group changed selects one more
+// column.
+func cacheSchemaSQL(count, changed int) string {
+	var sb strings.Builder
+
+	for i := range count {
+		extra := ""
+		if i == changed {
+			extra = ", o.created_at"
+		}
+
+		fmt.Fprintf(&sb, `
+CREATE TABLE orders_%[1]d (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid')),
+    total NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX orders_%[1]d_customer_idx ON orders_%[1]d (customer_id, created_at DESC);
+COMMENT ON TABLE orders_%[1]d IS 'Orders of region %[1]d';
+
+CREATE VIEW paid_orders_%[1]d AS
+SELECT o.id, o.customer_id, o.total%[2]s
+FROM orders_%[1]d o
+WHERE o.status = 'paid' AND o.total >= 10.5;
+
+CREATE MATERIALIZED VIEW order_totals_%[1]d AS
+SELECT customer_id, SUM(total) AS total FROM orders_%[1]d GROUP BY customer_id;
+
+CREATE FUNCTION touch_orders_%[1]d() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.total := coalesce(NEW.total, 0);
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER touch_orders_%[1]d BEFORE INSERT ON orders_%[1]d
+    FOR EACH ROW EXECUTE FUNCTION touch_orders_%[1]d();
+`, i, extra)
+	}
+
+	return sb.String()
+}
+
+func parseCacheSchema(tb testing.TB, count, changed int) *schema.Database {
+	tb.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(tb, p.ParseSQL(cacheSchemaSQL(count, changed), db))
+	require.Empty(tb, p.GetErrors())
+
+	return db
+}
+
+func compareWithCache(
+	t *testing.T,
+	cache *differ.ComparisonCache,
+	current, desired *schema.Database,
+) *differ.DiffResult {
+	t.Helper()
+
+	opts := differ.DefaultOptions()
+	opts.Cache = cache
+
+	result, err := differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+
+	return result
+}
+
+func changeDescriptions(result *differ.DiffResult) []string {
+	descriptions := make([]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		descriptions = append(descriptions, string(change.Type)+" "+change.Description)
+	}
+
+	return descriptions
+}
+
+func TestComparisonCache_ReusesEqualPairs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	current := parseCacheSchema(t, 20, -1)
+	desired := parseCacheSchema(t, 20, 3)
+
+	uncached, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, uncached.Changes, 1)
+
+	cold := differ.OpenComparisonCache(dir, "v1")
+	coldResult := compareWithCache(t, cold, current, desired)
+	require.NoError(t, cold.Save())
+
+	reused, compared := cold.Stats()
+	assert.Zero(t, reused)
+	assert.Equal(t, 100, compared)
+	assert.Equal(t, changeDescriptions(uncached), changeDescriptions(coldResult))
+
+	warm := differ.OpenComparisonCache(dir, "v1")
+	warmResult := compareWithCache(t, warm, current, desired)
+
+	reused, compared = warm.Stats()
+	assert.Equal(t, 99, reused)
+	assert.Equal(t, 1, compared, "the changed view is compared again")
+	assert.Equal(t, changeDescriptions(uncached), changeDescriptions(warmResult))
+	assert.Empty(t, warmResult.Diagnostics)
+}
+
+func TestComparisonCache_IgnoresSourceLocations(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	current := parseCacheSchema(t, 3, -1)
+
+	cold := differ.OpenComparisonCache(dir, "v1")
+	compareWithCache(t, cold, current, parseCacheSchema(t, 3, -1))
+	require.NoError(t, cold.Save())
+
+	moved := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL("\n\n\n"+cacheSchemaSQL(3, -1), moved))
+
+	warm := differ.OpenComparisonCache(dir, "v1")
+	result := compareWithCache(t, warm, current, moved)
+	assert.Empty(t, result.Changes)
+
+	reused, compared := warm.Stats()
+	assert.Equal(t, 15, reused)
+	assert.Zero(t, compared)
+}
+
+func TestComparisonCache_Invalidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		version string
+		modify  func(*differ.Options)
+	}{
+		{name: "tool version", version: "v2", modify: func(*differ.Options) {}},
+		{
+			name:    "options hash",
+			version: "v1",
+			modify:  func(opts *differ.Options) { opts.IgnoreComments = true },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			current := parseCacheSchema(t, 2, -1)
+			desired := parseCacheSchema(t, 2, -1)
+
+			cold := differ.OpenComparisonCache(dir, "v1")
+			compareWithCache(t, cold, current, desired)
+			require.NoError(t, cold.Save())
+
+			opts := differ.DefaultOptions()
+			tt.modify(opts)
+			opts.Cache = differ.OpenComparisonCache(dir, tt.version)
+
+			_, err := differ.New(opts).Compare(current, desired)
+			require.NoError(t, err)
+
+			reused, compared := opts.Cache.Stats()
+			assert.Zero(t, reused)
+			assert.Equal(t, 10, compared)
+		})
+	}
+}
+
+func TestComparisonCache_CorruptFileFallsBackToFullComparison(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, differ.ComparisonCacheFile)
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": "v1", "equal": [`), 0o644))
+
+	current := parseCacheSchema(t, 2, -1)
+	desired := parseCacheSchema(t, 2, 0)
+
+	cache := differ.OpenComparisonCache(dir, "v1")
+	result := compareWithCache(t, cache, current, desired)
+	require.Len(t, result.Changes, 1)
+
+	reused, compared := cache.Stats()
+	assert.Zero(t, reused)
+	assert.Equal(t, 10, compared)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodeComparisonCache, result.Diagnostics[0].Code)
+	assert.Equal(t, diag.SeverityWarning, result.Diagnostics[0].Severity)
+	assert.Contains(t, result.Diagnostics[0].Message, path)
+
+	require.NoError(t, cache.Save())
+
+	warm := differ.OpenComparisonCache(dir, "v1")
+	warmResult := compareWithCache(t, warm, current, desired)
+	assert.Empty(t, warmResult.Diagnostics)
+
+	reused, _ = warm.Stats()
+	assert.Equal(t, 9, reused)
+}
+
+// BenchmarkCompareWithCache compares 600 groups of tables, views,
+// materialized views, functions and triggers, 3,000 objects, of which one
+// view changed. A cold run compares every pair and fills the cache, which
+// costs little over an uncached run; a warm run only compares the changed
+// view, and takes about a third of the time.
+func BenchmarkCompareWithCache(b *testing.B) {
+	current := parseCacheSchema(b, 600, -1)
+	desired := parseCacheSchema(b, 600, 42)
+
+	run := func(b *testing.B, cache func() *differ.ComparisonCache) {
+		b.Helper()
+		b.ReportAllocs()
+
+		for b.Loop() {
+			opts := differ.DefaultOptions()
+			opts.Cache = cache()
+
+			if _, err := differ.New(opts).Compare(current, desired); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		run(b, func() *differ.ComparisonCache { return nil })
+	})
+
+	b.Run("cold", func(b *testing.B) {
+		run(b, func() *differ.ComparisonCache {
+			return differ.OpenComparisonCache(b.TempDir(), "bench")
+		})
+	})
+
+	b.Run("warm", func(b *testing.B) {
+		dir := b.TempDir()
+
+		opts := differ.DefaultOptions()
+		opts.Cache = differ.OpenComparisonCache(dir, "bench")
+
+		if _, err := differ.New(opts).Compare(current, desired); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := opts.Cache.Save(); err != nil {
+			b.Fatal(err)
+		}
+
+		warm := differ.OpenComparisonCache(dir, "bench")
+
+		run(b, func() *differ.ComparisonCache { return warm })
+	})
+}
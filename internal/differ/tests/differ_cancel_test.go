@@ -0,0 +1,100 @@
+package differ_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func cancelTestDatabase() *schema.Database {
+	return &schema.Database{
+		Tables: []schema.Table{
+			{
+				Schema:  schema.DefaultSchema,
+				Name:    "users",
+				Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+				Indexes: []schema.Index{
+					{Schema: schema.DefaultSchema, Name: "idx_users_id", TableName: "users",
+						Columns: []string{"id"}},
+				},
+			},
+			{
+				Schema:  schema.DefaultSchema,
+				Name:    "orders",
+				Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+			},
+		},
+		Views: []schema.View{
+			{Schema: schema.DefaultSchema, Name: "user_ids", Definition: "SELECT id FROM users"},
+		},
+	}
+}
+
+func TestDiffer_CompareContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cancelAt string
+		wantErr  string
+	}{
+		{
+			name:     "between comparison passes",
+			cancelAt: "index comparison",
+			wantErr:  "diff cancelled during ensure-only filtering after 3 of 4 objects",
+		},
+		{
+			name:     "during dependency resolution",
+			cancelAt: "concurrent refresh hazard detection",
+			wantErr:  "diff cancelled during dependency resolution after 0 of 4 changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var passes []string
+
+			opts := differ.DefaultOptions()
+			opts.Progress = func(pass string, _, _ int) {
+				passes = append(passes, pass)
+				if pass == tt.cancelAt {
+					cancel()
+				}
+			}
+
+			result, err := differ.New(opts).CompareContext(
+				ctx,
+				&schema.Database{},
+				cancelTestDatabase(),
+			)
+			require.ErrorIs(t, err, context.Canceled)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Nil(t, result)
+			assert.Equal(t, tt.cancelAt, passes[len(passes)-1])
+		})
+	}
+}
+
+func TestDiffer_CompareMatchesCompareContext(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+
+	want, err := d.Compare(&schema.Database{}, cancelTestDatabase())
+	require.NoError(t, err)
+
+	got, err := d.CompareContext(context.Background(), &schema.Database{}, cancelTestDatabase())
+	require.NoError(t, err)
+
+	assert.Equal(t, want.Changes, got.Changes)
+}
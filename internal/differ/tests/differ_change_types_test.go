@@ -0,0 +1,83 @@
+package differ_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+// declaredConstants returns the values of the string constants of the given
+// type declared in the Go files of dir, in declaration order.
+func declaredConstants(t *testing.T, dir, typeName string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var values []string
+
+	fset := token.NewFileSet()
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		require.NoError(t, err)
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+
+				ident, ok := valueSpec.Type.(*ast.Ident)
+				if !ok || ident.Name != typeName {
+					continue
+				}
+
+				for _, value := range valueSpec.Values {
+					lit, ok := value.(*ast.BasicLit)
+					require.True(t, ok, "constant of %s is not a literal", typeName)
+
+					unquoted, err := strconv.Unquote(lit.Value)
+					require.NoError(t, err)
+
+					values = append(values, unquoted)
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+func TestChangeTypes_ListsEveryDeclaredChangeType(t *testing.T) {
+	t.Parallel()
+
+	declared := declaredConstants(t, "..", "ChangeType")
+	require.NotEmpty(t, declared)
+
+	registered := make([]string, 0, len(differ.ChangeTypes()))
+	for _, changeType := range differ.ChangeTypes() {
+		registered = append(registered, string(changeType))
+	}
+
+	assert.ElementsMatch(t, declared, registered,
+		"a ChangeType constant is missing from differ.ChangeTypes, or listed twice")
+}
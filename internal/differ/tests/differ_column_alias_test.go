@@ -0,0 +1,148 @@
+package differ_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDiffer_OutputAliasDoesNotCoupleAggregateToColumn(t *testing.T) {
+	t.Parallel()
+
+	const schemaSQL = `
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, device_id TEXT NOT NULL, value DOUBLE PRECISION%s);
+SELECT create_hypertable('metrics', 'time');
+
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket(INTERVAL '1 hour', time) AS bucket, device_id, %s
+FROM metrics m GROUP BY bucket, device_id WITH NO DATA;
+`
+
+	tests := []struct {
+		name       string
+		current    string
+		desired    string
+		output     string
+		recreated  bool
+		columnType differ.ChangeType
+	}{
+		{
+			name:       "alias with the changed type",
+			current:    ", status TEXT",
+			desired:    ", status VARCHAR(20)",
+			output:     "count(*) AS status",
+			columnType: differ.ChangeTypeModifyColumnType,
+		},
+		{
+			name:       "alias without AS",
+			current:    ", status TEXT",
+			desired:    ", status TEXT NOT NULL",
+			output:     "avg(value) status",
+			columnType: differ.ChangeTypeModifyColumnNullability,
+		},
+		{
+			name:       "alias of a dropped column",
+			current:    ", status TEXT",
+			output:     "max(value) AS status",
+			columnType: differ.ChangeTypeDropColumn,
+		},
+		{
+			name:       "qualified reference",
+			current:    ", status TEXT",
+			desired:    ", status VARCHAR(20)",
+			output:     "max(m.status) AS last_status",
+			recreated:  true,
+			columnType: differ.ChangeTypeModifyColumnType,
+		},
+		{
+			name:       "unqualified reference under its own name",
+			current:    ", status TEXT",
+			desired:    ", status VARCHAR(20)",
+			output:     "max(status) AS status",
+			recreated:  true,
+			columnType: differ.ChangeTypeModifyColumnType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parse := func(columns string) *schema.Database {
+				db := &schema.Database{}
+				sql := fmt.Sprintf(schemaSQL, columns, tt.output)
+				require.NoError(t, parser.New().ParseSQL(sql, db))
+
+				return db
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).
+				Compare(parse(tt.current), parse(tt.desired))
+			require.NoError(t, err)
+
+			assert.Contains(t, changeTypes(result), tt.columnType)
+
+			aggregate := "public.metrics_hourly"
+			if tt.recreated {
+				assert.NotEqual(t, -1,
+					changeIndex(result, differ.ChangeTypeDropContinuousAggregate, aggregate))
+				assert.NotEqual(t, -1,
+					changeIndex(result, differ.ChangeTypeAddContinuousAggregate, aggregate))
+
+				return
+			}
+
+			for _, change := range result.Changes {
+				assert.NotEqual(t, aggregate, change.ObjectName,
+					"the aggregate is untouched: %s", change.Type)
+			}
+		})
+	}
+}
+
+func TestDiffer_RecreatesOnlyViewsReadingChangedColumn(t *testing.T) {
+	t.Parallel()
+
+	const schemaSQL = `
+CREATE TABLE customers (id BIGINT PRIMARY KEY, status TEXT);
+CREATE TABLE orders (id BIGINT PRIMARY KEY, customer_id BIGINT, status %s);
+
+CREATE VIEW customer_orders AS
+SELECT o.id, c.status FROM orders o JOIN customers c ON c.id = o.customer_id;
+CREATE VIEW order_counts AS SELECT customer_id, count(*) AS status FROM orders GROUP BY 1;
+CREATE VIEW order_casts AS SELECT id, id::text AS status FROM orders;
+CREATE VIEW order_status AS SELECT id, upper(status) AS label FROM orders;
+CREATE VIEW order_rows AS SELECT * FROM orders;
+CREATE VIEW order_labels AS SELECT id, label FROM order_status;
+`
+
+	parse := func(statusType string) *schema.Database {
+		db := &schema.Database{}
+		require.NoError(t, parser.New().ParseSQL(fmt.Sprintf(schemaSQL, statusType), db))
+
+		return db
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(parse("TEXT"), parse("VARCHAR(20)"))
+	require.NoError(t, err)
+
+	for _, view := range []string{"order_status", "order_rows", "order_labels"} {
+		assert.NotEqual(t, -1,
+			changeIndex(result, differ.ChangeTypeDropView, "public."+view), view)
+		assert.NotEqual(t, -1,
+			changeIndex(result, differ.ChangeTypeAddView, "public."+view), view)
+	}
+
+	for _, view := range []string{"customer_orders", "order_counts", "order_casts"} {
+		for _, change := range result.Changes {
+			assert.NotEqual(t, "public."+view, change.ObjectName,
+				"%s is untouched: %s", view, change.Type)
+		}
+	}
+}
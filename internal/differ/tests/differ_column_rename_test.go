@@ -0,0 +1,105 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func compareRenames(t *testing.T, detect bool, current, desired string) *differ.DiffResult {
+	t.Helper()
+
+	parse := func(sql string) *schema.Database {
+		db := &schema.Database{}
+		p := parser.New()
+		require.NoError(t, p.ParseSQL(sql, db))
+		require.Empty(t, p.GetErrors())
+
+		return db
+	}
+
+	opts := differ.DefaultOptions()
+	opts.DetectColumnRenames = detect
+
+	result, err := differ.New(opts).Compare(parse(current), parse(desired))
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestDiffer_DetectsColumnRename(t *testing.T) {
+	t.Parallel()
+
+	current := `CREATE TABLE payments (
+    id BIGINT PRIMARY KEY,
+    provider TEXT NOT NULL DEFAULT 'manual'
+);`
+	desired := `CREATE TABLE payments (
+    id BIGINT PRIMARY KEY,
+    source TEXT NOT NULL DEFAULT 'manual'
+);
+CREATE INDEX payments_source_idx ON payments (source);`
+
+	result := compareRenames(t, true, current, desired)
+	require.Len(t, result.Changes, 2)
+
+	rename := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeRenameColumn, rename.Type)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, rename.Severity)
+	assert.Equal(t, "provider", rename.Details["old_name"])
+	assert.Equal(t, "source", rename.Details["new_name"])
+	assert.Equal(t, "public.payments", rename.Details["table"])
+	assert.Equal(t,
+		"Column public.payments.provider is named source in desired schema (will be renamed)",
+		rename.Description)
+
+	assert.Equal(t, differ.ChangeTypeAddIndex, result.Changes[1].Type,
+		"the index on the new name follows the rename")
+	assert.Empty(t, result.Diagnostics)
+
+	withoutDetection := compareRenames(t, false, current, desired)
+	assert.Empty(t, changesOfType(withoutDetection, differ.ChangeTypeRenameColumn))
+	assert.Len(t, changesOfType(withoutDetection, differ.ChangeTypeDropColumn), 1)
+	assert.Len(t, changesOfType(withoutDetection, differ.ChangeTypeAddColumn), 1)
+}
+
+func TestDiffer_ColumnRenameNeedsAnExactMatch(t *testing.T) {
+	t.Parallel()
+
+	result := compareRenames(t, true,
+		`CREATE TABLE payments (id BIGINT, provider TEXT NOT NULL);`,
+		`CREATE TABLE payments (id BIGINT, source TEXT);`,
+	)
+
+	assert.Empty(t, changesOfType(result, differ.ChangeTypeRenameColumn))
+	assert.Len(t, changesOfType(result, differ.ChangeTypeDropColumn), 1)
+	assert.Len(t, changesOfType(result, differ.ChangeTypeAddColumn), 1)
+	assert.Empty(t, result.Diagnostics)
+}
+
+func TestDiffer_AmbiguousColumnRenameIsDroppedAndAdded(t *testing.T) {
+	t.Parallel()
+
+	result := compareRenames(t, true,
+		`CREATE TABLE payments (id BIGINT, provider TEXT, legacy_note INTEGER);`,
+		`CREATE TABLE payments (id BIGINT, source TEXT, channel TEXT, note INTEGER);`,
+	)
+
+	renames := changesOfType(result, differ.ChangeTypeRenameColumn)
+	require.Len(t, renames, 1, "the unambiguous pair is still renamed")
+	assert.Equal(t, "legacy_note", renames[0].Details["old_name"])
+	assert.Equal(t, "note", renames[0].Details["new_name"])
+
+	assert.Len(t, changesOfType(result, differ.ChangeTypeDropColumn), 1)
+	assert.Len(t, changesOfType(result, differ.ChangeTypeAddColumn), 2)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodeAmbiguousColumnRename, result.Diagnostics[0].Code)
+	assert.Contains(t, result.Diagnostics[0].Message, "dropped columns provider of public.payments")
+}
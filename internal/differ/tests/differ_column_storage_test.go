@@ -0,0 +1,108 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func storageTable(payload schema.Column) *schema.Database {
+	payload.Name = "payload"
+	payload.Position = 2
+
+	return &schema.Database{Tables: []schema.Table{{
+		Schema:  schema.DefaultSchema,
+		Name:    "events",
+		Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}, payload},
+	}}}
+}
+
+func TestDiffer_ColumnStorageAndCompression(t *testing.T) {
+	t.Parallel()
+
+	current := storageTable(schema.Column{DataType: "bytea", IsNullable: true})
+	desired := storageTable(schema.Column{
+		DataType:    "bytea",
+		IsNullable:  true,
+		Storage:     schema.StorageExternal,
+		Compression: schema.CompressionLZ4,
+	})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+
+	storage := result.Changes[changeIndex(result, differ.ChangeTypeModifyColumnStorage,
+		"public.events")]
+	assert.Equal(t, differ.SeveritySafe, storage.Severity)
+	assert.Equal(t, "extended", storage.Details["old_storage"])
+	assert.Equal(t, "external", storage.Details["new_storage"])
+
+	compression := result.Changes[changeIndex(result, differ.ChangeTypeModifyColumnCompression,
+		"public.events")]
+	assert.Equal(t, differ.SeveritySafe, compression.Severity)
+	assert.Equal(t, "", compression.Details["old_compression"])
+	assert.Equal(t, "lz4", compression.Details["new_compression"])
+	assert.Equal(t, "Column compression differs: public.events.payload is default in database, "+
+		"lz4 in desired schema",
+		compression.Description)
+}
+
+func TestDiffer_ColumnStorageComparesEffectiveStrategy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current schema.Column
+		desired schema.Column
+	}{
+		{
+			name:    "default of the type named explicitly",
+			current: schema.Column{DataType: "text"},
+			desired: schema.Column{DataType: "text", Storage: schema.StorageExtended},
+		},
+		{
+			name:    "fixed-length type",
+			current: schema.Column{DataType: "bigint", Storage: schema.StoragePlain},
+			desired: schema.Column{DataType: "bigint"},
+		},
+		{
+			name:    "type change resets the strategy",
+			current: schema.Column{DataType: "text"},
+			desired: schema.Column{DataType: "integer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				storageTable(tt.current), storageTable(tt.desired))
+			require.NoError(t, err)
+			assert.Equal(t, -1,
+				changeIndex(result, differ.ChangeTypeModifyColumnStorage, "public.events"))
+		})
+	}
+}
+
+func TestDiffer_ColumnStorageFollowsTypeChange(t *testing.T) {
+	t.Parallel()
+
+	current := storageTable(schema.Column{DataType: "varchar", Storage: schema.StorageMain})
+	desired := storageTable(schema.Column{DataType: "text", Storage: schema.StorageMain})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	retype := changeIndex(result, differ.ChangeTypeModifyColumnType, "public.events")
+	storage := changeIndex(result, differ.ChangeTypeModifyColumnStorage, "public.events")
+
+	require.NotEqual(t, -1, retype)
+	require.NotEqual(t, -1, storage, "the new type starts from its default strategy")
+	assert.Less(t, retype, storage)
+}
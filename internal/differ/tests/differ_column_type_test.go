@@ -0,0 +1,265 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseColumnType(t *testing.T, dataType string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL("CREATE TABLE t (c "+dataType+");", db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func TestColumnTypeChangeMatrix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		current    string
+		desired    string
+		wantChange bool
+		wantType   string
+		wantSafe   bool
+	}{
+		{"numeric", "numeric", false, "", false},
+		{"numeric", "numeric(20,8)", true, "NUMERIC(20, 8)", false},
+		{"numeric(20,8)", "numeric", true, "NUMERIC", true},
+		{"numeric(20,8)", "numeric(20,8)", false, "", false},
+		{"numeric(20,8)", "numeric(24,8)", true, "NUMERIC(24, 8)", true},
+		{"numeric(10)", "numeric(10,0)", false, "", false},
+		{"decimal(5,2)", "numeric(5,2)", false, "", false},
+		{"decimal", "numeric(5,2)", true, "NUMERIC(5, 2)", false},
+		{"numeric(10,2)[]", "numeric(10,2)[]", false, "", false},
+		{"numeric(10,2)[]", "numeric(12,2)[]", true, "NUMERIC(12, 2)[]", true},
+		{"float", "double precision", false, "", false},
+		{"float8", "double precision", false, "", false},
+		{"float(53)", "double precision", false, "", false},
+		{"float(25)", "float8", false, "", false},
+		{"float(24)", "real", false, "", false},
+		{"float(1)", "float4", false, "", false},
+		{"real", "float4", false, "", false},
+		{"real", "double precision", true, "DOUBLE PRECISION", false},
+		{"double precision[]", "float8[]", false, "", false},
+		{"double precision []", "double precision[]", false, "", false},
+		{"double precision ARRAY", "float8[]", false, "", false},
+		{"double precision[]", "double precision", true, "DOUBLE PRECISION", false},
+		{"money", "money", false, "", false},
+		{"money", "numeric(12,2)", true, "NUMERIC(12, 2)", false},
+		{"int4", "INTEGER", false, "", false},
+		{"int2", "smallint", false, "", false},
+		{"bool", "boolean", false, "", false},
+		{"timestamptz", "timestamp with time zone", false, "", false},
+		{"character varying", "varchar", false, "", false},
+		{"character varying(20)", "varchar(20)", false, "", false},
+		{"varchar(50)", "varchar", true, "VARCHAR", true},
+		{"varchar", "varchar(50)", true, "VARCHAR(50)", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+" to "+tt.desired, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				parseColumnType(t, tt.current), parseColumnType(t, tt.desired),
+			)
+			require.NoError(t, err)
+
+			if !tt.wantChange {
+				assert.Empty(t, result.Changes)
+				return
+			}
+
+			require.Len(t, result.Changes, 1)
+
+			change := result.Changes[0]
+			assert.Equal(t, differ.ChangeTypeModifyColumnType, change.Type)
+			assert.Equal(t, tt.wantType, change.Details["new_type"])
+			assert.Equal(t, tt.wantSafe, change.Severity == differ.SeveritySafe)
+		})
+	}
+}
+
+func TestColumnTypeExtractedNumericScale(t *testing.T) {
+	t.Parallel()
+
+	precision, scale := 10, 0
+	current := &schema.Database{Tables: []schema.Table{{
+		Schema: schema.DefaultSchema,
+		Name:   "t",
+		Columns: []schema.Column{{
+			Name: "c", DataType: "numeric", Precision: &precision, Scale: &scale,
+			IsNullable: true, Position: 1,
+		}},
+	}}}
+
+	assertNoChanges(t, current, parseColumnType(t, "numeric(10)"))
+}
+
+func TestTimeTypePrecisionMatrix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		current      string
+		desired      string
+		wantType     string
+		wantSeverity differ.ChangeSeverity
+		wantChange   string
+	}{
+		{"timestamptz(3)", "timestamp(3) with time zone", "", "", ""},
+		{"timestamptz", "timestamptz(6)", "", "", ""},
+		{"timestamp(3) without time zone", "timestamp(3)", "", "", ""},
+		{"timetz(4)", "time(4) with time zone", "", "", ""},
+		{"interval hour to minute", "INTERVAL HOUR TO MINUTE", "", "", ""},
+		{"interval", "interval(6)", "", "", ""},
+		{
+			"timestamptz(3)", "timestamptz(6)",
+			"TIMESTAMPTZ(6)", differ.SeveritySafe, differ.PrecisionChangeWiden,
+		},
+		{
+			"timestamptz(6)", "timestamp(3) with time zone",
+			"TIMESTAMP(3) WITH TIME ZONE", differ.SeverityPotentiallyBreaking,
+			differ.PrecisionChangeNarrow,
+		},
+		{
+			"timestamptz", "timestamptz(3)",
+			"TIMESTAMPTZ(3)", differ.SeverityPotentiallyBreaking, differ.PrecisionChangeNarrow,
+		},
+		{
+			"timestamp(0)", "timestamp",
+			"TIMESTAMP", differ.SeveritySafe, differ.PrecisionChangeWiden,
+		},
+		{
+			"time(2)", "time(4)",
+			"TIME(4)", differ.SeveritySafe, differ.PrecisionChangeWiden,
+		},
+		{
+			"timetz(4)", "timetz(1)",
+			"TIMETZ(1)", differ.SeverityPotentiallyBreaking, differ.PrecisionChangeNarrow,
+		},
+		{
+			"interval(3)", "interval(6)",
+			"INTERVAL(6)", differ.SeveritySafe, differ.PrecisionChangeWiden,
+		},
+		{
+			"interval day to second(2)", "interval day to second(0)",
+			"INTERVAL DAY TO SECOND(0)", differ.SeverityPotentiallyBreaking,
+			differ.PrecisionChangeNarrow,
+		},
+		{
+			"timestamptz(3)[]", "timestamptz(6)[]",
+			"TIMESTAMPTZ(6)[]", differ.SeveritySafe, differ.PrecisionChangeWiden,
+		},
+		{
+			"time(2) with time zone", "time(2)",
+			"TIME(2)", differ.SeverityDataMigrationRequired, "",
+		},
+		{
+			"interval", "interval hour to minute",
+			"INTERVAL HOUR TO MINUTE", differ.SeverityDataMigrationRequired, "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+" to "+tt.desired, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				parseColumnType(t, tt.current), parseColumnType(t, tt.desired),
+			)
+			require.NoError(t, err)
+
+			if tt.wantType == "" {
+				assert.Empty(t, result.Changes)
+				return
+			}
+
+			require.Len(t, result.Changes, 1)
+
+			change := result.Changes[0]
+			assert.Equal(t, differ.ChangeTypeModifyColumnType, change.Type)
+			assert.Equal(t, tt.wantType, change.Details["new_type"])
+			assert.Equal(t, tt.wantSeverity, change.Severity)
+
+			precisionChange, _ := change.Details[differ.DetailKeyPrecisionChange].(string)
+			assert.Equal(t, tt.wantChange, precisionChange)
+		})
+	}
+}
+
+func TestColumnTypeEquivalences(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{Tables: []schema.Table{{
+		Schema: schema.DefaultSchema,
+		Name:   "t",
+		Columns: []schema.Column{{
+			Name: "c", DataType: "PUBLIC.CITEXT", IsNullable: true, Position: 1,
+		}},
+	}}}
+	desired := parseColumnType(t, "citext")
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	assert.Equal(t, []differ.ChangeType{differ.ChangeTypeModifyColumnType}, changeTypes(result))
+
+	opts := differ.DefaultOptions()
+	opts.TypeEquivalences = map[string]string{"public.citext": "citext"}
+
+	result, err = differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+
+	assert.NotEqual(t, differ.DefaultOptions().Hash(), opts.Hash())
+}
+
+func TestFunctionTypeAliases(t *testing.T) {
+	t.Parallel()
+
+	parse := func(sql string) *schema.Database {
+		db := &schema.Database{}
+		require.NoError(t, parser.New().ParseSQL(sql, db))
+
+		return db
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+	}{
+		{
+			name: "function argument and return types",
+			current: `CREATE FUNCTION f(integer, character varying) RETURNS integer
+    AS $$ SELECT $1 $$ LANGUAGE sql;`,
+			desired: `CREATE FUNCTION f(a int, b varchar) RETURNS int4
+    AS $$ SELECT $1 $$ LANGUAGE sql;`,
+		},
+		{
+			name:    "procedure argument spelled in another case",
+			current: `CREATE PROCEDURE p(INT) AS $$ SELECT 1 $$ LANGUAGE sql;`,
+			desired: `CREATE PROCEDURE p(int) AS $$ SELECT 1 $$ LANGUAGE sql;`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				parse(tt.current), parse(tt.desired))
+			require.NoError(t, err)
+			assert.Empty(t, result.Changes)
+		})
+	}
+}
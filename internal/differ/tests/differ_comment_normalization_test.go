@@ -159,3 +159,90 @@ func TestDiffer_CommentNormalization_FunctionComments(t *testing.T) {
 		"multiline function comments should normalize to the same value",
 	)
 }
+
+func commentedTable(tableComment, columnComment string) *schema.Database {
+	return &schema.Database{Tables: []schema.Table{{
+		Schema:  schema.DefaultSchema,
+		Name:    "orders",
+		Comment: tableComment,
+		Columns: []schema.Column{{
+			Name:     "status",
+			DataType: "text",
+			Position: 1,
+			Comment:  columnComment,
+		}},
+	}}}
+}
+
+func commentChanges(t *testing.T, opts *differ.Options, current, desired string) []differ.Change {
+	t.Helper()
+
+	result, err := differ.New(opts).Compare(
+		commentedTable(current, current), commentedTable(desired, desired))
+	require.NoError(t, err)
+
+	return append(result.GetChangesByType(differ.ChangeTypeModifyTableComment),
+		result.GetChangesByType(differ.ChangeTypeModifyColumnComment)...)
+}
+
+func TestDiffer_CommentNormalization_WrappedText(t *testing.T) {
+	t.Parallel()
+
+	joined := "Orders placed by customers, one row per checkout. Status tracks fulfilment."
+
+	for name, wrapped := range map[string]string{
+		"double spaces at join points": "Orders placed by customers,  one row per checkout.  " +
+			"Status tracks fulfilment.",
+		"re-wrapped lines": "Orders placed by customers, one row\n" +
+			"per checkout. Status\n    tracks fulfilment.",
+		"surrounding whitespace": "  Orders placed by customers, one row per checkout.\t" +
+			"Status tracks fulfilment.\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Empty(t, commentChanges(t, differ.DefaultOptions(), joined, wrapped))
+		})
+	}
+}
+
+func TestDiffer_CommentNormalization_EditedText(t *testing.T) {
+	t.Parallel()
+
+	desired := "Orders placed by customers,\none row per checkout attempt."
+
+	changes := commentChanges(t, differ.DefaultOptions(),
+		"Orders placed by customers, one row per checkout.", desired)
+	require.Len(t, changes, 2)
+
+	for _, change := range changes {
+		assert.Equal(t, desired, change.Details["new_comment"])
+	}
+}
+
+func TestDiffer_CommentNormalization_ExactWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	opts := differ.DefaultOptions()
+	opts.NormalizeCommentWhitespace = false
+
+	changes := commentChanges(t, opts,
+		"Orders placed by customers, one row per checkout.",
+		"Orders placed by customers,  one row per checkout.")
+	assert.Len(t, changes, 2)
+
+	assert.NotEqual(t, differ.DefaultOptions().Hash(), opts.Hash())
+}
+
+func TestDiffer_CommentNormalization_LineBreaksIgnoredWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	opts := differ.DefaultOptions()
+	opts.NormalizeCommentWhitespace = false
+
+	// The database stores a generated multi-line comment without its line
+	// breaks, so it must not be changed again on the next comparison.
+	assert.Empty(t, commentChanges(t, opts,
+		"Orders placed by customers,one row per checkout.",
+		"Orders placed by customers,\none row per checkout."))
+}
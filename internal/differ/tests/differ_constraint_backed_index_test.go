@@ -281,3 +281,71 @@ func TestDiffer_StandaloneIndexesStillDetected(t *testing.T) {
 		t.Errorf("expected 1 ADD_INDEX change for standalone index, got %d", addIndexCount)
 	}
 }
+
+func TestDiffer_ConstraintAdoptedIndex(t *testing.T) {
+	t.Parallel()
+
+	columns := []schema.Column{
+		{Name: "id", DataType: "bigint", IsNullable: false, Position: 1},
+		{Name: "email", DataType: "text", IsNullable: false, Position: 2},
+	}
+
+	current := &schema.Database{
+		Tables: []schema.Table{
+			{
+				Schema:  schema.DefaultSchema,
+				Name:    "users",
+				Columns: columns,
+				Constraints: []schema.Constraint{
+					{
+						Name:       "users_email_key",
+						Type:       schema.ConstraintUnique,
+						Columns:    []string{"email"},
+						Definition: "UNIQUE (email)",
+						IndexName:  "users_email_idx",
+					},
+				},
+				Indexes: []schema.Index{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "users",
+						Name:      "users_email_idx",
+						Columns:   []string{"email"},
+						Type:      "btree",
+						IsUnique:  true,
+					},
+				},
+			},
+		},
+	}
+
+	desired := &schema.Database{
+		Tables: []schema.Table{
+			{
+				Schema:  schema.DefaultSchema,
+				Name:    "users",
+				Columns: columns,
+				Constraints: []schema.Constraint{
+					{
+						Name:       "users_email_key",
+						Type:       schema.ConstraintUnique,
+						Columns:    []string{"email"},
+						Definition: "UNIQUE (email)",
+					},
+				},
+				Indexes: []schema.Index{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "users",
+						Name:      "users_email_key",
+						Columns:   []string{"email"},
+						Type:      "btree",
+						IsUnique:  true,
+					},
+				},
+			},
+		},
+	}
+
+	assertNoChanges(t, current, desired)
+}
@@ -0,0 +1,101 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func checkDatabase(expression, comment string) *schema.Database {
+	return &schema.Database{Tables: []schema.Table{{
+		Schema:  schema.DefaultSchema,
+		Name:    "orders",
+		Columns: []schema.Column{{Name: "total", DataType: "numeric", Position: 1}},
+		Constraints: []schema.Constraint{{
+			Name:            "orders_total_check",
+			Type:            schema.ConstraintCheck,
+			Definition:      "CHECK (" + expression + ")",
+			CheckExpression: expression,
+			Comment:         comment,
+		}},
+	}}}
+}
+
+func TestDiffer_ConstraintComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		current        *schema.Database
+		desired        *schema.Database
+		ignoreComments bool
+		wantTypes      []differ.ChangeType
+	}{
+		{
+			name:      "whitespace-only difference",
+			current:   checkDatabase("total >= 0", "Non\n negative"),
+			desired:   checkDatabase("total >= 0", "Non negative"),
+			wantTypes: []differ.ChangeType{},
+		},
+		{
+			name:      "comment changed",
+			current:   checkDatabase("total >= 0", "Old meaning"),
+			desired:   checkDatabase("total >= 0", "New meaning"),
+			wantTypes: []differ.ChangeType{differ.ChangeTypeModifyConstraintComment},
+		},
+		{
+			name:           "comments ignored",
+			current:        checkDatabase("total >= 0", "Old meaning"),
+			desired:        checkDatabase("total >= 0", "New meaning"),
+			ignoreComments: true,
+			wantTypes:      []differ.ChangeType{},
+		},
+		{
+			name:    "recreated constraint sets its comment again",
+			current: checkDatabase("total >= 0", "Non negative"),
+			desired: checkDatabase("total > 0", "Non negative"),
+			wantTypes: []differ.ChangeType{
+				differ.ChangeTypeModifyConstraint,
+				differ.ChangeTypeModifyConstraintComment,
+			},
+		},
+		{
+			name:    "new table comment ordered after table",
+			current: &schema.Database{},
+			desired: checkDatabase("total >= 0", "Non negative"),
+			wantTypes: []differ.ChangeType{
+				differ.ChangeTypeAddTable,
+				differ.ChangeTypeModifyConstraintComment,
+			},
+		},
+		{
+			name:      "dropped table has no comment change",
+			current:   checkDatabase("total >= 0", "Non negative"),
+			desired:   &schema.Database{},
+			wantTypes: []differ.ChangeType{differ.ChangeTypeDropTable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := differ.DefaultOptions()
+			opts.IgnoreComments = tt.ignoreComments
+
+			result, err := differ.New(opts).Compare(tt.current, tt.desired)
+			require.NoError(t, err)
+
+			types := make([]differ.ChangeType, 0, len(result.Changes))
+			for _, change := range result.Changes {
+				types = append(types, change.Type)
+			}
+
+			assert.Equal(t, tt.wantTypes, types)
+		})
+	}
+}
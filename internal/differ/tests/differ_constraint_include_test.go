@@ -0,0 +1,81 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseIncludeSchema(t *testing.T, constraint string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(`CREATE TABLE lookups (
+    id BIGINT NOT NULL,
+    key TEXT NOT NULL,
+    payload JSONB,
+    updated_at TIMESTAMPTZ,
+    `+constraint+`
+);`, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func TestDiffer_ConstraintIncludeColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		changed bool
+	}{
+		{
+			name:    "include added",
+			current: "CONSTRAINT lookups_key UNIQUE (key)",
+			desired: "CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload)",
+			changed: true,
+		},
+		{
+			name:    "include column added to primary key",
+			current: "CONSTRAINT lookups_pkey PRIMARY KEY (id) INCLUDE (payload)",
+			desired: "CONSTRAINT lookups_pkey PRIMARY KEY (id) INCLUDE (payload, updated_at)",
+			changed: true,
+		},
+		{
+			name:    "include columns reordered",
+			current: "CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload, updated_at)",
+			desired: "CONSTRAINT lookups_key UNIQUE (key) INCLUDE (updated_at, payload)",
+		},
+		{
+			name:    "unchanged",
+			current: "CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload)",
+			desired: "CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				parseIncludeSchema(t, tt.current), parseIncludeSchema(t, tt.desired))
+			require.NoError(t, err)
+
+			if !tt.changed {
+				assert.Empty(t, result.Changes)
+				return
+			}
+
+			require.Len(t, result.Changes, 1)
+			assert.Equal(t, differ.ChangeTypeModifyConstraint, result.Changes[0].Type)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func enumDatabase(comment string, values ...string) *schema.Database {
+	return &schema.Database{
+		CustomTypes: []schema.CustomType{
+			{Schema: "app", Name: "order_status", Type: "enum", Values: values, Comment: comment},
+		},
+	}
+}
+
+func TestDiffer_EnumValueDescriptions(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		enumDatabase("", "pending", "paid", "legacy"),
+		enumDatabase("", "draft", "pending", "paid", "shipped"),
+	)
+	require.NoError(t, err)
+
+	descriptions := make([]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		descriptions = append(descriptions, change.Description)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"Enum value 'draft' of type app.order_status is in desired schema but not in database " +
+			"(will be added first)",
+		"Enum value 'shipped' of type app.order_status is in desired schema but not in database " +
+			"(will be added after 'paid')",
+		"Enum value 'legacy' of type app.order_status exists in database but not in " +
+			"desired schema (will be removed)",
+	}, descriptions)
+}
+
+func TestDiffer_CustomTypeComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		current        *schema.Database
+		desired        *schema.Database
+		ignoreComments bool
+		wantTypes      []differ.ChangeType
+	}{
+		{
+			name:      "whitespace-only difference",
+			current:   enumDatabase("Order\n lifecycle", "pending"),
+			desired:   enumDatabase("Order lifecycle", "pending"),
+			wantTypes: []differ.ChangeType{},
+		},
+		{
+			name:      "comment changed",
+			current:   enumDatabase("Old meaning", "pending"),
+			desired:   enumDatabase("New meaning", "pending"),
+			wantTypes: []differ.ChangeType{differ.ChangeTypeModifyCustomTypeComment},
+		},
+		{
+			name:           "comments ignored",
+			current:        enumDatabase("Old meaning", "pending"),
+			desired:        enumDatabase("New meaning", "pending"),
+			ignoreComments: true,
+			wantTypes:      []differ.ChangeType{},
+		},
+		{
+			name:    "new type comment ordered after type",
+			current: &schema.Database{},
+			desired: enumDatabase("Order lifecycle", "pending"),
+			wantTypes: []differ.ChangeType{
+				differ.ChangeTypeAddCustomType,
+				differ.ChangeTypeModifyCustomTypeComment,
+			},
+		},
+		{
+			name:      "dropped type has no comment change",
+			current:   enumDatabase("Order lifecycle", "pending"),
+			desired:   &schema.Database{},
+			wantTypes: []differ.ChangeType{differ.ChangeTypeDropCustomType},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := differ.DefaultOptions()
+			opts.IgnoreComments = tt.ignoreComments
+
+			result, err := differ.New(opts).Compare(tt.current, tt.desired)
+			require.NoError(t, err)
+
+			types := make([]differ.ChangeType, 0, len(result.Changes))
+			for _, change := range result.Changes {
+				types = append(types, change.Type)
+			}
+
+			assert.Equal(t, tt.wantTypes, types)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package differ_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func reportingDatabase(language string) *schema.Database {
+	return &schema.Database{
+		Tables: []schema.Table{{
+			Schema:  "app",
+			Name:    "orders",
+			Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+		}},
+		Functions: []schema.Function{{
+			Schema:     "app",
+			Name:       "order_ids",
+			ReturnType: "SETOF bigint",
+			Language:   language,
+			Body:       "SELECT id FROM app.daily_totals WHERE id > 0",
+		}},
+		MaterializedViews: []schema.MaterializedView{{
+			Schema:     "app",
+			Name:       "daily_totals",
+			Definition: "SELECT id FROM app.recent_orders",
+		}},
+		Views: []schema.View{{
+			Schema:     "app",
+			Name:       "recent_orders",
+			Definition: "SELECT o.id FROM app.orders o JOIN app.order_ids() AS ids(id) ON ids.id = o.id",
+		}},
+	}
+}
+
+func TestDiffer_CycleAcrossObjectKinds(t *testing.T) {
+	t.Parallel()
+
+	_, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{}, reportingDatabase("sql"))
+	require.Error(t, err)
+
+	var cycleErr *differ.CycleError
+	require.True(t, errors.As(err, &cycleErr), err.Error())
+	require.Len(t, cycleErr.Steps, 3)
+
+	kinds := make([]string, 0, len(cycleErr.Steps))
+	for _, step := range cycleErr.Steps {
+		kinds = append(kinds, step.Change.ObjectType)
+	}
+
+	assert.ElementsMatch(t, []string{"view", "function", "materialized_view"}, kinds)
+
+	assert.Equal(t, "resolving dependencies: circular dependency detected: "+
+		"view app.recent_orders -> function app.order_ids() -> "+
+		"materialized view app.daily_totals -> view app.recent_orders"+
+		"\n  view app.recent_orders calls function app.order_ids(): "+
+		`"...FROM app.orders o JOIN app.order_ids() AS ids(id) ON ids.id =..."`+
+		"\n  function app.order_ids() body references materialized view app.daily_totals: "+
+		`"SELECT id FROM app.daily_totals WHERE id > 0"`+
+		"\n  materialized view app.daily_totals references view app.recent_orders "+
+		`in its FROM clause: "SELECT id FROM app.recent_orders"`+
+		"\nto break the cycle, change one of:"+
+		"\n  view app.recent_orders: remove the call to app.order_ids() from its query"+
+		"\n  function app.order_ids(): declare it LANGUAGE plpgsql, whose body is not "+
+		"resolved when it is created, or remove the reference to app.daily_totals"+
+		"\n  materialized view app.daily_totals: remove the reference to "+
+		"app.recent_orders from its query",
+		err.Error())
+}
+
+func TestDiffer_PlpgsqlBodyDoesNotCycle(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{}, reportingDatabase("plpgsql"))
+	require.NoError(t, err)
+
+	fn := changeIndex(result, differ.ChangeTypeAddFunction, "app.order_ids()")
+	require.NotEqual(t, -1, fn)
+	assert.Less(t, fn, changeIndex(result, differ.ChangeTypeAddView, "app.recent_orders"))
+}
+
+func TestDiffer_SQLFunctionOrderedAfterViewsItReads(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{
+		Views: []schema.View{{
+			Schema:     schema.DefaultSchema,
+			Name:       "active_users",
+			Definition: "SELECT 1 AS id",
+		}},
+		Functions: []schema.Function{{
+			Schema:     schema.DefaultSchema,
+			Name:       "active_user_count",
+			ReturnType: "bigint",
+			Language:   "sql",
+			Body:       "SELECT count(*) FROM active_users",
+		}},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	assert.Less(t,
+		changeIndex(result, differ.ChangeTypeAddView, "public.active_users"),
+		changeIndex(result, differ.ChangeTypeAddFunction, "public.active_user_count()"))
+}
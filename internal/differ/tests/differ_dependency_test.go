@@ -1095,7 +1095,7 @@ func TestModifyViewDependsOnAddColumnMultipleTables(t *testing.T) {
 	}
 }
 
-func TestModifyViewComesBeforeDropColumn(t *testing.T) {
+func TestViewLosingColumnIsDroppedBeforeDropColumn(t *testing.T) {
 	t.Parallel()
 
 	current := &schema.Database{
@@ -1146,14 +1146,16 @@ func TestModifyViewComesBeforeDropColumn(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	dropColumnIndex, modifyViewIndex := -1, -1
+	dropColumnIndex, dropViewIndex := -1, -1
 
 	for i, change := range result.Changes {
 		switch change.Type {
 		case differ.ChangeTypeDropColumn:
 			dropColumnIndex = i
+		case differ.ChangeTypeDropView:
+			dropViewIndex = i
 		case differ.ChangeTypeModifyView:
-			modifyViewIndex = i
+			t.Fatal("a view losing a column cannot be replaced in place")
 		}
 	}
 
@@ -1161,14 +1163,14 @@ func TestModifyViewComesBeforeDropColumn(t *testing.T) {
 		t.Fatal("DROP_COLUMN change not found")
 	}
 
-	if modifyViewIndex == -1 {
-		t.Fatal("MODIFY_VIEW change not found")
+	if dropViewIndex == -1 {
+		t.Fatal("DROP_VIEW change not found")
 	}
 
-	if modifyViewIndex >= dropColumnIndex {
+	if dropViewIndex >= dropColumnIndex {
 		t.Errorf(
-			"MODIFY_VIEW (index %d) should come before DROP_COLUMN (index %d)",
-			modifyViewIndex,
+			"DROP_VIEW (index %d) should come before DROP_COLUMN (index %d)",
+			dropViewIndex,
 			dropColumnIndex,
 		)
 	}
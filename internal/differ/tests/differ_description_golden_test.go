@@ -0,0 +1,340 @@
+package differ_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+// The description of every change type is kept in testdata/descriptions.golden
+// so that rewording one is a deliberate change to that file:
+//
+//	go test ./internal/differ/tests/ -run DescriptionsGolden -update
+var updateDescriptions = flag.Bool("update", false, "rewrite testdata/descriptions.golden")
+
+var descriptionsGolden = filepath.Join("testdata", "descriptions.golden")
+
+type descriptionCase struct {
+	name    string
+	current string
+	desired string
+	options func(*differ.Options)
+}
+
+var descriptionCases = []descriptionCase{
+	{
+		name:    "schemas",
+		current: `CREATE SCHEMA legacy;`,
+		desired: `CREATE SCHEMA reporting;`,
+	},
+	{
+		name:    "tables",
+		current: `CREATE TABLE audit_log (id BIGINT);`,
+		desired: `CREATE TABLE users (id BIGINT);`,
+	},
+	{
+		name: "columns",
+		current: `CREATE TABLE users (
+    id INTEGER,
+    name TEXT,
+    score INTEGER DEFAULT 0,
+    legacy TEXT,
+    payload JSONB
+);
+COMMENT ON COLUMN users.name IS 'Display name';`,
+		desired: `CREATE TABLE users (
+    id INTEGER,
+    name TEXT NOT NULL,
+    score BIGINT DEFAULT 1,
+    email TEXT,
+    payload JSONB COMPRESSION lz4
+);
+ALTER TABLE users ALTER COLUMN payload SET STORAGE EXTERNAL;
+COMMENT ON COLUMN users.name IS 'Full name';`,
+	},
+	{
+		name:    "column renames",
+		current: `CREATE TABLE payments (id BIGINT, provider TEXT NOT NULL);`,
+		desired: `CREATE TABLE payments (id BIGINT, source TEXT NOT NULL);`,
+		options: func(opts *differ.Options) {
+			opts.DetectColumnRenames = true
+		},
+	},
+	{
+		name: "generated columns",
+		current: `CREATE TABLE lines (
+    price NUMERIC,
+    qty INTEGER,
+    total NUMERIC GENERATED ALWAYS AS (price * qty) STORED,
+    net NUMERIC GENERATED ALWAYS AS (price) STORED,
+    gross NUMERIC
+);`,
+		desired: `CREATE TABLE lines (
+    price NUMERIC,
+    qty INTEGER,
+    total NUMERIC GENERATED ALWAYS AS (price * qty * 2) STORED,
+    net NUMERIC,
+    gross NUMERIC GENERATED ALWAYS AS (price * 1.2) STORED
+);`,
+	},
+	{
+		name: "identity columns",
+		current: `CREATE TABLE tickets (
+    id BIGINT,
+    seq BIGINT GENERATED BY DEFAULT AS IDENTITY,
+    legacy_id BIGINT GENERATED ALWAYS AS IDENTITY
+);`,
+		desired: `CREATE TABLE tickets (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY (INCREMENT BY 5 CACHE 10),
+    legacy_id BIGINT NOT NULL
+);`,
+	},
+	{
+		name:    "table comments",
+		current: `CREATE TABLE users (id BIGINT); COMMENT ON TABLE users IS 'People';`,
+		desired: `CREATE TABLE users (id BIGINT); COMMENT ON TABLE users IS 'Accounts';`,
+	},
+	{
+		name: "constraints",
+		current: `CREATE TABLE users (
+    id BIGINT CONSTRAINT users_pkey PRIMARY KEY,
+    email TEXT,
+    age INTEGER,
+    CONSTRAINT users_age_check CHECK (age > 0),
+    CONSTRAINT users_legacy_key UNIQUE (email)
+);
+COMMENT ON CONSTRAINT users_pkey ON users IS 'Identity';`,
+		desired: `CREATE TABLE users (
+    id BIGINT CONSTRAINT users_pkey PRIMARY KEY,
+    email TEXT,
+    age INTEGER,
+    CONSTRAINT users_age_check CHECK (age >= 18),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
+COMMENT ON CONSTRAINT users_pkey ON users IS 'Surrogate key';`,
+	},
+	{
+		name: "indexes",
+		current: `CREATE TABLE users (id BIGINT, email TEXT, name TEXT);
+CREATE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_legacy ON users (id);`,
+		desired: `CREATE TABLE users (id BIGINT, email TEXT, name TEXT);
+CREATE INDEX idx_users_email ON users (lower(email));
+CREATE INDEX idx_users_name ON users (name);`,
+	},
+	{
+		name: "views",
+		current: `CREATE TABLE users (id BIGINT, active BOOLEAN);
+CREATE VIEW active_users AS SELECT id FROM users WHERE active;
+CREATE VIEW legacy_users AS SELECT id FROM users;
+CREATE VIEW secure_users AS SELECT id FROM users;
+CREATE MATERIALIZED VIEW user_counts AS SELECT count(*) AS total FROM users;
+CREATE MATERIALIZED VIEW old_counts AS SELECT count(*) AS total FROM users;`,
+		desired: `CREATE TABLE users (id BIGINT, active BOOLEAN);
+CREATE VIEW active_users AS SELECT id FROM users WHERE NOT active;
+CREATE VIEW new_users AS SELECT id FROM users;
+CREATE VIEW secure_users WITH (security_barrier) AS SELECT id FROM users;
+CREATE MATERIALIZED VIEW user_counts AS SELECT count(id) AS total FROM users;
+CREATE MATERIALIZED VIEW active_counts AS SELECT count(*) AS total FROM users WHERE active;`,
+	},
+	{
+		name: "functions",
+		current: `CREATE FUNCTION add_one(x INT) RETURNS INT LANGUAGE sql AS $$ SELECT x + 1 $$;
+CREATE FUNCTION legacy() RETURNS INT LANGUAGE sql AS $$ SELECT 1 $$;`,
+		desired: `CREATE FUNCTION add_one(x INT) RETURNS INT LANGUAGE sql AS $$ SELECT x + 2 $$;
+CREATE FUNCTION fresh() RETURNS INT LANGUAGE sql AS $$ SELECT 1 $$;`,
+	},
+	{
+		name:    "functions added and dropped",
+		current: `CREATE FUNCTION old_total() RETURNS INT LANGUAGE sql AS $$ SELECT 2 $$;`,
+		desired: `CREATE FUNCTION new_total() RETURNS INT LANGUAGE sql AS $$ SELECT 3 $$;`,
+	},
+	{
+		name:    "functions moved",
+		current: `CREATE FUNCTION check_order() RETURNS INT LANGUAGE sql AS $$ SELECT 4 $$;`,
+		desired: `CREATE SCHEMA app;
+CREATE FUNCTION app.check_order() RETURNS INT LANGUAGE sql AS $$ SELECT 4 $$;`,
+	},
+	{
+		name:    "procedures",
+		current: `CREATE PROCEDURE archive(cutoff DATE) LANGUAGE sql AS $$ SELECT 1 $$;`,
+		desired: `CREATE PROCEDURE purge(cutoff DATE) LANGUAGE sql AS $$ SELECT 2 $$;`,
+	},
+	{
+		name: "triggers",
+		current: `CREATE TABLE items (id BIGINT);
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END; $$;
+CREATE TRIGGER items_touch BEFORE INSERT ON items FOR EACH ROW EXECUTE FUNCTION touch();
+CREATE TRIGGER items_legacy BEFORE INSERT ON items FOR EACH ROW EXECUTE FUNCTION touch();`,
+		desired: `CREATE TABLE items (id BIGINT);
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END; $$;
+CREATE TRIGGER items_touch BEFORE UPDATE ON items FOR EACH ROW EXECUTE FUNCTION touch();
+CREATE TRIGGER items_audit AFTER INSERT ON items FOR EACH ROW EXECUTE FUNCTION touch();`,
+	},
+	{
+		name: "extensions",
+		current: `CREATE EXTENSION IF NOT EXISTS hstore;
+CREATE EXTENSION IF NOT EXISTS pgcrypto WITH VERSION '1.2';`,
+		desired: `CREATE EXTENSION IF NOT EXISTS citext;
+CREATE EXTENSION IF NOT EXISTS pgcrypto WITH VERSION '1.3';`,
+	},
+	{
+		name: "sequences",
+		current: `CREATE TABLE invoices (id BIGINT);
+CREATE SEQUENCE order_seq INCREMENT BY 2;
+CREATE SEQUENCE legacy_seq;`,
+		desired: `CREATE TABLE invoices (id BIGINT);
+CREATE SEQUENCE order_seq INCREMENT BY 1;
+CREATE SEQUENCE invoice_seq OWNED BY invoices.id;`,
+	},
+	{
+		name: "custom types",
+		current: `CREATE TYPE order_status AS ENUM ('pending', 'paid');
+CREATE TYPE mood AS ENUM ('happy');
+CREATE TYPE score_range AS RANGE (subtype = float8);
+COMMENT ON TYPE order_status IS 'Order lifecycle';`,
+		desired: `CREATE TYPE order_status AS ENUM ('pending', 'paid', 'shipped');
+CREATE TYPE priority AS ENUM ('low', 'high');
+CREATE TYPE score_range AS RANGE (subtype = numeric);
+COMMENT ON TYPE order_status IS 'Order states';`,
+	},
+	{
+		name:    "composite type attributes",
+		current: `CREATE TYPE address AS (street TEXT, zip INTEGER, legacy TEXT);`,
+		desired: `CREATE TYPE address AS (street TEXT, zip VARCHAR(10), city TEXT);`,
+	},
+	{
+		name: "partitions",
+		current: `CREATE TABLE events (id BIGINT, created_at DATE) PARTITION BY RANGE (created_at);
+CREATE TABLE events_2023 PARTITION OF events FOR VALUES FROM ('2023-01-01') TO ('2024-01-01');`,
+		desired: `CREATE TABLE events (id BIGINT, created_at DATE) PARTITION BY RANGE (created_at);
+CREATE TABLE events_2024 PARTITION OF events FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');`,
+	},
+	{
+		name: "hypertables",
+		current: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time', chunk_time_interval => INTERVAL '1 day');
+SELECT add_compression_policy('metrics', INTERVAL '7 days');
+SELECT add_retention_policy('metrics', INTERVAL '90 days');
+CREATE TABLE readings (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('readings', 'time');
+SELECT add_compression_policy('readings', INTERVAL '7 days');
+SELECT add_retention_policy('readings', INTERVAL '30 days');
+CREATE TABLE samples (time TIMESTAMPTZ NOT NULL);`,
+		desired: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time', chunk_time_interval => INTERVAL '7 days');
+SELECT add_compression_policy('metrics', INTERVAL '14 days');
+SELECT add_retention_policy('metrics', INTERVAL '180 days');
+CREATE TABLE readings (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('readings', 'time');
+CREATE TABLE samples (time TIMESTAMPTZ NOT NULL);
+SELECT create_hypertable('samples', 'time');
+SELECT add_compression_policy('samples', INTERVAL '1 day');
+SELECT add_retention_policy('samples', INTERVAL '10 days');`,
+	},
+	{
+		name: "hypertable dimensions and compression",
+		current: `CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL, device_id INT, site_id INT, zone_id INT
+);
+SELECT create_hypertable('metrics', 'time');
+SELECT add_dimension('metrics', 'site_id', number_partitions => 2);
+SELECT add_dimension('metrics', 'device_id', number_partitions => 4);
+ALTER TABLE metrics SET (timescaledb.compress, timescaledb.compress_segmentby = 'device_id');`,
+		desired: `CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL, device_id INT, site_id INT, zone_id INT
+);
+SELECT create_hypertable('metrics', 'time');
+SELECT add_dimension('metrics', 'device_id', number_partitions => 8);
+SELECT add_dimension('metrics', 'zone_id', number_partitions => 2);
+ALTER TABLE metrics SET (timescaledb.compress, timescaledb.compress_segmentby = 'site_id');`,
+	},
+	{
+		name:    "table recreation",
+		current: `CREATE TABLE jobs (id INTEGER, state TEXT, attempts INTEGER);`,
+		desired: `CREATE TABLE jobs (id BIGINT, state VARCHAR(20), attempts BIGINT);`,
+		options: func(opts *differ.Options) {
+			opts.TableRecreation = differ.DefaultTableRecreationThresholds()
+		},
+	},
+	{
+		name: "continuous aggregates",
+		current: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) AS avg_value FROM metrics GROUP BY bucket;
+CREATE MATERIALIZED VIEW metrics_legacy WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', time) AS bucket, max(value) AS max_value FROM metrics GROUP BY bucket;`,
+		desired: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, max(value) AS max_value FROM metrics GROUP BY bucket;
+CREATE MATERIALIZED VIEW metrics_daily WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', time) AS bucket, min(value) AS min_value FROM metrics GROUP BY bucket;`,
+	},
+	{
+		name: "refresh policies",
+		current: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) AS avg_value FROM metrics GROUP BY bucket;
+SELECT add_continuous_aggregate_policy('metrics_hourly', start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '1 hour');
+CREATE MATERIALIZED VIEW metrics_daily WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', time) AS bucket, max(value) AS max_value FROM metrics GROUP BY bucket;`,
+		desired: `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) AS avg_value FROM metrics GROUP BY bucket;
+SELECT add_continuous_aggregate_policy('metrics_hourly', start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '30 minutes');
+CREATE MATERIALIZED VIEW metrics_daily WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', time) AS bucket, max(value) AS max_value FROM metrics GROUP BY bucket;
+SELECT add_continuous_aggregate_policy('metrics_daily', start_offset => NULL,
+    end_offset => INTERVAL '1 day', schedule_interval => INTERVAL '1 day');`,
+	},
+}
+
+func TestDescriptionsGolden(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+
+	for _, tc := range descriptionCases {
+		opts := differ.DefaultOptions()
+		if tc.options != nil {
+			tc.options(opts)
+		}
+
+		result, err := differ.New(opts).Compare(
+			parseObjectSchema(t, tc.current), parseObjectSchema(t, tc.desired))
+		require.NoError(t, err, tc.name)
+
+		fmt.Fprintf(&out, "# %s\n", tc.name)
+
+		for _, change := range result.Changes {
+			fmt.Fprintf(&out, "%s: %s\n", change.Type, change.Description)
+		}
+
+		out.WriteString("\n")
+	}
+
+	got := out.String()
+
+	if *updateDescriptions {
+		require.NoError(t, os.WriteFile(descriptionsGolden, []byte(got), 0o600))
+	}
+
+	want, err := os.ReadFile(descriptionsGolden)
+	require.NoError(t, err, "run with -update to create %s", descriptionsGolden)
+	assert.Equal(t, string(want), got)
+}
@@ -0,0 +1,283 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func dropDependentsTable(columnType string) schema.Table {
+	return schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "orders",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "bigint", Position: 1},
+			{Name: "total", DataType: columnType, Position: 2},
+		},
+	}
+}
+
+func TestDiffer_RecreatesViewsOverRecreatedViews(t *testing.T) {
+	t.Parallel()
+
+	views := []schema.View{
+		{
+			Schema:     schema.DefaultSchema,
+			Name:       "order_totals",
+			Definition: "SELECT id, total FROM orders",
+		},
+		{
+			Schema:     schema.DefaultSchema,
+			Name:       "large_orders",
+			Definition: "SELECT id FROM order_totals WHERE total > 100",
+		},
+	}
+
+	current := &schema.Database{
+		Tables: []schema.Table{dropDependentsTable("integer")},
+		Views:  views,
+	}
+	desired := &schema.Database{
+		Tables: []schema.Table{dropDependentsTable("bigint")},
+		Views:  views,
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	dropOuter := changeIndex(result, differ.ChangeTypeDropView, "public.large_orders")
+	dropInner := changeIndex(result, differ.ChangeTypeDropView, "public.order_totals")
+	retype := changeIndex(result, differ.ChangeTypeModifyColumnType, "public.orders")
+	addInner := changeIndex(result, differ.ChangeTypeAddView, "public.order_totals")
+	addOuter := changeIndex(result, differ.ChangeTypeAddView, "public.large_orders")
+
+	require.NotEqual(t, -1, dropOuter, "the view over the recreated view is recreated too")
+	require.NotEqual(t, -1, addOuter)
+	assert.Less(t, dropOuter, dropInner)
+	assert.Less(t, dropInner, retype)
+	assert.Less(t, retype, addInner)
+	assert.Less(t, addInner, addOuter)
+	assert.Empty(t, result.UnhandledDropDependents())
+}
+
+func TestDiffer_RecreatesViewsOverRedefinedMaterializedView(t *testing.T) {
+	t.Parallel()
+
+	summary := schema.View{
+		Schema:     schema.DefaultSchema,
+		Name:       "summary",
+		Definition: "SELECT count(*) FROM daily",
+	}
+
+	current := &schema.Database{
+		Tables: []schema.Table{dropDependentsTable("integer")},
+		MaterializedViews: []schema.MaterializedView{{
+			Schema:     schema.DefaultSchema,
+			Name:       "daily",
+			Definition: "SELECT id, total FROM orders",
+		}},
+		Views: []schema.View{summary},
+	}
+	desired := &schema.Database{
+		Tables: []schema.Table{dropDependentsTable("integer")},
+		MaterializedViews: []schema.MaterializedView{{
+			Schema:     schema.DefaultSchema,
+			Name:       "daily",
+			Definition: "SELECT id, total FROM orders WHERE total > 0",
+		}},
+		Views: []schema.View{summary},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	dropView := changeIndex(result, differ.ChangeTypeDropView, "public.summary")
+	redefine := changeIndex(result, differ.ChangeTypeModifyMaterializedView, "public.daily")
+	addView := changeIndex(result, differ.ChangeTypeAddView, "public.summary")
+
+	require.NotEqual(t, -1, dropView)
+	require.NotEqual(t, -1, addView)
+	assert.Less(t, dropView, redefine)
+	assert.Less(t, redefine, addView)
+	assert.Empty(t, result.UnhandledDropDependents())
+}
+
+func TestDiffer_DropsFunctionAfterDefaultStopsCallingIt(t *testing.T) {
+	t.Parallel()
+
+	withDefault := func(def string) schema.Table {
+		table := dropDependentsTable("integer")
+		table.Columns[1].Default = def
+
+		return table
+	}
+
+	current := &schema.Database{
+		Tables: []schema.Table{withDefault("default_total()")},
+		Functions: []schema.Function{{
+			Schema:     schema.DefaultSchema,
+			Name:       "default_total",
+			ReturnType: "integer",
+			Language:   "sql",
+			Body:       "SELECT 0",
+		}},
+	}
+	desired := &schema.Database{Tables: []schema.Table{withDefault("0")}}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	modifyDefault := changeIndex(result, differ.ChangeTypeModifyColumnDefault, "public.orders")
+	dropFunction := changeIndex(result, differ.ChangeTypeDropFunction, "public.default_total()")
+
+	require.NotEqual(t, -1, modifyDefault)
+	require.NotEqual(t, -1, dropFunction)
+	assert.Less(t, modifyDefault, dropFunction)
+	assert.Empty(t, result.UnhandledDropDependents())
+}
+
+func TestDiffResult_UnhandledDropDependents(t *testing.T) {
+	t.Parallel()
+
+	dailyView := schema.View{
+		Schema:     schema.DefaultSchema,
+		Name:       "daily",
+		Definition: "SELECT id FROM orders",
+	}
+	totalFunction := schema.Function{
+		Schema:     schema.DefaultSchema,
+		Name:       "order_total",
+		ReturnType: "integer",
+		Language:   "sql",
+		Body:       "SELECT 1",
+	}
+
+	tests := []struct {
+		name    string
+		current *schema.Database
+		changes []differ.Change
+		want    []string
+	}{
+		{
+			name: "view over a dropped view",
+			current: &schema.Database{Views: []schema.View{dailyView, {
+				Schema:     schema.DefaultSchema,
+				Name:       "weekly",
+				Definition: "SELECT id FROM daily",
+			}}},
+			changes: []differ.Change{
+				{Type: differ.ChangeTypeDropView, ObjectType: "view", ObjectName: "public.daily"},
+			},
+			want: []string{"view public.weekly selects from view public.daily"},
+		},
+		{
+			name: "view over a dropped view that is dropped too",
+			current: &schema.Database{Views: []schema.View{dailyView, {
+				Schema:     schema.DefaultSchema,
+				Name:       "weekly",
+				Definition: "SELECT id FROM daily",
+			}}},
+			changes: []differ.Change{
+				{Type: differ.ChangeTypeDropView, ObjectType: "view", ObjectName: "public.daily"},
+				{Type: differ.ChangeTypeDropView, ObjectType: "view", ObjectName: "public.weekly"},
+			},
+		},
+		{
+			name: "view and trigger calling a dropped function",
+			current: &schema.Database{
+				Functions: []schema.Function{totalFunction},
+				Views: []schema.View{{
+					Schema:     schema.DefaultSchema,
+					Name:       "totals",
+					Definition: "SELECT order_total() AS total",
+				}},
+				Triggers: []schema.Trigger{{
+					Schema:         schema.DefaultSchema,
+					Name:           "orders_total",
+					TableName:      "orders",
+					FunctionSchema: schema.DefaultSchema,
+					FunctionName:   "order_total",
+				}},
+			},
+			changes: []differ.Change{{
+				Type:       differ.ChangeTypeDropFunction,
+				ObjectType: "function",
+				ObjectName: "public.order_total()",
+			}},
+			want: []string{
+				"view public.totals calls function public.order_total()",
+				"trigger public.orders.orders_total calls function public.order_total()",
+			},
+		},
+		{
+			name: "foreign key to a dropped table",
+			current: &schema.Database{Tables: []schema.Table{
+				{Schema: schema.DefaultSchema, Name: "customers"},
+				{
+					Schema: schema.DefaultSchema,
+					Name:   "orders",
+					Constraints: []schema.Constraint{{
+						Name:            "orders_customer_fk",
+						Type:            "FOREIGN KEY",
+						ReferencedTable: "customers",
+					}},
+				},
+			}},
+			changes: []differ.Change{{
+				Type:       differ.ChangeTypeDropTable,
+				ObjectType: "table",
+				ObjectName: "public.customers",
+			}},
+			want: []string{
+				"constraint orders_customer_fk on table public.orders references table " +
+					"public.customers",
+			},
+		},
+		{
+			name: "columns of a dropped type and sequence",
+			current: &schema.Database{Tables: []schema.Table{{
+				Schema: "app",
+				Name:   "orders",
+				Columns: []schema.Column{
+					{Name: "id", DataType: "bigint", Default: "nextval('app.order_ids'::regclass)"},
+					{Name: "states", DataType: "order_state[]"},
+				},
+			}}},
+			changes: []differ.Change{
+				{
+					Type:       differ.ChangeTypeDropCustomType,
+					ObjectType: "type",
+					ObjectName: "app.order_state",
+				},
+				{
+					Type:       differ.ChangeTypeDropSequence,
+					ObjectType: "sequence",
+					ObjectName: "app.order_ids",
+				},
+			},
+			want: []string{
+				"column app.orders.states has type app.order_state",
+				"column app.orders.id draws its default from sequence app.order_ids",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := &differ.DiffResult{Current: tt.current, Changes: tt.changes}
+
+			var got []string
+			for _, dep := range result.UnhandledDropDependents() {
+				got = append(got, dep.String())
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
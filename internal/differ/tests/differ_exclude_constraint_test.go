@@ -0,0 +1,121 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// extractedExcludeSchema holds the bookings table as the extractor reads it,
+// with the parts of its EXCLUDE constraint as PostgreSQL prints them.
+func extractedExcludeSchema() *schema.Database {
+	const timestamptz = "timestamp with time zone"
+
+	constraint := schema.Constraint{
+		Name:          "bookings_no_overlap",
+		Type:          schema.ConstraintExclude,
+		ExcludeMethod: "gist",
+		ExcludeElements: []schema.ExcludeElement{
+			{Element: "room", Operator: "="},
+			{Element: "tstzrange(starts_at, ends_at)", Operator: "&&"},
+		},
+		ExcludeWhere: "active",
+	}
+	constraint.Definition = constraint.ExcludeDefinition()
+
+	return &schema.Database{Tables: []schema.Table{{
+		Schema: schema.DefaultSchema,
+		Name:   "bookings",
+		Columns: []schema.Column{
+			{Name: "room", DataType: "integer", IsNullable: true, Position: 1},
+			{Name: "starts_at", DataType: timestamptz, IsNullable: true, Position: 2},
+			{Name: "ends_at", DataType: timestamptz, IsNullable: true, Position: 3},
+			{Name: "active", DataType: "boolean", IsNullable: true, Position: 4},
+		},
+		Constraints: []schema.Constraint{constraint},
+	}}}
+}
+
+func parseExcludeSchema(t *testing.T, constraint string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(`CREATE TABLE bookings (
+    room INTEGER,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    active BOOLEAN,
+    `+constraint+`
+);`, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func TestDiffer_ExcludeConstraintParts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		desired string
+		changed bool
+	}{
+		{
+			name: "same constraint written differently",
+			desired: `CONSTRAINT bookings_no_overlap EXCLUDE USING GIST (
+        "room" WITH =,
+        TSTZRANGE(starts_at,   ends_at) WITH OPERATOR(pg_catalog.&&)
+    ) WHERE ((active))`,
+		},
+		{
+			name: "operator changed",
+			desired: "CONSTRAINT bookings_no_overlap EXCLUDE USING gist " +
+				"(room WITH =, tstzrange(starts_at, ends_at) WITH -|-) WHERE (active)",
+			changed: true,
+		},
+		{
+			name: "predicate removed",
+			desired: "CONSTRAINT bookings_no_overlap EXCLUDE USING gist " +
+				"(room WITH =, tstzrange(starts_at, ends_at) WITH &&)",
+			changed: true,
+		},
+		{
+			name: "method changed",
+			desired: "CONSTRAINT bookings_no_overlap EXCLUDE USING spgist " +
+				"(room WITH =, tstzrange(starts_at, ends_at) WITH &&) WHERE (active)",
+			changed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				extractedExcludeSchema(), parseExcludeSchema(t, tt.desired))
+			require.NoError(t, err)
+
+			var changes []differ.Change
+
+			for _, change := range result.Changes {
+				if change.ObjectType == "constraint" {
+					changes = append(changes, change)
+				}
+			}
+
+			if !tt.changed {
+				assert.Empty(t, changes)
+				return
+			}
+
+			require.Len(t, changes, 1)
+			assert.Equal(t, differ.ChangeTypeModifyConstraint, changes[0].Type)
+		})
+	}
+}
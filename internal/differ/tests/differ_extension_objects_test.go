@@ -0,0 +1,98 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func materializationTable(schemaName, name string) schema.Table {
+	return schema.Table{
+		Schema: schemaName,
+		Name:   name,
+		Columns: []schema.Column{
+			{Name: "bucket", DataType: "timestamp with time zone", Position: 1},
+		},
+	}
+}
+
+func TestDiffer_SkipsMaterializationTablesOfContinuousAggregates(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Tables: []schema.Table{
+			materializationTable("analytics", "_materialized_hypertable_4"),
+			materializationTable("analytics", "_materialized_hypertable_40"),
+		},
+		ContinuousAggregates: []schema.ContinuousAggregate{{
+			Schema:           "analytics",
+			ViewName:         "weekly",
+			HypertableSchema: "analytics",
+			HypertableName:   "_materialized_hypertable_4",
+			Query: "SELECT time_bucket('7 days', bucket) AS bucket " +
+				"FROM daily GROUP BY 1",
+			Materialized: true,
+		}},
+	}
+	desired := &schema.Database{ContinuousAggregates: current.ContinuousAggregates}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1, "only a table no aggregate reads from is dropped")
+
+	assert.Equal(t, differ.ChangeTypeDropTable, result.Changes[0].Type)
+	assert.Equal(t, "analytics._materialized_hypertable_40", result.Changes[0].ObjectName)
+	assert.Contains(t, result.Notes, "skipped 1 managed-by-extension objects: "+
+		"TimescaleDB internal schemas and continuous aggregate materialization tables")
+}
+
+func TestDiffer_MaterializationTableReadByQuery(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Tables: []schema.Table{materializationTable("reporting", "_materialized_hypertable_7")},
+	}
+	desired := &schema.Database{
+		ContinuousAggregates: []schema.ContinuousAggregate{{
+			Schema:         "reporting",
+			ViewName:       "monthly",
+			HypertableName: "events",
+			Query: "SELECT time_bucket('1 month', bucket) AS bucket " +
+				"FROM reporting._materialized_hypertable_7 GROUP BY 1",
+			Materialized: true,
+		}},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []differ.ChangeType{differ.ChangeTypeAddContinuousAggregate},
+		changeTypes(result))
+}
+
+func TestDiffer_IncludeExtensionObjects(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Schemas: []schema.Schema{{Name: "_timescaledb_internal"}},
+		Tables:  []schema.Table{materializationTable("_timescaledb_internal", "_hyper_1_1_chunk")},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, &schema.Database{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+
+	opts := differ.DefaultOptions()
+	opts.IncludeExtensionObjects = true
+
+	result, err = differ.New(opts).Compare(current, &schema.Database{})
+	require.NoError(t, err)
+	assert.Equal(t, []differ.ChangeType{differ.ChangeTypeDropTable, differ.ChangeTypeDropSchema},
+		changeTypes(result))
+	assert.Empty(t, result.Notes)
+	assert.NotEqual(t, differ.DefaultOptions().Hash(), opts.Hash())
+}
@@ -0,0 +1,164 @@
+package differ_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseFKSchemaDir(t *testing.T, files map[string]string) *schema.Database {
+	t.Helper()
+
+	dir := t.TempDir()
+	tablesDir := filepath.Join(dir, "tables")
+	require.NoError(t, os.Mkdir(tablesDir, 0o755))
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tablesDir, name), []byte(content), 0o644))
+	}
+
+	result, err := parser.New().ParseDirectory(dir)
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+
+	return result.Database
+}
+
+func addTableOrder(result *differ.DiffResult) []string {
+	var tables []string
+
+	for _, change := range result.Changes {
+		if change.Type == differ.ChangeTypeAddTable {
+			tables = append(tables, change.ObjectName)
+		}
+	}
+
+	return tables
+}
+
+func foreignKeys(table *schema.Table) []schema.Constraint {
+	var fks []schema.Constraint
+
+	for _, constraint := range table.Constraints {
+		if constraint.IsForeignKey() {
+			fks = append(fks, constraint)
+		}
+	}
+
+	return fks
+}
+
+func TestForeignKeyOrdering_ChainAcrossFiles(t *testing.T) {
+	t.Parallel()
+
+	// Files and table names both sort opposite to the dependency order.
+	desired := parseFKSchemaDir(t, map[string]string{
+		"01_line_items.sql": `CREATE TABLE alpha_line_items (
+    id BIGINT PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES beta_orders(id)
+);`,
+		"02_orders.sql": `CREATE TABLE beta_orders (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT NOT NULL REFERENCES gamma_customers(id)
+);`,
+		"03_customers.sql": `CREATE TABLE gamma_customers (
+    id BIGINT PRIMARY KEY
+);`,
+	})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"public.gamma_customers",
+		"public.beta_orders",
+		"public.alpha_line_items",
+	}, addTableOrder(result))
+	assert.NotContains(t, changeTypes(result), differ.ChangeTypeAddConstraint)
+	assert.Empty(t, result.Notes)
+}
+
+func TestForeignKeyOrdering_SelfReferenceStaysInline(t *testing.T) {
+	t.Parallel()
+
+	desired := parseFKSchemaDir(t, map[string]string{
+		"nodes.sql": `CREATE TABLE nodes (
+    id BIGINT PRIMARY KEY,
+    parent_id BIGINT REFERENCES nodes(id)
+);`,
+		"edges.sql": `CREATE TABLE edges (
+    id BIGINT PRIMARY KEY,
+    node_id BIGINT REFERENCES nodes(id)
+);`,
+	})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"public.nodes", "public.edges"}, addTableOrder(result))
+	assert.NotContains(t, changeTypes(result), differ.ChangeTypeAddConstraint)
+
+	nodesIdx := changeIndex(result, differ.ChangeTypeAddTable, "public.nodes")
+	table, ok := result.Changes[nodesIdx].Details["table"].(*schema.Table)
+	require.True(t, ok)
+	assert.Len(t, foreignKeys(table), 1)
+}
+
+func TestForeignKeyOrdering_MutualCycleDefersConstraint(t *testing.T) {
+	t.Parallel()
+
+	desired := parseFKSchemaDir(t, map[string]string{
+		"cycle.sql": `CREATE TABLE departments (
+    id BIGINT PRIMARY KEY,
+    manager_id BIGINT REFERENCES employees(id)
+);
+
+CREATE TABLE employees (
+    id BIGINT PRIMARY KEY,
+    department_id BIGINT REFERENCES departments(id)
+);`,
+	})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	require.Equal(t, []differ.ChangeType{
+		differ.ChangeTypeAddTable,
+		differ.ChangeTypeAddTable,
+		differ.ChangeTypeAddConstraint,
+	}, changeTypes(result))
+	assert.Equal(t, []string{"public.departments", "public.employees"}, addTableOrder(result))
+
+	departments, ok := result.Changes[0].Details["table"].(*schema.Table)
+	require.True(t, ok)
+	assert.Empty(t, foreignKeys(departments), "deferred FK must be stripped from CREATE TABLE")
+	assert.Empty(t, result.Changes[0].DependsOn)
+
+	employees, ok := result.Changes[1].Details["table"].(*schema.Table)
+	require.True(t, ok)
+	assert.Len(t, foreignKeys(employees), 1)
+
+	deferred := result.Changes[2]
+	assert.Equal(t, "public.departments", deferred.ObjectName)
+	assert.Equal(t, []string{"public.employees"}, deferred.DependsOn)
+
+	constraint, ok := deferred.Details["constraint"].(*schema.Constraint)
+	require.True(t, ok)
+	assert.Equal(t, "employees", constraint.ReferencedTable)
+
+	require.Len(t, result.Notes, 1)
+	assert.Contains(t, result.Notes[0], "public.departments, public.employees")
+
+	idx := slices.IndexFunc(desired.Tables, func(table schema.Table) bool {
+		return table.Name == "departments"
+	})
+	assert.Len(t, foreignKeys(&desired.Tables[idx]), 1, "desired schema must not be modified")
+}
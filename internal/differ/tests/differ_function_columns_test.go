@@ -0,0 +1,163 @@
+package differ_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const functionColumnsBodies = `
+CREATE TABLE customers (id BIGINT, total NUMERIC);
+
+CREATE FUNCTION orders_touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.total := coalesce(NEW.total, 0);
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER orders_touch BEFORE INSERT ON orders
+    FOR EACH ROW EXECUTE FUNCTION orders_touch();
+
+CREATE FUNCTION customers_touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.total := 0; -- orders.total is not touched here
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER customers_touch BEFORE INSERT ON customers
+    FOR EACH ROW EXECUTE FUNCTION customers_touch();
+
+CREATE FUNCTION reset_orders() RETURNS void LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE public.orders SET total = 0 WHERE id > 0;
+END;
+$$;
+
+CREATE FUNCTION order_total(order_id BIGINT) RETURNS NUMERIC LANGUAGE plpgsql AS $$
+DECLARE
+    result NUMERIC;
+BEGIN
+    SELECT o.total INTO result FROM orders AS o WHERE o.id = order_id;
+    RETURN result;
+END;
+$$;
+
+CREATE FUNCTION reset_customers() RETURNS void LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE customers SET total = 0;
+    SELECT c.total FROM customers c JOIN orders o ON o.id = c.id;
+END;
+$$;
+
+CREATE FUNCTION reset_dynamic() RETURNS void LANGUAGE plpgsql AS $$
+BEGIN
+    EXECUTE 'UPDATE orders SET total = 0';
+END;
+$$;
+`
+
+func parseFunctionColumnsSchema(t *testing.T, ordersColumns string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(
+		"CREATE TABLE orders (id BIGINT, "+ordersColumns+");\n"+functionColumnsBodies, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func functionColumnWarnings(result *differ.DiffResult) []diag.Warning {
+	var warnings []diag.Warning
+
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodeFunctionColumnReference {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return warnings
+}
+
+func TestDiffer_AnalyzeFunctionBodies(t *testing.T) {
+	t.Parallel()
+
+	current := parseFunctionColumnsSchema(t, "total NUMERIC, note TEXT")
+
+	tests := []struct {
+		name       string
+		columns    string
+		changeType differ.ChangeType
+		want       string
+	}{
+		{
+			name:       "dropped column",
+			columns:    "note TEXT",
+			changeType: differ.ChangeTypeDropColumn,
+			want: "column public.orders.total is dropped, but the body of " +
+				"public.order_total, public.orders_touch, public.reset_orders appears to use it",
+		},
+		{
+			name:       "retyped column",
+			columns:    "total BIGINT, note TEXT",
+			changeType: differ.ChangeTypeModifyColumnType,
+			want: "column public.orders.total is changing type, but the body of " +
+				"public.order_total, public.orders_touch, public.reset_orders appears to use it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := differ.DefaultOptions()
+			opts.AnalyzeFunctionBodies = true
+
+			result, err := differ.New(opts).Compare(
+				current, parseFunctionColumnsSchema(t, tt.columns))
+			require.NoError(t, err)
+
+			warnings := functionColumnWarnings(result)
+			require.Len(t, warnings, 1)
+			assert.Equal(t, diag.SeverityWarning, warnings[0].Severity)
+			assert.Equal(t, string(tt.changeType), warnings[0].ChangeType)
+			assert.Equal(t, "public.orders", warnings[0].ObjectName)
+			assert.True(t, strings.HasPrefix(warnings[0].Message, tt.want), warnings[0].Message)
+			assert.Contains(t, warnings[0].Message, "(heuristic)")
+		})
+	}
+}
+
+func TestDiffer_AnalyzeFunctionBodiesIgnoresUnusedColumns(t *testing.T) {
+	t.Parallel()
+
+	current := parseFunctionColumnsSchema(t, "total NUMERIC, note TEXT")
+	desired := parseFunctionColumnsSchema(t, "total NUMERIC")
+
+	opts := differ.DefaultOptions()
+	opts.AnalyzeFunctionBodies = true
+
+	result, err := differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Empty(t, functionColumnWarnings(result))
+}
+
+func TestDiffer_FunctionBodiesNotAnalyzedByDefault(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		parseFunctionColumnsSchema(t, "total NUMERIC, note TEXT"),
+		parseFunctionColumnsSchema(t, "note TEXT"),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, functionColumnWarnings(result))
+}
@@ -0,0 +1,246 @@
+package differ_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestFunctionDependencies(t *testing.T) {
+	t.Parallel()
+
+	usersTable := schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   users,
+		Columns: []schema.Column{
+			{Name: "id", DataType: "bigint", Position: 1},
+		},
+	}
+
+	computeScore := schema.Function{
+		Schema:        schema.DefaultSchema,
+		Name:          "compute_score",
+		ArgumentTypes: []string{"users"},
+		ReturnType:    "integer",
+		Language:      "sql",
+		Body:          "SELECT count(*)::integer FROM users",
+	}
+
+	nextInvoiceNumber := schema.Function{
+		Schema:     "billing",
+		Name:       "next_invoice_number",
+		ReturnType: "bigint",
+		Language:   "sql",
+		Body:       "SELECT nextval('billing.invoice_seq')",
+	}
+
+	tests := []struct {
+		name      string
+		desired   *schema.Database
+		dependent differ.ChangeType
+		object    string
+		functions []string
+	}{
+		{
+			name: "view calling declared function",
+			desired: &schema.Database{
+				Tables:    []schema.Table{usersTable},
+				Functions: []schema.Function{computeScore},
+				Views: []schema.View{{
+					Schema:     schema.DefaultSchema,
+					Name:       "user_scores",
+					Definition: "SELECT u.id, compute_score(u.*) AS score FROM users u",
+				}},
+			},
+			dependent: differ.ChangeTypeAddView,
+			object:    "public.user_scores",
+			functions: []string{"public.compute_score(users)"},
+		},
+		{
+			name: "view calling every overload",
+			desired: &schema.Database{
+				Tables: []schema.Table{usersTable},
+				Functions: []schema.Function{
+					computeScore,
+					{
+						Schema:        schema.DefaultSchema,
+						Name:          "compute_score",
+						ArgumentTypes: []string{"bigint"},
+						ReturnType:    "integer",
+						Language:      "sql",
+						Body:          "SELECT 1",
+					},
+				},
+				Views: []schema.View{{
+					Schema:     schema.DefaultSchema,
+					Name:       "user_scores",
+					Definition: "SELECT id, public.compute_score(id) AS score FROM users",
+				}},
+			},
+			dependent: differ.ChangeTypeAddView,
+			object:    "public.user_scores",
+			functions: []string{"public.compute_score(bigint)", "public.compute_score(users)"},
+		},
+		{
+			name: "column default calling declared function",
+			desired: &schema.Database{
+				Schemas:   []schema.Schema{{Name: "billing"}},
+				Functions: []schema.Function{nextInvoiceNumber},
+				Tables: []schema.Table{{
+					Schema: "billing",
+					Name:   "invoices",
+					Columns: []schema.Column{
+						{
+							Name:     "number",
+							DataType: "bigint",
+							Position: 1,
+							Default:  "next_invoice_number()",
+						},
+					},
+				}},
+			},
+			dependent: differ.ChangeTypeAddTable,
+			object:    "billing.invoices",
+			functions: []string{"billing.next_invoice_number()"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, tt.desired)
+			require.NoError(t, err)
+
+			dependentIdx := changeIndex(result, tt.dependent, tt.object)
+			require.NotEqual(t, -1, dependentIdx, "%s %s not found", tt.dependent, tt.object)
+
+			for _, fn := range tt.functions {
+				assert.Contains(t, result.Changes[dependentIdx].DependsOn, fn)
+
+				fnIdx := changeIndex(result, differ.ChangeTypeAddFunction, fn)
+				require.NotEqual(t, -1, fnIdx, "function %s not found", fn)
+				assert.Less(t, fnIdx, dependentIdx, "function %s must be created first", fn)
+			}
+		})
+	}
+}
+
+func TestFunctionDependencies_AddedColumnDefault(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Tables: []schema.Table{{
+			Schema:  schema.DefaultSchema,
+			Name:    "orders",
+			Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+		}},
+	}
+
+	desired := &schema.Database{
+		Functions: []schema.Function{{
+			Schema:     schema.DefaultSchema,
+			Name:       "gen_order_code",
+			ReturnType: "text",
+			Language:   "sql",
+			Body:       "SELECT md5(random()::text)",
+		}},
+		Tables: []schema.Table{{
+			Schema: schema.DefaultSchema,
+			Name:   "orders",
+			Columns: []schema.Column{
+				{Name: "id", DataType: "bigint", Position: 1},
+				{Name: "code", DataType: "text", Position: 2, Default: "gen_order_code()"},
+			},
+		}},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	columnIdx := changeIndex(result, differ.ChangeTypeAddColumn, "public.orders")
+	fnIdx := changeIndex(result, differ.ChangeTypeAddFunction, "public.gen_order_code()")
+
+	require.NotEqual(t, -1, columnIdx)
+	require.NotEqual(t, -1, fnIdx)
+	assert.Less(t, fnIdx, columnIdx)
+}
+
+func TestFunctionDependencies_NoEdgeForBuiltins(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{
+		Functions: []schema.Function{{
+			Schema:     schema.DefaultSchema,
+			Name:       "touch_updated_at",
+			ReturnType: "trigger",
+			Language:   "plpgsql",
+			Body:       "BEGIN NEW.updated_at = now(); RETURN NEW; END;",
+		}},
+		Tables: []schema.Table{{
+			Schema: schema.DefaultSchema,
+			Name:   "events",
+			Columns: []schema.Column{
+				{Name: "id", DataType: "uuid", Position: 1, Default: "gen_random_uuid()"},
+				{Name: "created_at", DataType: "timestamptz", Position: 2, Default: "now()"},
+				{Name: "label", DataType: "text", Position: 3, Default: "'touch_updated_at()'"},
+			},
+		}},
+		Views: []schema.View{{
+			Schema:     schema.DefaultSchema,
+			Name:       "recent_events",
+			Definition: "SELECT id, lower(label) AS label FROM events WHERE created_at > now()",
+		}},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	for _, change := range result.Changes {
+		for _, dep := range change.DependsOn {
+			assert.NotContains(t, dep, "(", "%s %s has function edge %s",
+				change.Type, change.ObjectName, dep)
+		}
+	}
+}
+
+func TestFunctionDependencies_FunctionBodyDoesNotCycle(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{
+		Functions: []schema.Function{{
+			Schema:     schema.DefaultSchema,
+			Name:       "next_ticket",
+			ReturnType: "bigint",
+			Language:   "sql",
+			Body:       "SELECT coalesce(max(number), 0) + 1 FROM tickets",
+		}},
+		Tables: []schema.Table{{
+			Schema: schema.DefaultSchema,
+			Name:   "tickets",
+			Columns: []schema.Column{
+				{Name: "number", DataType: "bigint", Position: 1, Default: "next_ticket()"},
+			},
+		}},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	assert.Less(
+		t,
+		changeIndex(result, differ.ChangeTypeAddFunction, "public.next_ticket()"),
+		changeIndex(result, differ.ChangeTypeAddTable, "public.tickets"),
+	)
+}
+
+func changeIndex(result *differ.DiffResult, changeType differ.ChangeType, object string) int {
+	return slices.IndexFunc(result.Changes, func(change differ.Change) bool {
+		return change.Type == changeType && change.ObjectName == object
+	})
+}
@@ -0,0 +1,169 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func relocationFunction(schemaName, name string) schema.Function {
+	return schema.Function{
+		Schema:     schemaName,
+		Name:       name,
+		ReturnType: "trigger",
+		Language:   "plpgsql",
+		Body:       "BEGIN IF NEW.total < 0 THEN RAISE 'negative'; END IF; RETURN NEW; END;",
+		Volatility: schema.VolatilityVolatile,
+	}
+}
+
+func relocationTrigger(functionSchema, functionName string) schema.Trigger {
+	return schema.Trigger{
+		Schema:         schema.DefaultSchema,
+		Name:           "orders_validate",
+		TableName:      "orders",
+		Timing:         "BEFORE",
+		Events:         []string{"INSERT"},
+		ForEachRow:     true,
+		FunctionSchema: functionSchema,
+		FunctionName:   functionName,
+	}
+}
+
+func TestDiffer_MovesFunctionBetweenSchemas(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Functions: []schema.Function{relocationFunction(schema.DefaultSchema, "validate_order")},
+		Triggers:  []schema.Trigger{relocationTrigger(schema.DefaultSchema, "validate_order")},
+	}
+	desired := &schema.Database{
+		Functions: []schema.Function{relocationFunction("app", "validate_order")},
+		Triggers:  []schema.Trigger{relocationTrigger("app", "validate_order")},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1, "the trigger follows the function")
+
+	change := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeModifyFunction, change.Type)
+	assert.Equal(t, "app.validate_order()", change.ObjectName)
+	assert.Equal(t, true, change.Details["relocated"])
+	assert.Contains(t, change.Description, "is in schema app in desired schema")
+}
+
+func TestDiffer_RecreatesTriggerOnlyForOtherChanges(t *testing.T) {
+	t.Parallel()
+
+	trigger := relocationTrigger("app", "validate_order")
+	trigger.Events = []string{"INSERT", "UPDATE"}
+
+	current := &schema.Database{
+		Functions: []schema.Function{relocationFunction(schema.DefaultSchema, "validate_order")},
+		Triggers:  []schema.Trigger{relocationTrigger(schema.DefaultSchema, "validate_order")},
+	}
+	desired := &schema.Database{
+		Functions: []schema.Function{relocationFunction("app", "validate_order")},
+		Triggers:  []schema.Trigger{trigger},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	move := changeIndex(result, differ.ChangeTypeModifyFunction, "app.validate_order()")
+	modify := changeIndex(result, differ.ChangeTypeModifyTrigger, "public.orders.orders_validate")
+
+	require.NotEqual(t, -1, move)
+	require.NotEqual(t, -1, modify)
+	assert.Less(t, move, modify)
+}
+
+func TestDiffer_FunctionRenames(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Functions: []schema.Function{relocationFunction(schema.DefaultSchema, "validate_order")},
+	}
+	desired := &schema.Database{
+		Functions: []schema.Function{relocationFunction(schema.DefaultSchema, "check_order")},
+	}
+
+	t.Run("detected", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+		require.NoError(t, err)
+		require.Len(t, result.Changes, 1)
+		assert.Equal(t, differ.ChangeTypeModifyFunction, result.Changes[0].Type)
+		assert.Contains(t, result.Changes[0].Description, "is named check_order in desired schema")
+	})
+
+	t.Run("without rename detection", func(t *testing.T) {
+		t.Parallel()
+
+		opts := differ.DefaultOptions()
+		opts.DetectRenames = false
+
+		result, err := differ.New(opts).Compare(current, desired)
+		require.NoError(t, err)
+		assert.NotEqual(t, -1,
+			changeIndex(result, differ.ChangeTypeDropFunction, "public.validate_order()"))
+		assert.NotEqual(t, -1,
+			changeIndex(result, differ.ChangeTypeAddFunction, "public.check_order()"))
+	})
+}
+
+func TestDiffer_DoesNotRelocateChangedOrAmbiguousFunctions(t *testing.T) {
+	t.Parallel()
+
+	changed := relocationFunction("app", "validate_order")
+	changed.Body = "BEGIN RETURN NEW; END;"
+
+	tests := []struct {
+		name    string
+		current []schema.Function
+		desired []schema.Function
+	}{
+		{
+			name:    "body changed",
+			current: []schema.Function{relocationFunction(schema.DefaultSchema, "validate_order")},
+			desired: []schema.Function{changed},
+		},
+		{
+			name:    "moved and renamed",
+			current: []schema.Function{relocationFunction(schema.DefaultSchema, "validate_order")},
+			desired: []schema.Function{relocationFunction("app", "check_order")},
+		},
+		{
+			name:    "two candidates",
+			current: []schema.Function{relocationFunction(schema.DefaultSchema, "validate_order")},
+			desired: []schema.Function{
+				relocationFunction("app", "validate_order"),
+				relocationFunction("billing", "validate_order"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				&schema.Database{Functions: tt.current},
+				&schema.Database{Functions: tt.desired},
+			)
+			require.NoError(t, err)
+			assert.NotEqual(t, -1,
+				changeIndex(result, differ.ChangeTypeDropFunction, "public.validate_order()"))
+
+			for i := range result.Changes {
+				assert.Nil(t, result.Changes[i].Details["relocated"])
+			}
+		})
+	}
+}
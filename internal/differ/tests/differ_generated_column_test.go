@@ -0,0 +1,124 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func compareGenerated(t *testing.T, current, desired string) *differ.DiffResult {
+	t.Helper()
+
+	parse := func(total string) *schema.Database {
+		db := &schema.Database{}
+		p := parser.New()
+		require.NoError(t, p.ParseSQL(
+			"CREATE TABLE order_lines (price NUMERIC NOT NULL, qty INTEGER, total "+total+");",
+			db))
+		require.Empty(t, p.GetErrors())
+
+		return db
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(parse(current), parse(desired))
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestDiffer_GeneratedColumnExpressionsAreNormalized(t *testing.T) {
+	t.Parallel()
+
+	// PostgreSQL stores the expression parenthesized, with implicit casts.
+	result := compareGenerated(t,
+		"NUMERIC GENERATED ALWAYS AS ((price * (qty)::numeric)) STORED",
+		"NUMERIC GENERATED ALWAYS AS (price * qty) STORED",
+	)
+
+	assert.Empty(t, result.Changes)
+}
+
+func TestDiffer_ChangedGenerationExpressionReaddsColumn(t *testing.T) {
+	t.Parallel()
+
+	result := compareGenerated(t,
+		"NUMERIC GENERATED ALWAYS AS (price * qty) STORED",
+		"BIGINT GENERATED ALWAYS AS (price * qty * 100) STORED",
+	)
+
+	require.Len(t, result.Changes, 1, "the re-added column takes its new type with it")
+
+	change := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeModifyColumnGeneration, change.Type)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, change.Severity)
+	assert.Equal(t, "price * qty", change.Details["old_expression"])
+	assert.Equal(t, "price * qty * 100", change.Details["new_expression"])
+	assert.Equal(t, "Column generation expression differs: public.order_lines.total is "+
+		"price * qty in database, price * qty * 100 in desired schema "+
+		"(column will be dropped and re-added)", change.Description)
+	assert.Equal(t, 1, result.PlanStats().UnsafeChanges)
+}
+
+func TestDiffer_PlainColumnBecomesGenerated(t *testing.T) {
+	t.Parallel()
+
+	result := compareGenerated(t, "NUMERIC", "NUMERIC GENERATED ALWAYS AS (price * qty) STORED")
+
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifyColumnGeneration, result.Changes[0].Type)
+	assert.Equal(t, differ.SeverityDataMigrationRequired, result.Changes[0].Severity)
+}
+
+func TestDiffer_GeneratedColumnBecomesPlain(t *testing.T) {
+	t.Parallel()
+
+	result := compareGenerated(t,
+		"NUMERIC GENERATED ALWAYS AS (price * qty) STORED",
+		"NUMERIC NOT NULL DEFAULT 0",
+	)
+
+	require.Len(t, result.Changes, 3)
+
+	assert.Equal(t, differ.ChangeTypeModifyColumnGeneration, result.Changes[0].Type,
+		"the expression is dropped before the default is set")
+	assert.Equal(t, differ.SeveritySafe, result.Changes[0].Severity)
+	assert.Equal(t, differ.ChangeTypeModifyColumnDefault, result.Changes[1].Type)
+	assert.Equal(t, differ.ChangeTypeModifyColumnNullability, result.Changes[2].Type)
+}
+
+func TestDiffer_GeneratedColumnIsAddedAfterColumnsItReads(t *testing.T) {
+	t.Parallel()
+
+	parse := func(sql string) *schema.Database {
+		db := &schema.Database{}
+		require.NoError(t, parser.New().ParseSQL(sql, db))
+
+		return db
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		parse("CREATE TABLE events (id BIGINT);"),
+		parse(`CREATE TABLE events (
+    id BIGINT,
+    label TEXT GENERATED ALWAYS AS (upper(coalesce(source, 'x,y'))) STORED,
+    source TEXT
+);`),
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+
+	columns := make([]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		require.Equal(t, differ.ChangeTypeAddColumn, change.Type)
+
+		column, _ := change.Details["column"].(*schema.Column)
+		columns = append(columns, column.Name)
+	}
+
+	assert.Equal(t, []string{"source", "label"}, columns)
+}
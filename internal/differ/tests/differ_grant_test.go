@@ -0,0 +1,220 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func grantsTable() schema.Table {
+	return schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "orders",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "bigint", Position: 1},
+			{Name: "total", DataType: "numeric", Position: 2},
+		},
+	}
+}
+
+func tableGrant(grantee string, withOption bool, privileges ...string) schema.Grant {
+	return schema.Grant{
+		Schema:          schema.DefaultSchema,
+		ObjectName:      "orders",
+		ObjectType:      schema.GrantObjectTable,
+		Grantee:         grantee,
+		Privileges:      privileges,
+		WithGrantOption: withOption,
+	}
+}
+
+func grantsDatabase(grants ...schema.Grant) *schema.Database {
+	return &schema.Database{Tables: []schema.Table{grantsTable()}, Grants: grants}
+}
+
+func grantOfChange(t *testing.T, change differ.Change) *schema.Grant {
+	t.Helper()
+
+	grant, ok := change.Details["grant"].(*schema.Grant)
+	require.True(t, ok)
+
+	return grant
+}
+
+func TestDiffer_GrantsOfNewTableFollowIt(t *testing.T) {
+	t.Parallel()
+
+	desired := grantsDatabase(tableGrant("reader", false, "SELECT"))
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+
+	assert.Equal(t, differ.ChangeTypeAddTable, result.Changes[0].Type)
+	assert.Equal(t, differ.ChangeTypeAddGrant, result.Changes[1].Type)
+	assert.Equal(t, differ.SeveritySafe, result.Changes[1].Severity)
+	assert.Equal(t, []string{"public.orders"}, result.Changes[1].DependsOn)
+	assert.Equal(t, "Grant of SELECT to reader on public.orders is in desired schema but not in database (will be granted)",
+		result.Changes[1].Description)
+}
+
+func TestDiffer_GrantChanges(t *testing.T) {
+	t.Parallel()
+
+	current := grantsDatabase(
+		tableGrant("app", false, "INSERT", "SELECT", "DELETE"),
+		tableGrant("app", true, "UPDATE"),
+	)
+	desired := grantsDatabase(
+		tableGrant("app", false, "SELECT", "UPDATE"),
+		tableGrant("app", true, "INSERT", "TRUNCATE"),
+	)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	added := changesOfType(result, differ.ChangeTypeAddGrant)
+	require.Len(t, added, 2)
+	assert.Equal(t, []string{"TRUNCATE"}, grantOfChange(t, added[0]).Privileges)
+	assert.True(t, grantOfChange(t, added[0]).WithGrantOption)
+	assert.Equal(t, false, added[0].Details[differ.DetailKeyGrantOptionOnly])
+	assert.Equal(t, []string{"INSERT"}, grantOfChange(t, added[1]).Privileges)
+	assert.Equal(t, true, added[1].Details[differ.DetailKeyGrantOptionOnly])
+
+	revoked := changesOfType(result, differ.ChangeTypeRevokeGrant)
+	require.Len(t, revoked, 2)
+	assert.Equal(t, []string{"DELETE"}, grantOfChange(t, revoked[0]).Privileges)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, revoked[0].Severity)
+	assert.Equal(t, []string{"UPDATE"}, grantOfChange(t, revoked[1]).Privileges)
+	assert.Equal(t, true, revoked[1].Details[differ.DetailKeyGrantOptionOnly])
+	assert.Equal(t, "Grant option of UPDATE to app on public.orders exists in database "+
+		"but not in desired schema (will be revoked)", revoked[1].Description)
+}
+
+func TestDiffer_GrantsOfUnmanagedGranteesAreKept(t *testing.T) {
+	t.Parallel()
+
+	current := grantsDatabase(
+		tableGrant(schema.GrantRolePublic, false, "SELECT"),
+		tableGrant("legacy", false, "SELECT", "UPDATE"),
+		tableGrant("reader", false, "SELECT"),
+	)
+
+	assertNoChanges(t, current, grantsDatabase(tableGrant("reader", false, "SELECT")))
+	assertNoChanges(t, current, grantsDatabase())
+}
+
+func TestDiffer_RevokeDeclaredForGrantee(t *testing.T) {
+	t.Parallel()
+
+	current := grantsDatabase(tableGrant(schema.GrantRolePublic, false, "SELECT"))
+	desired := grantsDatabase(tableGrant("PUBLIC", false))
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+
+	assert.Equal(t, differ.ChangeTypeRevokeGrant, result.Changes[0].Type)
+	assert.Equal(t, "Grant of SELECT to public on public.orders exists in database "+
+		"but not in desired schema (will be revoked)", result.Changes[0].Description)
+}
+
+func TestDiffer_ColumnGrants(t *testing.T) {
+	t.Parallel()
+
+	column := tableGrant("reader", false, "SELECT")
+	column.ObjectType = schema.GrantObjectColumn
+	column.Column = "total"
+
+	desired := grantsDatabase(column)
+	desired.Tables[0].Columns = append(desired.Tables[0].Columns,
+		schema.Column{Name: "status", DataType: "text", Position: 3})
+
+	status := column
+	status.Column = "status"
+	desired.Grants = append(desired.Grants, status)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(grantsDatabase(), desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 3)
+
+	assert.Equal(t, differ.ChangeTypeAddColumn, result.Changes[0].Type)
+	assert.Equal(t, differ.ChangeTypeAddGrant, result.Changes[1].Type)
+	assert.Equal(t, "status", grantOfChange(t, result.Changes[1]).Column)
+	assert.Equal(t, differ.ChangeTypeAddGrant, result.Changes[2].Type)
+	assert.Equal(t, "public.orders.total", grantOfChange(t, result.Changes[2]).Target())
+}
+
+func TestDiffer_MissingGranteeWarns(t *testing.T) {
+	t.Parallel()
+
+	current := grantsDatabase()
+	current.Roles = []string{"postgres", "reader"}
+
+	desired := grantsDatabase(
+		tableGrant("reader", false, "SELECT"),
+		tableGrant("auditor", false, "SELECT"),
+		tableGrant(schema.GrantRolePublic, false, "SELECT"),
+	)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	added := changesOfType(result, differ.ChangeTypeAddGrant)
+	require.Len(t, added, 2)
+	assert.Equal(t, schema.GrantRolePublic, grantOfChange(t, added[0]).Grantee)
+	assert.Equal(t, "reader", grantOfChange(t, added[1]).Grantee)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodeMissingGrantee, result.Diagnostics[0].Code)
+	assert.Equal(t, "auditor", result.Diagnostics[0].ObjectName)
+}
+
+func TestDiffer_GrantsOfRecreatedRelation(t *testing.T) {
+	t.Parallel()
+
+	totals := func(definition string) *schema.Database {
+		grant := tableGrant("reader", false, "SELECT")
+		grant.ObjectName = "order_totals"
+
+		db := grantsDatabase(grant)
+		db.MaterializedViews = []schema.MaterializedView{{
+			Schema:     schema.DefaultSchema,
+			Name:       "order_totals",
+			Definition: definition,
+		}}
+
+		return db
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		totals("SELECT id, total FROM public.orders"),
+		totals("SELECT id, total * 2 AS total FROM public.orders"),
+	)
+	require.NoError(t, err)
+
+	index := func(changeType differ.ChangeType) int {
+		for i, change := range result.Changes {
+			if change.Type == changeType {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	revoke := index(differ.ChangeTypeRevokeGrant)
+	replace := index(differ.ChangeTypeModifyMaterializedView)
+	grant := index(differ.ChangeTypeAddGrant)
+
+	require.NotEqual(t, -1, revoke)
+	require.NotEqual(t, -1, replace)
+	require.NotEqual(t, -1, grant)
+	assert.Less(t, revoke, replace)
+	assert.Less(t, replace, grant)
+}
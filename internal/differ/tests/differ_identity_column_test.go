@@ -0,0 +1,114 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func compareIdentity(
+	t *testing.T,
+	opts *differ.Options,
+	current, desired string,
+) *differ.DiffResult {
+	t.Helper()
+
+	parse := func(sql string) *schema.Database {
+		db := &schema.Database{}
+		p := parser.New()
+		require.NoError(t, p.ParseSQL(sql, db))
+		require.Empty(t, p.GetErrors())
+
+		return db
+	}
+
+	result, err := differ.New(opts).Compare(parse(current), parse(desired))
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestDiffer_SerialToIdentityIsAModification(t *testing.T) {
+	t.Parallel()
+
+	result := compareIdentity(t, differ.DefaultOptions(), `
+CREATE TABLE orders (id SERIAL PRIMARY KEY, note TEXT);
+CREATE SEQUENCE orders_id_seq AS integer OWNED BY orders.id;`,
+		`CREATE TABLE orders (id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY, note TEXT);`)
+
+	assert.Equal(t, []differ.ChangeType{
+		differ.ChangeTypeModifyColumnDefault,
+		differ.ChangeTypeDropSequence,
+		differ.ChangeTypeAddColumnIdentity,
+	}, changeTypes(result), "the identity sequence takes the name the SERIAL one frees")
+
+	add := result.Changes[2]
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, add.Severity,
+		"inserts supplying an id fail on a GENERATED ALWAYS column")
+	assert.Equal(t, "Column identity differs: public.orders.id is none in database, "+
+		"ALWAYS in desired schema (will be added, starting after the highest value)",
+		add.Description)
+}
+
+func TestDiffer_IdentityToSerial(t *testing.T) {
+	t.Parallel()
+
+	result := compareIdentity(t, differ.DefaultOptions(),
+		`CREATE TABLE orders (id INTEGER GENERATED BY DEFAULT AS IDENTITY);`,
+		`CREATE TABLE orders (id SERIAL);`)
+
+	assert.Equal(t, []differ.ChangeType{
+		differ.ChangeTypeDropColumnIdentity,
+		differ.ChangeTypeModifyColumnDefault,
+	}, changeTypes(result))
+	assert.Equal(t, differ.SeveritySafe, result.Changes[0].Severity,
+		"the column keeps a default drawing from a sequence")
+}
+
+func TestDiffer_IdentityOptions(t *testing.T) {
+	t.Parallel()
+
+	current := `CREATE TABLE orders (id BIGINT GENERATED BY DEFAULT AS IDENTITY);`
+	desired := `CREATE TABLE orders (
+    id BIGINT GENERATED ALWAYS AS IDENTITY (START WITH 1000 INCREMENT BY 10)
+);`
+
+	result := compareIdentity(t, differ.DefaultOptions(), current, desired)
+	require.Len(t, result.Changes, 1)
+
+	change := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeModifyColumnIdentity, change.Type)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, change.Severity)
+	assert.Equal(t, "Identity of column public.orders.id differs between database and desired "+
+		"schema (generation BY DEFAULT -> ALWAYS, increment 1 -> 10)", change.Description,
+		"START WITH only counts when it is enforced")
+
+	opts := differ.DefaultOptions()
+	opts.EnforceSequenceStart = true
+
+	enforced := compareIdentity(t, opts, current, desired)
+	require.Len(t, enforced.Changes, 1)
+	assert.Contains(t, enforced.Changes[0].Description, "start 1 -> 1000")
+
+	assert.Empty(t, compareIdentity(t, differ.DefaultOptions(),
+		`CREATE TABLE orders (id BIGINT GENERATED ALWAYS AS IDENTITY (START WITH 1000));`,
+		`CREATE TABLE orders (id BIGINT GENERATED ALWAYS AS IDENTITY);`).Changes)
+}
+
+func TestDiffer_AddingIdentityColumnNeedsNoBackfill(t *testing.T) {
+	t.Parallel()
+
+	result := compareIdentity(t, differ.DefaultOptions(),
+		`CREATE TABLE orders (note TEXT);`,
+		`CREATE TABLE orders (note TEXT, id BIGINT GENERATED ALWAYS AS IDENTITY);`)
+
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, differ.ChangeTypeAddColumn, result.Changes[0].Type)
+	assert.Equal(t, differ.SeveritySafe, result.Changes[0].Severity)
+	assert.Zero(t, result.PlanStats().UnsafeChanges)
+}
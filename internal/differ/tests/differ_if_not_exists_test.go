@@ -0,0 +1,134 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const ensureOnlyDesiredSQL = `
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGINT PRIMARY KEY,
+    message TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_message ON audit_log (message);
+
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+`
+
+func parseEnsureOnlySchema(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(sql, db))
+
+	return db
+}
+
+func compareEnsureOnly(
+	t *testing.T,
+	current *schema.Database,
+	ensureOnly bool,
+) *differ.DiffResult {
+	t.Helper()
+
+	opts := differ.DefaultOptions()
+	opts.IfNotExistsMeansEnsureOnly = ensureOnly
+
+	desired := parseEnsureOnlySchema(t, ensureOnlyDesiredSQL)
+
+	result, err := differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+
+	return result
+}
+
+func changeTypes(result *differ.DiffResult) []differ.ChangeType {
+	types := make([]differ.ChangeType, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		types = append(types, change.Type)
+	}
+
+	return types
+}
+
+func TestDiffer_IfNotExistsTableWithExtraColumns(t *testing.T) {
+	t.Parallel()
+
+	current := parseEnsureOnlySchema(t, `
+CREATE TABLE audit_log (
+    id BIGINT PRIMARY KEY,
+    message VARCHAR(200),
+    legacy_source TEXT
+);
+
+CREATE INDEX idx_audit_log_message ON audit_log (message, id);
+CREATE INDEX idx_audit_log_source ON audit_log (legacy_source);
+
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    legacy_code TEXT
+);
+`)
+
+	t.Run("ensure only", func(t *testing.T) {
+		t.Parallel()
+
+		result := compareEnsureOnly(t, current, true)
+
+		require.Len(t, result.Changes, 1)
+		assert.Equal(t, differ.ChangeTypeDropColumn, result.Changes[0].Type)
+		assert.Equal(t, "public.accounts", result.Changes[0].ObjectName)
+
+		require.Len(t, result.Notes, 2)
+		assert.Contains(t, result.Notes[0], "index public.idx_audit_log_message is declared IF NOT EXISTS")
+		assert.Contains(t, result.Notes[1], "table public.audit_log is declared IF NOT EXISTS")
+		assert.Contains(t, result.Notes[1], "legacy_source")
+		assert.Contains(t, result.Notes[1], "idx_audit_log_source")
+		assert.Contains(t, result.Summary(), "Notes:")
+	})
+
+	t.Run("option disabled", func(t *testing.T) {
+		t.Parallel()
+
+		result := compareEnsureOnly(t, current, false)
+
+		types := changeTypes(result)
+		assert.Contains(t, types, differ.ChangeTypeDropColumn)
+		assert.Contains(t, types, differ.ChangeTypeModifyColumnType)
+		assert.Contains(t, types, differ.ChangeTypeModifyColumnNullability)
+		assert.Contains(t, types, differ.ChangeTypeDropIndex)
+		assert.Contains(t, types, differ.ChangeTypeModifyIndex)
+		assert.Empty(t, result.Notes)
+	})
+}
+
+func TestDiffer_IfNotExistsTableMissingEntirely(t *testing.T) {
+	t.Parallel()
+
+	current := parseEnsureOnlySchema(t, `
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+`)
+
+	result := compareEnsureOnly(t, current, true)
+
+	assert.ElementsMatch(
+		t,
+		[]differ.ChangeType{differ.ChangeTypeAddTable, differ.ChangeTypeAddIndex},
+		changeTypes(result),
+	)
+	assert.Empty(t, result.Notes)
+}
@@ -0,0 +1,59 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func storageMaterializedView(definition string, params map[string]string) *schema.Database {
+	return &schema.Database{MaterializedViews: []schema.MaterializedView{{
+		Schema:        schema.DefaultSchema,
+		Name:          "stats_mv",
+		Definition:    definition,
+		StorageParams: params,
+	}}}
+}
+
+func TestDiffer_MaterializedViewStorageParams(t *testing.T) {
+	t.Parallel()
+
+	const definition = "SELECT user_id, count(*) AS orders FROM orders GROUP BY user_id"
+
+	current := storageMaterializedView(definition, map[string]string{"fillfactor": "70"})
+	desired := storageMaterializedView(definition, map[string]string{
+		"FillFactor":         "'70'",
+		"autovacuum_enabled": "false",
+	})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+
+	change := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeModifyMaterializedView, change.Type)
+	assert.Equal(t, differ.SeveritySafe, change.Severity)
+	assert.Equal(t, map[string]string{"fillfactor": "70"}, change.Details["old_storage_params"])
+	assert.Equal(t, map[string]string{"fillfactor": "70", "autovacuum_enabled": "false"},
+		change.Details["new_storage_params"])
+	assert.NotContains(t, change.Details, "current")
+
+	assertNoChanges(t, current,
+		storageMaterializedView(definition, map[string]string{"FILLFACTOR": " 70 "}))
+}
+
+func TestDiffer_RedefinedMaterializedViewCarriesStorageParams(t *testing.T) {
+	t.Parallel()
+
+	current := storageMaterializedView("SELECT 1 AS one", nil)
+	desired := storageMaterializedView("SELECT 2 AS two", map[string]string{"fillfactor": "70"})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Contains(t, result.Changes[0].Details, "current")
+}
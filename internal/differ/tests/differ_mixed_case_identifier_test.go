@@ -0,0 +1,227 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const mixedCaseSchemaSQL = `
+CREATE SCHEMA "Billing";
+CREATE TABLE "UserAccounts" (
+    "Id" BIGINT PRIMARY KEY,
+    "CreatedAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "Email" TEXT NOT NULL CHECK ("Email" <> ''),
+    UNIQUE ("Email") INCLUDE ("CreatedAt")
+);
+CREATE INDEX "idx_UserAccounts_Email" ON "UserAccounts" ("Email");
+CREATE TABLE "Billing"."Invoices" (
+    id BIGINT PRIMARY KEY,
+    "AccountId" BIGINT NOT NULL REFERENCES "UserAccounts" ("Id")
+);
+CREATE VIEW "ActiveAccounts" AS SELECT "Id", "Email" FROM "UserAccounts";
+`
+
+// introspectedMixedCaseSchema is mixedCaseSchemaSQL as the extractor reads
+// it back from the catalog.
+func introspectedMixedCaseSchema() *schema.Database {
+	return &schema.Database{
+		Schemas: []schema.Schema{{Name: "Billing"}},
+		Tables: []schema.Table{
+			{
+				Schema: schema.DefaultSchema,
+				Name:   "UserAccounts",
+				Columns: []schema.Column{
+					{Name: "Id", DataType: "bigint", Position: 1},
+					{
+						Name: "CreatedAt", DataType: "timestamp with time zone",
+						Position: 2, Default: "now()",
+					},
+					{Name: "Email", DataType: "text", Position: 3},
+				},
+				Constraints: []schema.Constraint{
+					{
+						Name: "UserAccounts_pkey", Type: schema.ConstraintPrimaryKey,
+						Columns: []string{"Id"}, Definition: `PRIMARY KEY ("Id")`,
+					},
+					{
+						Name: "UserAccounts_Email_check", Type: schema.ConstraintCheck,
+						Columns:         []string{"Email"},
+						Definition:      `CHECK (("Email" <> ''::text))`,
+						CheckExpression: `CHECK (("Email" <> ''::text))`,
+					},
+					{
+						Name: "UserAccounts_Email_key", Type: schema.ConstraintUnique,
+						Columns:        []string{"Email"},
+						IncludeColumns: []string{"CreatedAt"},
+						Definition:     `UNIQUE ("Email") INCLUDE ("CreatedAt")`,
+					},
+				},
+				Indexes: []schema.Index{
+					{
+						Schema: schema.DefaultSchema, TableName: "UserAccounts",
+						Name: "UserAccounts_pkey", Columns: []string{"Id"},
+						Type: "btree", IsUnique: true, IsPrimary: true,
+						Definition: `CREATE UNIQUE INDEX "UserAccounts_pkey" ` +
+							`ON public."UserAccounts" USING btree ("Id")`,
+					},
+					{
+						Schema: schema.DefaultSchema, TableName: "UserAccounts",
+						Name: "UserAccounts_Email_key", Columns: []string{"Email"},
+						IncludeColumns: []string{"CreatedAt"}, Type: "btree", IsUnique: true,
+						Definition: `CREATE UNIQUE INDEX "UserAccounts_Email_key" ` +
+							`ON public."UserAccounts" USING btree ("Email") INCLUDE ("CreatedAt")`,
+					},
+					{
+						Schema: schema.DefaultSchema, TableName: "UserAccounts",
+						Name: "idx_UserAccounts_Email", Columns: []string{"Email"}, Type: "btree",
+						Definition: `CREATE INDEX "idx_UserAccounts_Email" ` +
+							`ON public."UserAccounts" USING btree ("Email")`,
+					},
+				},
+			},
+			{
+				Schema: "Billing",
+				Name:   "Invoices",
+				Columns: []schema.Column{
+					{Name: "id", DataType: "bigint", Position: 1},
+					{Name: "AccountId", DataType: "bigint", Position: 2},
+				},
+				Constraints: []schema.Constraint{
+					{
+						Name: "Invoices_pkey", Type: schema.ConstraintPrimaryKey,
+						Columns: []string{"id"}, Definition: "PRIMARY KEY (id)",
+					},
+					{
+						Name: "Invoices_AccountId_fkey", Type: schema.ConstraintForeignKey,
+						Columns:           []string{"AccountId"},
+						Definition:        `FOREIGN KEY ("AccountId") REFERENCES "UserAccounts"("Id")`,
+						ReferencedSchema:  schema.DefaultSchema,
+						ReferencedTable:   "UserAccounts",
+						ReferencedColumns: []string{"Id"},
+						OnDelete:          "NO ACTION",
+						OnUpdate:          "NO ACTION",
+					},
+				},
+				Indexes: []schema.Index{
+					{
+						Schema: "Billing", TableName: "Invoices",
+						Name: "Invoices_pkey", Columns: []string{"id"},
+						Type: "btree", IsUnique: true, IsPrimary: true,
+						Definition: `CREATE UNIQUE INDEX "Invoices_pkey" ` +
+							`ON "Billing"."Invoices" USING btree (id)`,
+					},
+				},
+			},
+		},
+		Views: []schema.View{
+			{
+				Schema: schema.DefaultSchema,
+				Name:   "ActiveAccounts",
+				Definition: " SELECT \"UserAccounts\".\"Id\",\n" +
+					"    \"UserAccounts\".\"Email\"\n   FROM \"UserAccounts\";",
+			},
+		},
+	}
+}
+
+func TestDiffer_MixedCaseIdentifiers_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(mixedCaseSchemaSQL, desired))
+
+	assertNoChanges(t, introspectedMixedCaseSchema(), desired)
+}
+
+func TestDiffer_MixedCaseIdentifiers_CaseMatters(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(
+		`CREATE TABLE "UserAccounts" ("Id" BIGINT, "CreatedAt" DATE);`, desired))
+
+	current := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(
+		`CREATE TABLE UserAccounts ("Id" BIGINT, CreatedAt DATE);`, current))
+
+	result, err := differ.New(nil).Compare(current, desired)
+	require.NoError(t, err)
+
+	var types []differ.ChangeType
+	for _, change := range result.Changes {
+		types = append(types, change.Type)
+	}
+
+	assert.ElementsMatch(t,
+		[]differ.ChangeType{differ.ChangeTypeDropTable, differ.ChangeTypeAddTable}, types,
+		`useraccounts and "UserAccounts" are different tables`)
+}
+
+func TestDiffer_MixedCaseIdentifiers_SequenceOwner(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sql         string
+		schemaName  string
+		tableName   string
+		columnName  string
+		ownedByText string
+	}{
+		{
+			name: "unquoted names fold",
+			sql: `CREATE SCHEMA App;
+CREATE TABLE App.Orders (ID BIGINT);
+CREATE SEQUENCE App.Ord_Seq OWNED BY App.Orders.ID;`,
+			schemaName:  "app",
+			tableName:   "orders",
+			columnName:  "id",
+			ownedByText: "app.orders",
+		},
+		{
+			name: "quoted names keep their case",
+			sql: `CREATE SCHEMA "Billing";
+CREATE TABLE "Billing"."Invoices" ("AccountId" BIGINT);
+CREATE SEQUENCE "Billing".ord_seq;
+ALTER SEQUENCE "Billing".ord_seq OWNED BY "Billing"."Invoices"."AccountId";`,
+			schemaName:  "Billing",
+			tableName:   "Invoices",
+			columnName:  "AccountId",
+			ownedByText: `"Billing"."Invoices"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			desired := &schema.Database{}
+			require.NoError(t, parser.New().ParseSQL(tt.sql, desired))
+			require.Len(t, desired.Sequences, 1)
+
+			// The extractor reads the owning table as regclass text and the
+			// column by its attname.
+			current := &schema.Database{
+				Schemas: []schema.Schema{{Name: tt.schemaName}},
+				Tables: []schema.Table{{
+					Schema: tt.schemaName,
+					Name:   tt.tableName,
+					Columns: []schema.Column{{
+						Name: tt.columnName, DataType: "bigint", IsNullable: true, Position: 1,
+					}},
+				}},
+				Sequences: []schema.Sequence{desired.Sequences[0]},
+			}
+			current.Sequences[0].OwnedByTable = tt.ownedByText
+			current.Sequences[0].OwnedByColumn = tt.columnName
+
+			assertNoChanges(t, current, desired)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func commentedObjectDatabase(indexColumn, schemaComment, indexComment, triggerComment string) *schema.Database {
+	return &schema.Database{
+		Schemas: []schema.Schema{{Name: "billing", Comment: schemaComment}},
+		Tables: []schema.Table{{
+			Schema:  "billing",
+			Name:    "invoices",
+			Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+			Indexes: []schema.Index{{
+				Schema:    "billing",
+				Name:      "invoices_idx",
+				TableName: "invoices",
+				Columns:   []string{indexColumn},
+				Type:      "btree",
+				Comment:   indexComment,
+			}},
+		}},
+		Triggers: []schema.Trigger{{
+			Schema:         "billing",
+			Name:           "invoices_touch",
+			TableName:      "invoices",
+			Timing:         "BEFORE",
+			Events:         []string{"UPDATE"},
+			ForEachRow:     true,
+			FunctionSchema: "billing",
+			FunctionName:   "touch",
+			Comment:        triggerComment,
+		}},
+	}
+}
+
+func TestDiffer_ObjectComments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		current        *schema.Database
+		desired        *schema.Database
+		ignoreComments bool
+		wantTypes      []differ.ChangeType
+	}{
+		{
+			name:      "whitespace-only difference",
+			current:   commentedObjectDatabase("id", "Invoicing\n", "By id", "Touch"),
+			desired:   commentedObjectDatabase("id", "Invoicing", "By  id", "Touch"),
+			wantTypes: []differ.ChangeType{},
+		},
+		{
+			name:    "comments changed",
+			current: commentedObjectDatabase("id", "Invoicing", "By id", "Touch"),
+			desired: commentedObjectDatabase("id", "Billing", "", "Sets updated_at"),
+			wantTypes: []differ.ChangeType{
+				differ.ChangeTypeModifySchemaComment,
+				differ.ChangeTypeModifyIndexComment,
+				differ.ChangeTypeModifyTriggerComment,
+			},
+		},
+		{
+			name:           "comments ignored",
+			current:        commentedObjectDatabase("id", "Invoicing", "By id", "Touch"),
+			desired:        commentedObjectDatabase("id", "Billing", "", "Sets updated_at"),
+			ignoreComments: true,
+			wantTypes:      []differ.ChangeType{},
+		},
+		{
+			name:    "recreated index sets its comment again",
+			current: commentedObjectDatabase("id", "Invoicing", "By id", "Touch"),
+			desired: commentedObjectDatabase("lower(id::text)", "Invoicing", "By id", "Touch"),
+			wantTypes: []differ.ChangeType{
+				differ.ChangeTypeModifyIndex,
+				differ.ChangeTypeModifyIndexComment,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := differ.DefaultOptions()
+			opts.IgnoreComments = tt.ignoreComments
+
+			result, err := differ.New(opts).Compare(tt.current, tt.desired)
+			require.NoError(t, err)
+
+			types := make([]differ.ChangeType, 0, len(result.Changes))
+			for _, change := range result.Changes {
+				types = append(types, change.Type)
+			}
+
+			assert.ElementsMatch(t, tt.wantTypes, types)
+		})
+	}
+}
+
+func TestDiffer_ObjectCommentsOrderAfterTheirObjects(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{},
+		commentedObjectDatabase("id", "Invoicing", "By id", "Touch"),
+	)
+	require.NoError(t, err)
+
+	position := make(map[differ.ChangeType]int)
+	for i, change := range result.Changes {
+		position[change.Type] = i
+	}
+
+	assert.Less(t, position[differ.ChangeTypeAddSchema], position[differ.ChangeTypeModifySchemaComment])
+	assert.Less(t, position[differ.ChangeTypeAddIndex], position[differ.ChangeTypeModifyIndexComment])
+	assert.Less(t, position[differ.ChangeTypeAddTrigger], position[differ.ChangeTypeModifyTriggerComment])
+}
@@ -0,0 +1,349 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const objectCurrentSQL = `
+CREATE TABLE users (id BIGINT PRIMARY KEY, email TEXT, score NUMERIC(10,2));
+COMMENT ON TABLE users IS 'Old comment';
+CREATE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_score ON users (score);
+CREATE TABLE legacy (id INT);
+CREATE TABLE lookup (code TEXT PRIMARY KEY);
+CREATE VIEW lookup_codes AS SELECT code FROM lookup;
+CREATE VIEW old_view AS SELECT 1 AS one;
+CREATE MATERIALIZED VIEW lookup_count AS SELECT count(*) AS n FROM lookup;
+CREATE INDEX idx_lookup_count ON lookup_count (n);
+CREATE FUNCTION add_one(x INT) RETURNS INT LANGUAGE sql AS $$ SELECT x + 1 $$;
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END; $$;
+CREATE TRIGGER users_touch BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION touch();
+`
+
+const objectDesiredSQL = `
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    email TEXT NOT NULL,
+    score NUMERIC(12,2),
+    created_at TIMESTAMPTZ,
+    CONSTRAINT users_score_check CHECK (score >= 0)
+);
+COMMENT ON TABLE users IS 'Registered users';
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_created ON users (created_at);
+CREATE TABLE accounts (id BIGINT PRIMARY KEY, name TEXT);
+COMMENT ON COLUMN accounts.name IS 'Display name';
+CREATE TABLE lookup (code TEXT PRIMARY KEY);
+CREATE VIEW lookup_codes AS SELECT code FROM lookup WHERE code <> '';
+COMMENT ON VIEW lookup_codes IS 'Non-empty codes';
+CREATE VIEW new_view AS SELECT 2 AS two;
+CREATE MATERIALIZED VIEW lookup_count AS SELECT count(code) AS n FROM lookup;
+CREATE INDEX idx_lookup_count ON lookup_count (n);
+CREATE FUNCTION add_one(x INT) RETURNS INT LANGUAGE sql AS $$ SELECT x + 1 $$;
+COMMENT ON FUNCTION add_one(INT) IS 'Increments x';
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NULL; END; $$;
+CREATE TRIGGER users_touch BEFORE INSERT OR UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION touch();
+`
+
+func parseObjectSchema(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(sql, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+// comparableChange drops the fields only Compare fills in: dependencies, order and
+// source locations.
+type comparableChange struct {
+	Type        differ.ChangeType
+	Severity    differ.ChangeSeverity
+	Description string
+	ObjectType  string
+	ObjectName  string
+	Details     map[string]any
+}
+
+func comparableChanges(changes []differ.Change) []comparableChange {
+	out := make([]comparableChange, 0, len(changes))
+	for _, change := range changes {
+		out = append(out, comparableChange{
+			Type:        change.Type,
+			Severity:    change.Severity,
+			Description: change.Description,
+			ObjectType:  change.ObjectType,
+			ObjectName:  change.ObjectName,
+			Details:     change.Details,
+		})
+	}
+
+	return out
+}
+
+func pairObjects[T any](
+	current, desired []T,
+	key func(*T) string,
+	compare func(current, desired *T) []differ.Change,
+) []differ.Change {
+	currentByKey := make(map[string]*T, len(current))
+	for i := range current {
+		currentByKey[key(&current[i])] = &current[i]
+	}
+
+	var changes []differ.Change
+
+	seen := make(map[string]bool, len(desired))
+
+	for i := range desired {
+		k := key(&desired[i])
+		seen[k] = true
+		changes = append(changes, compare(currentByKey[k], &desired[i])...)
+	}
+
+	for i := range current {
+		if !seen[key(&current[i])] {
+			changes = append(changes, compare(&current[i], nil)...)
+		}
+	}
+
+	return changes
+}
+
+func standaloneIndexes(db *schema.Database) []schema.Index {
+	var indexes []schema.Index
+
+	for i := range db.Tables {
+		for _, idx := range db.Tables[i].Indexes {
+			if !idx.IsPrimary {
+				indexes = append(indexes, idx)
+			}
+		}
+	}
+
+	for i := range db.MaterializedViews {
+		indexes = append(indexes, db.MaterializedViews[i].Indexes...)
+	}
+
+	return indexes
+}
+
+func TestSingleObjectComparisonMatchesCompare(t *testing.T) {
+	t.Parallel()
+
+	current := parseObjectSchema(t, objectCurrentSQL)
+	desired := parseObjectSchema(t, objectDesiredSQL)
+	d := differ.New(differ.DefaultOptions())
+
+	result, err := d.Compare(current, desired)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Changes)
+
+	var changes []differ.Change
+
+	changes = append(changes, pairObjects(current.Tables, desired.Tables,
+		func(t *schema.Table) string { return differ.TableKey(t.Schema, t.Name) },
+		d.CompareTable)...)
+	changes = append(changes, pairObjects(standaloneIndexes(current), standaloneIndexes(desired),
+		func(idx *schema.Index) string { return differ.IndexKey(idx.Schema, idx.Name) },
+		d.CompareIndex)...)
+	changes = append(changes, pairObjects(current.Views, desired.Views,
+		func(v *schema.View) string { return differ.ViewKey(v.Schema, v.Name) },
+		d.CompareView)...)
+	changes = append(changes, pairObjects(current.MaterializedViews, desired.MaterializedViews,
+		func(v *schema.MaterializedView) string { return differ.ViewKey(v.Schema, v.Name) },
+		d.CompareMaterializedView)...)
+	changes = append(changes, pairObjects(current.Functions, desired.Functions,
+		func(fn *schema.Function) string {
+			return differ.FunctionKey(fn.Schema, fn.Name, fn.ArgumentTypes)
+		},
+		func(cur, des *schema.Function) []differ.Change {
+			return d.CompareFunction(cur, des, current.Triggers)
+		})...)
+	changes = append(changes, pairObjects(current.Triggers, desired.Triggers,
+		func(tr *schema.Trigger) string { return tr.QualifiedTableName() + "." + tr.Name },
+		d.CompareTrigger)...)
+
+	assert.ElementsMatch(t, comparableChanges(result.Changes), comparableChanges(changes))
+}
+
+func TestCompareTable(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+	current := parseObjectSchema(t, "CREATE TABLE users (id BIGINT, email TEXT);")
+	desired := parseObjectSchema(t, `
+CREATE TABLE users (id BIGINT, email TEXT NOT NULL);
+COMMENT ON TABLE users IS 'Registered users';
+`)
+
+	changes := d.CompareTable(&current.Tables[0], &desired.Tables[0])
+	require.Len(t, changes, 2)
+	assert.Equal(t, differ.ChangeTypeModifyColumnNullability, changes[0].Type)
+	assert.Equal(t, "public.users", changes[0].ObjectName)
+	assert.NotEmpty(t, changes[0].Details)
+	assert.Equal(t, differ.ChangeTypeModifyTableComment, changes[1].Type)
+
+	added := d.CompareTable(nil, &desired.Tables[0])
+	require.Len(t, added, 2)
+	assert.Equal(t, differ.ChangeTypeAddTable, added[0].Type)
+	assert.Equal(t, &desired.Tables[0], added[0].Details["table"])
+
+	dropped := d.CompareTable(&current.Tables[0], nil)
+	require.Len(t, dropped, 1)
+	assert.Equal(t, differ.ChangeTypeDropTable, dropped[0].Type)
+	assert.Equal(t, differ.SeverityBreaking, dropped[0].Severity)
+
+	assert.Empty(t, d.CompareTable(&current.Tables[0], &current.Tables[0]))
+	assert.Empty(t, d.CompareTable(nil, nil))
+}
+
+func TestCompareIndex(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+	current := parseObjectSchema(t, `
+CREATE TABLE users (email TEXT);
+CREATE INDEX idx_users_email ON users (email);
+`)
+	desired := parseObjectSchema(t, `
+CREATE TABLE users (email TEXT);
+CREATE INDEX idx_users_email ON users (lower(email));
+`)
+
+	currentIdx, desiredIdx := &current.Tables[0].Indexes[0], &desired.Tables[0].Indexes[0]
+
+	changes := d.CompareIndex(currentIdx, desiredIdx)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifyIndex, changes[0].Type)
+	assert.Equal(t, desiredIdx, changes[0].Details["desired"])
+
+	assert.Empty(t, d.CompareIndex(currentIdx, currentIdx))
+	assert.Equal(t, differ.ChangeTypeAddIndex, d.CompareIndex(nil, desiredIdx)[0].Type)
+	assert.Equal(t, differ.ChangeTypeDropIndex, d.CompareIndex(currentIdx, nil)[0].Type)
+}
+
+func TestCompareView(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+	current := parseObjectSchema(t, "CREATE VIEW v AS SELECT 1 AS one;")
+	desired := parseObjectSchema(t, `
+CREATE VIEW v AS
+    SELECT   1 AS one;
+COMMENT ON VIEW v IS 'One';
+`)
+
+	changes := d.CompareView(&current.Views[0], &desired.Views[0])
+	require.Len(t, changes, 1, "whitespace is normalized away, the comment is not")
+	assert.Equal(t, "One", changes[0].Details["new_comment"])
+
+	assert.Equal(t, differ.ChangeTypeAddView, d.CompareView(nil, &desired.Views[0])[0].Type)
+	assert.Equal(t, differ.ChangeTypeDropView, d.CompareView(&current.Views[0], nil)[0].Type)
+}
+
+func TestCompareMaterializedView(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+	current := parseObjectSchema(t, "CREATE MATERIALIZED VIEW mv AS SELECT 1 AS one;")
+	desired := parseObjectSchema(t, "CREATE MATERIALIZED VIEW mv AS SELECT 2 AS one;")
+
+	currentView, desiredView := &current.MaterializedViews[0], &desired.MaterializedViews[0]
+
+	changes := d.CompareMaterializedView(currentView, desiredView)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifyMaterializedView, changes[0].Type)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, changes[0].Severity)
+
+	assert.Empty(t, d.CompareMaterializedView(currentView, currentView))
+	assert.Equal(t, differ.ChangeTypeAddMaterializedView,
+		d.CompareMaterializedView(nil, desiredView)[0].Type)
+	assert.Equal(t, differ.ChangeTypeDropMaterializedView,
+		d.CompareMaterializedView(currentView, nil)[0].Type)
+}
+
+func TestCompareFunction(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+	current := parseObjectSchema(t, `
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END; $$;
+CREATE TABLE users (id BIGINT);
+CREATE TRIGGER users_touch BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION touch();
+`)
+	desired := parseObjectSchema(t, `
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NULL; END; $$;
+`)
+
+	currentFn, desiredFn := &current.Functions[0], &desired.Functions[0]
+
+	changes := d.CompareFunction(currentFn, desiredFn, nil)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifyFunction, changes[0].Type)
+	assert.Equal(t, "public.touch()", changes[0].ObjectName)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, changes[0].Severity)
+
+	changes = d.CompareFunction(currentFn, desiredFn, current.Triggers)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.SeverityBreaking, changes[0].Severity,
+		"a function called by a current trigger is breaking to change")
+
+	assert.Empty(t, d.CompareFunction(currentFn, currentFn, nil))
+	assert.Equal(t, differ.ChangeTypeDropFunction, d.CompareFunction(currentFn, nil, nil)[0].Type)
+}
+
+func TestCompareTrigger(t *testing.T) {
+	t.Parallel()
+
+	d := differ.New(differ.DefaultOptions())
+	current := parseObjectSchema(t, `
+CREATE TABLE users (id BIGINT);
+CREATE TRIGGER users_touch BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION touch();
+`)
+	desired := parseObjectSchema(t, `
+CREATE TABLE users (id BIGINT);
+CREATE TRIGGER users_touch AFTER UPDATE ON users FOR EACH ROW EXECUTE FUNCTION touch();
+`)
+
+	currentTrigger, desiredTrigger := &current.Triggers[0], &desired.Triggers[0]
+
+	changes := d.CompareTrigger(currentTrigger, desiredTrigger)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifyTrigger, changes[0].Type)
+	assert.Equal(t, "public.users.users_touch", changes[0].ObjectName)
+
+	assert.Empty(t, d.CompareTrigger(currentTrigger, currentTrigger))
+	assert.Equal(t, differ.ChangeTypeAddTrigger, d.CompareTrigger(nil, desiredTrigger)[0].Type)
+	assert.Equal(t, differ.ChangeTypeDropTrigger, d.CompareTrigger(currentTrigger, nil)[0].Type)
+}
+
+func TestExportedNormalizers(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, differ.AreCheckExpressionsEqual("CHECK ((score >= 0))", "score>=0"))
+	assert.Equal(t, "score >= 0", differ.NormalizeCheckExpression("CHECK ((score >= 0))"))
+	assert.Equal(t, differ.NormalizeDefault("'active'::text"), differ.NormalizeDefault("'active'"))
+	assert.True(t, differ.AreViewDefinitionsEqual("SELECT  1", "select 1"))
+
+	precision := 10
+	assert.True(t, differ.AreColumnTypesEqual(
+		&schema.Column{DataType: "decimal", Precision: &precision},
+		&schema.Column{DataType: "numeric", Precision: &precision},
+	))
+	assert.False(t, differ.AreColumnTypesEqual(
+		&schema.Column{DataType: "numeric", Precision: &precision},
+		&schema.Column{DataType: "numeric"},
+	))
+}
@@ -0,0 +1,51 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestOptionsHash(t *testing.T) {
+	t.Parallel()
+
+	base := differ.DefaultOptions().Hash()
+	assert.Len(t, base, 12)
+	assert.Equal(t, base, differ.DefaultOptions().Hash(), "hash is stable")
+	assert.Equal(t, base, (*differ.Options)(nil).Hash(), "nil options are the defaults")
+
+	withProgress := differ.DefaultOptions()
+	withProgress.Progress = func(string, int, int) {}
+	assert.Equal(t, base, withProgress.Hash(), "progress does not affect the hash")
+
+	ensureOnly := differ.DefaultOptions()
+	ensureOnly.IfNotExistsMeansEnsureOnly = true
+	assert.NotEqual(t, base, ensureOnly.Hash())
+
+	recreate := differ.DefaultOptions()
+	recreate.TableRecreation = differ.DefaultTableRecreationThresholds()
+	assert.NotEqual(t, base, recreate.Hash())
+
+	stricter := differ.DefaultOptions()
+	stricter.TableRecreation = &differ.TableRecreationThresholds{
+		ColumnChangeRatio:       0.25,
+		PrimaryKeyColumnChanges: 3,
+	}
+	assert.NotEqual(t, recreate.Hash(), stricter.Hash())
+
+	enforceStart := differ.DefaultOptions()
+	enforceStart.EnforceSequenceStart = true
+	assert.NotEqual(t, base, enforceStart.Hash())
+
+	columnRenames := differ.DefaultOptions()
+	columnRenames.DetectColumnRenames = true
+	assert.NotEqual(t, base, columnRenames.Hash())
+
+	result, err := differ.New(ensureOnly).Compare(&schema.Database{}, &schema.Database{})
+	require.NoError(t, err)
+	assert.Equal(t, ensureOnly.Hash(), result.OptionsHash)
+}
@@ -0,0 +1,147 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const partialUniqueUsersTable = `
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    email TEXT NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+`
+
+func comparePartialUnique(t *testing.T, current, desired string) *differ.DiffResult {
+	t.Helper()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		parseEnsureOnlySchema(t, partialUniqueUsersTable+current),
+		parseEnsureOnlySchema(t, partialUniqueUsersTable+desired),
+	)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestDiffer_PartialUniqueIndexPredicate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		want    []differ.ChangeType
+	}{
+		{
+			name:    "predicate changed",
+			current: `CREATE UNIQUE INDEX users_email_active ON users (email) WHERE deleted_at IS NULL;`,
+			desired: `CREATE UNIQUE INDEX users_email_active ON users (email) WHERE id > 0;`,
+			want:    []differ.ChangeType{differ.ChangeTypeModifyIndex},
+		},
+		{
+			name:    "predicate added",
+			current: `CREATE UNIQUE INDEX users_email_active ON users (email);`,
+			desired: `CREATE UNIQUE INDEX users_email_active ON users (email) WHERE deleted_at IS NULL;`,
+			want:    []differ.ChangeType{differ.ChangeTypeModifyIndex},
+		},
+		{
+			name:    "keyword case and parentheses",
+			current: `CREATE UNIQUE INDEX users_email_active ON users (email) WHERE (deleted_at IS NULL);`,
+			desired: `CREATE UNIQUE INDEX users_email_active ON users (email) WHERE deleted_at is null;`,
+			want:    []differ.ChangeType{},
+		},
+		{
+			name:    "unnamed index matches its default name",
+			current: `CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE (deleted_at IS NULL);`,
+			desired: `CREATE UNIQUE INDEX ON users (email) WHERE deleted_at IS NULL;`,
+			want:    []differ.ChangeType{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := comparePartialUnique(t, tt.current, tt.desired)
+			assert.Equal(t, tt.want, changeTypes(result))
+		})
+	}
+}
+
+func TestDiffer_PartialUniqueIndexReplacesConstraint(t *testing.T) {
+	t.Parallel()
+
+	constraint := `ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);`
+	index := `CREATE UNIQUE INDEX users_email_key ON users (email) WHERE deleted_at IS NULL;`
+
+	t.Run("constraint to partial index", func(t *testing.T) {
+		t.Parallel()
+
+		result := comparePartialUnique(t, constraint, index)
+		assert.Equal(t, []differ.ChangeType{
+			differ.ChangeTypeDropConstraint,
+			differ.ChangeTypeAddIndex,
+		}, changeTypes(result))
+	})
+
+	t.Run("partial index to constraint", func(t *testing.T) {
+		t.Parallel()
+
+		result := comparePartialUnique(t, index, constraint)
+		assert.Equal(t, []differ.ChangeType{
+			differ.ChangeTypeDropIndex,
+			differ.ChangeTypeAddConstraint,
+		}, changeTypes(result))
+	})
+}
+
+func TestDiffer_OverlappingUniquenessWarning(t *testing.T) {
+	t.Parallel()
+
+	overlapping := func(result *differ.DiffResult) []diag.Warning {
+		var warnings []diag.Warning
+
+		for _, warning := range result.Diagnostics {
+			if warning.Code == diag.CodeOverlappingUniqueness {
+				warnings = append(warnings, warning)
+			}
+		}
+
+		return warnings
+	}
+
+	t.Run("constraint and partial index on the same columns", func(t *testing.T) {
+		t.Parallel()
+
+		result := comparePartialUnique(t, "", `
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+CREATE UNIQUE INDEX users_email_active ON users ("Email") WHERE deleted_at IS NULL;
+`)
+
+		warnings := overlapping(result)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, diag.SeverityWarning, warnings[0].Severity)
+		assert.Equal(t, schema.DefaultSchema+".users_email_active", warnings[0].ObjectName)
+		assert.Contains(t, warnings[0].Message, "users_email_key")
+	})
+
+	t.Run("partial index on other columns", func(t *testing.T) {
+		t.Parallel()
+
+		result := comparePartialUnique(t, "", `
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+CREATE UNIQUE INDEX users_email_active ON users (email, deleted_at) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_id_active ON users (id) WHERE deleted_at IS NULL;
+`)
+
+		assert.Empty(t, overlapping(result))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
@@ -433,3 +434,92 @@ func TestDiffer_PartitionChanges_NonPartitionedTable(t *testing.T) {
 	assert.Empty(t, addChanges, "Expected no partition changes for non-partitioned table")
 	assert.Empty(t, dropChanges, "Expected no partition changes for non-partitioned table")
 }
+
+func TestDiffer_PartitionChanges_LocalObjects(t *testing.T) {
+	t.Parallel()
+
+	const events = `
+CREATE TABLE events (
+    id BIGINT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind <> '')
+) PARTITION BY RANGE (id);
+CREATE TABLE events_1 PARTITION OF events FOR VALUES FROM (0) TO (100);
+CREATE TABLE events_2 PARTITION OF events FOR VALUES FROM (100) TO (200);
+`
+
+	current := parseEnsureOnlySchema(t, events+`
+ALTER TABLE events_1 ADD CONSTRAINT events_1_id_check CHECK (id > 0);
+ALTER TABLE events_2 ADD CONSTRAINT events_kind_check CHECK (kind <> '');
+`)
+	desired := parseEnsureOnlySchema(t, events+`
+CREATE INDEX events_2_kind_idx ON events_2 (kind);
+`)
+
+	parent := current.GetTable(schema.DefaultSchema, "events")
+	require.NotNil(t, parent)
+	assert.Empty(t, parent.PartitionStrategy.Partitions[1].Constraints,
+		"a constraint named like the parent's is the inherited copy")
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t,
+		[]differ.ChangeType{differ.ChangeTypeDropConstraint, differ.ChangeTypeAddIndex},
+		changeTypes(result))
+
+	for _, change := range result.Changes {
+		switch change.Type {
+		case differ.ChangeTypeDropConstraint:
+			assert.Equal(t, "public.events_1", change.Details["table"])
+		case differ.ChangeTypeAddIndex:
+			idx, ok := change.Details["index"].(*schema.Index)
+			require.True(t, ok)
+			assert.Equal(t, "public.events_2", idx.QualifiedTableName())
+		}
+	}
+}
+
+func TestDiffer_PartitionChanges_BoundsChange(t *testing.T) {
+	t.Parallel()
+
+	const parent = `
+CREATE TABLE logs (id BIGINT NOT NULL, created_at TIMESTAMPTZ NOT NULL)
+    PARTITION BY RANGE (created_at);
+`
+
+	current := parseEnsureOnlySchema(t, parent+`
+CREATE TABLE logs_2024 PARTITION OF logs
+    FOR VALUES FROM ('2024-01-01 00:00:00+00') TO ('2025-01-01 00:00:00+00');
+CREATE TABLE logs_2025 PARTITION OF logs FOR VALUES FROM ('2025-01-01') TO ('2025-07-01');
+CREATE INDEX logs_2025_id_idx ON logs_2025 (id);
+`)
+	desired := parseEnsureOnlySchema(t, parent+`
+CREATE TABLE logs_2024 PARTITION OF logs FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+CREATE TABLE logs_2025 PARTITION OF logs FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');
+CREATE INDEX logs_2025_id_idx ON logs_2025 (id);
+`)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]differ.ChangeType{
+			differ.ChangeTypeDropPartition,
+			differ.ChangeTypeAddPartition,
+			differ.ChangeTypeAddIndex,
+		},
+		changeTypes(result),
+		"the partition is dropped before it is added again, and gets its index back")
+
+	for _, change := range result.Changes[:2] {
+		assert.Equal(t, "public.logs.logs_2025", change.ObjectName)
+	}
+
+	partition, ok := result.Changes[1].Details["partition"].(*schema.Partition)
+	require.True(t, ok)
+	assert.Contains(t, partition.Definition, "'2026-01-01'")
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodePartitionBoundsChange, result.Diagnostics[0].Code)
+	assert.Equal(t, "public.logs.logs_2025", result.Diagnostics[0].ObjectName)
+}
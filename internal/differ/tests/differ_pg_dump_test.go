@@ -0,0 +1,168 @@
+package differ_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseFixture(t *testing.T, p *parser.Parser, name string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	require.NoError(t, p.ParseFile(filepath.Join("testdata", name), db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func TestPgDumpPartitionedTableDiffsClean(t *testing.T) {
+	t.Parallel()
+
+	dumpParser := parser.New()
+	current := parseFixture(t, dumpParser, "pg_dump_partitioned/dump.sql")
+	desired := parseFixture(t, parser.New(), "pg_dump_partitioned/desired.sql")
+
+	for _, warning := range dumpParser.GetWarnings() {
+		assert.Equal(t, diag.CodeSkippedStatement, warning.Code,
+			"only SET and OWNER statements should be skipped: %s", warning)
+	}
+
+	events := current.GetTable(schema.DefaultSchema, "events")
+	require.NotNil(t, events)
+	require.NotNil(t, events.PartitionStrategy)
+	require.Len(t, events.PartitionStrategy.Partitions, 2)
+	assert.Equal(t, "events_2024", events.PartitionStrategy.Partitions[0].Name)
+	assert.Equal(t, "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+		events.PartitionStrategy.Partitions[0].Definition)
+	assert.Nil(t, current.GetTable(schema.DefaultSchema, "events_2024"),
+		"an attached partition is not a standalone table")
+
+	idx := events.GetIndex("idx_events_account_kind")
+	require.NotNil(t, idx)
+	assert.True(t, idx.OnlyParent)
+	assert.Len(t, events.Indexes, 2, "partition indexes are not tracked")
+
+	require.NotNil(t, events.GetConstraint("events_pkey"))
+	require.NotNil(t, events.GetConstraint("events_account_id_fkey"))
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	for _, change := range result.Changes {
+		t.Errorf("unexpected %s change: %s", change.Type, change.Description)
+	}
+}
+
+func TestPgDumpPartitionChecksDiffsClean(t *testing.T) {
+	t.Parallel()
+
+	current := parseFixture(t, parser.New(), "pg_dump_partition_checks/dump.sql")
+	desired := parseFixture(t, parser.New(), "pg_dump_partition_checks/desired.sql")
+
+	readings := current.GetTable(schema.DefaultSchema, "readings")
+	require.NotNil(t, readings)
+	require.NotNil(t, readings.PartitionStrategy)
+
+	partitions := readings.PartitionStrategy.Partitions
+	require.Len(t, partitions, 12)
+
+	// Each dumped partition repeats the parent's CHECK and has a primary key
+	// and index attached to the parent's; only child-local objects remain.
+	for _, partition := range partitions {
+		switch partition.Name {
+		case "readings_2024_01":
+			require.Len(t, partition.Constraints, 1, partition.Name)
+			assert.Equal(t, "readings_2024_01_sensor_check", partition.Constraints[0].Name)
+			assert.Empty(t, partition.Indexes, partition.Name)
+		case "readings_2024_06":
+			assert.Empty(t, partition.Constraints, partition.Name)
+			require.Len(t, partition.Indexes, 1, partition.Name)
+			assert.Equal(t, "readings_2024_06_value_idx", partition.Indexes[0].Name)
+		default:
+			assert.Empty(t, partition.Constraints, partition.Name)
+			assert.Empty(t, partition.Indexes, partition.Name)
+		}
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	for _, change := range result.Changes {
+		t.Errorf("unexpected %s change: %s", change.Type, change.Description)
+	}
+}
+
+func TestPgDumpTimescaleDBDiffsClean(t *testing.T) {
+	t.Parallel()
+
+	current := parseFixture(t, parser.New(), "pg_dump_timescaledb/dump.sql")
+	desired := parseFixture(t, parser.New(), "pg_dump_timescaledb/desired.sql")
+
+	require.NotNil(t, current.GetTable("_timescaledb_internal", "_hyper_1_1_chunk"))
+	require.Len(t, current.ContinuousAggregates, 2)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	for _, change := range result.Changes {
+		t.Errorf("unexpected %s change: %s", change.Type, change.Description)
+	}
+
+	assert.Contains(t, result.Notes, "skipped 12 managed-by-extension objects: "+
+		"TimescaleDB internal schemas and continuous aggregate materialization tables")
+	assert.Len(t, current.Tables, 8, "the parsed schema is left as it is")
+
+	opts := differ.DefaultOptions()
+	opts.IncludeExtensionObjects = true
+
+	result, err = differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+
+	drops := make(map[differ.ChangeType]int)
+	for _, changeType := range changeTypes(result) {
+		drops[changeType]++
+	}
+
+	assert.Equal(t, 4, drops[differ.ChangeTypeDropSchema])
+	assert.Equal(t, 7, drops[differ.ChangeTypeDropTable])
+}
+
+func TestPgDumpSequencesAndIdentitiesDiffClean(t *testing.T) {
+	t.Parallel()
+
+	dumpParser := parser.New(parser.WithPgDump())
+	current := parseFixture(t, dumpParser, "pg_dump_sequences/dump.sql")
+	desired := parseFixture(t, parser.New(), "pg_dump_sequences/desired.sql")
+
+	assert.Empty(t, dumpParser.GetWarnings(),
+		"settings, ownership, privileges and sequence positions are not schema objects")
+
+	require.Len(t, current.Sequences, 1, "an identity column's sequence is part of the column")
+	assert.Equal(t, "users_id_seq", current.Sequences[0].Name)
+	assert.Equal(t, "app.users", current.Sequences[0].OwnedByTable)
+	assert.Equal(t, "id", current.Sequences[0].OwnedByColumn)
+
+	users := current.GetTable("app", "users")
+	require.NotNil(t, users)
+	assert.Equal(t, "nextval('app.users_id_seq'::regclass)", users.GetColumn("id").Default)
+
+	orders := current.GetTable("app", "orders")
+	require.NotNil(t, orders)
+	require.NotNil(t, orders.GetColumn("id").Identity)
+	assert.Equal(t, schema.IdentityAlways, orders.GetColumn("id").Identity.Generation)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	for _, change := range result.Changes {
+		t.Errorf("unexpected %s change: %s", change.Type, change.Description)
+	}
+}
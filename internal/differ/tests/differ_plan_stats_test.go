@@ -0,0 +1,208 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func syntheticPlan() *differ.DiffResult {
+	return &differ.DiffResult{Changes: []differ.Change{
+		{
+			Type:       differ.ChangeTypeAddTable,
+			Severity:   differ.SeveritySafe,
+			ObjectType: "table",
+			ObjectName: "app.orders",
+		},
+		{
+			Type:       differ.ChangeTypeAddColumn,
+			Severity:   differ.SeverityDataMigrationRequired,
+			ObjectType: "column",
+			ObjectName: "app.users",
+			Details: map[string]any{
+				"column": &schema.Column{Name: "email", DataType: "TEXT", IsNullable: false},
+			},
+		},
+		{
+			Type:       differ.ChangeTypeModifyColumnType,
+			Severity:   differ.SeveritySafe,
+			ObjectType: "column",
+			ObjectName: "app.users",
+			Details:    map[string]any{differ.DetailKeyPrecisionChange: differ.PrecisionChangeWiden},
+		},
+		{
+			Type:       differ.ChangeTypeDropColumn,
+			Severity:   differ.SeverityBreaking,
+			ObjectType: "column",
+			ObjectName: "app.users",
+		},
+		{
+			Type:       differ.ChangeTypeAddIndex,
+			Severity:   differ.SeveritySafe,
+			ObjectType: "index",
+			ObjectName: "billing.idx_invoices_due",
+			Details: map[string]any{
+				"index": &schema.Index{Schema: "billing", Name: "idx_invoices_due", TableName: "invoices"},
+			},
+		},
+		{
+			Type:       differ.ChangeTypeAddConstraint,
+			Severity:   differ.SeverityDataMigrationRequired,
+			ObjectType: "constraint",
+			ObjectName: "billing.invoices",
+			Details: map[string]any{
+				"constraint": &schema.Constraint{Name: "fk_user", Type: "FOREIGN KEY"},
+			},
+		},
+		{
+			Type:       differ.ChangeTypeDropView,
+			Severity:   differ.SeverityBreaking,
+			ObjectType: "view",
+			ObjectName: "reporting.daily",
+		},
+		{
+			Type:       differ.ChangeTypeAddSchema,
+			Severity:   differ.SeveritySafe,
+			ObjectType: "schema",
+			ObjectName: "Reporting",
+		},
+	}}
+}
+
+func TestDiffResult_PlanStats(t *testing.T) {
+	t.Parallel()
+
+	stats := syntheticPlan().PlanStats()
+
+	assert.Equal(t, 8, stats.TotalChanges)
+	assert.Equal(t, map[differ.ChangeType]int{
+		differ.ChangeTypeAddTable:         1,
+		differ.ChangeTypeAddColumn:        1,
+		differ.ChangeTypeModifyColumnType: 1,
+		differ.ChangeTypeDropColumn:       1,
+		differ.ChangeTypeAddIndex:         1,
+		differ.ChangeTypeAddConstraint:    1,
+		differ.ChangeTypeDropView:         1,
+		differ.ChangeTypeAddSchema:        1,
+	}, stats.ByType)
+	assert.Equal(t, map[differ.ChangeSeverity]int{
+		differ.SeveritySafe:                  4,
+		differ.SeverityBreaking:              2,
+		differ.SeverityDataMigrationRequired: 2,
+	}, stats.BySeverity)
+	assert.Equal(t, map[string]int{"app": 4, "billing": 2, "reporting": 2}, stats.BySchema)
+	assert.Equal(t, 6, stats.ObjectsTouched)
+	assert.Equal(t, 3, stats.TablesTouched)
+	assert.Equal(t, 4, stats.DestructiveChanges)
+	assert.Equal(t, 4, stats.UnsafeChanges)
+}
+
+func TestDiffResult_PlanStatsEmpty(t *testing.T) {
+	t.Parallel()
+
+	stats := (&differ.DiffResult{}).PlanStats()
+
+	assert.Zero(t, stats.TotalChanges)
+	assert.Empty(t, stats.ByType)
+	assert.Zero(t, stats.TablesTouched)
+	assert.Empty(t, differ.PlanSizeAdvisories(stats, &differ.PlanSizeLimits{MaxChanges: 1}))
+}
+
+func TestPlanSizeAdvisories(t *testing.T) {
+	t.Parallel()
+
+	stats := syntheticPlan().PlanStats()
+
+	tests := []struct {
+		name   string
+		limits *differ.PlanSizeLimits
+		want   []string
+	}{
+		{name: "no limits", limits: nil},
+		{name: "zero limits disabled", limits: &differ.PlanSizeLimits{}},
+		{
+			name: "exactly at every limit",
+			limits: &differ.PlanSizeLimits{
+				MaxChanges:            8,
+				MaxDestructiveChanges: 4,
+				MaxTablesTouched:      3,
+			},
+		},
+		{
+			name:   "one change over",
+			limits: &differ.PlanSizeLimits{MaxChanges: 7},
+			want: []string{
+				"plan has 8 changes (limit 7); consider splitting desired-state changes",
+			},
+		},
+		{
+			name:   "one destructive change over",
+			limits: &differ.PlanSizeLimits{MaxDestructiveChanges: 3},
+			want: []string{
+				"plan has 4 destructive changes (limit 3); consider splitting desired-state changes",
+			},
+		},
+		{
+			name:   "one table over",
+			limits: &differ.PlanSizeLimits{MaxTablesTouched: 2},
+			want: []string{
+				"plan touches 3 tables (limit 2); consider splitting desired-state changes",
+			},
+		},
+		{
+			name: "every limit crossed",
+			limits: &differ.PlanSizeLimits{
+				MaxChanges:            1,
+				MaxDestructiveChanges: 1,
+				MaxTablesTouched:      1,
+			},
+			want: []string{
+				"plan has 8 changes (limit 1); consider splitting desired-state changes",
+				"plan has 4 destructive changes (limit 1); consider splitting desired-state changes",
+				"plan touches 3 tables (limit 1); consider splitting desired-state changes",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			advisories := differ.PlanSizeAdvisories(stats, tt.limits)
+			require.Len(t, advisories, len(tt.want))
+
+			for i, advisory := range advisories {
+				assert.Equal(t, diag.CodePlanTooLarge, advisory.Code)
+				assert.Equal(t, diag.SeverityWarning, advisory.Severity)
+				assert.Equal(t, tt.want[i], advisory.Message)
+			}
+		})
+	}
+}
+
+func TestDiffer_PlanSizeOption(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{}
+	desired := &schema.Database{Tables: []schema.Table{
+		{Schema: "public", Name: "a", Columns: []schema.Column{{Name: "id", DataType: "BIGINT"}}},
+		{Schema: "public", Name: "b", Columns: []schema.Column{{Name: "id", DataType: "BIGINT"}}},
+	}}
+
+	opts := differ.DefaultOptions()
+	opts.PlanSize = &differ.PlanSizeLimits{MaxTablesTouched: 1}
+
+	result, err := differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodePlanTooLarge, result.Diagnostics[0].Code)
+	assert.Contains(t, result.Summary(), "Plan Size Advisories:\n  - plan touches 2 tables")
+	assert.Equal(t, differ.DefaultOptions().Hash(), result.OptionsHash)
+	assert.Len(t, result.Changes, 2)
+}
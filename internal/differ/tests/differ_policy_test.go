@@ -0,0 +1,168 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func docsTable(rowSecurity bool, policies ...schema.Policy) schema.Table {
+	return schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "docs",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "uuid", Position: 1},
+			{Name: "tenant_id", DataType: "uuid", Position: 2},
+		},
+		Policies:                policies,
+		RowLevelSecurityEnabled: rowSecurity,
+	}
+}
+
+func tenantPolicy(using string) schema.Policy {
+	return schema.Policy{
+		Schema:    schema.DefaultSchema,
+		Name:      "docs_tenant",
+		TableName: "docs",
+		Using:     using,
+	}
+}
+
+func changesOfType(result *differ.DiffResult, changeType differ.ChangeType) []differ.Change {
+	var changes []differ.Change
+
+	for _, change := range result.Changes {
+		if change.Type == changeType {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+func TestDiffer_PoliciesOfNewTableFollowIt(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{Tables: []schema.Table{
+		docsTable(true, tenantPolicy("tenant_id = current_setting('app.tenant')::uuid")),
+	}}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 3)
+
+	assert.Equal(t, differ.ChangeTypeAddTable, result.Changes[0].Type)
+	assert.Equal(t, differ.ChangeTypeAddPolicy, result.Changes[1].Type)
+	assert.Equal(t, differ.SeveritySafe, result.Changes[1].Severity)
+	assert.Equal(t, differ.ChangeTypeModifyTableRowSecurity, result.Changes[2].Type)
+	assert.Equal(t, true, result.Changes[2].Details["enabled"])
+}
+
+func TestDiffer_PolicyChanges(t *testing.T) {
+	t.Parallel()
+
+	restrictive := schema.Policy{
+		Schema:      schema.DefaultSchema,
+		Name:        "docs_live",
+		TableName:   "docs",
+		Command:     schema.PolicyCommandSelect,
+		Roles:       []string{"reader"},
+		Using:       "true",
+		Restrictive: true,
+	}
+	stale := schema.Policy{
+		Schema:    schema.DefaultSchema,
+		Name:      "docs_legacy",
+		TableName: "docs",
+		Using:     "true",
+	}
+
+	current := &schema.Database{Tables: []schema.Table{
+		docsTable(true, tenantPolicy("tenant_id IS NOT NULL"), stale),
+	}}
+	desired := &schema.Database{Tables: []schema.Table{
+		docsTable(true,
+			tenantPolicy("tenant_id = current_setting('app.tenant')::uuid"), restrictive),
+	}}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	added := changesOfType(result, differ.ChangeTypeAddPolicy)
+	require.Len(t, added, 1)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, added[0].Severity)
+	assert.Equal(t, "public.docs", added[0].ObjectName)
+
+	dropped := changesOfType(result, differ.ChangeTypeDropPolicy)
+	require.Len(t, dropped, 1)
+	assert.Contains(t, dropped[0].Description, "docs_legacy on public.docs")
+
+	modified := changesOfType(result, differ.ChangeTypeModifyPolicy)
+	require.Len(t, modified, 1)
+	assert.Contains(t, modified[0].Description, "docs_tenant on public.docs")
+
+	assert.Empty(t, changesOfType(result, differ.ChangeTypeModifyTableRowSecurity))
+}
+
+func TestDiffer_EquivalentPoliciesHaveNoChanges(t *testing.T) {
+	t.Parallel()
+
+	current := tenantPolicy("(tenant_id IS NOT NULL)")
+	current.Command = "all"
+	current.Roles = []string{"public"}
+
+	desired := tenantPolicy("tenant_id IS NOT NULL")
+
+	assertNoChanges(t,
+		&schema.Database{Tables: []schema.Table{docsTable(true, current)}},
+		&schema.Database{Tables: []schema.Table{docsTable(true, desired)}},
+	)
+}
+
+func TestDiffer_RowSecurityWaitsForPolicies(t *testing.T) {
+	t.Parallel()
+
+	policy := tenantPolicy("tenant_id IS NOT NULL")
+
+	enable, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{Tables: []schema.Table{docsTable(false)}},
+		&schema.Database{Tables: []schema.Table{docsTable(true, policy)}},
+	)
+	require.NoError(t, err)
+	require.Len(t, enable.Changes, 2)
+	assert.Equal(t, differ.ChangeTypeAddPolicy, enable.Changes[0].Type)
+	assert.Equal(t, differ.ChangeTypeModifyTableRowSecurity, enable.Changes[1].Type)
+	assert.Contains(t, enable.Changes[1].Description,
+		"Row level security differs: public.docs is disabled in database, "+
+			"enabled in desired schema")
+
+	disable, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{Tables: []schema.Table{docsTable(true, policy)}},
+		&schema.Database{Tables: []schema.Table{docsTable(false)}},
+	)
+	require.NoError(t, err)
+	require.Len(t, disable.Changes, 2)
+	assert.Equal(t, differ.ChangeTypeModifyTableRowSecurity, disable.Changes[0].Type)
+	assert.Equal(t, differ.ChangeTypeDropPolicy, disable.Changes[1].Type)
+}
+
+func TestDiffer_ColumnDropWaitsForPolicyUsingIt(t *testing.T) {
+	t.Parallel()
+
+	current := docsTable(true, tenantPolicy("tenant_id IS NOT NULL"))
+	desired := docsTable(true)
+	desired.Columns = desired.Columns[:1]
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{Tables: []schema.Table{current}},
+		&schema.Database{Tables: []schema.Table{desired}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, differ.ChangeTypeDropPolicy, result.Changes[0].Type)
+	assert.Equal(t, differ.ChangeTypeDropColumn, result.Changes[1].Type)
+}
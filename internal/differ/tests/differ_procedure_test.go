@@ -0,0 +1,114 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func procedureFunction(kind, body string) schema.Function {
+	fn := schema.Function{
+		Schema:        "maintenance",
+		Name:          "archive_orders",
+		ArgumentTypes: []string{"date"},
+		ArgumentNames: []string{"cutoff"},
+		ArgumentModes: []string{"IN"},
+		Language:      "plpgsql",
+		Body:          body,
+		Kind:          kind,
+	}
+
+	if kind == "" {
+		fn.ReturnType = "void"
+	}
+
+	return fn
+}
+
+func TestProcedureKey(t *testing.T) {
+	t.Parallel()
+
+	proc := procedureFunction(schema.FunctionKindProcedure, "BEGIN COMMIT; END;")
+	fn := procedureFunction("", "BEGIN END;")
+
+	assert.Equal(t, "maintenance.archive_orders(date) procedure", differ.RoutineKey(&proc))
+	assert.Equal(t, "maintenance.archive_orders(date)", differ.RoutineKey(&fn))
+}
+
+func TestDiffer_Procedures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("added", func(t *testing.T) {
+		t.Parallel()
+
+		desired := &schema.Database{Functions: []schema.Function{
+			procedureFunction(schema.FunctionKindProcedure, "BEGIN COMMIT; END;"),
+		}}
+
+		result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+		require.NoError(t, err)
+		require.Len(t, result.Changes, 1)
+
+		assert.Equal(t, differ.ChangeTypeAddFunction, result.Changes[0].Type)
+		assert.Equal(t, "Procedure maintenance.archive_orders(date) is in "+
+			"desired schema but not in database (will be created)",
+			result.Changes[0].Description)
+	})
+
+	t.Run("body normalized like a function's", func(t *testing.T) {
+		t.Parallel()
+
+		current := &schema.Database{Functions: []schema.Function{
+			procedureFunction(schema.FunctionKindProcedure, "BEGIN\n    COMMIT;\nEND;"),
+		}}
+		desired := &schema.Database{Functions: []schema.Function{
+			procedureFunction(schema.FunctionKindProcedure, "BEGIN COMMIT; END;"),
+		}}
+
+		assertNoChanges(t, current, desired)
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		t.Parallel()
+
+		current := &schema.Database{Functions: []schema.Function{
+			procedureFunction(schema.FunctionKindProcedure, "BEGIN COMMIT; END;"),
+		}}
+		desired := &schema.Database{Functions: []schema.Function{
+			procedureFunction(schema.FunctionKindProcedure, "BEGIN ROLLBACK; END;"),
+		}}
+
+		result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+		require.NoError(t, err)
+		require.Len(t, result.Changes, 1)
+
+		assert.Equal(t, differ.ChangeTypeModifyFunction, result.Changes[0].Type)
+		assert.Equal(t, "Procedure maintenance.archive_orders(date) differs "+
+			"between database and desired schema (will be replaced)",
+			result.Changes[0].Description)
+	})
+
+	t.Run("function of the same signature", func(t *testing.T) {
+		t.Parallel()
+
+		current := &schema.Database{Functions: []schema.Function{
+			procedureFunction("", "BEGIN END;"),
+		}}
+		desired := &schema.Database{Functions: []schema.Function{
+			procedureFunction("", "BEGIN END;"),
+			procedureFunction(schema.FunctionKindProcedure, "BEGIN END;"),
+		}}
+
+		result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+		require.NoError(t, err)
+		require.Len(t, result.Changes, 1, "the function stays as it is")
+
+		assert.Equal(t, differ.ChangeTypeAddFunction, result.Changes[0].Type)
+		assert.Equal(t, "maintenance.archive_orders(date) procedure",
+			result.Changes[0].ObjectName)
+	})
+}
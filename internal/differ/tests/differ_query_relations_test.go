@@ -0,0 +1,28 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+func TestQueryRelations(t *testing.T) {
+	t.Parallel()
+
+	relations, ok := differ.QueryRelations(`
+SELECT o.id, o.total, c.name, x.id
+FROM sales.orders o
+JOIN customers AS c ON c.id = o.customer_id
+JOIN orders x ON x.id = o.id
+CROSS JOIN LATERAL jsonb_array_elements(o.items) e`)
+	require.True(t, ok)
+
+	assert.Equal(t, []differ.QueryRelation{
+		{Schema: "sales", Name: "orders", Columns: []string{"id", "total", "customer_id", "items"}},
+		{Name: "customers", Columns: []string{"name", "id"}},
+		{Name: "orders", Columns: []string{"id"}},
+	}, relations)
+}
@@ -0,0 +1,154 @@
+package differ_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func refreshedViewDatabase(definition, indexName string, columns ...string) *schema.Database {
+	return &schema.Database{MaterializedViews: []schema.MaterializedView{{
+		Schema:     schema.DefaultSchema,
+		Name:       "daily_totals",
+		Definition: definition,
+		Indexes: []schema.Index{{
+			Schema:    schema.DefaultSchema,
+			Name:      indexName,
+			TableName: "daily_totals",
+			Columns:   columns,
+			IsUnique:  true,
+		}},
+	}}}
+}
+
+func refreshHazards(result *differ.DiffResult) []diag.Warning {
+	var hazards []diag.Warning
+
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodeMatviewConcurrentRefreshHazard {
+			hazards = append(hazards, warning)
+		}
+	}
+
+	return hazards
+}
+
+func TestDiffer_ConcurrentRefreshHazards(t *testing.T) {
+	t.Parallel()
+
+	const definition = "SELECT day, sum(total) AS total FROM orders GROUP BY day"
+
+	// A name of the maximum identifier length has no room for the suffix.
+	longName := "daily_totals_" + strings.Repeat("x", 50)
+	withoutIndex := refreshedViewDatabase(definition, "daily_totals_day_key")
+	withoutIndex.MaterializedViews[0].Indexes = nil
+
+	tests := []struct {
+		name        string
+		current     *schema.Database
+		desired     *schema.Database
+		swap        bool
+		wantHazard  string
+		wantSwapped string
+	}{
+		{
+			name:    "unique index rebuilt",
+			current: refreshedViewDatabase(definition, "daily_totals_day_key", "day"),
+			desired: refreshedViewDatabase(definition, "daily_totals_day_key", "day", "total"),
+			wantHazard: "unique index daily_totals_day_key on materialized view " +
+				"public.daily_totals is dropped and recreated",
+		},
+		{
+			name:        "unique index swapped in",
+			current:     refreshedViewDatabase(definition, "daily_totals_day_key", "day"),
+			desired:     refreshedViewDatabase(definition, "daily_totals_day_key", "day", "total"),
+			swap:        true,
+			wantSwapped: "daily_totals_day_key_pgtofu_swap",
+		},
+		{
+			name:        "long name swapped in",
+			current:     refreshedViewDatabase(definition, longName, "day"),
+			desired:     refreshedViewDatabase(definition, longName, "day", "total"),
+			swap:        true,
+			wantSwapped: "daily_totals_xxxxxxxxxxxxxxxxxxxxxxxxxxxxx_dfcef2d7_pgtofu_swap",
+		},
+		{
+			name:    "unique index dropped",
+			current: refreshedViewDatabase(definition, "daily_totals_day_key", "day"),
+			desired: withoutIndex,
+			wantHazard: "unique index daily_totals_day_key on materialized view " +
+				"public.daily_totals is dropped,",
+		},
+		{
+			name:    "view recreated",
+			current: refreshedViewDatabase(definition, "daily_totals_day_key", "day"),
+			desired: refreshedViewDatabase(
+				"SELECT day, sum(total) AS total FROM orders WHERE total > 0 GROUP BY day",
+				"daily_totals_day_key", "day",
+			),
+			wantHazard: "materialized view public.daily_totals is dropped and recreated, so REFRESH " +
+				"MATERIALIZED VIEW CONCURRENTLY fails until it and its unique index " +
+				"daily_totals_day_key exist again",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := differ.DefaultOptions()
+			opts.SwapMaterializedViewIndexes = tt.swap
+
+			result, err := differ.New(opts).Compare(tt.current, tt.desired)
+			require.NoError(t, err)
+
+			hazards := refreshHazards(result)
+
+			if tt.wantHazard == "" {
+				assert.Empty(t, hazards)
+			} else {
+				require.Len(t, hazards, 1)
+				assert.Contains(t, hazards[0].Message, tt.wantHazard)
+				assert.Contains(t, result.Summary(), "Concurrent Refresh Hazards:\n  - "+
+					hazards[0].Message)
+			}
+
+			for _, change := range result.Changes {
+				if change.Type == differ.ChangeTypeModifyIndex {
+					swapName, _ := change.Details[differ.DetailKeySwapIndex].(string)
+					assert.Equal(t, tt.wantSwapped, swapName)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffer_TableIndexRebuildIsNoRefreshHazard(t *testing.T) {
+	t.Parallel()
+
+	table := func(columns ...string) *schema.Database {
+		return &schema.Database{Tables: []schema.Table{{
+			Schema:  schema.DefaultSchema,
+			Name:    "orders",
+			Columns: []schema.Column{{Name: "day", DataType: "date", Position: 1}},
+			Indexes: []schema.Index{{
+				Schema:    schema.DefaultSchema,
+				Name:      "orders_day_key",
+				TableName: "orders",
+				Columns:   columns,
+				IsUnique:  true,
+			}},
+		}}}
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(table("day"), table("day", "id"))
+	require.NoError(t, err)
+	assert.Empty(t, refreshHazards(result))
+	assert.False(t, strings.Contains(result.Summary(), "Concurrent Refresh Hazards"))
+}
@@ -0,0 +1,137 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func refreshPolicyDatabase(query string, policy *schema.RefreshPolicy) *schema.Database {
+	return &schema.Database{
+		Hypertables: []schema.Hypertable{
+			{Schema: schema.DefaultSchema, TableName: "metrics"},
+		},
+		ContinuousAggregates: []schema.ContinuousAggregate{{
+			Schema:           schema.DefaultSchema,
+			ViewName:         "metrics_hourly",
+			HypertableSchema: schema.DefaultSchema,
+			HypertableName:   "metrics",
+			Query:            query,
+			RefreshPolicy:    policy,
+		}},
+	}
+}
+
+func TestDiffer_RefreshPolicyChanges(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT time_bucket('1 hour', time) AS bucket FROM metrics"
+
+	hourly := &schema.RefreshPolicy{
+		StartOffset:      "3 days",
+		EndOffset:        "1 hour",
+		ScheduleInterval: "1 hour",
+	}
+
+	tests := []struct {
+		name     string
+		current  *schema.RefreshPolicy
+		desired  *schema.RefreshPolicy
+		wantType differ.ChangeType
+		wantDesc string
+	}{
+		{
+			name:    "schedule changed",
+			current: hourly,
+			desired: &schema.RefreshPolicy{
+				StartOffset:      "3 days",
+				EndOffset:        "1 hour",
+				ScheduleInterval: "30 minutes",
+			},
+			wantType: differ.ChangeTypeModifyRefreshPolicy,
+			wantDesc: "Refresh policy differs: continuous aggregate public.metrics_hourly is " +
+				"start_offset 3 days, end_offset 1 hour, schedule_interval 1 hour in database, " +
+				"start_offset 3 days, end_offset 1 hour, schedule_interval 30 minutes " +
+				"in desired schema",
+		},
+		{
+			name:     "policy added",
+			desired:  hourly,
+			wantType: differ.ChangeTypeAddRefreshPolicy,
+		},
+		{
+			name:     "policy dropped",
+			current:  hourly,
+			wantType: differ.ChangeTypeDropRefreshPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				refreshPolicyDatabase(query, tt.current),
+				refreshPolicyDatabase(query, tt.desired),
+			)
+			require.NoError(t, err)
+			require.Len(t, result.Changes, 1)
+
+			change := result.Changes[0]
+			assert.Equal(t, tt.wantType, change.Type)
+			assert.Equal(t, differ.SeveritySafe, change.Severity)
+			assert.Equal(t, "refresh_policy", change.ObjectType)
+			assert.Equal(t, "public.metrics_hourly", change.ObjectName)
+
+			if tt.wantDesc != "" {
+				assert.Equal(t, tt.wantDesc, change.Description)
+			}
+		})
+	}
+}
+
+func TestDiffer_RefreshPolicyEquivalentForms(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT time_bucket('1 hour', time) AS bucket FROM metrics"
+
+	current := refreshPolicyDatabase(query, &schema.RefreshPolicy{
+		StartOffset:      "3 days",
+		ScheduleInterval: "01:00:00",
+		InitialStart:     "2026-01-01 00:00:00+00",
+	})
+	desired := refreshPolicyDatabase(query, &schema.RefreshPolicy{
+		StartOffset:      "3 days",
+		EndOffset:        "NULL",
+		ScheduleInterval: "1 hour",
+	})
+
+	assertNoChanges(t, current, desired)
+}
+
+func TestDiffer_RecreatedAggregateCarriesRefreshPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := func(startOffset string) *schema.RefreshPolicy {
+		return &schema.RefreshPolicy{
+			StartOffset:      startOffset,
+			EndOffset:        "1 hour",
+			ScheduleInterval: "1 hour",
+		}
+	}
+
+	current := refreshPolicyDatabase(
+		"SELECT time_bucket('1 hour', time) AS bucket FROM metrics", policy("3 days"))
+	desired := refreshPolicyDatabase(
+		"SELECT time_bucket('1 hour', time) AS bucket, count(*) AS total FROM metrics",
+		policy("7 days"))
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifyContinuousAggregate, result.Changes[0].Type)
+}
@@ -0,0 +1,95 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDiffer_RemoteAccessViews(t *testing.T) {
+	t.Parallel()
+
+	orders := schema.Table{
+		Schema:  schema.DefaultSchema,
+		Name:    "orders",
+		Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+	}
+
+	tests := []struct {
+		name       string
+		definition string
+		wantDeps   []string
+		wantRemote bool
+	}{
+		{
+			name: "dblink query string",
+			definition: "SELECT * FROM dblink('hostconn', 'SELECT id FROM orders JOIN public.orders o " +
+				"USING (id)') AS t(id bigint)",
+			wantRemote: true,
+		},
+		{
+			name: "dollar-quoted dblink query",
+			definition: "SELECT t.id FROM public.customers c " +
+				"JOIN dblink_open('cur', $q$SELECT id FROM orders$q$) AS t(id bigint) ON true",
+			wantDeps:   []string{"public.customers"},
+			wantRemote: true,
+		},
+		{
+			name:       "local query with a literal naming a table",
+			definition: "SELECT id, 'copied from orders' AS note FROM public.customers",
+			wantDeps:   []string{"public.customers"},
+		},
+		{
+			name:       "dblink named only in a literal",
+			definition: "SELECT id, 'dblink(x)' AS note FROM orders",
+			wantDeps:   []string{"orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			desired := &schema.Database{
+				Tables: []schema.Table{orders},
+				Views: []schema.View{{
+					Schema:     schema.DefaultSchema,
+					Name:       "legacy_orders",
+					Definition: tt.definition,
+				}},
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+			require.NoError(t, err)
+
+			idx := changeIndex(result, differ.ChangeTypeAddView, "public.legacy_orders")
+			require.GreaterOrEqual(t, idx, 0)
+			assert.ElementsMatch(t, tt.wantDeps, result.Changes[idx].DependsOn)
+
+			var remote []diag.Warning
+
+			for _, warning := range result.Diagnostics {
+				if warning.Code == diag.CodeRemoteAccess {
+					remote = append(remote, warning)
+				}
+			}
+
+			if !tt.wantRemote {
+				assert.Empty(t, remote)
+				return
+			}
+
+			require.Len(t, remote, 1)
+			assert.Equal(t, diag.SeverityInfo, remote[0].Severity)
+			assert.Equal(t, "public.legacy_orders", remote[0].ObjectName)
+			assert.Equal(t, "view public.legacy_orders reads from another server through dblink "+
+				"or postgres_fdw; pgtofu cannot check the objects its remote queries use",
+				remote[0].Message)
+		})
+	}
+}
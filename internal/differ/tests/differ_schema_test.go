@@ -173,3 +173,31 @@ func TestDiffer_SchemaDependencyOrdering(t *testing.T) {
 		t.Errorf("expected second change to be ADD_TABLE, got %s", result.Changes[1].Type)
 	}
 }
+
+func TestDiffer_NeverDropsPublicSchema(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"public", `"public"`} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			current := &schema.Database{
+				Schemas: []schema.Schema{{Name: name}, {Name: "app"}},
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(current, &schema.Database{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result.Changes) != 1 {
+				t.Fatalf("expected 1 change, got %d", len(result.Changes))
+			}
+
+			if result.Changes[0].Type != differ.ChangeTypeDropSchema ||
+				result.Changes[0].ObjectName != "app" {
+				t.Errorf("expected only app to be dropped, got %s", result.Changes[0].Description)
+			}
+		})
+	}
+}
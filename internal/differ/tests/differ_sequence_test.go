@@ -0,0 +1,162 @@
+package differ_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// extractedSequence is order_seq as the extractor reports it once the live
+// sequence has advanced: pg_sequence keeps the START WITH it was created with.
+func extractedSequence(start, increment int64) *schema.Database {
+	return &schema.Database{Sequences: []schema.Sequence{{
+		Schema:     schema.DefaultSchema,
+		Name:       "order_seq",
+		DataType:   "bigint",
+		StartValue: start,
+		MinValue:   1,
+		MaxValue:   math.MaxInt64,
+		Increment:  increment,
+		CacheSize:  1,
+	}}}
+}
+
+func parseSequenceSQL(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(sql, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func compareSequences(
+	t *testing.T,
+	enforceStart bool,
+	current, desired *schema.Database,
+) []differ.Change {
+	t.Helper()
+
+	opts := differ.DefaultOptions()
+	opts.EnforceSequenceStart = enforceStart
+
+	result, err := differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+
+	return result.Changes
+}
+
+func TestSequenceStartIsCreationOnlyByDefault(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSequenceSQL(t, `CREATE SEQUENCE order_seq START WITH 1000;`)
+
+	assert.Empty(t, compareSequences(t, false, extractedSequence(1, 1), desired))
+
+	changes := compareSequences(t, false, extractedSequence(1, 2), desired)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifySequence, changes[0].Type)
+	assert.Equal(t, "Sequence public.order_seq differs between database and desired schema "+
+		"(increment 2 -> 1)",
+		changes[0].Description)
+	assert.Equal(t, false, changes[0].Details["enforce_start"])
+}
+
+func TestSequenceStartEnforced(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSequenceSQL(t, `CREATE SEQUENCE order_seq START WITH 1000;`)
+
+	changes := compareSequences(t, true, extractedSequence(1, 1), desired)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifySequence, changes[0].Type)
+	assert.Equal(t, differ.SeveritySafe, changes[0].Severity)
+	assert.Contains(t, changes[0].Description, "(start 1 -> 1000)")
+	assert.Contains(t, changes[0].Description, "the current value is not moved")
+	assert.Equal(t, true, changes[0].Details["enforce_start"])
+
+	assert.Empty(t, compareSequences(t, true, extractedSequence(1000, 1), desired))
+}
+
+func TestSequenceDeclaredDefaultsMatchExtracted(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSequenceSQL(t, `CREATE SEQUENCE order_seq
+		START WITH 1 INCREMENT BY 1 NO MINVALUE NO MAXVALUE CACHE 1;`)
+
+	assert.Empty(t, compareSequences(t, true, extractedSequence(1, 1), desired))
+}
+
+func TestSequenceCacheAndOwnerDiffer(t *testing.T) {
+	t.Parallel()
+
+	current := extractedSequence(1, 1)
+	current.Sequences[0].OwnedByTable = "orders"
+	current.Sequences[0].OwnedByColumn = "legacy_id"
+
+	desired := parseSequenceSQL(t, `CREATE SEQUENCE order_seq CACHE 20 OWNED BY public.orders.id;`)
+
+	changes := compareSequences(t, false, current, desired)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.ChangeTypeModifySequence, changes[0].Type)
+	assert.Equal(t, "Sequence public.order_seq differs between database and desired schema "+
+		"(cache 1 -> 20, owned by public.orders.legacy_id -> public.orders.id)",
+		changes[0].Description)
+	assert.Equal(t, int64(1), changes[0].Details["old_cache"])
+	assert.Equal(t, int64(20), changes[0].Details["new_cache"])
+	assert.Equal(t, "public.orders.legacy_id", changes[0].Details["old_owned_by"])
+	assert.Equal(t, "public.orders.id", changes[0].Details["new_owned_by"])
+	assert.NotContains(t, changes[0].Details, "old_increment")
+
+	changes = compareSequences(t, false, extractedSequence(1, 1), desired)
+	require.Len(t, changes, 1)
+	assert.Contains(t, changes[0].Description, "owned by none -> public.orders.id")
+	assert.Equal(t, "", changes[0].Details["old_owned_by"])
+}
+
+func TestSequenceOwnedBySerialColumn(t *testing.T) {
+	t.Parallel()
+
+	const ordersTable = `CREATE TABLE orders (id bigserial PRIMARY KEY, note text);`
+
+	serialSequence := schema.Sequence{
+		Schema:        schema.DefaultSchema,
+		Name:          "orders_id_seq",
+		DataType:      "bigint",
+		StartValue:    1,
+		MinValue:      1,
+		MaxValue:      math.MaxInt64,
+		Increment:     1,
+		CacheSize:     1,
+		OwnedByTable:  "orders",
+		OwnedByColumn: "id",
+	}
+
+	current := parseSequenceSQL(t, ordersTable)
+	current.Sequences = []schema.Sequence{serialSequence}
+
+	assert.Empty(t, compareSequences(t, false, current, parseSequenceSQL(t, ordersTable)))
+
+	explicit := parseSequenceSQL(t, `CREATE SEQUENCE orders_id_seq;
+		CREATE TABLE orders (
+			id bigint DEFAULT nextval('orders_id_seq'::regclass) PRIMARY KEY,
+			note text
+		);`)
+	assert.Empty(t, compareSequences(t, false, current, explicit))
+
+	withoutColumn := parseSequenceSQL(t, `CREATE TABLE orders (note text);`)
+	changes := compareSequences(t, false, current, withoutColumn)
+	require.NotEmpty(t, changes)
+
+	for _, change := range changes {
+		assert.NotEqual(t, differ.ChangeTypeDropSequence, change.Type, change.Description)
+	}
+}
@@ -0,0 +1,125 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const (
+	// synthesizedTable leaves too little room for the columns and suffix of a
+	// generated constraint name.
+	synthesizedTable = "customer_notification_preferences_by_channel_and_locale"
+	// legacyKeyName is what older versions named both foreign keys of
+	// synthesizedTable, by cutting the generated names at 63 bytes.
+	legacyKeyName = "customer_notification_preferences_by_channel_and_locale_custome"
+)
+
+func parseSynthesizedSchema(t *testing.T, constraints string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(`CREATE TABLE customers (id BIGINT PRIMARY KEY);
+CREATE TABLE `+synthesizedTable+` (
+    id BIGINT PRIMARY KEY,
+    customer_account_id BIGINT,
+    customer_channel_id BIGINT`+constraints+`
+);`, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func TestDiffer_LegacyTruncatedConstraintNameRenamed(t *testing.T) {
+	t.Parallel()
+
+	current := parseSynthesizedSchema(t, `,
+    CONSTRAINT `+legacyKeyName+` FOREIGN KEY (customer_account_id) REFERENCES customers (id)`)
+	desired := parseSynthesizedSchema(t, `,
+    FOREIGN KEY (customer_account_id) REFERENCES customers (id)`)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+
+	change := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeModifyConstraint, change.Type)
+	assert.Equal(t, differ.SeveritySafe, change.Severity)
+	assert.Equal(t, true, change.Details[differ.DetailKeyRenameOnly])
+	assert.Equal(t, "FOREIGN KEY constraint "+legacyKeyName+" on public."+synthesizedTable+
+		" is named customer_notification_preferences_by_channel_and_3762e82e_fkey "+
+		"in desired schema (will be renamed)", change.Description)
+
+	require.Len(t, result.Notes, 1)
+	assert.Contains(t, result.Notes[0], "constraint "+legacyKeyName+" on public."+synthesizedTable+
+		" is renamed to customer_notification_preferences_by_channel_and_3762e82e_fkey")
+
+	assert.Zero(t, result.PlanStats().UnsafeChanges)
+}
+
+func TestDiffer_LegacyTruncatedConstraintNameNotRenamed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		want    []differ.ChangeType
+	}{
+		{
+			name: "definition changed",
+			current: `,
+    CONSTRAINT ` + legacyKeyName + ` FOREIGN KEY (customer_account_id) REFERENCES customers (id)`,
+			desired: `,
+    FOREIGN KEY (customer_account_id) REFERENCES customers (id) ON DELETE CASCADE`,
+			want: []differ.ChangeType{
+				differ.ChangeTypeAddConstraint, differ.ChangeTypeDropConstraint,
+			},
+		},
+		{
+			// Only the foreign key the legacy name belongs to takes it over;
+			// the other one, cut to the same name before, is added.
+			name: "legacy name belongs to another constraint",
+			current: `,
+    CONSTRAINT ` + legacyKeyName + ` FOREIGN KEY (customer_account_id) REFERENCES customers (id)`,
+			desired: `,
+    FOREIGN KEY (customer_account_id) REFERENCES customers (id),
+    FOREIGN KEY (customer_channel_id) REFERENCES customers (id)`,
+			want: []differ.ChangeType{
+				differ.ChangeTypeAddConstraint, differ.ChangeTypeModifyConstraint,
+			},
+		},
+		{
+			name: "legacy name still desired",
+			current: `,
+    CONSTRAINT ` + legacyKeyName + ` FOREIGN KEY (customer_account_id) REFERENCES customers (id)`,
+			desired: `,
+    CONSTRAINT ` + legacyKeyName + ` FOREIGN KEY (customer_account_id) REFERENCES customers (id),
+    FOREIGN KEY (customer_account_id) REFERENCES customers (id)`,
+			want: []differ.ChangeType{differ.ChangeTypeAddConstraint},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				parseSynthesizedSchema(t, tt.current), parseSynthesizedSchema(t, tt.desired))
+			require.NoError(t, err)
+
+			types := make([]differ.ChangeType, 0, len(result.Changes))
+			for _, change := range result.Changes {
+				types = append(types, change.Type)
+			}
+
+			assert.ElementsMatch(t, tt.want, types)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const recreationCurrentSQL = `
+CREATE TABLE job_progress (
+    provider VARCHAR(50) NOT NULL,
+    job_name VARCHAR(50) NOT NULL,
+    job_type VARCHAR(50) NOT NULL,
+    last_item_id BIGINT,
+    last_item_time TIMESTAMPTZ,
+    last_batch_id BIGINT,
+    total_processed BIGINT,
+    batch_size INTEGER,
+    last_error TEXT,
+    error_count INTEGER,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT job_progress_pkey PRIMARY KEY (provider, job_name, job_type)
+);
+
+CREATE INDEX idx_job_progress_provider_type ON job_progress (provider, job_type);
+
+CREATE VIEW job_status AS SELECT provider, job_name FROM job_progress;
+`
+
+const recreationDesiredSQL = `
+CREATE TABLE job_progress (
+    source VARCHAR(50) NOT NULL,
+    job_name VARCHAR(50) NOT NULL,
+    job_type VARCHAR(20) NOT NULL,
+    last_run_time TIMESTAMPTZ,
+    last_source_id BIGINT,
+    total_runs BIGINT,
+    batch_size INTEGER,
+    last_error TEXT,
+    error_count INTEGER,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT job_progress_pkey_new PRIMARY KEY (source, job_name, job_type)
+);
+
+CREATE INDEX idx_job_progress_source_type ON job_progress (source, job_type);
+
+CREATE VIEW job_status AS SELECT source, job_name FROM job_progress;
+`
+
+func compareRecreation(
+	t *testing.T,
+	thresholds *differ.TableRecreationThresholds,
+	currentSQL, desiredSQL string,
+) *differ.DiffResult {
+	t.Helper()
+
+	opts := differ.DefaultOptions()
+	opts.TableRecreation = thresholds
+
+	result, err := differ.New(opts).Compare(
+		parseEnsureOnlySchema(t, currentSQL),
+		parseEnsureOnlySchema(t, desiredSQL),
+	)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestTableRecreation_Disabled(t *testing.T) {
+	t.Parallel()
+
+	result := compareRecreation(t, nil, recreationCurrentSQL, recreationDesiredSQL)
+
+	assert.NotContains(t, changeTypes(result), differ.ChangeTypeRecreateTable)
+	assert.Contains(t, changeTypes(result), differ.ChangeTypeDropColumn)
+	assert.Greater(t, len(result.Changes), 15)
+}
+
+func TestTableRecreation_ColumnChangeRatio(t *testing.T) {
+	t.Parallel()
+
+	result := compareRecreation(t, differ.DefaultTableRecreationThresholds(),
+		recreationCurrentSQL, recreationDesiredSQL)
+
+	require.Len(t, result.GetChangesByType(differ.ChangeTypeRecreateTable), 1)
+
+	for _, change := range result.Changes {
+		if change.Type == differ.ChangeTypeRecreateTable || change.ObjectType == "view" {
+			continue
+		}
+
+		t.Errorf("unexpected %s change left beside the recreation: %s",
+			change.Type, change.Description)
+	}
+
+	recreate := result.GetChangesByType(differ.ChangeTypeRecreateTable)[0]
+	assert.Equal(t, "public.job_progress", recreate.ObjectName)
+	assert.Equal(t, differ.SeverityDataMigrationRequired, recreate.Severity)
+
+	current, ok := recreate.Details["current"].(*schema.Table)
+	require.True(t, ok)
+	assert.Equal(t, "provider", current.Columns[0].Name)
+
+	desired, ok := recreate.Details["desired"].(*schema.Table)
+	require.True(t, ok)
+	assert.Equal(t, "source", desired.Columns[0].Name)
+
+	reason, ok := recreate.Details["reason"].(string)
+	require.True(t, ok)
+	assert.Equal(t, "10 of 16 columns change (62%), above the 50% column change threshold", reason)
+
+	require.Len(t, result.Notes, 1)
+	assert.Contains(t, result.Notes[0], "public.job_progress is recreated instead of altered")
+	assert.Contains(t, result.Summary(), reason)
+}
+
+func TestTableRecreation_PrimaryKeyReplacement(t *testing.T) {
+	t.Parallel()
+
+	thresholds := &differ.TableRecreationThresholds{PrimaryKeyColumnChanges: 3}
+
+	result := compareRecreation(t, thresholds, recreationCurrentSQL, recreationDesiredSQL)
+
+	recreate := result.GetChangesByType(differ.ChangeTypeRecreateTable)
+	require.Len(t, recreate, 1)
+	assert.Equal(t,
+		"the primary key is replaced and 10 columns change (threshold 3)",
+		recreate[0].Details["reason"])
+}
+
+func TestTableRecreation_BelowThreshold(t *testing.T) {
+	t.Parallel()
+
+	current := `CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT,
+    created_at TIMESTAMPTZ NOT NULL
+);`
+
+	desired := `CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    email TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);`
+
+	thresholds := differ.DefaultTableRecreationThresholds()
+	withHeuristic := compareRecreation(t, thresholds, current, desired)
+	withoutHeuristic := compareRecreation(t, nil, current, desired)
+
+	assert.NotContains(t, changeTypes(withHeuristic), differ.ChangeTypeRecreateTable)
+	assert.ElementsMatch(t, changeTypes(withoutHeuristic), changeTypes(withHeuristic))
+	assert.Empty(t, withHeuristic.Notes)
+}
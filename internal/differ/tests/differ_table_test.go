@@ -465,3 +465,65 @@ func TestDiffer_DetectsCharLengthChange(t *testing.T) {
 		t.Fatalf("expected new_type CHAR(3), got %s", newType)
 	}
 }
+
+func TestTableComparator_CompareTables(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "users",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "bigint", IsNullable: false, Position: 1},
+			{Name: "email", DataType: "text", IsNullable: false, Position: 2},
+			{Name: "legacy", DataType: "text", IsNullable: true, Position: 3},
+		},
+	}
+
+	desired := &schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "users",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "bigint", IsNullable: false, Position: 1},
+			{Name: "email", DataType: "varchar(255)", IsNullable: false, Position: 2},
+			{Name: "name", DataType: "text", IsNullable: true, Position: 3},
+		},
+		Constraints: []schema.Constraint{
+			{Name: "users_email_key", Type: schema.ConstraintUnique, Columns: []string{"email"}},
+		},
+	}
+
+	changes := differ.NewTableComparator(differ.DefaultOptions()).CompareTables(current, desired)
+
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		descriptions = append(descriptions, change.Description)
+	}
+
+	want := []string{
+		"Column public.users.legacy exists in database but not in desired schema " +
+			"(will be dropped)",
+		"Column public.users.name (text) is in desired schema but not in database " +
+			"(will be created)",
+		"Column type differs: public.users.email is text in database, " +
+			"varchar(255) in desired schema",
+		"UNIQUE constraint users_email_key on public.users is in desired schema " +
+			"but not in database (will be created)",
+	}
+
+	if len(descriptions) != len(want) {
+		t.Fatalf("got %d changes %v, want %v", len(descriptions), descriptions, want)
+	}
+
+	for i := range want {
+		if descriptions[i] != want[i] {
+			t.Errorf("change %d = %q, want %q", i, descriptions[i], want[i])
+		}
+	}
+
+	if changes := differ.NewTableComparator(differ.DefaultOptions()).CompareTables(
+		current,
+		current,
+	); len(changes) != 0 {
+		t.Errorf("expected no changes for identical tables, got %d", len(changes))
+	}
+}
@@ -0,0 +1,116 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func targetSchemaOptions(schemas ...string) *differ.Options {
+	opts := differ.DefaultOptions()
+	opts.TargetSchemas = schemas
+
+	return opts
+}
+
+func idTable(schemaName, name string) schema.Table {
+	return schema.Table{
+		Schema:  schemaName,
+		Name:    name,
+		Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+	}
+}
+
+func TestDiffer_TargetSchemasKeepOtherSchemasOfCurrent(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Schemas: []schema.Schema{{Name: "analytics"}},
+		Tables:  []schema.Table{idTable("app", "users"), idTable("analytics", "events")},
+		Views: []schema.View{
+			{Schema: "analytics", Name: "daily", Definition: "SELECT 1"},
+		},
+	}
+	desired := &schema.Database{Tables: []schema.Table{idTable("app", "users")}}
+
+	result, err := differ.New(targetSchemaOptions("app", "public")).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Changes)
+	assert.Empty(t, result.Diagnostics)
+	assert.Contains(t, result.Notes,
+		"skipped 3 database objects outside the target schemas app, public")
+}
+
+func TestDiffer_TargetSchemasIgnoreDesiredObjectsOutsideThem(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{
+		Tables: []schema.Table{idTable("app", "users"), idTable("analytics", "foo")},
+	}
+
+	result, err := differ.New(targetSchemaOptions("app")).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, "app.users", result.Changes[0].ObjectName)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodeOutsideTargetSchemas, result.Diagnostics[0].Code)
+	assert.Equal(t, "analytics.foo", result.Diagnostics[0].ObjectName)
+	assert.Equal(t, "table analytics.foo of the desired schema is outside the target "+
+		"schemas app and is ignored", result.Diagnostics[0].Message)
+}
+
+func TestDiffer_TargetSchemasAllowReferencesAcrossThem(t *testing.T) {
+	t.Parallel()
+
+	orders := idTable("app", "orders")
+	orders.Columns = append(orders.Columns,
+		schema.Column{Name: "log_id", DataType: "bigint", Position: 2})
+	orders.Constraints = []schema.Constraint{{
+		Name:              "orders_log_id_fkey",
+		Type:              schema.ConstraintForeignKey,
+		Columns:           []string{"log_id"},
+		ReferencedSchema:  "audit",
+		ReferencedTable:   "log",
+		ReferencedColumns: []string{"id"},
+	}}
+
+	current := &schema.Database{Tables: []schema.Table{orders, idTable("audit", "log")}}
+	desired := &schema.Database{Tables: []schema.Table{orders}}
+
+	result, err := differ.New(targetSchemaOptions("app")).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Changes)
+	assert.Empty(t, result.Diagnostics)
+}
+
+func TestDiffer_TargetSchemasAreNormalized(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{Tables: []schema.Table{idTable("public", "users")}}
+
+	result, err := differ.New(targetSchemaOptions("PUBLIC")).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Empty(t, result.Diagnostics)
+}
+
+func TestOptionsHash_TargetSchemas(t *testing.T) {
+	t.Parallel()
+
+	base := differ.DefaultOptions().Hash()
+
+	assert.NotEqual(t, base, targetSchemaOptions("app").Hash())
+	assert.NotEqual(t, targetSchemaOptions("app").Hash(),
+		targetSchemaOptions("app", "public").Hash())
+	assert.Equal(t, targetSchemaOptions("app", "public").Hash(),
+		targetSchemaOptions("public", "app", "app").Hash(),
+		"the order and repetition of target schemas do not matter")
+}
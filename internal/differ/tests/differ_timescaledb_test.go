@@ -3,6 +3,10 @@ package differ_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
@@ -102,6 +106,162 @@ func TestDiffer_CompareTimescaleDB(t *testing.T) {
 			expectedChanges: 0,
 			expectedTypes:   []differ.ChangeType{},
 		},
+		{
+			name: "compression policy interval changed",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					compressedHypertableWithPolicy("7 days"),
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					compressedHypertableWithPolicy("14 days"),
+				},
+			},
+			expectedChanges: 1,
+			expectedTypes:   []differ.ChangeType{differ.ChangeTypeModifyCompressionSchedule},
+		},
+		{
+			name: "compression policy interval normalized",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					compressedHypertableWithPolicy("7 days"),
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					compressedHypertableWithPolicy("7 DAY"),
+				},
+			},
+			expectedChanges: 0,
+			expectedTypes:   []differ.ChangeType{},
+		},
+		{
+			name: "disabling compression drops policy without schedule change",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					compressedHypertableWithPolicy("7 days"),
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{Schema: schema.DefaultSchema, TableName: "metrics"},
+				},
+			},
+			expectedChanges: 1,
+			expectedTypes:   []differ.ChangeType{differ.ChangeTypeDropCompressionPolicy},
+		},
+		{
+			name: "add time and space dimensions",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{Schema: schema.DefaultSchema, TableName: "metrics", TimeColumnName: "time"},
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:         schema.DefaultSchema,
+						TableName:      "metrics",
+						TimeColumnName: "time",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "7 days"},
+							{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 8},
+						},
+					},
+				},
+			},
+			expectedChanges: 2,
+			expectedTypes: []differ.ChangeType{
+				differ.ChangeTypeAddDimension,
+				differ.ChangeTypeAddDimension,
+			},
+		},
+		{
+			name: "dimension interval normalized",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "metrics",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "7 days"},
+						},
+					},
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "metrics",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "7 day"},
+						},
+					},
+				},
+			},
+			expectedChanges: 0,
+			expectedTypes:   []differ.ChangeType{},
+		},
+		{
+			name: "dimension without recorded partition count",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "metrics",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "device_id", Type: schema.DimensionSpace},
+						},
+					},
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "metrics",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 4},
+						},
+					},
+				},
+			},
+			expectedChanges: 0,
+			expectedTypes:   []differ.ChangeType{},
+		},
+		{
+			name: "changed and removed dimensions need manual migration",
+			current: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "metrics",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 4},
+							{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "7 days"},
+						},
+					},
+				},
+			},
+			desired: &schema.Database{
+				Hypertables: []schema.Hypertable{
+					{
+						Schema:    schema.DefaultSchema,
+						TableName: "metrics",
+						Dimensions: []schema.Dimension{
+							{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 8},
+						},
+					},
+				},
+			},
+			expectedChanges: 2,
+			expectedTypes: []differ.ChangeType{
+				differ.ChangeTypeModifyDimension,
+				differ.ChangeTypeDropDimension,
+			},
+		},
 		{
 			name: "add continuous aggregate",
 			current: &schema.Database{
@@ -167,6 +327,20 @@ func TestDiffer_CompareTimescaleDB(t *testing.T) {
 	}
 }
 
+func compressedHypertableWithPolicy(compressAfter string) schema.Hypertable {
+	return schema.Hypertable{
+		Schema:              schema.DefaultSchema,
+		TableName:           "metrics",
+		CompressionEnabled:  true,
+		CompressionSettings: &schema.CompressionSettings{},
+		CompressionPolicy: &schema.CompressionPolicy{
+			HypertableSchema: schema.DefaultSchema,
+			HypertableName:   "metrics",
+			CompressAfter:    compressAfter,
+		},
+	}
+}
+
 func TestContinuousAggregateFormattingIdempotent(t *testing.T) {
 	t.Parallel()
 
@@ -456,3 +630,33 @@ GROUP BY category, bucket`,
 		t.Fatalf("expected no changes, got %d: %+v", len(result.Changes), result.Changes)
 	}
 }
+
+func TestDiffer_HypertableIntervalChangeWarning(t *testing.T) {
+	t.Parallel()
+
+	hypertable := func(interval string) *schema.Database {
+		return &schema.Database{
+			Tables: []schema.Table{{Schema: schema.DefaultSchema, Name: "metrics"}},
+			Hypertables: []schema.Hypertable{
+				{
+					Schema:            schema.DefaultSchema,
+					TableName:         "metrics",
+					TimeColumnName:    "time",
+					PartitionInterval: interval,
+				},
+			},
+		}
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		hypertable("1 day"),
+		hypertable("7 days"),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, diag.CodeHypertableIntervalChange, result.Diagnostics[0].Code)
+	assert.Equal(t, diag.SeverityWarning, result.Diagnostics[0].Severity)
+	assert.Equal(t, "public.metrics", result.Diagnostics[0].ObjectName)
+	assert.Equal(t, []string{result.Diagnostics[0].Message}, result.Warnings)
+}
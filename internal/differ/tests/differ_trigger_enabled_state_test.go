@@ -0,0 +1,67 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func auditTriggerDB(timing, enabledState string) *schema.Database {
+	db := triggerUpdateColumnsDB(nil)
+	db.Triggers[0].Timing = timing
+	db.Triggers[0].EnabledState = enabledState
+
+	return db
+}
+
+func TestTriggerComparator_EnabledStateChange(t *testing.T) {
+	t.Parallel()
+
+	current := auditTriggerDB("AFTER", "")
+	desired := auditTriggerDB("AFTER", schema.TriggerEnabledDisabled)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	require.Empty(t, result.GetChangesByType(differ.ChangeTypeAddTrigger))
+	require.Empty(t, result.GetChangesByType(differ.ChangeTypeDropTrigger))
+
+	changes := result.GetChangesByType(differ.ChangeTypeModifyTrigger)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, changes[0].Severity)
+	assert.Equal(t, true, changes[0].Details["enabled_state_only"])
+	assert.Equal(t,
+		"Trigger state differs: notify_changes on public.items is origin in database, "+
+			"disabled in desired schema",
+		changes[0].Description)
+}
+
+func TestTriggerComparator_OriginStateMatchesUnset(t *testing.T) {
+	t.Parallel()
+
+	current := auditTriggerDB("AFTER", "")
+	desired := auditTriggerDB("AFTER", schema.TriggerEnabledOrigin)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	assert.Empty(t, result.GetChangesByType(differ.ChangeTypeModifyTrigger))
+}
+
+func TestTriggerComparator_StructuralChangeKeepsReplacement(t *testing.T) {
+	t.Parallel()
+
+	current := auditTriggerDB("BEFORE", "")
+	desired := auditTriggerDB("AFTER", schema.TriggerEnabledDisabled)
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	changes := result.GetChangesByType(differ.ChangeTypeModifyTrigger)
+	require.Len(t, changes, 1)
+	assert.Equal(t, differ.SeverityBreaking, changes[0].Severity)
+	assert.NotContains(t, changes[0].Details, "enabled_state_only")
+}
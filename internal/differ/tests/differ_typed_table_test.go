@@ -0,0 +1,127 @@
+package differ_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func typedTableDatabase(attributes ...schema.TypeAttribute) *schema.Database {
+	columns := make([]schema.Column, 0, len(attributes))
+	for i, attr := range attributes {
+		columns = append(columns, schema.Column{
+			Name: attr.Name, DataType: attr.DataType, IsNullable: true, Position: i + 1,
+		})
+	}
+
+	return &schema.Database{
+		CustomTypes: []schema.CustomType{
+			{Schema: "app", Name: "event_type", Type: "composite", Attributes: attributes},
+		},
+		Tables: []schema.Table{
+			{Schema: "app", Name: "events", TypedOf: "app.event_type", Columns: columns},
+		},
+	}
+}
+
+func TestDiffer_CompositeTypeAttributes(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		typedTableDatabase(
+			schema.TypeAttribute{Name: "id", DataType: "bigint"},
+			schema.TypeAttribute{Name: "amount", DataType: "numeric(10,2)"},
+			schema.TypeAttribute{Name: "legacy", DataType: "text"},
+		),
+		typedTableDatabase(
+			schema.TypeAttribute{Name: "id", DataType: "BIGINT"},
+			schema.TypeAttribute{Name: "amount", DataType: "NUMERIC(12, 2)"},
+			schema.TypeAttribute{Name: "name", DataType: "VARCHAR(64)"},
+		),
+	)
+	require.NoError(t, err)
+
+	byAttribute := make(map[string]differ.Change)
+
+	for _, change := range result.Changes {
+		require.Equal(t, differ.ChangeTypeModifyCustomType, change.Type, change.Description)
+		assert.Equal(t, []string{"app.events"}, change.Details["typed_tables"])
+
+		byAttribute[change.Details["attribute"].(string)] = change
+	}
+
+	require.Len(t, byAttribute, 3)
+
+	assert.Equal(t, differ.SeverityDataMigrationRequired, byAttribute["amount"].Severity)
+	assert.Equal(t, "numeric(10,2)", byAttribute["amount"].Details["old_type"])
+	assert.Equal(t, "NUMERIC(12, 2)", byAttribute["amount"].Details["new_type"])
+
+	assert.Equal(t, differ.SeveritySafe, byAttribute["name"].Severity)
+	assert.NotContains(t, byAttribute["name"].Details, "old_type")
+
+	assert.Equal(t, differ.SeverityBreaking, byAttribute["legacy"].Severity)
+	assert.NotContains(t, byAttribute["legacy"].Details, "new_type")
+
+	assert.Contains(t, result.Notes, "attribute changes of type app.event_type also change "+
+		"the columns of its typed tables app.events, through ALTER TYPE ... CASCADE")
+}
+
+func TestDiffer_CompositeTypeAttributesUnchanged(t *testing.T) {
+	t.Parallel()
+
+	assertNoChanges(t,
+		typedTableDatabase(schema.TypeAttribute{Name: "amount", DataType: "numeric(10,2)"}),
+		typedTableDatabase(schema.TypeAttribute{Name: "amount", DataType: "NUMERIC(10, 2)"}),
+	)
+}
+
+func TestDiffer_TypedTableIndexAfterAttribute(t *testing.T) {
+	t.Parallel()
+
+	desired := typedTableDatabase(
+		schema.TypeAttribute{Name: "id", DataType: "bigint"},
+		schema.TypeAttribute{Name: "name", DataType: "text"},
+	)
+	desired.Tables[0].Indexes = []schema.Index{
+		{Schema: "app", TableName: "events", Name: "events_name_idx", Columns: []string{"name"}},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		typedTableDatabase(schema.TypeAttribute{Name: "id", DataType: "bigint"}),
+		desired,
+	)
+	require.NoError(t, err)
+
+	types := make([]differ.ChangeType, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		types = append(types, change.Type)
+	}
+
+	attribute := slices.Index(types, differ.ChangeTypeModifyCustomType)
+	index := slices.Index(types, differ.ChangeTypeAddIndex)
+
+	require.NotEqual(t, -1, attribute)
+	require.NotEqual(t, -1, index)
+	assert.NotContains(t, types, differ.ChangeTypeAddColumn)
+	assert.Less(t, attribute, index)
+}
+
+func TestDiffer_TypedOfChanged(t *testing.T) {
+	t.Parallel()
+
+	current := typedTableDatabase(schema.TypeAttribute{Name: "id", DataType: "bigint"})
+	desired := typedTableDatabase(schema.TypeAttribute{Name: "id", DataType: "bigint"})
+	desired.Tables[0].TypedOf = ""
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Contains(t, result.Notes, "table app.events is typed OF app.event_type in database "+
+		"and not typed in desired schema; pgtofu does not generate ALTER TABLE ... OF or NOT OF, "+
+		"so change it with a manual migration")
+}
@@ -116,6 +116,9 @@ func TestNormalizeDataType(t *testing.T) {
 		{"TIMESTAMPTZ", "timestamp with time zone"},
 		{"DECIMAL", "numeric"},
 		{"FLOAT8", "double precision"},
+		{"FLOAT", "double precision"},
+		{"float4", "real"},
+		{"DOUBLE  PRECISION", "double precision"},
 	}
 
 	for _, tt := range tests {
@@ -153,6 +156,8 @@ func TestAreDefaultsEqual(t *testing.T) {
 		{"'{}'::integer[]", "'{}'", true},
 		{"'{}'::uuid[]", "'{}'", true},
 		{"'{1,2}'::integer[]", "'{3,4}'::integer[]", false},
+		{"'active'::public.status", "'active'", true},
+		{"'active'::status", "'disabled'", false},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,99 @@
+package differ_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDiffer_FunctionsInFromAreNotDependencies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		source    string
+		formatted string
+	}{
+		{
+			name: "with ordinality",
+			source: `SELECT t.item, t.idx, o.id
+FROM jsonb_array_elements('[1, 2]'::jsonb) WITH ORDINALITY AS t(item, idx)
+JOIN orders o ON o.id = t.idx`,
+			formatted: ` SELECT t.item,
+    t.idx,
+    o.id
+   FROM (jsonb_array_elements('[1, 2]'::jsonb) WITH ORDINALITY t(item, idx)
+     JOIN orders o ON ((o.id = t.idx)))`,
+		},
+		{
+			name: "lateral",
+			source: `SELECT o.id, e.value
+FROM orders o
+CROSS JOIN LATERAL jsonb_each(o.config) AS e(key, value)`,
+			formatted: ` SELECT o.id,
+    e.value
+   FROM (orders o
+     CROSS JOIN LATERAL jsonb_each(o.config) e(key, value))`,
+		},
+		{
+			name: "rows from",
+			source: `SELECT r.n, r.label, o.id
+FROM ROWS FROM (generate_series(1, 3), unnest(ARRAY['a', 'b'])) WITH ORDINALITY AS r(n, label, pos)
+JOIN orders o ON o.id = r.n`,
+			formatted: ` SELECT r.n,
+    r.label,
+    o.id
+   FROM (ROWS FROM(generate_series(1, 3), unnest(ARRAY['a', 'b'])) WITH ORDINALITY r(n, label, pos)
+     JOIN orders o ON ((o.id = r.n)))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			desired := &schema.Database{
+				Tables: []schema.Table{{
+					Schema:  schema.DefaultSchema,
+					Name:    "orders",
+					Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+				}},
+				Views: []schema.View{{
+					Schema:     schema.DefaultSchema,
+					Name:       "order_items",
+					Definition: tt.source,
+				}},
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+			require.NoError(t, err)
+
+			idx := changeIndex(result, differ.ChangeTypeAddView, "public.order_items")
+			require.NotEqual(t, -1, idx)
+			assert.Equal(t, []string{"orders"}, result.Changes[idx].DependsOn)
+
+			normalized := differ.NormalizeViewDefinition(tt.source)
+			assert.Equal(t, normalized, differ.NormalizeViewDefinition(tt.formatted))
+
+			var stmt struct {
+				From []map[string]any `json:"from"`
+			}
+
+			require.NoError(t, json.Unmarshal([]byte(normalized), &stmt))
+
+			var tables []any
+			for _, item := range stmt.From {
+				if name, ok := item["name"]; ok {
+					tables = append(tables, name)
+				}
+			}
+
+			assert.Equal(t, []any{"orders"}, tables, normalized)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const optionsViewDefinition = "SELECT id FROM users WHERE active"
+
+var securityBarrier = map[string]string{"security_barrier": "true"}
+
+func optionsView(
+	definition, checkOption, comment string,
+	options map[string]string,
+) *schema.Database {
+	return &schema.Database{Views: []schema.View{{
+		Schema:      schema.DefaultSchema,
+		Name:        "active_users",
+		Definition:  definition,
+		CheckOption: checkOption,
+		Comment:     comment,
+		Options:     options,
+	}}}
+}
+
+func TestDiffer_ViewOptions(t *testing.T) {
+	t.Parallel()
+
+	current := optionsView(optionsViewDefinition, "NONE", "", nil)
+	desired := optionsView(optionsViewDefinition, "LOCAL", "",
+		map[string]string{"Security_Barrier": "on"})
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+
+	change := result.Changes[0]
+	assert.Equal(t, differ.ChangeTypeModifyView, change.Type)
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, change.Severity,
+		"a new check option rejects writes that succeeded before")
+	assert.Equal(t, map[string]string{}, change.Details["old_view_options"])
+	assert.Equal(t, map[string]string{"security_barrier": "true", "check_option": "local"},
+		change.Details["new_view_options"])
+	assert.NotContains(t, change.Details, "current")
+
+	result, err = differ.New(differ.DefaultOptions()).Compare(
+		optionsView(optionsViewDefinition, "", "", securityBarrier),
+		optionsView(optionsViewDefinition, "", "", map[string]string{"security_barrier": "false"}))
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, differ.SeveritySafe, result.Changes[0].Severity)
+
+	assertNoChanges(t,
+		optionsView(optionsViewDefinition, "CASCADED", "", securityBarrier),
+		optionsView(optionsViewDefinition, "cascaded", "",
+			map[string]string{"security_barrier": "1"}))
+}
+
+func TestDiffer_ViewCommentOnlyChange(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		optionsView(optionsViewDefinition, "", "Active users", securityBarrier),
+		optionsView(optionsViewDefinition, "", "Users that can log in", securityBarrier))
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, "Users that can log in", result.Changes[0].Details["new_comment"])
+}
+
+func TestDiffer_RedefinedViewCarriesOptions(t *testing.T) {
+	t.Parallel()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		optionsView(optionsViewDefinition, "", "", nil),
+		optionsView("SELECT id FROM users", "", "", securityBarrier))
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Contains(t, result.Changes[0].Details, "current")
+	assert.NotContains(t, result.Changes[0].Details, "new_view_options")
+}
@@ -0,0 +1,162 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDiffViewStructure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		current     string
+		desired     string
+		want        differ.ViewStructureDiff
+		wantSummary string
+	}{
+		{
+			name:    "added and removed columns with where change",
+			current: "SELECT u.id, u.name, u.legacy_score FROM users u WHERE u.active",
+			desired: "SELECT u.id, u.name, count(o.id) AS total_orders FROM users u " +
+				"JOIN orders o ON o.user_id = u.id WHERE u.active AND u.verified",
+			want: differ.ViewStructureDiff{
+				AddedColumns:   []string{"total_orders"},
+				RemovedColumns: []string{"legacy_score"},
+				AddedTables:    []string{"orders"},
+				WhereChanged:   true,
+			},
+			wantSummary: "view public.user_summary: +column total_orders, -column legacy_score, " +
+				"+table orders, WHERE changed",
+		},
+		{
+			name:    "aliased expression renamed",
+			current: "SELECT id, lower(email) AS email_key FROM users",
+			desired: "SELECT id, lower(email) AS normalized_email FROM users",
+			want: differ.ViewStructureDiff{
+				RenamedColumns: []differ.ViewColumnRename{
+					{From: "email_key", To: "normalized_email"},
+				},
+			},
+			wantSummary: "view public.user_summary: column email_key -> normalized_email",
+		},
+		{
+			name:    "aliased expression modified",
+			current: "SELECT id, price * 1.0 AS total FROM orders",
+			desired: "SELECT id, price * quantity AS total FROM orders",
+			want: differ.ViewStructureDiff{
+				ModifiedColumns: []string{"total"},
+			},
+			wantSummary: "view public.user_summary: ~column total",
+		},
+		{
+			name:    "table swapped in from clause",
+			current: "SELECT id FROM public.accounts",
+			desired: "SELECT id FROM public.users",
+			want: differ.ViewStructureDiff{
+				AddedTables:   []string{"users"},
+				RemovedTables: []string{"accounts"},
+			},
+			wantSummary: "view public.user_summary: +table users, -table accounts",
+		},
+		{
+			name: "cte view with only outer select changed",
+			current: "WITH recent AS (SELECT user_id, created_at FROM orders WHERE created_at > now()) " +
+				"SELECT user_id FROM recent",
+			desired: "WITH recent AS (SELECT user_id, created_at FROM orders WHERE created_at > now()) " +
+				"SELECT user_id, created_at AS last_order_at FROM recent",
+			want: differ.ViewStructureDiff{
+				AddedColumns: []string{"last_order_at"},
+			},
+			wantSummary: "view public.user_summary: +column last_order_at",
+		},
+		{
+			name: "cte body changed",
+			current: "WITH recent AS (SELECT user_id FROM orders WHERE created_at > now()) " +
+				"SELECT user_id FROM recent",
+			desired: "WITH recent AS (SELECT user_id FROM orders) SELECT user_id FROM recent",
+			want: differ.ViewStructureDiff{
+				WithChanged: true,
+			},
+			wantSummary: "view public.user_summary: WITH changed",
+		},
+		{
+			name:    "group by changed",
+			current: "SELECT status, count(*) AS total FROM orders GROUP BY status",
+			desired: "SELECT status, count(*) AS total FROM orders GROUP BY status HAVING count(*) > 1",
+			want: differ.ViewStructureDiff{
+				GroupByChanged: true,
+			},
+			wantSummary: "view public.user_summary: GROUP BY changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diff := differ.DiffViewStructure(tt.current, tt.desired)
+			require.NotNil(t, diff)
+
+			assert.Equal(t, tt.want, *diff)
+			assert.True(t, diff.HasChanges())
+			assert.Equal(t, tt.wantSummary, diff.Summary("public.user_summary"))
+		})
+	}
+}
+
+func TestViewComparator_ModifyChangeCarriesStructureDiff(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Views: []schema.View{
+			{
+				Schema:     schema.DefaultSchema,
+				Name:       "user_summary",
+				Definition: "SELECT id, legacy_score FROM users",
+			},
+		},
+	}
+
+	desired := &schema.Database{
+		Views: []schema.View{
+			{
+				Schema:     schema.DefaultSchema,
+				Name:       "user_summary",
+				Definition: "SELECT id, total_orders FROM users",
+			},
+		},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	// Replacing a column cannot be done in place, so the view is recreated
+	// and the recreation carries the diff.
+	added := result.GetChangesByType(differ.ChangeTypeAddView)
+	require.Len(t, added, 1)
+	assert.Equal(
+		t,
+		"view public.user_summary: +column total_orders, -column legacy_score",
+		differ.ViewDiffSummary(added[0]),
+	)
+}
+
+func TestViewStructureDiff_ParseFailureFallback(t *testing.T) {
+	t.Parallel()
+
+	diff := differ.DiffViewStructure("VALUES (1), (2)", "SELECT 1 AS one")
+	require.NotNil(t, diff)
+
+	assert.True(t, diff.ParseFailed)
+	assert.Equal(
+		t,
+		"view public.numbers: definition changed (structural diff unavailable)",
+		diff.Summary("public.numbers"),
+	)
+}
@@ -0,0 +1,124 @@
+package differ_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func viewTriggerDB(columnType, definition string) *schema.Database {
+	return &schema.Database{
+		Tables: []schema.Table{{
+			Schema:  schema.DefaultSchema,
+			Name:    "orders",
+			Columns: []schema.Column{{Name: "total", DataType: columnType, Position: 1}},
+		}},
+		Views: []schema.View{{
+			Schema:     schema.DefaultSchema,
+			Name:       "order_entry",
+			Definition: definition,
+		}},
+		Triggers: []schema.Trigger{{
+			Schema:         schema.DefaultSchema,
+			Name:           "order_entry_insert",
+			TableName:      "order_entry",
+			Timing:         "INSTEAD OF",
+			Events:         []string{"INSERT"},
+			ForEachRow:     true,
+			FunctionSchema: schema.DefaultSchema,
+			FunctionName:   "order_entry_insert",
+		}},
+	}
+}
+
+func TestDiffer_ViewRecreationRecreatesTriggers(t *testing.T) {
+	t.Parallel()
+
+	current := viewTriggerDB("integer", "SELECT total FROM orders")
+	desired := viewTriggerDB("bigint", "SELECT total FROM orders")
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []differ.ChangeType{
+		differ.ChangeTypeDropTrigger,
+		differ.ChangeTypeDropView,
+		differ.ChangeTypeModifyColumnType,
+		differ.ChangeTypeAddView,
+		differ.ChangeTypeAddTrigger,
+	}, changeTypes(result))
+
+	assert.Equal(t, differ.SeverityPotentiallyBreaking, result.Changes[0].Severity)
+	assert.Equal(t, true, result.Changes[0].Details["will_be_recreated"])
+	assert.Equal(t, true, result.Changes[4].Details["is_recreation"])
+}
+
+func TestDiffer_ViewRecreationReplacesTriggerModification(t *testing.T) {
+	t.Parallel()
+
+	current := viewTriggerDB("integer", "SELECT total FROM orders")
+	desired := viewTriggerDB("bigint", "SELECT total FROM orders")
+	desired.Triggers[0].Events = []string{"INSERT", "UPDATE"}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.GetChangesByType(differ.ChangeTypeModifyTrigger))
+	require.Len(t, result.GetChangesByType(differ.ChangeTypeDropTrigger), 1)
+
+	added := result.GetChangesByType(differ.ChangeTypeAddTrigger)
+	require.Len(t, added, 1)
+	assert.Equal(t, desired.Triggers[0].Events, added[0].Details["trigger"].(*schema.Trigger).Events)
+}
+
+func TestDiffer_DroppedViewDropsTriggerFirst(t *testing.T) {
+	t.Parallel()
+
+	current := viewTriggerDB("integer", "SELECT total FROM orders")
+	desired := viewTriggerDB("integer", "")
+	desired.Views = nil
+	desired.Triggers = nil
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []differ.ChangeType{
+		differ.ChangeTypeDropTrigger,
+		differ.ChangeTypeDropView,
+	}, changeTypes(result))
+}
+
+func TestDiffer_ViewLosingColumnRecreatesTriggers(t *testing.T) {
+	t.Parallel()
+
+	current := viewTriggerDB("integer", "SELECT total, total * 2 AS doubled FROM orders")
+	desired := viewTriggerDB("integer", "SELECT total FROM orders")
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []differ.ChangeType{
+		differ.ChangeTypeDropTrigger,
+		differ.ChangeTypeDropView,
+		differ.ChangeTypeAddView,
+		differ.ChangeTypeAddTrigger,
+	}, changeTypes(result))
+
+	assert.Equal(t, true, result.Changes[1].Details["for_column_list_change"])
+}
+
+func TestDiffer_ViewAppendingColumnIsReplaced(t *testing.T) {
+	t.Parallel()
+
+	current := viewTriggerDB("integer", "SELECT total FROM orders")
+	desired := viewTriggerDB("integer", "SELECT total, total * 2 AS doubled FROM orders")
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, []differ.ChangeType{differ.ChangeTypeModifyView}, changeTypes(result))
+}
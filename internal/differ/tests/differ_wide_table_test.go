@@ -0,0 +1,304 @@
+package differ_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// wideColumn is one column of a generated table and, every few columns, the
+// check constraint, unique constraint and index declared on it.
+type wideColumn struct {
+	name     string
+	dataType string
+	check    bool
+	unique   bool
+	index    bool
+}
+
+func wideTable(columns []wideColumn) *schema.Database {
+	table := schema.Table{Schema: schema.DefaultSchema, Name: "attributes"}
+
+	for i, col := range columns {
+		table.Columns = append(table.Columns, schema.Column{
+			Name:       col.name,
+			DataType:   col.dataType,
+			IsNullable: true,
+			Position:   i + 1,
+		})
+
+		if col.check {
+			expr := fmt.Sprintf("(%s IS NOT NULL)", col.name)
+			table.Constraints = append(table.Constraints, schema.Constraint{
+				Name:            col.name + "_check",
+				Type:            schema.ConstraintCheck,
+				Columns:         []string{col.name},
+				CheckExpression: expr,
+				Definition:      "CHECK " + expr,
+			})
+		}
+
+		if col.unique {
+			table.Constraints = append(table.Constraints, schema.Constraint{
+				Name:       col.name + "_key",
+				Type:       schema.ConstraintUnique,
+				Columns:    []string{col.name},
+				Definition: fmt.Sprintf("UNIQUE (%s)", col.name),
+			})
+			table.Indexes = append(table.Indexes, schema.Index{
+				Schema:    schema.DefaultSchema,
+				Name:      col.name + "_key",
+				TableName: table.Name,
+				Columns:   []string{col.name},
+				IsUnique:  true,
+			})
+		}
+
+		if col.index {
+			table.Indexes = append(table.Indexes, schema.Index{
+				Schema:    schema.DefaultSchema,
+				Name:      col.name + "_idx",
+				TableName: table.Name,
+				Columns:   []string{col.name},
+			})
+		}
+	}
+
+	return &schema.Database{Tables: []schema.Table{table}}
+}
+
+func wideColumns(count int) []wideColumn {
+	columns := make([]wideColumn, count)
+	for i := range columns {
+		columns[i] = wideColumn{
+			name:     fmt.Sprintf("attr_%04d", i),
+			dataType: "text",
+			check:    i%10 == 0,
+			unique:   i%25 == 0,
+			index:    i%10 == 5,
+		}
+	}
+
+	return columns
+}
+
+// BenchmarkCompareWideTable compares two definitions of a 1,500-column table
+// with 150 check constraints, 60 unique constraints and 210 indexes, of which
+// three columns changed type, one was added and one dropped. Columns,
+// constraints and indexes are matched through maps built once per table and
+// expression patterns are compiled once, so the comparison grows linearly
+// with the width of the table; matching each index against every constraint
+// and compiling patterns per expression took about seven times as long.
+func BenchmarkCompareWideTable(b *testing.B) {
+	columns := wideColumns(1500)
+	current := wideTable(columns)
+
+	columns[100].dataType = "varchar(64)"
+	columns[700].dataType = "varchar(64)"
+	columns[1400].dataType = "varchar(64)"
+	columns = slices.Delete(columns, 901, 902)
+	columns = append(columns, wideColumn{name: "attr_extra", dataType: "jsonb"})
+	desired := wideTable(columns)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if len(result.Changes) != 5 {
+			b.Fatalf("got %d changes, want 5", len(result.Changes))
+		}
+	}
+}
+
+// randomWideColumns derives a desired table from current by dropping,
+// retyping and adding columns at random, and by renaming some columns only in
+// case, which makes them other columns.
+func randomWideColumns(rng *rand.Rand, current []wideColumn) []wideColumn {
+	types := []string{"text", "integer", "bigint", "boolean", "jsonb"}
+
+	var desired []wideColumn
+
+	for _, col := range current {
+		switch rng.IntN(10) {
+		case 0:
+			continue
+		case 1:
+			col.dataType = types[rng.IntN(len(types))]
+		case 2:
+			col.name = strings.ToUpper(col.name)
+		case 3:
+			col.check = !col.check
+		case 4:
+			col.index = !col.index
+		}
+
+		desired = append(desired, col)
+	}
+
+	for i := range rng.IntN(20) {
+		desired = append(desired, wideColumn{
+			name:     fmt.Sprintf("added_%02d", i),
+			dataType: types[rng.IntN(len(types))],
+			index:    rng.IntN(2) == 0,
+		})
+	}
+
+	return desired
+}
+
+// expectedWideChanges finds what changed between two generated tables by
+// scanning one column list for every column of the other, the matching the
+// comparators used before they keyed columns in maps.
+func expectedWideChanges(current, desired []wideColumn) []string {
+	find := func(columns []wideColumn, name string) *wideColumn {
+		for i := range columns {
+			if columns[i].name == name {
+				return &columns[i]
+			}
+		}
+
+		return nil
+	}
+
+	var changes []string
+
+	for _, col := range desired {
+		name := strings.ToLower(col.name)
+
+		other := find(current, col.name)
+		if other == nil {
+			changes = append(changes, "ADD_COLUMN "+name)
+		} else if other.dataType != col.dataType {
+			changes = append(changes, "MODIFY_COLUMN_TYPE "+name)
+		}
+
+		if col.check && (other == nil || !other.check) {
+			changes = append(changes, "ADD_CONSTRAINT "+name+"_check")
+		}
+
+		if col.unique && other == nil {
+			changes = append(changes, "ADD_CONSTRAINT "+name+"_key")
+		}
+
+		if col.index && (other == nil || !other.index) {
+			changes = append(changes, "ADD_INDEX "+name+"_idx")
+		}
+	}
+
+	for _, col := range current {
+		name := strings.ToLower(col.name)
+
+		other := find(desired, col.name)
+		if other == nil {
+			changes = append(changes, "DROP_COLUMN "+name)
+		}
+
+		if col.check && (other == nil || !other.check) {
+			changes = append(changes, "DROP_CONSTRAINT "+name+"_check")
+		}
+
+		if col.unique && other == nil {
+			changes = append(changes, "DROP_CONSTRAINT "+name+"_key")
+		}
+
+		if col.index && (other == nil || !other.index) {
+			changes = append(changes, "DROP_INDEX "+name+"_idx")
+		}
+	}
+
+	slices.Sort(changes)
+
+	return changes
+}
+
+// wideChanges reduces the changes to one table to their type and the name of
+// the column, constraint or index they change.
+func wideChanges(t *testing.T, result *differ.DiffResult) []string {
+	t.Helper()
+
+	changes := make([]string, 0, len(result.Changes))
+
+	for _, change := range result.Changes {
+		var name string
+
+		switch details := change.Details; change.Type {
+		case differ.ChangeTypeAddColumn, differ.ChangeTypeDropColumn:
+			col, ok := details["column"].(*schema.Column)
+			require.True(t, ok)
+
+			name = col.Name
+		case differ.ChangeTypeModifyColumnType:
+			name, _ = details["column_name"].(string)
+		case differ.ChangeTypeAddConstraint, differ.ChangeTypeDropConstraint:
+			constraint, ok := details["constraint"].(*schema.Constraint)
+			require.True(t, ok)
+
+			name = constraint.Name
+		case differ.ChangeTypeAddIndex, differ.ChangeTypeDropIndex:
+			idx, ok := details["index"].(*schema.Index)
+			require.True(t, ok)
+
+			name = idx.Name
+		default:
+			t.Fatalf("unexpected %s change: %s", change.Type, change.Description)
+		}
+
+		changes = append(changes, string(change.Type)+" "+strings.ToLower(name))
+	}
+
+	slices.Sort(changes)
+
+	return changes
+}
+
+func TestDiffer_WideTableMatchesPairwiseScan(t *testing.T) {
+	t.Parallel()
+
+	for seed := range uint64(25) {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			t.Parallel()
+
+			rng := rand.New(rand.NewPCG(seed, 716))
+			current := wideColumns(100 + rng.IntN(400))
+			desired := randomWideColumns(rng, current)
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				wideTable(current), wideTable(desired))
+			require.NoError(t, err)
+
+			assert.Equal(t, expectedWideChanges(current, desired), wideChanges(t, result))
+		})
+	}
+}
+
+func TestDiffer_WideTableChangeOrderIsStable(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(7, 716))
+	current := wideColumns(300)
+	desired := randomWideColumns(rng, current)
+
+	first, err := differ.New(differ.DefaultOptions()).Compare(
+		wideTable(current), wideTable(desired))
+	require.NoError(t, err)
+
+	for range 5 {
+		again, err := differ.New(differ.DefaultOptions()).Compare(
+			wideTable(current), wideTable(desired))
+		require.NoError(t, err)
+
+		assert.Equal(t, changeDescriptions(first), changeDescriptions(again))
+	}
+}
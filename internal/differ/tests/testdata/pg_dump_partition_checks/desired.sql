@@ -0,0 +1,50 @@
+CREATE TABLE readings (
+    id BIGINT NOT NULL,
+    sensor_id BIGINT NOT NULL,
+    value NUMERIC NOT NULL CHECK (value >= 0),
+    read_on DATE NOT NULL,
+    PRIMARY KEY (id, read_on)
+) PARTITION BY RANGE (read_on);
+
+CREATE INDEX idx_readings_sensor ON readings (sensor_id);
+
+CREATE TABLE readings_2024_01 PARTITION OF readings
+    FOR VALUES FROM ('2024-01-01') TO ('2024-02-01');
+
+CREATE TABLE readings_2024_02 PARTITION OF readings
+    FOR VALUES FROM ('2024-02-01') TO ('2024-03-01');
+
+CREATE TABLE readings_2024_03 PARTITION OF readings
+    FOR VALUES FROM ('2024-03-01') TO ('2024-04-01');
+
+CREATE TABLE readings_2024_04 PARTITION OF readings
+    FOR VALUES FROM ('2024-04-01') TO ('2024-05-01');
+
+CREATE TABLE readings_2024_05 PARTITION OF readings
+    FOR VALUES FROM ('2024-05-01') TO ('2024-06-01');
+
+CREATE TABLE readings_2024_06 PARTITION OF readings
+    FOR VALUES FROM ('2024-06-01') TO ('2024-07-01');
+
+CREATE TABLE readings_2024_07 PARTITION OF readings
+    FOR VALUES FROM ('2024-07-01') TO ('2024-08-01');
+
+CREATE TABLE readings_2024_08 PARTITION OF readings
+    FOR VALUES FROM ('2024-08-01') TO ('2024-09-01');
+
+CREATE TABLE readings_2024_09 PARTITION OF readings
+    FOR VALUES FROM ('2024-09-01') TO ('2024-10-01');
+
+CREATE TABLE readings_2024_10 PARTITION OF readings
+    FOR VALUES FROM ('2024-10-01') TO ('2024-11-01');
+
+CREATE TABLE readings_2024_11 PARTITION OF readings
+    FOR VALUES FROM ('2024-11-01') TO ('2024-12-01');
+
+CREATE TABLE readings_2024_12 PARTITION OF readings
+    FOR VALUES FROM ('2024-12-01') TO ('2025-01-01');
+
+ALTER TABLE readings_2024_01
+    ADD CONSTRAINT readings_2024_01_sensor_check CHECK (sensor_id > 0);
+
+CREATE INDEX readings_2024_06_value_idx ON readings_2024_06 (value);
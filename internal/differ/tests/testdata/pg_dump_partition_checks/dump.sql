@@ -0,0 +1,601 @@
+--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SET client_encoding = 'UTF8';
+SELECT pg_catalog.set_config('search_path', '', false);
+
+SET default_tablespace = '';
+
+--
+-- Name: readings; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+)
+PARTITION BY RANGE (read_on);
+
+
+ALTER TABLE public.readings OWNER TO app;
+
+--
+-- Name: readings_2024_01; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_01 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric)),
+    CONSTRAINT readings_2024_01_sensor_check CHECK ((sensor_id > 0))
+);
+
+
+ALTER TABLE public.readings_2024_01 OWNER TO app;
+
+--
+-- Name: readings_2024_02; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_02 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_02 OWNER TO app;
+
+--
+-- Name: readings_2024_03; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_03 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_03 OWNER TO app;
+
+--
+-- Name: readings_2024_04; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_04 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_04 OWNER TO app;
+
+--
+-- Name: readings_2024_05; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_05 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_05 OWNER TO app;
+
+--
+-- Name: readings_2024_06; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_06 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_06 OWNER TO app;
+
+--
+-- Name: readings_2024_07; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_07 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_07 OWNER TO app;
+
+--
+-- Name: readings_2024_08; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_08 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_08 OWNER TO app;
+
+--
+-- Name: readings_2024_09; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_09 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_09 OWNER TO app;
+
+--
+-- Name: readings_2024_10; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_10 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_10 OWNER TO app;
+
+--
+-- Name: readings_2024_11; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_11 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_11 OWNER TO app;
+
+--
+-- Name: readings_2024_12; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.readings_2024_12 (
+    id bigint NOT NULL,
+    sensor_id bigint NOT NULL,
+    value numeric NOT NULL,
+    read_on date NOT NULL,
+    CONSTRAINT readings_value_check CHECK ((value >= (0)::numeric))
+);
+
+
+ALTER TABLE public.readings_2024_12 OWNER TO app;
+
+--
+-- Name: readings_2024_01; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_01 FOR VALUES FROM ('2024-01-01') TO ('2024-02-01');
+
+--
+-- Name: readings_2024_02; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_02 FOR VALUES FROM ('2024-02-01') TO ('2024-03-01');
+
+--
+-- Name: readings_2024_03; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_03 FOR VALUES FROM ('2024-03-01') TO ('2024-04-01');
+
+--
+-- Name: readings_2024_04; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_04 FOR VALUES FROM ('2024-04-01') TO ('2024-05-01');
+
+--
+-- Name: readings_2024_05; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_05 FOR VALUES FROM ('2024-05-01') TO ('2024-06-01');
+
+--
+-- Name: readings_2024_06; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_06 FOR VALUES FROM ('2024-06-01') TO ('2024-07-01');
+
+--
+-- Name: readings_2024_07; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_07 FOR VALUES FROM ('2024-07-01') TO ('2024-08-01');
+
+--
+-- Name: readings_2024_08; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_08 FOR VALUES FROM ('2024-08-01') TO ('2024-09-01');
+
+--
+-- Name: readings_2024_09; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_09 FOR VALUES FROM ('2024-09-01') TO ('2024-10-01');
+
+--
+-- Name: readings_2024_10; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_10 FOR VALUES FROM ('2024-10-01') TO ('2024-11-01');
+
+--
+-- Name: readings_2024_11; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_11 FOR VALUES FROM ('2024-11-01') TO ('2024-12-01');
+
+--
+-- Name: readings_2024_12; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings ATTACH PARTITION public.readings_2024_12 FOR VALUES FROM ('2024-12-01') TO ('2025-01-01');
+
+--
+-- Name: readings readings_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings
+    ADD CONSTRAINT readings_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_01 readings_2024_01_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_01
+    ADD CONSTRAINT readings_2024_01_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_02 readings_2024_02_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_02
+    ADD CONSTRAINT readings_2024_02_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_03 readings_2024_03_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_03
+    ADD CONSTRAINT readings_2024_03_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_04 readings_2024_04_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_04
+    ADD CONSTRAINT readings_2024_04_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_05 readings_2024_05_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_05
+    ADD CONSTRAINT readings_2024_05_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_06 readings_2024_06_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_06
+    ADD CONSTRAINT readings_2024_06_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_07 readings_2024_07_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_07
+    ADD CONSTRAINT readings_2024_07_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_08 readings_2024_08_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_08
+    ADD CONSTRAINT readings_2024_08_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_09 readings_2024_09_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_09
+    ADD CONSTRAINT readings_2024_09_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_10 readings_2024_10_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_10
+    ADD CONSTRAINT readings_2024_10_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_11 readings_2024_11_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_11
+    ADD CONSTRAINT readings_2024_11_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: readings_2024_12 readings_2024_12_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.readings_2024_12
+    ADD CONSTRAINT readings_2024_12_pkey PRIMARY KEY (id, read_on);
+
+--
+-- Name: idx_readings_sensor; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX idx_readings_sensor ON ONLY public.readings USING btree (sensor_id);
+
+--
+-- Name: readings_2024_01_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_01_sensor_id_idx ON public.readings_2024_01 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_02_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_02_sensor_id_idx ON public.readings_2024_02 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_03_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_03_sensor_id_idx ON public.readings_2024_03 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_04_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_04_sensor_id_idx ON public.readings_2024_04 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_05_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_05_sensor_id_idx ON public.readings_2024_05 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_06_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_06_sensor_id_idx ON public.readings_2024_06 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_07_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_07_sensor_id_idx ON public.readings_2024_07 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_08_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_08_sensor_id_idx ON public.readings_2024_08 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_09_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_09_sensor_id_idx ON public.readings_2024_09 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_10_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_10_sensor_id_idx ON public.readings_2024_10 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_11_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_11_sensor_id_idx ON public.readings_2024_11 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_12_sensor_id_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_12_sensor_id_idx ON public.readings_2024_12 USING btree (sensor_id);
+
+--
+-- Name: readings_2024_06_value_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX readings_2024_06_value_idx ON public.readings_2024_06 USING btree (value);
+
+--
+-- Name: readings_2024_01_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_01_sensor_id_idx;
+
+--
+-- Name: readings_2024_01_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_01_pkey;
+
+--
+-- Name: readings_2024_02_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_02_sensor_id_idx;
+
+--
+-- Name: readings_2024_02_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_02_pkey;
+
+--
+-- Name: readings_2024_03_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_03_sensor_id_idx;
+
+--
+-- Name: readings_2024_03_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_03_pkey;
+
+--
+-- Name: readings_2024_04_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_04_sensor_id_idx;
+
+--
+-- Name: readings_2024_04_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_04_pkey;
+
+--
+-- Name: readings_2024_05_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_05_sensor_id_idx;
+
+--
+-- Name: readings_2024_05_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_05_pkey;
+
+--
+-- Name: readings_2024_06_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_06_sensor_id_idx;
+
+--
+-- Name: readings_2024_06_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_06_pkey;
+
+--
+-- Name: readings_2024_07_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_07_sensor_id_idx;
+
+--
+-- Name: readings_2024_07_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_07_pkey;
+
+--
+-- Name: readings_2024_08_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_08_sensor_id_idx;
+
+--
+-- Name: readings_2024_08_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_08_pkey;
+
+--
+-- Name: readings_2024_09_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_09_sensor_id_idx;
+
+--
+-- Name: readings_2024_09_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_09_pkey;
+
+--
+-- Name: readings_2024_10_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_10_sensor_id_idx;
+
+--
+-- Name: readings_2024_10_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_10_pkey;
+
+--
+-- Name: readings_2024_11_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_11_sensor_id_idx;
+
+--
+-- Name: readings_2024_11_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_11_pkey;
+
+--
+-- Name: readings_2024_12_sensor_id_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_readings_sensor ATTACH PARTITION public.readings_2024_12_sensor_id_idx;
+
+--
+-- Name: readings_2024_12_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.readings_pkey ATTACH PARTITION public.readings_2024_12_pkey;
+
+--
+-- PostgreSQL database dump complete
+--
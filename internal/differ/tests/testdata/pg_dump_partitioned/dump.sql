@@ -0,0 +1,186 @@
+--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SET lock_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+
+SET default_tablespace = '';
+
+SET default_table_access_method = heap;
+
+--
+-- Name: accounts; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.accounts (
+    id bigint NOT NULL,
+    email character varying(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.accounts OWNER TO app;
+
+--
+-- Name: events; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.events (
+    id bigint NOT NULL,
+    account_id bigint NOT NULL,
+    kind text NOT NULL,
+    occurred_on date NOT NULL
+)
+PARTITION BY RANGE (occurred_on);
+
+
+ALTER TABLE public.events OWNER TO app;
+
+--
+-- Name: events_2024; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.events_2024 (
+    id bigint NOT NULL,
+    account_id bigint NOT NULL,
+    kind text NOT NULL,
+    occurred_on date NOT NULL
+);
+
+
+ALTER TABLE public.events_2024 OWNER TO app;
+
+--
+-- Name: events_2025; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.events_2025 (
+    id bigint NOT NULL,
+    account_id bigint NOT NULL,
+    kind text NOT NULL,
+    occurred_on date NOT NULL
+);
+
+
+ALTER TABLE public.events_2025 OWNER TO app;
+
+--
+-- Name: events_2024; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.events ATTACH PARTITION public.events_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+
+
+--
+-- Name: events_2025; Type: TABLE ATTACH; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.events ATTACH PARTITION public.events_2025 FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');
+
+
+--
+-- Name: accounts accounts_email_key; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT accounts_email_key UNIQUE (email);
+
+
+--
+-- Name: accounts accounts_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT accounts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: events events_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.events
+    ADD CONSTRAINT events_pkey PRIMARY KEY (id, occurred_on);
+
+
+--
+-- Name: events_2024 events_2024_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.events_2024
+    ADD CONSTRAINT events_2024_pkey PRIMARY KEY (id, occurred_on);
+
+
+--
+-- Name: events_2025 events_2025_pkey; Type: CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE ONLY public.events_2025
+    ADD CONSTRAINT events_2025_pkey PRIMARY KEY (id, occurred_on);
+
+
+--
+-- Name: idx_events_account_kind; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX idx_events_account_kind ON ONLY public.events USING btree (account_id, kind);
+
+
+--
+-- Name: events_2024_account_id_kind_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX events_2024_account_id_kind_idx ON public.events_2024 USING btree (account_id, kind);
+
+
+--
+-- Name: events_2025_account_id_kind_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX events_2025_account_id_kind_idx ON public.events_2025 USING btree (account_id, kind);
+
+
+--
+-- Name: events_2024_account_id_kind_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_events_account_kind ATTACH PARTITION public.events_2024_account_id_kind_idx;
+
+
+--
+-- Name: events_2024_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.events_pkey ATTACH PARTITION public.events_2024_pkey;
+
+
+--
+-- Name: events_2025_account_id_kind_idx; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.idx_events_account_kind ATTACH PARTITION public.events_2025_account_id_kind_idx;
+
+
+--
+-- Name: events_2025_pkey; Type: INDEX ATTACH; Schema: public; Owner: app
+--
+
+ALTER INDEX public.events_pkey ATTACH PARTITION public.events_2025_pkey;
+
+
+--
+-- Name: events events_account_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: app
+--
+
+ALTER TABLE public.events
+    ADD CONSTRAINT events_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(id);
+
+
+--
+-- PostgreSQL database dump complete
+--
+
@@ -0,0 +1,23 @@
+CREATE SCHEMA app;
+CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public;
+CREATE TYPE app.status AS ENUM ('active', 'archived');
+CREATE FUNCTION app.touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$;
+CREATE TABLE app.users (
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL,
+    status app.status NOT NULL DEFAULT 'active',
+    updated_at TIMESTAMPTZ
+);
+COMMENT ON COLUMN app.users.email IS 'Login email';
+CREATE UNIQUE INDEX users_email_idx ON app.users (email);
+CREATE TRIGGER users_touch BEFORE UPDATE ON app.users FOR EACH ROW EXECUTE FUNCTION app.touch();
+CREATE TABLE app.orders (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES app.users (id),
+    total NUMERIC(12, 2) CHECK (total >= 0)
+);
@@ -0,0 +1,218 @@
+\restrict Zq0fWp1Rl2Ykx3
+
+--
+-- PostgreSQL database dump
+--
+
+-- Dumped from database version 16.2
+-- Dumped by pg_dump version 16.2
+
+SET statement_timeout = 0;
+SET lock_timeout = 0;
+SET idle_in_transaction_session_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+SET xmloption = content;
+SET client_min_messages = warning;
+SET row_security = off;
+
+--
+-- Name: app; Type: SCHEMA; Schema: -; Owner: app
+--
+
+CREATE SCHEMA app;
+
+
+ALTER SCHEMA app OWNER TO app;
+
+--
+-- Name: pgcrypto; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION pgcrypto; Type: COMMENT; Schema: -; Owner: 
+--
+
+COMMENT ON EXTENSION pgcrypto IS 'cryptographic functions';
+
+
+--
+-- Name: status; Type: TYPE; Schema: app; Owner: app
+--
+
+CREATE TYPE app.status AS ENUM (
+    'active',
+    'archived'
+);
+
+
+ALTER TYPE app.status OWNER TO app;
+
+--
+-- Name: touch(); Type: FUNCTION; Schema: app; Owner: app
+--
+
+CREATE FUNCTION app.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$;
+
+
+ALTER FUNCTION app.touch() OWNER TO app;
+
+SET default_tablespace = '';
+
+SET default_table_access_method = heap;
+
+--
+-- Name: users; Type: TABLE; Schema: app; Owner: app
+--
+
+CREATE TABLE app.users (
+    id bigint NOT NULL,
+    email text NOT NULL,
+    status app.status DEFAULT 'active'::app.status NOT NULL,
+    updated_at timestamp with time zone
+);
+
+
+ALTER TABLE app.users OWNER TO app;
+
+--
+-- Name: COLUMN users.email; Type: COMMENT; Schema: app; Owner: app
+--
+
+COMMENT ON COLUMN app.users.email IS 'Login email';
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE; Schema: app; Owner: app
+--
+
+CREATE SEQUENCE app.users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE app.users_id_seq OWNER TO app;
+
+--
+-- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: app; Owner: app
+--
+
+ALTER SEQUENCE app.users_id_seq OWNED BY app.users.id;
+
+
+--
+-- Name: orders; Type: TABLE; Schema: app; Owner: app
+--
+
+CREATE TABLE app.orders (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    total numeric(12,2),
+    CONSTRAINT orders_total_check CHECK ((total >= (0)::numeric))
+);
+
+
+ALTER TABLE app.orders OWNER TO app;
+
+--
+-- Name: orders_id_seq; Type: SEQUENCE; Schema: app; Owner: app
+--
+
+ALTER TABLE app.orders ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME app.orders_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: users id; Type: DEFAULT; Schema: app; Owner: app
+--
+
+ALTER TABLE ONLY app.users ALTER COLUMN id SET DEFAULT nextval('app.users_id_seq'::regclass);
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE SET; Schema: app; Owner: app
+--
+
+SELECT pg_catalog.setval('app.users_id_seq', 42, true);
+
+
+--
+-- Name: orders orders_pkey; Type: CONSTRAINT; Schema: app; Owner: app
+--
+
+ALTER TABLE ONLY app.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: app; Owner: app
+--
+
+ALTER TABLE ONLY app.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: users_email_idx; Type: INDEX; Schema: app; Owner: app
+--
+
+CREATE UNIQUE INDEX users_email_idx ON app.users USING btree (email);
+
+
+--
+-- Name: users users_touch; Type: TRIGGER; Schema: app; Owner: app
+--
+
+CREATE TRIGGER users_touch BEFORE UPDATE ON app.users FOR EACH ROW EXECUTE FUNCTION app.touch();
+
+
+--
+-- Name: orders orders_user_id_fkey; Type: FK CONSTRAINT; Schema: app; Owner: app
+--
+
+ALTER TABLE ONLY app.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES app.users(id);
+
+
+--
+-- Name: SCHEMA app; Type: ACL; Schema: -; Owner: app
+--
+
+GRANT USAGE ON SCHEMA app TO reader;
+
+
+--
+-- Name: TABLE users; Type: ACL; Schema: app; Owner: app
+--
+
+GRANT SELECT ON TABLE app.users TO reader;
+REVOKE ALL ON TABLE app.orders FROM PUBLIC;
+
+--
+-- PostgreSQL database dump complete
+--
+
+
+\unrestrict Zq0fWp1Rl2Ykx3
+
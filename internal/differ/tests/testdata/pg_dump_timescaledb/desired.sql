@@ -0,0 +1,29 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id INTEGER NOT NULL,
+    value DOUBLE PRECISION
+);
+
+CREATE INDEX metrics_time_idx ON metrics (time DESC);
+
+SELECT create_hypertable('metrics', 'time', chunk_time_interval => INTERVAL '1 day');
+
+CREATE MATERIALIZED VIEW metrics_hourly
+WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket,
+    device_id,
+    avg(value) AS avg_value
+FROM metrics
+GROUP BY time_bucket('1 hour', time), device_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW metrics_daily
+WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', bucket) AS bucket,
+    device_id,
+    avg(avg_value) AS avg_value
+FROM metrics_hourly
+GROUP BY time_bucket('1 day', bucket), device_id
+WITH NO DATA;
@@ -0,0 +1,178 @@
+--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+
+--
+-- Name: timescaledb; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS timescaledb WITH SCHEMA public;
+
+--
+-- Name: _timescaledb_cache; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA _timescaledb_cache;
+
+--
+-- Name: _timescaledb_catalog; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA _timescaledb_catalog;
+
+--
+-- Name: _timescaledb_config; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA _timescaledb_config;
+
+--
+-- Name: _timescaledb_internal; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA _timescaledb_internal;
+
+--
+-- Name: hypertable; Type: TABLE; Schema: _timescaledb_catalog; Owner: postgres
+--
+
+CREATE TABLE _timescaledb_catalog.hypertable (
+    id integer NOT NULL,
+    schema_name name NOT NULL,
+    table_name name NOT NULL,
+    num_dimensions smallint NOT NULL
+);
+
+--
+-- Name: bgw_job; Type: TABLE; Schema: _timescaledb_config; Owner: postgres
+--
+
+CREATE TABLE _timescaledb_config.bgw_job (
+    id integer NOT NULL,
+    application_name name NOT NULL,
+    schedule_interval interval NOT NULL
+);
+
+--
+-- Name: cache_inval_hypertable; Type: TABLE; Schema: _timescaledb_cache; Owner: postgres
+--
+
+CREATE TABLE _timescaledb_cache.cache_inval_hypertable (
+);
+
+--
+-- Name: metrics; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.metrics (
+    "time" timestamp with time zone NOT NULL,
+    device_id integer NOT NULL,
+    value double precision
+);
+
+
+ALTER TABLE public.metrics OWNER TO app;
+
+SELECT create_hypertable('public.metrics', 'time', chunk_time_interval => INTERVAL '1 day');
+
+--
+-- Name: _hyper_1_1_chunk; Type: TABLE; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE TABLE _timescaledb_internal._hyper_1_1_chunk (
+    "time" timestamp with time zone NOT NULL,
+    device_id integer NOT NULL,
+    value double precision,
+    CONSTRAINT constraint_1 CHECK ((("time" >= '2024-06-01 00:00:00+00'::timestamp with time zone) AND ("time" < '2024-06-02 00:00:00+00'::timestamp with time zone)))
+);
+
+--
+-- Name: _hyper_1_2_chunk; Type: TABLE; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE TABLE _timescaledb_internal._hyper_1_2_chunk (
+    "time" timestamp with time zone NOT NULL,
+    device_id integer NOT NULL,
+    value double precision,
+    CONSTRAINT constraint_2 CHECK ((("time" >= '2024-06-02 00:00:00+00'::timestamp with time zone) AND ("time" < '2024-06-03 00:00:00+00'::timestamp with time zone)))
+);
+
+--
+-- Name: _materialized_hypertable_2; Type: TABLE; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE TABLE _timescaledb_internal._materialized_hypertable_2 (
+    bucket timestamp with time zone NOT NULL,
+    device_id integer,
+    avg_value double precision
+);
+
+--
+-- Name: metrics_hourly; Type: MATERIALIZED VIEW; Schema: public; Owner: app
+--
+
+CREATE MATERIALIZED VIEW public.metrics_hourly
+WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', "time") AS bucket,
+    device_id,
+    avg(value) AS avg_value
+FROM public.metrics
+GROUP BY time_bucket('1 hour', "time"), device_id
+WITH NO DATA;
+
+--
+-- Name: _materialized_hypertable_3; Type: TABLE; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE TABLE _timescaledb_internal._materialized_hypertable_3 (
+    bucket timestamp with time zone NOT NULL,
+    device_id integer,
+    avg_value double precision
+);
+
+--
+-- Name: metrics_daily; Type: MATERIALIZED VIEW; Schema: public; Owner: app
+--
+
+CREATE MATERIALIZED VIEW public.metrics_daily
+WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 day', bucket) AS bucket,
+    device_id,
+    avg(avg_value) AS avg_value
+FROM public.metrics_hourly
+GROUP BY time_bucket('1 day', bucket), device_id
+WITH NO DATA;
+
+--
+-- Name: _hyper_1_1_chunk_metrics_time_idx; Type: INDEX; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE INDEX _hyper_1_1_chunk_metrics_time_idx ON _timescaledb_internal._hyper_1_1_chunk USING btree ("time" DESC);
+
+--
+-- Name: _materialized_hypertable_2_bucket_idx; Type: INDEX; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE INDEX _materialized_hypertable_2_bucket_idx ON _timescaledb_internal._materialized_hypertable_2 USING btree (bucket DESC);
+
+--
+-- Name: metrics_time_idx; Type: INDEX; Schema: public; Owner: app
+--
+
+CREATE INDEX metrics_time_idx ON public.metrics USING btree ("time" DESC);
+
+--
+-- Name: _hyper_1_1_chunk ts_insert_blocker; Type: TRIGGER; Schema: _timescaledb_internal; Owner: app
+--
+
+CREATE TRIGGER ts_insert_blocker BEFORE INSERT ON _timescaledb_internal._hyper_1_1_chunk FOR EACH ROW EXECUTE FUNCTION _timescaledb_functions.insert_blocker();
+
+--
+-- PostgreSQL database dump complete
+--
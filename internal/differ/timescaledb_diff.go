@@ -1,9 +1,11 @@
 package differ
 
 import (
+	"cmp"
 	"fmt"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -16,11 +18,10 @@ func (d *Differ) compareHypertables(result *DiffResult) {
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeAddHypertable,
 				Severity: SeveritySafe,
-				Description: fmt.Sprintf(
-					"Convert table to hypertable: %s (time column: %s, interval: %s)",
+				Description: describe(descHypertableAdded,
 					hypertable.QualifiedTableName(),
 					hypertable.TimeColumnName,
-					hypertable.PartitionInterval,
+					cmp.Or(hypertable.PartitionInterval, "default"),
 				),
 				ObjectType: "hypertable",
 				ObjectName: key,
@@ -46,7 +47,7 @@ func (d *Differ) compareHypertables(result *DiffResult) {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropHypertable,
 				Severity:    SeverityBreaking,
-				Description: "Convert hypertable to regular table: " + hypertable.QualifiedTableName(),
+				Description: describe(descHypertableDropped, hypertable.QualifiedTableName()),
 				ObjectType:  "hypertable",
 				ObjectName:  key,
 				Details:     map[string]any{"hypertable": hypertable},
@@ -56,21 +57,100 @@ func (d *Differ) compareHypertables(result *DiffResult) {
 
 	for key, desiredHT := range desiredHypertables {
 		if currentHT, exists := currentHypertables[key]; exists {
+			d.compareDimensions(result, currentHT, desiredHT)
 			d.compareCompressionSettings(result, currentHT, desiredHT)
 			d.compareRetentionPolicies(result, currentHT, desiredHT)
 
 			if currentHT.PartitionInterval != desiredHT.PartitionInterval {
-				result.Warnings = append(result.Warnings, fmt.Sprintf(
-					"Partition interval change detected for %s: %s -> %s. This requires recreating the hypertable.",
-					currentHT.QualifiedTableName(),
-					currentHT.PartitionInterval,
-					desiredHT.PartitionInterval,
-				))
+				result.addWarning(diag.Warning{
+					Code:     diag.CodeHypertableIntervalChange,
+					Severity: diag.SeverityWarning,
+					Message: fmt.Sprintf(
+						"Partition interval change detected for %s: %s -> %s. "+
+							"This requires recreating the hypertable.",
+						currentHT.QualifiedTableName(),
+						currentHT.PartitionInterval,
+						desiredHT.PartitionInterval,
+					),
+					ObjectName: key,
+				})
 			}
 		}
 	}
 }
 
+// compareDimensions diffs the dimensions added with add_dimension. TimescaleDB
+// can only add dimensions, so removed or changed ones are reported as changes
+// that need a manual data migration.
+func (d *Differ) compareDimensions(
+	result *DiffResult,
+	current, desired *schema.Hypertable,
+) {
+	tableKey := TableKey(current.Schema, current.TableName)
+	tableName := current.QualifiedTableName()
+
+	currentDims := buildDimensionMap(current.Dimensions)
+	desiredDims := buildDimensionMap(desired.Dimensions)
+
+	for i := range desired.Dimensions {
+		dim := &desired.Dimensions[i]
+
+		currentDim, exists := currentDims[dim.ColumnName]
+		if !exists {
+			result.Changes = append(result.Changes, Change{
+				Type:     ChangeTypeAddDimension,
+				Severity: SeveritySafe,
+				Description: describeAdded(dim.Type+" dimension",
+					tableName+"("+dim.ColumnName+")"),
+				ObjectType: "dimension",
+				ObjectName: tableKey,
+				Details: map[string]any{
+					"hypertable": desired,
+					"dimension":  dim,
+				},
+			})
+
+			continue
+		}
+
+		if !areDimensionsEqual(currentDim, dim) {
+			result.Changes = append(result.Changes, Change{
+				Type:     ChangeTypeModifyDimension,
+				Severity: SeverityDataMigrationRequired,
+				Description: describe(descManualDiffers, "Dimension",
+					tableName+"("+dim.ColumnName+")"),
+				ObjectType: "dimension",
+				ObjectName: tableKey,
+				Details: map[string]any{
+					"hypertable":        desired,
+					"current_dimension": currentDim,
+					"desired_dimension": dim,
+				},
+			})
+		}
+	}
+
+	for i := range current.Dimensions {
+		dim := &current.Dimensions[i]
+		if _, exists := desiredDims[dim.ColumnName]; exists {
+			continue
+		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeDropDimension,
+			Severity: SeverityDataMigrationRequired,
+			Description: describe(descManualDropped, "Dimension",
+				tableName+"("+dim.ColumnName+")"),
+			ObjectType: "dimension",
+			ObjectName: tableKey,
+			Details: map[string]any{
+				"hypertable": current,
+				"dimension":  dim,
+			},
+		})
+	}
+}
+
 func (d *Differ) compareCompressionSettings(
 	result *DiffResult,
 	current, desired *schema.Hypertable,
@@ -80,11 +160,12 @@ func (d *Differ) compareCompressionSettings(
 
 	if !current.CompressionEnabled && desired.CompressionEnabled {
 		result.Changes = append(result.Changes, Change{
-			Type:        ChangeTypeAddCompressionPolicy,
-			Severity:    SeveritySafe,
-			Description: "Enable compression on hypertable: " + tableName,
-			ObjectType:  "compression_policy",
-			ObjectName:  tableKey,
+			Type:     ChangeTypeAddCompressionPolicy,
+			Severity: SeveritySafe,
+			Description: describeValue("Compression", "hypertable "+tableName,
+				"disabled", "enabled"),
+			ObjectType: "compression_policy",
+			ObjectName: tableKey,
 			Details: map[string]any{
 				"hypertable": desired,
 				"settings":   desired.CompressionSettings,
@@ -96,12 +177,13 @@ func (d *Differ) compareCompressionSettings(
 
 	if current.CompressionEnabled && !desired.CompressionEnabled {
 		result.Changes = append(result.Changes, Change{
-			Type:        ChangeTypeDropCompressionPolicy,
-			Severity:    SeverityPotentiallyBreaking,
-			Description: "Disable compression on hypertable: " + tableName,
-			ObjectType:  "compression_policy",
-			ObjectName:  tableKey,
-			Details:     map[string]any{"hypertable": current},
+			Type:     ChangeTypeDropCompressionPolicy,
+			Severity: SeverityPotentiallyBreaking,
+			Description: describeValue("Compression", "hypertable "+tableName,
+				"enabled", "disabled"),
+			ObjectType: "compression_policy",
+			ObjectName: tableKey,
+			Details:    map[string]any{"hypertable": current},
 		})
 
 		return
@@ -110,20 +192,61 @@ func (d *Differ) compareCompressionSettings(
 	if current.CompressionEnabled && desired.CompressionEnabled {
 		if !areCompressionSettingsEqual(current.CompressionSettings, desired.CompressionSettings) {
 			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeModifyCompressionPolicy,
-				Severity:    SeverityPotentiallyBreaking,
-				Description: "Modify compression settings for hypertable: " + tableName,
-				ObjectType:  "compression_policy",
-				ObjectName:  tableKey,
+				Type:     ChangeTypeModifyCompressionPolicy,
+				Severity: SeverityPotentiallyBreaking,
+				Description: describe(descSettingsDiffer, "Compression settings",
+					"hypertable "+tableName),
+				ObjectType: "compression_policy",
+				ObjectName: tableKey,
 				Details: map[string]any{
 					"current_settings": current.CompressionSettings,
 					"desired_settings": desired.CompressionSettings,
 				},
 			})
 		}
+
+		d.compareCompressionSchedules(result, current, desired)
 	}
 }
 
+// compareCompressionSchedules diffs the add_compression_policy job of a
+// hypertable whose compression stays enabled. Enabling or disabling
+// compression carries the job with it, so those cases never reach here.
+func (d *Differ) compareCompressionSchedules(
+	result *DiffResult,
+	current, desired *schema.Hypertable,
+) {
+	if areCompressionPoliciesEqual(current.CompressionPolicy, desired.CompressionPolicy) {
+		return
+	}
+
+	tableName := current.QualifiedTableName()
+
+	var currentPolicy, desiredPolicy string
+	if current.CompressionPolicy != nil {
+		currentPolicy = "compress after " + current.CompressionPolicy.CompressAfter
+	}
+
+	if desired.CompressionPolicy != nil {
+		desiredPolicy = "compress after " + desired.CompressionPolicy.CompressAfter
+	}
+
+	description := describeValue("Compression policy", "hypertable "+tableName,
+		currentPolicy, desiredPolicy)
+
+	result.Changes = append(result.Changes, Change{
+		Type:        ChangeTypeModifyCompressionSchedule,
+		Severity:    SeveritySafe,
+		Description: description,
+		ObjectType:  "compression_policy",
+		ObjectName:  TableKey(current.Schema, current.TableName),
+		Details: map[string]any{
+			"current_policy": current.CompressionPolicy,
+			"desired_policy": desired.CompressionPolicy,
+		},
+	})
+}
+
 func (d *Differ) compareRetentionPolicies(
 	result *DiffResult,
 	current, desired *schema.Hypertable,
@@ -135,8 +258,8 @@ func (d *Differ) compareRetentionPolicies(
 		result.Changes = append(result.Changes, Change{
 			Type:     ChangeTypeAddRetentionPolicy,
 			Severity: SeverityBreaking,
-			Description: fmt.Sprintf("Add retention policy to hypertable: %s (drop after: %s)",
-				tableName, desired.RetentionPolicy.DropAfter),
+			Description: describeValue("Retention policy", "hypertable "+tableName,
+				"", "drop after "+desired.RetentionPolicy.DropAfter),
 			ObjectType: "retention_policy",
 			ObjectName: tableKey,
 			Details:    map[string]any{"policy": desired.RetentionPolicy},
@@ -147,12 +270,13 @@ func (d *Differ) compareRetentionPolicies(
 
 	if current.RetentionPolicy != nil && desired.RetentionPolicy == nil {
 		result.Changes = append(result.Changes, Change{
-			Type:        ChangeTypeDropRetentionPolicy,
-			Severity:    SeveritySafe,
-			Description: "Remove retention policy from hypertable: " + tableName,
-			ObjectType:  "retention_policy",
-			ObjectName:  tableKey,
-			Details:     map[string]any{"policy": current.RetentionPolicy},
+			Type:     ChangeTypeDropRetentionPolicy,
+			Severity: SeveritySafe,
+			Description: describeValue("Retention policy", "hypertable "+tableName,
+				"drop after "+current.RetentionPolicy.DropAfter, ""),
+			ObjectType: "retention_policy",
+			ObjectName: tableKey,
+			Details:    map[string]any{"policy": current.RetentionPolicy},
 		})
 
 		return
@@ -171,12 +295,9 @@ func (d *Differ) compareRetentionPolicies(
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeModifyRetentionPolicy,
 				Severity: severity,
-				Description: fmt.Sprintf(
-					"Modify retention policy for hypertable: %s (%s -> %s)",
-					tableName,
-					current.RetentionPolicy.DropAfter,
-					desired.RetentionPolicy.DropAfter,
-				),
+				Description: describeValue("Retention policy", "hypertable "+tableName,
+					"drop after "+current.RetentionPolicy.DropAfter,
+					"drop after "+desired.RetentionPolicy.DropAfter),
 				ObjectType: "retention_policy",
 				ObjectName: tableKey,
 				Details: map[string]any{
@@ -197,8 +318,8 @@ func (d *Differ) compareContinuousAggregates(result *DiffResult) {
 			result.Changes = append(result.Changes, Change{
 				Type:     ChangeTypeAddContinuousAggregate,
 				Severity: SeveritySafe,
-				Description: fmt.Sprintf("Add continuous aggregate: %s on %s",
-					agg.QualifiedViewName(), agg.QualifiedHypertableName()),
+				Description: describeAdded("continuous aggregate",
+					agg.QualifiedViewName()+" on "+agg.QualifiedHypertableName()),
 				ObjectType: "continuous_aggregate",
 				ObjectName: key,
 				Details:    map[string]any{"aggregate": agg},
@@ -212,7 +333,7 @@ func (d *Differ) compareContinuousAggregates(result *DiffResult) {
 			result.Changes = append(result.Changes, Change{
 				Type:        ChangeTypeDropContinuousAggregate,
 				Severity:    SeverityBreaking,
-				Description: "Drop continuous aggregate: " + agg.QualifiedViewName(),
+				Description: describeDropped("continuous aggregate", agg.QualifiedViewName()),
 				ObjectType:  "continuous_aggregate",
 				ObjectName:  key,
 				Details:     map[string]any{"aggregate": agg},
@@ -222,21 +343,122 @@ func (d *Differ) compareContinuousAggregates(result *DiffResult) {
 
 	for key, desiredAgg := range desiredAggs {
 		if currentAgg, exists := currentAggs[key]; exists {
-			if !areContinuousAggregatesEqual(currentAgg, desiredAgg) {
+			if !areContinuousAggregatesEqual(currentAgg, desiredAgg, d.options) {
 				result.Changes = append(result.Changes, Change{
-					Type:        ChangeTypeModifyContinuousAggregate,
-					Severity:    SeverityBreaking,
-					Description: "Modify continuous aggregate: " + desiredAgg.QualifiedViewName(),
-					ObjectType:  "continuous_aggregate",
-					ObjectName:  key,
-					Details:     map[string]any{"current": currentAgg, "desired": desiredAgg},
-					DependsOn:   []string{desiredAgg.QualifiedHypertableName()},
+					Type:     ChangeTypeModifyContinuousAggregate,
+					Severity: SeverityBreaking,
+					Description: describeReplaced("continuous aggregate",
+						desiredAgg.QualifiedViewName()),
+					ObjectType: "continuous_aggregate",
+					ObjectName: key,
+					Details:    map[string]any{"current": currentAgg, "desired": desiredAgg},
+					DependsOn:  []string{desiredAgg.QualifiedHypertableName()},
 				})
+
+				continue
 			}
+
+			d.compareRefreshPolicies(result, currentAgg, desiredAgg)
+			d.compareAggregateCompression(result, currentAgg, desiredAgg)
 		}
 	}
 }
 
+// compareRefreshPolicies diffs the add_continuous_aggregate_policy job of a
+// continuous aggregate that is kept. Creating or recreating an aggregate
+// adds its policy, and dropping it removes the job, so those cases never
+// reach here.
+func (d *Differ) compareRefreshPolicies(
+	result *DiffResult,
+	current, desired *schema.ContinuousAggregate,
+) {
+	if areRefreshPoliciesEqual(current.RefreshPolicy, desired.RefreshPolicy) {
+		return
+	}
+
+	description := describeValue("Refresh policy",
+		"continuous aggregate "+current.QualifiedViewName(),
+		formatRefreshPolicy(current.RefreshPolicy), formatRefreshPolicy(desired.RefreshPolicy))
+
+	change := Change{
+		Severity:    SeveritySafe,
+		Description: description,
+		ObjectType:  "refresh_policy",
+		ObjectName:  ViewKey(current.Schema, current.ViewName),
+	}
+
+	switch {
+	case current.RefreshPolicy == nil:
+		change.Type = ChangeTypeAddRefreshPolicy
+		change.Details = map[string]any{"policy": desired.RefreshPolicy}
+	case desired.RefreshPolicy == nil:
+		change.Type = ChangeTypeDropRefreshPolicy
+		change.Details = map[string]any{"policy": current.RefreshPolicy}
+	default:
+		change.Type = ChangeTypeModifyRefreshPolicy
+		change.Details = map[string]any{
+			"current_policy": current.RefreshPolicy,
+			"desired_policy": desired.RefreshPolicy,
+		}
+	}
+
+	result.Changes = append(result.Changes, change)
+}
+
+// compareAggregateCompression diffs compression of a continuous aggregate
+// that is kept. Compression is switched on or off with ALTER MATERIALIZED
+// VIEW and the compression job replaced, so the aggregate keeps its data.
+func (d *Differ) compareAggregateCompression(
+	result *DiffResult,
+	current, desired *schema.ContinuousAggregate,
+) {
+	if current.CompressionEnabled == desired.CompressionEnabled &&
+		areCompressionPoliciesEqual(current.CompressionPolicy, desired.CompressionPolicy) {
+		return
+	}
+
+	severity := SeveritySafe
+	if current.CompressionEnabled && !desired.CompressionEnabled {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	result.Changes = append(result.Changes, Change{
+		Type:     ChangeTypeModifyCACompression,
+		Severity: severity,
+		Description: describeValue("Compression",
+			"continuous aggregate "+current.QualifiedViewName(),
+			formatAggregateCompression(current), formatAggregateCompression(desired)),
+		ObjectType: "continuous_aggregate",
+		ObjectName: ViewKey(current.Schema, current.ViewName),
+		Details:    map[string]any{"current": current, "desired": desired},
+	})
+}
+
+// formatAggregateCompression describes whether a continuous aggregate is
+// compressed, and after how long.
+func formatAggregateCompression(agg *schema.ContinuousAggregate) string {
+	switch {
+	case !agg.CompressionEnabled:
+		return "disabled"
+	case agg.CompressionPolicy == nil:
+		return "enabled"
+	default:
+		return "enabled, compress after " + agg.CompressionPolicy.CompressAfter
+	}
+}
+
+// formatRefreshPolicy describes a refresh policy by its window and schedule,
+// writing an open end of the window as NULL.
+func formatRefreshPolicy(policy *schema.RefreshPolicy) string {
+	if policy == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("start_offset %s, end_offset %s, schedule_interval %s",
+		cmp.Or(policy.StartOffset, "NULL"), cmp.Or(policy.EndOffset, "NULL"),
+		valueOrNone(policy.ScheduleInterval))
+}
+
 func buildHypertableMap(hypertables []schema.Hypertable) map[string]*schema.Hypertable {
 	m := make(map[string]*schema.Hypertable, len(hypertables))
 	for i := range hypertables {
@@ -259,6 +481,36 @@ func buildContinuousAggregateMap(
 	return m
 }
 
+func buildDimensionMap(dimensions []schema.Dimension) map[string]*schema.Dimension {
+	m := make(map[string]*schema.Dimension, len(dimensions))
+	for i := range dimensions {
+		m[dimensions[i].ColumnName] = &dimensions[i]
+	}
+
+	return m
+}
+
+func areDimensionsEqual(d1, d2 *schema.Dimension) bool {
+	if !strings.EqualFold(d1.Type, d2.Type) {
+		return false
+	}
+
+	// Schemas extracted before dimensions were recorded carry no partition
+	// count, so only compare counts both sides state.
+	if d1.NumberPartitions != 0 && d2.NumberPartitions != 0 &&
+		d1.NumberPartitions != d2.NumberPartitions {
+		return false
+	}
+
+	// An omitted interval means TimescaleDB's default, which the database
+	// reports explicitly, so only compare intervals both sides state.
+	if d1.Interval == "" || d2.Interval == "" {
+		return true
+	}
+
+	return normalizeInterval(d1.Interval) == normalizeInterval(d2.Interval)
+}
+
 func areCompressionSettingsEqual(s1, s2 *schema.CompressionSettings) bool {
 	if s1 == nil && s2 == nil {
 		return true
@@ -278,20 +530,38 @@ func areCompressionSettingsEqual(s1, s2 *schema.CompressionSettings) bool {
 	return equalOrderByColumns(s1.OrderByColumns, s2.OrderByColumns)
 }
 
-func areContinuousAggregatesEqual(a1, a2 *schema.ContinuousAggregate) bool {
-	if NormalizeViewDefinition(a1.Query) != NormalizeViewDefinition(a2.Query) {
+func areCompressionPoliciesEqual(p1, p2 *schema.CompressionPolicy) bool {
+	if p1 == nil && p2 == nil {
+		return true
+	}
+
+	if p1 == nil || p2 == nil {
 		return false
 	}
 
-	if a1.QualifiedHypertableName() != a2.QualifiedHypertableName() {
+	if normalizeInterval(p1.CompressAfter) != normalizeInterval(p2.CompressAfter) {
+		return false
+	}
+
+	// The database always reports a schedule, so only compare it when both
+	// sides state one explicitly.
+	if p1.ScheduleInterval == "" || p2.ScheduleInterval == "" {
+		return true
+	}
+
+	return normalizeInterval(p1.ScheduleInterval) == normalizeInterval(p2.ScheduleInterval)
+}
+
+func areContinuousAggregatesEqual(a1, a2 *schema.ContinuousAggregate, opts *Options) bool {
+	if NormalizeViewDefinition(a1.Query) != NormalizeViewDefinition(a2.Query) {
 		return false
 	}
 
-	if !areRefreshPoliciesEqual(a1.RefreshPolicy, a2.RefreshPolicy) {
+	if a1.QualifiedHypertableName() != a2.QualifiedHypertableName() {
 		return false
 	}
 
-	return normalizeComment(a1.Comment) == normalizeComment(a2.Comment)
+	return opts.commentsEqual(a1.Comment, a2.Comment)
 }
 
 func areRefreshPoliciesEqual(p1, p2 *schema.RefreshPolicy) bool {
@@ -303,11 +573,21 @@ func areRefreshPoliciesEqual(p1, p2 *schema.RefreshPolicy) bool {
 		return false
 	}
 
-	return normalizeInterval(p1.StartOffset) == normalizeInterval(p2.StartOffset) &&
-		normalizeInterval(p1.EndOffset) == normalizeInterval(p2.EndOffset) &&
+	return normalizeOffset(p1.StartOffset) == normalizeOffset(p2.StartOffset) &&
+		normalizeOffset(p1.EndOffset) == normalizeOffset(p2.EndOffset) &&
 		normalizeInterval(p1.ScheduleInterval) == normalizeInterval(p2.ScheduleInterval)
 }
 
+// normalizeOffset normalizes an offset of a refresh window, reading NULL as
+// the open end an empty offset stands for.
+func normalizeOffset(offset string) string {
+	if strings.EqualFold(strings.TrimSpace(offset), "null") {
+		return ""
+	}
+
+	return normalizeInterval(offset)
+}
+
 func normalizeInterval(interval string) string {
 	s := strings.ToLower(strings.TrimSpace(interval))
 
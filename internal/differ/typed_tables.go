@@ -0,0 +1,222 @@
+package differ
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// typedOfKey returns the TableKey of a typed table's composite type, or ""
+// for a table that is not typed.
+func typedOfKey(table *schema.Table) string {
+	if table.TypedOf == "" {
+		return ""
+	}
+
+	schemaName, name, found := strings.Cut(table.TypedOf, ".")
+	if !found {
+		schemaName, name = "", schemaName
+	}
+
+	return TableKey(schemaName, name)
+}
+
+// attributeTypesEqual compares attribute types the way the catalog and a
+// schema file may write them, as in numeric(10,2) and NUMERIC(10, 2).
+func attributeTypesEqual(a, b string) bool {
+	normalize := func(dataType string) string {
+		return strings.ReplaceAll(NormalizeDataType(dataType), ", ", ",")
+	}
+
+	return normalize(a) == normalize(b)
+}
+
+func (d *Differ) compareTypeAttributes(
+	result *DiffResult,
+	key string,
+	current, desired *schema.CustomType,
+) {
+	attributeKey := func(attr *schema.TypeAttribute) string {
+		return attr.Name
+	}
+
+	currentAttrs := make(map[string]*schema.TypeAttribute, len(current.Attributes))
+	for i := range current.Attributes {
+		currentAttrs[attributeKey(&current.Attributes[i])] = &current.Attributes[i]
+	}
+
+	desiredAttrs := make(map[string]*schema.TypeAttribute, len(desired.Attributes))
+	for i := range desired.Attributes {
+		desiredAttrs[attributeKey(&desired.Attributes[i])] = &desired.Attributes[i]
+	}
+
+	typeName := desired.QualifiedName()
+
+	for name, attr := range mappedInOrder(desired.Attributes, desiredAttrs, attributeKey) {
+		change := Change{
+			ObjectType: "type",
+			ObjectName: key,
+			Details: map[string]any{
+				"type_name": typeName,
+				"attribute": attr.Name,
+				"new_type":  attr.DataType,
+			},
+		}
+
+		switch existing := currentAttrs[name]; {
+		case existing == nil:
+			change.Severity = SeveritySafe
+			change.Description = describeAdded("attribute",
+				fmt.Sprintf("%s.%s (%s)", typeName, attr.Name, attr.DataType))
+		case !attributeTypesEqual(existing.DataType, attr.DataType):
+			change.Severity = SeverityDataMigrationRequired
+			change.Description = describeValue("Attribute type", typeName+"."+attr.Name,
+				existing.DataType, attr.DataType)
+			change.Details["old_type"] = existing.DataType
+		default:
+			continue
+		}
+
+		change.Type = ChangeTypeModifyCustomType
+		result.Changes = append(result.Changes, change)
+	}
+
+	for name, attr := range mappedInOrder(current.Attributes, currentAttrs, attributeKey) {
+		if _, exists := desiredAttrs[name]; exists {
+			continue
+		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeModifyCustomType,
+			Severity:    SeverityBreaking,
+			Description: describeDropped("attribute", typeName+"."+attr.Name),
+			ObjectType:  "type",
+			ObjectName:  key,
+			Details: map[string]any{
+				"type_name": typeName,
+				"attribute": attr.Name,
+				"old_type":  attr.DataType,
+			},
+		})
+	}
+}
+
+// followTypedTables reconciles typed tables with their composite types. The
+// columns of a typed table are the attributes of its type, so the column
+// changes found on a table typed by the same type in both schemas are made by
+// ALTER TYPE ... CASCADE, and ALTER TABLE would refuse them. Each attribute
+// change records the typed tables it carries over to, which a note lists.
+func (d *Differ) followTypedTables(result *DiffResult) {
+	current := make(map[string]*schema.Table, len(result.Current.Tables))
+	for i := range result.Current.Tables {
+		table := &result.Current.Tables[i]
+		current[TableKey(table.Schema, table.Name)] = table
+	}
+
+	typedTables := make(map[string][]string)
+	followed := make(map[string]bool)
+
+	for i := range result.Desired.Tables {
+		table := &result.Desired.Tables[i]
+		key := TableKey(table.Schema, table.Name)
+
+		existing := current[key]
+		if existing == nil {
+			continue
+		}
+
+		typeKey := typedOfKey(table)
+		if typeKey != typedOfKey(existing) {
+			result.Notes = append(result.Notes, fmt.Sprintf(
+				"table %s is %s in database and %s in desired schema; pgtofu does not "+
+					"generate ALTER TABLE ... OF or NOT OF, so change it with a manual migration",
+				table.QualifiedName(), describeTypedOf(existing), describeTypedOf(table),
+			))
+
+			continue
+		}
+
+		if typeKey != "" {
+			typedTables[typeKey] = append(typedTables[typeKey], table.QualifiedName())
+			followed[key] = true
+		}
+	}
+
+	if len(followed) == 0 {
+		return
+	}
+
+	result.Changes = slices.DeleteFunc(result.Changes, func(change Change) bool {
+		switch change.Type {
+		case ChangeTypeAddColumn, ChangeTypeDropColumn, ChangeTypeModifyColumnType:
+			return followed[change.ObjectName]
+		}
+
+		return false
+	})
+
+	var carried []string
+
+	for i := range result.Changes {
+		change := &result.Changes[i]
+		if _, ok := change.Details["attribute"].(string); !ok ||
+			change.Type != ChangeTypeModifyCustomType {
+			continue
+		}
+
+		tables := typedTables[change.ObjectName]
+		if len(tables) == 0 {
+			continue
+		}
+
+		change.Details["typed_tables"] = tables
+
+		if !slices.Contains(carried, change.ObjectName) {
+			carried = append(carried, change.ObjectName)
+			result.Notes = append(result.Notes, fmt.Sprintf(
+				"attribute changes of type %s also change the columns of its typed tables "+
+					"%s, through ALTER TYPE ... CASCADE",
+				change.Details["type_name"], strings.Join(tables, ", "),
+			))
+		}
+	}
+}
+
+func describeTypedOf(table *schema.Table) string {
+	if table.TypedOf == "" {
+		return "not typed"
+	}
+
+	return "typed OF " + table.TypedOf
+}
+
+// attributeChangeReaches reports whether an index or constraint change uses
+// the column that an attribute change adds, retypes or drops on one of the
+// type's typed tables.
+func attributeChangeReaches(attributeChange, other *Change) bool {
+	if attributeChange.Type != ChangeTypeModifyCustomType {
+		return false
+	}
+
+	attribute, _ := attributeChange.Details["attribute"].(string)
+	tables, _ := attributeChange.Details["typed_tables"].([]string)
+
+	for _, table := range tables {
+		if indexUsesColumn(other, table, attribute) ||
+			constraintUsesColumn(other, table, attribute) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attributeType returns the type an attribute change gives the attribute,
+// under "new_type", or takes from it, under "old_type"; it is empty for an
+// attribute the change adds or drops.
+func attributeType(change *Change, key string) string {
+	dataType, _ := change.Details[key].(string)
+	return dataType
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -21,8 +22,10 @@ type ChangeType string
 const (
 	ChangeTypeAddSchema                 ChangeType = "ADD_SCHEMA"
 	ChangeTypeDropSchema                ChangeType = "DROP_SCHEMA"
+	ChangeTypeModifySchemaComment       ChangeType = "MODIFY_SCHEMA_COMMENT"
 	ChangeTypeAddTable                  ChangeType = "ADD_TABLE"
 	ChangeTypeDropTable                 ChangeType = "DROP_TABLE"
+	ChangeTypeRecreateTable             ChangeType = "RECREATE_TABLE"
 	ChangeTypeModifyTableComment        ChangeType = "MODIFY_TABLE_COMMENT"
 	ChangeTypeAddView                   ChangeType = "ADD_VIEW"
 	ChangeTypeDropView                  ChangeType = "DROP_VIEW"
@@ -36,6 +39,13 @@ const (
 	ChangeTypeAddTrigger                ChangeType = "ADD_TRIGGER"
 	ChangeTypeDropTrigger               ChangeType = "DROP_TRIGGER"
 	ChangeTypeModifyTrigger             ChangeType = "MODIFY_TRIGGER"
+	ChangeTypeModifyTriggerComment      ChangeType = "MODIFY_TRIGGER_COMMENT"
+	ChangeTypeAddPolicy                 ChangeType = "ADD_POLICY"
+	ChangeTypeDropPolicy                ChangeType = "DROP_POLICY"
+	ChangeTypeModifyPolicy              ChangeType = "MODIFY_POLICY"
+	ChangeTypeModifyTableRowSecurity    ChangeType = "MODIFY_TABLE_ROW_SECURITY"
+	ChangeTypeAddGrant                  ChangeType = "ADD_GRANT"
+	ChangeTypeRevokeGrant               ChangeType = "REVOKE_GRANT"
 	ChangeTypeAddExtension              ChangeType = "ADD_EXTENSION"
 	ChangeTypeDropExtension             ChangeType = "DROP_EXTENSION"
 	ChangeTypeModifyExtension           ChangeType = "MODIFY_EXTENSION"
@@ -45,12 +55,20 @@ const (
 	ChangeTypeAddCustomType             ChangeType = "ADD_CUSTOM_TYPE"
 	ChangeTypeDropCustomType            ChangeType = "DROP_CUSTOM_TYPE"
 	ChangeTypeModifyCustomType          ChangeType = "MODIFY_CUSTOM_TYPE"
+	ChangeTypeModifyCustomTypeComment   ChangeType = "MODIFY_CUSTOM_TYPE_COMMENT"
 	ChangeTypeAddColumn                 ChangeType = "ADD_COLUMN"
 	ChangeTypeDropColumn                ChangeType = "DROP_COLUMN"
 	ChangeTypeModifyColumnType          ChangeType = "MODIFY_COLUMN_TYPE"
 	ChangeTypeModifyColumnNullability   ChangeType = "MODIFY_COLUMN_NULLABILITY"
 	ChangeTypeModifyColumnDefault       ChangeType = "MODIFY_COLUMN_DEFAULT"
 	ChangeTypeModifyColumnComment       ChangeType = "MODIFY_COLUMN_COMMENT"
+	ChangeTypeModifyColumnStorage       ChangeType = "MODIFY_COLUMN_STORAGE"
+	ChangeTypeModifyColumnCompression   ChangeType = "MODIFY_COLUMN_COMPRESSION"
+	ChangeTypeModifyColumnGeneration    ChangeType = "MODIFY_COLUMN_GENERATION"
+	ChangeTypeAddColumnIdentity         ChangeType = "ADD_COLUMN_IDENTITY"
+	ChangeTypeDropColumnIdentity        ChangeType = "DROP_COLUMN_IDENTITY"
+	ChangeTypeModifyColumnIdentity      ChangeType = "MODIFY_COLUMN_IDENTITY"
+	ChangeTypeModifyConstraintComment   ChangeType = "MODIFY_CONSTRAINT_COMMENT"
 	ChangeTypeRenameColumn              ChangeType = "RENAME_COLUMN"
 	ChangeTypeAddConstraint             ChangeType = "ADD_CONSTRAINT"
 	ChangeTypeDropConstraint            ChangeType = "DROP_CONSTRAINT"
@@ -58,22 +76,118 @@ const (
 	ChangeTypeAddIndex                  ChangeType = "ADD_INDEX"
 	ChangeTypeDropIndex                 ChangeType = "DROP_INDEX"
 	ChangeTypeModifyIndex               ChangeType = "MODIFY_INDEX"
+	ChangeTypeModifyIndexComment        ChangeType = "MODIFY_INDEX_COMMENT"
 	ChangeTypeAddPartition              ChangeType = "ADD_PARTITION"
 	ChangeTypeDropPartition             ChangeType = "DROP_PARTITION"
 	ChangeTypeAddHypertable             ChangeType = "ADD_HYPERTABLE"
 	ChangeTypeDropHypertable            ChangeType = "DROP_HYPERTABLE"
 	ChangeTypeModifyHypertable          ChangeType = "MODIFY_HYPERTABLE"
+	ChangeTypeAddDimension              ChangeType = "ADD_DIMENSION"
+	ChangeTypeDropDimension             ChangeType = "DROP_DIMENSION"
+	ChangeTypeModifyDimension           ChangeType = "MODIFY_DIMENSION"
 	ChangeTypeAddCompressionPolicy      ChangeType = "ADD_COMPRESSION_POLICY"
 	ChangeTypeDropCompressionPolicy     ChangeType = "DROP_COMPRESSION_POLICY"
 	ChangeTypeModifyCompressionPolicy   ChangeType = "MODIFY_COMPRESSION_POLICY"
+	ChangeTypeModifyCompressionSchedule ChangeType = "MODIFY_COMPRESSION_SCHEDULE"
 	ChangeTypeAddRetentionPolicy        ChangeType = "ADD_RETENTION_POLICY"
 	ChangeTypeDropRetentionPolicy       ChangeType = "DROP_RETENTION_POLICY"
 	ChangeTypeModifyRetentionPolicy     ChangeType = "MODIFY_RETENTION_POLICY"
 	ChangeTypeAddContinuousAggregate    ChangeType = "ADD_CONTINUOUS_AGGREGATE"
 	ChangeTypeDropContinuousAggregate   ChangeType = "DROP_CONTINUOUS_AGGREGATE"
 	ChangeTypeModifyContinuousAggregate ChangeType = "MODIFY_CONTINUOUS_AGGREGATE"
+	ChangeTypeAddRefreshPolicy          ChangeType = "ADD_REFRESH_POLICY"
+	ChangeTypeDropRefreshPolicy         ChangeType = "DROP_REFRESH_POLICY"
+	ChangeTypeModifyRefreshPolicy       ChangeType = "MODIFY_REFRESH_POLICY"
+	ChangeTypeModifyCACompression       ChangeType = "MODIFY_CA_COMPRESSION"
 )
 
+// ChangeTypes returns every change type, in the order they are declared. A
+// new change type is a new constant and a new entry here; `pgtofu version
+// --json` reports this list to wrappers that branch on what a release knows.
+func ChangeTypes() []ChangeType {
+	return []ChangeType{
+		ChangeTypeAddSchema,
+		ChangeTypeDropSchema,
+		ChangeTypeModifySchemaComment,
+		ChangeTypeAddTable,
+		ChangeTypeDropTable,
+		ChangeTypeRecreateTable,
+		ChangeTypeModifyTableComment,
+		ChangeTypeAddView,
+		ChangeTypeDropView,
+		ChangeTypeModifyView,
+		ChangeTypeAddMaterializedView,
+		ChangeTypeDropMaterializedView,
+		ChangeTypeModifyMaterializedView,
+		ChangeTypeAddFunction,
+		ChangeTypeDropFunction,
+		ChangeTypeModifyFunction,
+		ChangeTypeAddTrigger,
+		ChangeTypeDropTrigger,
+		ChangeTypeModifyTrigger,
+		ChangeTypeModifyTriggerComment,
+		ChangeTypeAddPolicy,
+		ChangeTypeDropPolicy,
+		ChangeTypeModifyPolicy,
+		ChangeTypeModifyTableRowSecurity,
+		ChangeTypeAddGrant,
+		ChangeTypeRevokeGrant,
+		ChangeTypeAddExtension,
+		ChangeTypeDropExtension,
+		ChangeTypeModifyExtension,
+		ChangeTypeAddSequence,
+		ChangeTypeDropSequence,
+		ChangeTypeModifySequence,
+		ChangeTypeAddCustomType,
+		ChangeTypeDropCustomType,
+		ChangeTypeModifyCustomType,
+		ChangeTypeModifyCustomTypeComment,
+		ChangeTypeAddColumn,
+		ChangeTypeDropColumn,
+		ChangeTypeModifyColumnType,
+		ChangeTypeModifyColumnNullability,
+		ChangeTypeModifyColumnDefault,
+		ChangeTypeModifyColumnComment,
+		ChangeTypeModifyColumnStorage,
+		ChangeTypeModifyColumnCompression,
+		ChangeTypeModifyColumnGeneration,
+		ChangeTypeAddColumnIdentity,
+		ChangeTypeDropColumnIdentity,
+		ChangeTypeModifyColumnIdentity,
+		ChangeTypeModifyConstraintComment,
+		ChangeTypeRenameColumn,
+		ChangeTypeAddConstraint,
+		ChangeTypeDropConstraint,
+		ChangeTypeModifyConstraint,
+		ChangeTypeAddIndex,
+		ChangeTypeDropIndex,
+		ChangeTypeModifyIndex,
+		ChangeTypeModifyIndexComment,
+		ChangeTypeAddPartition,
+		ChangeTypeDropPartition,
+		ChangeTypeAddHypertable,
+		ChangeTypeDropHypertable,
+		ChangeTypeModifyHypertable,
+		ChangeTypeAddDimension,
+		ChangeTypeDropDimension,
+		ChangeTypeModifyDimension,
+		ChangeTypeAddCompressionPolicy,
+		ChangeTypeDropCompressionPolicy,
+		ChangeTypeModifyCompressionPolicy,
+		ChangeTypeModifyCompressionSchedule,
+		ChangeTypeAddRetentionPolicy,
+		ChangeTypeDropRetentionPolicy,
+		ChangeTypeModifyRetentionPolicy,
+		ChangeTypeAddContinuousAggregate,
+		ChangeTypeDropContinuousAggregate,
+		ChangeTypeModifyContinuousAggregate,
+		ChangeTypeAddRefreshPolicy,
+		ChangeTypeDropRefreshPolicy,
+		ChangeTypeModifyRefreshPolicy,
+		ChangeTypeModifyCACompression,
+	}
+}
+
 type Change struct {
 	Type        ChangeType
 	Severity    ChangeSeverity
@@ -83,6 +197,23 @@ type Change struct {
 	Details     map[string]any
 	DependsOn   []string
 	Order       int
+	// Source locates the declaration the change was derived from. It is nil
+	// when the schemas the change came from were not parsed from files.
+	Source *ChangeSource
+	// Migration names the migration the change is pinned to, either by a
+	// "-- pgtofu:migration <name>" annotation on its object or because a
+	// pinned change depends on it. Unpinned changes leave it empty.
+	Migration string
+}
+
+// ChangeSource locates the declaration behind a change.
+type ChangeSource struct {
+	// Location is where the object is declared. For a removed object it
+	// points into the current state and is zero when that state was not
+	// parsed from files.
+	Location schema.SourceLocation
+	// Removed reports that the object is not present in the desired state.
+	Removed bool
 }
 
 func (c *Change) String() string {
@@ -90,11 +221,20 @@ func (c *Change) String() string {
 }
 
 type DiffResult struct {
-	Current  *schema.Database
-	Desired  *schema.Database
-	Changes  []Change
+	Current *schema.Database
+	Desired *schema.Database
+	Changes []Change
+	// Diagnostics are the warnings raised while comparing, with stable codes.
+	Diagnostics []diag.Warning
+	// Deprecated: Warnings holds the messages of Diagnostics and will be
+	// removed in the next release.
 	Warnings []string
-	Stats    DiffStats
+	// Notes are informational messages about differences that were
+	// deliberately not turned into changes.
+	Notes []string
+	// OptionsHash identifies the options the schemas were compared with.
+	OptionsHash string
+	Stats       DiffStats
 }
 
 type DiffStats struct {
@@ -116,6 +256,11 @@ type DiffStats struct {
 	FunctionsModified  int
 }
 
+func (dr *DiffResult) addWarning(warning diag.Warning) {
+	dr.Diagnostics = append(dr.Diagnostics, warning)
+	dr.Warnings = append(dr.Warnings, warning.Message)
+}
+
 func (dr *DiffResult) HasChanges() bool {
 	return len(dr.Changes) > 0
 }
@@ -161,6 +306,8 @@ func (dr *DiffResult) Summary() string {
 
 	if !dr.HasChanges() {
 		sb.WriteString("No changes detected.\n")
+		dr.writeNotes(&sb)
+
 		return sb.String()
 	}
 
@@ -210,37 +357,112 @@ func (dr *DiffResult) Summary() string {
 	fmt.Fprintf(&sb, "  Breaking: %d\n", len(breaking))
 	fmt.Fprintf(&sb, "  Data Migration Required: %d\n", len(dataMigration))
 
-	if len(dr.Warnings) > 0 {
-		fmt.Fprintf(&sb, "\nWarnings: %d\n", len(dr.Warnings))
+	if len(dr.Diagnostics) > 0 {
+		fmt.Fprintf(&sb, "\nWarnings: %d\n", len(dr.Diagnostics))
 	}
 
+	dr.writePlanSizeAdvisories(&sb)
+	dr.writeRefreshHazards(&sb)
+	dr.writeNotes(&sb)
+
 	return sb.String()
 }
 
+// writeRefreshHazards lists the changes that break concurrent refreshes of a
+// materialized view while they run, since a scheduled refresh cannot wait for
+// the migration.
+func (dr *DiffResult) writeRefreshHazards(sb *strings.Builder) {
+	dr.writeDiagnostics(sb, "Concurrent Refresh Hazards", diag.CodeMatviewConcurrentRefreshHazard)
+}
+
+// writePlanSizeAdvisories lists the plan size limits the plan exceeds, so
+// they are seen before the changes are reviewed one by one.
+func (dr *DiffResult) writePlanSizeAdvisories(sb *strings.Builder) {
+	dr.writeDiagnostics(sb, "Plan Size Advisories", diag.CodePlanTooLarge)
+}
+
+func (dr *DiffResult) writeDiagnostics(sb *strings.Builder, title string, code diag.Code) {
+	var messages []string
+
+	for _, warning := range dr.Diagnostics {
+		if warning.Code == code {
+			messages = append(messages, warning.Message)
+		}
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	sb.WriteString("\n" + title + ":\n")
+
+	for _, message := range messages {
+		fmt.Fprintf(sb, "  - %s\n", message)
+	}
+}
+
+func (dr *DiffResult) writeNotes(sb *strings.Builder) {
+	if len(dr.Notes) == 0 {
+		return
+	}
+
+	sb.WriteString("\nNotes:\n")
+
+	for _, note := range dr.Notes {
+		fmt.Fprintf(sb, "  - %s\n", note)
+	}
+}
+
+// TableKey is the key a table is matched by across both schemas. Names are
+// compared as PostgreSQL stores them, so "UserAccounts" and useraccounts are
+// different tables.
 func TableKey(schema, name string) string {
-	return fmt.Sprintf("%s.%s", normalizeSchema(schema), strings.ToLower(name))
+	return fmt.Sprintf("%s.%s", normalizeSchema(schema), name)
 }
 
 func ViewKey(schema, name string) string {
-	return fmt.Sprintf("%s.%s", normalizeSchema(schema), strings.ToLower(name))
+	return fmt.Sprintf("%s.%s", normalizeSchema(schema), name)
 }
 
-func FunctionKey(schema, name string, argTypes []string) string {
+// FunctionKey is the key a function is matched by across both schemas. Its
+// argument types are canonicalized, so f(int, varchar) and
+// f(integer, character varying) are the same function.
+func FunctionKey(schemaName, name string, argTypes []string) string {
+	canonical := make([]string, len(argTypes))
+	for i, argType := range argTypes {
+		canonical[i] = schema.CanonicalDataType(argType)
+	}
+
 	return fmt.Sprintf("%s.%s(%s)",
-		normalizeSchema(schema),
-		strings.ToLower(name),
-		strings.Join(argTypes, ","))
+		normalizeSchema(schemaName),
+		name,
+		strings.Join(canonical, ","))
+}
+
+// ProcedureKey is FunctionKey for a procedure. The suffix keeps a procedure
+// apart from a function with the same name and arguments.
+func ProcedureKey(schema, name string, argTypes []string) string {
+	return FunctionKey(schema, name, argTypes) + " procedure"
+}
+
+// RoutineKey is the FunctionKey or ProcedureKey of fn.
+func RoutineKey(fn *schema.Function) string {
+	if fn.IsProcedure() {
+		return ProcedureKey(fn.Schema, fn.Name, fn.ArgumentTypes)
+	}
+
+	return FunctionKey(fn.Schema, fn.Name, fn.ArgumentTypes)
 }
 
 func IndexKey(schema, name string) string {
-	return fmt.Sprintf("%s.%s", normalizeSchema(schema), strings.ToLower(name))
+	return fmt.Sprintf("%s.%s", normalizeSchema(schema), name)
 }
 
 func PartitionKey(tableSchema, tableName, partitionName string) string {
 	return fmt.Sprintf("%s.%s.%s",
 		normalizeSchema(tableSchema),
-		strings.ToLower(tableName),
-		strings.ToLower(partitionName))
+		tableName,
+		partitionName)
 }
 
 func normalizeSchema(s string) string {
@@ -248,5 +470,5 @@ func normalizeSchema(s string) string {
 		return schema.DefaultSchema
 	}
 
-	return strings.ToLower(s)
+	return s
 }
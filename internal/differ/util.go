@@ -6,6 +6,38 @@ import (
 	"strings"
 )
 
+// Expression normalization runs for every check constraint, index predicate
+// and default compared, so its patterns are compiled once.
+var (
+	parenthesizedNumberPattern = regexp.MustCompile(`\((-?\d+(?:\.\d+)?)\)`)
+	parenthesizedStringPattern = regexp.MustCompile(`\(('[^']*')\)`)
+	parenthesizedIdentPattern  = regexp.MustCompile(`\((\w+)\)`)
+
+	singleElementInPattern        = regexp.MustCompile(`(\w+)\s+in\s*\(([^,)]+)\)`)
+	singleElementInNoParenPattern = regexp.MustCompile(`(\w+)\s+in\s+('[^']*')`)
+	inListPattern                 = regexp.MustCompile(`(\w+)\s+in\s*\(([^)]+)\)`)
+	betweenPattern                = regexp.MustCompile(`(\w+)\s+between\s+(\S+)\s+and\s+(\S+)`)
+	whitespaceRunPattern          = regexp.MustCompile(`\s+`)
+
+	// materializationTablePattern matches the names TimescaleDB gives the
+	// hypertables that store continuous aggregates.
+	materializationTablePattern = regexp.MustCompile(`_materialized_hypertable_\d+`)
+
+	arrayComparisonPattern = regexp.MustCompile(
+		`\s*(=|<>)\s*(any|all)\s*\(\s*\(?array\s*\[(.*?)\]\s*\)?\s*\)`,
+	)
+	arrayLiteralPattern = regexp.MustCompile(
+		`(?:\((\w+|"[^"]+")\)|(\w+|"[^"]+"))` +
+			`\s*=\s*any\s*\(?\s*'\{([^}]*)\}'` +
+			`(?:::(?:text|character varying|integer|bigint))?(?:\[\])?\s*\)?`,
+	)
+	arrayLiteralAllPattern = regexp.MustCompile(
+		`(?:\((\w+|"[^"]+")\)|(\w+|"[^"]+"))` +
+			`\s*<>\s*all\s*\(?\s*'\{([^}]*)\}'` +
+			`(?:::(?:text|character varying|integer|bigint))?(?:\[\])?\s*\)?`,
+	)
+)
+
 func normalizeExpression(expr string) string {
 	expr = strings.TrimSpace(expr)
 
@@ -174,12 +206,9 @@ func removeTypeCasts(expr string) string {
 }
 
 func removeLiteralParens(expr string) string {
-	numPattern := regexp.MustCompile(`\((-?\d+(?:\.\d+)?)\)`)
-	expr = numPattern.ReplaceAllString(expr, "$1")
-	strPattern := regexp.MustCompile(`\(('[^']*')\)`)
-	expr = strPattern.ReplaceAllString(expr, "$1")
-	identPattern := regexp.MustCompile(`\((\w+)\)`)
-	expr = identPattern.ReplaceAllString(expr, "$1")
+	expr = parenthesizedNumberPattern.ReplaceAllString(expr, "$1")
+	expr = parenthesizedStringPattern.ReplaceAllString(expr, "$1")
+	expr = parenthesizedIdentPattern.ReplaceAllString(expr, "$1")
 	expr = removeComparisonParens(expr)
 
 	return expr
@@ -389,20 +418,13 @@ func normalizeInClauses(expr string) string {
 }
 
 func normalizeSingleElementIn(expr string) string {
-	withParens := regexp.MustCompile(`(\w+)\s+in\s*\(([^,)]+)\)`)
-	expr = withParens.ReplaceAllString(expr, "$1 = $2")
-
-	withoutParens := regexp.MustCompile(`(\w+)\s+in\s+('[^']*')`)
-	expr = withoutParens.ReplaceAllString(expr, "$1 = $2")
+	expr = singleElementInPattern.ReplaceAllString(expr, "$1 = $2")
+	expr = singleElementInNoParenPattern.ReplaceAllString(expr, "$1 = $2")
 
 	return expr
 }
 
 func normalizeArrayConstructorToIn(expr string) string {
-	arrayComparisonPattern := regexp.MustCompile(
-		`\s*(=|<>)\s*(any|all)\s*\(\s*\(?array\s*\[(.*?)\]\s*\)?\s*\)`,
-	)
-
 	for {
 		match := findArrayConstructorComparison(expr, arrayComparisonPattern)
 		if match == nil {
@@ -658,18 +680,6 @@ func splitExpressionList(values string) []string {
 }
 
 func normalizeArrayLiteralToIn(expr string) string {
-	arrayLiteralPattern := regexp.MustCompile(
-		`(?:\((\w+|"[^"]+")\)|(\w+|"[^"]+"))` +
-			`\s*=\s*any\s*\(?\s*'\{([^}]*)\}'` +
-			`(?:::(?:text|character varying|integer|bigint))?(?:\[\])?\s*\)?`,
-	)
-
-	arrayLiteralAllPattern := regexp.MustCompile(
-		`(?:\((\w+|"[^"]+")\)|(\w+|"[^"]+"))` +
-			`\s*<>\s*all\s*\(?\s*'\{([^}]*)\}'` +
-			`(?:::(?:text|character varying|integer|bigint))?(?:\[\])?\s*\)?`,
-	)
-
 	expr = arrayLiteralAllPattern.ReplaceAllStringFunc(expr, func(match string) string {
 		submatches := arrayLiteralAllPattern.FindStringSubmatch(match)
 		if len(submatches) == 4 {
@@ -732,10 +742,8 @@ func normalizeArrayLiteralToIn(expr string) string {
 }
 
 func normalizeInClauseSpacing(expr string) string {
-	inPattern := regexp.MustCompile(`(\w+)\s+in\s*\(([^)]+)\)`)
-
-	return inPattern.ReplaceAllStringFunc(expr, func(match string) string {
-		submatches := inPattern.FindStringSubmatch(match)
+	return inListPattern.ReplaceAllStringFunc(expr, func(match string) string {
+		submatches := inListPattern.FindStringSubmatch(match)
 		if len(submatches) == 3 {
 			col := submatches[1]
 			values := submatches[2]
@@ -786,10 +794,6 @@ func stripTopLevelAndParens(expr string) string {
 }
 
 func expandBetween(expr string) string {
-	betweenPattern := regexp.MustCompile(
-		`(\w+)\s+between\s+(\S+)\s+and\s+(\S+)`,
-	)
-
 	return betweenPattern.ReplaceAllString(expr, "(($1 >= $2) and ($1 <= $3))")
 }
 
@@ -829,15 +833,36 @@ func normalizeLikeOperators(expr string) string {
 	return strings.Join(strings.Fields(expr), " ")
 }
 
+// commentsEqual reports whether two comments are the same under the options.
+// Comments always match once their line breaks are removed: a multi-line
+// comment is generated as one literal per line, which PostgreSQL joins
+// without the line breaks. With NormalizeCommentWhitespace, comments also
+// match when they have the same words with any whitespace between them.
+func (o *Options) commentsEqual(a, b string) bool {
+	if !o.NormalizeCommentWhitespace {
+		return joinCommentLines(a) == joinCommentLines(b)
+	}
+
+	return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ") ||
+		normalizeComment(a) == normalizeComment(b)
+}
+
 func normalizeComment(comment string) string {
 	if comment == "" {
 		return ""
 	}
 
+	comment = joinCommentLines(comment)
+	comment = whitespaceRunPattern.ReplaceAllString(comment, " ")
+
+	return strings.TrimSpace(comment)
+}
+
+// joinCommentLines removes the line breaks of a comment, as PostgreSQL does
+// when it joins the per-line literals of a generated multi-line comment.
+func joinCommentLines(comment string) string {
 	comment = strings.ReplaceAll(comment, "\r\n", "")
 	comment = strings.ReplaceAll(comment, "\n", "")
-	comment = strings.ReplaceAll(comment, "\r", "")
-	comment = regexp.MustCompile(`\s+`).ReplaceAllString(comment, " ")
 
-	return strings.TrimSpace(comment)
+	return strings.ReplaceAll(comment, "\r", "")
 }
@@ -1,6 +1,9 @@
 package differ
 
 import (
+	"maps"
+	"strings"
+
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -23,26 +26,23 @@ func (vc *ViewComparator) AreEqual(current, desired schema.View) bool {
 		) == vc.normalizer.normalizeDefinition(
 			desired.Definition,
 		) &&
-			normalizeCheckOption(current.CheckOption) == normalizeCheckOption(desired.CheckOption)
+			maps.Equal(viewOptions(&current), viewOptions(&desired))
 	}
 
-	currentComment := normalizeComment(current.Comment)
-	desiredComment := normalizeComment(desired.Comment)
-
 	return vc.normalizer.normalizeDefinition(
 		current.Definition,
 	) == vc.normalizer.normalizeDefinition(
 		desired.Definition,
 	) &&
-		normalizeCheckOption(current.CheckOption) == normalizeCheckOption(desired.CheckOption) &&
-		currentComment == desiredComment
+		maps.Equal(viewOptions(&current), viewOptions(&desired)) &&
+		vc.options.commentsEqual(current.Comment, desired.Comment)
 }
 
 func (vc *ViewComparator) CreateAddChange(key string, view schema.View) Change {
 	return Change{
 		Type:        ChangeTypeAddView,
 		Severity:    SeveritySafe,
-		Description: "Add view: " + view.QualifiedName(),
+		Description: describeAdded("view", view.QualifiedName()),
 		ObjectType:  "view",
 		ObjectName:  key,
 		Details:     map[string]any{"view": view},
@@ -54,13 +54,15 @@ func (vc *ViewComparator) CreateDropChange(key string, view schema.View) Change
 	return Change{
 		Type:        ChangeTypeDropView,
 		Severity:    SeverityBreaking,
-		Description: "Drop view: " + view.QualifiedName(),
+		Description: describeDropped("view", view.QualifiedName()),
 		ObjectType:  "view",
 		ObjectName:  key,
 		Details:     map[string]any{"view": view},
 	}
 }
 
+// CreateModifyChange returns the change replacing a view whose query
+// differs, or an empty change when only its options or comment do.
 func (vc *ViewComparator) CreateModifyChange(key string, current, desired schema.View) Change {
 	defEqual := vc.normalizer.normalizeDefinition(
 		current.Definition,
@@ -73,21 +75,82 @@ func (vc *ViewComparator) CreateModifyChange(key string, current, desired schema
 		desired.CheckOption,
 	)
 
-	if !defEqual || !checkOptEqual {
+	if !defEqual {
+		viewDiff := DiffViewStructure(current.Definition, desired.Definition)
+		viewDiff.CheckOptionChanged = !checkOptEqual
+
 		return Change{
 			Type:        ChangeTypeModifyView,
 			Severity:    SeverityPotentiallyBreaking,
-			Description: "Modify view: " + desired.QualifiedName(),
+			Description: describeReplaced("view", desired.QualifiedName()),
 			ObjectType:  "view",
 			ObjectName:  key,
-			Details:     map[string]any{"current": current, "desired": desired},
-			DependsOn:   extractViewDependencies(desired.Definition),
+			Details: map[string]any{
+				"current":         current,
+				"desired":         desired,
+				DetailKeyViewDiff: viewDiff,
+			},
+			DependsOn: extractViewDependencies(desired.Definition),
 		}
 	}
 
 	return Change{}
 }
 
+// CreateOptionsChange returns the change setting the options of a view whose
+// query is unchanged, or an empty change when they are equal. Tightening or
+// loosening the check option changes which writes through the view succeed.
+func (vc *ViewComparator) CreateOptionsChange(key string, current, desired schema.View) Change {
+	from := viewOptions(&current)
+	to := viewOptions(&desired)
+
+	if maps.Equal(from, to) {
+		return Change{}
+	}
+
+	severity := SeveritySafe
+	if from["check_option"] != to["check_option"] {
+		severity = SeverityPotentiallyBreaking
+	}
+
+	return Change{
+		Type:     ChangeTypeModifyView,
+		Severity: severity,
+		Description: describeValue("View options", desired.QualifiedName(),
+			formatStorageParams(from), formatStorageParams(to)),
+		ObjectType: "view",
+		ObjectName: key,
+		Details: map[string]any{
+			"view":             desired,
+			"old_view_options": from,
+			"new_view_options": to,
+		},
+	}
+}
+
+// viewOptions returns the options of view as ALTER VIEW ... SET takes them,
+// check_option included, with boolean values spelled true or false.
+func viewOptions(view *schema.View) map[string]string {
+	options := make(map[string]string, len(view.Options)+1)
+
+	for key, value := range normalizeStorageParams(view.Options) {
+		switch strings.ToLower(value) {
+		case "true", "on", "yes", "1":
+			value = "true"
+		case "false", "off", "no", "0":
+			value = "false"
+		}
+
+		options[key] = value
+	}
+
+	if checkOption := normalizeCheckOption(view.CheckOption); checkOption != "" {
+		options["check_option"] = checkOption
+	}
+
+	return options
+}
+
 func (vc *ViewComparator) CreateCommentChange(
 	key string,
 	view schema.View,
@@ -96,7 +159,7 @@ func (vc *ViewComparator) CreateCommentChange(
 	return Change{
 		Type:        ChangeTypeModifyView,
 		Severity:    SeveritySafe,
-		Description: "Modify view comment: " + view.QualifiedName(),
+		Description: describeComment("view", view.QualifiedName(), oldComment, newComment),
 		ObjectType:  "view",
 		ObjectName:  key,
 		Details: map[string]any{
@@ -8,7 +8,8 @@ import (
 )
 
 var viewDependencyPattern = regexp.MustCompile(
-	`(?is)\b(?:from|join)\s+(?:lateral\s+)?(?:only\s+)?((?:"[^"]+"|[a-z0-9_]+)(?:\.(?:"[^"]+"|[a-z0-9_]+))?)`,
+	`(?is)\b(?:from|join)\s+(?:lateral\s+)?(?:only\s+)?(?:rows\s+from\s*\(\s*)?` +
+		`((?:"[^"]+"|[a-z0-9_]+)(?:\.(?:"[^"]+"|[a-z0-9_]+))?)`,
 )
 
 func (d *Differ) compareViews(result *DiffResult) {
@@ -17,45 +18,60 @@ func (d *Differ) compareViews(result *DiffResult) {
 
 	for key, view := range desiredViews {
 		if _, exists := currentViews[key]; !exists {
-			change := d.viewComp.CreateAddChange(key, *view)
-			result.Changes = append(result.Changes, change)
-
-			if !d.options.IgnoreComments && view.Comment != "" {
-				result.Changes = append(
-					result.Changes,
-					d.viewComp.CreateCommentChange(key, *view, "", view.Comment),
-				)
-			}
+			d.compareView(result, key, nil, view)
 		}
 	}
 
 	for key, view := range currentViews {
 		if _, exists := desiredViews[key]; !exists {
-			result.Changes = append(result.Changes, d.viewComp.CreateDropChange(key, *view))
+			d.compareView(result, key, view, nil)
 		}
 	}
 
 	for key, desiredView := range desiredViews {
 		if currentView, exists := currentViews[key]; exists {
-			if !d.viewComp.AreEqual(*currentView, *desiredView) {
-				change := d.viewComp.CreateModifyChange(key, *currentView, *desiredView)
-				if change.Type != "" {
-					result.Changes = append(result.Changes, change)
-				}
+			d.options.Cache.compare(result, "view", key, currentView, desiredView, func() {
+				d.compareView(result, key, currentView, desiredView)
+			})
+		}
+	}
+}
+
+// compareView appends the changes that turn current into desired. A nil
+// current adds the view and a nil desired drops it.
+func (d *Differ) compareView(result *DiffResult, key string, current, desired *schema.View) {
+	switch {
+	case current == nil && desired == nil:
+		return
+	case current == nil:
+		result.Changes = append(result.Changes, d.viewComp.CreateAddChange(key, *desired))
+
+		if !d.options.IgnoreComments && desired.Comment != "" {
+			result.Changes = append(
+				result.Changes,
+				d.viewComp.CreateCommentChange(key, *desired, "", desired.Comment),
+			)
+		}
+	case desired == nil:
+		result.Changes = append(result.Changes, d.viewComp.CreateDropChange(key, *current))
+	default:
+		if !d.viewComp.AreEqual(*current, *desired) {
+			change := d.viewComp.CreateModifyChange(key, *current, *desired)
+			if change.Type == "" {
+				change = d.viewComp.CreateOptionsChange(key, *current, *desired)
 			}
 
-			if !d.options.IgnoreComments && currentView.Comment != desiredView.Comment {
-				result.Changes = append(
-					result.Changes,
-					d.viewComp.CreateCommentChange(
-						key,
-						*desiredView,
-						currentView.Comment,
-						desiredView.Comment,
-					),
-				)
+			if change.Type != "" {
+				result.Changes = append(result.Changes, change)
 			}
 		}
+
+		if !d.options.IgnoreComments && !d.options.commentsEqual(current.Comment, desired.Comment) {
+			result.Changes = append(
+				result.Changes,
+				d.viewComp.CreateCommentChange(key, *desired, current.Comment, desired.Comment),
+			)
+		}
 	}
 }
 
@@ -84,83 +100,115 @@ func (d *Differ) processMaterializedViewChanges(
 ) {
 	for key, view := range desiredViews {
 		if _, exists := currentViews[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeAddMaterializedView,
-				Severity:    SeveritySafe,
-				Description: "Add materialized view: " + view.QualifiedName(),
-				ObjectType:  "materialized_view",
-				ObjectName:  key,
-				Details:     map[string]any{"view": view},
-				DependsOn:   extractViewDependencies(view.Definition),
-			})
-
-			if !d.options.IgnoreComments && view.Comment != "" {
-				result.Changes = append(result.Changes, Change{
-					Type:        ChangeTypeModifyMaterializedView,
-					Severity:    SeveritySafe,
-					Description: "Add materialized view comment: " + view.QualifiedName(),
-					ObjectType:  "materialized_view",
-					ObjectName:  key,
-					Details: map[string]any{
-						"view":        view,
-						"old_comment": "",
-						"new_comment": view.Comment,
-					},
-				})
-			}
+			d.compareMaterializedView(result, key, nil, view)
 		}
 	}
 
 	for key, view := range currentViews {
 		if _, exists := desiredViews[key]; !exists {
-			result.Changes = append(result.Changes, Change{
-				Type:        ChangeTypeDropMaterializedView,
-				Severity:    SeverityBreaking,
-				Description: "Drop materialized view: " + view.QualifiedName(),
-				ObjectType:  "materialized_view",
-				ObjectName:  key,
-				Details:     map[string]any{"view": view},
-			})
+			d.compareMaterializedView(result, key, view, nil)
 		}
 	}
 
 	for key, desiredView := range desiredViews {
 		if currentView, exists := currentViews[key]; exists {
-			defEqual := NormalizeViewDefinition(
-				currentView.Definition,
-			) == NormalizeViewDefinition(
-				desiredView.Definition,
-			)
-			currentComment := normalizeComment(currentView.Comment)
-			desiredComment := normalizeComment(desiredView.Comment)
-			commentEqual := currentComment == desiredComment
-
-			if !defEqual {
-				result.Changes = append(result.Changes, Change{
-					Type:        ChangeTypeModifyMaterializedView,
-					Severity:    SeverityPotentiallyBreaking,
-					Description: "Modify materialized view: " + desiredView.QualifiedName(),
-					ObjectType:  "materialized_view",
-					ObjectName:  key,
-					Details:     map[string]any{"current": currentView, "desired": desiredView},
-					DependsOn:   extractViewDependencies(desiredView.Definition),
+			d.options.Cache.compare(result, "materialized_view", key, currentView, desiredView,
+				func() {
+					d.compareMaterializedView(result, key, currentView, desiredView)
 				})
-			}
+		}
+	}
+}
 
-			if !d.options.IgnoreComments && !commentEqual {
-				result.Changes = append(result.Changes, Change{
-					Type:        ChangeTypeModifyMaterializedView,
-					Severity:    SeveritySafe,
-					Description: "Modify materialized view comment: " + desiredView.QualifiedName(),
-					ObjectType:  "materialized_view",
-					ObjectName:  key,
-					Details: map[string]any{
-						"view":        desiredView,
-						"old_comment": currentView.Comment,
-						"new_comment": desiredView.Comment,
-					},
-				})
-			}
+// compareMaterializedView appends the changes that turn current into
+// desired. A nil current adds the view and a nil desired drops it.
+func (d *Differ) compareMaterializedView(
+	result *DiffResult,
+	key string,
+	current, desired *schema.MaterializedView,
+) {
+	switch {
+	case current == nil && desired == nil:
+		return
+	case current == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeAddMaterializedView,
+			Severity:    SeveritySafe,
+			Description: describeAdded("materialized view", desired.QualifiedName()),
+			ObjectType:  "materialized_view",
+			ObjectName:  key,
+			Details:     map[string]any{"view": desired},
+			DependsOn:   extractViewDependencies(desired.Definition),
+		})
+
+		if !d.options.IgnoreComments && desired.Comment != "" {
+			result.Changes = append(result.Changes, Change{
+				Type:     ChangeTypeModifyMaterializedView,
+				Severity: SeveritySafe,
+				Description: describeComment("materialized view", desired.QualifiedName(),
+					"", desired.Comment),
+				ObjectType: "materialized_view",
+				ObjectName: key,
+				Details: map[string]any{
+					"view":        desired,
+					"old_comment": "",
+					"new_comment": desired.Comment,
+				},
+			})
+		}
+	case desired == nil:
+		result.Changes = append(result.Changes, Change{
+			Type:        ChangeTypeDropMaterializedView,
+			Severity:    SeverityBreaking,
+			Description: describeDropped("materialized view", current.QualifiedName()),
+			ObjectType:  "materialized_view",
+			ObjectName:  key,
+			Details:     map[string]any{"view": current},
+		})
+	default:
+		if !AreViewDefinitionsEqual(current.Definition, desired.Definition) {
+			result.Changes = append(result.Changes, Change{
+				Type:        ChangeTypeModifyMaterializedView,
+				Severity:    SeverityPotentiallyBreaking,
+				Description: describeReplaced("materialized view", desired.QualifiedName()),
+				ObjectType:  "materialized_view",
+				ObjectName:  key,
+				Details:     map[string]any{"current": current, "desired": desired},
+				DependsOn:   extractViewDependencies(desired.Definition),
+			})
+		} else if !equalStorageParams(current.StorageParams, desired.StorageParams) {
+			// A redefined view is created again with the desired parameters.
+			name := desired.QualifiedName()
+			result.Changes = append(result.Changes, Change{
+				Type:     ChangeTypeModifyMaterializedView,
+				Severity: SeveritySafe,
+				Description: describeValue("Materialized view storage parameters", name,
+					formatStorageParams(current.StorageParams),
+					formatStorageParams(desired.StorageParams)),
+				ObjectType: "materialized_view",
+				ObjectName: key,
+				Details: map[string]any{
+					"view":               desired,
+					"old_storage_params": normalizeStorageParams(current.StorageParams),
+					"new_storage_params": normalizeStorageParams(desired.StorageParams),
+				},
+			})
+		}
+
+		if !d.options.IgnoreComments && !d.options.commentsEqual(current.Comment, desired.Comment) {
+			result.Changes = append(result.Changes, Change{
+				Type:     ChangeTypeModifyMaterializedView,
+				Severity: SeveritySafe,
+				Description: describeComment("materialized view", desired.QualifiedName(),
+					current.Comment, desired.Comment),
+				ObjectType: "materialized_view",
+				ObjectName: key,
+				Details: map[string]any{
+					"view":        desired,
+					"old_comment": current.Comment,
+					"new_comment": desired.Comment,
+				},
+			})
 		}
 	}
 }
@@ -175,8 +223,13 @@ func buildMaterializedViewMap(views []schema.MaterializedView) map[string]*schem
 	return m
 }
 
+// extractViewDependencies returns the relations a query reads from. Names
+// inside string literals, such as the query a dblink call sends to another
+// server, are not references, and neither are functions called in FROM.
 func extractViewDependencies(definition string) []string {
-	matches := viewDependencyPattern.FindAllStringSubmatch(definition, -1)
+	definition = schema.MaskStringLiterals(definition)
+
+	matches := viewDependencyPattern.FindAllStringSubmatchIndex(definition, -1)
 	if len(matches) == 0 {
 		return nil
 	}
@@ -185,11 +238,11 @@ func extractViewDependencies(definition string) []string {
 	seen := make(map[string]struct{})
 
 	for _, match := range matches {
-		if len(match) < 2 {
+		if len(match) < 4 || callsFunction(definition[match[3]:]) {
 			continue
 		}
 
-		table := normalizeDependencyIdentifier(match[1])
+		table := normalizeDependencyIdentifier(definition[match[2]:match[3]])
 		if table == "" || isReservedWord(table) {
 			continue
 		}
@@ -205,6 +258,12 @@ func extractViewDependencies(definition string) []string {
 	return deps
 }
 
+// callsFunction reports whether the SQL following a name opens an argument
+// list, making the name a function rather than a relation.
+func callsFunction(rest string) bool {
+	return strings.HasPrefix(strings.TrimLeft(rest, " \t\r\n"), "(")
+}
+
 func normalizeDependencyIdentifier(identifier string) string {
 	trimmed := strings.TrimSpace(identifier)
 
@@ -219,9 +278,7 @@ func normalizeDependencyIdentifier(identifier string) string {
 	}
 
 	for i := range parts {
-		part := strings.TrimSpace(parts[i])
-
-		part = strings.Trim(part, `"`)
+		part := schema.FoldIdentifier(parts[i])
 		if part == "" {
 			return ""
 		}
@@ -229,7 +286,7 @@ func normalizeDependencyIdentifier(identifier string) string {
 		parts[i] = part
 	}
 
-	return strings.ToLower(strings.Join(parts, "."))
+	return strings.Join(parts, ".")
 }
 
 func splitIdentifierParts(identifier string) []string {
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 type viewNormalizer struct {
@@ -256,7 +257,7 @@ func (n *sqlNormalizer) parseSelectStatement() map[string]any {
 			break
 		}
 
-		if n.matchKeyword("WITH") {
+		if n.matchWord("WITH") {
 			n.advance()
 			ctes := n.parseWithClause()
 			stmt["with"] = ctes
@@ -546,9 +547,24 @@ func (n *sqlNormalizer) parseFromClause() ([]map[string]any, string) { //nolint:
 			continue
 		}
 
+		// LATERAL only lets the item refer to the items before it.
+		if n.current().Type == parser.TokenIdentifier && tokenLiteralEqual(n.current(), "LATERAL") {
+			n.advance()
+			continue
+		}
+
+		if n.current().Type == parser.TokenIdentifier && tokenLiteralEqual(n.current(), "ROWS") &&
+			tokenLiteralEqual(n.peek(1), "FROM") {
+			n.advance()
+			n.advance()
+			tables = append(tables, n.parseFromFunction("rows from"))
+
+			continue
+		}
+
 		if n.current().Type == parser.TokenIdentifier || //nolint:nestif
 			n.current().Type == parser.TokenQuotedIdentifier {
-			tableName := strings.ToLower(n.current().Literal)
+			tableName := n.normalizeTokenLiteral(n.current())
 			n.advance()
 
 			if n.current().Type == parser.TokenDot {
@@ -556,28 +572,16 @@ func (n *sqlNormalizer) parseFromClause() ([]map[string]any, string) { //nolint:
 
 				if n.current().Type == parser.TokenIdentifier ||
 					n.current().Type == parser.TokenQuotedIdentifier {
-					tableName = tableName + "." + strings.ToLower(n.current().Literal)
+					tableName = tableName + "." + n.normalizeTokenLiteral(n.current())
 					n.advance()
 				}
 			}
 
-			tableAlias := ""
-
-			if n.matchKeyword("AS") {
-				n.advance()
-			}
-
-			if n.current().Type == parser.TokenIdentifier ||
-				n.current().Type == parser.TokenQuotedIdentifier {
-				if !n.matchKeyword("WHERE") && !n.matchKeyword("GROUP") &&
-					!n.matchKeyword("ORDER") && !n.matchKeyword("HAVING") &&
-					!n.matchKeyword("LEFT") && !n.matchKeyword("RIGHT") &&
-					!n.matchKeyword("INNER") && !n.matchKeyword("CROSS") &&
-					!n.matchKeyword("JOIN") && !n.matchKeyword("LIMIT") &&
-					!n.matchKeyword("OFFSET") {
-					tableAlias = strings.ToLower(n.current().Literal)
-					n.advance()
-				}
+			// A name followed by an argument list calls a set-returning
+			// function, which is not a relation the view reads.
+			if n.current().Type == parser.TokenLParen {
+				tables = append(tables, n.parseFromFunction(tableName))
+				continue
 			}
 
 			if primaryTable == "" {
@@ -585,9 +589,7 @@ func (n *sqlNormalizer) parseFromClause() ([]map[string]any, string) { //nolint:
 			}
 
 			table := map[string]any{"name": tableName}
-			if tableAlias != "" {
-				table["alias"] = tableAlias
-			}
+			n.parseFromAlias(table)
 
 			tables = append(tables, table)
 
@@ -605,6 +607,106 @@ func (n *sqlNormalizer) parseFromClause() ([]map[string]any, string) { //nolint:
 	return tables, primaryTable
 }
 
+// parseFromFunction reads a function call in FROM, or the list of ROWS
+// FROM, starting at the opening parenthesis of its arguments, followed by
+// WITH ORDINALITY and an alias with its column list.
+func (n *sqlNormalizer) parseFromFunction(name string) map[string]any {
+	item := map[string]any{"function": name}
+
+	if n.current().Type == parser.TokenLParen {
+		n.advance()
+
+		item["args"] = n.parseExpression(false)
+
+		// Skip whatever the expression stopped short of, up to the closing
+		// parenthesis of the arguments.
+		for depth := 0; n.current().Type != parser.TokenEOF; n.advance() {
+			if n.current().Type == parser.TokenLParen {
+				depth++
+			} else if n.current().Type == parser.TokenRParen {
+				if depth == 0 {
+					n.advance()
+					break
+				}
+
+				depth--
+			}
+		}
+	}
+
+	if n.matchWord("WITH") && tokenLiteralEqual(n.peek(1), "ORDINALITY") {
+		n.advance()
+		n.advance()
+
+		item["ordinality"] = true
+	}
+
+	n.parseFromAlias(item)
+
+	return item
+}
+
+// parseFromAlias records the alias of a FROM item and the column names of
+// its alias list, as in "AS t(item, idx)".
+func (n *sqlNormalizer) parseFromAlias(item map[string]any) {
+	if n.matchKeyword("AS") {
+		n.advance()
+	}
+
+	if n.current().Type != parser.TokenIdentifier &&
+		n.current().Type != parser.TokenQuotedIdentifier {
+		return
+	}
+
+	if n.matchKeyword("WHERE") || n.matchKeyword("GROUP") ||
+		n.matchKeyword("ORDER") || n.matchKeyword("HAVING") ||
+		n.matchKeyword("LEFT") || n.matchKeyword("RIGHT") ||
+		n.matchKeyword("INNER") || n.matchKeyword("CROSS") ||
+		n.matchKeyword("JOIN") || n.matchKeyword("LIMIT") ||
+		n.matchKeyword("OFFSET") {
+		return
+	}
+
+	item["alias"] = strings.ToLower(n.current().Literal)
+	n.advance()
+
+	if n.current().Type != parser.TokenLParen {
+		return
+	}
+
+	n.advance()
+
+	var columns []string
+
+	// A column definition list, as for a function returning record, gives
+	// each column a type; only the names are kept.
+	expectName := true
+
+	for depth := 0; n.current().Type != parser.TokenEOF; n.advance() {
+		tok := n.current()
+
+		switch {
+		case tok.Type == parser.TokenLParen:
+			depth++
+		case tok.Type == parser.TokenRParen && depth == 0:
+			n.advance()
+
+			item["columns"] = columns
+
+			return
+		case tok.Type == parser.TokenRParen:
+			depth--
+		case tok.Type == parser.TokenComma && depth == 0:
+			expectName = true
+		case expectName:
+			columns = append(columns, n.normalizeTokenLiteral(tok))
+			expectName = false
+		}
+	}
+
+	item["columns"] = columns
+}
+
 func (n *sqlNormalizer) parseExpression(stopAtComma bool) map[string]any {
 	expr := make(map[string]any)
 
@@ -720,7 +822,7 @@ func (n *sqlNormalizer) normalizeTokenLiteral(tok parser.Token) string {
 	case parser.TokenIdentifier, parser.TokenKeyword:
 		return strings.ToLower(tok.Literal)
 	case parser.TokenQuotedIdentifier:
-		return strings.ToLower(strings.Trim(tok.Literal, `"`))
+		return schema.FoldIdentifier(tok.Literal)
 	default:
 		return tok.Literal
 	}
@@ -1171,6 +1273,15 @@ func (n *sqlNormalizer) current() parser.Token {
 	return n.tokens[n.pos]
 }
 
+// peek returns the token offset places after the current one.
+func (n *sqlNormalizer) peek(offset int) parser.Token {
+	if n.pos+offset >= len(n.tokens) {
+		return parser.Token{Type: parser.TokenEOF}
+	}
+
+	return n.tokens[n.pos+offset]
+}
+
 func (n *sqlNormalizer) advance() {
 	if n.pos < len(n.tokens) {
 		n.pos++
@@ -1185,3 +1296,14 @@ func (n *sqlNormalizer) matchKeyword(keyword string) bool {
 
 	return strings.EqualFold(tok.Literal, keyword)
 }
+
+// matchWord is matchKeyword for words such as WITH that the lexer does not
+// treat as keywords and so tokenizes as identifiers.
+func (n *sqlNormalizer) matchWord(word string) bool {
+	tok := n.current()
+	if tok.Type != parser.TokenKeyword && tok.Type != parser.TokenIdentifier {
+		return false
+	}
+
+	return strings.EqualFold(tok.Literal, word)
+}
@@ -6,34 +6,150 @@ import (
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
+// viewRecreationCause is why views are dropped and recreated around a change
+// to an object they select from.
+type viewRecreationCause struct {
+	// reason completes descriptions such as "Drop view for column type change".
+	reason string
+	// detailKey is set in the details of the drop and recreate changes.
+	detailKey string
+}
+
+//nolint:gochecknoglobals
+var (
+	causeColumnTypeChange = viewRecreationCause{
+		reason:    "column type change",
+		detailKey: "for_type_change",
+	}
+	causeContinuousAggregateRecreation = viewRecreationCause{
+		reason:    "continuous aggregate recreation",
+		detailKey: "for_continuous_aggregate",
+	}
+	causeMaterializedViewRecreation = viewRecreationCause{
+		reason:    "materialized view recreation",
+		detailKey: "for_materialized_view",
+	}
+	causeViewColumnListChange = viewRecreationCause{
+		reason:    "column list change",
+		detailKey: "for_column_list_change",
+	}
+)
+
 func (d *Differ) processViewRecreationForColumnTypeChanges(result *DiffResult) {
-	tablesWithTypeChanges := d.findTablesWithColumnTypeChanges(result.Changes)
-	if len(tablesWithTypeChanges) == 0 {
+	typeChanges := columnChangesByTable(result.Changes, ChangeTypeModifyColumnType)
+	if len(typeChanges) == 0 {
+		return
+	}
+
+	// Only the views reading a changed column block ALTER COLUMN TYPE. Views
+	// are dropped without CASCADE, so views over the recreated views are
+	// recreated with them.
+	affected := viewsUsingColumns(result.Current, typeChanges)
+	if len(affected) == 0 {
 		return
 	}
 
-	d.processViewsForTypeChanges(result, tablesWithTypeChanges)
-	d.processMaterializedViewsForTypeChanges(result, tablesWithTypeChanges)
+	addDependentViews(result.Desired, affected)
+
+	recreates := func(key, _ string) bool { return affected[key] }
+
+	d.processViewsForTypeChanges(result, recreates, causeColumnTypeChange)
+	d.processMaterializedViewsForTypeChanges(result, recreates, causeColumnTypeChange)
 }
 
-func (d *Differ) findTablesWithColumnTypeChanges(changes []Change) map[string]bool {
-	tables := make(map[string]bool)
+// processViewRecreationForColumnListChanges drops and recreates the views
+// whose new query removes, renames or reorders output columns, which CREATE OR
+// REPLACE VIEW refuses, together with the views selecting from them.
+func (d *Differ) processViewRecreationForColumnListChanges(result *DiffResult) {
+	recreated := make(map[string]bool)
+
+	for _, change := range result.Changes {
+		if change.Type != ChangeTypeModifyView {
+			continue
+		}
+
+		current, hasCurrent := change.Details["current"].(schema.View)
+		desired, hasDesired := change.Details["desired"].(schema.View)
+
+		if hasCurrent && hasDesired && !ViewColumnsExtend(current.Definition, desired.Definition) {
+			recreated[change.ObjectName] = true
+		}
+	}
+
+	if len(recreated) == 0 {
+		return
+	}
 
-	for _, change := range changes {
-		if change.Type == ChangeTypeModifyColumnType {
-			tableName, _ := change.Details["table"].(string)
-			if tableName != "" {
-				tables[strings.ToLower(tableName)] = true
+	addDependentViews(result.Desired, recreated)
+
+	recreates := func(key, _ string) bool { return recreated[key] }
+
+	d.processViewsForTypeChanges(result, recreates, causeViewColumnListChange)
+	d.processMaterializedViewsForTypeChanges(result, recreates, causeViewColumnListChange)
+}
+
+// viewsUsingColumns returns the keys of the current views and materialized
+// views whose query reads one of the changed columns.
+func viewsUsingColumns(db *schema.Database, columnChanges map[string][]columnRef) map[string]bool {
+	views := make(map[string]bool)
+
+	uses := func(definition string) bool {
+		for _, dep := range extractViewDependencies(definition) {
+			if queryUsesAnyColumn(definition, tableColumnChanges(dep, columnChanges)) {
+				return true
 			}
 		}
+
+		return false
+	}
+
+	for i := range db.Views {
+		if uses(db.Views[i].Definition) {
+			views[ViewKey(db.Views[i].Schema, db.Views[i].Name)] = true
+		}
+	}
+
+	for i := range db.MaterializedViews {
+		if uses(db.MaterializedViews[i].Definition) {
+			views[ViewKey(db.MaterializedViews[i].Schema, db.MaterializedViews[i].Name)] = true
+		}
 	}
 
-	return tables
+	return views
 }
 
+// processViewRecreationForMaterializedViews drops and recreates the views and
+// materialized views that select from a materialized view whose query
+// changes, directly or through other views. The materialized view is dropped
+// and created again, which fails while they exist.
+func (d *Differ) processViewRecreationForMaterializedViews(result *DiffResult) {
+	recreated := make(map[string]bool)
+
+	for _, change := range result.Changes {
+		if _, redefines := change.Details["current"]; redefines &&
+			change.Type == ChangeTypeModifyMaterializedView {
+			recreated[change.ObjectName] = true
+		}
+	}
+
+	if len(recreated) == 0 {
+		return
+	}
+
+	addDependentViews(result.Desired, recreated)
+
+	d.processViewsForTypeChanges(result, selectsFromAny(recreated),
+		causeMaterializedViewRecreation)
+	d.processMaterializedViewsForTypeChanges(result, selectsFromAny(recreated),
+		causeMaterializedViewRecreation)
+}
+
+// processViewsForTypeChanges drops and recreates the views for which
+// recreates, given the key and desired definition, is true.
 func (d *Differ) processViewsForTypeChanges(
 	result *DiffResult,
-	tablesWithTypeChanges map[string]bool,
+	recreates func(key, definition string) bool,
+	cause viewRecreationCause,
 ) {
 	currentViews := buildViewMap(result.Current.Views)
 	desiredViews := buildViewMap(result.Desired.Views)
@@ -47,18 +163,17 @@ func (d *Differ) processViewsForTypeChanges(
 	}
 
 	for key, desiredView := range desiredViews {
-		deps := extractViewDependencies(desiredView.Definition)
-		if !viewDependsOnAnyTable(deps, tablesWithTypeChanges) {
+		if !recreates(key, desiredView.Definition) {
 			continue
 		}
 
 		if idx, hasChange := viewsWithChanges[key]; hasChange {
 			if result.Changes[idx].Type == ChangeTypeModifyView {
-				d.convertModifyViewToDropAdd(result, idx, key, desiredView)
+				d.convertModifyViewToDropAdd(result, idx, key, desiredView, cause)
 			}
 		} else {
 			if currentView, exists := currentViews[key]; exists {
-				d.addViewRecreationChanges(result, key, currentView, desiredView)
+				d.addViewRecreationChanges(result, key, currentView, desiredView, cause)
 			}
 		}
 	}
@@ -66,7 +181,8 @@ func (d *Differ) processViewsForTypeChanges(
 
 func (d *Differ) processMaterializedViewsForTypeChanges(
 	result *DiffResult,
-	tablesWithTypeChanges map[string]bool,
+	recreates func(key, definition string) bool,
+	cause viewRecreationCause,
 ) {
 	currentViews := buildMaterializedViewMap(result.Current.MaterializedViews)
 	desiredViews := buildMaterializedViewMap(result.Desired.MaterializedViews)
@@ -81,23 +197,32 @@ func (d *Differ) processMaterializedViewsForTypeChanges(
 	}
 
 	for key, desiredView := range desiredViews {
-		deps := extractViewDependencies(desiredView.Definition)
-		if !viewDependsOnAnyTable(deps, tablesWithTypeChanges) {
+		if !recreates(key, desiredView.Definition) {
 			continue
 		}
 
 		if idx, hasChange := viewsWithChanges[key]; hasChange {
 			if result.Changes[idx].Type == ChangeTypeModifyMaterializedView {
-				d.convertModifyMaterializedViewToDropAdd(result, idx, key, desiredView)
+				d.convertModifyMaterializedViewToDropAdd(result, idx, key, desiredView, cause)
 			}
 		} else {
 			if currentView, exists := currentViews[key]; exists {
-				d.addMaterializedViewRecreationChanges(result, key, currentView, desiredView)
+				d.addMaterializedViewRecreationChanges(
+					result, key, currentView, desiredView, cause,
+				)
 			}
 		}
 	}
 }
 
+// selectsFromAny returns a predicate for the views that select from one of
+// the objects.
+func selectsFromAny(objects map[string]bool) func(key, definition string) bool {
+	return func(_, definition string) bool {
+		return viewDependsOnAnyTable(extractViewDependencies(definition), objects)
+	}
+}
+
 func viewDependsOnAnyTable(deps []string, tables map[string]bool) bool {
 	for _, dep := range deps {
 		if depMatchesTables(dep, tables) {
@@ -109,13 +234,11 @@ func viewDependsOnAnyTable(deps []string, tables map[string]bool) bool {
 }
 
 func depMatchesTables(dep string, tables map[string]bool) bool {
-	depLower := strings.ToLower(dep)
-
-	if tables[depLower] {
+	if tables[dep] {
 		return true
 	}
 
-	parts := strings.Split(depLower, ".")
+	parts := strings.Split(dep, ".")
 
 	if len(parts) == 2 && (parts[0] == schema.DefaultSchema || parts[0] == "public") {
 		if tables[parts[1]] {
@@ -124,7 +247,7 @@ func depMatchesTables(dep string, tables map[string]bool) bool {
 	}
 
 	if len(parts) == 1 {
-		if tables["public."+depLower] || tables[schema.DefaultSchema+"."+depLower] {
+		if tables["public."+dep] || tables[schema.DefaultSchema+"."+dep] {
 			return true
 		}
 	}
@@ -137,6 +260,7 @@ func (d *Differ) convertModifyViewToDropAdd(
 	idx int,
 	key string,
 	desiredView *schema.View,
+	cause viewRecreationCause,
 ) {
 	originalChange := result.Changes[idx]
 
@@ -158,49 +282,62 @@ func (d *Differ) convertModifyViewToDropAdd(
 
 	deps := extractViewDependencies(currentView.Definition)
 	result.Changes[idx] = Change{
-		Type:        ChangeTypeDropView,
-		Severity:    SeverityPotentiallyBreaking,
-		Description: "Drop view for recreation: " + desiredView.QualifiedName(),
-		ObjectType:  "view",
-		ObjectName:  key,
+		Type:     ChangeTypeDropView,
+		Severity: SeverityPotentiallyBreaking,
+		Description: describe(descDropForRecreation, "View", desiredView.QualifiedName(),
+			cause.reason),
+		ObjectType: "view",
+		ObjectName: key,
 		Details: map[string]any{
 			"view":              currentView,
-			"for_type_change":   true,
+			cause.detailKey:     true,
 			"original_change":   originalChange,
 			"will_be_recreated": true,
 		},
 		DependsOn: deps,
 	}
 
-	result.Changes = append(result.Changes, Change{
+	add := Change{
 		Type:        ChangeTypeAddView,
 		Severity:    SeveritySafe,
-		Description: "Recreate view: " + desiredView.QualifiedName(),
+		Description: describe(descRecreated, "View", desiredView.QualifiedName(), cause.reason),
 		ObjectType:  "view",
 		ObjectName:  key,
 		Details: map[string]any{
-			"view":            *desiredView,
-			"for_type_change": true,
-			"is_recreation":   true,
+			"view":          *desiredView,
+			cause.detailKey: true,
+			"is_recreation": true,
 		},
 		DependsOn: extractViewDependencies(desiredView.Definition),
-	})
+	}
+
+	// The recreated view reports how its query changed, as the replacement
+	// would have.
+	if viewDiff, ok := originalChange.Details[DetailKeyViewDiff]; ok {
+		add.Details[DetailKeyViewDiff] = viewDiff
+	}
+
+	result.Changes = append(result.Changes, add)
+
+	d.recreateViewTriggers(result, key, cause)
 }
 
 func (d *Differ) addViewRecreationChanges(
 	result *DiffResult,
 	key string,
 	currentView, desiredView *schema.View,
+	cause viewRecreationCause,
 ) {
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeDropView,
-		Severity:    SeverityPotentiallyBreaking,
-		Description: "Drop view for column type change: " + currentView.QualifiedName(),
-		ObjectType:  "view",
-		ObjectName:  key,
+		Type:     ChangeTypeDropView,
+		Severity: SeverityPotentiallyBreaking,
+		Description: describe(descDropForRecreation, "View", currentView.QualifiedName(),
+			cause.reason),
+		ObjectType: "view",
+		ObjectName: key,
 		Details: map[string]any{
 			"view":              *currentView,
-			"for_type_change":   true,
+			cause.detailKey:     true,
 			"will_be_recreated": true,
 		},
 		DependsOn: extractViewDependencies(currentView.Definition),
@@ -209,16 +346,97 @@ func (d *Differ) addViewRecreationChanges(
 	result.Changes = append(result.Changes, Change{
 		Type:        ChangeTypeAddView,
 		Severity:    SeveritySafe,
-		Description: "Recreate view after column type change: " + desiredView.QualifiedName(),
+		Description: describe(descRecreated, "View", desiredView.QualifiedName(), cause.reason),
 		ObjectType:  "view",
 		ObjectName:  key,
 		Details: map[string]any{
-			"view":            *desiredView,
-			"for_type_change": true,
-			"is_recreation":   true,
+			"view":          *desiredView,
+			cause.detailKey: true,
+			"is_recreation": true,
 		},
 		DependsOn: extractViewDependencies(desiredView.Definition),
 	})
+
+	d.recreateViewTriggers(result, key, cause)
+}
+
+// recreateViewTriggers keeps the triggers on a view that is dropped and
+// recreated, typically the INSTEAD OF triggers that make it updatable.
+// Dropping the view removes them, so each trigger the view keeps is dropped
+// before the view and created again after it. A modification of such a
+// trigger becomes that drop and create, since the view it would alter in place
+// no longer exists. Triggers that are only added or only dropped keep their
+// own changes.
+func (d *Differ) recreateViewTriggers(result *DiffResult, key string, cause viewRecreationCause) {
+	desiredTriggers := buildTriggerMap(triggersOnView(result.Desired.Triggers, key))
+
+	modified := make(map[string]int)
+
+	for i, change := range result.Changes {
+		if change.Type == ChangeTypeModifyTrigger {
+			modified[change.ObjectName] = i
+		}
+	}
+
+	for _, current := range triggersOnView(result.Current.Triggers, key) {
+		name := triggerKey(&current)
+
+		desired, exists := desiredTriggers[name]
+		if !exists {
+			continue
+		}
+
+		drop := Change{
+			Type:     ChangeTypeDropTrigger,
+			Severity: SeverityPotentiallyBreaking,
+			Description: describe(descDropForRecreation, "Trigger",
+				current.Name+" on "+current.QualifiedTableName(), "view recreation"),
+			ObjectType: "trigger",
+			ObjectName: name,
+			Details: map[string]any{
+				"trigger":           &current,
+				cause.detailKey:     true,
+				"will_be_recreated": true,
+			},
+			DependsOn: d.triggerComp.buildTriggerDependencies(&current, result.Current, false),
+		}
+
+		if idx, ok := modified[name]; ok {
+			result.Changes[idx] = drop
+		} else {
+			result.Changes = append(result.Changes, drop)
+		}
+
+		result.Changes = append(result.Changes, Change{
+			Type:     ChangeTypeAddTrigger,
+			Severity: SeveritySafe,
+			Description: describe(descRecreated, "Trigger",
+				desired.Name+" on "+desired.QualifiedTableName(), "view recreation"),
+			ObjectType: "trigger",
+			ObjectName: name,
+			Details: map[string]any{
+				"trigger":       desired,
+				cause.detailKey: true,
+				"is_recreation": true,
+			},
+			DependsOn: d.triggerComp.buildTriggerDependencies(desired, result.Desired, true),
+		})
+	}
+}
+
+// triggersOnView returns the triggers attached to the view with the given
+// key. A trigger names its relation in TableName whether that is a table or a
+// view.
+func triggersOnView(triggers []schema.Trigger, key string) []schema.Trigger {
+	var result []schema.Trigger
+
+	for _, trigger := range triggers {
+		if ViewKey(trigger.Schema, trigger.TableName) == key {
+			result = append(result, trigger)
+		}
+	}
+
+	return result
 }
 
 func (d *Differ) convertModifyMaterializedViewToDropAdd(
@@ -226,6 +444,7 @@ func (d *Differ) convertModifyMaterializedViewToDropAdd(
 	idx int,
 	key string,
 	desiredView *schema.MaterializedView,
+	cause viewRecreationCause,
 ) {
 	originalChange := result.Changes[idx]
 
@@ -250,14 +469,15 @@ func (d *Differ) convertModifyMaterializedViewToDropAdd(
 
 	deps := extractViewDependencies(currentView.Definition)
 	result.Changes[idx] = Change{
-		Type:        ChangeTypeDropMaterializedView,
-		Severity:    SeverityPotentiallyBreaking,
-		Description: "Drop materialized view for recreation: " + desiredView.QualifiedName(),
-		ObjectType:  "materialized_view",
-		ObjectName:  key,
+		Type:     ChangeTypeDropMaterializedView,
+		Severity: SeverityPotentiallyBreaking,
+		Description: describe(descDropForRecreation, "Materialized view",
+			desiredView.QualifiedName(), cause.reason),
+		ObjectType: "materialized_view",
+		ObjectName: key,
 		Details: map[string]any{
 			"view":              currentView,
-			"for_type_change":   true,
+			cause.detailKey:     true,
 			"original_change":   originalChange,
 			"will_be_recreated": true,
 		},
@@ -265,15 +485,16 @@ func (d *Differ) convertModifyMaterializedViewToDropAdd(
 	}
 
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeAddMaterializedView,
-		Severity:    SeveritySafe,
-		Description: "Recreate materialized view: " + desiredView.QualifiedName(),
-		ObjectType:  "materialized_view",
-		ObjectName:  key,
+		Type:     ChangeTypeAddMaterializedView,
+		Severity: SeveritySafe,
+		Description: describe(descRecreated, "Materialized view",
+			desiredView.QualifiedName(), cause.reason),
+		ObjectType: "materialized_view",
+		ObjectName: key,
 		Details: map[string]any{
-			"view":            *desiredView,
-			"for_type_change": true,
-			"is_recreation":   true,
+			"view":          *desiredView,
+			cause.detailKey: true,
+			"is_recreation": true,
 		},
 		DependsOn: extractViewDependencies(desiredView.Definition),
 	})
@@ -283,31 +504,34 @@ func (d *Differ) addMaterializedViewRecreationChanges(
 	result *DiffResult,
 	key string,
 	currentView, desiredView *schema.MaterializedView,
+	cause viewRecreationCause,
 ) {
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeDropMaterializedView,
-		Severity:    SeverityPotentiallyBreaking,
-		Description: "Drop materialized view for column type change: " + currentView.QualifiedName(),
-		ObjectType:  "materialized_view",
-		ObjectName:  key,
+		Type:     ChangeTypeDropMaterializedView,
+		Severity: SeverityPotentiallyBreaking,
+		Description: describe(descDropForRecreation, "Materialized view",
+			currentView.QualifiedName(), cause.reason),
+		ObjectType: "materialized_view",
+		ObjectName: key,
 		Details: map[string]any{
 			"view":              *currentView,
-			"for_type_change":   true,
+			cause.detailKey:     true,
 			"will_be_recreated": true,
 		},
 		DependsOn: extractViewDependencies(currentView.Definition),
 	})
 
 	result.Changes = append(result.Changes, Change{
-		Type:        ChangeTypeAddMaterializedView,
-		Severity:    SeveritySafe,
-		Description: "Recreate materialized view after column type change: " + desiredView.QualifiedName(),
-		ObjectType:  "materialized_view",
-		ObjectName:  key,
+		Type:     ChangeTypeAddMaterializedView,
+		Severity: SeveritySafe,
+		Description: describe(descRecreated, "Materialized view",
+			desiredView.QualifiedName(), cause.reason),
+		ObjectType: "materialized_view",
+		ObjectName: key,
 		Details: map[string]any{
-			"view":            *desiredView,
-			"for_type_change": true,
-			"is_recreation":   true,
+			"view":          *desiredView,
+			cause.detailKey: true,
+			"is_recreation": true,
 		},
 		DependsOn: extractViewDependencies(desiredView.Definition),
 	})
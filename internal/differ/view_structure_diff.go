@@ -0,0 +1,319 @@
+package differ
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+)
+
+// DetailKeyViewDiff is the Details key under which MODIFY_VIEW changes carry
+// their *ViewStructureDiff.
+const DetailKeyViewDiff = "view_diff"
+
+var simpleColumnRefRe = regexp.MustCompile(`^[a-z_][a-z0-9_$]*(?:\.[a-z_][a-z0-9_$]*)*$`)
+
+type ViewColumnRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ViewStructureDiff describes how the outer SELECT of a view changed between
+// two definitions. It is computed from the same structured form the view
+// normalizer uses for equality, so formatting-only differences never appear.
+type ViewStructureDiff struct {
+	AddedColumns       []string           `json:"added_columns,omitempty"`
+	RemovedColumns     []string           `json:"removed_columns,omitempty"`
+	RenamedColumns     []ViewColumnRename `json:"renamed_columns,omitempty"`
+	ModifiedColumns    []string           `json:"modified_columns,omitempty"`
+	AddedTables        []string           `json:"added_tables,omitempty"`
+	RemovedTables      []string           `json:"removed_tables,omitempty"`
+	WhereChanged       bool               `json:"where_changed,omitempty"`
+	GroupByChanged     bool               `json:"group_by_changed,omitempty"`
+	WithChanged        bool               `json:"with_changed,omitempty"`
+	CheckOptionChanged bool               `json:"check_option_changed,omitempty"`
+	ParseFailed        bool               `json:"parse_failed,omitempty"`
+}
+
+type viewSelectItem struct {
+	name string
+	expr string
+}
+
+// DiffViewStructure compares two view definitions structurally. When either
+// definition cannot be parsed the result has ParseFailed set and no other
+// fields populated.
+func DiffViewStructure(currentDef, desiredDef string) *ViewStructureDiff {
+	vn := NewViewNormalizer()
+
+	current := parseViewStructure(vn.NormalizeText(currentDef))
+	desired := parseViewStructure(vn.NormalizeText(desiredDef))
+
+	if current == nil || desired == nil {
+		return &ViewStructureDiff{ParseFailed: true}
+	}
+
+	diff := &ViewStructureDiff{}
+
+	diff.compareSelectLists(viewSelectItems(current), viewSelectItems(desired))
+	diff.AddedTables, diff.RemovedTables = diffStringSets(
+		viewFromTables(current),
+		viewFromTables(desired),
+	)
+	diff.WhereChanged = !structuralEqual(current["where"], desired["where"])
+	diff.GroupByChanged = !structuralEqual(current["group_by"], desired["group_by"]) ||
+		!structuralEqual(current["having"], desired["having"])
+	diff.WithChanged = !structuralEqual(current["with"], desired["with"])
+
+	return diff
+}
+
+func parseViewStructure(def string) map[string]any {
+	tokens, err := parser.NewLexer(def).Tokenize()
+	if err != nil {
+		return nil
+	}
+
+	normalizer := &sqlNormalizer{tokens: tokens}
+
+	stmt := normalizer.parseSelectStatement()
+	if _, ok := stmt["select"]; !ok {
+		return nil
+	}
+
+	return stmt
+}
+
+func (d *ViewStructureDiff) compareSelectLists(current, desired []viewSelectItem) {
+	currentByName := make(map[string]viewSelectItem, len(current))
+	for _, item := range current {
+		currentByName[item.name] = item
+	}
+
+	desiredByName := make(map[string]viewSelectItem, len(desired))
+	for _, item := range desired {
+		desiredByName[item.name] = item
+	}
+
+	var unmatchedCurrent, unmatchedDesired []viewSelectItem
+
+	for _, item := range current {
+		if _, ok := desiredByName[item.name]; !ok {
+			unmatchedCurrent = append(unmatchedCurrent, item)
+		}
+	}
+
+	for _, item := range desired {
+		prev, ok := currentByName[item.name]
+		if !ok {
+			unmatchedDesired = append(unmatchedDesired, item)
+			continue
+		}
+
+		if prev.expr != item.expr {
+			d.ModifiedColumns = append(d.ModifiedColumns, item.name)
+		}
+	}
+
+	renamedFrom := make(map[string]bool)
+
+	for _, item := range unmatchedDesired {
+		idx := slices.IndexFunc(unmatchedCurrent, func(prev viewSelectItem) bool {
+			return !renamedFrom[prev.name] && prev.expr == item.expr
+		})
+		if idx == -1 {
+			d.AddedColumns = append(d.AddedColumns, item.name)
+			continue
+		}
+
+		renamedFrom[unmatchedCurrent[idx].name] = true
+		d.RenamedColumns = append(d.RenamedColumns, ViewColumnRename{
+			From: unmatchedCurrent[idx].name,
+			To:   item.name,
+		})
+	}
+
+	for _, item := range unmatchedCurrent {
+		if !renamedFrom[item.name] {
+			d.RemovedColumns = append(d.RemovedColumns, item.name)
+		}
+	}
+}
+
+func (d *ViewStructureDiff) HasChanges() bool {
+	return len(d.AddedColumns) > 0 || len(d.RemovedColumns) > 0 ||
+		len(d.RenamedColumns) > 0 || len(d.ModifiedColumns) > 0 ||
+		len(d.AddedTables) > 0 || len(d.RemovedTables) > 0 ||
+		d.WhereChanged || d.GroupByChanged || d.WithChanged || d.CheckOptionChanged
+}
+
+// Summary renders the diff on one line, e.g.
+// "view public.user_summary: +column total_orders, -column legacy_score, WHERE changed".
+func (d *ViewStructureDiff) Summary(viewName string) string {
+	if d.ParseFailed {
+		return fmt.Sprintf("view %s: definition changed (structural diff unavailable)", viewName)
+	}
+
+	var parts []string
+
+	for _, col := range d.AddedColumns {
+		parts = append(parts, "+column "+col)
+	}
+
+	for _, col := range d.RemovedColumns {
+		parts = append(parts, "-column "+col)
+	}
+
+	for _, rename := range d.RenamedColumns {
+		parts = append(parts, fmt.Sprintf("column %s -> %s", rename.From, rename.To))
+	}
+
+	for _, col := range d.ModifiedColumns {
+		parts = append(parts, "~column "+col)
+	}
+
+	for _, table := range d.AddedTables {
+		parts = append(parts, "+table "+table)
+	}
+
+	for _, table := range d.RemovedTables {
+		parts = append(parts, "-table "+table)
+	}
+
+	if d.WithChanged {
+		parts = append(parts, "WITH changed")
+	}
+
+	if d.WhereChanged {
+		parts = append(parts, "WHERE changed")
+	}
+
+	if d.GroupByChanged {
+		parts = append(parts, "GROUP BY changed")
+	}
+
+	if d.CheckOptionChanged {
+		parts = append(parts, "CHECK OPTION changed")
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, "definition changed")
+	}
+
+	return fmt.Sprintf("view %s: %s", viewName, strings.Join(parts, ", "))
+}
+
+// ViewDiffSummary returns the rendered structural diff attached to a
+// MODIFY_VIEW change, or "" when the change carries none.
+func ViewDiffSummary(change Change) string {
+	diff, ok := change.Details[DetailKeyViewDiff].(*ViewStructureDiff)
+	if !ok || diff == nil {
+		return ""
+	}
+
+	return diff.Summary(change.ObjectName)
+}
+
+// ViewColumnsExtend reports whether the output columns of desiredDef are those
+// of currentDef, in the same order, followed by any new ones: the only column
+// change CREATE OR REPLACE VIEW accepts. Definitions that cannot be parsed are
+// assumed to be.
+func ViewColumnsExtend(currentDef, desiredDef string) bool {
+	vn := NewViewNormalizer()
+
+	current := parseViewStructure(vn.NormalizeText(currentDef))
+	desired := parseViewStructure(vn.NormalizeText(desiredDef))
+
+	if current == nil || desired == nil {
+		return true
+	}
+
+	currentItems := viewSelectItems(current)
+	desiredItems := viewSelectItems(desired)
+
+	if len(desiredItems) < len(currentItems) {
+		return false
+	}
+
+	for i, item := range currentItems {
+		if desiredItems[i].name != item.name {
+			return false
+		}
+	}
+
+	return true
+}
+
+func viewSelectItems(stmt map[string]any) []viewSelectItem {
+	raw, _ := stmt["select"].([]map[string]any)
+	items := make([]viewSelectItem, 0, len(raw))
+
+	for i, entry := range raw {
+		expr := ""
+		if exprMap, ok := entry["expr"].(map[string]any); ok {
+			expr, _ = exprMap["value"].(string)
+		}
+
+		name, _ := entry["alias"].(string)
+		if name == "" {
+			name = selectItemName(expr, i)
+		}
+
+		items = append(items, viewSelectItem{name: name, expr: expr})
+	}
+
+	return items
+}
+
+func selectItemName(expr string, position int) string {
+	compact := strings.ReplaceAll(expr, " ", "")
+	if simpleColumnRefRe.MatchString(compact) {
+		parts := strings.Split(compact, ".")
+		return parts[len(parts)-1]
+	}
+
+	if expr == "" {
+		return fmt.Sprintf("column%d", position+1)
+	}
+
+	return expr
+}
+
+func viewFromTables(stmt map[string]any) []string {
+	raw, _ := stmt["from"].([]map[string]any)
+	tables := make([]string, 0, len(raw))
+
+	for _, entry := range raw {
+		if name, ok := entry["name"].(string); ok && name != "" {
+			tables = append(tables, name)
+		}
+	}
+
+	return tables
+}
+
+func diffStringSets(current, desired []string) (added, removed []string) {
+	for _, name := range desired {
+		if !slices.Contains(current, name) && !slices.Contains(added, name) {
+			added = append(added, name)
+		}
+	}
+
+	for _, name := range current {
+		if !slices.Contains(desired, name) && !slices.Contains(removed, name) {
+			removed = append(removed, name)
+		}
+	}
+
+	return added, removed
+}
+
+func structuralEqual(a, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
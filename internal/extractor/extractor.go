@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/sync/errgroup"
+
 	"github.com/accented-ai/pgtofu/internal/schema"
 	"github.com/accented-ai/pgtofu/internal/util"
 	"github.com/accented-ai/pgtofu/pkg/database"
@@ -27,14 +30,21 @@ var systemSchemas = []string{ //nolint:gochecknoglobals
 	"timescaledb_internal",
 }
 
+// DefaultConcurrency is the number of catalog queries Extract runs at once
+// when Options.Concurrency is not set.
+const DefaultConcurrency = 4
+
 type Options struct {
 	ExcludeSchemas      []string
 	ExcludeExtensions   []string
 	IncludeSystemTables bool
+	// Concurrency is the most kinds of objects Extract reads at once, and the
+	// most queries it runs at once for the details of the tables. Zero uses
+	// DefaultConcurrency, and one runs every query after the other.
+	Concurrency int
 }
 
 type Extractor struct {
-	pool           *database.Pool
 	queryHelper    *database.QueryHelper
 	hasTimescaleDB bool
 	opts           Options
@@ -46,8 +56,23 @@ func New(ctx context.Context, pool *database.Pool, opts Options) (*Extractor, er
 		return nil, errors.New("pool cannot be nil")
 	}
 
-	hasTimescaleDB, err := pool.HasTimescaleDB(ctx)
-	if err != nil {
+	return NewFromQuerier(ctx, pool, opts)
+}
+
+// NewFromQuerier returns an Extractor that reads the catalog through
+// querier, such as a connection other than a pool.
+func NewFromQuerier(ctx context.Context, querier database.Querier, opts Options) (*Extractor, error) {
+	if querier == nil {
+		return nil, errors.New("querier cannot be nil")
+	}
+
+	queryHelper := database.NewQueryHelper(querier)
+
+	var hasTimescaleDB bool
+
+	if err := queryHelper.FetchOne(ctx, queryHasTimescaleDB, func(row pgx.Row) error {
+		return row.Scan(&hasTimescaleDB)
+	}); err != nil {
 		return nil, util.WrapError("check timescaledb", err)
 	}
 
@@ -61,9 +86,12 @@ func New(ctx context.Context, pool *database.Pool, opts Options) (*Extractor, er
 		opts.ExcludeExtensions = []string{"plpgsql"}
 	}
 
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+
 	return &Extractor{
-		pool:           pool,
-		queryHelper:    database.NewQueryHelper(pool),
+		queryHelper:    queryHelper,
 		hasTimescaleDB: hasTimescaleDB,
 		opts:           opts,
 		queries: &queryBuilder{
@@ -75,9 +103,15 @@ func New(ctx context.Context, pool *database.Pool, opts Options) (*Extractor, er
 	}, nil
 }
 
+// Extract reads the schema of the database. The kinds of objects are read
+// concurrently, and the first error or a canceled ctx stops the queries
+// still running.
 func (e *Extractor) Extract(ctx context.Context) (*schema.Database, error) {
-	dbName, err := e.pool.CurrentDatabase(ctx)
-	if err != nil {
+	var dbName string
+
+	if err := e.queryHelper.FetchOne(ctx, queryCurrentDatabase, func(row pgx.Row) error {
+		return row.Scan(&dbName)
+	}); err != nil {
 		return nil, util.WrapError("get database name", err)
 	}
 
@@ -87,116 +121,91 @@ func (e *Extractor) Extract(ctx context.Context) (*schema.Database, error) {
 		ExtractedAt:  time.Now().UTC().Format(time.RFC3339),
 	}
 
+	// Every extractor sets its own fields of db, so they do not race.
 	extractors := []struct {
 		name string
 		fn   func(context.Context) error
 	}{
-		{"schemas", func(ctx context.Context) error {
-			schemas, err := e.extractSchemas(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.Schemas = schemas
-
-			return nil
+		{"schemas", func(ctx context.Context) (err error) {
+			db.Schemas, err = e.extractSchemas(ctx)
+			return err
 		}},
-		{"extensions", func(ctx context.Context) error {
-			extensions, err := e.extractExtensions(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.Extensions = extensions
-
-			return nil
+		{"extensions", func(ctx context.Context) (err error) {
+			db.Extensions, err = e.extractExtensions(ctx)
+			return err
 		}},
-		{"custom types", func(ctx context.Context) error {
-			types, err := e.extractCustomTypes(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.CustomTypes = types
-
-			return nil
+		{"custom types", func(ctx context.Context) (err error) {
+			db.CustomTypes, err = e.extractCustomTypes(ctx)
+			return err
 		}},
-		{"sequences", func(ctx context.Context) error {
-			sequences, err := e.extractSequences(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.Sequences = sequences
-
-			return nil
+		{"sequences", func(ctx context.Context) (err error) {
+			db.Sequences, err = e.extractSequences(ctx)
+			return err
 		}},
-		{"tables", func(ctx context.Context) error {
-			tables, err := e.extractTables(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.Tables = tables
-
-			return nil
+		{"tables", func(ctx context.Context) (err error) {
+			db.Tables, err = e.extractTables(ctx)
+			return err
 		}},
-		{"views", func(ctx context.Context) error {
-			views, err := e.extractViews(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.Views = views
-
-			return nil
+		{"views", func(ctx context.Context) (err error) {
+			db.Views, err = e.extractViews(ctx)
+			return err
 		}},
-		{"materialized views", func(ctx context.Context) error {
-			matViews, err := e.extractMaterializedViews(ctx)
-			if err != nil {
-				return err
-			}
-
-			db.MaterializedViews = matViews
-
-			return nil
+		{"materialized views", func(ctx context.Context) (err error) {
+			db.MaterializedViews, err = e.extractMaterializedViews(ctx)
+			return err
+		}},
+		{"functions", func(ctx context.Context) (err error) {
+			db.Functions, err = e.extractFunctions(ctx)
+			return err
+		}},
+		{"triggers", func(ctx context.Context) (err error) {
+			db.Triggers, err = e.extractTriggers(ctx)
+			return err
+		}},
+		{"grants", func(ctx context.Context) (err error) {
+			db.Grants, err = e.extractGrants(ctx)
+			return err
+		}},
+		{"roles", func(ctx context.Context) (err error) {
+			db.Roles, err = e.extractRoles(ctx)
+			return err
 		}},
-		{"functions", func(ctx context.Context) error {
-			functions, err := e.extractFunctions(ctx)
-			if err != nil {
+	}
+
+	if e.hasTimescaleDB {
+		extractors = append(extractors, []struct {
+			name string
+			fn   func(context.Context) error
+		}{
+			{"hypertables", func(ctx context.Context) (err error) {
+				db.Hypertables, err = e.extractHypertables(ctx)
 				return err
-			}
+			}},
+			{"continuous aggregates", func(ctx context.Context) (err error) {
+				db.ContinuousAggregates, err = e.extractContinuousAggregates(ctx)
+				return err
+			}},
+		}...)
+	}
 
-			db.Functions = functions
+	group, groupCtx := e.group(ctx)
 
-			return nil
-		}},
-		{"triggers", func(ctx context.Context) error {
-			triggers, err := e.extractTriggers(ctx)
-			if err != nil {
-				return err
+	for _, extractor := range extractors {
+		group.Go(func() error {
+			if err := groupCtx.Err(); err != nil {
+				return fmt.Errorf("before extracting %s: %w", extractor.name, err)
 			}
 
-			db.Triggers = triggers
+			if err := extractor.fn(groupCtx); err != nil {
+				return util.WrapError("extract "+extractor.name, err)
+			}
 
 			return nil
-		}},
-	}
-
-	for _, extractor := range extractors {
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("before extracting %s: %w", extractor.name, err)
-		}
-
-		if err := extractor.fn(ctx); err != nil {
-			return nil, util.WrapError("extract "+extractor.name, err)
-		}
+		})
 	}
 
-	if e.hasTimescaleDB {
-		if err := e.extractTimescaleDBFeatures(ctx, db); err != nil {
-			return nil, util.WrapError("extract timescaledb features", err)
-		}
+	if err := group.Wait(); err != nil {
+		return nil, err //nolint:wrapcheck
 	}
 
 	db.Sort()
@@ -204,20 +213,11 @@ func (e *Extractor) Extract(ctx context.Context) (*schema.Database, error) {
 	return db, nil
 }
 
-func (e *Extractor) extractTimescaleDBFeatures(ctx context.Context, db *schema.Database) error {
-	hypertables, err := e.extractHypertables(ctx)
-	if err != nil {
-		return util.WrapError("extract hypertables", err)
-	}
-
-	db.Hypertables = hypertables
-
-	continuousAggregates, err := e.extractContinuousAggregates(ctx)
-	if err != nil {
-		return util.WrapError("extract continuous aggregates", err)
-	}
-
-	db.ContinuousAggregates = continuousAggregates
+// group returns an errgroup running up to Options.Concurrency functions at
+// once, whose context is canceled by the first that fails.
+func (e *Extractor) group(ctx context.Context) (*errgroup.Group, context.Context) {
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(e.opts.Concurrency)
 
-	return nil
+	return group, groupCtx
 }
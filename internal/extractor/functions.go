@@ -19,8 +19,9 @@ func (e *Extractor) extractFunctions(ctx context.Context) ([]schema.Function, er
 		scanner := NewNullScanner()
 
 		var (
-			fn        schema.Function
-			arguments *string
+			fn          schema.Function
+			arguments   *string
+			isProcedure bool
 		)
 
 		if err := rows.Scan(
@@ -38,10 +39,15 @@ func (e *Extractor) extractFunctions(ctx context.Context) ([]schema.Function, er
 			scanner.String("comment"),
 			scanner.String("owner"),
 			&fn.Definition,
+			&isProcedure,
 		); err != nil {
 			return util.WrapError("scan function", err)
 		}
 
+		if isProcedure {
+			fn.Kind = schema.FunctionKindProcedure
+		}
+
 		fn.ReturnType = scanner.GetString("returnType")
 		fn.Comment = scanner.GetString("comment")
 		fn.Owner = scanner.GetString("owner")
@@ -85,6 +91,7 @@ func (e *Extractor) extractTriggers(ctx context.Context) ([]schema.Trigger, erro
 			&trig.FunctionSchema,
 			&trig.FunctionName,
 			scanner.String("comment"),
+			&trig.EnabledState,
 		); err != nil {
 			return util.WrapError("scan trigger", err)
 		}
@@ -0,0 +1,73 @@
+package extractor
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// extractGrants reads the privileges granted on tables, views and
+// materialized views and on their columns, one grant per object or column,
+// grantee and grant option.
+func (e *Extractor) extractGrants(ctx context.Context) ([]schema.Grant, error) {
+	query := e.queries.grantsQuery()
+
+	var grants []schema.Grant
+
+	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
+		scanner := NewNullScanner()
+
+		var grant schema.Grant
+		if err := rows.Scan(
+			&grant.Schema,
+			&grant.ObjectName,
+			scanner.String("column"),
+			&grant.Grantee,
+			&grant.WithGrantOption,
+			&grant.Privileges,
+		); err != nil {
+			return util.WrapError("scan grant", err)
+		}
+
+		grant.Column = scanner.GetString("column")
+
+		grant.ObjectType = schema.GrantObjectTable
+		if grant.Column != "" {
+			grant.ObjectType = schema.GrantObjectColumn
+		}
+
+		grants = append(grants, grant)
+
+		return nil
+	})
+	if err != nil {
+		return nil, util.WrapError("fetch grants", err)
+	}
+
+	return grants, nil
+}
+
+// extractRoles reads the names of the roles of the cluster, against which
+// the grantees of the desired grants are checked.
+func (e *Extractor) extractRoles(ctx context.Context) ([]string, error) {
+	var roles []string
+
+	err := e.queryHelper.FetchAll(ctx, queryRoles, func(rows pgx.Rows) error {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return util.WrapError("scan role", err)
+		}
+
+		roles = append(roles, role)
+
+		return nil
+	})
+	if err != nil {
+		return nil, util.WrapError("fetch roles", err)
+	}
+
+	return roles, nil
+}
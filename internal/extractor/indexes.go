@@ -10,83 +10,88 @@ import (
 	"github.com/accented-ai/pgtofu/internal/util"
 )
 
-func (e *Extractor) extractIndexes(ctx context.Context, table *schema.Table) error {
-	dimensionColumns := e.getHypertableDimensionColumns(ctx, table.Schema, table.Name)
-
-	var indexes []schema.Index
-
-	err := e.queryHelper.FetchAll(ctx, queryIndexes, func(rows pgx.Rows) error {
-		scanner := NewNullScanner()
-
-		var idx schema.Index
-
-		if err := rows.Scan(
-			&idx.Schema,
-			&idx.Name,
-			&idx.TableName,
-			&idx.IsUnique,
-			&idx.IsPrimary,
-			&idx.Type,
-			scanner.String("where"),
-			&idx.Definition,
-			scanner.String("tablespace"),
-			&idx.NullsNotDistinct,
-		); err != nil {
-			return util.WrapError("scan index", err)
+// fetchIndexes reads the indexes query selects for the relations of oids by
+// relation oid.
+func (e *Extractor) fetchIndexes(
+	ctx context.Context,
+	query string,
+	oids []uint32,
+) (map[uint32][]schema.Index, error) {
+	indexes := make(map[uint32][]schema.Index)
+
+	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
+		oid, idx, err := scanIndex(rows)
+		if err != nil {
+			return err
 		}
 
-		idx.Where = scanner.GetString("where")
-		idx.Tablespace = scanner.GetString("tablespace")
+		indexes[oid] = append(indexes[oid], idx)
 
-		columns, includeColumns := parseIndexDefinition(idx.Definition)
-		idx.Columns = columns
-		idx.IncludeColumns = includeColumns
+		return nil
+	}, oids)
+	if err != nil {
+		return nil, util.WrapError("fetch indexes", err)
+	}
 
-		storageParams, err := e.extractIndexStorageParams(ctx, idx.Schema, idx.Name)
-		if err == nil && len(storageParams) > 0 {
-			idx.StorageParams = storageParams
-		}
+	return indexes, nil
+}
 
-		if e.isTimescaleDBManagedIndex(idx.Name, table.Name, dimensionColumns) {
-			return nil
-		}
+// scanIndex reads an index of indexSelect, and the oid of its relation.
+func scanIndex(rows pgx.Rows) (uint32, schema.Index, error) {
+	scanner := NewNullScanner()
 
-		indexes = append(indexes, idx)
+	var (
+		oid     uint32
+		idx     schema.Index
+		options []string
+	)
 
-		return nil
-	}, table.Schema, table.Name)
-	if err != nil {
-		return util.WrapError("fetch indexes", err)
+	if err := rows.Scan(
+		&oid,
+		&idx.Schema,
+		&idx.Name,
+		&idx.TableName,
+		&idx.IsUnique,
+		&idx.IsPrimary,
+		&idx.Type,
+		scanner.String("where"),
+		&idx.Definition,
+		scanner.String("tablespace"),
+		&idx.NullsNotDistinct,
+		scanner.String("comment"),
+		&options,
+	); err != nil {
+		return 0, schema.Index{}, util.WrapError("scan index", err)
 	}
 
-	table.Indexes = indexes
+	idx.Where = scanner.GetString("where")
+	idx.Tablespace = scanner.GetString("tablespace")
+	idx.Comment = scanner.GetString("comment")
 
-	return nil
+	columns, includeColumns := parseIndexDefinition(idx.Definition)
+	idx.Columns = columns
+	idx.IncludeColumns = includeColumns
+	idx.StorageParams = storageParams(options)
+
+	return oid, idx, nil
 }
 
-func (e *Extractor) extractIndexStorageParams(
-	ctx context.Context,
-	schemaName, indexName string,
-) (map[string]string, error) {
-	params := make(map[string]string)
-
-	err := e.queryHelper.FetchAll(ctx, queryIndexStorageParams, func(rows pgx.Rows) error {
-		var option string
-		if err := rows.Scan(&option); err != nil {
-			return util.WrapError("scan storage param", err)
-		}
+// storageParams returns the reloptions of a relation as a map, or nil when
+// it has none.
+func storageParams(options []string) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
 
+	params := make(map[string]string, len(options))
+
+	for _, option := range options {
 		if key, value, ok := strings.Cut(option, "="); ok {
 			params[key] = value
 		}
-
-		return nil
-	}, schemaName, indexName)
-	if err != nil {
-		return nil, util.WrapError("fetch storage params", err)
 	}
 
-	return params, nil
+	return params
 }
 
 func parseIndexDefinition(definition string) ([]string, []string) {
@@ -169,7 +174,7 @@ func parseColumnList(columnList string) []string {
 		case ',':
 			if !inString && depth == 0 {
 				if col := strings.TrimSpace(current.String()); col != "" {
-					columns = append(columns, col)
+					columns = append(columns, indexColumnName(col))
 				}
 
 				current.Reset()
@@ -182,34 +187,44 @@ func parseColumnList(columnList string) []string {
 	}
 
 	if col := strings.TrimSpace(current.String()); col != "" {
-		columns = append(columns, col)
+		columns = append(columns, indexColumnName(col))
 	}
 
 	return columns
 }
 
-func (e *Extractor) getHypertableDimensionColumns(
-	ctx context.Context,
-	schemaName, tableName string,
-) []string {
-	if !e.hasTimescaleDB {
-		return nil
+// indexColumnName returns the name of a column pg_get_indexdef quoted, such
+// as "CreatedAt", as the catalog stores it. Anything else is kept as written.
+func indexColumnName(col string) string {
+	if len(col) < 2 || col[0] != '"' || col[len(col)-1] != '"' ||
+		strings.Contains(strings.ReplaceAll(col[1:len(col)-1], `""`, ""), `"`) {
+		return col
 	}
 
-	var columns []string
+	return schema.NormalizeIdentifier(col)
+}
+
+// getHypertableDimensionColumns reads the dimension columns of every
+// hypertable by qualified table name.
+func (e *Extractor) getHypertableDimensionColumns(ctx context.Context) (map[string][]string, error) {
+	columns := make(map[string][]string)
 
-	_ = e.queryHelper.FetchAll(ctx, queryHypertableDimensions, func(rows pgx.Rows) error {
-		var column string
-		if err := rows.Scan(&column); err != nil {
+	err := e.queryHelper.FetchAll(ctx, queryHypertableDimensions, func(rows pgx.Rows) error {
+		var schemaName, tableName, column string
+		if err := rows.Scan(&schemaName, &tableName, &column); err != nil {
 			return util.WrapError("scan dimension column", err)
 		}
 
-		columns = append(columns, column)
+		key := schema.QualifiedName(schemaName, tableName)
+		columns[key] = append(columns[key], column)
 
 		return nil
-	}, schemaName, tableName)
+	})
+	if err != nil {
+		return nil, util.WrapError("fetch dimension columns", err)
+	}
 
-	return columns
+	return columns, nil
 }
 
 func (e *Extractor) isTimescaleDBManagedIndex(
@@ -0,0 +1,64 @@
+package extractor
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// extractPolicies reads the row-level security policies of the tables of
+// oids by table oid. A policy for all commands or for PUBLIC alone is
+// recorded as the parser records one written without FOR or TO.
+func (e *Extractor) extractPolicies(
+	ctx context.Context,
+	oids []uint32,
+) (map[uint32][]schema.Policy, error) {
+	policies := make(map[uint32][]schema.Policy)
+
+	err := e.queryHelper.FetchAll(ctx, queryPolicies, func(rows pgx.Rows) error {
+		scanner := NewNullScanner()
+
+		var (
+			oid    uint32
+			policy schema.Policy
+			roles  []string
+		)
+
+		if err := rows.Scan(
+			&oid,
+			&policy.Schema,
+			&policy.Name,
+			&policy.TableName,
+			&policy.Command,
+			&roles,
+			scanner.String("using"),
+			scanner.String("with_check"),
+			&policy.Restrictive,
+		); err != nil {
+			return util.WrapError("scan policy", err)
+		}
+
+		if policy.Command == schema.PolicyCommandAll {
+			policy.Command = ""
+		}
+
+		if len(roles) != 1 || roles[0] != schema.PolicyRolePublic {
+			policy.Roles = roles
+		}
+
+		policy.Using = scanner.GetString("using")
+		policy.WithCheck = scanner.GetString("with_check")
+
+		policies[oid] = append(policies[oid], policy)
+
+		return nil
+	}, oids)
+	if err != nil {
+		return nil, util.WrapError("fetch policies", err)
+	}
+
+	return policies, nil
+}
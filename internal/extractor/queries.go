@@ -102,6 +102,10 @@ func (qb *queryBuilder) buildInClause(values []string) string {
 }
 
 const (
+	queryCurrentDatabase = `SELECT current_database()`
+
+	queryHasTimescaleDB = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`
+
 	queryTables = `
 		SELECT
 			t.table_schema,
@@ -111,11 +115,16 @@ const (
 				'pg_class'
 			) as table_comment,
 			pg_catalog.pg_get_userbyid(c.relowner) as owner,
-			ts.spcname as tablespace
+			ts.spcname as tablespace,
+			oftn.nspname || '.' || oft.typname as typed_of,
+			c.relrowsecurity,
+			c.oid
 		FROM information_schema.tables t
 		JOIN pg_catalog.pg_class c ON c.relname = t.table_name
 		JOIN pg_catalog.pg_namespace n ON n.nspname = t.table_schema AND c.relnamespace = n.oid
 		LEFT JOIN pg_catalog.pg_tablespace ts ON c.reltablespace = ts.oid
+		LEFT JOIN pg_catalog.pg_type oft ON oft.oid = c.reloftype
+		LEFT JOIN pg_catalog.pg_namespace oftn ON oftn.oid = oft.typnamespace
 		WHERE t.table_type = 'BASE TABLE'
 		AND %s
 		%s
@@ -125,16 +134,16 @@ const (
 		)
 		ORDER BY t.table_schema, t.table_name`
 
+	// queryColumns and the other queries of table details below read those
+	// of every table whose oid is in $1 at once, each row led by the oid.
 	queryColumns = `
 		SELECT
+			rc.oid,
 			c.column_name,
 			c.data_type,
 			c.is_nullable = 'YES',
 			c.column_default,
-			col_description(
-				(quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass::oid,
-				c.ordinal_position
-			),
+			col_description(rc.oid, c.ordinal_position),
 			c.ordinal_position,
 			c.character_maximum_length,
 			c.numeric_precision,
@@ -142,18 +151,51 @@ const (
 			c.udt_name,
 			c.is_identity = 'YES',
 			c.identity_generation,
-			c.is_generated = 'ALWAYS',
-			c.generation_expression,
-			format_type(a.atttypid, a.atttypmod) AS full_type
+			COALESCE(seq.seqstart, 0),
+			COALESCE(seq.seqmin, 0),
+			COALESCE(seq.seqmax, 0),
+			COALESCE(seq.seqincrement, 0),
+			COALESCE(seq.seqcache, 0),
+			COALESCE(seq.seqcycle, false),
+			-- attgenerated is new in PostgreSQL 12; read through jsonb like
+			-- attcompression below.
+			COALESCE(to_jsonb(a) ->> 'attgenerated' = 's', false),
+			CASE WHEN to_jsonb(a) ->> 'attgenerated' = 's' THEN
+				pg_get_expr(ad.adbin, ad.adrelid)
+			END AS generation_expression,
+			format_type(a.atttypid, a.atttypmod) AS full_type,
+			CASE WHEN a.attstorage <> ty.typstorage THEN
+				CASE a.attstorage
+					WHEN 'p' THEN 'plain'
+					WHEN 'e' THEN 'external'
+					WHEN 'x' THEN 'extended'
+					WHEN 'm' THEN 'main'
+				END
+			END AS storage,
+			-- attcompression is new in PostgreSQL 14; read through jsonb so
+			-- older servers report no compression instead of failing.
+			CASE to_jsonb(a) ->> 'attcompression'
+				WHEN 'p' THEN 'pglz'
+				WHEN 'l' THEN 'lz4'
+			END AS compression
 		FROM information_schema.columns c
+		JOIN pg_catalog.pg_namespace rn ON rn.nspname = c.table_schema
+		JOIN pg_catalog.pg_class rc ON rc.relnamespace = rn.oid AND rc.relname = c.table_name
 		LEFT JOIN pg_catalog.pg_attribute a
-			ON a.attrelid = (quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass
-			AND a.attname = c.column_name
-		WHERE c.table_schema = $1 AND c.table_name = $2
-		ORDER BY c.ordinal_position`
-
-	queryConstraints = `
+			ON a.attrelid = rc.oid AND a.attname = c.column_name
+		LEFT JOIN pg_catalog.pg_type ty ON ty.oid = a.atttypid
+		LEFT JOIN pg_catalog.pg_attrdef ad
+			ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+		LEFT JOIN pg_catalog.pg_depend idd
+			ON idd.refobjid = a.attrelid AND idd.refobjsubid = a.attnum
+			AND idd.classid = 'pg_catalog.pg_class'::regclass AND idd.deptype = 'i'
+		LEFT JOIN pg_catalog.pg_sequence seq ON seq.seqrelid = idd.objid
+		WHERE rc.oid = ANY($1::oid[])
+		ORDER BY rc.oid, c.ordinal_position`
+
+	constraintSelect = `
 		SELECT
+			con.conrelid,
 			con.conname,
 			CASE con.contype
 				WHEN 'p' THEN 'PRIMARY KEY'
@@ -192,19 +234,35 @@ const (
 				WHEN 'd' THEN 'SET DEFAULT'
 			END,
 			con.condeferrable,
-			con.condeferred
+			con.condeferred,
+			obj_description(con.oid, 'pg_constraint'),
+			xam.amname,
+			ARRAY(
+				SELECT pg_get_indexdef(xi.indexrelid, k, true) ||
+					CASE WHEN opc.opcdefault THEN '' ELSE ' ' || opc.opcname END
+				FROM generate_subscripts(con.conexclop, 1) AS k
+				JOIN pg_opclass opc ON opc.oid = xi.indclass[k - 1]
+				ORDER BY k
+			),
+			ARRAY(
+				SELECT o.oprname
+				FROM unnest(con.conexclop) WITH ORDINALITY AS u(op, k)
+				JOIN pg_operator o ON o.oid = u.op
+				ORDER BY u.k
+			),
+			pg_get_expr(xi.indpred, xi.indrelid, true)
 		FROM pg_constraint con
 		JOIN pg_class c ON con.conrelid = c.oid
 		JOIN pg_namespace n ON c.relnamespace = n.oid
 		LEFT JOIN pg_class fc ON con.confrelid = fc.oid
 		LEFT JOIN pg_namespace fn ON fc.relnamespace = fn.oid
-		WHERE n.nspname = $1 AND c.relname = $2
-		AND con.conislocal = true
-		AND NOT EXISTS (
-			SELECT 1 FROM pg_catalog.pg_inherits i
-			WHERE i.inhrelid = con.conrelid AND i.inhparent != 0
-		)
+		LEFT JOIN pg_index xi ON xi.indexrelid = con.conindid AND con.contype = 'x'
+		LEFT JOIN pg_class xc ON xc.oid = xi.indexrelid
+		LEFT JOIN pg_am xam ON xam.oid = xc.relam`
+
+	constraintOrder = `
 		ORDER BY
+			con.conrelid,
 			CASE con.contype
 				WHEN 'p' THEN 1
 				WHEN 'f' THEN 2
@@ -214,37 +272,54 @@ const (
 			END,
 			con.conname`
 
+	queryConstraints = constraintSelect + `
+		WHERE con.conrelid = ANY($1::oid[])
+		AND con.conislocal = true
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_inherits i
+			WHERE i.inhrelid = con.conrelid AND i.inhparent != 0
+		)` + constraintOrder
+
+	// queryPartitionConstraints selects the constraints declared on a
+	// partition alone, leaving out those inherited or cloned from its parent.
+	queryPartitionConstraints = constraintSelect + `
+		WHERE con.conrelid = ANY($1::oid[])
+		AND con.conislocal = true
+		AND con.coninhcount = 0
+		AND con.conparentid = 0` + constraintOrder
+
 	queryPartitionInfo = `
 		SELECT
+			pt.partrelid,
 			pt.partstrat::text,
 			COALESCE(
 				array_agg(a.attname ORDER BY array_position(pt.partattrs, a.attnum))
 				FILTER (WHERE a.attname IS NOT NULL),
 				ARRAY[]::text[]
 			)
-		FROM pg_class c
-		JOIN pg_namespace n ON c.relnamespace = n.oid
-		LEFT JOIN pg_partitioned_table pt ON pt.partrelid = c.oid
-		LEFT JOIN pg_attribute a ON a.attrelid = c.oid
+		FROM pg_partitioned_table pt
+		LEFT JOIN pg_attribute a ON a.attrelid = pt.partrelid
 			AND a.attnum = ANY(pt.partattrs)
 			AND NOT a.attisdropped
-		WHERE n.nspname = $1 AND c.relname = $2
-		GROUP BY pt.partstrat`
+		WHERE pt.partrelid = ANY($1::oid[])
+		GROUP BY pt.partrelid, pt.partstrat`
 
+	// queryPartitions reads the partitions of the partitioned tables whose
+	// oid is in $1, each led by the oid of its parent and then its own.
 	queryPartitions = `
 		SELECT
+			i.inhparent,
+			c2.oid,
 			c2.relname,
 			pg_get_expr(c2.relpartbound, c2.oid)
-		FROM pg_class c1
-		JOIN pg_namespace n1 ON c1.relnamespace = n1.oid
-		JOIN pg_inherits i ON i.inhparent = c1.oid
+		FROM pg_inherits i
 		JOIN pg_class c2 ON i.inhrelid = c2.oid
-		JOIN pg_namespace n2 ON c2.relnamespace = n2.oid
-		WHERE n1.nspname = $1 AND c1.relname = $2
-		ORDER BY c2.relname`
+		WHERE i.inhparent = ANY($1::oid[])
+		ORDER BY i.inhparent, c2.relname`
 
-	queryIndexes = `
+	indexSelect = `
 		SELECT
+			ix.indrelid,
 			i.schemaname,
 			i.indexname,
 			i.tablename,
@@ -254,26 +329,63 @@ const (
 			pg_get_expr(ix.indpred, ix.indrelid),
 			pg_get_indexdef(ix.indexrelid),
 			ts.spcname,
-			ix.indnullsnotdistinct
+			ix.indnullsnotdistinct,
+			obj_description(c.oid, 'pg_class'),
+			c.reloptions
 		FROM pg_indexes i
-		JOIN pg_class c ON c.relname = i.indexname
+		JOIN pg_namespace ns ON ns.nspname = i.schemaname
+		JOIN pg_class c ON c.relname = i.indexname AND c.relnamespace = ns.oid
 		JOIN pg_index ix ON ix.indexrelid = c.oid
 		JOIN pg_am am ON c.relam = am.oid
-		LEFT JOIN pg_tablespace ts ON c.reltablespace = ts.oid
+		LEFT JOIN pg_tablespace ts ON c.reltablespace = ts.oid`
+
+	queryIndexes = indexSelect + `
+		WHERE ix.indrelid = ANY($1::oid[])
+		ORDER BY ix.indrelid, i.indexname`
+
+	// queryRelationIndexes selects the indexes of the relation $2 of schema
+	// $1, such as the materialization hypertable of a continuous aggregate.
+	queryRelationIndexes = indexSelect + `
 		WHERE i.schemaname = $1 AND i.tablename = $2
 		ORDER BY i.indexname`
 
-	queryIndexStorageParams = `
-		SELECT unnest(c.reloptions)
-		FROM pg_class c
-		JOIN pg_namespace n ON c.relnamespace = n.oid
-		WHERE n.nspname = $1 AND c.relname = $2 AND c.reloptions IS NOT NULL`
+	// queryPartitionIndexes selects the standalone indexes declared on a
+	// partition alone: indexes attached to a parent index and indexes backing
+	// a constraint are left out.
+	queryPartitionIndexes = indexSelect + `
+		WHERE ix.indrelid = ANY($1::oid[])
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_inherits inh
+			WHERE inh.inhrelid = ix.indexrelid
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_constraint con
+			WHERE con.conrelid = ix.indrelid AND con.conindid = ix.indexrelid
+			AND con.contype IN ('p', 'u', 'x')
+		)
+		ORDER BY ix.indrelid, i.indexname`
+
+	queryPolicies = `
+		SELECT
+			pc.oid,
+			p.schemaname,
+			p.policyname,
+			p.tablename,
+			p.cmd,
+			p.roles::text[],
+			p.qual,
+			p.with_check,
+			p.permissive = 'RESTRICTIVE'
+		FROM pg_catalog.pg_policies p
+		JOIN pg_catalog.pg_namespace pn ON pn.nspname = p.schemaname
+		JOIN pg_catalog.pg_class pc ON pc.relnamespace = pn.oid AND pc.relname = p.tablename
+		WHERE pc.oid = ANY($1::oid[])
+		ORDER BY pc.oid, p.policyname`
 
 	queryHypertableDimensions = `
-		SELECT column_name
+		SELECT hypertable_schema, hypertable_name, column_name
 		FROM timescaledb_information.dimensions
-		WHERE hypertable_schema = $1 AND hypertable_name = $2
-		ORDER BY dimension_number`
+		ORDER BY hypertable_schema, hypertable_name, dimension_number`
 
 	queryViews = `
 		SELECT
@@ -286,7 +398,8 @@ const (
 			),
 			pg_catalog.pg_get_userbyid(c.relowner),
 			v.check_option,
-			v.is_updatable = 'YES'
+			v.is_updatable = 'YES',
+			c.reloptions
 		FROM information_schema.views v
 		JOIN pg_catalog.pg_class c ON c.relname = v.table_name
 		JOIN pg_catalog.pg_namespace n ON n.nspname = v.table_schema AND c.relnamespace = n.oid
@@ -302,7 +415,9 @@ const (
 			obj_description(c.oid, 'pg_class'),
 			pg_catalog.pg_get_userbyid(c.relowner),
 			ts.spcname,
-			c.relispopulated
+			c.relispopulated,
+			c.reloptions,
+			c.oid
 		FROM pg_class c
 		JOIN pg_namespace n ON c.relnamespace = n.oid
 		LEFT JOIN pg_tablespace ts ON c.reltablespace = ts.oid
@@ -331,7 +446,8 @@ const (
 			END,
 			obj_description(p.oid, 'pg_proc'),
 			pg_catalog.pg_get_userbyid(p.proowner),
-			pg_catalog.pg_get_functiondef(p.oid)
+			pg_catalog.pg_get_functiondef(p.oid),
+			p.prokind = 'p'
 		FROM pg_proc p
 		JOIN pg_namespace n ON p.pronamespace = n.oid
 		JOIN pg_language l ON p.prolang = l.oid
@@ -369,7 +485,13 @@ const (
 			pg_get_triggerdef(t.oid),
 			pn.nspname,
 			p.proname,
-			obj_description(t.oid, 'pg_trigger')
+			obj_description(t.oid, 'pg_trigger'),
+			CASE t.tgenabled
+				WHEN 'D' THEN 'disabled'
+				WHEN 'R' THEN 'replica'
+				WHEN 'A' THEN 'always'
+				ELSE ''
+			END
 		FROM pg_trigger t
 		JOIN pg_class c ON t.tgrelid = c.oid
 		JOIN pg_namespace n ON c.relnamespace = n.oid
@@ -401,7 +523,8 @@ const (
 				ELSE 'other'
 			END,
 			obj_description(t.oid, 'pg_type'),
-			pg_catalog.format_type(t.oid, NULL)
+			pg_catalog.format_type(t.oid, NULL),
+			t.oid
 		FROM pg_type t
 		JOIN pg_namespace n ON t.typnamespace = n.oid
 		WHERE t.typtype IN ('e', 'c', 'd')
@@ -415,12 +538,18 @@ const (
 		ORDER BY n.nspname, t.typname`
 
 	queryEnumValues = `
-		SELECT e.enumlabel
+		SELECT e.enumtypid, e.enumlabel
 		FROM pg_enum e
-		JOIN pg_type t ON e.enumtypid = t.oid
-		JOIN pg_namespace n ON t.typnamespace = n.oid
-		WHERE n.nspname = $1 AND t.typname = $2
-		ORDER BY e.enumsortorder`
+		WHERE e.enumtypid = ANY($1::oid[])
+		ORDER BY e.enumtypid, e.enumsortorder`
+
+	queryCompositeAttributes = `
+		SELECT t.oid, a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod)
+		FROM pg_attribute a
+		JOIN pg_type t ON a.attrelid = t.typrelid
+		WHERE t.oid = ANY($1::oid[])
+		AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY t.oid, a.attnum`
 
 	querySequences = `
 		SELECT
@@ -441,15 +570,58 @@ const (
 		LEFT JOIN pg_depend d ON d.objid = c.oid AND d.deptype = 'a'
 		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
 		WHERE c.relkind = 'S'
+		-- Identity sequences are read as part of their column.
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_depend i WHERE i.objid = c.oid AND i.deptype = 'i'
+		)
 		AND %s
 		ORDER BY n.nspname, c.relname`
 
 	querySchemas = `
-		SELECT nspname
+		SELECT nspname, obj_description(oid, 'pg_namespace')
 		FROM pg_namespace
 		WHERE %s
 		ORDER BY nspname`
 
+	// queryGrants reads the privileges granted on tables, views and
+	// materialized views and on their columns, leaving out what the owner
+	// holds. MAINTAIN (PostgreSQL 17) is not managed.
+	queryGrants = `
+		SELECT
+			acl.schema_name,
+			acl.object_name,
+			acl.column_name,
+			CASE WHEN acl.grantee = 0 THEN 'public' ELSE pg_catalog.pg_get_userbyid(acl.grantee) END,
+			acl.is_grantable,
+			array_agg(acl.privilege_type ORDER BY acl.privilege_type)
+		FROM (
+			SELECT n.nspname AS schema_name, c.relname AS object_name, NULL::name AS column_name,
+				a.grantee, a.privilege_type, a.is_grantable, c.relowner
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			CROSS JOIN LATERAL aclexplode(c.relacl) a
+			WHERE c.relkind IN ('r', 'p', 'v', 'm') AND %[1]s
+			UNION ALL
+			SELECT n.nspname, c.relname, att.attname,
+				a.grantee, a.privilege_type, a.is_grantable, c.relowner
+			FROM pg_catalog.pg_attribute att
+			JOIN pg_catalog.pg_class c ON c.oid = att.attrelid
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			CROSS JOIN LATERAL aclexplode(att.attacl) a
+			WHERE c.relkind IN ('r', 'p', 'v', 'm')
+				AND att.attnum > 0
+				AND NOT att.attisdropped
+				AND %[1]s
+		) acl
+		WHERE acl.grantee <> acl.relowner AND acl.privilege_type <> 'MAINTAIN'
+		GROUP BY 1, 2, 3, 4, 5
+		ORDER BY 1, 2, 3 NULLS FIRST, 4, 5`
+
+	queryRoles = `
+		SELECT rolname
+		FROM pg_catalog.pg_roles
+		ORDER BY rolname`
+
 	queryHypertables = `
 		SELECT
 			h.hypertable_schema,
@@ -471,8 +643,12 @@ const (
 			WHERE hypertable_schema = $1 AND hypertable_name = $2
 		)`
 
-	querySpacePartitionColumns = `
-		SELECT column_name
+	queryDimensions = `
+		SELECT
+			column_name,
+			lower(dimension_type),
+			num_partitions,
+			COALESCE(time_interval::text, integer_interval::text)
 		FROM timescaledb_information.dimensions
 		WHERE hypertable_schema = $1 AND hypertable_name = $2
 		AND dimension_number > 1
@@ -490,6 +666,16 @@ const (
 		WHERE hypertable_schema = $1 AND hypertable_name = $2
 		GROUP BY hypertable_schema, hypertable_name`
 
+	queryCompressionPolicy = `
+		SELECT
+			config::json->>'compress_after',
+			schedule_interval::text
+		FROM timescaledb_information.jobs j
+		WHERE j.proc_name = 'policy_compression'
+		AND j.hypertable_schema = $1
+		AND j.hypertable_name = $2
+		LIMIT 1`
+
 	queryRetentionPolicy = `
 		SELECT
 			config::json->>'drop_after',
@@ -515,16 +701,20 @@ const (
 				'pg_class'
 			),
 			ca.materialization_hypertable_schema,
-			ca.materialization_hypertable_name
+			ca.materialization_hypertable_name,
+			ca.compression_enabled
 		FROM timescaledb_information.continuous_aggregates ca
 		WHERE %s
 		ORDER BY ca.view_schema, ca.view_name`
 
+	// queryRefreshPolicy reads initial_start through to_jsonb, since the jobs
+	// view only has the column from TimescaleDB 2.13.
 	queryRefreshPolicy = `
 		SELECT
 			config::json->>'start_offset',
 			config::json->>'end_offset',
-			schedule_interval::text
+			schedule_interval::text,
+			to_jsonb(j)->>'initial_start'
 		FROM timescaledb_information.jobs j
 		CROSS JOIN timescaledb_information.continuous_aggregates ca
 		WHERE j.proc_name = 'policy_refresh_continuous_aggregate'
@@ -571,6 +761,10 @@ func (qb *queryBuilder) schemasQuery() string {
 	return fmt.Sprintf(querySchemas, qb.namespaceFilter("nspname"))
 }
 
+func (qb *queryBuilder) grantsQuery() string {
+	return fmt.Sprintf(queryGrants, qb.namespaceFilter("n.nspname"))
+}
+
 func (qb *queryBuilder) continuousAggregatesQuery() string {
 	return fmt.Sprintf(queryContinuousAggregates, qb.namespaceFilter("ca.view_schema"))
 }
@@ -2,8 +2,8 @@ package extractor
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,20 +14,97 @@ import (
 )
 
 func (e *Extractor) extractTables(ctx context.Context) ([]schema.Table, error) {
-	query := e.queries.tablesQuery()
+	tables, oids, err := e.fetchTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tables) == 0 {
+		return tables, nil
+	}
 
-	var tables []schema.Table
+	var (
+		columns     map[uint32][]schema.Column
+		constraints map[uint32][]schema.Constraint
+		indexes     map[uint32][]schema.Index
+		partitions  map[uint32]*schema.PartitionStrategy
+		policies    map[uint32][]schema.Policy
+		dimensions  map[string][]string
+	)
 
-	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+	// Each detail is read for every table in one query, so the number of
+	// queries does not grow with the number of tables.
+	group, groupCtx := e.group(ctx)
+
+	group.Go(func() (err error) {
+		columns, err = e.extractColumns(groupCtx, oids)
+		return util.WrapError("extract columns", err)
+	})
+	group.Go(func() (err error) {
+		constraints, err = e.fetchConstraints(groupCtx, queryConstraints, oids)
+		return util.WrapError("extract constraints", err)
+	})
+	group.Go(func() (err error) {
+		indexes, err = e.fetchIndexes(groupCtx, queryIndexes, oids)
+		return util.WrapError("extract indexes", err)
+	})
+	group.Go(func() (err error) {
+		partitions, err = e.extractPartitionInfo(groupCtx, oids)
+		return util.WrapError("extract partition info", err)
+	})
+	group.Go(func() (err error) {
+		policies, err = e.extractPolicies(groupCtx, oids)
+		return util.WrapError("extract policies", err)
+	})
+
+	if e.hasTimescaleDB {
+		group.Go(func() (err error) {
+			dimensions, err = e.getHypertableDimensionColumns(groupCtx)
+			return util.WrapError("extract hypertable dimensions", err)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	for i := range tables {
+		table, oid := &tables[i], oids[i]
+
+		table.Columns = columns[oid]
+		table.Constraints = constraints[oid]
+		table.PartitionStrategy = partitions[oid]
+		table.Policies = policies[oid]
+
+		dimensionColumns := dimensions[table.QualifiedName()]
+		for _, idx := range indexes[oid] {
+			if !e.isTimescaleDBManagedIndex(idx.Name, table.Name, dimensionColumns) {
+				table.Indexes = append(table.Indexes, idx)
+			}
 		}
 
+		table.Sort()
+	}
+
+	return tables, nil
+}
+
+// fetchTables reads the tables without their details, and the oid of each.
+func (e *Extractor) fetchTables(ctx context.Context) ([]schema.Table, []uint32, error) {
+	query := e.queries.tablesQuery()
+
+	var (
+		tables []schema.Table
+		oids   []uint32
+	)
+
+	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
-		var table schema.Table
+		var (
+			table schema.Table
+			oid   uint32
+		)
 
 		if err := rows.Scan(
 			&table.Schema,
@@ -35,6 +112,9 @@ func (e *Extractor) extractTables(ctx context.Context) ([]schema.Table, error) {
 			scanner.String("comment"),
 			scanner.String("owner"),
 			scanner.String("tablespace"),
+			scanner.String("typed_of"),
+			&table.RowLevelSecurityEnabled,
+			&oid,
 		); err != nil {
 			return util.WrapError("scan table", err)
 		}
@@ -42,79 +122,39 @@ func (e *Extractor) extractTables(ctx context.Context) ([]schema.Table, error) {
 		table.Comment = scanner.GetString("comment")
 		table.Owner = scanner.GetString("owner")
 		table.Tablespace = scanner.GetString("tablespace")
+		table.TypedOf = scanner.GetString("typed_of")
 
 		tables = append(tables, table)
+		oids = append(oids, oid)
 
 		return nil
 	})
 	if err != nil {
-		return nil, util.WrapError("fetch tables", err)
-	}
-
-	for i := range tables {
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("before enriching table %s: %w", tables[i].QualifiedName(), err)
-		}
-
-		if err := e.enrichTable(ctx, &tables[i]); err != nil {
-			return nil, err
-		}
+		return nil, nil, util.WrapError("fetch tables", err)
 	}
 
-	return tables, nil
+	return tables, oids, nil
 }
 
-func (e *Extractor) enrichTable(ctx context.Context, table *schema.Table) error {
-	enrichers := []struct {
-		name string
-		fn   func(context.Context, *schema.Table) error
-	}{
-		{"extract columns", e.enrichColumns},
-		{"extract constraints", e.enrichConstraints},
-		{"extract indexes", e.enrichIndexes},
-		{"extract partition info", e.enrichPartitions},
-	}
-
-	for _, enricher := range enrichers {
-		if err := ctx.Err(); err != nil {
-			return fmt.Errorf("%s for table %s: %w", enricher.name, table.QualifiedName(), err)
-		}
-
-		if err := enricher.fn(ctx, table); err != nil {
-			return fmt.Errorf("%s for table %s: %w", enricher.name, table.QualifiedName(), err)
-		}
-	}
-
-	table.Sort()
-
-	return nil
-}
-
-func (e *Extractor) enrichColumns(ctx context.Context, table *schema.Table) error {
-	return e.extractColumns(ctx, table)
-}
-
-func (e *Extractor) enrichConstraints(ctx context.Context, table *schema.Table) error {
-	return e.extractConstraints(ctx, table)
-}
-
-func (e *Extractor) enrichIndexes(ctx context.Context, table *schema.Table) error {
-	return e.extractIndexes(ctx, table)
-}
-
-func (e *Extractor) enrichPartitions(ctx context.Context, table *schema.Table) error {
-	return e.extractPartitionInfo(ctx, table)
-}
-
-func (e *Extractor) extractColumns(ctx context.Context, table *schema.Table) error {
-	var columns []schema.Column
+// extractColumns reads the columns of the tables of oids by table oid.
+func (e *Extractor) extractColumns(
+	ctx context.Context,
+	oids []uint32,
+) (map[uint32][]schema.Column, error) {
+	columns := make(map[uint32][]schema.Column)
 
 	err := e.queryHelper.FetchAll(ctx, queryColumns, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
-		var col schema.Column
+		var (
+			oid        uint32
+			col        schema.Column
+			isIdentity bool
+			identity   schema.Sequence
+		)
 
 		if err := rows.Scan(
+			&oid,
 			&col.Name,
 			&col.DataType,
 			&col.IsNullable,
@@ -125,19 +165,32 @@ func (e *Extractor) extractColumns(ctx context.Context, table *schema.Table) err
 			scanner.Int32("numericPrecision"),
 			scanner.Int32("numericScale"),
 			scanner.String("udtName"),
-			&col.IsIdentity,
+			&isIdentity,
 			scanner.String("identityGen"),
+			&identity.StartValue,
+			&identity.MinValue,
+			&identity.MaxValue,
+			&identity.Increment,
+			&identity.CacheSize,
+			&identity.IsCyclic,
 			&col.IsGenerated,
 			scanner.String("generationExpr"),
 			scanner.String("fullType"),
+			scanner.String("storage"),
+			scanner.String("compression"),
 		); err != nil {
 			return util.WrapError("scan column", err)
 		}
 
 		col.Default = scanner.GetString("default")
 		col.Comment = scanner.GetString("comment")
-		col.IdentityGeneration = scanner.GetString("identityGen")
 		col.GenerationExpression = scanner.GetString("generationExpr")
+		col.Storage = scanner.GetString("storage")
+		col.Compression = scanner.GetString("compression")
+
+		if isIdentity {
+			col.Identity = schema.IdentityFromSequence(scanner.GetString("identityGen"), &identity)
+		}
 
 		if col.DataType == "USER-DEFINED" {
 			if fullType := scanner.GetString("fullType"); fullType != "" {
@@ -145,6 +198,12 @@ func (e *Extractor) extractColumns(ctx context.Context, table *schema.Table) err
 			}
 		}
 
+		// information_schema drops the precision and interval fields of time
+		// types, which format_type keeps.
+		if fullType := scanner.GetString("fullType"); schema.IsTimeType(fullType) {
+			col.DataType, col.Precision = schema.ParseTimeType(strings.TrimSuffix(fullType, "[]"))
+		}
+
 		if maxLength := scanner.GetInt("charMaxLength"); maxLength != nil {
 			col.MaxLength = maxLength
 		}
@@ -164,36 +223,44 @@ func (e *Extractor) extractColumns(ctx context.Context, table *schema.Table) err
 
 			elementType := extractArrayElementType(udtName)
 			if elementType != "" {
-				col.DataType = normalizeArrayElementType(elementType)
+				col.DataType = schema.CanonicalDataType(elementType)
 			}
 		}
 
-		columns = append(columns, col)
+		columns[oid] = append(columns[oid], col)
 
 		return nil
-	}, table.Schema, table.Name)
+	}, oids)
 	if err != nil {
-		return util.WrapError("fetch columns", err)
+		return nil, util.WrapError("fetch columns", err)
 	}
 
-	table.Columns = columns
-
-	return nil
+	return columns, nil
 }
 
-func (e *Extractor) extractConstraints(ctx context.Context, table *schema.Table) error {
-	var constraints []schema.Constraint
+// fetchConstraints reads the constraints query selects for the relations of
+// oids by relation oid.
+func (e *Extractor) fetchConstraints(
+	ctx context.Context,
+	query string,
+	oids []uint32,
+) (map[uint32][]schema.Constraint, error) {
+	constraints := make(map[uint32][]schema.Constraint)
 
-	err := e.queryHelper.FetchAll(ctx, queryConstraints, func(rows pgx.Rows) error {
+	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
 		var (
-			c              schema.Constraint
-			constraintType *string
-			refColumns     []string
+			oid              uint32
+			c                schema.Constraint
+			constraintType   *string
+			refColumns       []string
+			excludeElements  []string
+			excludeOperators []string
 		)
 
 		if err := rows.Scan(
+			&oid,
 			&c.Name,
 			&constraintType,
 			&c.Columns,
@@ -205,6 +272,11 @@ func (e *Extractor) extractConstraints(ctx context.Context, table *schema.Table)
 			scanner.String("onDelete"),
 			&c.IsDeferrable,
 			&c.InitiallyDeferred,
+			scanner.String("comment"),
+			scanner.String("excludeMethod"),
+			&excludeElements,
+			&excludeOperators,
+			scanner.String("excludeWhere"),
 		); err != nil {
 			return util.WrapError("scan constraint", err)
 		}
@@ -214,6 +286,7 @@ func (e *Extractor) extractConstraints(ctx context.Context, table *schema.Table)
 		}
 
 		c.Type = *constraintType
+		c.Comment = scanner.GetString("comment")
 
 		if c.Type == schema.ConstraintForeignKey {
 			c.ReferencedSchema = scanner.GetString("refSchema")
@@ -227,17 +300,50 @@ func (e *Extractor) extractConstraints(ctx context.Context, table *schema.Table)
 			c.CheckExpression = c.Definition
 		}
 
-		constraints = append(constraints, c)
+		// conkey only lists the key columns; the INCLUDE columns are read
+		// back from the definition.
+		if c.Type == schema.ConstraintPrimaryKey || c.Type == schema.ConstraintUnique ||
+			c.Type == schema.ConstraintExclude {
+			_, c.IncludeColumns = parseIndexDefinition(c.Definition)
+		}
+
+		if c.Type == schema.ConstraintExclude {
+			setExcludeParts(&c, scanner.GetString("excludeMethod"), excludeElements,
+				excludeOperators, scanner.GetString("excludeWhere"))
+		}
+
+		constraints[oid] = append(constraints[oid], c)
 
 		return nil
-	}, table.Schema, table.Name)
+	}, oids)
 	if err != nil {
-		return util.WrapError("fetch constraints", err)
+		return nil, util.WrapError("fetch constraints", err)
 	}
 
-	table.Constraints = constraints
+	return constraints, nil
+}
 
-	return nil
+// setExcludeParts records the index method, elements, operators and
+// predicate of the EXCLUDE constraint c, and renders its definition from
+// them as the parser does.
+func setExcludeParts(c *schema.Constraint, method string, elements, operators []string,
+	where string,
+) {
+	if method == "" || len(elements) == 0 || len(elements) != len(operators) {
+		return
+	}
+
+	c.ExcludeMethod = method
+	c.ExcludeWhere = where
+
+	for i, element := range elements {
+		c.ExcludeElements = append(c.ExcludeElements, schema.ExcludeElement{
+			Element:  element,
+			Operator: operators[i],
+		})
+	}
+
+	c.Definition = c.ExcludeDefinition()
 }
 
 func isNumericType(dataType string) bool {
@@ -253,36 +359,6 @@ func extractArrayElementType(udtName string) string {
 	return udtName[1:]
 }
 
-func normalizeArrayElementType(elementType string) string {
-	dt := strings.ToLower(strings.TrimSpace(elementType))
-
-	aliases := map[string]string{
-		"int":               "integer",
-		"int2":              "smallint",
-		"int4":              "integer",
-		"int8":              "bigint",
-		"float":             "double precision",
-		"float4":            "real",
-		"float8":            "double precision",
-		"serial":            "integer",
-		"bigserial":         "bigint",
-		"bool":              "boolean",
-		"character varying": "varchar",
-		"character":         "char",
-		"decimal":           "numeric",
-		"timestamp":         "timestamp without time zone",
-		"timestamptz":       "timestamp with time zone",
-		"time":              "time without time zone",
-		"timetz":            "time with time zone",
-	}
-
-	if normalized, exists := aliases[dt]; exists {
-		return normalized
-	}
-
-	return dt
-}
-
 func parseFullType(fullType string) (string, *int) {
 	openIdx := strings.Index(fullType, "(")
 	if openIdx == -1 {
@@ -304,84 +380,144 @@ func parseFullType(fullType string) (string, *int) {
 	return strings.ToUpper(fullType), nil
 }
 
-func (e *Extractor) extractPartitionInfo(ctx context.Context, table *schema.Table) error {
-	var (
-		partitionStrategy string
-		columns           []string
-	)
+// extractPartitionInfo reads the partitioning of the partitioned tables of
+// oids, with their partitions, by table oid.
+func (e *Extractor) extractPartitionInfo(
+	ctx context.Context,
+	oids []uint32,
+) (map[uint32]*schema.PartitionStrategy, error) {
+	strategies := make(map[uint32]*schema.PartitionStrategy)
 
-	err := e.queryHelper.FetchOne(ctx, queryPartitionInfo, func(row pgx.Row) error {
-		var start *string
-		if err := row.Scan(&start, &columns); err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return nil
-			}
+	err := e.queryHelper.FetchAll(ctx, queryPartitionInfo, func(rows pgx.Rows) error {
+		var (
+			oid     uint32
+			start   *string
+			columns []string
+		)
 
+		if err := rows.Scan(&oid, &start, &columns); err != nil {
 			return util.WrapError("scan partition info", err)
 		}
 
-		if start != nil && *start != "" {
+		var strategy string
+
+		if start != nil {
 			switch *start {
 			case "h":
-				partitionStrategy = "HASH"
+				strategy = "HASH"
 			case "r":
-				partitionStrategy = "RANGE"
+				strategy = "RANGE"
 			case "l":
-				partitionStrategy = "LIST"
+				strategy = "LIST"
 			}
 		}
 
-		return nil
-	}, table.Schema, table.Name)
-	if err != nil {
-		if err.Error() == "scan row: no rows in result set" {
-			return nil
+		if strategy != "" {
+			strategies[oid] = &schema.PartitionStrategy{Type: strategy, Columns: columns}
 		}
 
-		return util.WrapError("fetch partition info", err)
-	}
-
-	if partitionStrategy == "" {
 		return nil
+	}, oids)
+	if err != nil {
+		return nil, util.WrapError("fetch partition info", err)
 	}
 
-	table.PartitionStrategy = &schema.PartitionStrategy{
-		Type:    partitionStrategy,
-		Columns: columns,
+	if len(strategies) == 0 {
+		return strategies, nil
 	}
 
-	if err := e.extractPartitions(ctx, table); err != nil {
-		return util.WrapError("extract partitions", err)
+	if err := e.extractPartitions(ctx, strategies); err != nil {
+		return nil, util.WrapError("extract partitions", err)
 	}
 
-	return nil
+	return strategies, nil
 }
 
-func (e *Extractor) extractPartitions(ctx context.Context, table *schema.Table) error {
-	var partitions []schema.Partition
+// extractPartitions reads the partitions of the partitioned tables of
+// strategies into them.
+func (e *Extractor) extractPartitions(
+	ctx context.Context,
+	strategies map[uint32]*schema.PartitionStrategy,
+) error {
+	parents := slices.Sorted(maps.Keys(strategies))
+
+	var (
+		partitionOIDs []uint32
+		parentOIDs    []uint32
+		partitions    []schema.Partition
+	)
 
 	err := e.queryHelper.FetchAll(ctx, queryPartitions, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
-		var partition schema.Partition
+		var (
+			parent, oid uint32
+			partition   schema.Partition
+		)
 
-		if err := rows.Scan(&partition.Name, scanner.String("def")); err != nil {
+		if err := rows.Scan(&parent, &oid, &partition.Name, scanner.String("def")); err != nil {
 			return util.WrapError("scan partition", err)
 		}
 
 		partition.Definition = scanner.GetString("def")
 
+		parentOIDs = append(parentOIDs, parent)
+		partitionOIDs = append(partitionOIDs, oid)
 		partitions = append(partitions, partition)
 
 		return nil
-	}, table.Schema, table.Name)
+	}, parents)
 	if err != nil {
 		return util.WrapError("fetch partitions", err)
 	}
 
-	if table.PartitionStrategy != nil {
-		table.PartitionStrategy.Partitions = partitions
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	constraints, indexes, err := e.extractPartitionObjects(ctx, partitionOIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range partitions {
+		partitions[i].Constraints = constraints[partitionOIDs[i]]
+		partitions[i].Indexes = indexes[partitionOIDs[i]]
+
+		strategy := strategies[parentOIDs[i]]
+		strategy.Partitions = append(strategy.Partitions, partitions[i])
 	}
 
 	return nil
 }
+
+// extractPartitionObjects reads the constraints and indexes declared on the
+// partitions of oids themselves, by partition oid. Copies created from the
+// parent's constraints and indexes are left out; the parent's declaration
+// covers them.
+func (e *Extractor) extractPartitionObjects(
+	ctx context.Context,
+	oids []uint32,
+) (map[uint32][]schema.Constraint, map[uint32][]schema.Index, error) {
+	var (
+		constraints map[uint32][]schema.Constraint
+		indexes     map[uint32][]schema.Index
+	)
+
+	group, groupCtx := e.group(ctx)
+
+	group.Go(func() (err error) {
+		constraints, err = e.fetchConstraints(groupCtx, queryPartitionConstraints, oids)
+		return util.WrapError("extract constraints of partitions", err)
+	})
+	group.Go(func() (err error) {
+		indexes, err = e.fetchIndexes(groupCtx, queryPartitionIndexes, oids)
+		return util.WrapError("extract indexes of partitions", err)
+	})
+
+	if err := group.Wait(); err != nil {
+		return nil, nil, err //nolint:wrapcheck
+	}
+
+	return constraints, indexes, nil
+}
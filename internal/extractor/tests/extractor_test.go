@@ -0,0 +1,270 @@
+package extractor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/extractor"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// fakeCatalog answers the catalog queries of the extractor for a database of
+// tables tables, each with two columns, a primary key and an index, and
+// counts the queries sent to it.
+type fakeCatalog struct {
+	tables int
+	// block holds every query until its context is done, and failOn fails
+	// the queries containing it.
+	block  bool
+	failOn string
+
+	queries  atomic.Int32
+	inFlight atomic.Int32
+	started  chan struct{}
+}
+
+func (c *fakeCatalog) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.queries.Add(1)
+
+	if c.failOn != "" && strings.Contains(sql, c.failOn) {
+		return nil, errors.New("catalog unavailable")
+	}
+
+	if c.block {
+		c.inFlight.Add(1)
+		defer c.inFlight.Add(-1)
+
+		c.started <- struct{}{}
+		<-ctx.Done()
+
+		return nil, ctx.Err() //nolint:wrapcheck
+	}
+
+	return &fakeRows{rows: c.rows(sql, args)}, nil
+}
+
+func (c *fakeCatalog) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	c.queries.Add(1)
+
+	switch {
+	case strings.Contains(sql, "current_database()"):
+		return &fakeRows{rows: [][]any{{"app"}}}
+	case strings.Contains(sql, "extname = 'timescaledb'"):
+		return &fakeRows{rows: [][]any{{false}}}
+	default:
+		return &fakeRows{}
+	}
+}
+
+func (c *fakeCatalog) rows(sql string, args []any) [][]any {
+	var oids []uint32
+	if len(args) > 0 {
+		oids, _ = args[0].([]uint32)
+	}
+
+	var rows [][]any
+
+	switch {
+	case strings.Contains(sql, "information_schema.tables"):
+		for i := range c.tables {
+			rows = append(rows, []any{
+				"public", fmt.Sprintf("t%03d", i), nil, "app", nil, nil, false, uint32(1000 + i),
+			})
+		}
+	case strings.Contains(sql, "information_schema.columns"):
+		for _, oid := range oids {
+			for position, column := range []string{"id", "note"} {
+				rows = append(rows, []any{
+					oid, column, "text", position > 0, nil, nil, position + 1,
+					nil, nil, nil, "text", false, nil, 0, 0, 0, 0, 0, false,
+					false, nil, "text", nil, nil,
+				})
+			}
+		}
+	case strings.Contains(sql, "FROM pg_constraint con") && !strings.Contains(sql, "conparentid"):
+		for _, oid := range oids {
+			rows = append(rows, []any{
+				oid, fmt.Sprintf("pk_%d", oid), "PRIMARY KEY", []string{"id"}, "PRIMARY KEY (id)",
+				nil, nil, []string(nil), nil, nil, false, false, nil, nil,
+				[]string(nil), []string(nil), nil,
+			})
+		}
+	case strings.Contains(sql, "FROM pg_indexes i") && strings.Contains(sql, "ANY($1") &&
+		!strings.Contains(sql, "pg_inherits"):
+		for _, oid := range oids {
+			name := fmt.Sprintf("idx_%d", oid)
+			rows = append(rows, []any{
+				oid, "public", name, fmt.Sprintf("t%03d", oid-1000), false, false, "btree", nil,
+				fmt.Sprintf("CREATE INDEX %s ON public.t%03d USING btree (note)", name, oid-1000),
+				nil, false, nil, []string{"fillfactor=70"},
+			})
+		}
+	case strings.Contains(sql, "pg_policies"):
+		rows = append(rows, []any{
+			oids[0], "public", "tenant", "t000", "ALL", []string{"public"}, "true", nil, false,
+		})
+	}
+
+	return rows
+}
+
+// fakeRows returns rows of values, converting each to the type scanned into.
+type fakeRows struct {
+	rows [][]any
+	next int
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+
+	return r.next <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.next == 0 && !r.Next() {
+		return pgx.ErrNoRows
+	}
+
+	values := r.rows[r.next-1]
+	if len(values) != len(dest) {
+		return fmt.Errorf("%d values scanned into %d destinations", len(values), len(dest))
+	}
+
+	for i := range dest {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if values[i] == nil {
+			target.SetZero()
+			continue
+		}
+
+		value := reflect.ValueOf(values[i])
+		if target.Kind() == reflect.Pointer {
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(value.Convert(target.Type().Elem()))
+			target.Set(ptr)
+
+			continue
+		}
+
+		target.Set(value.Convert(target.Type()))
+	}
+
+	return nil
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func extractFake(t *testing.T, catalog *fakeCatalog, concurrency int) (int, error) {
+	t.Helper()
+
+	ext, err := extractor.NewFromQuerier(t.Context(), catalog,
+		extractor.Options{Concurrency: concurrency})
+	require.NoError(t, err)
+
+	catalog.queries.Store(0)
+
+	_, err = ext.Extract(t.Context())
+
+	return int(catalog.queries.Load()), err
+}
+
+func TestExtractQueriesDoNotGrowWithTables(t *testing.T) {
+	t.Parallel()
+
+	few, err := extractFake(t, &fakeCatalog{tables: 3}, 4)
+	require.NoError(t, err)
+
+	many, err := extractFake(t, &fakeCatalog{tables: 1200}, 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, few, many)
+	assert.Less(t, many, 25)
+}
+
+func TestExtractConcurrentMatchesSerial(t *testing.T) {
+	t.Parallel()
+
+	extract := func(concurrency int) *schema.Database {
+		ext, err := extractor.NewFromQuerier(t.Context(), &fakeCatalog{tables: 50},
+			extractor.Options{Concurrency: concurrency})
+		require.NoError(t, err)
+
+		db, err := ext.Extract(t.Context())
+		require.NoError(t, err)
+
+		db.ExtractedAt = ""
+
+		return db
+	}
+
+	db := extract(1)
+	assert.Equal(t, db, extract(8))
+
+	require.Len(t, db.Tables, 50)
+	assert.Equal(t, "t000", db.Tables[0].Name)
+	assert.Len(t, db.Tables[0].Columns, 2)
+	assert.Len(t, db.Tables[0].Constraints, 1)
+	assert.Len(t, db.Tables[0].Policies, 1)
+	assert.Empty(t, db.Tables[1].Policies)
+
+	require.Len(t, db.Tables[7].Indexes, 1)
+	assert.Equal(t, "idx_1007", db.Tables[7].Indexes[0].Name)
+	assert.Equal(t, map[string]string{"fillfactor": "70"}, db.Tables[7].Indexes[0].StorageParams)
+}
+
+func TestExtractCancelAbortsInFlightQueries(t *testing.T) {
+	t.Parallel()
+
+	catalog := &fakeCatalog{tables: 3, block: true, started: make(chan struct{}, 64)}
+
+	ext, err := extractor.NewFromQuerier(t.Context(), catalog, extractor.Options{Concurrency: 4})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	var (
+		wg         sync.WaitGroup
+		extractErr error
+	)
+
+	wg.Go(func() {
+		_, extractErr = ext.Extract(ctx)
+	})
+
+	for range 4 {
+		<-catalog.started
+	}
+
+	cancel()
+	wg.Wait()
+
+	require.ErrorIs(t, extractErr, context.Canceled)
+	assert.Zero(t, catalog.inFlight.Load())
+}
+
+func TestExtractReturnsQueryFailure(t *testing.T) {
+	t.Parallel()
+
+	_, err := extractFake(t, &fakeCatalog{tables: 3, failOn: "FROM pg_proc p"}, 4)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extract functions")
+	assert.Contains(t, err.Error(), "catalog unavailable")
+}
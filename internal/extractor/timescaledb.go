@@ -15,12 +15,6 @@ func (e *Extractor) extractHypertables(ctx context.Context) ([]schema.Hypertable
 	var hypertables []schema.Hypertable
 
 	err := e.queryHelper.FetchAll(ctx, queryHypertables, func(rows pgx.Rows) error {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
 		var ht schema.Hypertable
 
 		if err := rows.Scan(
@@ -34,10 +28,6 @@ func (e *Extractor) extractHypertables(ctx context.Context) ([]schema.Hypertable
 			return util.WrapError("scan hypertable", err)
 		}
 
-		if err := e.enrichHypertable(ctx, &ht); err != nil {
-			return util.WrapError("enrich hypertable "+ht.QualifiedTableName(), err)
-		}
-
 		hypertables = append(hypertables, ht)
 
 		return nil
@@ -46,6 +36,14 @@ func (e *Extractor) extractHypertables(ctx context.Context) ([]schema.Hypertable
 		return nil, util.WrapError("fetch hypertables", err)
 	}
 
+	// The rows are read before the details are queried, so no connection is
+	// held open while waiting for another.
+	for i := range hypertables {
+		if err := e.enrichHypertable(ctx, &hypertables[i]); err != nil {
+			return nil, util.WrapError("enrich hypertable "+hypertables[i].QualifiedTableName(), err)
+		}
+	}
+
 	return hypertables, nil
 }
 
@@ -54,7 +52,7 @@ func (e *Extractor) enrichHypertable(ctx context.Context, ht *schema.Hypertable)
 		return err
 	}
 
-	if err := e.enrichSpacePartitions(ctx, ht); err != nil {
+	if err := e.enrichDimensions(ctx, ht); err != nil {
 		return err
 	}
 
@@ -79,26 +77,30 @@ func (e *Extractor) enrichCompression(ctx context.Context, ht *schema.Hypertable
 		}
 
 		ht.CompressionSettings = compressionSettings
+
+		compressionPolicy, err := e.extractCompressionPolicy(ctx, ht.Schema, ht.TableName)
+		if err != nil {
+			return util.WrapError("extract compression policy", err)
+		}
+
+		ht.CompressionPolicy = compressionPolicy
 	}
 
 	return nil
 }
 
-func (e *Extractor) enrichSpacePartitions(ctx context.Context, ht *schema.Hypertable) error {
+func (e *Extractor) enrichDimensions(ctx context.Context, ht *schema.Hypertable) error {
 	if err := ctx.Err(); err != nil {
 		return err //nolint:wrapcheck
 	}
 
 	if ht.NumDimensions > 1 {
-		spaceColumns, err := e.extractSpacePartitionColumns(ctx, ht.Schema, ht.TableName)
+		dimensions, err := e.extractDimensions(ctx, ht.Schema, ht.TableName)
 		if err != nil {
-			return util.WrapError("extract space partition columns", err)
+			return util.WrapError("extract dimensions", err)
 		}
 
-		if len(spaceColumns) > 0 {
-			ht.SpacePartitions = len(spaceColumns)
-			ht.SpaceColumns = spaceColumns
-		}
+		ht.Dimensions = dimensions
 	}
 
 	return nil
@@ -123,35 +125,49 @@ func (e *Extractor) enrichRetentionPolicy(ctx context.Context, ht *schema.Hypert
 
 func (e *Extractor) isCompressionEnabled(ctx context.Context, schemaName, tableName string) bool {
 	var enabled bool
-	if err := e.pool.QueryRow(ctx, queryCompressionEnabled, schemaName, tableName).
-		Scan(&enabled); err != nil {
+	if err := e.queryHelper.FetchOne(ctx, queryCompressionEnabled, func(row pgx.Row) error {
+		return row.Scan(&enabled)
+	}, schemaName, tableName); err != nil {
 		return false
 	}
 
 	return enabled
 }
 
-func (e *Extractor) extractSpacePartitionColumns(
+func (e *Extractor) extractDimensions(
 	ctx context.Context,
 	schemaName, tableName string,
-) ([]string, error) {
-	var columns []string
+) ([]schema.Dimension, error) {
+	var dimensions []schema.Dimension
+
+	err := e.queryHelper.FetchAll(ctx, queryDimensions, func(rows pgx.Rows) error {
+		scanner := NewNullScanner()
+
+		var dim schema.Dimension
+		if err := rows.Scan(
+			&dim.ColumnName,
+			&dim.Type,
+			scanner.Int32("numPartitions"),
+			scanner.String("interval"),
+		); err != nil {
+			return util.WrapError("scan dimension", err)
+		}
 
-	err := e.queryHelper.FetchAll(ctx, querySpacePartitionColumns, func(rows pgx.Rows) error {
-		var column string
-		if err := rows.Scan(&column); err != nil {
-			return util.WrapError("scan space partition column", err)
+		if partitions := scanner.GetInt("numPartitions"); partitions != nil {
+			dim.NumberPartitions = *partitions
 		}
 
-		columns = append(columns, column)
+		dim.Interval = scanner.GetString("interval")
+
+		dimensions = append(dimensions, dim)
 
 		return nil
 	}, schemaName, tableName)
 	if err != nil {
-		return nil, util.WrapError("fetch space partition columns", err)
+		return nil, util.WrapError("fetch dimensions", err)
 	}
 
-	return columns, nil
+	return dimensions, nil
 }
 
 func (e *Extractor) extractCompressionSettings(
@@ -184,6 +200,34 @@ func (e *Extractor) extractCompressionSettings(
 	return settings, nil
 }
 
+func (e *Extractor) extractCompressionPolicy(
+	ctx context.Context,
+	schemaName, tableName string,
+) (*schema.CompressionPolicy, error) {
+	scanner := NewNullScanner()
+
+	policy := schema.CompressionPolicy{
+		HypertableSchema: schemaName,
+		HypertableName:   tableName,
+	}
+
+	err := e.queryHelper.FetchOne(ctx, queryCompressionPolicy, func(row pgx.Row) error {
+		return row.Scan(scanner.String("compressAfter"), scanner.String("scheduleInterval"))
+	}, schemaName, tableName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, util.WrapError("fetch compression policy", err)
+	}
+
+	policy.CompressAfter = scanner.GetString("compressAfter")
+	policy.ScheduleInterval = scanner.GetString("scheduleInterval")
+
+	return &policy, nil
+}
+
 func (e *Extractor) extractRetentionPolicy(
 	ctx context.Context,
 	schemaName, tableName string,
@@ -213,7 +257,10 @@ func (e *Extractor) extractContinuousAggregates(
 ) ([]schema.ContinuousAggregate, error) {
 	query := e.queries.continuousAggregatesQuery()
 
-	var aggregates []schema.ContinuousAggregate
+	var (
+		aggregates      []schema.ContinuousAggregate
+		materialization []relationName
+	)
 
 	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
@@ -236,6 +283,7 @@ func (e *Extractor) extractContinuousAggregates(
 			scanner.String("comment"),
 			&matHypertableSchema,
 			&matHypertableName,
+			&ca.CompressionEnabled,
 		); err != nil {
 			return util.WrapError("scan continuous aggregate", err)
 		}
@@ -248,19 +296,9 @@ func (e *Extractor) extractContinuousAggregates(
 
 		ca.Comment = scanner.GetString("comment")
 
-		refreshPolicy, err := e.extractRefreshPolicy(ctx, ca.Schema, ca.ViewName)
-		if err == nil && refreshPolicy != nil {
-			ca.RefreshPolicy = refreshPolicy
-		}
-
-		indexes, err := e.extractCAIndexes(
-			ctx, matHypertableSchema, matHypertableName, ca.Schema, ca.ViewName,
-		)
-		if err == nil {
-			ca.Indexes = indexes
-		}
-
 		aggregates = append(aggregates, ca)
+		materialization = append(materialization,
+			relationName{schema: matHypertableSchema, name: matHypertableName})
 
 		return nil
 	})
@@ -268,9 +306,56 @@ func (e *Extractor) extractContinuousAggregates(
 		return nil, util.WrapError("fetch continuous aggregates", err)
 	}
 
+	for i := range aggregates {
+		if err := e.enrichContinuousAggregate(ctx, &aggregates[i], &materialization[i]); err != nil {
+			return nil, err
+		}
+	}
+
 	return aggregates, nil
 }
 
+// relationName is the schema and name of a relation.
+type relationName struct {
+	schema, name string
+}
+
+// enrichContinuousAggregate reads the refresh and compression policies and
+// the indexes of ca, whose materialization hypertable is mat. A refresh
+// policy or indexes that cannot be read are left out; a compression policy
+// that cannot be read fails, since leaving it out would drop the job.
+func (e *Extractor) enrichContinuousAggregate(
+	ctx context.Context,
+	ca *schema.ContinuousAggregate,
+	mat *relationName,
+) error {
+	refreshPolicy, err := e.extractRefreshPolicy(ctx, ca.Schema, ca.ViewName)
+	if err == nil && refreshPolicy != nil {
+		ca.RefreshPolicy = refreshPolicy
+	}
+
+	if ca.CompressionEnabled {
+		// The compression job runs against the materialization hypertable.
+		policy, err := e.extractCompressionPolicy(ctx, mat.schema, mat.name)
+		if err != nil {
+			return util.WrapError("extract compression policy of "+ca.QualifiedViewName(), err)
+		}
+
+		if policy != nil {
+			policy.HypertableSchema = ca.Schema
+			policy.HypertableName = ca.ViewName
+			ca.CompressionPolicy = policy
+		}
+	}
+
+	indexes, err := e.extractCAIndexes(ctx, mat.schema, mat.name, ca.Schema, ca.ViewName)
+	if err == nil {
+		ca.Indexes = indexes
+	}
+
+	return nil
+}
+
 func (e *Extractor) extractRefreshPolicy(
 	ctx context.Context,
 	schemaName, viewName string,
@@ -284,6 +369,7 @@ func (e *Extractor) extractRefreshPolicy(
 			scanner.String("startOffset"),
 			scanner.String("endOffset"),
 			&policy.ScheduleInterval,
+			scanner.String("initialStart"),
 		)
 	}, schemaName, viewName)
 	if err != nil {
@@ -296,6 +382,7 @@ func (e *Extractor) extractRefreshPolicy(
 
 	policy.StartOffset = scanner.GetString("startOffset")
 	policy.EndOffset = scanner.GetString("endOffset")
+	policy.InitialStart = scanner.GetString("initialStart")
 
 	return &policy, nil
 }
@@ -306,22 +393,9 @@ func (e *Extractor) extractCAIndexes(
 ) ([]schema.Index, error) {
 	var indexes []schema.Index
 
-	err := e.queryHelper.FetchAll(ctx, queryIndexes, func(rows pgx.Rows) error {
-		scanner := NewNullScanner()
-
-		var idx schema.Index
-
-		if err := rows.Scan(
-			&idx.Schema,
-			&idx.Name,
-			&idx.TableName,
-			&idx.IsUnique,
-			&idx.IsPrimary,
-			&idx.Type,
-			scanner.String("where"),
-			&idx.Definition,
-			scanner.String("tablespace"),
-		); err != nil {
+	err := e.queryHelper.FetchAll(ctx, queryRelationIndexes, func(rows pgx.Rows) error {
+		_, idx, err := scanIndex(rows)
+		if err != nil {
 			return util.WrapError("scan CA index", err)
 		}
 
@@ -329,18 +403,6 @@ func (e *Extractor) extractCAIndexes(
 			return nil
 		}
 
-		idx.Where = scanner.GetString("where")
-		idx.Tablespace = scanner.GetString("tablespace")
-
-		columns, includeColumns := parseIndexDefinition(idx.Definition)
-		idx.Columns = columns
-		idx.IncludeColumns = includeColumns
-
-		storageParams, err := e.extractIndexStorageParams(ctx, idx.Schema, idx.Name)
-		if err == nil && len(storageParams) > 0 {
-			idx.StorageParams = storageParams
-		}
-
 		idx.TableName = viewName
 		idx.Definition = strings.Replace(
 			idx.Definition,
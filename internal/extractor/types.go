@@ -43,12 +43,18 @@ func (e *Extractor) extractExtensions(ctx context.Context) ([]schema.Extension,
 func (e *Extractor) extractCustomTypes(ctx context.Context) ([]schema.CustomType, error) {
 	query := e.queries.customTypesQuery()
 
-	var customTypes []schema.CustomType
+	var (
+		customTypes []schema.CustomType
+		oids        []uint32
+	)
 
 	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
-		var ct schema.CustomType
+		var (
+			ct  schema.CustomType
+			oid uint32
+		)
 
 		if err := rows.Scan(
 			&ct.Schema,
@@ -56,20 +62,15 @@ func (e *Extractor) extractCustomTypes(ctx context.Context) ([]schema.CustomType
 			&ct.Type,
 			scanner.String("comment"),
 			&ct.Definition,
+			&oid,
 		); err != nil {
 			return util.WrapError("scan custom type", err)
 		}
 
 		ct.Comment = scanner.GetString("comment")
 
-		if ct.Type == "enum" {
-			values, err := e.extractEnumValues(ctx, ct.Schema, ct.Name)
-			if err == nil {
-				ct.Values = values
-			}
-		}
-
 		customTypes = append(customTypes, ct)
+		oids = append(oids, oid)
 
 		return nil
 	})
@@ -77,25 +78,47 @@ func (e *Extractor) extractCustomTypes(ctx context.Context) ([]schema.CustomType
 		return nil, util.WrapError("fetch custom types", err)
 	}
 
+	if len(customTypes) == 0 {
+		return customTypes, nil
+	}
+
+	// Types whose values or attributes cannot be read are kept without them.
+	values, _ := e.extractEnumValues(ctx, oids)
+	attributes, _ := e.extractCompositeAttributes(ctx, oids)
+
+	for i := range customTypes {
+		switch customTypes[i].Type {
+		case "enum":
+			customTypes[i].Values = values[oids[i]]
+		case "composite":
+			customTypes[i].Attributes = attributes[oids[i]]
+		}
+	}
+
 	return customTypes, nil
 }
 
+// extractEnumValues reads the labels of the enums of oids by type oid.
 func (e *Extractor) extractEnumValues(
 	ctx context.Context,
-	schemaName, typeName string,
-) ([]string, error) {
-	var values []string
+	oids []uint32,
+) (map[uint32][]string, error) {
+	values := make(map[uint32][]string)
 
 	err := e.queryHelper.FetchAll(ctx, queryEnumValues, func(rows pgx.Rows) error {
-		var value string
-		if err := rows.Scan(&value); err != nil {
+		var (
+			oid   uint32
+			value string
+		)
+
+		if err := rows.Scan(&oid, &value); err != nil {
 			return util.WrapError("scan enum value", err)
 		}
 
-		values = append(values, value)
+		values[oid] = append(values[oid], value)
 
 		return nil
-	}, schemaName, typeName)
+	}, oids)
 	if err != nil {
 		return nil, util.WrapError("fetch enum values", err)
 	}
@@ -103,6 +126,35 @@ func (e *Extractor) extractEnumValues(
 	return values, nil
 }
 
+// extractCompositeAttributes reads the attributes of the composite types of
+// oids by type oid.
+func (e *Extractor) extractCompositeAttributes(
+	ctx context.Context,
+	oids []uint32,
+) (map[uint32][]schema.TypeAttribute, error) {
+	attributes := make(map[uint32][]schema.TypeAttribute)
+
+	err := e.queryHelper.FetchAll(ctx, queryCompositeAttributes, func(rows pgx.Rows) error {
+		var (
+			oid  uint32
+			attr schema.TypeAttribute
+		)
+
+		if err := rows.Scan(&oid, &attr.Name, &attr.DataType); err != nil {
+			return util.WrapError("scan composite attribute", err)
+		}
+
+		attributes[oid] = append(attributes[oid], attr)
+
+		return nil
+	}, oids)
+	if err != nil {
+		return nil, util.WrapError("fetch composite attributes", err)
+	}
+
+	return attributes, nil
+}
+
 func (e *Extractor) extractSequences(ctx context.Context) ([]schema.Sequence, error) {
 	query := e.queries.sequencesQuery()
 
@@ -132,10 +184,6 @@ func (e *Extractor) extractSequences(ctx context.Context) ([]schema.Sequence, er
 		seq.OwnedByTable = scanner.GetString("ownedByTable")
 		seq.OwnedByColumn = scanner.GetString("ownedByColumn")
 
-		if seq.OwnedByTable != "" && seq.OwnedByColumn != "" {
-			return nil
-		}
-
 		sequences = append(sequences, seq)
 
 		return nil
@@ -153,11 +201,15 @@ func (e *Extractor) extractSchemas(ctx context.Context) ([]schema.Schema, error)
 	var schemas []schema.Schema
 
 	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
+		scanner := NewNullScanner()
+
 		var sch schema.Schema
-		if err := rows.Scan(&sch.Name); err != nil {
+		if err := rows.Scan(&sch.Name, scanner.String("comment")); err != nil {
 			return util.WrapError("scan schema", err)
 		}
 
+		sch.Comment = scanner.GetString("comment")
+
 		schemas = append(schemas, sch)
 
 		return nil
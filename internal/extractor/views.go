@@ -17,7 +17,10 @@ func (e *Extractor) extractViews(ctx context.Context) ([]schema.View, error) {
 	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
-		var view schema.View
+		var (
+			view    schema.View
+			options []string
+		)
 
 		if err := rows.Scan(
 			&view.Schema,
@@ -27,6 +30,7 @@ func (e *Extractor) extractViews(ctx context.Context) ([]schema.View, error) {
 			scanner.String("owner"),
 			scanner.String("checkOption"),
 			&view.IsUpdatable,
+			&options,
 		); err != nil {
 			return util.WrapError("scan view", err)
 		}
@@ -35,6 +39,14 @@ func (e *Extractor) extractViews(ctx context.Context) ([]schema.View, error) {
 		view.Owner = scanner.GetString("owner")
 		view.CheckOption = scanner.GetString("checkOption")
 
+		// reloptions also holds check_option, already read from the view.
+		view.Options = storageParams(options)
+		delete(view.Options, "check_option")
+
+		if len(view.Options) == 0 {
+			view.Options = nil
+		}
+
 		views = append(views, view)
 
 		return nil
@@ -51,12 +63,19 @@ func (e *Extractor) extractMaterializedViews(
 ) ([]schema.MaterializedView, error) {
 	query := e.queries.materializedViewsQuery()
 
-	var matViews []schema.MaterializedView
+	var (
+		matViews []schema.MaterializedView
+		oids     []uint32
+	)
 
 	err := e.queryHelper.FetchAll(ctx, query, func(rows pgx.Rows) error {
 		scanner := NewNullScanner()
 
-		var mv schema.MaterializedView
+		var (
+			mv      schema.MaterializedView
+			options []string
+			oid     uint32
+		)
 
 		if err := rows.Scan(
 			&mv.Schema,
@@ -66,6 +85,8 @@ func (e *Extractor) extractMaterializedViews(
 			scanner.String("owner"),
 			scanner.String("tablespace"),
 			&mv.WithData,
+			&options,
+			&oid,
 		); err != nil {
 			return util.WrapError("scan materialized view", err)
 		}
@@ -74,13 +95,10 @@ func (e *Extractor) extractMaterializedViews(
 		mv.Owner = scanner.GetString("owner")
 		mv.Tablespace = scanner.GetString("tablespace")
 
-		table := &schema.Table{Schema: mv.Schema, Name: mv.Name}
-		if err := e.extractIndexes(ctx, table); err != nil {
-			return util.WrapError("extract indexes", err)
-		}
+		mv.StorageParams = storageParams(options)
 
-		mv.Indexes = table.Indexes
 		matViews = append(matViews, mv)
+		oids = append(oids, oid)
 
 		return nil
 	})
@@ -88,5 +106,18 @@ func (e *Extractor) extractMaterializedViews(
 		return nil, util.WrapError("fetch materialized views", err)
 	}
 
+	if len(matViews) == 0 {
+		return matViews, nil
+	}
+
+	indexes, err := e.fetchIndexes(ctx, queryIndexes, oids)
+	if err != nil {
+		return nil, util.WrapError("extract indexes", err)
+	}
+
+	for i := range matViews {
+		matViews[i].Indexes = indexes[oids[i]]
+	}
+
 	return matViews, nil
 }
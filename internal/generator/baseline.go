@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"slices"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// baselineDescription names the single migration Baseline writes.
+const baselineDescription = "baseline"
+
+// baselineBatch puts every change into one batch, in the order the batches of
+// groupChanges would run: schemas, extensions, then the objects of each
+// schema in dependency order. MaxOperationsPerFile and pinned migrations do
+// not split it.
+func (g *Generator) baselineBatch(changes []differ.Change) [][]differ.Change {
+	batches, _ := g.groupChanges(changes)
+
+	return [][]differ.Change{slices.Concat(batches...)}
+}
+
+// baselineDownStatements undoes a baseline by dropping every schema it
+// creates objects in with CASCADE, rather than dropping each object in turn.
+// The public schema is created again, empty, and the extensions the baseline
+// creates outside those schemas are dropped last.
+func baselineDownStatements(changes []differ.Change) []DDLStatement {
+	var (
+		schemas    []string
+		extensions []string
+	)
+
+	for i := range changes {
+		if name := string(extractSchema(&changes[i])); !slices.Contains(schemas, name) {
+			schemas = append(schemas, name)
+		}
+
+		if changes[i].Type == differ.ChangeTypeAddExtension {
+			extensions = append(extensions, changes[i].ObjectName)
+		}
+	}
+
+	statements := make([]DDLStatement, 0, len(schemas)+len(extensions)+1)
+
+	for _, name := range slices.Backward(schemas) {
+		statements = append(statements, DDLStatement{
+			SQL:         "DROP SCHEMA IF EXISTS " + QuoteIdentifier(name) + " CASCADE;",
+			Description: "Drop schema " + name + " and everything in it",
+			IsUnsafe:    true,
+		})
+
+		if name == schema.DefaultSchema {
+			statements = append(statements, DDLStatement{
+				SQL:         "CREATE SCHEMA " + QuoteIdentifier(name) + ";",
+				Description: "Recreate the empty " + name + " schema",
+			})
+		}
+	}
+
+	for _, name := range slices.Backward(extensions) {
+		statements = append(statements, DDLStatement{
+			SQL:         "DROP EXTENSION IF EXISTS " + QuoteIdentifier(name) + " CASCADE;",
+			Description: "Drop extension " + name,
+			IsUnsafe:    true,
+		})
+	}
+
+	return statements
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// CanonicalObject is one object of a schema rendered as the statements that
+// create it, written the way generated migrations write them.
+type CanonicalObject struct {
+	ObjectType string
+	ObjectName string
+	SQL        string
+}
+
+// Key identifies the object across two renderings.
+func (o CanonicalObject) Key() string {
+	return o.ObjectType + " " + o.ObjectName
+}
+
+// CanonicalKey is the Key of the rendered object a change applies to.
+func CanonicalKey(change differ.Change) string {
+	return canonicalObjectType(change.ObjectType) + " " + change.ObjectName
+}
+
+// RenderCanonical renders every object of db as the statements a migration
+// from an empty database would run for it. Statements that belong to the same
+// object, such as a table, its comments and its foreign keys, are rendered
+// together. Objects
+// are ordered by type and name so that two renderings line up.
+func RenderCanonical(ctx context.Context, db *schema.Database) ([]CanonicalObject, error) {
+	result, err := differ.New(differ.DefaultOptions()).CompareContext(ctx, &schema.Database{}, db)
+	if err != nil {
+		return nil, util.WrapError("compare with empty schema", err)
+	}
+
+	builder := NewDDLBuilder(result, false)
+	positions := make(map[string]int)
+
+	var objects []CanonicalObject
+
+	for _, change := range result.Changes {
+		stmt, err := builder.BuildUpStatement(change)
+		if err != nil {
+			return nil, util.WrapError("render "+change.ObjectName, err)
+		}
+
+		sql := strings.TrimSpace(stmt.SQL)
+		if sql == "" {
+			continue
+		}
+
+		object := CanonicalObject{
+			ObjectType: canonicalObjectType(change.ObjectType),
+			ObjectName: change.ObjectName,
+		}
+
+		if pos, ok := positions[object.Key()]; ok {
+			objects[pos].SQL += "\n\n" + sql
+			continue
+		}
+
+		object.SQL = sql
+		positions[object.Key()] = len(objects)
+		objects = append(objects, object)
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].ObjectType != objects[j].ObjectType {
+			return objects[i].ObjectType < objects[j].ObjectType
+		}
+
+		return objects[i].ObjectName < objects[j].ObjectName
+	})
+
+	return objects, nil
+}
+
+// canonicalObjectType folds the column and constraint statements of a table,
+// which name the table as their object, into the table itself.
+func canonicalObjectType(objectType string) string {
+	switch objectType {
+	case "column", "constraint":
+		return "table"
+	default:
+		return objectType
+	}
+}
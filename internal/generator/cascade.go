@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// cascadeKinds are the object kinds Options.CascadeDrops accepts besides name
+// patterns.
+//
+//nolint:gochecknoglobals
+var cascadeKinds = []string{
+	"table", "view", "materialized_view", "continuous_aggregate", "function",
+	"trigger", "type", "sequence", "schema", "extension",
+}
+
+// validateCascadeDrops rejects CascadeDrops entries that are neither a kind
+// nor a valid name pattern.
+func validateCascadeDrops(entries []string) []error {
+	var errs []error
+
+	for _, entry := range entries {
+		if slices.Contains(cascadeKinds, entry) {
+			continue
+		}
+
+		if _, err := path.Match(entry, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid cascade drop pattern %q: %w", entry, err))
+		}
+	}
+
+	return errs
+}
+
+// cascadesDrop reports whether the allowlist covers the object a change drops,
+// by its kind or by a pattern matching its qualified name. Function names are
+// matched without their argument types.
+func cascadesDrop(allowlist []string, change *differ.Change) bool {
+	name, _, _ := strings.Cut(strings.ToLower(change.ObjectName), "(")
+
+	for _, entry := range allowlist {
+		if entry == change.ObjectType {
+			return true
+		}
+
+		if matched, _ := path.Match(strings.ToLower(entry), name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cascade returns the CASCADE clause for a drop statement when the change's
+// object is allowlisted in Options.CascadeDrops, and nothing otherwise.
+func (b *DDLBuilder) cascade(change *differ.Change) string {
+	if cascadesDrop(b.cascadeDrops, change) {
+		return " CASCADE"
+	}
+
+	return ""
+}
+
+// DropDependentsError reports objects of the current schema that depend on
+// objects the plan drops without CASCADE, and that no change of the plan
+// drops or detaches first. Applying the migrations would fail at the drop.
+type DropDependentsError struct {
+	Dependents []differ.DropDependent
+}
+
+func (e *DropDependentsError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s of dropped objects not handled by the plan:",
+		util.Plural(len(e.Dependents), "dependent", "dependents"))
+
+	for _, dep := range e.Dependents {
+		b.WriteString("\n  " + dep.String())
+	}
+
+	b.WriteString("\ndrop or change them in the desired schema, or allow CASCADE for the " +
+		"dropped objects with CascadeDrops")
+
+	return b.String()
+}
+
+// Diagnostics returns a DROP_BLOCKED_BY_DEPENDENT error for each dependent.
+func (e *DropDependentsError) Diagnostics() []diag.Warning {
+	diagnostics := make([]diag.Warning, 0, len(e.Dependents))
+
+	for _, dep := range e.Dependents {
+		diagnostics = append(diagnostics, changeWarning(*dep.Drop,
+			diag.CodeDropBlockedByDependent, diag.SeverityError, dep.String()))
+	}
+
+	return diagnostics
+}
+
+// checkDropDependents returns a DropDependentsError for the dependents the
+// plan leaves on objects it drops without CASCADE.
+func (g *Generator) checkDropDependents(result *differ.DiffResult) error {
+	var blocked []differ.DropDependent
+
+	for _, dep := range result.UnhandledDropDependents() {
+		if !cascadesDrop(g.Options.CascadeDrops, dep.Drop) {
+			blocked = append(blocked, dep)
+		}
+	}
+
+	if len(blocked) == 0 {
+		return nil
+	}
+
+	return &DropDependentsError{Dependents: blocked}
+}
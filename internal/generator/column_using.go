@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+// textTypes are the string types every other type is read from by a cast of
+// its text, and written to by an assignment cast.
+var textTypes = []string{"text", "varchar", "char"} //nolint:gochecknoglobals
+
+// parsedFromText are the types a cast reads from the text of a string column.
+var parsedFromText = []string{ //nolint:gochecknoglobals
+	"uuid", "boolean", "smallint", "integer", "bigint", "numeric", "real",
+	"double precision", "date", "timestamp without time zone", "timestamp with time zone",
+	"time without time zone", "interval", "json", "jsonb", "inet", "cidr", "macaddr",
+}
+
+// usingCasts lists, by the type a column has, the types casting its values
+// converts them to. ALTER COLUMN ... TYPE gets USING column::type for these,
+// which PostgreSQL needs for the casts it only makes explicitly, such as
+// text to uuid, and spells out the others.
+var usingCasts = map[string][]string{ //nolint:gochecknoglobals
+	"text":                        parsedFromText,
+	"varchar":                     append([]string{"text"}, parsedFromText...),
+	"char":                        append([]string{"text", "varchar"}, parsedFromText...),
+	"smallint":                    {"integer", "bigint", "numeric"},
+	"integer":                     {"bigint", "numeric"},
+	"bigint":                      {"numeric"},
+	"real":                        {"double precision"},
+	"date":                        {"timestamp without time zone", "timestamp with time zone"},
+	"timestamp without time zone": {"timestamp with time zone"},
+	"json":                        {"jsonb"},
+}
+
+// numericTypes convert between each other with assignment casts.
+var numericTypes = []string{ //nolint:gochecknoglobals
+	"smallint", "integer", "bigint", "numeric", "real", "double precision",
+}
+
+// assignmentCasts lists the conversions outside numericTypes and to the
+// textTypes that PostgreSQL makes without a USING clause.
+var assignmentCasts = map[string][]string{ //nolint:gochecknoglobals
+	"timestamp with time zone":    {"timestamp without time zone", "date"},
+	"timestamp without time zone": {"date"},
+	"time without time zone":      {"time with time zone"},
+	"time with time zone":         {"time without time zone"},
+	"jsonb":                       {"json"},
+}
+
+// columnTypeUsing returns the USING clause that converts column from fromType
+// to toType: the expression given with pgtofu:using, a cast from usingCasts,
+// or "" when PostgreSQL converts the values by itself or fromType is not
+// known. needsUsing reports a conversion that has no cast PostgreSQL makes
+// without being told how.
+func columnTypeUsing(column, fromType, toType, expression string) (using string, needsUsing bool) {
+	if expression != "" {
+		return "USING " + expression, false
+	}
+
+	if fromType == "" {
+		return "", false
+	}
+
+	from, fromArray := baseColumnType(fromType)
+	to, toArray := baseColumnType(toType)
+
+	if fromArray != toArray {
+		return "", true
+	}
+
+	if slices.Contains(usingCasts[from], to) {
+		return fmt.Sprintf("USING %s::%s", QuoteIdentifier(column), toType), false
+	}
+
+	return "", !isImplicitConversion(from, to)
+}
+
+// isImplicitConversion reports whether ALTER COLUMN ... TYPE converts from to
+// to without a USING clause.
+func isImplicitConversion(from, to string) bool {
+	switch {
+	case from == to, slices.Contains(textTypes, to):
+		return true
+	case slices.Contains(numericTypes, from) && slices.Contains(numericTypes, to):
+		return true
+	}
+
+	return slices.Contains(assignmentCasts[from], to)
+}
+
+// baseColumnType returns the normalized name of a column type without its
+// modifiers, and whether it is an array type.
+func baseColumnType(dataType string) (string, bool) {
+	dataType = strings.TrimSpace(dataType)
+
+	isArray := strings.HasSuffix(dataType, "[]")
+	dataType = strings.TrimSpace(strings.TrimSuffix(dataType, "[]"))
+
+	if name, rest, ok := strings.Cut(dataType, "("); ok {
+		_, suffix, _ := strings.Cut(rest, ")")
+		dataType = strings.TrimSpace(name) + suffix
+	}
+
+	return differ.NormalizeDataType(dataType), isArray
+}
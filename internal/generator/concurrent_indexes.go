@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"maps"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// splitConcurrentIndexes moves each index added to or dropped from an existing
+// table into a batch of its own, marked to be built or dropped CONCURRENTLY.
+// Those statements cannot run inside a transaction, including the implicit one
+// PostgreSQL opens for a file sent as a single multi-statement query, so each
+// gets its own file while the changes around it stay transactional.
+func (g *Generator) splitConcurrentIndexes(
+	batches [][]differ.Change,
+	result *differ.DiffResult,
+) [][]differ.Change {
+	var split [][]differ.Change
+
+	for _, batch := range batches {
+		var segment []differ.Change
+
+		for _, change := range batch {
+			if !isConcurrentIndexCandidate(change, result) {
+				segment = append(segment, change)
+				continue
+			}
+
+			if len(segment) > 0 {
+				split = append(split, segment)
+				segment = nil
+			}
+
+			split = append(split, []differ.Change{withDetail(change, DetailKeyConcurrent, true)})
+		}
+
+		if len(segment) > 0 {
+			split = append(split, segment)
+		}
+	}
+
+	return split
+}
+
+// isConcurrentIndexCandidate reports whether change adds or drops an index of
+// a table that already exists. An index of a table created in the same plan
+// locks nothing worth avoiding, and PostgreSQL cannot build or drop indexes
+// of partitioned tables concurrently, nor TimescaleDB those of hypertables.
+func isConcurrentIndexCandidate(change differ.Change, result *differ.DiffResult) bool {
+	if change.Type != differ.ChangeTypeAddIndex && change.Type != differ.ChangeTypeDropIndex {
+		return false
+	}
+
+	index, err := getDetailIndex(change.Details)
+	if err != nil || index.OnlyParent || result.Current == nil {
+		return false
+	}
+
+	table := result.Current.GetTable(index.Schema, index.TableName)
+	if table == nil || table.PartitionStrategy != nil {
+		return false
+	}
+
+	builder := &DDLBuilder{result: result}
+	tableName := table.QualifiedName()
+
+	for _, db := range []*schema.Database{result.Current, result.Desired} {
+		if db != nil && builder.getHypertable(tableName, db) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isConcurrent(change differ.Change) bool {
+	concurrent, _ := change.Details[DetailKeyConcurrent.String()].(bool)
+	return concurrent
+}
+
+func withDetail(change differ.Change, key DetailKey, value any) differ.Change {
+	details := make(map[string]any, len(change.Details)+1)
+	maps.Copy(details, change.Details)
+	details[key.String()] = value
+	change.Details = details
+
+	return change
+}
@@ -4,6 +4,8 @@ const (
 	SQLIndent            = "    "
 	MigrationFilePattern = "%06d_%s.%s.sql"
 
+	GooseMigrationFilePattern = "%05d_%s.sql"
+
 	DefaultOutputDir     = "./migrations"
 	DefaultStartVersion  = 1
 	DefaultMaxOpsPerFile = 20
@@ -11,6 +13,10 @@ const (
 	DefaultDirMode       = 0o755
 )
 
+// DefaultSharedSubdirectory holds the migrations PartitionOutputBySchema does
+// not assign to a single schema.
+const DefaultSharedSubdirectory = "_global"
+
 type DetailKey string
 
 func (k DetailKey) String() string {
@@ -38,10 +44,93 @@ const (
 	DetailKeyTrigger       DetailKey = "trigger"
 	DetailKeySequence      DetailKey = "sequence"
 	DetailKeyCustomType    DetailKey = "custom_type"
+	DetailKeyTypeName      DetailKey = "type_name"
 	DetailKeyExtension     DetailKey = "extension"
 	DetailKeyHypertable    DetailKey = "hypertable"
 	DetailKeyOldDefinition DetailKey = "old_definition"
 	DetailKeyNewDefinition DetailKey = "new_definition"
 	DetailKeyCurrent       DetailKey = "current"
 	DetailKeyDesired       DetailKey = "desired"
+	DetailKeyUniqueStep    DetailKey = "unique_step"
+	DetailKeyValidateStep  DetailKey = "validate_step"
+	DetailKeyReason        DetailKey = "reason"
+	// DetailKeyEnabledStateOnly marks a trigger modification that only
+	// changes whether the trigger is enabled.
+	DetailKeyEnabledStateOnly DetailKey = "enabled_state_only"
+	// DetailKeyEnforceStart marks a sequence modification that also applies
+	// the desired START WITH.
+	DetailKeyEnforceStart DetailKey = "enforce_start"
+	// DetailKeyConstraintName names the constraint a constraint comment
+	// change applies to.
+	DetailKeyConstraintName DetailKey = "constraint_name"
+	// DetailKeySchemaName names the schema a schema comment change applies
+	// to.
+	DetailKeySchemaName DetailKey = "schema_name"
+	// DetailKeyRelocated marks a function modification that only moves the
+	// function to another schema or renames it.
+	DetailKeyRelocated DetailKey = "relocated"
+	// DetailKeyCommentChanged marks a relocation that also changes the
+	// function's comment.
+	DetailKeyCommentChanged DetailKey = "comment_changed"
+	// DetailKeyOldName and DetailKeyNewName are the names of a renamed column.
+	DetailKeyOldName DetailKey = "old_name"
+	DetailKeyNewName DetailKey = "new_name"
+	// DetailKeyOldStorage and DetailKeyNewStorage are the storage strategies
+	// of a column storage change.
+	DetailKeyOldStorage DetailKey = "old_storage"
+	DetailKeyNewStorage DetailKey = "new_storage"
+	// DetailKeyOldCompression and DetailKeyNewCompression are the compression
+	// methods of a column compression change; empty is the default method.
+	DetailKeyOldCompression DetailKey = "old_compression"
+	DetailKeyNewCompression DetailKey = "new_compression"
+	// DetailKeyOldColumn and DetailKeyNewColumn are the definitions of a
+	// column whose generation expression changes.
+	DetailKeyOldColumn DetailKey = "old_column"
+	DetailKeyNewColumn DetailKey = "new_column"
+	// DetailKeyOldStorageParams and DetailKeyNewStorageParams are the storage
+	// parameters of a materialized view storage change.
+	DetailKeyOldStorageParams DetailKey = "old_storage_params"
+	DetailKeyNewStorageParams DetailKey = "new_storage_params"
+	// DetailKeyOldViewOptions and DetailKeyNewViewOptions are the options of
+	// a view options change, check_option included.
+	DetailKeyOldViewOptions DetailKey = "old_view_options"
+	DetailKeyNewViewOptions DetailKey = "new_view_options"
+	// DetailKeyAttribute names the composite type attribute an attribute
+	// change applies to, and DetailKeyTypedTables the typed tables of the type
+	// it also changes.
+	DetailKeyAttribute   DetailKey = "attribute"
+	DetailKeyTypedTables DetailKey = "typed_tables"
+	// DetailKeyConcurrent marks an index change ConcurrentIndexes moved to a
+	// migration of its own, to be built or dropped CONCURRENTLY.
+	DetailKeyConcurrent DetailKey = "concurrent"
+	// DetailKeyDetach marks a dropped partition DetachPartitions detaches
+	// from its table rather than drops.
+	DetailKeyDetach DetailKey = "detach"
+	// DetailKeyPolicy is the policy an added or dropped policy change applies
+	// to, and DetailKeyEnabled whether a row security change enables it.
+	DetailKeyPolicy  DetailKey = "policy"
+	DetailKeyEnabled DetailKey = "enabled"
+	// DetailKeyOldOwnedBy and DetailKeyNewOwnedBy are the owning columns, as
+	// schema.table.column, of a sequence whose OWNED BY changes; empty is NONE.
+	DetailKeyOldOwnedBy DetailKey = "old_owned_by"
+	DetailKeyNewOwnedBy DetailKey = "new_owned_by"
+	// DetailKeyGrant is the grant a grant change gives or takes, and
+	// DetailKeyGrantOptionOnly marks one limited to its grant option.
+	DetailKeyGrant           DetailKey = "grant"
+	DetailKeyGrantOptionOnly DetailKey = "grant_option_only"
+)
+
+// Values for DetailKeyUniqueStep, set when SafeUniqueConstraints splits a new
+// UNIQUE constraint into a concurrent index build followed by its promotion.
+const (
+	UniqueStepBuildIndex = "build_index"
+	UniqueStepPromote    = "promote"
+)
+
+// Values for DetailKeyValidateStep, set when SafeConstraintMode splits a new
+// CHECK or foreign key constraint, or a column becoming NOT NULL, into a NOT
+// VALID constraint followed by its validation.
+const (
+	ValidateStepAddNotValid = "add_not_valid"
+	ValidateStepValidate    = "validate"
 )
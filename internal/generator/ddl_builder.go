@@ -12,6 +12,8 @@ type DDLBuilder struct {
 	idempotent bool
 	result     *differ.DiffResult
 	registry   *DDLBuilderRegistry
+	// cascadeDrops is the Options.CascadeDrops allowlist.
+	cascadeDrops []string
 }
 
 func NewDDLBuilder(result *differ.DiffResult, idempotent bool) *DDLBuilder {
@@ -23,11 +25,15 @@ func NewDDLBuilder(result *differ.DiffResult, idempotent bool) *DDLBuilder {
 }
 
 func (b *DDLBuilder) BuildUpStatement(change differ.Change) (DDLStatement, error) {
-	return b.registry.BuildUp(change, b)
+	stmt, err := b.registry.BuildUp(change, b)
+
+	return joinedStatement(stmt), err
 }
 
 func (b *DDLBuilder) BuildDownStatement(change differ.Change) (DDLStatement, error) {
-	return b.registry.BuildDown(change, b)
+	stmt, err := b.registry.BuildDown(change, b)
+
+	return joinedStatement(stmt), err
 }
 
 func (b *DDLBuilder) ifExists() string {
@@ -52,8 +58,8 @@ func (b *DDLBuilder) buildDropTableForDown(change differ.Change) (DDLStatement,
 		return DDLStatement{}, fmt.Errorf("table not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP TABLE %s%s CASCADE;",
-		b.ifExists(), QualifiedName(table.Schema, table.Name))
+	sql := fmt.Sprintf("DROP TABLE %s%s%s;",
+		b.ifExists(), QualifiedName(table.Schema, table.Name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -81,6 +87,16 @@ func (b *DDLBuilder) buildAddTableForDown(change differ.Change) (DDLStatement, e
 	var sb strings.Builder
 	appendStatement(&sb, tableSQL)
 
+	// The rows are gone with the dropped table, so there is nothing for the
+	// new sequences to catch up with; say so rather than resync them.
+	for i := range table.Columns {
+		if serialTypeFor(&table.Columns[i]) != "" {
+			fmt.Fprintf(&sb,
+				"\n-- The sequence of %s.%s restarts at 1; the dropped rows are not restored.",
+				table.Name, table.Columns[i].Name)
+		}
+	}
+
 	if table.PartitionStrategy != nil && len(table.PartitionStrategy.Partitions) > 0 {
 		for _, partition := range table.PartitionStrategy.Partitions {
 			if partition.Definition == "" {
@@ -123,6 +139,13 @@ func (b *DDLBuilder) buildAddTableForDown(change differ.Change) (DDLStatement, e
 		}
 	}
 
+	for i := range table.Constraints {
+		if constraint := &table.Constraints[i]; constraint.Comment != "" {
+			appendStatement(&sb, buildConstraintCommentStatement(
+				QualifiedName(table.Schema, table.Name), constraint.Name, constraint.Comment))
+		}
+	}
+
 	return DDLStatement{
 		SQL:         sb.String(),
 		Description: "Add table " + table.Name,
@@ -142,6 +165,10 @@ func (b *DDLBuilder) appendHypertableSQL(sb *strings.Builder, ht *schema.Hyperta
 
 	appendStatement(sb, hypertableSQL)
 
+	if err := appendDimensions(sb, ht); err != nil {
+		return err
+	}
+
 	if ht.CompressionEnabled && ht.CompressionSettings != nil {
 		compressionSQL, err := formatCompressionPolicy(ht)
 		if err != nil {
@@ -207,8 +234,54 @@ func (b *DDLBuilder) getSequence(name string, db *schema.Database) *schema.Seque
 	return nil
 }
 
+// getChangedSequence looks the sequence of an add or drop change up in db and
+// falls back to the copy in the change details. The down migration of either
+// change runs against the side that does not have the sequence.
+func (b *DDLBuilder) getChangedSequence(
+	change differ.Change,
+	db *schema.Database,
+) *schema.Sequence {
+	if seq := b.getSequence(change.ObjectName, db); seq != nil {
+		return seq
+	}
+
+	if seq, ok := change.Details[DetailKeySequence.String()].(schema.Sequence); ok {
+		return &seq
+	}
+
+	return nil
+}
+
+// getTable looks name up among db's tables and then among their partitions.
+// A partition is returned as a table with its parent's columns and its own
+// local constraints and indexes.
 func (b *DDLBuilder) getTable(name string, db *schema.Database) *schema.Table {
-	return db.GetTable(parseSchemaAndName(name))
+	schemaName, tableName := parseSchemaAndName(name)
+	if table := db.GetTable(schemaName, tableName); table != nil {
+		return table
+	}
+
+	for i := range db.Tables {
+		parent := &db.Tables[i]
+		if parent.PartitionStrategy == nil ||
+			schema.NormalizeSchemaName(parent.Schema) != schema.NormalizeSchemaName(schemaName) {
+			continue
+		}
+
+		for _, partition := range parent.PartitionStrategy.Partitions {
+			if schema.NormalizeIdentifier(partition.Name) == schema.NormalizeIdentifier(tableName) {
+				return &schema.Table{
+					Schema:      parent.Schema,
+					Name:        partition.Name,
+					Columns:     parent.Columns,
+					Constraints: partition.Constraints,
+					Indexes:     partition.Indexes,
+				}
+			}
+		}
+	}
+
+	return nil
 }
 
 func (b *DDLBuilder) getView(name string, db *schema.Database) *schema.View {
@@ -248,7 +321,7 @@ func (b *DDLBuilder) getFunction(name string, db *schema.Database) *schema.Funct
 	for i := range db.Functions {
 		fn := &db.Functions[i]
 
-		key := differ.FunctionKey(fn.Schema, fn.Name, fn.ArgumentTypes)
+		key := differ.RoutineKey(fn)
 		if key == name {
 			return fn
 		}
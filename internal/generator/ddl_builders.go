@@ -1,7 +1,10 @@
 package generator
 
 import (
+	"fmt"
+
 	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 type schemaBuilder struct{}
@@ -10,22 +13,28 @@ func (b *schemaBuilder) BuildUp(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddSchema {
+	switch change.Type {
+	case differ.ChangeTypeAddSchema:
 		return ddlBuilder.buildAddSchema(change)
+	case differ.ChangeTypeModifySchemaComment:
+		return ddlBuilder.buildSchemaComment(change, DetailKeyNewComment, "Modify")
+	default:
+		return ddlBuilder.buildDropSchema(change)
 	}
-
-	return ddlBuilder.buildDropSchema(change)
 }
 
 func (b *schemaBuilder) BuildDown(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddSchema {
+	switch change.Type {
+	case differ.ChangeTypeAddSchema:
 		return ddlBuilder.buildDropSchema(change)
+	case differ.ChangeTypeModifySchemaComment:
+		return ddlBuilder.buildSchemaComment(change, DetailKeyOldComment, "Revert")
+	default:
+		return ddlBuilder.buildAddSchema(change)
 	}
-
-	return ddlBuilder.buildAddSchema(change)
 }
 
 type extensionBuilder struct{}
@@ -58,28 +67,62 @@ func (b *extensionBuilder) BuildDown(
 	return ddlBuilder.buildAddExtension(change)
 }
 
+func (b *extensionBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	if change.Type == differ.ChangeTypeDropExtension {
+		return ReversibilityStructureOnly,
+			fmt.Sprintf("objects and data owned by extension %s are not restored", change.ObjectName)
+	}
+
+	return ReversibilityFull, ""
+}
+
 type customTypeBuilder struct{}
 
 func (b *customTypeBuilder) BuildUp(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddCustomType {
+	switch change.Type {
+	case differ.ChangeTypeAddCustomType:
 		return ddlBuilder.buildAddCustomType(change)
+	case differ.ChangeTypeModifyCustomTypeComment:
+		return ddlBuilder.buildCustomTypeComment(change, DetailKeyNewComment, "Modify")
+	case differ.ChangeTypeModifyCustomType:
+		return ddlBuilder.buildTypeAttributeChange(
+			change, DetailKeyOldType, DetailKeyNewType, "Modify")
+	default:
+		return ddlBuilder.buildDropCustomType(change)
 	}
-
-	return ddlBuilder.buildDropCustomType(change)
 }
 
 func (b *customTypeBuilder) BuildDown(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddCustomType {
+	switch change.Type {
+	case differ.ChangeTypeAddCustomType:
 		return ddlBuilder.buildDropCustomType(change)
+	case differ.ChangeTypeModifyCustomTypeComment:
+		return ddlBuilder.buildCustomTypeComment(change, DetailKeyOldComment, "Revert")
+	case differ.ChangeTypeModifyCustomType:
+		return ddlBuilder.buildTypeAttributeChange(
+			change, DetailKeyNewType, DetailKeyOldType, "Revert")
+	default:
+		return ddlBuilder.buildAddCustomType(change)
 	}
+}
+
+func (b *customTypeBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	attribute, _ := change.Details[DetailKeyAttribute.String()].(string)
+	oldType, _ := change.Details[DetailKeyOldType.String()].(string)
 
-	return ddlBuilder.buildAddCustomType(change)
+	if change.Type == differ.ChangeTypeModifyCustomType && attribute != "" && oldType != "" {
+		return ReversibilityStructureOnly, fmt.Sprintf(
+			"values of attribute %s of type %s, and of the columns of its typed tables, "+
+				"are not restored", attribute, change.ObjectName)
+	}
+
+	return ReversibilityFull, ""
 }
 
 type sequenceBuilder struct{}
@@ -88,8 +131,11 @@ func (b *sequenceBuilder) BuildUp(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddSequence {
+	switch change.Type {
+	case differ.ChangeTypeAddSequence:
 		return ddlBuilder.buildAddSequence(change)
+	case differ.ChangeTypeModifySequence:
+		return ddlBuilder.buildModifySequence(change)
 	}
 
 	return ddlBuilder.buildDropSequence(change)
@@ -99,13 +145,25 @@ func (b *sequenceBuilder) BuildDown(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddSequence {
+	switch change.Type {
+	case differ.ChangeTypeAddSequence:
 		return ddlBuilder.buildDropSequence(change)
+	case differ.ChangeTypeModifySequence:
+		return ddlBuilder.buildReverseModifySequence(change)
 	}
 
 	return ddlBuilder.buildAddSequence(change)
 }
 
+func (b *sequenceBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	if change.Type == differ.ChangeTypeDropSequence {
+		return ReversibilityStructureOnly,
+			fmt.Sprintf("current value of dropped sequence %s is not restored", change.ObjectName)
+	}
+
+	return ReversibilityFull, ""
+}
+
 type tableBuilder struct{}
 
 func (b *tableBuilder) BuildUp(change differ.Change, ddlBuilder *DDLBuilder) (DDLStatement, error) {
@@ -137,6 +195,36 @@ func (b *tableBuilder) BuildDown(
 	}
 }
 
+func (b *tableBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	if change.Type == differ.ChangeTypeDropTable {
+		return ReversibilityStructureOnly,
+			fmt.Sprintf("data in dropped table %s is not recoverable", change.ObjectName)
+	}
+
+	return ReversibilityFull, ""
+}
+
+type recreateTableBuilder struct{}
+
+func (b *recreateTableBuilder) BuildUp(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	return ddlBuilder.buildRecreateTable(change)
+}
+
+func (b *recreateTableBuilder) BuildDown(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	return ddlBuilder.buildReverseRecreateTable(change)
+}
+
+func (b *recreateTableBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	return ReversibilityManual,
+		fmt.Sprintf("recreation of table %s must be reverted manually", change.ObjectName)
+}
+
 type columnBuilder struct{}
 
 func (b *columnBuilder) BuildUp(
@@ -156,6 +244,18 @@ func (b *columnBuilder) BuildUp(
 		return ddlBuilder.buildModifyColumnDefault(change)
 	case differ.ChangeTypeModifyColumnComment:
 		return ddlBuilder.buildModifyColumnComment(change)
+	case differ.ChangeTypeModifyColumnStorage:
+		return ddlBuilder.buildModifyColumnStorage(change)
+	case differ.ChangeTypeModifyColumnCompression:
+		return ddlBuilder.buildModifyColumnCompression(change)
+	case differ.ChangeTypeModifyColumnGeneration:
+		return ddlBuilder.buildModifyColumnGeneration(change)
+	case differ.ChangeTypeAddColumnIdentity,
+		differ.ChangeTypeDropColumnIdentity,
+		differ.ChangeTypeModifyColumnIdentity:
+		return ddlBuilder.buildModifyColumnIdentity(change)
+	case differ.ChangeTypeRenameColumn:
+		return ddlBuilder.buildRenameColumn(change, DetailKeyOldName, DetailKeyNewName, "Rename")
 	default:
 		return ddlBuilder.buildAddColumn(change)
 	}
@@ -178,11 +278,66 @@ func (b *columnBuilder) BuildDown(
 		return ddlBuilder.buildReverseModifyColumnDefault(change)
 	case differ.ChangeTypeModifyColumnComment:
 		return ddlBuilder.buildReverseModifyColumnComment(change)
+	case differ.ChangeTypeModifyColumnStorage:
+		return ddlBuilder.buildReverseModifyColumnStorage(change)
+	case differ.ChangeTypeModifyColumnCompression:
+		return ddlBuilder.buildReverseModifyColumnCompression(change)
+	case differ.ChangeTypeModifyColumnGeneration:
+		return ddlBuilder.buildReverseModifyColumnGeneration(change)
+	case differ.ChangeTypeAddColumnIdentity,
+		differ.ChangeTypeDropColumnIdentity,
+		differ.ChangeTypeModifyColumnIdentity:
+		return ddlBuilder.buildReverseModifyColumnIdentity(change)
+	case differ.ChangeTypeRenameColumn:
+		return ddlBuilder.buildRenameColumn(change, DetailKeyNewName, DetailKeyOldName, "Revert")
 	default:
 		return ddlBuilder.buildDropColumn(change)
 	}
 }
 
+func (b *columnBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	table, _ := change.Details["table"].(string)
+	if table == "" {
+		table = change.ObjectName
+	}
+
+	switch change.Type {
+	case differ.ChangeTypeDropColumn:
+		name := "?"
+		if col, ok := change.Details["column"].(*schema.Column); ok && col != nil {
+			name = col.Name
+		}
+
+		return ReversibilityStructureOnly,
+			fmt.Sprintf("data in dropped column %s.%s is not recoverable", table, name)
+	case differ.ChangeTypeModifyColumnType:
+		if change.Severity == differ.SeveritySafe {
+			return ReversibilityFull, ""
+		}
+
+		columnName, _ := change.Details["column_name"].(string)
+		oldType, _ := change.Details["old_type"].(string)
+		newType, _ := change.Details["new_type"].(string)
+
+		return ReversibilityStructureOnly, fmt.Sprintf(
+			"values in %s.%s converted from %s to %s may not convert back losslessly",
+			table, columnName, oldType, newType,
+		)
+	case differ.ChangeTypeModifyColumnGeneration:
+		old, _ := change.Details[DetailKeyOldColumn.String()].(*schema.Column)
+		if old == nil || old.IsGenerated {
+			return ReversibilityFull, ""
+		}
+
+		return ReversibilityStructureOnly, fmt.Sprintf(
+			"values of %s.%s from before it was generated are not recoverable",
+			table, old.Name,
+		)
+	default:
+		return ReversibilityFull, ""
+	}
+}
+
 type constraintBuilder struct{}
 
 func (b *constraintBuilder) BuildUp(
@@ -194,6 +349,8 @@ func (b *constraintBuilder) BuildUp(
 		return ddlBuilder.buildAddConstraint(change)
 	case differ.ChangeTypeModifyConstraint:
 		return ddlBuilder.buildModifyConstraint(change)
+	case differ.ChangeTypeModifyConstraintComment:
+		return ddlBuilder.buildConstraintComment(change, DetailKeyNewComment, "Modify")
 	default:
 		return ddlBuilder.buildDropConstraint(change)
 	}
@@ -208,6 +365,8 @@ func (b *constraintBuilder) BuildDown(
 		return ddlBuilder.buildDropConstraint(change)
 	case differ.ChangeTypeModifyConstraint:
 		return ddlBuilder.buildReverseModifyConstraint(change)
+	case differ.ChangeTypeModifyConstraintComment:
+		return ddlBuilder.buildConstraintComment(change, DetailKeyOldComment, "Revert")
 	default:
 		return ddlBuilder.buildAddConstraint(change)
 	}
@@ -221,6 +380,8 @@ func (b *indexBuilder) BuildUp(change differ.Change, ddlBuilder *DDLBuilder) (DD
 		return ddlBuilder.buildAddIndex(change)
 	case differ.ChangeTypeModifyIndex:
 		return ddlBuilder.buildModifyIndex(change)
+	case differ.ChangeTypeModifyIndexComment:
+		return ddlBuilder.buildIndexComment(change, DetailKeyNewComment, "Modify")
 	default:
 		return ddlBuilder.buildDropIndex(change)
 	}
@@ -235,6 +396,8 @@ func (b *indexBuilder) BuildDown(
 		return ddlBuilder.buildDropIndex(change)
 	case differ.ChangeTypeModifyIndex:
 		return ddlBuilder.buildReverseModifyIndex(change)
+	case differ.ChangeTypeModifyIndexComment:
+		return ddlBuilder.buildIndexComment(change, DetailKeyOldComment, "Revert")
 	default:
 		return ddlBuilder.buildAddIndex(change)
 	}
@@ -246,22 +409,41 @@ func (b *partitionBuilder) BuildUp(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddPartition {
+	switch {
+	case change.Type == differ.ChangeTypeAddPartition && isDetach(change):
+		return ddlBuilder.buildAttachPartition(change)
+	case change.Type == differ.ChangeTypeAddPartition:
 		return ddlBuilder.buildAddPartition(change)
+	case isDetach(change):
+		return ddlBuilder.buildDetachPartition(change)
+	default:
+		return ddlBuilder.buildDropPartition(change)
 	}
-
-	return ddlBuilder.buildDropPartition(change)
 }
 
 func (b *partitionBuilder) BuildDown(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
-	if change.Type == differ.ChangeTypeAddPartition {
+	switch {
+	case change.Type == differ.ChangeTypeAddPartition && isDetach(change):
+		return ddlBuilder.buildDetachPartition(change)
+	case change.Type == differ.ChangeTypeAddPartition:
 		return ddlBuilder.buildDropPartition(change)
+	case isDetach(change):
+		return ddlBuilder.buildAttachPartition(change)
+	default:
+		return ddlBuilder.buildAddPartition(change)
+	}
+}
+
+func (b *partitionBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	if change.Type == differ.ChangeTypeDropPartition && !isDetach(change) {
+		return ReversibilityStructureOnly,
+			fmt.Sprintf("data in dropped partition %s is not recoverable", change.ObjectName)
 	}
 
-	return ddlBuilder.buildAddPartition(change)
+	return ReversibilityFull, ""
 }
 
 type viewBuilder struct{}
@@ -374,6 +556,8 @@ func (b *triggerBuilder) BuildUp(
 		return ddlBuilder.buildAddTrigger(change)
 	case differ.ChangeTypeModifyTrigger:
 		return ddlBuilder.buildModifyTrigger(change)
+	case differ.ChangeTypeModifyTriggerComment:
+		return ddlBuilder.buildTriggerComment(change, DetailKeyNewComment, "Modify")
 	default:
 		return ddlBuilder.buildDropTrigger(change)
 	}
@@ -388,11 +572,83 @@ func (b *triggerBuilder) BuildDown(
 		return ddlBuilder.buildDropTrigger(change)
 	case differ.ChangeTypeModifyTrigger:
 		return ddlBuilder.buildReverseModifyTrigger(change)
+	case differ.ChangeTypeModifyTriggerComment:
+		return ddlBuilder.buildTriggerComment(change, DetailKeyOldComment, "Revert")
 	default:
 		return ddlBuilder.buildAddTrigger(change)
 	}
 }
 
+type policyBuilder struct{}
+
+func (b *policyBuilder) BuildUp(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	switch change.Type {
+	case differ.ChangeTypeAddPolicy:
+		return ddlBuilder.buildAddPolicy(change)
+	case differ.ChangeTypeModifyPolicy:
+		return ddlBuilder.buildModifyPolicy(change, false)
+	default:
+		return ddlBuilder.buildDropPolicy(change)
+	}
+}
+
+func (b *policyBuilder) BuildDown(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	switch change.Type {
+	case differ.ChangeTypeAddPolicy:
+		return ddlBuilder.buildDropPolicy(change)
+	case differ.ChangeTypeModifyPolicy:
+		return ddlBuilder.buildModifyPolicy(change, true)
+	default:
+		return ddlBuilder.buildAddPolicy(change)
+	}
+}
+
+type grantBuilder struct{}
+
+func (b *grantBuilder) BuildUp(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	if change.Type == differ.ChangeTypeRevokeGrant {
+		return ddlBuilder.buildRevokeGrant(change)
+	}
+
+	return ddlBuilder.buildAddGrant(change)
+}
+
+func (b *grantBuilder) BuildDown(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	if change.Type == differ.ChangeTypeRevokeGrant {
+		return ddlBuilder.buildAddGrant(change)
+	}
+
+	return ddlBuilder.buildRevokeGrant(change)
+}
+
+type rowSecurityBuilder struct{}
+
+func (b *rowSecurityBuilder) BuildUp(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	return ddlBuilder.buildModifyRowSecurity(change, false)
+}
+
+func (b *rowSecurityBuilder) BuildDown(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	return ddlBuilder.buildModifyRowSecurity(change, true)
+}
+
 type hypertableBuilder struct{}
 
 func (b *hypertableBuilder) BuildUp(
@@ -400,7 +656,7 @@ func (b *hypertableBuilder) BuildUp(
 	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
 	if change.Type == differ.ChangeTypeAddHypertable {
-		return ddlBuilder.buildAddHypertable(change)
+		return ddlBuilder.buildAddHypertable(change, false)
 	}
 
 	return ddlBuilder.buildDropHypertable(change)
@@ -414,7 +670,41 @@ func (b *hypertableBuilder) BuildDown(
 		return ddlBuilder.buildDropHypertable(change)
 	}
 
-	return ddlBuilder.buildAddHypertable(change)
+	return ddlBuilder.buildAddHypertable(change, true)
+}
+
+func (b *hypertableBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	return ReversibilityManual,
+		fmt.Sprintf("hypertable conversion of %s must be reverted manually", change.ObjectName)
+}
+
+type dimensionBuilder struct{}
+
+func (b *dimensionBuilder) BuildUp(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	if change.Type == differ.ChangeTypeAddDimension {
+		return ddlBuilder.buildAddDimension(change)
+	}
+
+	return ddlBuilder.buildManualDimensionChange(change)
+}
+
+func (b *dimensionBuilder) BuildDown(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	if change.Type == differ.ChangeTypeDropDimension {
+		return ddlBuilder.buildAddDimension(change)
+	}
+
+	return ddlBuilder.buildManualDimensionChange(change)
+}
+
+func (b *dimensionBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	return ReversibilityManual,
+		fmt.Sprintf("dimensions of hypertable %s must be restored manually", change.ObjectName)
 }
 
 type timescalePolicyBuilder struct{}
@@ -430,10 +720,17 @@ func (b *timescalePolicyBuilder) BuildUp(
 		return ddlBuilder.buildDropCompressionPolicy(change)
 	case differ.ChangeTypeModifyCompressionPolicy:
 		return ddlBuilder.buildModifyCompressionPolicy(change)
+	case differ.ChangeTypeModifyCompressionSchedule:
+		return ddlBuilder.buildModifyCompressionSchedule(change, false)
 	case differ.ChangeTypeAddRetentionPolicy:
 		return ddlBuilder.buildAddRetentionPolicy(change)
 	case differ.ChangeTypeDropRetentionPolicy:
 		return ddlBuilder.buildDropRetentionPolicy(change)
+	case differ.ChangeTypeAddRefreshPolicy, differ.ChangeTypeDropRefreshPolicy,
+		differ.ChangeTypeModifyRefreshPolicy:
+		return ddlBuilder.buildRefreshPolicy(change, false)
+	case differ.ChangeTypeModifyCACompression:
+		return ddlBuilder.buildCACompression(change, false)
 	default:
 		return ddlBuilder.buildAddCompressionPolicy(change)
 	}
@@ -450,15 +747,31 @@ func (b *timescalePolicyBuilder) BuildDown(
 		return ddlBuilder.buildAddCompressionPolicy(change)
 	case differ.ChangeTypeModifyCompressionPolicy:
 		return ddlBuilder.buildReverseModifyCompressionPolicy(change)
+	case differ.ChangeTypeModifyCompressionSchedule:
+		return ddlBuilder.buildModifyCompressionSchedule(change, true)
 	case differ.ChangeTypeAddRetentionPolicy:
 		return ddlBuilder.buildDropRetentionPolicy(change)
 	case differ.ChangeTypeDropRetentionPolicy:
 		return ddlBuilder.buildAddRetentionPolicy(change)
+	case differ.ChangeTypeAddRefreshPolicy, differ.ChangeTypeDropRefreshPolicy,
+		differ.ChangeTypeModifyRefreshPolicy:
+		return ddlBuilder.buildRefreshPolicy(change, true)
+	case differ.ChangeTypeModifyCACompression:
+		return ddlBuilder.buildCACompression(change, true)
 	default:
 		return ddlBuilder.buildDropCompressionPolicy(change)
 	}
 }
 
+func (b *timescalePolicyBuilder) ClassifyDown(change differ.Change) (Reversibility, string) {
+	if change.Type == differ.ChangeTypeAddRetentionPolicy {
+		return ReversibilityStructureOnly,
+			fmt.Sprintf("chunks dropped by the retention policy on %s are not restored", change.ObjectName)
+	}
+
+	return ReversibilityFull, ""
+}
+
 type continuousAggregateBuilder struct{}
 
 func (b *continuousAggregateBuilder) BuildUp(
@@ -492,3 +805,16 @@ func (b *continuousAggregateBuilder) BuildDown(
 		return ddlBuilder.buildDropContinuousAggregate(change)
 	}
 }
+
+func (b *continuousAggregateBuilder) ClassifyDown(
+	change differ.Change,
+) (Reversibility, string) {
+	if change.Type == differ.ChangeTypeDropContinuousAggregate {
+		return ReversibilityStructureOnly, fmt.Sprintf(
+			"%s is rematerialized from its source; buckets whose raw data is gone are lost",
+			change.ObjectName,
+		)
+	}
+
+	return ReversibilityFull, ""
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 type commentDetails struct {
@@ -42,6 +43,40 @@ func isCommentChangeOnly(change differ.Change) (bool, error) {
 	return details.HasOld && details.HasNew, nil
 }
 
+// hasCommentChange reports whether the changes being generated include a
+// separate comment change of changeType for objectName. A statement that
+// creates or redefines the object then leaves the comment to that change.
+func (b *DDLBuilder) hasCommentChange(changeType differ.ChangeType, objectName string) bool {
+	for i := range b.result.Changes {
+		change := b.result.Changes[i]
+		if change.Type != changeType || change.ObjectName != objectName {
+			continue
+		}
+
+		if commentOnly, err := isCommentChangeOnly(change); err == nil && commentOnly {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasIndexChange reports whether the changes being generated include a
+// change of changeType for idx. The index is then created by that change, or
+// by its inverse in a down migration, so the statement creating its
+// materialized view must not create it too.
+func (b *DDLBuilder) hasIndexChange(changeType differ.ChangeType, idx *schema.Index) bool {
+	key := differ.IndexKey(idx.Schema, idx.Name)
+
+	for i := range b.result.Changes {
+		if b.result.Changes[i].Type == changeType && b.result.Changes[i].ObjectName == key {
+			return true
+		}
+	}
+
+	return false
+}
+
 func buildCommentStatement(objectType, target, comment string, forceMultiline bool) string {
 	if comment == "" {
 		return fmt.Sprintf("COMMENT ON %s %s IS NULL;", objectType, target)
@@ -69,17 +104,45 @@ func buildCommentStatement(objectType, target, comment string, forceMultiline bo
 	)
 }
 
+// buildConstraintCommentStatement builds COMMENT ON CONSTRAINT for the
+// constraint of a table, given by its qualified name.
+func buildConstraintCommentStatement(table, constraintName, comment string) string {
+	return buildCommentStatement(
+		"CONSTRAINT", QuoteIdentifier(constraintName)+" ON "+table, comment, false,
+	)
+}
+
+// ensureStatementTerminated ends sql with a semicolon unless it already has
+// one. Comment lines that trail the statement stay after the semicolon, and
+// SQL that is only comments, such as a manual rollback note, gets none.
 func ensureStatementTerminated(sql string) string {
 	trimmed := strings.TrimRight(sql, " \t\n\r")
 	if trimmed == "" {
 		return ""
 	}
 
-	if strings.HasSuffix(trimmed, ";") {
+	statement, comments := trimmed, ""
+
+	for {
+		start := strings.LastIndexByte(statement, '\n') + 1
+		if !strings.HasPrefix(strings.TrimSpace(statement[start:]), "--") {
+			break
+		}
+
+		statement, comments = statement[:start], statement[start:]+comments
+		if statement == "" {
+			return trimmed
+		}
+
+		statement = strings.TrimRight(statement, " \t\n\r")
+		comments = "\n" + comments
+	}
+
+	if strings.HasSuffix(statement, ";") {
 		return trimmed
 	}
 
-	return trimmed + ";"
+	return statement + ";" + comments
 }
 
 func appendStatement(sb *strings.Builder, statement string) {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
 )
 
 func (b *DDLBuilder) buildAddSchema(change differ.Change) (DDLStatement, error) {
@@ -30,13 +31,29 @@ func (b *DDLBuilder) buildAddSchema(change differ.Change) (DDLStatement, error)
 	}, nil
 }
 
+// buildAddSchemaForDown restores a dropped schema with its comment.
+func (b *DDLBuilder) buildAddSchemaForDown(change differ.Change) (DDLStatement, error) {
+	stmt, err := b.buildAddSchema(change)
+	if err != nil {
+		return stmt, err
+	}
+
+	if sch := b.getSchema(change.ObjectName, b.result.Current); sch != nil && sch.Comment != "" {
+		stmt.SQL += "\n" + buildCommentStatement(
+			"SCHEMA", QuoteIdentifier(sch.Name), sch.Comment, false)
+	}
+
+	return stmt, nil
+}
+
 func (b *DDLBuilder) buildDropSchema(change differ.Change) (DDLStatement, error) {
 	name := change.ObjectName
 	if name == "" {
 		return DDLStatement{}, fmt.Errorf("schema not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP SCHEMA %s%s CASCADE;", b.ifExists(), QuoteIdentifier(name))
+	sql := fmt.Sprintf("DROP SCHEMA %s%s%s;",
+		b.ifExists(), QuoteIdentifier(name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -46,6 +63,28 @@ func (b *DDLBuilder) buildDropSchema(change differ.Change) (DDLStatement, error)
 	}, nil
 }
 
+func (b *DDLBuilder) buildSchemaComment(
+	change differ.Change,
+	commentKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	schemaName, err := getDetailString(change.Details, DetailKeySchemaName)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildSchemaComment", &change, err)
+	}
+
+	comment, _, err := getOptionalDetailString(change.Details, commentKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildSchemaComment", &change, err)
+	}
+
+	return DDLStatement{
+		SQL:         buildCommentStatement("SCHEMA", QuoteIdentifier(schemaName), comment, false),
+		Description: action + " schema comment " + schemaName,
+		RequiresTx:  true,
+	}, nil
+}
+
 func (b *DDLBuilder) buildAddExtension(change differ.Change) (DDLStatement, error) {
 	ext := b.getExtension(change.ObjectName, b.result.Desired)
 	if ext == nil {
@@ -74,7 +113,8 @@ func (b *DDLBuilder) buildDropExtension(change differ.Change) (DDLStatement, err
 		return DDLStatement{}, fmt.Errorf("extension not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP EXTENSION %s%s CASCADE;", b.ifExists(), QuoteIdentifier(name))
+	sql := fmt.Sprintf("DROP EXTENSION %s%s%s;",
+		b.ifExists(), QuoteIdentifier(name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -179,11 +219,23 @@ func (b *DDLBuilder) buildAddCustomType(change differ.Change) (DDLStatement, err
 	}
 
 	var sql string
-	if ct.Type == "enum" {
+
+	switch {
+	case ct.Type == "enum":
 		sql = fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);",
 			QualifiedName(ct.Schema, ct.Name),
 			formatEnumValues(ct.Values))
-	} else {
+	case ct.Type == "composite" && len(ct.Attributes) > 0:
+		attributes := make([]string, len(ct.Attributes))
+		for i, attr := range ct.Attributes {
+			attributes[i] = sqlIndent + QuoteIdentifier(attr.Name) + " " +
+				NormalizeDataType(attr.DataType)
+		}
+
+		sql = fmt.Sprintf("CREATE TYPE %s AS (\n%s\n);",
+			QualifiedName(ct.Schema, ct.Name),
+			strings.Join(attributes, ",\n"))
+	default:
 		sql = fmt.Sprintf("CREATE TYPE %s AS %s;",
 			QualifiedName(ct.Schema, ct.Name),
 			ct.Definition)
@@ -202,8 +254,8 @@ func (b *DDLBuilder) buildDropCustomType(change differ.Change) (DDLStatement, er
 		return DDLStatement{}, fmt.Errorf("custom type not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP TYPE %s%s CASCADE;",
-		b.ifExists(), QualifiedName(ct.Schema, ct.Name))
+	sql := fmt.Sprintf("DROP TYPE %s%s%s;",
+		b.ifExists(), QualifiedName(ct.Schema, ct.Name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -213,8 +265,94 @@ func (b *DDLBuilder) buildDropCustomType(change differ.Change) (DDLStatement, er
 	}, nil
 }
 
+// buildTypeAttributeChange changes an attribute of a composite type from the
+// type held under fromKey to the one under toKey, where no type is an
+// attribute the composite type does not have. CASCADE carries the change
+// to the columns of the type's typed tables; without it ALTER TYPE refuses
+// while they exist.
+func (b *DDLBuilder) buildTypeAttributeChange(
+	change differ.Change,
+	fromKey, toKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	attribute, isAttribute, err := getOptionalDetailString(change.Details, DetailKeyAttribute)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildTypeAttributeChange", &change, err)
+	}
+
+	// Enum value changes are MODIFY_CUSTOM_TYPE changes too.
+	if !isAttribute {
+		return DDLStatement{}, newGeneratorError("buildTypeAttributeChange", &change,
+			util.WrapError("enum value change", ErrUnsupportedChangeType))
+	}
+
+	typeName, err := getDetailString(change.Details, DetailKeyTypeName)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildTypeAttributeChange", &change, err)
+	}
+
+	fromType, _ := change.Details[fromKey.String()].(string)
+	toType, _ := change.Details[toKey.String()].(string)
+	typedTables, _ := change.Details[DetailKeyTypedTables.String()].([]string)
+
+	var alteration string
+
+	switch {
+	case fromType == "":
+		alteration = fmt.Sprintf("ADD ATTRIBUTE %s %s",
+			QuoteIdentifier(attribute), NormalizeDataType(toType))
+	case toType == "":
+		alteration = "DROP ATTRIBUTE " + QuoteIdentifier(attribute)
+	default:
+		alteration = fmt.Sprintf("ALTER ATTRIBUTE %s TYPE %s",
+			QuoteIdentifier(attribute), NormalizeDataType(toType))
+	}
+
+	if len(typedTables) > 0 {
+		alteration += " CASCADE"
+	}
+
+	schemaName, name := parseSchemaAndName(typeName)
+
+	return DDLStatement{
+		SQL:         fmt.Sprintf("ALTER TYPE %s %s;", QualifiedName(schemaName, name), alteration),
+		Description: fmt.Sprintf("%s attribute %s of type %s", action, attribute, name),
+		IsUnsafe:    fromType != "",
+		RequiresTx:  true,
+	}, nil
+}
+
+// buildCustomTypeComment sets the type comment held under commentKey; an
+// empty comment becomes COMMENT ON TYPE ... IS NULL.
+func (b *DDLBuilder) buildCustomTypeComment(
+	change differ.Change,
+	commentKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	typeName, err := getDetailString(change.Details, DetailKeyTypeName)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildCustomTypeComment", &change, err)
+	}
+
+	comment, _, err := getOptionalDetailString(change.Details, commentKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildCustomTypeComment", &change, err)
+	}
+
+	schemaName, name := parseSchemaAndName(typeName)
+	if schemaName == "" {
+		schemaName = schema.DefaultSchema
+	}
+
+	return DDLStatement{
+		SQL:         buildCommentStatement("TYPE", QualifiedName(schemaName, name), comment, false),
+		Description: action + " type comment " + name,
+		RequiresTx:  true,
+	}, nil
+}
+
 func (b *DDLBuilder) buildAddSequence(change differ.Change) (DDLStatement, error) {
-	seq := b.getSequence(change.ObjectName, b.result.Desired)
+	seq := b.getChangedSequence(change, b.result.Desired)
 	if seq == nil {
 		return DDLStatement{}, newGeneratorError(
 			"buildAddSequence",
@@ -236,7 +374,7 @@ func (b *DDLBuilder) buildAddSequence(change differ.Change) (DDLStatement, error
 }
 
 func (b *DDLBuilder) buildDropSequence(change differ.Change) (DDLStatement, error) {
-	seq := b.getSequence(change.ObjectName, b.result.Current)
+	seq := b.getChangedSequence(change, b.result.Current)
 	if seq == nil {
 		return DDLStatement{}, newGeneratorError(
 			"buildDropSequence",
@@ -245,8 +383,8 @@ func (b *DDLBuilder) buildDropSequence(change differ.Change) (DDLStatement, erro
 		)
 	}
 
-	sql := fmt.Sprintf("DROP SEQUENCE %s%s CASCADE;",
-		b.ifExists(), QualifiedName(seq.Schema, seq.Name))
+	sql := fmt.Sprintf("DROP SEQUENCE %s%s%s;",
+		b.ifExists(), QualifiedName(seq.Schema, seq.Name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -255,3 +393,121 @@ func (b *DDLBuilder) buildDropSequence(change differ.Change) (DDLStatement, erro
 		RequiresTx:  true,
 	}, nil
 }
+
+func (b *DDLBuilder) buildModifySequence(change differ.Change) (DDLStatement, error) {
+	return b.buildSequenceAlter(change,
+		b.sequenceVersion(change, b.result.Current, DetailKeyCurrent),
+		b.sequenceVersion(change, b.result.Desired, DetailKeyDesired),
+		DetailKeyNewOwnedBy, "Modify")
+}
+
+func (b *DDLBuilder) buildReverseModifySequence(change differ.Change) (DDLStatement, error) {
+	return b.buildSequenceAlter(change,
+		b.sequenceVersion(change, b.result.Desired, DetailKeyDesired),
+		b.sequenceVersion(change, b.result.Current, DetailKeyCurrent),
+		DetailKeyOldOwnedBy, "Revert")
+}
+
+// sequenceVersion looks the sequence of a change up in db and falls back to
+// the version under key in the change details. The owner of a sequence added
+// in the same plan is set by a change whose current version is not in the
+// database.
+func (b *DDLBuilder) sequenceVersion(
+	change differ.Change,
+	db *schema.Database,
+	key DetailKey,
+) *schema.Sequence {
+	if seq := b.getSequence(change.ObjectName, db); seq != nil {
+		return seq
+	}
+
+	if seq, ok := change.Details[key.String()].(schema.Sequence); ok {
+		return &seq
+	}
+
+	return nil
+}
+
+// buildSequenceAlter alters the options that differ between the two versions
+// of a sequence. START WITH is only included when the change enforces it, and
+// RESTART is never written: it would move the live counter. OWNED BY is set to
+// the ownerKey detail, present only when the differ found the owner changed.
+func (b *DDLBuilder) buildSequenceAlter(
+	change differ.Change,
+	from, to *schema.Sequence,
+	ownerKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	if from == nil || to == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildSequenceAlter",
+			&change,
+			wrapObjectNotFoundError(ErrSequenceNotFound, "sequence", change.ObjectName),
+		)
+	}
+
+	enforceStart, _, err := optionalDetail[bool](change.Details, DetailKeyEnforceStart)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildSequenceAlter", &change, err)
+	}
+
+	var buf tokenBuffer
+	buf.Write("ALTER SEQUENCE")
+	buf.Write(QualifiedName(to.Schema, to.Name))
+
+	if from.Increment != to.Increment {
+		buf.Write(fmt.Sprintf("INCREMENT BY %d", to.Increment))
+	}
+
+	if from.MinValue != to.MinValue {
+		buf.Write(fmt.Sprintf("MINVALUE %d", to.MinValue))
+	}
+
+	if from.MaxValue != to.MaxValue {
+		buf.Write(fmt.Sprintf("MAXVALUE %d", to.MaxValue))
+	}
+
+	if enforceStart && from.StartValue != to.StartValue {
+		buf.Write(fmt.Sprintf("START WITH %d", to.StartValue))
+	}
+
+	if max(from.CacheSize, 1) != max(to.CacheSize, 1) {
+		buf.Write(fmt.Sprintf("CACHE %d", max(to.CacheSize, 1)))
+	}
+
+	if from.IsCyclic != to.IsCyclic {
+		if to.IsCyclic {
+			buf.Write("CYCLE")
+		} else {
+			buf.Write("NO CYCLE")
+		}
+	}
+
+	owner, changed, err := optionalDetail[string](change.Details, ownerKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildSequenceAlter", &change, err)
+	}
+
+	if changed {
+		buf.Write("OWNED BY " + formatSequenceOwner(owner))
+	}
+
+	return DDLStatement{
+		SQL:         ensureStatementTerminated(buf.String()),
+		Description: fmt.Sprintf("%s sequence %s", action, to.Name),
+		RequiresTx:  true,
+	}, nil
+}
+
+// formatSequenceOwner writes an owner from the differ, schema.table.column,
+// as an OWNED BY target; no owner is NONE.
+func formatSequenceOwner(owner string) string {
+	tableSchema, rest, found := strings.Cut(owner, ".")
+	table, column, hasColumn := strings.Cut(rest, ".")
+
+	if !found || !hasColumn {
+		return "NONE"
+	}
+
+	return QualifiedName(tableSchema, table) + "." + QuoteIdentifier(column)
+}
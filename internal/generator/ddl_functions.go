@@ -25,7 +25,7 @@ func (b *DDLBuilder) buildAddFunction(change differ.Change) (DDLStatement, error
 
 	return DDLStatement{
 		SQL:         ensureStatementTerminated(definition),
-		Description: "Add function " + fn.Name,
+		Description: "Add " + fn.KindName() + " " + fn.Name,
 		RequiresTx:  true,
 	}, nil
 }
@@ -44,14 +44,16 @@ func (b *DDLBuilder) buildDropFunction(change differ.Change) (DDLStatement, erro
 		argTypes = "(" + strings.Join(formatFunctionDataTypes(fn.ArgumentTypes), ", ") + ")"
 	}
 
-	sql := fmt.Sprintf("DROP FUNCTION %s%s%s CASCADE;",
+	sql := fmt.Sprintf("DROP %s %s%s%s%s;",
+		fn.Keyword(),
 		b.ifExists(),
 		QualifiedName(fn.Schema, fn.Name),
-		argTypes)
+		argTypes,
+		b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
-		Description: "Drop function " + fn.Name,
+		Description: "Drop " + fn.KindName() + " " + fn.Name,
 		IsUnsafe:    true,
 		RequiresTx:  true,
 	}, nil
@@ -74,12 +76,16 @@ func (b *DDLBuilder) buildAddFunctionForDown(change differ.Change) (DDLStatement
 
 	return DDLStatement{
 		SQL:         ensureStatementTerminated(definition),
-		Description: "Add function " + fn.Name,
+		Description: "Add " + fn.KindName() + " " + fn.Name,
 		RequiresTx:  true,
 	}, nil
 }
 
 func (b *DDLBuilder) buildModifyFunction(change differ.Change) (DDLStatement, error) {
+	if isFunctionRelocation(change) {
+		return buildRelocateFunction(change, DetailKeyCurrent, DetailKeyDesired)
+	}
+
 	comment, err := extractCommentDetails(change)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildModifyFunction", &change, err)
@@ -95,18 +101,11 @@ func (b *DDLBuilder) buildModifyFunction(change differ.Change) (DDLStatement, er
 			)
 		}
 
-		qualifiedTarget, formattedTarget := functionCommentTargets(fn)
-
-		target := formattedTarget
-		if comment.New == "" {
-			target = qualifiedTarget
-		}
-
-		sql := buildCommentStatement("FUNCTION", target, comment.New, true)
+		sql := buildCommentStatement(fn.Keyword(), functionCommentTarget(fn), comment.New, true)
 
 		return DDLStatement{
 			SQL:         sql,
-			Description: "Modify function comment " + fn.Name,
+			Description: "Modify " + fn.KindName() + " comment " + fn.Name,
 			RequiresTx:  true,
 		}, nil
 	}
@@ -129,19 +128,22 @@ func (b *DDLBuilder) buildModifyFunction(change differ.Change) (DDLStatement, er
 	appendStatement(&sb, definition)
 
 	if fn.Comment != "" {
-		_, formattedTarget := functionCommentTargets(fn)
-		commentSQL := buildCommentStatement("FUNCTION", formattedTarget, fn.Comment, true)
+		commentSQL := buildCommentStatement(fn.Keyword(), functionCommentTarget(fn), fn.Comment, true)
 		appendStatement(&sb, commentSQL)
 	}
 
 	return DDLStatement{
 		SQL:         sb.String(),
-		Description: "Modify function " + fn.Name,
+		Description: "Modify " + fn.KindName() + " " + fn.Name,
 		RequiresTx:  true,
 	}, nil
 }
 
 func (b *DDLBuilder) buildRevertModifyFunction(change differ.Change) (DDLStatement, error) {
+	if isFunctionRelocation(change) {
+		return buildRelocateFunction(change, DetailKeyDesired, DetailKeyCurrent)
+	}
+
 	comment, err := extractCommentDetails(change)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildRevertModifyFunction", &change, err)
@@ -158,12 +160,11 @@ func (b *DDLBuilder) buildRevertModifyFunction(change differ.Change) (DDLStateme
 				)
 			}
 
-			qualifiedTarget, _ := functionCommentTargets(fn)
-			sql := buildCommentStatement("FUNCTION", qualifiedTarget, comment.Old, true)
+			sql := buildCommentStatement(fn.Keyword(), functionCommentTarget(fn), comment.Old, true)
 
 			return DDLStatement{
 				SQL:         sql,
-				Description: "Revert function comment " + fn.Name,
+				Description: "Revert " + fn.KindName() + " comment " + fn.Name,
 				RequiresTx:  true,
 			}, nil
 		}
@@ -177,12 +178,11 @@ func (b *DDLBuilder) buildRevertModifyFunction(change differ.Change) (DDLStateme
 			)
 		}
 
-		_, formattedTarget := functionCommentTargets(fn)
-		sql := buildCommentStatement("FUNCTION", formattedTarget, comment.Old, true)
+		sql := buildCommentStatement(fn.Keyword(), functionCommentTarget(fn), comment.Old, true)
 
 		return DDLStatement{
 			SQL:         sql,
-			Description: "Revert function comment " + fn.Name,
+			Description: "Revert " + fn.KindName() + " comment " + fn.Name,
 			RequiresTx:  true,
 		}, nil
 	}
@@ -203,26 +203,64 @@ func (b *DDLBuilder) buildRevertModifyFunction(change differ.Change) (DDLStateme
 
 	return DDLStatement{
 		SQL:         ensureStatementTerminated(definition),
-		Description: "Revert function " + fn.Name,
+		Description: "Revert " + fn.KindName() + " " + fn.Name,
 		RequiresTx:  true,
 	}, nil
 }
 
-func functionCommentTargets(fn *schema.Function) (string, string) {
-	schemaName := fn.Schema
-	if schemaName == "" {
-		schemaName = schema.DefaultSchema
+func isFunctionRelocation(change differ.Change) bool {
+	relocated, _ := change.Details[DetailKeyRelocated.String()].(bool)
+	return relocated
+}
+
+// buildRelocateFunction renames the function under fromKey and moves it to
+// the schema of the function under toKey, in place, so the triggers and views
+// that call it keep calling it.
+func buildRelocateFunction(
+	change differ.Change,
+	fromKey, toKey DetailKey,
+) (DDLStatement, error) {
+	from, err := requireDetail[*schema.Function](change.Details, fromKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRelocateFunction", &change, err)
+	}
+
+	to, err := requireDetail[*schema.Function](change.Details, toKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRelocateFunction", &change, err)
 	}
 
-	argSignature := formatFunctionArgumentSignature(fn)
-	qualified := QualifiedName(schemaName, fn.Name) + argSignature
+	fromSchema := schema.NormalizeSchemaName(from.Schema)
+	toSchema := schema.NormalizeSchemaName(to.Schema)
+	argTypes := "(" + strings.Join(formatFunctionDataTypes(from.ArgumentTypes), ", ") + ")"
+
+	var sb strings.Builder
+
+	if !strings.EqualFold(from.Name, to.Name) {
+		appendStatement(&sb, fmt.Sprintf("ALTER %s %s%s RENAME TO %s;", from.Keyword(),
+			QualifiedName(fromSchema, from.Name), argTypes, QuoteIdentifier(to.Name)))
+	}
 
-	return qualified, fmt.Sprintf(
-		"%s.%s%s",
-		QuoteIdentifier(schemaName),
-		strings.ToUpper(fn.Name),
-		argSignature,
-	)
+	if fromSchema != toSchema {
+		appendStatement(&sb, fmt.Sprintf("ALTER %s %s%s SET SCHEMA %s;", from.Keyword(),
+			QualifiedName(fromSchema, to.Name), argTypes, QuoteIdentifier(toSchema)))
+	}
+
+	if commentChanged, _ := change.Details[DetailKeyCommentChanged.String()].(bool); commentChanged {
+		appendStatement(&sb, buildCommentStatement(to.Keyword(), functionCommentTarget(to),
+			to.Comment, true))
+	}
+
+	return DDLStatement{
+		SQL:         sb.String(),
+		Description: "Relocate " + to.KindName() + " " + QualifiedName(toSchema, to.Name),
+		RequiresTx:  true,
+	}, nil
+}
+
+// functionCommentTarget names fn with its argument signature for COMMENT ON.
+func functionCommentTarget(fn *schema.Function) string {
+	return QualifiedName(fn.Schema, fn.Name) + formatFunctionArgumentSignature(fn)
 }
 
 func (b *DDLBuilder) buildAddTrigger(change differ.Change) (DDLStatement, error) {
@@ -241,7 +279,7 @@ func (b *DDLBuilder) buildAddTrigger(change differ.Change) (DDLStatement, error)
 	}
 
 	return DDLStatement{
-		SQL:         ensureStatementTerminated(definition),
+		SQL:         withTriggerState(ensureStatementTerminated(definition), trigger),
 		Description: "Add trigger " + trigger.Name,
 		RequiresTx:  true,
 	}, nil
@@ -263,7 +301,8 @@ func (b *DDLBuilder) buildAddTriggerForDown(change differ.Change) (DDLStatement,
 	}
 
 	return DDLStatement{
-		SQL:         ensureStatementTerminated(definition),
+		SQL: appendTriggerComment(
+			withTriggerState(ensureStatementTerminated(definition), trigger), trigger),
 		Description: "Add trigger " + trigger.Name,
 		RequiresTx:  true,
 	}, nil
@@ -278,10 +317,11 @@ func (b *DDLBuilder) buildDropTrigger(change differ.Change) (DDLStatement, error
 		}
 	}
 
-	sql := fmt.Sprintf("DROP TRIGGER %s%s ON %s CASCADE;",
+	sql := fmt.Sprintf("DROP TRIGGER %s%s ON %s%s;",
 		b.ifExists(),
 		QuoteIdentifier(trigger.Name),
-		QualifiedName(trigger.Schema, trigger.TableName))
+		QualifiedName(trigger.Schema, trigger.TableName),
+		b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -291,8 +331,34 @@ func (b *DDLBuilder) buildDropTrigger(change differ.Change) (DDLStatement, error
 	}, nil
 }
 
+func (b *DDLBuilder) buildTriggerComment(
+	change differ.Change,
+	commentKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	trigger, err := getDetailTrigger(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildTriggerComment", &change, err)
+	}
+
+	comment, _, err := getOptionalDetailString(change.Details, commentKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildTriggerComment", &change, err)
+	}
+
+	target := QuoteIdentifier(trigger.Name) + " ON " +
+		QualifiedName(trigger.Schema, trigger.TableName)
+
+	return DDLStatement{
+		SQL:         buildCommentStatement("TRIGGER", target, comment, false),
+		Description: fmt.Sprintf("%s trigger comment %s.%s", action, trigger.TableName, trigger.Name),
+		RequiresTx:  true,
+	}, nil
+}
+
 // A trigger's timing, events, columns, condition, or function cannot be altered
 // in place, so a modification drops the old form and recreates the new one.
+// Only a change of enabled state is made in place.
 func (b *DDLBuilder) buildModifyTrigger(change differ.Change) (DDLStatement, error) {
 	current, err := getCurrentTrigger(change.Details)
 	if err != nil {
@@ -304,6 +370,14 @@ func (b *DDLBuilder) buildModifyTrigger(change differ.Change) (DDLStatement, err
 		return DDLStatement{}, newGeneratorError("buildModifyTrigger", &change, err)
 	}
 
+	if isEnabledStateOnly(change) {
+		return DDLStatement{
+			SQL:         formatTriggerState(desired, desired.GetEnabledState()),
+			Description: triggerStateDescription(desired),
+			RequiresTx:  true,
+		}, nil
+	}
+
 	sql, err := b.buildTriggerReplacement(current, desired)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildModifyTrigger", &change, err)
@@ -328,19 +402,39 @@ func (b *DDLBuilder) buildReverseModifyTrigger(change differ.Change) (DDLStateme
 		return DDLStatement{}, newGeneratorError("buildReverseModifyTrigger", &change, err)
 	}
 
+	if isEnabledStateOnly(change) {
+		return DDLStatement{
+			SQL:         formatTriggerState(current, current.GetEnabledState()),
+			Description: triggerStateDescription(current),
+			RequiresTx:  true,
+		}, nil
+	}
+
 	sql, err := b.buildTriggerReplacement(desired, current)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildReverseModifyTrigger", &change, err)
 	}
 
 	return DDLStatement{
-		SQL:         sql,
+		SQL:         appendTriggerComment(sql, current),
 		Description: "Revert trigger " + desired.Name,
 		IsUnsafe:    true,
 		RequiresTx:  true,
 	}, nil
 }
 
+// appendTriggerComment follows sql with the COMMENT ON TRIGGER that gives
+// trigger its comment, if it has one.
+func appendTriggerComment(sql string, trigger *schema.Trigger) string {
+	if trigger.Comment == "" {
+		return sql
+	}
+
+	return sql + "\n" + buildCommentStatement("TRIGGER",
+		QuoteIdentifier(trigger.Name)+" ON "+QualifiedName(trigger.Schema, trigger.TableName),
+		trigger.Comment, false)
+}
+
 func (b *DDLBuilder) buildTriggerReplacement(toDrop, toCreate *schema.Trigger) (string, error) {
 	dropSQL := fmt.Sprintf("DROP TRIGGER %s%s ON %s;",
 		b.ifExists(),
@@ -352,5 +446,28 @@ func (b *DDLBuilder) buildTriggerReplacement(toDrop, toCreate *schema.Trigger) (
 		return "", err
 	}
 
-	return dropSQL + "\n" + ensureStatementTerminated(definition), nil
+	createSQL := withTriggerState(ensureStatementTerminated(definition), toCreate)
+
+	return dropSQL + "\n" + createSQL, nil
+}
+
+// triggerStateDescription reads like "Disable trigger audit_trg".
+func triggerStateDescription(trigger *schema.Trigger) string {
+	action := strings.ToLower(triggerStateAction(trigger.GetEnabledState()))
+	return strings.ToUpper(action[:1]) + action[1:] + " " + trigger.Name
+}
+
+func isEnabledStateOnly(change differ.Change) bool {
+	stateOnly, _ := change.Details[DetailKeyEnabledStateOnly.String()].(bool)
+	return stateOnly
+}
+
+// withTriggerState appends the ALTER TABLE that gives a newly created trigger
+// its enabled state, unless that is the origin state it is created in.
+func withTriggerState(createSQL string, trigger *schema.Trigger) string {
+	if trigger.GetEnabledState() == schema.TriggerEnabledOrigin {
+		return createSQL
+	}
+
+	return createSQL + "\n" + formatTriggerState(trigger, trigger.GetEnabledState())
 }
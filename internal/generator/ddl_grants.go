@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// buildAddGrant grants the privileges of a grant change, with the grant
+// option when the grant holds them with it.
+func (b *DDLBuilder) buildAddGrant(change differ.Change) (DDLStatement, error) {
+	grant, err := requireDetail[*schema.Grant](change.Details, DetailKeyGrant)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAddGrant", &change, err)
+	}
+
+	sql := fmt.Sprintf("GRANT %s ON TABLE %s TO %s",
+		formatGrantPrivileges(grant), QualifiedName(grant.Schema, grant.ObjectName),
+		formatGrantee(grant))
+	if grant.WithGrantOption {
+		sql += " WITH GRANT OPTION"
+	}
+
+	return DDLStatement{
+		SQL: sql + ";",
+		Description: "Grant " + strings.Join(grant.Privileges, ", ") + " on " + grant.Target() +
+			" to " + grant.GranteeName(),
+		RequiresTx: true,
+	}, nil
+}
+
+// buildRevokeGrant revokes the privileges of a grant change, or only their
+// grant option when the change is limited to it.
+func (b *DDLBuilder) buildRevokeGrant(change differ.Change) (DDLStatement, error) {
+	grant, err := requireDetail[*schema.Grant](change.Details, DetailKeyGrant)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRevokeGrant", &change, err)
+	}
+
+	optionOnly, _, err := optionalDetail[bool](change.Details, DetailKeyGrantOptionOnly)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRevokeGrant", &change, err)
+	}
+
+	revoke, what := "REVOKE ", "Revoke "
+	if optionOnly {
+		revoke, what = "REVOKE GRANT OPTION FOR ", "Revoke grant option for "
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("%s%s ON TABLE %s FROM %s;",
+			revoke, formatGrantPrivileges(grant), QualifiedName(grant.Schema, grant.ObjectName),
+			formatGrantee(grant)),
+		Description: what + strings.Join(grant.Privileges, ", ") + " on " + grant.Target() +
+			" from " + grant.GranteeName(),
+		IsUnsafe:   true,
+		RequiresTx: true,
+	}, nil
+}
+
+// formatGrantPrivileges lists the privileges of grant, each followed by its
+// column for a column grant.
+func formatGrantPrivileges(grant *schema.Grant) string {
+	privileges := grant.PrivilegeNames()
+
+	if grant.Column != "" {
+		column := " (" + QuoteIdentifier(grant.Column) + ")"
+		for i := range privileges {
+			privileges[i] += column
+		}
+	}
+
+	return strings.Join(privileges, ", ")
+}
+
+func formatGrantee(grant *schema.Grant) string {
+	if grantee := grant.GranteeName(); grantee != schema.GrantRolePublic {
+		return QuoteIdentifier(grantee)
+	}
+
+	return "PUBLIC"
+}
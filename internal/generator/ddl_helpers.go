@@ -59,19 +59,21 @@ func buildSequenceSQL(seq *schema.Sequence) (string, error) {
 		buf.Write(fmt.Sprintf("INCREMENT BY %d", seq.Increment))
 	}
 
-	if seq.MinValue != 1 {
+	defaultMin, defaultMax := schema.SequenceBounds(seq.DataType, seq.Increment)
+
+	if seq.MinValue != defaultMin {
 		buf.Write(fmt.Sprintf("MINVALUE %d", seq.MinValue))
 	}
 
-	if seq.MaxValue != 0 {
+	if seq.MaxValue != 0 && seq.MaxValue != defaultMax {
 		buf.Write(fmt.Sprintf("MAXVALUE %d", seq.MaxValue))
 	}
 
-	if seq.StartValue != 1 {
+	if seq.StartValue != seq.DefaultStart() {
 		buf.Write(fmt.Sprintf("START WITH %d", seq.StartValue))
 	}
 
-	if seq.CacheSize != 1 {
+	if seq.CacheSize > 1 {
 		buf.Write(fmt.Sprintf("CACHE %d", seq.CacheSize))
 	}
 
@@ -94,27 +96,30 @@ func buildCreateTableSQL(table *schema.Table) (string, error) {
 	var sb strings.Builder
 	sb.WriteString("CREATE TABLE ")
 	sb.WriteString(QualifiedName(table.Schema, table.Name))
-	sb.WriteString(" (\n")
-
-	columnCount := 0
-
-	appendLine := func(definition string) {
-		if columnCount > 0 {
-			sb.WriteString(",\n")
-		}
 
-		sb.WriteString(definition)
+	var lines []string
 
-		columnCount++
-	}
+	if table.TypedOf != "" {
+		// A typed table takes its columns from the type, and only declares
+		// their options.
+		typeSchema, typeName := parseSchemaAndName(table.TypedOf)
+		sb.WriteString(" OF ")
+		sb.WriteString(QualifiedName(typeSchema, typeName))
 
-	for i := range table.Columns {
-		definition, err := formatColumnDefinition(&table.Columns[i])
-		if err != nil {
-			return "", err
+		for i := range table.Columns {
+			if options := formatColumnOptions(&table.Columns[i]); options != "" {
+				lines = append(lines, sqlIndent+options)
+			}
 		}
+	} else {
+		for i := range table.Columns {
+			definition, err := formatColumnDefinition(&table.Columns[i])
+			if err != nil {
+				return "", err
+			}
 
-		appendLine(sqlIndent + definition)
+			lines = append(lines, sqlIndent+definition)
+		}
 	}
 
 	for i := range table.Constraints {
@@ -127,10 +132,14 @@ func buildCreateTableSQL(table *schema.Table) (string, error) {
 			continue
 		}
 
-		appendLine(indentMultiline(definition))
+		lines = append(lines, indentMultiline(definition))
 	}
 
-	sb.WriteString("\n)")
+	if len(lines) > 0 || table.TypedOf == "" {
+		sb.WriteString(" (\n")
+		sb.WriteString(strings.Join(lines, ",\n"))
+		sb.WriteString("\n)")
+	}
 
 	if table.PartitionStrategy != nil {
 		columns := make([]string, len(table.PartitionStrategy.Columns))
@@ -145,7 +154,16 @@ func buildCreateTableSQL(table *schema.Table) (string, error) {
 		sb.WriteString(")")
 	}
 
-	return ensureStatementTerminated(sb.String()), nil
+	createSQL := ensureStatementTerminated(sb.String())
+
+	for i := range table.Columns {
+		storage := formatColumnStorage(table.Schema, table.Name, &table.Columns[i])
+		if storage != "" {
+			createSQL += "\n" + storage
+		}
+	}
+
+	return createSQL, nil
 }
 
 func buildContinuousAggregateSQL(ca *schema.ContinuousAggregate) (string, error) {
@@ -163,8 +181,10 @@ func buildContinuousAggregateSQL(ca *schema.ContinuousAggregate) (string, error)
 
 	var sql strings.Builder
 
+	viewName := QualifiedName(ca.Schema, ca.ViewName)
+
 	fmt.Fprintf(&sql, "CREATE MATERIALIZED VIEW %s\nWITH (timescaledb.continuous) AS\n%s",
-		QualifiedName(ca.Schema, ca.ViewName),
+		viewName,
 		ca.Query)
 
 	if ca.WithData {
@@ -173,22 +193,26 @@ func buildContinuousAggregateSQL(ca *schema.ContinuousAggregate) (string, error)
 		sql.WriteString("\nWITH NO DATA;")
 	}
 
-	if ca.RefreshPolicy != nil {
-		fmt.Fprintf(&sql, "\n\nSELECT add_continuous_aggregate_policy('%s',\n"+
-			sqlIndent+"start_offset => INTERVAL '%s',\n"+
-			sqlIndent+"end_offset => INTERVAL '%s',\n"+
-			sqlIndent+"schedule_interval => INTERVAL '%s');",
-			QualifiedName(ca.Schema, ca.ViewName),
-			ca.RefreshPolicy.StartOffset,
-			ca.RefreshPolicy.EndOffset,
-			ca.RefreshPolicy.ScheduleInterval)
+	if job := formatRefreshPolicyJob(viewName, ca.RefreshPolicy); job != "" {
+		sql.WriteString("\n\n")
+		sql.WriteString(ensureStatementTerminated(job))
+	}
+
+	if ca.CompressionEnabled {
+		fmt.Fprintf(&sql, "\n\nALTER MATERIALIZED VIEW %s SET (timescaledb.compress = true);",
+			viewName)
+
+		if job := formatCompressionJob(viewName, ca.CompressionPolicy); job != "" {
+			sql.WriteString("\n\n")
+			sql.WriteString(ensureStatementTerminated(job))
+		}
 	}
 
 	if ca.Comment != "" {
 		sql.WriteString("\n\n")
 		sql.WriteString(buildCommentStatement(
 			"VIEW",
-			QualifiedName(ca.Schema, ca.ViewName),
+			viewName,
 			ca.Comment,
 			false,
 		))
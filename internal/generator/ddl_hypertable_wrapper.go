@@ -76,13 +76,11 @@ func (b *DDLBuilder) wrapWithCompressionToggle(
 
 	qualifiedTable := QualifiedName(ht.Schema, ht.TableName)
 
-	disableSQL := formatDisableCompression(qualifiedTable)
-
-	skipReEnable := b.hasModifyCompressionPolicyForTable(tableName)
-
+	// A MODIFY_COMPRESSION_POLICY change of the table enables compression
+	// with its new settings, so the wrapped statement leaves it disabled.
 	var enableSQL string
 
-	if !skipReEnable {
+	if !b.hasModifyCompressionPolicyForTable(tableName) {
 		var err error
 
 		enableSQL, err = formatEnableCompression(ht)
@@ -91,27 +89,13 @@ func (b *DDLBuilder) wrapWithCompressionToggle(
 		}
 	}
 
-	var sb strings.Builder
-
-	fmt.Fprintf(&sb, compressedHypertableWarning, qualifiedTable)
-	sb.WriteString(disableSQL)
-	sb.WriteString("\n")
-
-	stmtSQL := strings.TrimSpace(stmt.SQL)
-	if !strings.HasSuffix(stmtSQL, ";") {
-		stmtSQL += ";"
-	}
-
-	sb.WriteString(stmtSQL)
-
-	if enableSQL != "" {
-		sb.WriteString("\n")
-		sb.WriteString(enableSQL)
-		sb.WriteString(";")
-	}
-
 	return DDLStatement{
-		SQL:         sb.String(),
+		Statements: []string{
+			fmt.Sprintf(compressedHypertableWarning, qualifiedTable) +
+				formatDisableCompression(qualifiedTable),
+			stmt.SQL,
+			enableSQL,
+		},
 		Description: stmt.Description,
 		IsUnsafe:    true,
 		RequiresTx:  stmt.RequiresTx,
@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// policyRoleKeywords are the role specifications CREATE POLICY accepts that
+// are keywords rather than role names, written unquoted.
+var policyRoleKeywords = map[string]bool{ //nolint:gochecknoglobals
+	schema.PolicyRolePublic: true,
+	"current_user":          true,
+	"session_user":          true,
+	"current_role":          true,
+}
+
+func (b *DDLBuilder) buildAddPolicy(change differ.Change) (DDLStatement, error) {
+	policy, err := getDetailPolicy(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAddPolicy", &change, err)
+	}
+
+	return DDLStatement{
+		SQL:         formatCreatePolicy(policy),
+		Description: "Add policy " + policy.Name + " on " + policy.TableName,
+		RequiresTx:  true,
+	}, nil
+}
+
+func (b *DDLBuilder) buildDropPolicy(change differ.Change) (DDLStatement, error) {
+	policy, err := getDetailPolicy(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildDropPolicy", &change, err)
+	}
+
+	return DDLStatement{
+		SQL:         b.formatDropPolicy(policy),
+		Description: "Drop policy " + policy.Name + " on " + policy.TableName,
+		IsUnsafe:    true,
+		RequiresTx:  true,
+	}, nil
+}
+
+func (b *DDLBuilder) buildModifyPolicy(change differ.Change, reverse bool) (DDLStatement, error) {
+	current, err := requireDetail[*schema.Policy](change.Details, DetailKeyCurrent)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildModifyPolicy", &change, err)
+	}
+
+	desired, err := requireDetail[*schema.Policy](change.Details, DetailKeyDesired)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildModifyPolicy", &change, err)
+	}
+
+	description := "Modify policy " + desired.Name + " on " + desired.TableName
+	if reverse {
+		current, desired = desired, current
+		description = "Revert policy " + desired.Name + " on " + desired.TableName
+	}
+
+	if canAlterPolicy(current, desired) {
+		return DDLStatement{
+			SQL:         formatAlterPolicy(desired),
+			Description: description,
+			IsUnsafe:    true,
+			RequiresTx:  true,
+		}, nil
+	}
+
+	return DDLStatement{
+		SQL:         b.formatDropPolicy(current) + "\n" + formatCreatePolicy(desired),
+		Description: description,
+		IsUnsafe:    true,
+		RequiresTx:  true,
+	}, nil
+}
+
+// buildModifyRowSecurity enables or disables row-level security on a table,
+// the state the change asks for or, when reverse, the one it started from.
+func (b *DDLBuilder) buildModifyRowSecurity(
+	change differ.Change,
+	reverse bool,
+) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildModifyRowSecurity", &change, err)
+	}
+
+	enabled, err := requireDetail[bool](change.Details, DetailKeyEnabled)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildModifyRowSecurity", &change, err)
+	}
+
+	if reverse {
+		enabled = !enabled
+	}
+
+	table := b.getTable(tableName, b.result.Desired)
+	if table == nil {
+		table = b.getTable(tableName, b.result.Current)
+	}
+
+	if table == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildModifyRowSecurity",
+			&change,
+			wrapObjectNotFoundError(ErrTableNotFound, "table", tableName),
+		)
+	}
+
+	action := "DISABLE"
+	if enabled {
+		action = "ENABLE"
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("ALTER TABLE %s %s ROW LEVEL SECURITY;",
+			QualifiedName(table.Schema, table.Name), action),
+		Description: strings.ToUpper(action[:1]) + strings.ToLower(action[1:]) +
+			" row level security on " + table.Name,
+		IsUnsafe:   true,
+		RequiresTx: true,
+	}, nil
+}
+
+// canAlterPolicy reports whether ALTER POLICY turns current into desired.
+// It cannot change a policy's command or whether it is restrictive, nor
+// remove a USING or WITH CHECK expression, so those changes recreate it.
+func canAlterPolicy(current, desired *schema.Policy) bool {
+	return current.CommandName() == desired.CommandName() &&
+		current.Restrictive == desired.Restrictive &&
+		(desired.Using != "" || current.Using == "") &&
+		(desired.WithCheck != "" || current.WithCheck == "")
+}
+
+func formatCreatePolicy(policy *schema.Policy) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "CREATE POLICY %s ON %s",
+		QuoteIdentifier(policy.Name),
+		QualifiedName(policy.Schema, policy.TableName))
+
+	if policy.Restrictive {
+		sb.WriteString(" AS RESTRICTIVE")
+	}
+
+	if command := policy.CommandName(); command != schema.PolicyCommandAll {
+		sb.WriteString(" FOR " + command)
+	}
+
+	if len(policy.Roles) > 0 {
+		sb.WriteString(" TO " + formatPolicyRoles(policy))
+	}
+
+	sb.WriteString(formatPolicyExpressions(policy))
+	sb.WriteString(";")
+
+	return sb.String()
+}
+
+// formatAlterPolicy sets every clause ALTER POLICY can change to the one of
+// policy, so the statement does not depend on which of them differ.
+func formatAlterPolicy(policy *schema.Policy) string {
+	return fmt.Sprintf("ALTER POLICY %s ON %s TO %s%s;",
+		QuoteIdentifier(policy.Name),
+		QualifiedName(policy.Schema, policy.TableName),
+		formatPolicyRoles(policy),
+		formatPolicyExpressions(policy))
+}
+
+func (b *DDLBuilder) formatDropPolicy(policy *schema.Policy) string {
+	return fmt.Sprintf("DROP POLICY %s%s ON %s;",
+		b.ifExists(),
+		QuoteIdentifier(policy.Name),
+		QualifiedName(policy.Schema, policy.TableName))
+}
+
+func formatPolicyRoles(policy *schema.Policy) string {
+	roles := policy.RoleNames()
+
+	formatted := make([]string, len(roles))
+	for i, role := range roles {
+		if policyRoleKeywords[role] {
+			formatted[i] = strings.ToUpper(role)
+		} else {
+			formatted[i] = QuoteIdentifier(role)
+		}
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func formatPolicyExpressions(policy *schema.Policy) string {
+	var sb strings.Builder
+
+	if policy.Using != "" {
+		sb.WriteString(" USING (" + policy.Using + ")")
+	}
+
+	if policy.WithCheck != "" {
+		sb.WriteString(" WITH CHECK (" + policy.WithCheck + ")")
+	}
+
+	return sb.String()
+}
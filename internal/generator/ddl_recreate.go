@@ -0,0 +1,325 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const (
+	recreateNewSuffix = "_new"
+	recreateOldSuffix = "_old"
+)
+
+// buildRecreateTable writes the steps for replacing a heavily rewritten
+// table: create the desired definition under a temporary name, copy the rows
+// of matching columns, build its indexes and swap the names. Every line is
+// commented out because the column mapping and data copy need review before
+// anything runs.
+func (b *DDLBuilder) buildRecreateTable(change differ.Change) (DDLStatement, error) {
+	current, desired, err := getRecreateTableDetails(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRecreateTable", &change, err)
+	}
+
+	target := QualifiedName(desired.Schema, desired.Name)
+	newName := desired.Name + recreateNewSuffix
+	oldName := desired.Name + recreateOldSuffix
+
+	newTable := *desired
+	newTable.Name = newName
+
+	createSQL, err := buildCreateTableSQL(&newTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRecreateTable", &change, err)
+	}
+
+	var steps strings.Builder
+
+	for _, name := range collidingIndexNames(current, desired) {
+		appendStatement(&steps, fmt.Sprintf("ALTER INDEX %s RENAME TO %s;",
+			QualifiedName(current.Schema, name), QuoteIdentifier(name+recreateOldSuffix)))
+	}
+
+	appendStatement(&steps, createSQL)
+	appendStatement(&steps, buildRecreateCopySQL(current, desired, newName))
+
+	resynced := copiedSerialColumns(current, desired)
+	for _, column := range resynced {
+		appendStatement(&steps, formatSequenceResync(desired.Schema, newName, column))
+	}
+
+	for i := range desired.Indexes {
+		if isConstraintIndex(desired, desired.Indexes[i].Name) {
+			continue
+		}
+
+		idx := desired.Indexes[i]
+		idx.TableName = newName
+
+		indexSQL, err := formatIndexDefinition(&idx)
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildRecreateTable", &change, err)
+		}
+
+		appendStatement(&steps, ensureStatementTerminated(indexSQL))
+	}
+
+	appendStatement(&steps, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;",
+		target, QuoteIdentifier(oldName)))
+	appendStatement(&steps, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;",
+		QualifiedName(desired.Schema, newName), QuoteIdentifier(desired.Name)))
+
+	if desired.Comment != "" {
+		appendStatement(&steps, buildCommentStatement("TABLE", target, desired.Comment, false))
+	}
+
+	for i := range desired.Columns {
+		if col := &desired.Columns[i]; col.Comment != "" {
+			appendStatement(&steps, buildCommentStatement(
+				"COLUMN", target+"."+QuoteIdentifier(col.Name), col.Comment, false))
+		}
+	}
+
+	for i := range desired.Constraints {
+		if constraint := &desired.Constraints[i]; constraint.Comment != "" {
+			appendStatement(&steps, buildConstraintCommentStatement(
+				target, constraint.Name, constraint.Comment))
+		}
+	}
+
+	for i := range desired.Indexes {
+		if idx := &desired.Indexes[i]; idx.Comment != "" && !isConstraintIndex(desired, idx.Name) {
+			appendStatement(&steps, buildCommentStatement(
+				"INDEX", QualifiedName(desired.Schema, idx.Name), idx.Comment, false))
+		}
+	}
+
+	appendStatement(&steps, "-- After verifying the copy:\nDROP TABLE "+
+		QualifiedName(desired.Schema, oldName)+";")
+
+	reason, _, _ := getOptionalDetailString(change.Details, DetailKeyReason)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "-- MANUAL STEP: recreate table %s\n", target)
+
+	if reason != "" {
+		fmt.Fprintf(&sb, "-- Reason: %s\n", reason)
+	}
+
+	sb.WriteString("-- Review the column mapping below, then uncomment and run these statements.\n")
+	sb.WriteString("-- Views and foreign keys that reference the table follow the renamed old\n")
+	sb.WriteString("-- table and must be recreated against the new one.\n")
+	sb.WriteString("--\n")
+	sb.WriteString(commentOutSQL(strings.TrimSpace(steps.String())))
+
+	description := fmt.Sprintf("Recreate table %s (manual intervention required)", desired.Name)
+
+	return DDLStatement{
+		SQL:         sb.String(),
+		Description: withSequenceNote(description, resynced),
+		IsUnsafe:    true,
+		RequiresTx:  true,
+	}, nil
+}
+
+func (b *DDLBuilder) buildReverseRecreateTable(change differ.Change) (DDLStatement, error) {
+	_, desired, err := getRecreateTableDetails(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildReverseRecreateTable", &change, err)
+	}
+
+	target := QualifiedName(desired.Schema, desired.Name)
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("-- WARNING: Cannot automatically revert recreation of table %s\n"+
+			"-- If the old table has not been dropped yet, restore it with:\n"+
+			"-- DROP TABLE %s;\n"+
+			"-- ALTER TABLE %s RENAME TO %s;",
+			target,
+			target,
+			QualifiedName(desired.Schema, desired.Name+recreateOldSuffix),
+			QuoteIdentifier(desired.Name)),
+		Description: fmt.Sprintf(
+			"Revert recreation of table %s (manual intervention required)",
+			desired.Name,
+		),
+		IsUnsafe:   true,
+		RequiresTx: true,
+	}, nil
+}
+
+func getRecreateTableDetails(details map[string]any) (*schema.Table, *schema.Table, error) {
+	current, err := requireDetail[*schema.Table](details, DetailKeyCurrent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desired, err := requireDetail[*schema.Table](details, DetailKeyDesired)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return current, desired, nil
+}
+
+// buildRecreateCopySQL copies the columns present in both definitions by
+// name. New columns without a source and dropped columns are listed so the
+// mapping can be completed by hand.
+func buildRecreateCopySQL(current, desired *schema.Table, newName string) string {
+	var (
+		matched    []string
+		unmatched  []string
+		retyped    []string
+		overriding string
+	)
+
+	for i := range desired.Columns {
+		col := &desired.Columns[i]
+
+		source := current.GetColumn(col.Name)
+		if source == nil {
+			unmatched = append(unmatched, col.Name)
+			continue
+		}
+
+		matched = append(matched, QuoteIdentifier(col.Name))
+
+		// A GENERATED ALWAYS identity column only takes the copied values
+		// with OVERRIDING SYSTEM VALUE.
+		if col.Identity != nil && col.Identity.Generation == schema.IdentityAlways {
+			overriding = "\nOVERRIDING SYSTEM VALUE"
+		}
+
+		if !strings.EqualFold(source.FullDataType(), col.FullDataType()) {
+			retyped = append(retyped, fmt.Sprintf("%s (%s -> %s)",
+				col.Name, source.FullDataType(), col.FullDataType()))
+		}
+	}
+
+	var dropped []string
+
+	for i := range current.Columns {
+		if desired.GetColumn(current.Columns[i].Name) == nil {
+			dropped = append(dropped, current.Columns[i].Name)
+		}
+	}
+
+	var sb strings.Builder
+
+	if len(unmatched) > 0 {
+		fmt.Fprintf(
+			&sb,
+			"-- UNMATCHED new columns (no source; add them to the copy or rely on defaults): %s\n",
+			strings.Join(unmatched, ", "),
+		)
+	}
+
+	if len(dropped) > 0 {
+		fmt.Fprintf(&sb, "-- UNMATCHED old columns (not copied, data is lost): %s\n",
+			strings.Join(dropped, ", "))
+	}
+
+	if len(retyped) > 0 {
+		fmt.Fprintf(&sb, "-- Type changes (check the casts): %s\n", strings.Join(retyped, ", "))
+	}
+
+	if len(matched) == 0 {
+		fmt.Fprintf(&sb, "-- No columns match by name; complete the copy by hand.\n"+
+			"INSERT INTO %s (...)\nSELECT ...\nFROM %s;",
+			QualifiedName(desired.Schema, newName),
+			QualifiedName(current.Schema, current.Name))
+
+		return sb.String()
+	}
+
+	columns := strings.Join(matched, ", ")
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s)%s\nSELECT %s\nFROM %s;",
+		QualifiedName(desired.Schema, newName),
+		columns,
+		overriding,
+		columns,
+		QualifiedName(current.Schema, current.Name))
+
+	return sb.String()
+}
+
+// copiedSerialColumns returns the serial and identity columns of desired that
+// the copy fills from current. Their new sequences start below the copied
+// values.
+func copiedSerialColumns(current, desired *schema.Table) []string {
+	var columns []string
+
+	for i := range desired.Columns {
+		col := &desired.Columns[i]
+		if (serialTypeFor(col) != "" || col.Identity != nil) && current.GetColumn(col.Name) != nil {
+			columns = append(columns, col.Name)
+		}
+	}
+
+	return columns
+}
+
+// collidingIndexNames returns the current index and constraint index names
+// the new table would reuse. Index names are unique per schema, so these
+// have to be moved aside before the new table is created.
+func collidingIndexNames(current, desired *schema.Table) []string {
+	desiredNames := make(map[string]bool)
+	for _, name := range indexNames(desired) {
+		desiredNames[strings.ToLower(name)] = true
+	}
+
+	var colliding []string
+
+	for _, name := range indexNames(current) {
+		if desiredNames[strings.ToLower(name)] {
+			colliding = append(colliding, name)
+		}
+	}
+
+	return colliding
+}
+
+func indexNames(table *schema.Table) []string {
+	var names []string
+
+	for i := range table.Constraints {
+		switch table.Constraints[i].Type {
+		case schema.ConstraintPrimaryKey, schema.ConstraintUnique, schema.ConstraintExclude:
+			if table.Constraints[i].Name != "" {
+				names = append(names, table.Constraints[i].Name)
+			}
+		}
+	}
+
+	for i := range table.Indexes {
+		if !isConstraintIndex(table, table.Indexes[i].Name) {
+			names = append(names, table.Indexes[i].Name)
+		}
+	}
+
+	return names
+}
+
+func isConstraintIndex(table *schema.Table, indexName string) bool {
+	constraint := table.GetConstraint(indexName)
+	return constraint != nil && !constraint.IsForeignKey() && !constraint.IsCheck()
+}
+
+func commentOutSQL(sql string) string {
+	lines := strings.Split(sql, "\n")
+	for i, line := range lines {
+		switch {
+		case line == "":
+			lines[i] = "--"
+		case strings.HasPrefix(line, "--"):
+			lines[i] = line
+		default:
+			lines[i] = "-- " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/util"
@@ -12,6 +13,14 @@ type StatementBuilder interface {
 	BuildDown(change differ.Change, builder *DDLBuilder) (DDLStatement, error)
 }
 
+// RollbackClassifier is implemented by builders whose down statements cannot
+// always restore the state before the up migration. It is consulted for the
+// builder registered for the original change type and returns the
+// classification plus a note naming what is not restored.
+type RollbackClassifier interface {
+	ClassifyDown(change differ.Change) (Reversibility, string)
+}
+
 type DDLBuilderRegistry struct {
 	builders map[differ.ChangeType]StatementBuilder
 }
@@ -23,28 +32,42 @@ func NewDDLBuilderRegistry() *DDLBuilderRegistry {
 
 	r.Register(differ.ChangeTypeAddSchema, &schemaBuilder{})
 	r.Register(differ.ChangeTypeDropSchema, &schemaBuilder{})
+	r.Register(differ.ChangeTypeModifySchemaComment, &schemaBuilder{})
 	r.Register(differ.ChangeTypeAddExtension, &extensionBuilder{})
 	r.Register(differ.ChangeTypeDropExtension, &extensionBuilder{})
 	r.Register(differ.ChangeTypeModifyExtension, &extensionBuilder{})
 	r.Register(differ.ChangeTypeAddCustomType, &customTypeBuilder{})
 	r.Register(differ.ChangeTypeDropCustomType, &customTypeBuilder{})
+	r.Register(differ.ChangeTypeModifyCustomType, &customTypeBuilder{})
+	r.Register(differ.ChangeTypeModifyCustomTypeComment, &customTypeBuilder{})
 	r.Register(differ.ChangeTypeAddSequence, &sequenceBuilder{})
 	r.Register(differ.ChangeTypeDropSequence, &sequenceBuilder{})
+	r.Register(differ.ChangeTypeModifySequence, &sequenceBuilder{})
 	r.Register(differ.ChangeTypeAddTable, &tableBuilder{})
 	r.Register(differ.ChangeTypeDropTable, &tableBuilder{})
+	r.Register(differ.ChangeTypeRecreateTable, &recreateTableBuilder{})
 	r.Register(differ.ChangeTypeAddColumn, &columnBuilder{})
 	r.Register(differ.ChangeTypeDropColumn, &columnBuilder{})
 	r.Register(differ.ChangeTypeModifyColumnType, &columnBuilder{})
 	r.Register(differ.ChangeTypeModifyColumnNullability, &columnBuilder{})
 	r.Register(differ.ChangeTypeModifyColumnDefault, &columnBuilder{})
 	r.Register(differ.ChangeTypeModifyColumnComment, &columnBuilder{})
+	r.Register(differ.ChangeTypeModifyColumnStorage, &columnBuilder{})
+	r.Register(differ.ChangeTypeModifyColumnCompression, &columnBuilder{})
+	r.Register(differ.ChangeTypeModifyColumnGeneration, &columnBuilder{})
+	r.Register(differ.ChangeTypeAddColumnIdentity, &columnBuilder{})
+	r.Register(differ.ChangeTypeDropColumnIdentity, &columnBuilder{})
+	r.Register(differ.ChangeTypeModifyColumnIdentity, &columnBuilder{})
+	r.Register(differ.ChangeTypeRenameColumn, &columnBuilder{})
 	r.Register(differ.ChangeTypeModifyTableComment, &tableBuilder{})
 	r.Register(differ.ChangeTypeAddConstraint, &constraintBuilder{})
 	r.Register(differ.ChangeTypeDropConstraint, &constraintBuilder{})
 	r.Register(differ.ChangeTypeModifyConstraint, &constraintBuilder{})
+	r.Register(differ.ChangeTypeModifyConstraintComment, &constraintBuilder{})
 	r.Register(differ.ChangeTypeAddIndex, &indexBuilder{})
 	r.Register(differ.ChangeTypeDropIndex, &indexBuilder{})
 	r.Register(differ.ChangeTypeModifyIndex, &indexBuilder{})
+	r.Register(differ.ChangeTypeModifyIndexComment, &indexBuilder{})
 	r.Register(differ.ChangeTypeAddPartition, &partitionBuilder{})
 	r.Register(differ.ChangeTypeDropPartition, &partitionBuilder{})
 	r.Register(differ.ChangeTypeAddView, &viewBuilder{})
@@ -59,16 +82,31 @@ func NewDDLBuilderRegistry() *DDLBuilderRegistry {
 	r.Register(differ.ChangeTypeAddTrigger, &triggerBuilder{})
 	r.Register(differ.ChangeTypeDropTrigger, &triggerBuilder{})
 	r.Register(differ.ChangeTypeModifyTrigger, &triggerBuilder{})
+	r.Register(differ.ChangeTypeModifyTriggerComment, &triggerBuilder{})
+	r.Register(differ.ChangeTypeAddPolicy, &policyBuilder{})
+	r.Register(differ.ChangeTypeDropPolicy, &policyBuilder{})
+	r.Register(differ.ChangeTypeModifyPolicy, &policyBuilder{})
+	r.Register(differ.ChangeTypeModifyTableRowSecurity, &rowSecurityBuilder{})
+	r.Register(differ.ChangeTypeAddGrant, &grantBuilder{})
+	r.Register(differ.ChangeTypeRevokeGrant, &grantBuilder{})
 	r.Register(differ.ChangeTypeAddHypertable, &hypertableBuilder{})
 	r.Register(differ.ChangeTypeDropHypertable, &hypertableBuilder{})
+	r.Register(differ.ChangeTypeAddDimension, &dimensionBuilder{})
+	r.Register(differ.ChangeTypeDropDimension, &dimensionBuilder{})
+	r.Register(differ.ChangeTypeModifyDimension, &dimensionBuilder{})
 	r.Register(differ.ChangeTypeAddCompressionPolicy, &timescalePolicyBuilder{})
 	r.Register(differ.ChangeTypeDropCompressionPolicy, &timescalePolicyBuilder{})
 	r.Register(differ.ChangeTypeModifyCompressionPolicy, &timescalePolicyBuilder{})
+	r.Register(differ.ChangeTypeModifyCompressionSchedule, &timescalePolicyBuilder{})
 	r.Register(differ.ChangeTypeAddRetentionPolicy, &timescalePolicyBuilder{})
 	r.Register(differ.ChangeTypeDropRetentionPolicy, &timescalePolicyBuilder{})
 	r.Register(differ.ChangeTypeAddContinuousAggregate, &continuousAggregateBuilder{})
 	r.Register(differ.ChangeTypeDropContinuousAggregate, &continuousAggregateBuilder{})
 	r.Register(differ.ChangeTypeModifyContinuousAggregate, &continuousAggregateBuilder{})
+	r.Register(differ.ChangeTypeAddRefreshPolicy, &timescalePolicyBuilder{})
+	r.Register(differ.ChangeTypeDropRefreshPolicy, &timescalePolicyBuilder{})
+	r.Register(differ.ChangeTypeModifyRefreshPolicy, &timescalePolicyBuilder{})
+	r.Register(differ.ChangeTypeModifyCACompression, &timescalePolicyBuilder{})
 
 	return r
 }
@@ -77,6 +115,11 @@ func (r *DDLBuilderRegistry) Register(changeType differ.ChangeType, builder Stat
 	r.builders[changeType] = builder
 }
 
+// builderName names the builder of a change type in diagnostics.
+func (r *DDLBuilderRegistry) builderName(changeType differ.ChangeType) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", r.builders[changeType]), "*generator.")
+}
+
 func (r *DDLBuilderRegistry) BuildUp(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
@@ -96,6 +139,22 @@ func (r *DDLBuilderRegistry) BuildUp(
 func (r *DDLBuilderRegistry) BuildDown(
 	change differ.Change,
 	ddlBuilder *DDLBuilder,
+) (DDLStatement, error) {
+	stmt, err := r.buildDown(change, ddlBuilder)
+	if err != nil {
+		return DDLStatement{}, err
+	}
+
+	if classifier, ok := r.builders[change.Type].(RollbackClassifier); ok {
+		stmt.Reversibility, stmt.RollbackNote = classifier.ClassifyDown(change)
+	}
+
+	return stmt, nil
+}
+
+func (r *DDLBuilderRegistry) buildDown(
+	change differ.Change,
+	ddlBuilder *DDLBuilder,
 ) (DDLStatement, error) {
 	inverseMap := map[differ.ChangeType]differ.ChangeType{
 		differ.ChangeTypeAddSchema:               differ.ChangeTypeDropSchema,
@@ -126,12 +185,16 @@ func (r *DDLBuilderRegistry) BuildDown(
 		differ.ChangeTypeDropTrigger:             differ.ChangeTypeAddTrigger,
 		differ.ChangeTypeAddHypertable:           differ.ChangeTypeDropHypertable,
 		differ.ChangeTypeDropHypertable:          differ.ChangeTypeAddHypertable,
+		differ.ChangeTypeAddDimension:            differ.ChangeTypeDropDimension,
+		differ.ChangeTypeDropDimension:           differ.ChangeTypeAddDimension,
 		differ.ChangeTypeAddCompressionPolicy:    differ.ChangeTypeDropCompressionPolicy,
 		differ.ChangeTypeDropCompressionPolicy:   differ.ChangeTypeAddCompressionPolicy,
 		differ.ChangeTypeAddRetentionPolicy:      differ.ChangeTypeDropRetentionPolicy,
 		differ.ChangeTypeDropRetentionPolicy:     differ.ChangeTypeAddRetentionPolicy,
 		differ.ChangeTypeAddContinuousAggregate:  differ.ChangeTypeDropContinuousAggregate,
 		differ.ChangeTypeDropContinuousAggregate: differ.ChangeTypeAddContinuousAggregate,
+		differ.ChangeTypeAddRefreshPolicy:        differ.ChangeTypeDropRefreshPolicy,
+		differ.ChangeTypeDropRefreshPolicy:       differ.ChangeTypeAddRefreshPolicy,
 	}
 
 	reverseMap := map[differ.ChangeType]differ.ChangeType{
@@ -139,16 +202,41 @@ func (r *DDLBuilderRegistry) BuildDown(
 		differ.ChangeTypeModifyColumnNullability:   differ.ChangeTypeModifyColumnNullability,
 		differ.ChangeTypeModifyColumnDefault:       differ.ChangeTypeModifyColumnDefault,
 		differ.ChangeTypeModifyColumnComment:       differ.ChangeTypeModifyColumnComment,
+		differ.ChangeTypeModifyColumnStorage:       differ.ChangeTypeModifyColumnStorage,
+		differ.ChangeTypeModifyColumnCompression:   differ.ChangeTypeModifyColumnCompression,
+		differ.ChangeTypeModifyColumnGeneration:    differ.ChangeTypeModifyColumnGeneration,
+		differ.ChangeTypeAddColumnIdentity:         differ.ChangeTypeAddColumnIdentity,
+		differ.ChangeTypeDropColumnIdentity:        differ.ChangeTypeDropColumnIdentity,
+		differ.ChangeTypeModifyColumnIdentity:      differ.ChangeTypeModifyColumnIdentity,
+		differ.ChangeTypeRenameColumn:              differ.ChangeTypeRenameColumn,
 		differ.ChangeTypeModifyExtension:           differ.ChangeTypeModifyExtension,
+		differ.ChangeTypeModifySequence:            differ.ChangeTypeModifySequence,
 		differ.ChangeTypeModifyTableComment:        differ.ChangeTypeModifyTableComment,
+		differ.ChangeTypeModifySchemaComment:       differ.ChangeTypeModifySchemaComment,
+		differ.ChangeTypeRecreateTable:             differ.ChangeTypeRecreateTable,
+		differ.ChangeTypeModifyCustomType:          differ.ChangeTypeModifyCustomType,
+		differ.ChangeTypeModifyCustomTypeComment:   differ.ChangeTypeModifyCustomTypeComment,
 		differ.ChangeTypeModifyView:                differ.ChangeTypeModifyView,
 		differ.ChangeTypeModifyMaterializedView:    differ.ChangeTypeModifyMaterializedView,
 		differ.ChangeTypeModifyFunction:            differ.ChangeTypeModifyFunction,
 		differ.ChangeTypeModifyCompressionPolicy:   differ.ChangeTypeModifyCompressionPolicy,
+		differ.ChangeTypeModifyCompressionSchedule: differ.ChangeTypeModifyCompressionSchedule,
+		differ.ChangeTypeModifyDimension:           differ.ChangeTypeModifyDimension,
 		differ.ChangeTypeModifyContinuousAggregate: differ.ChangeTypeModifyContinuousAggregate,
+		differ.ChangeTypeModifyRefreshPolicy:       differ.ChangeTypeModifyRefreshPolicy,
+		differ.ChangeTypeModifyCACompression:       differ.ChangeTypeModifyCACompression,
 		differ.ChangeTypeModifyConstraint:          differ.ChangeTypeModifyConstraint,
+		differ.ChangeTypeModifyConstraintComment:   differ.ChangeTypeModifyConstraintComment,
 		differ.ChangeTypeModifyIndex:               differ.ChangeTypeModifyIndex,
+		differ.ChangeTypeModifyIndexComment:        differ.ChangeTypeModifyIndexComment,
 		differ.ChangeTypeModifyTrigger:             differ.ChangeTypeModifyTrigger,
+		differ.ChangeTypeModifyTriggerComment:      differ.ChangeTypeModifyTriggerComment,
+		differ.ChangeTypeAddPolicy:                 differ.ChangeTypeAddPolicy,
+		differ.ChangeTypeDropPolicy:                differ.ChangeTypeDropPolicy,
+		differ.ChangeTypeModifyPolicy:              differ.ChangeTypeModifyPolicy,
+		differ.ChangeTypeModifyTableRowSecurity:    differ.ChangeTypeModifyTableRowSecurity,
+		differ.ChangeTypeAddGrant:                  differ.ChangeTypeAddGrant,
+		differ.ChangeTypeRevokeGrant:               differ.ChangeTypeRevokeGrant,
 	}
 
 	var targetType differ.ChangeType
@@ -181,12 +269,18 @@ func (r *DDLBuilderRegistry) BuildDown(
 			return ddlBuilder.buildAddTableForDown(change)
 		case differ.ChangeTypeDropTrigger:
 			return ddlBuilder.buildAddTriggerForDown(change)
+		case differ.ChangeTypeDropIndex:
+			return ddlBuilder.buildAddIndexForDown(change)
+		case differ.ChangeTypeDropSchema:
+			return ddlBuilder.buildAddSchemaForDown(change)
 		case differ.ChangeTypeDropView:
 			return ddlBuilder.buildAddViewForDown(change)
 		case differ.ChangeTypeDropMaterializedView:
 			return ddlBuilder.buildAddMaterializedViewForDown(change)
 		case differ.ChangeTypeDropFunction:
 			return ddlBuilder.buildAddFunctionForDown(change)
+		case differ.ChangeTypeDropHypertable:
+			return ddlBuilder.buildAddHypertable(change, true)
 		}
 
 		inverseChange := change
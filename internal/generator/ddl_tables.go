@@ -3,6 +3,7 @@ package generator
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
@@ -10,7 +11,13 @@ import (
 )
 
 func (b *DDLBuilder) buildAddTable(change differ.Change) (DDLStatement, error) {
-	table := b.getTable(change.ObjectName, b.result.Desired)
+	// The differ may attach an adjusted copy of the desired table, e.g. with
+	// foreign keys moved to a later ADD CONSTRAINT to break a cycle.
+	table, _ := change.Details[DetailKeyTable.String()].(*schema.Table)
+	if table == nil {
+		table = b.getTable(change.ObjectName, b.result.Desired)
+	}
+
 	if table == nil {
 		return DDLStatement{}, newGeneratorError(
 			"buildAddTable",
@@ -61,8 +68,8 @@ func (b *DDLBuilder) buildDropTable(change differ.Change) (DDLStatement, error)
 		)
 	}
 
-	sql := fmt.Sprintf("DROP TABLE %s%s CASCADE;",
-		b.ifExists(), QualifiedName(table.Schema, table.Name))
+	sql := fmt.Sprintf("DROP TABLE %s%s%s;",
+		b.ifExists(), QualifiedName(table.Schema, table.Name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -100,12 +107,24 @@ func (b *DDLBuilder) buildAddColumn(change differ.Change) (DDLStatement, error)
 	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;",
 		QualifiedName(table.Schema, table.Name),
 		definition)
+	description := fmt.Sprintf("Add column %s.%s", table.Name, column.Name)
+
+	if storage := formatColumnStorage(table.Schema, table.Name, column); storage != "" {
+		sql += "\n" + storage
+	}
+
+	// The table already has rows, which the new sequence knows nothing about.
+	if serialTypeFor(column) != "" {
+		sql += "\n\n" + formatSequenceResync(table.Schema, table.Name, column.Name)
+		description = withSequenceNote(description, []string{column.Name})
+	}
 
-	isUnsafe := !column.IsNullable && column.Default == ""
+	// An identity column fills the existing rows from its sequence.
+	isUnsafe := !column.IsNullable && column.Default == "" && column.Identity == nil
 
 	stmt := DDLStatement{
 		SQL:         sql,
-		Description: fmt.Sprintf("Add column %s.%s", table.Name, column.Name),
+		Description: description,
 		IsUnsafe:    isUnsafe,
 		RequiresTx:  true,
 	}
@@ -206,11 +225,54 @@ func (b *DDLBuilder) buildColumnTypeChange(
 		)
 	}
 
-	sql := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s;",
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s",
 		QualifiedName(table.Schema, table.Name),
 		QuoteIdentifier(columnName),
 		dataType)
 
+	// Only the precision of a time type changes: raising it keeps every
+	// value without a rewrite, and lowering it rounds them.
+	if precisionChange, ok := change.Details[differ.DetailKeyPrecisionChange].(string); ok {
+		widens := (precisionChange == differ.PrecisionChangeWiden) == (typeKey == DetailKeyNewType)
+
+		description := "Reduce precision of column %s.%s"
+		if widens {
+			description = "Widen precision of column %s.%s"
+		}
+
+		return DDLStatement{
+			SQL:         alter + ";",
+			Description: fmt.Sprintf(description, table.Name, columnName),
+			IsUnsafe:    !widens,
+			RequiresTx:  true,
+		}, nil
+	}
+
+	fromKey := DetailKeyOldType
+	if typeKey == DetailKeyOldType {
+		fromKey = DetailKeyNewType
+	}
+
+	fromType, _ := change.Details[fromKey.String()].(string)
+
+	var expression string
+	if column := table.GetColumn(columnName); column != nil {
+		expression = column.UsingExpression
+	}
+
+	using, needsUsing := columnTypeUsing(columnName, fromType, dataType, expression)
+
+	sql := alter + ";"
+	if using != "" {
+		sql = alter + " " + using + ";"
+	}
+
+	if needsUsing {
+		sql = fmt.Sprintf("-- TODO: %s does not convert to %s without a USING clause. Add one, "+
+			"or declare it on the column with -- pgtofu:using <expression>\n%s",
+			fromType, dataType, sql)
+	}
+
 	return DDLStatement{
 		SQL:         sql,
 		Description: fmt.Sprintf("%s column type %s.%s", action, table.Name, columnName),
@@ -220,6 +282,10 @@ func (b *DDLBuilder) buildColumnTypeChange(
 }
 
 func (b *DDLBuilder) buildModifyColumnNullability(change differ.Change) (DDLStatement, error) {
+	if step := validateStep(change); step != "" {
+		return b.buildSafeSetNotNull(change, step)
+	}
+
 	stmt, err := b.buildColumnNullabilityChange(
 		change, b.result.Desired, DetailKeyNewNullable, "Modify",
 	)
@@ -238,6 +304,10 @@ func (b *DDLBuilder) buildModifyColumnNullability(change differ.Change) (DDLStat
 func (b *DDLBuilder) buildReverseModifyColumnNullability(
 	change differ.Change,
 ) (DDLStatement, error) {
+	if validateStep(change) == ValidateStepAddNotValid {
+		return b.buildDropNotNullCheck(change)
+	}
+
 	stmt, err := b.buildColumnNullabilityChange(
 		change, b.result.Current, DetailKeyOldNullable, "Revert",
 	)
@@ -303,6 +373,81 @@ func (b *DDLBuilder) buildColumnNullabilityChange(
 	}, nil
 }
 
+// buildSafeSetNotNull builds a step of setting NOT NULL through a CHECK
+// constraint: adding it NOT VALID, or validating it, setting NOT NULL, which
+// PostgreSQL then does without scanning the table, and dropping it.
+func (b *DDLBuilder) buildSafeSetNotNull(change differ.Change, step string) (DDLStatement, error) {
+	table, columnName, err := b.notNullCheckTarget(change)
+	if err != nil {
+		return DDLStatement{}, err
+	}
+
+	qualified := QualifiedName(table.Schema, table.Name)
+	check := QuoteIdentifier(notNullCheckName(table.Name, columnName))
+	column := QuoteIdentifier(columnName)
+
+	if step == ValidateStepAddNotValid {
+		return DDLStatement{
+			SQL: fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s IS NOT NULL) NOT VALID;",
+				qualified, check, column),
+			Description: fmt.Sprintf("Add not-null check %s.%s", table.Name, columnName),
+			RequiresTx:  true,
+		}, nil
+	}
+
+	return DDLStatement{
+		Statements: []string{
+			fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s", qualified, check),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", qualified, column),
+			fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s%s", qualified, b.ifExists(), check),
+		},
+		Description: fmt.Sprintf("Modify column nullability %s.%s", table.Name, columnName),
+		IsUnsafe:    true,
+		RequiresTx:  true,
+	}, nil
+}
+
+// buildDropNotNullCheck reverts adding the NOT VALID not-null check. When the
+// validation has already been rolled back, or has run, the check is gone, so
+// IF EXISTS is always used here.
+func (b *DDLBuilder) buildDropNotNullCheck(change differ.Change) (DDLStatement, error) {
+	table, columnName, err := b.notNullCheckTarget(change)
+	if err != nil {
+		return DDLStatement{}, err
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;",
+			QualifiedName(table.Schema, table.Name),
+			QuoteIdentifier(notNullCheckName(table.Name, columnName))),
+		Description: fmt.Sprintf("Drop not-null check %s.%s", table.Name, columnName),
+		RequiresTx:  true,
+	}, nil
+}
+
+func (b *DDLBuilder) notNullCheckTarget(change differ.Change) (*schema.Table, string, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return nil, "", newGeneratorError("notNullCheckTarget", &change, err)
+	}
+
+	columnName, err := getDetailString(change.Details, DetailKeyColumnName)
+	if err != nil {
+		return nil, "", newGeneratorError("notNullCheckTarget", &change, err)
+	}
+
+	table := b.getTable(tableName, b.result.Desired)
+	if table == nil {
+		return nil, "", newGeneratorError(
+			"notNullCheckTarget",
+			&change,
+			wrapObjectNotFoundError(ErrTableNotFound, "table", tableName),
+		)
+	}
+
+	return table, columnName, nil
+}
+
 func (b *DDLBuilder) buildModifyColumnDefault(change differ.Change) (DDLStatement, error) {
 	return b.buildColumnDefaultChange(change, b.result.Desired, DetailKeyNewDefault, "Modify")
 }
@@ -362,6 +507,365 @@ func (b *DDLBuilder) buildColumnDefaultChange(
 	}, nil
 }
 
+// buildRenameColumn renames a column from the name under fromKey to the one
+// under toKey. Indexes, constraints and views that use the column follow it,
+// since PostgreSQL refers to columns by number rather than by name.
+func (b *DDLBuilder) buildRenameColumn(
+	change differ.Change,
+	fromKey, toKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRenameColumn", &change, err)
+	}
+
+	from, err := getDetailString(change.Details, fromKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRenameColumn", &change, err)
+	}
+
+	to, err := getDetailString(change.Details, toKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRenameColumn", &change, err)
+	}
+
+	schemaName, name := parseSchemaAndName(tableName)
+	if schemaName == "" {
+		schemaName = schema.DefaultSchema
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;",
+			QualifiedName(schemaName, name), QuoteIdentifier(from), QuoteIdentifier(to)),
+		Description: fmt.Sprintf("%s column %s.%s to %s", action, name, from, to),
+		RequiresTx:  true,
+	}, nil
+}
+
+func (b *DDLBuilder) buildModifyColumnStorage(change differ.Change) (DDLStatement, error) {
+	return b.buildColumnAttributeChange(change, "STORAGE", DetailKeyNewStorage, "Modify")
+}
+
+func (b *DDLBuilder) buildReverseModifyColumnStorage(change differ.Change) (DDLStatement, error) {
+	return b.buildColumnAttributeChange(change, "STORAGE", DetailKeyOldStorage, "Revert")
+}
+
+func (b *DDLBuilder) buildModifyColumnCompression(change differ.Change) (DDLStatement, error) {
+	return b.buildColumnAttributeChange(change, "COMPRESSION", DetailKeyNewCompression, "Modify")
+}
+
+func (b *DDLBuilder) buildReverseModifyColumnCompression(
+	change differ.Change,
+) (DDLStatement, error) {
+	return b.buildColumnAttributeChange(change, "COMPRESSION", DetailKeyOldCompression, "Revert")
+}
+
+func (b *DDLBuilder) buildModifyColumnGeneration(change differ.Change) (DDLStatement, error) {
+	return b.buildColumnGenerationChange(change, b.result.Current, b.result.Desired,
+		DetailKeyOldColumn, DetailKeyNewColumn, "Modify")
+}
+
+func (b *DDLBuilder) buildReverseModifyColumnGeneration(
+	change differ.Change,
+) (DDLStatement, error) {
+	return b.buildColumnGenerationChange(change, b.result.Desired, b.result.Current,
+		DetailKeyNewColumn, DetailKeyOldColumn, "Revert")
+}
+
+// buildColumnGenerationChange turns the column under fromKey, of the table in
+// from, into the one under toKey, of the table in to. A column that stops
+// being generated keeps its values with DROP EXPRESSION. Any other change
+// drops the column and adds it again, so PostgreSQL computes the new
+// expression for every row. The comment and the indexes and constraints on the
+// column that both tables have are dropped with it, so they are created again;
+// the others have changes of their own.
+func (b *DDLBuilder) buildColumnGenerationChange(
+	change differ.Change,
+	from, to *schema.Database,
+	fromKey, toKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnGenerationChange", &change, err)
+	}
+
+	fromCol, err := requireDetail[*schema.Column](change.Details, fromKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnGenerationChange", &change, err)
+	}
+
+	col, err := requireDetail[*schema.Column](change.Details, toKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnGenerationChange", &change, err)
+	}
+
+	table := b.getTable(tableName, to)
+	fromTable := b.getTable(tableName, from)
+
+	if table == nil || fromTable == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildColumnGenerationChange",
+			&change,
+			wrapObjectNotFoundError(ErrTableNotFound, "table", tableName),
+		)
+	}
+
+	qualifiedTable := QualifiedName(table.Schema, table.Name)
+
+	if fromCol.IsGenerated && !col.IsGenerated {
+		stmt := DDLStatement{
+			SQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP EXPRESSION;",
+				qualifiedTable, QuoteIdentifier(fromCol.Name)),
+			Description: fmt.Sprintf("%s column %s.%s to a plain column",
+				action, table.Name, fromCol.Name),
+			RequiresTx: true,
+		}
+
+		return b.wrapWithCompressionToggle(stmt, tableName)
+	}
+
+	definition, err := formatColumnDefinition(col)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnGenerationChange", &change, err)
+	}
+
+	var sb strings.Builder
+	appendStatement(&sb, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;",
+		qualifiedTable, QuoteIdentifier(fromCol.Name)))
+	appendStatement(&sb, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;",
+		qualifiedTable, definition))
+
+	if storage := formatColumnStorage(table.Schema, table.Name, col); storage != "" {
+		appendStatement(&sb, storage)
+	}
+
+	if col.Comment != "" {
+		appendStatement(&sb, buildCommentStatement("COLUMN",
+			qualifiedTable+"."+QuoteIdentifier(col.Name), col.Comment, false))
+	}
+
+	usesColumn := func(columns ...[]string) bool {
+		return slices.ContainsFunc(slices.Concat(columns...), func(name string) bool {
+			return strings.EqualFold(name, col.Name)
+		})
+	}
+
+	for i := range table.Constraints {
+		constraint := &table.Constraints[i]
+		if !usesColumn(constraint.Columns) || fromTable.GetConstraint(constraint.Name) == nil {
+			continue
+		}
+
+		constraintDef, err := formatConstraintDefinition(constraint)
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildColumnGenerationChange", &change, err)
+		}
+
+		appendStatement(&sb, fmt.Sprintf("ALTER TABLE %s ADD %s;", qualifiedTable, constraintDef))
+	}
+
+	for i := range table.Indexes {
+		idx := &table.Indexes[i]
+		if isConstraintIndex(table, idx.Name) || !usesColumn(idx.Columns, idx.IncludeColumns) ||
+			fromTable.GetIndex(idx.Name) == nil {
+			continue
+		}
+
+		indexSQL, err := b.buildIndexSQL(idx)
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildColumnGenerationChange", &change, err)
+		}
+
+		appendStatement(&sb, indexSQL)
+	}
+
+	stmt := DDLStatement{
+		SQL: strings.TrimSpace(sb.String()),
+		Description: fmt.Sprintf("%s column %s.%s by dropping and re-adding it",
+			action, table.Name, col.Name),
+		IsUnsafe:   true,
+		RequiresTx: true,
+	}
+
+	return b.wrapWithCompressionToggle(stmt, tableName)
+}
+
+func (b *DDLBuilder) buildModifyColumnIdentity(change differ.Change) (DDLStatement, error) {
+	return b.buildColumnIdentityChange(change, b.result.Desired,
+		DetailKeyOldColumn, DetailKeyNewColumn, "Modify")
+}
+
+func (b *DDLBuilder) buildReverseModifyColumnIdentity(change differ.Change) (DDLStatement, error) {
+	return b.buildColumnIdentityChange(change, b.result.Current,
+		DetailKeyNewColumn, DetailKeyOldColumn, "Revert")
+}
+
+// buildColumnIdentityChange turns the column under fromKey into the identity
+// column, or the plain column, under toKey, of the table in to. A new identity
+// sequence is moved past the values the column already holds. DROP IDENTITY
+// drops the sequence, so a column going back to SERIAL gets one again unless
+// to declares it and a change of its own creates it.
+func (b *DDLBuilder) buildColumnIdentityChange(
+	change differ.Change,
+	to *schema.Database,
+	fromKey, toKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnIdentityChange", &change, err)
+	}
+
+	fromCol, err := requireDetail[*schema.Column](change.Details, fromKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnIdentityChange", &change, err)
+	}
+
+	col, err := requireDetail[*schema.Column](change.Details, toKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnIdentityChange", &change, err)
+	}
+
+	enforceStart, _, err := optionalDetail[bool](change.Details, DetailKeyEnforceStart)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnIdentityChange", &change, err)
+	}
+
+	table := b.getTable(tableName, to)
+	if table == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildColumnIdentityChange",
+			&change,
+			wrapObjectNotFoundError(ErrTableNotFound, "table", tableName),
+		)
+	}
+
+	qualifiedTable := QualifiedName(table.Schema, table.Name)
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", qualifiedTable, QuoteIdentifier(col.Name))
+
+	var sb strings.Builder
+
+	switch {
+	case fromCol.Identity == nil:
+		appendStatement(&sb, fmt.Sprintf("%s ADD %s;", alter, formatIdentity(col)))
+		appendStatement(&sb, formatIdentityResync(table.Schema, table.Name, col))
+	case col.Identity == nil:
+		appendStatement(&sb, alter+" DROP IDENTITY;")
+
+		if name := defaultSequenceName(col); name != "" && serialTypeFor(col) != "" {
+			seqSchema, seqName := parseSchemaAndName(name)
+			if seqSchema == "" {
+				seqSchema = table.Schema
+			}
+
+			if b.getSequence(schema.QualifiedName(seqSchema, seqName), to) == nil {
+				appendStatement(&sb, fmt.Sprintf("CREATE SEQUENCE %s AS %s OWNED BY %s.%s;",
+					QualifiedName(seqSchema, seqName), strings.ToLower(col.DataType),
+					qualifiedTable, QuoteIdentifier(col.Name)))
+				appendStatement(&sb, formatSequenceResync(table.Schema, table.Name, col.Name))
+			}
+		}
+	default:
+		var buf tokenBuffer
+		buf.Write(alter)
+
+		from, target := fromCol.Identity, col.Identity
+
+		if from.Generation != target.Generation {
+			buf.Write("SET GENERATED " + target.Generation)
+		}
+
+		if from.Increment != target.Increment {
+			buf.Write(fmt.Sprintf("SET INCREMENT BY %d", target.Increment))
+		}
+
+		if from.MinValue != target.MinValue {
+			buf.Write(fmt.Sprintf("SET MINVALUE %d", target.MinValue))
+		}
+
+		if from.MaxValue != target.MaxValue {
+			buf.Write(fmt.Sprintf("SET MAXVALUE %d", target.MaxValue))
+		}
+
+		if enforceStart && from.StartValue != target.StartValue {
+			buf.Write(fmt.Sprintf("SET START WITH %d", target.StartValue))
+		}
+
+		if max(from.CacheSize, 1) != max(target.CacheSize, 1) {
+			buf.Write(fmt.Sprintf("SET CACHE %d", max(target.CacheSize, 1)))
+		}
+
+		if from.IsCyclic != target.IsCyclic {
+			if target.IsCyclic {
+				buf.Write("SET CYCLE")
+			} else {
+				buf.Write("SET NO CYCLE")
+			}
+		}
+
+		appendStatement(&sb, buf.String())
+	}
+
+	return DDLStatement{
+		SQL:         strings.TrimSpace(sb.String()),
+		Description: fmt.Sprintf("%s column identity %s.%s", action, table.Name, col.Name),
+		RequiresTx:  true,
+	}, nil
+}
+
+// buildColumnAttributeChange sets the storage strategy or compression method
+// of a column. Neither decompresses or rewrites existing rows; they apply to
+// values written afterwards, so the statement runs on a compressed
+// hypertable without toggling its compression.
+func (b *DDLBuilder) buildColumnAttributeChange(
+	change differ.Change,
+	attribute string,
+	valueKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnAttributeChange", &change, err)
+	}
+
+	columnName, err := getDetailString(change.Details, DetailKeyColumnName)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnAttributeChange", &change, err)
+	}
+
+	value, err := getDetailString(change.Details, valueKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildColumnAttributeChange", &change, err)
+	}
+
+	// Storage strategies are keywords; compression methods are names, written
+	// as PostgreSQL lists them.
+	switch {
+	case value == "":
+		value = "DEFAULT"
+	case attribute == "STORAGE":
+		value = strings.ToUpper(value)
+	}
+
+	schemaName, name := parseSchemaAndName(tableName)
+	if schemaName == "" {
+		schemaName = schema.DefaultSchema
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET %s %s;",
+			QualifiedName(schemaName, name),
+			QuoteIdentifier(columnName),
+			attribute,
+			value),
+		Description: fmt.Sprintf("%s column %s %s.%s",
+			action, strings.ToLower(attribute), name, columnName),
+		RequiresTx: true,
+	}, nil
+}
+
 func (b *DDLBuilder) buildModifyTableComment(change differ.Change) (DDLStatement, error) {
 	return b.buildTableCommentChange(change, b.result.Desired, DetailKeyNewComment, "Modify")
 }
@@ -548,11 +1052,30 @@ func (b *DDLBuilder) buildAddConstraint(change differ.Change) (DDLStatement, err
 		)
 	}
 
+	step, _, err := getOptionalDetailString(change.Details, DetailKeyUniqueStep)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAddConstraint", &change, err)
+	}
+
+	switch step {
+	case UniqueStepBuildIndex:
+		return b.buildUniqueConstraintIndex(table, constraint), nil
+	case UniqueStepPromote:
+		return b.buildPromoteUniqueConstraint(table, constraint), nil
+	}
+
 	definition, err := formatConstraintDefinition(constraint)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildAddConstraint", &change, err)
 	}
 
+	switch validateStep(change) {
+	case ValidateStepAddNotValid:
+		definition += " NOT VALID"
+	case ValidateStepValidate:
+		return b.buildValidateConstraint(table, constraint.Name), nil
+	}
+
 	sql := fmt.Sprintf("ALTER TABLE %s ADD %s;",
 		QualifiedName(table.Schema, table.Name),
 		definition)
@@ -567,6 +1090,92 @@ func (b *DDLBuilder) buildAddConstraint(change differ.Change) (DDLStatement, err
 	return b.wrapWithCompressionToggle(stmt, tableName)
 }
 
+func (b *DDLBuilder) buildValidateConstraint(table *schema.Table, name string) DDLStatement {
+	return DDLStatement{
+		SQL: fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;",
+			QualifiedName(table.Schema, table.Name),
+			QuoteIdentifier(name)),
+		Description: fmt.Sprintf("Validate constraint %s.%s", table.Name, name),
+		RequiresTx:  true,
+	}
+}
+
+// buildUniqueConstraintIndex builds the index that will back a new UNIQUE
+// constraint. The index is named after the constraint, which is the name
+// PostgreSQL gives it once promoted.
+func (b *DDLBuilder) buildUniqueConstraintIndex(
+	table *schema.Table,
+	constraint *schema.Constraint,
+) DDLStatement {
+	include := ""
+	if len(constraint.IncludeColumns) > 0 {
+		include = fmt.Sprintf(" INCLUDE (%s)", quoteColumns(constraint.IncludeColumns))
+	}
+
+	sql := fmt.Sprintf("CREATE UNIQUE INDEX CONCURRENTLY %s%s ON %s (%s)%s;",
+		b.ifNotExists(),
+		QuoteIdentifier(constraint.Name),
+		QualifiedName(table.Schema, table.Name),
+		quoteColumns(constraint.Columns),
+		include)
+
+	return DDLStatement{
+		SQL:         sql,
+		Description: fmt.Sprintf("Build unique index %s.%s", table.Name, constraint.Name),
+		CannotUseTx: true,
+	}
+}
+
+func (b *DDLBuilder) buildPromoteUniqueConstraint(
+	table *schema.Table,
+	constraint *schema.Constraint,
+) DDLStatement {
+	sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE USING INDEX %s",
+		QualifiedName(table.Schema, table.Name),
+		QuoteIdentifier(constraint.Name),
+		QuoteIdentifier(constraint.Name))
+
+	if constraint.IsDeferrable {
+		sql += " DEFERRABLE"
+		if constraint.InitiallyDeferred {
+			sql += " INITIALLY DEFERRED"
+		}
+	}
+
+	return DDLStatement{
+		SQL:         sql + ";",
+		Description: fmt.Sprintf("Promote unique index %s.%s", table.Name, constraint.Name),
+		RequiresTx:  true,
+	}
+}
+
+// buildDropUniqueConstraintIndex reverts the concurrent index build. When the
+// promotion has already been rolled back, dropping the constraint removed the
+// index too, so IF EXISTS is always used here.
+func (b *DDLBuilder) buildDropUniqueConstraintIndex(change differ.Change) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildDropUniqueConstraintIndex", &change, err)
+	}
+
+	constraint, err := getDetailConstraint(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildDropUniqueConstraintIndex", &change, err)
+	}
+
+	schemaName, name := parseSchemaAndName(tableName)
+	if schemaName == "" {
+		schemaName = schema.DefaultSchema
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s;",
+			QualifiedName(schemaName, constraint.Name)),
+		Description: fmt.Sprintf("Drop unique index %s.%s", name, constraint.Name),
+		CannotUseTx: true,
+	}, nil
+}
+
 func (b *DDLBuilder) buildDropConstraint(change differ.Change) (DDLStatement, error) {
 	tableName, err := getDetailString(change.Details, DetailKeyTable)
 	if err != nil {
@@ -578,11 +1187,27 @@ func (b *DDLBuilder) buildDropConstraint(change differ.Change) (DDLStatement, er
 		return DDLStatement{}, newGeneratorError("buildDropConstraint", &change, err)
 	}
 
+	// Rolling back the index build of a split UNIQUE constraint only drops the index.
+	step, _, _ := getOptionalDetailString(change.Details, DetailKeyUniqueStep)
+	if step == UniqueStepBuildIndex {
+		return b.buildDropUniqueConstraintIndex(change)
+	}
+
 	schemaName, name := parseSchemaAndName(tableName)
 	if schemaName == "" {
 		schemaName = schema.DefaultSchema
 	}
 
+	// Validating a NOT VALID constraint has nothing to undo: the down
+	// migration of the step that added it drops the constraint.
+	if validateStep(change) == ValidateStepValidate {
+		return DDLStatement{
+			SQL: fmt.Sprintf("-- Nothing to undo: the previous migration's down drops constraint %s",
+				constraint.Name),
+			Description: "Keep validated constraint " + constraint.Name,
+		}, nil
+	}
+
 	sql := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s%s;",
 		QualifiedName(schemaName, name),
 		b.ifExists(),
@@ -598,6 +1223,76 @@ func (b *DDLBuilder) buildDropConstraint(change differ.Change) (DDLStatement, er
 	return b.wrapWithCompressionToggle(stmt, tableName)
 }
 
+// buildConstraintComment sets the constraint comment held under commentKey;
+// an empty comment becomes COMMENT ON CONSTRAINT ... IS NULL.
+func (b *DDLBuilder) buildConstraintComment(
+	change differ.Change,
+	commentKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildConstraintComment", &change, err)
+	}
+
+	constraintName, err := getDetailString(change.Details, DetailKeyConstraintName)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildConstraintComment", &change, err)
+	}
+
+	comment, _, err := getOptionalDetailString(change.Details, commentKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildConstraintComment", &change, err)
+	}
+
+	schemaName, name := parseSchemaAndName(tableName)
+	if schemaName == "" {
+		schemaName = schema.DefaultSchema
+	}
+
+	sql := buildConstraintCommentStatement(QualifiedName(schemaName, name), constraintName, comment)
+
+	return DDLStatement{
+		SQL:         sql,
+		Description: fmt.Sprintf("%s constraint comment %s.%s", action, name, constraintName),
+		RequiresTx:  true,
+	}, nil
+}
+
+func (b *DDLBuilder) buildIndexComment(
+	change differ.Change,
+	commentKey DetailKey,
+	action string,
+) (DDLStatement, error) {
+	index, err := getDetailIndex(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildIndexComment", &change, err)
+	}
+
+	comment, _, err := getOptionalDetailString(change.Details, commentKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildIndexComment", &change, err)
+	}
+
+	return DDLStatement{
+		SQL: buildCommentStatement(
+			"INDEX", QualifiedName(index.Schema, index.Name), comment, false),
+		Description: action + " index comment " + index.Name,
+		RequiresTx:  true,
+	}, nil
+}
+
+// appendIndexComment follows sql with the COMMENT ON INDEX that gives idx its
+// comment, if it has one.
+func appendIndexComment(sql string, idx *schema.Index) string {
+	if idx.Comment == "" {
+		return sql
+	}
+
+	return sql + "\n" + buildCommentStatement(
+		"INDEX", QualifiedName(idx.Schema, idx.Name), idx.Comment, false)
+}
+
 func (b *DDLBuilder) buildModifyConstraint(change differ.Change) (DDLStatement, error) {
 	tableName, err := getDetailString(change.Details, DetailKeyTable)
 	if err != nil {
@@ -621,6 +1316,16 @@ func (b *DDLBuilder) buildModifyConstraint(change differ.Change) (DDLStatement,
 
 	qualifiedTable := QualifiedName(schemaName, name)
 
+	if isRenameOnly(change) {
+		sql := formatRenameConstraint(qualifiedTable, currentConstraint, desiredConstraint)
+
+		return DDLStatement{
+			SQL:         sql,
+			Description: fmt.Sprintf("Rename constraint %s.%s", name, desiredConstraint.Name),
+			RequiresTx:  true,
+		}, nil
+	}
+
 	dropSQL := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s%s;",
 		qualifiedTable,
 		b.ifExists(),
@@ -668,6 +1373,16 @@ func (b *DDLBuilder) buildReverseModifyConstraint(change differ.Change) (DDLStat
 
 	qualifiedTable := QualifiedName(schemaName, name)
 
+	if isRenameOnly(change) {
+		sql := formatRenameConstraint(qualifiedTable, desiredConstraint, currentConstraint)
+
+		return DDLStatement{
+			SQL:         sql,
+			Description: fmt.Sprintf("Revert constraint name %s.%s", name, currentConstraint.Name),
+			RequiresTx:  true,
+		}, nil
+	}
+
 	dropSQL := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s%s;",
 		qualifiedTable,
 		b.ifExists(),
@@ -692,30 +1407,144 @@ func (b *DDLBuilder) buildReverseModifyConstraint(change differ.Change) (DDLStat
 	return b.wrapWithCompressionToggle(stmt, tableName)
 }
 
+// formatRenameConstraint renames a constraint in place. A primary key or
+// unique constraint takes its index with it.
+func formatRenameConstraint(qualifiedTable string, from, to *schema.Constraint) string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s;",
+		qualifiedTable,
+		QuoteIdentifier(from.Name),
+		QuoteIdentifier(to.Name))
+}
+
+func isRenameOnly(change differ.Change) bool {
+	renameOnly, _ := change.Details[differ.DetailKeyRenameOnly].(bool)
+	return renameOnly
+}
+
 func (b *DDLBuilder) buildAddIndex(change differ.Change) (DDLStatement, error) {
 	index, err := getDetailIndex(change.Details)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildAddIndex", &change, err)
 	}
 
-	sql, err := formatIndexDefinition(index)
+	if isConcurrent(change) {
+		sql, err := formatCreateIndex(index, "CONCURRENTLY "+b.ifNotExists())
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildAddIndex", &change, err)
+		}
+
+		return DDLStatement{
+			SQL:         ensureStatementTerminated(sql),
+			Description: "Add index " + index.Name + " concurrently",
+			CannotUseTx: true,
+		}, nil
+	}
+
+	sql, err := b.buildIndexSQL(index)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildAddIndex", &change, err)
 	}
 
 	return DDLStatement{
-		SQL:         ensureStatementTerminated(sql),
+		SQL:         sql,
 		Description: "Add index " + index.Name,
 		RequiresTx:  true,
 	}, nil
 }
 
+// buildAddIndexForDown restores a dropped index with its comment; a new index
+// gets its comment from a comment change of its own. An index built
+// concurrently runs alone, so its comment is not restored.
+func (b *DDLBuilder) buildAddIndexForDown(change differ.Change) (DDLStatement, error) {
+	stmt, err := b.buildAddIndex(change)
+	if err != nil || isConcurrent(change) {
+		return stmt, err
+	}
+
+	index, err := getDetailIndex(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAddIndexForDown", &change, err)
+	}
+
+	stmt.SQL = appendIndexComment(stmt.SQL, index)
+
+	return stmt, nil
+}
+
+// buildIndexSQL returns the CREATE INDEX for idx. An ON ONLY index covers
+// the partitioned parent alone and stays invalid until every partition has an
+// index attached to it, so one is created and attached for each partition.
+func (b *DDLBuilder) buildIndexSQL(idx *schema.Index) (string, error) {
+	createSQL, err := formatIndexDefinition(idx)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	appendStatement(&sb, createSQL)
+
+	if !idx.OnlyParent {
+		return sb.String(), nil
+	}
+
+	for _, partition := range b.indexPartitions(idx) {
+		partitionIdx := *idx
+		partitionIdx.TableName = partition.Name
+		partitionIdx.Name = schema.SynthesizedIdentifier(idx.Name, partition.Name)
+		partitionIdx.OnlyParent = false
+
+		partitionSQL, err := formatIndexDefinition(&partitionIdx)
+		if err != nil {
+			return "", err
+		}
+
+		appendStatement(&sb, partitionSQL)
+		appendStatement(&sb, fmt.Sprintf("ALTER INDEX %s ATTACH PARTITION %s;",
+			QualifiedName(idx.Schema, idx.Name),
+			QualifiedName(idx.Schema, partitionIdx.Name)))
+	}
+
+	return sb.String(), nil
+}
+
+// indexPartitions returns the partitions of the index's table, taken from
+// whichever side declares the index.
+func (b *DDLBuilder) indexPartitions(idx *schema.Index) []schema.Partition {
+	for _, db := range []*schema.Database{b.result.Desired, b.result.Current} {
+		if db == nil {
+			continue
+		}
+
+		table := db.GetTable(idx.Schema, idx.TableName)
+		if table == nil || table.GetIndex(idx.Name) == nil {
+			continue
+		}
+
+		if table.PartitionStrategy != nil {
+			return table.PartitionStrategy.Partitions
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
 func (b *DDLBuilder) buildDropIndex(change differ.Change) (DDLStatement, error) {
 	index, err := getDetailIndex(change.Details)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildDropIndex", &change, err)
 	}
 
+	if isConcurrent(change) {
+		return DDLStatement{
+			SQL: fmt.Sprintf("DROP INDEX CONCURRENTLY %s%s;",
+				b.ifExists(), QualifiedName(index.Schema, index.Name)),
+			Description: "Drop index " + index.Name + " concurrently",
+			CannotUseTx: true,
+		}, nil
+	}
+
 	sql := fmt.Sprintf("DROP INDEX %s%s;",
 		b.ifExists(), QualifiedName(index.Schema, index.Name))
 
@@ -732,15 +1561,28 @@ func (b *DDLBuilder) buildModifyIndex(change differ.Change) (DDLStatement, error
 		return DDLStatement{}, newGeneratorError("buildModifyIndex", &change, err)
 	}
 
+	if swapName, _ := change.Details[differ.DetailKeySwapIndex].(string); swapName != "" {
+		sql, err := b.swapIndexSQL(desiredIndex, swapName)
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildModifyIndex", &change, err)
+		}
+
+		return DDLStatement{
+			SQL:         sql,
+			Description: "Swap in new definition of index " + desiredIndex.Name,
+			RequiresTx:  true,
+		}, nil
+	}
+
 	dropSQL := fmt.Sprintf("DROP INDEX %s%s;",
 		b.ifExists(), QualifiedName(currentIndex.Schema, currentIndex.Name))
 
-	createSQL, err := formatIndexDefinition(desiredIndex)
+	createSQL, err := b.buildIndexSQL(desiredIndex)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildModifyIndex", &change, err)
 	}
 
-	sql := dropSQL + "\n" + ensureStatementTerminated(createSQL)
+	sql := dropSQL + "\n" + createSQL
 
 	return DDLStatement{
 		SQL:         sql,
@@ -755,15 +1597,28 @@ func (b *DDLBuilder) buildReverseModifyIndex(change differ.Change) (DDLStatement
 		return DDLStatement{}, newGeneratorError("buildReverseModifyIndex", &change, err)
 	}
 
+	if swapName, _ := change.Details[differ.DetailKeySwapIndex].(string); swapName != "" {
+		sql, err := b.swapIndexSQL(currentIndex, swapName)
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildReverseModifyIndex", &change, err)
+		}
+
+		return DDLStatement{
+			SQL:         sql,
+			Description: "Swap back previous definition of index " + currentIndex.Name,
+			RequiresTx:  true,
+		}, nil
+	}
+
 	dropSQL := fmt.Sprintf("DROP INDEX %s%s;",
 		b.ifExists(), QualifiedName(desiredIndex.Schema, desiredIndex.Name))
 
-	createSQL, err := formatIndexDefinition(currentIndex)
+	createSQL, err := b.buildIndexSQL(currentIndex)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildReverseModifyIndex", &change, err)
 	}
 
-	sql := dropSQL + "\n" + ensureStatementTerminated(createSQL)
+	sql := appendIndexComment(dropSQL+"\n"+createSQL, currentIndex)
 
 	return DDLStatement{
 		SQL:         sql,
@@ -772,6 +1627,28 @@ func (b *DDLBuilder) buildReverseModifyIndex(change differ.Change) (DDLStatement
 	}, nil
 }
 
+// swapIndexSQL replaces the index named like idx with idx without a moment
+// in which neither exists: idx is created as swapName, the old index is
+// dropped and the new one takes its name.
+func (b *DDLBuilder) swapIndexSQL(idx *schema.Index, swapName string) (string, error) {
+	replacement := *idx
+	replacement.Name = swapName
+
+	createSQL, err := formatIndexDefinition(&replacement)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	appendStatement(&sb, createSQL)
+	appendStatement(&sb, fmt.Sprintf("DROP INDEX %s%s;",
+		b.ifExists(), QualifiedName(idx.Schema, idx.Name)))
+	appendStatement(&sb, fmt.Sprintf("ALTER INDEX %s RENAME TO %s;",
+		QualifiedName(replacement.Schema, replacement.Name), QuoteIdentifier(idx.Name)))
+
+	return sb.String(), nil
+}
+
 func getModifyIndexDetails(details map[string]any) (*schema.Index, *schema.Index, error) {
 	currentRaw, ok := details["current"]
 	if !ok {
@@ -853,3 +1730,58 @@ func (b *DDLBuilder) buildDropPartition(change differ.Change) (DDLStatement, err
 		RequiresTx:  true,
 	}, nil
 }
+
+func (b *DDLBuilder) buildDetachPartition(change differ.Change) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildDetachPartition", &change, err)
+	}
+
+	partition, err := getDetailPartition(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildDetachPartition", &change, err)
+	}
+
+	tableSchema, _ := parseSchemaAndName(tableName)
+	if tableSchema == "" {
+		tableSchema = schema.DefaultSchema
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s;",
+		tableName, QualifiedName(tableSchema, partition.Name))
+
+	return DDLStatement{
+		SQL:         sql,
+		Description: fmt.Sprintf("Detach partition %s from %s", partition.Name, tableName),
+		RequiresTx:  true,
+	}, nil
+}
+
+// buildAttachPartition attaches a detached partition with the bounds of the
+// change: those it was detached with when it is attached back, or its new
+// ones when its bounds changed.
+func (b *DDLBuilder) buildAttachPartition(change differ.Change) (DDLStatement, error) {
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAttachPartition", &change, err)
+	}
+
+	partition, err := getDetailPartition(change.Details)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAttachPartition", &change, err)
+	}
+
+	tableSchema, _ := parseSchemaAndName(tableName)
+	if tableSchema == "" {
+		tableSchema = schema.DefaultSchema
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s\n%s;",
+		tableName, QualifiedName(tableSchema, partition.Name), partition.Definition)
+
+	return DDLStatement{
+		SQL:         sql,
+		Description: fmt.Sprintf("Attach partition %s to %s", partition.Name, tableName),
+		RequiresTx:  true,
+	}, nil
+}
@@ -9,7 +9,14 @@ import (
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
-func (b *DDLBuilder) buildAddHypertable(change differ.Change) (DDLStatement, error) {
+// buildAddHypertable converts a table to a hypertable. The differ reports the
+// compression settings and retention policy of a new hypertable as their own
+// changes, so they are only rendered here with policies set, when restoring a
+// dropped hypertable in a down migration.
+func (b *DDLBuilder) buildAddHypertable(
+	change differ.Change,
+	withPolicies bool,
+) (DDLStatement, error) {
 	var ht *schema.Hypertable
 
 	if htAny, ok := change.Details["hypertable"]; ok {
@@ -39,7 +46,11 @@ func (b *DDLBuilder) buildAddHypertable(change differ.Change) (DDLStatement, err
 
 	appendStatement(&sb, sql)
 
-	if ht.CompressionEnabled && ht.CompressionSettings != nil {
+	if err := appendDimensions(&sb, ht); err != nil {
+		return DDLStatement{}, newGeneratorError("buildAddHypertable", &change, err)
+	}
+
+	if withPolicies && ht.CompressionEnabled && ht.CompressionSettings != nil {
 		compressionSQL, err := formatCompressionPolicy(ht)
 		if err != nil {
 			return DDLStatement{}, newGeneratorError("buildAddHypertable", &change, err)
@@ -50,7 +61,7 @@ func (b *DDLBuilder) buildAddHypertable(change differ.Change) (DDLStatement, err
 		}
 	}
 
-	if ht.RetentionPolicy != nil && ht.RetentionPolicy.DropAfter != "" {
+	if withPolicies && ht.RetentionPolicy != nil && ht.RetentionPolicy.DropAfter != "" {
 		retentionSQL, err := formatRetentionPolicy(ht)
 		if err != nil {
 			return DDLStatement{}, newGeneratorError("buildAddHypertable", &change, err)
@@ -81,6 +92,74 @@ func (b *DDLBuilder) buildDropHypertable(change differ.Change) (DDLStatement, er
 	}, nil
 }
 
+func (b *DDLBuilder) buildAddDimension(change differ.Change) (DDLStatement, error) {
+	ht, _ := change.Details["hypertable"].(*schema.Hypertable)
+	if ht == nil {
+		ht = b.findHypertable(change.ObjectName)
+	}
+
+	dim, _ := change.Details["dimension"].(*schema.Dimension)
+
+	if ht == nil || dim == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildAddDimension",
+			&change,
+			wrapObjectNotFoundError(ErrHypertableNotFound, "hypertable", change.ObjectName),
+		)
+	}
+
+	sql, err := formatAddDimension(ht, dim)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildAddDimension", &change, err)
+	}
+
+	return DDLStatement{
+		SQL:         ensureStatementTerminated(sql),
+		Description: fmt.Sprintf("Add dimension %s to hypertable %s", dim.ColumnName, ht.TableName),
+		RequiresTx:  false,
+	}, nil
+}
+
+// buildManualDimensionChange covers removed and changed dimensions, which
+// TimescaleDB cannot apply to an existing hypertable.
+func (b *DDLBuilder) buildManualDimensionChange(change differ.Change) (DDLStatement, error) {
+	dim, _ := change.Details["dimension"].(*schema.Dimension)
+	if dim == nil {
+		dim, _ = change.Details["desired_dimension"].(*schema.Dimension)
+	}
+
+	if dim == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildManualDimensionChange",
+			&change,
+			errors.New("dimension details are missing"),
+		)
+	}
+
+	action, title := "remove", "Remove"
+	if change.Type == differ.ChangeTypeModifyDimension {
+		action, title = "change", "Change"
+	}
+
+	return DDLStatement{
+		SQL: fmt.Sprintf(
+			"-- WARNING: TimescaleDB cannot %s dimension %s on hypertable %s\n"+
+				"-- Manual data migration required",
+			action,
+			dim.ColumnName,
+			change.ObjectName,
+		),
+		Description: fmt.Sprintf(
+			"%s dimension %s on %s (manual intervention required)",
+			title,
+			dim.ColumnName,
+			change.ObjectName,
+		),
+		IsUnsafe:   true,
+		RequiresTx: false,
+	}, nil
+}
+
 func (b *DDLBuilder) buildAddCompressionPolicy(change differ.Change) (DDLStatement, error) {
 	var ht *schema.Hypertable
 
@@ -115,8 +194,16 @@ func (b *DDLBuilder) buildAddCompressionPolicy(change differ.Change) (DDLStateme
 		)
 	}
 
+	var sb strings.Builder
+
+	appendStatement(&sb, sql)
+	appendStatement(
+		&sb,
+		formatCompressionJob(QualifiedName(ht.Schema, ht.TableName), ht.CompressionPolicy),
+	)
+
 	return DDLStatement{
-		SQL:         ensureStatementTerminated(sql),
+		SQL:         sb.String(),
 		Description: "Add compression policy for " + ht.TableName,
 		RequiresTx:  false,
 	}, nil
@@ -128,16 +215,66 @@ func (b *DDLBuilder) buildDropCompressionPolicy(change differ.Change) (DDLStatem
 		return DDLStatement{}, fmt.Errorf("hypertable not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("SELECT remove_compression_policy('%s');",
-		QualifiedName(ht.Schema, ht.TableName))
+	tableName := QualifiedName(ht.Schema, ht.TableName)
+
+	var sb strings.Builder
+
+	appendStatement(&sb, formatRemoveCompressionJob(tableName))
+	appendStatement(&sb, formatDisableCompression(tableName))
 
 	return DDLStatement{
-		SQL:         sql,
+		SQL:         sb.String(),
 		Description: "Drop compression policy for " + ht.TableName,
 		RequiresTx:  false,
 	}, nil
 }
 
+func (b *DDLBuilder) buildModifyCompressionSchedule(
+	change differ.Change,
+	reverse bool,
+) (DDLStatement, error) {
+	fromKey, toKey := "current_policy", "desired_policy"
+	if reverse {
+		fromKey, toKey = toKey, fromKey
+	}
+
+	from, _ := change.Details[fromKey].(*schema.CompressionPolicy)
+	to, _ := change.Details[toKey].(*schema.CompressionPolicy)
+
+	ht := b.findHypertable(change.ObjectName)
+	if ht == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildModifyCompressionSchedule",
+			&change,
+			wrapObjectNotFoundError(ErrHypertableNotFound, "hypertable", change.ObjectName),
+		)
+	}
+
+	tableName := QualifiedName(ht.Schema, ht.TableName)
+
+	var sb strings.Builder
+
+	if from != nil {
+		appendStatement(&sb, formatRemoveCompressionJob(tableName))
+	}
+
+	appendStatement(&sb, formatCompressionJob(tableName, to))
+
+	if sb.Len() == 0 {
+		return DDLStatement{}, newGeneratorError(
+			"buildModifyCompressionSchedule",
+			&change,
+			errors.New("compression policy is not configured"),
+		)
+	}
+
+	return DDLStatement{
+		SQL:         sb.String(),
+		Description: "Update compression policy schedule for " + ht.TableName,
+		RequiresTx:  false,
+	}, nil
+}
+
 func (b *DDLBuilder) buildModifyCompressionPolicy(change differ.Change) (DDLStatement, error) {
 	ht := b.getHypertable(change.ObjectName, b.result.Desired)
 	if ht == nil {
@@ -252,6 +389,73 @@ func (b *DDLBuilder) buildDropRetentionPolicy(change differ.Change) (DDLStatemen
 	}, nil
 }
 
+// buildRefreshPolicy replaces the add_continuous_aggregate_policy job of a
+// continuous aggregate: the job is removed when the aggregate has one before
+// the change, and added when it has one after it.
+func (b *DDLBuilder) buildRefreshPolicy(change differ.Change, reverse bool) (DDLStatement, error) {
+	var from, to *schema.RefreshPolicy
+
+	policy, _ := change.Details["policy"].(*schema.RefreshPolicy)
+
+	switch change.Type {
+	case differ.ChangeTypeAddRefreshPolicy:
+		to = policy
+	case differ.ChangeTypeDropRefreshPolicy:
+		from = policy
+	default:
+		from, _ = change.Details["current_policy"].(*schema.RefreshPolicy)
+		to, _ = change.Details["desired_policy"].(*schema.RefreshPolicy)
+	}
+
+	if reverse {
+		from, to = to, from
+	}
+
+	ca := b.findContinuousAggregate(change.ObjectName)
+	if ca == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildRefreshPolicy",
+			&change,
+			wrapObjectNotFoundError(
+				ErrMaterializedViewNotFound,
+				"continuous aggregate",
+				change.ObjectName,
+			),
+		)
+	}
+
+	viewName := QualifiedName(ca.Schema, ca.ViewName)
+
+	var sb strings.Builder
+
+	if from != nil {
+		appendStatement(&sb, formatRemoveRefreshPolicyJob(viewName))
+	}
+
+	appendStatement(&sb, formatRefreshPolicyJob(viewName, to))
+
+	if sb.Len() == 0 {
+		return DDLStatement{}, newGeneratorError(
+			"buildRefreshPolicy",
+			&change,
+			errors.New("refresh policy is not configured"),
+		)
+	}
+
+	action := "Update"
+	if from == nil {
+		action = "Add"
+	} else if to == nil {
+		action = "Drop"
+	}
+
+	return DDLStatement{
+		SQL:         sb.String(),
+		Description: fmt.Sprintf("%s refresh policy for %s", action, ca.ViewName),
+		RequiresTx:  false,
+	}, nil
+}
+
 func (b *DDLBuilder) buildAddContinuousAggregate(change differ.Change) (DDLStatement, error) {
 	var ca *schema.ContinuousAggregate
 
@@ -295,8 +499,8 @@ func (b *DDLBuilder) buildDropContinuousAggregate(change differ.Change) (DDLStat
 		return DDLStatement{}, fmt.Errorf("continuous aggregate not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s%s CASCADE;",
-		b.ifExists(), QualifiedName(ca.Schema, ca.ViewName))
+	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s%s%s;",
+		b.ifExists(), QualifiedName(ca.Schema, ca.ViewName), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -339,9 +543,10 @@ func (b *DDLBuilder) buildContinuousAggregateRecreate(
 	var sb strings.Builder
 
 	dropStatement := fmt.Sprintf(
-		"DROP MATERIALIZED VIEW %s%s CASCADE;",
+		"DROP MATERIALIZED VIEW %s%s%s;",
 		b.ifExists(),
 		QualifiedName(caOld.Schema, caOld.ViewName),
+		b.cascade(&change),
 	)
 	appendStatement(&sb, dropStatement)
 
@@ -396,3 +601,54 @@ func (b *DDLBuilder) buildHypertableWithPolicy(
 		RetentionPolicy: policy,
 	}
 }
+
+// buildCACompression switches compression of a continuous aggregate on or
+// off in place and replaces its compression job, leaving the aggregate and
+// its data alone.
+func (b *DDLBuilder) buildCACompression(change differ.Change, reverse bool) (DDLStatement, error) {
+	from, _ := change.Details["current"].(*schema.ContinuousAggregate)
+	to, _ := change.Details["desired"].(*schema.ContinuousAggregate)
+
+	if reverse {
+		from, to = to, from
+	}
+
+	if from == nil || to == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildCACompression",
+			&change,
+			errors.New("missing continuous aggregate details"),
+		)
+	}
+
+	viewName := QualifiedName(to.Schema, to.ViewName)
+
+	var sb strings.Builder
+
+	if from.CompressionPolicy != nil {
+		appendStatement(&sb, formatRemoveCompressionJob(viewName))
+	}
+
+	if from.CompressionEnabled != to.CompressionEnabled {
+		appendStatement(&sb, fmt.Sprintf(
+			"ALTER MATERIALIZED VIEW %s SET (timescaledb.compress = %t)",
+			viewName, to.CompressionEnabled))
+	}
+
+	if to.CompressionEnabled {
+		appendStatement(&sb, formatCompressionJob(viewName, to.CompressionPolicy))
+	}
+
+	action := "Update"
+	if !from.CompressionEnabled {
+		action = "Enable"
+	} else if !to.CompressionEnabled {
+		action = "Disable"
+	}
+
+	return DDLStatement{
+		SQL:         sb.String(),
+		Description: fmt.Sprintf("%s compression for %s", action, to.ViewName),
+		RequiresTx:  false,
+	}, nil
+}
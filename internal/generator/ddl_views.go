@@ -2,9 +2,11 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 func (b *DDLBuilder) buildAddView(change differ.Change) (DDLStatement, error) {
@@ -25,7 +27,7 @@ func (b *DDLBuilder) buildAddView(change differ.Change) (DDLStatement, error) {
 	var sb strings.Builder
 	appendStatement(&sb, definition)
 
-	if view.Comment != "" {
+	if view.Comment != "" && !b.hasCommentChange(differ.ChangeTypeModifyView, change.ObjectName) {
 		commentSQL := buildCommentStatement(
 			"VIEW",
 			QualifiedName(view.Schema, view.Name),
@@ -48,8 +50,8 @@ func (b *DDLBuilder) buildDropView(change differ.Change) (DDLStatement, error) {
 		return DDLStatement{}, fmt.Errorf("view not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP VIEW %s%s CASCADE;",
-		b.ifExists(), QualifiedName(view.Schema, view.Name))
+	sql := fmt.Sprintf("DROP VIEW %s%s%s;",
+		b.ifExists(), QualifiedName(view.Schema, view.Name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -95,6 +97,10 @@ func (b *DDLBuilder) buildAddViewForDown(change differ.Change) (DDLStatement, er
 }
 
 func (b *DDLBuilder) buildModifyView(change differ.Change) (DDLStatement, error) {
+	if _, ok := change.Details[DetailKeyNewViewOptions.String()]; ok {
+		return b.buildViewOptions(change, DetailKeyOldViewOptions, DetailKeyNewViewOptions, "Modify")
+	}
+
 	comment, err := extractCommentDetails(change)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildModifyView", &change, err)
@@ -141,7 +147,7 @@ func (b *DDLBuilder) buildModifyView(change differ.Change) (DDLStatement, error)
 	var sb strings.Builder
 	appendStatement(&sb, definition)
 
-	if view.Comment != "" {
+	if view.Comment != "" && !b.hasCommentChange(differ.ChangeTypeModifyView, change.ObjectName) {
 		commentSQL := buildCommentStatement(
 			"VIEW",
 			QualifiedName(view.Schema, view.Name),
@@ -159,13 +165,26 @@ func (b *DDLBuilder) buildModifyView(change differ.Change) (DDLStatement, error)
 }
 
 func (b *DDLBuilder) buildRevertModifyView(change differ.Change) (DDLStatement, error) {
+	if _, ok := change.Details[DetailKeyNewViewOptions.String()]; ok {
+		return b.buildViewOptions(change, DetailKeyNewViewOptions, DetailKeyOldViewOptions, "Revert")
+	}
+
+	comment, err := extractCommentDetails(change)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRevertModifyView", &change, err)
+	}
+
 	view := b.getView(change.ObjectName, b.result.Current)
-	if view == nil {
-		comment, err := extractCommentDetails(change)
-		if err != nil {
-			return DDLStatement{}, newGeneratorError("buildRevertModifyView", &change, err)
-		}
+	if view != nil && comment.HasOld && comment.HasNew {
+		return DDLStatement{
+			SQL: buildCommentStatement(
+				"VIEW", QualifiedName(view.Schema, view.Name), comment.Old, false),
+			Description: "Revert view comment " + view.Name,
+			RequiresTx:  true,
+		}, nil
+	}
 
+	if view == nil {
 		if comment.HasOld && comment.HasNew && comment.Old == "" {
 			desiredView := b.getView(change.ObjectName, b.result.Desired)
 			if desiredView != nil {
@@ -191,6 +210,11 @@ func (b *DDLBuilder) buildRevertModifyView(change differ.Change) (DDLStatement,
 		)
 	}
 
+	if desired := b.getView(change.ObjectName, b.result.Desired); desired != nil &&
+		!differ.ViewColumnsExtend(desired.Definition, view.Definition) {
+		return b.buildRecreateViewForDown(change, view)
+	}
+
 	definition, err := formatViewDefinition(view, true)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildRevertModifyView", &change, err)
@@ -203,6 +227,49 @@ func (b *DDLBuilder) buildRevertModifyView(change differ.Change) (DDLStatement,
 	}, nil
 }
 
+// buildRecreateViewForDown reverts a view whose up migration appended columns.
+// CREATE OR REPLACE VIEW cannot drop them again, so the view is dropped and
+// created with its previous query, along with its comment and the triggers
+// the drop removes.
+func (b *DDLBuilder) buildRecreateViewForDown(
+	change differ.Change,
+	view *schema.View,
+) (DDLStatement, error) {
+	definition, err := formatViewDefinition(view, false)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildRecreateViewForDown", &change, err)
+	}
+
+	var sb strings.Builder
+	appendStatement(&sb, fmt.Sprintf("DROP VIEW %s;", QualifiedName(view.Schema, view.Name)))
+	appendStatement(&sb, definition)
+
+	if view.Comment != "" {
+		appendStatement(&sb, buildCommentStatement(
+			"VIEW", QualifiedName(view.Schema, view.Name), view.Comment, false))
+	}
+
+	for i := range b.result.Current.Triggers {
+		trigger := &b.result.Current.Triggers[i]
+		if differ.ViewKey(trigger.Schema, trigger.TableName) != change.ObjectName {
+			continue
+		}
+
+		triggerSQL, err := formatTriggerDefinition(trigger)
+		if err != nil {
+			return DDLStatement{}, newGeneratorError("buildRecreateViewForDown", &change, err)
+		}
+
+		appendStatement(&sb, triggerSQL)
+	}
+
+	return DDLStatement{
+		SQL:         sb.String(),
+		Description: "Recreate view " + view.Name,
+		RequiresTx:  true,
+	}, nil
+}
+
 func (b *DDLBuilder) buildAddMaterializedView(change differ.Change) (DDLStatement, error) {
 	mv := b.getMaterializedView(change.ObjectName, b.result.Desired)
 	if mv == nil {
@@ -225,7 +292,8 @@ func (b *DDLBuilder) buildAddMaterializedView(change differ.Change) (DDLStatemen
 	var sb strings.Builder
 	appendStatement(&sb, definition)
 
-	if mv.Comment != "" {
+	if mv.Comment != "" &&
+		!b.hasCommentChange(differ.ChangeTypeModifyMaterializedView, change.ObjectName) {
 		commentSQL := buildCommentStatement(
 			"MATERIALIZED VIEW",
 			QualifiedName(mv.Schema, mv.Name),
@@ -236,6 +304,10 @@ func (b *DDLBuilder) buildAddMaterializedView(change differ.Change) (DDLStatemen
 	}
 
 	for _, idx := range mv.Indexes {
+		if b.hasIndexChange(differ.ChangeTypeAddIndex, &idx) {
+			continue
+		}
+
 		idxSQL, err := formatIndexDefinition(&idx)
 		if err != nil {
 			return DDLStatement{}, newGeneratorError("buildAddMaterializedView", &change, err)
@@ -257,8 +329,8 @@ func (b *DDLBuilder) buildDropMaterializedView(change differ.Change) (DDLStateme
 		return DDLStatement{}, fmt.Errorf("materialized view not found: %s", change.ObjectName)
 	}
 
-	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s%s CASCADE;",
-		b.ifExists(), QualifiedName(mv.Schema, mv.Name))
+	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s%s%s;",
+		b.ifExists(), QualifiedName(mv.Schema, mv.Name), b.cascade(&change))
 
 	return DDLStatement{
 		SQL:         sql,
@@ -303,6 +375,10 @@ func (b *DDLBuilder) buildAddMaterializedViewForDown(
 	}
 
 	for _, idx := range mv.Indexes {
+		if b.hasIndexChange(differ.ChangeTypeDropIndex, &idx) {
+			continue
+		}
+
 		idxSQL, err := formatIndexDefinition(&idx)
 		if err != nil {
 			return DDLStatement{}, newGeneratorError(
@@ -320,6 +396,11 @@ func (b *DDLBuilder) buildAddMaterializedViewForDown(
 }
 
 func (b *DDLBuilder) buildModifyMaterializedView(change differ.Change) (DDLStatement, error) {
+	if _, ok := change.Details[DetailKeyNewStorageParams.String()]; ok {
+		return b.buildMaterializedViewStorage(
+			change, DetailKeyOldStorageParams, DetailKeyNewStorageParams, "Modify")
+	}
+
 	comment, err := extractCommentDetails(change)
 	if err != nil {
 		return DDLStatement{}, newGeneratorError("buildModifyMaterializedView", &change, err)
@@ -382,7 +463,8 @@ func (b *DDLBuilder) buildModifyMaterializedView(change differ.Change) (DDLState
 
 	appendStatement(&sb, definition)
 
-	if mv.Comment != "" {
+	if mv.Comment != "" &&
+		!b.hasCommentChange(differ.ChangeTypeModifyMaterializedView, change.ObjectName) {
 		commentSQL := buildCommentStatement(
 			"MATERIALIZED VIEW",
 			QualifiedName(mv.Schema, mv.Name),
@@ -401,6 +483,11 @@ func (b *DDLBuilder) buildModifyMaterializedView(change differ.Change) (DDLState
 }
 
 func (b *DDLBuilder) buildRevertModifyMaterializedView(change differ.Change) (DDLStatement, error) {
+	if _, ok := change.Details[DetailKeyNewStorageParams.String()]; ok {
+		return b.buildMaterializedViewStorage(
+			change, DetailKeyNewStorageParams, DetailKeyOldStorageParams, "Revert")
+	}
+
 	mv := b.getMaterializedView(change.ObjectName, b.result.Current)
 	if mv == nil {
 		comment, err := extractCommentDetails(change)
@@ -462,3 +549,113 @@ func (b *DDLBuilder) buildRevertModifyMaterializedView(change differ.Change) (DD
 		RequiresTx:  true,
 	}, nil
 }
+
+// buildViewOptions changes the options of a view from those under fromKey to
+// those under toKey, as buildMaterializedViewStorage does for the storage
+// parameters of a materialized view. The query is left as it is.
+func (b *DDLBuilder) buildViewOptions(
+	change differ.Change,
+	fromKey, toKey DetailKey,
+	verb string,
+) (DDLStatement, error) {
+	from, err := requireDetail[map[string]string](change.Details, fromKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildViewOptions", &change, err)
+	}
+
+	to, err := requireDetail[map[string]string](change.Details, toKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildViewOptions", &change, err)
+	}
+
+	view := b.getView(change.ObjectName, b.result.Desired)
+	if view == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildViewOptions",
+			&change,
+			wrapObjectNotFoundError(ErrViewNotFound, "view", change.ObjectName),
+		)
+	}
+
+	return DDLStatement{
+		SQL:         alterRelationOptions("VIEW", QualifiedName(view.Schema, view.Name), from, to),
+		Description: verb + " view options " + view.Name,
+		RequiresTx:  true,
+	}, nil
+}
+
+// alterRelationOptions returns the ALTER statements of a relation of kind
+// that set the options added or changed from from to to, and reset those
+// removed.
+func alterRelationOptions(kind, name string, from, to map[string]string) string {
+	set := make(map[string]string)
+
+	for key, value := range to {
+		if old, ok := from[key]; !ok || old != value {
+			set[key] = value
+		}
+	}
+
+	var reset []string
+
+	for key := range from {
+		if _, ok := to[key]; !ok {
+			reset = append(reset, key)
+		}
+	}
+
+	sort.Strings(reset)
+
+	var sb strings.Builder
+
+	if len(set) > 0 {
+		appendStatement(&sb, fmt.Sprintf(
+			"ALTER %s %s SET (%s);", kind, name, formatStorageParams(set)))
+	}
+
+	if len(reset) > 0 {
+		appendStatement(&sb, fmt.Sprintf(
+			"ALTER %s %s RESET (%s);", kind, name, strings.Join(reset, ", ")))
+	}
+
+	return sb.String()
+}
+
+// buildMaterializedViewStorage changes the storage parameters of a
+// materialized view from those under fromKey to those under toKey, setting
+// the added and changed parameters and resetting the removed ones.
+func (b *DDLBuilder) buildMaterializedViewStorage(
+	change differ.Change,
+	fromKey, toKey DetailKey,
+	verb string,
+) (DDLStatement, error) {
+	from, err := requireDetail[map[string]string](change.Details, fromKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildMaterializedViewStorage", &change, err)
+	}
+
+	to, err := requireDetail[map[string]string](change.Details, toKey)
+	if err != nil {
+		return DDLStatement{}, newGeneratorError("buildMaterializedViewStorage", &change, err)
+	}
+
+	mv := b.getMaterializedView(change.ObjectName, b.result.Desired)
+	if mv == nil {
+		return DDLStatement{}, newGeneratorError(
+			"buildMaterializedViewStorage",
+			&change,
+			wrapObjectNotFoundError(
+				ErrMaterializedViewNotFound,
+				"materialized view",
+				change.ObjectName,
+			),
+		)
+	}
+
+	return DDLStatement{
+		SQL: alterRelationOptions(
+			"MATERIALIZED VIEW", QualifiedName(mv.Schema, mv.Name), from, to),
+		Description: verb + " materialized view storage parameters " + mv.Name,
+		RequiresTx:  true,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"slices"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// detachDroppedPartitions marks each dropped partition to be detached from its
+// table instead. The partition stays behind as a table of its own, rows and
+// all, and the down migration attaches it again. A partition the plan adds
+// back under the same name, because its bounds changed, is marked too: the
+// detached table is attached again with the new bounds rather than replaced.
+// It keeps its local indexes and constraints, so the changes creating them
+// anew, and their drops in the down migration, are left out.
+func detachDroppedPartitions(batches [][]differ.Change) [][]differ.Change {
+	changes := slices.Concat(batches...)
+	detached := detachedPartitionNames(changes)
+	reattached := reattachedPartitions(changes, detached)
+	marked := make([][]differ.Change, len(batches))
+
+	for i, batch := range batches {
+		marked[i] = make([]differ.Change, 0, len(batch))
+		for _, change := range batch {
+			if keptByReattachedPartition(change, reattached) {
+				continue
+			}
+
+			marked[i] = append(marked[i], detachedPartition(change, detached))
+		}
+	}
+
+	return marked
+}
+
+// detachedPartitionNames returns the names of the partitions changes drop.
+func detachedPartitionNames(changes []differ.Change) map[string]bool {
+	names := make(map[string]bool)
+
+	for _, change := range changes {
+		if change.Type == differ.ChangeTypeDropPartition {
+			names[change.ObjectName] = true
+		}
+	}
+
+	return names
+}
+
+// reattachedPartitions returns the partitions, as they are before the
+// change, that are detached and attached again with new bounds, by name.
+func reattachedPartitions(
+	changes []differ.Change,
+	detached map[string]bool,
+) map[string]*schema.Partition {
+	added := make(map[string]bool)
+
+	for _, change := range changes {
+		if change.Type == differ.ChangeTypeAddPartition && detached[change.ObjectName] {
+			added[change.ObjectName] = true
+		}
+	}
+
+	partitions := make(map[string]*schema.Partition)
+
+	for _, change := range changes {
+		if change.Type != differ.ChangeTypeDropPartition || !added[change.ObjectName] {
+			continue
+		}
+
+		if partition, err := getDetailPartition(change.Details); err == nil {
+			partitions[change.ObjectName] = partition
+		}
+	}
+
+	return partitions
+}
+
+// keptByReattachedPartition reports whether change creates, or comments, an
+// index or constraint local to a reattached partition that the partition
+// already has. Objects new to the partition are still created.
+func keptByReattachedPartition(
+	change differ.Change,
+	reattached map[string]*schema.Partition,
+) bool {
+	for _, dependency := range change.DependsOn {
+		partition := reattached[dependency]
+		if partition == nil {
+			continue
+		}
+
+		switch change.Type {
+		case differ.ChangeTypeAddIndex:
+			index, _ := change.Details["index"].(*schema.Index)
+			return index != nil && partitionIndex(partition, index.Name) != nil
+		case differ.ChangeTypeModifyIndexComment:
+			index, _ := change.Details["index"].(*schema.Index)
+			if index == nil {
+				return false
+			}
+
+			current := partitionIndex(partition, index.Name)
+
+			return current != nil && current.Comment == index.Comment
+		case differ.ChangeTypeAddConstraint:
+			constraint, _ := change.Details["constraint"].(*schema.Constraint)
+			return constraint != nil && partitionConstraint(partition, constraint.Name) != nil
+		case differ.ChangeTypeModifyConstraintComment:
+			name, _ := change.Details["constraint_name"].(string)
+			comment, _ := change.Details["new_comment"].(string)
+			current := partitionConstraint(partition, name)
+
+			return current != nil && current.Comment == comment
+		}
+	}
+
+	return false
+}
+
+func partitionIndex(partition *schema.Partition, name string) *schema.Index {
+	for i := range partition.Indexes {
+		if partition.Indexes[i].Name == name {
+			return &partition.Indexes[i]
+		}
+	}
+
+	return nil
+}
+
+func partitionConstraint(partition *schema.Partition, name string) *schema.Constraint {
+	for i := range partition.Constraints {
+		if partition.Constraints[i].Name == name {
+			return &partition.Constraints[i]
+		}
+	}
+
+	return nil
+}
+
+func detachedPartition(change differ.Change, detached map[string]bool) differ.Change {
+	if (change.Type != differ.ChangeTypeDropPartition &&
+		change.Type != differ.ChangeTypeAddPartition) || !detached[change.ObjectName] {
+		return change
+	}
+
+	return withDetail(change, DetailKeyDetach, true)
+}
+
+func isDetach(change differ.Change) bool {
+	detach, _ := change.Details[DetailKeyDetach.String()].(bool)
+	return detach
+}
@@ -166,10 +166,18 @@ func getDesiredTrigger(details map[string]any) (*schema.Trigger, error) {
 	return requireDetail[*schema.Trigger](details, DetailKeyDesired)
 }
 
+func getDetailTrigger(details map[string]any) (*schema.Trigger, error) {
+	return requireDetail[*schema.Trigger](details, DetailKeyTrigger)
+}
+
 func getDetailIndex(details map[string]any) (*schema.Index, error) {
 	return requireDetail[*schema.Index](details, DetailKeyIndex)
 }
 
+func getDetailPolicy(details map[string]any) (*schema.Policy, error) {
+	return requireDetail[*schema.Policy](details, DetailKeyPolicy)
+}
+
 func getDetailPartition(details map[string]any) (*schema.Partition, error) {
 	return requireDetail[*schema.Partition](details, DetailKeyPartition)
 }
@@ -1,7 +1,7 @@
 // Package generator provides functionality for generating PostgreSQL migration files
 // from schema differences. It converts high-level change descriptions into
 // executable SQL statements, organized into versioned migration files compatible
-// with golang-migrate.
+// with golang-migrate or goose.
 //
 // # Architecture
 //
@@ -31,6 +31,9 @@
 //
 //	fmt.Println(result.Summary())
 //
+// GenerateContext accepts a context that is checked before every batch and
+// file write; a cancelled run removes the files it already wrote.
+//
 // # Migration Generation
 //
 // The generator groups changes by schema and dependency order, then creates
@@ -52,7 +55,22 @@
 //   - Idempotent: Use IF EXISTS/IF NOT EXISTS clauses
 //   - GenerateDownMigrations: Create rollback migrations
 //   - MaxOperationsPerFile: Split large migrations into batches
-//   - PreviewMode: Generate without writing files
+//   - PreviewMode: Generate without writing files, keeping each file's
+//     text in its Content. Otherwise files are streamed to disk as they are
+//     generated and only their Checksum is kept.
+//   - SafeUniqueConstraints: Build new unique indexes CONCURRENTLY before
+//     promoting them to constraints
+//   - SafeConstraintMode: Add constraints of existing tables NOT VALID and
+//     validate them in the following migration
+//   - ConcurrentIndexes: Build and drop indexes of existing tables
+//     CONCURRENTLY, each in a migration of its own
+//   - DetachPartitions: Detach removed partitions instead of dropping them
+//   - Baseline: Write every change to a single migration whose down
+//     migration drops the schemas it creates objects in
+//   - OutputFormat: golang-migrate up/down file pairs or single goose files
+//   - Progress: Callback invoked as migrations are generated and written
+//   - PartitionOutputBySchema: Write each schema's migrations to its own
+//     subdirectory, numbered independently
 //
 // # Thread Safety
 //
@@ -61,13 +79,21 @@
 package generator
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/graph"
 	"github.com/accented-ai/pgtofu/internal/schema"
@@ -87,6 +113,15 @@ func New(opts *Options) *Generator {
 }
 
 func (g *Generator) Generate(result *differ.DiffResult) (*GenerateResult, error) {
+	return g.GenerateContext(context.Background(), result)
+}
+
+// GenerateContext is Generate with cancellation checked before every batch
+// and every file write. A cancelled run removes the files it already wrote.
+func (g *Generator) GenerateContext(
+	ctx context.Context,
+	result *differ.DiffResult,
+) (*GenerateResult, error) {
 	if result == nil {
 		return nil, ErrNilDiffResult
 	}
@@ -95,47 +130,201 @@ func (g *Generator) Generate(result *differ.DiffResult) (*GenerateResult, error)
 		return nil, util.WrapError("invalid options", err)
 	}
 
+	genResult := &GenerateResult{
+		Migrations:  []MigrationPair{},
+		Diagnostics: []diag.Warning{},
+		Warnings:    []string{},
+	}
+
 	if !result.HasChanges() {
-		return &GenerateResult{
-			Migrations: []MigrationPair{},
-			Warnings:   []string{"No changes detected, no migrations generated"},
-		}, nil
+		genResult.addWarning(diag.Warning{
+			Code:     diag.CodeNoChanges,
+			Severity: diag.SeverityInfo,
+			Message:  "No changes detected, no migrations generated",
+		})
+
+		return genResult, nil
 	}
 
-	genResult := &GenerateResult{
-		Migrations: []MigrationPair{},
-		Warnings:   []string{},
+	if err := g.checkDropDependents(result); err != nil {
+		return nil, err
 	}
 
-	batches := g.GroupChangesBySchema(result.Changes)
+	// Plan size advisories concern the migrations as much as the diff, so
+	// they are repeated for callers that only look at the generated result.
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodePlanTooLarge {
+			genResult.addWarning(warning)
+		}
+	}
+
+	var batches [][]differ.Change
+
+	if g.Options.Baseline {
+		batches = g.baselineBatch(result.Changes)
+	} else {
+		var groupWarnings []diag.Warning
+
+		batches, groupWarnings = g.groupChanges(result.Changes)
+		for _, warning := range groupWarnings {
+			genResult.addWarning(warning)
+		}
+	}
+
+	if g.Options.SafeUniqueConstraints {
+		batches = g.splitSafeUniqueConstraints(batches, result)
+	}
+
+	if g.Options.SafeConstraintMode {
+		batches = g.splitSafeConstraints(batches, result)
+	}
+
+	if g.Options.ConcurrentIndexes {
+		batches = g.splitConcurrentIndexes(batches, result)
+	}
+
+	if g.Options.DetachPartitions {
+		batches = detachDroppedPartitions(batches)
+	}
+
+	batches, emptyWarnings := g.dropEmptyBatches(batches, result)
+	for _, warning := range emptyWarnings {
+		genResult.addWarning(warning)
+	}
+
+	if len(batches) == 0 {
+		return genResult, nil
+	}
+
+	plans, planWarnings, err := g.planMigrations(batches)
+	if err != nil {
+		return nil, util.WrapError("plan migrations", err)
+	}
+
+	for _, warning := range planWarnings {
+		genResult.addWarning(warning)
+	}
+
+	generate := func(run *outputRun) error {
+		return g.generateMigrations(ctx, genResult, plans, batches, result, run)
+	}
+
+	if g.Options.PreviewMode {
+		err = generate(nil)
+	} else {
+		err = g.writeMigrationFiles(generate)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !g.Options.PreviewMode {
+		genResult.FilesGenerated = len(genResult.files())
+	}
+
+	return genResult, nil
+}
+
+// generateMigrations builds the migration of every batch. Each file is laid
+// out as soon as its migration is built: into its Content in PreviewMode, or
+// into the staging directory of run otherwise, so only one migration's
+// statements are held at a time.
+func (g *Generator) generateMigrations(
+	ctx context.Context,
+	genResult *GenerateResult,
+	plans []migrationPlan,
+	batches [][]differ.Change,
+	result *differ.DiffResult,
+	run *outputRun,
+) error {
+	total := len(batches) * g.filesPerMigration()
 
-	currentVersion := g.Options.StartVersion
 	for i, batch := range batches {
-		migration, warnings := g.generateMigration(currentVersion+i, batch, result)
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("generate cancelled after %d of %d migrations: %w",
+				i, len(batches), err)
+		}
+
+		migration, rollbacks, warnings := g.generateMigration(&plans[i], batch, result)
+
+		if run == nil {
+			if err := keepMigrationContent(&migration); err != nil {
+				return util.WrapError("lay out migration", err)
+			}
+		} else if err := g.stageMigration(ctx, &migration, run, total); err != nil {
+			return util.WrapError("write migration files", err)
+		}
+
 		genResult.Migrations = append(genResult.Migrations, migration)
-		genResult.Warnings = append(genResult.Warnings, warnings...)
+		for _, warning := range warnings {
+			genResult.addWarning(warning)
+		}
+		genResult.LossyRollbacks += rollbacks.lossy
+		genResult.ManualRollbacks += rollbacks.manual
+		genResult.SafetyReport.Locks = append(genResult.SafetyReport.Locks,
+			migration.UpFile.Locks...)
+
+		g.reportProgress("generating migrations", i+1, len(batches))
 	}
 
-	if !g.Options.PreviewMode {
-		if err := g.writeMigrationFiles(genResult); err != nil {
-			return nil, util.WrapError("write migration files", err)
+	return g.checkLocks(&genResult.SafetyReport)
+}
+
+// filesPerMigration is how many files each migration is written to.
+func (g *Generator) filesPerMigration() int {
+	if g.Options.OutputFormat == OutputFormatGoose || !g.Options.GenerateDownMigrations {
+		return 1
+	}
+
+	return 2
+}
+
+// groupChanges batches changes as GroupChangesBySchema does, except that the
+// changes of a pinned migration, which the differ orders consecutively, make
+// up a batch of their own.
+func (g *Generator) groupChanges(changes []differ.Change) ([][]differ.Change, []diag.Warning) {
+	var (
+		batches  [][]differ.Change
+		warnings []diag.Warning
+	)
+
+	for start := 0; start < len(changes); {
+		end := start + 1
+		for end < len(changes) && changes[end].Migration == changes[start].Migration {
+			end++
 		}
 
-		genResult.FilesGenerated = len(genResult.Migrations)
-		if g.Options.GenerateDownMigrations {
-			genResult.FilesGenerated *= 2
+		run := slices.Clone(changes[start:end])
+		if run[0].Migration == "" {
+			grouped, groupWarnings := g.groupChangesBySchema(run)
+			batches = append(batches, grouped...)
+			warnings = append(warnings, groupWarnings...)
+		} else {
+			g.sortSchemaChanges(run)
+			batches = append(batches, run)
 		}
+
+		start = end
 	}
 
-	return genResult, nil
+	return batches, warnings
 }
 
 func (g *Generator) GroupChangesBySchema(changes []differ.Change) [][]differ.Change {
+	batches, _ := g.groupChangesBySchema(changes)
+	return batches
+}
+
+func (g *Generator) groupChangesBySchema(changes []differ.Change) ([][]differ.Change, []diag.Warning) {
 	if len(changes) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	var batches [][]differ.Change
+	var (
+		batches  [][]differ.Change
+		warnings []diag.Warning
+	)
 
 	addSchemaChanges, dropSchemaChanges, nonSchemaChanges := g.separateSchemaChanges(changes)
 
@@ -158,7 +347,10 @@ func (g *Generator) GroupChangesBySchema(changes []differ.Change) [][]differ.Cha
 		}
 
 		g.sortSchemaChanges(schemaChanges)
-		batches = append(batches, g.splitIntoBatches(schemaChanges)...)
+
+		split, splitWarnings := g.splitIntoBatches(schemaChanges)
+		batches = append(batches, split...)
+		warnings = append(warnings, splitWarnings...)
 	}
 
 	// DROP SCHEMA must run after every object inside it has been dropped.
@@ -166,7 +358,7 @@ func (g *Generator) GroupChangesBySchema(changes []differ.Change) [][]differ.Cha
 		batches = append(batches, dropSchemaChanges)
 	}
 
-	return batches
+	return batches, warnings
 }
 
 func (g *Generator) groupBySchema(changes []differ.Change) map[string][]differ.Change {
@@ -185,8 +377,9 @@ func (g *Generator) orderSchemasByDependencies( //nolint:gocognit
 	allChanges []differ.Change,
 ) [][]string {
 	dg := graph.NewDirectedGraph[string]()
+	schemaNames := slices.Sorted(maps.Keys(schemaGroups))
 
-	for schema := range schemaGroups {
+	for _, schema := range schemaNames {
 		dg.AddNode(schema)
 	}
 
@@ -214,7 +407,8 @@ func (g *Generator) orderSchemasByDependencies( //nolint:gocognit
 		}
 	}
 
-	for schemaName, changes := range schemaGroups {
+	for _, schemaName := range schemaNames {
+		changes := schemaGroups[schemaName]
 		for i := range changes {
 			change := &changes[i]
 
@@ -252,18 +446,38 @@ func (g *Generator) orderSchemasByDependencies( //nolint:gocognit
 	return dg.CondensationOrder()
 }
 
-func (g *Generator) splitIntoBatches(changes []differ.Change) [][]differ.Change {
+// splitIntoBatches cuts changes into batches of MaxOperationsPerFile. A batch
+// only ends where nothing before the cut depends on an object created after
+// it; when the changes depend on each other past the limit, the batch grows
+// until they don't, and a warning names it.
+func (g *Generator) splitIntoBatches(changes []differ.Change) ([][]differ.Change, []diag.Warning) {
 	if len(changes) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var (
 		batches      [][]differ.Change
 		currentBatch []differ.Change
+		warnings     []diag.Warning
+		// heldTogether records that the current batch passed the limit
+		// because a dependency crossed a cut.
+		heldTogether bool
 	)
 
+	reach := dependencyReach(changes)
 	objectsInBatch := make(map[string]bool)
 
+	endBatch := func() {
+		if heldTogether {
+			warnings = append(warnings, oversizedBatchWarning(currentBatch, g.Options.MaxOperationsPerFile))
+		}
+
+		batches = append(batches, currentBatch)
+		currentBatch = []differ.Change{}
+		objectsInBatch = make(map[string]bool)
+		heldTogether = false
+	}
+
 	for i, change := range changes {
 		currentBatch = append(currentBatch, change)
 
@@ -271,20 +485,86 @@ func (g *Generator) splitIntoBatches(changes []differ.Change) [][]differ.Change
 			objectsInBatch[normalizeObjectName(change.ObjectName)] = true
 		}
 
-		if len(currentBatch) >= g.Options.MaxOperationsPerFile {
-			if i+1 < len(changes) && g.canSplitBetween(currentBatch, changes[i+1], objectsInBatch) {
-				batches = append(batches, currentBatch)
-				currentBatch = []differ.Change{}
-				objectsInBatch = make(map[string]bool)
+		if len(currentBatch) >= g.Options.MaxOperationsPerFile && i+1 < len(changes) {
+			if reach[i] > i {
+				heldTogether = true
+				continue
+			}
+
+			if g.canSplitBetween(currentBatch, changes[i+1], objectsInBatch) {
+				endBatch()
 			}
 		}
 	}
 
 	if len(currentBatch) > 0 {
-		batches = append(batches, currentBatch)
+		endBatch()
 	}
 
-	return batches
+	return batches, warnings
+}
+
+// dependencyReach returns, for each position of changes, the last position
+// holding an object that a change up to it depends on. A batch can end after
+// position i only when reach[i] is i.
+func dependencyReach(changes []differ.Change) []int {
+	creators := make(map[string]int)
+
+	for i := range changes {
+		if !createsDependency(changes[i].Type) {
+			continue
+		}
+
+		for _, name := range changeObjectNames(&changes[i]) {
+			if _, exists := creators[name]; !exists {
+				creators[name] = i
+			}
+		}
+	}
+
+	reach := make([]int, len(changes))
+	last := -1
+
+	for i := range changes {
+		last = max(last, i)
+
+		for _, dep := range changes[i].DependsOn {
+			if creator, exists := creators[normalizeObjectName(dep)]; exists {
+				last = max(last, creator)
+			}
+		}
+
+		reach[i] = last
+	}
+
+	return reach
+}
+
+// createsDependency reports whether a change creates an object other changes
+// can depend on, as the differ resolves DependsOn. Sequences and types are
+// left out: the differ orders changes after them implicitly, never through
+// DependsOn.
+func createsDependency(changeType differ.ChangeType) bool {
+	switch changeType {
+	case differ.ChangeTypeAddTable, differ.ChangeTypeAddView,
+		differ.ChangeTypeAddMaterializedView, differ.ChangeTypeAddFunction,
+		differ.ChangeTypeAddHypertable, differ.ChangeTypeAddPartition:
+		return true
+	}
+
+	return false
+}
+
+func oversizedBatchWarning(batch []differ.Change, limit int) diag.Warning {
+	return diag.Warning{
+		Code:     diag.CodeOversizedMigration,
+		Severity: diag.SeverityWarning,
+		Message: fmt.Sprintf(
+			"migration %s has %d changes, more than the %d per file allowed: "+
+				"changes before each possible cut depend on objects created after it",
+			migrationDescription(batch), len(batch), limit,
+		),
+	}
 }
 
 func (g *Generator) canSplitBetween(
@@ -507,7 +787,7 @@ func (g *Generator) separateSchemaChanges(
 ) (addSchema, dropSchema, other []differ.Change) {
 	for _, change := range changes {
 		switch change.Type {
-		case differ.ChangeTypeAddSchema:
+		case differ.ChangeTypeAddSchema, differ.ChangeTypeModifySchemaComment:
 			addSchema = append(addSchema, change)
 		case differ.ChangeTypeDropSchema:
 			dropSchema = append(dropSchema, change)
@@ -520,77 +800,156 @@ func (g *Generator) separateSchemaChanges(
 }
 
 func (g *Generator) generateMigration(
-	version int,
+	plan *migrationPlan,
 	changes []differ.Change,
 	result *differ.DiffResult,
-) (MigrationPair, []string) {
-	var warnings []string
+) (MigrationPair, rollbackSummary, []diag.Warning) {
+	var warnings []diag.Warning
+
+	version, description := plan.version, plan.description
 
-	description := GenerateMigrationName(changes)
 	builder := NewDDLBuilder(result, g.Options.Idempotent)
+	builder.cascadeDrops = g.Options.CascadeDrops
 
-	upStatements, upWarnings := g.buildUpStatements(changes, builder)
+	upStatements, upWarnings := g.buildUpStatements(
+		changes,
+		builder,
+		g.identifyDroppedTables(result.Changes),
+	)
 	warnings = append(warnings, upWarnings...)
 
 	var (
 		downStatements []DDLStatement
-		downWarnings   []string
+		downWarnings   []diag.Warning
 	)
 
-	if g.Options.GenerateDownMigrations {
+	switch {
+	case g.Options.GenerateDownMigrations && g.Options.Baseline:
+		downStatements = baselineDownStatements(changes)
+	case g.Options.GenerateDownMigrations:
 		downStatements, downWarnings = g.buildDownStatements(changes, builder)
 		warnings = append(warnings, downWarnings...)
 	}
 
+	if g.Options.OutputFormat == OutputFormatGoose {
+		pair := g.gooseMigration(plan, upStatements, downStatements, changes, result.OptionsHash)
+		return pair, summarizeRollbacks(downStatements), warnings
+	}
+
+	upFileName := FormatMigrationFileName(version, description, DirectionUp)
 	upFile := &MigrationFile{
 		Version:     version,
 		Description: description,
 		Direction:   DirectionUp,
-		FileName:    FormatMigrationFileName(version, description, DirectionUp),
-		Content: g.formatMigrationContent(
-			version,
-			description,
-			DirectionUp,
-			upStatements,
-			changes,
-		),
+		FileName:    upFileName,
+		Locks:       statementLocks(upFileName, upStatements),
+		layOut: func(w contentWriter) {
+			g.writeMigrationContent(w, plan, DirectionUp, upStatements, changes, result.OptionsHash)
+		},
 	}
 
-	var downFile *MigrationFile
+	var (
+		downFile  *MigrationFile
+		rollbacks rollbackSummary
+	)
+
 	if g.Options.GenerateDownMigrations {
+		rollbacks = summarizeRollbacks(downStatements)
+		downFileName := FormatMigrationFileName(version, description, DirectionDown)
 		downFile = &MigrationFile{
 			Version:     version,
 			Description: description,
 			Direction:   DirectionDown,
-			FileName:    FormatMigrationFileName(version, description, DirectionDown),
-			Content: g.formatMigrationContent(
-				version,
-				description,
-				DirectionDown,
-				downStatements,
-				changes,
-			),
+			FileName:    downFileName,
+			Locks:       statementLocks(downFileName, downStatements),
+			layOut: func(w contentWriter) {
+				g.writeMigrationContent(
+					w, plan, DirectionDown, downStatements, changes, result.OptionsHash)
+			},
+			Reversibility: rollbacks.worst,
 		}
 	}
 
 	return MigrationPair{
-		Version:     version,
-		Description: description,
-		UpFile:      upFile,
-		DownFile:    downFile,
-	}, warnings
+		Version:      version,
+		Description:  description,
+		Subdirectory: plan.subdirectory,
+		UpFile:       upFile,
+		DownFile:     downFile,
+	}, rollbacks, warnings
 }
 
+type rollbackSummary struct {
+	worst  Reversibility
+	notes  []string
+	lossy  int
+	manual int
+}
+
+func summarizeRollbacks(statements []DDLStatement) rollbackSummary {
+	var summary rollbackSummary
+
+	for _, stmt := range statements {
+		switch stmt.Reversibility {
+		case ReversibilityFull:
+			continue
+		case ReversibilityStructureOnly:
+			summary.lossy++
+		case ReversibilityManual:
+			summary.manual++
+		}
+
+		summary.worst = max(summary.worst, stmt.Reversibility)
+
+		if note := rollbackNote(stmt); note != "" {
+			summary.notes = append(summary.notes, note)
+		}
+	}
+
+	return summary
+}
+
+func rollbackNote(stmt DDLStatement) string {
+	if stmt.Reversibility == ReversibilityFull {
+		return ""
+	}
+
+	if stmt.RollbackNote == "" {
+		return stmt.Reversibility.String()
+	}
+
+	return stmt.Reversibility.String() + "; " + stmt.RollbackNote
+}
+
+// sourceNote renders where the change behind a statement is declared, for
+// appending to the statement's description comment.
+func sourceNote(source *differ.ChangeSource) string {
+	switch {
+	case source == nil:
+		return ""
+	case !source.Removed:
+		return " (from " + source.Location.String() + ")"
+	case source.Location.File != "":
+		return " (not present in desired state; declared at " + source.Location.String() + ")"
+	default:
+		return " (not present in desired state)"
+	}
+}
+
+// buildUpStatements builds the up statements of a batch. droppedTables are
+// the tables the whole plan drops, whose hypertable settings need no
+// statements in any migration.
 func (g *Generator) buildUpStatements(
 	changes []differ.Change,
 	builder *DDLBuilder,
-) ([]DDLStatement, []string) {
+	droppedTables map[string]bool,
+) ([]DDLStatement, []diag.Warning) {
 	var (
 		statements []DDLStatement
-		warnings   []string
+		warnings   []diag.Warning
 	)
 
-	droppedTables := g.identifyDroppedTables(changes)
+	locks := newLockAnalyzer(builder.result.Current)
 
 	for _, change := range changes {
 		if g.shouldSkipHypertableChange(change, droppedTables) {
@@ -599,18 +958,21 @@ func (g *Generator) buildUpStatements(
 
 		stmt, err := builder.BuildUpStatement(change)
 		if err != nil {
-			warnings = append(
-				warnings,
-				fmt.Sprintf("Failed to build UP statement for %s: %v", change.Description, err),
-			)
+			warnings = append(warnings, changeWarning(change, diag.CodeBuildUpFailed,
+				diag.SeverityError,
+				fmt.Sprintf("Failed to build UP statement for %s: %v", change.Description, err)))
 
 			continue
 		}
 
+		stmt.Source = change.Source
+		warnings = append(warnings, builder.finishStatement(change, &stmt)...)
+		locks.analyze(&stmt)
 		statements = append(statements, stmt)
 
 		if stmt.IsUnsafe {
-			warnings = append(warnings, "Unsafe operation: "+stmt.Description)
+			warnings = append(warnings, changeWarning(change, diag.CodeUnsafeOperation,
+				diag.SeverityWarning, "Unsafe operation: "+stmt.Description))
 		}
 	}
 
@@ -620,14 +982,16 @@ func (g *Generator) buildUpStatements(
 func (g *Generator) buildDownStatements(
 	changes []differ.Change,
 	builder *DDLBuilder,
-) ([]DDLStatement, []string) {
+) ([]DDLStatement, []diag.Warning) {
 	var (
 		statements []DDLStatement
-		warnings   []string
+		warnings   []diag.Warning
 	)
 
 	dropTargets := g.identifyDropTargets(changes)
 	droppedTables := g.identifyDroppedTables(changes)
+	// The down migration runs against the desired schema.
+	locks := newLockAnalyzer(builder.result.Desired)
 
 	for i := len(changes) - 1; i >= 0; i-- {
 		change := changes[i]
@@ -642,35 +1006,93 @@ func (g *Generator) buildDownStatements(
 
 		stmt, err := builder.BuildDownStatement(change)
 		if err != nil {
-			warnings = append(
-				warnings,
-				fmt.Sprintf("Failed to build DOWN statement for %s: %v", change.Description, err),
-			)
+			warnings = append(warnings, changeWarning(change, diag.CodeBuildDownFailed,
+				diag.SeverityError,
+				fmt.Sprintf("Failed to build DOWN statement for %s: %v", change.Description, err)))
 			statements = append(statements, DDLStatement{
-				SQL:         "-- WARNING: Manual rollback required for: " + change.Description,
-				Description: "Manual rollback required: " + change.Description,
-				IsUnsafe:    true,
+				SQL:           "-- WARNING: Manual rollback required for: " + change.Description,
+				Description:   "Manual rollback required: " + change.Description,
+				IsUnsafe:      true,
+				Reversibility: ReversibilityManual,
 			})
 
 			continue
 		}
 
+		stmt.Source = change.Source
+		warnings = append(warnings, builder.finishStatement(change, &stmt)...)
+		locks.analyze(&stmt)
 		statements = append(statements, stmt)
 
-		if stmt.IsUnsafe {
-			warnings = append(warnings, "Unsafe rollback operation: "+stmt.Description)
+		switch {
+		case stmt.Reversibility == ReversibilityManual:
+			warnings = append(warnings, changeWarning(change, diag.CodeManualRollbackRequired,
+				diag.SeverityWarning, "Unsafe rollback operation: "+stmt.Description))
+		case stmt.IsUnsafe:
+			warnings = append(warnings, changeWarning(change, diag.CodeUnsafeRollback,
+				diag.SeverityWarning, "Unsafe rollback operation: "+stmt.Description))
 		}
 	}
 
 	return statements, warnings
 }
 
+func changeWarning(
+	change differ.Change,
+	code diag.Code,
+	severity diag.Severity,
+	message string,
+) diag.Warning {
+	return diag.Warning{
+		Code:       code,
+		Severity:   severity,
+		Message:    message,
+		ObjectName: change.ObjectName,
+		ChangeType: string(change.Type),
+	}
+}
+
+// dropEmptyBatches leaves out the batches none of whose changes needs an up
+// statement, which would otherwise be written as migrations with nothing
+// between their header and COMMIT. Each is reported with an EMPTY_MIGRATION
+// note.
+func (g *Generator) dropEmptyBatches(
+	batches [][]differ.Change,
+	result *differ.DiffResult,
+) ([][]differ.Change, []diag.Warning) {
+	droppedTables := g.identifyDroppedTables(result.Changes)
+
+	var warnings []diag.Warning
+
+	kept := slices.DeleteFunc(batches, func(batch []differ.Change) bool {
+		for _, change := range batch {
+			if !g.shouldSkipHypertableChange(change, droppedTables) {
+				return false
+			}
+		}
+
+		warnings = append(warnings, diag.Warning{
+			Code:     diag.CodeEmptyMigration,
+			Severity: diag.SeverityInfo,
+			Message: fmt.Sprintf(
+				"skipped migration %s: none of its %d changes needs a statement",
+				migrationDescription(batch), len(batch),
+			),
+		})
+
+		return true
+	})
+
+	return kept, warnings
+}
+
 func (g *Generator) identifyDropTargets(changes []differ.Change) map[string]bool {
 	dropTargets := make(map[string]bool)
 
 	for _, ch := range changes {
 		switch ch.Type {
-		case differ.ChangeTypeAddTable,
+		case differ.ChangeTypeAddSchema,
+			differ.ChangeTypeAddTable,
 			differ.ChangeTypeAddView,
 			differ.ChangeTypeAddMaterializedView,
 			differ.ChangeTypeAddFunction,
@@ -708,7 +1130,10 @@ func (g *Generator) shouldSkipHypertableChange(
 
 	switch change.Type {
 	case differ.ChangeTypeDropHypertable,
+		differ.ChangeTypeDropDimension,
+		differ.ChangeTypeModifyDimension,
 		differ.ChangeTypeDropCompressionPolicy,
+		differ.ChangeTypeModifyCompressionSchedule,
 		differ.ChangeTypeDropRetentionPolicy:
 		return true
 	}
@@ -725,7 +1150,12 @@ func (g *Generator) shouldSkipCommentOnlyChange(
 	}
 
 	if change.Type == differ.ChangeTypeModifyTableComment ||
-		change.Type == differ.ChangeTypeModifyColumnComment {
+		change.Type == differ.ChangeTypeModifyColumnComment ||
+		change.Type == differ.ChangeTypeModifyConstraintComment ||
+		change.Type == differ.ChangeTypeModifyCustomTypeComment ||
+		change.Type == differ.ChangeTypeModifyIndexComment ||
+		change.Type == differ.ChangeTypeModifyTriggerComment ||
+		change.Type == differ.ChangeTypeModifySchemaComment {
 		return true
 	}
 
@@ -742,26 +1172,25 @@ func (g *Generator) isCommentOnlyChange(change differ.Change) bool {
 	return err == nil && ok
 }
 
-func (g *Generator) formatMigrationContent(
-	version int,
-	description string,
+func (g *Generator) writeMigrationContent(
+	sb contentWriter,
+	plan *migrationPlan,
 	direction Direction,
 	statements []DDLStatement,
 	changes []differ.Change,
-) string {
-	var sb strings.Builder
-
+	optionsHash string,
+) {
 	if g.Options.IncludeComments {
-		header := &migrationHeader{
-			Version:     version,
-			Description: description,
-			Direction:   direction,
-			Generated:   time.Now(),
-			Changes:     make([]string, 0, len(changes)),
-		}
+		header := g.newMigrationHeader(
+			FormatMigrationFileName(plan.version, plan.description, direction),
+			changes,
+			optionsHash,
+		)
+		header.DependencyNotes = plan.dependencyNotes
+		header.setLocks(statementLocks(header.FileName, statements))
 
-		for _, change := range changes {
-			header.Changes = append(header.Changes, change.Description)
+		if direction == DirectionDown {
+			header.setRollbacks(summarizeRollbacks(statements))
 		}
 
 		sb.WriteString(header.String())
@@ -773,34 +1202,115 @@ func (g *Generator) formatMigrationContent(
 		sb.WriteString("BEGIN;\n\n")
 	}
 
+	g.writeStatements(sb, statements, false, useTransaction)
+
+	if useTransaction {
+		sb.WriteString("\nCOMMIT;\n")
+	}
+}
+
+func (g *Generator) newMigrationHeader(
+	fileName string,
+	changes []differ.Change,
+	optionsHash string,
+) *migrationHeader {
+	header := &migrationHeader{
+		FileName:    fileName,
+		ToolVersion: g.Options.ToolVersion,
+		OptionsHash: optionsHash,
+		Comparison:  g.Options.Comparison,
+		Changes:     make([]string, 0, len(changes)),
+	}
+
+	if !g.Options.OmitTimestamp {
+		header.Generated = g.now()
+	}
+
+	for _, change := range changes {
+		header.Changes = append(header.Changes, change.Description)
+
+		if summary := differ.ViewDiffSummary(change); summary != "" {
+			header.Changes = append(header.Changes, "  "+summary)
+		}
+	}
+
+	return header
+}
+
+func (mh *migrationHeader) setLocks(locks []StatementLock) {
+	if lock, ok := strongestLock(locks); ok {
+		mh.StrongestLock = &lock
+	}
+}
+
+func (mh *migrationHeader) setRollbacks(rollbacks rollbackSummary) {
+	mh.Reversibility = rollbacks.worst
+	mh.RollbackNotes = rollbacks.notes
+}
+
+// writeStatements writes each statement with its comments. When fence is
+// set, statements goose cannot split on semicolons are wrapped in
+// StatementBegin/StatementEnd annotations. When transactional is set and
+// EmitSavepoints is enabled, each statement runs inside its own numbered
+// savepoint, and its comment carries the savepoint name.
+func (g *Generator) writeStatements(
+	sb contentWriter,
+	statements []DDLStatement,
+	fence, transactional bool,
+) {
+	savepoints := transactional && g.Options.EmitSavepoints
+
 	for i, stmt := range statements {
 		if i > 0 {
 			sb.WriteString("\n")
 		}
 
+		savepoint := savepointName(i)
+
 		if g.Options.IncludeComments && stmt.Description != "" {
-			fmt.Fprintf(&sb, "-- %s\n", stmt.Description)
+			if savepoints {
+				fmt.Fprintf(sb, "-- %s: %s%s\n",
+					savepoint, stmt.Description, sourceNote(stmt.Source))
+			} else {
+				fmt.Fprintf(sb, "-- %s%s\n", stmt.Description, sourceNote(stmt.Source))
+			}
 		}
 
 		if stmt.IsUnsafe && g.Options.IncludeComments {
 			sb.WriteString("-- WARNING: This operation is potentially unsafe\n")
 		}
 
-		sb.WriteString(stmt.SQL)
+		if note := rollbackNote(stmt); note != "" && g.Options.IncludeComments {
+			fmt.Fprintf(sb, "-- ROLLBACK NOTE: %s\n", note)
+		}
+
+		if savepoints {
+			fmt.Fprintf(sb, "SAVEPOINT %s;\n", savepoint)
+		}
 
-		trimmed := strings.TrimRight(stmt.SQL, " \t\n\r")
-		if trimmed != "" && !strings.HasSuffix(trimmed, ";") {
-			sb.WriteString(";")
+		fenced := fence && needsStatementFence(stmt.SQL)
+		if fenced {
+			sb.WriteString(gooseStatementBegin + "\n")
 		}
 
+		sb.WriteString(ensureStatementTerminated(stmt.SQL))
 		sb.WriteString("\n")
-	}
 
-	if useTransaction {
-		sb.WriteString("\nCOMMIT;\n")
+		if fenced {
+			sb.WriteString(gooseStatementEnd + "\n")
+		}
+
+		if savepoints {
+			fmt.Fprintf(sb, "RELEASE SAVEPOINT %s;\n", savepoint)
+		}
 	}
+}
 
-	return sb.String()
+// savepointName names the savepoint of the statement at index i of a file.
+// Names are numbered from 1 in file order, so they are the same every time a
+// file is generated.
+func savepointName(i int) string {
+	return fmt.Sprintf("pgtofu_%d", i+1)
 }
 
 func (g *Generator) ShouldUseTransaction(statements []DDLStatement) bool {
@@ -822,64 +1332,184 @@ func (g *Generator) ShouldUseTransaction(statements []DDLStatement) bool {
 	}
 }
 
-func (g *Generator) writeMigrationFiles(result *GenerateResult) error {
-	if err := os.MkdirAll(g.Options.OutputDir, DefaultDirMode); err != nil {
-		return util.WrapError("create output directory", err)
+// writeMigrationFiles writes every file of the run or none of them. stage
+// generates the files and writes and syncs them to a staging directory inside
+// OutputDir, on the same file system as their destinations; they are renamed
+// into place only once all of them are written. If any step fails, the files
+// moved so far are removed, the files they replaced restored and the
+// directories the run created removed, leaving OutputDir as it was.
+func (g *Generator) writeMigrationFiles(stage func(*outputRun) error) error {
+	run := &outputRun{replaced: make(map[string]string)}
+
+	err := g.openOutputRun(run)
+	if err == nil {
+		err = stage(run)
 	}
 
-	for _, migration := range result.Migrations {
-		if migration.UpFile != nil {
-			if err := g.writeMigrationFile(migration.UpFile); err != nil {
-				return util.WrapError("write UP file", err)
-			}
+	if err == nil {
+		if err = run.commit(); err != nil {
+			err = util.WrapError("write migration files", err)
 		}
+	}
 
-		if migration.DownFile != nil {
-			if err := g.writeMigrationFile(migration.DownFile); err != nil {
-				return util.WrapError("write DOWN file", err)
-			}
-		}
+	if err != nil {
+		run.rollback()
+		return err
 	}
 
+	os.RemoveAll(run.staging) //nolint:errcheck
+
 	return nil
 }
 
-func (g *Generator) writeMigrationFile(file *MigrationFile) error {
-	filePath := filepath.Join(g.Options.OutputDir, file.FileName)
+func (g *Generator) openOutputRun(run *outputRun) error {
+	if err := run.mkdirAll(g.Options.OutputDir); err != nil {
+		return util.WrapError("write migration files",
+			util.WrapError("create output directory", err))
+	}
 
-	if err := os.WriteFile(filePath, []byte(file.Content), DefaultFileMode); err != nil {
-		return util.WrapError("write file "+filePath, err)
+	staging, err := os.MkdirTemp(g.Options.OutputDir, stagingDirPrefix)
+	if err != nil {
+		return util.WrapError("write migration files",
+			util.WrapError("create staging directory", err))
 	}
 
+	run.staging = staging
+
 	return nil
 }
 
-func (g *Generator) GetNextMigrationVersion() (int, error) {
-	if _, err := os.Stat(g.Options.OutputDir); os.IsNotExist(err) {
-		return g.Options.StartVersion, nil
-	}
-
-	entries, err := os.ReadDir(g.Options.OutputDir)
-	if err != nil {
-		return 0, util.WrapError("read directory", err)
+// stageMigration writes the files of migration to the staging directory of
+// run. total is how many files the whole run writes.
+func (g *Generator) stageMigration(
+	ctx context.Context,
+	migration *MigrationPair,
+	run *outputRun,
+	total int,
+) error {
+	stagingDir := filepath.Join(run.staging, migration.Subdirectory)
+	if err := os.MkdirAll(stagingDir, DefaultDirMode); err != nil {
+		return util.WrapError("create staging directory", err)
 	}
 
-	maxVersion := g.Options.StartVersion - 1
+	dir := filepath.Join(g.Options.OutputDir, migration.Subdirectory)
 
-	for _, entry := range entries {
-		if entry.IsDir() {
+	for _, file := range []*MigrationFile{migration.UpFile, migration.DownFile} {
+		if file == nil {
 			continue
 		}
 
-		version, _, _, err := ParseMigrationFileName(entry.Name())
-		if err != nil {
+		kind := "write " + fileKind(file) + " file"
+		target := filepath.Join(dir, file.FileName)
+		staged := filepath.Join(stagingDir, file.FileName)
+
+		if err := ctx.Err(); err != nil {
+			return util.WrapError(kind, fmt.Errorf(
+				"generate cancelled while writing files after %d of %d: %w",
+				len(run.pending), total, err))
+		}
+
+		if err := writeSyncedFile(staged, file); err != nil {
+			return util.WrapError(kind, util.WrapError("write file "+target, err))
+		}
+
+		run.pending = append(run.pending, stagedFile{path: staged, target: target})
+		g.reportProgress("writing files", len(run.pending), total)
+	}
+
+	return nil
+}
+
+// keepMigrationContent lays out the files of migration into their Content.
+func keepMigrationContent(migration *MigrationPair) error {
+	for _, file := range []*MigrationFile{migration.UpFile, migration.DownFile} {
+		if file == nil {
 			continue
 		}
 
-		if version > maxVersion {
-			maxVersion = version
+		var sb strings.Builder
+		if err := layOutFile(file, &sb); err != nil {
+			return err
 		}
+
+		file.Content = sb.String()
+	}
+
+	return nil
+}
+
+// contentWriter is what the text of a migration is laid out on. Writes are
+// not checked: the buffer it is backed by keeps the first error and reports
+// it when flushed.
+type contentWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
+// layOutFile writes the text of file to w through a buffer, hashing it on
+// the way into Checksum, and releases the statements the file held.
+func layOutFile(file *MigrationFile, w io.Writer) error {
+	hash := sha256.New()
+	buf := bufio.NewWriter(io.MultiWriter(w, hash))
+
+	file.layOut(buf)
+
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	file.Checksum = hex.EncodeToString(hash.Sum(nil))
+	file.layOut = nil
+
+	return nil
+}
+
+// writeSyncedFile writes the text of migration to a new file at path and
+// flushes it to disk.
+func writeSyncedFile(path string, migration *MigrationFile) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, DefaultFileMode)
+	if err != nil {
+		return err
+	}
+
+	err = layOutFile(migration, file)
+	if err == nil {
+		err = file.Chmod(DefaultFileMode)
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
 	}
 
-	return maxVersion + 1, nil
+	return err
+}
+
+func fileKind(file *MigrationFile) string {
+	if file.Direction == "" {
+		return "migration"
+	}
+
+	return strings.ToUpper(string(file.Direction))
+}
+
+func (g *Generator) now() time.Time {
+	if g.Options.Now != nil {
+		return g.Options.Now()
+	}
+
+	return time.Now()
+}
+
+func (g *Generator) reportProgress(stage string, done, total int) {
+	if g.Options.Progress != nil {
+		g.Options.Progress(stage, done, total)
+	}
+}
+
+func (g *Generator) GetNextMigrationVersion() (int, error) {
+	return g.GetNextMigrationVersionIn("")
 }
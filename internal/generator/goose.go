@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"regexp"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+const (
+	gooseUp             = "-- +goose Up"
+	gooseDown           = "-- +goose Down"
+	gooseNoTransaction  = "-- +goose NO TRANSACTION"
+	gooseStatementBegin = "-- +goose StatementBegin"
+	gooseStatementEnd   = "-- +goose StatementEnd"
+)
+
+var (
+	dollarQuotePattern      = regexp.MustCompile(`\$[A-Za-z_]*\$`)
+	literalSemicolonPattern = regexp.MustCompile(`'(?:[^']|'')*;(?:[^']|'')*'`)
+)
+
+// gooseMigration builds the single goose file holding both directions of a
+// migration.
+func (g *Generator) gooseMigration(
+	plan *migrationPlan,
+	upStatements, downStatements []DDLStatement,
+	changes []differ.Change,
+	optionsHash string,
+) MigrationPair {
+	fileName := FormatMigrationFileNameFor(OutputFormatGoose, plan.version, plan.description, "")
+	file := &MigrationFile{
+		Version:     plan.version,
+		Description: plan.description,
+		FileName:    fileName,
+		Locks:       statementLocks(fileName, upStatements),
+		layOut: func(w contentWriter) {
+			g.writeGooseMigrationContent(w, plan, upStatements, downStatements, changes, optionsHash)
+		},
+		Reversibility: summarizeRollbacks(downStatements).worst,
+	}
+
+	return MigrationPair{
+		Version:      plan.version,
+		Description:  plan.description,
+		Subdirectory: plan.subdirectory,
+		UpFile:       file,
+	}
+}
+
+// writeGooseMigrationContent lays out a migration for goose. Goose runs each
+// section in its own transaction, so no BEGIN/COMMIT is written; a file that
+// must not run in a transaction is marked NO TRANSACTION instead, which goose
+// applies to both sections.
+func (g *Generator) writeGooseMigrationContent(
+	sb contentWriter,
+	plan *migrationPlan,
+	upStatements, downStatements []DDLStatement,
+	changes []differ.Change,
+	optionsHash string,
+) {
+	if g.Options.IncludeComments {
+		header := g.newMigrationHeader(
+			FormatMigrationFileNameFor(OutputFormatGoose, plan.version, plan.description, ""),
+			changes,
+			optionsHash,
+		)
+		header.DependencyNotes = plan.dependencyNotes
+		header.setLocks(statementLocks(header.FileName, upStatements))
+
+		if g.Options.GenerateDownMigrations {
+			header.setRollbacks(summarizeRollbacks(downStatements))
+		}
+
+		sb.WriteString(header.String())
+	}
+
+	useTransaction := g.ShouldUseTransaction(upStatements)
+	if g.Options.GenerateDownMigrations {
+		useTransaction = useTransaction && g.ShouldUseTransaction(downStatements)
+	}
+
+	if !useTransaction {
+		sb.WriteString(gooseNoTransaction + "\n\n")
+	}
+
+	sb.WriteString(gooseUp + "\n")
+	g.writeStatements(sb, upStatements, true, useTransaction)
+
+	if g.Options.GenerateDownMigrations {
+		sb.WriteString("\n" + gooseDown + "\n")
+		g.writeStatements(sb, downStatements, true, useTransaction)
+	}
+}
+
+// needsStatementFence reports whether goose's semicolon splitting would break
+// sql apart: dollar-quoted bodies such as functions and DO blocks, and string
+// literals containing semicolons.
+func needsStatementFence(sql string) bool {
+	return dollarQuotePattern.MatchString(sql) || literalSemicolonPattern.MatchString(sql)
+}
@@ -11,7 +11,8 @@ import (
 type SchemaName string
 
 func extractSchema(change *differ.Change) SchemaName {
-	if change.Type == differ.ChangeTypeAddSchema || change.Type == differ.ChangeTypeDropSchema {
+	if change.Type == differ.ChangeTypeAddSchema || change.Type == differ.ChangeTypeDropSchema ||
+		change.Type == differ.ChangeTypeModifySchemaComment {
 		return SchemaName(strings.ToLower(change.ObjectName))
 	}
 
@@ -66,6 +67,7 @@ func isTableRelatedChange(changeType differ.ChangeType) bool {
 	switch changeType {
 	case differ.ChangeTypeAddTable,
 		differ.ChangeTypeDropTable,
+		differ.ChangeTypeRecreateTable,
 		differ.ChangeTypeModifyTableComment,
 		differ.ChangeTypeAddColumn,
 		differ.ChangeTypeDropColumn,
@@ -73,14 +75,27 @@ func isTableRelatedChange(changeType differ.ChangeType) bool {
 		differ.ChangeTypeModifyColumnType,
 		differ.ChangeTypeModifyColumnNullability,
 		differ.ChangeTypeModifyColumnDefault,
+		differ.ChangeTypeModifyColumnStorage,
+		differ.ChangeTypeModifyColumnCompression,
+		differ.ChangeTypeModifyColumnGeneration,
+		differ.ChangeTypeAddColumnIdentity,
+		differ.ChangeTypeDropColumnIdentity,
+		differ.ChangeTypeModifyColumnIdentity,
+		differ.ChangeTypeRenameColumn,
 		differ.ChangeTypeAddConstraint,
 		differ.ChangeTypeDropConstraint,
 		differ.ChangeTypeModifyConstraint,
+		differ.ChangeTypeModifyConstraintComment,
 		differ.ChangeTypeAddIndex,
 		differ.ChangeTypeDropIndex,
 		differ.ChangeTypeModifyIndex,
+		differ.ChangeTypeModifyIndexComment,
 		differ.ChangeTypeAddPartition,
-		differ.ChangeTypeDropPartition:
+		differ.ChangeTypeDropPartition,
+		differ.ChangeTypeAddPolicy,
+		differ.ChangeTypeDropPolicy,
+		differ.ChangeTypeModifyPolicy,
+		differ.ChangeTypeModifyTableRowSecurity:
 		return true
 	default:
 		return false
@@ -93,15 +108,20 @@ func getChangePriorityForTable(changeType differ.ChangeType) int {
 		return 1
 	case differ.ChangeTypeModifyTableComment:
 		return 2
-	case differ.ChangeTypeAddColumn:
+	case differ.ChangeTypeAddColumn, differ.ChangeTypeRenameColumn:
 		return 3
-	case differ.ChangeTypeModifyColumnComment:
+	case differ.ChangeTypeModifyColumnComment, differ.ChangeTypeModifyColumnGeneration,
+		differ.ChangeTypeDropColumnIdentity:
 		return 4
 	case differ.ChangeTypeModifyColumnType:
 		return 5
-	case differ.ChangeTypeModifyColumnNullability:
-		return 6
 	case differ.ChangeTypeModifyColumnDefault:
+		return 6
+	case differ.ChangeTypeModifyColumnNullability,
+		differ.ChangeTypeModifyColumnStorage,
+		differ.ChangeTypeModifyColumnCompression,
+		differ.ChangeTypeAddColumnIdentity,
+		differ.ChangeTypeModifyColumnIdentity:
 		return 7
 	case differ.ChangeTypeAddConstraint, differ.ChangeTypeDropConstraint:
 		return 8
@@ -0,0 +1,850 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// LockLevel is a PostgreSQL table lock mode, ordered from the weakest to the
+// strongest. LockNone is a statement that locks no object existing before its
+// migration runs.
+type LockLevel int
+
+const (
+	LockNone LockLevel = iota
+	LockAccessShare
+	LockRowShare
+	LockRowExclusive
+	LockShareUpdateExclusive
+	LockShare
+	LockShareRowExclusive
+	LockExclusive
+	LockAccessExclusive
+)
+
+//nolint:gochecknoglobals
+var lockLevelNames = [...]string{
+	LockNone:                 "NONE",
+	LockAccessShare:          "ACCESS SHARE",
+	LockRowShare:             "ROW SHARE",
+	LockRowExclusive:         "ROW EXCLUSIVE",
+	LockShareUpdateExclusive: "SHARE UPDATE EXCLUSIVE",
+	LockShare:                "SHARE",
+	LockShareRowExclusive:    "SHARE ROW EXCLUSIVE",
+	LockExclusive:            "EXCLUSIVE",
+	LockAccessExclusive:      "ACCESS EXCLUSIVE",
+}
+
+func (l LockLevel) String() string {
+	if l < 0 || int(l) >= len(lockLevelNames) {
+		return "unknown"
+	}
+
+	return lockLevelNames[l]
+}
+
+// ParseLockLevel reads a lock mode as PostgreSQL names it, such as ACCESS
+// EXCLUSIVE, ignoring case and with its words separated by spaces, hyphens or
+// underscores, as in access-exclusive.
+func ParseLockLevel(s string) (LockLevel, error) {
+	name := strings.ToUpper(strings.NewReplacer("-", " ", "_", " ").Replace(strings.TrimSpace(s)))
+
+	for level, levelName := range lockLevelNames {
+		if name == levelName {
+			return LockLevel(level), nil
+		}
+	}
+
+	return LockNone, fmt.Errorf("unknown lock level %q", s)
+}
+
+// StatementLock is a statement of a migration file that locks an object
+// existing before the file runs.
+type StatementLock struct {
+	FileName       string
+	Description    string
+	LockLevel      LockLevel
+	AffectedObject string
+	// Note says what keeps the lock held longer than a catalog update, such
+	// as "rewrites the table". It is empty for a brief lock.
+	Note string
+}
+
+func (l StatementLock) String() string {
+	s := fmt.Sprintf("%s: %s on %s", l.FileName, l.LockLevel, l.AffectedObject)
+	if l.Note != "" {
+		s += " (" + l.Note + ")"
+	}
+
+	return s + ": " + l.Description
+}
+
+// SafetyReport lists the statements of the up migrations that lock objects
+// existing before their migration runs, in the order they run.
+type SafetyReport struct {
+	Locks []StatementLock
+}
+
+// AtLeast returns the locks of level or stronger.
+func (r *SafetyReport) AtLeast(level LockLevel) []StatementLock {
+	var locks []StatementLock
+
+	for _, lock := range r.Locks {
+		if lock.LockLevel >= level {
+			locks = append(locks, lock)
+		}
+	}
+
+	return locks
+}
+
+// AccessExclusive returns the locks that block reads as well as writes.
+func (r *SafetyReport) AccessExclusive() []StatementLock {
+	return r.AtLeast(LockAccessExclusive)
+}
+
+// LockError reports up statements taking locks of Options.FailOnLock or
+// stronger on objects that Options.AllowLocks does not allowlist.
+type LockError struct {
+	Level LockLevel
+	Locks []StatementLock
+}
+
+func (e *LockError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s taking %s or stronger locks:",
+		util.Plural(len(e.Locks), "statement", "statements"), e.Level)
+
+	for _, lock := range e.Locks {
+		b.WriteString("\n  " + lock.String())
+	}
+
+	b.WriteString("\nchange the desired schema, or allowlist the locked objects")
+
+	return b.String()
+}
+
+// Diagnostics returns a LOCK_NOT_ALLOWED error for each lock.
+func (e *LockError) Diagnostics() []diag.Warning {
+	diagnostics := make([]diag.Warning, 0, len(e.Locks))
+
+	for _, lock := range e.Locks {
+		diagnostics = append(diagnostics, diag.Warning{
+			Code:       diag.CodeLockNotAllowed,
+			Severity:   diag.SeverityError,
+			Message:    lock.String(),
+			ObjectName: lock.AffectedObject,
+			File:       lock.FileName,
+		})
+	}
+
+	return diagnostics
+}
+
+// checkLocks returns a LockError for the locks of the safety report that
+// reach Options.FailOnLock on objects AllowLocks does not match.
+func (g *Generator) checkLocks(report *SafetyReport) error {
+	if g.Options.FailOnLock == LockNone {
+		return nil
+	}
+
+	var denied []StatementLock
+
+	for _, lock := range report.AtLeast(g.Options.FailOnLock) {
+		if !locksAllowed(g.Options.AllowLocks, lock.AffectedObject) {
+			denied = append(denied, lock)
+		}
+	}
+
+	if len(denied) == 0 {
+		return nil
+	}
+
+	return &LockError{Level: g.Options.FailOnLock, Locks: denied}
+}
+
+// validateAllowLocks rejects AllowLocks entries that are not valid name
+// patterns.
+func validateAllowLocks(entries []string) []error {
+	var errs []error
+
+	for _, entry := range entries {
+		if _, err := path.Match(entry, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid allowed lock pattern %q: %w", entry, err))
+		}
+	}
+
+	return errs
+}
+
+func locksAllowed(allowlist []string, object string) bool {
+	for _, entry := range allowlist {
+		if matched, _ := path.Match(strings.ToLower(entry), object); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statementLocks returns the locks of the statements of a file.
+func statementLocks(fileName string, statements []DDLStatement) []StatementLock {
+	var locks []StatementLock
+
+	for _, stmt := range statements {
+		if stmt.LockLevel == LockNone {
+			continue
+		}
+
+		locks = append(locks, StatementLock{
+			FileName:       fileName,
+			Description:    stmt.Description,
+			LockLevel:      stmt.LockLevel,
+			AffectedObject: stmt.AffectedObject,
+			Note:           stmt.LockNote,
+		})
+	}
+
+	return locks
+}
+
+// strongestLock returns the first of the strongest locks, and false when
+// there are none.
+func strongestLock(locks []StatementLock) (StatementLock, bool) {
+	var strongest StatementLock
+
+	for _, lock := range locks {
+		if lock.LockLevel > strongest.LockLevel {
+			strongest = lock
+		}
+	}
+
+	return strongest, strongest.LockLevel != LockNone
+}
+
+// Notes of locks held longer than a catalog update.
+const (
+	lockNoteRewrite       = "rewrites the table"
+	lockNoteMayRewrite    = "may rewrite the table"
+	lockNoteScan          = "scans the table"
+	lockNoteBuildIndex    = "builds an index"
+	lockNoteScanPartition = "scans the partition"
+	lockNoteRefresh       = "reruns the view query"
+)
+
+// volatileFunctions are the built-in volatile functions a default commonly
+// calls. A column added with such a default is written to every row.
+//
+//nolint:gochecknoglobals
+var volatileFunctions = map[string]bool{
+	"random": true, "gen_random_uuid": true, "uuid_generate_v1": true,
+	"uuid_generate_v1mc": true, "uuid_generate_v4": true, "clock_timestamp": true,
+	"timeofday": true, "nextval": true, "txid_current": true,
+}
+
+// objectLock is a lock one statement takes on one object.
+type objectLock struct {
+	level  LockLevel
+	object string
+	note   string
+	// validates is set for VALIDATE CONSTRAINT, and setsNotNull for SET NOT
+	// NULL, which skips its scan after a CHECK (column IS NOT NULL) has been
+	// validated.
+	validates   bool
+	setsNotNull bool
+}
+
+// lockAnalyzer derives the locks statements take from their SQL, following
+// PostgreSQL's documented lock modes for each command. Locks on objects
+// missing from the schema the statements run against are left out: those
+// objects are created by the plan, and nothing else uses them yet. Functions
+// called by SELECT statements, such as TimescaleDB's, are not analyzed.
+type lockAnalyzer struct {
+	existing    map[string]bool
+	indexTables map[string]string
+}
+
+func newLockAnalyzer(before *schema.Database) *lockAnalyzer {
+	a := &lockAnalyzer{existing: map[string]bool{}, indexTables: map[string]string{}}
+	if before == nil {
+		return a
+	}
+
+	addIndexes := func(table string, indexes []schema.Index) {
+		for i := range indexes {
+			a.indexTables[lockObjectName(indexes[i].Schema, indexes[i].Name)] = table
+		}
+	}
+
+	for i := range before.Tables {
+		table := &before.Tables[i]
+		name := lockObjectName(table.Schema, table.Name)
+		a.existing[name] = true
+		addIndexes(name, table.Indexes)
+
+		if table.PartitionStrategy == nil {
+			continue
+		}
+
+		for j := range table.PartitionStrategy.Partitions {
+			partition := &table.PartitionStrategy.Partitions[j]
+			partitionName := lockObjectName(table.Schema, partition.Name)
+
+			if strings.Contains(partition.Name, ".") {
+				partitionName = strings.ToLower(partition.Name)
+			}
+
+			a.existing[partitionName] = true
+			addIndexes(partitionName, partition.Indexes)
+		}
+	}
+
+	for i := range before.Views {
+		a.existing[lockObjectName(before.Views[i].Schema, before.Views[i].Name)] = true
+	}
+
+	for i := range before.MaterializedViews {
+		mv := &before.MaterializedViews[i]
+		name := lockObjectName(mv.Schema, mv.Name)
+		a.existing[name] = true
+		addIndexes(name, mv.Indexes)
+	}
+
+	for i := range before.ContinuousAggregates {
+		ca := &before.ContinuousAggregates[i]
+		a.existing[lockObjectName(ca.Schema, ca.ViewName)] = true
+	}
+
+	return a
+}
+
+func lockObjectName(schemaName, name string) string {
+	return strings.ToLower(schema.QualifiedName(schemaName, name))
+}
+
+// analyze sets the lock fields of stmt from the strongest lock its statements
+// take on an existing object. A SET NOT NULL following a VALIDATE CONSTRAINT
+// of the same table in stmt is taken not to scan it, as the statements
+// SafeConstraintMode builds are written.
+func (a *lockAnalyzer) analyze(stmt *DDLStatement) {
+	stmt.LockLevel, stmt.AffectedObject, stmt.LockNote = LockNone, "", ""
+
+	validated := map[string]bool{}
+
+	for _, statement := range splitLockStatements(stmt.SQL) {
+		for _, lock := range a.statementLocks(statement) {
+			if lock.validates {
+				validated[lock.object] = true
+			}
+
+			if lock.setsNotNull && validated[lock.object] {
+				lock.note = ""
+			}
+
+			if lock.level > stmt.LockLevel && a.existing[lock.object] {
+				stmt.LockLevel, stmt.AffectedObject = lock.level, lock.object
+				stmt.LockNote = lock.note
+			}
+		}
+	}
+}
+
+// splitLockStatements returns the tokens of each statement of sql without
+// comments and semicolons. SQL that does not tokenize has no statements.
+func splitLockStatements(sql string) []lockTokens {
+	tokens, err := parser.NewLexer(sql).Tokenize()
+	if err != nil {
+		return nil
+	}
+
+	var (
+		statements []lockTokens
+		current    lockTokens
+	)
+
+	for _, token := range tokens {
+		switch token.Type {
+		case parser.TokenComment:
+			continue
+		case parser.TokenSemicolon, parser.TokenEOF:
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+		default:
+			current = append(current, token)
+		}
+	}
+
+	return statements
+}
+
+// lockTokens are the tokens of one statement.
+type lockTokens []parser.Token
+
+// word returns the uppercased literal of the token at i, or "" past the end.
+func (t lockTokens) word(i int) string {
+	if i < 0 || i >= len(t) || t[i].Type == parser.TokenString ||
+		t[i].Type == parser.TokenQuotedIdentifier {
+		return ""
+	}
+
+	return strings.ToUpper(t[i].Literal)
+}
+
+// skip returns the index after the optional words at i, such as IF EXISTS.
+func (t lockTokens) skip(i int, words ...string) int {
+	for j, word := range words {
+		if t.word(i+j) != word {
+			return i
+		}
+	}
+
+	return i + len(words)
+}
+
+// find returns the index of the first word at parenthesis depth zero from i
+// on, or -1.
+func (t lockTokens) find(i int, word string) int {
+	depth := 0
+
+	for ; i < len(t); i++ {
+		switch t[i].Type {
+		case parser.TokenLParen:
+			depth++
+		case parser.TokenRParen:
+			depth--
+		default:
+			if depth == 0 && t.word(i) == word {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// has reports whether word occurs at parenthesis depth zero from i on.
+func (t lockTokens) has(i int, word string) bool {
+	return t.find(i, word) != -1
+}
+
+// name reads the possibly qualified name at i. Unqualified names are in the
+// public schema, as the generator qualifies every name it writes.
+func (t lockTokens) name(i int) (string, int) {
+	var parts []string
+
+	for i < len(t) {
+		switch t[i].Type {
+		case parser.TokenIdentifier, parser.TokenKeyword:
+			parts = append(parts, strings.ToLower(t[i].Literal))
+		case parser.TokenQuotedIdentifier:
+			parts = append(parts, schema.FoldIdentifier(t[i].Literal))
+		default:
+			return qualifiedLockName(parts), i
+		}
+
+		i++
+		if i >= len(t) || t[i].Type != parser.TokenDot {
+			break
+		}
+
+		i++
+	}
+
+	return qualifiedLockName(parts), i
+}
+
+func qualifiedLockName(parts []string) string {
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return schema.DefaultSchema + "." + parts[0]
+	default:
+		return strings.Join(parts, ".")
+	}
+}
+
+// names reads the comma-separated names at i, as DROP TABLE takes them.
+func (t lockTokens) names(i int) []string {
+	var names []string
+
+	for i < len(t) {
+		name, next := t.name(i)
+		if name == "" {
+			break
+		}
+
+		names = append(names, name)
+
+		if t.word(next) != "," {
+			break
+		}
+
+		i = next + 1
+	}
+
+	return names
+}
+
+// statementLocks returns the locks one statement takes.
+func (a *lockAnalyzer) statementLocks(t lockTokens) []objectLock {
+	switch t.word(0) {
+	case "ALTER":
+		return a.alterLocks(t)
+	case "CREATE":
+		return createLocks(t)
+	case "DROP":
+		return a.dropLocks(t)
+	case "COMMENT":
+		return commentLocks(t)
+	case "REFRESH":
+		i := t.skip(1, "MATERIALIZED", "VIEW")
+		if t.word(i) == "CONCURRENTLY" {
+			name, _ := t.name(i + 1)
+			return []objectLock{{level: LockExclusive, object: name, note: lockNoteRefresh}}
+		}
+
+		name, _ := t.name(i)
+
+		return []objectLock{{level: LockAccessExclusive, object: name, note: lockNoteRefresh}}
+	case "TRUNCATE":
+		return locksOn(LockAccessExclusive, "", t.names(t.skip(t.skip(1, "TABLE"), "ONLY"))...)
+	case "INSERT":
+		name, _ := t.name(t.skip(1, "INTO"))
+		return locksOn(LockRowExclusive, "", name)
+	case "UPDATE":
+		name, _ := t.name(t.skip(1, "ONLY"))
+		return locksOn(LockRowExclusive, "", name)
+	case "DELETE":
+		name, _ := t.name(t.skip(t.skip(1, "FROM"), "ONLY"))
+		return locksOn(LockRowExclusive, "", name)
+	}
+
+	return nil
+}
+
+func locksOn(level LockLevel, note string, objects ...string) []objectLock {
+	locks := make([]objectLock, 0, len(objects))
+	for _, object := range objects {
+		locks = append(locks, objectLock{level: level, object: object, note: note})
+	}
+
+	return locks
+}
+
+func (a *lockAnalyzer) alterLocks(t lockTokens) []objectLock {
+	switch t.word(1) {
+	case "TABLE":
+		return alterTableLocks(t, t.skip(t.skip(2, "IF", "EXISTS"), "ONLY"))
+	case "INDEX":
+		index, next := t.name(t.skip(2, "IF", "EXISTS"))
+
+		table, ok := a.indexTables[index]
+		if !ok {
+			return nil
+		}
+
+		if t.word(next) == "SET" && t.word(next+1) == "TABLESPACE" {
+			return locksOn(LockAccessExclusive, lockNoteRewrite, table)
+		}
+
+		return locksOn(LockShareUpdateExclusive, "", table)
+	case "VIEW":
+		name, _ := t.name(t.skip(2, "IF", "EXISTS"))
+		return locksOn(LockAccessExclusive, "", name)
+	case "MATERIALIZED":
+		name, _ := t.name(t.skip(3, "IF", "EXISTS"))
+		return locksOn(LockAccessExclusive, "", name)
+	}
+
+	return nil
+}
+
+// alterTableLocks returns the locks of the actions of an ALTER TABLE whose
+// table name is at i.
+func alterTableLocks(t lockTokens, i int) []objectLock {
+	table, next := t.name(i)
+
+	var locks []objectLock
+
+	for start := next; start < len(t); {
+		end := t.find(start, ",")
+		if end == -1 {
+			end = len(t)
+		}
+
+		action := t[start:end]
+		locks = append(locks, alterTableActionLocks(action, table)...)
+		start = end + 1
+	}
+
+	return locks
+}
+
+//nolint:cyclop,gocyclo
+func alterTableActionLocks(t lockTokens, table string) []objectLock {
+	exclusive := func(note string) []objectLock {
+		return locksOn(LockAccessExclusive, note, table)
+	}
+
+	switch t.word(0) {
+	case "ADD":
+		return addLocks(t, table)
+	case "VALIDATE":
+		return []objectLock{{
+			level: LockShareUpdateExclusive, object: table, note: lockNoteScan, validates: true,
+		}}
+	case "ALTER":
+		return alterColumnLocks(t, table)
+	case "SET":
+		switch t.word(1) {
+		case "TABLESPACE", "LOGGED", "UNLOGGED", "ACCESS":
+			return exclusive(lockNoteRewrite)
+		case "WITHOUT":
+			if t.word(2) == "CLUSTER" {
+				return locksOn(LockShareUpdateExclusive, "", table)
+			}
+		case "(":
+			if strings.Contains(strings.ToLower(tokenText(t)), "timescaledb.") {
+				return exclusive("")
+			}
+
+			return locksOn(LockShareUpdateExclusive, "", table)
+		}
+	case "RESET":
+		return locksOn(LockShareUpdateExclusive, "", table)
+	case "CLUSTER":
+		return locksOn(LockShareUpdateExclusive, "", table)
+	case "ENABLE", "DISABLE":
+		if t.has(1, "TRIGGER") {
+			return locksOn(LockShareRowExclusive, "", table)
+		}
+	case "ATTACH":
+		partition, _ := t.name(2)
+
+		return append(locksOn(LockShareUpdateExclusive, "", table),
+			locksOn(LockAccessExclusive, lockNoteScanPartition, partition)...)
+	case "DETACH":
+		partition, next := t.name(2)
+		if t.word(next) == "CONCURRENTLY" {
+			return locksOn(LockShareUpdateExclusive, "", table, partition)
+		}
+
+		return locksOn(LockAccessExclusive, "", table, partition)
+	}
+
+	return exclusive("")
+}
+
+// tokenText joins the literals of t.
+func tokenText(t lockTokens) string {
+	literals := make([]string, 0, len(t))
+	for _, token := range t {
+		literals = append(literals, token.Literal)
+	}
+
+	return strings.Join(literals, " ")
+}
+
+// addLocks returns the locks of an ADD action: a constraint or a column.
+func addLocks(t lockTokens, table string) []objectLock {
+	i := 1
+	if t.word(i) == "CONSTRAINT" {
+		i += 2
+	}
+
+	notValid := t.has(i, "VALID") && t.word(t.find(i, "VALID")-1) == "NOT"
+
+	switch t.word(i) {
+	case "FOREIGN":
+		note := lockNoteScan
+		if notValid {
+			note = ""
+		}
+
+		referenced := ""
+		if ref := t.find(i, "REFERENCES"); ref != -1 {
+			referenced, _ = t.name(ref + 1)
+		}
+
+		return locksOn(LockShareRowExclusive, note, table, referenced)
+	case "CHECK":
+		note := lockNoteScan
+		if notValid {
+			note = ""
+		}
+
+		return locksOn(LockAccessExclusive, note, table)
+	case "PRIMARY", "UNIQUE", "EXCLUDE":
+		note := lockNoteBuildIndex
+		if t.has(i, "USING") && t.word(t.find(i, "USING")+1) == "INDEX" {
+			note = ""
+		}
+
+		return locksOn(LockAccessExclusive, note, table)
+	}
+
+	column := t.skip(t.skip(1, "COLUMN"), "IF", "NOT", "EXISTS")
+	note := ""
+
+	if addsRewrite(t, column) {
+		note = lockNoteRewrite
+	}
+
+	return locksOn(LockAccessExclusive, note, table)
+}
+
+// addsRewrite reports whether the ADD COLUMN whose name is at i writes the
+// column to every row: a stored generated or identity column, a serial, or a
+// default calling a volatile function.
+func addsRewrite(t lockTokens, i int) bool {
+	switch t.word(i + 1) {
+	case "SERIAL", "BIGSERIAL", "SMALLSERIAL", "SERIAL2", "SERIAL4", "SERIAL8":
+		return true
+	}
+
+	if t.has(i, "GENERATED") {
+		return true
+	}
+
+	for j := i; j+1 < len(t); j++ {
+		if t[j+1].Type == parser.TokenLParen && volatileFunctions[strings.ToLower(t[j].Literal)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func alterColumnLocks(t lockTokens, table string) []objectLock {
+	i := t.skip(1, "COLUMN") + 1
+
+	switch t.word(i) {
+	case "TYPE":
+		return locksOn(LockAccessExclusive, lockNoteMayRewrite, table)
+	case "SET":
+		switch t.word(i + 1) {
+		case "NOT":
+			return []objectLock{{
+				level: LockAccessExclusive, object: table, note: lockNoteScan, setsNotNull: true,
+			}}
+		case "DATA":
+			return locksOn(LockAccessExclusive, lockNoteMayRewrite, table)
+		case "STATISTICS", "(":
+			return locksOn(LockShareUpdateExclusive, "", table)
+		}
+	case "RESET":
+		return locksOn(LockShareUpdateExclusive, "", table)
+	}
+
+	return locksOn(LockAccessExclusive, "", table)
+}
+
+func createLocks(t lockTokens) []objectLock {
+	i := t.skip(t.skip(1, "OR", "REPLACE"), "UNIQUE")
+
+	switch t.word(i) {
+	case "INDEX":
+		level := LockShare
+		if t.word(i+1) == "CONCURRENTLY" {
+			level = LockShareUpdateExclusive
+		}
+
+		if on := t.find(i, "ON"); on != -1 {
+			table, _ := t.name(t.skip(on+1, "ONLY"))
+			return locksOn(level, lockNoteBuildIndex, table)
+		}
+	case "TRIGGER", "CONSTRAINT":
+		if on := t.find(i, "ON"); on != -1 {
+			table, _ := t.name(on + 1)
+			return locksOn(LockShareRowExclusive, "", table)
+		}
+	case "POLICY":
+		if on := t.find(i, "ON"); on != -1 {
+			table, _ := t.name(on + 1)
+			return locksOn(LockAccessExclusive, "", table)
+		}
+	case "VIEW", "RECURSIVE":
+		name, _ := t.name(t.skip(i, "RECURSIVE") + 1)
+		return locksOn(LockAccessExclusive, "", name)
+	case "TABLE":
+		if partition := t.find(i, "PARTITION"); partition != -1 && t.word(partition+1) == "OF" {
+			parent, _ := t.name(partition + 2)
+			return locksOn(LockAccessExclusive, "", parent)
+		}
+	}
+
+	return nil
+}
+
+func (a *lockAnalyzer) dropLocks(t lockTokens) []objectLock {
+	switch t.word(1) {
+	case "TABLE", "VIEW":
+		return locksOn(LockAccessExclusive, "", t.names(t.skip(2, "IF", "EXISTS"))...)
+	case "MATERIALIZED":
+		return locksOn(LockAccessExclusive, "", t.names(t.skip(3, "IF", "EXISTS"))...)
+	case "INDEX":
+		i := 2
+		level := LockAccessExclusive
+
+		if t.word(i) == "CONCURRENTLY" {
+			i++
+			level = LockShareUpdateExclusive
+		}
+
+		var tables []string
+
+		for _, index := range t.names(t.skip(i, "IF", "EXISTS")) {
+			if table, ok := a.indexTables[index]; ok {
+				tables = append(tables, table)
+			}
+		}
+
+		return locksOn(level, "", tables...)
+	case "TRIGGER", "POLICY":
+		if on := t.find(2, "ON"); on != -1 {
+			table, _ := t.name(on + 1)
+			return locksOn(LockAccessExclusive, "", table)
+		}
+	}
+
+	return nil
+}
+
+// commentLocks returns the SHARE UPDATE EXCLUSIVE lock COMMENT takes on the
+// relation it describes or whose column, constraint, trigger or policy it
+// describes.
+func commentLocks(t lockTokens) []objectLock {
+	i := t.skip(1, "ON")
+
+	switch t.word(i) {
+	case "TABLE", "VIEW":
+		name, _ := t.name(i + 1)
+		return locksOn(LockShareUpdateExclusive, "", name)
+	case "MATERIALIZED":
+		name, _ := t.name(i + 2)
+		return locksOn(LockShareUpdateExclusive, "", name)
+	case "COLUMN":
+		column, _ := t.name(i + 1)
+		if dot := strings.LastIndex(column, "."); dot != -1 {
+			return locksOn(LockShareUpdateExclusive, "", qualifiedLockName(
+				strings.Split(column[:dot], ".")))
+		}
+	case "CONSTRAINT", "TRIGGER", "POLICY":
+		if on := t.find(i, "ON"); on != -1 {
+			table, _ := t.name(t.skip(on+1, "DOMAIN"))
+			return locksOn(LockShareUpdateExclusive, "", table)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
@@ -36,6 +37,10 @@ func GenerateMigrationName(changes []differ.Change) string {
 	}
 
 	name := buildMigrationName(typeCounts, primaryObject)
+	if validatesOnly(changes) {
+		name = "validate_constraints"
+	}
+
 	schemaPrefix := getSchemaPrefix(schemas)
 
 	if schemaPrefix != "" {
@@ -46,7 +51,8 @@ func GenerateMigrationName(changes []differ.Change) string {
 }
 
 func extractSchemaFromChange(change *differ.Change) string {
-	if change.Type == differ.ChangeTypeAddSchema || change.Type == differ.ChangeTypeDropSchema {
+	if change.Type == differ.ChangeTypeAddSchema || change.Type == differ.ChangeTypeDropSchema ||
+		change.Type == differ.ChangeTypeModifySchemaComment {
 		return change.ObjectName
 	}
 
@@ -121,10 +127,14 @@ func describeSingleChange(changeType differ.ChangeType, objectName string) strin
 		return "add_table" + suffix
 	case differ.ChangeTypeDropTable:
 		return "drop_table" + suffix
+	case differ.ChangeTypeRecreateTable:
+		return "recreate_table" + suffix
 	case differ.ChangeTypeAddColumn:
 		return "add_columns" + suffix
 	case differ.ChangeTypeDropColumn:
 		return "drop_columns" + suffix
+	case differ.ChangeTypeRenameColumn:
+		return "rename_columns" + suffix
 	case differ.ChangeTypeModifyColumnType:
 		return "modify_column_types" + suffix
 	case differ.ChangeTypeAddIndex:
@@ -147,6 +157,10 @@ func describeSingleChange(changeType differ.ChangeType, objectName string) strin
 		return "add_retention" + suffix
 	case differ.ChangeTypeAddContinuousAggregate:
 		return "add_continuous_aggregate" + suffix
+	case differ.ChangeTypeAddRefreshPolicy, differ.ChangeTypeModifyRefreshPolicy:
+		return "update_refresh_policy" + suffix
+	case differ.ChangeTypeAddGrant, differ.ChangeTypeRevokeGrant:
+		return "update_grants" + suffix
 	default:
 		return "schema_changes" //nolint:goconst
 	}
@@ -224,7 +238,9 @@ func hasFunctionChanges(counts map[differ.ChangeType]int) bool {
 func hasTimescaleChanges(counts map[differ.ChangeType]int) bool {
 	return counts[differ.ChangeTypeAddHypertable] > 0 ||
 		counts[differ.ChangeTypeAddCompressionPolicy] > 0 ||
-		counts[differ.ChangeTypeAddRetentionPolicy] > 0
+		counts[differ.ChangeTypeAddRetentionPolicy] > 0 ||
+		counts[differ.ChangeTypeAddRefreshPolicy] > 0 ||
+		counts[differ.ChangeTypeModifyRefreshPolicy] > 0
 }
 
 func extractSimpleName(qualifiedName string, changeType differ.ChangeType) string {
@@ -241,9 +257,9 @@ func extractSimpleName(qualifiedName string, changeType differ.ChangeType) strin
 		name = name[:idx]
 	}
 
-	return strings.ToLower(strings.TrimFunc(name, func(r rune) bool {
+	return strings.TrimFunc(strings.ToLower(name), func(r rune) bool {
 		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
-	}))
+	})
 }
 
 func isColumnChange(changeType differ.ChangeType) bool {
@@ -253,6 +269,12 @@ func isColumnChange(changeType differ.ChangeType) bool {
 		changeType == differ.ChangeTypeModifyColumnNullability ||
 		changeType == differ.ChangeTypeModifyColumnDefault ||
 		changeType == differ.ChangeTypeModifyColumnComment ||
+		changeType == differ.ChangeTypeModifyColumnStorage ||
+		changeType == differ.ChangeTypeModifyColumnCompression ||
+		changeType == differ.ChangeTypeModifyColumnGeneration ||
+		changeType == differ.ChangeTypeAddColumnIdentity ||
+		changeType == differ.ChangeTypeDropColumnIdentity ||
+		changeType == differ.ChangeTypeModifyColumnIdentity ||
 		changeType == differ.ChangeTypeRenameColumn
 }
 
@@ -270,7 +292,23 @@ func sanitizeName(name string) string {
 }
 
 func FormatMigrationFileName(version int, description string, direction Direction) string {
-	return fmt.Sprintf("%06d_%s.%s.sql", version, description, direction)
+	return fmt.Sprintf(MigrationFilePattern, version, description, direction)
+}
+
+// FormatMigrationFileNameFor returns the file name used for a migration in
+// the given output format. Goose files hold both directions, so direction is
+// ignored for them.
+func FormatMigrationFileNameFor(
+	format OutputFormat,
+	version int,
+	description string,
+	direction Direction,
+) string {
+	if format == OutputFormatGoose {
+		return fmt.Sprintf(GooseMigrationFilePattern, version, description)
+	}
+
+	return FormatMigrationFileName(version, description, direction)
 }
 
 func ParseMigrationFileName(fileName string) (int, string, Direction, error) {
@@ -300,3 +338,32 @@ func ParseMigrationFileName(fileName string) (int, string, Direction, error) {
 
 	return version, description, direction, nil
 }
+
+// ParseMigrationFileNameFor parses a file name written in the given output
+// format. Goose names carry no direction, so it is empty for them; goose Go
+// migrations are accepted as well so their versions are not reused.
+func ParseMigrationFileNameFor(
+	format OutputFormat,
+	fileName string,
+) (int, string, Direction, error) {
+	if format != OutputFormatGoose {
+		return ParseMigrationFileName(fileName)
+	}
+
+	base := strings.TrimSuffix(fileName, ".sql")
+	if base == fileName {
+		base = strings.TrimSuffix(fileName, ".go")
+	}
+
+	versionPart, description, found := strings.Cut(base, "_")
+	if base == fileName || !found || description == "" {
+		return 0, "", "", fmt.Errorf("invalid goose migration file name: %s", fileName)
+	}
+
+	version, err := strconv.Atoi(versionPart)
+	if err != nil || version < 0 {
+		return 0, "", "", fmt.Errorf("invalid version number in: %s", fileName)
+	}
+
+	return version, description, "", nil
+}
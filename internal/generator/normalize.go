@@ -3,6 +3,8 @@ package generator
 import (
 	"regexp"
 	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 func NormalizeSQL(sql string) string {
@@ -67,6 +69,11 @@ func NormalizeDefaultValue(defaultVal string) string {
 }
 
 func NormalizeDataType(dataType string) string {
+	// A quoted type name is written as it is stored.
+	if strings.Contains(dataType, `"`) {
+		return strings.TrimSpace(dataType)
+	}
+
 	upper := strings.ToUpper(strings.TrimSpace(dataType))
 
 	isArray := strings.HasSuffix(upper, "[]")
@@ -83,10 +90,19 @@ func NormalizeDataType(dataType string) string {
 		"CHARACTER":                   "CHAR",
 	}
 
+	var precision *int
+	if schema.IsTimeType(upper) {
+		upper, precision = schema.ParseTimeType(upper)
+	}
+
 	if alias, ok := typeAliases[upper]; ok {
 		upper = alias
 	}
 
+	if precision != nil {
+		upper = schema.FormatTimeType(upper, *precision)
+	}
+
 	if isArray {
 		upper += "[]"
 	}
@@ -106,10 +122,14 @@ func normalizeBooleans(s string) string {
 	return result
 }
 
+// stringCastSuffix matches the cast of a string literal to a type, which may
+// be schema-qualified like the casts pg_dump writes for enum values.
+const stringCastSuffix = `::[a-zA-Z_][a-zA-Z0-9_\s]*(?:\.[a-zA-Z_][a-zA-Z0-9_]*)?(?:\[\])?`
+
 func normalizeTypeCasts(s string) string {
 	result := s
 
-	stringCastPattern := regexp.MustCompile(`('(?:[^']*|'')*')::[a-zA-Z_][a-zA-Z0-9_\s]*(?:\[\])?`)
+	stringCastPattern := regexp.MustCompile(`('(?:[^']*|'')*')` + stringCastSuffix)
 	result = stringCastPattern.ReplaceAllString(result, "$1")
 
 	numericCastPattern := regexp.MustCompile(
@@ -353,7 +373,7 @@ func handleQuoteChar(inQuote *bool, i *int, values string, current *strings.Buil
 }
 
 func normalizeArrayValue(val string) string {
-	castPattern := regexp.MustCompile(`^('(?:[^']*|'')*')::[a-zA-Z_][a-zA-Z0-9_\s]*(?:\[\])?$`)
+	castPattern := regexp.MustCompile(`^('(?:[^']*|'')*')` + stringCastSuffix + `$`)
 	if matches := castPattern.FindStringSubmatch(val); matches != nil {
 		return matches[1]
 	}
@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stagingDirPrefix names the directory inside OutputDir that a run writes its
+// files to before moving them into place.
+const stagingDirPrefix = ".pgtofu-tmp-"
+
+// stagedFile is a file written to the staging directory and the path it is
+// moved to.
+type stagedFile struct {
+	path   string
+	target string
+}
+
+// outputRun records what writing the files of a run changed in OutputDir, so
+// a failed run can undo it.
+type outputRun struct {
+	staging string
+	pending []stagedFile
+	// createdDirs are the directories the run created, parents first.
+	createdDirs []string
+	// moved are the files renamed into place so far.
+	moved []string
+	// replaced maps the existing files the run overwrote to their backups in
+	// the staging directory.
+	replaced map[string]string
+}
+
+// mkdirAll creates dir and its missing parents, recording each one created.
+func (r *outputRun) mkdirAll(dir string) error {
+	var missing []string
+
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		_, err := os.Stat(current)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		missing = append(missing, current)
+
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], DefaultDirMode); err != nil {
+			return err
+		}
+
+		r.createdDirs = append(r.createdDirs, missing[i])
+	}
+
+	return nil
+}
+
+// commit moves the staged files into place. An existing file at a target is
+// moved to the staging directory first, so rollback can restore it.
+func (r *outputRun) commit() error {
+	for _, file := range r.pending {
+		if err := r.mkdirAll(filepath.Dir(file.target)); err != nil {
+			return err
+		}
+
+		if info, err := os.Lstat(file.target); err == nil && info.Mode().IsRegular() {
+			backup := file.path + ".orig"
+			if err := os.Rename(file.target, backup); err != nil {
+				return err
+			}
+
+			r.replaced[file.target] = backup
+		}
+
+		if err := os.Rename(file.path, file.target); err != nil {
+			return err
+		}
+
+		r.moved = append(r.moved, file.target)
+	}
+
+	return nil
+}
+
+// rollback removes the files moved into place, restores the files they
+// replaced and removes the staging directory and every directory the run
+// created.
+func (r *outputRun) rollback() {
+	for _, path := range r.moved {
+		os.Remove(path) //nolint:errcheck
+	}
+
+	for target, backup := range r.replaced {
+		os.Rename(backup, target) //nolint:errcheck
+	}
+
+	if r.staging != "" {
+		os.RemoveAll(r.staging) //nolint:errcheck
+	}
+
+	for i := len(r.createdDirs) - 1; i >= 0; i-- {
+		os.Remove(r.createdDirs[i]) //nolint:errcheck
+	}
+}
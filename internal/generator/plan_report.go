@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+)
+
+// PlanReportVersion is the version of the PlanReport JSON shape. It is
+// incremented when a field is removed or changes meaning; fields may be added
+// without a new version.
+const PlanReportVersion = 1
+
+// PlanReport is the machine-readable form of a comparison: every change with
+// the SQL the generator builds for it, and counts of the changes by severity.
+// It is what pgtofu diff --format json prints.
+type PlanReport struct {
+	Version    int          `json:"version"`
+	HasChanges bool         `json:"has_changes"`
+	Summary    PlanSummary  `json:"summary"`
+	Changes    []PlanChange `json:"changes"`
+	// Diagnostics are the comparison's diagnostics followed by those of
+	// building the statements.
+	Diagnostics []diag.Warning `json:"diagnostics"`
+}
+
+// PlanSummary counts the changes of a PlanReport.
+type PlanSummary struct {
+	TotalChanges int `json:"total_changes"`
+	// BySeverity has an entry for every severity, including those no change
+	// has.
+	BySeverity         map[differ.ChangeSeverity]int `json:"by_severity"`
+	ByType             map[differ.ChangeType]int     `json:"by_type"`
+	DestructiveChanges int                           `json:"destructive_changes"`
+	// UnsafeChanges counts the changes whose up SQL is marked unsafe.
+	UnsafeChanges int `json:"unsafe_changes"`
+}
+
+// PlanChange is a change of a PlanReport. UpSQL and DownSQL are empty when the
+// statement could not be built, which is reported in the diagnostics.
+type PlanChange struct {
+	Order       int                   `json:"order"`
+	Type        differ.ChangeType     `json:"type"`
+	Severity    differ.ChangeSeverity `json:"severity"`
+	ObjectType  string                `json:"object_type"`
+	ObjectName  string                `json:"object_name"`
+	Description string                `json:"description"`
+	DependsOn   []string              `json:"depends_on"`
+	// Details holds the change's details that are plain values. Schema
+	// objects are replaced by their names and other values are left out.
+	Details   map[string]any `json:"details,omitempty"`
+	Migration string         `json:"migration,omitempty"`
+	File      string         `json:"file,omitempty"`
+	Line      int            `json:"line,omitempty"`
+	UpSQL     string         `json:"up_sql"`
+	DownSQL   string         `json:"down_sql"`
+	Unsafe    bool           `json:"unsafe"`
+	// ManualRollback is set when the down SQL does not fully restore the
+	// state before the change.
+	ManualRollback bool `json:"manual_rollback"`
+}
+
+// NewPlanReport builds the report of result, building each change's
+// statements with the Idempotent, CascadeDrops and DetachPartitions settings
+// of opts.
+func NewPlanReport(result *differ.DiffResult, opts *Options) *PlanReport {
+	builder := NewDDLBuilder(result, opts.Idempotent)
+	builder.cascadeDrops = opts.CascadeDrops
+
+	stats := result.PlanStats()
+	report := &PlanReport{
+		Version:    PlanReportVersion,
+		HasChanges: result.HasChanges(),
+		Summary: PlanSummary{
+			TotalChanges: stats.TotalChanges,
+			BySeverity: map[differ.ChangeSeverity]int{
+				differ.SeveritySafe:                  0,
+				differ.SeverityPotentiallyBreaking:   0,
+				differ.SeverityBreaking:              0,
+				differ.SeverityDataMigrationRequired: 0,
+			},
+			ByType:             stats.ByType,
+			DestructiveChanges: stats.DestructiveChanges,
+		},
+		Changes:     make([]PlanChange, 0, len(result.Changes)),
+		Diagnostics: append([]diag.Warning{}, result.Diagnostics...),
+	}
+
+	for severity, count := range stats.BySeverity {
+		report.Summary.BySeverity[severity] = count
+	}
+
+	var detached map[string]bool
+	if opts.DetachPartitions {
+		detached = detachedPartitionNames(result.Changes)
+	}
+
+	for _, change := range result.Changes {
+		change = detachedPartition(change, detached)
+
+		planned := newPlanChange(change)
+
+		if stmt, err := builder.BuildUpStatement(change); err != nil {
+			report.addDiagnostics(changeWarning(change, diag.CodeBuildUpFailed,
+				diag.SeverityError,
+				fmt.Sprintf("Failed to build UP statement for %s: %v", change.Description, err)))
+		} else {
+			report.addDiagnostics(builder.finishStatement(change, &stmt)...)
+			planned.UpSQL = stmt.SQL
+			planned.Unsafe = stmt.IsUnsafe
+		}
+
+		if stmt, err := builder.BuildDownStatement(change); err != nil {
+			report.addDiagnostics(changeWarning(change, diag.CodeBuildDownFailed,
+				diag.SeverityError,
+				fmt.Sprintf("Failed to build DOWN statement for %s: %v", change.Description, err)))
+			planned.ManualRollback = true
+		} else {
+			report.addDiagnostics(builder.finishStatement(change, &stmt)...)
+			planned.DownSQL = stmt.SQL
+			planned.ManualRollback = stmt.Reversibility == ReversibilityManual
+		}
+
+		if planned.Unsafe {
+			report.Summary.UnsafeChanges++
+		}
+
+		report.Changes = append(report.Changes, planned)
+	}
+
+	return report
+}
+
+func (r *PlanReport) addDiagnostics(warnings ...diag.Warning) {
+	r.Diagnostics = append(r.Diagnostics, warnings...)
+}
+
+func newPlanChange(change differ.Change) PlanChange {
+	planned := PlanChange{
+		Order:       change.Order,
+		Type:        change.Type,
+		Severity:    change.Severity,
+		ObjectType:  change.ObjectType,
+		ObjectName:  change.ObjectName,
+		Description: change.Description,
+		DependsOn:   append([]string{}, change.DependsOn...),
+		Details:     planDetails(change.Details),
+		Migration:   change.Migration,
+	}
+
+	if change.Source != nil && !change.Source.Removed {
+		planned.File = change.Source.Location.File
+		planned.Line = change.Source.Location.Line
+	}
+
+	return planned
+}
+
+// planDetails returns the details that can be reported as they are, with
+// schema objects replaced by their qualified names, or by their names when
+// they have no qualified name, such as columns and constraints.
+func planDetails(details map[string]any) map[string]any {
+	reported := make(map[string]any, len(details))
+
+	for key, value := range details {
+		if v, ok := planDetail(value); ok {
+			reported[key] = v
+		}
+	}
+
+	if len(reported) == 0 {
+		return nil
+	}
+
+	return reported
+}
+
+func planDetail(value any) (any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string, bool, int, int64, float64, []string, map[string]string:
+		return v, true
+	case interface{ QualifiedName() string }:
+		return v.QualifiedName(), true
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, false
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	// Schema objects held by value only have QualifiedName on their pointer.
+	ptr := reflect.New(rv.Type())
+	ptr.Elem().Set(rv)
+
+	if named, ok := ptr.Interface().(interface{ QualifiedName() string }); ok {
+		return named.QualifiedName(), true
+	}
+
+	if name := rv.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String {
+		return name.String(), true
+	}
+
+	return nil, false
+}
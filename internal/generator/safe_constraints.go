@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// splitSafeConstraints rewrites batches so that each run of new CHECK and
+// foreign key constraints of existing tables, and of their columns becoming
+// NOT NULL, ends its batch with the constraints added NOT VALID, and the
+// following batch validates them. Adding a NOT VALID constraint only holds
+// its lock briefly, and VALIDATE CONSTRAINT scans the table without blocking
+// writes. Both must commit separately: a lock is held until the end of its
+// transaction, so the validation gets its own file, and the rest of the
+// batch follows in another.
+func (g *Generator) splitSafeConstraints(
+	batches [][]differ.Change,
+	result *differ.DiffResult,
+) [][]differ.Change {
+	var split [][]differ.Change
+
+	for _, batch := range batches {
+		var segment []differ.Change
+
+		for i := 0; i < len(batch); {
+			if !isSafeConstraintCandidate(batch[i], result) {
+				segment = append(segment, batch[i])
+				i++
+
+				continue
+			}
+
+			end := i
+			for end < len(batch) && isSafeConstraintCandidate(batch[end], result) {
+				end++
+			}
+
+			validate := make([]differ.Change, 0, end-i)
+
+			for _, change := range batch[i:end] {
+				addChange := withValidateStep(change, ValidateStepAddNotValid)
+				addChange.Description = "Add NOT VALID for: " + change.Description
+
+				segment = append(segment, addChange)
+				validate = append(validate, withValidateStep(change, ValidateStepValidate))
+			}
+
+			split = append(split, segment, validate)
+			segment = nil
+			i = end
+		}
+
+		if len(segment) > 0 {
+			split = append(split, segment)
+		}
+	}
+
+	return split
+}
+
+// isSafeConstraintCandidate reports whether change adds a named CHECK or
+// foreign key constraint to, or sets NOT NULL on a column of, a table that
+// already exists. A table created in the same plan has no rows to scan.
+// Partitioned tables and hypertables pass their constraints on to every
+// partition or chunk, and are left to the single statement.
+func isSafeConstraintCandidate(change differ.Change, result *differ.DiffResult) bool {
+	switch change.Type {
+	case differ.ChangeTypeAddConstraint:
+		constraint, err := getDetailConstraint(change.Details)
+		if err != nil || constraint.Name == "" ||
+			(constraint.Type != schema.ConstraintCheck && constraint.Type != "FOREIGN KEY") {
+			return false
+		}
+	case differ.ChangeTypeModifyColumnNullability:
+		nullable, err := getDetailBool(change.Details, DetailKeyNewNullable)
+		if err != nil || nullable {
+			return false
+		}
+	default:
+		return false
+	}
+
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil || result.Current == nil {
+		return false
+	}
+
+	builder := &DDLBuilder{result: result}
+
+	for _, db := range []*schema.Database{result.Current, result.Desired} {
+		table := builder.getTable(tableName, db)
+		if table == nil || table.PartitionStrategy != nil ||
+			builder.getHypertable(tableName, db) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func withValidateStep(change differ.Change, step string) differ.Change {
+	return withDetail(change, DetailKeyValidateStep, step)
+}
+
+func validateStep(change differ.Change) string {
+	step, _ := change.Details[DetailKeyValidateStep.String()].(string)
+	return step
+}
+
+// validatesOnly reports whether every change validates a constraint added
+// NOT VALID, as in the batches splitSafeConstraints writes for validation.
+func validatesOnly(changes []differ.Change) bool {
+	for _, change := range changes {
+		if validateStep(change) != ValidateStepValidate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// notNullCheckName names the CHECK (column IS NOT NULL) constraint that
+// SafeConstraintMode validates before setting NOT NULL on a column. It is
+// not the name PostgreSQL 18 gives the not-null constraint itself.
+func notNullCheckName(table, column string) string {
+	return schema.SynthesizedIdentifier("not_null_check", table, column)
+}
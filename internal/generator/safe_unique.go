@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// splitSafeUniqueConstraints rewrites batches so that each run of new UNIQUE
+// constraints is preceded by its own batch building the backing indexes
+// CONCURRENTLY. CREATE INDEX CONCURRENTLY cannot run inside a transaction, so
+// the build must live in a separate file; the constraint is then promoted with
+// ADD CONSTRAINT ... USING INDEX in the following file.
+func (g *Generator) splitSafeUniqueConstraints(
+	batches [][]differ.Change,
+	result *differ.DiffResult,
+) [][]differ.Change {
+	var split [][]differ.Change
+
+	for _, batch := range batches {
+		var segment []differ.Change
+
+		for i := 0; i < len(batch); {
+			if !isSafeUniqueCandidate(batch[i], result) {
+				segment = append(segment, batch[i])
+				i++
+
+				continue
+			}
+
+			end := i
+			for end < len(batch) && isSafeUniqueCandidate(batch[end], result) {
+				end++
+			}
+
+			if len(segment) > 0 {
+				split = append(split, segment)
+			}
+
+			build := make([]differ.Change, 0, end-i)
+			segment = make([]differ.Change, 0, end-i)
+
+			for _, change := range batch[i:end] {
+				buildChange := withUniqueStep(change, UniqueStepBuildIndex)
+				buildChange.Description = "Build index concurrently for: " + change.Description
+
+				build = append(build, buildChange)
+				segment = append(segment, withUniqueStep(change, UniqueStepPromote))
+			}
+
+			split = append(split, build)
+			i = end
+		}
+
+		if len(segment) > 0 {
+			split = append(split, segment)
+		}
+	}
+
+	return split
+}
+
+func isSafeUniqueCandidate(change differ.Change, result *differ.DiffResult) bool {
+	if change.Type != differ.ChangeTypeAddConstraint {
+		return false
+	}
+
+	constraint, err := getDetailConstraint(change.Details)
+	if err != nil || constraint.Type != schema.ConstraintUnique {
+		return false
+	}
+
+	tableName, err := getDetailString(change.Details, DetailKeyTable)
+	if err != nil {
+		return false
+	}
+
+	// TimescaleDB does not support building indexes concurrently on hypertables.
+	builder := &DDLBuilder{result: result}
+
+	return builder.getHypertable(tableName, result.Desired) == nil
+}
+
+func withUniqueStep(change differ.Change, step string) differ.Change {
+	return withDetail(change, DetailKeyUniqueStep, step)
+}
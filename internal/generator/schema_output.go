@@ -0,0 +1,239 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/util"
+)
+
+// migrationPlan is the version, name and directory a batch of changes is
+// written as.
+type migrationPlan struct {
+	version      int
+	description  string
+	subdirectory string
+	// dependencyNotes warn about migrations in other schemas' subdirectories
+	// that the batch depends on or that depend on it.
+	dependencyNotes []string
+}
+
+// planMigrations numbers and names the batches. With PartitionOutputBySchema
+// each batch is placed in its schema's subdirectory, whose versions continue
+// from the files already in it, and dependencies between schemas are noted.
+func (g *Generator) planMigrations(
+	batches [][]differ.Change,
+) ([]migrationPlan, []diag.Warning, error) {
+	plans := make([]migrationPlan, len(batches))
+
+	for i, batch := range batches {
+		plans[i] = migrationPlan{
+			version:     g.Options.StartVersion + i,
+			description: migrationDescription(batch),
+		}
+
+		if g.Options.Baseline {
+			plans[i].description = baselineDescription
+		}
+	}
+
+	if !g.Options.PartitionOutputBySchema {
+		return plans, nil, nil
+	}
+
+	nextVersions := make(map[string]int)
+
+	for i, batch := range batches {
+		subdirectory := g.batchSubdirectory(batch)
+
+		version, seen := nextVersions[subdirectory]
+		if !seen {
+			var err error
+			if version, err = g.GetNextMigrationVersionIn(subdirectory); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		plans[i].version = version
+		plans[i].subdirectory = subdirectory
+		nextVersions[subdirectory] = version + 1
+	}
+
+	return plans, g.noteCrossSchemaDependencies(plans, batches), nil
+}
+
+// migrationDescription names a batch after its changes, or after the
+// migration its changes are pinned to.
+func migrationDescription(changes []differ.Change) string {
+	if pinned := changes[0].Migration; pinned != "" {
+		return sanitizeName(pinned)
+	}
+
+	return GenerateMigrationName(changes)
+}
+
+// batchSubdirectory returns the schema subdirectory of a batch. Schema and
+// extension changes, and batches whose changes span schemas, belong to every
+// schema and go to the shared subdirectory.
+func (g *Generator) batchSubdirectory(batch []differ.Change) string {
+	var batchSchema SchemaName
+
+	for i := range batch {
+		switch batch[i].Type {
+		case differ.ChangeTypeAddSchema, differ.ChangeTypeDropSchema,
+			differ.ChangeTypeModifySchemaComment, differ.ChangeTypeAddExtension, differ.ChangeTypeDropExtension,
+			differ.ChangeTypeModifyExtension:
+			return g.sharedSubdirectory()
+		}
+
+		changeSchema := extractSchema(&batch[i])
+		if batchSchema != "" && changeSchema != batchSchema {
+			return g.sharedSubdirectory()
+		}
+
+		batchSchema = changeSchema
+	}
+
+	if name := sanitizeName(string(batchSchema)); name != "" {
+		return name
+	}
+
+	return g.sharedSubdirectory()
+}
+
+func (g *Generator) sharedSubdirectory() string {
+	if g.Options.SharedSubdirectory == "" {
+		return DefaultSharedSubdirectory
+	}
+
+	return g.Options.SharedSubdirectory
+}
+
+// noteCrossSchemaDependencies records, in the plans of both migrations, each
+// dependency of a batch on a batch in another schema's subdirectory, and
+// returns a warning for each. Shared migrations are applied before any
+// schema's, so dependencies on them are not noted.
+func (g *Generator) noteCrossSchemaDependencies(
+	plans []migrationPlan,
+	batches [][]differ.Change,
+) []diag.Warning {
+	shared := g.sharedSubdirectory()
+	owners := make(map[string]int)
+
+	for i, batch := range batches {
+		for j := range batch {
+			for _, name := range changeObjectNames(&batch[j]) {
+				if _, exists := owners[name]; !exists {
+					owners[name] = i
+				}
+			}
+		}
+	}
+
+	var warnings []diag.Warning
+
+	for i, batch := range batches {
+		if plans[i].subdirectory == shared {
+			continue
+		}
+
+		noted := make(map[int]bool)
+
+		for j := range batch {
+			for _, dep := range batch[j].DependsOn {
+				owner, exists := owners[normalizeObjectName(dep)]
+				if !exists || noted[owner] || plans[owner].subdirectory == shared ||
+					plans[owner].subdirectory == plans[i].subdirectory {
+					continue
+				}
+
+				noted[owner] = true
+
+				dependent, dependency := g.migrationRef(plans[i]), g.migrationRef(plans[owner])
+				plans[i].dependencyNotes = append(plans[i].dependencyNotes, fmt.Sprintf(
+					"depends on %s, which another schema's pipeline may apply after this one",
+					dependency,
+				))
+				plans[owner].dependencyNotes = append(plans[owner].dependencyNotes, fmt.Sprintf(
+					"%s depends on this migration, but another schema's pipeline may apply "+
+						"it first",
+					dependent,
+				))
+				warnings = append(warnings, changeWarning(batch[j], diag.CodeCrossSchemaDependency,
+					diag.SeverityWarning, fmt.Sprintf(
+						"migration %s depends on %s in another schema's directory; apply %s first",
+						dependent, dependency, dependency,
+					)))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// changeObjectNames returns the normalized names other changes refer to a
+// change's object by.
+func changeObjectNames(change *differ.Change) []string {
+	var names []string
+
+	if change.ObjectName != "" {
+		names = append(names, normalizeObjectName(change.ObjectName))
+	}
+
+	if change.Type == differ.ChangeTypeAddTable {
+		if table, hasTable, err := getOptionalTable(change.Details); err == nil && hasTable {
+			names = append(names, normalizeObjectName(table.QualifiedName()))
+		}
+	}
+
+	return names
+}
+
+// migrationRef names a planned migration by its subdirectory, version and
+// description, without the extension of any one of its files.
+func (g *Generator) migrationRef(plan migrationPlan) string {
+	name := FormatMigrationFileNameFor(
+		g.Options.OutputFormat, plan.version, plan.description, DirectionUp,
+	)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".sql"), "."+string(DirectionUp))
+
+	return plan.subdirectory + "/" + name
+}
+
+// GetNextMigrationVersionIn returns the version after the highest one in the
+// subdirectory of OutputDir, or StartVersion when there is none higher.
+func (g *Generator) GetNextMigrationVersionIn(subdirectory string) (int, error) {
+	dir := filepath.Join(g.Options.OutputDir, subdirectory)
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return g.Options.StartVersion, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, util.WrapError("read directory", err)
+	}
+
+	maxVersion := g.Options.StartVersion - 1
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		version, _, _, err := ParseMigrationFileNameFor(g.Options.OutputFormat, entry.Name())
+		if err != nil {
+			continue
+		}
+
+		if version > maxVersion {
+			maxVersion = version
+		}
+	}
+
+	return maxVersion + 1, nil
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+)
+
+// joinStatements joins the statements of a multi-statement DDLStatement, one
+// per line, each terminated by exactly one semicolon. Blank statements, such
+// as a section a builder left out, are skipped instead of leaving a stray
+// separator behind.
+func joinStatements(statements []string) string {
+	var sb strings.Builder
+
+	for _, statement := range statements {
+		statement = ensureStatementTerminated(strings.TrimSpace(statement))
+		if statement == "" {
+			continue
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(statement)
+	}
+
+	return sb.String()
+}
+
+// removeEmptyStatements drops the semicolons of empty statements from sql: a
+// semicolon that only whitespace and comments separate from the previous one,
+// or from the start of sql. Semicolons inside strings, quoted identifiers and
+// dollar-quoted bodies are left alone, and sql that does not tokenize is
+// returned as it is. It reports how many semicolons were dropped.
+func removeEmptyStatements(sql string) (string, int) {
+	tokens, err := parser.NewLexer(sql).Tokenize()
+	if err != nil {
+		return sql, 0
+	}
+
+	var (
+		sb      strings.Builder
+		removed int
+		last    int
+	)
+
+	empty := true
+
+	for _, token := range tokens {
+		switch token.Type {
+		case parser.TokenComment, parser.TokenEOF:
+			continue
+		case parser.TokenSemicolon:
+			if empty {
+				sb.WriteString(strings.TrimRight(sql[last:token.Start], " \t"))
+				last = token.End
+				removed++
+
+				continue
+			}
+
+			empty = true
+		default:
+			empty = false
+		}
+	}
+
+	if removed == 0 {
+		return sql, 0
+	}
+
+	sb.WriteString(sql[last:])
+
+	return sb.String(), removed
+}
+
+// joinedStatement returns stmt with the Statements a builder set joined into
+// its SQL, so a builder may return either.
+func joinedStatement(stmt DDLStatement) DDLStatement {
+	if len(stmt.Statements) > 0 {
+		stmt.SQL = joinStatements(stmt.Statements)
+		stmt.Statements = nil
+	}
+
+	return stmt
+}
+
+// finishStatement removes any empty statements left in the SQL of a built
+// statement. Builders should not produce those, so each removal is reported
+// with the builder that did.
+func (b *DDLBuilder) finishStatement(change differ.Change, stmt *DDLStatement) []diag.Warning {
+	sql, removed := removeEmptyStatements(stmt.SQL)
+	if removed == 0 {
+		return nil
+	}
+
+	stmt.SQL = sql
+
+	return []diag.Warning{changeWarning(change, diag.CodeEmptyStatement, diag.SeverityInfo,
+		fmt.Sprintf("%s built SQL with %d empty statements, which were removed: %s",
+			b.registry.builderName(change.Type), removed, change.Description))}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
@@ -18,30 +19,33 @@ func formatSQLStringLiteral(s string) string {
 	return fmt.Sprintf("'%s'", escapeSQLString(s))
 }
 
+// QuoteIdentifier writes a name into SQL, quoted exactly when PostgreSQL
+// would not read it back as written.
 func QuoteIdentifier(name string) string {
-	if len(name) == 0 {
-		return `""`
-	}
+	return schema.QuoteIdentifier(name)
+}
 
-	if name[0] >= '0' && name[0] <= '9' {
-		return fmt.Sprintf(`"%s"`, name)
-	}
+// excludeConstraintDefinition renders an EXCLUDE constraint from its parts,
+// quoting the elements that are column names.
+func excludeConstraintDefinition(c *schema.Constraint) string {
+	quoted := *c
+	quoted.ExcludeElements = make([]schema.ExcludeElement, len(c.ExcludeElements))
 
-	for _, char := range name {
-		if (char < 'a' || char > 'z') && (char < '0' || char > '9') && char != '_' {
-			return fmt.Sprintf(`"%s"`, name)
+	for i, element := range c.ExcludeElements {
+		if !strings.ContainsAny(element.Element, " \t\n()\".") {
+			element.Element = QuoteIdentifier(element.Element)
 		}
+
+		quoted.ExcludeElements[i] = element
 	}
 
-	return name
+	return quoted.ExcludeDefinition()
 }
 
+// QualifiedName writes schemaName.name into SQL, each part quoted as
+// QuoteIdentifier quotes it.
 func QualifiedName(schemaName, name string) string {
-	if schemaName == "" {
-		schemaName = schema.DefaultSchema
-	}
-
-	return fmt.Sprintf("%s.%s", QuoteIdentifier(schemaName), QuoteIdentifier(name))
+	return schema.QuotedName(schemaName, name)
 }
 
 type tokenBuffer struct {
@@ -85,25 +89,9 @@ func formatColumnDefinition(col *schema.Column) (string, error) {
 		return "", errors.New("column data type cannot be empty")
 	}
 
-	if defaultValue != "" && strings.Contains(strings.ToLower(defaultValue), "nextval(") {
-		escapedColName := regexp.QuoteMeta(col.Name)
-
-		serialPattern := regexp.MustCompile(
-			`(?i)nextval\('(?:[^']+_)?` + escapedColName + `_seq'(?:::regclass)?\)`,
-		)
-		if serialPattern.MatchString(defaultValue) {
-			switch strings.ToLower(col.DataType) {
-			case "integer", "int", "int4":
-				dataType = "SERIAL"
-				defaultValue = ""
-			case "bigint", "int8":
-				dataType = "BIGSERIAL"
-				defaultValue = ""
-			case "smallint", "int2":
-				dataType = "SMALLSERIAL"
-				defaultValue = ""
-			}
-		}
+	if serialType := serialTypeFor(col); serialType != "" {
+		dataType = serialType
+		defaultValue = ""
 	}
 
 	if col.IsArray && !strings.HasSuffix(dataType, "[]") {
@@ -114,11 +102,22 @@ func formatColumnDefinition(col *schema.Column) (string, error) {
 	buf.Write(QuoteIdentifier(col.Name))
 	buf.Write(dataType)
 
+	if col.Compression != "" {
+		buf.Write("COMPRESSION")
+		buf.Write(strings.ToLower(col.Compression))
+	}
+
 	if !col.IsNullable {
 		buf.Write("NOT NULL")
 	}
 
-	if defaultValue != "" {
+	// Generated and identity columns cannot have a default.
+	if col.Identity != nil {
+		buf.Write(formatIdentity(col))
+	} else if col.IsGenerated && col.GenerationExpression != "" {
+		expression, _ := unwrapOuterParens(col.GenerationExpression)
+		buf.Write("GENERATED ALWAYS AS (" + expression + ") STORED")
+	} else if defaultValue != "" {
 		buf.Write("DEFAULT")
 		buf.Write(defaultValue)
 	}
@@ -126,6 +125,173 @@ func formatColumnDefinition(col *schema.Column) (string, error) {
 	return buf.String(), nil
 }
 
+// formatColumnOptions declares the NOT NULL and DEFAULT of a typed table's
+// column, whose type comes from the table's type. It is empty when the
+// column has neither.
+func formatColumnOptions(col *schema.Column) string {
+	var buf tokenBuffer
+
+	if !col.IsNullable {
+		buf.Write("NOT NULL")
+	}
+
+	if defaultValue := NormalizeDefaultValue(col.Default); defaultValue != "" {
+		buf.Write("DEFAULT")
+		buf.Write(defaultValue)
+	}
+
+	if buf.String() == "" {
+		return ""
+	}
+
+	return QuoteIdentifier(col.Name) + " WITH OPTIONS " + buf.String()
+}
+
+// formatColumnStorage sets the storage strategy of a column that does not use
+// the default of its type. A column definition only takes STORAGE from
+// PostgreSQL 16 on, so the strategy is set after the column is created.
+func formatColumnStorage(schemaName, tableName string, col *schema.Column) string {
+	if col.Storage == "" || col.EffectiveStorage() == schema.DefaultStorage(col) {
+		return ""
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET STORAGE %s;",
+		QualifiedName(schemaName, tableName),
+		QuoteIdentifier(col.Name),
+		strings.ToUpper(col.Storage))
+}
+
+// serialTypeFor returns SERIAL, BIGSERIAL or SMALLSERIAL for a column declared
+// with one of them, which shows as an integer column whose default draws from
+// its own sequence, and an empty string for any other column.
+func serialTypeFor(col *schema.Column) string {
+	defaultValue := NormalizeDefaultValue(col.Default)
+	if !strings.Contains(strings.ToLower(defaultValue), "nextval(") {
+		return ""
+	}
+
+	serialPattern := regexp.MustCompile(
+		`(?i)nextval\('(?:[^']+_)?` + regexp.QuoteMeta(col.Name) + `_seq'(?:::regclass)?\)`,
+	)
+	if !serialPattern.MatchString(defaultValue) {
+		return ""
+	}
+
+	switch strings.ToLower(col.DataType) {
+	case "integer", "int", "int4":
+		return "SERIAL"
+	case "bigint", "int8":
+		return "BIGSERIAL"
+	case "smallint", "int2":
+		return "SMALLSERIAL"
+	default:
+		return ""
+	}
+}
+
+// formatSequenceResync moves the sequence of a serial column past the highest
+// value in the column. A serial column created on a table that already has
+// rows gets a new sequence starting at 1, whatever values the rows carry. The
+// sequence is looked up through the column, so the statement does nothing
+// when the column has none.
+func formatSequenceResync(schemaName, tableName, columnName string) string {
+	table := QualifiedName(schemaName, tableName)
+
+	return fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE((SELECT MAX(%s) FROM %s), 1));",
+		formatSQLStringLiteral(table),
+		formatSQLStringLiteral(columnName),
+		QuoteIdentifier(columnName),
+		table,
+	)
+}
+
+// formatIdentityResync moves the sequence of a column that just became an
+// identity column past the values the rows already carry, like
+// formatSequenceResync. The sequence is left at its start when every value is
+// before it.
+func formatIdentityResync(schemaName, tableName string, col *schema.Column) string {
+	table := QualifiedName(schemaName, tableName)
+	column := QuoteIdentifier(col.Name)
+	bound, comparison := "MAX", ">="
+
+	if col.Identity.Increment < 0 {
+		bound, comparison = "MIN", "<="
+	}
+
+	return fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence(%s, %s), %s(%s)) FROM %s HAVING %s(%s) %s %d;",
+		formatSQLStringLiteral(table),
+		formatSQLStringLiteral(col.Name),
+		bound, column, table,
+		bound, column, comparison, col.Identity.StartValue,
+	)
+}
+
+// formatIdentity returns the GENERATED ... AS IDENTITY clause of an identity
+// column, listing the sequence options that differ from the defaults.
+func formatIdentity(col *schema.Column) string {
+	clause := "GENERATED " + col.Identity.Generation + " AS IDENTITY"
+
+	seq := col.Identity.Sequence(strings.ToLower(col.DataType))
+	defaultMin, defaultMax := schema.SequenceBounds(seq.DataType, seq.Increment)
+
+	var options tokenBuffer
+
+	if seq.Increment != 1 {
+		options.Write(fmt.Sprintf("INCREMENT BY %d", seq.Increment))
+	}
+
+	if seq.MinValue != defaultMin {
+		options.Write(fmt.Sprintf("MINVALUE %d", seq.MinValue))
+	}
+
+	if seq.MaxValue != defaultMax {
+		options.Write(fmt.Sprintf("MAXVALUE %d", seq.MaxValue))
+	}
+
+	if seq.StartValue != seq.DefaultStart() {
+		options.Write(fmt.Sprintf("START WITH %d", seq.StartValue))
+	}
+
+	if seq.CacheSize > 1 {
+		options.Write(fmt.Sprintf("CACHE %d", seq.CacheSize))
+	}
+
+	if seq.IsCyclic {
+		options.Write("CYCLE")
+	}
+
+	if options.String() == "" {
+		return clause
+	}
+
+	return clause + " (" + options.String() + ")"
+}
+
+var sequenceNamePattern = regexp.MustCompile(`(?i)nextval\('([^']+)'`)
+
+// defaultSequenceName returns the sequence a column default draws from, or an
+// empty string when it draws from none.
+func defaultSequenceName(col *schema.Column) string {
+	match := sequenceNamePattern.FindStringSubmatch(col.Default)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+// withSequenceNote names the serial columns whose sequences a statement
+// resyncs in its description.
+func withSequenceNote(description string, columns []string) string {
+	if len(columns) == 0 {
+		return description
+	}
+
+	return fmt.Sprintf("%s; resyncs the sequence of %s", description, strings.Join(columns, ", "))
+}
+
 func formatConstraintDefinition( //nolint:cyclop,gocognit,gocyclo
 	c *schema.Constraint,
 ) (string, error) {
@@ -148,6 +314,7 @@ func formatConstraintDefinition( //nolint:cyclop,gocognit,gocyclo
 
 		buf.Write("PRIMARY KEY")
 		buf.Write(fmt.Sprintf("(%s)", quoteColumns(c.Columns)))
+		writeIncludeColumns(&buf, c.IncludeColumns)
 
 	case "FOREIGN KEY":
 		if len(c.Columns) == 0 {
@@ -161,9 +328,9 @@ func formatConstraintDefinition( //nolint:cyclop,gocognit,gocyclo
 			return "", errors.New("foreign key constraint missing referenced table")
 		}
 
-		referenced := c.ReferencedTable
+		referenced := QuoteIdentifier(c.ReferencedTable)
 		if c.ReferencedSchema != "" {
-			referenced = schema.QualifiedName(c.ReferencedSchema, c.ReferencedTable)
+			referenced = QualifiedName(c.ReferencedSchema, c.ReferencedTable)
 		}
 
 		buf.Write("REFERENCES")
@@ -190,6 +357,7 @@ func formatConstraintDefinition( //nolint:cyclop,gocognit,gocyclo
 
 		buf.Write("UNIQUE")
 		buf.Write(fmt.Sprintf("(%s)", quoteColumns(c.Columns)))
+		writeIncludeColumns(&buf, c.IncludeColumns)
 
 	case "CHECK":
 		def := strings.TrimSpace(c.Definition)
@@ -207,6 +375,11 @@ func formatConstraintDefinition( //nolint:cyclop,gocognit,gocyclo
 		}
 
 	case "EXCLUDE":
+		if len(c.ExcludeElements) > 0 && c.ExcludeMethod != "" {
+			buf.Write(excludeConstraintDefinition(c))
+			break
+		}
+
 		if strings.TrimSpace(c.Definition) == "" {
 			return "", errors.New("exclude constraint requires a definition")
 		}
@@ -269,6 +442,15 @@ func formatConstraintDefinition( //nolint:cyclop,gocognit,gocyclo
 	return buf.String(), nil
 }
 
+// writeIncludeColumns writes the INCLUDE clause of a primary key or unique
+// constraint that has one.
+func writeIncludeColumns(buf *tokenBuffer, columns []string) {
+	if len(columns) > 0 {
+		buf.Write("INCLUDE")
+		buf.Write(fmt.Sprintf("(%s)", quoteColumns(columns)))
+	}
+}
+
 func formatCheckConstraintDefinition(def string) string {
 	lines := compactSQLLines(def)
 	if len(lines) == 0 {
@@ -810,6 +992,12 @@ func isExpression(s string) bool {
 }
 
 func formatIndexDefinition(idx *schema.Index) (string, error) {
+	return formatCreateIndex(idx, "")
+}
+
+// formatCreateIndex formats the CREATE INDEX of idx with options, such as
+// CONCURRENTLY, written after INDEX.
+func formatCreateIndex(idx *schema.Index, options string) (string, error) {
 	if idx == nil {
 		return "", errors.New("index cannot be nil")
 	}
@@ -829,8 +1017,14 @@ func formatIndexDefinition(idx *schema.Index) (string, error) {
 		buf.Write("CREATE INDEX")
 	}
 
+	buf.Write(options)
 	buf.Write(QuoteIdentifier(idx.Name))
 	buf.Write("ON")
+
+	if idx.OnlyParent {
+		buf.Write("ONLY")
+	}
+
 	buf.Write(QualifiedName(idx.Schema, idx.TableName))
 
 	if idx.Type != "" && idx.Type != "btree" {
@@ -896,7 +1090,21 @@ func formatViewDefinition(v *schema.View, orReplace bool) (string, error) {
 		prefix = "CREATE OR REPLACE VIEW"
 	}
 
-	return fmt.Sprintf("%s %s AS\n%s", prefix, QualifiedName(v.Schema, v.Name), v.Definition), nil
+	with := ""
+	if len(v.Options) > 0 {
+		with = fmt.Sprintf(" WITH (%s)", formatStorageParams(v.Options))
+	}
+
+	definition := v.Definition
+
+	checkOption := strings.ToUpper(strings.TrimSpace(v.CheckOption))
+	if checkOption != "" && checkOption != "NONE" {
+		definition = strings.TrimSuffix(strings.TrimSpace(definition), ";")
+		definition += fmt.Sprintf("\nWITH %s CHECK OPTION", checkOption)
+	}
+
+	return fmt.Sprintf("%s %s%s AS\n%s",
+		prefix, QualifiedName(v.Schema, v.Name), with, definition), nil
 }
 
 func formatMaterializedViewDefinition(mv *schema.MaterializedView) (string, error) {
@@ -912,13 +1120,35 @@ func formatMaterializedViewDefinition(mv *schema.MaterializedView) (string, erro
 		return "", errors.New("materialized view definition cannot be empty")
 	}
 
+	with := ""
+	if len(mv.StorageParams) > 0 {
+		with = fmt.Sprintf(" WITH (%s)", formatStorageParams(mv.StorageParams))
+	}
+
 	return fmt.Sprintf(
-		"CREATE MATERIALIZED VIEW %s AS\n%s",
+		"CREATE MATERIALIZED VIEW %s%s AS\n%s",
 		QualifiedName(mv.Schema, mv.Name),
+		with,
 		mv.Definition,
 	), nil
 }
 
+// dollarQuoteTag returns the dollar quote to write body in: $$ unless body
+// contains it, else the first of $fn$, $fn1$, $fn2$... that it does not.
+func dollarQuoteTag(body string) string {
+	tag := "$$"
+
+	for i := 0; strings.Contains(body, tag); i++ {
+		if i == 0 {
+			tag = "$fn$"
+		} else {
+			tag = "$fn" + strconv.Itoa(i) + "$"
+		}
+	}
+
+	return tag
+}
+
 func formatFunctionDefinition(f *schema.Function) (string, error) {
 	if f == nil {
 		return "", errors.New("function cannot be nil")
@@ -928,7 +1158,7 @@ func formatFunctionDefinition(f *schema.Function) (string, error) {
 		return "", errors.New("function name cannot be empty")
 	}
 
-	if strings.TrimSpace(f.ReturnType) == "" {
+	if strings.TrimSpace(f.ReturnType) == "" && !f.IsProcedure() {
 		return "", errors.New("function return type cannot be empty")
 	}
 
@@ -936,16 +1166,7 @@ func formatFunctionDefinition(f *schema.Function) (string, error) {
 		return "", errors.New("function language cannot be empty")
 	}
 
-	nameUpper := strings.ToUpper(f.Name)
-
-	schemaName := f.Schema
-	if schemaName == "" {
-		schemaName = schema.DefaultSchema
-	}
-
-	funcSignature := fmt.Sprintf("%s.%s", QuoteIdentifier(schemaName), nameUpper)
-
-	funcSignature += formatFunctionArgumentSignature(f)
+	funcSignature := QualifiedName(f.Schema, f.Name) + formatFunctionArgumentSignature(f)
 
 	body := strings.TrimSpace(f.Body)
 	if strings.HasPrefix(body, "$$") && strings.HasSuffix(body, "$$") && len(body) >= 4 {
@@ -955,12 +1176,20 @@ func formatFunctionDefinition(f *schema.Function) (string, error) {
 	}
 
 	var sb strings.Builder
-	sb.WriteString("CREATE OR REPLACE FUNCTION ")
+	sb.WriteString("CREATE OR REPLACE " + f.Keyword() + " ")
 	sb.WriteString(funcSignature)
 	sb.WriteString("\n\n")
-	sb.WriteString("RETURNS ")
-	sb.WriteString(formatFunctionDataType(f.ReturnType))
-	sb.WriteString(" AS $$\n")
+
+	// A procedure has no result, volatility or strictness.
+	if !f.IsProcedure() {
+		sb.WriteString("RETURNS ")
+		sb.WriteString(formatFunctionDataType(f.ReturnType))
+		sb.WriteString(" ")
+	}
+
+	tag := dollarQuoteTag(body)
+
+	sb.WriteString("AS " + tag + "\n")
 
 	if body != "" {
 		sb.WriteString(body)
@@ -970,11 +1199,11 @@ func formatFunctionDefinition(f *schema.Function) (string, error) {
 		}
 	}
 
-	sb.WriteString("$$ ")
+	sb.WriteString(tag + " ")
 	sb.WriteString("LANGUAGE ")
 	sb.WriteString(f.Language)
 
-	if f.Volatility != "" && f.Volatility != "VOLATILE" {
+	if f.Volatility != "" && f.Volatility != "VOLATILE" && !f.IsProcedure() {
 		sb.WriteString(" ")
 		sb.WriteString(f.Volatility)
 	}
@@ -983,13 +1212,33 @@ func formatFunctionDefinition(f *schema.Function) (string, error) {
 		sb.WriteString(" SECURITY DEFINER")
 	}
 
-	if f.IsStrict {
+	if f.IsStrict && !f.IsProcedure() {
 		sb.WriteString(" STRICT")
 	}
 
 	return sb.String(), nil
 }
 
+// formatTriggerState renders the ALTER TABLE statement that puts trigger in
+// one of the schema.TriggerEnabled* states.
+func formatTriggerState(t *schema.Trigger, state string) string {
+	return fmt.Sprintf("ALTER TABLE %s %s %s;",
+		QualifiedName(t.Schema, t.TableName), triggerStateAction(state), QuoteIdentifier(t.Name))
+}
+
+func triggerStateAction(state string) string {
+	switch state {
+	case schema.TriggerEnabledReplica:
+		return "ENABLE REPLICA TRIGGER"
+	case schema.TriggerEnabledAlways:
+		return "ENABLE ALWAYS TRIGGER"
+	case schema.TriggerEnabledDisabled:
+		return "DISABLE TRIGGER"
+	default:
+		return "ENABLE TRIGGER"
+	}
+}
+
 func formatTriggerDefinition(t *schema.Trigger) (string, error) {
 	if t == nil {
 		return "", errors.New("trigger cannot be nil")
@@ -1036,17 +1285,7 @@ func formatTriggerDefinition(t *schema.Trigger) (string, error) {
 
 	sb.WriteString("\nEXECUTE FUNCTION ")
 
-	funcNameUpper := strings.ToUpper(t.FunctionName)
-
-	funcSchema := t.FunctionSchema
-	if funcSchema == "" {
-		funcSchema = schema.DefaultSchema
-	}
-
-	sb.WriteString(QuoteIdentifier(funcSchema))
-	sb.WriteString(".")
-	sb.WriteString(funcNameUpper)
-
+	sb.WriteString(QualifiedName(t.FunctionSchema, t.FunctionName))
 	sb.WriteString("()")
 
 	return sb.String(), nil
@@ -1077,11 +1316,49 @@ func formatCreateHypertable(ht *schema.Hypertable) (string, error) {
 		)
 	}
 
-	if ht.SpacePartitions > 0 {
-		args = append(args, fmt.Sprintf("number_partitions => %d", ht.SpacePartitions))
+	return fmt.Sprintf("SELECT create_hypertable(%s)", strings.Join(args, ", ")), nil
+}
+
+func formatAddDimension(ht *schema.Hypertable, dim *schema.Dimension) (string, error) {
+	if ht == nil || dim == nil {
+		return "", errors.New("hypertable dimension cannot be nil")
 	}
 
-	return fmt.Sprintf("SELECT create_hypertable(%s)", strings.Join(args, ", ")), nil
+	if dim.ColumnName == "" {
+		return "", errors.New("dimension column cannot be empty")
+	}
+
+	args := []string{
+		fmt.Sprintf("'%s'", QualifiedName(ht.Schema, ht.TableName)),
+		fmt.Sprintf("'%s'", dim.ColumnName),
+	}
+
+	if dim.Type == schema.DimensionSpace {
+		if dim.NumberPartitions <= 0 {
+			return "", fmt.Errorf("space dimension %s requires number_partitions", dim.ColumnName)
+		}
+
+		args = append(args, fmt.Sprintf("number_partitions => %d", dim.NumberPartitions))
+	} else if dim.Interval != "" {
+		args = append(args, fmt.Sprintf("chunk_time_interval => INTERVAL '%s'", dim.Interval))
+	}
+
+	args = append(args, "if_not_exists => true")
+
+	return fmt.Sprintf("SELECT add_dimension(%s)", strings.Join(args, ", ")), nil
+}
+
+func appendDimensions(sb *strings.Builder, ht *schema.Hypertable) error {
+	for i := range ht.Dimensions {
+		sql, err := formatAddDimension(ht, &ht.Dimensions[i])
+		if err != nil {
+			return err
+		}
+
+		appendStatement(sb, sql)
+	}
+
+	return nil
 }
 
 func formatCompressionPolicy(ht *schema.Hypertable) (string, error) {
@@ -1127,6 +1404,34 @@ func formatCompressionPolicy(ht *schema.Hypertable) (string, error) {
 	return fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress)", tableName), nil
 }
 
+func formatCompressionJob(tableName string, policy *schema.CompressionPolicy) string {
+	if policy == nil || policy.CompressAfter == "" {
+		return ""
+	}
+
+	args := []string{
+		fmt.Sprintf("'%s'", tableName),
+		fmt.Sprintf("INTERVAL '%s'", policy.CompressAfter),
+	}
+
+	if policy.ScheduleInterval != "" {
+		args = append(
+			args,
+			fmt.Sprintf("schedule_interval => INTERVAL '%s'", policy.ScheduleInterval),
+		)
+	}
+
+	if policy.InitialStart != "" {
+		args = append(args, "initial_start => "+policy.InitialStart)
+	}
+
+	return fmt.Sprintf("SELECT add_compression_policy(%s)", strings.Join(args, ", "))
+}
+
+func formatRemoveCompressionJob(tableName string) string {
+	return fmt.Sprintf("SELECT remove_compression_policy('%s', if_exists => true)", tableName)
+}
+
 func formatRetentionPolicy(ht *schema.Hypertable) (string, error) {
 	if ht == nil {
 		return "", errors.New("hypertable cannot be nil")
@@ -1145,6 +1450,49 @@ func formatRetentionPolicy(ht *schema.Hypertable) (string, error) {
 	), nil
 }
 
+// formatRefreshPolicyJob builds the add_continuous_aggregate_policy call of
+// the continuous aggregate viewName, or "" when it has no policy.
+func formatRefreshPolicyJob(viewName string, policy *schema.RefreshPolicy) string {
+	if policy == nil {
+		return ""
+	}
+
+	args := []string{
+		"start_offset => " + formatRefreshOffset(policy.StartOffset),
+		"end_offset => " + formatRefreshOffset(policy.EndOffset),
+		fmt.Sprintf("schedule_interval => INTERVAL '%s'", policy.ScheduleInterval),
+	}
+
+	if policy.InitialStart != "" {
+		args = append(args, "initial_start => "+policy.InitialStart)
+	}
+
+	return fmt.Sprintf("SELECT add_continuous_aggregate_policy('%s',\n%s%s)",
+		viewName, sqlIndent, strings.Join(args, ",\n"+sqlIndent))
+}
+
+// formatRefreshOffset writes an offset of a refresh window: NULL for an open
+// end, an integer as it is for an aggregate on an integer time column, and
+// an interval otherwise.
+func formatRefreshOffset(offset string) string {
+	offset = strings.TrimSpace(offset)
+
+	if offset == "" || strings.EqualFold(offset, "null") {
+		return "NULL"
+	}
+
+	if _, err := strconv.ParseInt(offset, 10, 64); err == nil {
+		return offset
+	}
+
+	return fmt.Sprintf("INTERVAL '%s'", offset)
+}
+
+func formatRemoveRefreshPolicyJob(viewName string) string {
+	return fmt.Sprintf(
+		"SELECT remove_continuous_aggregate_policy('%s', if_exists => true)", viewName)
+}
+
 func dedupeCompressionColumns(columns []string) []string {
 	result := make([]string, 0, len(columns))
 	seen := make(map[string]struct{}, len(columns))
@@ -0,0 +1,166 @@
+package generator_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+// snapshotDir returns the contents of every file under dir by relative path,
+// with directories recorded as "<dir>".
+func snapshotDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	snapshot := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			snapshot[rel] = "<dir>"
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		snapshot[rel] = string(content)
+
+		return err
+	})
+	require.NoError(t, err)
+
+	return snapshot
+}
+
+// plannedFiles returns the paths, relative to OutputDir, that a run with opts
+// writes.
+func plannedFiles(t *testing.T, opts *generator.Options, result *differ.DiffResult) []string {
+	t.Helper()
+
+	preview := *opts
+	preview.PreviewMode = true
+
+	genResult, err := generator.New(&preview).Generate(result)
+	require.NoError(t, err)
+
+	var paths []string
+
+	for _, migration := range genResult.Migrations {
+		for _, file := range []*generator.MigrationFile{migration.UpFile, migration.DownFile} {
+			if file != nil {
+				paths = append(paths, filepath.Join(migration.Subdirectory, file.FileName))
+			}
+		}
+	}
+
+	return paths
+}
+
+func TestGenerator_FailedMoveLeavesOutputDirUntouched(t *testing.T) {
+	t.Parallel()
+
+	for _, bySchema := range []bool{false, true} {
+		name := "flat"
+		if bySchema {
+			name = "by schema"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := generator.DefaultOptions()
+			opts.OutputDir = t.TempDir()
+			opts.PartitionOutputBySchema = bySchema
+
+			result := cancelTestDiff(t)
+			paths := plannedFiles(t, opts, result)
+			require.Greater(t, len(paths), 3)
+
+			// The first file replaces an existing one, and a directory in the
+			// place of the third makes moving it fail.
+			first := filepath.Join(opts.OutputDir, paths[0])
+			require.NoError(t, os.MkdirAll(filepath.Dir(first), 0o755))
+			require.NoError(t, os.WriteFile(first, []byte("-- hand edited\n"), 0o644))
+			require.NoError(t, os.WriteFile(
+				filepath.Join(opts.OutputDir, "README.md"), []byte("migrations\n"), 0o644))
+			require.NoError(t, os.MkdirAll(filepath.Join(opts.OutputDir, paths[2]), 0o755))
+
+			before := snapshotDir(t, opts.OutputDir)
+
+			genResult, err := generator.New(opts).Generate(result)
+			require.Error(t, err)
+			assert.Nil(t, genResult)
+			assert.Equal(t, before, snapshotDir(t, opts.OutputDir))
+		})
+	}
+}
+
+func TestGenerator_FailedWriteRemovesCreatedDirectories(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(parent, "keep.sql"), []byte("SELECT 1;\n"), 0o644))
+
+	before := snapshotDir(t, parent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = filepath.Join(parent, "db", "migrations")
+	opts.PartitionOutputBySchema = true
+	opts.Progress = func(stage string, done, _ int) {
+		if stage == "writing files" && done == 2 {
+			cancel()
+		}
+	}
+
+	genResult, err := generator.New(opts).GenerateContext(ctx, cancelTestDiff(t))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, genResult)
+	assert.Equal(t, before, snapshotDir(t, parent))
+}
+
+func TestGenerator_SuccessfulWriteRemovesStagingDirectory(t *testing.T) {
+	t.Parallel()
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = t.TempDir()
+	opts.PartitionOutputBySchema = true
+
+	result := cancelTestDiff(t)
+	paths := plannedFiles(t, opts, result)
+
+	genResult, err := generator.New(opts).Generate(result)
+	require.NoError(t, err)
+	assert.Equal(t, len(paths), genResult.FilesGenerated)
+
+	written := make(map[string]bool)
+
+	for path, content := range snapshotDir(t, opts.OutputDir) {
+		if content != "<dir>" {
+			written[path] = true
+		}
+	}
+
+	assert.Len(t, written, len(paths))
+
+	for _, path := range paths {
+		assert.True(t, written[path], path)
+	}
+}
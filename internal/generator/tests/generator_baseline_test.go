@@ -0,0 +1,69 @@
+package generator_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestGenerator_Baseline(t *testing.T) {
+	t.Parallel()
+
+	var sql strings.Builder
+
+	sql.WriteString(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE SCHEMA app;
+CREATE TYPE app.status AS ENUM ('active', 'inactive');
+`)
+
+	for i := range 30 {
+		fmt.Fprintf(&sql, "CREATE TABLE app.t%02d (id BIGINT PRIMARY KEY, status app.status);\n", i)
+	}
+
+	sql.WriteString("CREATE TABLE events (id BIGINT PRIMARY KEY);\n")
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{}, parseSchemaSQL(t, sql.String()))
+	require.NoError(t, err)
+
+	opts := testOptions()
+	opts.Baseline = true
+	opts.MaxOperationsPerFile = 5
+
+	result, err := generator.New(opts).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	migration := result.Migrations[0]
+	assert.Equal(t, 1, migration.Version)
+	assert.Equal(t, "000001_baseline.up.sql", migration.UpFile.FileName)
+
+	up := migration.UpFile.Content
+	schemaAt := strings.Index(up, "CREATE SCHEMA IF NOT EXISTS app;")
+	extensionAt := strings.Index(up, `CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`)
+	typeAt := strings.Index(up, "CREATE TYPE app.status")
+	tableAt := strings.Index(up, "CREATE TABLE app.t00")
+
+	require.NotEqual(t, -1, schemaAt)
+	assert.Less(t, schemaAt, extensionAt)
+	assert.Less(t, extensionAt, typeAt)
+	assert.Less(t, typeAt, tableAt)
+	assert.Contains(t, up, "CREATE TABLE app.t29")
+	assert.Contains(t, up, "CREATE TABLE public.events")
+	assert.NotContains(t, up, "DROP ")
+
+	down := migration.DownFile.Content
+	assert.Contains(t, down, "DROP SCHEMA IF EXISTS app CASCADE;")
+	assert.Contains(t, down, "DROP SCHEMA IF EXISTS public CASCADE;\n\n"+
+		"-- Recreate the empty public schema\nCREATE SCHEMA public;")
+	assert.Contains(t, down, `DROP EXTENSION IF EXISTS "uuid-ossp" CASCADE;`)
+	assert.NotContains(t, down, "DROP TABLE")
+	assert.NotContains(t, down, "DROP TYPE")
+}
@@ -0,0 +1,154 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const fkChainSchema = `
+CREATE TABLE accounts (id BIGINT PRIMARY KEY);
+CREATE TABLE projects (id BIGINT PRIMARY KEY, account_id BIGINT REFERENCES accounts (id));
+CREATE TABLE tasks (id BIGINT PRIMARY KEY, project_id BIGINT REFERENCES projects (id));
+CREATE TABLE audit_log (id BIGINT PRIMARY KEY);
+CREATE TABLE settings (id BIGINT PRIMARY KEY);
+`
+
+// migrationOf returns the index of the first migration whose up file
+// contains fragment.
+func migrationOf(t *testing.T, migrations []generator.MigrationPair, fragment string) int {
+	t.Helper()
+
+	for i := range migrations {
+		if strings.Contains(migrations[i].UpFile.Content, fragment) {
+			return i
+		}
+	}
+
+	t.Fatalf("no migration contains %q", fragment)
+
+	return -1
+}
+
+func TestBatchingKeepsFKChainInDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	for _, limit := range []int{1, 2, 3} {
+		diff, err := differ.New(differ.DefaultOptions()).
+			Compare(parseSchemaSQL(t, ""), parseSchemaSQL(t, fkChainSchema))
+		require.NoError(t, err)
+
+		opts := testOptions()
+		opts.MaxOperationsPerFile = limit
+
+		result, err := generator.New(opts).Generate(diff)
+		require.NoError(t, err)
+
+		accounts := migrationOf(t, result.Migrations, "CREATE TABLE public.accounts ")
+		projects := migrationOf(t, result.Migrations, "CREATE TABLE public.projects ")
+		tasks := migrationOf(t, result.Migrations, "CREATE TABLE public.tasks ")
+
+		assert.LessOrEqual(t, accounts, projects, "limit %d", limit)
+		assert.LessOrEqual(t, projects, tasks, "limit %d", limit)
+	}
+}
+
+func TestBatchingMergesBatchesAcrossBackwardDependency(t *testing.T) {
+	t.Parallel()
+
+	diff, err := differ.New(differ.DefaultOptions()).
+		Compare(parseSchemaSQL(t, ""), parseSchemaSQL(t, fkChainSchema))
+	require.NoError(t, err)
+
+	// Put tasks ahead of the projects table it references, as a plan whose
+	// order does not follow its dependencies would.
+	var tasks, projects *differ.Change
+
+	for i := range diff.Changes {
+		switch diff.Changes[i].ObjectName {
+		case "public.tasks":
+			tasks = &diff.Changes[i]
+		case "public.projects":
+			projects = &diff.Changes[i]
+		}
+	}
+
+	require.NotNil(t, tasks)
+	require.NotNil(t, projects)
+	tasks.Order, projects.Order = projects.Order, tasks.Order
+
+	opts := testOptions()
+	opts.MaxOperationsPerFile = 1
+
+	result, err := generator.New(opts).Generate(diff)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		migrationOf(t, result.Migrations, "CREATE TABLE public.projects "),
+		migrationOf(t, result.Migrations, "CREATE TABLE public.tasks "),
+		"a batch does not end between a change and an object it depends on")
+
+	var oversized []diag.Warning
+
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodeOversizedMigration {
+			oversized = append(oversized, warning)
+		}
+	}
+
+	require.Len(t, oversized, 1)
+	assert.Contains(t, oversized[0].Message, "more than the 1 per file allowed")
+}
+
+func TestBatchingKeepsForeignKeyConstraintWithReferencedTable(t *testing.T) {
+	t.Parallel()
+
+	const current = `CREATE TABLE orders (id BIGINT PRIMARY KEY, customer_id BIGINT);`
+
+	desired := current + `
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+CREATE TABLE audit_log (id BIGINT PRIMARY KEY);
+ALTER TABLE orders ADD CONSTRAINT orders_customer_fk
+    FOREIGN KEY (customer_id) REFERENCES customers (id);
+`
+
+	for _, limit := range []int{1, 2} {
+		diff, err := differ.New(differ.DefaultOptions()).
+			Compare(parseSchemaSQL(t, current), parseSchemaSQL(t, desired))
+		require.NoError(t, err)
+
+		// Run the foreign key ahead of the table it references, with an
+		// unrelated table between them.
+		order := map[string]int{
+			"ADD_CONSTRAINT public.orders": 0,
+			"ADD_TABLE public.audit_log":   1,
+			"ADD_TABLE public.customers":   2,
+		}
+
+		require.Len(t, diff.Changes, len(order))
+
+		for i := range diff.Changes {
+			position, ok := order[string(diff.Changes[i].Type)+" "+diff.Changes[i].ObjectName]
+			require.True(t, ok, diff.Changes[i].Description)
+
+			diff.Changes[i].Order = position
+		}
+
+		opts := testOptions()
+		opts.MaxOperationsPerFile = limit
+
+		result, err := generator.New(opts).Generate(diff)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			migrationOf(t, result.Migrations, "CREATE TABLE public.customers "),
+			migrationOf(t, result.Migrations, "ADD CONSTRAINT orders_customer_fk"),
+			"limit %d: the foreign key is not split from the table it references", limit)
+	}
+}
@@ -0,0 +1,102 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDDLBuilder_CACompression(t *testing.T) {
+	t.Parallel()
+
+	const (
+		enable  = "ALTER MATERIALIZED VIEW public.metrics_hourly SET (timescaledb.compress = true);"
+		disable = "ALTER MATERIALIZED VIEW public.metrics_hourly SET (timescaledb.compress = false);"
+		remove  = "SELECT remove_compression_policy('public.metrics_hourly', if_exists => true);"
+		add     = "SELECT add_compression_policy('public.metrics_hourly', INTERVAL "
+	)
+
+	weekly := &schema.CompressionPolicy{CompressAfter: "7 days"}
+
+	tests := []struct {
+		name         string
+		currentOn    bool
+		current      *schema.CompressionPolicy
+		desiredOn    bool
+		desired      *schema.CompressionPolicy
+		wantSeverity differ.ChangeSeverity
+		wantUp       string
+		wantDown     string
+	}{
+		{
+			name:         "compression enabled",
+			desiredOn:    true,
+			desired:      weekly,
+			wantSeverity: differ.SeveritySafe,
+			wantUp:       enable + "\n\n" + add + "'7 days');",
+			wantDown:     remove + "\n\n" + disable,
+		},
+		{
+			name:         "compression disabled",
+			currentOn:    true,
+			current:      weekly,
+			wantSeverity: differ.SeverityPotentiallyBreaking,
+			wantUp:       remove + "\n\n" + disable,
+			wantDown:     enable + "\n\n" + add + "'7 days');",
+		},
+		{
+			name:         "policy changed",
+			currentOn:    true,
+			current:      weekly,
+			desiredOn:    true,
+			desired:      &schema.CompressionPolicy{CompressAfter: "30 days"},
+			wantSeverity: differ.SeveritySafe,
+			wantUp:       remove + "\n\n" + add + "'30 days');",
+			wantDown:     remove + "\n\n" + add + "'7 days');",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			database := func(enabled bool, policy *schema.CompressionPolicy) *schema.Database {
+				return &schema.Database{
+					ContinuousAggregates: []schema.ContinuousAggregate{{
+						Schema:             schema.DefaultSchema,
+						ViewName:           "metrics_hourly",
+						HypertableSchema:   schema.DefaultSchema,
+						HypertableName:     "metrics",
+						Query:              "SELECT time_bucket('1 hour', time) FROM metrics",
+						CompressionEnabled: enabled,
+						CompressionPolicy:  policy,
+					}},
+				}
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				database(tt.currentOn, tt.current), database(tt.desiredOn, tt.desired))
+			require.NoError(t, err)
+			require.Len(t, result.Changes, 1, "the aggregate is not recreated")
+
+			change := result.Changes[0]
+			require.Equal(t, differ.ChangeTypeModifyCACompression, change.Type)
+			assert.Equal(t, tt.wantSeverity, change.Severity)
+
+			builder := generator.NewDDLBuilder(result, true)
+
+			upStmt, err := builder.BuildUpStatement(change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, upStmt.SQL)
+
+			downStmt, err := builder.BuildDownStatement(change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDown, downStmt.SQL)
+		})
+	}
+}
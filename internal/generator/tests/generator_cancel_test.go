@@ -0,0 +1,84 @@
+package generator_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func cancelTestDiff(t *testing.T) *differ.DiffResult {
+	t.Helper()
+
+	desired := &schema.Database{}
+	for i := range 3 {
+		desired.Schemas = append(desired.Schemas, schema.Schema{Name: fmt.Sprintf("app%d", i)})
+		desired.Tables = append(desired.Tables, schema.Table{
+			Schema:  fmt.Sprintf("app%d", i),
+			Name:    "events",
+			Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+		})
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestGenerator_GenerateContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cancelStage string
+		cancelAt    int
+		wantErr     string
+	}{
+		{
+			name:        "while generating migrations",
+			cancelStage: "generating migrations",
+			cancelAt:    1,
+			wantErr:     "generate cancelled after 1 of",
+		},
+		{
+			name:        "while writing files",
+			cancelStage: "writing files",
+			cancelAt:    3,
+			wantErr:     "generate cancelled while writing files after 3 of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			opts := generator.DefaultOptions()
+			opts.OutputDir = t.TempDir()
+			opts.Progress = func(stage string, done, _ int) {
+				if stage == tt.cancelStage && done == tt.cancelAt {
+					cancel()
+				}
+			}
+
+			result, err := generator.New(opts).GenerateContext(ctx, cancelTestDiff(t))
+			require.ErrorIs(t, err, context.Canceled)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Nil(t, result)
+
+			entries, err := os.ReadDir(opts.OutputDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "cancelled run must not leave files behind")
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package generator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestRenderCanonical(t *testing.T) {
+	t.Parallel()
+
+	db := parseSchemaSQL(t, `
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    email TEXT NOT NULL
+);
+COMMENT ON COLUMN users.email IS 'login address';
+CREATE INDEX idx_users_email ON users (email);
+CREATE VIEW user_emails AS SELECT email FROM users;
+`)
+
+	objects, err := generator.RenderCanonical(context.Background(), db)
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key())
+	}
+
+	assert.Equal(t, []string{
+		"index public.idx_users_email",
+		"table public.users",
+		"view public.user_emails",
+	}, keys)
+
+	assert.Equal(t, "CREATE INDEX idx_users_email ON public.users (email);", objects[0].SQL)
+	assert.Equal(t, `CREATE TABLE public.users (
+    id BIGINT NOT NULL,
+    email TEXT NOT NULL,
+    CONSTRAINT users_pkey PRIMARY KEY (id)
+);
+
+COMMENT ON COLUMN public.users.email IS 'login address';`, objects[1].SQL)
+}
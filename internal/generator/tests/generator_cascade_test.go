@@ -0,0 +1,171 @@
+package generator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// unmanagedDependentPlans are plans that drop an object a dependent of the
+// current schema uses, without handling the dependent.
+func unmanagedDependentPlans(t *testing.T) map[string]*differ.DiffResult {
+	t.Helper()
+
+	daily := schema.View{
+		Schema:     schema.DefaultSchema,
+		Name:       "daily",
+		Definition: "SELECT 1 AS id",
+	}
+	total := schema.Function{
+		Schema:     schema.DefaultSchema,
+		Name:       "order_total",
+		ReturnType: "integer",
+		Language:   "sql",
+		Body:       "SELECT 1",
+	}
+
+	return map[string]*differ.DiffResult{
+		"view": {
+			Current: &schema.Database{Views: []schema.View{daily, {
+				Schema:     schema.DefaultSchema,
+				Name:       "weekly",
+				Definition: "SELECT id FROM daily",
+			}}},
+			Desired: &schema.Database{},
+			Changes: []differ.Change{{
+				Type:       differ.ChangeTypeDropView,
+				Severity:   differ.SeverityBreaking,
+				ObjectType: "view",
+				ObjectName: "public.daily",
+				Details:    map[string]any{"view": &daily},
+			}},
+		},
+		"function": {
+			Current: &schema.Database{
+				Functions: []schema.Function{total},
+				Views: []schema.View{{
+					Schema:     schema.DefaultSchema,
+					Name:       "totals",
+					Definition: "SELECT order_total() AS total",
+				}},
+			},
+			Desired: &schema.Database{},
+			Changes: []differ.Change{{
+				Type:       differ.ChangeTypeDropFunction,
+				Severity:   differ.SeverityBreaking,
+				ObjectType: "function",
+				ObjectName: "public.order_total()",
+				Details:    map[string]any{"function": &total},
+			}},
+		},
+	}
+}
+
+func TestGenerator_RejectsUnmanagedDropDependents(t *testing.T) {
+	t.Parallel()
+
+	want := map[string]string{
+		"view":     "view public.weekly selects from view public.daily",
+		"function": "view public.totals calls function public.order_total()",
+	}
+
+	for name, plan := range unmanagedDependentPlans(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := generator.New(testOptions()).Generate(plan)
+			require.Error(t, err)
+
+			var dependentsErr *generator.DropDependentsError
+			require.True(t, errors.As(err, &dependentsErr))
+			assert.Contains(t, err.Error(), "1 dependent of dropped objects not handled")
+			assert.Contains(t, err.Error(), want[name])
+
+			diagnostics := dependentsErr.Diagnostics()
+			require.Len(t, diagnostics, 1)
+			assert.Equal(t, diag.CodeDropBlockedByDependent, diagnostics[0].Code)
+			assert.Equal(t, diag.SeverityError, diagnostics[0].Severity)
+			assert.Equal(t, want[name], diagnostics[0].Message)
+			assert.Equal(t, plan.Changes[0].ObjectName, diagnostics[0].ObjectName)
+		})
+	}
+}
+
+func TestGenerator_CascadeDropsAllowlist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		plan      string
+		allowlist []string
+		want      string
+	}{
+		{
+			plan:      "view",
+			allowlist: []string{"view"},
+			want:      "DROP VIEW IF EXISTS public.daily CASCADE;",
+		},
+		{
+			plan:      "view",
+			allowlist: []string{"public.dai*"},
+			want:      "DROP VIEW IF EXISTS public.daily CASCADE;",
+		},
+		{
+			plan:      "function",
+			allowlist: []string{"public.order_total"},
+			want:      "DROP FUNCTION IF EXISTS public.order_total() CASCADE;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plan+" "+tt.allowlist[0], func(t *testing.T) {
+			t.Parallel()
+
+			opts := testOptions()
+			opts.CascadeDrops = tt.allowlist
+
+			result, err := generator.New(opts).Generate(unmanagedDependentPlans(t)[tt.plan])
+			require.NoError(t, err)
+			require.Len(t, result.Migrations, 1)
+			assert.Contains(t, result.Migrations[0].UpFile.Content, tt.want)
+		})
+	}
+}
+
+func TestGenerator_DropsWithoutCascadeByDefault(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `CREATE TABLE orders (id BIGINT PRIMARY KEY);
+CREATE VIEW order_ids AS SELECT id FROM orders;
+CREATE FUNCTION order_count() RETURNS bigint LANGUAGE sql AS $$ SELECT count(*) FROM orders $$;`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, &schema.Database{})
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	for _, migration := range result.Migrations {
+		assert.NotContains(t, migration.UpFile.Content, "CASCADE")
+	}
+
+	assert.Contains(t, result.Migrations[0].UpFile.Content,
+		"DROP VIEW IF EXISTS public.order_ids;")
+}
+
+func TestOptions_ValidateCascadeDrops(t *testing.T) {
+	t.Parallel()
+
+	opts := generator.DefaultOptions()
+	opts.CascadeDrops = []string{"materialized_view", "reporting.*"}
+	require.NoError(t, opts.Validate())
+
+	opts.CascadeDrops = []string{"reporting.[a-"}
+	assert.ErrorContains(t, opts.Validate(), `invalid cascade drop pattern "reporting.[a-"`)
+}
@@ -0,0 +1,50 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestGenerator_RenamesColumn(t *testing.T) {
+	t.Parallel()
+
+	opts := differ.DefaultOptions()
+	opts.DetectColumnRenames = true
+
+	diff, err := differ.New(opts).Compare(
+		parseSchemaSQL(t, `CREATE TABLE payments (
+    id BIGINT PRIMARY KEY,
+    provider TEXT NOT NULL DEFAULT 'manual'
+);`),
+		parseSchemaSQL(t, `CREATE TABLE payments (
+    id BIGINT PRIMARY KEY,
+    source TEXT NOT NULL DEFAULT 'manual'
+);
+CREATE INDEX payments_source_idx ON payments (source);`),
+	)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	down := result.Migrations[0].DownFile.Content
+
+	assertOrdered(t, up,
+		"ALTER TABLE public.payments RENAME COLUMN provider TO source;",
+		"CREATE INDEX payments_source_idx ON public.payments",
+	)
+	assert.NotContains(t, up, "DROP COLUMN")
+	assert.NotContains(t, up, "ADD COLUMN")
+
+	assertOrdered(t, down,
+		"DROP INDEX IF EXISTS public.payments_source_idx;",
+		"ALTER TABLE public.payments RENAME COLUMN source TO provider;",
+	)
+}
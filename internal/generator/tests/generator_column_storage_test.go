@@ -0,0 +1,105 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestGenerator_ColumnStorageInCreateTable(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSchemaSQL(t, `
+CREATE TABLE events (
+    id BIGINT NOT NULL,
+    payload JSONB COMPRESSION lz4,
+    body TEXT
+);
+ALTER TABLE events ALTER COLUMN body SET STORAGE EXTERNAL;
+ALTER TABLE events ALTER COLUMN id SET STORAGE PLAIN;
+`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "    payload JSONB COMPRESSION lz4,\n")
+	assert.Contains(t, up, ");\nALTER TABLE public.events ALTER COLUMN body SET STORAGE EXTERNAL;")
+	assert.NotContains(t, up, "COLUMN id SET STORAGE", "plain is the default of bigint")
+}
+
+func TestGenerator_ColumnStorageAndCompressionChanges(t *testing.T) {
+	t.Parallel()
+
+	hypertable := `
+SELECT create_hypertable('events', 'ts');
+ALTER TABLE events SET (timescaledb.compress, timescaledb.compress_segmentby = 'id');
+`
+	current := parseSchemaSQL(t, `
+CREATE TABLE events (id BIGINT NOT NULL, ts TIMESTAMPTZ NOT NULL, payload BYTEA);
+`+hypertable)
+	desired := parseSchemaSQL(t, `
+CREATE TABLE events (
+    id BIGINT NOT NULL,
+    ts TIMESTAMPTZ NOT NULL,
+    payload BYTEA COMPRESSION lz4
+);
+ALTER TABLE events ALTER COLUMN payload SET STORAGE EXTERNAL;
+`+hypertable)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	builder := generator.NewDDLBuilder(diff, true)
+
+	tests := []struct {
+		changeType differ.ChangeType
+		wantUp     string
+		wantDown   string
+	}{
+		{
+			changeType: differ.ChangeTypeModifyColumnStorage,
+			wantUp:     "ALTER TABLE public.events ALTER COLUMN payload SET STORAGE EXTERNAL;",
+			wantDown:   "ALTER TABLE public.events ALTER COLUMN payload SET STORAGE EXTENDED;",
+		},
+		{
+			changeType: differ.ChangeTypeModifyColumnCompression,
+			wantUp:     "ALTER TABLE public.events ALTER COLUMN payload SET COMPRESSION lz4;",
+			wantDown:   "ALTER TABLE public.events ALTER COLUMN payload SET COMPRESSION DEFAULT;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.changeType), func(t *testing.T) {
+			t.Parallel()
+
+			idx := -1
+
+			for i := range diff.Changes {
+				if diff.Changes[i].Type == tt.changeType {
+					idx = i
+				}
+			}
+
+			require.NotEqual(t, -1, idx)
+
+			up, err := builder.BuildUpStatement(diff.Changes[idx])
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, up.SQL, "no decompression is needed")
+			assert.False(t, up.IsUnsafe)
+
+			down, err := builder.BuildDownStatement(diff.Changes[idx])
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDown, down.SQL)
+		})
+	}
+}
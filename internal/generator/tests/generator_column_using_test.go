@@ -0,0 +1,81 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_ColumnTypeUsing(t *testing.T) {
+	t.Parallel()
+
+	const alter = "ALTER TABLE public.events ALTER COLUMN "
+
+	tests := []struct {
+		name        string
+		current     string
+		desired     string
+		wantUp      string
+		wantDown    string
+		wantUpTODO  bool
+		wantNoUsing bool
+	}{
+		{
+			name:     "cast from the lookup table",
+			current:  `CREATE TABLE events (id TEXT, created_at BIGINT);`,
+			desired:  `CREATE TABLE events (id UUID, created_at BIGINT);`,
+			wantUp:   alter + "id TYPE UUID USING id::UUID;",
+			wantDown: alter + "id TYPE TEXT;",
+		},
+		{
+			name:    "expression from a pgtofu:using comment",
+			current: `CREATE TABLE events (id TEXT, created_at TIMESTAMPTZ);`,
+			desired: `CREATE TABLE events (
+    id TEXT,
+    created_at BIGINT -- pgtofu:using (extract(epoch from created_at))::bigint
+);`,
+			wantUp: alter +
+				"created_at TYPE BIGINT USING (extract(epoch from created_at))::bigint;",
+			wantDown: "-- TODO: BIGINT does not convert to TIMESTAMPTZ without a USING clause.",
+		},
+		{
+			name:        "implicit conversion",
+			current:     `CREATE TABLE events (id TEXT, created_at TIMESTAMPTZ);`,
+			desired:     `CREATE TABLE events (id TEXT, created_at DATE);`,
+			wantUp:      alter + "created_at TYPE DATE;",
+			wantDown:    alter + "created_at TYPE TIMESTAMPTZ USING created_at::TIMESTAMPTZ;",
+			wantNoUsing: true,
+		},
+		{
+			name:       "no conversion",
+			current:    `CREATE TABLE events (id TEXT, created_at INTEGER);`,
+			desired:    `CREATE TABLE events (id TEXT, created_at BOOLEAN);`,
+			wantUp:     alter + "created_at TYPE BOOLEAN;",
+			wantDown:   alter + "created_at TYPE INTEGER;",
+			wantUpTODO: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			up, down := generateViewTriggerFiles(t, tt.current, tt.desired)
+
+			assert.Contains(t, up, tt.wantUp)
+			assert.Contains(t, down, tt.wantDown)
+
+			if tt.wantUpTODO {
+				assert.Contains(t, up, "-- TODO: INTEGER does not convert to BOOLEAN without "+
+					"a USING clause. Add one, or declare it on the column with "+
+					"-- pgtofu:using <expression>\n"+tt.wantUp)
+			} else {
+				assert.NotContains(t, up, "-- TODO")
+			}
+
+			if tt.wantNoUsing {
+				assert.NotContains(t, up, "USING")
+			}
+		})
+	}
+}
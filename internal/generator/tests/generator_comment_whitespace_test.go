@@ -0,0 +1,39 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_EditedCommentKeepsSourceLines(t *testing.T) {
+	t.Parallel()
+
+	const table = "CREATE TABLE orders (id BIGINT);\n"
+
+	up, _ := generateViewTriggerFiles(t,
+		table+"COMMENT ON TABLE orders IS 'Orders placed by customers.  One row per checkout.';",
+		table+"COMMENT ON TABLE orders IS 'Orders placed by customers.\n"+
+			"One row per checkout attempt.\n"+
+			"Status tracks fulfilment.';",
+	)
+
+	assert.Contains(t, up, "COMMENT ON TABLE public.orders IS\n'Orders placed by customers.'\n"+
+		"'One row per checkout attempt.'\n"+
+		"'Status tracks fulfilment.';")
+}
+
+func TestGenerator_RewrappedCommentIsUnchanged(t *testing.T) {
+	t.Parallel()
+
+	const table = "CREATE TABLE orders (id BIGINT);\n"
+
+	up, _ := generateViewTriggerFiles(t,
+		table+"COMMENT ON TABLE orders IS 'Orders placed by customers. One row per checkout.';",
+		table+"COMMENT ON TABLE orders IS 'Orders placed by\n"+
+			"    customers.  One row per checkout.';\n"+
+			"CREATE INDEX orders_id_idx ON orders (id);",
+	)
+
+	assert.NotContains(t, up, "COMMENT ON TABLE")
+}
@@ -0,0 +1,140 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func ordersTable(columns ...schema.Column) schema.Table {
+	return schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "orders",
+		Columns: append([]schema.Column{
+			{Name: "id", DataType: "bigint", Position: 1},
+			{Name: "customer_id", DataType: "bigint", Position: 2},
+		}, columns...),
+	}
+}
+
+func concurrentIndexOptions() *generator.Options {
+	opts := testOptions()
+	opts.ConcurrentIndexes = true
+
+	return opts
+}
+
+func TestGenerator_ConcurrentIndexesGetMigrationsOfTheirOwn(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{Tables: []schema.Table{ordersTable()}}
+
+	table := ordersTable(schema.Column{Name: "status", DataType: "text", Position: 3})
+	table.Indexes = []schema.Index{
+		{
+			Schema:    schema.DefaultSchema,
+			Name:      "orders_customer_id_idx",
+			TableName: "orders",
+			Columns:   []string{"customer_id"},
+		},
+		{
+			Schema:    schema.DefaultSchema,
+			Name:      "orders_status_idx",
+			TableName: "orders",
+			Columns:   []string{"status"},
+		},
+	}
+	desired := &schema.Database{Tables: []schema.Table{table}}
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(concurrentIndexOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 3)
+
+	column := result.Migrations[0]
+	assert.Contains(t, column.UpFile.Content, "BEGIN;")
+	assert.Contains(t, column.UpFile.Content, "ADD COLUMN")
+	assert.NotContains(t, column.UpFile.Content, "CREATE INDEX")
+
+	for i, name := range []string{"orders_customer_id_idx", "orders_status_idx"} {
+		index := result.Migrations[i+1]
+
+		assert.Contains(t, index.UpFile.Content,
+			"CREATE INDEX CONCURRENTLY IF NOT EXISTS "+name+" ON public.orders")
+		assert.NotContains(t, index.UpFile.Content, "BEGIN;")
+		assert.Contains(t, index.DownFile.Content,
+			"DROP INDEX CONCURRENTLY IF EXISTS public."+name+";")
+		assert.NotContains(t, index.DownFile.Content, "BEGIN;")
+	}
+}
+
+func TestGenerator_ConcurrentIndexDrop(t *testing.T) {
+	t.Parallel()
+
+	table := ordersTable()
+	table.Indexes = []schema.Index{{
+		Schema:    schema.DefaultSchema,
+		Name:      "orders_customer_id_idx",
+		TableName: "orders",
+		Columns:   []string{"customer_id"},
+	}}
+
+	current := &schema.Database{Tables: []schema.Table{table}}
+	desired := &schema.Database{Tables: []schema.Table{ordersTable()}}
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(concurrentIndexOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	migration := result.Migrations[0]
+	assert.Contains(t, migration.UpFile.Content,
+		"DROP INDEX CONCURRENTLY IF EXISTS public.orders_customer_id_idx;")
+	assert.NotContains(t, migration.UpFile.Content, "BEGIN;")
+	assert.Contains(t, migration.DownFile.Content,
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS orders_customer_id_idx ON public.orders")
+}
+
+func TestGenerator_ConcurrentIndexesSkipNewTables(t *testing.T) {
+	t.Parallel()
+
+	table := ordersTable()
+	table.Indexes = []schema.Index{{
+		Schema:    schema.DefaultSchema,
+		Name:      "orders_customer_id_idx",
+		TableName: "orders",
+		Columns:   []string{"customer_id"},
+	}}
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{}, &schema.Database{Tables: []schema.Table{table}})
+	require.NoError(t, err)
+
+	result, err := generator.New(concurrentIndexOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	assert.NotContains(t, result.Migrations[0].UpFile.Content, "CONCURRENTLY")
+	assert.Contains(t, result.Migrations[0].UpFile.Content, "BEGIN;")
+}
+
+func TestOptions_ConcurrentIndexesNeedTransactionsOff(t *testing.T) {
+	t.Parallel()
+
+	opts := concurrentIndexOptions()
+	opts.TransactionMode = generator.TransactionModeAlways
+
+	err := opts.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"concurrent indexes cannot be used with transaction mode always")
+}
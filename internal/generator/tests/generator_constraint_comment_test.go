@@ -0,0 +1,110 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const commentedConstraintSchema = `
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+
+CREATE TABLE orders (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    total NUMERIC NOT NULL,
+    CONSTRAINT orders_total_check CHECK (total >= 0),
+    CONSTRAINT orders_customer_fk FOREIGN KEY (customer_id) REFERENCES customers (id)
+);
+
+COMMENT ON CONSTRAINT orders_total_check ON orders IS 'Totals can''t be negative';
+COMMENT ON CONSTRAINT orders_customer_fk ON orders IS 'Every order has a customer';
+`
+
+const (
+	totalCheckComment = "COMMENT ON CONSTRAINT orders_total_check ON public.orders IS " +
+		"'Totals can''t be negative';"
+	customerFKComment = "COMMENT ON CONSTRAINT orders_customer_fk ON public.orders IS " +
+		"'Every order has a customer';"
+)
+
+func generateConstraintCommentFiles(
+	t *testing.T,
+	current, desired *schema.Database,
+) (up, down string) {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	for _, migration := range result.Migrations {
+		up += migration.UpFile.Content
+		down += migration.DownFile.Content
+	}
+
+	return up, down
+}
+
+func TestGenerator_DropTableRestoresConstraintComments(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, commentedConstraintSchema)
+	desired := parseSchemaSQL(t, `CREATE TABLE customers (id BIGINT PRIMARY KEY);`)
+
+	up, down := generateConstraintCommentFiles(t, current, desired)
+
+	assert.Contains(t, up, "DROP TABLE IF EXISTS public.orders;")
+	assert.NotContains(t, up, "COMMENT ON CONSTRAINT")
+
+	assert.Contains(t, down, totalCheckComment)
+	assert.Contains(t, down, customerFKComment)
+	assert.Less(t, strings.Index(down, "CREATE TABLE public.orders"),
+		strings.Index(down, "COMMENT ON CONSTRAINT"))
+}
+
+func TestGenerator_AddTableSetsConstraintComments(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `CREATE TABLE customers (id BIGINT PRIMARY KEY);`)
+	desired := parseSchemaSQL(t, commentedConstraintSchema)
+
+	up, down := generateConstraintCommentFiles(t, current, desired)
+
+	assert.Contains(t, up, totalCheckComment)
+	assert.Contains(t, up, customerFKComment)
+	assert.Less(t, strings.Index(up, "CREATE TABLE IF NOT EXISTS public.orders"),
+		strings.Index(up, "COMMENT ON CONSTRAINT"))
+
+	assert.Contains(t, down, "DROP TABLE IF EXISTS public.orders;")
+	assert.NotContains(t, down, "COMMENT ON CONSTRAINT",
+		"comment reverts are skipped when the table is dropped")
+}
+
+func TestGenerator_ModifyConstraintComment(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, commentedConstraintSchema)
+	desired := parseSchemaSQL(t, commentedConstraintSchema+`
+COMMENT ON CONSTRAINT orders_total_check ON orders IS 'Refunds are separate orders';
+COMMENT ON CONSTRAINT orders_customer_fk ON orders IS NULL;
+`)
+
+	up, down := generateConstraintCommentFiles(t, current, desired)
+
+	assert.Contains(t, up, "COMMENT ON CONSTRAINT orders_total_check ON public.orders IS "+
+		"'Refunds are separate orders';")
+	assert.Contains(t, up, "COMMENT ON CONSTRAINT orders_customer_fk ON public.orders IS NULL;")
+	assert.NotContains(t, up, "DROP CONSTRAINT")
+
+	assert.Contains(t, down, totalCheckComment)
+	assert.Contains(t, down, customerFKComment)
+}
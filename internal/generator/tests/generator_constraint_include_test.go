@@ -0,0 +1,62 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const includeTableColumns = `
+    id BIGINT NOT NULL,
+    key TEXT NOT NULL,
+    payload JSONB,
+    updated_at TIMESTAMPTZ`
+
+func TestGenerator_CreateTableKeyConstraintInclude(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t, "", `CREATE TABLE lookups (`+includeTableColumns+`,
+    CONSTRAINT lookups_pkey PRIMARY KEY (id) INCLUDE (payload),
+    CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload, updated_at) DEFERRABLE
+);`)
+
+	assert.Contains(t, up, "CONSTRAINT lookups_pkey PRIMARY KEY (id) INCLUDE (payload)")
+	assert.Contains(t, up,
+		"CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload, updated_at) DEFERRABLE")
+	assert.NotContains(t, up, "CREATE UNIQUE INDEX")
+	assert.Contains(t, down, "DROP TABLE")
+}
+
+func TestGenerator_ModifyConstraintInclude(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		`CREATE TABLE lookups (`+includeTableColumns+`,
+    CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload)
+);`,
+		`CREATE TABLE lookups (`+includeTableColumns+`,
+    CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload, updated_at)
+);`)
+
+	assertOrdered(t, up,
+		"ALTER TABLE public.lookups DROP CONSTRAINT IF EXISTS lookups_key;",
+		"ALTER TABLE public.lookups ADD CONSTRAINT lookups_key UNIQUE (key) "+
+			"INCLUDE (payload, updated_at);")
+	assertOrdered(t, down,
+		"ALTER TABLE public.lookups DROP CONSTRAINT IF EXISTS lookups_key;",
+		"ALTER TABLE public.lookups ADD CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload);")
+}
+
+func TestGenerator_AddConstraintInclude(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		`CREATE TABLE lookups (`+includeTableColumns+`);`,
+		`CREATE TABLE lookups (`+includeTableColumns+`);
+ALTER TABLE lookups ADD CONSTRAINT lookups_pkey PRIMARY KEY (id) INCLUDE (key, payload);`)
+
+	assert.Contains(t, up,
+		"ALTER TABLE public.lookups ADD CONSTRAINT lookups_pkey PRIMARY KEY (id) "+
+			"INCLUDE (key, payload);")
+	assert.Contains(t, down, "ALTER TABLE public.lookups DROP CONSTRAINT IF EXISTS lookups_pkey;")
+}
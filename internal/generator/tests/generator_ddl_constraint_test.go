@@ -337,6 +337,32 @@ func TestDDLBuilder_ConstraintOperations(t *testing.T) { //nolint:maintidx
 			wantUnsafe:     false,
 			wantRequiresTx: true,
 		},
+		{
+			name:       "add EXCLUDE constraint from its parts",
+			changeType: differ.ChangeTypeAddConstraint,
+			table: &schema.Table{
+				Schema: schema.DefaultSchema,
+				Name:   "reservations",
+			},
+			constraint: &schema.Constraint{
+				Name:          "reservations_no_overlap",
+				Type:          "EXCLUDE",
+				ExcludeMethod: "gist",
+				ExcludeElements: []schema.ExcludeElement{
+					{Element: "Room", Operator: "="},
+					{Element: "tstzrange(valid_from, valid_until)", Operator: "&&"},
+				},
+				ExcludeWhere: "active",
+				IsDeferrable: true,
+			},
+			wantSQL: []string{
+				"ADD CONSTRAINT reservations_no_overlap EXCLUDE USING gist " +
+					`("Room" WITH =, tstzrange(valid_from, valid_until) WITH &&) ` +
+					"WHERE (active) DEFERRABLE",
+			},
+			wantUnsafe:     false,
+			wantRequiresTx: true,
+		},
 		{
 			name:       "add deferrable constraint",
 			changeType: differ.ChangeTypeAddConstraint,
@@ -119,7 +119,7 @@ func TestDDLBuilder_ExtensionOperations(t *testing.T) {
 			extension: &schema.Extension{
 				Name: "old_extension",
 			},
-			wantSQL:        []string{"DROP EXTENSION", "IF EXISTS", "old_extension", "CASCADE"},
+			wantSQL:        []string{"DROP EXTENSION", "IF EXISTS", "old_extension"},
 			wantUnsafe:     true,
 			wantRequiresTx: false,
 		},
@@ -1,6 +1,7 @@
 package generator_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -36,7 +37,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 			},
 			wantSQL: []string{
 				"CREATE OR REPLACE FUNCTION",
-				"public.UPDATE_TIMESTAMP",
+				"public.update_timestamp",
 				"RETURNS TRIGGER",
 				"LANGUAGE plpgsql",
 			},
@@ -57,7 +58,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 			},
 			wantSQL: []string{
 				"CREATE OR REPLACE FUNCTION",
-				"public.CALCULATE_TOTAL",
+				"public.calculate_total",
 				"price",
 				"tax",
 				"RETURNS NUMERIC",
@@ -142,7 +143,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 				Language:      "plpgsql",
 				Body:          "$$ BEGIN SELECT * INTO user_info FROM users WHERE id = user_id; END; $$",
 			},
-			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "public.GET_USER_INFO"},
+			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "public.get_user_info"},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
 		},
@@ -158,7 +159,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 				Comment:       "This function does something useful",
 				Body:          "$$ BEGIN NULL; END; $$",
 			},
-			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "public.COMMENTED_FUNCTION"},
+			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "public.commented_function"},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
 		},
@@ -177,7 +178,6 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 				"DROP FUNCTION",
 				"IF EXISTS",
 				"public.old_function",
-				"CASCADE",
 			},
 			wantUnsafe:     true,
 			wantRequiresTx: true,
@@ -213,7 +213,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 				Language:      "plpgsql",
 				Body:          "$$ BEGIN PERFORM new_logic(); END; $$",
 			},
-			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "public.UPDATED_FUNCTION"},
+			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "public.updated_function"},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
 		},
@@ -229,7 +229,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 				Comment:       "Updated comment",
 				Body:          "$$ BEGIN NULL; END; $$",
 			},
-			wantSQL:        []string{"COMMENT ON FUNCTION", "public.FUNCTION_WITH_COMMENT"},
+			wantSQL:        []string{"COMMENT ON FUNCTION", "public.function_with_comment"},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
 		},
@@ -244,7 +244,7 @@ func TestDDLBuilder_FunctionOperations(t *testing.T) { //nolint:maintidx
 				Language:      "plpgsql",
 				Body:          "$$ BEGIN NULL; END; $$",
 			},
-			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "app.SCHEMA_FUNCTION"},
+			wantSQL:        []string{"CREATE OR REPLACE FUNCTION", "app.schema_function"},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
 		},
@@ -336,7 +336,7 @@ func TestDDLBuilder_FunctionModifyWithCommentChange(t *testing.T) {
 	stmt, err := builder.BuildUpStatement(result.Changes[0])
 
 	require.NoError(t, err)
-	assert.Contains(t, stmt.SQL, "COMMENT ON FUNCTION public.TEST_FUNCTION")
+	assert.Contains(t, stmt.SQL, "COMMENT ON FUNCTION public.test_function")
 	assert.Contains(t, stmt.SQL, "New comment")
 	assert.NotContains(t, stmt.SQL, "CREATE OR REPLACE")
 }
@@ -384,7 +384,7 @@ func TestDDLBuilder_FunctionModifyCommentWithArguments(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Contains(t, stmt.SQL,
-		"COMMENT ON FUNCTION app.FORMAT_LABEL(kind TEXT, attributes JSONB, fallback TEXT) IS")
+		"COMMENT ON FUNCTION app.format_label(kind TEXT, attributes JSONB, fallback TEXT) IS")
 	assert.NotContains(t, stmt.SQL, "FORMAT_LABELkind")
 }
 
@@ -444,7 +444,7 @@ func TestDDLBuilder_FunctionDefinitionFormatsDataTypesUppercase(t *testing.T) {
 	commentStmt, err := builder.BuildUpStatement(result.Changes[1])
 	require.NoError(t, err)
 
-	signature := "app.FORMAT_ITEM_LABEL(category TEXT, attributes JSONB, " +
+	signature := "app.format_item_label(category TEXT, attributes JSONB, " +
 		"item_type TEXT, display_name TEXT, fallback_name TEXT)"
 
 	assert.Contains(t, addStmt.SQL, signature)
@@ -788,3 +788,124 @@ func TestDownSkipsFunctionCommentWhenDroppingFunction(t *testing.T) {
 		t.Fatalf("down migration should drop the function; got:\n%s", out)
 	}
 }
+
+func TestGenerator_MovesFunctionWithoutRecreatingTriggers(t *testing.T) {
+	t.Parallel()
+
+	const function = `CREATE TABLE orders (id BIGINT PRIMARY KEY, total NUMERIC);
+CREATE FUNCTION %[1]s.validate_order() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    IF NEW.total < 0 THEN RAISE EXCEPTION 'negative total'; END IF;
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER orders_validate BEFORE INSERT ON orders
+    FOR EACH ROW EXECUTE FUNCTION %[1]s.validate_order();`
+
+	current := parseSchemaSQL(t, fmt.Sprintf(function, "public"))
+	desired := parseSchemaSQL(t, "CREATE SCHEMA app;\n"+fmt.Sprintf(function, "app"))
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	var up, down string
+	for _, migration := range result.Migrations {
+		up += migration.UpFile.Content
+		down = migration.DownFile.Content + down
+	}
+
+	assert.Contains(t, up, "ALTER FUNCTION public.validate_order() SET SCHEMA app;")
+	assert.NotContains(t, up, "DROP FUNCTION")
+	assert.NotContains(t, up, "DROP TRIGGER")
+	assert.Less(t, strings.Index(up, "CREATE SCHEMA"), strings.Index(up, "ALTER FUNCTION"))
+
+	assert.Contains(t, down, "ALTER FUNCTION app.validate_order() SET SCHEMA public;")
+	assert.NotContains(t, down, "DROP TRIGGER")
+}
+
+func TestDDLBuilder_FunctionRename(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Function{
+		Schema:        "app",
+		Name:          "validate_order",
+		ArgumentTypes: []string{"bigint"},
+		ReturnType:    "boolean",
+		Language:      "sql",
+		Body:          "SELECT $1 > 0",
+		Comment:       "Checks an order",
+	}
+	desired := *current
+	desired.Name = "check_order"
+	desired.Comment = "Checks an order total"
+
+	change := differ.Change{
+		Type:       differ.ChangeTypeModifyFunction,
+		ObjectName: differ.FunctionKey(desired.Schema, desired.Name, desired.ArgumentTypes),
+		Details: map[string]any{
+			"current":         current,
+			"desired":         &desired,
+			"relocated":       true,
+			"comment_changed": true,
+		},
+	}
+
+	builder := generator.NewDDLBuilder(&differ.DiffResult{
+		Current: &schema.Database{Functions: []schema.Function{*current}},
+		Desired: &schema.Database{Functions: []schema.Function{desired}},
+	}, true)
+
+	up, err := builder.BuildUpStatement(change)
+	require.NoError(t, err)
+	assert.Contains(t, up.SQL, "ALTER FUNCTION app.validate_order(BIGINT) RENAME TO check_order;")
+	assert.Contains(t, up.SQL, "'Checks an order total';")
+	assert.NotContains(t, up.SQL, "SET SCHEMA")
+
+	down, err := builder.BuildDownStatement(change)
+	require.NoError(t, err)
+	assert.Contains(t, down.SQL, "ALTER FUNCTION app.check_order(BIGINT) RENAME TO validate_order;")
+	assert.Contains(t, down.SQL, "'Checks an order';")
+}
+
+func TestGenerator_FunctionBodyContainingDollarQuotes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantTag string
+	}{
+		{
+			name:    "plain body",
+			body:    "SELECT 1;",
+			wantTag: "$$",
+		},
+		{
+			name:    "body with $$",
+			body:    "BEGIN\n    EXECUTE $$SELECT 1; SELECT 2$$;\nEND;",
+			wantTag: "$fn$",
+		},
+		{
+			name:    "body with $$ and $fn$",
+			body:    "BEGIN\n    EXECUTE $$SELECT 1$$;\n    PERFORM $fn$ nested $fn$;\nEND;",
+			wantTag: "$fn1$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sql := "CREATE FUNCTION run_twice() RETURNS void AS $func$\n" + tt.body +
+				"\n$func$ LANGUAGE plpgsql;"
+
+			up, _ := generateViewTriggerFiles(t, "", sql)
+			assert.Contains(t, up, "RETURNS VOID AS "+tt.wantTag+"\n"+tt.body+"\n"+tt.wantTag+
+				" LANGUAGE plpgsql;")
+			assert.Empty(t, roundTripChanges(t, sql))
+		})
+	}
+}
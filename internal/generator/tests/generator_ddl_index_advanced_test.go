@@ -756,3 +756,46 @@ func indexOf(s, substr string) int {
 
 	return -1
 }
+
+func TestDDLBuilder_SwapIndex(t *testing.T) {
+	t.Parallel()
+
+	index := func(columns ...string) *schema.Index {
+		return &schema.Index{
+			Schema:    schema.DefaultSchema,
+			Name:      "daily_totals_day_key",
+			TableName: "daily_totals",
+			Columns:   columns,
+			IsUnique:  true,
+		}
+	}
+
+	change := differ.Change{
+		Type:       differ.ChangeTypeModifyIndex,
+		ObjectType: "index",
+		ObjectName: "public.daily_totals_day_key",
+		Details: map[string]any{
+			"current":                 index("day"),
+			"desired":                 index("day", "region"),
+			differ.DetailKeySwapIndex: "daily_totals_day_key_pgtofu_swap",
+		},
+	}
+
+	builder := generator.NewDDLBuilder(&differ.DiffResult{Changes: []differ.Change{change}}, true)
+
+	up, err := builder.BuildUpStatement(change)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE UNIQUE INDEX daily_totals_day_key_pgtofu_swap ON public.daily_totals "+
+		"(day, region);\n\n"+
+		"DROP INDEX IF EXISTS public.daily_totals_day_key;\n\n"+
+		"ALTER INDEX public.daily_totals_day_key_pgtofu_swap RENAME TO daily_totals_day_key;",
+		up.SQL)
+
+	down, err := builder.BuildDownStatement(change)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE UNIQUE INDEX daily_totals_day_key_pgtofu_swap ON public.daily_totals "+
+		"(day);\n\n"+
+		"DROP INDEX IF EXISTS public.daily_totals_day_key;\n\n"+
+		"ALTER INDEX public.daily_totals_day_key_pgtofu_swap RENAME TO daily_totals_day_key;",
+		down.SQL)
+}
@@ -70,7 +70,7 @@ func TestDDLBuilder_MaterializedViewOperations(t *testing.T) {
 				Name:       "old_mv",
 				Definition: "SELECT * FROM old_table",
 			},
-			wantSQL:        []string{"DROP MATERIALIZED VIEW", "IF EXISTS", "old_mv", "CASCADE"},
+			wantSQL:        []string{"DROP MATERIALIZED VIEW", "IF EXISTS", "old_mv"},
 			wantUnsafe:     true,
 			wantRequiresTx: true,
 		},
@@ -0,0 +1,174 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func generateSequenceStatements(
+	t *testing.T,
+	enforceStart bool,
+	current, desired *schema.Database,
+) (up, down []string) {
+	t.Helper()
+
+	opts := differ.DefaultOptions()
+	opts.EnforceSequenceStart = enforceStart
+
+	diff, err := differ.New(opts).Compare(current, desired)
+	require.NoError(t, err)
+
+	generated, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	for _, migration := range generated.Migrations {
+		up = append(up, migrationStatements(migration.UpFile)...)
+		down = append(down, migrationStatements(migration.DownFile)...)
+	}
+
+	return up, down
+}
+
+func TestGenerateAddSequenceWithAllOptions(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSchemaSQL(t, `CREATE SEQUENCE public.order_seq AS integer
+		INCREMENT BY 5 MINVALUE 10 MAXVALUE 5000 START WITH 1000 CACHE 20 CYCLE;
+		CREATE SEQUENCE public.plain_seq;`)
+
+	up, down := generateSequenceStatements(t, false, &schema.Database{}, desired)
+
+	assert.Contains(t, up, "CREATE SEQUENCE public.order_seq AS integer INCREMENT BY 5 "+
+		"MINVALUE 10 MAXVALUE 5000 START WITH 1000 CACHE 20 CYCLE")
+	assert.Contains(t, up, "CREATE SEQUENCE public.plain_seq")
+	assert.Contains(t, down, "DROP SEQUENCE IF EXISTS public.order_seq")
+
+	up, down = generateSequenceStatements(t, false, desired, &schema.Database{})
+	assert.Contains(t, up, "DROP SEQUENCE IF EXISTS public.order_seq")
+	assert.Contains(t, down, "CREATE SEQUENCE public.order_seq AS integer INCREMENT BY 5 "+
+		"MINVALUE 10 MAXVALUE 5000 START WITH 1000 CACHE 20 CYCLE")
+}
+
+func TestGenerateModifySequenceStart(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `CREATE SEQUENCE public.order_seq INCREMENT BY 2;`)
+	desired := parseSchemaSQL(t, `CREATE SEQUENCE public.order_seq START WITH 1000 CYCLE;`)
+
+	tests := []struct {
+		name         string
+		enforceStart bool
+		wantUp       string
+		wantDown     string
+	}{
+		{
+			name:     "start is creation-only",
+			wantUp:   "ALTER SEQUENCE public.order_seq INCREMENT BY 1 CYCLE",
+			wantDown: "ALTER SEQUENCE public.order_seq INCREMENT BY 2 NO CYCLE",
+		},
+		{
+			name:         "start enforced",
+			enforceStart: true,
+			wantUp:       "ALTER SEQUENCE public.order_seq INCREMENT BY 1 START WITH 1000 CYCLE",
+			wantDown:     "ALTER SEQUENCE public.order_seq INCREMENT BY 2 START WITH 1 NO CYCLE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			up, down := generateSequenceStatements(t, tt.enforceStart, current, desired)
+			assert.Equal(t, []string{tt.wantUp}, up)
+			assert.Equal(t, []string{tt.wantDown}, down)
+		})
+	}
+}
+
+func TestGenerateModifySequenceCacheAndOwner(t *testing.T) {
+	t.Parallel()
+
+	const orders = `CREATE TABLE public.orders (id bigint, legacy_id bigint);`
+
+	current := parseSchemaSQL(t, orders+`
+		CREATE SEQUENCE public.order_seq INCREMENT BY 2 OWNED BY public.orders.legacy_id;`)
+	desired := parseSchemaSQL(t, orders+`
+		CREATE SEQUENCE public.order_seq CACHE 20 OWNED BY orders.id;`)
+
+	up, down := generateSequenceStatements(t, false, current, desired)
+	assert.Equal(t, []string{
+		"ALTER SEQUENCE public.order_seq INCREMENT BY 1 CACHE 20 OWNED BY public.orders.id",
+	}, up)
+	assert.Equal(t, []string{
+		"ALTER SEQUENCE public.order_seq INCREMENT BY 2 CACHE 1 OWNED BY public.orders.legacy_id",
+	}, down)
+
+	unowned := parseSchemaSQL(t, orders+`CREATE SEQUENCE public.order_seq CACHE 20;`)
+
+	up, down = generateSequenceStatements(t, false, desired, unowned)
+	assert.Equal(t, []string{"ALTER SEQUENCE public.order_seq OWNED BY NONE"}, up)
+	assert.Equal(t, []string{"ALTER SEQUENCE public.order_seq OWNED BY public.orders.id"}, down)
+}
+
+func TestGenerateSequenceOwnerAfterOwningColumn(t *testing.T) {
+	t.Parallel()
+
+	statementIndex := func(t *testing.T, statements []string, prefix string) int {
+		t.Helper()
+
+		for i, stmt := range statements {
+			if strings.HasPrefix(stmt, prefix) {
+				return i
+			}
+		}
+
+		t.Fatalf("no statement starting with %q in %q", prefix, statements)
+
+		return -1
+	}
+
+	t.Run("new sequence and table", func(t *testing.T) {
+		t.Parallel()
+
+		desired := parseSchemaSQL(t, `CREATE SEQUENCE public.invoice_number;
+			CREATE TABLE public.invoices (
+				id bigint,
+				number bigint DEFAULT nextval('public.invoice_number')
+			);
+			ALTER SEQUENCE public.invoice_number OWNED BY public.invoices.number;`)
+
+		up, down := generateSequenceStatements(t, false, &schema.Database{}, desired)
+
+		create := statementIndex(t, up, "CREATE SEQUENCE public.invoice_number")
+		table := statementIndex(t, up, "CREATE TABLE public.invoices")
+		owner := statementIndex(t, up,
+			"ALTER SEQUENCE public.invoice_number OWNED BY public.invoices.number")
+
+		assert.Less(t, create, table, "the table's default draws from the sequence")
+		assert.Less(t, table, owner, "the owning column exists before OWNED BY")
+		assert.Less(t,
+			statementIndex(t, down, "ALTER SEQUENCE public.invoice_number OWNED BY NONE"),
+			statementIndex(t, down, "DROP TABLE IF EXISTS public.invoices"))
+	})
+
+	t.Run("new sequence owned by a new column", func(t *testing.T) {
+		t.Parallel()
+
+		current := parseSchemaSQL(t, `CREATE TABLE public.orders (id bigint);`)
+		desired := parseSchemaSQL(t, `CREATE TABLE public.orders (id bigint, number bigint);
+			CREATE SEQUENCE public.order_number OWNED BY public.orders.number;`)
+
+		up, _ := generateSequenceStatements(t, false, current, desired)
+
+		assert.Less(t,
+			statementIndex(t, up, "ALTER TABLE public.orders ADD COLUMN number"),
+			statementIndex(t, up, "ALTER SEQUENCE public.order_number OWNED BY public.orders.number"))
+	})
+}
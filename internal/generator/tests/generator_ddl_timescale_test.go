@@ -1,6 +1,7 @@
 package generator_test
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -429,3 +430,251 @@ func TestDDLBuilder_CompressionPolicyDedupesSettings(t *testing.T) {
 	assert.Contains(t, stmt.SQL, "timescaledb.compress_orderby = 'event_time DESC'")
 	assert.NotContains(t, stmt.SQL, "event_time DESC,event_time DESC")
 }
+
+func TestDDLBuilder_ModifyCompressionSchedule(t *testing.T) {
+	t.Parallel()
+
+	withPolicy := func(compressAfter string) *schema.CompressionPolicy {
+		if compressAfter == "" {
+			return nil
+		}
+
+		return &schema.CompressionPolicy{
+			HypertableSchema: schema.DefaultSchema,
+			HypertableName:   "metrics",
+			CompressAfter:    compressAfter,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		current  string
+		desired  string
+		wantUp   string
+		wantDown string
+	}{
+		{
+			name:    "interval changed",
+			current: "7 days",
+			desired: "14 days",
+			wantUp: "SELECT remove_compression_policy('public.metrics', if_exists => true);\n\n" +
+				"SELECT add_compression_policy('public.metrics', INTERVAL '14 days');",
+			wantDown: "SELECT remove_compression_policy('public.metrics', if_exists => true);\n\n" +
+				"SELECT add_compression_policy('public.metrics', INTERVAL '7 days');",
+		},
+		{
+			name:     "policy added",
+			desired:  "7 days",
+			wantUp:   "SELECT add_compression_policy('public.metrics', INTERVAL '7 days');",
+			wantDown: "SELECT remove_compression_policy('public.metrics', if_exists => true);",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hypertable := func(compressAfter string) schema.Hypertable {
+				return schema.Hypertable{
+					Schema:              schema.DefaultSchema,
+					TableName:           "metrics",
+					CompressionEnabled:  true,
+					CompressionSettings: &schema.CompressionSettings{},
+					CompressionPolicy:   withPolicy(compressAfter),
+				}
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				&schema.Database{Hypertables: []schema.Hypertable{hypertable(tt.current)}},
+				&schema.Database{Hypertables: []schema.Hypertable{hypertable(tt.desired)}},
+			)
+			require.NoError(t, err)
+			require.Len(t, result.Changes, 1)
+
+			change := result.Changes[0]
+			require.Equal(t, differ.ChangeTypeModifyCompressionSchedule, change.Type)
+
+			builder := generator.NewDDLBuilder(result, true)
+
+			upStmt, err := builder.BuildUpStatement(change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, upStmt.SQL)
+
+			downStmt, err := builder.BuildDownStatement(change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDown, downStmt.SQL)
+		})
+	}
+}
+
+func TestGenerator_DisableCompressionRemovesPolicy(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Hypertables: []schema.Hypertable{
+			{
+				Schema:              schema.DefaultSchema,
+				TableName:           "metrics",
+				CompressionEnabled:  true,
+				CompressionSettings: &schema.CompressionSettings{},
+				CompressionPolicy: &schema.CompressionPolicy{
+					HypertableSchema: schema.DefaultSchema,
+					HypertableName:   "metrics",
+					CompressAfter:    "7 days",
+				},
+			},
+		},
+	}
+	desired := &schema.Database{
+		Hypertables: []schema.Hypertable{
+			{Schema: schema.DefaultSchema, TableName: "metrics"},
+		},
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	genResult, err := generator.New(testOptions()).Generate(result)
+	require.NoError(t, err)
+	require.Len(t, genResult.Migrations, 1)
+
+	up := genResult.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "SELECT remove_compression_policy('public.metrics', if_exists => true);")
+	assert.Contains(t, up, "ALTER TABLE public.metrics SET (timescaledb.compress = false);")
+	assert.NotContains(t, up, "add_compression_policy")
+
+	down := genResult.Migrations[0].DownFile.Content
+	assert.Contains(t, down, "ALTER TABLE public.metrics SET (timescaledb.compress);")
+	assert.Contains(t, down, "SELECT add_compression_policy('public.metrics', INTERVAL '7 days');")
+}
+
+func TestGenerator_HypertableDimensions(t *testing.T) {
+	t.Parallel()
+
+	columns := []schema.Column{
+		{Name: "time", DataType: "timestamptz", IsNullable: false, Position: 1},
+		{Name: "device_id", DataType: "integer", IsNullable: false, Position: 2},
+	}
+
+	dimensions := []schema.Dimension{
+		{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "7 days"},
+		{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 8},
+	}
+
+	t.Run("new hypertable", func(t *testing.T) {
+		t.Parallel()
+
+		current := &schema.Database{}
+		desired := &schema.Database{
+			Tables: []schema.Table{
+				{Schema: schema.DefaultSchema, Name: "metrics", Columns: columns},
+			},
+			Hypertables: []schema.Hypertable{
+				{
+					Schema:         schema.DefaultSchema,
+					TableName:      "metrics",
+					TimeColumnName: "time",
+					Dimensions:     dimensions,
+				},
+			},
+		}
+
+		up := generateUp(t, current, desired)
+
+		createIdx := strings.Index(up, "create_hypertable")
+		timeIdx := strings.Index(up, "SELECT add_dimension('public.metrics', 'received_at', "+
+			"chunk_time_interval => INTERVAL '7 days', if_not_exists => true);")
+		spaceIdx := strings.Index(up, "SELECT add_dimension('public.metrics', 'device_id', "+
+			"number_partitions => 8, if_not_exists => true);")
+
+		require.NotEqual(t, -1, createIdx)
+		require.NotEqual(t, -1, timeIdx)
+		require.NotEqual(t, -1, spaceIdx)
+		assert.Less(t, createIdx, timeIdx)
+		assert.Less(t, timeIdx, spaceIdx)
+		assert.Equal(t, 1, strings.Count(up, "'received_at'"))
+	})
+
+	t.Run("existing hypertable with new column", func(t *testing.T) {
+		t.Parallel()
+
+		current := &schema.Database{
+			Tables: []schema.Table{
+				{Schema: schema.DefaultSchema, Name: "metrics", Columns: columns},
+			},
+			Hypertables: []schema.Hypertable{
+				{Schema: schema.DefaultSchema, TableName: "metrics", TimeColumnName: "time"},
+			},
+		}
+		desired := &schema.Database{
+			Tables: []schema.Table{
+				{
+					Schema: schema.DefaultSchema,
+					Name:   "metrics",
+					Columns: append(slices.Clone(columns), schema.Column{
+						Name: "received_at", DataType: "timestamptz", IsNullable: false, Position: 3,
+					}),
+				},
+			},
+			Hypertables: []schema.Hypertable{
+				{
+					Schema:         schema.DefaultSchema,
+					TableName:      "metrics",
+					TimeColumnName: "time",
+					Dimensions:     dimensions[:1],
+				},
+			},
+		}
+
+		up := generateUp(t, current, desired)
+
+		columnIdx := strings.Index(up, "ADD COLUMN received_at")
+		dimensionIdx := strings.Index(up, "SELECT add_dimension('public.metrics', 'received_at'")
+
+		require.NotEqual(t, -1, columnIdx)
+		require.NotEqual(t, -1, dimensionIdx)
+		assert.Less(t, columnIdx, dimensionIdx)
+	})
+
+	t.Run("removed dimension", func(t *testing.T) {
+		t.Parallel()
+
+		current := &schema.Database{
+			Hypertables: []schema.Hypertable{
+				{
+					Schema:         schema.DefaultSchema,
+					TableName:      "metrics",
+					TimeColumnName: "time",
+					Dimensions:     dimensions[1:],
+				},
+			},
+		}
+		desired := &schema.Database{
+			Hypertables: []schema.Hypertable{
+				{Schema: schema.DefaultSchema, TableName: "metrics", TimeColumnName: "time"},
+			},
+		}
+
+		up := generateUp(t, current, desired)
+		assert.Contains(t, up,
+			"-- WARNING: TimescaleDB cannot remove dimension device_id on hypertable public.metrics")
+		assert.NotContains(t, up, "add_dimension")
+	})
+}
+
+func generateUp(t *testing.T, current, desired *schema.Database) string {
+	t.Helper()
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	genResult, err := generator.New(testOptions()).Generate(result)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	for _, migration := range genResult.Migrations {
+		sb.WriteString(migration.UpFile.Content)
+	}
+
+	return sb.String()
+}
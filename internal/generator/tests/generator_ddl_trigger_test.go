@@ -42,7 +42,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"set_updated_at",
 				"BEFORE UPDATE",
 				"FOR EACH ROW",
-				"EXECUTE FUNCTION public.UPDATE_TIMESTAMP",
+				"EXECUTE FUNCTION public.update_timestamp",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -65,7 +65,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"audit_log",
 				"AFTER INSERT OR UPDATE",
 				"FOR EACH STATEMENT",
-				"EXECUTE FUNCTION public.LOG_CHANGES",
+				"EXECUTE FUNCTION public.log_changes",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -88,7 +88,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"view_trigger",
 				"INSTEAD OF INSERT",
 				"FOR EACH ROW",
-				"EXECUTE FUNCTION public.HANDLE_VIEW_INSERT",
+				"EXECUTE FUNCTION public.handle_view_insert",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -112,7 +112,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"conditional_trigger",
 				"WHEN",
 				"NEW.status = 'completed'",
-				"EXECUTE FUNCTION public.HANDLE_COMPLETION",
+				"EXECUTE FUNCTION public.handle_completion",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -135,7 +135,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"multi_event_trigger",
 				"AFTER INSERT OR UPDATE OR DELETE",
 				"FOR EACH ROW",
-				"EXECUTE FUNCTION public.LOG_ALL_CHANGES",
+				"EXECUTE FUNCTION public.log_all_changes",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -159,7 +159,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"notify_changes",
 				"AFTER INSERT OR UPDATE OF status, shipped_at",
 				"FOR EACH ROW",
-				"EXECUTE FUNCTION public.NOTIFY",
+				"EXECUTE FUNCTION public.notify",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -197,7 +197,7 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 			wantSQL: []string{
 				"CREATE TRIGGER",
 				"qualified_function_trigger",
-				"EXECUTE FUNCTION app.CUSTOM_FUNCTION",
+				"EXECUTE FUNCTION app.custom_function",
 			},
 			wantUnsafe:     false,
 			wantRequiresTx: true,
@@ -220,7 +220,6 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"IF EXISTS",
 				"old_trigger",
 				"ON public.users",
-				"CASCADE",
 			},
 			wantUnsafe:     true,
 			wantRequiresTx: true,
@@ -243,7 +242,6 @@ func TestDDLBuilder_TriggerOperations(t *testing.T) {
 				"IF EXISTS",
 				"schema_trigger",
 				"ON app.users",
-				"CASCADE",
 			},
 			wantUnsafe:     true,
 			wantRequiresTx: true,
@@ -541,3 +539,97 @@ func TestDDLBuilder_DropTriggerDownMigration(t *testing.T) {
 	assert.Contains(t, downStmt.SQL, "ON app.records")
 	assert.Contains(t, downStmt.SQL, "EXECUTE FUNCTION")
 }
+
+func TestDDLBuilder_TriggerEnabledState(t *testing.T) {
+	t.Parallel()
+
+	trigger := func(timing, state string) *schema.Trigger {
+		return &schema.Trigger{
+			Schema:         schema.DefaultSchema,
+			Name:           "audit_trg",
+			TableName:      "orders",
+			Timing:         timing,
+			Events:         []string{"INSERT"},
+			ForEachRow:     true,
+			FunctionSchema: schema.DefaultSchema,
+			FunctionName:   "audit",
+			EnabledState:   state,
+		}
+	}
+
+	modify := func(
+		current, desired *schema.Trigger,
+		stateOnly bool,
+	) (*generator.DDLBuilder, differ.Change) {
+		change := differ.Change{
+			Type:       differ.ChangeTypeModifyTrigger,
+			ObjectName: "public.orders.audit_trg",
+			Details:    map[string]any{"current": current, "desired": desired},
+		}
+		if stateOnly {
+			change.Details["enabled_state_only"] = true
+		}
+
+		return generator.NewDDLBuilder(&differ.DiffResult{
+			Current: &schema.Database{Triggers: []schema.Trigger{*current}},
+			Desired: &schema.Database{Triggers: []schema.Trigger{*desired}},
+			Changes: []differ.Change{change},
+		}, false), change
+	}
+
+	t.Run("state only", func(t *testing.T) {
+		t.Parallel()
+
+		builder, change := modify(trigger("AFTER", ""),
+			trigger("AFTER", schema.TriggerEnabledDisabled), true)
+
+		up, err := builder.BuildUpStatement(change)
+		require.NoError(t, err)
+		assert.Equal(t, "ALTER TABLE public.orders DISABLE TRIGGER audit_trg;", up.SQL)
+		assert.Equal(t, "Disable trigger audit_trg", up.Description)
+		assert.False(t, up.IsUnsafe)
+
+		down, err := builder.BuildDownStatement(change)
+		require.NoError(t, err)
+		assert.Equal(t, "ALTER TABLE public.orders ENABLE TRIGGER audit_trg;", down.SQL)
+	})
+
+	t.Run("replacement keeps desired state", func(t *testing.T) {
+		t.Parallel()
+
+		builder, change := modify(trigger("BEFORE", schema.TriggerEnabledReplica),
+			trigger("AFTER", schema.TriggerEnabledAlways), false)
+
+		up, err := builder.BuildUpStatement(change)
+		require.NoError(t, err)
+		assert.Contains(t, up.SQL, "AFTER INSERT ON public.orders")
+		assert.True(t, strings.HasSuffix(up.SQL,
+			"\nALTER TABLE public.orders ENABLE ALWAYS TRIGGER audit_trg;"))
+
+		down, err := builder.BuildDownStatement(change)
+		require.NoError(t, err)
+		assert.Contains(t, down.SQL, "BEFORE INSERT ON public.orders")
+		assert.True(t, strings.HasSuffix(down.SQL,
+			"\nALTER TABLE public.orders ENABLE REPLICA TRIGGER audit_trg;"))
+	})
+
+	t.Run("dropped trigger is restored disabled", func(t *testing.T) {
+		t.Parallel()
+
+		builder := generator.NewDDLBuilder(&differ.DiffResult{
+			Current: &schema.Database{
+				Triggers: []schema.Trigger{*trigger("AFTER", schema.TriggerEnabledDisabled)},
+			},
+			Desired: &schema.Database{},
+		}, false)
+
+		down, err := builder.BuildDownStatement(differ.Change{
+			Type:       differ.ChangeTypeDropTrigger,
+			ObjectName: "public.orders.audit_trg",
+		})
+		require.NoError(t, err)
+		assert.Contains(t, down.SQL, "CREATE TRIGGER audit_trg")
+		assert.True(t, strings.HasSuffix(down.SQL,
+			"\nALTER TABLE public.orders DISABLE TRIGGER audit_trg;"))
+	})
+}
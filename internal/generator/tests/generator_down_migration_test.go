@@ -1,6 +1,7 @@
 package generator_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -128,7 +130,7 @@ func TestDropFunctionDownMigrationRecreatesFunction(t *testing.T) {
 	require.NoError(t, err, "DOWN migration for DROP_FUNCTION should not error")
 	assert.Contains(t, downStmt.SQL, "CREATE")
 	assert.Contains(t, downStmt.SQL, "FUNCTION")
-	assert.Contains(t, downStmt.SQL, "UPDATE_TIMESTAMP")
+	assert.Contains(t, downStmt.SQL, "public.update_timestamp()")
 	assert.Contains(t, downStmt.SQL, "RETURNS TRIGGER")
 	assert.Contains(t, downStmt.SQL, "NEW.updated_at = NOW()")
 }
@@ -156,7 +158,7 @@ func TestDropFunctionWithArgsDownMigration(t *testing.T) {
 		Desired: desired,
 		Changes: []differ.Change{{
 			Type:       differ.ChangeTypeDropFunction,
-			ObjectName: "public.calculate_total(INTEGER,NUMERIC)",
+			ObjectName: "public.calculate_total(integer,numeric)",
 		}},
 	}
 
@@ -166,7 +168,7 @@ func TestDropFunctionWithArgsDownMigration(t *testing.T) {
 	require.NoError(t, err, "DOWN migration for DROP_FUNCTION with args should not error")
 	assert.Contains(t, downStmt.SQL, "CREATE")
 	assert.Contains(t, downStmt.SQL, "FUNCTION")
-	assert.Contains(t, downStmt.SQL, "CALCULATE_TOTAL")
+	assert.Contains(t, downStmt.SQL, "public.calculate_total(")
 	assert.Contains(t, downStmt.SQL, "RETURNS NUMERIC")
 }
 
@@ -366,3 +368,63 @@ func TestFullMigrationDropMaterializedViewHasValidDownMigration(t *testing.T) {
 	assert.Contains(t, downContent, "cached_stats")
 	assert.NotContains(t, downContent, "Manual rollback required")
 }
+
+func TestFullMigrationDropTablePreservesColumnOrder(t *testing.T) {
+	t.Parallel()
+
+	columnList := `    zeta_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    owner_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    body TEXT,
+    summary TEXT,
+    status TEXT NOT NULL,
+    locale TEXT,
+    version INTEGER NOT NULL,
+    published_at TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL`
+
+	p := parser.New()
+	db := &schema.Database{}
+	require.NoError(t, p.ParseSQL("CREATE TABLE documents (\n"+columnList+"\n);", db))
+	require.Empty(t, p.GetErrors())
+
+	unpositioned := *db
+	unpositioned.Tables = []schema.Table{db.Tables[0]}
+	unpositioned.Tables[0].Columns = append([]schema.Column(nil), db.Tables[0].Columns...)
+
+	for i := range unpositioned.Tables[0].Columns {
+		unpositioned.Tables[0].Columns[i].Position = 0
+	}
+
+	tests := []struct {
+		name    string
+		current *schema.Database
+	}{
+		{name: "parsed positions", current: db},
+		{name: "missing positions", current: &unpositioned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			current := tt.current
+			current.Sort()
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(current, &schema.Database{})
+			require.NoError(t, err)
+
+			genResult, err := generator.New(testOptions()).Generate(result)
+			require.NoError(t, err)
+			require.Len(t, genResult.Migrations, 1)
+
+			down := genResult.Migrations[0].DownFile.Content
+			assert.Contains(t, down, "CREATE TABLE public.documents (\n"+columnList+"\n);")
+			assert.Equal(t, 1, strings.Count(down, "zeta_id"))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const duplicateCurrentSQL = `
+CREATE TABLE users (id BIGINT NOT NULL, email TEXT);
+COMMENT ON TABLE users IS 'Users';
+
+CREATE TABLE sales (id BIGINT PRIMARY KEY, region TEXT NOT NULL);
+CREATE MATERIALIZED VIEW old_totals AS SELECT region FROM sales;
+CREATE INDEX idx_old_totals ON old_totals (region);
+`
+
+const duplicateDesiredSQL = `
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE users (
+    id BIGINT NOT NULL,
+    email TEXT NOT NULL,
+    CONSTRAINT users_pkey PRIMARY KEY (id)
+);
+COMMENT ON TABLE users IS 'Registered users';
+COMMENT ON COLUMN users.email IS 'Login address';
+
+CREATE VIEW user_emails AS SELECT email FROM users;
+COMMENT ON VIEW user_emails IS 'Login addresses';
+
+CREATE TABLE sales (id BIGINT PRIMARY KEY, region TEXT NOT NULL);
+CREATE MATERIALIZED VIEW sales_by_region AS SELECT region, count(*) AS n FROM sales GROUP BY region;
+COMMENT ON MATERIALIZED VIEW sales_by_region IS 'Sales per region';
+CREATE INDEX idx_sales_by_region ON sales_by_region (region);
+
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, device_id TEXT NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+ALTER TABLE metrics SET (timescaledb.compress, timescaledb.compress_segmentby = 'device_id');
+SELECT add_compression_policy('metrics', INTERVAL '7 days');
+SELECT add_retention_policy('metrics', INTERVAL '90 days');
+`
+
+// migrationStatements splits migration files into their statements, leaving
+// out comments and transaction control.
+func migrationStatements(files ...*generator.MigrationFile) []string {
+	var statements []string
+
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+
+		for _, stmt := range strings.Split(file.Content, ";\n") {
+			var lines []string
+
+			for _, line := range strings.Split(stmt, "\n") {
+				if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+					lines = append(lines, line)
+				}
+			}
+
+			sql := strings.TrimSpace(strings.Join(lines, "\n"))
+			if sql != "" && sql != "BEGIN" && sql != "COMMIT" {
+				statements = append(statements, sql)
+			}
+		}
+	}
+
+	return statements
+}
+
+func TestGenerateEmitsEachStatementOnce(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, duplicateCurrentSQL)
+	desired := parseSchemaSQL(t, duplicateDesiredSQL)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	generated, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	var up, down []*generator.MigrationFile
+	for _, migration := range generated.Migrations {
+		up = append(up, migration.UpFile)
+		down = append(down, migration.DownFile)
+	}
+
+	upStatements := migrationStatements(up...)
+	for _, want := range []string{
+		"ALTER TABLE public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id)",
+		"COMMENT ON VIEW public.user_emails IS 'Login addresses'",
+		"COMMENT ON MATERIALIZED VIEW public.sales_by_region IS 'Sales per region'",
+		"CREATE INDEX idx_sales_by_region ON public.sales_by_region (region)",
+		"SELECT add_retention_policy('public.metrics', INTERVAL '90 days')",
+	} {
+		assert.Contains(t, upStatements, want)
+	}
+
+	for name, statements := range map[string][]string{
+		"up":   upStatements,
+		"down": migrationStatements(down...),
+	} {
+		seen := make(map[string]bool, len(statements))
+		for _, stmt := range statements {
+			assert.False(t, seen[stmt], "%s migration runs %q twice", name, stmt)
+			seen[stmt] = true
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestGenerator_SkipsMigrationsWithoutStatements(t *testing.T) {
+	t.Parallel()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(parseSchemaSQL(t, `
+CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');`), parseSchemaSQL(t, ""))
+	require.NoError(t, err)
+	require.Len(t, diff.Changes, 2)
+
+	// Pinning the drop to a migration of its own leaves the hypertable
+	// conversion, which the drop makes unnecessary, alone in a batch.
+	for i := range diff.Changes {
+		if diff.Changes[i].Type == differ.ChangeTypeDropTable {
+			diff.Changes[i].Migration = "retire_metrics"
+		}
+	}
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile
+	assert.Equal(t, 1, up.Version)
+	assert.Contains(t, up.FileName, "retire_metrics")
+	assert.Contains(t, up.Content, "DROP TABLE IF EXISTS public.metrics;")
+	assert.NotContains(t, up.Content, "hypertable")
+
+	var notes []diag.Warning
+
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodeEmptyMigration {
+			notes = append(notes, warning)
+		}
+	}
+
+	require.Len(t, notes, 1)
+	assert.Equal(t, diag.SeverityInfo, notes[0].Severity)
+	assert.Contains(t, notes[0].Message, "none of its 1 changes needs a statement")
+}
@@ -283,3 +283,28 @@ func TestGeneratorFunctionsBeforeTriggers(t *testing.T) {
 	require.GreaterOrEqual(t, triggerPos, 0, "trigger statement not found")
 	assert.Less(t, functionPos, triggerPos, "function should be created before trigger")
 }
+
+func TestColumnAttributesPrecedeConstraintsOnTheColumn(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `CREATE TABLE jobs (id BIGINT PRIMARY KEY, retries INTEGER);`)
+	desired := parseSchemaSQL(t, `CREATE TABLE jobs (
+		id BIGINT PRIMARY KEY,
+		retries INTEGER NOT NULL DEFAULT 0 CHECK (retries >= 0),
+		CONSTRAINT jobs_retries_max CHECK (retries <= 10)
+	);`)
+
+	diffResult, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	genResult, err := generator.New(testOptions()).Generate(diffResult)
+	require.NoError(t, err)
+	require.Len(t, genResult.Migrations, 1)
+
+	assert.Equal(t, []string{
+		"ALTER TABLE public.jobs ALTER COLUMN retries SET DEFAULT 0",
+		"ALTER TABLE public.jobs ALTER COLUMN retries SET NOT NULL",
+		"ALTER TABLE public.jobs ADD CONSTRAINT jobs_retries_check CHECK (retries >= 0)",
+		"ALTER TABLE public.jobs ADD CONSTRAINT jobs_retries_max CHECK (retries <= 10)",
+	}, migrationStatements(genResult.Migrations[0].UpFile))
+}
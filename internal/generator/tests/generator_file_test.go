@@ -3,11 +3,13 @@ package generator_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/generator"
 	"github.com/accented-ai/pgtofu/internal/schema"
@@ -84,6 +86,79 @@ func TestGenerator_NextVersion(t *testing.T) {
 	assert.Equal(t, 3, version)
 }
 
+func TestGenerator_FileWritingGoose(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	result := &differ.DiffResult{
+		Current: &schema.Database{},
+		Desired: &schema.Database{
+			Tables: []schema.Table{
+				{
+					Schema: schema.DefaultSchema,
+					Name:   "users",
+					Columns: []schema.Column{
+						{Name: "id", DataType: "bigint", IsNullable: false, Position: 1},
+					},
+				},
+			},
+		},
+		Changes: []differ.Change{{Type: differ.ChangeTypeAddTable, ObjectName: userTable}},
+	}
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = tmpDir
+	opts.OutputFormat = generator.OutputFormatGoose
+
+	genResult, err := generator.New(opts).Generate(result)
+	require.NoError(t, err)
+	assert.Equal(t, 1, genResult.FilesGenerated)
+	require.Len(t, genResult.Migrations, 1)
+	assert.Nil(t, genResult.Migrations[0].DownFile)
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "00001_add_table_users.sql", entries[0].Name())
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, entries[0].Name()))
+	require.NoError(t, err)
+
+	up, down, found := strings.Cut(string(content), "-- +goose Down\n")
+	require.True(t, found)
+	assert.Contains(t, up, "-- +goose Up\n")
+	assert.Contains(t, up, "CREATE TABLE")
+	assert.Contains(t, down, "DROP TABLE")
+	assert.NotContains(t, string(content), "BEGIN;")
+}
+
+func TestGenerator_NextVersionGoose(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	existingFiles := []string{
+		"00001_initial.sql",
+		"00002_backfill.go",
+		"00003_add_users.sql",
+		"notes.txt",
+	}
+
+	for _, filename := range existingFiles {
+		err := os.WriteFile(filepath.Join(tmpDir, filename), []byte(""), 0o644)
+		require.NoError(t, err)
+	}
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = tmpDir
+	opts.OutputFormat = generator.OutputFormatGoose
+
+	gen := generator.New(opts)
+	version, err := gen.GetNextMigrationVersion()
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, version)
+}
+
 func TestGenerateResult_Summary(t *testing.T) {
 	t.Parallel()
 
@@ -105,10 +180,16 @@ func TestGenerateResult_Summary(t *testing.T) {
 						},
 					},
 				},
-				Warnings:       []string{"Warning 1", "Warning 2"},
+				Diagnostics: []diag.Warning{
+					{Code: diag.CodeUnsafeOperation, Message: "Warning 1"},
+					{Code: diag.CodeUnsafeRollback, Message: "Warning 2"},
+				},
 				FilesGenerated: 2,
 			},
-			wantText: []string{"Migrations Generated: 1", "Files Created: 2", "Warnings: 2"},
+			wantText: []string{
+				"Migrations Generated: 1", "Files Created: 2", "Warnings: 2",
+				"[UNSAFE_ROLLBACK] Warning 2",
+			},
 		},
 		{
 			name: "no migrations",
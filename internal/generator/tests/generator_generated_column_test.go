@@ -0,0 +1,110 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestGenerator_GeneratedColumnInCreateTableAndAddColumn(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `CREATE TABLE order_lines (price NUMERIC NOT NULL);`)
+	desired := parseSchemaSQL(t, `
+CREATE TABLE order_lines (
+    price NUMERIC NOT NULL,
+    total NUMERIC GENERATED ALWAYS AS (round(price * 1.2, 2)) STORED
+);
+CREATE TABLE invoices (
+    net NUMERIC NOT NULL,
+    gross NUMERIC NOT NULL GENERATED ALWAYS AS (net * 1.2) STORED
+);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "    gross NUMERIC NOT NULL GENERATED ALWAYS AS (net * 1.2) STORED\n")
+	assert.Contains(t, up, "ALTER TABLE public.order_lines ADD COLUMN total NUMERIC "+
+		"GENERATED ALWAYS AS (round(price * 1.2, 2)) STORED;")
+}
+
+func TestGenerator_ChangedGenerationExpression(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `
+CREATE TABLE order_lines (
+    price NUMERIC NOT NULL,
+    qty INTEGER,
+    total NUMERIC GENERATED ALWAYS AS (price * qty) STORED
+);
+COMMENT ON COLUMN order_lines.total IS 'Line total';
+CREATE INDEX order_lines_total_idx ON order_lines (total);`)
+	desired := parseSchemaSQL(t, `
+CREATE TABLE order_lines (
+    price NUMERIC NOT NULL,
+    qty INTEGER,
+    total NUMERIC GENERATED ALWAYS AS (price * coalesce(qty, 1)) STORED
+);
+COMMENT ON COLUMN order_lines.total IS 'Line total';
+CREATE INDEX order_lines_total_idx ON order_lines (total);
+CREATE INDEX order_lines_price_total_idx ON order_lines (price, total);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assertOrdered(t, up,
+		"ALTER TABLE public.order_lines DROP COLUMN total;",
+		"ALTER TABLE public.order_lines ADD COLUMN total NUMERIC "+
+			"GENERATED ALWAYS AS (price * coalesce(qty, 1)) STORED;",
+		"COMMENT ON COLUMN public.order_lines.total IS 'Line total';",
+		"CREATE INDEX order_lines_total_idx ON public.order_lines",
+	)
+	assert.Equal(t, 1, strings.Count(up, "CREATE INDEX order_lines_price_total_idx"),
+		"a new index on the column is only created by its own change")
+	assert.Contains(t, result.Warnings, "Unsafe operation: "+
+		"Modify column order_lines.total by dropping and re-adding it")
+
+	down := result.Migrations[0].DownFile.Content
+	assert.Contains(t, down, "ADD COLUMN total NUMERIC GENERATED ALWAYS AS (price * qty) STORED;")
+}
+
+func TestGenerator_GeneratedColumnBecomesPlain(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `
+CREATE TABLE order_lines (
+    price NUMERIC NOT NULL,
+    total NUMERIC GENERATED ALWAYS AS (price * 2) STORED
+);`)
+	desired := parseSchemaSQL(t, `
+CREATE TABLE order_lines (price NUMERIC NOT NULL, total NUMERIC DEFAULT 0);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	assertOrdered(t, result.Migrations[0].UpFile.Content,
+		"ALTER TABLE public.order_lines ALTER COLUMN total DROP EXPRESSION;",
+		"ALTER TABLE public.order_lines ALTER COLUMN total SET DEFAULT 0;",
+	)
+	assert.Contains(t, result.Migrations[0].DownFile.Content,
+		"ADD COLUMN total NUMERIC GENERATED ALWAYS AS (price * 2) STORED;")
+}
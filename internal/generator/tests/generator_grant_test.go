@@ -0,0 +1,78 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const grantOrdersTable = `CREATE TABLE orders (id bigint PRIMARY KEY, total numeric);
+`
+
+func TestGenerator_GrantsOfNewTable(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t, ``, grantOrdersTable+`
+GRANT SELECT, INSERT ON orders TO app;
+GRANT SELECT ON orders TO "Reporting" WITH GRANT OPTION;
+GRANT UPDATE (total) ON orders TO PUBLIC;`)
+
+	createTable := strings.Index(up, "CREATE TABLE public.orders (")
+	app := strings.Index(up, "GRANT INSERT, SELECT ON TABLE public.orders TO app;")
+	reporting := strings.Index(up,
+		`GRANT SELECT ON TABLE public.orders TO "Reporting" WITH GRANT OPTION;`)
+	public := strings.Index(up, "GRANT UPDATE (total) ON TABLE public.orders TO PUBLIC;")
+
+	require.NotEqual(t, -1, createTable, up)
+	require.NotEqual(t, -1, app, up)
+	require.NotEqual(t, -1, reporting, up)
+	require.NotEqual(t, -1, public, up)
+	assert.Less(t, createTable, app)
+	assert.Less(t, createTable, reporting)
+	assert.Less(t, createTable, public)
+
+	assert.Contains(t, down, "DROP TABLE IF EXISTS public.orders")
+}
+
+func TestGenerator_ModifyGrants(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t,
+		grantOrdersTable+`GRANT SELECT, DELETE ON orders TO app;
+GRANT UPDATE ON orders TO app WITH GRANT OPTION;`,
+		grantOrdersTable+`GRANT SELECT, UPDATE, INSERT ON orders TO app;`)
+
+	assert.Contains(t, up, "GRANT INSERT ON TABLE public.orders TO app;")
+	assert.Contains(t, up, "REVOKE DELETE ON TABLE public.orders FROM app;")
+	assert.Contains(t, up, "REVOKE GRANT OPTION FOR UPDATE ON TABLE public.orders FROM app;")
+	assert.NotContains(t, up, "SELECT ON TABLE")
+
+	assert.Contains(t, down, "REVOKE INSERT ON TABLE public.orders FROM app;")
+	assert.Contains(t, down, "GRANT DELETE ON TABLE public.orders TO app;")
+	assert.Contains(t, down, "GRANT UPDATE ON TABLE public.orders TO app WITH GRANT OPTION;")
+}
+
+func TestGenerator_RevokeBeforeDroppingColumn(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t,
+		`CREATE TABLE orders (id bigint PRIMARY KEY, total numeric, note text);
+GRANT SELECT (note) ON orders TO app;`,
+		grantOrdersTable+`REVOKE ALL ON orders FROM app;`)
+
+	revoke := strings.Index(up, "REVOKE SELECT (note) ON TABLE public.orders FROM app;")
+	drop := strings.Index(up, "DROP COLUMN")
+
+	require.NotEqual(t, -1, revoke, up)
+	require.NotEqual(t, -1, drop, up)
+	assert.Less(t, revoke, drop)
+
+	addColumn := strings.Index(down, "ADD COLUMN note")
+	grant := strings.Index(down, "GRANT SELECT (note) ON TABLE public.orders TO app;")
+
+	require.NotEqual(t, -1, addColumn, down)
+	require.NotEqual(t, -1, grant, down)
+	assert.Less(t, addColumn, grant)
+}
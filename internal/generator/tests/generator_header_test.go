@@ -0,0 +1,113 @@
+package generator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const (
+	headerCurrent = `CREATE TABLE accounts (id BIGINT PRIMARY KEY);`
+	headerDesired = `CREATE TABLE accounts (id BIGINT PRIMARY KEY, email TEXT);`
+)
+
+// generateHeaderFiles generates the header test schemas twice, advancing the
+// clock between runs, and returns the up and down files of both runs.
+func generateHeaderFiles(t *testing.T, configure func(*generator.Options)) [2][]string {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseSchemaSQL(t, headerCurrent),
+		parseSchemaSQL(t, headerDesired),
+	)
+	require.NoError(t, err)
+
+	clock := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var runs [2][]string
+
+	for i := range runs {
+		opts := testOptions()
+		opts.Now = func() time.Time { return clock }
+		configure(opts)
+
+		result, err := generator.New(opts).Generate(diff)
+		require.NoError(t, err)
+		require.Len(t, result.Migrations, 1)
+
+		runs[i] = []string{
+			result.Migrations[0].UpFile.Content,
+			result.Migrations[0].DownFile.Content,
+		}
+		clock = clock.Add(time.Hour)
+	}
+
+	return runs
+}
+
+func TestGenerator_HeaderMetadata(t *testing.T) {
+	t.Parallel()
+
+	optionsHash := differ.DefaultOptions().Hash()
+
+	t.Run("timestamp recorded by default", func(t *testing.T) {
+		t.Parallel()
+
+		runs := generateHeaderFiles(t, func(*generator.Options) {})
+
+		assert.Contains(t, runs[0][0], "-- Generated: 2024-03-01T12:00:00Z\n")
+		assert.Contains(t, runs[1][0], "-- Generated: 2024-03-01T13:00:00Z\n")
+		assert.Contains(t, runs[0][0], "-- Generated by pgtofu\n")
+		assert.Contains(t, runs[0][0], "-- Differ options: "+optionsHash+"\n")
+	})
+
+	t.Run("fixed clock is byte identical", func(t *testing.T) {
+		t.Parallel()
+
+		fixed := time.Unix(1704067200, 0).UTC()
+		runs := generateHeaderFiles(t, func(opts *generator.Options) {
+			opts.Now = func() time.Time { return fixed }
+		})
+
+		assert.Equal(t, runs[0], runs[1])
+		assert.Contains(t, runs[0][0], "-- Generated: 2024-01-01T00:00:00Z\n")
+	})
+
+	t.Run("omitted timestamp is byte identical", func(t *testing.T) {
+		t.Parallel()
+
+		runs := generateHeaderFiles(t, func(opts *generator.Options) {
+			opts.OmitTimestamp = true
+			opts.ToolVersion = "v1.4.0 (3f2a9c1)"
+		})
+
+		assert.Equal(t, runs[0], runs[1])
+
+		for _, content := range runs[0] {
+			assert.NotContains(t, content, "-- Generated:")
+			assert.Contains(t, content, "-- Generated by pgtofu v1.4.0 (3f2a9c1)\n")
+			assert.Contains(t, content, "-- Differ options: "+optionsHash+"\n")
+		}
+	})
+
+	t.Run("comparison recorded when set", func(t *testing.T) {
+		t.Parallel()
+
+		runs := generateHeaderFiles(t, func(*generator.Options) {})
+		assert.NotContains(t, runs[0][0], "-- Compared:")
+
+		runs = generateHeaderFiles(t, func(opts *generator.Options) {
+			opts.Comparison = "schema files at main -> working tree"
+		})
+
+		for _, content := range runs[0] {
+			assert.Contains(t, content, "-- Differ options: "+optionsHash+"\n"+
+				"-- Compared: schema files at main -> working tree\n")
+		}
+	})
+}
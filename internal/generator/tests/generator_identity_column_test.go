@@ -0,0 +1,118 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestGenerator_IdentityColumnInCreateTable(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSchemaSQL(t, `CREATE TABLE orders (
+    id BIGINT GENERATED ALWAYS AS IDENTITY (START WITH 100) PRIMARY KEY,
+    seq INTEGER GENERATED BY DEFAULT AS IDENTITY (INCREMENT BY -1)
+);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(parseSchemaSQL(t, ""), desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up,
+		"    id BIGINT NOT NULL GENERATED ALWAYS AS IDENTITY (START WITH 100),\n")
+	assert.Contains(t, up,
+		"    seq INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY (INCREMENT BY -1),\n")
+}
+
+func TestGenerator_SerialToIdentity(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `
+CREATE TABLE orders (id SERIAL PRIMARY KEY);
+CREATE SEQUENCE orders_id_seq AS integer OWNED BY orders.id;`)
+	desired := parseSchemaSQL(t,
+		`CREATE TABLE orders (id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	assertOrdered(t, result.Migrations[0].UpFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN id DROP DEFAULT;",
+		"DROP SEQUENCE IF EXISTS public.orders_id_seq;",
+		"ALTER TABLE public.orders ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY;",
+		"SELECT setval(pg_get_serial_sequence('public.orders', 'id'), MAX(id)) "+
+			"FROM public.orders HAVING MAX(id) >= 1;",
+	)
+
+	assertOrdered(t, result.Migrations[0].DownFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN id DROP IDENTITY;",
+		"CREATE SEQUENCE public.orders_id_seq AS integer",
+		"ALTER TABLE public.orders ALTER COLUMN id SET DEFAULT NEXTVAL('orders_id_seq');",
+	)
+}
+
+func TestGenerator_IdentityToSerialCreatesTheSequence(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t,
+		`CREATE TABLE orders (id BIGINT GENERATED BY DEFAULT AS IDENTITY (START WITH 10));`)
+	desired := parseSchemaSQL(t, `CREATE TABLE orders (id BIGSERIAL);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	assertOrdered(t, result.Migrations[0].UpFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN id DROP IDENTITY;",
+		"CREATE SEQUENCE public.orders_id_seq AS bigint OWNED BY public.orders.id;",
+		"SELECT setval(pg_get_serial_sequence('public.orders', 'id'), "+
+			"COALESCE((SELECT MAX(id) FROM public.orders), 1));",
+		"ALTER TABLE public.orders ALTER COLUMN id SET DEFAULT NEXTVAL('orders_id_seq');",
+	)
+
+	assertOrdered(t, result.Migrations[0].DownFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN id DROP DEFAULT;",
+		"ALTER TABLE public.orders ALTER COLUMN id "+
+			"ADD GENERATED BY DEFAULT AS IDENTITY (START WITH 10);",
+		"FROM public.orders HAVING MAX(id) >= 10;",
+	)
+}
+
+func TestGenerator_ModifiedIdentityOptions(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t,
+		`CREATE TABLE orders (id BIGINT GENERATED BY DEFAULT AS IDENTITY (CACHE 5));`)
+	desired := parseSchemaSQL(t, `CREATE TABLE orders (
+    id BIGINT GENERATED ALWAYS AS IDENTITY (INCREMENT BY 2 MAXVALUE 1000000 CYCLE)
+);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	assert.Contains(t, result.Migrations[0].UpFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN id SET GENERATED ALWAYS SET INCREMENT BY 2 "+
+			"SET MAXVALUE 1000000 SET CACHE 1 SET CYCLE;")
+	assert.Contains(t, result.Migrations[0].DownFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN id SET GENERATED BY DEFAULT SET INCREMENT BY 1 "+
+			"SET MAXVALUE 9223372036854775807 SET CACHE 5 SET NO CYCLE;")
+}
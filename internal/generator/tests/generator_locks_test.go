@@ -0,0 +1,205 @@
+package generator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const (
+	lockTestOrders  = `CREATE TABLE orders (id BIGINT NOT NULL, status TEXT, total INTEGER);`
+	lockTestNotNull = `CREATE TABLE orders (id BIGINT NOT NULL, status TEXT NOT NULL, total INT);`
+)
+
+func generateLocks(
+	t *testing.T,
+	desired string,
+	configure func(*generator.Options),
+) (*generator.GenerateResult, error) {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseSchemaSQL(t, lockTestOrders), parseSchemaSQL(t, desired))
+	require.NoError(t, err)
+
+	opts := testOptions()
+	if configure != nil {
+		configure(opts)
+	}
+
+	return generator.New(opts).Generate(diff)
+}
+
+func TestGenerator_StatementLockLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		desired   string
+		configure func(*generator.Options)
+		level     generator.LockLevel
+		note      string
+	}{
+		{
+			name: "add nullable column",
+			desired: `CREATE TABLE orders (id BIGINT NOT NULL, status TEXT, total INTEGER,
+				note TEXT);`,
+			level: generator.LockAccessExclusive,
+		},
+		{
+			name: "add column with constant default",
+			desired: `CREATE TABLE orders (id BIGINT NOT NULL, status TEXT, total INTEGER,
+				placed_at TIMESTAMPTZ NOT NULL DEFAULT now());`,
+			level: generator.LockAccessExclusive,
+		},
+		{
+			name: "add column with volatile default",
+			desired: `CREATE TABLE orders (id BIGINT NOT NULL, status TEXT, total INTEGER,
+				token UUID NOT NULL DEFAULT gen_random_uuid());`,
+			level: generator.LockAccessExclusive,
+			note:  "rewrites the table",
+		},
+		{
+			name:    "set not null",
+			desired: lockTestNotNull,
+			level:   generator.LockAccessExclusive,
+			note:    "scans the table",
+		},
+		{
+			name:    "change column type",
+			desired: `CREATE TABLE orders (id BIGINT NOT NULL, status TEXT, total BIGINT);`,
+			level:   generator.LockAccessExclusive,
+			note:    "may rewrite the table",
+		},
+		{
+			name:    "create index",
+			desired: lockTestOrders + `CREATE INDEX orders_status_idx ON orders (status);`,
+			level:   generator.LockShare,
+			note:    "builds an index",
+		},
+		{
+			name:    "create index concurrently",
+			desired: lockTestOrders + `CREATE INDEX orders_status_idx ON orders (status);`,
+			configure: func(opts *generator.Options) {
+				opts.ConcurrentIndexes = true
+			},
+			level: generator.LockShareUpdateExclusive,
+			note:  "builds an index",
+		},
+		{
+			name:    "new table",
+			desired: lockTestOrders + `CREATE TABLE customers (id BIGINT PRIMARY KEY);`,
+			level:   generator.LockNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := generateLocks(t, tt.desired, tt.configure)
+			require.NoError(t, err)
+
+			if tt.level == generator.LockNone {
+				assert.Empty(t, result.SafetyReport.Locks)
+				return
+			}
+
+			locks := result.SafetyReport.AtLeast(tt.level)
+			require.NotEmpty(t, locks, "locks: %v", result.SafetyReport.Locks)
+			assert.Equal(t, tt.level, locks[0].LockLevel)
+			assert.Equal(t, "public.orders", locks[0].AffectedObject)
+			assert.Equal(t, tt.note, locks[0].Note)
+			assert.Empty(t, result.SafetyReport.AtLeast(tt.level+1))
+		})
+	}
+}
+
+func TestGenerator_StrongestLockInHeader(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateLocks(t, lockTestNotNull, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	migration := result.Migrations[0]
+	assert.Contains(t, migration.UpFile.Content,
+		"-- Strongest lock: ACCESS EXCLUSIVE on public.orders (scans the table)\n")
+	assert.Contains(t, migration.DownFile.Content,
+		"-- Strongest lock: ACCESS EXCLUSIVE on public.orders\n")
+	assert.Len(t, result.SafetyReport.AccessExclusive(), 1)
+	assert.Contains(t, result.Summary(), "ACCESS EXCLUSIVE Locks: 1")
+}
+
+func TestGenerator_FailOnLock(t *testing.T) {
+	t.Parallel()
+
+	_, err := generateLocks(t, lockTestNotNull, func(opts *generator.Options) {
+		opts.FailOnLock = generator.LockShareRowExclusive
+	})
+
+	var lockErr *generator.LockError
+	require.True(t, errors.As(err, &lockErr), "expected a LockError, got %v", err)
+	assert.Equal(t, generator.LockShareRowExclusive, lockErr.Level)
+	require.Len(t, lockErr.Locks, 1)
+	assert.Contains(t, err.Error(), "ACCESS EXCLUSIVE on public.orders (scans the table)")
+
+	diagnostics := lockErr.Diagnostics()
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "LOCK_NOT_ALLOWED", string(diagnostics[0].Code))
+	assert.Equal(t, "public.orders", diagnostics[0].ObjectName)
+
+	_, err = generateLocks(t, lockTestNotNull, func(opts *generator.Options) {
+		opts.FailOnLock = generator.LockShareRowExclusive
+		opts.AllowLocks = []string{"public.ORD*"}
+	})
+	require.NoError(t, err)
+
+	_, err = generateLocks(t, lockTestOrders+`CREATE INDEX orders_status_idx ON orders (status);`,
+		func(opts *generator.Options) {
+			opts.FailOnLock = generator.LockShareRowExclusive
+		})
+	require.NoError(t, err, "SHARE is weaker than SHARE ROW EXCLUSIVE")
+}
+
+func TestGenerator_InvalidAllowLockPattern(t *testing.T) {
+	t.Parallel()
+
+	opts := testOptions()
+	opts.AllowLocks = []string{"public.["}
+
+	require.ErrorContains(t, opts.Validate(), `invalid allowed lock pattern "public.["`)
+}
+
+func TestParseLockLevel(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]generator.LockLevel{
+		"ACCESS EXCLUSIVE":       generator.LockAccessExclusive,
+		"access-exclusive":       generator.LockAccessExclusive,
+		"share_update_exclusive": generator.LockShareUpdateExclusive,
+		" Share ":                generator.LockShare,
+	} {
+		level, err := generator.ParseLockLevel(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, level, input)
+		assert.Equal(t, want, mustParseLockLevel(t, level.String()))
+	}
+
+	_, err := generator.ParseLockLevel("table")
+	require.ErrorContains(t, err, `unknown lock level "table"`)
+}
+
+func mustParseLockLevel(t *testing.T, s string) generator.LockLevel {
+	t.Helper()
+
+	level, err := generator.ParseLockLevel(s)
+	require.NoError(t, err)
+
+	return level
+}
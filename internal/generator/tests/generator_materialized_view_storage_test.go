@@ -0,0 +1,56 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const storageViewTable = `CREATE TABLE orders (id BIGINT, user_id BIGINT, total NUMERIC);`
+
+func TestGenerator_MaterializedViewStorageParams(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		storageViewTable+`
+CREATE MATERIALIZED VIEW stats_mv WITH (fillfactor = 70, toast_tuple_target = 256) AS
+SELECT user_id, SUM(total) AS total FROM orders GROUP BY user_id;`,
+		storageViewTable+`
+CREATE MATERIALIZED VIEW stats_mv AS
+SELECT user_id, SUM(total) AS total FROM orders GROUP BY user_id;
+ALTER MATERIALIZED VIEW stats_mv SET (autovacuum_enabled = false, fillfactor = 90);`,
+	)
+
+	const alter = "ALTER MATERIALIZED VIEW public.stats_mv "
+
+	assert.Contains(t, up, alter+"SET (autovacuum_enabled = false, fillfactor = 90);")
+	assert.Contains(t, up, alter+"RESET (toast_tuple_target);")
+	assert.NotContains(t, up, "DROP MATERIALIZED VIEW")
+
+	assert.Contains(t, down, alter+"SET (fillfactor = 70, toast_tuple_target = 256);")
+	assert.Contains(t, down, alter+"RESET (autovacuum_enabled);")
+}
+
+func TestGenerator_RecreatedMaterializedViewKeepsStorageParams(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		storageViewTable+`
+CREATE MATERIALIZED VIEW stats_mv AS
+SELECT user_id, SUM(total) AS total FROM orders GROUP BY user_id;
+ALTER MATERIALIZED VIEW stats_mv SET (fillfactor = 70);`,
+		storageViewTable+`
+CREATE MATERIALIZED VIEW stats_mv AS
+SELECT user_id, MAX(total) AS total FROM orders GROUP BY user_id;
+ALTER MATERIALIZED VIEW stats_mv SET (autovacuum_enabled = false);`,
+	)
+
+	assertOrdered(t, up,
+		"DROP MATERIALIZED VIEW IF EXISTS public.stats_mv;",
+		"CREATE MATERIALIZED VIEW public.stats_mv WITH (autovacuum_enabled = false) AS")
+	assert.NotContains(t, up, "ALTER MATERIALIZED VIEW")
+
+	assertOrdered(t, down,
+		"DROP MATERIALIZED VIEW IF EXISTS public.stats_mv;",
+		"CREATE MATERIALIZED VIEW public.stats_mv WITH (fillfactor = 70) AS")
+}
@@ -0,0 +1,55 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_MixedCaseIdentifiersAreQuoted(t *testing.T) {
+	t.Parallel()
+
+	const current = `
+CREATE TYPE "Status" AS ENUM ('open', 'closed');
+CREATE TABLE "UserAccounts" ("Id" BIGINT PRIMARY KEY, email TEXT);
+`
+
+	up, down := generateViewTriggerFiles(t, current, current+`
+CREATE TABLE "Billing" (
+    "AccountId" BIGINT NOT NULL REFERENCES "UserAccounts" ("Id"),
+    "State" "Status",
+    "user" TEXT
+);
+CREATE INDEX "idx_Billing_AccountId" ON "Billing" ("AccountId");
+`)
+
+	assert.Contains(t, up, `CREATE TABLE public."Billing" (`)
+	assert.Contains(t, up, `"AccountId" BIGINT NOT NULL,`)
+	assert.Contains(t, up, `"State" "Status",`)
+	assert.Contains(t, up, `"user" TEXT`)
+	assert.Contains(t, up, `REFERENCES public."UserAccounts" ("Id")`)
+	assert.Contains(t, up,
+		`CREATE INDEX "idx_Billing_AccountId" ON public."Billing" ("AccountId");`)
+	assert.NotContains(t, up, "useraccounts")
+	assert.Contains(t, down, `DROP TABLE IF EXISTS public."Billing"`)
+}
+
+func TestGenerator_MixedCaseFunctionAndTriggerRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	changes := roundTripChanges(t, `
+CREATE SCHEMA "App";
+CREATE TABLE "App"."Orders" (id BIGINT PRIMARY KEY, updated_at TIMESTAMPTZ);
+CREATE FUNCTION "App"."TouchUpdatedAt"() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+COMMENT ON FUNCTION "App"."TouchUpdatedAt"() IS 'Keeps updated_at current';
+CREATE TRIGGER "Orders_Touch" BEFORE UPDATE ON "App"."Orders"
+    FOR EACH ROW EXECUTE FUNCTION "App"."TouchUpdatedAt"();
+`)
+
+	assert.Empty(t, changes)
+}
@@ -195,6 +195,110 @@ func TestParseMigrationFileName(t *testing.T) {
 	}
 }
 
+func TestFormatMigrationFileNameFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format    generator.OutputFormat
+		direction generator.Direction
+		expected  string
+	}{
+		{generator.OutputFormatGolangMigrate, generator.DirectionUp, "000042_add_users.up.sql"},
+		{generator.OutputFormatGolangMigrate, generator.DirectionDown, "000042_add_users.down.sql"},
+		{generator.OutputFormatGoose, generator.DirectionUp, "00042_add_users.sql"},
+		{generator.OutputFormatGoose, "", "00042_add_users.sql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+
+			result := generator.FormatMigrationFileNameFor(tt.format, 42, "add_users", tt.direction)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseMigrationFileNameFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		format      generator.OutputFormat
+		fileName    string
+		wantVersion int
+		wantDesc    string
+		wantDir     generator.Direction
+		wantErr     bool
+	}{
+		{
+			name:        "golang-migrate up migration",
+			format:      generator.OutputFormatGolangMigrate,
+			fileName:    "000007_add_users.up.sql",
+			wantVersion: 7,
+			wantDesc:    "add_users",
+			wantDir:     generator.DirectionUp,
+		},
+		{
+			name:        "goose sql migration",
+			format:      generator.OutputFormatGoose,
+			fileName:    "00042_add_user_email_index.sql",
+			wantVersion: 42,
+			wantDesc:    "add_user_email_index",
+		},
+		{
+			name:        "goose go migration",
+			format:      generator.OutputFormatGoose,
+			fileName:    "00043_backfill_emails.go",
+			wantVersion: 43,
+			wantDesc:    "backfill_emails",
+		},
+		{
+			name:        "goose timestamp version",
+			format:      generator.OutputFormatGoose,
+			fileName:    "20240101120000_init.sql",
+			wantVersion: 20240101120000,
+			wantDesc:    "init",
+		},
+		{
+			name:     "golang-migrate rejects goose name",
+			format:   generator.OutputFormatGolangMigrate,
+			fileName: "00042_add_users.sql",
+			wantErr:  true,
+		},
+		{
+			name:     "goose missing description",
+			format:   generator.OutputFormatGoose,
+			fileName: "00042.sql",
+			wantErr:  true,
+		},
+		{
+			name:     "goose non-migration file",
+			format:   generator.OutputFormatGoose,
+			fileName: "README.md",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			version, desc, dir, err := generator.ParseMigrationFileNameFor(tt.format, tt.fileName)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVersion, version)
+			assert.Equal(t, tt.wantDesc, desc)
+			assert.Equal(t, tt.wantDir, dir)
+		})
+	}
+}
+
 func TestQuoteIdentifier(t *testing.T) {
 	t.Parallel()
 
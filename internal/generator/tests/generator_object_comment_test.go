@@ -0,0 +1,105 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const commentedObjectSchema = `
+CREATE SCHEMA billing;
+
+CREATE TABLE billing.invoices (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX invoices_customer_idx ON billing.invoices (customer_id);
+
+CREATE TRIGGER invoices_touch BEFORE UPDATE ON billing.invoices
+    FOR EACH ROW EXECUTE FUNCTION billing.touch();
+
+COMMENT ON SCHEMA billing IS 'Invoicing';
+COMMENT ON INDEX billing.invoices_customer_idx IS 'Invoices by customer';
+COMMENT ON TRIGGER invoices_touch ON billing.invoices IS 'Keeps updated_at current';
+`
+
+const (
+	schemaComment  = "COMMENT ON SCHEMA billing IS 'Invoicing';"
+	indexComment   = "COMMENT ON INDEX billing.invoices_customer_idx IS 'Invoices by customer';"
+	triggerComment = "COMMENT ON TRIGGER invoices_touch ON billing.invoices IS " +
+		"'Keeps updated_at current';"
+)
+
+func TestGenerator_AddObjectsSetsComments(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, "")
+	desired := parseSchemaSQL(t, commentedObjectSchema)
+
+	up, down := generateConstraintCommentFiles(t, current, desired)
+
+	assert.Contains(t, up, schemaComment)
+	assert.Contains(t, up, indexComment)
+	assert.Contains(t, up, triggerComment)
+	assert.Less(t, strings.Index(up, "CREATE SCHEMA"), strings.Index(up, schemaComment))
+	assert.Less(t, strings.Index(up, "CREATE INDEX"), strings.Index(up, indexComment))
+	assert.Less(t, strings.Index(up, "CREATE TRIGGER"), strings.Index(up, triggerComment))
+
+	assert.NotContains(t, down, "COMMENT ON",
+		"comment reverts are skipped when the objects are dropped")
+}
+
+func TestGenerator_DropObjectsRestoresComments(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, commentedObjectSchema)
+	desired := parseSchemaSQL(t, "")
+
+	up, down := generateConstraintCommentFiles(t, current, desired)
+
+	assert.NotContains(t, up, "COMMENT ON")
+
+	assert.Contains(t, down, schemaComment)
+	assert.Contains(t, down, indexComment)
+	assert.Contains(t, down, triggerComment)
+}
+
+func TestGenerator_ModifyObjectComments(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, commentedObjectSchema)
+	desired := parseSchemaSQL(t, commentedObjectSchema+`
+COMMENT ON SCHEMA billing IS 'Invoices and payments';
+COMMENT ON INDEX billing.invoices_customer_idx IS NULL;
+COMMENT ON TRIGGER invoices_touch ON billing.invoices IS 'Sets updated_at';
+`)
+
+	up, down := generateConstraintCommentFiles(t, current, desired)
+
+	assert.Contains(t, up, "COMMENT ON SCHEMA billing IS 'Invoices and payments';")
+	assert.Contains(t, up, "COMMENT ON INDEX billing.invoices_customer_idx IS NULL;")
+	assert.Contains(t, up, "COMMENT ON TRIGGER invoices_touch ON billing.invoices IS "+
+		"'Sets updated_at';")
+	assert.NotContains(t, up, "DROP INDEX")
+	assert.NotContains(t, up, "DROP TRIGGER")
+
+	assert.Contains(t, down, schemaComment)
+	assert.Contains(t, down, indexComment)
+	assert.Contains(t, down, triggerComment)
+}
+
+func TestGenerator_RecreatedIndexSetsCommentAgain(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, commentedObjectSchema)
+	desired := parseSchemaSQL(t, strings.Replace(commentedObjectSchema,
+		"(customer_id)", "(customer_id, id)", 1))
+
+	up, _ := generateConstraintCommentFiles(t, current, desired)
+
+	assert.Contains(t, up, indexComment)
+	assert.Less(t, strings.Index(up, "CREATE INDEX"), strings.Index(up, indexComment))
+}
@@ -0,0 +1,52 @@
+package generator_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestOutputFormats_ListsEveryDeclaredFormat(t *testing.T) {
+	t.Parallel()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "../types.go", nil, 0)
+	require.NoError(t, err)
+
+	var declared []generator.OutputFormat
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := spec.Type.(*ast.Ident); ok && ident.Name == "OutputFormat" {
+			for _, value := range spec.Values {
+				unquoted, err := strconv.Unquote(value.(*ast.BasicLit).Value)
+				require.NoError(t, err)
+
+				declared = append(declared, generator.OutputFormat(unquoted))
+			}
+		}
+
+		return false
+	})
+
+	require.NotEmpty(t, declared)
+	assert.ElementsMatch(t, declared, generator.OutputFormats(),
+		"an OutputFormat constant is missing from generator.OutputFormats")
+
+	for _, format := range generator.OutputFormats() {
+		opts := generator.DefaultOptions()
+		opts.OutputFormat = format
+
+		assert.NoError(t, opts.Validate(), "output format %s", format)
+	}
+}
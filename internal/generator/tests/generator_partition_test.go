@@ -265,3 +265,189 @@ func TestGenerator_MultiplePartitionChanges(t *testing.T) {
 	assert.Equal(t, 4, strings.Count(upContent, "PARTITION OF"))
 	assert.Equal(t, 4, strings.Count(upContent, "CREATE TABLE IF NOT EXISTS"))
 }
+
+func TestGenerator_OnlyParentIndexAttachesPartitionIndexes(t *testing.T) {
+	t.Parallel()
+
+	table := func(indexes ...schema.Index) schema.Table {
+		return schema.Table{
+			Schema: schema.DefaultSchema,
+			Name:   "events",
+			Columns: []schema.Column{
+				{Name: "id", DataType: "bigint", Position: 1},
+				{Name: "event_date", DataType: "date", Position: 2},
+			},
+			Indexes: indexes,
+			PartitionStrategy: &schema.PartitionStrategy{
+				Type:    "RANGE",
+				Columns: []string{"event_date"},
+				Partitions: []schema.Partition{
+					{
+						Name:       "events_2025",
+						Definition: "FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+					},
+				},
+			},
+		}
+	}
+
+	index := schema.Index{
+		Schema:     schema.DefaultSchema,
+		Name:       "idx_events_date",
+		TableName:  "events",
+		Columns:    []string{"event_date"},
+		Type:       schema.IndexTypeBTree,
+		OnlyParent: true,
+	}
+
+	result, err := differ.New(nil).Compare(
+		&schema.Database{Tables: []schema.Table{table()}},
+		&schema.Database{Tables: []schema.Table{table(index)}},
+	)
+	require.NoError(t, err)
+
+	genResult, err := generator.New(testOptions()).Generate(result)
+	require.NoError(t, err)
+	require.Len(t, genResult.Migrations, 1)
+
+	assert.Contains(t, genResult.Migrations[0].UpFile.Content,
+		"CREATE INDEX idx_events_date ON ONLY public.events (event_date);\n\n"+
+			"CREATE INDEX events_2025_idx_events_date ON public.events_2025 (event_date);\n\n"+
+			"ALTER INDEX public.idx_events_date ATTACH PARTITION "+
+			"public.events_2025_idx_events_date;")
+}
+
+func TestGenerator_PartitionLocalConstraints(t *testing.T) {
+	t.Parallel()
+
+	const events = `
+CREATE TABLE events (id BIGINT NOT NULL, kind TEXT NOT NULL) PARTITION BY RANGE (id);
+CREATE TABLE events_1 PARTITION OF events FOR VALUES FROM (0) TO (100);
+`
+
+	up, down := generateMigrationSQL(t,
+		events+`ALTER TABLE events_1 ADD CONSTRAINT events_1_id_check CHECK (id > 0);`,
+		events+`CREATE TABLE events_2 PARTITION OF events FOR VALUES FROM (100) TO (200);
+ALTER TABLE events_2 ADD CONSTRAINT events_2_id_check CHECK (id >= 100);`,
+		differ.DefaultOptions(),
+	)
+
+	addPartition := strings.Index(up, "CREATE TABLE IF NOT EXISTS public.events_2 PARTITION OF")
+	addCheck := strings.Index(up,
+		"ALTER TABLE public.events_2 ADD CONSTRAINT events_2_id_check CHECK (id >= 100);")
+	require.NotEqual(t, -1, addPartition)
+	require.NotEqual(t, -1, addCheck)
+	assert.Less(t, addPartition, addCheck, "the partition exists before its constraint")
+	assert.Contains(t, up,
+		"ALTER TABLE public.events_1 DROP CONSTRAINT IF EXISTS events_1_id_check;")
+
+	assert.Contains(t, down,
+		"ALTER TABLE public.events_1 ADD CONSTRAINT events_1_id_check CHECK (id > 0);")
+	assert.Contains(t, down,
+		"ALTER TABLE public.events_2 DROP CONSTRAINT IF EXISTS events_2_id_check;")
+}
+
+func TestGenerator_DetachPartitions(t *testing.T) {
+	t.Parallel()
+
+	const parent = `
+CREATE TABLE logs (id BIGINT NOT NULL, log_date DATE NOT NULL) PARTITION BY RANGE (log_date);
+CREATE TABLE logs_2025 PARTITION OF logs FOR VALUES FROM ('2025-01-01') TO ('2025-07-01');
+`
+
+	generate := func(t *testing.T, current, desired string) (string, string) {
+		t.Helper()
+
+		diff, err := differ.New(differ.DefaultOptions()).Compare(
+			parseSchemaSQL(t, current), parseSchemaSQL(t, desired))
+		require.NoError(t, err)
+
+		opts := testOptions()
+		opts.DetachPartitions = true
+
+		result, err := generator.New(opts).Generate(diff)
+		require.NoError(t, err)
+		require.Len(t, result.Migrations, 1)
+
+		return result.Migrations[0].UpFile.Content, result.Migrations[0].DownFile.Content
+	}
+
+	t.Run("removed partition", func(t *testing.T) {
+		t.Parallel()
+
+		up, down := generate(t,
+			parent+`CREATE TABLE logs_2024 PARTITION OF logs
+    FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');`,
+			parent)
+
+		assert.Contains(t, up, "ALTER TABLE public.logs DETACH PARTITION public.logs_2024;")
+		assert.NotContains(t, up, "DROP TABLE")
+		assert.Contains(t, down, "ALTER TABLE public.logs ATTACH PARTITION public.logs_2024\n"+
+			"FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');")
+		assert.NotContains(t, down, "MANUAL ROLLBACK")
+	})
+
+	t.Run("changed bounds", func(t *testing.T) {
+		t.Parallel()
+
+		up, down := generate(t, parent, strings.Replace(parent, "'2025-07-01'", "'2026-01-01'", 1))
+
+		detach := strings.Index(up, "ALTER TABLE public.logs DETACH PARTITION public.logs_2025;")
+		attach := strings.Index(up, "ALTER TABLE public.logs ATTACH PARTITION public.logs_2025\n"+
+			"FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');")
+		require.NotEqual(t, -1, detach)
+		require.NotEqual(t, -1, attach)
+		assert.Less(t, detach, attach)
+		assert.NotContains(t, up, "CREATE TABLE")
+
+		assert.Contains(t, down, "ALTER TABLE public.logs ATTACH PARTITION public.logs_2025\n"+
+			"FOR VALUES FROM ('2025-01-01') TO ('2025-07-01');")
+	})
+
+	t.Run("changed bounds keep local indexes", func(t *testing.T) {
+		t.Parallel()
+
+		const indexes = `
+CREATE INDEX logs_2025_id_idx ON logs_2025 (id);
+`
+		desired := strings.Replace(parent, "'2025-07-01'", "'2026-01-01'", 1)
+
+		up, down := generate(t, parent+indexes,
+			desired+indexes+"CREATE INDEX logs_2025_date_idx ON logs_2025 (log_date);\n")
+
+		assert.Contains(t, up, "ALTER TABLE public.logs ATTACH PARTITION public.logs_2025\n")
+		assert.NotContains(t, up, "logs_2025_id_idx", "the reattached partition keeps its index")
+		assert.NotContains(t, down, "logs_2025_id_idx")
+
+		attach := strings.Index(up, "ATTACH PARTITION public.logs_2025")
+		create := strings.Index(up, "CREATE INDEX")
+		require.NotEqual(t, -1, create, "an index new to the partition is still created")
+		assert.Contains(t, up[create:], "logs_2025_date_idx")
+		assert.Less(t, attach, create)
+		assert.Contains(t, down, "logs_2025_date_idx")
+	})
+}
+
+func TestGenerator_PartitionBoundsChangeRecreates(t *testing.T) {
+	t.Parallel()
+
+	const parent = `
+CREATE TABLE logs (id BIGINT NOT NULL, log_date DATE NOT NULL) PARTITION BY RANGE (log_date);
+`
+
+	up, down := generateMigrationSQL(t,
+		parent+`CREATE TABLE logs_2025 PARTITION OF logs FOR VALUES FROM ('2025-01-01') TO ('2025-07-01');`,
+		parent+`CREATE TABLE logs_2025 PARTITION OF logs FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');`,
+		differ.DefaultOptions(),
+	)
+
+	drop := strings.Index(up, "DROP TABLE IF EXISTS public.logs_2025;")
+	create := strings.Index(up, "CREATE TABLE IF NOT EXISTS public.logs_2025 PARTITION OF public.logs\n"+
+		"FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');")
+	require.NotEqual(t, -1, drop)
+	require.NotEqual(t, -1, create)
+	assert.Less(t, drop, create)
+
+	assert.Contains(t, down, "CREATE TABLE IF NOT EXISTS public.logs_2025 PARTITION OF public.logs\n"+
+		"FOR VALUES FROM ('2025-01-01') TO ('2025-07-01');")
+}
@@ -0,0 +1,91 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const pinnedMigrationSchema = `
+CREATE TABLE accounts (id BIGINT PRIMARY KEY);
+
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+
+-- pgtofu:migration payouts_tables
+CREATE TABLE payouts (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT NOT NULL REFERENCES customers (id),
+    amount NUMERIC NOT NULL
+);
+
+CREATE INDEX payouts_customer_idx ON payouts (customer_id);
+
+CREATE VIEW payout_totals AS
+SELECT customer_id, sum(amount) AS total FROM payouts GROUP BY customer_id;
+`
+
+func TestGenerator_PinnedMigration(t *testing.T) {
+	t.Parallel()
+
+	diff, err := differ.New(differ.DefaultOptions()).
+		Compare(&schema.Database{}, parseSchemaSQL(t, pinnedMigrationSchema))
+	require.NoError(t, err)
+
+	require.Len(t, diff.Diagnostics, 1)
+	assert.Equal(t, diag.CodePinnedDependency, diff.Diagnostics[0].Code)
+	assert.Equal(t, "migration payouts_tables also includes table public.customers, "+
+		"which its pinned changes depend on", diff.Diagnostics[0].Message)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(result.Migrations))
+	for _, migration := range result.Migrations {
+		names = append(names, migration.UpFile.FileName)
+	}
+
+	require.Equal(t, []string{
+		"000001_add_table_accounts.up.sql",
+		"000002_payouts_tables.up.sql",
+		"000003_update_view_payout_totals.up.sql",
+	}, names, "unpinned changes batch as usual around the pinned migration")
+
+	pinned := result.Migrations[1]
+	assert.Equal(t, "payouts_tables", pinned.Description)
+	assert.Equal(t, "000002_payouts_tables.down.sql", pinned.DownFile.FileName)
+
+	up := pinned.UpFile.Content
+	assert.Contains(t, up, "CREATE TABLE public.customers (")
+	assert.Contains(t, up, "CREATE INDEX payouts_customer_idx ON public.payouts (customer_id);")
+	assert.NotContains(t, up, "(from line", "SQL that did not come from a file has no locations")
+	assert.Less(t, strings.Index(up, "CREATE TABLE public.customers ("),
+		strings.Index(up, "CREATE TABLE public.payouts ("))
+	assert.Less(t, strings.Index(up, "CREATE TABLE public.payouts ("),
+		strings.Index(up, "CREATE INDEX payouts_customer_idx"))
+
+	assert.Contains(t, result.Migrations[2].UpFile.Content, "CREATE VIEW public.payout_totals AS")
+}
+
+func TestGenerator_ConflictingPinnedMigrations(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSchemaSQL(t, `
+-- pgtofu:migration customers
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+
+-- pgtofu:migration payouts_tables
+CREATE TABLE payouts (id BIGINT PRIMARY KEY, customer_id BIGINT REFERENCES customers (id));
+`)
+
+	_, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table public.payouts is pinned to migration payouts_tables "+
+		"but depends on table public.customers, which is pinned to migration customers")
+}
@@ -0,0 +1,92 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+func TestNewPlanReport(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `
+CREATE TABLE users (id BIGINT PRIMARY KEY, legacy TEXT);`)
+	desired := parseSchemaSQL(t, `
+CREATE TABLE users (id BIGINT PRIMARY KEY, email TEXT);
+CREATE INDEX users_email_idx ON users (email);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	report := generator.NewPlanReport(diff, testOptions())
+
+	assert.Equal(t, generator.PlanReportVersion, report.Version)
+	assert.True(t, report.HasChanges)
+	assert.Equal(t, 3, report.Summary.TotalChanges)
+	assert.Equal(t, map[differ.ChangeSeverity]int{
+		differ.SeveritySafe:                  2,
+		differ.SeverityPotentiallyBreaking:   1,
+		differ.SeverityBreaking:              0,
+		differ.SeverityDataMigrationRequired: 0,
+	}, report.Summary.BySeverity)
+	assert.Equal(t, 1, report.Summary.UnsafeChanges)
+
+	byType := map[differ.ChangeType]generator.PlanChange{}
+	for _, change := range report.Changes {
+		byType[change.Type] = change
+	}
+
+	drop := byType[differ.ChangeTypeDropColumn]
+	assert.Equal(t, "ALTER TABLE public.users DROP COLUMN IF EXISTS legacy;", drop.UpSQL)
+	assert.Equal(t, "ALTER TABLE public.users ADD COLUMN legacy TEXT;", drop.DownSQL)
+	assert.True(t, drop.Unsafe)
+	assert.Equal(t, map[string]any{"table": "public.users", "column": "legacy"}, drop.Details,
+		"schema objects are reported by name")
+
+	index := byType[differ.ChangeTypeAddIndex]
+	assert.Equal(t, []string{byType[differ.ChangeTypeAddColumn].ObjectName}, index.DependsOn)
+	assert.Contains(t, index.UpSQL, "CREATE INDEX users_email_idx ON public.users (email);")
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Contains(t, decoded, "summary")
+	assert.Contains(t, decoded, "changes")
+}
+
+func TestNewPlanReportWithoutChanges(t *testing.T) {
+	t.Parallel()
+
+	schema := parseSchemaSQL(t, `CREATE TABLE users (id BIGINT PRIMARY KEY);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(schema, schema)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(generator.NewPlanReport(diff, testOptions()))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1,
+		"has_changes": false,
+		"summary": {
+			"total_changes": 0,
+			"by_severity": {
+				"SAFE": 0,
+				"POTENTIALLY_BREAKING": 0,
+				"BREAKING": 0,
+				"DATA_MIGRATION_REQUIRED": 0
+			},
+			"by_type": {},
+			"destructive_changes": 0,
+			"unsafe_changes": 0
+		},
+		"changes": [],
+		"diagnostics": []
+	}`, string(data))
+}
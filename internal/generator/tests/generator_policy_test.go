@@ -0,0 +1,105 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const policyDocsTable = `CREATE TABLE docs (id uuid PRIMARY KEY, tenant_id uuid NOT NULL);
+`
+
+func generatePolicyMigration(t *testing.T, current, desired string) (string, string) {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseSchemaSQL(t, current),
+		parseSchemaSQL(t, desired),
+	)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	return result.Migrations[0].UpFile.Content, result.Migrations[0].DownFile.Content
+}
+
+func TestGenerator_PoliciesOfNewTable(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t, ``, policyDocsTable+`
+ALTER TABLE docs ENABLE ROW LEVEL SECURITY;
+CREATE POLICY docs_tenant ON docs USING (tenant_id = current_setting('app.tenant')::uuid);
+CREATE POLICY docs_insert ON docs AS RESTRICTIVE FOR INSERT TO app_user, current_user
+    WITH CHECK (tenant_id IS NOT NULL);`)
+
+	createTable := strings.Index(up, "CREATE TABLE public.docs (")
+	tenant := strings.Index(up,
+		"CREATE POLICY docs_tenant ON public.docs "+
+			"USING (tenant_id = current_setting('app.tenant')::uuid);")
+	insert := strings.Index(up,
+		"CREATE POLICY docs_insert ON public.docs AS RESTRICTIVE FOR INSERT "+
+			"TO app_user, CURRENT_USER WITH CHECK (tenant_id IS NOT NULL);")
+	enable := strings.Index(up, "ALTER TABLE public.docs ENABLE ROW LEVEL SECURITY;")
+
+	require.NotEqual(t, -1, createTable, up)
+	require.NotEqual(t, -1, tenant, up)
+	require.NotEqual(t, -1, insert, up)
+	require.NotEqual(t, -1, enable, up)
+	assert.Less(t, createTable, tenant)
+	assert.Less(t, createTable, insert)
+	assert.Less(t, tenant, enable)
+	assert.Less(t, insert, enable)
+
+	assert.Contains(t, down, "DROP TABLE IF EXISTS public.docs")
+}
+
+func TestGenerator_ModifyPolicyInPlace(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t,
+		policyDocsTable+`CREATE POLICY docs_tenant ON docs TO reader USING (true);`,
+		policyDocsTable+`CREATE POLICY docs_tenant ON docs USING (tenant_id IS NOT NULL);`)
+
+	assert.Contains(t, up,
+		"ALTER POLICY docs_tenant ON public.docs TO PUBLIC USING (tenant_id IS NOT NULL);")
+	assert.NotContains(t, up, "DROP POLICY")
+	assert.Contains(t, down, "ALTER POLICY docs_tenant ON public.docs TO reader USING (true);")
+}
+
+func TestGenerator_ModifyPolicyCommandRecreatesIt(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t,
+		policyDocsTable+`CREATE POLICY docs_tenant ON docs FOR SELECT USING (true);`,
+		policyDocsTable+`CREATE POLICY docs_tenant ON docs FOR UPDATE USING (true);`)
+
+	assert.Contains(t, up, "DROP POLICY IF EXISTS docs_tenant ON public.docs;\n"+
+		"CREATE POLICY docs_tenant ON public.docs FOR UPDATE USING (true);")
+	assert.Contains(t, down, "DROP POLICY IF EXISTS docs_tenant ON public.docs;\n"+
+		"CREATE POLICY docs_tenant ON public.docs FOR SELECT USING (true);")
+}
+
+func TestGenerator_DropPolicyAndDisableRowSecurity(t *testing.T) {
+	t.Parallel()
+
+	up, down := generatePolicyMigration(t, policyDocsTable+`
+ALTER TABLE docs ENABLE ROW LEVEL SECURITY;
+CREATE POLICY docs_tenant ON docs USING (true);`, policyDocsTable)
+
+	disable := strings.Index(up, "ALTER TABLE public.docs DISABLE ROW LEVEL SECURITY;")
+	drop := strings.Index(up, "DROP POLICY IF EXISTS docs_tenant ON public.docs;")
+
+	require.NotEqual(t, -1, disable, up)
+	require.NotEqual(t, -1, drop, up)
+	assert.Less(t, disable, drop)
+
+	assert.Contains(t, down, "CREATE POLICY docs_tenant ON public.docs USING (true);")
+	assert.Contains(t, down, "ALTER TABLE public.docs ENABLE ROW LEVEL SECURITY;")
+}
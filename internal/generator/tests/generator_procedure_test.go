@@ -0,0 +1,86 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const procedureSQL = `CREATE PROCEDURE maintenance.archive_orders(
+    IN cutoff date,
+    INOUT moved bigint
+)
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM orders WHERE created_at < cutoff;
+    GET DIAGNOSTICS moved = ROW_COUNT;
+    COMMIT;
+EXCEPTION WHEN OTHERS THEN
+    ROLLBACK;
+END;
+$$;`
+
+func TestGenerator_Procedure(t *testing.T) {
+	t.Parallel()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{}, parseSchemaSQL(t, procedureSQL))
+	require.NoError(t, err)
+
+	opts := testOptions()
+	opts.TransactionMode = generator.TransactionModeAuto
+	gen := generator.New(opts)
+
+	result, err := gen.Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "CREATE OR REPLACE PROCEDURE maintenance.archive_orders("+
+		"cutoff DATE, INOUT moved BIGINT)\n\nAS $$\n")
+	assert.NotContains(t, up, "RETURNS")
+	assert.Contains(t, up, "$$ LANGUAGE plpgsql;")
+
+	down := result.Migrations[0].DownFile.Content
+	assert.Contains(t, down, "DROP PROCEDURE IF EXISTS maintenance.archive_orders(DATE, BIGINT);")
+}
+
+func TestGenerator_ProcedureBodyDoesNotDecideTransactionMode(t *testing.T) {
+	t.Parallel()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{}, parseSchemaSQL(t, procedureSQL))
+	require.NoError(t, err)
+
+	opts := testOptions()
+	opts.TransactionMode = generator.TransactionModeAuto
+	gen := generator.New(opts)
+
+	builder := generator.NewDDLBuilder(diff, opts.Idempotent)
+
+	statements := make([]generator.DDLStatement, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		stmt, err := builder.BuildUpStatement(change)
+		require.NoError(t, err)
+
+		statements = append(statements, stmt)
+	}
+
+	require.NotEmpty(t, statements)
+	assert.True(t, gen.ShouldUseTransaction(statements),
+		"COMMIT and ROLLBACK inside the body only run when the procedure is called")
+
+	result, err := gen.Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "BEGIN;\n")
+	assert.Contains(t, up, "\nCOMMIT;\n")
+}
@@ -0,0 +1,117 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func recreateTableDiff() *differ.DiffResult {
+	current := &schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "events",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "BIGINT", Position: 1},
+			{Name: "kind", DataType: "TEXT", Position: 2},
+			{Name: "payload", DataType: "JSON", Position: 3},
+		},
+	}
+
+	desired := &schema.Table{
+		Schema: schema.DefaultSchema,
+		Name:   "events",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "BIGINT", Position: 1},
+			{Name: "category", DataType: "TEXT", Position: 2},
+		},
+		Constraints: []schema.Constraint{
+			{Name: "events_pkey", Type: schema.ConstraintPrimaryKey, Columns: []string{"id"}},
+		},
+		Indexes: []schema.Index{
+			{
+				Schema:    schema.DefaultSchema,
+				Name:      "idx_events_category",
+				TableName: "events",
+				Columns:   []string{"category"},
+			},
+		},
+	}
+
+	return &differ.DiffResult{
+		Current: &schema.Database{Tables: []schema.Table{*current}},
+		Desired: &schema.Database{Tables: []schema.Table{*desired}},
+		Changes: []differ.Change{
+			{
+				Type:        differ.ChangeTypeRecreateTable,
+				Severity:    differ.SeverityDataMigrationRequired,
+				Description: "Recreate table: public.events (replaces 4 changes)",
+				ObjectType:  "table",
+				ObjectName:  "public.events",
+				Details: map[string]any{
+					"current": current,
+					"desired": desired,
+					"reason":  "3 of 4 columns change (75%), above the 50% column change threshold",
+				},
+			},
+		},
+	}
+}
+
+func TestGenerator_RecreateTableTemplate(t *testing.T) {
+	t.Parallel()
+
+	result, err := generator.New(testOptions()).Generate(recreateTableDiff())
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+
+	assert.Contains(t, up, "-- Reason: 3 of 4 columns change (75%)")
+	assert.Contains(t, up, "-- CREATE TABLE public.events_new (")
+	assert.Contains(t, up, "-- INSERT INTO public.events_new (id)\n-- SELECT id\n-- FROM public.events;")
+	assert.Contains(t, up, "-- UNMATCHED new columns (no source; add them to the copy or rely "+
+		"on defaults): category")
+	assert.Contains(t, up, "-- UNMATCHED old columns (not copied, data is lost): kind, payload")
+	assert.Contains(t, up, "-- CREATE INDEX idx_events_category ON public.events_new (category);")
+	assert.Contains(t, up, "-- ALTER TABLE public.events RENAME TO events_old;")
+	assert.Contains(t, up, "-- ALTER TABLE public.events_new RENAME TO events;")
+	assert.NotContains(t, up, "ALTER INDEX", "no current index name is reused")
+
+	for line := range strings.SplitSeq(up, "\n") {
+		if line == "" || line == "BEGIN;" || line == "COMMIT;" {
+			continue
+		}
+
+		assert.True(t, strings.HasPrefix(line, "--"), "template line is not commented: %q", line)
+	}
+
+	assert.Equal(t, 1, result.ManualRollbacks)
+	assert.Contains(t, result.Migrations[0].DownFile.Content,
+		"-- ALTER TABLE public.events_old RENAME TO events;")
+}
+
+func TestGenerator_RecreateTableRenamesCollidingIndexes(t *testing.T) {
+	t.Parallel()
+
+	diff := recreateTableDiff()
+	current := diff.Changes[0].Details["current"].(*schema.Table) //nolint:forcetypeassert
+	current.Constraints = []schema.Constraint{
+		{Name: "events_pkey", Type: schema.ConstraintPrimaryKey, Columns: []string{"id"}},
+	}
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	up := result.Migrations[0].UpFile.Content
+	renameIdx := strings.Index(up, "-- ALTER INDEX public.events_pkey RENAME TO events_pkey_old;")
+	createIdx := strings.Index(up, "-- CREATE TABLE public.events_new (")
+
+	require.NotEqual(t, -1, renameIdx)
+	assert.Less(t, renameIdx, createIdx)
+}
@@ -0,0 +1,116 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDDLBuilder_RefreshPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		remove = "SELECT remove_continuous_aggregate_policy(" +
+			"'public.metrics_hourly', if_exists => true);"
+		add = "SELECT add_continuous_aggregate_policy('public.metrics_hourly',\n"
+	)
+
+	hourly := &schema.RefreshPolicy{
+		StartOffset:      "3 days",
+		EndOffset:        "1 hour",
+		ScheduleInterval: "1 hour",
+	}
+	addHourly := add +
+		"    start_offset => INTERVAL '3 days',\n" +
+		"    end_offset => INTERVAL '1 hour',\n" +
+		"    schedule_interval => INTERVAL '1 hour');"
+
+	tests := []struct {
+		name     string
+		current  *schema.RefreshPolicy
+		desired  *schema.RefreshPolicy
+		wantType differ.ChangeType
+		wantUp   string
+		wantDown string
+	}{
+		{
+			name:    "schedule changed",
+			current: hourly,
+			desired: &schema.RefreshPolicy{
+				StartOffset:      "3 days",
+				EndOffset:        "1 hour",
+				ScheduleInterval: "30 minutes",
+			},
+			wantType: differ.ChangeTypeModifyRefreshPolicy,
+			wantUp: remove + "\n\n" + add +
+				"    start_offset => INTERVAL '3 days',\n" +
+				"    end_offset => INTERVAL '1 hour',\n" +
+				"    schedule_interval => INTERVAL '30 minutes');",
+			wantDown: remove + "\n\n" + addHourly,
+		},
+		{
+			name: "policy added",
+			desired: &schema.RefreshPolicy{
+				StartOffset:      "7 days",
+				ScheduleInterval: "1 hour",
+				InitialStart:     "'2026-01-01 00:00:00+00'::timestamptz",
+			},
+			wantType: differ.ChangeTypeAddRefreshPolicy,
+			wantUp: add +
+				"    start_offset => INTERVAL '7 days',\n" +
+				"    end_offset => NULL,\n" +
+				"    schedule_interval => INTERVAL '1 hour',\n" +
+				"    initial_start => '2026-01-01 00:00:00+00'::timestamptz);",
+			wantDown: remove,
+		},
+		{
+			name:     "policy dropped",
+			current:  hourly,
+			wantType: differ.ChangeTypeDropRefreshPolicy,
+			wantUp:   remove,
+			wantDown: addHourly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			database := func(policy *schema.RefreshPolicy) *schema.Database {
+				return &schema.Database{
+					ContinuousAggregates: []schema.ContinuousAggregate{{
+						Schema:           schema.DefaultSchema,
+						ViewName:         "metrics_hourly",
+						HypertableSchema: schema.DefaultSchema,
+						HypertableName:   "metrics",
+						Query:            "SELECT time_bucket('1 hour', time) FROM metrics",
+						RefreshPolicy:    policy,
+					}},
+				}
+			}
+
+			result, err := differ.New(differ.DefaultOptions()).Compare(
+				database(tt.current), database(tt.desired))
+			require.NoError(t, err)
+			require.Len(t, result.Changes, 1)
+
+			change := result.Changes[0]
+			require.Equal(t, tt.wantType, change.Type)
+
+			builder := generator.NewDDLBuilder(result, true)
+
+			upStmt, err := builder.BuildUpStatement(change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, upStmt.SQL)
+
+			downStmt, err := builder.BuildDownStatement(change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDown, downStmt.SQL)
+		})
+	}
+}
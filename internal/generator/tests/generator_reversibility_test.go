@@ -0,0 +1,257 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const reversibilityBaseSQL = `
+CREATE SCHEMA app;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE app.users (
+    id BIGINT PRIMARY KEY,
+    email TEXT NOT NULL,
+    note TEXT,
+    score TEXT,
+    nickname TEXT,
+    CONSTRAINT users_email_check CHECK (email <> '')
+);
+COMMENT ON TABLE app.users IS 'users';
+COMMENT ON COLUMN app.users.email IS 'login';
+CREATE INDEX users_email_idx ON app.users (email);
+
+CREATE TABLE app.events (
+    id BIGINT NOT NULL,
+    created_at DATE NOT NULL
+) PARTITION BY RANGE (created_at);
+CREATE TABLE app.events_2024 PARTITION OF app.events
+    FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+
+CREATE VIEW app.active_users AS SELECT id, email FROM app.users;
+CREATE MATERIALIZED VIEW app.user_counts AS SELECT count(*) AS total FROM app.users;
+
+CREATE FUNCTION app.touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER users_touch BEFORE UPDATE ON app.users
+    FOR EACH ROW EXECUTE FUNCTION app.touch();
+
+CREATE TABLE app.metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id INT NOT NULL,
+    value DOUBLE PRECISION
+);
+SELECT create_hypertable('app.metrics', 'time');
+SELECT add_dimension('app.metrics', 'device_id', number_partitions => 4);
+ALTER TABLE app.metrics SET (timescaledb.compress, timescaledb.compress_segmentby = 'device_id');
+SELECT add_compression_policy('app.metrics', INTERVAL '7 days');
+SELECT add_retention_policy('app.metrics', INTERVAL '90 days');
+
+CREATE MATERIALIZED VIEW app.metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, avg(value) AS avg_value
+FROM app.metrics
+GROUP BY 1;
+`
+
+func parseReversibilitySchema(t *testing.T, replacer *strings.Replacer) *schema.Database {
+	t.Helper()
+
+	sql := reversibilityBaseSQL
+	if replacer != nil {
+		sql = replacer.Replace(sql)
+	}
+
+	p := parser.New()
+	db := &schema.Database{}
+	require.NoError(t, p.ParseSQL(sql, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func TestDDLBuilder_DownReversibility(t *testing.T) {
+	t.Parallel()
+
+	base := parseReversibilitySchema(t, nil)
+	modified := parseReversibilitySchema(t, strings.NewReplacer(
+		"score TEXT", "score INTEGER",
+		"note TEXT", "note TEXT NOT NULL",
+		"email TEXT NOT NULL", "email TEXT NOT NULL DEFAULT 'none'",
+		"IS 'login'", "IS 'sign-in'",
+		"IS 'users'", "IS 'accounts'",
+		"(email);", "(email, id);",
+		"email <> ''", "length(email) > 3",
+		"SELECT id, email FROM app.users", "SELECT id FROM app.users",
+		"count(*) AS total", "count(id) AS total",
+		"RETURN NEW;", "RETURN OLD;",
+		"BEFORE UPDATE", "AFTER UPDATE",
+		"compress_segmentby = 'device_id'", "compress_segmentby = 'device_id, value'",
+		"INTERVAL '7 days'", "INTERVAL '14 days'",
+		"number_partitions => 4", "number_partitions => 8",
+		"avg(value)", "max(value)",
+	))
+	stripped := parseReversibilitySchema(t, strings.NewReplacer(
+		"    nickname TEXT,\n    CONSTRAINT users_email_check CHECK (email <> '')\n", "",
+		"    score TEXT,\n", "    score TEXT\n",
+		"CREATE TABLE app.events_2024 PARTITION OF app.events\n"+
+			"    FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');", "",
+		"SELECT add_dimension('app.metrics', 'device_id', number_partitions => 4);", "",
+		"ALTER TABLE app.metrics SET (timescaledb.compress, "+
+			"timescaledb.compress_segmentby = 'device_id');", "",
+		"SELECT add_compression_policy('app.metrics', INTERVAL '7 days');", "",
+		"SELECT add_retention_policy('app.metrics', INTERVAL '90 days');", "",
+	))
+
+	got := make(map[differ.ChangeType]generator.DDLStatement)
+
+	for _, pair := range [][2]*schema.Database{
+		{&schema.Database{}, base},
+		{base, &schema.Database{}},
+		{base, modified},
+		{base, stripped},
+		{stripped, base},
+	} {
+		result, err := differ.New(differ.DefaultOptions()).Compare(pair[0], pair[1])
+		require.NoError(t, err)
+
+		builder := generator.NewDDLBuilder(result, true)
+
+		for _, change := range result.Changes {
+			stmt, err := builder.BuildDownStatement(change)
+			if !assert.NoError(t, err, change.Description) {
+				continue
+			}
+
+			if prev, ok := got[change.Type]; !ok || stmt.Reversibility > prev.Reversibility {
+				got[change.Type] = stmt
+			}
+		}
+	}
+
+	structureOnly := generator.ReversibilityStructureOnly
+	manual := generator.ReversibilityManual
+
+	want := map[differ.ChangeType]generator.Reversibility{
+		differ.ChangeTypeAddSchema:                 generator.ReversibilityFull,
+		differ.ChangeTypeDropSchema:                generator.ReversibilityFull,
+		differ.ChangeTypeAddExtension:              generator.ReversibilityFull,
+		differ.ChangeTypeDropExtension:             structureOnly,
+		differ.ChangeTypeAddTable:                  generator.ReversibilityFull,
+		differ.ChangeTypeDropTable:                 structureOnly,
+		differ.ChangeTypeModifyTableComment:        generator.ReversibilityFull,
+		differ.ChangeTypeAddColumn:                 generator.ReversibilityFull,
+		differ.ChangeTypeDropColumn:                structureOnly,
+		differ.ChangeTypeModifyColumnType:          structureOnly,
+		differ.ChangeTypeModifyColumnNullability:   generator.ReversibilityFull,
+		differ.ChangeTypeModifyColumnDefault:       generator.ReversibilityFull,
+		differ.ChangeTypeModifyColumnComment:       generator.ReversibilityFull,
+		differ.ChangeTypeAddConstraint:             generator.ReversibilityFull,
+		differ.ChangeTypeDropConstraint:            generator.ReversibilityFull,
+		differ.ChangeTypeModifyConstraint:          generator.ReversibilityFull,
+		differ.ChangeTypeAddIndex:                  generator.ReversibilityFull,
+		differ.ChangeTypeDropIndex:                 generator.ReversibilityFull,
+		differ.ChangeTypeModifyIndex:               generator.ReversibilityFull,
+		differ.ChangeTypeAddPartition:              generator.ReversibilityFull,
+		differ.ChangeTypeDropPartition:             structureOnly,
+		differ.ChangeTypeAddView:                   generator.ReversibilityFull,
+		differ.ChangeTypeDropView:                  generator.ReversibilityFull,
+		differ.ChangeTypeAddMaterializedView:       generator.ReversibilityFull,
+		differ.ChangeTypeDropMaterializedView:      generator.ReversibilityFull,
+		differ.ChangeTypeAddFunction:               generator.ReversibilityFull,
+		differ.ChangeTypeDropFunction:              generator.ReversibilityFull,
+		differ.ChangeTypeModifyFunction:            generator.ReversibilityFull,
+		differ.ChangeTypeAddTrigger:                generator.ReversibilityFull,
+		differ.ChangeTypeDropTrigger:               generator.ReversibilityFull,
+		differ.ChangeTypeModifyTrigger:             generator.ReversibilityFull,
+		differ.ChangeTypeAddHypertable:             manual,
+		differ.ChangeTypeDropHypertable:            manual,
+		differ.ChangeTypeAddDimension:              manual,
+		differ.ChangeTypeDropDimension:             manual,
+		differ.ChangeTypeModifyDimension:           manual,
+		differ.ChangeTypeAddCompressionPolicy:      generator.ReversibilityFull,
+		differ.ChangeTypeDropCompressionPolicy:     generator.ReversibilityFull,
+		differ.ChangeTypeModifyCompressionPolicy:   generator.ReversibilityFull,
+		differ.ChangeTypeModifyCompressionSchedule: generator.ReversibilityFull,
+		differ.ChangeTypeAddRetentionPolicy:        structureOnly,
+		differ.ChangeTypeDropRetentionPolicy:       generator.ReversibilityFull,
+		differ.ChangeTypeAddContinuousAggregate:    generator.ReversibilityFull,
+		differ.ChangeTypeDropContinuousAggregate:   structureOnly,
+		differ.ChangeTypeModifyContinuousAggregate: generator.ReversibilityFull,
+	}
+
+	for changeType, reversibility := range want {
+		stmt, ok := got[changeType]
+		if !assert.True(t, ok, "no down statement built for %s", changeType) {
+			continue
+		}
+
+		assert.Equal(t, reversibility, stmt.Reversibility, changeType)
+
+		if reversibility == generator.ReversibilityFull {
+			assert.Empty(t, stmt.RollbackNote, changeType)
+		} else {
+			assert.NotEmpty(t, stmt.RollbackNote, changeType)
+		}
+	}
+}
+
+func TestDDLBuilder_WideningTypeChangeIsReversible(t *testing.T) {
+	t.Parallel()
+
+	columns := func(dataType string) []schema.Column {
+		return []schema.Column{{Name: "id", DataType: dataType, Position: 1}}
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(
+		&schema.Database{Tables: []schema.Table{
+			{Schema: schema.DefaultSchema, Name: "users", Columns: columns("integer")},
+		}},
+		&schema.Database{Tables: []schema.Table{
+			{Schema: schema.DefaultSchema, Name: "users", Columns: columns("bigint")},
+		}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+
+	stmt, err := generator.NewDDLBuilder(result, true).BuildDownStatement(result.Changes[0])
+	require.NoError(t, err)
+	assert.Equal(t, generator.ReversibilityFull, stmt.Reversibility)
+}
+
+func TestGenerator_LossyDownMigrationHeader(t *testing.T) {
+	t.Parallel()
+
+	current := parseReversibilitySchema(t, nil)
+	desired := parseReversibilitySchema(t, strings.NewReplacer(",\n    nickname TEXT", ""))
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	genResult, err := generator.New(testOptions()).Generate(result)
+	require.NoError(t, err)
+	require.Len(t, genResult.Migrations, 1)
+
+	note := "-- ROLLBACK NOTE: restores structure only; " +
+		"data in dropped column app.users.nickname is not recoverable"
+
+	down := genResult.Migrations[0].DownFile
+	assert.Equal(t, generator.ReversibilityStructureOnly, down.Reversibility)
+	assert.Contains(t, down.Content, "-- Reversibility: restores structure only\n"+note+"\n")
+	assert.Equal(t, 2, strings.Count(down.Content, note))
+	assert.NotContains(t, genResult.Migrations[0].UpFile.Content, "ROLLBACK NOTE")
+
+	assert.Equal(t, 1, genResult.LossyRollbacks)
+	assert.Equal(t, 0, genResult.ManualRollbacks)
+	assert.Contains(t, genResult.Summary(), "Structure-only rollbacks: 1")
+}
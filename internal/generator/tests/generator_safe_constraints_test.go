@@ -0,0 +1,117 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const safeConstraintsCurrent = `
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+CREATE TABLE orders (id BIGINT PRIMARY KEY, customer_id BIGINT, total INTEGER, status TEXT);`
+
+func generateSafeConstraints(t *testing.T, desired string) *generator.GenerateResult {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseSchemaSQL(t, safeConstraintsCurrent), parseSchemaSQL(t, desired))
+	require.NoError(t, err)
+
+	opts := testOptions()
+	opts.SafeConstraintMode = true
+
+	result, err := generator.New(opts).Generate(diff)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestGenerator_SafeConstraintModeCheckAndForeignKey(t *testing.T) {
+	t.Parallel()
+
+	result := generateSafeConstraints(t, `
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+CREATE TABLE orders (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT,
+    total INTEGER,
+    status TEXT,
+    CONSTRAINT orders_total_check CHECK (total >= 0),
+    CONSTRAINT orders_customer_fk FOREIGN KEY (customer_id) REFERENCES customers (id)
+);`)
+	require.Len(t, result.Migrations, 2)
+
+	add := result.Migrations[0]
+	assert.Contains(t, add.UpFile.Content, "ALTER TABLE public.orders ADD CONSTRAINT "+
+		"orders_total_check CHECK (total >= 0) NOT VALID;")
+	assert.Contains(t, add.UpFile.Content, "ALTER TABLE public.orders ADD CONSTRAINT "+
+		"orders_customer_fk FOREIGN KEY (customer_id) REFERENCES public.customers (id) NOT VALID;")
+	assert.Contains(t, add.DownFile.Content,
+		"ALTER TABLE public.orders DROP CONSTRAINT IF EXISTS orders_total_check;")
+
+	validate := result.Migrations[1]
+	assert.Equal(t, add.Version+1, validate.Version)
+	assertOrdered(t, validate.UpFile.Content,
+		"BEGIN;",
+		"ALTER TABLE public.orders VALIDATE CONSTRAINT orders_",
+		"ALTER TABLE public.orders VALIDATE CONSTRAINT orders_",
+		"COMMIT;",
+	)
+	assert.NotContains(t, validate.UpFile.Content, "NOT VALID")
+	assert.Contains(t, validate.UpFile.Content,
+		"-- Strongest lock: SHARE UPDATE EXCLUSIVE on public.orders (scans the table)\n")
+	assert.Contains(t, validate.UpFile.FileName, "validate_constraints")
+	assert.NotContains(t, validate.DownFile.Content, "DROP CONSTRAINT",
+		"the down migration of the NOT VALID step drops the constraints")
+}
+
+func TestGenerator_SafeConstraintModeNotNull(t *testing.T) {
+	t.Parallel()
+
+	result := generateSafeConstraints(t, `
+CREATE TABLE customers (id BIGINT PRIMARY KEY);
+CREATE TABLE orders (
+    id BIGINT PRIMARY KEY, customer_id BIGINT, total INTEGER, status TEXT NOT NULL
+);`)
+	require.Len(t, result.Migrations, 2)
+
+	add := result.Migrations[0]
+	assert.Contains(t, add.UpFile.Content, "ALTER TABLE public.orders ADD CONSTRAINT "+
+		"orders_status_not_null_check CHECK (status IS NOT NULL) NOT VALID;")
+	assert.NotContains(t, add.UpFile.Content, "SET NOT NULL")
+	assert.Contains(t, add.DownFile.Content,
+		"ALTER TABLE public.orders DROP CONSTRAINT IF EXISTS orders_status_not_null_check;")
+
+	validate := result.Migrations[1]
+	assertOrdered(t, validate.UpFile.Content,
+		"ALTER TABLE public.orders VALIDATE CONSTRAINT orders_status_not_null_check;",
+		"ALTER TABLE public.orders ALTER COLUMN status SET NOT NULL;",
+		"ALTER TABLE public.orders DROP CONSTRAINT IF EXISTS orders_status_not_null_check;",
+	)
+	assert.Contains(t, validate.UpFile.Content,
+		"-- Strongest lock: ACCESS EXCLUSIVE on public.orders\n",
+		"SET NOT NULL is proven by the validated check and does not scan")
+	assert.Contains(t, validate.UpFile.FileName, "validate_constraints")
+	assert.Contains(t, validate.DownFile.Content,
+		"ALTER TABLE public.orders ALTER COLUMN status DROP NOT NULL;")
+}
+
+func TestGenerator_SafeConstraintModeKeepsSimpleFormForNewTables(t *testing.T) {
+	t.Parallel()
+
+	result := generateSafeConstraints(t, safeConstraintsCurrent+`
+CREATE TABLE invoices (id BIGINT PRIMARY KEY, order_id BIGINT, amount INTEGER);
+ALTER TABLE invoices ADD CONSTRAINT invoices_amount_check CHECK (amount > 0);
+ALTER TABLE invoices ADD CONSTRAINT invoices_order_fk
+    FOREIGN KEY (order_id) REFERENCES orders (id);`)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.NotContains(t, up, "NOT VALID")
+	assert.NotContains(t, up, "VALIDATE CONSTRAINT")
+	assert.Contains(t, up, "invoices_amount_check CHECK (amount > 0)")
+}
@@ -0,0 +1,116 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func safeUniqueDiff(t *testing.T, hypertable bool) *differ.DiffResult {
+	t.Helper()
+
+	columns := []schema.Column{
+		{Name: "id", DataType: "bigint", IsNullable: false, Position: 1},
+		{Name: "email", DataType: "text", IsNullable: false, Position: 2},
+	}
+
+	current := &schema.Database{
+		Tables: []schema.Table{
+			{Schema: schema.DefaultSchema, Name: "users", Columns: columns},
+		},
+	}
+
+	desired := &schema.Database{
+		Tables: []schema.Table{
+			{
+				Schema:  schema.DefaultSchema,
+				Name:    "users",
+				Columns: columns,
+				Constraints: []schema.Constraint{
+					{
+						Name:    "users_email_key",
+						Type:    schema.ConstraintUnique,
+						Columns: []string{"email"},
+					},
+				},
+			},
+		},
+	}
+
+	if hypertable {
+		ht := schema.Hypertable{
+			Schema:         schema.DefaultSchema,
+			TableName:      "users",
+			TimeColumnName: "id",
+		}
+		current.Hypertables = []schema.Hypertable{ht}
+		desired.Hypertables = []schema.Hypertable{ht}
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestGenerator_SafeUniqueConstraints(t *testing.T) {
+	t.Parallel()
+
+	opts := testOptions()
+	opts.SafeUniqueConstraints = true
+
+	result, err := generator.New(opts).Generate(safeUniqueDiff(t, false))
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 2)
+
+	build := result.Migrations[0]
+	assert.Contains(t, build.UpFile.Content,
+		"CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS users_email_key ON public.users (email);")
+	assert.NotContains(t, build.UpFile.Content, "BEGIN;")
+	assert.Contains(t, build.DownFile.Content,
+		"DROP INDEX CONCURRENTLY IF EXISTS public.users_email_key;")
+	assert.NotContains(t, build.DownFile.Content, "BEGIN;")
+
+	promote := result.Migrations[1]
+	assert.Equal(t, build.Version+1, promote.Version)
+	assert.Contains(t, promote.UpFile.Content,
+		"ALTER TABLE public.users ADD CONSTRAINT users_email_key UNIQUE USING INDEX users_email_key;")
+	assert.Contains(t, promote.UpFile.Content, "BEGIN;")
+	assert.Contains(t, promote.DownFile.Content,
+		"ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_email_key;")
+}
+
+func TestGenerator_SafeUniqueConstraintsFallbacks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		safe       bool
+		hypertable bool
+	}{
+		{name: "option disabled", safe: false, hypertable: false},
+		{name: "hypertable excluded", safe: true, hypertable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testOptions()
+			opts.SafeUniqueConstraints = tt.safe
+
+			result, err := generator.New(opts).Generate(safeUniqueDiff(t, tt.hypertable))
+			require.NoError(t, err)
+			require.Len(t, result.Migrations, 1)
+
+			content := result.Migrations[0].UpFile.Content
+			assert.NotContains(t, content, "CONCURRENTLY")
+			assert.Contains(t, content, "ADD CONSTRAINT users_email_key UNIQUE (email);")
+		})
+	}
+}
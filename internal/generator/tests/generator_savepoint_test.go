@@ -0,0 +1,167 @@
+package generator_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const (
+	savepointCurrent = `CREATE TABLE orders (id BIGINT PRIMARY KEY, total NUMERIC);`
+	savepointDesired = `CREATE TABLE orders (
+    id BIGINT PRIMARY KEY,
+    total NUMERIC,
+    note TEXT,
+    CONSTRAINT orders_total_check CHECK (total >= 0)
+);
+
+CREATE FUNCTION archive_orders() RETURNS void LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM orders WHERE total = 0;
+    EXECUTE 'DO $do$ BEGIN COMMIT; END $do$';
+END;
+$$;`
+)
+
+func generateSavepointFiles(
+	t *testing.T,
+	configure func(*generator.Options),
+) []*generator.MigrationFile {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseSchemaSQL(t, savepointCurrent),
+		parseSchemaSQL(t, savepointDesired),
+	)
+	require.NoError(t, err)
+
+	opts := testOptions()
+	opts.EmitSavepoints = true
+	opts.OmitTimestamp = true
+	configure(opts)
+
+	result, err := generator.New(opts).Generate(diff)
+	require.NoError(t, err)
+
+	var files []*generator.MigrationFile
+
+	for _, migration := range result.Migrations {
+		files = append(files, migration.UpFile)
+		if migration.DownFile != nil {
+			files = append(files, migration.DownFile)
+		}
+	}
+
+	return files
+}
+
+func TestGenerator_EmitSavepoints(t *testing.T) {
+	t.Parallel()
+
+	t.Run("each statement wrapped and numbered", func(t *testing.T) {
+		t.Parallel()
+
+		files := generateSavepointFiles(t, func(*generator.Options) {})
+		require.NotEmpty(t, files)
+
+		for _, file := range files {
+			var got, want []string
+
+			for line := range strings.Lines(file.Content) {
+				if strings.Contains(line, "SAVEPOINT") && !strings.HasPrefix(line, "--") {
+					got = append(got, line)
+				}
+			}
+
+			for n := 1; len(want) < len(got); n++ {
+				name := "pgtofu_" + strconv.Itoa(n)
+				want = append(want, "SAVEPOINT "+name+";\n", "RELEASE SAVEPOINT "+name+";\n")
+			}
+
+			assert.NotEmpty(t, got, file.FileName)
+			assert.Equal(t, want, got, file.FileName)
+		}
+
+		up := files[0].Content
+		assert.Contains(t, up, "BEGIN;\n\n-- pgtofu_1: Add function archive_orders\n"+
+			"SAVEPOINT pgtofu_1;\nCREATE OR REPLACE FUNCTION")
+		assert.Contains(t, up, "-- pgtofu_3: Add constraint orders.orders_total_check\n"+
+			"SAVEPOINT pgtofu_3;\nALTER TABLE public.orders ADD CONSTRAINT orders_total_check")
+		assert.Contains(t, up, "RELEASE SAVEPOINT pgtofu_3;\n\nCOMMIT;\n")
+		assert.Contains(t, up,
+			"    EXECUTE 'DO $do$ BEGIN COMMIT; END $do$';\nEND;\n$$ LANGUAGE plpgsql;\n"+
+				"RELEASE SAVEPOINT pgtofu_1;\n",
+			"transaction control inside a function body is left as written")
+	})
+
+	t.Run("deterministic names", func(t *testing.T) {
+		t.Parallel()
+
+		first := generateSavepointFiles(t, func(*generator.Options) {})
+		second := generateSavepointFiles(t, func(*generator.Options) {})
+
+		require.Len(t, second, len(first))
+
+		for i := range first {
+			assert.Equal(t, first[i].Content, second[i].Content)
+		}
+	})
+
+	t.Run("without comments", func(t *testing.T) {
+		t.Parallel()
+
+		files := generateSavepointFiles(t, func(opts *generator.Options) {
+			opts.IncludeComments = false
+		})
+
+		up := files[0].Content
+		assert.True(t, strings.HasPrefix(up, "BEGIN;\n\nSAVEPOINT pgtofu_1;\n"), up)
+		assert.NotContains(t, up, "-- ")
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Parallel()
+
+		files := generateSavepointFiles(t, func(opts *generator.Options) {
+			opts.EmitSavepoints = false
+		})
+
+		for _, file := range files {
+			assert.NotContains(t, file.Content, "SAVEPOINT")
+			assert.NotContains(t, file.Content, "pgtofu_1")
+		}
+	})
+
+	t.Run("no-op outside a transaction", func(t *testing.T) {
+		t.Parallel()
+
+		files := generateSavepointFiles(t, func(opts *generator.Options) {
+			opts.TransactionMode = generator.TransactionModeNever
+		})
+
+		for _, file := range files {
+			assert.NotContains(t, file.Content, "SAVEPOINT")
+			assert.NotContains(t, file.Content, "pgtofu_1")
+		}
+	})
+
+	t.Run("goose sections", func(t *testing.T) {
+		t.Parallel()
+
+		files := generateSavepointFiles(t, func(opts *generator.Options) {
+			opts.OutputFormat = generator.OutputFormatGoose
+		})
+
+		up := files[0].Content
+		assert.Contains(t, up, "-- +goose Up\n-- pgtofu_1: ")
+		assert.Contains(t, up, "-- +goose Down\n-- pgtofu_1: ")
+		assert.Contains(t, up, "SAVEPOINT pgtofu_1;\n-- +goose StatementBegin\n")
+		assert.Contains(t, up, "-- +goose StatementEnd\nRELEASE SAVEPOINT pgtofu_1;\n")
+	})
+}
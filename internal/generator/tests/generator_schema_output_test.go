@@ -0,0 +1,128 @@
+package generator_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const twoSchemaPlan = `
+CREATE SCHEMA app;
+CREATE SCHEMA billing;
+
+CREATE TABLE billing.accounts (id BIGINT PRIMARY KEY);
+
+CREATE TABLE app.orders (
+    id BIGINT PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES billing.accounts (id)
+);
+`
+
+func TestGenerator_PartitionOutputBySchema(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "app", "000004_add_table_users.up.sql"), nil, 0o644,
+	))
+
+	diff, err := differ.New(differ.DefaultOptions()).
+		Compare(&schema.Database{}, parseSchemaSQL(t, twoSchemaPlan))
+	require.NoError(t, err)
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = dir
+	opts.OmitTimestamp = true
+	opts.PartitionOutputBySchema = true
+
+	result, err := generator.New(opts).Generate(diff)
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(result.Migrations))
+	for _, migration := range result.Migrations {
+		paths = append(paths, migration.Path(migration.UpFile))
+	}
+
+	require.Equal(t, []string{
+		"_global/000001_app_and_billing_schema_changes.up.sql",
+		"billing/000001_billing_add_table_accounts.up.sql",
+		"app/000005_app_add_table_orders.up.sql",
+	}, paths, "each subdirectory is numbered on its own, continuing app's existing files")
+	assert.Equal(t, "billing", result.Migrations[1].Subdirectory)
+
+	written := make(map[*generator.MigrationFile]string)
+
+	for _, migration := range result.Migrations {
+		for _, file := range []*generator.MigrationFile{migration.UpFile, migration.DownFile} {
+			content, err := os.ReadFile(filepath.Join(dir, migration.Subdirectory, file.FileName))
+			require.NoError(t, err)
+			assert.Empty(t, file.Content, "written files are not kept in memory")
+			assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(content)), file.Checksum)
+
+			written[file] = string(content)
+		}
+	}
+
+	billing, app := result.Migrations[1], result.Migrations[2]
+
+	for _, file := range []*generator.MigrationFile{billing.UpFile, billing.DownFile} {
+		assert.Contains(t, written[file], "-- CROSS-SCHEMA DEPENDENCY: "+
+			"app/000005_app_add_table_orders depends on this migration, but another schema's pipeline may apply it first\n")
+	}
+
+	for _, file := range []*generator.MigrationFile{app.UpFile, app.DownFile} {
+		assert.Contains(t, written[file], "-- CROSS-SCHEMA DEPENDENCY: depends on "+
+			"billing/000001_billing_add_table_accounts, which another schema's pipeline may apply "+
+			"after this one\n")
+	}
+
+	assert.NotContains(t, written[result.Migrations[0].UpFile], "CROSS-SCHEMA DEPENDENCY",
+		"shared migrations are applied first and are not noted")
+
+	var warnings []diag.Warning
+
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodeCrossSchemaDependency {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "migration app/000005_app_add_table_orders depends on "+
+		"billing/000001_billing_add_table_accounts in another schema's directory; apply "+
+		"billing/000001_billing_add_table_accounts first", warnings[0].Message)
+}
+
+func TestGenerator_PartitionOutputBySchemaSharedSubdirectory(t *testing.T) {
+	t.Parallel()
+
+	diff, err := differ.New(differ.DefaultOptions()).
+		Compare(&schema.Database{}, parseSchemaSQL(t, twoSchemaPlan))
+	require.NoError(t, err)
+
+	opts := testOptions()
+	opts.PartitionOutputBySchema = true
+	opts.SharedSubdirectory = "shared"
+
+	result, err := generator.New(opts).Generate(diff)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Migrations)
+	assert.Equal(t, "shared", result.Migrations[0].Subdirectory)
+
+	opts.SharedSubdirectory = "../shared"
+
+	_, err = generator.New(opts).Generate(diff)
+	require.ErrorContains(t, err,
+		`shared subdirectory must be a single directory name, got "../shared"`)
+}
@@ -114,3 +114,81 @@ func TestGenerator_SerialColumn_AllTypes(t *testing.T) {
 		})
 	}
 }
+
+func generateMigrationSQL(
+	t *testing.T,
+	current, desired string,
+	opts *differ.Options,
+) (string, string) {
+	t.Helper()
+
+	diff, err := differ.New(opts).Compare(parseSchemaSQL(t, current), parseSchemaSQL(t, desired))
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	return result.Migrations[0].UpFile.Content, result.Migrations[0].DownFile.Content
+}
+
+func TestGenerator_SerialSequenceResync(t *testing.T) {
+	t.Parallel()
+
+	const (
+		withSerial = `CREATE TABLE steps (id BIGSERIAL PRIMARY KEY, name TEXT);`
+		noSerial   = `CREATE TABLE steps (name TEXT);`
+		resync     = "SELECT setval(pg_get_serial_sequence('public.steps', 'id'), " +
+			"COALESCE((SELECT MAX(id) FROM public.steps), 1));"
+	)
+
+	t.Run("new table", func(t *testing.T) {
+		t.Parallel()
+
+		up, down := generateMigrationSQL(t, "", withSerial, differ.DefaultOptions())
+		require.NotContains(t, up, "setval")
+		require.NotContains(t, down, "setval")
+	})
+
+	t.Run("serial column added to an existing table", func(t *testing.T) {
+		t.Parallel()
+
+		up, down := generateMigrationSQL(t, noSerial, withSerial, differ.DefaultOptions())
+		require.Contains(t, up, "ADD COLUMN id BIGSERIAL NOT NULL;\n\n"+resync)
+		require.NotContains(t, down, "setval")
+	})
+
+	t.Run("down migration restores a dropped serial column", func(t *testing.T) {
+		t.Parallel()
+
+		up, down := generateMigrationSQL(t, withSerial, noSerial, differ.DefaultOptions())
+		require.NotContains(t, up, "setval")
+		require.Contains(t, down, "ADD COLUMN id BIGSERIAL NOT NULL;\n\n"+resync)
+		require.Contains(t, down, "resyncs the sequence of id")
+	})
+
+	t.Run("down migration recreates a dropped table", func(t *testing.T) {
+		t.Parallel()
+
+		_, down := generateMigrationSQL(t, withSerial, "", differ.DefaultOptions())
+		require.NotContains(t, down, "setval")
+		require.Contains(t, down,
+			"-- The sequence of steps.id restarts at 1; the dropped rows are not restored.")
+	})
+
+	t.Run("table recreation template", func(t *testing.T) {
+		t.Parallel()
+
+		opts := differ.DefaultOptions()
+		opts.TableRecreation = differ.DefaultTableRecreationThresholds()
+
+		up, _ := generateMigrationSQL(t,
+			`CREATE TABLE steps (id BIGSERIAL PRIMARY KEY, a INT, b INT, c INT);`,
+			`CREATE TABLE steps (id BIGSERIAL PRIMARY KEY, d TEXT, e TEXT, f TEXT);`,
+			opts,
+		)
+		require.Contains(t, up, "-- SELECT setval(pg_get_serial_sequence('public.steps_new', 'id'), "+
+			"COALESCE((SELECT MAX(id) FROM public.steps_new), 1));")
+		require.Contains(t, up, "resyncs the sequence of id")
+	})
+}
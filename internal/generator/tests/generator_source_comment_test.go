@@ -0,0 +1,120 @@
+package generator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const (
+	sourceCommentCurrent = `CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    nickname TEXT
+);
+
+CREATE TABLE sessions (id BIGINT PRIMARY KEY);
+`
+	sourceCommentDesired = `CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    email TEXT
+);
+
+CREATE INDEX idx_users_email ON users (email);
+`
+)
+
+func parseSchemaFile(t *testing.T, path, sql string) *schema.Database {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(sql), 0o600))
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseFile(path, db))
+	require.Empty(t, p.GetErrors())
+
+	return db
+}
+
+func parseSchemaSQL(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(sql, db))
+
+	return db
+}
+
+func generateSourceCommentUp(t *testing.T, current, desired *schema.Database) string {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	return result.Migrations[0].UpFile.Content
+}
+
+func TestGenerator_SourceComments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	desiredPath := filepath.Join(dir, "desired.sql")
+	currentPath := filepath.Join(dir, "current.sql")
+
+	t.Run("desired and current from files", func(t *testing.T) {
+		t.Parallel()
+
+		content := generateSourceCommentUp(t,
+			parseSchemaFile(t, currentPath, sourceCommentCurrent),
+			parseSchemaFile(t, desiredPath, sourceCommentDesired),
+		)
+
+		assert.Contains(t, content,
+			"-- Add column users.email (from "+desiredPath+":3)\n")
+		assert.Contains(t, content,
+			"-- Add index idx_users_email (from "+desiredPath+":6)\n")
+		assert.Contains(t, content, "-- Drop column users.nickname "+
+			"(not present in desired state; declared at "+currentPath+":3)\n")
+		assert.Contains(t, content, "-- Drop table sessions "+
+			"(not present in desired state; declared at "+currentPath+":6)\n")
+	})
+
+	t.Run("current not from files", func(t *testing.T) {
+		t.Parallel()
+
+		content := generateSourceCommentUp(t,
+			parseSchemaSQL(t, sourceCommentCurrent),
+			parseSchemaFile(t, filepath.Join(dir, "desired_only.sql"), sourceCommentDesired),
+		)
+
+		assert.Contains(t, content,
+			"-- Drop column users.nickname (not present in desired state)\n")
+		assert.Contains(t, content,
+			"-- Drop table sessions (not present in desired state)\n")
+	})
+
+	t.Run("no inputs from files", func(t *testing.T) {
+		t.Parallel()
+
+		content := generateSourceCommentUp(t,
+			parseSchemaSQL(t, sourceCommentCurrent),
+			parseSchemaSQL(t, sourceCommentDesired),
+		)
+
+		assert.Contains(t, content, "-- Add column users.email\n")
+		assert.NotContains(t, content, "(from ")
+		assert.NotContains(t, content, "not present in desired state")
+	})
+}
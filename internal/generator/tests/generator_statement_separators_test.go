@@ -0,0 +1,74 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestGenerator_RemovesEmptyStatements(t *testing.T) {
+	t.Parallel()
+
+	desired := &schema.Database{
+		Views: []schema.View{
+			{Schema: "public", Name: "active_users", Definition: "SELECT ';;' AS marker;\n;"},
+		},
+	}
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "SELECT ';;' AS marker;\n")
+	assert.NotRegexp(t, `;\s*;`, strings.ReplaceAll(up, "';;'", ""))
+
+	var empty []diag.Warning
+
+	for _, warning := range result.Diagnostics {
+		if warning.Code == diag.CodeEmptyStatement {
+			empty = append(empty, warning)
+		}
+	}
+
+	require.Len(t, empty, 1)
+	assert.Equal(t, diag.SeverityInfo, empty[0].Severity)
+	assert.Contains(t, empty[0].Message, "viewBuilder built SQL with 1 empty statements")
+}
+
+func TestGenerator_CommentOnlyStatementIsNotTerminated(t *testing.T) {
+	t.Parallel()
+
+	current := &schema.Database{
+		Tables: []schema.Table{{
+			Schema:  "public",
+			Name:    "metrics",
+			Columns: []schema.Column{{Name: "time", DataType: "timestamptz", Position: 1}},
+		}},
+		Hypertables: []schema.Hypertable{
+			{Schema: "public", TableName: "metrics", TimeColumnName: "time"},
+		},
+	}
+	desired := &schema.Database{Tables: current.Tables}
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "-- Manual data migration required\n")
+	assert.NotContains(t, up, "-- Manual data migration required;")
+}
@@ -0,0 +1,113 @@
+package generator_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// streamingCommentSize is the size of the comment on every table of
+// largePlan, which makes up most of each migration's text.
+const streamingCommentSize = 256 << 10
+
+// largePlan adds one table in each of count schemas, each commented with
+// streamingCommentSize bytes, so every migration is about as large.
+func largePlan(t *testing.T, count int) *differ.DiffResult {
+	t.Helper()
+
+	desired := &schema.Database{}
+
+	for i := range count {
+		name := fmt.Sprintf("tenant_%02d", i)
+		desired.Schemas = append(desired.Schemas, schema.Schema{Name: name})
+		desired.Tables = append(desired.Tables, schema.Table{
+			Schema:  name,
+			Name:    "events",
+			Columns: []schema.Column{{Name: "id", DataType: "bigint", Position: 1}},
+			Comment: strings.Repeat(string(rune('a'+i%26)), streamingCommentSize),
+		})
+	}
+
+	result, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	return result
+}
+
+func liveHeap() uint64 {
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapAlloc
+}
+
+// generateMeasured writes the migrations of plan and returns how much the
+// live heap grew at most while doing so, sampled after every migration and
+// file, and how many bytes were written.
+func generateMeasured(t *testing.T, plan *differ.DiffResult) (peak, written uint64) {
+	t.Helper()
+
+	opts := generator.DefaultOptions()
+	opts.OutputDir = t.TempDir()
+	opts.OmitTimestamp = true
+
+	baseline := liveHeap()
+	opts.Progress = func(string, int, int) {
+		if heap := liveHeap(); heap > baseline {
+			peak = max(peak, heap-baseline)
+		}
+	}
+
+	result, err := generator.New(opts).Generate(plan)
+	require.NoError(t, err)
+
+	err = filepath.WalkDir(opts.OutputDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err == nil {
+			written += uint64(info.Size())
+		}
+
+		return err
+	})
+	require.NoError(t, err)
+
+	runtime.KeepAlive(result)
+
+	return peak, written
+}
+
+// TestGenerator_StreamsLargeMigrations checks that writing migrations holds
+// the text of one migration at a time, not of the whole run. Quadrupling the
+// schemas of the plan quadruples what is written; the largest growth of the
+// live heap while writing may only rise by a quarter of that difference,
+// which leaves room for garbage collection timing and the files' small
+// metadata while still failing when every file's text is kept.
+func TestGenerator_StreamsLargeMigrations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes about 10 MB of migrations")
+	}
+
+	smallPeak, smallWritten := generateMeasured(t, largePlan(t, 8))
+	largePeak, largeWritten := generateMeasured(t, largePlan(t, 32))
+
+	require.Greater(t, largeWritten, 3*smallWritten)
+	assert.Less(t, largePeak, smallPeak+(largeWritten-smallWritten)/4,
+		"peak heap growth of %d bytes writing %d bytes, %d bytes writing %d bytes",
+		smallPeak, smallWritten, largePeak, largeWritten)
+}
@@ -0,0 +1,110 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestGenerator_RenameLegacyTruncatedConstraint(t *testing.T) {
+	t.Parallel()
+
+	const (
+		table    = "customer_notification_preferences_by_channel_and_locale"
+		legacy   = "customer_notification_preferences_by_channel_and_locale_custome"
+		renamed  = "customer_notification_preferences_by_channel_and_3762e82e_fkey"
+		customer = "CREATE TABLE customers (id BIGINT PRIMARY KEY);\n"
+		columns  = "id BIGINT PRIMARY KEY, customer_account_id BIGINT"
+	)
+
+	up, down := generateViewTriggerFiles(t,
+		customer+"CREATE TABLE "+table+" ("+columns+", CONSTRAINT "+legacy+
+			" FOREIGN KEY (customer_account_id) REFERENCES customers (id));",
+		customer+"CREATE TABLE "+table+" ("+columns+
+			", FOREIGN KEY (customer_account_id) REFERENCES customers (id));")
+
+	assert.Contains(t, up,
+		"ALTER TABLE public."+table+" RENAME CONSTRAINT "+legacy+" TO "+renamed+";")
+	assert.NotContains(t, up, "DROP CONSTRAINT")
+	assert.NotContains(t, up, "ADD CONSTRAINT")
+	assert.Contains(t, down,
+		"ALTER TABLE public."+table+" RENAME CONSTRAINT "+renamed+" TO "+legacy+";")
+}
+
+func TestGenerator_PartitionIndexNamesDoNotCollide(t *testing.T) {
+	t.Parallel()
+
+	// Cut at 63 bytes, both partition indexes would be named
+	// <partition>_idx_eve.
+	partition := "events_by_customer_notification_channel_and_locale_2025"
+
+	table := func(indexes ...schema.Index) schema.Table {
+		return schema.Table{
+			Schema: schema.DefaultSchema,
+			Name:   "events",
+			Columns: []schema.Column{
+				{Name: "id", DataType: "bigint", Position: 1},
+				{Name: "event_date", DataType: "date", Position: 2},
+				{Name: "kind", DataType: "text", Position: 3},
+			},
+			Indexes: indexes,
+			PartitionStrategy: &schema.PartitionStrategy{
+				Type:    "RANGE",
+				Columns: []string{"event_date"},
+				Partitions: []schema.Partition{{
+					Name:       partition,
+					Definition: "FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+				}},
+			},
+		}
+	}
+
+	index := func(name, column string) schema.Index {
+		return schema.Index{
+			Schema:     schema.DefaultSchema,
+			Name:       name,
+			TableName:  "events",
+			Columns:    []string{column},
+			Type:       schema.IndexTypeBTree,
+			OnlyParent: true,
+		}
+	}
+
+	result, err := differ.New(nil).Compare(
+		&schema.Database{Tables: []schema.Table{table()}},
+		&schema.Database{Tables: []schema.Table{table(
+			index("idx_events_date", "event_date"),
+			index("idx_events_kind", "kind"),
+		)}},
+	)
+	require.NoError(t, err)
+
+	genResult, err := generator.New(testOptions()).Generate(result)
+	require.NoError(t, err)
+	require.Len(t, genResult.Migrations, 1)
+
+	up := genResult.Migrations[0].UpFile.Content
+	names := make(map[string]bool)
+
+	for line := range strings.SplitSeq(up, "\n") {
+		rest, ok := strings.CutPrefix(line, "CREATE INDEX ")
+		if !ok || strings.Contains(line, " ON ONLY ") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(rest, " ")
+		assert.LessOrEqual(t, len(name), schema.MaxIdentifierLength, name)
+		assert.True(t, strings.HasSuffix(name, "_idx_events_date") ||
+			strings.HasSuffix(name, "_idx_events_kind"), name)
+
+		names[name] = true
+	}
+
+	assert.Len(t, names, 2, up)
+}
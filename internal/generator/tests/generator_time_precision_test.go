@@ -0,0 +1,103 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestGenerator_TimeTypePrecisionRendering(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSchemaSQL(t, `CREATE TABLE events (
+    created_at timestamp(3) with time zone,
+    logged_at timestamp (0) without time zone,
+    starts_at time(2) with time zone,
+    slot interval hour to minute,
+    took interval day to second(3)
+);`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	up := result.Migrations[0].UpFile.Content
+	assert.Contains(t, up, "    created_at TIMESTAMPTZ(3),\n")
+	assert.Contains(t, up, "    logged_at TIMESTAMP(0),\n")
+	assert.Contains(t, up, "    starts_at TIMETZ(2),\n")
+	assert.Contains(t, up, "    slot INTERVAL HOUR TO MINUTE,\n")
+	assert.Contains(t, up, "    took INTERVAL DAY TO SECOND(3)\n")
+}
+
+func TestGenerator_TimeTypePrecisionChange(t *testing.T) {
+	t.Parallel()
+
+	current := parseSchemaSQL(t, `CREATE TABLE events (created_at timestamptz(3), logged_at time(6));`)
+	desired := parseSchemaSQL(t, `CREATE TABLE events (created_at timestamptz(6), logged_at time(0));`)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(current, desired)
+	require.NoError(t, err)
+
+	builder := generator.NewDDLBuilder(diff, true)
+
+	tests := []struct {
+		column   string
+		wantUp   string
+		upDesc   string
+		upUnsafe bool
+		wantDown string
+		downDesc string
+	}{
+		{
+			column:   "created_at",
+			wantUp:   "ALTER TABLE public.events ALTER COLUMN created_at TYPE TIMESTAMPTZ(6);",
+			upDesc:   "Widen precision of column events.created_at",
+			wantDown: "ALTER TABLE public.events ALTER COLUMN created_at TYPE TIMESTAMPTZ(3);",
+			downDesc: "Reduce precision of column events.created_at",
+		},
+		{
+			column:   "logged_at",
+			wantUp:   "ALTER TABLE public.events ALTER COLUMN logged_at TYPE TIME(0);",
+			upDesc:   "Reduce precision of column events.logged_at",
+			upUnsafe: true,
+			wantDown: "ALTER TABLE public.events ALTER COLUMN logged_at TYPE TIME(6);",
+			downDesc: "Widen precision of column events.logged_at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			t.Parallel()
+
+			var change *differ.Change
+
+			for i := range diff.Changes {
+				if diff.Changes[i].Details["column_name"] == tt.column {
+					change = &diff.Changes[i]
+				}
+			}
+
+			require.NotNil(t, change)
+
+			up, err := builder.BuildUpStatement(*change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, up.SQL)
+			assert.Equal(t, tt.upDesc, up.Description)
+			assert.Equal(t, tt.upUnsafe, up.IsUnsafe)
+
+			down, err := builder.BuildDownStatement(*change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDown, down.SQL)
+			assert.Equal(t, tt.downDesc, down.Description)
+			assert.Equal(t, !tt.upUnsafe, down.IsUnsafe)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseTypeSchema(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(sql, db))
+
+	return db
+}
+
+func TestGenerator_CustomTypeComments(t *testing.T) {
+	t.Parallel()
+
+	const enumSQL = `CREATE TYPE app.order_status AS ENUM ('pending', 'paid');`
+
+	tests := []struct {
+		name     string
+		current  string
+		desired  string
+		wantDesc string
+		wantUp   []string
+		wantDown []string
+	}{
+		{
+			name:    "comment added to existing type",
+			current: enumSQL,
+			desired: enumSQL + `COMMENT ON TYPE app.order_status IS 'Order lifecycle';`,
+			wantDesc: "Comment on type app.order_status is in desired schema but not in database " +
+				"(will be set)",
+			wantUp:   []string{"COMMENT ON TYPE app.order_status IS 'Order lifecycle';"},
+			wantDown: []string{"COMMENT ON TYPE app.order_status IS NULL;"},
+		},
+		{
+			name:    "comment changed",
+			current: enumSQL + `COMMENT ON TYPE app.order_status IS 'Old meaning';`,
+			desired: enumSQL + `COMMENT ON TYPE app.order_status IS 'New meaning';`,
+			wantDesc: "Comment on type app.order_status differs between database and " +
+				"desired schema (will be updated)",
+			wantUp:   []string{"COMMENT ON TYPE app.order_status IS 'New meaning';"},
+			wantDown: []string{"COMMENT ON TYPE app.order_status IS 'Old meaning';"},
+		},
+		{
+			name:    "comment removed",
+			current: enumSQL + `COMMENT ON TYPE app.order_status IS 'Old meaning';`,
+			desired: enumSQL,
+			wantDesc: "Comment on type app.order_status exists in database but not in " +
+				"desired schema (will be removed)",
+			wantUp:   []string{"COMMENT ON TYPE app.order_status IS NULL;"},
+			wantDown: []string{"COMMENT ON TYPE app.order_status IS 'Old meaning';"},
+		},
+		{
+			name:    "new type with comment",
+			current: ``,
+			desired: enumSQL + `COMMENT ON TYPE app.order_status IS 'Order lifecycle';`,
+			wantDesc: "Comment on type app.order_status is in desired schema but not in database " +
+				"(will be set)",
+			wantUp: []string{
+				"CREATE TYPE app.order_status AS ENUM ('pending', 'paid');",
+				"COMMENT ON TYPE app.order_status IS 'Order lifecycle';",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diff, err := differ.New(differ.DefaultOptions()).Compare(
+				parseTypeSchema(t, tt.current),
+				parseTypeSchema(t, tt.desired),
+			)
+			require.NoError(t, err)
+
+			var descriptions []string
+			for _, change := range diff.Changes {
+				descriptions = append(descriptions, change.Description)
+			}
+
+			assert.Contains(t, descriptions, tt.wantDesc)
+
+			result, err := generator.New(testOptions()).Generate(diff)
+			require.NoError(t, err)
+			require.Len(t, result.Migrations, 1)
+
+			up := result.Migrations[0].UpFile.Content
+			down := result.Migrations[0].DownFile.Content
+
+			last := -1
+			for _, want := range tt.wantUp {
+				idx := strings.Index(up, want)
+				require.NotEqual(t, -1, idx, "up migration missing %q:\n%s", want, up)
+				assert.Greater(t, idx, last, "up migration order for %q", want)
+				last = idx
+			}
+
+			for _, want := range tt.wantDown {
+				assert.Contains(t, down, want)
+			}
+
+			if len(tt.wantDown) == 0 {
+				assert.NotContains(t, down, "COMMENT ON TYPE")
+			}
+		})
+	}
+}
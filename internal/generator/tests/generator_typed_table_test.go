@@ -0,0 +1,88 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseTypedTableSchema(t *testing.T, sql string) *schema.Database {
+	t.Helper()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(sql, db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+
+	return db
+}
+
+func generateTypedTableMigration(t *testing.T, current, desired string) (string, string) {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseTypedTableSchema(t, current),
+		parseTypedTableSchema(t, desired),
+	)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	return result.Migrations[0].UpFile.Content, result.Migrations[0].DownFile.Content
+}
+
+func TestGenerator_TypedTableCreate(t *testing.T) {
+	t.Parallel()
+
+	up, _ := generateTypedTableMigration(t, ``, `CREATE TYPE app.event_type AS (
+    id BIGINT,
+    name VARCHAR(64)
+);
+CREATE TABLE app.events OF app.event_type (
+    id WITH OPTIONS NOT NULL,
+    CONSTRAINT events_pkey PRIMARY KEY (id)
+);
+CREATE TABLE app.archived_events OF app.event_type;`)
+
+	assert.Contains(t, up,
+		"CREATE TYPE app.event_type AS (\n    id BIGINT,\n    name VARCHAR(64)\n);")
+	assert.Contains(t, up,
+		"CREATE TABLE app.events OF app.event_type (\n    id WITH OPTIONS NOT NULL,")
+	assert.Contains(t, up, "CONSTRAINT events_pkey PRIMARY KEY (id)")
+	assert.Contains(t, up, "CREATE TABLE app.archived_events OF app.event_type;")
+	assert.NotContains(t, up, "name VARCHAR(64) WITH OPTIONS")
+}
+
+func TestGenerator_TypedTableAttributeChanges(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateTypedTableMigration(t,
+		`CREATE TYPE app.event_type AS (id BIGINT, note TEXT);
+CREATE TABLE app.events OF app.event_type;`,
+		`CREATE TYPE app.event_type AS (id INTEGER, name TEXT);
+CREATE TABLE app.events OF app.event_type;
+CREATE TABLE app.archived_events OF app.event_type;`)
+
+	assert.Contains(t, up, "ALTER TYPE app.event_type ADD ATTRIBUTE name TEXT CASCADE;")
+	assert.Contains(t, up, "ALTER TYPE app.event_type DROP ATTRIBUTE note CASCADE;")
+	assert.Contains(t, up, "ALTER TYPE app.event_type ALTER ATTRIBUTE id TYPE INTEGER CASCADE;")
+	assert.NotContains(t, up, "ALTER TABLE")
+	assert.Less(t,
+		strings.Index(up, "ADD ATTRIBUTE name"),
+		strings.Index(up, "CREATE TABLE app.archived_events OF app.event_type;"),
+		"a new typed table is created once its type has its attributes")
+
+	assert.Contains(t, down, "ALTER TYPE app.event_type DROP ATTRIBUTE name CASCADE;")
+	assert.Contains(t, down, "ALTER TYPE app.event_type ADD ATTRIBUTE note TEXT CASCADE;")
+	assert.Contains(t, down, "ALTER TYPE app.event_type ALTER ATTRIBUTE id TYPE BIGINT CASCADE;")
+}
@@ -0,0 +1,69 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const optionsViewTable = `CREATE TABLE users (id BIGINT, active BOOLEAN);`
+
+func TestGenerator_ViewOptions(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		optionsViewTable+`
+CREATE VIEW active_users WITH (security_invoker = true) AS
+SELECT id FROM users WHERE active;`,
+		optionsViewTable+`
+CREATE VIEW active_users WITH (security_barrier) AS
+SELECT id FROM users WHERE active
+WITH LOCAL CHECK OPTION;`,
+	)
+
+	const alter = "ALTER VIEW public.active_users "
+
+	assert.Contains(t, up, alter+"SET (check_option = local, security_barrier = true);")
+	assert.Contains(t, up, alter+"RESET (security_invoker);")
+	assert.NotContains(t, up, "CREATE OR REPLACE VIEW")
+
+	assert.Contains(t, down, alter+"SET (security_invoker = true);")
+	assert.Contains(t, down, alter+"RESET (check_option, security_barrier);")
+}
+
+func TestGenerator_ViewCommentOnlyChange(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		optionsViewTable+`
+CREATE VIEW active_users WITH (security_barrier) AS SELECT id FROM users WHERE active;
+COMMENT ON VIEW active_users IS 'Active users';`,
+		optionsViewTable+`
+CREATE VIEW active_users WITH (security_barrier) AS SELECT id FROM users WHERE active;
+COMMENT ON VIEW active_users IS 'Users that can log in';`,
+	)
+
+	assert.Contains(t, up, "COMMENT ON VIEW public.active_users IS 'Users that can log in';")
+	assert.Contains(t, down, "COMMENT ON VIEW public.active_users IS 'Active users';")
+	assert.NotContains(t, up+down, "CREATE OR REPLACE VIEW")
+}
+
+func TestGenerator_RedefinedViewKeepsOptions(t *testing.T) {
+	t.Parallel()
+
+	up, down := generateViewTriggerFiles(t,
+		optionsViewTable+`
+CREATE VIEW active_users AS SELECT id FROM users WHERE active WITH CHECK OPTION;`,
+		optionsViewTable+`
+CREATE VIEW active_users WITH (security_barrier = true) AS
+SELECT id FROM users WHERE NOT active;`,
+	)
+
+	assert.Contains(t, up,
+		"CREATE OR REPLACE VIEW public.active_users WITH (security_barrier = true) AS")
+	assert.NotContains(t, up, "WITH CASCADED CHECK OPTION")
+	assert.NotContains(t, up, "ALTER VIEW")
+
+	assert.Contains(t, down, "CREATE OR REPLACE VIEW public.active_users AS")
+	assert.Contains(t, down, "WITH CASCADED CHECK OPTION;")
+}
@@ -0,0 +1,148 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
+
+const (
+	viewTriggerTable = `CREATE TABLE orders (id BIGINT PRIMARY KEY, total INTEGER);`
+	viewTriggerView  = `CREATE VIEW order_entry AS SELECT id, total FROM orders;`
+	viewTrigger      = `
+CREATE FUNCTION order_entry_insert() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO orders (id, total) VALUES (NEW.id, NEW.total);
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER order_entry_insert INSTEAD OF INSERT ON order_entry
+    FOR EACH ROW EXECUTE FUNCTION order_entry_insert();
+`
+)
+
+func generateViewTriggerFiles(t *testing.T, current, desired string) (up, down string) {
+	t.Helper()
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(
+		parseSchemaSQL(t, current),
+		parseSchemaSQL(t, desired),
+	)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+	require.Len(t, result.Migrations, 1)
+
+	return result.Migrations[0].UpFile.Content, result.Migrations[0].DownFile.Content
+}
+
+// assertOrdered fails unless each of statements appears in content after the
+// one before it.
+func assertOrdered(t *testing.T, content string, statements ...string) {
+	t.Helper()
+
+	offset := 0
+
+	for _, statement := range statements {
+		idx := strings.Index(content[offset:], statement)
+		if !assert.GreaterOrEqual(t, idx, 0, "%q missing or out of order in:\n%s",
+			statement, content) {
+			return
+		}
+
+		offset += idx + len(statement)
+	}
+}
+
+func TestGenerator_InsteadOfTriggerOnView(t *testing.T) {
+	t.Parallel()
+
+	const (
+		createTrigger = "CREATE TRIGGER order_entry_insert\nINSTEAD OF INSERT ON public.order_entry"
+		dropTrigger   = "DROP TRIGGER IF EXISTS order_entry_insert ON public.order_entry"
+		dropView      = "DROP VIEW IF EXISTS public.order_entry;"
+		createView    = "CREATE VIEW public.order_entry AS"
+	)
+
+	current := viewTriggerTable + viewTriggerView + viewTrigger
+
+	t.Run("created after the view", func(t *testing.T) {
+		t.Parallel()
+
+		up, down := generateViewTriggerFiles(t, "", current)
+
+		assertOrdered(t, up, "CREATE OR REPLACE FUNCTION", createView, createTrigger)
+		assertOrdered(t, down, dropTrigger, dropView)
+	})
+
+	t.Run("function body change keeps the trigger", func(t *testing.T) {
+		t.Parallel()
+
+		desired := viewTriggerTable + viewTriggerView +
+			strings.Replace(viewTrigger, "RETURN NEW;", "RETURN NULL;", 1)
+
+		up, down := generateViewTriggerFiles(t, current, desired)
+
+		assert.Contains(t, up, "RETURN NULL;")
+		assert.Contains(t, down, "RETURN NEW;")
+
+		for _, content := range []string{up, down} {
+			assert.NotContains(t, content, "DROP VIEW")
+			assert.NotContains(t, content, "DROP TRIGGER")
+		}
+	})
+
+	t.Run("view recreation recreates the trigger", func(t *testing.T) {
+		t.Parallel()
+
+		desired := strings.Replace(viewTriggerTable, "INTEGER", "BIGINT", 1) +
+			`CREATE VIEW order_entry AS SELECT id, total, total > 100 AS large FROM orders;` +
+			viewTrigger
+
+		up, down := generateViewTriggerFiles(t, current, desired)
+
+		assertOrdered(t, up, dropTrigger, dropView, "TYPE BIGINT", createView,
+			"total > 100 AS large", createTrigger)
+		assertOrdered(t, down, dropTrigger, dropView, "TYPE INTEGER", createView,
+			"SELECT id, total FROM orders;", createTrigger)
+	})
+
+	t.Run("appended column is reverted by recreating the view", func(t *testing.T) {
+		t.Parallel()
+
+		desired := viewTriggerTable +
+			`CREATE VIEW order_entry AS SELECT id, total, total > 100 AS large FROM orders;` +
+			viewTrigger
+
+		up, down := generateViewTriggerFiles(t, current, desired)
+
+		assert.Contains(t, up, "CREATE OR REPLACE VIEW public.order_entry AS")
+		assert.NotContains(t, up, "DROP VIEW")
+
+		assertOrdered(t, down, "DROP VIEW public.order_entry;", createView,
+			"SELECT id, total FROM orders;", createTrigger)
+		assert.NotContains(t, down, "CREATE OR REPLACE VIEW")
+	})
+
+	t.Run("modified trigger on a recreated view", func(t *testing.T) {
+		t.Parallel()
+
+		desired := strings.Replace(viewTriggerTable, "INTEGER", "BIGINT", 1) +
+			viewTriggerView +
+			strings.Replace(viewTrigger, "INSTEAD OF INSERT", "INSTEAD OF INSERT OR UPDATE", 1)
+
+		up, down := generateViewTriggerFiles(t, current, desired)
+
+		assertOrdered(t, up, dropTrigger, dropView, createView,
+			"INSTEAD OF INSERT OR UPDATE ON public.order_entry")
+		assertOrdered(t, down, dropTrigger, dropView, createView, createTrigger+"\n")
+		assert.Equal(t, 1, strings.Count(up, "CREATE TRIGGER"))
+	})
+}
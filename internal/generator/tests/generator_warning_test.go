@@ -0,0 +1,119 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func warningCodes(warnings []diag.Warning) []diag.Code {
+	codes := make([]diag.Code, 0, len(warnings))
+	for _, warning := range warnings {
+		codes = append(codes, warning.Code)
+	}
+
+	return codes
+}
+
+func TestGenerator_WarningCodes(t *testing.T) {
+	t.Parallel()
+
+	dropTable := func(current *schema.Database) *differ.DiffResult {
+		return &differ.DiffResult{
+			Current: current,
+			Desired: &schema.Database{},
+			Changes: []differ.Change{
+				{
+					Type:        differ.ChangeTypeDropTable,
+					Severity:    differ.SeverityBreaking,
+					Description: "Drop table: public.users",
+					ObjectType:  "table",
+					ObjectName:  "public.users",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		diff      *differ.DiffResult
+		wantCodes []diag.Code
+	}{
+		{
+			name:      "no changes",
+			diff:      &differ.DiffResult{Current: &schema.Database{}, Desired: &schema.Database{}},
+			wantCodes: []diag.Code{diag.CodeNoChanges},
+		},
+		{
+			name: "unsafe drop table",
+			diff: dropTable(&schema.Database{
+				Tables: []schema.Table{{
+					Schema:  schema.DefaultSchema,
+					Name:    "users",
+					Columns: []schema.Column{{Name: "id", DataType: "BIGINT", Position: 1}},
+				}},
+			}),
+			wantCodes: []diag.Code{diag.CodeUnsafeOperation},
+		},
+		{
+			name:      "manual rollback",
+			diff:      recreateTableDiff(),
+			wantCodes: []diag.Code{diag.CodeUnsafeOperation, diag.CodeManualRollbackRequired},
+		},
+		{
+			name:      "build failures",
+			diff:      dropTable(&schema.Database{}),
+			wantCodes: []diag.Code{diag.CodeBuildUpFailed, diag.CodeBuildDownFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := generator.New(testOptions()).Generate(tt.diff)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCodes, warningCodes(result.Diagnostics))
+			assert.Equal(t, diag.Messages(result.Diagnostics), result.Warnings)
+
+			for _, warning := range result.Diagnostics {
+				if warning.Code == diag.CodeNoChanges {
+					assert.Equal(t, diag.SeverityInfo, warning.Severity)
+					continue
+				}
+
+				require.Len(t, tt.diff.Changes, 1)
+				assert.Equal(t, tt.diff.Changes[0].ObjectName, warning.ObjectName)
+				assert.Equal(t, string(tt.diff.Changes[0].Type), warning.ChangeType)
+			}
+		})
+	}
+}
+
+func TestGenerator_CarriesPlanSizeAdvisories(t *testing.T) {
+	t.Parallel()
+
+	desired := parseSchemaSQL(t, `CREATE TABLE a (id BIGINT);
+CREATE TABLE b (id BIGINT);`)
+
+	opts := differ.DefaultOptions()
+	opts.PlanSize = &differ.PlanSizeLimits{MaxChanges: 1}
+
+	diff, err := differ.New(opts).Compare(&schema.Database{}, desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, result.Diagnostics)
+	assert.Equal(t, diag.CodePlanTooLarge, result.Diagnostics[0].Code)
+	assert.NotEmpty(t, result.Migrations)
+	assert.Contains(t, result.Summary(), "plan has 2 changes (limit 1)")
+}
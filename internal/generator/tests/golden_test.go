@@ -0,0 +1,271 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// Golden cases live in testdata/<case>/ as current.sql and desired.sql, with
+// an optional options.json. The expected plan and migration files are kept in
+// testdata/<case>/golden/ and are rewritten with:
+//
+//	go test ./internal/generator/tests/ -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden files under testdata")
+
+const (
+	goldenDataDir  = "testdata"
+	goldenDirName  = "golden"
+	goldenPlanFile = "plan.json"
+	goldenOptions  = "options.json"
+
+	// goldenRuns is how many times each case is generated; every run must
+	// produce identical output so goldens never depend on map iteration.
+	goldenRuns = 3
+)
+
+var goldenTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// goldenCaseOptions adjusts how a case is generated. Files for every output
+// format other than golang-migrate are kept under golden/<format>/.
+type goldenCaseOptions struct {
+	OutputFormats         []generator.OutputFormat `json:"output_formats"`
+	SafeUniqueConstraints bool                     `json:"safe_unique_constraints"`
+	TableRecreation       bool                     `json:"table_recreation"`
+	OmitTimestamp         bool                     `json:"omit_timestamp"`
+	ToolVersion           string                   `json:"tool_version"`
+}
+
+type goldenPlan struct {
+	Changes  []goldenChange `json:"changes"`
+	Warnings []diag.Warning `json:"warnings,omitempty"`
+}
+
+type goldenChange struct {
+	Order       int      `json:"order"`
+	Type        string   `json:"type"`
+	Severity    string   `json:"severity"`
+	ObjectType  string   `json:"object_type"`
+	ObjectName  string   `json:"object_name"`
+	Description string   `json:"description"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+}
+
+func TestGolden(t *testing.T) {
+	t.Parallel()
+
+	entries, err := os.ReadDir(goldenDataDir)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		caseDir := filepath.Join(goldenDataDir, entry.Name())
+
+		t.Run(entry.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			want := runGoldenCase(t, caseDir)
+			for range goldenRuns - 1 {
+				require.Equal(t, want, runGoldenCase(t, caseDir), "output is not deterministic")
+			}
+
+			goldenDir := filepath.Join(caseDir, goldenDirName)
+
+			if *updateGolden {
+				writeGoldenFiles(t, goldenDir, want)
+				return
+			}
+
+			got := readGoldenFiles(t, goldenDir)
+			assert.ElementsMatch(t, slices.Collect(maps.Keys(want)), slices.Collect(maps.Keys(got)),
+				"golden file set is stale; rerun with -update and review the diff")
+
+			for name, content := range want {
+				assert.Equal(t, content, got[name],
+					"%s is stale; rerun with -update and review the diff", name)
+			}
+		})
+	}
+}
+
+// runGoldenCase parses, diffs and generates one case and returns the output
+// files keyed by their name in the golden directory.
+func runGoldenCase(t *testing.T, caseDir string) map[string]string {
+	t.Helper()
+
+	current := parseGoldenSchema(t, filepath.Join(caseDir, "current.sql"))
+	desired := parseGoldenSchema(t, filepath.Join(caseDir, "desired.sql"))
+
+	caseOpts := readGoldenOptions(t, caseDir)
+
+	diffOpts := differ.DefaultOptions()
+	if caseOpts.TableRecreation {
+		diffOpts.TableRecreation = differ.DefaultTableRecreationThresholds()
+	}
+
+	diff, err := differ.New(diffOpts).Compare(current, desired)
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+
+	var generateWarnings []diag.Warning
+
+	for _, format := range caseOpts.OutputFormats {
+		opts := testOptions()
+		opts.Now = func() time.Time { return goldenTime }
+		opts.OutputFormat = format
+		opts.SafeUniqueConstraints = caseOpts.SafeUniqueConstraints
+		opts.OmitTimestamp = caseOpts.OmitTimestamp
+		opts.ToolVersion = caseOpts.ToolVersion
+
+		result, err := generator.New(opts).Generate(diff)
+		require.NoError(t, err)
+
+		if generateWarnings == nil {
+			generateWarnings = result.Diagnostics
+		}
+
+		prefix := ""
+		if format != generator.OutputFormatGolangMigrate {
+			prefix = string(format) + "/"
+		}
+
+		for _, migration := range result.Migrations {
+			for _, file := range []*generator.MigrationFile{migration.UpFile, migration.DownFile} {
+				if file != nil {
+					files[prefix+file.FileName] = file.Content
+				}
+			}
+		}
+	}
+
+	plan := goldenPlan{
+		Changes:  make([]goldenChange, 0, len(diff.Changes)),
+		Warnings: slices.Concat(diff.Diagnostics, generateWarnings),
+	}
+
+	for _, change := range diff.Changes {
+		plan.Changes = append(plan.Changes, goldenChange{
+			Order:       change.Order,
+			Type:        string(change.Type),
+			Severity:    string(change.Severity),
+			ObjectType:  change.ObjectType,
+			ObjectName:  change.ObjectName,
+			Description: change.Description,
+			DependsOn:   change.DependsOn,
+		})
+	}
+
+	planJSON, err := json.MarshalIndent(plan, "", "  ")
+	require.NoError(t, err)
+
+	files[goldenPlanFile] = string(planJSON) + "\n"
+
+	return files
+}
+
+func readGoldenOptions(t *testing.T, caseDir string) goldenCaseOptions {
+	t.Helper()
+
+	var opts goldenCaseOptions
+
+	content, err := os.ReadFile(filepath.Join(caseDir, goldenOptions))
+	if err == nil {
+		require.NoError(t, json.Unmarshal(content, &opts))
+	} else {
+		require.ErrorIs(t, err, os.ErrNotExist)
+	}
+
+	if len(opts.OutputFormats) == 0 {
+		opts.OutputFormats = []generator.OutputFormat{generator.OutputFormatGolangMigrate}
+	}
+
+	return opts
+}
+
+// parseGoldenSchema parses a case file the way the CLI parses desired state.
+// A missing file is an empty database.
+func parseGoldenSchema(t *testing.T, path string) *schema.Database {
+	t.Helper()
+
+	db := &schema.Database{Version: schema.SchemaVersion, Tables: []schema.Table{}}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db
+	}
+
+	require.NoError(t, err)
+
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(string(content), db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+	require.Empty(t, p.GetErrors(), "parse errors in %s", path)
+
+	db.Sort()
+
+	return db
+}
+
+func readGoldenFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	_, err := os.Stat(dir)
+	require.NoError(t, err, "missing golden directory; run with -update to create it")
+
+	files := make(map[string]string)
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(name)] = string(content)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return files
+}
+
+func writeGoldenFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644)) //nolint:gosec
+	}
+}
@@ -1,6 +1,14 @@
 package generator_test
 
-import "github.com/accented-ai/pgtofu/internal/generator"
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/generator"
+)
 
 func testOptions() *generator.Options {
 	opts := generator.DefaultOptions()
@@ -8,3 +16,31 @@ func testOptions() *generator.Options {
 
 	return opts
 }
+
+// roundTripChanges generates the migrations that create the schema of sql
+// from an empty database, parses their up files back, and returns the
+// changes that remain between the two schemas. There are none when the generated
+// SQL creates exactly the schema it was generated from.
+func roundTripChanges(t *testing.T, sql string) []differ.Change {
+	t.Helper()
+
+	desired := parseSchemaSQL(t, sql)
+
+	diff, err := differ.New(differ.DefaultOptions()).Compare(parseSchemaSQL(t, ""), desired)
+	require.NoError(t, err)
+
+	result, err := generator.New(testOptions()).Generate(diff)
+	require.NoError(t, err)
+
+	var up strings.Builder
+	for _, migration := range result.Migrations {
+		up.WriteString(migration.UpFile.Content)
+	}
+
+	generated := parseSchemaSQL(t, up.String())
+
+	again, err := differ.New(differ.DefaultOptions()).Compare(generated, desired)
+	require.NoError(t, err)
+
+	return again.Changes
+}
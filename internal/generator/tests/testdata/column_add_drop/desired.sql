@@ -0,0 +1,6 @@
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    archived_at TIMESTAMPTZ
+);
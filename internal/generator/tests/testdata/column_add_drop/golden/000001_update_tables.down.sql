@@ -0,0 +1,34 @@
+-- =====================================================
+-- Migration: 000001_update_tables.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Reversibility: restores structure only
+-- ROLLBACK NOTE: restores structure only; data in dropped column public.accounts.legacy_code is not recoverable
+--
+-- Changes:
+--   Column public.accounts.archived_at (TIMESTAMPTZ) is in desired schema but not in database (will be created)
+--   Column public.accounts.status (TEXT) is in desired schema but not in database (will be created)
+--   Column public.accounts.legacy_code exists in database but not in desired schema (will be dropped)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add column accounts.legacy_code
+-- ROLLBACK NOTE: restores structure only; data in dropped column public.accounts.legacy_code is not recoverable
+ALTER TABLE public.accounts ADD COLUMN legacy_code TEXT;
+
+-- Drop column accounts.status
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS status;
+
+-- Drop column accounts.archived_at
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS archived_at;
+
+COMMIT;
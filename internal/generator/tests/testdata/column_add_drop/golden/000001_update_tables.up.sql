@@ -0,0 +1,29 @@
+-- =====================================================
+-- Migration: 000001_update_tables.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   Column public.accounts.archived_at (TIMESTAMPTZ) is in desired schema but not in database (will be created)
+--   Column public.accounts.status (TEXT) is in desired schema but not in database (will be created)
+--   Column public.accounts.legacy_code exists in database but not in desired schema (will be dropped)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add column accounts.archived_at
+ALTER TABLE public.accounts ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Add column accounts.status
+ALTER TABLE public.accounts ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+
+-- Drop column accounts.legacy_code
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS legacy_code;
+
+COMMIT;
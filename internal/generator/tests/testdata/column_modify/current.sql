@@ -0,0 +1,5 @@
+CREATE TABLE accounts (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(50),
+    balance NUMERIC(10, 2) DEFAULT 0
+);
@@ -0,0 +1,5 @@
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    balance NUMERIC(10, 2) DEFAULT 100
+);
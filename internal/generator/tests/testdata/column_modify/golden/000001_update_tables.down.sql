@@ -0,0 +1,34 @@
+-- =====================================================
+-- Migration: 000001_update_tables.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   Column type differs: public.accounts.id is INTEGER in database, BIGINT in desired schema
+--   Column type differs: public.accounts.name is VARCHAR(50) in database, VARCHAR(200) in desired schema
+--   Column default differs: public.accounts.balance is 0 in database, 100 in desired schema
+--   Column nullability differs: public.accounts.name is nullable in database, NOT NULL in desired schema
+--
+-- =====================================================
+
+BEGIN;
+
+-- Revert column nullability accounts.name
+ALTER TABLE public.accounts ALTER COLUMN name DROP NOT NULL;
+
+-- Revert column default accounts.balance
+ALTER TABLE public.accounts ALTER COLUMN balance SET DEFAULT 0;
+
+-- Revert column type accounts.name
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts ALTER COLUMN name TYPE VARCHAR(50);
+
+-- Revert column type accounts.id
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts ALTER COLUMN id TYPE INTEGER;
+
+COMMIT;
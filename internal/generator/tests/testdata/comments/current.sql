@@ -0,0 +1,6 @@
+CREATE TABLE products (
+    id BIGINT PRIMARY KEY,
+    sku TEXT NOT NULL
+);
+
+COMMENT ON TABLE products IS 'Old description';
@@ -0,0 +1,10 @@
+CREATE TABLE products (
+    id BIGINT PRIMARY KEY,
+    sku TEXT NOT NULL
+);
+
+COMMENT ON TABLE products IS 'Catalog of sellable products';
+COMMENT ON COLUMN products.sku IS 'Stock keeping unit';
+
+CREATE VIEW product_skus AS SELECT sku FROM products;
+COMMENT ON VIEW product_skus IS 'SKU listing';
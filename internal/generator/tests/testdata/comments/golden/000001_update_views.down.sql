@@ -0,0 +1,31 @@
+-- =====================================================
+-- Migration: 000001_update_views.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.product_skus
+--
+-- Changes:
+--   View public.product_skus is in desired schema but not in database (will be created)
+--   Comment on view public.product_skus is in desired schema but not in database (will be set)
+--   Comment on table public.products differs between database and desired schema (will be updated)
+--   Comment on column public.products.sku is in desired schema but not in database (will be set)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Revert column comment products.sku
+COMMENT ON COLUMN public.products.sku IS NULL;
+
+-- Revert table comment products
+COMMENT ON TABLE public.products IS
+'Old description';
+
+-- Drop view product_skus
+-- WARNING: This operation is potentially unsafe
+DROP VIEW IF EXISTS public.product_skus;
+
+COMMIT;
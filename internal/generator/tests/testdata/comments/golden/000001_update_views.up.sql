@@ -0,0 +1,34 @@
+-- =====================================================
+-- Migration: 000001_update_views.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: SHARE UPDATE EXCLUSIVE on public.products
+--
+-- Changes:
+--   View public.product_skus is in desired schema but not in database (will be created)
+--   Comment on view public.product_skus is in desired schema but not in database (will be set)
+--   Comment on table public.products differs between database and desired schema (will be updated)
+--   Comment on column public.products.sku is in desired schema but not in database (will be set)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add view product_skus
+CREATE VIEW public.product_skus AS
+SELECT sku FROM products;
+
+-- Modify view comment product_skus
+COMMENT ON VIEW public.product_skus IS 'SKU listing';
+
+-- Modify table comment products
+COMMENT ON TABLE public.products IS
+'Catalog of sellable products';
+
+-- Modify column comment products.sku
+COMMENT ON COLUMN public.products.sku IS 'Stock keeping unit';
+
+COMMIT;
@@ -0,0 +1,29 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id TEXT NOT NULL,
+    value DOUBLE PRECISION,
+    legacy TEXT
+);
+
+SELECT create_hypertable('metrics', 'time', chunk_time_interval => INTERVAL '1 day');
+
+ALTER TABLE metrics SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'device_id'
+);
+
+CREATE TABLE readings (
+    time TIMESTAMPTZ NOT NULL,
+    sensor_id TEXT NOT NULL,
+    value DOUBLE PRECISION,
+    legacy TEXT
+);
+
+SELECT create_hypertable('readings', 'time', chunk_time_interval => INTERVAL '1 day');
+
+ALTER TABLE readings SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'sensor_id'
+);
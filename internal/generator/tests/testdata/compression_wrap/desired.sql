@@ -0,0 +1,30 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id TEXT NOT NULL,
+    value DOUBLE PRECISION
+);
+
+SELECT create_hypertable('metrics', 'time', chunk_time_interval => INTERVAL '1 day');
+
+ALTER TABLE metrics SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'device_id'
+);
+
+-- The compression settings change too, so compression is not re-enabled
+-- after the column is dropped.
+CREATE TABLE readings (
+    time TIMESTAMPTZ NOT NULL,
+    sensor_id TEXT NOT NULL,
+    value DOUBLE PRECISION
+);
+
+SELECT create_hypertable('readings', 'time', chunk_time_interval => INTERVAL '1 day');
+
+ALTER TABLE readings SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'sensor_id',
+    timescaledb.compress_orderby = 'time DESC'
+);
@@ -0,0 +1,51 @@
+-- =====================================================
+-- Migration: 000001_update_tables.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.metrics
+--
+-- Changes:
+--   Column public.metrics.legacy exists in database but not in desired schema (will be dropped)
+--   Column public.readings.legacy exists in database but not in desired schema (will be dropped)
+--   Compression settings of hypertable public.readings differ between database and desired schema (will be updated)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop column metrics.legacy
+-- WARNING: This operation is potentially unsafe
+-- WARNING: This table is a compressed hypertable.
+-- If chunks are already compressed, you must decompress them first:
+--
+--   SELECT decompress_chunk(c)
+--   FROM show_chunks('public.metrics') c
+--   WHERE is_compressed;
+--
+-- Decompression can be slow and resource-intensive on large tables.
+-- Consider running this migration during a maintenance window.
+ALTER TABLE public.metrics SET (timescaledb.compress = false);
+ALTER TABLE public.metrics DROP COLUMN IF EXISTS legacy;
+ALTER TABLE public.metrics SET (timescaledb.compress, timescaledb.compress_segmentby = 'device_id');
+
+-- Drop column readings.legacy
+-- WARNING: This operation is potentially unsafe
+-- WARNING: This table is a compressed hypertable.
+-- If chunks are already compressed, you must decompress them first:
+--
+--   SELECT decompress_chunk(c)
+--   FROM show_chunks('public.readings') c
+--   WHERE is_compressed;
+--
+-- Decompression can be slow and resource-intensive on large tables.
+-- Consider running this migration during a maintenance window.
+ALTER TABLE public.readings SET (timescaledb.compress = false);
+ALTER TABLE public.readings DROP COLUMN IF EXISTS legacy;
+
+-- Modify compression policy for readings
+ALTER TABLE public.readings SET (timescaledb.compress, timescaledb.compress_segmentby = 'sensor_id', timescaledb.compress_orderby = 'time DESC');
+
+COMMIT;
@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY
+);
+
+CREATE TABLE posts (
+    id BIGINT PRIMARY KEY,
+    author_id BIGINT NOT NULL,
+    title TEXT NOT NULL
+);
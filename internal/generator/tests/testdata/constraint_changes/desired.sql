@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY
+);
+
+CREATE TABLE posts (
+    id BIGINT PRIMARY KEY,
+    author_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    CONSTRAINT posts_author_fk FOREIGN KEY (author_id) REFERENCES users (id),
+    CONSTRAINT posts_title_not_blank CHECK (length(title) > 0)
+);
@@ -0,0 +1,26 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.posts
+--
+-- Changes:
+--   CHECK constraint posts_title_not_blank on public.posts is in desired schema but not in database (will be created)
+--   FOREIGN KEY constraint posts_author_fk on public.posts is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop constraint posts.posts_author_fk
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.posts DROP CONSTRAINT IF EXISTS posts_author_fk;
+
+-- Drop constraint posts.posts_title_not_blank
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.posts DROP CONSTRAINT IF EXISTS posts_title_not_blank;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.posts (scans the table)
+--
+-- Changes:
+--   CHECK constraint posts_title_not_blank on public.posts is in desired schema but not in database (will be created)
+--   FOREIGN KEY constraint posts_author_fk on public.posts is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add constraint posts.posts_title_not_blank
+ALTER TABLE public.posts ADD CONSTRAINT posts_title_not_blank CHECK (length(title) > 0);
+
+-- Add constraint posts.posts_author_fk
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.posts ADD CONSTRAINT posts_author_fk FOREIGN KEY (author_id) REFERENCES public.users (id);
+
+COMMIT;
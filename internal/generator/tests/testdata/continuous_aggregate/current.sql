@@ -0,0 +1,9 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id TEXT NOT NULL,
+    value DOUBLE PRECISION
+);
+
+SELECT create_hypertable('metrics', 'time');
@@ -0,0 +1,21 @@
+-- =====================================================
+-- Migration: 000001_add_continuous_aggregate_metrics_hourly.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.metrics_hourly
+--
+-- Changes:
+--   Continuous aggregate public.metrics_hourly on public.metrics is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop continuous aggregate metrics_hourly
+-- WARNING: This operation is potentially unsafe
+DROP MATERIALIZED VIEW IF EXISTS public.metrics_hourly;
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- =====================================================
+-- Migration: 000001_add_continuous_aggregate_metrics_hourly.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Continuous aggregate public.metrics_hourly on public.metrics is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add continuous aggregate metrics_hourly
+CREATE MATERIALIZED VIEW public.metrics_hourly
+WITH (timescaledb.continuous) AS
+SELECT time_bucket(INTERVAL '1 hour', time) AS bucket, device_id, avg(value) AS avg_value
+FROM metrics
+GROUP BY bucket, device_id
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('public.metrics_hourly',
+    start_offset => INTERVAL '3 hours',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
+
+COMMIT;
@@ -0,0 +1,31 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id TEXT NOT NULL,
+    value DOUBLE PRECISION
+);
+
+SELECT create_hypertable('metrics', 'time');
+
+CREATE MATERIALIZED VIEW metrics_hourly
+WITH (timescaledb.continuous) AS
+SELECT time_bucket(INTERVAL '1 hour', time) AS bucket, device_id, avg(value) AS avg_value, max(value) AS max_value
+FROM metrics
+GROUP BY bucket, device_id
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('metrics_hourly',
+    start_offset => INTERVAL '3 hours',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
+
+ALTER MATERIALIZED VIEW metrics_hourly SET (timescaledb.compress = true);
+
+SELECT add_compression_policy('metrics_hourly', compress_after => INTERVAL '7 days');
+
+CREATE VIEW device_hourly AS
+SELECT bucket, device_id, avg_value FROM metrics_hourly;
+
+CREATE VIEW device_latest AS
+SELECT device_id, max(bucket) AS last_bucket FROM device_hourly GROUP BY device_id;
@@ -0,0 +1,57 @@
+-- =====================================================
+-- Migration: 000001_update_views.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.device_latest
+--
+-- Changes:
+--   View public.device_latest is dropped for continuous aggregate recreation (will be recreated)
+--   View public.device_hourly is dropped for continuous aggregate recreation (will be recreated)
+--   Continuous aggregate public.metrics_hourly differs between database and desired schema (will be replaced)
+--   View public.device_hourly is recreated from desired schema after continuous aggregate recreation
+--   View public.device_latest is recreated from desired schema after continuous aggregate recreation
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop view device_latest
+-- WARNING: This operation is potentially unsafe
+DROP VIEW IF EXISTS public.device_latest;
+
+-- Drop view device_hourly
+-- WARNING: This operation is potentially unsafe
+DROP VIEW IF EXISTS public.device_hourly;
+
+-- Modify continuous aggregate metrics_hourly
+-- WARNING: This operation is potentially unsafe
+DROP MATERIALIZED VIEW IF EXISTS public.metrics_hourly;
+
+CREATE MATERIALIZED VIEW public.metrics_hourly
+WITH (timescaledb.continuous) AS
+SELECT time_bucket(INTERVAL '1 hour', time) AS bucket, device_id, avg(value) AS avg_value, max(value) AS max_value
+FROM metrics
+GROUP BY bucket, device_id
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('public.metrics_hourly',
+    start_offset => INTERVAL '3 hours',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
+
+ALTER MATERIALIZED VIEW public.metrics_hourly SET (timescaledb.compress = true);
+
+SELECT add_compression_policy('public.metrics_hourly', INTERVAL '7 days');
+
+-- Add view device_hourly
+CREATE VIEW public.device_hourly AS
+SELECT bucket, device_id, avg_value FROM metrics_hourly;
+
+-- Add view device_latest
+CREATE VIEW public.device_latest AS
+SELECT device_id, max(bucket) AS last_bucket FROM device_hourly GROUP BY device_id;
+
+COMMIT;
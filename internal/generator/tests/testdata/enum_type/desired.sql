@@ -0,0 +1,4 @@
+CREATE TYPE order_status AS ENUM ('pending', 'paid');
+COMMENT ON TYPE order_status IS 'Lifecycle of an order';
+
+CREATE TYPE priority AS ENUM ('low', 'high');
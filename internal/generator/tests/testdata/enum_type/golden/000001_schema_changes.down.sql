@@ -0,0 +1,27 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Reversibility: manual rollback required
+-- ROLLBACK NOTE: manual rollback required
+--
+-- Changes:
+--   Comment on type public.order_status is in desired schema but not in database (will be set)
+--   Enum type public.priority is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Manual rollback required: Enum type public.priority is in desired schema but not in database (will be created)
+-- WARNING: This operation is potentially unsafe
+-- ROLLBACK NOTE: manual rollback required
+-- WARNING: Manual rollback required for: Enum type public.priority is in desired schema but not in database (will be created)
+
+-- Revert type comment order_status
+COMMENT ON TYPE public.order_status IS NULL;
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Comment on type public.order_status is in desired schema but not in database (will be set)
+--   Enum type public.priority is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Modify type comment order_status
+COMMENT ON TYPE public.order_status IS 'Lifecycle of an order';
+
+-- Add custom type priority
+CREATE TYPE public.priority AS ENUM ('low', 'high');
+
+COMMIT;
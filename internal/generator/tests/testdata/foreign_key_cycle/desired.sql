@@ -0,0 +1,24 @@
+CREATE TABLE line_items (
+    id BIGINT PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id)
+);
+
+CREATE TABLE orders (
+    id BIGINT PRIMARY KEY,
+    customer_id BIGINT NOT NULL REFERENCES customers(id)
+);
+
+CREATE TABLE customers (
+    id BIGINT PRIMARY KEY,
+    referred_by BIGINT REFERENCES customers(id)
+);
+
+CREATE TABLE departments (
+    id BIGINT PRIMARY KEY,
+    manager_id BIGINT REFERENCES employees(id)
+);
+
+CREATE TABLE employees (
+    id BIGINT PRIMARY KEY,
+    department_id BIGINT REFERENCES departments(id)
+);
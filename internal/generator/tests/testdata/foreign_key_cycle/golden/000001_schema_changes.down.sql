@@ -0,0 +1,46 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.line_items
+--
+-- Changes:
+--   Table public.customers is in desired schema but not in database (will be created)
+--   Table public.departments is in desired schema but not in database (will be created)
+--   Table public.employees is in desired schema but not in database (will be created)
+--   FOREIGN KEY constraint departments_manager_id_fkey on public.departments is in desired schema but not in database (will be created)
+--   Table public.orders is in desired schema but not in database (will be created)
+--   Table public.line_items is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop table line_items
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.line_items;
+
+-- Drop table orders
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.orders;
+
+-- Drop constraint departments.departments_manager_id_fkey
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.departments DROP CONSTRAINT IF EXISTS departments_manager_id_fkey;
+
+-- Drop table employees
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.employees;
+
+-- Drop table departments
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.departments;
+
+-- Drop table customers
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.customers;
+
+COMMIT;
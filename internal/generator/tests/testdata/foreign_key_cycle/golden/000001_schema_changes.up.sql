@@ -0,0 +1,63 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Table public.customers is in desired schema but not in database (will be created)
+--   Table public.departments is in desired schema but not in database (will be created)
+--   Table public.employees is in desired schema but not in database (will be created)
+--   FOREIGN KEY constraint departments_manager_id_fkey on public.departments is in desired schema but not in database (will be created)
+--   Table public.orders is in desired schema but not in database (will be created)
+--   Table public.line_items is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add table customers
+CREATE TABLE public.customers (
+    id BIGINT NOT NULL,
+    referred_by BIGINT,
+    CONSTRAINT customers_pkey PRIMARY KEY (id),
+    CONSTRAINT customers_referred_by_fkey FOREIGN KEY (referred_by) REFERENCES public.customers (id)
+);
+
+-- Add table departments
+CREATE TABLE public.departments (
+    id BIGINT NOT NULL,
+    manager_id BIGINT,
+    CONSTRAINT departments_pkey PRIMARY KEY (id)
+);
+
+-- Add table employees
+CREATE TABLE public.employees (
+    id BIGINT NOT NULL,
+    department_id BIGINT,
+    CONSTRAINT employees_pkey PRIMARY KEY (id),
+    CONSTRAINT employees_department_id_fkey FOREIGN KEY (department_id) REFERENCES public.departments (id)
+);
+
+-- Add constraint departments.departments_manager_id_fkey
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.departments ADD CONSTRAINT departments_manager_id_fkey FOREIGN KEY (manager_id) REFERENCES public.employees (id);
+
+-- Add table orders
+CREATE TABLE public.orders (
+    id BIGINT NOT NULL,
+    customer_id BIGINT NOT NULL,
+    CONSTRAINT orders_pkey PRIMARY KEY (id),
+    CONSTRAINT orders_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES public.customers (id)
+);
+
+-- Add table line_items
+CREATE TABLE public.line_items (
+    id BIGINT NOT NULL,
+    order_id BIGINT NOT NULL,
+    CONSTRAINT line_items_pkey PRIMARY KEY (id),
+    CONSTRAINT line_items_order_id_fkey FOREIGN KEY (order_id) REFERENCES public.orders (id)
+);
+
+COMMIT;
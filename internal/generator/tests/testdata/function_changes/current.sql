@@ -0,0 +1,3 @@
+CREATE FUNCTION add_numbers(a INTEGER, b INTEGER) RETURNS INTEGER
+LANGUAGE sql IMMUTABLE
+AS $$ SELECT a + b $$;
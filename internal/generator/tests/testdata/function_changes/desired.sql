@@ -0,0 +1,11 @@
+CREATE FUNCTION add_numbers(a INTEGER, b INTEGER) RETURNS INTEGER
+LANGUAGE sql IMMUTABLE
+AS $$ SELECT a + b + 0 $$;
+
+CREATE FUNCTION greet(name TEXT) RETURNS TEXT
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RETURN 'hello ' || name;
+END;
+$$;
@@ -0,0 +1,27 @@
+-- =====================================================
+-- Migration: 000001_update_functions.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Function public.add_numbers(INTEGER, INTEGER) differs between database and desired schema (will be replaced)
+--   Function public.greet(TEXT) is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop function greet
+-- WARNING: This operation is potentially unsafe
+DROP FUNCTION IF EXISTS public.greet(TEXT);
+
+-- Revert function add_numbers
+CREATE OR REPLACE FUNCTION public.add_numbers(a INTEGER, b INTEGER)
+
+RETURNS INTEGER AS $$
+SELECT a + b
+$$ LANGUAGE sql IMMUTABLE;
+
+COMMIT;
@@ -0,0 +1,21 @@
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    score INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE FUNCTION next_invoice_number() RETURNS BIGINT
+LANGUAGE sql
+AS $$ SELECT 1::bigint $$;
+
+CREATE FUNCTION compute_score(points INTEGER) RETURNS INTEGER
+LANGUAGE sql IMMUTABLE
+AS $$ SELECT points * 10 $$;
+
+CREATE TABLE invoices (
+    number BIGINT NOT NULL DEFAULT next_invoice_number(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE VIEW user_scores AS
+SELECT u.id, compute_score(u.score) AS total
+FROM users u;
@@ -0,0 +1,41 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.user_scores
+--
+-- Changes:
+--   Function public.compute_score(INTEGER) is in desired schema but not in database (will be created)
+--   Function public.next_invoice_number() is in desired schema but not in database (will be created)
+--   Table public.invoices is in desired schema but not in database (will be created)
+--   Table public.users is in desired schema but not in database (will be created)
+--   View public.user_scores is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop view user_scores
+-- WARNING: This operation is potentially unsafe
+DROP VIEW IF EXISTS public.user_scores;
+
+-- Drop table users
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.users;
+
+-- Drop table invoices
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.invoices;
+
+-- Drop function next_invoice_number
+-- WARNING: This operation is potentially unsafe
+DROP FUNCTION IF EXISTS public.next_invoice_number();
+
+-- Drop function compute_score
+-- WARNING: This operation is potentially unsafe
+DROP FUNCTION IF EXISTS public.compute_score(INTEGER);
+
+COMMIT;
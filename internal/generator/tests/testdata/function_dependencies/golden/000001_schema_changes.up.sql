@@ -0,0 +1,51 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Function public.compute_score(INTEGER) is in desired schema but not in database (will be created)
+--   Function public.next_invoice_number() is in desired schema but not in database (will be created)
+--   Table public.invoices is in desired schema but not in database (will be created)
+--   Table public.users is in desired schema but not in database (will be created)
+--   View public.user_scores is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add function compute_score
+CREATE OR REPLACE FUNCTION public.compute_score(points INTEGER)
+
+RETURNS INTEGER AS $$
+SELECT points * 10
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Add function next_invoice_number
+CREATE OR REPLACE FUNCTION public.next_invoice_number()
+
+RETURNS BIGINT AS $$
+SELECT 1::bigint
+$$ LANGUAGE sql;
+
+-- Add table invoices
+CREATE TABLE public.invoices (
+    number BIGINT NOT NULL DEFAULT next_invoice_number(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Add table users
+CREATE TABLE public.users (
+    id BIGINT NOT NULL,
+    score INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT users_pkey PRIMARY KEY (id)
+);
+
+-- Add view user_scores
+CREATE VIEW public.user_scores AS
+SELECT u.id, compute_score(u.score) AS total
+FROM users u;
+
+COMMIT;
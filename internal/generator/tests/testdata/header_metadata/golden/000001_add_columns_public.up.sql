@@ -0,0 +1,19 @@
+-- =====================================================
+-- Migration: 000001_add_columns_public.up.sql
+-- Generated by pgtofu v1.4.0 (3f2a9c1)
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   Column public.accounts.email (TEXT) is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add column accounts.email
+ALTER TABLE public.accounts ADD COLUMN email TEXT;
+
+COMMIT;
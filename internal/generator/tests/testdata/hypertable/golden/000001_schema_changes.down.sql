@@ -0,0 +1,19 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Extension timescaledb is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop extension timescaledb
+-- WARNING: This operation is potentially unsafe
+DROP EXTENSION IF EXISTS timescaledb;
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Extension timescaledb is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add extension timescaledb
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+COMMIT;
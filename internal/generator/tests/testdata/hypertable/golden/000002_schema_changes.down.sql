@@ -0,0 +1,43 @@
+-- =====================================================
+-- Migration: 000002_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.metrics
+--
+-- Reversibility: manual rollback required
+-- ROLLBACK NOTE: restores structure only; chunks dropped by the retention policy on public.metrics are not restored
+-- ROLLBACK NOTE: manual rollback required; hypertable conversion of public.metrics must be reverted manually
+--
+-- Changes:
+--   Table public.metrics is in desired schema but not in database (will be created)
+--   Table public.metrics is a hypertable in desired schema but not in database (will be converted, time column: time, interval: default)
+--   Compression differs: hypertable public.metrics is disabled in database, enabled in desired schema
+--   Retention policy differs: hypertable public.metrics is none in database, drop after 90 days in desired schema
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop retention policy for metrics
+-- ROLLBACK NOTE: restores structure only; chunks dropped by the retention policy on public.metrics are not restored
+SELECT remove_retention_policy('public.metrics');
+
+-- Drop compression policy for metrics
+SELECT remove_compression_policy('public.metrics', if_exists => true);
+
+ALTER TABLE public.metrics SET (timescaledb.compress = false);
+
+-- Drop hypertable public.metrics (manual intervention required)
+-- WARNING: This operation is potentially unsafe
+-- ROLLBACK NOTE: manual rollback required; hypertable conversion of public.metrics must be reverted manually
+-- WARNING: Cannot automatically revert hypertable public.metrics
+-- Manual data migration required
+
+-- Drop table metrics
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.metrics;
+
+COMMIT;
@@ -0,0 +1,37 @@
+-- =====================================================
+-- Migration: 000002_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Table public.metrics is in desired schema but not in database (will be created)
+--   Table public.metrics is a hypertable in desired schema but not in database (will be converted, time column: time, interval: default)
+--   Compression differs: hypertable public.metrics is disabled in database, enabled in desired schema
+--   Retention policy differs: hypertable public.metrics is none in database, drop after 90 days in desired schema
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add table metrics
+CREATE TABLE public.metrics (
+    time TIMESTAMPTZ NOT NULL,
+    device_id TEXT NOT NULL,
+    value DOUBLE PRECISION
+);
+
+-- Convert table metrics to hypertable
+SELECT create_hypertable('public.metrics', 'time');
+
+-- Add compression policy for metrics
+ALTER TABLE public.metrics SET (timescaledb.compress, timescaledb.compress_segmentby = 'device_id');
+
+SELECT add_compression_policy('public.metrics', INTERVAL '7 days');
+
+-- Add retention policy for metrics
+-- WARNING: This operation is potentially unsafe
+SELECT add_retention_policy('public.metrics', INTERVAL '90 days');
+
+COMMIT;
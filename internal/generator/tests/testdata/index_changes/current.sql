@@ -0,0 +1,8 @@
+CREATE TABLE events (
+    id BIGINT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_events_kind ON events (kind);
+CREATE INDEX idx_events_old ON events (created_at);
@@ -0,0 +1,8 @@
+CREATE TABLE events (
+    id BIGINT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_events_kind ON events (kind, created_at DESC);
+CREATE UNIQUE INDEX idx_events_recent ON events (id) WHERE created_at > '2024-01-01';
@@ -0,0 +1,29 @@
+-- =====================================================
+-- Migration: 000001_update_indexes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.events
+--
+-- Changes:
+--   Index idx_events_kind on public.events differs between database and desired schema (will be replaced)
+--   Index idx_events_old on public.events exists in database but not in desired schema (will be dropped)
+--   Unique partial index idx_events_recent on public.events(id) is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Modify index idx_events_kind
+DROP INDEX IF EXISTS public.idx_events_kind;
+CREATE INDEX idx_events_kind ON public.events (kind, created_at DESC);
+
+-- Drop index idx_events_old
+DROP INDEX IF EXISTS public.idx_events_old;
+
+-- Add index idx_events_recent
+CREATE UNIQUE INDEX idx_events_recent ON public.events (id) WHERE created_at > '2024-01-01';
+
+COMMIT;
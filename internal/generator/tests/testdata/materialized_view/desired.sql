@@ -0,0 +1,10 @@
+CREATE TABLE sales (
+    id BIGINT PRIMARY KEY,
+    region TEXT NOT NULL,
+    amount NUMERIC NOT NULL
+);
+
+CREATE MATERIALIZED VIEW sales_by_region AS
+SELECT region, sum(amount) AS total FROM sales GROUP BY region;
+
+CREATE INDEX idx_sales_by_region ON sales_by_region (region);
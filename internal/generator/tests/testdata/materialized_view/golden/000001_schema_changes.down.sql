@@ -0,0 +1,30 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.sales_by_region
+--
+-- Changes:
+--   Table public.sales is in desired schema but not in database (will be created)
+--   Materialized view public.sales_by_region is in desired schema but not in database (will be created)
+--   Index idx_sales_by_region on public.sales_by_region(region) is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop index idx_sales_by_region
+DROP INDEX IF EXISTS public.idx_sales_by_region;
+
+-- Drop materialized view sales_by_region
+-- WARNING: This operation is potentially unsafe
+DROP MATERIALIZED VIEW IF EXISTS public.sales_by_region;
+
+-- Drop table sales
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.sales;
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Table public.sales is in desired schema but not in database (will be created)
+--   Materialized view public.sales_by_region is in desired schema but not in database (will be created)
+--   Index idx_sales_by_region on public.sales_by_region(region) is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add table sales
+CREATE TABLE public.sales (
+    id BIGINT NOT NULL,
+    region TEXT NOT NULL,
+    amount NUMERIC NOT NULL,
+    CONSTRAINT sales_pkey PRIMARY KEY (id)
+);
+
+-- Add materialized view sales_by_region
+CREATE MATERIALIZED VIEW public.sales_by_region AS
+SELECT region, sum(amount) AS total FROM sales GROUP BY region;
+
+-- Add index idx_sales_by_region
+CREATE INDEX idx_sales_by_region ON public.sales_by_region (region);
+
+COMMIT;
@@ -0,0 +1,4 @@
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    email TEXT NOT NULL
+);
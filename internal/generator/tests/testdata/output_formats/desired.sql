@@ -0,0 +1,20 @@
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY,
+    email TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT accounts_email_key UNIQUE (email)
+);
+
+COMMENT ON TABLE accounts IS 'Customer accounts; one per login';
+COMMENT ON COLUMN accounts.email IS 'Login address';
+
+CREATE FUNCTION touch_updated_at() RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$;
+
+COMMENT ON FUNCTION touch_updated_at() IS 'Keeps updated_at current';
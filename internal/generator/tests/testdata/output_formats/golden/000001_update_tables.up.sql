@@ -0,0 +1,29 @@
+-- =====================================================
+-- Migration: 000001_update_tables.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   Comment on table public.accounts is in desired schema but not in database (will be set)
+--   Column public.accounts.updated_at (TIMESTAMPTZ) is in desired schema but not in database (will be created)
+--   Comment on column public.accounts.email is in desired schema but not in database (will be set)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Modify table comment accounts
+COMMENT ON TABLE public.accounts IS
+'Customer accounts; one per login';
+
+-- Add column accounts.updated_at
+ALTER TABLE public.accounts ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Modify column comment accounts.email
+COMMENT ON COLUMN public.accounts.email IS 'Login address';
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- =====================================================
+-- Migration: 000002_add_constraint_accounts.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: SHARE UPDATE EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   Build index concurrently for: UNIQUE constraint accounts_email_key on public.accounts is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+-- Drop unique index accounts.accounts_email_key
+DROP INDEX CONCURRENTLY IF EXISTS public.accounts_email_key;
@@ -0,0 +1,27 @@
+-- =====================================================
+-- Migration: 000003_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   UNIQUE constraint accounts_email_key on public.accounts is in desired schema but not in database (will be created)
+--   Function public.touch_updated_at() is in desired schema but not in database (will be created)
+--   Comment on function public.touch_updated_at() is in desired schema but not in database (will be set)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop function touch_updated_at
+-- WARNING: This operation is potentially unsafe
+DROP FUNCTION IF EXISTS public.touch_updated_at();
+
+-- Drop constraint accounts.accounts_email_key
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts DROP CONSTRAINT IF EXISTS accounts_email_key;
+
+COMMIT;
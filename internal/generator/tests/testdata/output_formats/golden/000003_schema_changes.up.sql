@@ -0,0 +1,36 @@
+-- =====================================================
+-- Migration: 000003_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   UNIQUE constraint accounts_email_key on public.accounts is in desired schema but not in database (will be created)
+--   Function public.touch_updated_at() is in desired schema but not in database (will be created)
+--   Comment on function public.touch_updated_at() is in desired schema but not in database (will be set)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Promote unique index accounts.accounts_email_key
+ALTER TABLE public.accounts ADD CONSTRAINT accounts_email_key UNIQUE USING INDEX accounts_email_key;
+
+-- Add function touch_updated_at
+CREATE OR REPLACE FUNCTION public.touch_updated_at()
+
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Modify function comment touch_updated_at
+COMMENT ON FUNCTION public.touch_updated_at() IS
+'Keeps updated_at current';
+
+COMMIT;
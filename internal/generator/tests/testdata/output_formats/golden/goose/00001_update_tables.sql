@@ -0,0 +1,39 @@
+-- =====================================================
+-- Migration: 00001_update_tables.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.accounts
+--
+-- Changes:
+--   Comment on table public.accounts is in desired schema but not in database (will be set)
+--   Column public.accounts.updated_at (TIMESTAMPTZ) is in desired schema but not in database (will be created)
+--   Comment on column public.accounts.email is in desired schema but not in database (will be set)
+--
+-- =====================================================
+
+-- +goose Up
+-- Modify table comment accounts
+-- +goose StatementBegin
+COMMENT ON TABLE public.accounts IS
+'Customer accounts; one per login';
+-- +goose StatementEnd
+
+-- Add column accounts.updated_at
+ALTER TABLE public.accounts ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Modify column comment accounts.email
+COMMENT ON COLUMN public.accounts.email IS 'Login address';
+
+-- +goose Down
+-- Revert column comment accounts.email
+COMMENT ON COLUMN public.accounts.email IS NULL;
+
+-- Drop column accounts.updated_at
+-- WARNING: This operation is potentially unsafe
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS updated_at;
+
+-- Revert table comment accounts
+COMMENT ON TABLE public.accounts IS NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE measurements (
+    id BIGINT NOT NULL,
+    recorded_on DATE NOT NULL,
+    value DOUBLE PRECISION
+) PARTITION BY RANGE (recorded_on);
+
+CREATE TABLE measurements_2023 PARTITION OF measurements
+    FOR VALUES FROM ('2023-01-01') TO ('2024-01-01');
@@ -0,0 +1,30 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.measurements_2024
+--
+-- Reversibility: restores structure only
+-- ROLLBACK NOTE: restores structure only; data in dropped partition public.measurements.measurements_2023 is not recoverable
+--
+-- Changes:
+--   Partition measurements_2023 of table public.measurements exists in database but not in desired schema (will be dropped)
+--   Partition measurements_2024 of table public.measurements is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop partition measurements_2024 from public.measurements
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.measurements_2024;
+
+-- Add partition measurements_2023 to public.measurements
+-- ROLLBACK NOTE: restores structure only; data in dropped partition public.measurements.measurements_2023 is not recoverable
+CREATE TABLE IF NOT EXISTS public.measurements_2023 PARTITION OF public.measurements
+FOR VALUES FROM ('2023-01-01') TO ('2024-01-01');
+
+COMMIT;
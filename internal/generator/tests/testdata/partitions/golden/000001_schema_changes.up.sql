@@ -0,0 +1,26 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.measurements_2023
+--
+-- Changes:
+--   Partition measurements_2023 of table public.measurements exists in database but not in desired schema (will be dropped)
+--   Partition measurements_2024 of table public.measurements is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop partition measurements_2023 from public.measurements
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.measurements_2023;
+
+-- Add partition measurements_2024 to public.measurements
+CREATE TABLE IF NOT EXISTS public.measurements_2024 PARTITION OF public.measurements
+FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+
+COMMIT;
@@ -0,0 +1,14 @@
+CREATE SCHEMA app;
+
+CREATE TABLE app.users (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    display_name TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE app.orders (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES app.users (id) ON DELETE CASCADE,
+    total NUMERIC(12, 2) NOT NULL CHECK (total >= 0)
+);
@@ -0,0 +1,18 @@
+-- =====================================================
+-- Migration: 000001_app_add_schema_app.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Schema app is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add schema app
+CREATE SCHEMA IF NOT EXISTS app;
+
+COMMIT;
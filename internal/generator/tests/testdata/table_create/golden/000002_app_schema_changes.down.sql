@@ -0,0 +1,26 @@
+-- =====================================================
+-- Migration: 000002_app_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on app.orders
+--
+-- Changes:
+--   Table app.users is in desired schema but not in database (will be created)
+--   Table app.orders is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop table orders
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS app.orders;
+
+-- Drop table users
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS app.users;
+
+COMMIT;
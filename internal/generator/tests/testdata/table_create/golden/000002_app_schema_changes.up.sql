@@ -0,0 +1,36 @@
+-- =====================================================
+-- Migration: 000002_app_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Table app.users is in desired schema but not in database (will be created)
+--   Table app.orders is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add table users
+CREATE TABLE app.users (
+    id BIGINT NOT NULL GENERATED ALWAYS AS IDENTITY,
+    email TEXT NOT NULL,
+    display_name TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT users_pkey PRIMARY KEY (id),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
+
+-- Add table orders
+CREATE TABLE app.orders (
+    id BIGINT NOT NULL GENERATED ALWAYS AS IDENTITY,
+    user_id BIGINT NOT NULL,
+    total NUMERIC(12, 2) NOT NULL,
+    CONSTRAINT orders_pkey PRIMARY KEY (id),
+    CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES app.users (id) ON DELETE CASCADE,
+    CONSTRAINT orders_total_check CHECK (total >= 0)
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+CREATE TABLE legacy_events (
+    id BIGINT PRIMARY KEY,
+    payload JSONB
+);
+
+CREATE TABLE accounts (
+    id BIGINT PRIMARY KEY
+);
@@ -0,0 +1,26 @@
+-- =====================================================
+-- Migration: 000001_drop_table_legacy_events.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Reversibility: restores structure only
+-- ROLLBACK NOTE: restores structure only; data in dropped table public.legacy_events is not recoverable
+--
+-- Changes:
+--   Table public.legacy_events exists in database but not in desired schema (will be dropped)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add table legacy_events
+-- ROLLBACK NOTE: restores structure only; data in dropped table public.legacy_events is not recoverable
+CREATE TABLE public.legacy_events (
+    id BIGINT NOT NULL,
+    payload JSONB,
+    CONSTRAINT legacy_events_pkey PRIMARY KEY (id)
+);
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- =====================================================
+-- Migration: 000001_drop_table_legacy_events.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.legacy_events
+--
+-- Changes:
+--   Table public.legacy_events exists in database but not in desired schema (will be dropped)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop table legacy_events
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.legacy_events;
+
+COMMIT;
@@ -0,0 +1,13 @@
+CREATE TABLE job_progress (
+    provider VARCHAR(50) NOT NULL,
+    job_name VARCHAR(50) NOT NULL,
+    job_type VARCHAR(50) NOT NULL,
+    last_item_id BIGINT,
+    last_item_time TIMESTAMPTZ,
+    total_processed BIGINT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT job_progress_pkey PRIMARY KEY (provider, job_name, job_type)
+);
+
+CREATE INDEX idx_job_progress_created ON job_progress (created_at);
@@ -0,0 +1,14 @@
+CREATE TABLE job_progress (
+    source VARCHAR(50) NOT NULL,
+    job_name VARCHAR(50) NOT NULL,
+    job_type VARCHAR(20) NOT NULL,
+    last_run_time TIMESTAMPTZ,
+    total_runs BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT job_progress_pkey PRIMARY KEY (source, job_name, job_type)
+);
+
+CREATE INDEX idx_job_progress_created ON job_progress (created_at DESC);
+
+COMMENT ON TABLE job_progress IS 'Unified job progress tracking';
@@ -0,0 +1,26 @@
+-- =====================================================
+-- Migration: 000001_recreate_table_job_progress.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: 4c8e361a8881
+-- =====================================================
+--
+-- Reversibility: manual rollback required
+-- ROLLBACK NOTE: manual rollback required; recreation of table public.job_progress must be reverted manually
+--
+-- Changes:
+--   Table public.job_progress differs between database and desired schema in ways ALTER TABLE cannot apply (will be recreated, replacing 11 changes)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Revert recreation of table job_progress (manual intervention required)
+-- WARNING: This operation is potentially unsafe
+-- ROLLBACK NOTE: manual rollback required; recreation of table public.job_progress must be reverted manually
+-- WARNING: Cannot automatically revert recreation of table public.job_progress
+-- If the old table has not been dropped yet, restore it with:
+-- DROP TABLE public.job_progress;
+-- ALTER TABLE public.job_progress_old RENAME TO job_progress;
+
+COMMIT;
@@ -0,0 +1,56 @@
+-- =====================================================
+-- Migration: 000001_recreate_table_job_progress.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: 4c8e361a8881
+-- =====================================================
+--
+-- Changes:
+--   Table public.job_progress differs between database and desired schema in ways ALTER TABLE cannot apply (will be recreated, replacing 11 changes)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Recreate table job_progress (manual intervention required)
+-- WARNING: This operation is potentially unsafe
+-- MANUAL STEP: recreate table public.job_progress
+-- Reason: 8 of 11 columns change (73%), above the 50% column change threshold
+-- Review the column mapping below, then uncomment and run these statements.
+-- Views and foreign keys that reference the table follow the renamed old
+-- table and must be recreated against the new one.
+--
+-- ALTER INDEX public.job_progress_pkey RENAME TO job_progress_pkey_old;
+--
+-- ALTER INDEX public.idx_job_progress_created RENAME TO idx_job_progress_created_old;
+--
+-- CREATE TABLE public.job_progress_new (
+--     source VARCHAR(50) NOT NULL,
+--     job_name VARCHAR(50) NOT NULL,
+--     job_type VARCHAR(20) NOT NULL,
+--     last_run_time TIMESTAMPTZ,
+--     total_runs BIGINT NOT NULL DEFAULT 0,
+--     last_error TEXT,
+--     created_at TIMESTAMPTZ NOT NULL,
+--     CONSTRAINT job_progress_pkey PRIMARY KEY (source, job_name, job_type)
+-- );
+--
+-- UNMATCHED new columns (no source; add them to the copy or rely on defaults): source, last_run_time, total_runs
+-- UNMATCHED old columns (not copied, data is lost): provider, last_item_id, last_item_time, total_processed
+-- Type changes (check the casts): job_type (VARCHAR(50) -> VARCHAR(20))
+-- INSERT INTO public.job_progress_new (job_name, job_type, last_error, created_at)
+-- SELECT job_name, job_type, last_error, created_at
+-- FROM public.job_progress;
+--
+-- CREATE INDEX idx_job_progress_created ON public.job_progress_new (created_at DESC);
+--
+-- ALTER TABLE public.job_progress RENAME TO job_progress_old;
+--
+-- ALTER TABLE public.job_progress_new RENAME TO job_progress;
+--
+-- COMMENT ON TABLE public.job_progress IS 'Unified job progress tracking';
+--
+-- After verifying the copy:
+-- DROP TABLE public.job_progress_old;
+
+COMMIT;
@@ -0,0 +1,18 @@
+CREATE TABLE documents (
+    id BIGINT PRIMARY KEY,
+    body TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION touch_updated_at() RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER documents_touch_updated_at
+BEFORE UPDATE ON documents
+FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
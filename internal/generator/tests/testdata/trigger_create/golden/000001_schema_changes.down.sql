@@ -0,0 +1,31 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.documents
+--
+-- Changes:
+--   Table public.documents is in desired schema but not in database (will be created)
+--   Function public.touch_updated_at() is in desired schema but not in database (will be created)
+--   Trigger documents_touch_updated_at on public.documents is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop trigger documents_touch_updated_at
+-- WARNING: This operation is potentially unsafe
+DROP TRIGGER IF EXISTS documents_touch_updated_at ON public.documents;
+
+-- Drop function touch_updated_at
+-- WARNING: This operation is potentially unsafe
+DROP FUNCTION IF EXISTS public.touch_updated_at();
+
+-- Drop table documents
+-- WARNING: This operation is potentially unsafe
+DROP TABLE IF EXISTS public.documents;
+
+COMMIT;
@@ -0,0 +1,41 @@
+-- =====================================================
+-- Migration: 000001_schema_changes.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Changes:
+--   Table public.documents is in desired schema but not in database (will be created)
+--   Function public.touch_updated_at() is in desired schema but not in database (will be created)
+--   Trigger documents_touch_updated_at on public.documents is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Add table documents
+CREATE TABLE public.documents (
+    id BIGINT NOT NULL,
+    body TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT documents_pkey PRIMARY KEY (id)
+);
+
+-- Add function touch_updated_at
+CREATE OR REPLACE FUNCTION public.touch_updated_at()
+
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Add trigger documents_touch_updated_at
+CREATE TRIGGER documents_touch_updated_at
+BEFORE UPDATE ON public.documents
+FOR EACH ROW
+EXECUTE FUNCTION public.touch_updated_at();
+
+COMMIT;
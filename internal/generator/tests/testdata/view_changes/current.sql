@@ -0,0 +1,8 @@
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE VIEW active_users AS
+SELECT id, name FROM users WHERE active;
@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE VIEW active_users AS
+SELECT id, name, upper(name) AS display_name FROM users WHERE active;
+
+CREATE VIEW user_names AS
+SELECT name FROM users;
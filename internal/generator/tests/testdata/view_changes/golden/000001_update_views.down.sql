@@ -0,0 +1,29 @@
+-- =====================================================
+-- Migration: 000001_update_views.down.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.user_names
+--
+-- Changes:
+--   View public.active_users differs between database and desired schema (will be replaced)
+--     view public.active_users: +column display_name
+--   View public.user_names is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Drop view user_names
+-- WARNING: This operation is potentially unsafe
+DROP VIEW IF EXISTS public.user_names;
+
+-- Recreate view active_users
+DROP VIEW public.active_users;
+
+CREATE VIEW public.active_users AS
+SELECT id, name FROM users WHERE active;
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- =====================================================
+-- Migration: 000001_update_views.up.sql
+-- Generated: 2024-01-01T00:00:00Z
+-- Generated by pgtofu
+-- Differ options: a41ef99f3351
+-- =====================================================
+--
+-- Strongest lock: ACCESS EXCLUSIVE on public.active_users
+--
+-- Changes:
+--   View public.active_users differs between database and desired schema (will be replaced)
+--     view public.active_users: +column display_name
+--   View public.user_names is in desired schema but not in database (will be created)
+--
+-- =====================================================
+
+BEGIN;
+
+-- Modify view active_users
+CREATE OR REPLACE VIEW public.active_users AS
+SELECT id, name, upper(name) AS display_name FROM users WHERE active;
+
+-- Add view user_names
+CREATE VIEW public.user_names AS
+SELECT name FROM users;
+
+COMMIT;
@@ -3,9 +3,13 @@ package generator
 import (
 	"errors"
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
 	"github.com/accented-ai/pgtofu/internal/util"
 )
 
@@ -18,8 +22,81 @@ type Options struct {
 	GenerateDownMigrations bool
 	MaxOperationsPerFile   int
 	PreviewMode            bool
+	SafeUniqueConstraints  bool
+	// SafeConstraintMode adds the CHECK and foreign key constraints of
+	// existing tables NOT VALID and validates them in the following
+	// migration, and sets NOT NULL on their columns through a validated
+	// CHECK (column IS NOT NULL). Partitioned tables and hypertables are
+	// excluded.
+	SafeConstraintMode bool
+	// ConcurrentIndexes builds and drops the indexes of existing tables
+	// CONCURRENTLY, each in a migration of its own that runs outside a
+	// transaction. Partitioned tables and hypertables are excluded.
+	ConcurrentIndexes bool
+	// DetachPartitions detaches the partitions removed from the desired
+	// schema with ALTER TABLE ... DETACH PARTITION instead of dropping them,
+	// so their rows are kept in a standalone table. Down migrations attach
+	// them again.
+	DetachPartitions bool
+	// Baseline writes every change to a single migration named "baseline",
+	// however many operations it holds, for creating a schema from nothing.
+	// Its down migration drops each schema the changes create objects in
+	// with CASCADE instead of dropping the objects one by one.
+	Baseline bool
+	// OutputFormat selects the migration tool the files are written for. An
+	// empty value is treated as OutputFormatGolangMigrate.
+	OutputFormat OutputFormat
+	// Progress, when set, is called after each migration is generated and
+	// after each file is written.
+	Progress ProgressFunc
+	// Now supplies the timestamp written to migration headers. It defaults to
+	// time.Now and is fixed in tests that compare generated files.
+	Now func() time.Time
+	// OmitTimestamp leaves the Generated line out of migration headers so
+	// regenerating unchanged migrations produces identical files.
+	OmitTimestamp bool
+	// ToolVersion is the pgtofu version recorded in migration headers, such
+	// as "v1.4.0 (3f2a9c1)". It is left out when empty.
+	ToolVersion string
+	// Comparison describes what the migrations were generated from when the
+	// current schema is not a database, such as an earlier revision of the
+	// schema files. It is recorded in migration headers when set.
+	Comparison string
+	// EmitSavepoints wraps each statement of a transactional migration in
+	// SAVEPOINT pgtofu_<n> and RELEASE SAVEPOINT pgtofu_<n>, so the savepoint
+	// named in an error identifies the failing statement. Files that run
+	// outside a transaction are unaffected.
+	EmitSavepoints bool
+	// PartitionOutputBySchema writes the migrations of each schema to a
+	// subdirectory of OutputDir named after it, numbered independently of the
+	// other schemas: each subdirectory continues from the highest version
+	// already in it, or from StartVersion. Schema and extension migrations,
+	// and migrations spanning several schemas, go to SharedSubdirectory.
+	PartitionOutputBySchema bool
+	// SharedSubdirectory is the subdirectory PartitionOutputBySchema writes
+	// the migrations shared by all schemas to. An empty value is treated as
+	// DefaultSharedSubdirectory.
+	SharedSubdirectory string
+	// CascadeDrops allowlists the drops written with CASCADE. Each entry is an
+	// object kind, such as "view" or "materialized_view", or a path.Match
+	// pattern for qualified names, such as "reporting.*". Other objects are
+	// dropped without CASCADE, and generation fails if one of them still has
+	// dependents the plan neither drops nor changes.
+	CascadeDrops []string
+	// FailOnLock fails generation when an up statement takes a lock of this
+	// level or stronger on an object that exists before its migration runs,
+	// unless AllowLocks matches the object. LockNone, the default, never
+	// fails.
+	FailOnLock LockLevel
+	// AllowLocks are path.Match patterns for the qualified names of the
+	// objects FailOnLock does not apply to, such as "public.audit_*".
+	AllowLocks []string
 }
 
+// ProgressFunc receives the current generation stage and how many of its
+// items are done.
+type ProgressFunc func(stage string, done, total int)
+
 type TransactionMode string
 
 const (
@@ -28,6 +105,23 @@ const (
 	TransactionModeNever  TransactionMode = "never"
 )
 
+// OutputFormat is the migration tool generated files are laid out for.
+type OutputFormat string
+
+const (
+	// OutputFormatGolangMigrate writes {version}_{name}.up.sql and
+	// {version}_{name}.down.sql for every migration.
+	OutputFormatGolangMigrate OutputFormat = "golang-migrate"
+	// OutputFormatGoose writes a single {version}_{name}.sql per migration
+	// with -- +goose Up and -- +goose Down sections.
+	OutputFormatGoose OutputFormat = "goose"
+)
+
+// OutputFormats returns every output format, the default first.
+func OutputFormats() []OutputFormat {
+	return []OutputFormat{OutputFormatGolangMigrate, OutputFormatGoose}
+}
+
 func DefaultOptions() *Options {
 	return &Options{
 		OutputDir:              DefaultOutputDir,
@@ -38,6 +132,8 @@ func DefaultOptions() *Options {
 		GenerateDownMigrations: true,
 		MaxOperationsPerFile:   DefaultMaxOpsPerFile,
 		PreviewMode:            false,
+		SafeUniqueConstraints:  false,
+		OutputFormat:           OutputFormatGolangMigrate,
 	}
 }
 
@@ -75,6 +171,34 @@ func (o *Options) Validate() error {
 		)
 	}
 
+	switch o.OutputFormat {
+	case "", OutputFormatGolangMigrate, OutputFormatGoose:
+	default:
+		errs = append(
+			errs,
+			fmt.Errorf(
+				"invalid output format: %s (must be golang-migrate or goose)",
+				o.OutputFormat,
+			),
+		)
+	}
+
+	if o.SharedSubdirectory != "" && (o.SharedSubdirectory != filepath.Base(o.SharedSubdirectory) ||
+		o.SharedSubdirectory == "." || o.SharedSubdirectory == "..") {
+		errs = append(errs, fmt.Errorf(
+			"shared subdirectory must be a single directory name, got %q", o.SharedSubdirectory,
+		))
+	}
+
+	if o.ConcurrentIndexes && o.TransactionMode == TransactionModeAlways {
+		errs = append(errs, errors.New(
+			"concurrent indexes cannot be used with transaction mode always",
+		))
+	}
+
+	errs = append(errs, validateCascadeDrops(o.CascadeDrops)...)
+	errs = append(errs, validateAllowLocks(o.AllowLocks)...)
+
 	if len(errs) > 0 {
 		return util.WrapError("invalid options", errors.Join(errs...))
 	}
@@ -85,9 +209,25 @@ func (o *Options) Validate() error {
 type MigrationFile struct {
 	Version     int
 	Description string
-	Direction   Direction
-	FileName    string
-	Content     string
+	// Direction is empty for goose files, which hold both directions.
+	Direction Direction
+	FileName  string
+	// Content is the text of the file. It is only kept in PreviewMode;
+	// otherwise each file is streamed to disk as it is generated, so a run
+	// never holds the text of more than one migration, and Content is empty.
+	Content string
+	// Checksum is the hex SHA-256 of the file's text, computed as the text
+	// is laid out.
+	Checksum string
+	// Reversibility is the worst classification among the file's statements.
+	Reversibility Reversibility
+	// Locks are the statements of the file that lock objects existing before
+	// it runs. A goose file lists those of its up section.
+	Locks []StatementLock
+
+	// layOut writes the text of the file. It is dropped once the file is
+	// written, releasing the statements it holds.
+	layOut func(contentWriter)
 }
 
 type Direction string
@@ -97,17 +237,41 @@ const (
 	DirectionDown Direction = "down"
 )
 
+// MigrationPair is one migration version. In goose format UpFile holds the
+// single file containing both directions and DownFile is nil.
 type MigrationPair struct {
 	Version     int
 	Description string
-	UpFile      *MigrationFile
-	DownFile    *MigrationFile
+	// Subdirectory is the directory under OutputDir the files are written to.
+	// It is empty unless PartitionOutputBySchema is set.
+	Subdirectory string
+	UpFile       *MigrationFile
+	DownFile     *MigrationFile
+}
+
+// Path returns the path of file relative to OutputDir.
+func (mp *MigrationPair) Path(file *MigrationFile) string {
+	return path.Join(mp.Subdirectory, file.FileName)
 }
 
 type GenerateResult struct {
-	Migrations     []MigrationPair
-	Warnings       []string
-	FilesGenerated int
+	Migrations []MigrationPair
+	// Diagnostics are the warnings raised while building statements, with
+	// stable codes.
+	Diagnostics []diag.Warning
+	// Deprecated: Warnings holds the messages of Diagnostics and will be
+	// removed in the next release.
+	Warnings        []string
+	FilesGenerated  int
+	LossyRollbacks  int
+	ManualRollbacks int
+	// SafetyReport lists the locks the up migrations take.
+	SafetyReport SafetyReport
+}
+
+func (gr *GenerateResult) addWarning(warning diag.Warning) {
+	gr.Diagnostics = append(gr.Diagnostics, warning)
+	gr.Warnings = append(gr.Warnings, warning.Message)
 }
 
 func (gr *GenerateResult) Summary() string {
@@ -124,10 +288,24 @@ func (gr *GenerateResult) Summary() string {
 	fmt.Fprintf(&sb, "Migrations Generated: %d\n", len(gr.Migrations))
 	fmt.Fprintf(&sb, "Files Created: %d\n", gr.FilesGenerated)
 
-	if len(gr.Warnings) > 0 {
-		fmt.Fprintf(&sb, "\nWarnings: %d\n", len(gr.Warnings))
+	if gr.LossyRollbacks > 0 || gr.ManualRollbacks > 0 {
+		sb.WriteString("\nRollback Limitations:\n")
+		fmt.Fprintf(&sb, "  Structure-only rollbacks: %d\n", gr.LossyRollbacks)
+		fmt.Fprintf(&sb, "  Manual rollbacks: %d\n", gr.ManualRollbacks)
+	}
+
+	if locks := gr.SafetyReport.AccessExclusive(); len(locks) > 0 {
+		fmt.Fprintf(&sb, "\nACCESS EXCLUSIVE Locks: %d\n", len(locks))
+
+		for _, lock := range locks {
+			fmt.Fprintf(&sb, "  - %s\n", lock)
+		}
+	}
+
+	if len(gr.Diagnostics) > 0 {
+		fmt.Fprintf(&sb, "\nWarnings: %d\n", len(gr.Diagnostics))
 
-		for _, warning := range gr.Warnings {
+		for _, warning := range gr.Diagnostics {
 			fmt.Fprintf(&sb, "  - %s\n", warning)
 		}
 	}
@@ -136,34 +314,103 @@ func (gr *GenerateResult) Summary() string {
 
 	for _, migration := range gr.Migrations {
 		if migration.UpFile != nil {
-			fmt.Fprintf(&sb, "  %s\n", migration.UpFile.FileName)
+			fmt.Fprintf(&sb, "  %s\n", migration.Path(migration.UpFile))
 		}
 
 		if migration.DownFile != nil {
-			fmt.Fprintf(&sb, "  %s\n", migration.DownFile.FileName)
+			fmt.Fprintf(&sb, "  %s\n", migration.Path(migration.DownFile))
 		}
 	}
 
 	return sb.String()
 }
 
+func (gr *GenerateResult) files() []*MigrationFile {
+	files := make([]*MigrationFile, 0, 2*len(gr.Migrations))
+
+	for _, migration := range gr.Migrations {
+		if migration.UpFile != nil {
+			files = append(files, migration.UpFile)
+		}
+
+		if migration.DownFile != nil {
+			files = append(files, migration.DownFile)
+		}
+	}
+
+	return files
+}
+
 type migrationHeader struct {
-	Version     int
-	Description string
-	Direction   Direction
-	Generated   time.Time
-	Changes     []string
+	FileName string
+	// Generated is zero when the timestamp is omitted.
+	Generated     time.Time
+	ToolVersion   string
+	OptionsHash   string
+	Comparison    string
+	Changes       []string
+	Reversibility Reversibility
+	RollbackNotes []string
+	// StrongestLock is the first of the strongest locks the file's statements
+	// take, when they take any.
+	StrongestLock *StatementLock
+	// DependencyNotes warn about migrations of other schemas this one depends
+	// on or is depended on by.
+	DependencyNotes []string
 }
 
 func (mh *migrationHeader) String() string {
 	var sb strings.Builder
 
 	sb.WriteString("-- =====================================================\n")
-	fmt.Fprintf(&sb, "-- Migration: %06d_%s.%s.sql\n", mh.Version, mh.Description, mh.Direction)
-	fmt.Fprintf(&sb, "-- Generated: %s\n", mh.Generated.Format(time.RFC3339))
-	sb.WriteString("-- Generated by pgtofu\n")
+	fmt.Fprintf(&sb, "-- Migration: %s\n", mh.FileName)
+
+	if !mh.Generated.IsZero() {
+		fmt.Fprintf(&sb, "-- Generated: %s\n", mh.Generated.Format(time.RFC3339))
+	}
+
+	if mh.ToolVersion != "" {
+		fmt.Fprintf(&sb, "-- Generated by pgtofu %s\n", mh.ToolVersion)
+	} else {
+		sb.WriteString("-- Generated by pgtofu\n")
+	}
+
+	if mh.OptionsHash != "" {
+		fmt.Fprintf(&sb, "-- Differ options: %s\n", mh.OptionsHash)
+	}
+
+	if mh.Comparison != "" {
+		fmt.Fprintf(&sb, "-- Compared: %s\n", mh.Comparison)
+	}
+
 	sb.WriteString("-- =====================================================\n")
 
+	if len(mh.DependencyNotes) > 0 {
+		sb.WriteString("--\n")
+
+		for _, note := range mh.DependencyNotes {
+			fmt.Fprintf(&sb, "-- CROSS-SCHEMA DEPENDENCY: %s\n", note)
+		}
+	}
+
+	if lock := mh.StrongestLock; lock != nil {
+		fmt.Fprintf(&sb, "--\n-- Strongest lock: %s on %s", lock.LockLevel, lock.AffectedObject)
+
+		if lock.Note != "" {
+			fmt.Fprintf(&sb, " (%s)", lock.Note)
+		}
+
+		sb.WriteString("\n")
+	}
+
+	if mh.Reversibility != ReversibilityFull {
+		fmt.Fprintf(&sb, "--\n-- Reversibility: %s\n", mh.Reversibility)
+
+		for _, note := range mh.RollbackNotes {
+			fmt.Fprintf(&sb, "-- ROLLBACK NOTE: %s\n", note)
+		}
+	}
+
 	if len(mh.Changes) > 0 {
 		sb.WriteString("--\n-- Changes:\n")
 
@@ -180,9 +427,49 @@ func (mh *migrationHeader) String() string {
 }
 
 type DDLStatement struct {
-	SQL         string
+	SQL string
+	// Statements are the statements of a DDLStatement that runs several in
+	// order. A builder sets them instead of SQL, and generation joins them
+	// into SQL, each terminated by exactly one semicolon.
+	Statements  []string
 	Description string
 	IsUnsafe    bool
 	RequiresTx  bool
 	CannotUseTx bool
+	// Reversibility and RollbackNote are only set on down statements.
+	Reversibility Reversibility
+	RollbackNote  string
+	// LockLevel is the strongest lock the statement takes on an object that
+	// exists before its migration runs, AffectedObject is that object and
+	// LockNote says what holds the lock longer than a catalog update. They are
+	// derived from the SQL once the statement is built.
+	LockLevel      LockLevel
+	AffectedObject string
+	LockNote       string
+	// Source locates the declaration of the change the statement was built
+	// from, when there is one.
+	Source *differ.ChangeSource
+}
+
+// Reversibility describes how faithfully a down statement restores the state
+// that existed before its up migration ran. Higher values are worse.
+type Reversibility int
+
+const (
+	ReversibilityFull Reversibility = iota
+	ReversibilityStructureOnly
+	ReversibilityManual
+)
+
+func (r Reversibility) String() string {
+	switch r {
+	case ReversibilityFull:
+		return "fully reversible"
+	case ReversibilityStructureOnly:
+		return "restores structure only"
+	case ReversibilityManual:
+		return "manual rollback required"
+	default:
+		return "unknown"
+	}
 }
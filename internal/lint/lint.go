@@ -0,0 +1,126 @@
+// Package lint checks a desired schema for mistakes PostgreSQL would only
+// report once a migration runs, without connecting to a database. Each rule of
+// the registry pairs a diagnostic code with a function that finds its
+// violations in a schema.Database.
+package lint
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// Rule is a check of a schema.
+type Rule struct {
+	// Code identifies the rule in findings, such as "DUPLICATE_RELATION".
+	Code        diag.Code
+	Description string
+	// Check returns the violations of the rule in db. Their Rule and Severity
+	// are filled in from the rule.
+	Check func(db *schema.Database) []Finding
+}
+
+// Finding is a violation of a rule, or a diagnostic of parsing the schema.
+type Finding struct {
+	Rule     diag.Code     `json:"rule"`
+	Severity diag.Severity `json:"severity"`
+	// Object names the object with its kind, such as "table public.orders".
+	Object  string `json:"object,omitempty"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// Location is where the object was declared, or an empty string.
+func (f *Finding) Location() string {
+	if f.File == "" {
+		return ""
+	}
+
+	return schema.SourceLocation{File: f.File, Line: f.Line}.String()
+}
+
+// Diagnostic returns the finding as a diagnostic of the command that found it.
+func (f *Finding) Diagnostic() diag.Warning {
+	return diag.Warning{
+		Code:       f.Rule,
+		Severity:   f.Severity,
+		Message:    f.Message,
+		ObjectName: f.Object,
+		File:       f.File,
+		Line:       f.Line,
+	}
+}
+
+// Report lists the findings of a schema, ordered by where their objects were
+// declared.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Errors returns the findings with SeverityError.
+func (r *Report) Errors() []Finding {
+	var errs []Finding
+
+	for _, finding := range r.Findings {
+		if finding.Severity == diag.SeverityError {
+			errs = append(errs, finding)
+		}
+	}
+
+	return errs
+}
+
+// Check runs every rule of the registry over db. The diagnostics of parsing
+// db, such as the parser's warnings, are reported with the findings.
+func Check(db *schema.Database, parsed []diag.Warning) *Report {
+	return CheckRules(db, Rules(), parsed)
+}
+
+// CheckRules is Check with the given registry.
+func CheckRules(db *schema.Database, rules []Rule, parsed []diag.Warning) *Report {
+	report := &Report{Findings: []Finding{}}
+
+	for i := range parsed {
+		report.Findings = append(report.Findings, Finding{
+			Rule:     parsed[i].Code,
+			Severity: parsed[i].Severity,
+			Object:   parsed[i].ObjectName,
+			Message:  parsed[i].Message,
+			File:     parsed[i].File,
+			Line:     parsed[i].Line,
+		})
+	}
+
+	for _, rule := range rules {
+		for _, finding := range rule.Check(db) {
+			finding.Rule = rule.Code
+			finding.Severity = diag.SeverityError
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.Object, b.Object),
+		)
+	})
+
+	return report
+}
+
+// finding is a violation by an object declared at source, which may be nil.
+func finding(object string, source *schema.SourceLocation, message string) Finding {
+	f := Finding{Object: object, Message: message}
+	if source != nil {
+		f.File = source.File
+		f.Line = source.Line
+	}
+
+	return f
+}
@@ -0,0 +1,690 @@
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// Rules returns the registry. A new rule is a new entry with its check
+// function and a diag code of its own.
+func Rules() []Rule {
+	return []Rule{
+		{
+			Code:        diag.CodeDuplicateRelation,
+			Description: "tables, views, sequences and indexes sharing a name in a schema",
+			Check:       checkDuplicateRelations,
+		},
+		{
+			Code:        diag.CodeDuplicateConstraint,
+			Description: "constraint names declared twice on a table",
+			Check:       checkDuplicateConstraints,
+		},
+		{
+			Code:        diag.CodeMissingReferencedTable,
+			Description: "foreign keys referencing undeclared tables",
+			Check:       checkReferencedTables,
+		},
+		{
+			Code:        diag.CodeMissingTriggerFunction,
+			Description: "triggers executing undeclared functions",
+			Check:       checkTriggerFunctionsExist,
+		},
+		{
+			Code:        diag.CodeInvalidTriggerFunction,
+			Description: "triggers executing functions that do not return trigger",
+			Check:       checkTriggerFunctionReturnTypes,
+		},
+		{
+			Code:        diag.CodeMissingViewRelation,
+			Description: "views reading undeclared tables and views",
+			Check:       checkViewRelations,
+		},
+		{
+			Code:        diag.CodeMissingViewColumn,
+			Description: "views reading columns their tables do not have",
+			Check:       checkViewColumns,
+		},
+		{
+			Code:        diag.CodeInvalidInterval,
+			Description: "TimescaleDB interval settings PostgreSQL rejects",
+			Check:       checkIntervals,
+		},
+		{
+			Code:        diag.CodeInvalidEnumLiteral,
+			Description: "defaults and CHECK constraints using undeclared enum values",
+			Check:       checkEnumLiterals,
+		},
+		{
+			Code:        diag.CodeInvalidTypedTableColumn,
+			Description: "typed table columns their composite types do not have",
+			Check:       checkTypedTables,
+		},
+	}
+}
+
+// builtinTriggerFunctions are the trigger functions of pg_catalog.
+var builtinTriggerFunctions = map[string]bool{ //nolint:gochecknoglobals
+	"suppress_redundant_updates_trigger": true,
+	"tsvector_update_trigger":            true,
+	"tsvector_update_trigger_column":     true,
+}
+
+// extensionTriggerFunctions are the trigger functions contrib extensions
+// create, by extension.
+var extensionTriggerFunctions = map[string][]string{ //nolint:gochecknoglobals
+	"autoinc":         {"autoinc"},
+	"insert_username": {"insert_username"},
+	"lo":              {"lo_manage"},
+	"moddatetime":     {"moddatetime"},
+	"refint":          {"check_primary_key", "check_foreign_key"},
+	"tcn":             {"triggered_change_notification"},
+}
+
+// objectKey identifies an object of a schema by its normalized names.
+func objectKey(schemaName, name string) string {
+	return schema.QualifiedName(schema.NormalizeSchemaName(schemaName),
+		schema.NormalizeIdentifier(name))
+}
+
+// relation is an entry of the namespace tables, views, sequences and indexes
+// share in a schema.
+type relation struct {
+	kind   string
+	object string
+	source *schema.SourceLocation
+	table  *schema.Table
+}
+
+// relations returns the relations of db in declaration order, with the same
+// key more than once where db declares a name twice.
+func relations(db *schema.Database) ([]string, []relation) {
+	var (
+		keys  []string
+		found []relation
+	)
+
+	add := func(
+		kind, schemaName, name string,
+		source *schema.SourceLocation,
+		table *schema.Table,
+	) {
+		keys = append(keys, objectKey(schemaName, name))
+		found = append(found, relation{
+			kind:   kind,
+			object: kind + " " + schema.QualifiedName(schemaName, name),
+			source: source,
+			table:  table,
+		})
+	}
+
+	indexes := func(schemaName string, indexes []schema.Index, source *schema.SourceLocation) {
+		for i := range indexes {
+			idx := &indexes[i]
+			add("index", schemaName, idx.Name, firstSource(idx.Source, source), nil)
+		}
+	}
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		add("table", table.Schema, table.Name, table.Source, table)
+		indexes(table.Schema, table.Indexes, table.Source)
+
+		if table.PartitionStrategy == nil {
+			continue
+		}
+
+		for j := range table.PartitionStrategy.Partitions {
+			partition := &table.PartitionStrategy.Partitions[j]
+			add("partition", table.Schema, partition.Name, table.Source, nil)
+			indexes(table.Schema, partition.Indexes, table.Source)
+		}
+	}
+
+	for i := range db.Views {
+		view := &db.Views[i]
+		add("view", view.Schema, view.Name, view.Source, nil)
+	}
+
+	for i := range db.MaterializedViews {
+		view := &db.MaterializedViews[i]
+		add("materialized view", view.Schema, view.Name, view.Source, nil)
+		indexes(view.Schema, view.Indexes, view.Source)
+	}
+
+	for i := range db.ContinuousAggregates {
+		agg := &db.ContinuousAggregates[i]
+		add("continuous aggregate", agg.Schema, agg.ViewName, nil, nil)
+		indexes(agg.Schema, agg.Indexes, nil)
+	}
+
+	for i := range db.Sequences {
+		seq := &db.Sequences[i]
+		add("sequence", seq.Schema, seq.Name, nil, nil)
+	}
+
+	return keys, found
+}
+
+// compareSources orders locations by file and line, with unknown ones last.
+func compareSources(a, b *schema.SourceLocation) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+}
+
+// firstSource is the first of the locations that is set.
+func firstSource(sources ...*schema.SourceLocation) *schema.SourceLocation {
+	for _, source := range sources {
+		if source != nil {
+			return source
+		}
+	}
+
+	return nil
+}
+
+func checkDuplicateRelations(db *schema.Database) []Finding {
+	keys, found := relations(db)
+
+	// The later declaration of a name is the duplicate, whichever kinds of
+	// relation the two are.
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return compareSources(found[a].source, found[b].source)
+	})
+
+	first := make(map[string]int)
+
+	var findings []Finding
+
+	for _, i := range order {
+		key := keys[i]
+
+		j, seen := first[key]
+		if !seen {
+			first[key] = i
+			continue
+		}
+
+		other := found[j].object
+		if source := found[j].source; source != nil {
+			other += " (" + source.String() + ")"
+		}
+
+		findings = append(findings, finding(found[i].object, found[i].source,
+			fmt.Sprintf("%s has the same name as %s", found[i].object, other)))
+	}
+
+	return findings
+}
+
+func checkDuplicateConstraints(db *schema.Database) []Finding {
+	var findings []Finding
+
+	check := func(
+		tableName string,
+		constraints []schema.Constraint,
+		source *schema.SourceLocation,
+	) {
+		seen := make(map[string]bool)
+
+		for i := range constraints {
+			name := schema.NormalizeIdentifier(constraints[i].Name)
+			if name == "" {
+				continue
+			}
+
+			if seen[name] {
+				object := "constraint " + constraints[i].Name + " on " + tableName
+				findings = append(findings, finding(object, source,
+					fmt.Sprintf("%s is declared more than once", object)))
+			}
+
+			seen[name] = true
+		}
+	}
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		check(table.QualifiedName(), table.Constraints, table.Source)
+
+		if table.PartitionStrategy == nil {
+			continue
+		}
+
+		for j := range table.PartitionStrategy.Partitions {
+			partition := &table.PartitionStrategy.Partitions[j]
+			check(schema.QualifiedName(table.Schema, partition.Name),
+				partition.Constraints, table.Source)
+		}
+	}
+
+	return findings
+}
+
+func checkReferencedTables(db *schema.Database) []Finding {
+	keys, found := relations(db)
+	tables := make(map[string]bool)
+
+	for i, key := range keys {
+		if found[i].kind == "table" || found[i].kind == "partition" {
+			tables[key] = true
+		}
+	}
+
+	var findings []Finding
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+
+		for j := range table.Constraints {
+			constraint := &table.Constraints[j]
+			if !constraint.IsForeignKey() ||
+				tables[objectKey(constraint.ReferencedSchema, constraint.ReferencedTable)] {
+				continue
+			}
+
+			object := "constraint " + constraint.Name + " on " + table.QualifiedName()
+			findings = append(findings, finding(object, table.Source,
+				fmt.Sprintf("foreign key %s references %s, which the schema does not declare",
+					object, constraint.QualifiedReferencedTable())))
+		}
+	}
+
+	return findings
+}
+
+func checkTriggerFunctionsExist(db *schema.Database) []Finding {
+	functions := make(map[string]bool)
+
+	for i := range db.Functions {
+		functions[objectKey(db.Functions[i].Schema, db.Functions[i].Name)] = true
+	}
+
+	for i := range db.Extensions {
+		for _, name := range extensionTriggerFunctions[strings.ToLower(db.Extensions[i].Name)] {
+			functions[objectKey(db.Extensions[i].Schema, name)] = true
+		}
+	}
+
+	managed := managedSchemas(db)
+
+	var findings []Finding
+
+	for i := range db.Triggers {
+		trigger := &db.Triggers[i]
+
+		functionSchema := schema.NormalizeSchemaName(trigger.FunctionSchema)
+		name := schema.NormalizeIdentifier(trigger.FunctionName)
+
+		if !managed[functionSchema] || functions[objectKey(functionSchema, name)] ||
+			(functionSchema == schema.DefaultSchema && builtinTriggerFunctions[name]) {
+			continue
+		}
+
+		object := "trigger " + trigger.Name + " on " + trigger.QualifiedTableName()
+		findings = append(findings, finding(object, trigger.Source,
+			fmt.Sprintf("%s executes %s(), which the schema does not declare",
+				object, trigger.QualifiedFunctionName())))
+	}
+
+	return findings
+}
+
+// query is the query of a view, materialized view or continuous aggregate.
+type query struct {
+	object string
+	source *schema.SourceLocation
+	sql    string
+}
+
+func queries(db *schema.Database) []query {
+	var found []query
+
+	for i := range db.Views {
+		view := &db.Views[i]
+		found = append(found, query{"view " + view.QualifiedName(), view.Source, view.Definition})
+	}
+
+	for i := range db.MaterializedViews {
+		view := &db.MaterializedViews[i]
+		found = append(found, query{
+			"materialized view " + view.QualifiedName(), view.Source, view.Definition,
+		})
+	}
+
+	for i := range db.ContinuousAggregates {
+		agg := &db.ContinuousAggregates[i]
+		found = append(found, query{
+			"continuous aggregate " + agg.QualifiedViewName(), nil, agg.Query,
+		})
+	}
+
+	return found
+}
+
+// namespace looks up the relations of a schema by qualified name, and by
+// name alone in whichever schema declares it first.
+type namespace struct {
+	byKey   map[string]*relation
+	byName  map[string]*relation
+	managed map[string]bool
+}
+
+func newNamespace(db *schema.Database) *namespace {
+	ns := &namespace{
+		byKey:   make(map[string]*relation),
+		byName:  make(map[string]*relation),
+		managed: managedSchemas(db),
+	}
+
+	keys, found := relations(db)
+
+	for i, key := range keys {
+		if _, exists := ns.byKey[key]; !exists {
+			ns.byKey[key] = &found[i]
+		}
+
+		_, name, _ := strings.Cut(key, ".")
+		if _, exists := ns.byName[name]; !exists {
+			ns.byName[name] = &found[i]
+		}
+	}
+
+	return ns
+}
+
+// resolvedRelation is a relation of a query with what the schema declares
+// under its name: found is nil when the schema does not have it, and external
+// is set when the name is not the schema's to declare.
+type resolvedRelation struct {
+	differ.QueryRelation
+	name     string
+	found    *relation
+	external bool
+}
+
+// resolve looks up the relations q reads. An unqualified name is looked up in
+// the default schema, and, failing that, in any schema that declares it.
+func (ns *namespace) resolve(q *query) []resolvedRelation {
+	read, ok := differ.QueryRelations(q.sql)
+	if !ok {
+		return nil
+	}
+
+	ctes := commonTableExpressions(q.sql)
+	resolved := make([]resolvedRelation, 0, len(read))
+
+	for _, rel := range read {
+		r := resolvedRelation{QueryRelation: rel, name: rel.Name}
+
+		if rel.Schema == "" {
+			r.found = ns.byKey[objectKey("", rel.Name)]
+			if r.found == nil {
+				r.found = ns.byName[rel.Name]
+			}
+
+			r.external = ctes[rel.Name] || strings.HasPrefix(rel.Name, "pg_")
+		} else {
+			r.name = schema.QualifiedName(rel.Schema, rel.Name)
+			r.found = ns.byKey[objectKey(rel.Schema, rel.Name)]
+			r.external = !ns.managed[rel.Schema]
+		}
+
+		resolved = append(resolved, r)
+	}
+
+	return resolved
+}
+
+// managedSchemas are the schemas db declares or declares objects in, whose
+// relations and functions it is expected to declare. Catalogs such as
+// pg_catalog and the schemas of extensions are not among them.
+func managedSchemas(db *schema.Database) map[string]bool {
+	managed := map[string]bool{schema.DefaultSchema: true}
+
+	for i := range db.Schemas {
+		managed[schema.NormalizeSchemaName(db.Schemas[i].Name)] = true
+	}
+
+	for i := range db.Tables {
+		managed[schema.NormalizeSchemaName(db.Tables[i].Schema)] = true
+	}
+
+	for i := range db.Views {
+		managed[schema.NormalizeSchemaName(db.Views[i].Schema)] = true
+	}
+
+	for i := range db.MaterializedViews {
+		managed[schema.NormalizeSchemaName(db.MaterializedViews[i].Schema)] = true
+	}
+
+	for i := range db.Functions {
+		managed[schema.NormalizeSchemaName(db.Functions[i].Schema)] = true
+	}
+
+	return managed
+}
+
+func checkViewRelations(db *schema.Database) []Finding {
+	ns := newNamespace(db)
+
+	var findings []Finding
+
+	for _, q := range queries(db) {
+		for _, rel := range ns.resolve(&q) {
+			if rel.found != nil || rel.external {
+				continue
+			}
+
+			findings = append(findings, finding(q.object, q.source,
+				fmt.Sprintf("%s reads %s, which the schema does not declare", q.object, rel.name)))
+		}
+	}
+
+	return findings
+}
+
+// systemColumns are the columns every table has without declaring them.
+var systemColumns = map[string]bool{ //nolint:gochecknoglobals
+	"tableoid": true,
+	"xmin":     true,
+	"cmin":     true,
+	"xmax":     true,
+	"cmax":     true,
+	"ctid":     true,
+}
+
+func checkViewColumns(db *schema.Database) []Finding {
+	ns := newNamespace(db)
+
+	var findings []Finding
+
+	for _, q := range queries(db) {
+		for _, rel := range ns.resolve(&q) {
+			if rel.found == nil || rel.external {
+				continue
+			}
+
+			// The columns of a typed table come from its type, and a table
+			// without columns may inherit them.
+			table := rel.found.table
+			if table == nil || table.TypedOf != "" || len(table.Columns) == 0 {
+				continue
+			}
+
+			for _, column := range rel.Columns {
+				if systemColumns[column] || slices.ContainsFunc(table.Columns,
+					func(col schema.Column) bool {
+						return schema.NormalizeIdentifier(col.Name) == column
+					}) {
+					continue
+				}
+
+				findings = append(findings, finding(q.object, q.source,
+					fmt.Sprintf("%s reads column %s.%s, which the table does not have",
+						q.object, table.QualifiedName(), column)))
+			}
+		}
+	}
+
+	return findings
+}
+
+// commonTableExpressions returns the names a query gives its WITH queries,
+// which its FROM clauses read like relations.
+func commonTableExpressions(sql string) map[string]bool {
+	names := make(map[string]bool)
+
+	tokens, err := parser.NewLexer(sql).Tokenize()
+	if err != nil {
+		return names
+	}
+
+	tokens = slices.DeleteFunc(tokens, func(tok parser.Token) bool {
+		return tok.Type == parser.TokenComment
+	})
+
+	is := func(i int, keyword string) bool {
+		return i < len(tokens) && strings.EqualFold(tokens[i].Literal, keyword)
+	}
+
+	// skipParens returns the position after the parenthesized group at i.
+	skipParens := func(i int) int {
+		depth := 0
+
+		for ; i < len(tokens); i++ {
+			switch tokens[i].Type {
+			case parser.TokenLParen:
+				depth++
+			case parser.TokenRParen:
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			default:
+			}
+		}
+
+		return i
+	}
+
+	for i := range tokens {
+		if !is(i, "WITH") {
+			continue
+		}
+
+		j := i + 1
+		if is(j, "RECURSIVE") {
+			j++
+		}
+
+		for j < len(tokens) {
+			name := schema.FoldIdentifier(tokens[j].Literal)
+
+			j++
+			if j < len(tokens) && tokens[j].Type == parser.TokenLParen {
+				j = skipParens(j)
+			}
+
+			if !is(j, "AS") {
+				break
+			}
+
+			j++
+			if is(j, "NOT") {
+				j++
+			}
+
+			if is(j, "MATERIALIZED") {
+				j++
+			}
+
+			if j >= len(tokens) || tokens[j].Type != parser.TokenLParen {
+				break
+			}
+
+			names[name] = true
+
+			j = skipParens(j)
+			if j >= len(tokens) || tokens[j].Type != parser.TokenComma {
+				break
+			}
+
+			j++
+		}
+	}
+
+	return names
+}
+
+func checkTriggerFunctionReturnTypes(db *schema.Database) []Finding {
+	var findings []Finding
+
+	for _, err := range schema.ValidateTriggerFunctions(db) {
+		findings = append(findings, finding("trigger "+err.Object, nil, err.Error()))
+	}
+
+	return findings
+}
+
+func checkIntervals(db *schema.Database) []Finding {
+	var findings []Finding
+
+	for _, err := range schema.ValidateIntervals(db) {
+		findings = append(findings, finding(err.Object, nil, err.Error()))
+	}
+
+	return findings
+}
+
+func checkEnumLiterals(db *schema.Database) []Finding {
+	var findings []Finding
+
+	for _, err := range schema.ValidateEnumLiterals(db) {
+		findings = append(findings,
+			finding("table "+err.Object, tableSource(db, err.Object), err.Error()))
+	}
+
+	return findings
+}
+
+func checkTypedTables(db *schema.Database) []Finding {
+	var findings []Finding
+
+	for _, err := range schema.ValidateTypedTables(db) {
+		findings = append(findings,
+			finding("table "+err.Object, tableSource(db, err.Object), err.Error()))
+	}
+
+	return findings
+}
+
+// tableSource is where the table with the qualified name was declared.
+func tableSource(db *schema.Database, qualifiedName string) *schema.SourceLocation {
+	for i := range db.Tables {
+		if db.Tables[i].QualifiedName() == qualifiedName {
+			return db.Tables[i].Source
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package lint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/lint"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// lintSQL lints sql parsed from schema.sql, so that findings carry locations.
+func lintSQL(t *testing.T, sql string) *lint.Report {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(path, []byte(sql), 0o600))
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseFile(path, db))
+	require.Empty(t, p.GetErrors())
+
+	return lint.Check(db, p.GetWarnings())
+}
+
+// ruleMessages returns the messages of the findings of the rule.
+func ruleMessages(report *lint.Report, rule diag.Code) []string {
+	var messages []string
+
+	for _, finding := range report.Findings {
+		if finding.Rule == rule {
+			messages = append(messages, finding.Message)
+		}
+	}
+
+	return messages
+}
+
+func TestCheck_CleanSchema(t *testing.T) {
+	t.Parallel()
+
+	report := lintSQL(t, `
+CREATE EXTENSION moddatetime;
+CREATE SCHEMA sales;
+CREATE TABLE sales.customers (id bigint PRIMARY KEY, name text, updated_at timestamptz);
+CREATE TABLE sales.orders (
+    id bigint PRIMARY KEY,
+    customer_id bigint REFERENCES sales.customers (id),
+    total numeric
+);
+CREATE INDEX orders_customer_idx ON sales.orders (customer_id);
+CREATE FUNCTION sales.touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER customers_touch BEFORE UPDATE ON sales.customers
+    FOR EACH ROW EXECUTE FUNCTION sales.touch();
+CREATE TRIGGER customers_moddatetime BEFORE UPDATE ON sales.customers
+    FOR EACH ROW EXECUTE FUNCTION moddatetime(updated_at);
+CREATE VIEW sales.order_totals AS
+    WITH big AS (SELECT id FROM sales.orders WHERE total > 100)
+    SELECT c.name, sum(o.total) AS total, EXTRACT(year FROM now()) AS year
+    FROM sales.orders o
+    JOIN sales.customers c ON c.id = o.customer_id
+    JOIN big ON big.id = o.id
+    CROSS JOIN pg_catalog.pg_database d
+    CROSS JOIN LATERAL jsonb_array_elements('[]') e
+    GROUP BY c.name;
+`)
+
+	assert.Empty(t, report.Findings)
+	assert.Empty(t, report.Errors())
+}
+
+func TestCheck_DuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	report := lintSQL(t, `
+CREATE TABLE orders (
+    id bigint PRIMARY KEY,
+    total numeric,
+    CONSTRAINT orders_total_check CHECK (total > 0),
+    CONSTRAINT orders_total_check CHECK (total < 1000)
+);
+CREATE INDEX orders_pkey ON orders (total);
+CREATE VIEW orders_total_idx AS SELECT total FROM orders;
+CREATE INDEX orders_total_idx ON orders (total);
+`)
+
+	duplicates := ruleMessages(report, diag.CodeDuplicateRelation)
+	require.Len(t, duplicates, 1)
+	assert.Contains(t, duplicates[0],
+		"index public.orders_total_idx has the same name as view public.orders_total_idx (")
+	assert.Contains(t, duplicates[0], "schema.sql:9)")
+	assert.Equal(t, []string{
+		"constraint orders_total_check on public.orders is declared more than once",
+	}, ruleMessages(report, diag.CodeDuplicateConstraint))
+
+	redefined := ruleMessages(report, diag.CodeDuplicateDefinition)
+	require.Len(t, redefined, 1)
+	assert.Contains(t, redefined[0], "index public.orders_pkey is defined again")
+}
+
+func TestCheck_MissingReferences(t *testing.T) {
+	t.Parallel()
+
+	report := lintSQL(t, `
+CREATE TABLE orders (
+    id bigint PRIMARY KEY,
+    customer_id bigint REFERENCES customers (id),
+    total numeric
+);
+CREATE TRIGGER orders_audit AFTER INSERT ON orders
+    FOR EACH ROW EXECUTE FUNCTION audit_orders();
+CREATE VIEW order_totals AS
+    SELECT o.id, o.totl, a.id AS archived
+    FROM orders o
+    LEFT JOIN archived_orders a ON a.id = o.id
+    LEFT JOIN reporting.orders r ON r.id = o.id;
+`)
+
+	assert.Equal(t, []string{
+		"foreign key constraint orders_customer_id_fkey on public.orders references " +
+			"public.customers, which the schema does not declare",
+	}, ruleMessages(report, diag.CodeMissingReferencedTable))
+	assert.Equal(t, []string{
+		"trigger orders_audit on public.orders executes public.audit_orders(), " +
+			"which the schema does not declare",
+	}, ruleMessages(report, diag.CodeMissingTriggerFunction))
+	assert.Equal(t, []string{
+		"view public.order_totals reads archived_orders, which the schema does not declare",
+	}, ruleMessages(report, diag.CodeMissingViewRelation))
+	assert.Equal(t, []string{
+		"view public.order_totals reads column public.orders.totl, " +
+			"which the table does not have",
+	}, ruleMessages(report, diag.CodeMissingViewColumn))
+
+	for _, finding := range report.Findings {
+		assert.Equal(t, diag.SeverityError, finding.Severity)
+	}
+}
+
+func TestCheck_ReportsParserDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	report := lintSQL(t, `
+CREATE TABLE orders (id bigint PRIMARY KEY);
+COMMENT ON TABLE missing IS 'gone';
+`)
+
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, diag.CodeObjectNotFound, report.Findings[0].Rule)
+	assert.Equal(t, diag.SeverityWarning, report.Findings[0].Severity)
+	assert.Empty(t, report.Errors())
+}
+
+func TestCheck_FindingsCarrySourceLocations(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "orders.sql")
+	require.NoError(t, os.WriteFile(path, []byte(`CREATE TABLE orders (id bigint PRIMARY KEY);
+
+CREATE TRIGGER orders_audit AFTER INSERT ON orders
+    FOR EACH ROW EXECUTE FUNCTION audit_orders();`), 0o600))
+
+	db := &schema.Database{}
+	require.NoError(t, parser.New().ParseFile(path, db))
+
+	report := lint.Check(db, nil)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, path+":3", report.Findings[0].Location())
+
+	diagnostic := report.Findings[0].Diagnostic()
+	assert.Equal(t, diag.CodeMissingTriggerFunction, diagnostic.Code)
+	assert.Equal(t, "trigger orders_audit on public.orders", diagnostic.ObjectName)
+}
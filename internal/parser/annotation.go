@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// migrationAnnotationPrefix starts a comment that pins the objects a
+// statement declares to a named migration, such as
+// "-- pgtofu:migration add_payouts_tables".
+const migrationAnnotationPrefix = "pgtofu:migration"
+
+var migrationNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// migrationAnnotation returns the migration named by a pgtofu:migration line
+// comment among the comments leading a statement, or "" when there is none.
+func migrationAnnotation(tokens []Token) (string, error) {
+	for _, token := range tokens {
+		if token.Type != TokenComment {
+			return "", nil
+		}
+
+		text, ok := strings.CutPrefix(token.Literal, "--")
+		if !ok {
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(strings.TrimSpace(text), migrationAnnotationPrefix)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		name := strings.TrimSpace(rest)
+		if !migrationNamePattern.MatchString(name) {
+			return "", NewParseError(
+				"pgtofu:migration needs a migration name of letters, digits and underscores",
+			)
+		}
+
+		return name, nil
+	}
+
+	return "", nil
+}
+
+// usingAnnotationPrefix starts a comment on a column of CREATE TABLE giving
+// the USING expression that converts its values when its type changes, such
+// as "-- pgtofu:using (extract(epoch from created_at))::bigint".
+const usingAnnotationPrefix = "pgtofu:using"
+
+// columnUsingAnnotations maps the columns of the first parenthesized list in
+// tokens to the expressions of their pgtofu:using comments. A comment inside
+// a column definition, or after it on the same line, belongs to that column;
+// a comment on a line of its own belongs to the column that follows.
+func columnUsingAnnotations(tokens []Token) (map[string]string, error) {
+	usings := make(map[string]string)
+
+	var (
+		depth              int
+		element, previous  string
+		pending            string
+		previousLineEndsAt = -1
+	)
+
+	for _, token := range tokens {
+		switch {
+		case token.Type == TokenComment:
+			if depth == 0 {
+				continue
+			}
+
+			expr, ok, err := usingAnnotation(token.Literal)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case !ok:
+			case element != "":
+				usings[element] = expr
+			case previous != "" && token.Line == previousLineEndsAt:
+				usings[previous] = expr
+			default:
+				pending = expr
+			}
+		case token.Type == TokenLParen:
+			depth++
+		case token.Type == TokenRParen:
+			depth--
+			if depth == 0 {
+				return usings, nil
+			}
+		case depth == 1 && token.Type == TokenComma:
+			previous, element, previousLineEndsAt = element, "", token.Line
+		case depth == 1 && element == "":
+			element = schema.FoldIdentifier(token.Literal)
+			if pending != "" {
+				usings[element], pending = pending, ""
+			}
+		}
+	}
+
+	return usings, nil
+}
+
+// usingAnnotation returns the expression of a pgtofu:using line comment, and
+// whether comment is one.
+func usingAnnotation(comment string) (string, bool, error) {
+	text, ok := strings.CutPrefix(comment, "--")
+	if !ok {
+		return "", false, nil
+	}
+
+	rest, ok := strings.CutPrefix(strings.TrimSpace(text), usingAnnotationPrefix)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false, nil
+	}
+
+	expr := strings.TrimSpace(rest)
+	if expr == "" {
+		return "", false, NewParseError(
+			"pgtofu:using needs the expression converting the values of the column",
+		)
+	}
+
+	return expr, true, nil
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProgressFunc is called after each statement is parsed with the file being
+// parsed ("" for inline SQL) and the number of statements done so far in it.
+type ProgressFunc func(file string, done, total int)
+
+// WithProgress sets a callback invoked after every parsed statement.
+func WithProgress(progress ProgressFunc) Option {
+	return func(p *Parser) {
+		p.progress = progress
+	}
+}
+
+// bindContext makes ctx the cancellation context for the duration of a public
+// parse call and returns the function that unbinds it. Nested calls (ParseFile
+// calling ParseSQL) keep the outermost context.
+func (p *Parser) bindContext(ctx context.Context) func() {
+	if p.cancel != nil {
+		return func() {}
+	}
+
+	p.cancel = ctx
+
+	return func() {
+		p.cancel = nil
+	}
+}
+
+func (p *Parser) checkCancelled(done, total int) error {
+	if p.cancel == nil {
+		return nil
+	}
+
+	err := p.cancel.Err()
+	if err == nil {
+		return nil
+	}
+
+	location := "inline SQL"
+	if file := p.getCurrentFile(); file != "" {
+		location = file
+	}
+
+	return fmt.Errorf("parse cancelled in %s after %d of %d statements: %w",
+		location, done, total, err)
+}
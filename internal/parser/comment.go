@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -18,6 +19,10 @@ const (
 	commentObjectFunction
 	commentObjectExtension
 	commentObjectTypeAlias
+	commentObjectConstraint
+	commentObjectIndex
+	commentObjectTrigger
+	commentObjectSchema
 )
 
 type commentStatement struct {
@@ -25,11 +30,17 @@ type commentStatement struct {
 	schemaName   string
 	objectName   string
 	columnName   string
+	memberName   string
 	functionArgs []string
+	procedure    bool
 	commentText  string
 	isNull       bool
 }
 
+func (s *commentStatement) qualifiedName() string {
+	return schema.QualifiedName(s.schemaName, s.objectName)
+}
+
 func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint:cyclop
 	parsed, err := p.parseCommentStatement(stmt)
 	if err != nil {
@@ -54,7 +65,9 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 		}
 
 		p.addWarning(
+			diag.CodeObjectNotFound,
 			0,
+			parsed.qualifiedName(),
 			fmt.Sprintf("table %s.%s not found for comment", parsed.schemaName, parsed.objectName),
 		)
 
@@ -62,7 +75,9 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 		table := db.GetTable(parsed.schemaName, parsed.objectName)
 		if table == nil {
 			p.addWarning(
+				diag.CodeObjectNotFound,
 				0,
+				parsed.qualifiedName(),
 				fmt.Sprintf(
 					"table %s.%s not found for column comment",
 					parsed.schemaName,
@@ -77,7 +92,9 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 			col.Comment = commentValue
 		} else {
 			p.addWarning(
+				diag.CodeObjectNotFound,
 				0,
+				parsed.qualifiedName()+"."+parsed.columnName,
 				fmt.Sprintf(
 					"column %s not found in table %s.%s",
 					parsed.columnName,
@@ -99,7 +116,9 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 		}
 
 		p.addWarning(
+			diag.CodeObjectNotFound,
 			0,
+			parsed.qualifiedName(),
 			fmt.Sprintf("view %s.%s not found for comment", parsed.schemaName, parsed.objectName),
 		)
 
@@ -118,7 +137,9 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 		}
 
 		p.addWarning(
+			diag.CodeObjectNotFound,
 			0,
+			parsed.qualifiedName(),
 			fmt.Sprintf(
 				"materialized view %s.%s not found for comment",
 				parsed.schemaName,
@@ -127,16 +148,23 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 		)
 
 	case commentObjectFunction:
-		fn := db.GetFunction(parsed.schemaName, parsed.objectName, parsed.functionArgs)
-		if fn != nil {
+		lookup, kind := db.GetFunction, "function"
+		if parsed.procedure {
+			lookup, kind = db.GetProcedure, "procedure"
+		}
+
+		if fn := lookup(parsed.schemaName, parsed.objectName, parsed.functionArgs); fn != nil {
 			fn.Comment = commentValue
 			return nil
 		}
 
 		p.addWarning(
+			diag.CodeObjectNotFound,
 			0,
+			parsed.qualifiedName(),
 			fmt.Sprintf(
-				"function %s.%s not found for comment",
+				"%s %s.%s not found for comment",
+				kind,
 				parsed.schemaName,
 				parsed.objectName,
 			),
@@ -150,7 +178,12 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 			}
 		}
 
-		p.addWarning(0, fmt.Sprintf("extension %s not found for comment", parsed.objectName))
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			parsed.objectName,
+			fmt.Sprintf("extension %s not found for comment", parsed.objectName),
+		)
 
 	case commentObjectTypeAlias:
 		for i := range db.CustomTypes {
@@ -162,12 +195,157 @@ func (p *Parser) parseComment(stmt string, db *schema.Database) error { //nolint
 		}
 
 		p.addWarning(
+			diag.CodeObjectNotFound,
 			0,
+			parsed.qualifiedName(),
 			fmt.Sprintf("type %s.%s not found for comment", parsed.schemaName, parsed.objectName),
 		)
 
+	case commentObjectConstraint:
+		return p.applyConstraintComment(parsed, commentValue, db)
+
+	case commentObjectIndex:
+		if idx := findIndex(db, parsed.schemaName, parsed.objectName); idx != nil {
+			idx.Comment = commentValue
+			return nil
+		}
+
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			parsed.qualifiedName(),
+			fmt.Sprintf("index %s.%s not found for comment", parsed.schemaName, parsed.objectName),
+		)
+
+	case commentObjectTrigger:
+		for i := range db.Triggers {
+			trigger := &db.Triggers[i]
+			if trigger.Schema == parsed.schemaName && trigger.TableName == parsed.objectName &&
+				trigger.Name == parsed.memberName {
+				trigger.Comment = commentValue
+				return nil
+			}
+		}
+
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			parsed.qualifiedName()+"."+parsed.memberName,
+			fmt.Sprintf(
+				"trigger %s not found on %s.%s",
+				parsed.memberName,
+				parsed.schemaName,
+				parsed.objectName,
+			),
+		)
+
+	case commentObjectSchema:
+		p.applySchemaComment(parsed, commentValue, db)
+
 	default:
-		p.addWarning(0, "unsupported COMMENT ON statement")
+		p.addWarning(diag.CodeSkippedStatement, 0, "", "unsupported COMMENT ON statement")
+	}
+
+	return nil
+}
+
+func (p *Parser) applyConstraintComment(
+	parsed *commentStatement,
+	commentValue string,
+	db *schema.Database,
+) error {
+	table := db.GetTable(parsed.schemaName, parsed.objectName)
+	if table == nil {
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			parsed.qualifiedName(),
+			fmt.Sprintf(
+				"table %s.%s not found for constraint comment",
+				parsed.schemaName,
+				parsed.objectName,
+			),
+		)
+
+		return nil
+	}
+
+	if constraint := table.GetConstraint(parsed.memberName); constraint != nil {
+		constraint.Comment = commentValue
+		return nil
+	}
+
+	p.addWarning(
+		diag.CodeObjectNotFound,
+		0,
+		parsed.qualifiedName()+"."+parsed.memberName,
+		fmt.Sprintf(
+			"constraint %s not found in table %s.%s",
+			parsed.memberName,
+			parsed.schemaName,
+			parsed.objectName,
+		),
+	)
+
+	return nil
+}
+
+// applySchemaComment sets the comment of a schema. public is seldom declared
+// with CREATE SCHEMA, so a comment on it declares it.
+func (p *Parser) applySchemaComment(
+	parsed *commentStatement,
+	commentValue string,
+	db *schema.Database,
+) {
+	for i := range db.Schemas {
+		if db.Schemas[i].Name == parsed.objectName {
+			db.Schemas[i].Comment = commentValue
+			return
+		}
+	}
+
+	if parsed.objectName == schema.DefaultSchema {
+		db.Schemas = append(db.Schemas, schema.Schema{Name: parsed.objectName, Comment: commentValue})
+		return
+	}
+
+	p.addWarning(
+		diag.CodeObjectNotFound,
+		0,
+		parsed.objectName,
+		fmt.Sprintf("schema %s not found for comment", parsed.objectName),
+	)
+}
+
+// findIndex returns the index of a table, materialized view or continuous
+// aggregate of the schema by name. Index names are unique within a schema.
+func findIndex(db *schema.Database, schemaName, name string) *schema.Index {
+	var groups [][]schema.Index
+
+	for i := range db.Tables {
+		if db.Tables[i].Schema == schemaName {
+			groups = append(groups, db.Tables[i].Indexes)
+		}
+	}
+
+	for i := range db.MaterializedViews {
+		if db.MaterializedViews[i].Schema == schemaName {
+			groups = append(groups, db.MaterializedViews[i].Indexes)
+		}
+	}
+
+	for i := range db.ContinuousAggregates {
+		if db.ContinuousAggregates[i].Schema == schemaName {
+			groups = append(groups, db.ContinuousAggregates[i].Indexes)
+		}
+	}
+
+	for _, indexes := range groups {
+		for i := range indexes {
+			if indexes[i].Name == name {
+				return &indexes[i]
+			}
+		}
 	}
 
 	return nil
@@ -226,8 +404,9 @@ func (p *Parser) parseCommentStatement(stmt string) (*commentStatement, error) {
 
 		return p.populateTableLikeComment(statement, stmt, tokens, nameStart)
 
-	case "FUNCTION":
+	case "FUNCTION", "PROCEDURE":
 		statement.objectType = commentObjectFunction
+		statement.procedure = upperLiteral(tokens, objIdx) == "PROCEDURE"
 		nameStart := nextNonCommentIndex(tokens, objIdx+1)
 
 		return p.populateFunctionComment(statement, stmt, tokens, nameStart)
@@ -244,9 +423,27 @@ func (p *Parser) parseCommentStatement(stmt string) (*commentStatement, error) {
 
 		return p.populateTableLikeComment(statement, stmt, tokens, nameStart)
 
+	case "CONSTRAINT", "TRIGGER":
+		statement.objectType = commentObjectConstraint
+		if upperLiteral(tokens, objIdx) == "TRIGGER" {
+			statement.objectType = commentObjectTrigger
+		}
+
+		nameStart := nextNonCommentIndex(tokens, objIdx+1)
+
+		return p.populateTableMemberComment(statement, stmt, tokens, nameStart)
+
 	case "INDEX":
-		p.addWarning(0, "index comments not yet supported")
-		return nil, nil //nolint:nilnil
+		statement.objectType = commentObjectIndex
+		nameStart := nextNonCommentIndex(tokens, objIdx+1)
+
+		return p.populateTableLikeComment(statement, stmt, tokens, nameStart)
+
+	case "SCHEMA":
+		statement.objectType = commentObjectSchema
+		nameStart := nextNonCommentIndex(tokens, objIdx+1)
+
+		return p.populateSchemaComment(statement, stmt, tokens, nameStart)
 
 	default:
 		return nil, NewParseError("unsupported COMMENT ON target")
@@ -339,6 +536,62 @@ func (p *Parser) populateColumnComment(
 	return statement, nil
 }
 
+// populateTableMemberComment reads the "name ON table" target of COMMENT ON
+// CONSTRAINT and COMMENT ON TRIGGER. Constraints of domains are not tracked
+// and are skipped.
+func (p *Parser) populateTableMemberComment(
+	statement *commentStatement,
+	stmt string,
+	tokens []Token,
+	startIdx int,
+) (*commentStatement, error) {
+	kind := "constraint"
+	if statement.objectType == commentObjectTrigger {
+		kind = "trigger"
+	}
+
+	isIdx := findKeyword(tokens, "IS", startIdx)
+	if isIdx == -1 {
+		return nil, NewParseError("missing IS keyword")
+	}
+
+	onIdx := findKeyword(tokens, "ON", startIdx)
+	if onIdx == -1 || onIdx > isIdx {
+		return nil, NewParseError("missing ON keyword in " + kind + " comment")
+	}
+
+	startIdx = nextNonCommentIndex(tokens, startIdx)
+	if startIdx >= onIdx {
+		return nil, NewParseError("missing " + kind + " name")
+	}
+
+	tableIdx := nextNonCommentIndex(tokens, onIdx+1)
+	if tableIdx >= isIdx {
+		return nil, NewParseError("missing " + kind + " table")
+	}
+
+	if statement.objectType == commentObjectConstraint && upperLiteral(tokens, tableIdx) == "DOMAIN" {
+		p.addWarning(diag.CodeSkippedStatement, 0, "", "domain constraint comments not supported")
+		return nil, nil //nolint:nilnil
+	}
+
+	name := strings.TrimSpace(stmt[tokens[startIdx].Start:tokens[onIdx].Start])
+	literal := strings.TrimSpace(stmt[tokens[tableIdx].Start:tokens[isIdx].Start])
+
+	statement.memberName = p.normalizeIdent(name)
+	statement.schemaName, statement.objectName = p.splitSchemaTable(literal)
+
+	commentText, isNull, err := parseCommentText(tokens, isIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	statement.commentText = commentText
+	statement.isNull = isNull
+
+	return statement, nil
+}
+
 func (p *Parser) populateFunctionComment(
 	statement *commentStatement,
 	stmt string,
@@ -380,6 +633,40 @@ func (p *Parser) populateFunctionComment(
 	return statement, nil
 }
 
+func (p *Parser) populateSchemaComment(
+	statement *commentStatement,
+	stmt string,
+	tokens []Token,
+	startIdx int,
+) (*commentStatement, error) {
+	isIdx := findKeyword(tokens, "IS", startIdx)
+	if isIdx == -1 {
+		return nil, NewParseError("missing IS keyword")
+	}
+
+	startIdx = nextNonCommentIndex(tokens, startIdx)
+	if startIdx >= isIdx {
+		return nil, NewParseError("missing schema name")
+	}
+
+	literal := strings.TrimSpace(stmt[tokens[startIdx].Start:tokens[isIdx].Start])
+	if literal == "" {
+		return nil, NewParseError("empty schema name")
+	}
+
+	statement.objectName = p.normalizeIdent(literal)
+
+	commentText, isNull, err := parseCommentText(tokens, isIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	statement.commentText = commentText
+	statement.isNull = isNull
+
+	return statement, nil
+}
+
 func (p *Parser) populateExtensionComment(
 	statement *commentStatement,
 	stmt string,
@@ -53,4 +53,7 @@ var (
 	ErrUnexpectedToken   = errors.New("unexpected token")
 	ErrUnknownStatement  = errors.New("unknown statement type")
 	ErrMissingIdentifier = errors.New("missing identifier")
+
+	ErrCreateTableAs = errors.New("CREATE TABLE ... AS is not supported")
+	ErrSelectInto    = errors.New("SELECT ... INTO is not supported")
 )
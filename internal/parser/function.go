@@ -2,6 +2,7 @@ package parser
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
@@ -21,9 +22,10 @@ type functionStatement struct {
 	isStrict    bool
 	securityDef bool
 	definition  string
+	kind        string
 }
 
-func (p *Parser) parseCreateFunction(stmt string, db *schema.Database) error {
+func (p *Parser) parseCreateFunction(stmt string, line int, db *schema.Database) error {
 	parsed, err := p.parseFunctionStatement(stmt)
 	if err != nil || parsed == nil {
 		return err
@@ -42,10 +44,13 @@ func (p *Parser) parseCreateFunction(stmt string, db *schema.Database) error {
 		Definition:        parsed.definition,
 		IsStrict:          parsed.isStrict,
 		IsSecurityDefiner: parsed.securityDef,
+		Kind:              parsed.kind,
+		Source:            p.sourceAt(line),
 	}
 
 	for i, existing := range db.Functions {
-		if existing.Schema == parsed.schemaName && existing.Name == parsed.funcName &&
+		if existing.Kind == parsed.kind &&
+			existing.Schema == parsed.schemaName && existing.Name == parsed.funcName &&
 			equalStringSlices(existing.ArgumentTypes, parsed.argTypes) {
 			db.Functions[i] = fn
 			return nil
@@ -83,8 +88,13 @@ func (p *Parser) parseFunctionStatement(stmt string) (*functionStatement, error)
 		idx = nextNonCommentIndex(tokens, replaceIdx+1)
 	}
 
-	if idx >= len(tokens) || upperLiteral(tokens, idx) != "FUNCTION" {
-		return nil, NewParseError("expected FUNCTION keyword")
+	var kind string
+
+	switch {
+	case idx < len(tokens) && upperLiteral(tokens, idx) == "PROCEDURE":
+		kind = schema.FunctionKindProcedure
+	case idx >= len(tokens) || upperLiteral(tokens, idx) != "FUNCTION":
+		return nil, NewParseError("expected FUNCTION or PROCEDURE keyword")
 	}
 
 	nameIdx := nextNonCommentIndex(tokens, idx+1)
@@ -116,10 +126,14 @@ func (p *Parser) parseFunctionStatement(stmt string) (*functionStatement, error)
 
 	argNames, argTypes, argModes := parseFunctionArguments(argsLiteral)
 
+	// A procedure returns nothing, not even void.
 	returnType := "void"
+	if kind == schema.FunctionKindProcedure {
+		returnType = ""
+	}
 
 	retIdx := findKeyword(tokens, "RETURNS", nextIdx)
-	if retIdx != -1 {
+	if retIdx != -1 && kind == "" {
 		retLiteral, afterRet := collectLiteralUntil(
 			tokens,
 			stmt,
@@ -153,7 +167,7 @@ func (p *Parser) parseFunctionStatement(stmt string) (*functionStatement, error)
 		}
 	}
 
-	body := p.extractFunctionBody(stmt)
+	body := p.extractFunctionBody(stmt, tokens, nextIdx)
 
 	volatility := schema.VolatilityVolatile
 
@@ -183,6 +197,7 @@ func (p *Parser) parseFunctionStatement(stmt string) (*functionStatement, error)
 		isStrict:    isStrict,
 		securityDef: securityDef,
 		definition:  stmt,
+		kind:        kind,
 	}, nil
 }
 
@@ -200,6 +215,27 @@ func (p *Parser) parseFunctionName(literal string) (string, string, error) {
 	return schemaName, funcName, nil
 }
 
+// multiWordTypes are the built-in type names written as several words, or
+// their first words, that an argument without a name can start with.
+var multiWordTypes = []string{ //nolint:gochecknoglobals
+	"bit varying", "char varying", "character varying", "double precision",
+	"interval", "time with", "time without", "timestamp with", "timestamp without",
+}
+
+// isUnnamedArgumentType reports whether the words of an argument are its type
+// alone, such as character varying, rather than a name and a type.
+func isUnnamedArgumentType(parts []string) bool {
+	if strings.ContainsAny(parts[0], "([") {
+		return true
+	}
+
+	words := strings.ToLower(strings.Join(parts, " "))
+
+	return slices.ContainsFunc(multiWordTypes, func(typeName string) bool {
+		return words == typeName || strings.HasPrefix(words, typeName+" ")
+	})
+}
+
 func parseFunctionArguments(argsLiteral string) ([]string, []string, []string) {
 	if strings.TrimSpace(argsLiteral) == "" {
 		return nil, nil, nil
@@ -232,7 +268,7 @@ func parseFunctionArguments(argsLiteral string) ([]string, []string, []string) {
 			offset = 1
 		}
 
-		if len(parts) > offset+1 {
+		if len(parts) > offset+1 && !isUnnamedArgumentType(parts[offset:]) {
 			argNames = append(argNames, parts[offset])
 			argTypes = append(argTypes, strings.Join(parts[offset+1:], " "))
 		} else if len(parts) > offset {
@@ -246,16 +282,20 @@ func parseFunctionArguments(argsLiteral string) ([]string, []string, []string) {
 	return argNames, argTypes, argModes
 }
 
-func (p *Parser) extractFunctionBody(stmt string) string {
-	dollarPattern := regexp.MustCompile(`\$([a-zA-Z_][a-zA-Z0-9_]*)?\$`)
-	matches := dollarPattern.FindAllStringIndex(stmt, -1)
-
-	if len(matches) >= 2 {
-		start := matches[0][1]
+// extractFunctionBody returns the first dollar-quoted string following an AS
+// from start on, or else a single-quoted body. The lexer reads a dollar-quoted
+// body as one token whatever its tag, so semicolons and dollar quotes with
+// other tags inside it are part of the body.
+func (p *Parser) extractFunctionBody(stmt string, tokens []Token, start int) string {
+	for i := findKeyword(tokens, "AS", start); i != -1; i = findKeyword(tokens, "AS", i+1) {
+		bodyIdx := nextNonCommentIndex(tokens, i+1)
+		if bodyIdx >= len(tokens) || tokens[bodyIdx].Type != TokenString ||
+			!strings.HasPrefix(tokens[bodyIdx].Literal, "$") {
+			continue
+		}
 
-		end := matches[len(matches)-1][0]
-		if start < end {
-			return strings.TrimSpace(stmt[start:end])
+		if body, err := decodeStringLiteral(tokens[bodyIdx].Literal); err == nil {
+			return strings.TrimSpace(body)
 		}
 	}
 
@@ -281,7 +321,7 @@ type triggerStatement struct {
 	definition     string
 }
 
-func (p *Parser) parseCreateTrigger(stmt string, db *schema.Database) error {
+func (p *Parser) parseCreateTrigger(stmt string, line int, db *schema.Database) error {
 	parsed, err := p.parseTriggerStatement(stmt)
 	if err != nil || parsed == nil {
 		return err
@@ -299,6 +339,7 @@ func (p *Parser) parseCreateTrigger(stmt string, db *schema.Database) error {
 		FunctionSchema: parsed.functionSchema,
 		FunctionName:   parsed.functionName,
 		Definition:     parsed.definition,
+		Source:         p.sourceAt(line),
 	}
 
 	for i, existing := range db.Triggers {
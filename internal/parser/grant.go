@@ -0,0 +1,510 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// privilegeMaintain is the PostgreSQL 17 MAINTAIN privilege, which is not
+// managed: it is dropped from the statements read as it is from the
+// extracted grants.
+const privilegeMaintain = "MAINTAIN"
+
+var errUnsupportedGrant = errors.New("unsupported grant")
+
+// grantObjectKinds are the object kinds GRANT ON takes besides TABLE. Grants
+// on them are not managed.
+//
+//nolint:gochecknoglobals
+var grantObjectKinds = map[string]bool{
+	"ALL": true, "DATABASE": true, "DOMAIN": true, "FOREIGN": true, "FUNCTION": true,
+	"LANGUAGE": true, "LARGE": true, "PARAMETER": true, "PROCEDURE": true, "ROUTINE": true,
+	"SCHEMA": true, "SEQUENCE": true, "TABLESPACE": true, "TYPE": true,
+}
+
+// grantStatement is a GRANT or REVOKE of privileges on tables.
+type grantStatement struct {
+	revoke bool
+	// grantOption is WITH GRANT OPTION on a GRANT and GRANT OPTION FOR on a
+	// REVOKE.
+	grantOption bool
+	privileges  []grantPrivilege
+	objects     []string
+	grantees    []string
+}
+
+// grantPrivilege is a privilege of a GRANT or REVOKE, ALL for ALL
+// [PRIVILEGES], on the table or on the listed columns.
+type grantPrivilege struct {
+	name    string
+	columns []string
+}
+
+// isTableGrant reports whether tokens are a GRANT or REVOKE of privileges on
+// tables, views or materialized views, rather than on other objects or of
+// role membership.
+func isTableGrant(tokens []Token) bool {
+	depth := 0
+
+	for i := range tokens {
+		switch tokens[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+		case TokenKeyword, TokenIdentifier:
+			if depth == 0 && upperLiteral(tokens, i) == "ON" {
+				return !grantObjectKinds[upperLiteral(tokens, nextNonCommentIndex(tokens, i+1))]
+			}
+		}
+	}
+
+	return false
+}
+
+// parseGrant reads GRANT and REVOKE of privileges on tables, views and
+// materialized views, and on their columns, into the grants of db. A REVOKE
+// takes privileges away from the grants read before it and keeps an empty
+// grant for the grantee, so the privileges are revoked from the database too.
+// Grants on other objects and of role membership are skipped.
+func (p *Parser) parseGrant(stmt string, line int, db *schema.Database) error {
+	parsed, err := p.parseGrantStatement(stmt)
+	if errors.Is(err, errUnsupportedGrant) {
+		p.addWarning(diag.CodeSkippedStatement, line, "", "unsupported statement: "+truncate(stmt, 50))
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, object := range parsed.objects {
+		schemaName, name := p.splitSchemaTable(object)
+		if !hasRelation(db, schemaName, name) {
+			qualified := schema.QualifiedName(schemaName, name)
+			p.addWarning(
+				diag.CodeObjectNotFound,
+				0,
+				qualified,
+				fmt.Sprintf("table %s not found for grant", qualified),
+			)
+
+			continue
+		}
+
+		for _, grantee := range parsed.grantees {
+			for _, privilege := range parsed.privileges {
+				columns := privilege.columns
+				if len(columns) == 0 {
+					columns = []string{""}
+				}
+
+				for _, column := range columns {
+					target := schema.Grant{
+						Schema:     schemaName,
+						ObjectName: name,
+						ObjectType: schema.GrantObjectTable,
+						Column:     column,
+						Grantee:    grantee,
+						Source:     p.sourceAt(line),
+					}
+
+					if column != "" {
+						target.ObjectType = schema.GrantObjectColumn
+					}
+
+					privileges := expandPrivilege(privilege.name, column != "")
+
+					switch {
+					case !parsed.revoke:
+						grantPrivileges(db, &target, privileges, parsed.grantOption)
+					case parsed.grantOption:
+						revokeGrantOption(db, &target, privileges)
+					default:
+						revokePrivileges(db, &target, privileges)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseGrantStatement reads GRANT privileges ON [TABLE] name, ... TO role,
+// ... [WITH GRANT OPTION] [GRANTED BY role] and REVOKE [GRANT OPTION FOR]
+// privileges ON [TABLE] name, ... FROM role, ... [GRANTED BY role] [CASCADE |
+// RESTRICT]. Other forms return errUnsupportedGrant.
+func (p *Parser) parseGrantStatement(stmt string) (*grantStatement, error) { //nolint:cyclop,funlen
+	tokens, err := NewLexer(stmt).Tokenize()
+	if err != nil {
+		return nil, WrapParseError(err, "tokenizing grant statement")
+	}
+
+	parsed := &grantStatement{}
+
+	idx := nextNonCommentIndex(tokens, 0)
+	switch upperLiteral(tokens, idx) {
+	case "GRANT":
+	case "REVOKE":
+		parsed.revoke = true
+	default:
+		return nil, NewParseError("expected GRANT or REVOKE keyword")
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if parsed.revoke && upperLiteral(tokens, idx) == "GRANT" {
+		optionIdx := nextNonCommentIndex(tokens, idx+1)
+		forIdx := nextNonCommentIndex(tokens, optionIdx+1)
+
+		if upperLiteral(tokens, optionIdx) != "OPTION" || upperLiteral(tokens, forIdx) != "FOR" {
+			return nil, NewParseError("expected GRANT OPTION FOR")
+		}
+
+		parsed.grantOption = true
+		idx = nextNonCommentIndex(tokens, forIdx+1)
+	}
+
+	parsed.privileges, idx, err = p.readGrantPrivileges(tokens, idx)
+	if err != nil {
+		return nil, err
+	}
+
+	if upperLiteral(tokens, idx) != "ON" {
+		return nil, errUnsupportedGrant
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if kind := upperLiteral(tokens, idx); kind == "TABLE" {
+		idx = nextNonCommentIndex(tokens, idx+1)
+	} else if grantObjectKinds[kind] {
+		return nil, errUnsupportedGrant
+	}
+
+	if err := checkTablePrivileges(parsed.privileges); err != nil {
+		return nil, err
+	}
+
+	for {
+		name, next := readQualifiedName(tokens, idx)
+		if name == "" {
+			return nil, NewParseError("missing grant table")
+		}
+
+		parsed.objects = append(parsed.objects, name)
+
+		idx = nextNonCommentIndex(tokens, next)
+		if idx >= len(tokens) || tokens[idx].Type != TokenComma {
+			break
+		}
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	keyword := "TO"
+	if parsed.revoke {
+		keyword = "FROM"
+	}
+
+	if upperLiteral(tokens, idx) != keyword {
+		return nil, NewParseError("expected " + keyword)
+	}
+
+	parsed.grantees, idx, err = p.readGrantees(tokens, nextNonCommentIndex(tokens, idx+1))
+	if err != nil {
+		return nil, err
+	}
+
+	for idx < len(tokens) {
+		switch upperLiteral(tokens, idx) {
+		case "WITH":
+			optionIdx := nextNonCommentIndex(tokens, idx+1)
+			if parsed.revoke || upperLiteral(tokens, optionIdx) != "GRANT" ||
+				upperLiteral(tokens, nextNonCommentIndex(tokens, optionIdx+1)) != "OPTION" {
+				return nil, NewParseError("expected WITH GRANT OPTION")
+			}
+
+			parsed.grantOption = true
+			idx = nextNonCommentIndex(tokens, nextNonCommentIndex(tokens, optionIdx+1)+1)
+		case "GRANTED":
+			byIdx := nextNonCommentIndex(tokens, idx+1)
+			if upperLiteral(tokens, byIdx) != "BY" {
+				return nil, NewParseError("expected GRANTED BY")
+			}
+
+			idx = nextNonCommentIndex(tokens, nextNonCommentIndex(tokens, byIdx+1)+1)
+		case "CASCADE", "RESTRICT":
+			idx = nextNonCommentIndex(tokens, idx+1)
+		default:
+			if tokens[idx].Type == TokenSemicolon || tokens[idx].Type == TokenEOF {
+				return parsed, nil
+			}
+
+			return nil, NewParseError("unexpected " + tokens[idx].Literal + " in grant")
+		}
+	}
+
+	return parsed, nil
+}
+
+// readGrantPrivileges reads the comma-separated privileges of a GRANT or
+// REVOKE up to ON, each with its optional column list.
+func (p *Parser) readGrantPrivileges(tokens []Token, idx int) ([]grantPrivilege, int, error) {
+	var privileges []grantPrivilege
+
+	for idx < len(tokens) {
+		token := tokens[idx]
+		if token.Type != TokenKeyword && token.Type != TokenIdentifier {
+			return nil, idx, errUnsupportedGrant
+		}
+
+		privilege := grantPrivilege{name: upperLiteral(tokens, idx)}
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+		if privilege.name == "ALL" && upperLiteral(tokens, idx) == "PRIVILEGES" {
+			idx = nextNonCommentIndex(tokens, idx+1)
+		}
+
+		if idx < len(tokens) && tokens[idx].Type == TokenLParen {
+			for idx = nextNonCommentIndex(tokens, idx+1); idx < len(tokens); {
+				if tokens[idx].Type != TokenIdentifier && tokens[idx].Type != TokenQuotedIdentifier &&
+					tokens[idx].Type != TokenKeyword {
+					return nil, idx, NewParseError("invalid column list in grant")
+				}
+
+				privilege.columns = append(privilege.columns, p.normalizeIdent(tokens[idx].Literal))
+
+				idx = nextNonCommentIndex(tokens, idx+1)
+				if idx < len(tokens) && tokens[idx].Type == TokenComma {
+					idx = nextNonCommentIndex(tokens, idx+1)
+					continue
+				}
+
+				if idx >= len(tokens) || tokens[idx].Type != TokenRParen {
+					return nil, idx, NewParseError("unterminated column list in grant")
+				}
+
+				idx = nextNonCommentIndex(tokens, idx+1)
+
+				break
+			}
+		}
+
+		privileges = append(privileges, privilege)
+
+		if idx >= len(tokens) || tokens[idx].Type != TokenComma {
+			break
+		}
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	return privileges, idx, nil
+}
+
+// checkTablePrivileges rejects privileges that tables or columns do not have.
+func checkTablePrivileges(privileges []grantPrivilege) error {
+	for _, privilege := range privileges {
+		valid := schema.TablePrivileges
+		if len(privilege.columns) > 0 {
+			valid = schema.ColumnPrivileges
+		}
+
+		if privilege.name != "ALL" && privilege.name != privilegeMaintain &&
+			!slices.Contains(valid, privilege.name) {
+			return NewParseError("invalid privilege " + privilege.name + " in grant")
+		}
+	}
+
+	return nil
+}
+
+// readGrantees reads the comma-separated roles of a TO or FROM clause. PUBLIC
+// is recorded in lowercase; CURRENT_USER and the like name no role that can
+// be compared and return errUnsupportedGrant.
+func (p *Parser) readGrantees(tokens []Token, idx int) ([]string, int, error) {
+	var grantees []string
+
+	for idx < len(tokens) {
+		if upperLiteral(tokens, idx) == "GROUP" && tokens[idx].Type != TokenQuotedIdentifier {
+			idx = nextNonCommentIndex(tokens, idx+1)
+		}
+
+		token := tokens[idx]
+		if token.Type != TokenIdentifier && token.Type != TokenQuotedIdentifier &&
+			token.Type != TokenKeyword {
+			break
+		}
+
+		if token.Type != TokenQuotedIdentifier {
+			switch upperLiteral(tokens, idx) {
+			case "CURRENT_USER", "CURRENT_ROLE", "SESSION_USER":
+				return nil, idx, errUnsupportedGrant
+			}
+		}
+
+		grantees = append(grantees, p.normalizeIdent(token.Literal))
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+		if idx >= len(tokens) || tokens[idx].Type != TokenComma {
+			break
+		}
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	if len(grantees) == 0 {
+		return nil, idx, NewParseError("missing grantee")
+	}
+
+	return grantees, idx, nil
+}
+
+// expandPrivilege returns the privileges name stands for on a table or a
+// column.
+func expandPrivilege(name string, column bool) []string {
+	switch {
+	case name == privilegeMaintain:
+		return nil
+	case name != "ALL":
+		return []string{name}
+	case column:
+		return slices.Clone(schema.ColumnPrivileges)
+	default:
+		return slices.Clone(schema.TablePrivileges)
+	}
+}
+
+func hasRelation(db *schema.Database, schemaName, name string) bool {
+	return db.GetTable(schemaName, name) != nil || db.GetView(schemaName, name) != nil ||
+		db.GetMaterializedView(schemaName, name) != nil
+}
+
+// grantPrivileges adds privileges to the grant of target. A privilege is held
+// either with the grant option or without it, so granting it with the option
+// moves it out of the grant without, and granting it without the option
+// leaves it with the option when it already has it.
+func grantPrivileges(db *schema.Database, target *schema.Grant, privileges []string, withOption bool) {
+	if withOption {
+		if plain := lookupGrant(db, target, false); plain != nil {
+			plain.Privileges = removePrivileges(plain.Privileges, privileges)
+		}
+
+		grant := ensureGrant(db, target, true)
+		grant.Privileges = addPrivileges(grant.Privileges, privileges)
+
+		return
+	}
+
+	if option := lookupGrant(db, target, true); option != nil {
+		privileges = removePrivileges(privileges, option.Privileges)
+	}
+
+	grant := ensureGrant(db, target, false)
+	grant.Privileges = addPrivileges(grant.Privileges, privileges)
+}
+
+// revokePrivileges takes privileges away from the grants of target, with and
+// without the grant option, and keeps an empty grant without it. Revoking
+// a privilege on a table revokes it on the table's columns as well.
+func revokePrivileges(db *schema.Database, target *schema.Grant, privileges []string) {
+	for i := range db.Grants {
+		if grant := &db.Grants[i]; sameGrantTarget(grant, target, target.Column == "") {
+			grant.Privileges = removePrivileges(grant.Privileges, privileges)
+		}
+	}
+
+	dropEmptyGrants(db, target, target.Column == "")
+
+	ensureGrant(db, target, false)
+}
+
+// revokeGrantOption moves privileges of the grant of target with the grant
+// option to the one without it.
+func revokeGrantOption(db *schema.Database, target *schema.Grant, privileges []string) {
+	option := lookupGrant(db, target, true)
+	if option == nil {
+		return
+	}
+
+	var moved []string
+
+	for _, privilege := range privileges {
+		if slices.Contains(option.Privileges, privilege) {
+			moved = append(moved, privilege)
+		}
+	}
+
+	option.Privileges = removePrivileges(option.Privileges, moved)
+
+	grant := ensureGrant(db, target, false)
+	grant.Privileges = addPrivileges(grant.Privileges, moved)
+
+	dropEmptyGrants(db, target, false)
+}
+
+func lookupGrant(db *schema.Database, target *schema.Grant, withOption bool) *schema.Grant {
+	return db.GetGrant(target.Schema, target.ObjectName, target.Column, target.Grantee, withOption)
+}
+
+func ensureGrant(db *schema.Database, target *schema.Grant, withOption bool) *schema.Grant {
+	if grant := lookupGrant(db, target, withOption); grant != nil {
+		return grant
+	}
+
+	grant := *target
+	grant.Privileges = []string{}
+	grant.WithGrantOption = withOption
+	db.Grants = append(db.Grants, grant)
+
+	return &db.Grants[len(db.Grants)-1]
+}
+
+// dropEmptyGrants removes the grants of target left without privileges, the
+// grant of its own column without the grant option excepted, and those of
+// every column of its object too when columns is set.
+func dropEmptyGrants(db *schema.Database, target *schema.Grant, columns bool) {
+	db.Grants = slices.DeleteFunc(db.Grants, func(grant schema.Grant) bool {
+		if len(grant.Privileges) > 0 || !sameGrantTarget(&grant, target, columns) {
+			return false
+		}
+
+		own := schema.NormalizeIdentifier(grant.Column) == schema.NormalizeIdentifier(target.Column)
+
+		return !own || grant.WithGrantOption
+	})
+}
+
+// sameGrantTarget reports whether grant is to the grantee of target on its
+// object and column, or on any column of the object when columns is set.
+func sameGrantTarget(grant, target *schema.Grant, columns bool) bool {
+	if schema.NormalizeSchemaName(grant.Schema) != schema.NormalizeSchemaName(target.Schema) ||
+		schema.NormalizeIdentifier(grant.ObjectName) != schema.NormalizeIdentifier(target.ObjectName) ||
+		grant.GranteeName() != target.GranteeName() {
+		return false
+	}
+
+	return columns || schema.NormalizeIdentifier(grant.Column) == schema.NormalizeIdentifier(target.Column)
+}
+
+func addPrivileges(held, privileges []string) []string {
+	for _, privilege := range privileges {
+		if !slices.Contains(held, privilege) {
+			held = append(held, privilege)
+		}
+	}
+
+	slices.Sort(held)
+
+	return held
+}
+
+func removePrivileges(held, privileges []string) []string {
+	return slices.DeleteFunc(slices.Clone(held), func(privilege string) bool {
+		return slices.Contains(privileges, privilege)
+	})
+}
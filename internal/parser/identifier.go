@@ -5,15 +5,20 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
+// identPattern matches one name written bare or in double quotes, and
+// qualifiedNamePattern a name that may be qualified by its schema.
+const (
+	identPattern         = `(?:[a-zA-Z_][a-zA-Z0-9_]*|"(?:[^"]|"")*")`
+	qualifiedNamePattern = identPattern + `(?:\.` + identPattern + `)?`
+)
+
 var (
-	quotedIdentRe = regexp.MustCompile(`^"([^"]*)"$`)
-	schemaTableRe = regexp.MustCompile(
-		`^(?:([a-zA-Z_][a-zA-Z0-9_]*|"[^"]*")\.)?([a-zA-Z_][a-zA-Z0-9_]*|"[^"]*")$`,
-	)
-	identifierRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	schemaTableRe = regexp.MustCompile(`^(?:(` + identPattern + `)\.)?(` + identPattern + `)$`)
+	identifierRe  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
 type IdentifierNormalizer struct {
@@ -24,24 +29,15 @@ func NewIdentifierNormalizer(caseSensitive bool) *IdentifierNormalizer {
 	return &IdentifierNormalizer{caseSensitive: caseSensitive}
 }
 
+// Normalize returns the name PostgreSQL stores for ident: a quoted name
+// keeps its case and an unquoted one is folded to lower case. A
+// case-sensitive normalizer keeps unquoted names as they are written too.
 func (n *IdentifierNormalizer) Normalize(ident string) string {
-	ident = strings.TrimSpace(ident)
-
-	var name string
-
-	if matches := quotedIdentRe.FindStringSubmatch(ident); matches != nil {
-		if n.caseSensitive {
-			name = matches[1]
-		} else {
-			name = strings.ToLower(matches[1])
-		}
-	} else if n.caseSensitive {
-		name = ident
-	} else {
-		name = strings.ToLower(ident)
+	if n.caseSensitive {
+		return schema.NormalizeIdentifier(ident)
 	}
 
-	return schema.TruncateIdentifier(name)
+	return schema.FoldIdentifier(ident)
 }
 
 func (n *IdentifierNormalizer) SplitQualified(qualified string) (schemaName, objectName string) {
@@ -109,10 +105,7 @@ func (n *IdentifierNormalizer) IsValidIdentifier(ident string) bool {
 }
 
 func (n *IdentifierNormalizer) IsKeyword(ident string) bool {
-	ident = strings.ToUpper(strings.TrimSpace(ident))
-	_, ok := keywordSet[ident]
-
-	return ok
+	return isKeyword(strings.TrimSpace(ident))
 }
 
 func (p *Parser) normalizeIdent(ident string) string {
@@ -141,3 +134,37 @@ func unquote(s string) string {
 
 	return s
 }
+
+// warnLongIdentifiers reports each name of a statement that is longer than
+// PostgreSQL keeps. The server cuts it silently, so two such names that
+// share their first 63 bytes name the same object.
+func (p *Parser) warnLongIdentifiers(stmt Statement) {
+	seen := make(map[string]bool)
+
+	for _, tok := range stmt.Tokens {
+		if tok.Type != TokenIdentifier && tok.Type != TokenQuotedIdentifier {
+			continue
+		}
+
+		name := tok.Literal
+		if tok.Type == TokenQuotedIdentifier {
+			name = strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+		}
+
+		if len(name) <= schema.MaxIdentifierLength || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+
+		line := tok.Line
+		if line == 0 {
+			line = stmt.Line
+		}
+
+		p.addWarning(diag.CodeIdentifierTooLong, line, "", fmt.Sprintf(
+			"identifier %s is %d bytes long; PostgreSQL keeps only the first %d, %s",
+			name, len(name), schema.MaxIdentifierLength, schema.TruncateIdentifier(name),
+		))
+	}
+}
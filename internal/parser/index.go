@@ -2,8 +2,11 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -19,9 +22,30 @@ type indexStatement struct {
 	whereClause      string
 	storageParams    map[string]string
 	definition       string
+	ifNotExists      bool
+	onlyParent       bool
 }
 
-func (p *Parser) parseCreateIndex(stmt string, db *schema.Database) error {
+// warnRedefinedIndex reports an index declared under the name of an index of
+// the same table, which replaces it. PostgreSQL refuses the second CREATE
+// INDEX unless it says IF NOT EXISTS.
+func (p *Parser) warnRedefinedIndex(existing, idx *schema.Index, line int) {
+	if idx.IfNotExists {
+		return
+	}
+
+	qualified := schema.QualifiedName(idx.Schema, idx.Name)
+
+	message := "index " + qualified + " is defined again"
+	if existing.Source != nil {
+		message += " (first defined at " + existing.Source.String() + ")"
+	}
+
+	p.addWarning(diag.CodeDuplicateDefinition, line, qualified,
+		message+"; PostgreSQL refuses to create it twice without IF NOT EXISTS")
+}
+
+func (p *Parser) parseCreateIndex(stmt string, line int, db *schema.Database) error {
 	parsed, err := p.parseIndexStatement(stmt)
 	if err != nil || parsed == nil {
 		return err
@@ -40,12 +64,39 @@ func (p *Parser) parseCreateIndex(stmt string, db *schema.Database) error {
 		IncludeColumns:   parsed.includeCols,
 		StorageParams:    parsed.storageParams,
 		Definition:       parsed.definition,
+		IfNotExists:      parsed.ifNotExists,
+		OnlyParent:       parsed.onlyParent,
+		Source:           p.sourceAt(line),
+	}
+
+	if idx.Name == "" {
+		idx.Name = defaultIndexName(db, &idx)
+		parsed.indexName = idx.Name
+	}
+
+	// An index on a partition is local to it until ALTER INDEX ... ATTACH
+	// PARTITION makes it the copy of a partitioned index.
+	if _, partition := p.findPartition(db, parsed.tableSchema, parsed.tableName); partition != nil {
+		partition.Indexes = slices.DeleteFunc(partition.Indexes, func(existing schema.Index) bool {
+			if existing.Name != idx.Name {
+				return false
+			}
+
+			p.warnRedefinedIndex(&existing, &idx, line)
+
+			return true
+		})
+		partition.Indexes = append(partition.Indexes, idx)
+
+		return nil
 	}
 
 	if table := db.GetTable(parsed.tableSchema, parsed.tableName); table != nil {
 		for i, existing := range table.Indexes {
 			if existing.Name == parsed.indexName {
+				p.warnRedefinedIndex(&existing, &idx, line)
 				table.Indexes[i] = idx
+
 				return nil
 			}
 		}
@@ -58,7 +109,9 @@ func (p *Parser) parseCreateIndex(stmt string, db *schema.Database) error {
 	if mv := db.GetMaterializedView(parsed.tableSchema, parsed.tableName); mv != nil {
 		for i, existing := range mv.Indexes {
 			if existing.Name == parsed.indexName {
+				p.warnRedefinedIndex(&existing, &idx, line)
 				mv.Indexes[i] = idx
+
 				return nil
 			}
 		}
@@ -71,7 +124,9 @@ func (p *Parser) parseCreateIndex(stmt string, db *schema.Database) error {
 	if ca := db.GetContinuousAggregate(parsed.tableSchema, parsed.tableName); ca != nil {
 		for i, existing := range ca.Indexes {
 			if existing.Name == parsed.indexName {
+				p.warnRedefinedIndex(&existing, &idx, line)
 				ca.Indexes[i] = idx
+
 				return nil
 			}
 		}
@@ -82,7 +137,9 @@ func (p *Parser) parseCreateIndex(stmt string, db *schema.Database) error {
 	}
 
 	p.addWarning(
+		diag.CodeObjectNotFound,
 		0,
+		schema.QualifiedName(parsed.tableSchema, parsed.tableName),
 		fmt.Sprintf(
 			"table %s.%s not found for index %s",
 			parsed.tableSchema,
@@ -94,6 +151,50 @@ func (p *Parser) parseCreateIndex(stmt string, db *schema.Database) error {
 	return nil
 }
 
+// parseAlterIndex accepts ALTER INDEX ... ATTACH PARTITION, which pg_dump
+// writes to attach each partition's index to the partitioned parent index.
+// The attached index stops being local to its partition. Other ALTER INDEX
+// statements are skipped like any unsupported statement.
+func (p *Parser) parseAlterIndex(stmt Statement, db *schema.Database) {
+	sql := stmt.NormalizedSQL()
+
+	if tokens, err := NewLexer(sql).Tokenize(); err == nil {
+		idx := nextNonCommentIndex(tokens, 0)    // ALTER
+		idx = nextNonCommentIndex(tokens, idx+1) // INDEX
+		idx = nextNonCommentIndex(tokens, idx+1)
+
+		if upperLiteral(tokens, idx) == "IF" {
+			idx = nextNonCommentIndex(tokens, nextNonCommentIndex(tokens, idx+1)+1)
+		}
+
+		_, idx = readQualifiedName(tokens, idx)
+		idx = nextNonCommentIndex(tokens, idx)
+
+		if upperLiteral(tokens, idx) == "ATTACH" &&
+			upperLiteral(tokens, nextNonCommentIndex(tokens, idx+1)) == "PARTITION" {
+			partitionIdx := nextNonCommentIndex(tokens, idx+1)
+
+			childLiteral, _ := readQualifiedName(
+				tokens,
+				nextNonCommentIndex(tokens, partitionIdx+1),
+			)
+			if childLiteral != "" {
+				schemaName, indexName := p.splitSchemaTable(childLiteral)
+				p.attachPartitionIndex(db, schemaName, indexName)
+			}
+
+			return
+		}
+	}
+
+	p.addWarning(
+		diag.CodeSkippedStatement,
+		stmt.Line,
+		"",
+		"unsupported statement: "+truncate(sql, 50),
+	)
+}
+
 func (p *Parser) parseIndexStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 	stmt string,
 ) (*indexStatement, error) {
@@ -129,7 +230,10 @@ func (p *Parser) parseIndexStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 		idx = nextNonCommentIndex(tokens, idx+1)
 	}
 
+	ifNotExists := false
 	if idx < len(tokens) && upperLiteral(tokens, idx) == "IF" {
+		ifNotExists = true
+
 		if nextNonCommentIndex(tokens, idx+1) >= len(tokens) ||
 			upperLiteral(tokens, idx+1) != "NOT" ||
 			nextNonCommentIndex(tokens, idx+2) >= len(tokens) ||
@@ -144,13 +248,19 @@ func (p *Parser) parseIndexStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 		return nil, NewParseError("missing index name")
 	}
 
-	nameToken := tokens[idx]
-	if nameToken.Type != TokenIdentifier && nameToken.Type != TokenQuotedIdentifier {
-		return nil, NewParseError("invalid index name")
-	}
+	// The name is optional; an unnamed index is named after its table and
+	// columns once they are known, see parseCreateIndex.
+	indexName := ""
 
-	indexName := p.normalizeIdent(nameToken.Literal)
-	idx = nextNonCommentIndex(tokens, idx+1)
+	if upperLiteral(tokens, idx) != "ON" {
+		nameToken := tokens[idx]
+		if nameToken.Type != TokenIdentifier && nameToken.Type != TokenQuotedIdentifier {
+			return nil, NewParseError("invalid index name")
+		}
+
+		indexName = p.normalizeIdent(nameToken.Literal)
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
 
 	if idx >= len(tokens) || upperLiteral(tokens, idx) != "ON" {
 		return nil, NewParseError("expected ON clause")
@@ -178,7 +288,9 @@ func (p *Parser) parseIndexStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 		return nil, NewParseError("missing table reference")
 	}
 
+	onlyParent := false
 	if strings.HasPrefix(strings.ToUpper(tableLiteral), "ONLY ") {
+		onlyParent = true
 		tableLiteral = strings.TrimSpace(tableLiteral[4:])
 	}
 
@@ -307,6 +419,8 @@ func (p *Parser) parseIndexStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 		whereClause:      strings.TrimSpace(whereClause),
 		storageParams:    storageParams,
 		definition:       stmt,
+		ifNotExists:      ifNotExists,
+		onlyParent:       onlyParent,
 	}, nil
 }
 
@@ -391,3 +505,94 @@ func (p *Parser) normalizeIndexColumn(colExpr string) string {
 
 	return strings.Join(parts, " ")
 }
+
+// defaultIndexName picks the name PostgreSQL gives an index declared without
+// one: the table name, the key and INCLUDE column names and "idx", joined
+// with underscores and cut to the identifier length limit, with a number
+// appended when the name is already taken by another index of the table.
+func defaultIndexName(db *schema.Database, idx *schema.Index) string {
+	columns := make([]string, 0, len(idx.Columns)+len(idx.IncludeColumns))
+	for _, col := range idx.Columns {
+		columns = append(columns, indexColumnName(col))
+	}
+
+	columns = append(columns, idx.IncludeColumns...)
+
+	base := makeObjectName(idx.TableName, strings.Join(columns, "_"), "idx")
+	taken := make(map[string]bool)
+
+	for _, existing := range existingIndexes(db, idx.Schema, idx.TableName) {
+		taken[existing.Name] = true
+	}
+
+	name := base
+	for n := 1; taken[name]; n++ {
+		name = makeObjectName(idx.TableName, strings.Join(columns, "_"), fmt.Sprintf("idx%d", n))
+	}
+
+	return name
+}
+
+func existingIndexes(db *schema.Database, schemaName, tableName string) []schema.Index {
+	if table := db.GetTable(schemaName, tableName); table != nil {
+		return table.Indexes
+	}
+
+	if mv := db.GetMaterializedView(schemaName, tableName); mv != nil {
+		return mv.Indexes
+	}
+
+	if ca := db.GetContinuousAggregate(schemaName, tableName); ca != nil {
+		return ca.Indexes
+	}
+
+	return nil
+}
+
+// indexColumnName is the name an index key contributes to a generated index
+// name: the column itself, the function name for a function call, or "expr"
+// for any other expression.
+func indexColumnName(col string) string {
+	col = strings.TrimSpace(col)
+
+	if !strings.ContainsAny(col, "()") {
+		if fields := strings.Fields(col); len(fields) > 0 {
+			return strings.Trim(fields[0], `"`)
+		}
+	}
+
+	if match := indexFunctionCallPattern.FindStringSubmatch(col); match != nil {
+		return strings.ToLower(match[1])
+	}
+
+	return "expr"
+}
+
+var indexFunctionCallPattern = regexp.MustCompile(`^\(*\s*([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// makeObjectName joins name1, name2 and label with underscores, shortening
+// the longer of name1 and name2 until the result fits in an identifier.
+func makeObjectName(name1, name2, label string) string {
+	overhead := len(label) + 1
+	if name2 != "" {
+		overhead++
+	}
+
+	available := schema.MaxIdentifierLength - overhead
+	len1, len2 := len(name1), len(name2)
+
+	for len1+len2 > available {
+		if len1 > len2 {
+			len1--
+		} else {
+			len2--
+		}
+	}
+
+	parts := []string{name1[:len1]}
+	if name2 != "" {
+		parts = append(parts, name2[:len2])
+	}
+
+	return strings.Join(append(parts, label), "_")
+}
@@ -53,6 +53,13 @@ var (
 	errUnterminatedComment     = errors.New("unterminated comment")
 )
 
+// tokenBytesEstimate is roughly how many bytes of SQL make up a token.
+const tokenBytesEstimate = 6
+
+// maxKeywordLength is at least the length of the longest entry in keywordSet,
+// so longer ASCII words are never keywords.
+const maxKeywordLength = 32
+
 var keywordSet = map[string]struct{}{ //nolint:gochecknoglobals
 	"ADD":          {},
 	"ALTER":        {},
@@ -136,7 +143,9 @@ func NewLexer(input string) *Lexer {
 }
 
 func (l *Lexer) Tokenize() ([]Token, error) {
-	var tokens []Token
+	// Schema files average a token every six or so bytes; sizing for that up
+	// front saves regrowing the slice many times over on large files.
+	tokens := make([]Token, 0, len(l.input)/tokenBytesEstimate+1)
 
 	for {
 		token, err := l.nextToken()
@@ -273,10 +282,9 @@ func (l *Lexer) readIdentifier(startPos, startLine, startCol int) Token {
 	}
 
 	literal := l.input[startPos:l.pos]
-	upper := strings.ToUpper(literal)
 
 	tokenType := TokenIdentifier
-	if _, ok := keywordSet[upper]; ok {
+	if isKeyword(literal) {
 		tokenType = TokenKeyword
 	}
 
@@ -290,6 +298,37 @@ func (l *Lexer) readIdentifier(startPos, startLine, startCol int) Token {
 	}
 }
 
+// isKeyword reports whether word is a keyword, ignoring case. The lexer checks
+// every identifier it reads, so ASCII words are uppercased into a buffer on the
+// stack rather than a new string.
+func isKeyword(word string) bool {
+	var buf [maxKeywordLength]byte
+
+	for i := range len(word) {
+		c := word[i]
+		if c >= utf8.RuneSelf {
+			_, ok := keywordSet[strings.ToUpper(word)]
+			return ok
+		}
+
+		if i < len(buf) {
+			if 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+
+			buf[i] = c
+		}
+	}
+
+	if len(word) > len(buf) {
+		return false
+	}
+
+	_, ok := keywordSet[string(buf[:len(word)])]
+
+	return ok
+}
+
 func (l *Lexer) readNumber(startPos, startLine, startCol int) Token {
 	hasDot := false
 
@@ -493,33 +532,27 @@ func (l *Lexer) consumeDollarTag() (string, error) {
 		return "", errInvalidDollarTag
 	}
 
-	var builder strings.Builder
+	start := l.pos
 
-	r, _ := l.advance()
-	builder.WriteRune(r)
+	l.advance()
 
 	for {
 		ch := l.peek()
-		if ch == 0 {
-			return "", errUnterminatedDollarQuote
-		}
-
 		if ch == '$' {
-			r, _ = l.advance()
-			builder.WriteRune(r)
-
+			l.advance()
 			break
 		}
 
-		if !isDollarTagChar(ch) {
+		// A tag follows the rules of an unquoted identifier without "$": it
+		// cannot start with a digit, so $1 is a parameter, not a tag.
+		if !isDollarTagChar(ch) || (l.pos == start+1 && unicode.IsDigit(ch)) {
 			return "", errInvalidDollarTag
 		}
 
-		r, _ = l.advance()
-		builder.WriteRune(r)
+		l.advance()
 	}
 
-	return builder.String(), nil
+	return l.input[start:l.pos], nil
 }
 
 func (l *Lexer) consumeSingleCharacter(
@@ -637,8 +670,11 @@ func isIdentifierStart(r rune) bool {
 	return r == '_' || unicode.IsLetter(r) || r >= 0x80
 }
 
+// isIdentifierPart includes "$", which PostgreSQL allows in identifiers after
+// their first character. A dollar quote directly after a word is therefore
+// part of it, as it is to PostgreSQL.
 func isIdentifierPart(r rune) bool {
-	return isIdentifierStart(r) || unicode.IsDigit(r)
+	return isIdentifierStart(r) || unicode.IsDigit(r) || r == '$'
 }
 
 func isDollarTagChar(r rune) bool {
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 	"github.com/accented-ai/pgtofu/internal/util"
 )
@@ -21,6 +23,16 @@ type parseContext struct {
 	errors      []ParseError
 	warnings    []Warning
 	deferred    []deferredPartition
+	// deferredColumns are column attributes set on tables not parsed yet.
+	deferredColumns []columnAttribute
+	// deferredTypedTables are the typed tables whose columns are derived
+	// from their types.
+	deferredTypedTables []typedTable
+	// refreshed are the views of the REFRESH MATERIALIZED VIEW statements
+	// ignored so far, which refreshWarning, the position of their warning in
+	// warnings plus one, reports together.
+	refreshed      []string
+	refreshWarning int
 }
 
 type Parser struct {
@@ -31,20 +43,31 @@ type Parser struct {
 	registry   *ParserRegistry
 	ctx        *parseContext
 	deferred   []deferredPartition
+
+	deferredColumns     []columnAttribute
+	deferredTypedTables []typedTable
+
+	tableSources          map[string]tableSource
+	describeTableConflict TableConflictDescriber
+	// migration is the pgtofu:migration annotation of the statement being
+	// parsed.
+	migration string
+	// pgDump is set by WithPgDump.
+	pgDump bool
+
+	cancel   context.Context //nolint:containedctx // scoped to a single *Context call
+	progress ProgressFunc
+	skipped  SkippedStatementFunc
 }
 
 type deferredPartition struct {
-	parentSchema  string
-	parentName    string
-	partitionName string
-	definition    string
+	parentSchema string
+	parentName   string
+	partition    schema.Partition
 }
 
-type Warning struct {
-	File    string
-	Line    int
-	Message string
-}
+// Warning is a parser diagnostic; File and Line locate the statement.
+type Warning = diag.Warning
 
 type Result struct {
 	Database *schema.Database
@@ -81,6 +104,8 @@ func (p *Parser) runWithContext(file string, fn func() error) (*parseContext, er
 		p.errors = p.ctx.errors
 		p.warnings = p.ctx.warnings
 		p.deferred = p.ctx.deferred
+		p.deferredColumns = p.ctx.deferredColumns
+		p.deferredTypedTables = p.ctx.deferredTypedTables
 
 		return p.ctx, err
 	}
@@ -93,6 +118,8 @@ func (p *Parser) runWithContext(file string, fn func() error) (*parseContext, er
 	p.errors = ctx.errors
 	p.warnings = ctx.warnings
 	p.deferred = ctx.deferred
+	p.deferredColumns = ctx.deferredColumns
+	p.deferredTypedTables = ctx.deferredTypedTables
 	p.ctx = nil
 
 	return ctx, err
@@ -101,9 +128,11 @@ func (p *Parser) runWithContext(file string, fn func() error) (*parseContext, er
 func (p *Parser) ensureContext() *parseContext {
 	if p.ctx == nil {
 		p.ctx = &parseContext{
-			errors:   append([]ParseError(nil), p.errors...),
-			warnings: append([]Warning(nil), p.warnings...),
-			deferred: append([]deferredPartition(nil), p.deferred...),
+			errors:              append([]ParseError(nil), p.errors...),
+			warnings:            append([]Warning(nil), p.warnings...),
+			deferred:            append([]deferredPartition(nil), p.deferred...),
+			deferredColumns:     append([]columnAttribute(nil), p.deferredColumns...),
+			deferredTypedTables: append([]typedTable(nil), p.deferredTypedTables...),
 		}
 	}
 
@@ -132,11 +161,19 @@ func (p *Parser) GetWarnings() []Warning {
 }
 
 func (p *Parser) ParseDirectory(dirPath string) (*Result, error) {
+	return p.ParseDirectoryContext(context.Background(), dirPath)
+}
+
+// ParseDirectoryContext is ParseDirectory with cancellation checked before
+// every statement.
+func (p *Parser) ParseDirectoryContext(ctx context.Context, dirPath string) (*Result, error) {
+	defer p.bindContext(ctx)()
+
 	db := &schema.Database{
 		Version: "1.0",
 	}
 
-	ctx, err := p.runWithContext("", func() error {
+	_, err := p.runWithContext("", func() error {
 		return p.parseDirectoryContents(dirPath, db)
 	})
 	if err != nil {
@@ -147,14 +184,23 @@ func (p *Parser) ParseDirectory(dirPath string) (*Result, error) {
 		return nil, util.WrapError("processing deferred partitions", err)
 	}
 
+	// Deferred statements are resolved after the run, and may add diagnostics.
 	return &Result{
 		Database: db,
-		Errors:   ctx.errors,
-		Warnings: ctx.warnings,
+		Errors:   p.errors,
+		Warnings: p.warnings,
 	}, nil
 }
 
 func (p *Parser) ParseFile(filePath string, db *schema.Database) error {
+	return p.ParseFileContext(context.Background(), filePath, db)
+}
+
+// ParseFileContext is ParseFile with cancellation checked before every
+// statement.
+func (p *Parser) ParseFileContext(ctx context.Context, filePath string, db *schema.Database) error {
+	defer p.bindContext(ctx)()
+
 	_, err := p.runWithContext(filePath, func() error {
 		file, err := os.Open(filePath)
 		if err != nil {
@@ -176,6 +222,32 @@ func (p *Parser) ParseFile(filePath string, db *schema.Database) error {
 	return p.ProcessDeferredPartitions(db)
 }
 
+// ParseReaderContext parses all of the SQL read from r, such as standard
+// input, attributing errors and warnings to name the way ParseFileContext
+// attributes them to the file path.
+func (p *Parser) ParseReaderContext(
+	ctx context.Context,
+	name string,
+	r io.Reader,
+	db *schema.Database,
+) error {
+	defer p.bindContext(ctx)()
+
+	_, err := p.runWithContext(name, func() error {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return util.WrapError("reading "+name, err)
+		}
+
+		return p.ParseSQL(string(content), db)
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.ProcessDeferredPartitions(db)
+}
+
 func (p *Parser) parseDirectoryContents(dirPath string, db *schema.Database) error {
 	subdirs := []string{
 		"extensions",
@@ -202,6 +274,14 @@ func (p *Parser) parseDirectoryContents(dirPath string, db *schema.Database) err
 }
 
 func (p *Parser) ParseSQL(sql string, db *schema.Database) error {
+	return p.ParseSQLContext(context.Background(), sql, db)
+}
+
+// ParseSQLContext is ParseSQL with cancellation checked before every
+// statement.
+func (p *Parser) ParseSQLContext(ctx context.Context, sql string, db *schema.Database) error {
+	defer p.bindContext(ctx)()
+
 	if p.ctx == nil {
 		_, err := p.runWithContext(p.getCurrentFile(), func() error {
 			return p.parseSQLInternal(sql, db)
@@ -214,13 +294,25 @@ func (p *Parser) ParseSQL(sql string, db *schema.Database) error {
 }
 
 func (p *Parser) parseSQLInternal(sql string, db *schema.Database) error {
+	if p.pgDump {
+		sql = stripPsqlMetaCommands(sql)
+	}
+
 	statements, err := splitStatements(sql)
 	if err != nil {
 		return err
 	}
 
-	for _, stmt := range statements {
-		p.recordParseError(stmt, p.parseStatement(stmt, db))
+	for i, stmt := range statements {
+		if err := p.checkCancelled(i, len(statements)); err != nil {
+			return err
+		}
+
+		p.parseAndRecord(stmt, db)
+
+		if p.progress != nil {
+			p.progress(p.getCurrentFile(), i+1, len(statements))
+		}
 	}
 
 	return nil
@@ -232,16 +324,34 @@ func (p *Parser) parseStatement(stmt Statement, db *schema.Database) error {
 		return nil
 	}
 
+	migration, err := migrationAnnotation(stmt.Tokens)
+	if err != nil {
+		return err
+	}
+
+	p.migration = migration
+	defer func() { p.migration = "" }()
+
+	if p.pgDump && isPgDumpNoise(stmt.Tokens) {
+		return nil
+	}
+
 	stmtType := stmt.Type
 	if stmtType == StmtUnknown {
 		stmtType = determineStatementType(stmt.Tokens, sql)
 	}
 
 	if handler := p.registry.Get(stmtType); handler != nil {
+		p.warnLongIdentifiers(stmt)
 		return handler.Parse(p, stmt, db) //nolint:wrapcheck
 	}
 
-	p.addWarning(stmt.Line, "unsupported statement: "+truncate(sql, 50))
+	p.addWarning(
+		diag.CodeSkippedStatement,
+		stmt.Line,
+		"",
+		"unsupported statement: "+truncate(sql, 50),
+	)
 
 	return nil
 }
@@ -267,6 +377,18 @@ func (p *Parser) parseSubdirectory(dirPath string, db *schema.Database) error {
 }
 
 func (p *Parser) ParseFileWithoutProcessingDeferred(filePath string, db *schema.Database) error {
+	return p.ParseFileWithoutProcessingDeferredContext(context.Background(), filePath, db)
+}
+
+// ParseFileWithoutProcessingDeferredContext is ParseFileWithoutProcessingDeferred
+// with cancellation checked before every statement.
+func (p *Parser) ParseFileWithoutProcessingDeferredContext(
+	ctx context.Context,
+	filePath string,
+	db *schema.Database,
+) error {
+	defer p.bindContext(ctx)()
+
 	p.setCurrentFile(filePath)
 
 	file, err := os.Open(filePath)
@@ -334,13 +456,16 @@ func (p *Parser) recordParseError(stmt Statement, err error) {
 	p.errors = ctx.errors
 }
 
-func (p *Parser) addWarning(line int, message string) {
+func (p *Parser) addWarning(code diag.Code, line int, objectName, message string) {
 	ctx := p.ensureContext()
 
 	ctx.warnings = append(ctx.warnings, Warning{
-		File:    ctx.currentFile,
-		Line:    line,
-		Message: message,
+		Code:       code,
+		Severity:   diag.SeverityWarning,
+		Message:    message,
+		ObjectName: objectName,
+		File:       ctx.currentFile,
+		Line:       line,
 	})
 
 	p.warnings = ctx.warnings
@@ -365,6 +490,18 @@ func (p *Parser) ProcessDeferredPartitions(db *schema.Database) error {
 
 	ctx := p.ctx
 	for _, deferred := range ctx.deferred {
+		partition := deferred.partition
+
+		// A partition attached with ALTER TABLE ... ATTACH PARTITION was also
+		// created as a standalone table.
+		if child := db.GetTable(deferred.parentSchema, partition.Name); child != nil {
+			constraints, indexes := localPartitionObjects(child)
+			partition.Constraints = append(constraints, partition.Constraints...)
+			partition.Indexes = append(indexes, partition.Indexes...)
+		}
+
+		removeTable(db, deferred.parentSchema, partition.Name)
+
 		parentTable := db.GetTable(deferred.parentSchema, deferred.parentName)
 		if parentTable == nil {
 			p.addError(
@@ -373,7 +510,7 @@ func (p *Parser) ProcessDeferredPartitions(db *schema.Database) error {
 					"parent table %s.%s not found for partition %s",
 					deferred.parentSchema,
 					deferred.parentName,
-					deferred.partitionName,
+					partition.Name,
 				),
 				"",
 			)
@@ -381,23 +518,38 @@ func (p *Parser) ProcessDeferredPartitions(db *schema.Database) error {
 			continue
 		}
 
-		if parentTable.PartitionStrategy == nil {
-			parentTable.PartitionStrategy = &schema.PartitionStrategy{}
-		}
+		addPartition(parentTable, partition)
+	}
+
+	// A typed table only has the columns of its type once they are derived,
+	// so the column attributes below can name them.
+	for _, typed := range ctx.deferredTypedTables {
+		p.deriveTypedTableColumns(db, typed)
+	}
 
-		partition := schema.Partition{
-			Name:       deferred.partitionName,
-			Definition: deferred.definition,
+	// Partitions come first: a column attribute set on a partition names a
+	// table that the loop above may have folded into its parent.
+	for _, attr := range ctx.deferredColumns {
+		table := db.GetTable(attr.schemaName, attr.tableName)
+		if table == nil {
+			qualified := schema.QualifiedName(attr.schemaName, attr.tableName)
+			p.addWarning(diag.CodeObjectNotFound, 0, qualified, fmt.Sprintf(
+				"table %s not found for %s on column %s", qualified, attr.clause(), attr.column))
+
+			continue
 		}
 
-		parentTable.PartitionStrategy.Partitions = append(
-			parentTable.PartitionStrategy.Partitions,
-			partition,
-		)
+		if err := p.applyColumnAttribute(table, attr); err != nil {
+			p.addError(0, err.Error(), "")
+		}
 	}
 
 	ctx.deferred = nil
+	ctx.deferredColumns = nil
+	ctx.deferredTypedTables = nil
 	p.deferred = nil
+	p.deferredColumns = nil
+	p.deferredTypedTables = nil
 	p.errors = ctx.errors
 	p.warnings = ctx.warnings
 
@@ -0,0 +1,81 @@
+package parser
+
+import "strings"
+
+// WithPgDump reads the input as the output of pg_dump --schema-only. The
+// statements pg_dump writes that carry no schema objects, such as session
+// settings, ownership, privileges and sequence positions, are ignored rather
+// than reported as skipped, and its psql meta-commands are removed.
+func WithPgDump() Option {
+	return func(p *Parser) {
+		p.pgDump = true
+	}
+}
+
+// psqlMetaCommands are the backslash commands pg_dump and pg_dumpall write
+// at the start of a line.
+var psqlMetaCommands = []string{`\connect`, `\restrict`, `\unrestrict`} //nolint:gochecknoglobals
+
+// stripPsqlMetaCommands blanks the lines holding psql meta-commands, keeping
+// the line numbers of the statements around them.
+func stripPsqlMetaCommands(sql string) string {
+	lines := strings.Split(sql, "\n")
+
+	for i, line := range lines {
+		for _, command := range psqlMetaCommands {
+			if line == command || strings.HasPrefix(line, command+" ") {
+				lines[i] = ""
+				break
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isPgDumpNoise reports whether a statement of tokens is one pg_dump writes
+// around the schema objects without describing any of them: SET and RESET,
+// SELECT pg_catalog.set_config(...) and pg_catalog.setval(...), GRANT and
+// REVOKE on anything but tables and their columns, ALTER DEFAULT PRIVILEGES
+// and ALTER ... OWNER TO.
+func isPgDumpNoise(tokens []Token) bool {
+	var words []string
+
+	for i := range tokens {
+		switch tokens[i].Type {
+		case TokenComment, TokenSemicolon, TokenEOF:
+			continue
+		}
+
+		words = append(words, upperLiteral(tokens, i))
+	}
+
+	if len(words) == 0 {
+		return false
+	}
+
+	switch words[0] {
+	case "SET", "RESET":
+		return true
+	case "GRANT", "REVOKE":
+		return !isTableGrant(tokens)
+	case "SELECT":
+		name := words[1:]
+		if len(name) > 2 && name[0] == "PG_CATALOG" && name[1] == "." {
+			name = name[2:]
+		}
+
+		return len(name) > 1 && (name[0] == "SET_CONFIG" || name[0] == "SETVAL") &&
+			name[1] == "("
+	case "ALTER":
+		if len(words) > 2 && words[1] == "DEFAULT" && words[2] == "PRIVILEGES" {
+			return true
+		}
+
+		n := len(words)
+
+		return n > 3 && words[n-3] == "OWNER" && words[n-2] == "TO"
+	}
+
+	return false
+}
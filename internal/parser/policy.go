@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// parseCreatePolicy reads CREATE POLICY name ON table [AS PERMISSIVE |
+// RESTRICTIVE] [FOR command] [TO role, ...] [USING (expr)] [WITH CHECK
+// (expr)] and adds the policy to its table, replacing one of the same name.
+func (p *Parser) parseCreatePolicy(stmt string, line int, db *schema.Database) error {
+	policy, err := p.parsePolicyStatement(stmt)
+	if err != nil {
+		return err
+	}
+
+	policy.Source = p.sourceAt(line)
+
+	table := db.GetTable(policy.Schema, policy.TableName)
+	if table == nil {
+		qualified := policy.QualifiedTableName()
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			qualified,
+			fmt.Sprintf("table %s not found for policy %s", qualified, policy.Name),
+		)
+
+		return nil
+	}
+
+	if existing := table.GetPolicy(policy.Name); existing != nil {
+		*existing = *policy
+		return nil
+	}
+
+	table.Policies = append(table.Policies, *policy)
+
+	return nil
+}
+
+func (p *Parser) parsePolicyStatement(stmt string) (*schema.Policy, error) { //nolint:cyclop
+	tokens, err := NewLexer(stmt).Tokenize()
+	if err != nil {
+		return nil, WrapParseError(err, "tokenizing policy statement")
+	}
+
+	idx := nextNonCommentIndex(tokens, 0)
+	if upperLiteral(tokens, idx) != "CREATE" {
+		return nil, NewParseError("expected CREATE keyword")
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if upperLiteral(tokens, idx) != "POLICY" {
+		return nil, NewParseError("expected POLICY keyword")
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if idx >= len(tokens) ||
+		(tokens[idx].Type != TokenIdentifier && tokens[idx].Type != TokenQuotedIdentifier) {
+		return nil, NewParseError("missing policy name")
+	}
+
+	policy := &schema.Policy{Name: p.normalizeIdent(tokens[idx].Literal)}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if upperLiteral(tokens, idx) != "ON" {
+		return nil, NewParseError("missing ON clause")
+	}
+
+	name, idx := readQualifiedName(tokens, nextNonCommentIndex(tokens, idx+1))
+	if name == "" {
+		return nil, NewParseError("missing policy table")
+	}
+
+	policy.Schema, policy.TableName = p.splitSchemaTable(name)
+
+	for idx = nextNonCommentIndex(tokens, idx); idx < len(tokens); {
+		switch upperLiteral(tokens, idx) {
+		case "AS":
+			idx = nextNonCommentIndex(tokens, idx+1)
+			switch upperLiteral(tokens, idx) {
+			case "PERMISSIVE":
+			case "RESTRICTIVE":
+				policy.Restrictive = true
+			default:
+				return nil, NewParseError("expected PERMISSIVE or RESTRICTIVE")
+			}
+
+			idx = nextNonCommentIndex(tokens, idx+1)
+		case "FOR":
+			idx = nextNonCommentIndex(tokens, idx+1)
+			switch command := upperLiteral(tokens, idx); command {
+			case schema.PolicyCommandAll, schema.PolicyCommandSelect, schema.PolicyCommandInsert,
+				schema.PolicyCommandUpdate, schema.PolicyCommandDelete:
+				if command != schema.PolicyCommandAll {
+					policy.Command = command
+				}
+			default:
+				return nil, NewParseError("invalid policy command")
+			}
+
+			idx = nextNonCommentIndex(tokens, idx+1)
+		case "TO":
+			policy.Roles, idx = p.readPolicyRoles(tokens, nextNonCommentIndex(tokens, idx+1))
+		case "USING":
+			policy.Using, idx, err = extractParenthesizedLiteral(
+				stmt, tokens, nextNonCommentIndex(tokens, idx+1))
+			if err != nil {
+				return nil, err
+			}
+
+			idx = nextNonCommentIndex(tokens, idx)
+		case "WITH":
+			checkIdx := nextNonCommentIndex(tokens, idx+1)
+			if upperLiteral(tokens, checkIdx) != "CHECK" {
+				return nil, NewParseError("expected WITH CHECK")
+			}
+
+			policy.WithCheck, idx, err = extractParenthesizedLiteral(
+				stmt, tokens, nextNonCommentIndex(tokens, checkIdx+1))
+			if err != nil {
+				return nil, err
+			}
+
+			idx = nextNonCommentIndex(tokens, idx)
+		default:
+			if tokens[idx].Type == TokenSemicolon || tokens[idx].Type == TokenEOF {
+				return policy, nil
+			}
+
+			return nil, NewParseError("unexpected " + tokens[idx].Literal + " in policy")
+		}
+	}
+
+	return policy, nil
+}
+
+// readPolicyRoles reads the comma-separated roles of a TO clause. PUBLIC and
+// the CURRENT_USER-style keywords are recorded in lowercase; a policy for
+// PUBLIC alone records no roles, as one without a TO clause does.
+func (p *Parser) readPolicyRoles(tokens []Token, idx int) ([]string, int) {
+	var roles []string
+
+	for idx < len(tokens) {
+		token := tokens[idx]
+		if token.Type != TokenIdentifier && token.Type != TokenQuotedIdentifier &&
+			token.Type != TokenKeyword {
+			break
+		}
+
+		roles = append(roles, p.normalizeIdent(token.Literal))
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+		if idx >= len(tokens) || tokens[idx].Type != TokenComma {
+			break
+		}
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	if len(roles) == 1 && roles[0] == schema.PolicyRolePublic {
+		roles = nil
+	}
+
+	return roles, idx
+}
+
+// parseAlterTableRowSecurity records ALTER TABLE ... ENABLE and DISABLE ROW
+// LEVEL SECURITY.
+func (p *Parser) parseAlterTableRowSecurity(alter *alterTableStatement, db *schema.Database) error {
+	tokens := alter.tokens
+
+	idx := nextNonCommentIndex(tokens, alter.actionIdx+1)
+	for _, word := range []string{"ROW", "LEVEL", "SECURITY"} {
+		if upperLiteral(tokens, idx) != word {
+			return NewParseError("expected ROW LEVEL SECURITY")
+		}
+
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	table := db.GetTable(alter.schemaName, alter.tableName)
+	if table == nil {
+		qualified := schema.QualifiedName(alter.schemaName, alter.tableName)
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			qualified,
+			"table "+qualified+" not found for row level security",
+		)
+
+		return nil
+	}
+
+	table.RowLevelSecurityEnabled = strings.EqualFold(tokens[alter.actionIdx].Literal, "ENABLE")
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// parseSequenceOptions reads the options following the sequence name of a
+// CREATE SEQUENCE statement. Bounds and the start value left undeclared get
+// the values PostgreSQL assigns, so a parsed sequence compares equal to the
+// one extracted from the database it creates.
+func parseSequenceOptions(seq *schema.Sequence, options string) error {
+	words := strings.Fields(strings.TrimSuffix(strings.TrimSpace(options), ";"))
+
+	var minValue, maxValue, start *int64
+
+	number := func(i int, option string) (int64, error) {
+		if i >= len(words) {
+			return 0, fmt.Errorf("missing value for sequence option %s", option)
+		}
+
+		value, err := strconv.ParseInt(words[i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q for sequence option %s", words[i], option)
+		}
+
+		return value, nil
+	}
+
+	for i := 0; i < len(words); i++ {
+		option := strings.ToUpper(words[i])
+
+		if i+1 < len(words) {
+			switch next := strings.ToUpper(words[i+1]); {
+			case option == "INCREMENT" && next == "BY", option == "START" && next == "WITH":
+				i++
+			case option == "NO" && (next == "MINVALUE" || next == "MAXVALUE" || next == "CYCLE"):
+				option = "NO " + next
+				i++
+			}
+		}
+
+		var err error
+
+		switch option {
+		case "AS":
+			i++
+			if i >= len(words) {
+				return fmt.Errorf("missing value for sequence option %s", option)
+			}
+
+			seq.DataType = normalizeSequenceType(words[i])
+		case "INCREMENT":
+			i++
+			seq.Increment, err = number(i, option)
+		case "MINVALUE":
+			i++
+			value, parseErr := number(i, option)
+			minValue, err = &value, parseErr
+		case "MAXVALUE":
+			i++
+			value, parseErr := number(i, option)
+			maxValue, err = &value, parseErr
+		case "START":
+			i++
+			value, parseErr := number(i, option)
+			start, err = &value, parseErr
+		case "CACHE":
+			i++
+			seq.CacheSize, err = number(i, option)
+		case "CYCLE":
+			seq.IsCyclic = true
+		case "SEQUENCE":
+			// pg_dump names the sequence of every identity column, which
+			// Identity does not record.
+			i += 2
+			if i >= len(words) || !strings.EqualFold(words[i-1], "NAME") {
+				return fmt.Errorf("missing value for sequence option %s", option)
+			}
+		case "NO CYCLE", "NO MINVALUE", "NO MAXVALUE":
+		case "OWNED":
+			i += 2
+			if i >= len(words) || !strings.EqualFold(words[i-1], "BY") {
+				return fmt.Errorf("missing value for sequence option %s", option)
+			}
+
+			setSequenceOwner(seq, words[i])
+		default:
+			return fmt.Errorf("unsupported sequence option %s", words[i])
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	seq.MinValue, seq.MaxValue = schema.SequenceBounds(seq.DataType, seq.Increment)
+	if minValue != nil {
+		seq.MinValue = *minValue
+	}
+
+	if maxValue != nil {
+		seq.MaxValue = *maxValue
+	}
+
+	seq.StartValue = seq.DefaultStart()
+	if start != nil {
+		seq.StartValue = *start
+	}
+
+	if seq.CacheSize == 0 {
+		seq.CacheSize = 1
+	}
+
+	return nil
+}
+
+// setSequenceOwner records the table.column of OWNED BY, or clears the owner
+// for NONE.
+func setSequenceOwner(seq *schema.Sequence, owner string) {
+	seq.OwnedByTable, seq.OwnedByColumn = "", ""
+
+	if strings.EqualFold(owner, "NONE") {
+		return
+	}
+
+	if dot := strings.LastIndex(owner, "."); dot > 0 {
+		seq.OwnedByTable, seq.OwnedByColumn = owner[:dot], owner[dot+1:]
+	}
+}
+
+// foldSequenceOwner stores the OWNED BY target of seq as the database
+// reports it: the table as regclass text, schema-qualified and quoted where
+// needed, and the column by its name in the catalog.
+func (p *Parser) foldSequenceOwner(seq *schema.Sequence) {
+	if seq.OwnedByTable == "" {
+		return
+	}
+
+	schemaName, tableName := p.splitSchemaTable(seq.OwnedByTable)
+	seq.OwnedByTable = schema.QuotedName(schemaName, tableName)
+	seq.OwnedByColumn = p.normalizeIdent(seq.OwnedByColumn)
+}
+
+// parseAlterSequence records ALTER SEQUENCE ... OWNED BY, which pg_dump
+// writes after the table owning a serial column's sequence. Other ALTER
+// SEQUENCE statements are skipped like any unsupported statement.
+func (p *Parser) parseAlterSequence(stmt Statement, db *schema.Database) {
+	sql := stmt.NormalizedSQL()
+
+	if tokens, err := NewLexer(sql).Tokenize(); err == nil {
+		idx := nextNonCommentIndex(tokens, 0)    // ALTER
+		idx = nextNonCommentIndex(tokens, idx+1) // SEQUENCE
+		idx = nextNonCommentIndex(tokens, idx+1)
+
+		if upperLiteral(tokens, idx) == "IF" {
+			idx = nextNonCommentIndex(tokens, nextNonCommentIndex(tokens, idx+1)+1)
+		}
+
+		name, idx := readQualifiedName(tokens, idx)
+		idx = nextNonCommentIndex(tokens, idx)
+		byIdx := nextNonCommentIndex(tokens, idx+1)
+
+		owner, end := readQualifiedName(tokens, nextNonCommentIndex(tokens, byIdx+1))
+		end = nextNonCommentIndex(tokens, end)
+
+		if name != "" && owner != "" && upperLiteral(tokens, idx) == "OWNED" &&
+			upperLiteral(tokens, byIdx) == "BY" &&
+			(end >= len(tokens) || tokens[end].Type == TokenSemicolon ||
+				tokens[end].Type == TokenEOF) {
+			p.setAlteredSequenceOwner(db, name, owner, stmt.Line)
+			return
+		}
+	}
+
+	p.addWarning(
+		diag.CodeSkippedStatement,
+		stmt.Line,
+		"",
+		"unsupported statement: "+truncate(sql, 50),
+	)
+}
+
+func (p *Parser) setAlteredSequenceOwner(db *schema.Database, name, owner string, line int) {
+	schemaName, sequenceName := p.splitSchemaTable(name)
+
+	for i := range db.Sequences {
+		seq := &db.Sequences[i]
+		if seq.Schema == schemaName && seq.Name == sequenceName {
+			setSequenceOwner(seq, owner)
+			p.foldSequenceOwner(seq)
+
+			return
+		}
+	}
+
+	qualified := schema.QualifiedName(schemaName, sequenceName)
+	p.addWarning(diag.CodeObjectNotFound, line, qualified, "sequence "+qualified+" not found")
+}
+
+func normalizeSequenceType(dataType string) string {
+	return schema.CanonicalDataType(dataType)
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// SkippedStatement is a statement that did not make it into the parsed
+// schema, either because it is unsupported or because it failed to parse.
+type SkippedStatement struct {
+	File   string
+	Line   int
+	SQL    string
+	Reason string
+}
+
+// SkippedStatementFunc receives every statement the parser left out.
+type SkippedStatementFunc func(SkippedStatement)
+
+// WithSkippedStatements sets a callback invoked with the full text of every
+// statement that was skipped or failed to parse.
+func WithSkippedStatements(skipped SkippedStatementFunc) Option {
+	return func(p *Parser) {
+		p.skipped = skipped
+	}
+}
+
+func (p *Parser) parseAndRecord(stmt Statement, db *schema.Database) {
+	if p.skipped == nil {
+		p.recordParseError(stmt, p.parseStatement(stmt, db))
+		return
+	}
+
+	ctx := p.ensureContext()
+	errorCount, warningCount := len(ctx.errors), len(ctx.warnings)
+
+	p.recordParseError(stmt, p.parseStatement(stmt, db))
+
+	reason := ""
+
+	if len(ctx.errors) > errorCount {
+		reason = ctx.errors[errorCount].Message
+	} else {
+		for _, warning := range ctx.warnings[warningCount:] {
+			if warning.Code == diag.CodeSkippedStatement {
+				reason = warning.Message
+				break
+			}
+		}
+	}
+
+	if reason == "" {
+		return
+	}
+
+	p.skipped(SkippedStatement{
+		File:   ctx.currentFile,
+		Line:   stmt.Line,
+		SQL:    strings.TrimSpace(stmt.SQL),
+		Reason: reason,
+	})
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// SourceLocation is the file and line a desired-state object was defined at.
+type SourceLocation = schema.SourceLocation
+
+// TableConflictDescriber returns one line per difference between two
+// definitions of the same table, declared at firstAt and secondAt, or
+// nothing when they are equivalent.
+type TableConflictDescriber func(
+	first, second *schema.Table,
+	firstAt, secondAt SourceLocation,
+) []string
+
+// WithTableConflictDescriber sets the function used to explain how two
+// conflicting CREATE TABLE statements for the same table differ.
+func WithTableConflictDescriber(describe TableConflictDescriber) Option {
+	return func(p *Parser) {
+		p.describeTableConflict = describe
+	}
+}
+
+type tableSource struct {
+	location   SourceLocation
+	definition schema.Table
+}
+
+// SourceOf returns where the named table was first defined.
+func (p *Parser) SourceOf(schemaName, tableName string) (SourceLocation, bool) {
+	source, ok := p.tableSources[tableSourceKey(schemaName, tableName)]
+	if !ok {
+		return SourceLocation{}, false
+	}
+
+	return source.location, true
+}
+
+// sourceAt locates an object declared at line of the current file, with the
+// migration its statement is annotated with. Objects parsed from SQL that did
+// not come from a file get no location unless they are annotated.
+func (p *Parser) sourceAt(line int) *schema.SourceLocation {
+	file := p.getCurrentFile()
+	if file == "" && p.migration == "" {
+		return nil
+	}
+
+	return &schema.SourceLocation{File: file, Line: line, Migration: p.migration}
+}
+
+func (p *Parser) recordTableSource(table *schema.Table, line int) {
+	if p.tableSources == nil {
+		p.tableSources = make(map[string]tableSource)
+	}
+
+	p.tableSources[tableSourceKey(table.Schema, table.Name)] = tableSource{
+		location:   SourceLocation{File: p.getCurrentFile(), Line: line},
+		definition: snapshotTable(table),
+	}
+}
+
+// checkRedefinedTable is called when table is already present in the
+// database and reports whether the new definition should replace it. A
+// repeated identical definition is a warning and keeps the existing table; a
+// conflicting one is an error listing the differences between the two.
+func (p *Parser) checkRedefinedTable(table *schema.Table, line int) (bool, error) {
+	previous, exists := p.tableSources[tableSourceKey(table.Schema, table.Name)]
+	if !exists {
+		p.recordTableSource(table, line)
+		return true, nil
+	}
+
+	var differences []string
+	if !reflect.DeepEqual(previous.definition, snapshotTable(table)) {
+		differences = []string{"definitions differ"}
+
+		if p.describeTableConflict != nil {
+			differences = p.describeTableConflict(&previous.definition, table,
+				previous.location, SourceLocation{File: p.getCurrentFile(), Line: line})
+		}
+	}
+
+	qualified := schema.QualifiedName(table.Schema, table.Name)
+
+	if len(differences) == 0 {
+		p.addWarning(diag.CodeDuplicateDefinition, line, qualified, fmt.Sprintf(
+			"table %s is defined again with an identical definition (first defined at %s)",
+			qualified,
+			previous.location,
+		))
+
+		return false, nil
+	}
+
+	p.recordTableSource(table, line)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "table %s is already defined at %s with a different definition:",
+		qualified, previous.location)
+
+	for _, difference := range differences {
+		sb.WriteString("\n    ")
+		sb.WriteString(difference)
+	}
+
+	return true, NewParseError(sb.String())
+}
+
+// snapshotTable copies table without its source locations, which differ
+// between two declarations even when the definitions are identical.
+func snapshotTable(table *schema.Table) schema.Table {
+	snapshot := *table
+	snapshot.Source = nil
+	snapshot.Columns = slices.Clone(table.Columns)
+	snapshot.Constraints = slices.Clone(table.Constraints)
+	snapshot.Indexes = slices.Clone(table.Indexes)
+
+	for i := range snapshot.Columns {
+		snapshot.Columns[i].Source = nil
+	}
+
+	for i := range snapshot.Indexes {
+		snapshot.Indexes[i].Source = nil
+	}
+
+	return snapshot
+}
+
+// tableSourceKey keys a table by its name as GetTable matches it, so
+// "Users" and users are different tables.
+func tableSourceKey(schemaName, tableName string) string {
+	return schema.NormalizeSchemaName(schemaName) + "." + schema.NormalizeIdentifier(tableName)
+}
@@ -2,7 +2,18 @@ package parser
 
 import "strings"
 
+// byteOrderMark starts files that some editors save as UTF-8 with a BOM.
+const byteOrderMark = "\ufeff"
+
+// SplitStatements splits sql into its statements, as the parser reads them.
+// The SQL of each keeps the comments before it but not its semicolon.
+func SplitStatements(sql string) ([]Statement, error) {
+	return splitStatements(sql)
+}
+
 func splitStatements(sql string) ([]Statement, error) {
+	sql = strings.TrimPrefix(sql, byteOrderMark)
+
 	tokens, err := NewLexer(sql).Tokenize()
 	if err != nil {
 		return nil, err
@@ -20,7 +31,7 @@ func splitTokensIntoStatements(sql string, tokens []Token) []Statement {
 
 	emit := func(end int, includeSemicolon bool) {
 		segment := strings.TrimSpace(sql[start:end])
-		if segment == "" || isCommentOnly(segment) {
+		if segment == "" || commentsOnly(currentTokens) {
 			currentTokens = currentTokens[:0]
 			start = end
 
@@ -57,7 +68,7 @@ func splitTokensIntoStatements(sql string, tokens []Token) []Statement {
 		}
 	}
 
-	if trimmed := strings.TrimSpace(sql[start:]); trimmed != "" && !isCommentOnly(trimmed) {
+	if trimmed := strings.TrimSpace(sql[start:]); trimmed != "" && !commentsOnly(currentTokens) {
 		emit(len(sql), false)
 	}
 
@@ -89,14 +100,14 @@ func statementLine(tokens []Token) int {
 	return 0
 }
 
-func isCommentOnly(stmt string) bool {
-	for line := range strings.SplitSeq(stmt, "\n") {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "/*") {
-			continue
+// commentsOnly reports whether the tokens of a statement hold nothing but
+// comments and its semicolon, as in a file that only explains why it is
+// empty.
+func commentsOnly(tokens []Token) bool {
+	for _, token := range tokens {
+		if token.Type != TokenComment && token.Type != TokenSemicolon {
+			return false
 		}
-
-		return false
 	}
 
 	return true
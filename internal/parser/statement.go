@@ -17,12 +17,22 @@ const (
 	StmtCreateSequence
 	StmtCreateSchema
 	StmtAlterTable
+	StmtAlterIndex
+	StmtAlterMaterializedView
+	StmtAlterSequence
 	StmtComment
 	StmtSelectCreateHypertable
+	StmtSelectAddDimension
 	StmtSelectAddCompressionPolicy
+	StmtSelectRemoveCompressionPolicy
 	StmtSelectAddRetentionPolicy
 	StmtSelectAddContinuousAggregatePolicy
 	StmtDoBlock
+	StmtSelectInto
+	StmtRefreshMaterializedView
+	StmtCreatePolicy
+	StmtGrant
+	StmtRevoke
 )
 
 type Statement struct {
@@ -81,7 +91,7 @@ func DetectStatementType(tokens []Token) StatementType { //nolint:cyclop,gocyclo
 			return StmtUnknown
 		case "VIEW":
 			return StmtCreateView
-		case "FUNCTION":
+		case "FUNCTION", "PROCEDURE":
 			return StmtCreateFunction
 		case "TRIGGER":
 			return StmtCreateTrigger
@@ -93,19 +103,32 @@ func DetectStatementType(tokens []Token) StatementType { //nolint:cyclop,gocyclo
 			return StmtCreateSequence
 		case "SCHEMA":
 			return StmtCreateSchema
+		case "POLICY":
+			return StmtCreatePolicy
 		case "OR":
 			if len(parts) > 3 && parts[2] == "REPLACE" {
 				switch parts[3] {
 				case "VIEW":
 					return StmtCreateView
-				case "FUNCTION":
+				case "FUNCTION", "PROCEDURE":
 					return StmtCreateFunction
 				}
 			}
 		}
 	case "ALTER":
-		if len(parts) > 1 && parts[1] == "TABLE" {
-			return StmtAlterTable
+		if len(parts) > 1 {
+			switch parts[1] {
+			case "TABLE":
+				return StmtAlterTable
+			case "INDEX":
+				return StmtAlterIndex
+			case "SEQUENCE":
+				return StmtAlterSequence
+			case "MATERIALIZED":
+				if len(parts) > 2 && parts[2] == "VIEW" {
+					return StmtAlterMaterializedView
+				}
+			}
 		}
 	case "COMMENT":
 		if len(parts) > 1 && parts[1] == "ON" {
@@ -119,13 +142,29 @@ func DetectStatementType(tokens []Token) StatementType { //nolint:cyclop,gocyclo
 		switch parts[1] {
 		case "CREATE_HYPERTABLE":
 			return StmtSelectCreateHypertable
+		case "ADD_DIMENSION":
+			return StmtSelectAddDimension
 		case "ADD_COMPRESSION_POLICY":
 			return StmtSelectAddCompressionPolicy
+		case "REMOVE_COMPRESSION_POLICY":
+			return StmtSelectRemoveCompressionPolicy
 		case "ADD_RETENTION_POLICY":
 			return StmtSelectAddRetentionPolicy
 		case "ADD_CONTINUOUS_AGGREGATE_POLICY":
 			return StmtSelectAddContinuousAggregatePolicy
 		}
+
+		if hasTopLevelKeyword(tokens, "INTO") {
+			return StmtSelectInto
+		}
+	case "REFRESH":
+		if len(parts) > 2 && parts[1] == "MATERIALIZED" && parts[2] == "VIEW" {
+			return StmtRefreshMaterializedView
+		}
+	case "GRANT":
+		return StmtGrant
+	case "REVOKE":
+		return StmtRevoke
 	case "DO":
 		return StmtDoBlock
 	}
@@ -153,7 +192,9 @@ func detectStatementTypeFromSQL(sql string) StatementType {
 		strings.HasPrefix(upper, "CREATE VIEW"):
 		return StmtCreateView
 	case strings.HasPrefix(upper, "CREATE OR REPLACE FUNCTION"),
-		strings.HasPrefix(upper, "CREATE FUNCTION"):
+		strings.HasPrefix(upper, "CREATE FUNCTION"),
+		strings.HasPrefix(upper, "CREATE OR REPLACE PROCEDURE"),
+		strings.HasPrefix(upper, "CREATE PROCEDURE"):
 		return StmtCreateFunction
 	case strings.HasPrefix(upper, "CREATE TRIGGER"):
 		return StmtCreateTrigger
@@ -165,18 +206,36 @@ func detectStatementTypeFromSQL(sql string) StatementType {
 		return StmtCreateSequence
 	case strings.HasPrefix(upper, "CREATE SCHEMA"):
 		return StmtCreateSchema
+	case strings.HasPrefix(upper, "CREATE POLICY"):
+		return StmtCreatePolicy
 	case strings.HasPrefix(upper, "ALTER TABLE"):
 		return StmtAlterTable
+	case strings.HasPrefix(upper, "ALTER INDEX"):
+		return StmtAlterIndex
+	case strings.HasPrefix(upper, "ALTER MATERIALIZED VIEW"):
+		return StmtAlterMaterializedView
+	case strings.HasPrefix(upper, "ALTER SEQUENCE"):
+		return StmtAlterSequence
 	case strings.HasPrefix(upper, "COMMENT ON"):
 		return StmtComment
 	case strings.HasPrefix(upper, "SELECT CREATE_HYPERTABLE"):
 		return StmtSelectCreateHypertable
+	case strings.HasPrefix(upper, "SELECT ADD_DIMENSION"):
+		return StmtSelectAddDimension
 	case strings.HasPrefix(upper, "SELECT ADD_COMPRESSION_POLICY"):
 		return StmtSelectAddCompressionPolicy
+	case strings.HasPrefix(upper, "SELECT REMOVE_COMPRESSION_POLICY"):
+		return StmtSelectRemoveCompressionPolicy
 	case strings.HasPrefix(upper, "SELECT ADD_RETENTION_POLICY"):
 		return StmtSelectAddRetentionPolicy
 	case strings.HasPrefix(upper, "SELECT ADD_CONTINUOUS_AGGREGATE_POLICY"):
 		return StmtSelectAddContinuousAggregatePolicy
+	case strings.HasPrefix(upper, "REFRESH MATERIALIZED VIEW"):
+		return StmtRefreshMaterializedView
+	case strings.HasPrefix(upper, "GRANT"):
+		return StmtGrant
+	case strings.HasPrefix(upper, "REVOKE"):
+		return StmtRevoke
 	case strings.HasPrefix(upper, "DO"):
 		return StmtDoBlock
 	default:
@@ -28,17 +28,25 @@ func NewParserRegistry() *ParserRegistry {
 	r.Register(NewMaterializedViewParser())
 	r.Register(NewFunctionParser())
 	r.Register(NewTriggerParser())
+	r.Register(NewPolicyParser())
+	r.Register(NewGrantParser())
 	r.Register(NewExtensionParser())
 	r.Register(NewSchemaParser())
 	r.Register(NewTypeParser())
 	r.Register(NewSequenceParser())
 	r.Register(NewAlterTableParser())
+	r.Register(NewAlterIndexParser())
+	r.Register(NewAlterMaterializedViewParser())
+	r.Register(NewAlterSequenceParser())
 	r.Register(NewHypertableParser())
+	r.Register(NewDimensionParser())
 	r.Register(NewCompressionPolicyParser())
 	r.Register(NewRetentionPolicyParser())
 	r.Register(NewContinuousAggregatePolicyParser())
 	r.Register(NewCommentParser())
 	r.Register(NewDoBlockParser())
+	r.Register(NewSelectIntoParser())
+	r.Register(NewRefreshMaterializedViewParser())
 
 	return r
 }
@@ -85,11 +93,11 @@ func (p *ExtensionParser) Parse(root *Parser, stmt Statement, db *schema.Databas
 		return errors.New("cannot extract extension name")
 	}
 
-	name := unquote(matches[1])
+	name := schema.FoldIdentifier(matches[1])
 	ext := schema.Extension{Name: name}
 
 	if schemaMatch := p.schemaPattern.FindStringSubmatch(sql); len(schemaMatch) > 1 {
-		ext.Schema = schema.NormalizeIdentifier(unquote(schemaMatch[1]))
+		ext.Schema = schema.FoldIdentifier(schemaMatch[1])
 	}
 
 	if versionMatch := p.versionPattern.FindStringSubmatch(sql); len(versionMatch) > 1 {
@@ -125,7 +133,7 @@ func (p *SchemaParser) Parse(_ *Parser, stmt Statement, db *schema.Database) err
 		return errors.New("cannot extract schema name")
 	}
 
-	name := schema.NormalizeIdentifier(unquote(matches[1]))
+	name := schema.FoldIdentifier(matches[1])
 	newSchema := schema.Schema{Name: name}
 
 	for i := range db.Schemas {
@@ -147,7 +155,7 @@ type TypeParser struct {
 func NewTypeParser() *TypeParser {
 	return &TypeParser{
 		definitionPattern: regexp.MustCompile(
-			`(?i)CREATE\s+TYPE\s+([a-zA-Z_][a-zA-Z0-9_.]*)\s+AS\s+(\w+)`,
+			`(?i)CREATE\s+TYPE\s+(` + qualifiedNamePattern + `)\s+AS\s*(\w+|\()`,
 		),
 	}
 }
@@ -173,6 +181,11 @@ func (p *TypeParser) Parse(root *Parser, stmt Statement, db *schema.Database) er
 		Definition: sql,
 	}
 
+	if matches[2] == "(" {
+		customType.Type = "composite"
+		customType.Attributes = root.parseTypeAttributes(extractParens(sql))
+	}
+
 	if strings.EqualFold(matches[2], "ENUM") {
 		if values := extractParens(sql); values != "" {
 			for _, val := range splitByComma(values) {
@@ -193,7 +206,8 @@ type SequenceParser struct {
 func NewSequenceParser() *SequenceParser {
 	return &SequenceParser{
 		namePattern: regexp.MustCompile(
-			`(?i)CREATE\s+SEQUENCE\s+([a-zA-Z_][a-zA-Z0-9_.]*|"[^"]*"(?:\."[^"]*")?)`,
+			`(?i)CREATE\s+SEQUENCE\s+(?:IF\s+NOT\s+EXISTS\s+)?` +
+				`(` + qualifiedNamePattern + `)`,
 		),
 	}
 }
@@ -219,6 +233,12 @@ func (p *SequenceParser) Parse(root *Parser, stmt Statement, db *schema.Database
 		Increment: 1,
 	}
 
+	if err := parseSequenceOptions(&sequence, sql[len(matches[0]):]); err != nil {
+		return err
+	}
+
+	root.foldSequenceOwner(&sequence)
+
 	db.Sequences = append(db.Sequences, sequence)
 
 	return nil
@@ -235,7 +255,7 @@ func (p *TableParser) StatementTypes() []StatementType {
 }
 
 func (p *TableParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseCreateTable(stmt.NormalizedSQL(), db)
+	return root.parseCreateTable(stmt.NormalizedSQL(), stmt.Line, db)
 }
 
 type IndexParser struct{}
@@ -249,7 +269,7 @@ func (p *IndexParser) StatementTypes() []StatementType {
 }
 
 func (p *IndexParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseCreateIndex(stmt.NormalizedSQL(), db)
+	return root.parseCreateIndex(stmt.NormalizedSQL(), stmt.Line, db)
 }
 
 type ViewParser struct{}
@@ -263,7 +283,7 @@ func (p *ViewParser) StatementTypes() []StatementType {
 }
 
 func (p *ViewParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseCreateView(stmt.NormalizedSQL(), db)
+	return root.parseCreateView(stmt.NormalizedSQL(), stmt.Line, db)
 }
 
 type MaterializedViewParser struct{}
@@ -277,7 +297,7 @@ func (p *MaterializedViewParser) StatementTypes() []StatementType {
 }
 
 func (p *MaterializedViewParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseCreateMaterializedView(stmt.NormalizedSQL(), db)
+	return root.parseCreateMaterializedView(stmt.NormalizedSQL(), stmt.Line, db)
 }
 
 type FunctionParser struct{}
@@ -291,7 +311,7 @@ func (p *FunctionParser) StatementTypes() []StatementType {
 }
 
 func (p *FunctionParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseCreateFunction(stmt.NormalizedSQL(), db)
+	return root.parseCreateFunction(stmt.NormalizedSQL(), stmt.Line, db)
 }
 
 type TriggerParser struct{}
@@ -305,7 +325,35 @@ func (p *TriggerParser) StatementTypes() []StatementType {
 }
 
 func (p *TriggerParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseCreateTrigger(stmt.NormalizedSQL(), db)
+	return root.parseCreateTrigger(stmt.NormalizedSQL(), stmt.Line, db)
+}
+
+type PolicyParser struct{}
+
+func NewPolicyParser() *PolicyParser {
+	return &PolicyParser{}
+}
+
+func (p *PolicyParser) StatementTypes() []StatementType {
+	return []StatementType{StmtCreatePolicy}
+}
+
+func (p *PolicyParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
+	return root.parseCreatePolicy(stmt.NormalizedSQL(), stmt.Line, db)
+}
+
+type GrantParser struct{}
+
+func NewGrantParser() *GrantParser {
+	return &GrantParser{}
+}
+
+func (p *GrantParser) StatementTypes() []StatementType {
+	return []StatementType{StmtGrant, StmtRevoke}
+}
+
+func (p *GrantParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
+	return root.parseGrant(stmt.NormalizedSQL(), stmt.Line, db)
 }
 
 type AlterTableParser struct{}
@@ -319,7 +367,56 @@ func (p *AlterTableParser) StatementTypes() []StatementType {
 }
 
 func (p *AlterTableParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
-	return root.parseAlterTable(stmt.NormalizedSQL(), db)
+	return root.parseAlterTable(stmt.NormalizedSQL(), stmt.Line, db)
+}
+
+type AlterIndexParser struct{}
+
+func NewAlterIndexParser() *AlterIndexParser {
+	return &AlterIndexParser{}
+}
+
+func (p *AlterIndexParser) StatementTypes() []StatementType {
+	return []StatementType{StmtAlterIndex}
+}
+
+func (p *AlterIndexParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
+	root.parseAlterIndex(stmt, db)
+	return nil
+}
+
+type AlterMaterializedViewParser struct{}
+
+func NewAlterMaterializedViewParser() *AlterMaterializedViewParser {
+	return &AlterMaterializedViewParser{}
+}
+
+func (p *AlterMaterializedViewParser) StatementTypes() []StatementType {
+	return []StatementType{StmtAlterMaterializedView}
+}
+
+func (p *AlterMaterializedViewParser) Parse(
+	root *Parser,
+	stmt Statement,
+	db *schema.Database,
+) error {
+	root.parseAlterMaterializedView(stmt, db)
+	return nil
+}
+
+type AlterSequenceParser struct{}
+
+func NewAlterSequenceParser() *AlterSequenceParser {
+	return &AlterSequenceParser{}
+}
+
+func (p *AlterSequenceParser) StatementTypes() []StatementType {
+	return []StatementType{StmtAlterSequence}
+}
+
+func (p *AlterSequenceParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
+	root.parseAlterSequence(stmt, db)
+	return nil
 }
 
 type HypertableParser struct{}
@@ -336,6 +433,20 @@ func (p *HypertableParser) Parse(root *Parser, stmt Statement, db *schema.Databa
 	return root.parseCreateHypertable(stmt.NormalizedSQL(), db)
 }
 
+type DimensionParser struct{}
+
+func NewDimensionParser() *DimensionParser {
+	return &DimensionParser{}
+}
+
+func (p *DimensionParser) StatementTypes() []StatementType {
+	return []StatementType{StmtSelectAddDimension}
+}
+
+func (p *DimensionParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
+	return root.parseAddDimension(stmt.NormalizedSQL(), db)
+}
+
 type CompressionPolicyParser struct{}
 
 func NewCompressionPolicyParser() *CompressionPolicyParser {
@@ -343,10 +454,14 @@ func NewCompressionPolicyParser() *CompressionPolicyParser {
 }
 
 func (p *CompressionPolicyParser) StatementTypes() []StatementType {
-	return []StatementType{StmtSelectAddCompressionPolicy}
+	return []StatementType{StmtSelectAddCompressionPolicy, StmtSelectRemoveCompressionPolicy}
 }
 
 func (p *CompressionPolicyParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
+	if stmt.Type == StmtSelectRemoveCompressionPolicy {
+		return root.parseRemoveCompressionPolicy(stmt.NormalizedSQL(), db)
+	}
+
 	return root.parseCompressionPolicy(stmt.NormalizedSQL(), db)
 }
 
@@ -409,3 +524,40 @@ func (p *DoBlockParser) StatementTypes() []StatementType {
 func (p *DoBlockParser) Parse(root *Parser, stmt Statement, db *schema.Database) error {
 	return root.parseDoBlock(stmt.NormalizedSQL(), db)
 }
+
+// SelectIntoParser rejects SELECT ... INTO, which creates a table whose
+// columns cannot be known without running the query.
+type SelectIntoParser struct{}
+
+func NewSelectIntoParser() *SelectIntoParser {
+	return &SelectIntoParser{}
+}
+
+func (p *SelectIntoParser) StatementTypes() []StatementType {
+	return []StatementType{StmtSelectInto}
+}
+
+func (p *SelectIntoParser) Parse(root *Parser, stmt Statement, _ *schema.Database) error {
+	return root.parseSelectInto(stmt.Tokens)
+}
+
+// RefreshMaterializedViewParser ignores REFRESH MATERIALIZED VIEW, which
+// fills a view with data rather than declaring it.
+type RefreshMaterializedViewParser struct{}
+
+func NewRefreshMaterializedViewParser() *RefreshMaterializedViewParser {
+	return &RefreshMaterializedViewParser{}
+}
+
+func (p *RefreshMaterializedViewParser) StatementTypes() []StatementType {
+	return []StatementType{StmtRefreshMaterializedView}
+}
+
+func (p *RefreshMaterializedViewParser) Parse(
+	root *Parser,
+	stmt Statement,
+	_ *schema.Database,
+) error {
+	root.ignoreRefresh(stmt)
+	return nil
+}
@@ -7,14 +7,17 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
+var tableIfNotExistsRe = regexp.MustCompile(`(?i)^CREATE\s+TABLE\s+IF\s+NOT\s+EXISTS\b`)
+
 var tableNameRe = regexp.MustCompile(
-	`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-zA-Z_][a-zA-Z0-9_]*(?:\.[a-zA-Z_][a-zA-Z0-9_]*)?|"[^"]*"(?:\."[^"]*")?)`, //nolint:lll
+	`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + qualifiedNamePattern + `)`,
 )
 
-func (p *Parser) parseCreateTable(stmt string, db *schema.Database) error {
+func (p *Parser) parseCreateTable(stmt string, line int, db *schema.Database) error {
 	stmtUpper := strings.ToUpper(stmt)
 
 	if strings.Contains(stmtUpper, "PARTITION OF") {
@@ -28,12 +31,29 @@ func (p *Parser) parseCreateTable(stmt string, db *schema.Database) error {
 
 	schemaName, tableName := p.splitSchemaTable(matches[1])
 
-	content := extractParens(stmt)
-	if content == "" {
-		return errors.New("no table definition found")
+	tokens, tokenErr := NewLexer(stmt).Tokenize()
+	if tokenErr == nil && hasTopLevelKeyword(tokens, "AS") {
+		return derivedTableError(ErrCreateTableAs, schema.QualifiedName(schemaName, tableName))
 	}
 
-	columns, constraints := p.parseTableContent(content)
+	typedOf, rest := p.parseTypedTableOf(stmt[len(matches[0]):])
+
+	var (
+		columns     []schema.Column
+		constraints []schema.Constraint
+	)
+
+	switch {
+	case typedOf == "":
+		content := extractParens(stmt)
+		if content == "" {
+			return errors.New("no table definition found")
+		}
+
+		columns, constraints = p.parseTableContent(content)
+	case strings.HasPrefix(strings.TrimSpace(rest), "("):
+		columns, constraints = p.parseTypedTableContent(extractParens(rest))
+	}
 
 	partitionStrategy := p.parsePartitionBy(stmt)
 
@@ -44,22 +64,129 @@ func (p *Parser) parseCreateTable(stmt string, db *schema.Database) error {
 		Constraints:       constraints,
 		Indexes:           []schema.Index{},
 		PartitionStrategy: partitionStrategy,
+		IfNotExists:       tableIfNotExistsRe.MatchString(stmt),
+		TypedOf:           typedOf,
+		Source:            p.sourceAt(line),
+	}
+
+	if typedOf != "" {
+		ctx := p.ensureContext()
+		ctx.deferredTypedTables = append(ctx.deferredTypedTables,
+			typedTable{schemaName: schemaName, tableName: tableName})
+	}
+
+	if table.Source != nil && tokenErr == nil {
+		p.setColumnSources(table.Columns, tokens, line)
+	}
+
+	if tokenErr == nil {
+		usings, err := columnUsingAnnotations(tokens)
+		if err != nil {
+			return err
+		}
+
+		for i := range table.Columns {
+			table.Columns[i].UsingExpression = usings[table.Columns[i].Name]
+		}
 	}
 
 	p.finalizeTableConstraints(&table)
 
 	for i, existing := range db.Tables {
 		if existing.Schema == schemaName && existing.Name == tableName {
-			db.Tables[i] = table
-			return nil
+			replace, err := p.checkRedefinedTable(&table, line)
+			if replace {
+				db.Tables[i] = table
+			}
+
+			return err
 		}
 	}
 
+	p.recordTableSource(&table, line)
 	db.Tables = append(db.Tables, table)
 
 	return nil
 }
 
+// derivedTableError rejects statements that create a table from a query.
+// Their columns are only known once the query runs, so they cannot be part
+// of the declarative desired state.
+func derivedTableError(cause error, table string) ParseError {
+	return ParseError{
+		Message: fmt.Sprintf(
+			"%v (table %s): declare the table with explicit columns in CREATE TABLE "+
+				"and populate it with a seed or backfill migration",
+			cause, table,
+		),
+		Cause: cause,
+	}
+}
+
+func (p *Parser) parseSelectInto(tokens []Token) error {
+	target := "unknown"
+
+	if name := selectIntoTarget(tokens); name != "" {
+		schemaName, tableName := p.splitSchemaTable(name)
+		target = schema.QualifiedName(schemaName, tableName)
+	}
+
+	return derivedTableError(ErrSelectInto, target)
+}
+
+func selectIntoTarget(tokens []Token) string {
+	depth := 0
+
+	for i, token := range tokens {
+		switch token.Type {
+		case TokenLParen:
+			depth++
+			continue
+		case TokenRParen:
+			depth--
+			continue
+		}
+
+		if depth != 0 || !strings.EqualFold(token.Literal, "INTO") {
+			continue
+		}
+
+		var sb strings.Builder
+
+		for _, next := range tokens[i+1:] {
+			if sb.Len() == 0 && isSelectIntoModifier(next.Literal) {
+				continue
+			}
+
+			if next.Type == TokenDot {
+				sb.WriteString(".")
+				continue
+			}
+
+			isName := next.Type == TokenIdentifier || next.Type == TokenQuotedIdentifier ||
+				next.Type == TokenKeyword
+			if !isName || (sb.Len() > 0 && !strings.HasSuffix(sb.String(), ".")) {
+				break
+			}
+
+			sb.WriteString(next.Literal)
+		}
+
+		return sb.String()
+	}
+
+	return ""
+}
+
+func isSelectIntoModifier(word string) bool {
+	switch strings.ToUpper(word) {
+	case "TEMP", "TEMPORARY", "UNLOGGED", "TABLE":
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseTableContent(content string) ([]schema.Column, []schema.Constraint) {
 	var (
 		columns     []schema.Column
@@ -80,12 +207,16 @@ func (p *Parser) parseTableContent(content string) ([]schema.Column, []schema.Co
 			if c, err := p.parseConstraint(part); err == nil {
 				constraints = append(constraints, c)
 			} else {
-				p.addWarning(0, fmt.Sprintf("parsing constraint: %v", err))
+				p.addWarning(
+					diag.CodeSkippedDefinition, 0, "", fmt.Sprintf("parsing constraint: %v", err),
+				)
 			}
 		} else {
 			col, inline, err := p.parseColumn(part, position)
 			if err != nil {
-				p.addWarning(0, fmt.Sprintf("parsing column: %v", err))
+				p.addWarning(
+					diag.CodeSkippedDefinition, 0, "", fmt.Sprintf("parsing column: %v", err),
+				)
 				continue
 			}
 
@@ -98,6 +229,52 @@ func (p *Parser) parseTableContent(content string) ([]schema.Column, []schema.Co
 	return columns, constraints
 }
 
+// setColumnSources locates each column at the line its definition starts on.
+// tokens are those of the CREATE TABLE statement, which starts at line.
+func (p *Parser) setColumnSources(columns []schema.Column, tokens []Token, line int) {
+	starts := tableElementLines(tokens)
+
+	for i := range columns {
+		if start, ok := starts[columns[i].Name]; ok {
+			columns[i].Source = p.sourceAt(line + start - 1)
+		}
+	}
+}
+
+// tableElementLines maps the first word of each element of the first
+// parenthesized list in tokens to the line the element starts on.
+func tableElementLines(tokens []Token) map[string]int {
+	starts := make(map[string]int)
+	depth := 0
+	elementStart := false
+
+	for _, token := range tokens {
+		switch {
+		case token.Type == TokenComment:
+			continue
+		case token.Type == TokenLParen:
+			depth++
+			elementStart = depth == 1
+		case token.Type == TokenRParen:
+			depth--
+			if depth == 0 {
+				return starts
+			}
+		case depth == 1 && token.Type == TokenComma:
+			elementStart = true
+		case depth == 1 && elementStart:
+			name := schema.FoldIdentifier(token.Literal)
+			if _, seen := starts[name]; !seen {
+				starts[name] = token.Line
+			}
+
+			elementStart = false
+		}
+	}
+
+	return starts
+}
+
 func splitTableDefinition(content string) []string {
 	tokens, err := NewLexer(content).Tokenize()
 	if err != nil || len(tokens) == 0 {
@@ -161,7 +338,8 @@ func isColumnConstraintBoundary(tokens []Token, idx int) bool {
 	lit := upperLiteral(tokens, idx)
 
 	switch lit {
-	case "CONSTRAINT", "DEFAULT", "REFERENCES", "CHECK", "UNIQUE", "GENERATED", "COLLATE":
+	case "CONSTRAINT", "DEFAULT", "REFERENCES", "CHECK", "UNIQUE", "GENERATED", "COLLATE",
+		"COMPRESSION", "STORAGE":
 		return true
 	case "PRIMARY":
 		return upperLiteral(tokens, idx+1) == "KEY"
@@ -432,6 +610,36 @@ func (cp *constraintParser) consumeReferenceTable() (string, error) {
 	return strings.TrimSpace(cp.def[start:cp.tokens[cp.pos].Start]), nil
 }
 
+// consumeInclude reads the INCLUDE column list that may follow the key columns
+// of a primary key or unique constraint.
+func (cp *constraintParser) consumeInclude() ([]string, error) {
+	cp.skipComments()
+
+	if cp.peekWord() != "INCLUDE" {
+		return nil, nil
+	}
+
+	cp.pos++
+
+	columnList, err := cp.consumeParenthesized()
+	if err != nil {
+		return nil, WrapParseError(err, "reading INCLUDE columns")
+	}
+
+	return parseIncludeColumnsLiteral(cp.parser, columnList), nil
+}
+
+// keyConstraintDefinition renders a primary key or unique constraint from its
+// key and included columns.
+func keyConstraintDefinition(keyword string, columns, includeColumns []string) string {
+	definition := fmt.Sprintf("%s (%s)", keyword, schema.QuoteIdentifierList(columns))
+	if len(includeColumns) > 0 {
+		definition += fmt.Sprintf(" INCLUDE (%s)", schema.QuoteIdentifierList(includeColumns))
+	}
+
+	return definition
+}
+
 func (cp *constraintParser) remaining() string {
 	cp.skipComments()
 
@@ -457,7 +665,13 @@ func (cp *constraintParser) parsePrimaryKey(name string) (schema.Constraint, err
 	}
 
 	columns := normalizeIdentifierList(cp.parser, columnList)
-	definition := fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(columns, ", "))
+
+	includeColumns, err := cp.consumeInclude()
+	if err != nil {
+		return schema.Constraint{}, err
+	}
+
+	definition := keyConstraintDefinition("PRIMARY KEY", columns, includeColumns)
 
 	remaining := cp.remaining()
 	isDeferrable := hasKeyword(remaining, "DEFERRABLE")
@@ -468,6 +682,7 @@ func (cp *constraintParser) parsePrimaryKey(name string) (schema.Constraint, err
 		Type:              schema.ConstraintPrimaryKey,
 		Columns:           columns,
 		Definition:        definition,
+		IncludeColumns:    includeColumns,
 		IsDeferrable:      isDeferrable,
 		InitiallyDeferred: initiallyDeferred,
 	}, nil
@@ -484,7 +699,13 @@ func (cp *constraintParser) parseUnique(name string) (schema.Constraint, error)
 	}
 
 	columns := normalizeIdentifierList(cp.parser, columnList)
-	definition := fmt.Sprintf("UNIQUE (%s)", strings.Join(columns, ", "))
+
+	includeColumns, err := cp.consumeInclude()
+	if err != nil {
+		return schema.Constraint{}, err
+	}
+
+	definition := keyConstraintDefinition("UNIQUE", columns, includeColumns)
 
 	remaining := cp.remaining()
 	isDeferrable := hasKeyword(remaining, "DEFERRABLE")
@@ -496,6 +717,7 @@ func (cp *constraintParser) parseUnique(name string) (schema.Constraint, error)
 		Type:              schema.ConstraintUnique,
 		Columns:           columns,
 		Definition:        definition,
+		IncludeColumns:    includeColumns,
 		IsDeferrable:      isDeferrable,
 		InitiallyDeferred: initiallyDeferred,
 	}, nil
@@ -521,6 +743,173 @@ func (cp *constraintParser) parseCheck(name string) (schema.Constraint, error) {
 	}, nil
 }
 
+// parseExclude reads EXCLUDE [USING method] (element WITH operator, ...)
+// with its optional INCLUDE, WITH, USING INDEX TABLESPACE and WHERE clauses.
+// The method defaults to btree, as in PostgreSQL.
+func (cp *constraintParser) parseExclude(name string) (schema.Constraint, error) {
+	if err := cp.consumeWord("EXCLUDE"); err != nil {
+		return schema.Constraint{}, err
+	}
+
+	method := "btree"
+
+	if cp.peekWord() == "USING" {
+		cp.pos++
+
+		token, err := cp.consumeToken()
+		if err != nil {
+			return schema.Constraint{}, WrapParseError(err, "reading EXCLUDE index method")
+		}
+
+		method = strings.ToLower(token.Literal)
+	}
+
+	elementList, err := cp.consumeParenthesized()
+	if err != nil {
+		return schema.Constraint{}, err
+	}
+
+	elements, err := cp.parseExcludeElements(elementList)
+	if err != nil {
+		return schema.Constraint{}, err
+	}
+
+	includeColumns, err := cp.consumeInclude()
+	if err != nil {
+		return schema.Constraint{}, err
+	}
+
+	where, err := cp.consumeExcludeIndexClauses()
+	if err != nil {
+		return schema.Constraint{}, err
+	}
+
+	remaining := cp.remaining()
+	constraint := schema.Constraint{
+		Name:              name,
+		Type:              schema.ConstraintExclude,
+		IncludeColumns:    includeColumns,
+		ExcludeMethod:     method,
+		ExcludeElements:   elements,
+		ExcludeWhere:      where,
+		IsDeferrable:      hasKeyword(remaining, "DEFERRABLE"),
+		InitiallyDeferred: hasKeyword(remaining, "INITIALLY DEFERRED"),
+	}
+	constraint.Definition = constraint.ExcludeDefinition()
+
+	return constraint, nil
+}
+
+// parseExcludeElements splits the element list of an EXCLUDE constraint at
+// the last WITH of each element, which separates it from its operator.
+func (cp *constraintParser) parseExcludeElements(list string) ([]schema.ExcludeElement, error) {
+	parts := splitTableDefinition(list)
+	elements := make([]schema.ExcludeElement, 0, len(parts))
+
+	for _, part := range parts {
+		tokens, err := NewLexer(part).Tokenize()
+		if err != nil {
+			return nil, WrapParseError(err, "tokenizing EXCLUDE element")
+		}
+
+		with, depth := -1, 0
+
+		for i, token := range tokens {
+			switch token.Type {
+			case TokenLParen:
+				depth++
+			case TokenRParen:
+				depth--
+			default:
+				if depth == 0 && upperLiteral(tokens, i) == "WITH" {
+					with = i
+				}
+			}
+		}
+
+		if with == -1 {
+			return nil, NewParseError("missing WITH operator in EXCLUDE element " + part)
+		}
+
+		element := strings.TrimSpace(part[:tokens[with].Start])
+		if first := nextNonCommentIndex(tokens, 0); first < with &&
+			nextNonCommentIndex(tokens, first+1) == with && isExcludeNameToken(tokens[first]) {
+			element = cp.parser.normalizeIdent(tokens[first].Literal)
+		}
+
+		elements = append(elements, schema.ExcludeElement{
+			Element:  element,
+			Operator: excludeOperator(part[tokens[with].End:]),
+		})
+	}
+
+	return elements, nil
+}
+
+// isExcludeNameToken reports whether token can name a column or function in
+// an EXCLUDE element. Unreserved keywords such as name are valid names.
+func isExcludeNameToken(token Token) bool {
+	return token.Type == TokenIdentifier || token.Type == TokenQuotedIdentifier ||
+		token.Type == TokenKeyword
+}
+
+// excludeOperator returns the operator of an EXCLUDE element, unwrapping
+// OPERATOR(pg_catalog.&&) to &&.
+func excludeOperator(operator string) string {
+	operator = strings.TrimSpace(operator)
+
+	if upper := strings.ToUpper(operator); strings.HasPrefix(upper, "OPERATOR") {
+		inner := strings.TrimSpace(operator[len("OPERATOR"):])
+		inner = strings.TrimSuffix(strings.TrimPrefix(inner, "("), ")")
+		operator = strings.TrimSpace(inner)
+	}
+
+	if dot := strings.LastIndex(operator, "."); dot != -1 {
+		operator = operator[dot+1:]
+	}
+
+	return operator
+}
+
+// consumeExcludeIndexClauses skips the WITH (storage parameters) and USING
+// INDEX TABLESPACE clauses of an EXCLUDE constraint and returns its WHERE
+// predicate.
+func (cp *constraintParser) consumeExcludeIndexClauses() (string, error) {
+	where := ""
+
+	for {
+		switch cp.peekWord() {
+		case "WITH":
+			cp.pos++
+
+			if _, err := cp.consumeParenthesized(); err != nil {
+				return "", WrapParseError(err, "reading EXCLUDE storage parameters")
+			}
+		case "USING":
+			for _, word := range []string{"USING", "INDEX", "TABLESPACE"} {
+				if err := cp.consumeWord(word); err != nil {
+					return "", err
+				}
+			}
+
+			if _, err := cp.consumeToken(); err != nil {
+				return "", WrapParseError(err, "reading EXCLUDE tablespace")
+			}
+		case "WHERE":
+			cp.pos++
+
+			predicate, err := cp.consumeParenthesized()
+			if err != nil {
+				return "", WrapParseError(err, "reading EXCLUDE predicate")
+			}
+
+			where = predicate
+		default:
+			return where, nil
+		}
+	}
+}
+
 func (cp *constraintParser) parseForeignKey(name string) (schema.Constraint, error) {
 	if err := cp.consumeWord("FOREIGN"); err != nil {
 		return schema.Constraint{}, err
@@ -569,9 +958,9 @@ func (cp *constraintParser) parseForeignKey(name string) (schema.Constraint, err
 
 	definition := fmt.Sprintf(
 		"FOREIGN KEY (%s) REFERENCES %s(%s)",
-		strings.Join(srcColumns, ", "),
+		schema.QuoteIdentifierList(srcColumns),
 		refTable,
-		strings.Join(refColumns, ", "),
+		schema.QuoteIdentifierList(refColumns),
 	)
 
 	return schema.Constraint{
@@ -649,14 +1038,37 @@ func (p *Parser) parseColumn( //nolint:cyclop,gocyclo
 		return schema.Column{}, nil, errors.New("cannot extract data type")
 	}
 
-	isArray := strings.HasSuffix(dataType, "[]")
+	dataType, isArray := splitArrayType(dataType)
 	baseType, precision, scale, maxLength := parseTypeParams(dataType)
 
 	rest := strings.TrimSpace(def[typeEnd:])
-	constraintTokens := filterConstraintTokens(tokens[constraintStartIdx:])
+	clauseTokens := tokens[constraintStartIdx:]
+
+	generation, first, last, isGenerated := generationClause(def, clauseTokens)
+	if isGenerated {
+		// The words of the expression, such as a NOT NULL in it, are not
+		// constraints of the column.
+		clauseTokens = slices.Concat(clauseTokens[:first], clauseTokens[last+1:])
+	}
+
+	identity, first, last, err := identityClause(def, baseType, clauseTokens)
+	if err != nil {
+		return schema.Column{}, nil, err
+	}
+
+	if identity != nil {
+		clauseTokens = slices.Concat(clauseTokens[:first], clauseTokens[last+1:])
+	}
+
+	constraintTokens := filterConstraintTokens(clauseTokens)
 	upperWords := constraintWords(constraintTokens)
 	defaultVal := extractDefault(rest)
 
+	// The DEFAULT of GENERATED BY DEFAULT AS IDENTITY is not a default.
+	if identity != nil {
+		defaultVal = ""
+	}
+
 	isSerialType := false
 
 	baseTypeUpper := strings.ToUpper(baseType)
@@ -683,19 +1095,29 @@ func (p *Parser) parseColumn( //nolint:cyclop,gocyclo
 			defaultVal = "__SMALLSERIAL__"
 		}
 	default:
-		baseType = strings.ToUpper(baseType)
+		// A quoted type name keeps its case, as format_type writes it.
+		if !strings.Contains(baseType, `"`) {
+			baseType = strings.ToUpper(baseType)
+		}
 	}
 
 	column := schema.Column{
-		Name:       columnName,
-		DataType:   baseType,
-		IsNullable: isColumnNullable(upperWords) && !isSerialType,
-		Default:    defaultVal,
-		Position:   position,
-		Precision:  precision,
-		Scale:      scale,
-		MaxLength:  maxLength,
-		IsArray:    isArray,
+		Name:                 columnName,
+		DataType:             baseType,
+		IsNullable:           isColumnNullable(upperWords) && !isSerialType && identity == nil,
+		Default:              defaultVal,
+		Position:             position,
+		Precision:            precision,
+		Scale:                scale,
+		MaxLength:            maxLength,
+		IsArray:              isArray,
+		Identity:             identity,
+		IsGenerated:          isGenerated,
+		GenerationExpression: generation,
+	}
+
+	if err := setInlineColumnAttributes(&column, tokens[constraintStartIdx:]); err != nil {
+		return schema.Column{}, nil, err
 	}
 
 	var inline []schema.Constraint
@@ -704,7 +1126,7 @@ func (p *Parser) parseColumn( //nolint:cyclop,gocyclo
 		inline = append(inline, schema.Constraint{
 			Type:       schema.ConstraintPrimaryKey,
 			Columns:    []string{columnName},
-			Definition: fmt.Sprintf("PRIMARY KEY (%s)", columnName),
+			Definition: fmt.Sprintf("PRIMARY KEY (%s)", schema.QuoteIdentifier(columnName)),
 		})
 	}
 
@@ -712,7 +1134,7 @@ func (p *Parser) parseColumn( //nolint:cyclop,gocyclo
 		inline = append(inline, schema.Constraint{
 			Type:       schema.ConstraintUnique,
 			Columns:    []string{columnName},
-			Definition: fmt.Sprintf("UNIQUE (%s)", columnName),
+			Definition: fmt.Sprintf("UNIQUE (%s)", schema.QuoteIdentifier(columnName)),
 		})
 	}
 
@@ -731,53 +1153,269 @@ func (p *Parser) parseColumn( //nolint:cyclop,gocyclo
 	return column, inline, nil
 }
 
-func filterConstraintTokens(tokens []Token) []Token {
-	if len(tokens) == 0 {
-		return nil
-	}
+// generationClause finds the GENERATED ALWAYS AS (expression) STORED clause
+// of a column definition. It returns the expression and the indexes of the
+// first and last tokens of the clause, or false when the column is not
+// generated. GENERATED ... AS IDENTITY is not a generation clause.
+func generationClause(def string, tokens []Token) (string, int, int, bool) {
+	depth := 0
 
-	var filtered []Token
+	for i := range tokens {
+		switch tokens[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+		}
 
-	for _, token := range tokens {
-		if token.Type == TokenEOF || token.Type == TokenComment {
+		if depth != 0 || upperLiteral(tokens, i) != "GENERATED" {
 			continue
 		}
 
-		filtered = append(filtered, token)
-	}
+		always := nextNonCommentIndex(tokens, i+1)
+		as := nextNonCommentIndex(tokens, always+1)
+		open := nextNonCommentIndex(tokens, as+1)
 
-	return filtered
-}
+		if upperLiteral(tokens, always) != "ALWAYS" || upperLiteral(tokens, as) != "AS" ||
+			open >= len(tokens) || tokens[open].Type != TokenLParen {
+			continue
+		}
 
-func constraintWords(tokens []Token) []string {
-	if len(tokens) == 0 {
-		return nil
-	}
+		closing := matchingParenToken(tokens, open)
+		if closing == -1 {
+			return "", 0, 0, false
+		}
 
-	var words []string
+		last := closing
+		stored := nextNonCommentIndex(tokens, closing+1)
 
-	for _, token := range tokens {
-		switch token.Type {
-		case TokenKeyword, TokenIdentifier:
-			word := strings.ToUpper(strings.TrimSpace(token.Literal))
-			if word != "" {
-				words = append(words, word)
-			}
+		if upperLiteral(tokens, stored) == "STORED" {
+			last = stored
 		}
+
+		expr := strings.TrimSpace(def[tokens[open].End:tokens[closing].Start])
+
+		return expr, i, last, true
 	}
 
-	return words
+	return "", 0, 0, false
 }
 
-func containsWord(words []string, target string) bool {
-	target = strings.ToUpper(target)
-	return slices.Contains(words, target)
-}
+// identityClause finds the GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY clause
+// of a column definition of dataType, with its optional sequence options. It
+// returns the identity and the indexes of the first and last tokens of the
+// clause, or nil when the column is not an identity column.
+func identityClause(def, dataType string, tokens []Token) (*schema.Identity, int, int, error) {
+	depth := 0
 
-func containsSequence(words []string, sequence ...string) bool {
-	if len(sequence) == 0 || len(words) < len(sequence) {
-		return false
-	}
+	for i := range tokens {
+		switch tokens[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+		}
+
+		if depth != 0 || upperLiteral(tokens, i) != "GENERATED" {
+			continue
+		}
+
+		generation := schema.IdentityAlways
+		mode := nextNonCommentIndex(tokens, i+1)
+		as := nextNonCommentIndex(tokens, mode+1)
+
+		if upperLiteral(tokens, mode) == "BY" && upperLiteral(tokens, as) == "DEFAULT" {
+			generation = schema.IdentityByDefault
+			as = nextNonCommentIndex(tokens, as+1)
+		} else if upperLiteral(tokens, mode) != "ALWAYS" {
+			continue
+		}
+
+		identity := nextNonCommentIndex(tokens, as+1)
+		if upperLiteral(tokens, as) != "AS" || upperLiteral(tokens, identity) != "IDENTITY" {
+			continue
+		}
+
+		seq := &schema.Sequence{DataType: normalizeSequenceType(dataType), Increment: 1}
+		last := identity
+		options := ""
+
+		if open := nextNonCommentIndex(tokens, identity+1); open < len(tokens) &&
+			tokens[open].Type == TokenLParen {
+			last = matchingParenToken(tokens, open)
+			if last == -1 {
+				return nil, 0, 0, errors.New("unterminated identity options")
+			}
+
+			options = def[tokens[open].End:tokens[last].Start]
+		}
+
+		if err := parseSequenceOptions(seq, options); err != nil {
+			return nil, 0, 0, fmt.Errorf("identity options: %w", err)
+		}
+
+		return schema.IdentityFromSequence(generation, seq), i, last, nil
+	}
+
+	return nil, 0, 0, nil
+}
+
+// matchingParenToken returns the index of the token closing the parenthesis
+// opened at open, or -1 when it is never closed.
+func matchingParenToken(tokens []Token, open int) int {
+	depth := 0
+
+	for i := open; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// setInlineColumnAttributes records the STORAGE and COMPRESSION clauses of a
+// column definition, which follow its type.
+func setInlineColumnAttributes(column *schema.Column, tokens []Token) error {
+	depth := 0
+
+	for i := range tokens {
+		switch tokens[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+		}
+
+		if depth != 0 {
+			continue
+		}
+
+		switch upperLiteral(tokens, i) {
+		case "STORAGE":
+			if err := setColumnStorage(column, columnAttributeValue(tokens, i)); err != nil {
+				return err
+			}
+		case "COMPRESSION":
+			if err := setColumnCompression(column, columnAttributeValue(tokens, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func columnAttributeValue(tokens []Token, idx int) string {
+	return strings.ToLower(upperLiteral(tokens, nextNonCommentIndex(tokens, idx+1)))
+}
+
+// setColumnStorage records the storage strategy of column. DEFAULT returns
+// it to the default of the column's type.
+func setColumnStorage(column *schema.Column, storage string) error {
+	switch {
+	case storage == "default":
+		column.Storage = ""
+	case schema.IsStorage(storage):
+		column.Storage = storage
+	default:
+		return fmt.Errorf("invalid storage %q for column %s", storage, column.Name)
+	}
+
+	return nil
+}
+
+// setColumnIdentity makes column an identity column with the GENERATED
+// clause def. Identity columns are NOT NULL.
+func setColumnIdentity(column *schema.Column, def string) error {
+	tokens, err := NewLexer(def).Tokenize()
+	if err != nil {
+		return WrapParseError(err, "tokenizing identity clause")
+	}
+
+	identity, _, _, err := identityClause(def, column.DataType, tokens)
+	if err != nil {
+		return fmt.Errorf("column %s: %w", column.Name, err)
+	}
+
+	if identity == nil {
+		return fmt.Errorf("invalid identity clause for column %s", column.Name)
+	}
+
+	column.Identity = identity
+	column.IsNullable = false
+
+	return nil
+}
+
+// setColumnCompression records the compression method of column. DEFAULT
+// returns it to default_toast_compression.
+func setColumnCompression(column *schema.Column, compression string) error {
+	switch {
+	case compression == "default":
+		column.Compression = ""
+	case schema.IsCompression(compression):
+		column.Compression = compression
+	default:
+		return fmt.Errorf("invalid compression %q for column %s", compression, column.Name)
+	}
+
+	return nil
+}
+
+func filterConstraintTokens(tokens []Token) []Token {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var filtered []Token
+
+	for _, token := range tokens {
+		if token.Type == TokenEOF || token.Type == TokenComment {
+			continue
+		}
+
+		filtered = append(filtered, token)
+	}
+
+	return filtered
+}
+
+func constraintWords(tokens []Token) []string {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var words []string
+
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenKeyword, TokenIdentifier:
+			word := strings.ToUpper(strings.TrimSpace(token.Literal))
+			if word != "" {
+				words = append(words, word)
+			}
+		}
+	}
+
+	return words
+}
+
+func containsWord(words []string, target string) bool {
+	target = strings.ToUpper(target)
+	return slices.Contains(words, target)
+}
+
+func containsSequence(words []string, sequence ...string) bool {
+	if len(sequence) == 0 || len(words) < len(sequence) {
+		return false
+	}
 
 	for i := 0; i <= len(words)-len(sequence); i++ {
 		match := true
@@ -864,11 +1502,7 @@ func (p *Parser) parseConstraint(def string) (schema.Constraint, error) {
 	case "CHECK":
 		return parser.parseCheck(name)
 	case "EXCLUDE":
-		return schema.Constraint{
-			Name:       name,
-			Type:       schema.ConstraintExclude,
-			Definition: def,
-		}, nil
+		return parser.parseExclude(name)
 	default:
 		return schema.Constraint{}, NewParseError("unknown constraint type")
 	}
@@ -978,9 +1612,9 @@ func (p *Parser) parseInlineForeignKey(colName, def string) (schema.Constraint,
 
 	definition := fmt.Sprintf(
 		"FOREIGN KEY (%s) REFERENCES %s(%s)",
-		colName,
+		schema.QuoteIdentifier(colName),
 		refTableLiteral,
-		strings.Join(refColumns, ", "),
+		schema.QuoteIdentifierList(refColumns),
 	)
 
 	return schema.Constraint{
@@ -1032,16 +1666,40 @@ func (p *Parser) parseInlineCheck(colName, def string) (schema.Constraint, error
 	}, nil
 }
 
+var arrayTypeSuffixRe = regexp.MustCompile(`(?i)(?:\[\s*\d*\s*\]|\bARRAY(?:\s*\[\s*\d*\s*\])?)\s*$`)
+
+// splitArrayType strips the array decorations PostgreSQL accepts after a
+// type, such as [], [3], [][] and ARRAY, from dataType. Array dimensions are
+// not enforced, so they are not kept.
+func splitArrayType(dataType string) (string, bool) {
+	dataType = strings.TrimSpace(dataType)
+	isArray := false
+
+	for {
+		loc := arrayTypeSuffixRe.FindStringIndex(dataType)
+		if loc == nil {
+			break
+		}
+
+		dataType = strings.TrimSpace(dataType[:loc[0]])
+		isArray = true
+	}
+
+	return dataType, isArray
+}
+
 func parseTypeParams(dataType string) (base string, precision, scale, maxLength *int) {
 	dataType = strings.TrimSpace(dataType)
 
-	if strings.HasSuffix(dataType, "[]") {
-		base = strings.TrimSuffix(dataType, "[]")
-		return base, nil, nil, nil
+	// The precision of timestamp(3) with time zone comes before the rest of
+	// the type, which has to be kept.
+	if schema.IsTimeType(dataType) {
+		base, precision = schema.ParseTimeType(dataType)
+		return base, precision, nil, nil
 	}
 
 	if !strings.Contains(dataType, "(") {
-		return dataType, nil, nil, nil
+		return strings.Join(strings.Fields(dataType), " "), nil, nil, nil
 	}
 
 	openIdx := strings.Index(dataType, "(")
@@ -1120,7 +1778,9 @@ func (p *Parser) finalizeTableConstraints(table *schema.Table) {
 		col := &table.Columns[i]
 		if col.Default == "__SERIAL__" || col.Default == "__BIGSERIAL__" ||
 			col.Default == "__SMALLSERIAL__" {
-			sequenceName := fmt.Sprintf("%s_%s_seq", table.Name, col.Name)
+			// PostgreSQL names the sequence itself, shortening the table and
+			// column names rather than adding a digest.
+			sequenceName := makeObjectName(table.Name, col.Name, "seq")
 			if table.Schema != "" && table.Schema != schema.DefaultSchema {
 				sequenceName = fmt.Sprintf("%s.%s", table.Schema, sequenceName)
 			}
@@ -1141,113 +1801,218 @@ func (p *Parser) finalizeTableConstraints(table *schema.Table) {
 		constraint := &table.Constraints[i]
 
 		if constraint.Name == "" {
-			base := p.generateConstraintName(table.Name, constraint)
+			parts, suffix := constraintNameParts(table.Name, constraint)
+			base := schema.SynthesizedIdentifier(suffix, parts...)
+			legacy := schema.TruncateIdentifier(strings.Join(parts, "_") + "_" + suffix)
 
 			name := base
 			if n := usedNames[base]; n > 0 {
-				name = fmt.Sprintf("%s%d", base, n)
+				name = schema.SynthesizedIdentifier(fmt.Sprintf("%s%d", suffix, n), parts...)
+				legacy = fmt.Sprintf("%s%d", legacy, n)
 			}
 
 			constraint.Name = name
+			if legacy != name {
+				constraint.LegacyName = legacy
+			}
+
 			usedNames[base]++
 		}
 
-		if constraint.Type == schema.ConstraintPrimaryKey ||
-			constraint.Type == schema.ConstraintUnique {
-			indexName := constraint.Name
-			if constraint.Type == schema.ConstraintPrimaryKey {
-				table.Indexes = append(table.Indexes, schema.Index{
-					Schema:    table.Schema,
-					TableName: table.Name,
-					Name:      indexName,
-					Columns:   constraint.Columns,
-					Type:      "btree",
-					IsUnique:  true,
-					IsPrimary: true,
-					Definition: fmt.Sprintf(
-						"CREATE UNIQUE INDEX %s ON %s USING btree (%s)",
-						indexName,
-						table.QualifiedName(),
-						strings.Join(constraint.Columns, ", "),
-					),
-				})
-			} else {
-				table.Indexes = append(table.Indexes, schema.Index{
-					Schema:    table.Schema,
-					TableName: table.Name,
-					Name:      indexName,
-					Columns:   constraint.Columns,
-					Type:      "btree",
-					IsUnique:  true,
-					IsPrimary: false,
-					Definition: fmt.Sprintf(
-						"CREATE UNIQUE INDEX %s ON %s USING btree (%s)",
-						indexName,
-						table.QualifiedName(),
-						strings.Join(constraint.Columns, ", "),
-					),
-				})
-			}
+		p.finalizeConstraint(table, constraint)
+	}
+}
+
+// finalizeConstraint adds the index backing a primary key or unique
+// constraint and resolves a foreign key's referenced schema.
+func (p *Parser) finalizeConstraint(table *schema.Table, constraint *schema.Constraint) {
+	if constraint.Type == schema.ConstraintPrimaryKey ||
+		constraint.Type == schema.ConstraintUnique {
+		indexName := constraint.Name
+
+		definition := fmt.Sprintf(
+			"CREATE UNIQUE INDEX %s ON %s USING btree (%s)",
+			schema.QuoteIdentifier(indexName),
+			schema.QuotedName(table.Schema, table.Name),
+			schema.QuoteIdentifierList(constraint.Columns),
+		)
+		if len(constraint.IncludeColumns) > 0 {
+			definition += fmt.Sprintf(" INCLUDE (%s)",
+				schema.QuoteIdentifierList(constraint.IncludeColumns))
 		}
 
-		if constraint.Type == schema.ConstraintForeignKey && constraint.ReferencedTable != "" {
-			refSchema, refTable := p.splitSchemaTable(constraint.ReferencedTable)
-			if refSchema == "" {
-				refSchema = table.Schema
-			}
+		if constraint.Type == schema.ConstraintPrimaryKey {
+			table.Indexes = append(table.Indexes, schema.Index{
+				Schema:         table.Schema,
+				TableName:      table.Name,
+				Name:           indexName,
+				Columns:        constraint.Columns,
+				Type:           "btree",
+				IsUnique:       true,
+				IsPrimary:      true,
+				IncludeColumns: constraint.IncludeColumns,
+				Definition:     definition,
+			})
+		} else {
+			table.Indexes = append(table.Indexes, schema.Index{
+				Schema:         table.Schema,
+				TableName:      table.Name,
+				Name:           indexName,
+				Columns:        constraint.Columns,
+				Type:           "btree",
+				IsUnique:       true,
+				IsPrimary:      false,
+				IncludeColumns: constraint.IncludeColumns,
+				Definition:     definition,
+			})
+		}
+	}
 
-			constraint.ReferencedSchema = refSchema
-			constraint.ReferencedTable = refTable
+	if constraint.Type == schema.ConstraintForeignKey && constraint.ReferencedTable != "" {
+		refSchema, refTable := p.splitSchemaTable(constraint.ReferencedTable)
+		if refSchema == "" {
+			refSchema = table.Schema
 		}
+
+		constraint.ReferencedSchema = refSchema
+		constraint.ReferencedTable = refTable
 	}
 }
 
-func (p *Parser) generateConstraintName(tableName string, constraint *schema.Constraint) string {
-	var name string
+// excludeColumnNames returns the names PostgreSQL gives the index columns of
+// an EXCLUDE constraint's elements, from which it names the constraint: a
+// column's name, the name of a function called, or expr, with a number added
+// to repeated names.
+func excludeColumnNames(elements []schema.ExcludeElement) []string {
+	names := make([]string, 0, len(elements))
+	used := make(map[string]bool, len(elements))
+
+	for _, element := range elements {
+		name := excludeColumnName(element.Element)
+
+		unique := name
+		for i := 1; used[unique]; i++ {
+			unique = fmt.Sprintf("%s%d", name, i)
+		}
 
+		used[unique] = true
+		names = append(names, unique)
+	}
+
+	return names
+}
+
+func excludeColumnName(element string) string {
+	tokens, err := NewLexer(element).Tokenize()
+	if err != nil {
+		return "expr"
+	}
+
+	first := nextNonCommentIndex(tokens, 0)
+	isIdentifier := func(idx int) bool {
+		return idx < len(tokens) && isExcludeNameToken(tokens[idx])
+	}
+
+	if isIdentifier(first) {
+		return schema.FoldIdentifier(tokens[first].Literal)
+	}
+
+	// An expression is named after the function it calls, if any.
+	if first >= len(tokens) || tokens[first].Type != TokenLParen {
+		return "expr"
+	}
+
+	name := nextNonCommentIndex(tokens, first+1)
+	next := nextNonCommentIndex(tokens, name+1)
+
+	for isIdentifier(name) && next < len(tokens) && tokens[next].Type == TokenDot {
+		name = nextNonCommentIndex(tokens, next+1)
+		next = nextNonCommentIndex(tokens, name+1)
+	}
+
+	if isIdentifier(name) && next < len(tokens) && tokens[next].Type == TokenLParen {
+		return schema.FoldIdentifier(tokens[name].Literal)
+	}
+
+	return "expr"
+}
+
+// constraintNameParts returns the parts and suffix of the name PostgreSQL
+// would give an unnamed constraint, such as orders, customer_id and fkey.
+func constraintNameParts(tableName string, constraint *schema.Constraint) ([]string, string) {
 	switch constraint.Type {
 	case schema.ConstraintPrimaryKey:
-		name = tableName + "_pkey"
+		return []string{tableName}, "pkey"
 	case schema.ConstraintUnique:
-		if len(constraint.Columns) == 1 {
-			name = tableName + "_" + constraint.Columns[0] + "_key"
-		} else {
-			name = tableName + "_" + strings.Join(constraint.Columns, "_") + "_key"
-		}
+		return append([]string{tableName}, constraint.Columns...), "key"
 	case schema.ConstraintForeignKey:
-		if len(constraint.Columns) == 1 {
-			name = tableName + "_" + constraint.Columns[0] + "_fkey"
-		} else {
-			name = tableName + "_" + strings.Join(constraint.Columns, "_") + "_fkey"
-		}
+		return append([]string{tableName}, constraint.Columns...), "fkey"
 	case schema.ConstraintCheck:
 		if len(constraint.Columns) == 1 {
-			name = tableName + "_" + constraint.Columns[0] + "_check"
-		} else {
-			name = tableName + "_check"
+			return []string{tableName, constraint.Columns[0]}, "check"
 		}
+
+		return []string{tableName}, "check"
 	case schema.ConstraintExclude:
-		name = tableName + "_exclude"
+		return append([]string{tableName}, excludeColumnNames(constraint.ExcludeElements)...),
+			"excl"
 	default:
-		name = tableName + "_constraint"
+		return []string{tableName}, "constraint"
 	}
-
-	return schema.TruncateIdentifier(name)
 }
 
-func (p *Parser) parseAlterTable(stmt string, db *schema.Database) error {
-	if !hasKeyword(strings.ToUpper(stmt), "TIMESCALEDB.COMPRESS") {
-		return nil
+var alterTableUsingIndexRe = regexp.MustCompile(
+	`(?is)ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(` + qualifiedNamePattern + `)` +
+		`\s+ADD\s+CONSTRAINT\s+(` + identPattern + `)\s+(UNIQUE|PRIMARY\s+KEY)` +
+		`\s+USING\s+INDEX\s+(` + identPattern + `)(.*)$`,
+)
+
+func (p *Parser) parseAlterTable(stmt string, line int, db *schema.Database) error {
+	if matches := alterTableUsingIndexRe.FindStringSubmatch(stmt); matches != nil {
+		return p.parseAddConstraintUsingIndex(matches, db)
 	}
 
-	pattern := regexp.MustCompile(`(?i)ALTER\s+TABLE\s+([a-zA-Z_][a-zA-Z0-9_.]*)\s+SET`)
+	alter, err := p.parseAlterTableTarget(stmt)
+	if err != nil {
+		return err
+	}
 
-	matches := pattern.FindStringSubmatch(stmt)
-	if len(matches) < 2 {
-		return errors.New("cannot extract table name")
+	switch alter.action() {
+	case "ADD CONSTRAINT":
+		return p.parseAlterTableAddConstraint(alter, db)
+	case "ATTACH PARTITION":
+		return p.parseAttachPartition(alter, db)
+	case "ENABLE TRIGGER", "ENABLE REPLICA", "ENABLE ALWAYS", "DISABLE TRIGGER":
+		return p.parseAlterTableTriggerState(alter, db)
+	case "ENABLE ROW", "DISABLE ROW":
+		return p.parseAlterTableRowSecurity(alter, db)
 	}
 
-	schemaName, tableName := p.splitSchemaTable(matches[1])
+	if attr, ok := p.parseAlterColumnAttribute(alter); ok {
+		// A typed table's columns are derived once every file is parsed.
+		table := db.GetTable(alter.schemaName, alter.tableName)
+		if table == nil || table.TypedOf != "" {
+			ctx := p.ensureContext()
+			ctx.deferredColumns = append(ctx.deferredColumns, attr)
+
+			return nil
+		}
+
+		return p.applyColumnAttribute(table, attr)
+	}
+
+	if !hasKeyword(strings.ToUpper(stmt), "TIMESCALEDB.COMPRESS") {
+		p.addWarning(
+			diag.CodeSkippedStatement,
+			line,
+			"",
+			"unsupported statement: "+truncate(stmt, 50),
+		)
+
+		return nil
+	}
+
+	schemaName, tableName := alter.schemaName, alter.tableName
 
 	var ht *schema.Hypertable
 
@@ -1314,6 +2079,452 @@ func (p *Parser) parseAlterTable(stmt string, db *schema.Database) error {
 	return nil
 }
 
+// alterTableStatement is the table an ALTER TABLE targets and the action
+// that follows it. pg_dump writes ALTER TABLE ONLY so an action does not
+// recurse into partitions; ONLY is recorded but the target is the same table.
+type alterTableStatement struct {
+	schemaName string
+	tableName  string
+	only       bool
+	stmt       string
+	tokens     []Token
+	actionIdx  int
+}
+
+func (p *Parser) parseAlterTableTarget(stmt string) (*alterTableStatement, error) {
+	tokens, err := NewLexer(stmt).Tokenize()
+	if err != nil {
+		return nil, WrapParseError(err, "tokenizing ALTER TABLE statement")
+	}
+
+	idx := nextNonCommentIndex(tokens, 0)
+	if upperLiteral(tokens, idx) != "ALTER" {
+		return nil, NewParseError("expected ALTER keyword")
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if upperLiteral(tokens, idx) != "TABLE" {
+		return nil, NewParseError("expected TABLE keyword")
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if upperLiteral(tokens, idx) == "IF" {
+		existsIdx := nextNonCommentIndex(tokens, idx+1)
+		if upperLiteral(tokens, existsIdx) != "EXISTS" {
+			return nil, NewParseError("malformed IF EXISTS clause")
+		}
+
+		idx = nextNonCommentIndex(tokens, existsIdx+1)
+	}
+
+	only := false
+	if upperLiteral(tokens, idx) == "ONLY" {
+		only = true
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	name, idx := readQualifiedName(tokens, idx)
+	if name == "" {
+		return nil, errors.New("cannot extract table name")
+	}
+
+	// A trailing * includes descendant tables, which is already the default.
+	if idx < len(tokens) && tokens[idx].Literal == "*" {
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	schemaName, tableName := p.splitSchemaTable(name)
+
+	return &alterTableStatement{
+		schemaName: schemaName,
+		tableName:  tableName,
+		only:       only,
+		stmt:       stmt,
+		tokens:     tokens,
+		actionIdx:  idx,
+	}, nil
+}
+
+// action returns the first two words of the action, such as
+// "ADD CONSTRAINT".
+func (a *alterTableStatement) action() string {
+	second := nextNonCommentIndex(a.tokens, a.actionIdx+1)
+	return upperLiteral(a.tokens, a.actionIdx) + " " + upperLiteral(a.tokens, second)
+}
+
+// actionSQL returns the action text from its first word up to the
+// terminating semicolon.
+func (a *alterTableStatement) actionSQL() string {
+	if a.actionIdx >= len(a.tokens) {
+		return ""
+	}
+
+	end := len(a.stmt)
+	if semicolonIdx := findToken(a.tokens, TokenSemicolon, a.actionIdx); semicolonIdx != -1 {
+		end = a.tokens[semicolonIdx].Start
+	}
+
+	return strings.TrimSpace(a.stmt[a.tokens[a.actionIdx].Start:end])
+}
+
+// readQualifiedName reads a possibly schema-qualified name starting at idx
+// and returns it with the index of the token after it.
+func readQualifiedName(tokens []Token, idx int) (string, int) {
+	var sb strings.Builder
+
+	for idx < len(tokens) {
+		token := tokens[idx]
+
+		switch {
+		case token.Type == TokenDot && sb.Len() > 0:
+			sb.WriteString(".")
+		case (token.Type == TokenIdentifier || token.Type == TokenQuotedIdentifier ||
+			token.Type == TokenKeyword) && (sb.Len() == 0 || strings.HasSuffix(sb.String(), ".")):
+			sb.WriteString(token.Literal)
+		default:
+			return sb.String(), idx
+		}
+
+		idx++
+	}
+
+	return sb.String(), idx
+}
+
+// parseAlterTableTriggerState records ALTER TABLE ... ENABLE [REPLICA | ALWAYS]
+// TRIGGER and DISABLE TRIGGER on the named trigger. ALL and USER apply the
+// state to every trigger declared on the table so far.
+func (p *Parser) parseAlterTableTriggerState(
+	alter *alterTableStatement,
+	db *schema.Database,
+) error {
+	tokens := alter.tokens
+	state := schema.TriggerEnabledDisabled
+
+	idx := nextNonCommentIndex(tokens, alter.actionIdx+1)
+	if upperLiteral(tokens, alter.actionIdx) == "ENABLE" {
+		state = ""
+
+		switch upperLiteral(tokens, idx) {
+		case "REPLICA":
+			state = schema.TriggerEnabledReplica
+			idx = nextNonCommentIndex(tokens, idx+1)
+		case "ALWAYS":
+			state = schema.TriggerEnabledAlways
+			idx = nextNonCommentIndex(tokens, idx+1)
+		}
+	}
+
+	if upperLiteral(tokens, idx) != "TRIGGER" {
+		return NewParseError("expected TRIGGER keyword")
+	}
+
+	idx = nextNonCommentIndex(tokens, idx+1)
+	if idx >= len(tokens) || tokens[idx].Type == TokenSemicolon {
+		return NewParseError("missing trigger name")
+	}
+
+	all := tokens[idx].Type != TokenQuotedIdentifier &&
+		(upperLiteral(tokens, idx) == "ALL" || upperLiteral(tokens, idx) == "USER")
+	name := p.normalizeIdent(tokens[idx].Literal)
+	found := false
+
+	for i := range db.Triggers {
+		trigger := &db.Triggers[i]
+		if trigger.Schema != alter.schemaName || trigger.TableName != alter.tableName {
+			continue
+		}
+
+		if all || trigger.Name == name {
+			trigger.EnabledState = state
+			found = true
+		}
+	}
+
+	if !found && !all {
+		qualified := schema.QualifiedName(alter.schemaName, alter.tableName)
+		p.addWarning(
+			diag.CodeObjectNotFound,
+			0,
+			qualified,
+			fmt.Sprintf("trigger %s not found on table %s", name, qualified),
+		)
+	}
+
+	return nil
+}
+
+// columnAttribute is a SET STORAGE, SET COMPRESSION, SET DEFAULT or ADD
+// GENERATED ... AS IDENTITY of one column.
+type columnAttribute struct {
+	schemaName string
+	tableName  string
+	column     string
+	// name is STORAGE, COMPRESSION, DEFAULT or IDENTITY.
+	name string
+	// value is the storage or compression method, the default expression, or
+	// the GENERATED clause of an identity.
+	value string
+}
+
+// clause describes the ALTER COLUMN action of attr.
+func (attr columnAttribute) clause() string {
+	if attr.name == "IDENTITY" {
+		return "ADD GENERATED AS IDENTITY"
+	}
+
+	return "SET " + attr.name
+}
+
+// parseAlterColumnAttribute reads ALTER TABLE ... ALTER [COLUMN] c SET
+// STORAGE s, SET COMPRESSION m, SET DEFAULT expr or ADD GENERATED ... AS
+// IDENTITY, the last two as pg_dump writes the defaults and identities of
+// columns. It reports false for any other action, including a statement with
+// further actions after this one.
+func (p *Parser) parseAlterColumnAttribute(alter *alterTableStatement) (columnAttribute, bool) {
+	tokens := alter.tokens
+	if upperLiteral(tokens, alter.actionIdx) != "ALTER" {
+		return columnAttribute{}, false
+	}
+
+	idx := nextNonCommentIndex(tokens, alter.actionIdx+1)
+	if upperLiteral(tokens, idx) == "COLUMN" && tokens[idx].Type != TokenQuotedIdentifier {
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	if idx >= len(tokens) || tokens[idx].Type == TokenSemicolon || tokens[idx].Type == TokenEOF {
+		return columnAttribute{}, false
+	}
+
+	setIdx := nextNonCommentIndex(tokens, idx+1)
+	nameIdx := nextNonCommentIndex(tokens, setIdx+1)
+	valueIdx := nextNonCommentIndex(tokens, nameIdx+1)
+	endIdx := nextNonCommentIndex(tokens, valueIdx+1)
+
+	name := upperLiteral(tokens, nameIdx)
+	switch {
+	case upperLiteral(tokens, setIdx) == "SET" && name == "DEFAULT":
+		return p.columnExpressionAttribute(alter, idx, name, valueIdx)
+	case upperLiteral(tokens, setIdx) == "ADD" && name == "GENERATED":
+		return p.columnExpressionAttribute(alter, idx, "IDENTITY", nameIdx)
+	}
+
+	if upperLiteral(tokens, setIdx) != "SET" || (name != "STORAGE" && name != "COMPRESSION") ||
+		valueIdx >= len(tokens) ||
+		(endIdx < len(tokens) && tokens[endIdx].Type != TokenSemicolon &&
+			tokens[endIdx].Type != TokenEOF) {
+		return columnAttribute{}, false
+	}
+
+	return columnAttribute{
+		schemaName: alter.schemaName,
+		tableName:  alter.tableName,
+		column:     p.normalizeIdent(tokens[idx].Literal),
+		name:       name,
+		value:      strings.ToLower(upperLiteral(tokens, valueIdx)),
+	}, true
+}
+
+// columnExpressionAttribute returns the attribute name of the column at
+// columnIdx whose value is the text from the token at start up to the end of
+// the statement. It reports false when a comma starts another action.
+func (p *Parser) columnExpressionAttribute(
+	alter *alterTableStatement,
+	columnIdx int,
+	name string,
+	start int,
+) (columnAttribute, bool) {
+	tokens := alter.tokens
+
+	end := len(alter.stmt)
+	if semicolonIdx := findToken(tokens, TokenSemicolon, start); semicolonIdx != -1 {
+		end = tokens[semicolonIdx].Start
+	}
+
+	depth := 0
+
+	for i := start; i < len(tokens) && tokens[i].Start < end; i++ {
+		switch tokens[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+		case TokenComma:
+			if depth == 0 {
+				return columnAttribute{}, false
+			}
+		}
+	}
+
+	if start >= len(tokens) || tokens[start].Start >= end {
+		return columnAttribute{}, false
+	}
+
+	return columnAttribute{
+		schemaName: alter.schemaName,
+		tableName:  alter.tableName,
+		column:     p.normalizeIdent(tokens[columnIdx].Literal),
+		name:       name,
+		value:      strings.TrimSpace(alter.stmt[tokens[start].Start:end]),
+	}, true
+}
+
+// applyColumnAttribute sets attr on its column of table. A column the table
+// does not declare is reported and otherwise ignored.
+func (p *Parser) applyColumnAttribute(table *schema.Table, attr columnAttribute) error {
+	for i := range table.Columns {
+		column := &table.Columns[i]
+		if column.Name != attr.column {
+			continue
+		}
+
+		switch attr.name {
+		case "STORAGE":
+			return setColumnStorage(column, attr.value)
+		case "DEFAULT":
+			column.Default = attr.value
+			return nil
+		case "IDENTITY":
+			return setColumnIdentity(column, attr.value)
+		}
+
+		return setColumnCompression(column, attr.value)
+	}
+
+	p.addWarning(
+		diag.CodeObjectNotFound,
+		0,
+		table.QualifiedName(),
+		fmt.Sprintf("column %s not found on table %s", attr.column, table.QualifiedName()),
+	)
+
+	return nil
+}
+
+// parseAlterTableAddConstraint adds a constraint declared with ALTER TABLE
+// ... ADD CONSTRAINT, the form pg_dump uses for every primary key, unique and
+// foreign key constraint. On a partition the constraint is recorded as local
+// to it unless the parent declares it: PostgreSQL creates those copies from
+// the partitioned parent's constraints.
+func (p *Parser) parseAlterTableAddConstraint(
+	alter *alterTableStatement,
+	db *schema.Database,
+) error {
+	// Drop ADD so the definition starts at CONSTRAINT, as inside CREATE TABLE.
+	definition := strings.TrimSpace(alter.actionSQL()[len("ADD"):])
+
+	parent, partition := p.findPartition(db, alter.schemaName, alter.tableName)
+	if partition != nil {
+		constraint, err := p.parseConstraint(definition)
+		if err != nil {
+			return WrapParseError(err, "parsing constraint")
+		}
+
+		if (parent == nil || parent.GetConstraint(constraint.Name) == nil) &&
+			!slices.ContainsFunc(partition.Constraints, func(existing schema.Constraint) bool {
+				return existing.Name == constraint.Name
+			}) {
+			// The partition's indexes come from CREATE INDEX, so the index
+			// finalizeConstraint adds for a key is discarded with the table.
+			p.finalizeConstraint(
+				&schema.Table{Schema: alter.schemaName, Name: alter.tableName},
+				&constraint,
+			)
+			partition.Constraints = append(partition.Constraints, constraint)
+		}
+
+		return nil
+	}
+
+	table := db.GetTable(alter.schemaName, alter.tableName)
+	if table == nil {
+		return fmt.Errorf("table %s.%s not found", alter.schemaName, alter.tableName)
+	}
+
+	constraint, err := p.parseConstraint(definition)
+	if err != nil {
+		return WrapParseError(err, "parsing constraint")
+	}
+
+	for i := range table.Constraints {
+		if table.Constraints[i].Name == constraint.Name {
+			return nil
+		}
+	}
+
+	p.finalizeConstraint(table, &constraint)
+	table.Constraints = append(table.Constraints, constraint)
+
+	if table.PartitionStrategy != nil {
+		for i := range table.PartitionStrategy.Partitions {
+			dropInheritedConstraints(table, &table.PartitionStrategy.Partitions[i])
+		}
+	}
+
+	return nil
+}
+
+// parseAddConstraintUsingIndex handles ALTER TABLE ... ADD CONSTRAINT ... USING INDEX,
+// which promotes an existing unique index to a constraint. PostgreSQL renames the
+// adopted index after the constraint, so the index is renamed here too and the
+// differ treats it as constraint-backed by name.
+func (p *Parser) parseAddConstraintUsingIndex(matches []string, db *schema.Database) error {
+	schemaName, tableName := p.splitSchemaTable(matches[1])
+	constraintName := p.normalizeIdent(matches[2])
+	indexName := p.normalizeIdent(matches[4])
+	remaining := matches[5]
+
+	table := db.GetTable(schemaName, tableName)
+	if table == nil {
+		return fmt.Errorf("table %s.%s not found", schemaName, tableName)
+	}
+
+	var index *schema.Index
+
+	for i := range table.Indexes {
+		if table.Indexes[i].Name == indexName {
+			index = &table.Indexes[i]
+			break
+		}
+	}
+
+	if index == nil {
+		return fmt.Errorf("index %s not found on table %s.%s", indexName, schemaName, tableName)
+	}
+
+	index.Name = constraintName
+
+	constraintType := schema.ConstraintUnique
+	if strings.HasPrefix(strings.ToUpper(matches[3]), "PRIMARY") {
+		constraintType = schema.ConstraintPrimaryKey
+		index.IsPrimary = true
+	}
+
+	columns := append([]string(nil), index.Columns...)
+
+	isDeferrable := hasKeyword(remaining, "DEFERRABLE") && !hasKeyword(remaining, "NOT DEFERRABLE")
+
+	constraint := schema.Constraint{
+		Name:              constraintName,
+		Type:              constraintType,
+		Columns:           columns,
+		Definition:        constraintType + " (" + schema.QuoteIdentifierList(columns) + ")",
+		IsDeferrable:      isDeferrable,
+		InitiallyDeferred: hasKeyword(remaining, "INITIALLY DEFERRED"),
+	}
+
+	for i := range table.Constraints {
+		if table.Constraints[i].Name == constraintName {
+			table.Constraints[i] = constraint
+			return nil
+		}
+	}
+
+	table.Constraints = append(table.Constraints, constraint)
+
+	return nil
+}
+
 func (p *Parser) parsePartitionBy(stmt string) *schema.PartitionStrategy {
 	strategy, err := p.parsePartitionByTokens(stmt)
 	if err != nil {
@@ -1422,7 +2633,19 @@ func (p *Parser) parsePartitionOfTokens(stmt string, db *schema.Database) error
 		return errors.New("missing TABLE keyword")
 	}
 
-	tableLiteral, afterTableIdx := collectLiteralUntil(tokens, stmt, tableIdx+1, "PARTITION")
+	nameIdx := nextNonCommentIndex(tokens, tableIdx+1)
+	if upperLiteral(tokens, nameIdx) == "IF" {
+		notIdx := nextNonCommentIndex(tokens, nameIdx+1)
+
+		existsIdx := nextNonCommentIndex(tokens, notIdx+1)
+		if upperLiteral(tokens, notIdx) != "NOT" || upperLiteral(tokens, existsIdx) != "EXISTS" {
+			return NewParseError("malformed IF NOT EXISTS clause")
+		}
+
+		nameIdx = existsIdx + 1
+	}
+
+	tableLiteral, afterTableIdx := collectLiteralUntil(tokens, stmt, nameIdx, "PARTITION")
 	if tableLiteral == "" {
 		return errors.New("cannot extract partition table name")
 	}
@@ -1454,57 +2677,233 @@ func (p *Parser) parsePartitionOfTokens(stmt string, db *schema.Database) error
 
 	parentSchema, parentName := p.splitSchemaTable(parentLiteral)
 
-	partitionDef := ""
+	partitionDef := partitionBoundSQL(tokens, stmt, afterParentIdx)
 
-	forIdx := findKeyword(tokens, "FOR", afterParentIdx)
-	if forIdx != -1 {
-		valuesIdx := nextNonCommentIndex(tokens, forIdx+1)
-		if valuesIdx < len(tokens) && upperLiteral(tokens, valuesIdx) == "VALUES" {
-			start := tokens[forIdx].Start
+	parentTable := db.GetTable(parentSchema, parentName)
+	if parentTable == nil {
+		ctx := p.ensureContext()
+		ctx.deferred = append(ctx.deferred, deferredPartition{
+			parentSchema: parentSchema,
+			parentName:   parentName,
+			partition: schema.Partition{
+				Name:       partitionName,
+				Definition: partitionDef,
+			},
+		})
 
-			end := len(stmt)
-			if semicolonIdx := findToken(tokens, TokenSemicolon, forIdx); semicolonIdx != -1 {
-				end = tokens[semicolonIdx].Start
-			}
+		return nil
+	}
 
-			partitionDef = strings.TrimSpace(stmt[start:end])
-		}
+	addPartition(parentTable, schema.Partition{
+		Name:       partitionName,
+		Definition: partitionDef,
+	})
+
+	return nil
+}
+
+// partitionBoundSQL returns the FOR VALUES clause found at or after idx, or
+// an empty string when there is none.
+func partitionBoundSQL(tokens []Token, stmt string, idx int) string {
+	forIdx := findKeyword(tokens, "FOR", idx)
+	if forIdx == -1 {
+		return ""
 	}
 
-	parentTable := db.GetTable(parentSchema, parentName)
+	valuesIdx := nextNonCommentIndex(tokens, forIdx+1)
+	if valuesIdx >= len(tokens) || upperLiteral(tokens, valuesIdx) != "VALUES" {
+		return ""
+	}
+
+	end := len(stmt)
+	if semicolonIdx := findToken(tokens, TokenSemicolon, forIdx); semicolonIdx != -1 {
+		end = tokens[semicolonIdx].Start
+	}
+
+	return strings.TrimSpace(stmt[tokens[forIdx].Start:end])
+}
+
+func addPartition(parent *schema.Table, partition schema.Partition) {
+	if parent.PartitionStrategy == nil {
+		parent.PartitionStrategy = &schema.PartitionStrategy{}
+	}
+
+	dropInheritedConstraints(parent, &partition)
+	parent.PartitionStrategy.Partitions = append(parent.PartitionStrategy.Partitions, partition)
+}
+
+// localPartitionObjects returns the constraints and standalone indexes of a
+// table that is becoming a partition. Indexes backing a constraint are left
+// out; they go with their constraint.
+func localPartitionObjects(child *schema.Table) ([]schema.Constraint, []schema.Index) {
+	constraints := slices.Clone(child.Constraints)
+	indexes := slices.DeleteFunc(slices.Clone(child.Indexes), func(idx schema.Index) bool {
+		return idx.IsPrimary || child.GetConstraint(idx.Name) != nil
+	})
+
+	return constraints, indexes
+}
+
+// dropInheritedConstraints removes the partition's copies of constraints the
+// parent declares under the same name. PostgreSQL propagates a parent's
+// constraints to every partition, so only the parent's declaration counts.
+func dropInheritedConstraints(parent *schema.Table, partition *schema.Partition) {
+	partition.Constraints = slices.DeleteFunc(
+		partition.Constraints,
+		func(constraint schema.Constraint) bool {
+			return parent.GetConstraint(constraint.Name) != nil
+		},
+	)
+}
+
+// parseAttachPartition handles ALTER TABLE parent ATTACH PARTITION child,
+// which pg_dump writes after creating each partition as a standalone table.
+// The child becomes a partition of the parent, as if it had been declared
+// with CREATE TABLE ... PARTITION OF.
+func (p *Parser) parseAttachPartition(alter *alterTableStatement, db *schema.Database) error {
+	tokens := alter.tokens
+
+	partitionIdx := nextNonCommentIndex(tokens, alter.actionIdx+1)
+
+	childLiteral, afterChildIdx := readQualifiedName(
+		tokens,
+		nextNonCommentIndex(tokens, partitionIdx+1),
+	)
+	if childLiteral == "" {
+		return errors.New("cannot extract partition table name")
+	}
+
+	_, partitionName := p.splitSchemaTable(childLiteral)
+	partitionDef := partitionBoundSQL(tokens, alter.stmt, afterChildIdx)
+
+	parentTable := db.GetTable(alter.schemaName, alter.tableName)
 	if parentTable == nil {
 		ctx := p.ensureContext()
 		ctx.deferred = append(ctx.deferred, deferredPartition{
-			parentSchema:  parentSchema,
-			parentName:    parentName,
-			partitionName: partitionName,
-			definition:    partitionDef,
+			parentSchema: alter.schemaName,
+			parentName:   alter.tableName,
+			partition: schema.Partition{
+				Name:       partitionName,
+				Definition: partitionDef,
+			},
 		})
 
 		return nil
 	}
 
-	if parentTable.PartitionStrategy == nil {
-		parentTable.PartitionStrategy = &schema.PartitionStrategy{}
+	partition := schema.Partition{
+		Name:       partitionName,
+		Definition: partitionDef,
 	}
 
-	parentTable.PartitionStrategy.Partitions = append(
-		parentTable.PartitionStrategy.Partitions,
-		schema.Partition{
-			Name:       partitionName,
-			Definition: partitionDef,
-		},
-	)
+	if child := db.GetTable(alter.schemaName, partitionName); child != nil {
+		partition.Constraints, partition.Indexes = localPartitionObjects(child)
+	}
+
+	removeTable(db, alter.schemaName, partitionName)
+
+	// Removing the child shifts the tables after it, so look the parent up again.
+	parentTable = db.GetTable(alter.schemaName, alter.tableName)
+	addPartition(parentTable, partition)
 
 	return nil
 }
 
+// removeTable drops the standalone definition of a table that turned out to
+// be a partition.
+func removeTable(db *schema.Database, schemaName, tableName string) {
+	db.Tables = slices.DeleteFunc(db.Tables, func(table schema.Table) bool {
+		return table.Schema == schemaName && table.Name == tableName
+	})
+}
+
+// isPartition reports whether the table is a partition of a parent seen so
+// far, including partitions still waiting for their parent.
+func (p *Parser) isPartition(db *schema.Database, schemaName, tableName string) bool {
+	_, partition := p.findPartition(db, schemaName, tableName)
+	return partition != nil
+}
+
+// findPartition returns the partition entry of the table and its parent. A
+// partition still waiting for its parent is returned with a nil parent.
+func (p *Parser) findPartition(
+	db *schema.Database,
+	schemaName, tableName string,
+) (*schema.Table, *schema.Partition) {
+	for i := range db.Tables {
+		parent := &db.Tables[i]
+		if parent.Schema != schemaName || parent.PartitionStrategy == nil {
+			continue
+		}
+
+		for j := range parent.PartitionStrategy.Partitions {
+			if parent.PartitionStrategy.Partitions[j].Name == tableName {
+				return parent, &parent.PartitionStrategy.Partitions[j]
+			}
+		}
+	}
+
+	deferred := p.deferred
+	if p.ctx != nil {
+		deferred = p.ctx.deferred
+	}
+
+	for i := range deferred {
+		if deferred[i].parentSchema == schemaName && deferred[i].partition.Name == tableName {
+			return nil, &deferred[i].partition
+		}
+	}
+
+	return nil, nil
+}
+
+// attachPartitionIndex handles ALTER INDEX parent ATTACH PARTITION child:
+// the child index, and the primary key or unique constraint it backs, become
+// the parent's copy on that partition and stop being local to it.
+func (p *Parser) attachPartitionIndex(db *schema.Database, schemaName, indexName string) {
+	detach := func(partition *schema.Partition) {
+		partition.Indexes = slices.DeleteFunc(partition.Indexes, func(idx schema.Index) bool {
+			return idx.Name == indexName
+		})
+		partition.Constraints = slices.DeleteFunc(
+			partition.Constraints,
+			func(constraint schema.Constraint) bool {
+				return constraint.Name == indexName
+			},
+		)
+	}
+
+	for i := range db.Tables {
+		parent := &db.Tables[i]
+		if parent.Schema != schemaName || parent.PartitionStrategy == nil {
+			continue
+		}
+
+		for j := range parent.PartitionStrategy.Partitions {
+			detach(&parent.PartitionStrategy.Partitions[j])
+		}
+	}
+
+	deferred := p.deferred
+	if p.ctx != nil {
+		deferred = p.ctx.deferred
+	}
+
+	for i := range deferred {
+		if deferred[i].parentSchema == schemaName {
+			detach(&deferred[i].partition)
+		}
+	}
+}
+
 func (p *Parser) parseDoBlock(stmt string, _ *schema.Database) error {
 	stmtUpper := strings.ToUpper(stmt)
 
 	if strings.Contains(stmtUpper, "CREATE TABLE") && strings.Contains(stmtUpper, "PARTITION") {
 		p.addWarning(
+			diag.CodeProceduralPartitioning,
 			0,
+			"",
 			"DO block contains partition creation logic. "+
 				"Consider using declarative PARTITION BY syntax with explicit CREATE TABLE ... PARTITION OF statements instead.",
 		)
@@ -71,6 +71,29 @@ func TestLexerDollarQuotedString(t *testing.T) {
 	require.Contains(t, literals, "$tag$nested$tag$")
 }
 
+func TestLexerDollarQuoteTags(t *testing.T) {
+	t.Parallel()
+
+	input := "SELECT $func$ a; $$ b $$; $body$ c $body$ $func$, $1, price$, a$b$c, $_1$x$_1$ $"
+
+	tokens, err := parser.NewLexer(input).Tokenize()
+	require.NoError(t, err)
+
+	literals := make([]string, 0, len(tokens))
+	for _, tok := range tokens[1 : len(tokens)-1] {
+		literals = append(literals, tok.Literal)
+	}
+
+	require.Equal(t, []string{
+		"$func$ a; $$ b $$; $body$ c $body$ $func$", ",",
+		"$", "1", ",",
+		"price$", ",",
+		"a$b$c", ",",
+		"$_1$x$_1$",
+		"$",
+	}, literals, "tags cannot start with a digit, and $ after a word is part of it")
+}
+
 func TestLexerComments(t *testing.T) {
 	t.Parallel()
 
@@ -139,3 +162,26 @@ func TestLexerErrors(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "unterminated comment")
 }
+
+func TestLexerKeywordCase(t *testing.T) {
+	t.Parallel()
+
+	long := "orders_customer_id_created_at_status_idx"
+
+	tokens, err := parser.NewLexer("create Table sElEcT " + long + " tablé").Tokenize()
+	require.NoError(t, err)
+
+	types := make([]parser.TokenType, 0, len(tokens))
+	for _, tok := range tokens[:len(tokens)-1] {
+		types = append(types, tok.Type)
+	}
+
+	require.Equal(t, []parser.TokenType{
+		parser.TokenKeyword,
+		parser.TokenKeyword,
+		parser.TokenKeyword,
+		parser.TokenIdentifier,
+		parser.TokenIdentifier,
+	}, types)
+	require.Equal(t, long, tokens[3].Literal)
+}
@@ -0,0 +1,211 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseAlterTableAddConstraint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		alter string
+	}{
+		{name: "plain", alter: "ALTER TABLE public.orders"},
+		{name: "only", alter: "ALTER TABLE ONLY public.orders"},
+		{name: "if exists only", alter: "ALTER TABLE IF EXISTS ONLY orders"},
+		{name: "quoted", alter: `ALTER TABLE ONLY "public"."orders"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQL(t, `
+CREATE TABLE users (id BIGINT NOT NULL);
+CREATE TABLE orders (id BIGINT NOT NULL, user_id BIGINT NOT NULL);
+`+tt.alter+`
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+`+tt.alter+`
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);
+`)
+
+			orders := db.GetTable(schema.DefaultSchema, "orders")
+			require.NotNil(t, orders)
+
+			pkey := orders.GetConstraint("orders_pkey")
+			require.NotNil(t, pkey)
+			assert.Equal(t, schema.ConstraintPrimaryKey, pkey.Type)
+			assert.Equal(t, []string{"id"}, pkey.Columns)
+			require.NotNil(t, orders.GetIndex("orders_pkey"), "primary key index is added")
+
+			fkey := orders.GetConstraint("orders_user_id_fkey")
+			require.NotNil(t, fkey)
+			assert.Equal(t, schema.DefaultSchema, fkey.ReferencedSchema)
+			assert.Equal(t, "users", fkey.ReferencedTable)
+		})
+	}
+}
+
+func TestParseAttachPartition(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`
+CREATE TABLE public.events_2024 (id bigint NOT NULL, day date NOT NULL);
+ALTER TABLE ONLY public.events_2024 ADD CONSTRAINT events_2024_pkey PRIMARY KEY (id, day);
+CREATE INDEX events_2024_day_idx ON public.events_2024 USING btree (day);
+
+CREATE TABLE public.events (id bigint NOT NULL, day date NOT NULL) PARTITION BY RANGE (day);
+ALTER TABLE ONLY public.events ATTACH PARTITION public.events_2024
+    FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+CREATE INDEX idx_events_day ON ONLY public.events USING btree (day);
+CREATE INDEX events_2024_day_idx2 ON public.events_2024 USING btree (day);
+ALTER INDEX public.idx_events_day ATTACH PARTITION public.events_2024_day_idx2;
+`, db))
+
+	assert.Empty(t, p.GetErrors())
+	assert.Empty(t, p.GetWarnings())
+
+	require.Len(t, db.Tables, 1)
+
+	events := &db.Tables[0]
+	require.NotNil(t, events.PartitionStrategy)
+	require.Len(t, events.PartitionStrategy.Partitions, 1)
+
+	partition := events.PartitionStrategy.Partitions[0]
+	assert.Equal(t, "events_2024", partition.Name)
+	assert.Equal(t, "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')", partition.Definition)
+
+	// The parent has no primary key and never attaches events_2024_day_idx,
+	// so both stay local to the partition; the attached index does not.
+	require.Len(t, partition.Constraints, 1)
+	assert.Equal(t, "events_2024_pkey", partition.Constraints[0].Name)
+	require.Len(t, partition.Indexes, 1)
+	assert.Equal(t, "events_2024_day_idx", partition.Indexes[0].Name)
+
+	require.Len(t, events.Indexes, 1)
+	assert.Equal(t, "idx_events_day", events.Indexes[0].Name)
+	assert.True(t, events.Indexes[0].OnlyParent)
+}
+
+func TestParseAttachPartitionBeforeParent(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`
+CREATE TABLE events_2024 (id BIGINT NOT NULL, day DATE NOT NULL);
+ALTER TABLE events ATTACH PARTITION events_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+CREATE TABLE events (id BIGINT NOT NULL, day DATE NOT NULL) PARTITION BY RANGE (day);
+`, db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+
+	require.Len(t, db.Tables, 1)
+	require.NotNil(t, db.Tables[0].PartitionStrategy)
+	require.Len(t, db.Tables[0].PartitionStrategy.Partitions, 1)
+	assert.Equal(t, "events_2024", db.Tables[0].PartitionStrategy.Partitions[0].Name)
+}
+
+func TestParsePartitionLocalConstraints(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`
+CREATE TABLE events_2024 (
+    id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    CONSTRAINT events_day_check CHECK (day > '2000-01-01'),
+    CONSTRAINT events_2024_id_check CHECK (id > 0)
+);
+ALTER TABLE events ATTACH PARTITION events_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+ALTER TABLE ONLY events_2024 ADD CONSTRAINT events_2024_pkey PRIMARY KEY (id, day);
+CREATE TABLE events (
+    id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    CONSTRAINT events_day_check CHECK (day > '2000-01-01')
+) PARTITION BY RANGE (day);
+ALTER TABLE ONLY events ADD CONSTRAINT events_pkey PRIMARY KEY (id, day);
+ALTER INDEX events_pkey ATTACH PARTITION events_2024_pkey;
+`, db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+	require.Empty(t, p.GetErrors())
+
+	require.Len(t, db.Tables, 1)
+	require.NotNil(t, db.Tables[0].PartitionStrategy)
+	require.Len(t, db.Tables[0].PartitionStrategy.Partitions, 1)
+
+	partition := db.Tables[0].PartitionStrategy.Partitions[0]
+	require.Len(t, partition.Constraints, 1,
+		"the inherited check and the attached primary key are the parent's")
+	assert.Equal(t, "events_2024_id_check", partition.Constraints[0].Name)
+	assert.Empty(t, partition.Indexes)
+}
+
+func TestParseColumnStorageAndCompression(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`
+ALTER TABLE ONLY public.events ALTER COLUMN body SET STORAGE EXTERNAL;
+CREATE TABLE events (
+    id BIGINT NOT NULL,
+    payload JSONB COMPRESSION lz4 NOT NULL,
+    body TEXT,
+    raw BYTEA
+);
+ALTER TABLE events ALTER raw SET STORAGE MAIN;
+ALTER TABLE events ALTER COLUMN raw SET COMPRESSION pglz;
+ALTER TABLE events ALTER COLUMN payload SET COMPRESSION DEFAULT;
+ALTER TABLE events ALTER COLUMN missing SET STORAGE PLAIN;
+`, db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+	require.Empty(t, p.GetErrors())
+
+	events := db.GetTable(schema.DefaultSchema, "events")
+	require.NotNil(t, events)
+
+	payload := events.GetColumn("payload")
+	require.NotNil(t, payload)
+	assert.Equal(t, "JSONB", payload.DataType)
+	assert.False(t, payload.IsNullable)
+	assert.Empty(t, payload.Compression, "SET COMPRESSION DEFAULT resets the inline method")
+
+	assert.Equal(t, schema.StorageExternal, events.GetColumn("body").Storage,
+		"the statement before the table is applied once the table is parsed")
+	assert.Equal(t, schema.StorageMain, events.GetColumn("raw").Storage)
+	assert.Equal(t, schema.CompressionPGLZ, events.GetColumn("raw").Compression)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Message, "column missing not found")
+}
+
+func TestParseColumnStorageRejectsUnknownValues(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`
+CREATE TABLE events (payload BYTEA);
+ALTER TABLE events ALTER COLUMN payload SET COMPRESSION zstd;
+`, db))
+
+	errs := p.GetErrors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), `invalid compression "zstd" for column payload`)
+}
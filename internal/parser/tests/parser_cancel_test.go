@@ -0,0 +1,80 @@
+package parser_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const cancelSQL = `
+CREATE TABLE t1 (id BIGINT);
+CREATE TABLE t2 (id BIGINT);
+CREATE TABLE t3 (id BIGINT);
+CREATE TABLE t4 (id BIGINT);
+CREATE TABLE t5 (id BIGINT);
+`
+
+func TestParseSQLContext_CancelledMidRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := parser.New(parser.WithProgress(func(_ string, done, _ int) {
+		if done == 2 {
+			cancel()
+		}
+	}))
+	db := &schema.Database{}
+
+	err := p.ParseSQLContext(ctx, cancelSQL, db)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseSQLContext() error = %v, want context.Canceled", err)
+	}
+
+	if !strings.Contains(err.Error(), "parse cancelled in inline SQL after 2 of 5 statements") {
+		t.Errorf("error = %q, want statement progress", err.Error())
+	}
+
+	if len(db.Tables) != 2 {
+		t.Errorf("parsed %d tables before cancellation, want 2", len(db.Tables))
+	}
+
+	if err := p.ParseSQL(cancelSQL, &schema.Database{}); err != nil {
+		t.Errorf("ParseSQL() after cancelled run error = %v", err)
+	}
+}
+
+func TestParseDirectoryContext_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tablesDir := filepath.Join(dir, "tables")
+
+	if err := os.MkdirAll(tablesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(tablesDir, "tables.sql")
+	if err := os.WriteFile(file, []byte(cancelSQL), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := parser.New().ParseDirectoryContext(ctx, dir)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseDirectoryContext() error = %v, want context.Canceled", err)
+	}
+
+	if !strings.Contains(err.Error(), "parse cancelled in "+file+" after 0 of 5 statements") {
+		t.Errorf("error = %q, want file and statement progress", err.Error())
+	}
+}
@@ -1,9 +1,11 @@
 package parser_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/accented-ai/pgtofu/internal/differ"
+	"github.com/accented-ai/pgtofu/internal/parser"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
@@ -177,3 +179,78 @@ END;
 		}
 	}
 }
+
+func TestCommentOnEnumType(t *testing.T) {
+	t.Parallel()
+
+	sql := `
+		CREATE TYPE app.order_status AS ENUM ('pending', 'paid', 'shipped');
+
+		COMMENT ON TYPE app.order_status IS
+		'Lifecycle of an order. '
+		'shipped means handed to the carrier.';
+	`
+
+	parsed := parseSQL(t, sql)
+
+	if len(parsed.CustomTypes) != 1 {
+		t.Fatalf("expected 1 custom type, got %d", len(parsed.CustomTypes))
+	}
+
+	want := "Lifecycle of an order. shipped means handed to the carrier."
+	if got := parsed.CustomTypes[0].Comment; got != want {
+		t.Errorf("type comment = %q, want %q", got, want)
+	}
+
+	d := differ.New(differ.DefaultOptions())
+
+	result, err := d.Compare(parsed, parseSQL(t, sql))
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if len(result.Changes) != 0 {
+		t.Errorf("expected no changes for identical type comments, got %d", len(result.Changes))
+	}
+}
+
+func TestCommentOnConstraint(t *testing.T) {
+	t.Parallel()
+
+	sql := `
+		CREATE TABLE app.orders (
+			id BIGINT PRIMARY KEY,
+			total NUMERIC,
+			CONSTRAINT orders_total_check CHECK (total >= 0)
+		);
+
+		COMMENT ON CONSTRAINT orders_total_check ON app.orders IS 'Totals can''t be negative';
+		COMMENT ON CONSTRAINT "orders_pkey" ON app.orders IS 'Surrogate key';
+		COMMENT ON CONSTRAINT missing_check ON app.orders IS 'Nothing to attach to';
+	`
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	if err := p.ParseSQL(sql, db); err != nil {
+		t.Fatalf("ParseSQL failed: %v", err)
+	}
+
+	table := db.GetTable("app", "orders")
+	if table == nil {
+		t.Fatal("table app.orders not found")
+	}
+
+	if got := table.GetConstraint("orders_total_check").Comment; got != "Totals can't be negative" {
+		t.Errorf("check constraint comment = %q", got)
+	}
+
+	if pk := table.GetPrimaryKey(); pk == nil || pk.Comment != "Surrogate key" {
+		t.Errorf("primary key comment not applied: %+v", pk)
+	}
+
+	warnings := p.GetWarnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "missing_check") {
+		t.Errorf("expected one warning for the unknown constraint, got %v", warnings)
+	}
+}
@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseKeyConstraintInclude(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		constraint     string
+		constraintType string
+		columns        []string
+		include        []string
+		definition     string
+		deferrable     bool
+		deferred       bool
+	}{
+		{
+			name:           "primary key",
+			constraint:     "CONSTRAINT lookups_pkey PRIMARY KEY (id) INCLUDE (payload)",
+			constraintType: schema.ConstraintPrimaryKey,
+			columns:        []string{"id"},
+			include:        []string{"payload"},
+			definition:     "PRIMARY KEY (id) INCLUDE (payload)",
+		},
+		{
+			name: "unique with several include columns",
+			constraint: "CONSTRAINT lookups_key UNIQUE (tenant_id, key) " +
+				"INCLUDE (payload, \"updatedAt\")",
+			constraintType: schema.ConstraintUnique,
+			columns:        []string{"tenant_id", "key"},
+			include:        []string{"payload", "updatedAt"},
+			definition:     `UNIQUE (tenant_id, key) INCLUDE (payload, "updatedAt")`,
+		},
+		{
+			name: "deferrable after include",
+			constraint: "CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload) " +
+				"DEFERRABLE INITIALLY DEFERRED",
+			constraintType: schema.ConstraintUnique,
+			columns:        []string{"key"},
+			include:        []string{"payload"},
+			definition:     "UNIQUE (key) INCLUDE (payload)",
+			deferrable:     true,
+			deferred:       true,
+		},
+		{
+			name:           "primary key without include",
+			constraint:     "CONSTRAINT lookups_pkey PRIMARY KEY (id) DEFERRABLE",
+			constraintType: schema.ConstraintPrimaryKey,
+			columns:        []string{"id"},
+			definition:     "PRIMARY KEY (id)",
+			deferrable:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQL(t, `CREATE TABLE lookups (
+    id BIGINT NOT NULL,
+    tenant_id BIGINT NOT NULL,
+    key TEXT NOT NULL,
+    payload JSONB,
+    "updatedAt" TIMESTAMPTZ,
+    `+tt.constraint+`
+);`)
+			table := requireSingleTable(t, db)
+
+			require.Len(t, table.Constraints, 1)
+			constraint := table.Constraints[0]
+			assert.Equal(t, tt.constraintType, constraint.Type)
+			assert.Equal(t, tt.columns, constraint.Columns)
+			assert.Equal(t, tt.include, constraint.IncludeColumns)
+			assert.Equal(t, tt.definition, constraint.Definition)
+			assert.Equal(t, tt.deferrable, constraint.IsDeferrable)
+			assert.Equal(t, tt.deferred, constraint.InitiallyDeferred)
+
+			index := table.GetIndex(constraint.Name)
+			require.NotNil(t, index)
+			assert.Equal(t, tt.columns, index.Columns)
+			assert.Equal(t, tt.include, index.IncludeColumns)
+		})
+	}
+}
+
+func TestParseAlterTableAddConstraintInclude(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE lookups (id BIGINT NOT NULL, key TEXT NOT NULL, payload JSONB);
+ALTER TABLE lookups ADD CONSTRAINT lookups_key UNIQUE (key) INCLUDE (payload, id);
+`)
+	table := requireSingleTable(t, db)
+
+	constraint := table.GetConstraint("lookups_key")
+	require.NotNil(t, constraint)
+	assert.Equal(t, []string{"key"}, constraint.Columns)
+	assert.Equal(t, []string{"payload", "id"}, constraint.IncludeColumns)
+
+	index := table.GetIndex("lookups_key")
+	require.NotNil(t, index)
+	assert.Equal(t, []string{"payload", "id"}, index.IncludeColumns)
+}
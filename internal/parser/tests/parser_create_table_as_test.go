@@ -0,0 +1,59 @@
+package parser_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseRejectsTablesCreatedFromQueries(t *testing.T) {
+	t.Parallel()
+
+	sql := `CREATE TABLE reporting.users (
+    id BIGINT PRIMARY KEY,
+    label TEXT DEFAULT 'AS',
+    total INT GENERATED ALWAYS AS (id * 2) STORED
+);
+
+CREATE TABLE reporting.snapshot AS
+SELECT id, (label) AS name FROM reporting.users;
+
+SELECT id, label INTO TEMP reporting.copy FROM reporting.users WHERE id IN (SELECT 1);
+
+CREATE TABLE reporting.orders (id BIGINT PRIMARY KEY);
+`
+
+	path := filepath.Join(t.TempDir(), "reporting.sql")
+	require.NoError(t, os.WriteFile(path, []byte(sql), 0o600))
+
+	p := parser.New()
+	db := &schema.Database{}
+	require.NoError(t, p.ParseFileWithoutProcessingDeferred(path, db))
+
+	parseErrors := p.GetErrors()
+	require.Len(t, parseErrors, 2)
+
+	assert.Equal(t, 7, parseErrors[0].Line)
+	assert.True(t, errors.Is(parseErrors[0], parser.ErrCreateTableAs))
+	assert.Contains(t, parseErrors[0].Error(), "(table reporting.snapshot): declare the table "+
+		"with explicit columns in CREATE TABLE and populate it with a seed or backfill migration")
+
+	assert.Equal(t, 10, parseErrors[1].Line)
+	assert.True(t, errors.Is(parseErrors[1], parser.ErrSelectInto))
+	assert.Contains(t, parseErrors[1].Error(), "(table reporting.copy)")
+
+	names := make([]string, 0, len(db.Tables))
+	for _, table := range db.Tables {
+		names = append(names, table.QualifiedName())
+	}
+
+	assert.Equal(t, []string{"reporting.users", "reporting.orders"}, names)
+	assert.Len(t, db.Tables[0].Columns, 3)
+}
@@ -0,0 +1,136 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseDuplicateTableDefinitions(t *testing.T) {
+	t.Parallel()
+
+	first := `CREATE TABLE users (id BIGINT PRIMARY KEY, email TEXT NOT NULL);`
+
+	tests := []struct {
+		name        string
+		second      string
+		wantErr     string
+		wantWarning string
+		wantColumns string
+		wantSource  string
+	}{
+		{
+			name:   "identical definition is a warning",
+			second: first,
+			wantWarning: "table public.users is defined again with an identical definition " +
+				"(first defined at {dir}/a.sql:1)",
+			wantColumns: "id, email",
+			wantSource:  "a.sql",
+		},
+		{
+			name:   "conflicting definition is an error",
+			second: "\n\nCREATE TABLE users (id BIGINT PRIMARY KEY, name TEXT);",
+			wantErr: "{dir}/b.sql:3: table public.users is already defined at {dir}/a.sql:1 " +
+				"with a different definition:\n    id, email -> id, name",
+			wantColumns: "id, name",
+			wantSource:  "b.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			firstPath := filepath.Join(dir, "a.sql")
+			secondPath := filepath.Join(dir, "b.sql")
+
+			require.NoError(t, os.WriteFile(firstPath, []byte(first), 0o600))
+			require.NoError(t, os.WriteFile(secondPath, []byte(tt.second), 0o600))
+
+			p := parser.New(parser.WithTableConflictDescriber(
+				func(a, b *schema.Table, _, _ parser.SourceLocation) []string {
+					return []string{columnList(a) + " -> " + columnList(b)}
+				},
+			))
+			db := &schema.Database{}
+
+			require.NoError(t, p.ParseFileWithoutProcessingDeferred(firstPath, db))
+			require.NoError(t, p.ParseFileWithoutProcessingDeferred(secondPath, db))
+
+			require.Len(t, db.Tables, 1)
+			assert.Equal(t, tt.wantColumns, columnList(&db.Tables[0]))
+
+			if tt.wantErr == "" {
+				assert.Empty(t, p.GetErrors())
+			} else {
+				require.Len(t, p.GetErrors(), 1)
+				assert.Equal(
+					t,
+					strings.ReplaceAll(tt.wantErr, "{dir}", dir),
+					p.GetErrors()[0].Error(),
+				)
+			}
+
+			if tt.wantWarning == "" {
+				assert.Empty(t, p.GetWarnings())
+			} else {
+				require.Len(t, p.GetWarnings(), 1)
+				assert.Equal(
+					t,
+					strings.ReplaceAll(tt.wantWarning, "{dir}", dir),
+					p.GetWarnings()[0].Message,
+				)
+			}
+
+			location, ok := p.SourceOf(schema.DefaultSchema, "users")
+			require.True(t, ok)
+			assert.Equal(t, filepath.Join(dir, tt.wantSource), location.File)
+		})
+	}
+}
+
+func TestParseTablesDifferingOnlyInCase(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	quotedPath := filepath.Join(dir, "a.sql")
+	foldedPath := filepath.Join(dir, "b.sql")
+
+	require.NoError(t, os.WriteFile(quotedPath,
+		[]byte(`CREATE TABLE "Users" (id BIGINT PRIMARY KEY);`), 0o600))
+	require.NoError(t, os.WriteFile(foldedPath,
+		[]byte(`CREATE TABLE users (id BIGINT PRIMARY KEY, email TEXT);`), 0o600))
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseFileWithoutProcessingDeferred(quotedPath, db))
+	require.NoError(t, p.ParseFileWithoutProcessingDeferred(foldedPath, db))
+
+	assert.Empty(t, p.GetErrors())
+	assert.Empty(t, p.GetWarnings())
+	assert.Len(t, db.Tables, 2)
+
+	for name, want := range map[string]string{"Users": quotedPath, "users": foldedPath} {
+		location, ok := p.SourceOf(schema.DefaultSchema, name)
+		require.True(t, ok, name)
+		assert.Equal(t, want, location.File, name)
+	}
+}
+
+func columnList(table *schema.Table) string {
+	names := make([]string, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		names = append(names, col.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
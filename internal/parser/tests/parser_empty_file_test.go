@@ -0,0 +1,49 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParser_FilesWithoutStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "whitespace", content: "\n\t  \r\n"},
+		{name: "line comments", content: "-- retired schema\n-- nothing left\n"},
+		{name: "block comment", content: "/* retired\n   schema */\n"},
+		{name: "semicolons", content: ";\n;; -- empty\n"},
+		{name: "byte order mark", content: "\ufeff-- only a comment\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "empty.sql")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			p := parser.New()
+			db := &schema.Database{}
+			require.NoError(t, p.ParseSQL("CREATE TABLE users (id BIGINT);", db))
+			require.NoError(t, p.ParseFile(path, db))
+
+			assert.Empty(t, p.GetErrors())
+			assert.Empty(t, p.GetWarnings())
+			require.Len(t, db.Tables, 1)
+			assert.Equal(t, "users", db.Tables[0].Name)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseExcludeConstraintParts(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE bookings (
+    room INTEGER NOT NULL,
+    during TSTZRANGE NOT NULL,
+    active BOOLEAN,
+    CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        room WITH =,
+        during WITH OPERATOR(pg_catalog.&&)
+    ) INCLUDE (active) WITH (fillfactor = 90) WHERE (active) DEFERRABLE INITIALLY DEFERRED
+);`)
+	table := requireSingleTable(t, db)
+
+	constraint := table.GetConstraint("bookings_no_overlap")
+	require.NotNil(t, constraint)
+	assert.Equal(t, schema.ConstraintExclude, constraint.Type)
+	assert.Equal(t, "gist", constraint.ExcludeMethod)
+	assert.Equal(t, []schema.ExcludeElement{
+		{Element: "room", Operator: "="},
+		{Element: "during", Operator: "&&"},
+	}, constraint.ExcludeElements)
+	assert.Equal(t, "active", constraint.ExcludeWhere)
+	assert.Equal(t, []string{"active"}, constraint.IncludeColumns)
+	assert.True(t, constraint.IsDeferrable)
+	assert.True(t, constraint.InitiallyDeferred)
+	assert.Equal(t, "EXCLUDE USING gist (room WITH =, during WITH &&) INCLUDE (active) "+
+		"WHERE (active)", constraint.Definition)
+}
+
+func TestParseExcludeConstraintElements(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE slots (
+    room INTEGER,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    EXCLUDE (room WITH =),
+    EXCLUDE USING gist (
+        room gist_int4_ops WITH =,
+        tstzrange(starts_at, ends_at) WITH &&,
+        (lower(tstzrange(starts_at, ends_at))) WITH =,
+        (starts_at + interval '1 day') WITH =
+    )
+);`)
+	table := requireSingleTable(t, db)
+	require.Len(t, table.Constraints, 2)
+
+	plain := table.Constraints[0]
+	assert.Equal(t, "slots_room_excl", plain.Name)
+	assert.Equal(t, "btree", plain.ExcludeMethod, "btree is the default method")
+	assert.Equal(t, []schema.ExcludeElement{{Element: "room", Operator: "="}},
+		plain.ExcludeElements)
+
+	ranged := table.Constraints[1]
+	assert.Equal(t, "slots_room_tstzrange_lower_expr_excl", ranged.Name,
+		"expressions are named after the function they call, or expr")
+	assert.Equal(t, []schema.ExcludeElement{
+		{Element: "room gist_int4_ops", Operator: "="},
+		{Element: "tstzrange(starts_at, ends_at)", Operator: "&&"},
+		{Element: "(lower(tstzrange(starts_at, ends_at)))", Operator: "="},
+		{Element: "(starts_at + interval '1 day')", Operator: "="},
+	}, ranged.ExcludeElements)
+}
@@ -55,7 +55,7 @@ func TestParseCreateExtension(t *testing.T) {
 			name:          "extension with equals schema clause",
 			sql:           `CREATE EXTENSION pg_trgm WITH SCHEMA = "Extensions";`,
 			wantExtension: "pg_trgm",
-			wantSchema:    "extensions",
+			wantSchema:    "Extensions",
 		},
 		{
 			name:          "extension with version",
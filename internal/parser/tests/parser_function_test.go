@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -132,3 +133,61 @@ func TestParseTrigger_UpdateOfColumns(t *testing.T) {
 		t.Errorf("update columns = %q, want \"status,shipped_at\"", got)
 	}
 }
+
+func TestParseFunctionWithCustomDollarQuoteTags(t *testing.T) {
+	t.Parallel()
+
+	body := `DECLARE
+    ddl text := 'CREATE TABLE audit_' || suffix || ' (id BIGINT);';
+BEGIN
+    EXECUTE ddl;
+    EXECUTE $$SELECT 1; SELECT 2$$;
+    PERFORM $body$ nested $$ quote; $body$;
+END;`
+
+	db := parseSQL(t, `CREATE FUNCTION make_audit(suffix TEXT, note TEXT DEFAULT $$n/a$$)
+RETURNS void AS $func$
+`+body+`
+$func$ LANGUAGE plpgsql;
+
+CREATE TABLE after_function (id BIGINT);`)
+
+	if len(db.Functions) != 1 || len(db.Tables) != 1 {
+		t.Fatalf("expected 1 function and 1 table, got %d and %d",
+			len(db.Functions), len(db.Tables))
+	}
+
+	if got := db.Functions[0].Body; got != body {
+		t.Errorf("function body = %q, want %q", got, body)
+	}
+
+	if table := db.Tables[0]; table.Name != "after_function" || len(table.Columns) != 1 {
+		t.Errorf("unexpected table after the function: %s with %d columns",
+			table.Name, len(table.Columns))
+	}
+}
+
+func TestParseFunctionUnnamedMultiWordArgumentTypes(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE FUNCTION f(character varying, double precision,
+    timestamp with time zone, label character varying(20))
+RETURNS integer AS $$ SELECT 1 $$ LANGUAGE sql;`)
+
+	if len(db.Functions) != 1 {
+		t.Fatalf("expected 1 function, got %d", len(db.Functions))
+	}
+
+	fn := db.Functions[0]
+
+	wantTypes := []string{
+		"character varying", "double precision", "timestamp with time zone", "character varying(20)",
+	}
+	if !slices.Equal(fn.ArgumentTypes, wantTypes) {
+		t.Errorf("argument types = %q, want %q", fn.ArgumentTypes, wantTypes)
+	}
+
+	if wantNames := []string{"", "", "", "label"}; !slices.Equal(fn.ArgumentNames, wantNames) {
+		t.Errorf("argument names = %q, want %q", fn.ArgumentNames, wantNames)
+	}
+}
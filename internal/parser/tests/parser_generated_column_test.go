@@ -0,0 +1,40 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGeneratedColumns(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE order_lines (
+    price NUMERIC NOT NULL,
+    qty INTEGER,
+    total NUMERIC GENERATED ALWAYS AS (round(price * coalesce(qty, 1), 2)) STORED,
+    has_qty BOOLEAN GENERATED ALWAYS AS (qty IS NOT NULL) STORED NOT NULL,
+    id BIGINT GENERATED ALWAYS AS IDENTITY
+);`)
+	table := requireSingleTable(t, db)
+	require.Len(t, table.Columns, 5)
+
+	total := table.GetColumn("total")
+	require.NotNil(t, total)
+	assert.True(t, total.IsGenerated)
+	assert.Equal(t, "round(price * coalesce(qty, 1), 2)", total.GenerationExpression)
+	assert.True(t, total.IsNullable)
+	assert.Empty(t, total.Default)
+
+	hasQty := table.GetColumn("has_qty")
+	require.NotNil(t, hasQty)
+	assert.True(t, hasQty.IsGenerated)
+	assert.Equal(t, "qty IS NOT NULL", hasQty.GenerationExpression)
+	assert.False(t, hasQty.IsNullable, "NOT NULL after the expression applies to the column")
+
+	id := table.GetColumn("id")
+	require.NotNil(t, id)
+	assert.False(t, id.IsGenerated, "identity columns are not generated columns")
+	assert.Empty(t, id.GenerationExpression)
+}
@@ -0,0 +1,161 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const grantUsersTable = `CREATE TABLE users (id bigint PRIMARY KEY, email text, password text);
+`
+
+func TestParseGrant(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, grantUsersTable+`
+CREATE VIEW active_users AS SELECT id, email FROM users;
+
+GRANT SELECT, insert ON users TO app, "Reporting";
+GRANT ALL PRIVILEGES ON TABLE public.active_users TO admin WITH GRANT OPTION;
+GRANT SELECT (id, email), UPDATE (email) ON users TO PUBLIC;
+`)
+
+	app := db.GetGrant("public", "users", "", "app", false)
+	require.NotNil(t, app)
+	assert.Equal(t, schema.GrantObjectTable, app.ObjectType)
+	assert.Equal(t, []string{"INSERT", "SELECT"}, app.Privileges)
+	assert.False(t, app.WithGrantOption)
+
+	reporting := db.GetGrant("public", "users", "", "Reporting", false)
+	require.NotNil(t, reporting)
+	assert.Equal(t, "Reporting", reporting.GranteeName())
+
+	admin := db.GetGrant("public", "active_users", "", "admin", true)
+	require.NotNil(t, admin)
+	assert.Equal(t, schema.TablePrivileges, admin.Privileges)
+
+	email := db.GetGrant("public", "users", "email", schema.GrantRolePublic, false)
+	require.NotNil(t, email)
+	assert.Equal(t, schema.GrantObjectColumn, email.ObjectType)
+	assert.Equal(t, []string{"SELECT", "UPDATE"}, email.Privileges)
+
+	id := db.GetGrant("public", "users", "id", schema.GrantRolePublic, false)
+	require.NotNil(t, id)
+	assert.Equal(t, []string{"SELECT"}, id.Privileges)
+
+	assert.Len(t, db.Grants, 5)
+}
+
+func TestParseRevokeTakesAwayEarlierGrants(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, grantUsersTable+`
+GRANT ALL ON users TO app;
+GRANT SELECT (password) ON users TO app;
+REVOKE DELETE, TRUNCATE ON users FROM app;
+REVOKE SELECT ON users FROM app;
+
+REVOKE ALL ON users FROM PUBLIC;
+`)
+
+	app := db.GetGrant("public", "users", "", "app", false)
+	require.NotNil(t, app)
+	assert.Equal(t, []string{"INSERT", "REFERENCES", "TRIGGER", "UPDATE"}, app.Privileges)
+	assert.Nil(t, db.GetGrant("public", "users", "password", "app", false),
+		"revoking on the table revokes on its columns")
+
+	public := db.GetGrant("public", "users", "", schema.GrantRolePublic, false)
+	require.NotNil(t, public, "a revoke declares that the grantee holds nothing")
+	assert.Empty(t, public.Privileges)
+}
+
+func TestParseGrantOption(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, grantUsersTable+`
+GRANT SELECT, UPDATE ON users TO app;
+GRANT SELECT ON users TO app WITH GRANT OPTION;
+GRANT SELECT ON users TO app;
+
+GRANT INSERT, DELETE ON users TO admin WITH GRANT OPTION GRANTED BY postgres;
+REVOKE GRANT OPTION FOR DELETE ON users FROM admin CASCADE;
+`)
+
+	app := db.GetGrant("public", "users", "", "app", false)
+	require.NotNil(t, app)
+	assert.Equal(t, []string{"UPDATE"}, app.Privileges)
+
+	appOption := db.GetGrant("public", "users", "", "app", true)
+	require.NotNil(t, appOption, "granting without the option keeps it")
+	assert.Equal(t, []string{"SELECT"}, appOption.Privileges)
+
+	admin := db.GetGrant("public", "users", "", "admin", false)
+	require.NotNil(t, admin)
+	assert.Equal(t, []string{"DELETE"}, admin.Privileges)
+
+	adminOption := db.GetGrant("public", "users", "", "admin", true)
+	require.NotNil(t, adminOption)
+	assert.Equal(t, []string{"INSERT"}, adminOption.Privileges)
+}
+
+func TestParseGrantSkipsUnsupportedForms(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(grantUsersTable+`
+GRANT USAGE ON SCHEMA public TO app;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO app;
+GRANT EXECUTE ON FUNCTION now() TO app;
+GRANT admin TO app;
+GRANT SELECT ON users TO CURRENT_USER;
+GRANT SELECT ON missing TO app;
+`, db))
+
+	assert.Empty(t, db.Grants)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 6)
+
+	for _, warning := range warnings[:5] {
+		assert.Equal(t, diag.CodeSkippedStatement, warning.Code)
+	}
+
+	assert.Equal(t, diag.CodeObjectNotFound, warnings[5].Code)
+	assert.Equal(t, "public.missing", warnings[5].ObjectName)
+}
+
+func TestParseGrantRejectsInvalidPrivileges(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(grantUsersTable+`GRANT DELETE (email) ON users TO app;`, db))
+	require.Len(t, p.GetErrors(), 1)
+	assert.Contains(t, p.GetErrors()[0].Message, "invalid privilege DELETE")
+	assert.Empty(t, db.Grants)
+}
+
+func TestParsePgDumpKeepsTableGrants(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New(parser.WithPgDump())
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(pgDumpNoise, db))
+
+	reader := db.GetGrant("public", "users", "", "reader", false)
+	require.NotNil(t, reader)
+	assert.Equal(t, []string{"SELECT"}, reader.Privileges)
+
+	public := db.GetGrant("public", "users", "", schema.GrantRolePublic, false)
+	require.NotNil(t, public)
+	assert.Empty(t, public.Privileges)
+}
@@ -0,0 +1,72 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseIdentityColumns(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE orders (
+    id bigint GENERATED ALWAYS AS IDENTITY (START WITH 100) PRIMARY KEY,
+    seq integer GENERATED BY DEFAULT AS IDENTITY (INCREMENT BY -1 MAXVALUE 1000 CACHE 20),
+    note text
+);`)
+	table := requireSingleTable(t, db)
+	require.Len(t, table.Columns, 3)
+
+	id := table.GetColumn("id")
+	require.NotNil(t, id)
+	assert.Equal(t, "BIGINT", id.DataType, "the options are not part of the type")
+	assert.False(t, id.IsNullable)
+	assert.Empty(t, id.Default)
+	assert.Equal(t, &schema.Identity{
+		Generation: schema.IdentityAlways,
+		StartValue: 100,
+		MinValue:   1,
+		MaxValue:   9223372036854775807,
+		Increment:  1,
+		CacheSize:  1,
+	}, id.Identity)
+	require.Len(t, table.Constraints, 1)
+	assert.Equal(t, schema.ConstraintPrimaryKey, table.Constraints[0].Type)
+
+	seq := table.GetColumn("seq")
+	require.NotNil(t, seq)
+	assert.Equal(t, "INTEGER", seq.DataType)
+	assert.Empty(t, seq.Default, "BY DEFAULT is not a column default")
+	assert.Equal(t, &schema.Identity{
+		Generation: schema.IdentityByDefault,
+		StartValue: 1000,
+		MinValue:   -2147483648,
+		MaxValue:   1000,
+		Increment:  -1,
+		CacheSize:  20,
+	}, seq.Identity)
+
+	assert.Nil(t, table.GetColumn("note").Identity)
+}
+
+func TestParseIdentityColumnWithUnknownOptionIsSkipped(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(
+		`CREATE TABLE orders (id bigint GENERATED ALWAYS AS IDENTITY (STEP 2), note text);`, db))
+
+	table := requireSingleTable(t, db)
+	assert.Nil(t, table.GetColumn("id"))
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, diag.CodeSkippedDefinition, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "unsupported sequence option STEP")
+}
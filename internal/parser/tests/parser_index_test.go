@@ -603,3 +603,40 @@ func TestParseSchemaQualifiedIndex(t *testing.T) { //nolint:gocognit
 		})
 	}
 }
+
+func TestParseUnnamedIndex(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    email TEXT,
+    tenant_id BIGINT,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX ON users (tenant_id, lower(email)) INCLUDE (id);
+CREATE INDEX IF NOT EXISTS ON users ((email || 'x'));
+CREATE INDEX ON users (email);
+`)
+
+	users := db.GetTable(schema.DefaultSchema, "users")
+	if users == nil {
+		t.Fatal("table users not found")
+	}
+
+	partial := findIndexByName(users.Indexes, "users_email_idx")
+	if partial == nil {
+		t.Fatalf("unnamed index not named users_email_idx: %v", users.Indexes)
+	}
+
+	if !partial.IsUnique || partial.Where != "deleted_at IS NULL" {
+		t.Errorf("users_email_idx = unique %v where %q", partial.IsUnique, partial.Where)
+	}
+
+	for _, name := range []string{"users_tenant_id_lower_id_idx", "users_expr_idx", "users_email_idx1"} {
+		if findIndexByName(users.Indexes, name) == nil {
+			t.Errorf("index %s not found", name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseMaterializedViewRunbookStatements(t *testing.T) {
+	t.Parallel()
+
+	sql := `
+CREATE TABLE orders (id BIGINT, user_id BIGINT, total NUMERIC);
+
+CREATE MATERIALIZED VIEW stats_mv WITH (fillfactor = 70) AS
+SELECT user_id, SUM(total) AS total FROM orders GROUP BY user_id;
+
+REFRESH MATERIALIZED VIEW stats_mv;
+
+ALTER MATERIALIZED VIEW stats_mv SET (autovacuum_enabled = false, fillfactor = 90);
+
+CREATE MATERIALIZED VIEW daily_mv AS
+SELECT user_id, COUNT(*) AS orders FROM orders GROUP BY user_id
+WITH NO DATA;
+
+ALTER MATERIALIZED VIEW daily_mv SET (autovacuum_enabled = false);
+ALTER MATERIALIZED VIEW daily_mv RESET (autovacuum_enabled);
+
+REFRESH MATERIALIZED VIEW CONCURRENTLY public.daily_mv WITH DATA;
+REFRESH MATERIALIZED VIEW stats_mv;
+`
+
+	path := filepath.Join(t.TempDir(), "views.sql")
+	require.NoError(t, os.WriteFile(path, []byte(sql), 0o644))
+
+	p := parser.New()
+	db := &schema.Database{}
+	require.NoError(t, p.ParseFile(path, db))
+
+	stats := db.GetMaterializedView(schema.DefaultSchema, "stats_mv")
+	require.NotNil(t, stats)
+	assert.Equal(t, map[string]string{
+		"autovacuum_enabled": "false",
+		"fillfactor":         "90",
+	}, stats.StorageParams)
+
+	daily := db.GetMaterializedView(schema.DefaultSchema, "daily_mv")
+	require.NotNil(t, daily)
+	assert.Nil(t, daily.StorageParams)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+
+	warning := warnings[0]
+	assert.Equal(t, diag.CodeOperationalStatement, warning.Code)
+	assert.Equal(t, diag.SeverityInfo, warning.Severity)
+	assert.Equal(t, path, warning.File)
+	assert.Equal(t, 7, warning.Line)
+	assert.Contains(t, warning.Message,
+		"ignored 3 REFRESH MATERIALIZED VIEW statement(s) "+
+			"(public.stats_mv, public.daily_mv, public.stats_mv)")
+	assert.Contains(t, warning.Message, "refresh policy or pg_cron")
+}
+
+func TestParseAlterMaterializedViewOwnerIsSkipped(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+	require.NoError(t, p.ParseSQL(`
+CREATE MATERIALIZED VIEW stats_mv AS SELECT 1 AS one;
+ALTER MATERIALIZED VIEW stats_mv OWNER TO reporting;
+`, db))
+
+	require.Len(t, db.MaterializedViews, 1)
+	assert.Nil(t, db.MaterializedViews[0].StorageParams)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, diag.CodeSkippedStatement, warnings[0].Code)
+}
@@ -0,0 +1,79 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParser_MixedCaseIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE SCHEMA "Billing";
+CREATE TABLE "Billing"."UserAccounts" (
+    "Id" BIGINT PRIMARY KEY,
+    "CreatedAt" TIMESTAMPTZ NOT NULL,
+    Email TEXT UNIQUE
+);
+`)
+
+	require.Len(t, db.Schemas, 1)
+	assert.Equal(t, "Billing", db.Schemas[0].Name)
+
+	table := db.GetTable("Billing", "UserAccounts")
+	require.NotNil(t, table)
+	require.Len(t, table.Columns, 3)
+	assert.Equal(t, "Id", table.Columns[0].Name)
+	assert.Equal(t, "CreatedAt", table.Columns[1].Name)
+	assert.Equal(t, "email", table.Columns[2].Name)
+
+	assert.Nil(t, db.GetTable("billing", "useraccounts"),
+		"a quoted name keeps its case")
+
+	pk := table.GetPrimaryKey()
+	require.NotNil(t, pk)
+	assert.Equal(t, []string{"Id"}, pk.Columns)
+	assert.Equal(t, `PRIMARY KEY ("Id")`, pk.Definition)
+}
+
+func TestFoldIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ident string
+		want  string
+	}{
+		{ident: "UserAccounts", want: "useraccounts"},
+		{ident: `"UserAccounts"`, want: "UserAccounts"},
+		{ident: `"say ""hi"""`, want: `say "hi"`},
+		{ident: ` users `, want: "users"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, schema.FoldIdentifier(tt.ident), tt.ident)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "users", want: "users"},
+		{name: "UserAccounts", want: `"UserAccounts"`},
+		{name: "user", want: `"user"`},
+		{name: "2fa", want: `"2fa"`},
+		{name: `say "hi"`, want: `"say ""hi"""`},
+		{name: "", want: `""`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, schema.QuoteIdentifier(tt.name), tt.name)
+	}
+}
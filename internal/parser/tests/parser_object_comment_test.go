@@ -0,0 +1,65 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestCommentOnIndexTriggerAndSchema(t *testing.T) {
+	t.Parallel()
+
+	parsed := parseSQL(t, `
+		CREATE SCHEMA "Billing";
+
+		CREATE TABLE "Billing".invoices (id BIGINT PRIMARY KEY, customer_id BIGINT);
+		CREATE INDEX invoices_customer_idx ON "Billing".invoices (customer_id);
+		CREATE TRIGGER invoices_touch BEFORE UPDATE ON "Billing".invoices
+			FOR EACH ROW EXECUTE FUNCTION touch();
+
+		COMMENT ON SCHEMA "Billing" IS 'Invoicing';
+		COMMENT ON SCHEMA public IS 'Standard public schema';
+		COMMENT ON INDEX "Billing".invoices_customer_idx IS 'Invoices by customer';
+		COMMENT ON TRIGGER invoices_touch ON "Billing".invoices IS 'Keeps updated_at current';
+	`)
+
+	schemas := make(map[string]string)
+	for _, sch := range parsed.Schemas {
+		schemas[sch.Name] = sch.Comment
+	}
+
+	assert.Equal(t, map[string]string{
+		"Billing": "Invoicing",
+		"public":  "Standard public schema",
+	}, schemas)
+
+	require.Len(t, parsed.Tables, 1)
+	table := &parsed.Tables[0]
+
+	idx := table.GetIndex("invoices_customer_idx")
+	require.NotNil(t, idx)
+	assert.Equal(t, "Invoices by customer", idx.Comment)
+
+	require.Len(t, parsed.Triggers, 1)
+	assert.Equal(t, "Keeps updated_at current", parsed.Triggers[0].Comment)
+}
+
+func TestCommentOnMissingIndexWarns(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`
+		CREATE TABLE invoices (id BIGINT PRIMARY KEY);
+		COMMENT ON INDEX invoices_missing_idx IS 'Nothing here';
+	`, db))
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Message, "invoices_missing_idx")
+}
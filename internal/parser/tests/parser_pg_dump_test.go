@@ -0,0 +1,131 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const pgDumpNoise = `\restrict d7Fq
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+CREATE TABLE public.users (id bigint NOT NULL);
+ALTER TABLE public.users OWNER TO app;
+CREATE SEQUENCE public.users_id_seq START WITH 1 INCREMENT BY 1 NO MINVALUE NO MAXVALUE CACHE 1;
+ALTER SEQUENCE public.users_id_seq OWNER TO app;
+SELECT pg_catalog.setval('public.users_id_seq', 42, true);
+GRANT SELECT ON TABLE public.users TO reader;
+REVOKE ALL ON TABLE public.users FROM PUBLIC;
+ALTER DEFAULT PRIVILEGES FOR ROLE app GRANT SELECT ON TABLES TO reader;
+\unrestrict d7Fq
+`
+
+func TestParsePgDumpIgnoresNoise(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New(parser.WithPgDump())
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(pgDumpNoise, db))
+	assert.Empty(t, p.GetErrors())
+	assert.Empty(t, p.GetWarnings())
+	require.Len(t, db.Tables, 1)
+	require.Len(t, db.Sequences, 1)
+}
+
+func TestParseNoiseWithoutPgDumpIsSkipped(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.Error(t, p.ParseSQL(pgDumpNoise, db), "psql meta-commands are not SQL")
+
+	p = parser.New()
+	require.NoError(t, p.ParseSQL(`SET statement_timeout = 0;
+ALTER TABLE users OWNER TO app;`, db))
+
+	for _, warning := range p.GetWarnings() {
+		assert.Equal(t, diag.CodeSkippedStatement, warning.Code)
+	}
+
+	assert.Len(t, p.GetWarnings(), 2)
+}
+
+func TestParseAlterSequenceOwnedBy(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`CREATE TABLE app.users (id bigint NOT NULL);
+CREATE SEQUENCE app.users_id_seq;
+ALTER SEQUENCE app.users_id_seq OWNED BY app.users.id;
+CREATE SEQUENCE app.counter_seq OWNED BY app.users.id;
+ALTER SEQUENCE IF EXISTS app.counter_seq OWNED BY NONE;
+ALTER SEQUENCE app.missing_seq OWNED BY app.users.id;
+ALTER SEQUENCE app.users_id_seq RESTART WITH 10;`, db))
+	require.Len(t, db.Sequences, 2)
+	assert.Equal(t, "app.users", db.Sequences[0].OwnedByTable)
+	assert.Equal(t, "id", db.Sequences[0].OwnedByColumn)
+	assert.Empty(t, db.Sequences[1].OwnedByTable)
+	assert.Empty(t, db.Sequences[1].OwnedByColumn)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 2)
+	assert.Equal(t, diag.CodeObjectNotFound, warnings[0].Code)
+	assert.Equal(t, "app.missing_seq", warnings[0].ObjectName)
+	assert.Equal(t, diag.CodeSkippedStatement, warnings[1].Code)
+}
+
+func TestParseAlterColumnDefaultAndIdentity(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE orders (id bigint NOT NULL, ref integer, note text);
+ALTER TABLE ONLY orders ALTER COLUMN note SET DEFAULT 'none'::text;
+ALTER TABLE orders ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.orders_id_seq
+    START WITH 100
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+ALTER TABLE orders ALTER COLUMN ref ADD GENERATED BY DEFAULT AS IDENTITY;`)
+	table := requireSingleTable(t, db)
+
+	assert.Equal(t, "'none'::text", table.GetColumn("note").Default)
+
+	id := table.GetColumn("id")
+	assert.Equal(t, &schema.Identity{
+		Generation: schema.IdentityAlways,
+		StartValue: 100,
+		MinValue:   1,
+		MaxValue:   9223372036854775807,
+		Increment:  1,
+		CacheSize:  1,
+	}, id.Identity)
+
+	ref := table.GetColumn("ref")
+	assert.Equal(t, schema.NewIdentity(schema.IdentityByDefault, "integer"), ref.Identity)
+	assert.False(t, ref.IsNullable, "identity columns are NOT NULL")
+}
+
+func TestParseAlterColumnDefaultOfLaterTable(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`ALTER TABLE events ALTER COLUMN kind SET DEFAULT 'click';
+CREATE TABLE events (kind text);`, db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+
+	assert.Equal(t, "'click'", requireSingleTable(t, db).GetColumn("kind").Default)
+	assert.Empty(t, p.GetWarnings())
+}
@@ -0,0 +1,97 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseCreatePolicy(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE docs (id uuid PRIMARY KEY, tenant_id uuid NOT NULL);
+ALTER TABLE docs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY docs_tenant ON docs
+    USING (tenant_id = current_setting('app.tenant')::uuid);
+
+CREATE POLICY "docs write" ON public.docs AS RESTRICTIVE FOR INSERT
+    TO app_user, CURRENT_USER
+    WITH CHECK (tenant_id IS NOT NULL);
+`)
+
+	docs := requireSingleTable(t, db)
+	assert.True(t, docs.RowLevelSecurityEnabled)
+	require.Len(t, docs.Policies, 2)
+
+	tenant := docs.GetPolicy("docs_tenant")
+	require.NotNil(t, tenant)
+	assert.Equal(t, "public", tenant.Schema)
+	assert.Equal(t, "docs", tenant.TableName)
+	assert.Empty(t, tenant.Command)
+	assert.Equal(t, schema.PolicyCommandAll, tenant.CommandName())
+	assert.Equal(t, []string{schema.PolicyRolePublic}, tenant.RoleNames())
+	assert.Equal(t, "tenant_id = current_setting('app.tenant')::uuid", tenant.Using)
+	assert.Empty(t, tenant.WithCheck)
+	assert.False(t, tenant.Restrictive)
+
+	write := docs.GetPolicy("docs write")
+	require.NotNil(t, write)
+	assert.Equal(t, schema.PolicyCommandInsert, write.Command)
+	assert.Equal(t, []string{"app_user", "current_user"}, write.Roles)
+	assert.Empty(t, write.Using)
+	assert.Equal(t, "tenant_id IS NOT NULL", write.WithCheck)
+	assert.True(t, write.Restrictive)
+}
+
+func TestParseCreatePolicy_PublicAndAllAreDefaults(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE docs (id uuid PRIMARY KEY);
+CREATE POLICY docs_all ON docs AS PERMISSIVE FOR ALL TO PUBLIC USING (true);
+`)
+
+	docs := requireSingleTable(t, db)
+	assert.False(t, docs.RowLevelSecurityEnabled)
+	require.Len(t, docs.Policies, 1)
+
+	policy := docs.Policies[0]
+	assert.Empty(t, policy.Command)
+	assert.Nil(t, policy.Roles)
+	assert.False(t, policy.Restrictive)
+	assert.Equal(t, "true", policy.Using)
+}
+
+func TestParseAlterTable_DisableRowLevelSecurity(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE docs (id uuid PRIMARY KEY);
+ALTER TABLE docs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE docs DISABLE ROW LEVEL SECURITY;
+`)
+
+	assert.False(t, requireSingleTable(t, db).RowLevelSecurityEnabled)
+}
+
+func TestParseCreatePolicy_MissingTable(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(`CREATE POLICY docs_tenant ON docs USING (true);`, db))
+	assert.Empty(t, db.Tables)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, diag.CodeObjectNotFound, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "table public.docs not found for policy docs_tenant")
+}
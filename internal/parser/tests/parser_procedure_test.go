@@ -0,0 +1,64 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseCreateProcedure(t *testing.T) {
+	t.Parallel()
+
+	sql := `CREATE OR REPLACE PROCEDURE maintenance.archive_orders(
+    IN cutoff date,
+    INOUT moved bigint
+)
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    INSERT INTO archive.orders SELECT * FROM orders WHERE created_at < cutoff;
+    GET DIAGNOSTICS moved = ROW_COUNT;
+    COMMIT;
+END;
+$$;
+
+COMMENT ON PROCEDURE maintenance.archive_orders(date, bigint) IS 'Moves old orders';`
+
+	db := parseSQL(t, sql)
+	require.Len(t, db.Functions, 1)
+
+	proc := db.Functions[0]
+	assert.Equal(t, "maintenance", proc.Schema)
+	assert.Equal(t, "archive_orders", proc.Name)
+	assert.Equal(t, schema.FunctionKindProcedure, proc.Kind)
+	assert.True(t, proc.IsProcedure())
+	assert.Empty(t, proc.ReturnType)
+	assert.Equal(t, []string{"date", "bigint"}, proc.ArgumentTypes)
+	assert.Equal(t, []string{"cutoff", "moved"}, proc.ArgumentNames)
+	assert.Equal(t, []string{"IN", "INOUT"}, proc.ArgumentModes)
+	assert.Equal(t, "plpgsql", proc.Language)
+	assert.Contains(t, proc.Body, "COMMIT;")
+	assert.Equal(t, "Moves old orders", proc.Comment)
+}
+
+func TestParseCreateProcedure_KeptApartFromFunction(t *testing.T) {
+	t.Parallel()
+
+	sql := `CREATE FUNCTION refresh(n int) RETURNS void LANGUAGE sql AS $$ SELECT 1 $$;
+CREATE PROCEDURE refresh(n int) LANGUAGE sql AS $$ SELECT 2 $$;
+COMMENT ON FUNCTION refresh(int) IS 'function';
+COMMENT ON PROCEDURE refresh(int) IS 'procedure';`
+
+	db := parseSQL(t, sql)
+	require.Len(t, db.Functions, 2)
+
+	assert.False(t, db.Functions[0].IsProcedure())
+	assert.Equal(t, "void", db.Functions[0].ReturnType)
+	assert.Equal(t, "function", db.Functions[0].Comment)
+
+	assert.True(t, db.Functions[1].IsProcedure())
+	assert.Equal(t, "procedure", db.Functions[1].Comment)
+}
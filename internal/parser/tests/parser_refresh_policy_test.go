@@ -0,0 +1,38 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseRefreshPolicyInitialStart(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE MATERIALIZED VIEW metrics_hourly
+WITH (timescaledb.continuous) AS
+SELECT time_bucket('1 hour', time) AS bucket, count(*) AS total
+FROM metrics
+GROUP BY bucket
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('metrics_hourly',
+    start_offset => INTERVAL '3 days',
+    end_offset => NULL,
+    schedule_interval => INTERVAL '1 hour',
+    initial_start => '2026-01-01 00:00:00+00'::timestamptz
+);
+`)
+
+	require.Len(t, db.ContinuousAggregates, 1)
+	assert.Equal(t, &schema.RefreshPolicy{
+		StartOffset:      "3 days",
+		EndOffset:        "NULL",
+		ScheduleInterval: "1 hour",
+		InitialStart:     "'2026-01-01 00:00:00+00'::timestamptz",
+	}, db.ContinuousAggregates[0].RefreshPolicy)
+}
@@ -0,0 +1,137 @@
+package parser_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+const sourceLocationSQL = `-- users and their lookups
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY,
+    -- login address
+    "Email" TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_users_email ON users ("Email");
+
+CREATE VIEW active_users AS
+SELECT id FROM users;
+
+CREATE MATERIALIZED VIEW user_counts AS SELECT count(*) AS n FROM users;
+
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER users_touch BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION touch();
+`
+
+func TestParseRecordsSourceLocations(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "users.sql")
+	require.NoError(t, os.WriteFile(path, []byte(sourceLocationSQL), 0o600))
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseFile(path, db))
+	require.Empty(t, p.GetErrors())
+
+	at := func(line int) *schema.SourceLocation {
+		return &schema.SourceLocation{File: path, Line: line}
+	}
+
+	users := db.GetTable(schema.DefaultSchema, "users")
+	require.NotNil(t, users)
+	assert.Equal(t, at(2), users.Source)
+	assert.Equal(t, at(3), users.GetColumn("id").Source)
+	assert.Equal(t, at(5), users.GetColumn("Email").Source)
+	assert.Equal(t, at(6), users.GetColumn("created_at").Source)
+	assert.Equal(t, at(9), users.GetIndex("idx_users_email").Source)
+	assert.Nil(t, users.GetIndex("users_pkey").Source, "implicit indexes have no declaration")
+
+	require.Len(t, db.Views, 1)
+	assert.Equal(t, at(11), db.Views[0].Source)
+	require.Len(t, db.MaterializedViews, 1)
+	assert.Equal(t, at(14), db.MaterializedViews[0].Source)
+	require.Len(t, db.Functions, 1)
+	assert.Equal(t, at(16), db.Functions[0].Source)
+	require.Len(t, db.Triggers, 1)
+	assert.Equal(t, at(22), db.Triggers[0].Source)
+}
+
+func TestParseSQLRecordsNoSourceLocations(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{}
+	require.NoError(t, parser.New().ParseSQL(sourceLocationSQL, db))
+
+	users := db.GetTable(schema.DefaultSchema, "users")
+	require.NotNil(t, users)
+	assert.Nil(t, users.Source)
+	assert.Nil(t, users.GetColumn("id").Source)
+	assert.Nil(t, users.GetIndex("idx_users_email").Source)
+	require.Len(t, db.Views, 1)
+	assert.Nil(t, db.Views[0].Source)
+	require.Len(t, db.Functions, 1)
+	assert.Nil(t, db.Functions[0].Source)
+}
+
+func TestParseReaderRecordsLocationsUnderName(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseReaderContext(context.Background(), "<stdin>",
+		strings.NewReader(sourceLocationSQL+"CREATE TABLE;\n"), db))
+
+	users := db.GetTable(schema.DefaultSchema, "users")
+	require.NotNil(t, users)
+	assert.Equal(t, &schema.SourceLocation{File: "<stdin>", Line: 2}, users.Source)
+
+	require.Len(t, p.GetErrors(), 1)
+	assert.Equal(t, "<stdin>", p.GetErrors()[0].File)
+	assert.Equal(t, 24, p.GetErrors()[0].Line)
+}
+
+func TestParseRecordsMigrationAnnotations(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(`-- payouts go out with the feature flag
+-- pgtofu:migration payouts_tables
+CREATE TABLE payouts (id BIGINT PRIMARY KEY);
+
+CREATE TABLE refunds (id BIGINT PRIMARY KEY);
+
+--pgtofu:migration
+CREATE TABLE credits (id BIGINT PRIMARY KEY);
+`, db))
+
+	payouts := db.GetTable(schema.DefaultSchema, "payouts")
+	require.NotNil(t, payouts)
+	assert.Equal(t, &schema.SourceLocation{Line: 3, Migration: "payouts_tables"}, payouts.Source)
+
+	refunds := db.GetTable(schema.DefaultSchema, "refunds")
+	require.NotNil(t, refunds)
+	assert.Nil(t, refunds.Source, "the annotation only applies to its own statement")
+
+	assert.Nil(t, db.GetTable(schema.DefaultSchema, "credits"))
+	require.Len(t, p.GetErrors(), 1)
+	assert.Contains(t, p.GetErrors()[0].Message, "pgtofu:migration needs a migration name")
+	assert.Equal(t, 8, p.GetErrors()[0].Line)
+}
@@ -0,0 +1,148 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// longTable leaves too little room under the identifier limit for the
+// columns and suffix of a generated constraint name.
+const longTable = "customer_notification_preferences_by_channel_and_locale"
+
+func constraintNames(table *schema.Table, constraintType string) []string {
+	var names []string
+
+	for _, constraint := range table.Constraints {
+		if constraint.Type == constraintType {
+			names = append(names, constraint.Name)
+		}
+	}
+
+	return names
+}
+
+func TestParser_SynthesizedConstraintNamesDoNotCollide(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE `+longTable+` (
+		id BIGINT PRIMARY KEY,
+		customer_account_id BIGINT REFERENCES customers (id),
+		customer_channel_id BIGINT REFERENCES channels (id),
+		priority INT,
+		weight INT,
+		CHECK (priority > weight),
+		CHECK (priority < 100 OR weight < 100)
+	);`)
+	table := requireSingleTable(t, db)
+
+	fkeys := constraintNames(table, schema.ConstraintForeignKey)
+	require.Len(t, fkeys, 2)
+	assert.Equal(t, []string{
+		"customer_notification_preferences_by_channel_and_3762e82e_fkey",
+		"customer_notification_preferences_by_channel_and_fd557d8b_fkey",
+	}, fkeys)
+
+	checks := constraintNames(table, schema.ConstraintCheck)
+	require.Len(t, checks, 2)
+	assert.NotEqual(t, checks[0], checks[1])
+
+	for _, constraint := range table.Constraints {
+		assert.LessOrEqual(t, len(constraint.Name), schema.MaxIdentifierLength, constraint.Name)
+	}
+
+	// What older versions generated: both foreign keys cut to the same name.
+	cut := (longTable + "_customer_")[:schema.MaxIdentifierLength]
+	assert.Equal(t, cut, table.Constraints[1].LegacyName)
+	assert.Equal(t, cut, table.Constraints[2].LegacyName)
+}
+
+func TestParser_SynthesizedConstraintNamesAcrossTables(t *testing.T) {
+	t.Parallel()
+
+	// Cut at the limit, both unique constraints, and the indexes backing them
+	// that share the schema's namespace, would be named
+	// customer_notification_preferences_by_channel_and_locale_cust_a_.
+	db := parseSQL(t, "CREATE TABLE "+longTable+" (cust_a BIGINT UNIQUE);\n"+
+		"CREATE TABLE "+longTable+"_cust (a_b BIGINT UNIQUE);")
+	require.Len(t, db.Tables, 2)
+
+	first := db.Tables[0].Constraints[0]
+	second := db.Tables[1].Constraints[0]
+
+	assert.Equal(t, first.LegacyName, second.LegacyName)
+	assert.NotEqual(t, first.Name, second.Name)
+	assert.True(t, strings.HasSuffix(first.Name, "_key"), first.Name)
+	assert.True(t, strings.HasSuffix(second.Name, "_key"), second.Name)
+
+	// The backing indexes take the same names.
+	assert.Equal(t, first.Name, db.Tables[0].Indexes[0].Name)
+	assert.Equal(t, second.Name, db.Tables[1].Indexes[0].Name)
+}
+
+func TestParser_ShortSynthesizedConstraintNamesUnchanged(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE orders (
+		id BIGINT PRIMARY KEY,
+		customer_id BIGINT REFERENCES customers (id),
+		total INT CHECK (total > 0),
+		CHECK (total < 100),
+		CHECK (total <> 50)
+	);`)
+	table := requireSingleTable(t, db)
+
+	names := make([]string, 0, len(table.Constraints))
+
+	for _, constraint := range table.Constraints {
+		names = append(names, constraint.Name)
+		assert.Empty(t, constraint.LegacyName)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"orders_pkey", "orders_customer_id_fkey", "orders_total_check",
+		"orders_check", "orders_check1",
+	}, names)
+}
+
+func TestParser_SerialSequenceNameMirrorsPostgres(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, "CREATE TABLE "+longTable+"_archive (id BIGSERIAL PRIMARY KEY);")
+	table := requireSingleTable(t, db)
+
+	// PostgreSQL names the sequence itself, shortening the table name.
+	assert.Equal(t,
+		"nextval('customer_notification_preferences_by_channel_and_locale__id_seq'::regclass)",
+		table.Columns[0].Default)
+}
+
+func TestParser_WarnsAboutLongIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	const longName = "widgets_some_really_long_descriptive_constraint_name_for_testing_check"
+
+	p := parser.New()
+	db := &schema.Database{}
+	require.NoError(t, p.ParseSQL(`CREATE TABLE widgets (
+    code TEXT NOT NULL,
+    CONSTRAINT `+longName+` CHECK (code <> ''),
+    CONSTRAINT "`+longName+`" CHECK (code <> 'x')
+);
+CREATE TABLE `+longTable+` (id BIGINT PRIMARY KEY);`, db))
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1,
+		"a name is reported once per statement, and generated names not at all")
+	assert.Equal(t, diag.CodeIdentifierTooLong, warnings[0].Code)
+	assert.Equal(t, 3, warnings[0].Line)
+	assert.Equal(t, "identifier "+longName+" is 70 bytes long; "+
+		"PostgreSQL keeps only the first 63, "+longName[:schema.MaxIdentifierLength],
+		warnings[0].Message)
+}
@@ -573,6 +573,42 @@ func TestParseArrayTypes(t *testing.T) {
 			wantDataType: "INTEGER",
 			wantIsArray:  true,
 		},
+		{
+			name: "two-word type array",
+			sql: `CREATE TABLE items (
+				readings DOUBLE PRECISION [] NOT NULL
+			);`,
+			columnName:   "readings",
+			wantDataType: "DOUBLE PRECISION",
+			wantIsArray:  true,
+		},
+		{
+			name: "ARRAY keyword",
+			sql: `CREATE TABLE items (
+				readings double precision ARRAY
+			);`,
+			columnName:   "readings",
+			wantDataType: "DOUBLE PRECISION",
+			wantIsArray:  true,
+		},
+		{
+			name: "sized multi-dimensional array",
+			sql: `CREATE TABLE items (
+				grid INTEGER[3][3]
+			);`,
+			columnName:   "grid",
+			wantDataType: "INTEGER",
+			wantIsArray:  true,
+		},
+		{
+			name: "numeric with typmod array",
+			sql: `CREATE TABLE items (
+				prices NUMERIC(10,2)[]
+			);`,
+			columnName:   "prices",
+			wantDataType: "NUMERIC",
+			wantIsArray:  true,
+		},
 		{
 			name: "non-array TEXT",
 			sql: `CREATE TABLE items (
@@ -861,6 +897,24 @@ FOR VALUES FROM ('2025-10-01') TO ('2026-01-01');`
 	}
 }
 
+func TestParsePartitionIfNotExists(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TABLE app.items (id BIGINT, day DATE) PARTITION BY RANGE (day);
+
+CREATE TABLE IF NOT EXISTS app.items_2026 PARTITION OF app.items
+FOR VALUES FROM ('2026-01-01') TO ('2027-01-01');`)
+
+	table := requireSingleTable(t, db)
+	if table.PartitionStrategy == nil || len(table.PartitionStrategy.Partitions) != 1 {
+		t.Fatalf("expected 1 partition, got %+v", table.PartitionStrategy)
+	}
+
+	if name := table.PartitionStrategy.Partitions[0].Name; name != "items_2026" {
+		t.Errorf("partition name = %q, want items_2026", name)
+	}
+}
+
 func TestParsePartitionsDeferred(t *testing.T) {
 	t.Parallel()
 
@@ -992,3 +1046,159 @@ CREATE TABLE items_p2 PARTITION OF items
 		}
 	}
 }
+
+func TestParseAddConstraintUsingIndex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sql         string
+		wantType    string
+		wantName    string
+		wantColumns []string
+		wantErr     bool
+	}{
+		{
+			name: "unique constraint adopts index",
+			sql: `CREATE TABLE users (id BIGINT, email TEXT NOT NULL);
+				CREATE UNIQUE INDEX CONCURRENTLY users_email_idx ON users (email);
+				ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE USING INDEX users_email_idx;`,
+			wantType:    schema.ConstraintUnique,
+			wantName:    "users_email_key",
+			wantColumns: []string{"email"},
+		},
+		{
+			name: "primary key adopts index on qualified table",
+			sql: `CREATE TABLE app.accounts (id BIGINT NOT NULL);
+				CREATE UNIQUE INDEX accounts_id_idx ON app.accounts (id);
+				ALTER TABLE ONLY app.accounts
+					ADD CONSTRAINT accounts_pkey PRIMARY KEY USING INDEX accounts_id_idx;`,
+			wantType:    schema.ConstraintPrimaryKey,
+			wantName:    "accounts_pkey",
+			wantColumns: []string{"id"},
+		},
+		{
+			name: "missing index is an error",
+			sql: `CREATE TABLE users (id BIGINT, email TEXT NOT NULL);
+				ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE USING INDEX users_email_idx;`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := parser.New()
+			db := &schema.Database{}
+
+			if err := p.ParseSQL(tt.sql, db); err != nil {
+				t.Fatalf("ParseSQL() error = %v", err)
+			}
+
+			if tt.wantErr {
+				if len(p.GetErrors()) == 0 {
+					t.Fatal("expected parse error, got none")
+				}
+
+				return
+			}
+
+			if errs := p.GetErrors(); len(errs) > 0 {
+				t.Fatalf("unexpected parse errors: %v", errs)
+			}
+
+			table := requireSingleTable(t, db)
+
+			var constraint *schema.Constraint
+
+			for i := range table.Constraints {
+				if table.Constraints[i].Name == tt.wantName {
+					constraint = &table.Constraints[i]
+				}
+			}
+
+			if constraint == nil {
+				t.Fatalf("constraint %s not found", tt.wantName)
+			}
+
+			if constraint.Type != tt.wantType {
+				t.Errorf("constraint type = %v, want %v", constraint.Type, tt.wantType)
+			}
+
+			if len(table.Indexes) != 1 || table.Indexes[0].Name != tt.wantName {
+				t.Errorf("indexes = %+v, want the adopted index renamed to %s", table.Indexes, tt.wantName)
+			}
+
+			if strings.Join(constraint.Columns, ",") != strings.Join(tt.wantColumns, ",") {
+				t.Errorf("columns = %v, want %v", constraint.Columns, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestParseAddConstraintUsingIndexFreesIndexName(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	if err := p.ParseSQL(`CREATE TABLE users (id BIGINT, email TEXT NOT NULL);
+CREATE UNIQUE INDEX users_email_idx ON users (email);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE USING INDEX users_email_idx;
+CREATE INDEX users_email_idx ON users (lower(email));
+COMMENT ON INDEX users_email_key IS 'adopted';`, db); err != nil {
+		t.Fatalf("ParseSQL() error = %v", err)
+	}
+
+	if warnings := p.GetWarnings(); len(warnings) > 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	table := requireSingleTable(t, db)
+	if len(table.Indexes) != 2 {
+		t.Fatalf("indexes = %+v, want the adopted index and the new one", table.Indexes)
+	}
+
+	if adopted := table.Indexes[0]; adopted.Name != "users_email_key" || adopted.Comment != "adopted" {
+		t.Errorf("adopted index = %+v, want users_email_key with its comment", adopted)
+	}
+
+	if table.Indexes[1].Name != "users_email_idx" {
+		t.Errorf("new index = %+v, want users_email_idx", table.Indexes[1])
+	}
+}
+
+func TestParseIfNotExistsFlag(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE IF NOT EXISTS audit_log (id BIGINT, message TEXT);
+CREATE TABLE accounts (id BIGINT);
+CREATE INDEX IF NOT EXISTS idx_audit_log_message ON audit_log (message);
+CREATE INDEX idx_accounts_id ON accounts (id);
+`)
+
+	auditLog := db.GetTable(schema.DefaultSchema, "audit_log")
+	accounts := db.GetTable(schema.DefaultSchema, "accounts")
+
+	if auditLog == nil || accounts == nil {
+		t.Fatalf("expected audit_log and accounts tables, got %d tables", len(db.Tables))
+	}
+
+	if !auditLog.IfNotExists {
+		t.Error("audit_log.IfNotExists = false, want true")
+	}
+
+	if accounts.IfNotExists {
+		t.Error("accounts.IfNotExists = true, want false")
+	}
+
+	if len(auditLog.Indexes) != 1 || !auditLog.Indexes[0].IfNotExists {
+		t.Errorf("audit_log indexes = %+v, want one IF NOT EXISTS index", auditLog.Indexes)
+	}
+
+	if len(accounts.Indexes) != 1 || accounts.Indexes[0].IfNotExists {
+		t.Errorf("accounts indexes = %+v, want one plain index", accounts.Indexes)
+	}
+}
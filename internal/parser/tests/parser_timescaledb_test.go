@@ -2,6 +2,8 @@ package parser_test
 
 import (
 	"testing"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
 func TestParseTimescaleDB(t *testing.T) {
@@ -44,3 +46,220 @@ func TestParseTimescaleDB(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCompressionPolicy(t *testing.T) {
+	t.Parallel()
+
+	setupSQL := `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+ALTER TABLE metrics SET (timescaledb.compress);`
+
+	tests := []struct {
+		name    string
+		tsdbSQL string
+		want    *schema.CompressionPolicy
+	}{
+		{
+			name:    "positional interval",
+			tsdbSQL: `SELECT add_compression_policy('metrics', INTERVAL '7 days');`,
+			want: &schema.CompressionPolicy{
+				HypertableSchema: schema.DefaultSchema,
+				HypertableName:   "metrics",
+				CompressAfter:    "7 days",
+			},
+		},
+		{
+			name: "named arguments",
+			tsdbSQL: `SELECT add_compression_policy('metrics', compress_after => INTERVAL '14 days',
+	schedule_interval => INTERVAL '1 hour', initial_start => '2024-01-01 00:00:00+00');`,
+			want: &schema.CompressionPolicy{
+				HypertableSchema: schema.DefaultSchema,
+				HypertableName:   "metrics",
+				CompressAfter:    "14 days",
+				ScheduleInterval: "1 hour",
+				InitialStart:     "'2024-01-01 00:00:00+00'",
+			},
+		},
+		{
+			name: "removed policy",
+			tsdbSQL: `SELECT add_compression_policy('metrics', INTERVAL '7 days');
+SELECT remove_compression_policy('metrics', if_exists => true);`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQLWithSetup(t, setupSQL, tt.tsdbSQL)
+
+			if len(db.Hypertables) != 1 {
+				t.Fatalf("expected 1 hypertable, got %d", len(db.Hypertables))
+			}
+
+			ht := db.Hypertables[0]
+			if !ht.CompressionEnabled {
+				t.Errorf("expected compression to stay enabled")
+			}
+
+			got := ht.CompressionPolicy
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("compression policy = %+v, want %+v", got, tt.want)
+			}
+
+			if got != nil && *got != *tt.want {
+				t.Errorf("compression policy = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAddDimension(t *testing.T) {
+	t.Parallel()
+
+	setupSQL := `CREATE TABLE metrics (
+    time TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL,
+    device_id INT NOT NULL,
+    value DOUBLE PRECISION
+);
+SELECT create_hypertable('metrics', 'time');`
+
+	tests := []struct {
+		name    string
+		tsdbSQL string
+		want    []schema.Dimension
+	}{
+		{
+			name: "named arguments",
+			tsdbSQL: `SELECT add_dimension('metrics', 'received_at', chunk_time_interval => INTERVAL '7 days');
+SELECT add_dimension('metrics', 'device_id', number_partitions => 8);`,
+			want: []schema.Dimension{
+				{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "7 days"},
+				{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 8},
+			},
+		},
+		{
+			name:    "positional partitions",
+			tsdbSQL: `SELECT add_dimension('public.metrics', 'device_id', 4);`,
+			want: []schema.Dimension{
+				{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 4},
+			},
+		},
+		{
+			name: "dimension builders",
+			tsdbSQL: `SELECT add_dimension('metrics', by_range('received_at', INTERVAL '1 day'));
+SELECT add_dimension('metrics', by_hash('device_id', 16));`,
+			want: []schema.Dimension{
+				{ColumnName: "received_at", Type: schema.DimensionTime, Interval: "1 day"},
+				{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 16},
+			},
+		},
+		{
+			name: "create_hypertable partitioning column",
+			tsdbSQL: `SELECT create_hypertable('metrics', 'time',
+    partitioning_column => 'device_id', number_partitions => 4);`,
+			want: []schema.Dimension{
+				{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQLWithSetup(t, setupSQL, tt.tsdbSQL)
+
+			if len(db.Hypertables) != 1 {
+				t.Fatalf("expected 1 hypertable, got %d", len(db.Hypertables))
+			}
+
+			got := db.Hypertables[0].Dimensions
+			if len(got) != len(tt.want) {
+				t.Fatalf("dimensions = %+v, want %+v", got, tt.want)
+			}
+
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("dimension %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseContinuousAggregateCompression(t *testing.T) {
+	t.Parallel()
+
+	setupSQL := `CREATE TABLE metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('metrics', 'time');
+CREATE MATERIALIZED VIEW metrics_hourly WITH (timescaledb.continuous) AS
+SELECT time_bucket(INTERVAL '1 hour', time) AS bucket, avg(value) AS avg_value
+FROM metrics GROUP BY bucket WITH NO DATA;`
+
+	tests := []struct {
+		name        string
+		tsdbSQL     string
+		wantEnabled bool
+		wantPolicy  *schema.CompressionPolicy
+	}{
+		{
+			name: "enabled with policy",
+			tsdbSQL: `ALTER MATERIALIZED VIEW metrics_hourly SET (timescaledb.compress = true);
+SELECT add_compression_policy('metrics_hourly', compress_after => INTERVAL '7 days');`,
+			wantEnabled: true,
+			wantPolicy: &schema.CompressionPolicy{
+				HypertableSchema: schema.DefaultSchema,
+				HypertableName:   "metrics_hourly",
+				CompressAfter:    "7 days",
+			},
+		},
+		{
+			name:        "enabled without value",
+			tsdbSQL:     `ALTER MATERIALIZED VIEW public.metrics_hourly SET (timescaledb.compress);`,
+			wantEnabled: true,
+		},
+		{
+			name: "disabled again",
+			tsdbSQL: `ALTER MATERIALIZED VIEW metrics_hourly SET (timescaledb.compress = true);
+SELECT add_compression_policy('metrics_hourly', INTERVAL '7 days');
+ALTER MATERIALIZED VIEW metrics_hourly SET (timescaledb.compress = false);`,
+		},
+		{
+			name: "policy removed",
+			tsdbSQL: `ALTER MATERIALIZED VIEW metrics_hourly SET (timescaledb.compress = true);
+SELECT add_compression_policy('metrics_hourly', INTERVAL '7 days');
+SELECT remove_compression_policy('metrics_hourly');`,
+			wantEnabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQLWithSetup(t, setupSQL, tt.tsdbSQL)
+
+			if len(db.ContinuousAggregates) != 1 {
+				t.Fatalf("expected 1 continuous aggregate, got %d", len(db.ContinuousAggregates))
+			}
+
+			cagg := db.ContinuousAggregates[0]
+			if cagg.CompressionEnabled != tt.wantEnabled {
+				t.Errorf("compression enabled = %t, want %t",
+					cagg.CompressionEnabled, tt.wantEnabled)
+			}
+
+			got := cagg.CompressionPolicy
+			if (got == nil) != (tt.wantPolicy == nil) {
+				t.Fatalf("compression policy = %+v, want %+v", got, tt.wantPolicy)
+			}
+
+			if got != nil && *got != *tt.wantPolicy {
+				t.Errorf("compression policy = %+v, want %+v", *got, *tt.wantPolicy)
+			}
+		})
+	}
+}
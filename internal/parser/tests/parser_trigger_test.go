@@ -59,3 +59,87 @@ EXECUTE FUNCTION UPDATE_TIMESTAMP();
 	require.Equal(t, "set_updated_at", orderTrigger.Name)
 	require.Equal(t, "set_updated_at", auditTrigger.Name)
 }
+
+func TestParseAlterTableTriggerState(t *testing.T) {
+	t.Parallel()
+
+	setup := `
+CREATE TABLE public.orders (id UUID PRIMARY KEY);
+CREATE TRIGGER audit_trg AFTER INSERT ON public.orders
+FOR EACH ROW EXECUTE FUNCTION audit();
+CREATE TRIGGER notify_trg AFTER INSERT ON public.orders
+FOR EACH ROW EXECUTE FUNCTION notify();
+`
+
+	tests := []struct {
+		name       string
+		alter      string
+		wantAudit  string
+		wantNotify string
+	}{
+		{
+			name:      "disable",
+			alter:     "ALTER TABLE public.orders DISABLE TRIGGER audit_trg;",
+			wantAudit: schema.TriggerEnabledDisabled,
+		},
+		{
+			name:      "enable replica",
+			alter:     "ALTER TABLE ONLY orders ENABLE REPLICA TRIGGER audit_trg;",
+			wantAudit: schema.TriggerEnabledReplica,
+		},
+		{
+			name:      "enable always",
+			alter:     `ALTER TABLE IF EXISTS orders ENABLE ALWAYS TRIGGER "audit_trg";`,
+			wantAudit: schema.TriggerEnabledAlways,
+		},
+		{
+			name: "enable after disable",
+			alter: "ALTER TABLE orders DISABLE TRIGGER audit_trg;\n" +
+				"ALTER TABLE orders ENABLE TRIGGER audit_trg;",
+			wantAudit: "",
+		},
+		{
+			name:       "disable all",
+			alter:      "ALTER TABLE orders DISABLE TRIGGER ALL;",
+			wantAudit:  schema.TriggerEnabledDisabled,
+			wantNotify: schema.TriggerEnabledDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQL(t, setup+tt.alter)
+
+			require.Len(t, db.Triggers, 2)
+			require.Equal(t, "audit_trg", db.Triggers[0].Name)
+			require.Equal(t, tt.wantAudit, db.Triggers[0].EnabledState)
+			require.Equal(t, tt.wantNotify, db.Triggers[1].EnabledState)
+		})
+	}
+}
+
+func TestParseCreateTrigger_InsteadOfOnView(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE orders (id BIGINT PRIMARY KEY, total NUMERIC);
+
+CREATE VIEW order_entry AS SELECT id, total FROM orders;
+
+CREATE TRIGGER order_entry_write
+INSTEAD OF INSERT OR UPDATE ON order_entry
+FOR EACH ROW
+EXECUTE FUNCTION order_entry_write();
+`)
+
+	require.Len(t, db.Triggers, 1)
+
+	trigger := db.Triggers[0]
+	require.Equal(t, "INSTEAD OF", trigger.Timing)
+	require.Equal(t, []string{"INSERT", "UPDATE"}, trigger.Events)
+	require.Equal(t, "order_entry", trigger.TableName)
+	require.True(t, trigger.ForEachRow)
+	require.Equal(t, "order_entry_write", trigger.FunctionName)
+}
@@ -0,0 +1,144 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func parseTypedTables(t *testing.T, sql string) (*schema.Database, *parser.Parser) {
+	t.Helper()
+
+	p := parser.New()
+	db := &schema.Database{}
+
+	require.NoError(t, p.ParseSQL(sql, db))
+	require.NoError(t, p.ProcessDeferredPartitions(db))
+
+	return db, p
+}
+
+func TestParseCompositeType(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE TYPE app.event_type AS (
+    id BIGINT,
+    name VARCHAR(64),
+    tags TEXT[]
+);`)
+
+	require.Len(t, db.CustomTypes, 1)
+
+	ct := db.CustomTypes[0]
+	assert.Equal(t, "app", ct.Schema)
+	assert.Equal(t, "event_type", ct.Name)
+	assert.Equal(t, "composite", ct.Type)
+	assert.Equal(t, []schema.TypeAttribute{
+		{Name: "id", DataType: "BIGINT"},
+		{Name: "name", DataType: "VARCHAR(64)"},
+		{Name: "tags", DataType: "TEXT[]"},
+	}, ct.Attributes)
+}
+
+func TestParseTypedTable(t *testing.T) {
+	t.Parallel()
+
+	db, p := parseTypedTables(t, `CREATE TABLE events OF event_type (
+    id WITH OPTIONS PRIMARY KEY,
+    name NOT NULL DEFAULT 'unnamed',
+    CONSTRAINT events_amount_check CHECK (amount > 0)
+);
+CREATE TABLE archived_events OF event_type;
+
+CREATE TYPE event_type AS (id BIGINT, name VARCHAR(64), amount NUMERIC(10, 2));`)
+
+	assert.Empty(t, p.GetWarnings())
+	require.Len(t, db.Tables, 2)
+
+	events := db.GetTable("public", "events")
+	require.NotNil(t, events)
+	assert.Equal(t, "public.event_type", events.TypedOf)
+	require.Len(t, events.Columns, 3)
+
+	id, name, amount := events.Columns[0], events.Columns[1], events.Columns[2]
+	assert.Equal(t, "id", id.Name)
+	assert.Equal(t, "BIGINT", id.DataType)
+	assert.False(t, id.IsNullable)
+	assert.Equal(t, "name", name.Name)
+	assert.Equal(t, "VARCHAR(64)", name.FullDataType())
+	assert.False(t, name.IsNullable)
+	assert.Equal(t, "'unnamed'", name.Default)
+	assert.Equal(t, "amount", amount.Name)
+	assert.Equal(t, "NUMERIC(10, 2)", amount.FullDataType())
+	assert.True(t, amount.IsNullable)
+	assert.Equal(t, 3, amount.Position)
+
+	var constraintTypes []string
+	for _, c := range events.Constraints {
+		constraintTypes = append(constraintTypes, c.Type)
+	}
+
+	assert.ElementsMatch(t,
+		[]string{schema.ConstraintPrimaryKey, schema.ConstraintCheck}, constraintTypes)
+
+	archived := db.GetTable("public", "archived_events")
+	require.NotNil(t, archived)
+	assert.Equal(t, "public.event_type", archived.TypedOf)
+	require.Len(t, archived.Columns, 3)
+	assert.True(t, archived.Columns[0].IsNullable)
+	assert.Empty(t, archived.Constraints)
+}
+
+func TestParseTypedTable_KeepsLocalColumns(t *testing.T) {
+	t.Parallel()
+
+	db, _ := parseTypedTables(t, `CREATE TYPE event_type AS (id BIGINT);
+CREATE TABLE events OF event_type (id NOT NULL, note TEXT, missing WITH OPTIONS NOT NULL);`)
+
+	events := requireSingleTable(t, db)
+	require.Len(t, events.Columns, 3)
+	assert.Equal(t, "id", events.Columns[0].Name)
+	assert.Equal(t, "note", events.Columns[1].Name)
+	assert.Equal(t, "TEXT", events.Columns[1].DataType)
+	assert.Equal(t, "missing", events.Columns[2].Name)
+	assert.Empty(t, events.Columns[2].DataType)
+
+	errs := schema.ValidateTypedTables(db)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "note", errs[0].Column)
+	assert.Equal(t, "missing", errs[1].Column)
+	assert.Contains(t, errs[0].Error(),
+		"public.events.note is not an attribute of public.event_type")
+}
+
+func TestParseTypedTable_MissingType(t *testing.T) {
+	t.Parallel()
+
+	db, p := parseTypedTables(t, `CREATE TABLE events OF event_type;`)
+
+	events := requireSingleTable(t, db)
+	assert.Equal(t, "public.event_type", events.TypedOf)
+	assert.Empty(t, events.Columns)
+
+	warnings := p.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, diag.CodeObjectNotFound, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "composite type public.event_type not found")
+}
+
+func TestParseTypedTable_ColumnAttributesAfterDerivation(t *testing.T) {
+	t.Parallel()
+
+	db, _ := parseTypedTables(t, `CREATE TABLE events OF event_type;
+ALTER TABLE events ALTER COLUMN payload SET STORAGE EXTERNAL;
+CREATE TYPE event_type AS (id BIGINT, payload JSONB);`)
+
+	events := requireSingleTable(t, db)
+	require.Len(t, events.Columns, 2)
+	assert.Equal(t, "external", events.Columns[1].Storage)
+}
@@ -0,0 +1,57 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParseColumnUsingAnnotations(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `
+CREATE TABLE events (
+    id UUID PRIMARY KEY, -- pgtofu:using id::uuid
+    -- seconds since the epoch
+    -- pgtofu:using (extract(epoch from created_at))::bigint
+    created_at BIGINT NOT NULL,
+    status event_status
+        -- pgtofu:using CASE status WHEN 1 THEN 'open' ELSE 'closed' END::event_status
+        NOT NULL,
+    note TEXT -- not a directive
+);
+`)
+
+	table := requireSingleTable(t, db)
+
+	usings := make(map[string]string, len(table.Columns))
+	for _, column := range table.Columns {
+		usings[column.Name] = column.UsingExpression
+	}
+
+	assert.Equal(t, map[string]string{
+		"id":         "id::uuid",
+		"created_at": "(extract(epoch from created_at))::bigint",
+		"status":     "CASE status WHEN 1 THEN 'open' ELSE 'closed' END::event_status",
+		"note":       "",
+	}, usings)
+}
+
+func TestParseColumnUsingAnnotationNeedsExpression(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(`CREATE TABLE events (
+    created_at BIGINT -- pgtofu:using
+);
+`, db))
+
+	assert.Empty(t, db.Tables)
+	require.Len(t, p.GetErrors(), 1)
+	assert.Contains(t, p.GetErrors()[0].Message, "pgtofu:using needs the expression")
+}
@@ -0,0 +1,70 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseViewOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		sql             string
+		wantOptions     map[string]string
+		wantCheckOption string
+	}{
+		{
+			name:        "boolean option without a value",
+			sql:         `CREATE VIEW v WITH (security_barrier) AS SELECT id FROM users;`,
+			wantOptions: map[string]string{"security_barrier": "true"},
+		},
+		{
+			name: "check option in the option list",
+			sql: `CREATE VIEW v WITH (Security_Invoker = 'on', check_option = local) AS
+SELECT id FROM users;`,
+			wantOptions:     map[string]string{"security_invoker": "on"},
+			wantCheckOption: "LOCAL",
+		},
+		{
+			name: "trailing check option",
+			sql: `CREATE VIEW v AS SELECT id FROM users WHERE active
+WITH CHECK OPTION;`,
+			wantCheckOption: "CASCADED",
+		},
+		{
+			name: "trailing local check option",
+			sql: `CREATE VIEW v AS SELECT id FROM users WHERE active
+    WITH LOCAL CHECK OPTION -- only this view
+;`,
+			wantCheckOption: "LOCAL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := parseSQL(t, tt.sql)
+			require.Len(t, db.Views, 1)
+
+			view := db.Views[0]
+			assert.Equal(t, tt.wantOptions, view.Options)
+			assert.Equal(t, tt.wantCheckOption, view.CheckOption)
+			assert.NotContains(t, view.Definition, "CHECK OPTION")
+			assert.NotContains(t, view.Definition, "WITH")
+		})
+	}
+}
+
+func TestParseViewCheckOptionInQuery(t *testing.T) {
+	t.Parallel()
+
+	db := parseSQL(t, `CREATE VIEW v AS
+WITH recent AS (SELECT id FROM users) SELECT id FROM recent;`)
+	require.Len(t, db.Views, 1)
+	assert.Empty(t, db.Views[0].CheckOption)
+	assert.Contains(t, db.Views[0].Definition, "SELECT id FROM recent")
+}
@@ -0,0 +1,118 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/parser"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestParserWarningCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		sql        string
+		wantCode   diag.Code
+		wantObject string
+		wantLine   int
+	}{
+		{
+			name:     "unsupported statement",
+			sql:      "CREATE TABLE users (id BIGINT);\n\nGRANT USAGE ON SCHEMA public TO app;",
+			wantCode: diag.CodeSkippedStatement,
+			wantLine: 3,
+		},
+		{
+			name:     "unsupported alter table action",
+			sql:      "CREATE TABLE users (id BIGINT);\nALTER TABLE users OWNER TO app;",
+			wantCode: diag.CodeSkippedStatement,
+			wantLine: 2,
+		},
+		{
+			name:       "comment on missing table",
+			sql:        "COMMENT ON TABLE missing IS 'gone';",
+			wantCode:   diag.CodeObjectNotFound,
+			wantObject: "public.missing",
+		},
+		{
+			name:       "index on missing table",
+			sql:        "CREATE INDEX idx_missing_id ON missing (id);",
+			wantCode:   diag.CodeObjectNotFound,
+			wantObject: "public.missing",
+		},
+		{
+			name:       "identical duplicate table",
+			sql:        "CREATE TABLE users (id BIGINT);\nCREATE TABLE users (id BIGINT);",
+			wantCode:   diag.CodeDuplicateDefinition,
+			wantObject: "public.users",
+			wantLine:   2,
+		},
+		{
+			name: "index redefined on the same table",
+			sql: "CREATE TABLE users (id BIGINT);\nCREATE INDEX users_id ON users (id);\n" +
+				"CREATE INDEX users_id ON users (id DESC);",
+			wantCode:   diag.CodeDuplicateDefinition,
+			wantObject: "public.users_id",
+			wantLine:   3,
+		},
+		{
+			name: "partitions created in a DO block",
+			sql: "CREATE TABLE events (id BIGINT, day DATE) PARTITION BY RANGE (day);\n" +
+				"DO $$ BEGIN\n" +
+				"  EXECUTE 'CREATE TABLE events_2026 PARTITION OF events " +
+				"FOR VALUES FROM (''2026-01-01'') TO (''2027-01-01'')';\n" +
+				"END $$;",
+			wantCode: diag.CodeProceduralPartitioning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := parser.New()
+			require.NoError(t, p.ParseSQL(tt.sql, &schema.Database{}))
+
+			warnings := p.GetWarnings()
+			require.Len(t, warnings, 1)
+
+			warning := warnings[0]
+			assert.Equal(t, tt.wantCode, warning.Code)
+			assert.Equal(t, diag.SeverityWarning, warning.Severity)
+			assert.Equal(t, tt.wantObject, warning.ObjectName)
+			assert.Equal(t, tt.wantLine, warning.Line)
+			assert.NotEmpty(t, warning.Message)
+			assert.Contains(t, warning.String(), "["+string(tt.wantCode)+"]")
+		})
+	}
+}
+
+func TestParserReportsSkippedStatements(t *testing.T) {
+	t.Parallel()
+
+	var skipped []parser.SkippedStatement
+
+	p := parser.New(parser.WithSkippedStatements(func(stmt parser.SkippedStatement) {
+		skipped = append(skipped, stmt)
+	}))
+
+	require.NoError(t, p.ParseSQL(`CREATE TABLE users (id BIGINT);
+GRANT USAGE ON SCHEMA public
+    TO app;
+CREATE INDEX idx_users_id ON users (id);
+CREATE TABLE broken (id BIGINT;
+`, &schema.Database{}))
+
+	require.Len(t, skipped, 2)
+	assert.Equal(t, 2, skipped[0].Line)
+	assert.Equal(t, "GRANT USAGE ON SCHEMA public\n    TO app", skipped[0].SQL)
+	assert.Contains(t, skipped[0].Reason, "unsupported statement")
+	assert.Equal(t, 5, skipped[1].Line)
+	assert.Equal(t, "CREATE TABLE broken (id BIGINT", skipped[1].SQL)
+	assert.Equal(t, p.GetErrors()[0].Message, skipped[1].Reason)
+}
@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -27,11 +28,14 @@ func TestRegistryIncludesAllBuiltinParsers(t *testing.T) {
 		parser.StmtCreateSequence,
 		parser.StmtAlterTable,
 		parser.StmtSelectCreateHypertable,
+		parser.StmtSelectAddDimension,
 		parser.StmtSelectAddCompressionPolicy,
+		parser.StmtSelectRemoveCompressionPolicy,
 		parser.StmtSelectAddRetentionPolicy,
 		parser.StmtSelectAddContinuousAggregatePolicy,
 		parser.StmtComment,
 		parser.StmtDoBlock,
+		parser.StmtSelectInto,
 	}
 
 	for _, stmtType := range expected {
@@ -94,7 +98,7 @@ func TestSchemaParserParse(t *testing.T) {
 
 	require.NoError(t, p.Parse(parser.New(), stmt, db))
 	require.Len(t, db.Schemas, 1)
-	require.Equal(t, "my-app", db.Schemas[0].Name)
+	require.Equal(t, "My-App", db.Schemas[0].Name)
 	require.NoError(t, p.Parse(parser.New(), stmt, db))
 	require.Len(t, db.Schemas, 1)
 }
@@ -161,3 +165,76 @@ func TestSequenceParserParse(t *testing.T) {
 	require.Equal(t, "bigint", seq.DataType)
 	require.EqualValues(t, 1, seq.Increment)
 }
+
+func TestSequenceParserOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want schema.Sequence
+	}{
+		{
+			name: "defaults",
+			sql:  `CREATE SEQUENCE public.order_seq;`,
+			want: schema.Sequence{
+				DataType: "bigint", Increment: 1, MinValue: 1, MaxValue: math.MaxInt64,
+				StartValue: 1, CacheSize: 1,
+			},
+		},
+		{
+			name: "all options",
+			sql: `CREATE SEQUENCE IF NOT EXISTS public.order_seq AS integer INCREMENT BY 5
+				MINVALUE 10 MAXVALUE 5000 START WITH 1000 CACHE 20 CYCLE
+				OWNED BY public.orders.id;`,
+			want: schema.Sequence{
+				DataType: "integer", Increment: 5, MinValue: 10, MaxValue: 5000,
+				StartValue: 1000, CacheSize: 20, IsCyclic: true,
+				OwnedByTable: "public.orders", OwnedByColumn: "id",
+			},
+		},
+		{
+			name: "pg_dump style",
+			sql: `CREATE SEQUENCE public.order_seq AS smallint START 7 INCREMENT 1
+				NO MINVALUE NO MAXVALUE CACHE 1 NO CYCLE;`,
+			want: schema.Sequence{
+				DataType: "smallint", Increment: 1, MinValue: 1, MaxValue: math.MaxInt16,
+				StartValue: 7, CacheSize: 1,
+			},
+		},
+		{
+			name: "descending",
+			sql:  `CREATE SEQUENCE public.order_seq AS int4 INCREMENT BY -1;`,
+			want: schema.Sequence{
+				DataType: "integer", Increment: -1, MinValue: math.MinInt32, MaxValue: -1,
+				StartValue: -1, CacheSize: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &schema.Database{}
+			p := parser.New()
+			require.NoError(t, p.ParseSQL(tt.sql, db))
+			require.Empty(t, p.GetErrors())
+			require.Len(t, db.Sequences, 1)
+
+			want := tt.want
+			want.Schema, want.Name = "public", "order_seq"
+			require.Equal(t, want, db.Sequences[0])
+		})
+	}
+}
+
+func TestSequenceParserRejectsUnknownOption(t *testing.T) {
+	t.Parallel()
+
+	db := &schema.Database{}
+	p := parser.New()
+	require.NoError(t, p.ParseSQL(`CREATE SEQUENCE public.order_seq INCREMENT BY many;`, db))
+	require.NotEmpty(t, p.GetErrors())
+	require.Empty(t, db.Sequences)
+}
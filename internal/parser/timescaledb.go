@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/accented-ai/pgtofu/internal/schema"
@@ -46,7 +47,7 @@ func (p *Parser) parseCreateHypertable(stmt string, db *schema.Database) error {
 		timeColumn = column
 		partitionInterval = interval
 	case strings.HasPrefix(strings.ToUpper(second), "BY_HASH"):
-		column, err := parseTimescaleByHash(second)
+		column, _, err := parseTimescaleByHash(second)
 		if err != nil {
 			return err
 		}
@@ -88,9 +89,123 @@ func (p *Parser) parseCreateHypertable(stmt string, db *schema.Database) error {
 		ht.ChunkTimeInterval = partitionInterval
 	}
 
+	if column, ok := call.named["partitioning_column"]; ok {
+		partitions, err := parsePartitionCount(call.named["number_partitions"])
+		if err != nil {
+			return err
+		}
+
+		setDimension(ht, schema.Dimension{
+			ColumnName:       unquote(column),
+			Type:             schema.DimensionSpace,
+			NumberPartitions: partitions,
+		})
+	}
+
 	return nil
 }
 
+func (p *Parser) parseAddDimension(stmt string, db *schema.Database) error {
+	call, err := parseTimescaleCall(stmt)
+	if err != nil {
+		return err
+	}
+
+	if call.name != "add_dimension" {
+		return NewParseError("unexpected TimescaleDB function")
+	}
+
+	if len(call.positional) < 2 {
+		return NewParseError("add_dimension requires hypertable and column")
+	}
+
+	tableSchema, tableName := p.splitSchemaTable(unquote(call.positional[0]))
+
+	dim, err := parseDimensionArgs(call)
+	if err != nil {
+		return err
+	}
+
+	ht := findHypertable(db, tableSchema, tableName)
+	if ht == nil {
+		return fmt.Errorf("hypertable %s.%s not found", tableSchema, tableName)
+	}
+
+	setDimension(ht, dim)
+
+	return nil
+}
+
+func setDimension(ht *schema.Hypertable, dim schema.Dimension) {
+	for i := range ht.Dimensions {
+		if strings.EqualFold(ht.Dimensions[i].ColumnName, dim.ColumnName) {
+			ht.Dimensions[i] = dim
+			return
+		}
+	}
+
+	ht.Dimensions = append(ht.Dimensions, dim)
+}
+
+// parseDimensionArgs accepts both the by_range()/by_hash() dimension builders
+// and the older column_name, number_partitions, chunk_time_interval form.
+func parseDimensionArgs(call *timescaleCall) (schema.Dimension, error) {
+	second := strings.TrimSpace(call.positional[1])
+
+	switch {
+	case strings.HasPrefix(strings.ToUpper(second), "BY_RANGE"):
+		column, interval, err := parseTimescaleByRange(second)
+		if err != nil {
+			return schema.Dimension{}, err
+		}
+
+		return schema.Dimension{
+			ColumnName: column,
+			Type:       schema.DimensionTime,
+			Interval:   interval,
+		}, nil
+	case strings.HasPrefix(strings.ToUpper(second), "BY_HASH"):
+		column, partitions, err := parseTimescaleByHash(second)
+		if err != nil {
+			return schema.Dimension{}, err
+		}
+
+		return schema.Dimension{
+			ColumnName:       column,
+			Type:             schema.DimensionSpace,
+			NumberPartitions: partitions,
+		}, nil
+	}
+
+	partitions := call.named["number_partitions"]
+	if partitions == "" && len(call.positional) > 2 {
+		partitions = call.positional[2]
+	}
+
+	numPartitions, err := parsePartitionCount(partitions)
+	if err != nil {
+		return schema.Dimension{}, err
+	}
+
+	interval := call.named["chunk_time_interval"]
+	if interval == "" && len(call.positional) > 3 {
+		interval = call.positional[3]
+	}
+
+	dim := schema.Dimension{
+		ColumnName:       unquote(second),
+		Type:             schema.DimensionTime,
+		NumberPartitions: numPartitions,
+		Interval:         extractIntervalValue(interval),
+	}
+
+	if numPartitions > 0 {
+		dim.Type = schema.DimensionSpace
+	}
+
+	return dim, nil
+}
+
 func (p *Parser) parseCompressionPolicy(stmt string, db *schema.Database) error {
 	call, err := parseTimescaleCall(stmt)
 	if err != nil {
@@ -120,16 +235,39 @@ func (p *Parser) parseCompressionPolicy(stmt string, db *schema.Database) error
 
 	compressAfter = extractIntervalValue(compressAfter)
 
-	var ht *schema.Hypertable
+	scheduleInterval := ""
+	if val, ok := call.named["schedule_interval"]; ok {
+		scheduleInterval = val
+	} else if len(call.positional) > 3 {
+		scheduleInterval = call.positional[3]
+	}
 
-	for i := range db.Hypertables {
-		if db.Hypertables[i].Schema == tableSchema && db.Hypertables[i].TableName == tableName {
-			ht = &db.Hypertables[i]
-			break
-		}
+	initialStart := ""
+	if val, ok := call.named["initial_start"]; ok {
+		initialStart = val
+	} else if len(call.positional) > 4 {
+		initialStart = call.positional[4]
+	}
+
+	policy := &schema.CompressionPolicy{
+		HypertableSchema: tableSchema,
+		HypertableName:   tableName,
+		CompressAfter:    compressAfter,
+		ScheduleInterval: extractIntervalValue(scheduleInterval),
+		InitialStart:     strings.TrimSpace(initialStart),
 	}
 
+	ht := findHypertable(db, tableSchema, tableName)
 	if ht == nil {
+		// Continuous aggregates are compressed through their materialization
+		// hypertable but are named by their view.
+		if cagg := findContinuousAggregate(db, tableSchema, tableName); cagg != nil {
+			cagg.CompressionEnabled = true
+			cagg.CompressionPolicy = policy
+
+			return nil
+		}
+
 		return fmt.Errorf("hypertable %s.%s not found", tableSchema, tableName)
 	}
 
@@ -138,7 +276,62 @@ func (p *Parser) parseCompressionPolicy(stmt string, db *schema.Database) error
 		ht.CompressionSettings = &schema.CompressionSettings{}
 	}
 
-	ht.CompressionSettings.ChunkTimeInterval = compressAfter
+	ht.CompressionPolicy = policy
+
+	return nil
+}
+
+func (p *Parser) parseRemoveCompressionPolicy(stmt string, db *schema.Database) error {
+	call, err := parseTimescaleCall(stmt)
+	if err != nil {
+		return err
+	}
+
+	if call.name != "remove_compression_policy" {
+		return NewParseError("unexpected TimescaleDB function")
+	}
+
+	if len(call.positional) < 1 {
+		return NewParseError("remove_compression_policy requires hypertable name")
+	}
+
+	tableSchema, tableName := p.splitSchemaTable(unquote(call.positional[0]))
+
+	ht := findHypertable(db, tableSchema, tableName)
+	if ht == nil {
+		if cagg := findContinuousAggregate(db, tableSchema, tableName); cagg != nil {
+			cagg.CompressionPolicy = nil
+			return nil
+		}
+
+		return fmt.Errorf("hypertable %s.%s not found", tableSchema, tableName)
+	}
+
+	ht.CompressionPolicy = nil
+
+	return nil
+}
+
+func findHypertable(db *schema.Database, tableSchema, tableName string) *schema.Hypertable {
+	for i := range db.Hypertables {
+		if db.Hypertables[i].Schema == tableSchema && db.Hypertables[i].TableName == tableName {
+			return &db.Hypertables[i]
+		}
+	}
+
+	return nil
+}
+
+func findContinuousAggregate(
+	db *schema.Database,
+	viewSchema, viewName string,
+) *schema.ContinuousAggregate {
+	for i := range db.ContinuousAggregates {
+		if db.ContinuousAggregates[i].Schema == viewSchema &&
+			db.ContinuousAggregates[i].ViewName == viewName {
+			return &db.ContinuousAggregates[i]
+		}
+	}
 
 	return nil
 }
@@ -229,16 +422,10 @@ func (p *Parser) parseContinuousAggregatePolicy(stmt string, db *schema.Database
 		scheduleInterval = call.positional[3]
 	}
 
-	var cagg *schema.ContinuousAggregate
-
-	for i := range db.ContinuousAggregates {
-		if db.ContinuousAggregates[i].Schema == caggSchema &&
-			db.ContinuousAggregates[i].ViewName == caggName {
-			cagg = &db.ContinuousAggregates[i]
-			break
-		}
-	}
+	// initial_start follows if_not_exists, so it is only read by name.
+	initialStart := call.named["initial_start"]
 
+	cagg := findContinuousAggregate(db, caggSchema, caggName)
 	if cagg == nil {
 		return fmt.Errorf("continuous aggregate %s.%s not found", caggSchema, caggName)
 	}
@@ -247,6 +434,7 @@ func (p *Parser) parseContinuousAggregatePolicy(stmt string, db *schema.Database
 		StartOffset:      extractIntervalValue(startOffset),
 		EndOffset:        extractIntervalValue(endOffset),
 		ScheduleInterval: extractIntervalValue(scheduleInterval),
+		InitialStart:     strings.TrimSpace(initialStart),
 	}
 
 	return nil
@@ -303,17 +491,43 @@ func parseTimescaleByRange(arg string) (string, string, error) {
 	return column, interval, nil
 }
 
-func parseTimescaleByHash(arg string) (string, error) {
+func parseTimescaleByHash(arg string) (string, int, error) {
 	call, err := parseInlineCall(arg, "BY_HASH")
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	if len(call.positional) == 0 {
-		return "", NewParseError("by_hash requires a column name")
+		return "", 0, NewParseError("by_hash requires a column name")
+	}
+
+	partitions := ""
+	if len(call.positional) > 1 {
+		partitions = call.positional[1]
+	} else if val, ok := call.named["number_partitions"]; ok {
+		partitions = val
+	}
+
+	numPartitions, err := parsePartitionCount(partitions)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return unquote(strings.TrimSpace(call.positional[0])), numPartitions, nil
+}
+
+func parsePartitionCount(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, NewParseError("invalid number_partitions: " + value)
 	}
 
-	return unquote(strings.TrimSpace(call.positional[0])), nil
+	return count, nil
 }
 
 func parseInlineCall(literal, expected string) (*timescaleCall, error) {
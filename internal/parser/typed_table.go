@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/accented-ai/pgtofu/internal/diag"
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+var typedTableOfRe = regexp.MustCompile(
+	`(?i)^\s+OF\s+(` + qualifiedNamePattern + `)`,
+)
+
+// columnOptionsRe matches an element of a typed table's column list that sets
+// options of one of the type's columns: the column name, optionally followed
+// by WITH OPTIONS, and then column constraints or nothing. A name followed by
+// a type is a local column instead.
+var columnOptionsRe = regexp.MustCompile(
+	`(?is)^\s*([a-zA-Z_][a-zA-Z0-9_]*|"[^"]*")(\s+WITH\s+OPTIONS\b)?\s*` +
+		`(?:$|(?:NOT|NULL|DEFAULT|PRIMARY|UNIQUE|CHECK|REFERENCES|CONSTRAINT|GENERATED|COLLATE)\b)`,
+)
+
+// typedTable names a table created OF a composite type. Its columns come
+// from the type's attributes, which are only known once every file is parsed.
+type typedTable struct {
+	schemaName string
+	tableName  string
+}
+
+// parseTypedTableOf reads the OF clause that follows the table name of a
+// CREATE TABLE. It returns the qualified type name and the rest of the
+// statement, or an empty type name for a table that is not typed.
+func (p *Parser) parseTypedTableOf(rest string) (string, string) {
+	matches := typedTableOfRe.FindStringSubmatch(rest)
+	if matches == nil {
+		return "", rest
+	}
+
+	schemaName, typeName := p.splitSchemaTable(matches[1])
+
+	return schema.QualifiedName(schemaName, typeName), rest[len(matches[0]):]
+}
+
+// parseTypeAttributes reads the attributes of CREATE TYPE ... AS (...). Each
+// is read as a column definition, which normalizes its type the way a
+// column's is.
+func (p *Parser) parseTypeAttributes(content string) []schema.TypeAttribute {
+	var attributes []schema.TypeAttribute
+
+	for _, part := range splitTableDefinition(stripComments(content)) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		col, _, err := p.parseColumn(part, len(attributes)+1)
+		if err != nil {
+			p.addWarning(
+				diag.CodeSkippedDefinition, 0, "", fmt.Sprintf("parsing attribute: %v", err),
+			)
+
+			continue
+		}
+
+		attributes = append(attributes, schema.TypeAttribute{
+			Name:     col.Name,
+			DataType: col.FullDataType(),
+		})
+	}
+
+	return attributes
+}
+
+// parseTypedTableContent reads the column list of a typed table: options of
+// the type's columns, table constraints and, although PostgreSQL rejects
+// them, local columns. A column given options has no type of its own, and is
+// returned with an empty DataType until deriveTypedTableColumns merges it
+// into the type's attributes.
+func (p *Parser) parseTypedTableContent(content string) ([]schema.Column, []schema.Constraint) {
+	parts := splitTableDefinition(stripComments(content))
+	options := make(map[string]bool)
+
+	for i, part := range parts {
+		if isConstraint(part) {
+			continue
+		}
+
+		loc := columnOptionsRe.FindStringSubmatchIndex(part)
+		if loc == nil {
+			continue
+		}
+
+		name := part[loc[2]:loc[3]]
+		options[p.normalizeIdent(name)] = true
+
+		rest := part[loc[3]:]
+		if loc[4] >= 0 {
+			rest = part[loc[5]:]
+		}
+
+		// The placeholder type lets the options be read as a column
+		// definition.
+		parts[i] = name + " TEXT " + rest
+	}
+
+	columns, constraints := p.parseTableContent(strings.Join(parts, ",\n"))
+
+	for i := range columns {
+		if options[columns[i].Name] {
+			columns[i].DataType = ""
+		}
+	}
+
+	return columns, constraints
+}
+
+// deriveTypedTableColumns replaces the columns of a typed table with the
+// attributes of its type, carrying over the options declared for them. Local
+// columns, and options of columns the type does not have, follow the
+// attributes for schema.ValidateTypedTables to report.
+func (p *Parser) deriveTypedTableColumns(db *schema.Database, typed typedTable) {
+	table := db.GetTable(typed.schemaName, typed.tableName)
+	if table == nil || table.TypedOf == "" {
+		return
+	}
+
+	ct := db.CompositeTypeOf(table)
+	if ct == nil {
+		p.addWarning(diag.CodeObjectNotFound, 0, table.QualifiedName(), fmt.Sprintf(
+			"composite type %s not found for typed table %s", table.TypedOf, table.QualifiedName()))
+
+		return
+	}
+
+	declared := make(map[string]*schema.Column, len(table.Columns))
+	for i := range table.Columns {
+		if table.Columns[i].DataType == "" {
+			declared[table.Columns[i].Name] = &table.Columns[i]
+		}
+	}
+
+	columns := make([]schema.Column, 0, len(ct.Attributes)+len(table.Columns))
+	merged := make(map[string]bool, len(declared))
+
+	for _, attr := range ct.Attributes {
+		quoted := `"` + strings.ReplaceAll(attr.Name, `"`, `""`) + `"`
+
+		col, _, err := p.parseColumn(quoted+" "+attr.DataType, len(columns)+1)
+		if err != nil {
+			p.addError(0, fmt.Sprintf("typed table %s: attribute %s of %s: %v",
+				table.QualifiedName(), attr.Name, ct.QualifiedName(), err), "")
+
+			continue
+		}
+
+		if option := declared[col.Name]; option != nil {
+			col.IsNullable = option.IsNullable
+			col.Default = option.Default
+			col.Source = option.Source
+			merged[col.Name] = true
+		}
+
+		columns = append(columns, col)
+	}
+
+	for _, col := range table.Columns {
+		if col.DataType == "" && merged[col.Name] {
+			continue
+		}
+
+		col.Position = len(columns) + 1
+		columns = append(columns, col)
+	}
+
+	table.Columns = columns
+}
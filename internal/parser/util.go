@@ -139,3 +139,24 @@ func normalizeWhitespace(s string) string {
 
 	return strings.TrimSpace(s)
 }
+
+// hasTopLevelKeyword reports whether keyword appears as a bare word outside
+// any parentheses, ignoring strings, quoted identifiers and comments.
+func hasTopLevelKeyword(tokens []Token, keyword string) bool {
+	depth := 0
+
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			depth--
+		case TokenKeyword, TokenIdentifier:
+			if depth == 0 && strings.EqualFold(token.Literal, keyword) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -1,31 +1,46 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
+	"github.com/accented-ai/pgtofu/internal/diag"
 	"github.com/accented-ai/pgtofu/internal/schema"
 )
 
+var caCompressOptionPattern = regexp.MustCompile(
+	`(?i)timescaledb\.(?:compress|enable_columnstore)\b\s*(?:=\s*'?([a-z]+)'?)?`,
+)
+
 type viewStatement struct {
 	schemaName   string
 	viewName     string
 	definition   string
 	withClause   string
+	checkOption  string
 	withData     bool
 	materialized bool
 }
 
-func (p *Parser) parseCreateView(stmt string, db *schema.Database) error {
+func (p *Parser) parseCreateView(stmt string, line int, db *schema.Database) error {
 	parsed, err := p.parseViewStatement(stmt, false)
 	if err != nil || parsed == nil {
 		return err
 	}
 
+	options, checkOption := parseViewOptions(parsed.withClause)
+	if parsed.checkOption != "" {
+		checkOption = parsed.checkOption
+	}
+
 	view := schema.View{
-		Schema:     parsed.schemaName,
-		Name:       parsed.viewName,
-		Definition: parsed.definition,
+		Schema:      parsed.schemaName,
+		Name:        parsed.viewName,
+		Definition:  parsed.definition,
+		CheckOption: checkOption,
+		Options:     options,
+		Source:      p.sourceAt(line),
 	}
 
 	for i, existing := range db.Views {
@@ -40,7 +55,11 @@ func (p *Parser) parseCreateView(stmt string, db *schema.Database) error {
 	return nil
 }
 
-func (p *Parser) parseCreateMaterializedView(stmt string, db *schema.Database) error {
+func (p *Parser) parseCreateMaterializedView(
+	stmt string,
+	line int,
+	db *schema.Database,
+) error {
 	parsed, err := p.parseViewStatement(stmt, true)
 	if err != nil || parsed == nil {
 		return err
@@ -78,10 +97,12 @@ func (p *Parser) parseCreateMaterializedView(stmt string, db *schema.Database) e
 		db.ContinuousAggregates = append(db.ContinuousAggregates, cagg)
 	} else {
 		mv := schema.MaterializedView{
-			Schema:     parsed.schemaName,
-			Name:       parsed.viewName,
-			Definition: definition,
-			WithData:   parsed.withData,
+			Schema:        parsed.schemaName,
+			Name:          parsed.viewName,
+			Definition:    definition,
+			WithData:      parsed.withData,
+			StorageParams: parseStorageParamsLiteral(parsed.withClause),
+			Source:        p.sourceAt(line),
 		}
 
 		for i, existing := range db.MaterializedViews {
@@ -97,6 +118,157 @@ func (p *Parser) parseCreateMaterializedView(stmt string, db *schema.Database) e
 	return nil
 }
 
+// parseAlterMaterializedView records the storage parameters set or reset on a
+// materialized view, and ALTER MATERIALIZED VIEW ... SET
+// (timescaledb.compress) on a continuous aggregate. Every other form is
+// skipped with a warning.
+func (p *Parser) parseAlterMaterializedView(stmt Statement, db *schema.Database) {
+	sql := stmt.NormalizedSQL()
+
+	if tokens, err := NewLexer(sql).Tokenize(); err == nil {
+		idx := nextNonCommentIndex(tokens, 0)    // ALTER
+		idx = nextNonCommentIndex(tokens, idx+1) // MATERIALIZED
+		idx = nextNonCommentIndex(tokens, idx+1) // VIEW
+		idx = nextNonCommentIndex(tokens, idx+1)
+
+		if upperLiteral(tokens, idx) == "IF" {
+			idx = nextNonCommentIndex(tokens, nextNonCommentIndex(tokens, idx+1)+1)
+		}
+
+		name, idx := readQualifiedName(tokens, idx)
+		idx = nextNonCommentIndex(tokens, idx)
+		action := upperLiteral(tokens, idx)
+
+		if name != "" && (action == "SET" || action == "RESET") {
+			viewSchema, viewName := p.splitSchemaTable(name)
+			if mv := db.GetMaterializedView(viewSchema, viewName); mv != nil &&
+				setMaterializedViewStorage(mv, sql, tokens, idx) {
+				return
+			}
+		}
+
+		match := caCompressOptionPattern.FindStringSubmatch(sql)
+		if name != "" && action == "SET" && match != nil {
+			viewSchema, viewName := p.splitSchemaTable(name)
+
+			cagg := findContinuousAggregate(db, viewSchema, viewName)
+			if cagg == nil {
+				p.addWarning(
+					diag.CodeObjectNotFound,
+					stmt.Line,
+					viewSchema+"."+viewName,
+					"continuous aggregate not found for compression: "+viewSchema+"."+viewName,
+				)
+
+				return
+			}
+
+			switch strings.ToLower(match[1]) {
+			case "false", "off":
+				cagg.CompressionEnabled = false
+				cagg.CompressionPolicy = nil
+			default:
+				cagg.CompressionEnabled = true
+			}
+
+			return
+		}
+	}
+
+	p.addWarning(
+		diag.CodeSkippedStatement,
+		stmt.Line,
+		"",
+		"unsupported statement: "+truncate(sql, 50),
+	)
+}
+
+// ignoreRefresh skips a REFRESH MATERIALIZED VIEW statement. A run reports
+// all of them in a single informational warning, updated as more are found.
+func (p *Parser) ignoreRefresh(stmt Statement) {
+	tokens := stmt.Tokens
+	if len(tokens) == 0 {
+		tokens, _ = NewLexer(stmt.NormalizedSQL()).Tokenize()
+	}
+
+	idx := nextNonCommentIndex(tokens, 0)    // REFRESH
+	idx = nextNonCommentIndex(tokens, idx+1) // MATERIALIZED
+	idx = nextNonCommentIndex(tokens, idx+1) // VIEW
+	idx = nextNonCommentIndex(tokens, idx+1)
+
+	if upperLiteral(tokens, idx) == "CONCURRENTLY" {
+		idx = nextNonCommentIndex(tokens, idx+1)
+	}
+
+	view := "unknown"
+	if name, _ := readQualifiedName(tokens, idx); name != "" {
+		viewSchema, viewName := p.splitSchemaTable(name)
+		view = schema.QualifiedName(viewSchema, viewName)
+	}
+
+	ctx := p.ensureContext()
+	ctx.refreshed = append(ctx.refreshed, view)
+
+	message := fmt.Sprintf("ignored %d REFRESH MATERIALIZED VIEW statement(s) (%s): "+
+		"refreshing is operational, not part of the schema; schedule it with a "+
+		"TimescaleDB refresh policy or pg_cron instead",
+		len(ctx.refreshed), strings.Join(ctx.refreshed, ", "))
+
+	if ctx.refreshWarning > 0 {
+		ctx.warnings[ctx.refreshWarning-1].Message = message
+		p.warnings = ctx.warnings
+
+		return
+	}
+
+	ctx.warnings = append(ctx.warnings, Warning{
+		Code:     diag.CodeOperationalStatement,
+		Severity: diag.SeverityInfo,
+		Message:  message,
+		File:     ctx.currentFile,
+		Line:     stmt.Line,
+	})
+	ctx.refreshWarning = len(ctx.warnings)
+	p.warnings = ctx.warnings
+}
+
+// setMaterializedViewStorage applies the SET (...) or RESET (...) at
+// tokens[idx] to the storage parameters of mv. It reports false for the other
+// forms of SET, such as SET SCHEMA.
+func setMaterializedViewStorage(
+	mv *schema.MaterializedView,
+	sql string,
+	tokens []Token,
+	idx int,
+) bool {
+	reset := upperLiteral(tokens, idx) == "RESET"
+
+	literal, _, err := extractParenthesizedLiteral(sql, tokens, nextNonCommentIndex(tokens, idx+1))
+	if err != nil {
+		return false
+	}
+
+	if reset {
+		for _, key := range splitByComma(literal) {
+			delete(mv.StorageParams, strings.ToLower(strings.TrimSpace(key)))
+		}
+	} else {
+		for key, value := range parseStorageParamsLiteral(literal) {
+			if mv.StorageParams == nil {
+				mv.StorageParams = make(map[string]string)
+			}
+
+			mv.StorageParams[key] = value
+		}
+	}
+
+	if len(mv.StorageParams) == 0 {
+		mv.StorageParams = nil
+	}
+
+	return true
+}
+
 func (p *Parser) parseViewStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 	stmt string,
 	materialized bool,
@@ -247,6 +419,11 @@ func (p *Parser) parseViewStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 	}
 
 	withData := true
+	checkOption := ""
+
+	if !materialized {
+		checkOption, defEnd = trailingCheckOption(tokens, defStartIdx, defEnd)
+	}
 
 	if materialized { //nolint:nestif
 		lastIdx := prevNonCommentIndex(tokens, len(tokens)-1)
@@ -283,11 +460,72 @@ func (p *Parser) parseViewStatement( //nolint:cyclop,gocognit,gocyclo,maintidx
 		viewName:     viewName,
 		definition:   definition,
 		withClause:   withClause,
+		checkOption:  checkOption,
 		withData:     withData,
 		materialized: materialized,
 	}, nil
 }
 
+// trailingCheckOption returns the check option of a view whose query, from
+// token start to byte end, ends in WITH [CASCADED | LOCAL] CHECK OPTION, and
+// the end of the query before it. CASCADED is the default.
+func trailingCheckOption(tokens []Token, start, end int) (string, int) {
+	idx := len(tokens) - 1
+	for idx >= start && (tokens[idx].Start >= end || tokens[idx].Type == TokenComment ||
+		tokens[idx].Type == TokenEOF) {
+		idx--
+	}
+
+	if idx-2 < start || upperLiteral(tokens, idx) != "OPTION" ||
+		upperLiteral(tokens, prevNonCommentIndex(tokens, idx-1)) != "CHECK" {
+		return "", end
+	}
+
+	idx = prevNonCommentIndex(tokens, prevNonCommentIndex(tokens, idx-1)-1)
+	checkOption := "CASCADED"
+
+	if word := upperLiteral(tokens, idx); word == "CASCADED" || word == "LOCAL" {
+		checkOption = word
+		idx = prevNonCommentIndex(tokens, idx-1)
+	}
+
+	if idx < start || upperLiteral(tokens, idx) != "WITH" {
+		return "", end
+	}
+
+	return checkOption, tokens[idx].Start
+}
+
+// parseViewOptions reads the options of CREATE VIEW ... WITH (...). A boolean
+// option given without a value, such as security_barrier, is true, as
+// PostgreSQL records it, and check_option is returned on its own.
+func parseViewOptions(literal string) (map[string]string, string) {
+	options := make(map[string]string)
+
+	for _, part := range splitByComma(literal) {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			value = "true"
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+
+		options[key] = unquote(strings.TrimSpace(value))
+	}
+
+	checkOption := strings.ToUpper(options["check_option"])
+	delete(options, "check_option")
+
+	if len(options) == 0 {
+		return nil, checkOption
+	}
+
+	return options, checkOption
+}
+
 func stripInlineComments(sql string) string {
 	lines := strings.Split(sql, "\n")
 
@@ -318,9 +556,8 @@ func stripInlineComments(sql string) string {
 
 func extractHypertableFromQuery(query string) (schemaName, tableName string) {
 	normalized := normalizeWhitespace(query)
-	normalized = strings.ToUpper(normalized)
 
-	fromPattern := regexp.MustCompile(`\bFROM\s+([a-zA-Z_][a-zA-Z0-9_.]*|"[^"]*"(?:\."[^"]*")?)`)
+	fromPattern := regexp.MustCompile(`(?i)\bFROM\s+(` + qualifiedNamePattern + `)`)
 	matches := fromPattern.FindStringSubmatch(normalized)
 
 	if len(matches) < 2 {
@@ -336,12 +573,8 @@ func extractHypertableFromQuery(query string) (schemaName, tableName string) {
 	if strings.Contains(tableName, ".") {
 		parts := strings.SplitN(tableName, ".", 2)
 
-		return strings.ToLower(
-				strings.Trim(parts[0], `"`),
-			), strings.ToLower(
-				strings.Trim(parts[1], `"`),
-			)
+		return schema.FoldIdentifier(parts[0]), schema.FoldIdentifier(parts[1])
 	}
 
-	return schema.DefaultSchema, strings.ToLower(strings.Trim(tableName, `"`))
+	return schema.DefaultSchema, schema.FoldIdentifier(tableName)
 }
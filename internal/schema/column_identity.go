@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Generation modes of an identity column, as written after GENERATED.
+const (
+	IdentityAlways    = "ALWAYS"
+	IdentityByDefault = "BY DEFAULT"
+)
+
+// Identity is the GENERATED ... AS IDENTITY clause of a column and the options
+// of the sequence PostgreSQL creates for it. Options left undeclared have the
+// values PostgreSQL assigns, as in Sequence, so a parsed identity compares
+// equal to the one extracted from the database it creates.
+type Identity struct {
+	// Generation is IdentityAlways or IdentityByDefault.
+	Generation string `json:"generation"`
+	StartValue int64  `json:"start_value"`
+	MinValue   int64  `json:"min_value"`
+	MaxValue   int64  `json:"max_value"`
+	Increment  int64  `json:"increment"`
+	CacheSize  int64  `json:"cache_size"`
+	IsCyclic   bool   `json:"is_cyclic"`
+}
+
+// NewIdentity returns the identity PostgreSQL gives a column of dataType
+// declared GENERATED generation AS IDENTITY with no sequence options.
+func NewIdentity(generation, dataType string) *Identity {
+	seq := &Sequence{DataType: dataType, Increment: 1, CacheSize: 1}
+	seq.MinValue, seq.MaxValue = SequenceBounds(dataType, seq.Increment)
+	seq.StartValue = seq.DefaultStart()
+
+	return IdentityFromSequence(generation, seq)
+}
+
+// IdentityFromSequence returns the identity generating values from seq.
+func IdentityFromSequence(generation string, seq *Sequence) *Identity {
+	return &Identity{
+		Generation: strings.ToUpper(generation),
+		StartValue: seq.StartValue,
+		MinValue:   seq.MinValue,
+		MaxValue:   seq.MaxValue,
+		Increment:  seq.Increment,
+		CacheSize:  seq.CacheSize,
+		IsCyclic:   seq.IsCyclic,
+	}
+}
+
+// Sequence returns the options of the identity's sequence for a column of
+// dataType. The sequence has no name; PostgreSQL chooses it.
+func (i *Identity) Sequence(dataType string) *Sequence {
+	return &Sequence{
+		DataType:   dataType,
+		StartValue: i.StartValue,
+		MinValue:   i.MinValue,
+		MaxValue:   i.MaxValue,
+		Increment:  i.Increment,
+		CacheSize:  i.CacheSize,
+		IsCyclic:   i.IsCyclic,
+	}
+}
+
+// UnmarshalJSON also reads the is_identity and identity_generation fields that
+// schemas extracted before Identity recorded the sequence options carry. Their
+// options are taken to be the defaults.
+func (c *Column) UnmarshalJSON(data []byte) error {
+	type alias Column
+
+	legacy := struct {
+		*alias
+
+		IsIdentity         bool   `json:"is_identity"`
+		IdentityGeneration string `json:"identity_generation"`
+	}{alias: (*alias)(c)}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if c.Identity == nil && legacy.IsIdentity {
+		c.Identity = NewIdentity(legacy.IdentityGeneration, strings.ToLower(c.DataType))
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package schema
+
+import "strings"
+
+// Storage strategies of a column, as written in SET STORAGE.
+const (
+	StoragePlain    = "plain"
+	StorageExternal = "external"
+	StorageExtended = "extended"
+	StorageMain     = "main"
+)
+
+// Compression methods of a column, as written in SET COMPRESSION.
+const (
+	CompressionPGLZ = "pglz"
+	CompressionLZ4  = "lz4"
+)
+
+// plainStorageTypes are the fixed-length built-in types, which PostgreSQL
+// never moves out of line or compresses.
+//
+//nolint:gochecknoglobals
+var plainStorageTypes = map[string]bool{
+	"smallint": true, "integer": true, "int": true, "bigint": true,
+	"int2": true, "int4": true, "int8": true,
+	"real": true, "float4": true, "double precision": true, "float8": true, "float": true,
+	"boolean": true, "bool": true, "date": true, "uuid": true, "money": true, "oid": true,
+	"serial": true, "bigserial": true, "smallserial": true,
+	"point": true, "macaddr": true, "macaddr8": true,
+}
+
+// IsStorage reports whether value names a storage strategy.
+func IsStorage(value string) bool {
+	switch strings.ToLower(value) {
+	case StoragePlain, StorageExternal, StorageExtended, StorageMain:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCompression reports whether value names a compression method.
+func IsCompression(value string) bool {
+	switch strings.ToLower(value) {
+	case CompressionPGLZ, CompressionLZ4:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultStorage returns the storage strategy PostgreSQL gives a column of
+// col's type: plain for fixed-length types, main for numeric and network
+// addresses, and extended for everything else, including arrays.
+func DefaultStorage(col *Column) string {
+	if col.IsArray {
+		return StorageExtended
+	}
+
+	dataType := strings.ToLower(strings.TrimSpace(col.DataType))
+	if name, _, found := strings.Cut(dataType, "("); found {
+		dataType = strings.TrimSpace(name)
+	}
+
+	switch {
+	case plainStorageTypes[dataType], IsTimeType(dataType):
+		return StoragePlain
+	case dataType == "numeric", dataType == "decimal", dataType == "inet", dataType == "cidr":
+		return StorageMain
+	default:
+		return StorageExtended
+	}
+}
+
+// EffectiveStorage returns the storage strategy of the column, which is the
+// default of its type unless SET STORAGE chose another.
+func (c *Column) EffectiveStorage() string {
+	if c.Storage != "" {
+		return strings.ToLower(c.Storage)
+	}
+
+	return DefaultStorage(c)
+}
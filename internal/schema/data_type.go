@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"maps"
+	"strings"
+)
+
+// dataTypeAliases maps the alternate names PostgreSQL documents for its
+// built-in types, lowercase, to the spelling data types are compared in.
+// The serial types are the integer types of the columns they declare, and
+// bpchar is the name the catalog gives character.
+var dataTypeAliases = map[string]string{ //nolint:gochecknoglobals
+	"int":               "integer",
+	"int2":              "smallint",
+	"int4":              "integer",
+	"int8":              "bigint",
+	"smallserial":       "smallint",
+	"serial2":           "smallint",
+	"serial":            "integer",
+	"serial4":           "integer",
+	"bigserial":         "bigint",
+	"serial8":           "bigint",
+	"float":             "double precision",
+	"float4":            "real",
+	"float8":            "double precision",
+	"bool":              "boolean",
+	"character varying": "varchar",
+	"character":         "char",
+	"bpchar":            "char",
+	"varbit":            "bit varying",
+	"decimal":           "numeric",
+	"timestamp":         "timestamp without time zone",
+	"timestamptz":       "timestamp with time zone",
+	"time":              "time without time zone",
+	"timetz":            "time with time zone",
+}
+
+// DataTypeAliases returns the aliases CanonicalDataType resolves, from each
+// alias to its canonical spelling.
+func DataTypeAliases() map[string]string {
+	return maps.Clone(dataTypeAliases)
+}
+
+// CanonicalDataType returns the spelling of dataType that every alias of the
+// same type shares: lowercase, with single spaces, without the pg_catalog
+// schema and with aliases resolved, keeping any type modifier, such as
+// "INT4" as "integer" and "Character Varying(20)" as "varchar(20)".
+func CanonicalDataType(dataType string) string {
+	dt := strings.Join(strings.Fields(strings.ToLower(dataType)), " ")
+	dt = strings.TrimPrefix(dt, "pg_catalog.")
+
+	if canonical, ok := dataTypeAliases[dt]; ok {
+		return canonical
+	}
+
+	if name, modifier, ok := strings.Cut(dt, "("); ok {
+		if canonical, ok := dataTypeAliases[strings.TrimSpace(name)]; ok {
+			return canonical + "(" + modifier
+		}
+	}
+
+	return dt
+}
+
+// DataTypeMapper resolves data types to their canonical spelling with the
+// built-in aliases and extra equivalences, such as those of extension types.
+type DataTypeMapper struct {
+	equivalences map[string]string
+}
+
+// NewDataTypeMapper returns a mapper that takes each key of equivalences for
+// the type its value names, after resolving the built-in aliases of both, so
+// {"public.citext": "citext"} compares the qualified name of the extension
+// type equal to the bare one.
+func NewDataTypeMapper(equivalences map[string]string) *DataTypeMapper {
+	mapper := &DataTypeMapper{equivalences: make(map[string]string, len(equivalences))}
+
+	for alias, canonical := range equivalences {
+		mapper.equivalences[CanonicalDataType(alias)] = CanonicalDataType(canonical)
+	}
+
+	return mapper
+}
+
+// Canonical returns the canonical spelling of dataType. A nil mapper applies
+// the built-in aliases alone.
+func (m *DataTypeMapper) Canonical(dataType string) string {
+	dt := CanonicalDataType(dataType)
+	if m == nil {
+		return dt
+	}
+
+	if canonical, ok := m.equivalences[dt]; ok {
+		return canonical
+	}
+
+	return dt
+}
@@ -0,0 +1,546 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EnumLiteralError is a default or CHECK constraint of a column of an enum
+// type that names a value the enum, as the same schema declares it, does not
+// have. PostgreSQL only rejects it when the migration runs.
+type EnumLiteralError struct {
+	// Object is the qualified name of the table.
+	Object string
+	Column string
+	// Context is where the literal appears: "default", or "check" and the
+	// constraint name.
+	Context string
+	Literal string
+	// Enum is the qualified name of the column's type, and Values the values
+	// it declares.
+	Enum   string
+	Values []string
+}
+
+func (e *EnumLiteralError) Error() string {
+	values := make([]string, len(e.Values))
+	for i, value := range e.Values {
+		values[i] = "'" + value + "'"
+	}
+
+	return fmt.Sprintf("%s.%s: %s uses %q, which is not a value of %s (%s)",
+		e.Object, e.Column, e.Context, e.Literal, e.Enum, strings.Join(values, ", "))
+}
+
+// ValidateEnumLiterals checks the defaults and CHECK constraints of every
+// column of db whose type is an enum that db declares. A default must be a
+// value of the enum when it is a quoted literal, optionally cast to the enum.
+// A CHECK is searched for the column compared with =, <> or != to a literal,
+// and for IN lists and = ANY (ARRAY[...]) of literals; any literal there must
+// be a value too. Literals in other expressions, such as a function call or a
+// cast of the column to text, are left alone, as are enums whose values the
+// check cannot read back.
+func ValidateEnumLiterals(db *Database) []*EnumLiteralError {
+	enums := make(map[string]*CustomType)
+
+	for i := range db.CustomTypes {
+		ct := &db.CustomTypes[i]
+		if ct.Type == "enum" && len(ct.Values) > 0 {
+			enums[enumKey(ct.Schema, ct.Name)] = ct
+		}
+	}
+
+	if len(enums) == 0 {
+		return nil
+	}
+
+	var errs []*EnumLiteralError
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+
+		for j := range table.Columns {
+			col := &table.Columns[j]
+			if col.IsArray {
+				continue
+			}
+
+			enum := enums[typeKey(col.DataType)]
+			if enum == nil {
+				continue
+			}
+
+			report := func(context, literal string) {
+				if strings.Contains(literal, "'") || slices.Contains(enum.Values, literal) {
+					return
+				}
+
+				errs = append(errs, &EnumLiteralError{
+					Object:  table.QualifiedName(),
+					Column:  col.Name,
+					Context: context,
+					Literal: literal,
+					Enum:    enum.QualifiedName(),
+					Values:  enum.Values,
+				})
+			}
+
+			if literal, ok := enumDefaultLiteral(col.Default, enum); ok {
+				report("default", literal)
+			}
+
+			for k := range table.Constraints {
+				c := &table.Constraints[k]
+				if !strings.EqualFold(c.Type, ConstraintCheck) {
+					continue
+				}
+
+				expression := c.CheckExpression
+				if expression == "" {
+					expression = c.Definition
+				}
+
+				literals := checkedEnumLiterals(expression, table.Name, col.Name, enum)
+				for _, literal := range literals {
+					report("check "+c.Name, literal)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+func enumKey(schemaName, name string) string {
+	return NormalizeSchemaName(schemaName) + "." + NormalizeIdentifier(name)
+}
+
+// typeKey returns the enumKey of a column type. An unqualified type is looked
+// up in the default schema, where the default search path finds it.
+func typeKey(dataType string) string {
+	tokens, ok := tokenizeExpression(dataType)
+	if !ok {
+		return ""
+	}
+
+	end, name, ok := readName(tokens, 0)
+	if !ok || end != len(tokens) || len(name) > 2 {
+		return ""
+	}
+
+	if len(name) == 1 {
+		return enumKey("", name[0])
+	}
+
+	return enumKey(name[0], name[1])
+}
+
+// enumDefaultLiteral returns the literal of a default that is a quoted
+// string, optionally cast to the enum.
+func enumDefaultLiteral(value string, enum *CustomType) (string, bool) {
+	tokens, ok := tokenizeExpression(value)
+	if !ok || len(tokens) == 0 || tokens[0].kind != exprString {
+		return "", false
+	}
+
+	end, ok := skipEnumCast(tokens, 1, enum)
+	if !ok || end != len(tokens) {
+		return "", false
+	}
+
+	return tokens[0].text, true
+}
+
+// checkedEnumLiterals returns the literals a CHECK expression compares the
+// column with, in the forms ValidateEnumLiterals describes.
+func checkedEnumLiterals(expression, table, column string, enum *CustomType) []string {
+	tokens, ok := tokenizeExpression(expression)
+	if !ok {
+		return nil
+	}
+
+	var literals []string
+
+	for i := 0; i < len(tokens); i++ {
+		if !startsOperand(tokens, i) {
+			continue
+		}
+
+		if found, end := columnComparison(tokens, i, table, column, enum); end > 0 {
+			literals = append(literals, found...)
+			i = end - 1
+		} else if literal, end := reversedComparison(tokens, i, table, column, enum); end > 0 {
+			literals = append(literals, literal)
+			i = end - 1
+		}
+	}
+
+	return literals
+}
+
+// columnComparison reads the column, optionally cast to the enum, compared
+// with literals from tokens[i], and returns the literals with the offset
+// after the comparison. The offset is 0 when tokens[i] starts no such
+// comparison.
+func columnComparison(
+	tokens exprTokens,
+	i int,
+	table, column string,
+	enum *CustomType,
+) ([]string, int) {
+	j, ok := readColumn(tokens, i, table, column)
+	if !ok {
+		return nil, 0
+	}
+
+	if j, ok = skipEnumCast(tokens, j, enum); !ok || j >= len(tokens) {
+		return nil, 0
+	}
+
+	var (
+		literals []string
+		end      int
+	)
+
+	switch tok := tokens[j]; {
+	case tok.isOperator("=") && tokens.at(j+1).isWord("any"):
+		if !tokens.at(j+2).isPunct("(") || !tokens.at(j+3).isWord("array") ||
+			!tokens.at(j+4).isPunct("[") {
+			return nil, 0
+		}
+
+		literals, end = readLiteralList(tokens, j+5, "]", enum)
+		if end == 0 || !tokens.at(end).isPunct(")") {
+			return nil, 0
+		}
+
+		end++
+	case tok.isOperator("=") || tok.isOperator("<>") || tok.isOperator("!="):
+		if tokens.at(j+1).kind != exprString {
+			return nil, 0
+		}
+
+		if end, ok = skipEnumCast(tokens, j+2, enum); !ok {
+			return nil, 0
+		}
+
+		literals = []string{tokens[j+1].text}
+	case tok.isWord("in") || tok.isWord("not") && tokens.at(j+1).isWord("in"):
+		if tok.isWord("not") {
+			j++
+		}
+
+		if !tokens.at(j + 1).isPunct("(") {
+			return nil, 0
+		}
+
+		if literals, end = readLiteralList(tokens, j+2, ")", enum); end == 0 {
+			return nil, 0
+		}
+	default:
+		return nil, 0
+	}
+
+	if !endsOperand(tokens, end) {
+		return nil, 0
+	}
+
+	return literals, end
+}
+
+// reversedComparison reads a literal compared with the column, as in
+// 'active' = status, from tokens[i].
+func reversedComparison(
+	tokens exprTokens,
+	i int,
+	table, column string,
+	enum *CustomType,
+) (string, int) {
+	if tokens[i].kind != exprString {
+		return "", 0
+	}
+
+	j, ok := skipEnumCast(tokens, i+1, enum)
+	if !ok {
+		return "", 0
+	}
+
+	op := tokens.at(j)
+	if !op.isOperator("=") && !op.isOperator("<>") && !op.isOperator("!=") {
+		return "", 0
+	}
+
+	end, ok := readColumn(tokens, j+1, table, column)
+	if !ok {
+		return "", 0
+	}
+
+	if end, ok = skipEnumCast(tokens, end, enum); !ok || !endsOperand(tokens, end) {
+		return "", 0
+	}
+
+	return tokens[i].text, end
+}
+
+// readLiteralList reads literals, each optionally cast to the enum,
+// separated by commas up to the closing punctuation, and returns them with
+// the offset after it.
+func readLiteralList(tokens exprTokens, i int, closing string, enum *CustomType) ([]string, int) {
+	var literals []string
+
+	for {
+		if tokens.at(i).kind != exprString {
+			return nil, 0
+		}
+
+		literals = append(literals, tokens[i].text)
+
+		end, ok := skipEnumCast(tokens, i+1, enum)
+		if !ok {
+			return nil, 0
+		}
+
+		switch next := tokens.at(end); {
+		case next.isPunct(","):
+			i = end + 1
+		case next.isPunct(closing):
+			return literals, end + 1
+		default:
+			return nil, 0
+		}
+	}
+}
+
+// readColumn reads the column, bare or qualified by its table, from
+// tokens[i] and returns the offset after it.
+func readColumn(tokens exprTokens, i int, table, column string) (int, bool) {
+	end, name, ok := readName(tokens, i)
+	if !ok {
+		return 0, false
+	}
+
+	switch len(name) {
+	case 1:
+		ok = name[0] == NormalizeIdentifier(column)
+	case 2:
+		ok = name[0] == NormalizeIdentifier(table) && name[1] == NormalizeIdentifier(column)
+	default:
+		ok = false
+	}
+
+	if !ok || tokens.at(end).isPunct("(") {
+		return 0, false
+	}
+
+	return end, true
+}
+
+// skipEnumCast skips a cast to the enum at tokens[i]. It fails on a cast to
+// any other type, which changes what the literal or column is compared as.
+func skipEnumCast(tokens exprTokens, i int, enum *CustomType) (int, bool) {
+	if !tokens.at(i).isOperator("::") {
+		return i, true
+	}
+
+	end, name, ok := readName(tokens, i+1)
+	if !ok {
+		return 0, false
+	}
+
+	switch len(name) {
+	case 1:
+		ok = name[0] == NormalizeIdentifier(enum.Name)
+	case 2:
+		ok = enumKey(name[0], name[1]) == enumKey(enum.Schema, enum.Name)
+	default:
+		ok = false
+	}
+
+	return end, ok
+}
+
+// readName reads a name of dot-separated identifiers from tokens[i].
+func readName(tokens exprTokens, i int) (int, []string, bool) {
+	if tokens.at(i).kind != exprName {
+		return 0, nil, false
+	}
+
+	name := []string{tokens[i].text}
+
+	for tokens.at(i+1).isPunct(".") && tokens.at(i+2).kind == exprName {
+		name = append(name, tokens[i+2].text)
+		i += 2
+	}
+
+	return i + 1, name, true
+}
+
+// startsOperand reports whether tokens[i] starts an operand of AND, OR or
+// NOT, so a comparison read from it is not part of a larger expression.
+func startsOperand(tokens exprTokens, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev := tokens[i-1]
+
+	return prev.isPunct("(") || prev.isWord("and") || prev.isWord("or") ||
+		prev.isWord("not") || prev.isWord("check")
+}
+
+// endsOperand reports whether a comparison ending before tokens[i] is a whole
+// operand of AND or OR.
+func endsOperand(tokens exprTokens, i int) bool {
+	if i >= len(tokens) {
+		return true
+	}
+
+	next := tokens[i]
+
+	return next.isPunct(")") || next.isWord("and") || next.isWord("or")
+}
+
+type exprTokenKind int
+
+const (
+	// exprName is an identifier or keyword, lowercased unless quoted.
+	exprName exprTokenKind = iota
+	// exprString is a quoted string, with its quotes removed and doubled
+	// quotes undone.
+	exprString
+	exprOperator
+	exprPunct
+	exprNumber
+)
+
+type exprToken struct {
+	kind   exprTokenKind
+	text   string
+	quoted bool
+}
+
+type exprTokens []exprToken
+
+func (t exprTokens) at(i int) exprToken {
+	if i < 0 || i >= len(t) {
+		return exprToken{kind: exprPunct}
+	}
+
+	return t[i]
+}
+
+func (t exprToken) isPunct(text string) bool {
+	return t.kind == exprPunct && t.text == text
+}
+
+func (t exprToken) isOperator(text string) bool {
+	return t.kind == exprOperator && t.text == text
+}
+
+func (t exprToken) isWord(word string) bool {
+	return t.kind == exprName && !t.quoted && t.text == word
+}
+
+const operatorBytes = "+-*/<>=~!@#%^&|`?"
+
+// tokenizeExpression splits a SQL expression into tokens. It fails on what
+// it cannot read with confidence: dollar quotes, escape strings, other
+// prefixed strings and unterminated quotes.
+func tokenizeExpression(sql string) (exprTokens, bool) {
+	var tokens exprTokens
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			i = skipPast(sql, i+2, "\n")
+		case strings.HasPrefix(sql[i:], "/*"):
+			i = skipPast(sql, i+2, "*/")
+		case c == '\'':
+			if i > 0 && isIdentifierByte(sql[i-1]) {
+				return nil, false
+			}
+
+			text, end, ok := readQuoted(sql, i, '\'')
+			if !ok {
+				return nil, false
+			}
+
+			tokens = append(tokens, exprToken{kind: exprString, text: text})
+			i = end
+		case c == '"':
+			text, end, ok := readQuoted(sql, i, '"')
+			if !ok {
+				return nil, false
+			}
+
+			tokens = append(tokens, exprToken{kind: exprName, text: text, quoted: true})
+			i = end
+		case c == '$':
+			return nil, false
+		case c == ':' && strings.HasPrefix(sql[i:], "::"):
+			tokens = append(tokens, exprToken{kind: exprOperator, text: "::"})
+			i += 2
+		case strings.IndexByte("()[],.;:", c) >= 0:
+			tokens = append(tokens, exprToken{kind: exprPunct, text: string(c)})
+			i++
+		case strings.IndexByte(operatorBytes, c) >= 0:
+			end := i
+			for end < len(sql) && strings.IndexByte(operatorBytes, sql[end]) >= 0 &&
+				!strings.HasPrefix(sql[end:], "--") && !strings.HasPrefix(sql[end:], "/*") {
+				end++
+			}
+
+			tokens = append(tokens, exprToken{kind: exprOperator, text: sql[i:end]})
+			i = end
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(sql) && (isIdentifierByte(sql[end]) || sql[end] == '.') {
+				end++
+			}
+
+			tokens = append(tokens, exprToken{kind: exprNumber, text: sql[i:end]})
+			i = end
+		case isIdentifierByte(c):
+			end := i
+			for end < len(sql) && (isIdentifierByte(sql[end]) || sql[end] == '$') {
+				end++
+			}
+
+			tokens = append(tokens, exprToken{kind: exprName, text: strings.ToLower(sql[i:end])})
+			i = end
+		default:
+			return nil, false
+		}
+	}
+
+	return tokens, true
+}
+
+// readQuoted reads a string or identifier quoted with quote from sql[i],
+// undoing doubled quotes, and returns it with the offset after it.
+func readQuoted(sql string, i int, quote byte) (string, int, bool) {
+	var sb strings.Builder
+
+	for j := i + 1; j < len(sql); j++ {
+		if sql[j] != quote {
+			sb.WriteByte(sql[j])
+			continue
+		}
+
+		if j+1 < len(sql) && sql[j+1] == quote {
+			sb.WriteByte(quote)
+			j++
+
+			continue
+		}
+
+		return sb.String(), j + 1, true
+	}
+
+	return "", 0, false
+}
@@ -0,0 +1,36 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExcludeElement is an element of an EXCLUDE constraint and the operator
+// compared on it, as in room WITH =.
+type ExcludeElement struct {
+	// Element is the column, or the expression in parentheses unless it is a
+	// function call, followed by its operator class when that is not the
+	// default of the index method.
+	Element  string `json:"element"`
+	Operator string `json:"operator"`
+}
+
+// ExcludeDefinition renders the EXCLUDE constraint c on one line, as in
+// EXCLUDE USING gist (room WITH =, during WITH &&) WHERE (active).
+func (c *Constraint) ExcludeDefinition() string {
+	elements := make([]string, 0, len(c.ExcludeElements))
+	for _, element := range c.ExcludeElements {
+		elements = append(elements, element.Element+" WITH "+element.Operator)
+	}
+
+	def := fmt.Sprintf("EXCLUDE USING %s (%s)", c.ExcludeMethod, strings.Join(elements, ", "))
+	if len(c.IncludeColumns) > 0 {
+		def += fmt.Sprintf(" INCLUDE (%s)", strings.Join(c.IncludeColumns, ", "))
+	}
+
+	if c.ExcludeWhere != "" {
+		def += fmt.Sprintf(" WHERE (%s)", c.ExcludeWhere)
+	}
+
+	return def
+}
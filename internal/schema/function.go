@@ -11,6 +11,9 @@ const (
 	VolatilityVolatile  = "VOLATILE"
 )
 
+// FunctionKindProcedure is the Kind of a procedure.
+const FunctionKindProcedure = "procedure"
+
 type Function struct {
 	Schema        string   `json:"schema"`
 	Name          string   `json:"name"`
@@ -22,6 +25,9 @@ type Function struct {
 	Body          string   `json:"body"`
 	Volatility    string   `json:"volatility"`
 	Definition    string   `json:"definition"`
+	// Kind is FunctionKindProcedure for a procedure, which CALL runs and
+	// which returns nothing, and empty for a function.
+	Kind string `json:"kind,omitempty"`
 
 	IsAggregate       bool   `json:"is_aggregate,omitempty"`
 	IsWindow          bool   `json:"is_window,omitempty"`
@@ -29,6 +35,8 @@ type Function struct {
 	IsSecurityDefiner bool   `json:"is_security_definer,omitempty"`
 	Comment           string `json:"comment,omitempty"`
 	Owner             string `json:"owner,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
 type Trigger struct {
@@ -44,12 +52,46 @@ type Trigger struct {
 	FunctionName   string   `json:"function_name"`
 	Definition     string   `json:"definition"`
 	Comment        string   `json:"comment,omitempty"`
+	// EnabledState is set by ALTER TABLE ... ENABLE/DISABLE TRIGGER. Empty
+	// means TriggerEnabledOrigin, the state a new trigger starts in.
+	EnabledState string `json:"enabled_state,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
+// Trigger enabled states, following session_replication_role: origin
+// triggers fire outside replica sessions, replica triggers only in them, and
+// always triggers in every session.
+const (
+	TriggerEnabledOrigin   = "origin"
+	TriggerEnabledReplica  = "replica"
+	TriggerEnabledAlways   = "always"
+	TriggerEnabledDisabled = "disabled"
+)
+
 func (f *Function) QualifiedName() string {
 	return QualifiedName(f.Schema, f.Name)
 }
 
+// IsProcedure reports whether f is a procedure rather than a function.
+func (f *Function) IsProcedure() bool {
+	return f.Kind == FunctionKindProcedure
+}
+
+// Keyword is the keyword that names f in DDL: FUNCTION or PROCEDURE.
+func (f *Function) Keyword() string {
+	if f.IsProcedure() {
+		return "PROCEDURE"
+	}
+
+	return "FUNCTION"
+}
+
+// KindName is "procedure" for a procedure and "function" otherwise.
+func (f *Function) KindName() string {
+	return strings.ToLower(f.Keyword())
+}
+
 func (f *Function) Signature() string {
 	argTypes := strings.Join(f.ArgumentTypes, ", ")
 	return fmt.Sprintf("%s(%s)", f.QualifiedName(), argTypes)
@@ -90,6 +132,15 @@ func (t *Trigger) QualifiedFunctionName() string {
 	return QualifiedName(t.FunctionSchema, t.FunctionName)
 }
 
+// GetEnabledState returns EnabledState, or TriggerEnabledOrigin when unset.
+func (t *Trigger) GetEnabledState() string {
+	if t.EnabledState == "" {
+		return TriggerEnabledOrigin
+	}
+
+	return t.EnabledState
+}
+
 // EventList renders the trigger's event clause, expanding UPDATE to
 // "UPDATE OF col, ..." when the trigger is scoped to specific columns.
 func (t *Trigger) EventList() string {
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"slices"
+	"strings"
+)
+
+// Grant object types.
+const (
+	GrantObjectTable  = "TABLE"
+	GrantObjectColumn = "COLUMN"
+)
+
+// GrantRolePublic is the grantee PUBLIC stands for, every role.
+const GrantRolePublic = "public"
+
+// TablePrivileges are the privileges GRANT ALL gives on a table, view or
+// materialized view, and ColumnPrivileges those it gives on a column.
+//
+//nolint:gochecknoglobals
+var (
+	TablePrivileges = []string{
+		"DELETE", "INSERT", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE",
+	}
+	ColumnPrivileges = []string{"INSERT", "REFERENCES", "SELECT", "UPDATE"}
+)
+
+// Grant is the privileges a role holds on a table, view or materialized view,
+// or on one of its columns. Privileges held with the grant option and
+// without it are recorded as separate grants.
+type Grant struct {
+	Schema string `json:"schema"`
+	// ObjectName is the table, view or materialized view the privileges are
+	// on, and ObjectType GrantObjectTable, or GrantObjectColumn for
+	// privileges on its Column.
+	ObjectName string `json:"object_name"`
+	ObjectType string `json:"object_type"`
+	Column     string `json:"column,omitempty"`
+	// Grantee is the role holding the privileges, GrantRolePublic for PUBLIC.
+	Grantee string `json:"grantee"`
+	// Privileges are the privileges held, such as SELECT. A grant of the
+	// desired schema left without privileges by a REVOKE declares that the
+	// grantee holds none.
+	Privileges      []string `json:"privileges"`
+	WithGrantOption bool     `json:"with_grant_option,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
+}
+
+func (g *Grant) QualifiedObjectName() string {
+	return QualifiedName(g.Schema, g.ObjectName)
+}
+
+// Target returns the qualified object name, followed by the column for a
+// column grant.
+func (g *Grant) Target() string {
+	if g.Column == "" {
+		return g.QualifiedObjectName()
+	}
+
+	return g.QualifiedObjectName() + "." + g.Column
+}
+
+// GranteeName returns the normalized grantee.
+func (g *Grant) GranteeName() string {
+	if strings.EqualFold(g.Grantee, GrantRolePublic) {
+		return GrantRolePublic
+	}
+
+	return NormalizeIdentifier(g.Grantee)
+}
+
+// PrivilegeNames returns the privileges in uppercase, sorted and without
+// duplicates.
+func (g *Grant) PrivilegeNames() []string {
+	privileges := make([]string, 0, len(g.Privileges))
+	for _, privilege := range g.Privileges {
+		privileges = append(privileges, strings.ToUpper(strings.TrimSpace(privilege)))
+	}
+
+	slices.Sort(privileges)
+
+	return slices.Compact(privileges)
+}
+
+// GetGrant returns the grant of db to grantee on the object or column, with or
+// without the grant option.
+func (db *Database) GetGrant(
+	schemaName, objectName, column, grantee string,
+	withGrantOption bool,
+) *Grant {
+	key := (&Grant{
+		Schema: schemaName, ObjectName: objectName, Column: column, Grantee: grantee,
+	}).Key()
+
+	for i := range db.Grants {
+		if db.Grants[i].WithGrantOption == withGrantOption && db.Grants[i].Key() == key {
+			return &db.Grants[i]
+		}
+	}
+
+	return nil
+}
+
+// Key identifies the object or column and the grantee of a grant.
+func (g *Grant) Key() string {
+	return strings.Join([]string{
+		NormalizeSchemaName(g.Schema),
+		NormalizeIdentifier(g.ObjectName),
+		NormalizeIdentifier(g.Column),
+		g.GranteeName(),
+	}, "\x00")
+}
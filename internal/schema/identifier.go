@@ -0,0 +1,108 @@
+package schema
+
+import (
+	"strings"
+)
+
+// quotedKeywords are the keywords PostgreSQL does not accept as a bare
+// column or table name: the reserved ones and those that can only name a
+// type or a function. quote_ident quotes these too.
+var quotedKeywords = map[string]bool{ //nolint:gochecknoglobals
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true,
+	"array": true, "as": true, "asc": true, "asymmetric": true, "authorization": true,
+	"binary": true, "both": true, "case": true, "cast": true, "check": true,
+	"collate": true, "collation": true, "column": true, "concurrently": true,
+	"constraint": true, "create": true, "cross": true, "current_catalog": true,
+	"current_date": true, "current_role": true, "current_schema": true,
+	"current_time": true, "current_timestamp": true, "current_user": true,
+	"default": true, "deferrable": true, "desc": true, "distinct": true, "do": true,
+	"else": true, "end": true, "except": true, "false": true, "fetch": true,
+	"for": true, "foreign": true, "freeze": true, "from": true, "full": true,
+	"grant": true, "group": true, "having": true, "ilike": true, "in": true,
+	"initially": true, "inner": true, "intersect": true, "into": true, "is": true,
+	"isnull": true, "join": true, "lateral": true, "leading": true, "left": true,
+	"like": true, "limit": true, "localtime": true, "localtimestamp": true,
+	"natural": true, "not": true, "notnull": true, "null": true, "offset": true,
+	"on": true, "only": true, "or": true, "order": true, "outer": true,
+	"overlaps": true, "placing": true, "primary": true, "references": true,
+	"returning": true, "right": true, "select": true, "session_user": true,
+	"similar": true, "some": true, "symmetric": true, "system_user": true,
+	"table": true, "tablesample": true, "then": true, "to": true, "trailing": true,
+	"true": true, "union": true, "unique": true, "user": true, "using": true,
+	"variadic": true, "verbose": true, "when": true, "where": true, "window": true,
+	"with": true,
+}
+
+// FoldIdentifier returns the name PostgreSQL stores for ident as it is
+// written in SQL. A quoted identifier keeps its case, with "" read as ", and
+// an unquoted one is folded to lower case.
+func FoldIdentifier(ident string) string {
+	ident = strings.TrimSpace(ident)
+
+	if name, ok := unquoteIdentifier(ident); ok {
+		return TruncateIdentifier(name)
+	}
+
+	return TruncateIdentifier(strings.ToLower(ident))
+}
+
+// NeedsQuoting reports whether name, as it is stored in the catalog, has to
+// be quoted to be read back as itself: it is empty, has a character other
+// than a lower case letter, a digit or an underscore, starts with a digit,
+// or is a keyword PostgreSQL does not take as a name.
+func NeedsQuoting(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return true
+	}
+
+	for i := range len(name) {
+		c := name[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return true
+		}
+	}
+
+	return quotedKeywords[name]
+}
+
+// QuoteIdentifier writes name so that PostgreSQL reads it back as name:
+// bare when it can be, otherwise in double quotes with the quotes it has
+// doubled.
+func QuoteIdentifier(name string) string {
+	if !NeedsQuoting(name) {
+		return name
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteIdentifierList writes names as the comma-separated list of a column
+// list, each quoted as QuoteIdentifier quotes it.
+func QuoteIdentifierList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = QuoteIdentifier(name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// QuotedName is QualifiedName with both parts quoted as QuoteIdentifier
+// quotes them, for writing the name into SQL.
+func QuotedName(schemaName, name string) string {
+	if schemaName == "" {
+		schemaName = DefaultSchema
+	}
+
+	return QuoteIdentifier(schemaName) + "." + QuoteIdentifier(name)
+}
+
+// unquoteIdentifier returns the name a quoted identifier stands for, and
+// whether ident is one.
+func unquoteIdentifier(ident string) (string, bool) {
+	if len(ident) < 2 || ident[0] != '"' || ident[len(ident)-1] != '"' {
+		return "", false
+	}
+
+	return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`), true
+}
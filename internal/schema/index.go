@@ -30,6 +30,16 @@ type Index struct {
 	IncludeColumns      []string          `json:"include_columns,omitempty"`
 	Tablespace          string            `json:"tablespace,omitempty"`
 	StorageParams       map[string]string `json:"storage_params,omitempty"`
+	// IfNotExists records that the desired state declared the index with
+	// CREATE INDEX IF NOT EXISTS.
+	IfNotExists bool `json:"if_not_exists,omitempty"`
+	// OnlyParent records CREATE INDEX ... ON ONLY on a partitioned table,
+	// which pg_dump writes before creating each partition's index and
+	// attaching it with ALTER INDEX ... ATTACH PARTITION.
+	OnlyParent bool   `json:"only_parent,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
 func (i *Index) QualifiedName() string {
@@ -0,0 +1,285 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	intervalNumberPattern    = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+	intervalQuantityPattern  = regexp.MustCompile(`([+-]?(?:\d+\.?\d*|\.\d+))([a-z]+)`)
+	intervalClockPattern     = regexp.MustCompile(`^[+-]?\d+:\d{1,2}(:\d{1,2}(\.\d+)?)?$`)
+	intervalYearMonthPattern = regexp.MustCompile(`^[+-]?\d+-\d+$`)
+	intervalISOPattern       = regexp.MustCompile(
+		`^p(\d+(\.\d+)?[ymwd])*(t(\d+(\.\d+)?[hms])+)?$`)
+	integerLiteralPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	literalCastPattern    = regexp.MustCompile(`\s*::\s*[a-z_ ]+$`)
+)
+
+// intervalUnits are the units PostgreSQL accepts in an interval literal,
+// with their abbreviations and plurals.
+//
+//nolint:gochecknoglobals
+var intervalUnits = map[string]bool{
+	"microsecond": true, "microseconds": true, "us": true, "usec": true, "usecs": true,
+	"usecond": true, "useconds": true,
+	"millisecond": true, "milliseconds": true, "ms": true, "msec": true, "msecs": true,
+	"msecond": true, "mseconds": true,
+	"second": true, "seconds": true, "s": true, "sec": true, "secs": true,
+	"minute": true, "minutes": true, "m": true, "min": true, "mins": true,
+	"hour": true, "hours": true, "h": true, "hr": true, "hrs": true,
+	"day": true, "days": true, "d": true,
+	"week": true, "weeks": true, "w": true,
+	"month": true, "months": true, "mon": true, "mons": true,
+	"year": true, "years": true, "y": true, "yr": true, "yrs": true,
+	"decade": true, "decades": true, "dec": true, "decs": true,
+	"century": true, "centuries": true, "c": true, "cent": true,
+	"millennium": true, "millennia": true, "mil": true, "mils": true,
+}
+
+// IntervalError is a TimescaleDB setting whose value PostgreSQL or
+// TimescaleDB would reject when the migration runs.
+type IntervalError struct {
+	// Object is the qualified name of the hypertable or continuous aggregate.
+	Object string
+	// Setting names the value, such as "chunk_time_interval".
+	Setting string
+	Literal string
+	Reason  string
+}
+
+func (e *IntervalError) Error() string {
+	return fmt.Sprintf("%s: invalid %s %q: %s", e.Object, e.Setting, e.Literal, e.Reason)
+}
+
+// ValidateInterval reports why PostgreSQL would reject literal as an interval,
+// or nil if it is accepted. It takes the text of the literal, optionally
+// quoted, prefixed with INTERVAL or cast with ::interval. Unit, ISO 8601,
+// HH:MM:SS and year-month forms are accepted, as is a bare number of
+// seconds.
+func ValidateInterval(literal string) error {
+	value := strings.ToLower(strings.TrimSpace(unwrapLiteral(literal)))
+	value = strings.TrimSpace(strings.TrimPrefix(value, "@"))
+
+	fields := strings.Fields(value)
+	if len(fields) > 1 && fields[len(fields)-1] == "ago" {
+		fields = fields[:len(fields)-1]
+	}
+
+	if len(fields) == 0 {
+		return errors.New("empty interval")
+	}
+
+	if len(fields) == 1 && fields[0] != "p" && intervalISOPattern.MatchString(fields[0]) {
+		return nil
+	}
+
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+
+		switch {
+		case intervalClockPattern.MatchString(field),
+			intervalYearMonthPattern.MatchString(field):
+			continue
+		case intervalNumberPattern.MatchString(field):
+			if i+1 == len(fields) || intervalClockPattern.MatchString(fields[i+1]) {
+				continue
+			}
+
+			i++
+			if !intervalUnits[fields[i]] {
+				return fmt.Errorf("unknown unit %q", fields[i])
+			}
+		default:
+			if err := validateQuantities(field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateQuantities checks a field that runs numbers and units together,
+// such as "1h" or "1h30m".
+func validateQuantities(field string) error {
+	matches := intervalQuantityPattern.FindAllStringSubmatchIndex(field, -1)
+
+	end := 0
+	for _, match := range matches {
+		if match[0] != end {
+			break
+		}
+
+		if unit := field[match[4]:match[5]]; !intervalUnits[unit] {
+			return fmt.Errorf("unknown unit %q", unit)
+		}
+
+		end = match[1]
+	}
+
+	if len(matches) == 0 || end != len(field) {
+		return fmt.Errorf("unexpected %q", field)
+	}
+
+	return nil
+}
+
+// unwrapLiteral strips a trailing cast, an INTERVAL prefix and the quotes
+// around a literal.
+func unwrapLiteral(literal string) string {
+	value := strings.TrimSpace(literal)
+	if loc := literalCastPattern.FindStringIndex(strings.ToLower(value)); loc != nil {
+		value = value[:loc[0]]
+	}
+
+	if len(value) > len("interval") && strings.EqualFold(value[:len("interval")], "interval") {
+		value = strings.TrimSpace(value[len("interval"):])
+	}
+
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		value = value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// intervalKind is what a TimescaleDB setting must hold.
+type intervalKind int
+
+const (
+	// kindInterval is always an interval, such as a schedule interval.
+	kindInterval intervalKind = iota
+	// kindTimeRange is an interval, or an integer for a hypertable
+	// partitioned by an integer column.
+	kindTimeRange
+	// kindOffset is a time range that may also be NULL.
+	kindOffset
+)
+
+// ValidateIntervals checks every interval-typed TimescaleDB setting of db:
+// chunk intervals, dimension intervals and the intervals of compression,
+// retention and refresh policies. Settings of a hypertable partitioned by an
+// integer column that stand for a time range must be plain integers.
+func ValidateIntervals(db *Database) []*IntervalError {
+	var errs []*IntervalError
+
+	check := func(object, setting, literal string, kind intervalKind, integer bool) {
+		if err := validateSetting(literal, kind, integer); err != nil {
+			errs = append(errs, &IntervalError{
+				Object:  object,
+				Setting: setting,
+				Literal: literal,
+				Reason:  err.Error(),
+			})
+		}
+	}
+
+	integerTime := make(map[string]bool, len(db.Hypertables))
+
+	for i := range db.Hypertables {
+		ht := &db.Hypertables[i]
+		name := ht.QualifiedTableName()
+		integer := isIntegerType(hypertableColumnType(db, ht, ht.TimeColumnName))
+		integerTime[strings.ToLower(name)] = integer
+
+		check(name, "partition_interval", ht.PartitionInterval, kindTimeRange, integer)
+		check(name, "chunk_time_interval", ht.ChunkTimeInterval, kindTimeRange, integer)
+
+		for _, dim := range ht.Dimensions {
+			if dim.Type == DimensionTime {
+				check(name, "interval of dimension "+dim.ColumnName, dim.Interval, kindTimeRange,
+					isIntegerType(hypertableColumnType(db, ht, dim.ColumnName)))
+			}
+		}
+
+		if cs := ht.CompressionSettings; cs != nil {
+			check(name, "compress_chunk_time_interval", cs.ChunkTimeInterval, kindInterval, false)
+		}
+
+		if cp := ht.CompressionPolicy; cp != nil {
+			check(name, "compress_after", cp.CompressAfter, kindTimeRange, integer)
+			check(name, "compression schedule_interval", cp.ScheduleInterval, kindInterval, false)
+		}
+
+		if rp := ht.RetentionPolicy; rp != nil {
+			check(name, "drop_after", rp.DropAfter, kindTimeRange, integer)
+			check(name, "retention schedule_interval", rp.ScheduleInterval, kindInterval, false)
+		}
+	}
+
+	for i := range db.ContinuousAggregates {
+		ca := &db.ContinuousAggregates[i]
+		name := ca.QualifiedViewName()
+		integer := integerTime[strings.ToLower(ca.QualifiedHypertableName())]
+
+		if rp := ca.RefreshPolicy; rp != nil {
+			check(name, "start_offset", rp.StartOffset, kindOffset, integer)
+			check(name, "end_offset", rp.EndOffset, kindOffset, integer)
+			check(name, "refresh schedule_interval", rp.ScheduleInterval, kindInterval, false)
+		}
+
+		if cp := ca.CompressionPolicy; cp != nil {
+			check(name, "compress_after", cp.CompressAfter, kindTimeRange, integer)
+			check(name, "compression schedule_interval", cp.ScheduleInterval, kindInterval, false)
+		}
+	}
+
+	return errs
+}
+
+// validateSetting checks one setting. Unset settings are always valid.
+func validateSetting(literal string, kind intervalKind, integer bool) error {
+	value := strings.TrimSpace(literal)
+
+	switch {
+	case value == "":
+		return nil
+	case kind == kindOffset && strings.EqualFold(value, "null"):
+		return nil
+	case kind != kindInterval && integer:
+		if !integerLiteralPattern.MatchString(unwrapLiteral(value)) {
+			return errors.New("the hypertable is partitioned by an integer column, " +
+				"so this must be an integer")
+		}
+
+		return nil
+	default:
+		return ValidateInterval(value)
+	}
+}
+
+// hypertableColumnType returns the declared type of a column of the
+// hypertable's table, or an empty string when the table is not declared.
+func hypertableColumnType(db *Database, ht *Hypertable, column string) string {
+	if ht.TimeColumnType != "" && strings.EqualFold(column, ht.TimeColumnName) {
+		return ht.TimeColumnType
+	}
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		if NormalizeSchemaName(table.Schema) != NormalizeSchemaName(ht.Schema) ||
+			!strings.EqualFold(table.Name, ht.TableName) {
+			continue
+		}
+
+		for j := range table.Columns {
+			if strings.EqualFold(table.Columns[j].Name, column) {
+				return table.Columns[j].DataType
+			}
+		}
+	}
+
+	return ""
+}
+
+func isIntegerType(dataType string) bool {
+	switch strings.ToLower(strings.TrimSpace(dataType)) {
+	case "smallint", "integer", "int", "bigint", "int2", "int4", "int8":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"slices"
+	"strings"
+)
+
+// Policy commands. PolicyCommandAll is the default of CREATE POLICY.
+const (
+	PolicyCommandAll    = "ALL"
+	PolicyCommandSelect = "SELECT"
+	PolicyCommandInsert = "INSERT"
+	PolicyCommandUpdate = "UPDATE"
+	PolicyCommandDelete = "DELETE"
+)
+
+// PolicyRolePublic is the role a policy applies to when it names none.
+const PolicyRolePublic = "public"
+
+// Policy is a row-level security policy of a table, created with CREATE
+// POLICY. Policies only restrict rows once row-level security is enabled on
+// their table.
+type Policy struct {
+	Schema    string `json:"schema"`
+	Name      string `json:"name"`
+	TableName string `json:"table_name"`
+	// Command is the command the policy applies to, such as SELECT. Empty
+	// means PolicyCommandAll.
+	Command string `json:"command,omitempty"`
+	// Roles are the roles the policy applies to. Empty means PUBLIC.
+	Roles []string `json:"roles,omitempty"`
+	// Using is the USING expression, which existing rows must satisfy, and
+	// WithCheck the WITH CHECK expression, which new rows must satisfy.
+	Using     string `json:"using,omitempty"`
+	WithCheck string `json:"with_check,omitempty"`
+	// Restrictive marks a policy created AS RESTRICTIVE, which every row must
+	// pass in addition to one of the permissive policies.
+	Restrictive bool `json:"restrictive,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
+}
+
+func (p *Policy) QualifiedTableName() string {
+	return QualifiedName(p.Schema, p.TableName)
+}
+
+// CommandName returns Command, or PolicyCommandAll when it is empty.
+func (p *Policy) CommandName() string {
+	if p.Command == "" {
+		return PolicyCommandAll
+	}
+
+	return strings.ToUpper(p.Command)
+}
+
+// RoleNames returns the roles the policy applies to, normalized and sorted,
+// with PUBLIC written as PolicyRolePublic.
+func (p *Policy) RoleNames() []string {
+	if len(p.Roles) == 0 {
+		return []string{PolicyRolePublic}
+	}
+
+	roles := make([]string, 0, len(p.Roles))
+	for _, role := range p.Roles {
+		roles = append(roles, NormalizeIdentifier(role))
+	}
+
+	slices.Sort(roles)
+
+	return slices.Compact(roles)
+}
@@ -1,10 +1,14 @@
 package schema
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
@@ -17,6 +21,10 @@ const (
 	// MaxIdentifierLength is PostgreSQL's identifier length limit (NAMEDATALEN - 1).
 	// Names longer than this are silently truncated when stored in pg_catalog.
 	MaxIdentifierLength = 63
+
+	// identifierHashLength is how many hex digits of a digest shorten a
+	// synthesized name that is too long.
+	identifierHashLength = 8
 )
 
 type Database struct {
@@ -35,10 +43,15 @@ type Database struct {
 	Triggers             []Trigger             `json:"triggers,omitempty"`
 	Hypertables          []Hypertable          `json:"hypertables,omitempty"`
 	ContinuousAggregates []ContinuousAggregate `json:"continuous_aggregates,omitempty"`
+	Grants               []Grant               `json:"grants,omitempty"`
+	// Roles are the roles of an extracted database. They are unknown for a
+	// schema parsed from SQL, which leaves them empty.
+	Roles []string `json:"roles,omitempty"`
 }
 
 type Schema struct {
-	Name string `json:"name"`
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
 }
 
 func (s *Schema) QualifiedName() string {
@@ -58,7 +71,16 @@ type CustomType struct {
 	Type       string   `json:"type"`
 	Definition string   `json:"definition"`
 	Values     []string `json:"values,omitempty"`
-	Comment    string   `json:"comment,omitempty"`
+	// Attributes are the attributes of a composite type, in order.
+	Attributes []TypeAttribute `json:"attributes,omitempty"`
+	Comment    string          `json:"comment,omitempty"`
+}
+
+// TypeAttribute is an attribute of a composite type. DataType is written the
+// way Column.FullDataType writes a column's type.
+type TypeAttribute struct {
+	Name     string `json:"name"`
+	DataType string `json:"data_type"`
 }
 
 type Sequence struct {
@@ -79,6 +101,35 @@ func (s *Sequence) QualifiedName() string {
 	return QualifiedName(s.Schema, s.Name)
 }
 
+// SequenceBounds returns the MINVALUE and MAXVALUE PostgreSQL gives a sequence
+// of the data type and increment when neither is declared.
+func SequenceBounds(dataType string, increment int64) (minValue, maxValue int64) {
+	limit := int64(math.MaxInt64)
+
+	switch strings.ToLower(dataType) {
+	case "smallint", "int2":
+		limit = math.MaxInt16
+	case "integer", "int", "int4":
+		limit = math.MaxInt32
+	}
+
+	if increment < 0 {
+		return -limit - 1, -1
+	}
+
+	return 1, limit
+}
+
+// DefaultStart returns the START WITH PostgreSQL gives the sequence when none
+// is declared: MINVALUE for ascending sequences and MAXVALUE for descending.
+func (s *Sequence) DefaultStart() int64 {
+	if s.Increment < 0 {
+		return s.MaxValue
+	}
+
+	return s.MinValue
+}
+
 func (db *Database) MarshalJSON() ([]byte, error) {
 	type Alias Database
 	return json.MarshalIndent((*Alias)(db), "", "  ") //nolint:wrapcheck
@@ -103,6 +154,20 @@ func (db *Database) GetTable(schema, name string) *Table {
 	return nil
 }
 
+func (db *Database) GetCustomType(schema, name string) *CustomType {
+	schema = NormalizeSchemaName(schema)
+	name = NormalizeIdentifier(name)
+
+	for i := range db.CustomTypes {
+		if NormalizeSchemaName(db.CustomTypes[i].Schema) == schema &&
+			NormalizeIdentifier(db.CustomTypes[i].Name) == name {
+			return &db.CustomTypes[i]
+		}
+	}
+
+	return nil
+}
+
 func (db *Database) GetView(schema, name string) *View {
 	schema = NormalizeSchemaName(schema)
 	name = NormalizeIdentifier(name)
@@ -146,11 +211,21 @@ func (db *Database) GetMaterializedView(schema, name string) *MaterializedView {
 }
 
 func (db *Database) GetFunction(schema, name string, argTypes []string) *Function {
+	return db.getRoutine(false, schema, name, argTypes)
+}
+
+// GetProcedure is GetFunction for procedures.
+func (db *Database) GetProcedure(schema, name string, argTypes []string) *Function {
+	return db.getRoutine(true, schema, name, argTypes)
+}
+
+func (db *Database) getRoutine(procedure bool, schema, name string, argTypes []string) *Function {
 	schema = NormalizeSchemaName(schema)
 	name = NormalizeIdentifier(name)
 
 	for i := range db.Functions {
-		if NormalizeSchemaName(db.Functions[i].Schema) == schema &&
+		if db.Functions[i].IsProcedure() == procedure &&
+			NormalizeSchemaName(db.Functions[i].Schema) == schema &&
 			NormalizeIdentifier(db.Functions[i].Name) == name &&
 			equalStringSlices(db.Functions[i].ArgumentTypes, argTypes) {
 			return &db.Functions[i]
@@ -169,6 +244,7 @@ func (db *Database) GetViews() int                { return len(db.Views) }
 func (db *Database) GetMaterializedViews() int    { return len(db.MaterializedViews) }
 func (db *Database) GetFunctions() int            { return len(db.Functions) }
 func (db *Database) GetTriggers() int             { return len(db.Triggers) }
+func (db *Database) GetGrants() int               { return len(db.Grants) }
 func (db *Database) GetHypertables() int          { return len(db.Hypertables) }
 func (db *Database) GetContinuousAggregates() int { return len(db.ContinuousAggregates) }
 
@@ -224,6 +300,16 @@ func (db *Database) Sort() {
 	sort.Slice(db.ContinuousAggregates, func(i, j int) bool {
 		return db.ContinuousAggregates[i].QualifiedViewName() < db.ContinuousAggregates[j].QualifiedViewName()
 	})
+
+	sort.SliceStable(db.Grants, func(i, j int) bool {
+		if db.Grants[i].Key() != db.Grants[j].Key() {
+			return db.Grants[i].Key() < db.Grants[j].Key()
+		}
+
+		return !db.Grants[i].WithGrantOption && db.Grants[j].WithGrantOption
+	})
+
+	sort.Strings(db.Roles)
 }
 
 func (ct *CustomType) QualifiedName() string {
@@ -244,9 +330,16 @@ func equalStringSlices(a, b []string) bool {
 	return true
 }
 
+// NormalizeIdentifier returns the catalog name identifier stands for, given
+// either as it is stored or quoted. Names are compared as PostgreSQL
+// compares them, so the case of a stored name is kept; FoldIdentifier reads
+// a name as it is written in SQL.
 func NormalizeIdentifier(identifier string) string {
-	identifier = strings.Trim(identifier, `"`)
-	return TruncateIdentifier(strings.ToLower(identifier))
+	if name, ok := unquoteIdentifier(strings.TrimSpace(identifier)); ok {
+		identifier = name
+	}
+
+	return TruncateIdentifier(identifier)
 }
 
 // TruncateIdentifier mirrors PostgreSQL's silent truncation of identifiers
@@ -259,6 +352,38 @@ func TruncateIdentifier(identifier string) string {
 	return identifier
 }
 
+// SynthesizedIdentifier joins parts and the suffix with underscores into a
+// name for an object pgtofu names itself. A name too long for PostgreSQL
+// keeps the start of the parts, then the first digits of the SHA-256 of the
+// whole name and the suffix, so names that share a long prefix stay apart
+// and the same parts always give the same name. A suffix too long to keep
+// is left to the digest.
+func SynthesizedIdentifier(suffix string, parts ...string) string {
+	name := strings.Join(append(parts[:len(parts):len(parts)], suffix), "_")
+	if len(name) <= MaxIdentifierLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+
+	tail := "_" + hex.EncodeToString(sum[:])[:identifierHashLength]
+	if len(tail)+1+len(suffix) <= MaxIdentifierLength/2 {
+		tail += "_" + suffix
+	}
+
+	return strings.TrimRight(clipIdentifier(name, MaxIdentifierLength-len(tail)), "_") + tail
+}
+
+// clipIdentifier cuts identifier to at most n bytes without splitting a
+// character.
+func clipIdentifier(identifier string, n int) string {
+	for n > 0 && !utf8.RuneStart(identifier[n]) {
+		n--
+	}
+
+	return identifier[:n]
+}
+
 func NormalizeSchemaName(schema string) string {
 	if schema == "" {
 		return DefaultSchema
@@ -0,0 +1,27 @@
+package schema
+
+import "fmt"
+
+// SourceLocation is the file and line an object was declared at. It is only
+// recorded for objects parsed from schema files; objects read from any other
+// source carry no location.
+type SourceLocation struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	// Migration is the migration the declaring statement pins the object to
+	// with a "-- pgtofu:migration <name>" comment.
+	Migration string `json:"migration,omitempty"`
+}
+
+func (l SourceLocation) String() string {
+	switch {
+	case l.File != "" && l.Line > 0:
+		return fmt.Sprintf("%s:%d", l.File, l.Line)
+	case l.File != "":
+		return l.File
+	case l.Line > 0:
+		return fmt.Sprintf("line %d", l.Line)
+	default:
+		return "unknown location"
+	}
+}
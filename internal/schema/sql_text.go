@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+)
+
+var remoteAccessCallPattern = regexp.MustCompile(
+	`(?i)(?:^|[^a-z0-9_$."])(dblink(?:_[a-z_]+)?|postgres_fdw_[a-z_]+)\s*\(`,
+)
+
+// MaskStringLiterals returns sql with the contents of its string literals,
+// escape strings and dollar-quoted strings replaced by spaces. Quotes,
+// newlines and the length stay as they are, so offsets into the result are
+// offsets into sql. Quoted identifiers and comments are left alone.
+func MaskStringLiterals(sql string) string {
+	if !strings.ContainsAny(sql, `'$`) {
+		return sql
+	}
+
+	masked := []byte(sql)
+
+	for i := 0; i < len(sql); {
+		switch {
+		case sql[i] == '\'':
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') &&
+				(i == 1 || !isIdentifierByte(sql[i-2]))
+			i = maskQuoted(sql, masked, i+1, escapes)
+		case sql[i] == '"':
+			i = skipPast(sql, i+1, `"`)
+		case strings.HasPrefix(sql[i:], "--"):
+			i = skipPast(sql, i+2, "\n")
+		case strings.HasPrefix(sql[i:], "/*"):
+			i = skipPast(sql, i+2, "*/")
+		case sql[i] == '$' && (i == 0 || !isIdentifierByte(sql[i-1])):
+			tag := dollarQuoteTag(sql[i:])
+			if tag == "" {
+				i++
+				continue
+			}
+
+			start := i + len(tag)
+
+			end := strings.Index(sql[start:], tag)
+			if end < 0 {
+				end = len(sql) - start
+			}
+
+			blank(masked[start : start+end])
+			i = min(start+end+len(tag), len(sql))
+		default:
+			i++
+		}
+	}
+
+	return string(masked)
+}
+
+// UsesRemoteAccess reports whether sql calls dblink or a postgres_fdw helper,
+// which run queries pgtofu cannot see on another server.
+func UsesRemoteAccess(sql string) bool {
+	return remoteAccessCallPattern.MatchString(MaskStringLiterals(sql))
+}
+
+// UsesRemoteAccess reports whether the view's query reads from another server
+// through dblink or postgres_fdw helpers.
+func (v *View) UsesRemoteAccess() bool {
+	return UsesRemoteAccess(v.Definition)
+}
+
+// UsesRemoteAccess reports whether the view's query reads from another server
+// through dblink or postgres_fdw helpers.
+func (mv *MaterializedView) UsesRemoteAccess() bool {
+	return UsesRemoteAccess(mv.Definition)
+}
+
+// maskQuoted blanks a single-quoted string whose contents start at i and
+// returns the offset after its closing quote.
+func maskQuoted(sql string, masked []byte, i int, escapes bool) int {
+	for i < len(sql) {
+		switch {
+		case escapes && sql[i] == '\\' && i+1 < len(sql):
+			blank(masked[i : i+2])
+			i += 2
+		case sql[i] == '\'' && i+1 < len(sql) && sql[i+1] == '\'':
+			blank(masked[i : i+2])
+			i += 2
+		case sql[i] == '\'':
+			return i + 1
+		default:
+			blank(masked[i : i+1])
+			i++
+		}
+	}
+
+	return i
+}
+
+// dollarQuoteTag returns the $tag$ that s starts with, or "" when it does not
+// start a dollar-quoted string.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c >= '0' && c <= '9':
+			if i == 1 {
+				return ""
+			}
+		case !isIdentifierByte(c):
+			return ""
+		}
+	}
+
+	return ""
+}
+
+func skipPast(sql string, i int, terminator string) int {
+	end := strings.Index(sql[i:], terminator)
+	if end < 0 {
+		return len(sql)
+	}
+
+	return i + end + len(terminator)
+}
+
+func blank(b []byte) {
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -12,10 +12,22 @@ type Table struct {
 	Columns           []Column           `json:"columns"`
 	Constraints       []Constraint       `json:"constraints,omitempty"`
 	Indexes           []Index            `json:"indexes,omitempty"`
+	Policies          []Policy           `json:"policies,omitempty"`
 	Comment           string             `json:"comment,omitempty"`
 	Owner             string             `json:"owner,omitempty"`
 	Tablespace        string             `json:"tablespace,omitempty"`
 	PartitionStrategy *PartitionStrategy `json:"partition_strategy,omitempty"`
+	// IfNotExists records that the desired state declared the table with
+	// CREATE TABLE IF NOT EXISTS.
+	IfNotExists bool `json:"if_not_exists,omitempty"`
+	// TypedOf is the qualified name of the composite type of a table created
+	// with CREATE TABLE ... OF, whose columns are the type's attributes.
+	TypedOf string `json:"typed_of,omitempty"`
+	// RowLevelSecurityEnabled records ALTER TABLE ... ENABLE ROW LEVEL
+	// SECURITY, without which the table's policies are not applied.
+	RowLevelSecurityEnabled bool `json:"row_level_security_enabled,omitempty"`
+	// Source is where the table was declared, when it was parsed from a file.
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
 type PartitionStrategy struct {
@@ -27,6 +39,11 @@ type PartitionStrategy struct {
 type Partition struct {
 	Name       string `json:"name"`
 	Definition string `json:"definition,omitempty"`
+	// Constraints and Indexes are the objects declared on this partition
+	// alone. Copies PostgreSQL creates from the parent's constraints and
+	// indexes are not recorded; the parent's declaration is authoritative.
+	Constraints []Constraint `json:"constraints,omitempty"`
+	Indexes     []Index      `json:"indexes,omitempty"`
 }
 
 type Column struct {
@@ -42,11 +59,25 @@ type Column struct {
 	Precision *int `json:"precision,omitempty"`
 	Scale     *int `json:"scale,omitempty"`
 
-	IsArray              bool   `json:"is_array,omitempty"`
-	IsIdentity           bool   `json:"is_identity,omitempty"`
-	IdentityGeneration   string `json:"identity_generation,omitempty"`
-	IsGenerated          bool   `json:"is_generated,omitempty"`
-	GenerationExpression string `json:"generation_expression,omitempty"`
+	IsArray bool `json:"is_array,omitempty"`
+	// Identity is set on a GENERATED ... AS IDENTITY column.
+	Identity             *Identity `json:"identity,omitempty"`
+	IsGenerated          bool      `json:"is_generated,omitempty"`
+	GenerationExpression string    `json:"generation_expression,omitempty"`
+
+	// Storage is the strategy set with SET STORAGE: plain, external,
+	// extended or main. Empty is the default strategy of the type.
+	Storage string `json:"storage,omitempty"`
+	// Compression is the TOAST compression method, pglz or lz4. Empty is
+	// default_toast_compression.
+	Compression string `json:"compression,omitempty"`
+
+	// UsingExpression converts the stored values when the type of the column
+	// changes, as the USING clause of ALTER COLUMN ... TYPE. It is declared
+	// with a "-- pgtofu:using <expression>" comment on the column.
+	UsingExpression string `json:"using_expression,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
 const (
@@ -62,6 +93,9 @@ type Constraint struct {
 	Type       string   `json:"type"`
 	Columns    []string `json:"columns"`
 	Definition string   `json:"definition,omitempty"`
+	// IncludeColumns are the non-key columns of a primary key or unique
+	// constraint's INCLUDE clause, stored in its index but not constrained.
+	IncludeColumns []string `json:"include_columns,omitempty"`
 
 	ReferencedSchema  string   `json:"referenced_schema,omitempty"`
 	ReferencedTable   string   `json:"referenced_table,omitempty"`
@@ -72,8 +106,23 @@ type Constraint struct {
 	CheckExpression string `json:"check_expression,omitempty"`
 	IndexName       string `json:"index_name,omitempty"`
 
+	// ExcludeMethod, ExcludeElements and ExcludeWhere are the index method,
+	// the elements with their operators and the predicate of an EXCLUDE
+	// constraint. Definition holds them as ExcludeDefinition renders them.
+	ExcludeMethod   string           `json:"exclude_method,omitempty"`
+	ExcludeElements []ExcludeElement `json:"exclude_elements,omitempty"`
+	ExcludeWhere    string           `json:"exclude_where,omitempty"`
+
 	IsDeferrable      bool `json:"is_deferrable,omitempty"`
 	InitiallyDeferred bool `json:"initially_deferred,omitempty"`
+
+	Comment string `json:"comment,omitempty"`
+
+	// LegacyName is the name older pgtofu versions gave a constraint whose
+	// synthesized name is too long, by cutting it at the identifier limit. It
+	// is set only when that differs from Name, so a constraint still carrying
+	// it is renamed rather than rebuilt.
+	LegacyName string `json:"legacy_name,omitempty"`
 }
 
 func (t *Table) QualifiedName() string {
@@ -123,8 +172,22 @@ func (t *Table) GetIndex(name string) *Index {
 	return nil
 }
 
+func (t *Table) GetPolicy(name string) *Policy {
+	normalizedName := NormalizeIdentifier(name)
+	for i := range t.Policies {
+		if NormalizeIdentifier(t.Policies[i].Name) == normalizedName {
+			return &t.Policies[i]
+		}
+	}
+
+	return nil
+}
+
+// Sort orders constraints, indexes and policies canonically. Columns are only ordered by
+// Position, and the sort is stable so columns sharing a Position (or carrying
+// none) keep their declaration order; column order is never alphabetical.
 func (t *Table) Sort() {
-	sort.Slice(t.Columns, func(i, j int) bool {
+	sort.SliceStable(t.Columns, func(i, j int) bool {
 		return t.Columns[i].Position < t.Columns[j].Position
 	})
 
@@ -150,14 +213,21 @@ func (t *Table) Sort() {
 	sort.Slice(t.Indexes, func(i, j int) bool {
 		return t.Indexes[i].Name < t.Indexes[j].Name
 	})
+
+	sort.Slice(t.Policies, func(i, j int) bool {
+		return t.Policies[i].Name < t.Policies[j].Name
+	})
 }
 
 func (c *Column) FullDataType() string {
 	dt := c.DataType
 
-	if c.MaxLength != nil && isCharacterType(dt) {
+	switch {
+	case c.MaxLength != nil && isCharacterType(dt):
 		dt = formatCharacterType(dt, *c.MaxLength)
-	} else if c.Precision != nil {
+	case c.Precision != nil && IsTimeType(dt):
+		dt = FormatTimeType(dt, *c.Precision)
+	case c.Precision != nil:
 		if c.Scale != nil && *c.Scale > 0 {
 			dt = fmt.Sprintf("%s(%d, %d)", dt, *c.Precision, *c.Scale)
 		} else {
@@ -0,0 +1,51 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestColumnIdentityRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	col := schema.Column{Name: "id", DataType: "integer", Identity: &schema.Identity{
+		Generation: schema.IdentityByDefault,
+		StartValue: 100,
+		MinValue:   1,
+		MaxValue:   2147483647,
+		Increment:  5,
+		CacheSize:  10,
+	}}
+
+	data, err := json.Marshal(col)
+	require.NoError(t, err)
+
+	var decoded schema.Column
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, col, decoded)
+}
+
+func TestColumnReadsLegacyIdentityFields(t *testing.T) {
+	t.Parallel()
+
+	var col schema.Column
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "id",
+		"data_type": "BIGINT",
+		"is_identity": true,
+		"identity_generation": "ALWAYS"
+	}`), &col))
+
+	assert.Equal(t, "id", col.Name)
+	assert.Equal(t, schema.NewIdentity(schema.IdentityAlways, "bigint"), col.Identity)
+	assert.Equal(t, int64(9223372036854775807), col.Identity.MaxValue)
+
+	var plain schema.Column
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "note", "data_type": "text"}`), &plain))
+	assert.Nil(t, plain.Identity)
+}
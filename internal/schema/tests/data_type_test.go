@@ -0,0 +1,73 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestDataTypeAliases(t *testing.T) {
+	t.Parallel()
+
+	aliases := schema.DataTypeAliases()
+
+	for alias, canonical := range map[string]string{
+		"int4":              "integer",
+		"int8":              "bigint",
+		"bigserial":         "bigint",
+		"bool":              "boolean",
+		"bpchar":            "char",
+		"character varying": "varchar",
+		"decimal":           "numeric",
+		"float8":            "double precision",
+		"timestamptz":       "timestamp with time zone",
+	} {
+		assert.Equal(t, canonical, aliases[alias], alias)
+	}
+
+	aliases["int4"] = "text"
+	assert.Equal(t, "integer", schema.CanonicalDataType("int4"),
+		"the returned table is a copy")
+}
+
+func TestCanonicalDataType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"INTEGER", "integer"},
+		{"int4", "integer"},
+		{"pg_catalog.int8", "bigint"},
+		{"TIMESTAMPTZ", "timestamp with time zone"},
+		{"timestamp  with time zone", "timestamp with time zone"},
+		{"Character Varying(20)", "varchar(20)"},
+		{"VARCHAR", "varchar"},
+		{"decimal(10, 2)", "numeric(10, 2)"},
+		{"public.citext", "public.citext"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, schema.CanonicalDataType(tt.input), tt.input)
+	}
+}
+
+func TestDataTypeMapper(t *testing.T) {
+	t.Parallel()
+
+	mapper := schema.NewDataTypeMapper(map[string]string{
+		"Public.CITEXT": "citext",
+		"my_int":        "INT4",
+	})
+
+	assert.Equal(t, "citext", mapper.Canonical("public.citext"))
+	assert.Equal(t, "integer", mapper.Canonical("my_int"))
+	assert.Equal(t, "integer", mapper.Canonical("int"))
+	assert.Equal(t, "text", mapper.Canonical("TEXT"))
+
+	var none *schema.DataTypeMapper
+	assert.Equal(t, "bigint", none.Canonical("int8"))
+}
@@ -0,0 +1,151 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func enumDatabase(columnType, columnDefault string, checks ...string) *schema.Database {
+	table := schema.Table{
+		Schema: "app",
+		Name:   "users",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "BIGINT", Position: 1},
+			{Name: "status", DataType: columnType, Position: 2, Default: columnDefault},
+			{Name: "note", DataType: "TEXT", Position: 3},
+		},
+	}
+
+	for _, check := range checks {
+		table.Constraints = append(table.Constraints, schema.Constraint{
+			Name:            "users_check",
+			Type:            schema.ConstraintCheck,
+			Definition:      check,
+			CheckExpression: check,
+		})
+	}
+
+	return &schema.Database{
+		CustomTypes: []schema.CustomType{
+			{Schema: "app", Name: "status", Type: "enum", Values: []string{"active", "archived"}},
+			{Schema: "public", Name: "mood", Type: "enum", Values: []string{"happy"}},
+		},
+		Tables: []schema.Table{table},
+	}
+}
+
+func TestValidateEnumLiterals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		columnType string
+		def        string
+		check      string
+		want       []string
+	}{
+		{name: "default", def: "'inactive'", want: []string{"inactive"}},
+		{
+			name: "default cast to the enum",
+			def:  "'inactive'::app.status",
+			want: []string{"inactive"},
+		},
+		{name: "valid default", def: "'archived'::status"},
+		{
+			name:  "in list",
+			check: "CHECK (status IN ('active', 'inactive', 'gone'))",
+			want:  []string{"inactive", "gone"},
+		},
+		{
+			name:  "not in list",
+			check: "CHECK (status NOT IN ('inactive'::app.status))",
+			want:  []string{"inactive"},
+		},
+		{
+			name:  "any array",
+			check: "CHECK ((status = ANY (ARRAY['active'::app.status, 'inactive'::app.status])))",
+			want:  []string{"inactive"},
+		},
+		{name: "equality", check: "CHECK (status = 'inactive')", want: []string{"inactive"}},
+		{
+			name:  "inequality in a conjunction",
+			check: "CHECK (id > 0 AND users.status <> 'inactive' OR note IS NULL)",
+			want:  []string{"inactive"},
+		},
+		{name: "literal first", check: "CHECK ('inactive' != status)", want: []string{"inactive"}},
+		{name: "valid check", check: "CHECK (status IN ('active', 'archived'))"},
+		{
+			name:       "unqualified type in the default schema",
+			columnType: "MOOD",
+			def:        "'sad'",
+			check:      "CHECK (status <> 'grumpy')",
+			want:       []string{"sad", "grumpy"},
+		},
+		{name: "column cast to text", check: "CHECK (status::text = 'inactive')"},
+		{name: "literal cast to text", check: "CHECK (status = 'inactive'::text)"},
+		{name: "default of another type", def: "'inactive'::character varying"},
+		{name: "default expression", def: "lower('INACTIVE')"},
+		{name: "function call", check: "CHECK (lower(status::text) = 'inactive')"},
+		{name: "concatenated literal", check: "CHECK (status = 'in' || 'active')"},
+		{name: "other column", check: "CHECK (note = 'inactive' AND note IN ('x'))"},
+		{name: "column inside an expression", check: "CHECK (coalesce(status, 'x') = 'active')"},
+		{name: "escape string", check: "CHECK (status = E'inactive')"},
+		{name: "dollar quotes", check: "CHECK (status = $$inactive$$)"},
+		{name: "mixed list", check: "CHECK (status IN ('inactive', other_status()))"},
+		{name: "undeclared type", columnType: "app.other", def: "'inactive'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			columnType := tt.columnType
+			if columnType == "" {
+				columnType = "APP.STATUS"
+			}
+
+			var checks []string
+			if tt.check != "" {
+				checks = append(checks, tt.check)
+			}
+
+			errs := schema.ValidateEnumLiterals(enumDatabase(columnType, tt.def, checks...))
+
+			literals := make([]string, 0, len(errs))
+			for _, err := range errs {
+				literals = append(literals, err.Literal)
+			}
+
+			if len(tt.want) == 0 {
+				assert.Empty(t, literals)
+			} else {
+				assert.Equal(t, tt.want, literals)
+			}
+		})
+	}
+}
+
+func TestEnumLiteralError(t *testing.T) {
+	t.Parallel()
+
+	errs := schema.ValidateEnumLiterals(enumDatabase("APP.STATUS", "'inactive'",
+		"CHECK (status IN ('active', 'inactive'))"))
+	require.Len(t, errs, 2)
+
+	assert.Equal(t, "app.users", errs[0].Object)
+	assert.Equal(t, "status", errs[0].Column)
+	assert.Equal(t, "app.status", errs[0].Enum)
+	assert.Equal(t, []string{"active", "archived"}, errs[0].Values)
+	assert.Equal(t,
+		`app.users.status: default uses "inactive", which is not a value of app.status `+
+			`('active', 'archived')`,
+		errs[0].Error())
+	assert.Equal(t,
+		`app.users.status: check users_check uses "inactive", which is not a value of `+
+			`app.status ('active', 'archived')`,
+		errs[1].Error())
+}
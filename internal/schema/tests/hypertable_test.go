@@ -0,0 +1,53 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestHypertableReadsLegacySpaceColumns(t *testing.T) {
+	t.Parallel()
+
+	var ht schema.Hypertable
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schema": "public",
+		"table_name": "metrics",
+		"time_column_name": "time",
+		"space_partitions": 2,
+		"space_columns": ["device_id", "region"],
+		"num_dimensions": 3
+	}`), &ht))
+
+	assert.Equal(t, "metrics", ht.TableName)
+	assert.Equal(t, 3, ht.NumDimensions)
+	assert.Equal(t, []schema.Dimension{
+		{ColumnName: "device_id", Type: schema.DimensionSpace},
+		{ColumnName: "region", Type: schema.DimensionSpace},
+	}, ht.Dimensions)
+}
+
+func TestHypertableDimensionsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ht := schema.Hypertable{
+		Schema:         schema.DefaultSchema,
+		TableName:      "metrics",
+		TimeColumnName: "time",
+		Dimensions: []schema.Dimension{
+			{ColumnName: "device_id", Type: schema.DimensionSpace, NumberPartitions: 4},
+		},
+		NumDimensions: 2,
+	}
+
+	data, err := json.Marshal(ht)
+	require.NoError(t, err)
+
+	var decoded schema.Hypertable
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, ht, decoded)
+}
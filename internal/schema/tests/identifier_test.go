@@ -0,0 +1,95 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+// longTableName fills the identifier limit on its own, so every name
+// synthesized from it has to be shortened.
+const longTableName = "customer_notification_preferences_by_channel_and_locale_archive"
+
+func TestSynthesizedIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		suffix string
+		parts  []string
+		want   string
+	}{
+		{
+			name:   "short name kept",
+			suffix: "fkey",
+			parts:  []string{"orders", "customer_id"},
+			want:   "orders_customer_id_fkey",
+		},
+		{
+			name:   "name at the limit kept",
+			suffix: "key",
+			parts:  []string{strings.Repeat("t", 59)},
+			want:   strings.Repeat("t", 59) + "_key",
+		},
+		{
+			name:   "long name shortened",
+			suffix: "fkey",
+			parts:  []string{longTableName, "customer_id"},
+			want:   "customer_notification_preferences_by_channel_and_1a5b093e_fkey",
+		},
+		{
+			name:   "suffix too long to keep",
+			suffix: strings.Repeat("i", 40),
+			parts:  []string{longTableName},
+			want:   longTableName[:54] + "_96b45cbe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := schema.SynthesizedIdentifier(tt.suffix, tt.parts...)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, len(got), schema.MaxIdentifierLength)
+		})
+	}
+}
+
+func TestSynthesizedIdentifier_SharedPrefixesStayApart(t *testing.T) {
+	t.Parallel()
+
+	// Both names cut at the limit to the same 63 bytes.
+	first := schema.SynthesizedIdentifier("fkey", longTableName+"_a", "customer_id")
+	second := schema.SynthesizedIdentifier("fkey", longTableName+"_b", "customer_id")
+	sameTable := schema.SynthesizedIdentifier("fkey", longTableName, "channel_id")
+	other := schema.SynthesizedIdentifier("fkey", longTableName, "customer_id")
+
+	names := []string{first, second, sameTable, other}
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		assert.False(t, seen[name], "duplicate name %s", name)
+		assert.LessOrEqual(t, len(name), schema.MaxIdentifierLength)
+		assert.True(t, strings.HasSuffix(name, "_fkey"), name)
+
+		seen[name] = true
+	}
+
+	assert.Equal(t, first, schema.SynthesizedIdentifier("fkey", longTableName+"_a", "customer_id"),
+		"the same parts give the same name")
+}
+
+func TestSynthesizedIdentifier_DoesNotSplitCharacters(t *testing.T) {
+	t.Parallel()
+
+	got := schema.SynthesizedIdentifier("pkey", "t"+strings.Repeat("é", 40))
+
+	assert.True(t, utf8.ValidString(got), got)
+	assert.LessOrEqual(t, len(got), schema.MaxIdentifierLength)
+	assert.True(t, strings.HasSuffix(got, "_pkey"), got)
+}
@@ -0,0 +1,150 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func TestValidateInterval(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{
+		"1 day",
+		"7 days",
+		"1.5 hours",
+		".5 day",
+		"-1 day",
+		"+2 weeks",
+		"1 day 02:00:00",
+		"02:30",
+		"-01:30:15.5",
+		"1 2:03:04",
+		"1-2",
+		"3600",
+		"1h",
+		"1h30m",
+		"1day",
+		"@ 3 mons ago",
+		"1 year 2 months 3 days",
+		"10 microseconds",
+		"5 msec",
+		"2 centuries",
+		"P1D",
+		"PT1H30M",
+		"P1Y2M3DT4H5M6S",
+		"INTERVAL '1 day'",
+		"'7 days'",
+		"'1 hour'::interval",
+		"1 DAY",
+	}
+
+	for _, literal := range valid {
+		t.Run(literal, func(t *testing.T) {
+			t.Parallel()
+
+			assert.NoError(t, schema.ValidateInterval(literal))
+		})
+	}
+
+	invalid := map[string]string{
+		"1 dya":         `unknown unit "dya"`,
+		"1 weeek":       `unknown unit "weeek"`,
+		"1 day 2 hourz": `unknown unit "hourz"`,
+		"1dya":          `unknown unit "dya"`,
+		"weekly":        `unexpected "weekly"`,
+		"1 day,":        `unknown unit "day,"`,
+		"1:2:3:4":       `unexpected "1:2:3:4"`,
+		"one day":       `unexpected "one"`,
+		"''":            "empty interval",
+		"P":             `unexpected "p"`,
+	}
+
+	for literal, reason := range invalid {
+		t.Run(literal, func(t *testing.T) {
+			t.Parallel()
+
+			assert.EqualError(t, schema.ValidateInterval(literal), reason)
+		})
+	}
+}
+
+func TestValidateIntervals(t *testing.T) {
+	t.Parallel()
+
+	table := func(name, timeType string) schema.Table {
+		return schema.Table{
+			Schema:  schema.DefaultSchema,
+			Name:    name,
+			Columns: []schema.Column{{Name: "ts", DataType: timeType}},
+		}
+	}
+
+	db := &schema.Database{
+		Tables: []schema.Table{table("metrics", "timestamptz"), table("events", "bigint")},
+		Hypertables: []schema.Hypertable{
+			{
+				Schema:            schema.DefaultSchema,
+				TableName:         "metrics",
+				TimeColumnName:    "ts",
+				ChunkTimeInterval: "1 weeek",
+				RetentionPolicy:   &schema.RetentionPolicy{DropAfter: "90 days"},
+				CompressionPolicy: &schema.CompressionPolicy{
+					CompressAfter:    "7 days",
+					ScheduleInterval: "1 dya",
+				},
+			},
+			{
+				Schema:            schema.DefaultSchema,
+				TableName:         "events",
+				TimeColumnName:    "ts",
+				ChunkTimeInterval: "86400000",
+				RetentionPolicy:   &schema.RetentionPolicy{DropAfter: "30 days"},
+			},
+		},
+		ContinuousAggregates: []schema.ContinuousAggregate{
+			{
+				Schema:         schema.DefaultSchema,
+				ViewName:       "metrics_hourly",
+				HypertableName: "metrics",
+				RefreshPolicy: &schema.RefreshPolicy{
+					StartOffset:      "NULL",
+					EndOffset:        "1 hour",
+					ScheduleInterval: "1 hour",
+				},
+			},
+			{
+				Schema:         schema.DefaultSchema,
+				ViewName:       "events_daily",
+				HypertableName: "events",
+				RefreshPolicy: &schema.RefreshPolicy{
+					StartOffset:      "1000",
+					EndOffset:        "1 day",
+					ScheduleInterval: "1 day",
+				},
+			},
+		},
+	}
+
+	errs := schema.ValidateIntervals(db)
+	require.Len(t, errs, 4)
+
+	got := make([]string, 0, len(errs))
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+
+	integerReason := "the hypertable is partitioned by an integer column, " +
+		"so this must be an integer"
+	assert.Equal(t, []string{
+		`public.metrics: invalid chunk_time_interval "1 weeek": unknown unit "weeek"`,
+		`public.metrics: invalid compression schedule_interval "1 dya": unknown unit "dya"`,
+		`public.events: invalid drop_after "30 days": ` + integerReason,
+		`public.events_daily: invalid end_offset "1 day": ` + integerReason,
+	}, got)
+	assert.Equal(t, "public.metrics", errs[0].Object)
+	assert.Equal(t, "1 weeek", errs[0].Literal)
+}
@@ -0,0 +1,98 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/accented-ai/pgtofu/internal/schema"
+)
+
+func triggerDatabase(functions ...schema.Function) *schema.Database {
+	return &schema.Database{
+		Functions: functions,
+		Triggers: []schema.Trigger{{
+			Schema:         "app",
+			Name:           "users_touch",
+			TableName:      "users",
+			Timing:         "AFTER",
+			Events:         []string{"UPDATE"},
+			ForEachRow:     true,
+			FunctionSchema: "app",
+			FunctionName:   "touch",
+		}},
+	}
+}
+
+func TestValidateTriggerFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns trigger", func(t *testing.T) {
+		t.Parallel()
+
+		db := triggerDatabase(schema.Function{Schema: "app", Name: "touch", ReturnType: "TRIGGER"})
+		assert.Empty(t, schema.ValidateTriggerFunctions(db))
+	})
+
+	t.Run("returns void", func(t *testing.T) {
+		t.Parallel()
+
+		db := triggerDatabase(schema.Function{Schema: "app", Name: "touch", ReturnType: "void"})
+
+		errs := schema.ValidateTriggerFunctions(db)
+		require.Len(t, errs, 1)
+
+		assert.Equal(t, "users_touch on app.users", errs[0].Object)
+		assert.Equal(t, "app.touch", errs[0].Function)
+		assert.Equal(t, "void", errs[0].ReturnType)
+		assert.Equal(t, "trigger users_touch on app.users executes app.touch(), "+
+			"which returns void instead of trigger", errs[0].Error())
+	})
+
+	t.Run("procedure", func(t *testing.T) {
+		t.Parallel()
+
+		db := triggerDatabase(schema.Function{
+			Schema: "app",
+			Name:   "touch",
+			Kind:   schema.FunctionKindProcedure,
+		})
+
+		errs := schema.ValidateTriggerFunctions(db)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "procedure", errs[0].ReturnType)
+	})
+
+	t.Run("function missing", func(t *testing.T) {
+		t.Parallel()
+
+		db := triggerDatabase(schema.Function{Schema: "app", Name: "other", ReturnType: "void"})
+		assert.Empty(t, schema.ValidateTriggerFunctions(db))
+	})
+
+	t.Run("only an overload with arguments", func(t *testing.T) {
+		t.Parallel()
+
+		db := triggerDatabase(schema.Function{
+			Schema:        "app",
+			Name:          "touch",
+			ArgumentTypes: []string{"bigint"},
+			ArgumentModes: []string{"IN"},
+			ReturnType:    "void",
+		})
+		assert.Empty(t, schema.ValidateTriggerFunctions(db),
+			"CREATE TRIGGER never calls a function with input arguments")
+	})
+
+	t.Run("unqualified names", func(t *testing.T) {
+		t.Parallel()
+
+		db := triggerDatabase(schema.Function{Name: "touch", ReturnType: "integer"})
+		db.Triggers[0].FunctionSchema = ""
+
+		errs := schema.ValidateTriggerFunctions(db)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "public.touch", errs[0].Function)
+	})
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultTimePrecision is the fractional seconds precision of a timestamp,
+// time or interval declared without one.
+const DefaultTimePrecision = 6
+
+var timePrecisionPattern = regexp.MustCompile(`\s*\(\s*(\d+)\s*\)`)
+
+// IsTimeType reports whether dataType is a timestamp, time or interval type,
+// whose typmod is a fractional seconds precision.
+func IsTimeType(dataType string) bool {
+	fields := strings.Fields(strings.ToLower(dataType))
+	if len(fields) == 0 {
+		return false
+	}
+
+	name, _, _ := strings.Cut(fields[0], "(")
+
+	switch name {
+	case "timestamp", "timestamptz", "time", "timetz", "interval":
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTimeType splits a time type such as "timestamp(3) with time zone" or
+// "interval day to second(2)" into the type without its precision and the
+// precision, which is nil when none is declared. Interval fields are kept as
+// part of the type.
+func ParseTimeType(dataType string) (string, *int) {
+	loc := timePrecisionPattern.FindStringSubmatchIndex(dataType)
+	if loc == nil {
+		return strings.Join(strings.Fields(dataType), " "), nil
+	}
+
+	precision, err := strconv.Atoi(dataType[loc[2]:loc[3]])
+	if err != nil {
+		return strings.Join(strings.Fields(dataType), " "), nil
+	}
+
+	base := dataType[:loc[0]] + " " + dataType[loc[1]:]
+
+	return strings.Join(strings.Fields(base), " "), &precision
+}
+
+// FormatTimeType renders a time type with its precision where PostgreSQL
+// expects it: after timestamp or time, before WITH TIME ZONE, and after the
+// fields of an interval.
+func FormatTimeType(dataType string, precision int) string {
+	fields := strings.Fields(dataType)
+	if len(fields) > 1 && !strings.EqualFold(fields[0], "interval") {
+		return fmt.Sprintf("%s(%d) %s", fields[0], precision, strings.Join(fields[1:], " "))
+	}
+
+	return fmt.Sprintf("%s(%d)", strings.Join(fields, " "), precision)
+}
@@ -1,6 +1,9 @@
 package schema
 
-import "strings"
+import (
+	"encoding/json"
+	"strings"
+)
 
 type Hypertable struct {
 	Schema    string `json:"schema"`
@@ -10,16 +13,56 @@ type Hypertable struct {
 	TimeColumnType    string `json:"time_column_type"`
 	PartitionInterval string `json:"partition_interval"`
 
-	SpacePartitions int      `json:"space_partitions,omitempty"`
-	SpaceColumns    []string `json:"space_columns,omitempty"`
+	// Dimensions lists the dimensions added with add_dimension, in order. The
+	// primary time dimension is described by TimeColumnName.
+	Dimensions []Dimension `json:"dimensions,omitempty"`
 
 	CompressionEnabled  bool                 `json:"compression_enabled"`
 	CompressionSettings *CompressionSettings `json:"compression_settings,omitempty"`
+	CompressionPolicy   *CompressionPolicy   `json:"compression_policy,omitempty"`
 	RetentionPolicy     *RetentionPolicy     `json:"retention_policy,omitempty"`
 	ChunkTimeInterval   string               `json:"chunk_time_interval,omitempty"`
 	NumDimensions       int                  `json:"num_dimensions"`
 }
 
+// UnmarshalJSON also reads the space_partitions and space_columns fields that
+// schemas extracted before Dimensions was introduced carry. Each space column
+// becomes a space dimension; those files did not record the partition count,
+// so it is left unset.
+func (h *Hypertable) UnmarshalJSON(data []byte) error {
+	type alias Hypertable
+
+	legacy := struct {
+		*alias
+
+		SpaceColumns []string `json:"space_columns"`
+	}{alias: (*alias)(h)}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if len(h.Dimensions) == 0 {
+		for _, col := range legacy.SpaceColumns {
+			h.Dimensions = append(h.Dimensions, Dimension{ColumnName: col, Type: DimensionSpace})
+		}
+	}
+
+	return nil
+}
+
+const (
+	DimensionTime  = "time"
+	DimensionSpace = "space"
+)
+
+type Dimension struct {
+	ColumnName       string `json:"column_name"`
+	Type             string `json:"type"`
+	NumberPartitions int    `json:"number_partitions,omitempty"`
+	Interval         string `json:"interval,omitempty"`
+}
+
 type CompressionSettings struct {
 	SegmentByColumns  []string        `json:"segment_by_columns,omitempty"`
 	OrderByColumns    []OrderByColumn `json:"order_by_columns,omitempty"`
@@ -49,12 +92,22 @@ type ContinuousAggregate struct {
 	Finalized        bool           `json:"finalized,omitempty"`
 	Comment          string         `json:"comment,omitempty"`
 	Indexes          []Index        `json:"indexes,omitempty"`
+	// CompressionEnabled and CompressionPolicy describe compression of the
+	// aggregate's materialization hypertable. The policy is keyed by the
+	// aggregate's view name, which is how TimescaleDB addresses it.
+	CompressionEnabled bool               `json:"compression_enabled,omitempty"`
+	CompressionPolicy  *CompressionPolicy `json:"compression_policy,omitempty"`
 }
 
+// RefreshPolicy is the add_continuous_aggregate_policy job of a continuous
+// aggregate. An empty or NULL offset leaves that end of the refresh window
+// open. InitialStart only sets when the job first runs, so it is written when
+// the policy is added but never compared.
 type RefreshPolicy struct {
 	StartOffset      string `json:"start_offset"`
 	EndOffset        string `json:"end_offset"`
 	ScheduleInterval string `json:"schedule_interval"`
+	InitialStart     string `json:"initial_start,omitempty"`
 }
 
 type CompressionPolicy struct {
@@ -62,6 +115,7 @@ type CompressionPolicy struct {
 	HypertableName   string `json:"hypertable_name"`
 	CompressAfter    string `json:"compress_after"`
 	ScheduleInterval string `json:"schedule_interval,omitempty"`
+	InitialStart     string `json:"initial_start,omitempty"`
 }
 
 func (h *Hypertable) QualifiedTableName() string {
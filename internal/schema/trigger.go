@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TriggerFunctionError is a trigger of a schema whose function, as the same
+// schema declares it, does not return trigger. PostgreSQL only rejects the
+// CREATE TRIGGER when the migration runs.
+type TriggerFunctionError struct {
+	// Object is the trigger's name and the qualified name of its table.
+	Object string
+	// Function is the qualified name of the function the trigger executes,
+	// and ReturnType what it returns instead, or "procedure" for a procedure.
+	Function   string
+	ReturnType string
+}
+
+func (e *TriggerFunctionError) Error() string {
+	return fmt.Sprintf("trigger %s executes %s(), which returns %s instead of trigger",
+		e.Object, e.Function, e.ReturnType)
+}
+
+// ValidateTriggerFunctions checks that every trigger of db, constraint
+// triggers included, executes a function that returns trigger. The function
+// is the one db declares under the trigger's function name without input
+// arguments, as CREATE TRIGGER resolves it; its arguments are passed through
+// TG_ARGV instead. A trigger whose function db does not declare is left
+// alone.
+func ValidateTriggerFunctions(db *Database) []*TriggerFunctionError {
+	functions := make(map[string]*Function)
+
+	for i := range db.Functions {
+		fn := &db.Functions[i]
+		if !hasInputArguments(fn) {
+			functions[triggerFunctionKey(fn.Schema, fn.Name)] = fn
+		}
+	}
+
+	var errs []*TriggerFunctionError
+
+	for i := range db.Triggers {
+		trigger := &db.Triggers[i]
+
+		fn := functions[triggerFunctionKey(trigger.FunctionSchema, trigger.FunctionName)]
+		if fn == nil {
+			continue
+		}
+
+		returnType := strings.ToLower(strings.TrimSpace(fn.ReturnType))
+		if fn.IsProcedure() {
+			returnType = FunctionKindProcedure
+		}
+
+		if strings.TrimPrefix(returnType, "pg_catalog.") == "trigger" {
+			continue
+		}
+
+		errs = append(errs, &TriggerFunctionError{
+			Object:     trigger.Name + " on " + trigger.QualifiedTableName(),
+			Function:   fn.QualifiedName(),
+			ReturnType: returnType,
+		})
+	}
+
+	return errs
+}
+
+func triggerFunctionKey(schemaName, name string) string {
+	return QualifiedName(NormalizeSchemaName(schemaName), NormalizeIdentifier(name))
+}
+
+// hasInputArguments reports whether calling fn takes arguments: OUT and
+// TABLE arguments are results, not inputs.
+func hasInputArguments(fn *Function) bool {
+	for i := range fn.ArgumentTypes {
+		if i >= len(fn.ArgumentModes) {
+			return true
+		}
+
+		switch strings.ToUpper(fn.ArgumentModes[i]) {
+		case "OUT", "TABLE":
+		default:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypedTableError is a column of a typed table that its composite type, as
+// the same schema declares it, does not have. A typed table takes its columns
+// from the type, so PostgreSQL rejects a local column when the migration
+// runs.
+type TypedTableError struct {
+	// Object is the qualified name of the table.
+	Object string
+	Column string
+	// Type is the qualified name of the table's type.
+	Type string
+}
+
+func (e *TypedTableError) Error() string {
+	return fmt.Sprintf("%s.%s is not an attribute of %s: a table created OF a type "+
+		"takes its columns from the type, so add the attribute to the type instead",
+		e.Object, e.Column, e.Type)
+}
+
+// ValidateTypedTables checks that every column of a typed table of db is an
+// attribute of the composite type db declares for it. Typed tables whose type
+// db does not declare are left alone.
+func ValidateTypedTables(db *Database) []*TypedTableError {
+	var errs []*TypedTableError
+
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		if table.TypedOf == "" {
+			continue
+		}
+
+		ct := db.CompositeTypeOf(table)
+		if ct == nil {
+			continue
+		}
+
+		attributes := make(map[string]bool, len(ct.Attributes))
+		for _, attr := range ct.Attributes {
+			attributes[NormalizeIdentifier(attr.Name)] = true
+		}
+
+		for j := range table.Columns {
+			if !attributes[NormalizeIdentifier(table.Columns[j].Name)] {
+				errs = append(errs, &TypedTableError{
+					Object: table.QualifiedName(),
+					Column: table.Columns[j].Name,
+					Type:   ct.QualifiedName(),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// CompositeTypeOf returns the composite type db declares for a typed table,
+// or nil. An unqualified type name is looked up in the default schema.
+func (db *Database) CompositeTypeOf(table *Table) *CustomType {
+	schemaName, name, found := strings.Cut(table.TypedOf, ".")
+	if !found {
+		schemaName, name = "", schemaName
+	}
+
+	ct := db.GetCustomType(schemaName, name)
+	if ct == nil || ct.Type != "composite" {
+		return nil
+	}
+
+	return ct
+}
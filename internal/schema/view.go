@@ -1,13 +1,21 @@
 package schema
 
 type View struct {
-	Schema      string `json:"schema"`
-	Name        string `json:"name"`
-	Definition  string `json:"definition"`
-	Comment     string `json:"comment,omitempty"`
-	Owner       string `json:"owner,omitempty"`
+	Schema     string `json:"schema"`
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+	Comment    string `json:"comment,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	// CheckOption is LOCAL or CASCADED for a view declared WITH CHECK OPTION,
+	// and empty or NONE otherwise.
 	CheckOption string `json:"check_option,omitempty"`
 	IsUpdatable bool   `json:"is_updatable,omitempty"`
+	// Options are the view options set with WITH (...), such as
+	// security_barrier and security_invoker. A check_option given there is
+	// recorded in CheckOption instead.
+	Options map[string]string `json:"options,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
 type MaterializedView struct {
@@ -19,6 +27,11 @@ type MaterializedView struct {
 	Tablespace string  `json:"tablespace,omitempty"`
 	Indexes    []Index `json:"indexes,omitempty"`
 	WithData   bool    `json:"with_data"`
+	// StorageParams are the storage parameters set with WITH (...) or ALTER
+	// MATERIALIZED VIEW ... SET (...), such as fillfactor.
+	StorageParams map[string]string `json:"storage_params,omitempty"`
+
+	Source *SourceLocation `json:"source,omitempty"`
 }
 
 func (v *View) QualifiedName() string {
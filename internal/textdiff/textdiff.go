@@ -0,0 +1,190 @@
+// Package textdiff produces line-based unified diffs. It is a small longest
+// common subsequence implementation meant for schema objects, which are short
+// enough that the quadratic table is not a concern.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change.
+const DefaultContext = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns the unified diff of from and to, labelled fromName and
+// toName, with context unchanged lines around each hunk. It returns an empty
+// string when the texts have the same lines.
+func Unified(fromName, toName, from, to string, context int) string {
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	hunks := groupHunks(ops, max(context, 0))
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range hunks {
+		h.write(&sb, ops)
+	}
+
+	return sb.String()
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+// diffLines returns the edit script turning a into b. Deletions are listed
+// before insertions where both are possible, as diff(1) does.
+func diffLines(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{kind: opDelete, line: a[i]})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		ops = append(ops, op{kind: opDelete, line: a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		ops = append(ops, op{kind: opInsert, line: b[j]})
+	}
+
+	return ops
+}
+
+// hunk is the range ops[start:end] together with the line numbers, counted
+// from zero, that the range starts at in each text.
+type hunk struct {
+	start, end       int
+	fromLine, toLine int
+}
+
+func groupHunks(ops []op, context int) []hunk {
+	var hunks []hunk
+
+	fromLine, toLine := 0, 0
+	lastChange := -1
+
+	for idx, o := range ops {
+		if o.kind != opEqual {
+			if lastChange >= 0 && idx-lastChange-1 <= 2*context {
+				hunks[len(hunks)-1].end = min(idx+context+1, len(ops))
+			} else {
+				start := max(idx-context, 0)
+				hunks = append(hunks, hunk{
+					start:    start,
+					end:      min(idx+context+1, len(ops)),
+					fromLine: fromLine - (idx - start),
+					toLine:   toLine - (idx - start),
+				})
+			}
+
+			lastChange = idx
+		}
+
+		if o.kind != opInsert {
+			fromLine++
+		}
+
+		if o.kind != opDelete {
+			toLine++
+		}
+	}
+
+	return hunks
+}
+
+func (h hunk) write(sb *strings.Builder, ops []op) {
+	fromCount, toCount := 0, 0
+
+	for _, o := range ops[h.start:h.end] {
+		if o.kind != opInsert {
+			fromCount++
+		}
+
+		if o.kind != opDelete {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n",
+		hunkRange(h.fromLine, fromCount), hunkRange(h.toLine, toCount))
+
+	for _, o := range ops[h.start:h.end] {
+		switch o.kind {
+		case opEqual:
+			sb.WriteString(" ")
+		case opDelete:
+			sb.WriteString("-")
+		case opInsert:
+			sb.WriteString("+")
+		}
+
+		sb.WriteString(o.line)
+		sb.WriteString("\n")
+	}
+}
+
+// hunkRange formats a hunk's line range the way diff(1) does: the count is
+// left out when it is one, and an empty range names the line before it.
+func hunkRange(line, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line)
+	case 1:
+		return fmt.Sprintf("%d", line+1)
+	default:
+		return fmt.Sprintf("%d,%d", line+1, count)
+	}
+}
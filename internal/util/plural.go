@@ -0,0 +1,13 @@
+package util
+
+import "strconv"
+
+// Plural returns n followed by singular when n is 1 and by plural otherwise,
+// such as "1 trigger" and "2 triggers".
+func Plural(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+
+	return strconv.Itoa(n) + " " + plural
+}
@@ -95,3 +95,34 @@ func (p *Pool) CurrentDatabase(ctx context.Context) (string, error) {
 
 	return dbName, nil
 }
+
+// Conn is a connection held from the pool. Session state, such as an
+// advisory lock or an open transaction, carries from one call to the next.
+type Conn struct {
+	conn *pgxpool.Conn
+}
+
+func (p *Pool) Acquire(ctx context.Context) (*Conn, error) {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, util.WrapError("acquire connection", err)
+	}
+
+	return &Conn{conn: conn}, nil
+}
+
+func (c *Conn) Exec(ctx context.Context, sql string, args ...any) error {
+	_, err := c.conn.Exec(ctx, sql, args...)
+
+	return err //nolint:wrapcheck
+}
+
+func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return c.conn.QueryRow(ctx, sql, args...)
+}
+
+// Release returns the connection to the pool, which closes it if a
+// transaction is still open on it.
+func (c *Conn) Release() {
+	c.conn.Release()
+}
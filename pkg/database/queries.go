@@ -8,12 +8,19 @@ import (
 	"github.com/accented-ai/pgtofu/internal/util"
 )
 
+// Querier runs queries. Pool implements it, and tests stand in for a
+// database with their own.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type QueryHelper struct {
-	pool *Pool
+	querier Querier
 }
 
-func NewQueryHelper(pool *Pool) *QueryHelper {
-	return &QueryHelper{pool: pool}
+func NewQueryHelper(querier Querier) *QueryHelper {
+	return &QueryHelper{querier: querier}
 }
 
 func (qh *QueryHelper) FetchAll(
@@ -22,7 +29,7 @@ func (qh *QueryHelper) FetchAll(
 	scanFunc func(pgx.Rows) error,
 	args ...any,
 ) error {
-	rows, err := qh.pool.Query(ctx, query, args...)
+	rows, err := qh.querier.Query(ctx, query, args...)
 	if err != nil {
 		return util.WrapError("execute query", err)
 	}
@@ -47,7 +54,7 @@ func (qh *QueryHelper) FetchOne(
 	scanFunc func(pgx.Row) error,
 	args ...any,
 ) error {
-	row := qh.pool.QueryRow(ctx, query, args...)
+	row := qh.querier.QueryRow(ctx, query, args...)
 	if err := scanFunc(row); err != nil {
 		return util.WrapError("scan row", err)
 	}